@@ -0,0 +1,341 @@
+// Package aggregate implements a BookFinder decorator that searches all
+// providers at once and merges their results for the same book, recording
+// which provider supplied each field.
+package aggregate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/isbn"
+	"github.com/fwojciec/bookid/policy"
+)
+
+// Ensure type implements interface.
+var _ bookid.BookFinder = (*Finder)(nil)
+
+// Provider represents a provider searched by a Finder.
+type Provider struct {
+	Name   string
+	Finder bookid.BookFinder
+
+	// Longest time a search of the provider may take. Zero leaves only the
+	// deadline of the caller's context.
+	Timeout time.Duration
+}
+
+// Finder searches every provider at the same time and merges the results
+// that describe the same publication, so that fields missing from the most
+// preferred provider's result are filled in from the others and conflicting
+// fields are resolved by merge policies. Unlike falling
+// back from one provider to the next, every search waits for the slowest
+// provider, up to the Timeout.
+type Finder struct {
+	providers []Provider
+
+	// Soft deadline of a whole search. Once it passes, the results of the
+	// providers that answered are returned without waiting for the others.
+	// Zero waits for every provider within the caller's deadline.
+	Timeout time.Duration
+
+	// Policies resolving fields that providers give different values for.
+	// The most preferred provider's value is kept for fields without one.
+	Policies policy.Policies
+
+	// Receives debug logs of each provider's outcome and latency. Defaults
+	// to discarding them.
+	Logger *slog.Logger
+
+	// Returns the current time. Defaults to time.Now().
+	// Can be mocked for tests.
+	Now func() time.Time
+}
+
+// NewFinder returns a Finder merging the results of providers, most
+// preferred first.
+func NewFinder(providers ...Provider) *Finder {
+	return &Finder{
+		providers: providers,
+		Logger:    slog.New(slog.DiscardHandler),
+		Now:       time.Now,
+	}
+}
+
+// Search searches every provider and merges their results in order of
+// preference. A provider that fails is left out; the last error is returned
+// only if every provider failed. Providers that run out of their own time or
+// have not answered by the Finder's Timeout are left out too, and named in
+// the TimedOut field of each result. Returns the context's error if ctx is
+// done.
+func (f *Finder) Search(ctx context.Context, query string, opts bookid.SearchOptions) ([]bookid.BookResult, error) {
+	searchCtx := ctx
+	if f.Timeout > 0 {
+		var cancel context.CancelFunc
+		searchCtx, cancel = context.WithTimeout(ctx, f.Timeout)
+		defer cancel()
+	}
+
+	type answer struct {
+		i       int
+		results []bookid.BookResult
+		err     error
+	}
+	// Buffered so that providers answering after the deadline do not block.
+	ch := make(chan answer, len(f.providers))
+	for i, p := range f.providers {
+		go func() {
+			results, err := f.search(searchCtx, p, query, opts)
+			ch <- answer{i, results, err}
+		}()
+	}
+
+	answers := make([][]bookid.BookResult, len(f.providers))
+	errs := make([]error, len(f.providers))
+	for i := range errs {
+		errs[i] = context.DeadlineExceeded
+	}
+wait:
+	for range f.providers {
+		select {
+		case a := <-ch:
+			answers[a.i], errs[a.i] = a.results, a.err
+		case <-searchCtx.Done():
+			break wait
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var lastErr error
+	var timedOut []string
+	answered := false
+	var results []bookid.BookResult
+	for i, p := range f.providers {
+		if errs[i] != nil {
+			lastErr = fmt.Errorf("searching %s: %w", p.Name, errs[i])
+			if errors.Is(errs[i], context.DeadlineExceeded) {
+				timedOut = append(timedOut, p.Name)
+			}
+			continue
+		}
+		answered = true
+		results = mergeInto(results, answers[i], f.Policies)
+	}
+	if !answered && lastErr != nil {
+		return nil, lastErr
+	}
+	if len(timedOut) > 0 {
+		f.Logger.DebugContext(ctx, "returning partial results", "timed_out", timedOut)
+	}
+
+	results = opts.Apply(results)
+	for i := range results {
+		results[i].TimedOut = timedOut
+	}
+	return results, nil
+}
+
+// search searches a single provider within its timeout.
+func (f *Finder) search(ctx context.Context, p Provider, query string, opts bookid.SearchOptions) ([]bookid.BookResult, error) {
+	if p.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.Timeout)
+		defer cancel()
+	}
+
+	start := f.Now()
+	results, err := p.Finder.Search(ctx, query, opts)
+	f.Logger.DebugContext(ctx, "searched provider",
+		"provider", p.Name, "timeout", p.Timeout, "duration", f.Now().Sub(start), "results", len(results), "error", err)
+	return results, err
+}
+
+// mergeInto merges each of others into the result in results describing the
+// same publication by policies, or appends it if there is none.
+func mergeInto(results, others []bookid.BookResult, policies policy.Policies) []bookid.BookResult {
+	for _, other := range others {
+		i := slices.IndexFunc(results, func(r bookid.BookResult) bool { return Same(&r, &other) })
+		if i < 0 {
+			results = append(results, other)
+			continue
+		}
+		Merge(&results[i], &other, policies)
+	}
+	return results
+}
+
+// Same reports whether two results describe the same publication: they
+// share an ISBN, with ISBN-10s compared as ISBN-13s, or a Google Books
+// volume ID.
+func Same(a, b *bookid.BookResult) bool {
+	if x, y := isbn13(a), isbn13(b); x != "" && x == y {
+		return true
+	}
+	return a.GoogleBooksVolumeID != "" && a.GoogleBooksVolumeID == b.GoogleBooksVolumeID
+}
+
+// isbn13 returns the normalized ISBN-13 of r, converted from its ISBN-10 if
+// needed.
+func isbn13(r *bookid.BookResult) string {
+	if v := isbn.Normalize(r.ISBN13); v != "" {
+		return v
+	}
+	v, _ := isbn.To13(isbn.Normalize(r.ISBN10))
+	return v
+}
+
+// Merge fills in the fields missing from r with those of other, a less
+// preferred result for the same publication, recording other's provider of
+// each field taken in r.Provenance. Metadata is recorded under its key
+// prefixed with "metadata.". Fields both results have are resolved by
+// policies: values that replace r's are recorded the same way, and those
+// held back for review are added to r.Conflicts. The confidence of r becomes
+// the higher of the two.
+func Merge(r, other *bookid.BookResult, policies policy.Policies) {
+	// Maps may be shared with the results of the providers.
+	r.Provenance, r.Metadata = maps.Clone(r.Provenance), maps.Clone(r.Metadata)
+
+	for _, f := range fields() {
+		current := policy.Value{Value: f.get(r), Provider: r.FieldProvider(f.name)}
+		candidate := policy.Value{Value: f.get(other), Provider: other.FieldProvider(f.name)}
+		switch policies.Resolve(f.name, current, candidate, policy.Keep) {
+		case policy.Replace:
+			f.set(r, f.get(other))
+			setProvider(r, f.name, candidate.Provider)
+		case policy.Review:
+			r.Conflicts = append(r.Conflicts, bookid.FieldConflict{Field: f.name, Value: candidate.Value, Provider: candidate.Provider})
+		}
+	}
+	if len(r.GoogleBooksData) == 0 && len(other.GoogleBooksData) > 0 {
+		r.GoogleBooksData = other.GoogleBooksData
+		setProvider(r, "google_books_data", other.FieldProvider("google_books_data"))
+	}
+	if r.Dimensions.IsZero() && !other.Dimensions.IsZero() {
+		r.Dimensions = other.Dimensions
+		setProvider(r, "dimensions", other.FieldProvider("dimensions"))
+	}
+	if r.Series == "" && other.Series != "" {
+		r.Series, r.SeriesVolume = other.Series, other.SeriesVolume
+		setProvider(r, "series", other.FieldProvider("series"))
+	}
+	if r.Sale == nil && other.Sale != nil {
+		r.Sale = other.Sale
+		setProvider(r, "sale", other.FieldProvider("sale"))
+	}
+	if r.Access == nil && other.Access != nil {
+		r.Access = other.Access
+		setProvider(r, "access", other.FieldProvider("access"))
+	}
+	if r.Classification == nil && other.Classification != nil {
+		r.Classification = other.Classification
+		setProvider(r, "classification", other.FieldProvider("classification"))
+	}
+	if len(r.Contributors) == 0 && len(other.Contributors) > 0 {
+		r.Contributors = slices.Clone(other.Contributors)
+		setProvider(r, "contributors", other.FieldProvider("contributors"))
+	}
+	if len(r.TableOfContents) == 0 && len(other.TableOfContents) > 0 {
+		r.TableOfContents = slices.Clone(other.TableOfContents)
+		setProvider(r, "table_of_contents", other.FieldProvider("table_of_contents"))
+	}
+	if len(r.Subjects) == 0 && len(other.Subjects) > 0 {
+		r.Subjects = slices.Clone(other.Subjects)
+		setProvider(r, "subjects", other.FieldProvider("subjects"))
+	}
+	if len(r.BISACCodes) == 0 && len(other.BISACCodes) > 0 {
+		r.BISACCodes = slices.Clone(other.BISACCodes)
+		setProvider(r, "bisac_codes", other.FieldProvider("bisac_codes"))
+	}
+	for k, v := range other.Metadata {
+		if _, ok := r.Metadata[k]; ok {
+			continue
+		}
+		if r.Metadata == nil {
+			r.Metadata = make(map[string]string)
+		}
+		r.Metadata[k] = v
+		setProvider(r, "metadata."+k, other.FieldProvider("metadata."+k))
+	}
+	r.Confidence = max(r.Confidence, other.Confidence)
+}
+
+// field is a field of results that merge policies apply to, with its value
+// as compared by policies.
+type field struct {
+	name string
+	get  func(r *bookid.BookResult) string
+	set  func(r *bookid.BookResult, v string)
+}
+
+// fields returns the fields of results that merge policies apply to.
+func fields() []field {
+	str := func(name string, f func(r *bookid.BookResult) *string) field {
+		return field{name, func(r *bookid.BookResult) string { return *f(r) }, func(r *bookid.BookResult, v string) { *f(r) = v }}
+	}
+	return []field{
+		str("title", func(r *bookid.BookResult) *string { return &r.Title }),
+		{"authors", func(r *bookid.BookResult) string { return strings.Join(r.Authors, "; ") }, func(r *bookid.BookResult, v string) {
+			r.Authors = strings.Split(v, "; ")
+		}},
+		str("isbn10", func(r *bookid.BookResult) *string { return &r.ISBN10 }),
+		str("isbn13", func(r *bookid.BookResult) *string { return &r.ISBN13 }),
+		str("publisher", func(r *bookid.BookResult) *string { return &r.Publisher }),
+		{"published_year", func(r *bookid.BookResult) string {
+			if r.PublishedYear == 0 {
+				return ""
+			}
+			return strconv.Itoa(r.PublishedYear)
+		}, func(r *bookid.BookResult, v string) {
+			r.PublishedYear, _ = strconv.Atoi(v)
+		}},
+		str("language", func(r *bookid.BookResult) *string { return &r.Language }),
+		str("binding", func(r *bookid.BookResult) *string { return (*string)(&r.Binding) }),
+		{"page_count", func(r *bookid.BookResult) string {
+			if r.PageCount == 0 {
+				return ""
+			}
+			return strconv.Itoa(r.PageCount)
+		}, func(r *bookid.BookResult, v string) {
+			r.PageCount, _ = strconv.Atoi(v)
+		}},
+		{"duration_minutes", func(r *bookid.BookResult) string {
+			if r.DurationMinutes == 0 {
+				return ""
+			}
+			return strconv.Itoa(r.DurationMinutes)
+		}, func(r *bookid.BookResult, v string) {
+			r.DurationMinutes, _ = strconv.Atoi(v)
+		}},
+		str("google_books_volume_id", func(r *bookid.BookResult) *string { return &r.GoogleBooksVolumeID }),
+		str("oclc_number", func(r *bookid.BookResult) *string { return &r.OCLCNumber }),
+		str("lccn", func(r *bookid.BookResult) *string { return &r.LCCN }),
+		str("doi", func(r *bookid.BookResult) *string { return &r.DOI }),
+		str("asin", func(r *bookid.BookResult) *string { return &r.ASIN }),
+		str("thumbnail_url", func(r *bookid.BookResult) *string { return &r.ThumbnailURL }),
+		str("description", func(r *bookid.BookResult) *string { return &r.Description }),
+		str("original_title", func(r *bookid.BookResult) *string { return &r.OriginalTitle }),
+		str("original_language", func(r *bookid.BookResult) *string { return &r.OriginalLanguage }),
+	}
+}
+
+// setProvider records the provider of a field of r, unless it is r's own
+// provider.
+func setProvider(r *bookid.BookResult, name, provider string) {
+	if provider == "" || provider == r.Provider {
+		delete(r.Provenance, name)
+		return
+	} else if r.Provenance == nil {
+		r.Provenance = make(map[string]string)
+	}
+	r.Provenance[name] = provider
+}
@@ -0,0 +1,239 @@
+package aggregate_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/aggregate"
+	"github.com/fwojciec/bookid/mock"
+	"github.com/fwojciec/bookid/policy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// provider returns a provider finding results, or failing with err.
+func provider(name string, err error, results ...bookid.BookResult) aggregate.Provider {
+	for i := range results {
+		results[i].Provider = name
+	}
+	return aggregate.Provider{Name: name, Finder: &mock.BookFinder{
+		SearchFn: func(context.Context, string, bookid.SearchOptions) ([]bookid.BookResult, error) {
+			return results, err
+		},
+	}}
+}
+
+func TestFinder_Search(t *testing.T) {
+	t.Parallel()
+
+	t.Run("merges", func(t *testing.T) {
+		t.Parallel()
+		f := aggregate.NewFinder(
+			provider("googlebooks", nil,
+				bookid.BookResult{Title: "The Hobbit", ISBN13: "9780261102217", Confidence: 0.8},
+				bookid.BookResult{Title: "The Hobbit: Graphic Novel", ISBN13: "9780261102668", Confidence: 0.5},
+			),
+			provider("worldcat", bookid.Errorf(bookid.EUNAVAILABLE, "WorldCat is unavailable.")),
+			provider("openlibrary", nil, bookid.BookResult{
+				Title:         "The hobbit",
+				Authors:       []string{"J. R. R. Tolkien"},
+				ISBN10:        "0-261-10221-4",
+				Publisher:     "Unwin",
+				PublishedYear: 1991,
+				Metadata:      map[string]string{"pages": "310"},
+				Confidence:    0.9,
+			}),
+		)
+
+		results, err := f.Search(context.Background(), "the hobbit", bookid.SearchOptions{})
+		require.NoError(t, err)
+		require.Len(t, results, 2)
+		assert.Equal(t, bookid.BookResult{
+			Title:         "The Hobbit",
+			Authors:       []string{"J. R. R. Tolkien"},
+			ISBN10:        "0-261-10221-4",
+			ISBN13:        "9780261102217",
+			Publisher:     "Unwin",
+			PublishedYear: 1991,
+			Metadata:      map[string]string{"pages": "310"},
+			Provider:      "googlebooks",
+			Provenance: map[string]string{
+				"authors":        "openlibrary",
+				"isbn10":         "openlibrary",
+				"publisher":      "openlibrary",
+				"published_year": "openlibrary",
+				"metadata.pages": "openlibrary",
+			},
+			Confidence: 0.9,
+		}, results[0])
+		assert.Equal(t, "googlebooks", results[0].FieldProvider("title"))
+		assert.Equal(t, "The Hobbit: Graphic Novel", results[1].Title)
+		assert.Nil(t, results[1].Provenance)
+	})
+
+	t.Run("policies", func(t *testing.T) {
+		t.Parallel()
+		f := aggregate.NewFinder(
+			provider("googlebooks", nil, bookid.BookResult{
+				Title:         "Dune",
+				ISBN13:        "9780441172719",
+				Publisher:     "Penguin",
+				PublishedYear: 1990,
+				Language:      "en",
+			}),
+			provider("openlibrary", nil, bookid.BookResult{
+				Title:         "Dune: Deluxe Edition",
+				ISBN13:        "978-0-441-17271-9",
+				Publisher:     "Ace",
+				PublishedYear: 2019,
+				Language:      "fr",
+			}),
+		)
+		f.Policies = policy.Policies{
+			"title":          {Rule: policy.RuleLongest},
+			"publisher":      {Rule: policy.RulePrefer, Providers: []string{"openlibrary"}},
+			"published_year": {Rule: policy.RuleNewest},
+			"language":       {Rule: policy.RuleReview},
+		}
+
+		results, err := f.Search(context.Background(), "dune", bookid.SearchOptions{})
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		assert.Equal(t, "Dune: Deluxe Edition", results[0].Title)
+		assert.Equal(t, "Ace", results[0].Publisher)
+		assert.Equal(t, 2019, results[0].PublishedYear)
+		assert.Equal(t, "en", results[0].Language)
+		assert.Equal(t, "9780441172719", results[0].ISBN13, "equal values are kept")
+		assert.Equal(t, map[string]string{
+			"title":          "openlibrary",
+			"publisher":      "openlibrary",
+			"published_year": "openlibrary",
+		}, results[0].Provenance)
+		assert.Equal(t, []bookid.FieldConflict{{Field: "language", Value: "fr", Provider: "openlibrary"}}, results[0].Conflicts)
+	})
+
+	t.Run("max_results", func(t *testing.T) {
+		t.Parallel()
+		f := aggregate.NewFinder(
+			provider("googlebooks", nil, bookid.BookResult{Title: "Dune", ISBN13: "9780441172719"}),
+			provider("openlibrary", nil, bookid.BookResult{Title: "Dune Messiah", ISBN13: "9780441172696"}),
+		)
+		results, err := f.Search(context.Background(), "dune", bookid.SearchOptions{MaxResults: 1})
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		assert.Equal(t, "Dune", results[0].Title)
+	})
+
+	t.Run("all_failed", func(t *testing.T) {
+		t.Parallel()
+		f := aggregate.NewFinder(
+			provider("googlebooks", bookid.Errorf(bookid.EUNAVAILABLE, "Google Books is unavailable.")),
+			provider("openlibrary", bookid.Errorf(bookid.EUNAVAILABLE, "Open Library is unavailable.")),
+		)
+		_, err := f.Search(context.Background(), "dune", bookid.SearchOptions{})
+		assert.Equal(t, bookid.EUNAVAILABLE, bookid.ErrorCode(err))
+		assert.ErrorContains(t, err, "searching openlibrary")
+	})
+
+	t.Run("nothing_found", func(t *testing.T) {
+		t.Parallel()
+		f := aggregate.NewFinder(
+			provider("googlebooks", bookid.Errorf(bookid.EUNAVAILABLE, "Google Books is unavailable.")),
+			provider("openlibrary", nil),
+		)
+		results, err := f.Search(context.Background(), "dune", bookid.SearchOptions{})
+		require.NoError(t, err)
+		assert.Empty(t, results)
+	})
+
+	t.Run("soft_deadline", func(t *testing.T) {
+		t.Parallel()
+		// The slow provider ignores cancellation, so the search must not
+		// wait for it.
+		release := make(chan struct{})
+		t.Cleanup(func() { close(release) })
+		slow := aggregate.Provider{Name: "worldcat", Finder: &mock.BookFinder{
+			SearchFn: func(context.Context, string, bookid.SearchOptions) ([]bookid.BookResult, error) {
+				<-release
+				return nil, nil
+			},
+		}}
+		timesOut := aggregate.Provider{Name: "isbndb", Timeout: time.Millisecond, Finder: &mock.BookFinder{
+			SearchFn: func(ctx context.Context, _ string, _ bookid.SearchOptions) ([]bookid.BookResult, error) {
+				<-ctx.Done()
+				return nil, ctx.Err()
+			},
+		}}
+		f := aggregate.NewFinder(
+			provider("googlebooks", nil, bookid.BookResult{Title: "Dune", ISBN13: "9780441172719"}),
+			timesOut,
+			slow,
+		)
+		f.Timeout = 50 * time.Millisecond
+
+		results, err := f.Search(context.Background(), "dune", bookid.SearchOptions{})
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		assert.Equal(t, "Dune", results[0].Title)
+		assert.Equal(t, []string{"isbndb", "worldcat"}, results[0].TimedOut)
+	})
+
+	t.Run("all_timed_out", func(t *testing.T) {
+		t.Parallel()
+		f := aggregate.NewFinder(aggregate.Provider{Name: "worldcat", Finder: &mock.BookFinder{
+			SearchFn: func(ctx context.Context, _ string, _ bookid.SearchOptions) ([]bookid.BookResult, error) {
+				<-ctx.Done()
+				return nil, ctx.Err()
+			},
+		}})
+		f.Timeout = time.Millisecond
+
+		_, err := f.Search(context.Background(), "dune", bookid.SearchOptions{})
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+}
+
+func TestMerge(t *testing.T) {
+	t.Parallel()
+
+	// Provenance of an already merged result carries over.
+	r := bookid.BookResult{Title: "Dune", Provider: "googlebooks"}
+	other := bookid.BookResult{
+		Publisher:     "Ace",
+		PublishedYear: 1990,
+		Provider:      "openlibrary",
+		Provenance:    map[string]string{"published_year": "isbndb"},
+	}
+	aggregate.Merge(&r, &other, nil)
+	assert.Equal(t, map[string]string{"publisher": "openlibrary", "published_year": "isbndb"}, r.Provenance)
+	assert.Equal(t, map[string]string{"published_year": "isbndb"}, other.Provenance, "other is unchanged")
+}
+
+func TestMerge_Sale(t *testing.T) {
+	t.Parallel()
+
+	// Only Google Books tells sale and access, so they fill in for others.
+	r := bookid.BookResult{Title: "Dune", Provider: "isbndb"}
+	other := bookid.BookResult{
+		Sale:     &bookid.SaleInfo{Country: "US", Availability: bookid.AvailabilityForSale},
+		Access:   &bookid.AccessInfo{EPUBAvailable: true},
+		Provider: "googlebooks",
+	}
+	aggregate.Merge(&r, &other, nil)
+	assert.Equal(t, other.Sale, r.Sale)
+	assert.Equal(t, other.Access, r.Access)
+	assert.Equal(t, "googlebooks", r.FieldProvider("sale"))
+}
+
+func TestMerge_BISACCodes(t *testing.T) {
+	t.Parallel()
+
+	r := bookid.BookResult{Title: "Dune", Subjects: []string{"Science fiction"}, Provider: "openlibrary"}
+	other := bookid.BookResult{Subjects: []string{"Fiction"}, BISACCodes: []string{"FIC000000"}, Provider: "googlebooks"}
+	aggregate.Merge(&r, &other, nil)
+	assert.Equal(t, []string{"Science fiction"}, r.Subjects)
+	assert.Equal(t, []string{"FIC000000"}, r.BISACCodes)
+	assert.Equal(t, "googlebooks", r.FieldProvider("bisac_codes"))
+}
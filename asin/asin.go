@@ -0,0 +1,27 @@
+// Package asin normalizes Amazon Standard Identification Numbers, the
+// ten-character product codes of Amazon and Audible. Books with an ISBN-10
+// use it as their ASIN; other products, such as audiobooks, have codes
+// starting with "B0".
+package asin
+
+import "strings"
+
+// Normalize removes surrounding whitespace and uppercases s, as ASINs are
+// case-insensitive. It does not validate the result.
+func Normalize(s string) string {
+	return strings.ToUpper(strings.TrimSpace(s))
+}
+
+// Valid returns true if s is a normalized ASIN: ten uppercase ASCII letters
+// and digits.
+func Valid(s string) bool {
+	if len(s) != 10 {
+		return false
+	}
+	for _, r := range s {
+		if (r < '0' || r > '9') && (r < 'A' || r > 'Z') {
+			return false
+		}
+	}
+	return true
+}
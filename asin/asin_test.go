@@ -0,0 +1,24 @@
+package asin_test
+
+import (
+	"testing"
+
+	"github.com/fwojciec/bookid/asin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalize(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "B08G9PRS1K", asin.Normalize(" b08g9prs1k "))
+}
+
+func TestValid(t *testing.T) {
+	t.Parallel()
+
+	for _, in := range []string{"B08G9PRS1K", "0743273567", "B00K0OI42W"} {
+		assert.True(t, asin.Valid(in), in)
+	}
+	for _, in := range []string{"", "b08g9prs1k", "B08G9PRS1", "B08G9PRS1K1", "B08G9-RS1K"} {
+		assert.False(t, asin.Valid(in), in)
+	}
+}
@@ -0,0 +1,98 @@
+package bookid
+
+import (
+	"context"
+	"time"
+)
+
+// Entity types of audit entries.
+const (
+	AuditEntityWork        = "work"
+	AuditEntityAuthor      = "author"
+	AuditEntityPublication = "publication"
+)
+
+// Actions of audit entries.
+const (
+	AuditActionCreate  = "create"
+	AuditActionUpdate  = "update"
+	AuditActionDelete  = "delete"  // Moved to the trash
+	AuditActionRestore = "restore" // Restored from the trash
+	AuditActionPurge   = "purge"   // Permanently removed from the trash
+	AuditActionMerge   = "merge"   // A work merged into another
+	AuditActionLink    = "link"    // An author linked to a work
+	AuditActionUnlink  = "unlink"  // An author unlinked from a work
+)
+
+// AuditEntry records a single change to a work, author or publication of the
+// catalog, for reviewing the history of shared catalogs.
+type AuditEntry struct {
+	ID         int64  `json:"id"`
+	EntityType string `json:"entity_type"`
+	EntityID   int64  `json:"entity_id"`
+
+	// Work the change concerns: the work itself, the work of a publication,
+	// the work an author was linked to or unlinked from, or the work another
+	// was merged into. Zero for changes to authors alone.
+	WorkID int64 `json:"work_id,omitempty"`
+
+	Action string `json:"action"`
+	Actor  string `json:"actor,omitempty"` // Who made the change, if known
+
+	// Changed fields by JSON name. Created entities have only new values and
+	// deleted ones only old values.
+	Diff map[string]AuditChange `json:"diff"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// AuditChange represents the change of a single field.
+type AuditChange struct {
+	Old any `json:"old,omitempty"`
+	New any `json:"new,omitempty"`
+}
+
+// AuditService represents a service for reviewing the audit log. Entries are
+// recorded by the catalog services as part of each change.
+type AuditService interface {
+	// FindAuditEntries retrieves audit entries matching the filter, oldest
+	// first, along with the total number of matches, ignoring Offset and
+	// Limit.
+	FindAuditEntries(ctx context.Context, filter AuditFilter) ([]*AuditEntry, int, error)
+}
+
+// AuditFilter represents a filter used by FindAuditEntries.
+type AuditFilter struct {
+	EntityType *string
+	EntityID   *int64
+
+	// WorkID matches the entries concerning a work, including changes to its
+	// publications and author links.
+	WorkID *int64
+
+	// Restrict to subset of results.
+	Offset int
+	Limit  int
+}
+
+// contextKey represents an internal key for adding context fields.
+type contextKey int
+
+// List of context keys.
+const (
+	// Stores the actor making changes to the catalog.
+	actorContextKey = contextKey(iota + 1)
+)
+
+// NewContextWithActor returns a new context with the given actor, which is
+// recorded in the audit entries of changes made with it.
+func NewContextWithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorContextKey, actor)
+}
+
+// ActorFromContext returns the actor of the context, or an empty string if
+// none is set.
+func ActorFromContext(ctx context.Context) string {
+	actor, _ := ctx.Value(actorContextKey).(string)
+	return actor
+}
@@ -0,0 +1,214 @@
+// Package audnexus implements the BookFinder interface on top of the Audnexus
+// API, which serves the metadata of Audible audiobooks. Audiobooks are looked
+// up by ASIN only; other queries find nothing, as the API cannot search books.
+package audnexus
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/description"
+	"github.com/fwojciec/bookid/internal/httpclient"
+	"github.com/fwojciec/bookid/isbn"
+	"github.com/fwojciec/bookid/language"
+	bookidquery "github.com/fwojciec/bookid/query"
+	"github.com/fwojciec/bookid/scoring"
+)
+
+// ProviderName identifies results produced by this package.
+const ProviderName = "audnexus"
+
+// DefaultBaseURL is the root of the public Audnexus API.
+const DefaultBaseURL = "https://api.audnex.us"
+
+// DefaultRegion is the Audible marketplace books are looked up in.
+const DefaultRegion = "us"
+
+// Client implements the BookFinder interface for the Audnexus API.
+type Client struct {
+	httpClient *http.Client
+	baseURL    string
+
+	// Audible marketplace to look books up in, e.g. "us" or "uk". ASINs
+	// of audiobooks differ between marketplaces.
+	Region string
+
+	// Computes the confidence of each result.
+	Scorer *scoring.Scorer
+}
+
+// Register the provider so it can be enabled by name. The URL of the
+// provider's profile points it at another Audnexus instance.
+func init() {
+	bookid.RegisterFinder(ProviderName, func(config bookid.ProviderConfig) (bookid.BookFinder, error) {
+		return NewClientWithBaseURL(config.Client(), cmp.Or(config.URL, DefaultBaseURL)), nil
+	})
+}
+
+// NewClient creates a new Audnexus API client. The API does not require an
+// API key.
+func NewClient() *Client {
+	return NewClientWithBaseURL(http.DefaultClient, DefaultBaseURL)
+}
+
+// NewClientWithBaseURL creates a new client against a custom endpoint (for testing)
+func NewClientWithBaseURL(httpClient *http.Client, baseURL string) *Client {
+	return &Client{
+		httpClient: httpClient,
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		Region:     DefaultRegion,
+		Scorer:     scoring.Default(),
+	}
+}
+
+// Search looks up the audiobook identified by the first ASIN of the query.
+// Queries without an ASIN find nothing. Options other than IncludeRaw and
+// the result filters are ignored.
+func (c *Client) Search(ctx context.Context, query string, opts bookid.SearchOptions) ([]bookid.BookResult, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, errors.New("query cannot be empty")
+	} else if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	parsed := bookidquery.Parse(query)
+	if len(parsed.ASINs) == 0 {
+		return []bookid.BookResult{}, nil
+	}
+	results, err := c.searchASIN(ctx, parsed.ASINs[0])
+	if err != nil {
+		return nil, err
+	}
+	for i := range results {
+		results[i].Confidence = c.Scorer.Score(scoring.Input{Query: query, Options: opts, Result: results[i]})
+	}
+	return opts.Apply(results), nil
+}
+
+// searchASIN looks up a single audiobook by ASIN.
+func (c *Client) searchASIN(ctx context.Context, code string) ([]bookid.BookResult, error) {
+	var raw json.RawMessage
+	if err := c.get(ctx, "/books/"+url.PathEscape(code), url.Values{"region": {c.Region}}, &raw); err != nil {
+		if httpclient.IsStatus(err, http.StatusNotFound, http.StatusBadRequest) {
+			return []bookid.BookResult{}, nil
+		}
+		return nil, err
+	}
+
+	var b book
+	if err := json.Unmarshal(raw, &b); err != nil {
+		return nil, fmt.Errorf("decoding audnexus book: %w", err)
+	}
+	result := b.toBookResult()
+	result.ProviderData = raw
+	return []bookid.BookResult{result}, nil
+}
+
+// get performs a GET request against the API and decodes the JSON response into v.
+func (c *Client) get(ctx context.Context, path string, params url.Values, v any) error {
+	return httpclient.GetJSON(ctx, c.httpClient, "Audnexus", c.baseURL+path+"?"+params.Encode(), nil, v)
+}
+
+// book is an audiobook as returned by the books endpoint.
+type book struct {
+	ASIN          string   `json:"asin"`
+	Title         string   `json:"title"`
+	Subtitle      string   `json:"subtitle"`
+	Authors       []person `json:"authors"`
+	Narrators     []person `json:"narrators"`
+	PublisherName string   `json:"publisherName"`
+	ReleaseDate   string   `json:"releaseDate"` // e.g. "2021-05-04T00:00:00.000Z"
+	Language      string   `json:"language"`    // English name, e.g. "english"
+	ISBN          string   `json:"isbn"`
+	Image         string   `json:"image"`
+	Summary       string   `json:"summary"`    // HTML
+	FormatType    string   `json:"formatType"` // "abridged" or "unabridged"
+	RuntimeLength int      `json:"runtimeLengthMin"`
+	Genres        []struct {
+		Name string `json:"name"`
+		Type string `json:"type"` // "genre" or "tag"
+	} `json:"genres"`
+	SeriesPrimary *struct {
+		Name     string `json:"name"`
+		Position string `json:"position"`
+	} `json:"seriesPrimary"`
+}
+
+// person is an author or narrator of an audiobook.
+type person struct {
+	Name string `json:"name"`
+}
+
+// toBookResult converts a book to our BookResult. Narrators are credited as
+// contributors of the work.
+func (b *book) toBookResult() bookid.BookResult {
+	result := bookid.BookResult{
+		Title:           strings.TrimSpace(b.Title),
+		Authors:         []string{},
+		ASIN:            b.ASIN,
+		Publisher:       b.PublisherName,
+		PublishedYear:   releaseYear(b.ReleaseDate),
+		Language:        language.FromName(b.Language),
+		Binding:         bookid.BindingAudiobook,
+		DurationMinutes: b.RuntimeLength,
+		ThumbnailURL:    b.Image,
+		Description:     description.Clean(b.Summary),
+		Provider:        ProviderName,
+		SearchType:      bookid.SearchTypeASIN,
+	}
+	if b.Subtitle != "" {
+		result.Title += ": " + strings.TrimSpace(b.Subtitle)
+	}
+	for _, a := range b.Authors {
+		if name := strings.TrimSpace(a.Name); name != "" {
+			result.Authors = append(result.Authors, name)
+		}
+	}
+	for _, n := range b.Narrators {
+		if name := strings.TrimSpace(n.Name); name != "" {
+			result.Contributors = append(result.Contributors, bookid.Contributor{Name: name, Role: bookid.ContributorRoleNarrator})
+		}
+	}
+
+	switch code := isbn.Normalize(b.ISBN); {
+	case isbn.Valid13(code):
+		result.ISBN13 = code
+	case isbn.Valid10(code):
+		result.ISBN10 = code
+	}
+	for _, g := range b.Genres {
+		if g.Type == "genre" {
+			result.Subjects = append(result.Subjects, g.Name)
+		}
+	}
+	if s := b.SeriesPrimary; s != nil {
+		result.Series = s.Name
+		result.SeriesVolume, _ = strconv.ParseFloat(s.Position, 64)
+	}
+	if b.FormatType != "" {
+		result.Metadata = map[string]string{"format_type": b.FormatType}
+	}
+	return result
+}
+
+// releaseYear returns the year of a release date such as
+// "2021-05-04T00:00:00.000Z", or 0 if it has none.
+func releaseYear(date string) int {
+	if len(date) < 4 {
+		return 0
+	}
+	year, err := strconv.Atoi(date[:4])
+	if err != nil {
+		return 0
+	}
+	return year
+}
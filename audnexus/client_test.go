@@ -0,0 +1,95 @@
+package audnexus_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/audnexus"
+	"github.com/fwojciec/bookid/internal/httptestutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newClient returns a client replaying the given synthetic fixture of
+// Audnexus responses, written by hand rather than recorded.
+func newClient(t *testing.T, fixture string) *audnexus.Client {
+	t.Helper()
+	return audnexus.NewClientWithBaseURL(httptestutil.Replay(t, filepath.Join("testdata", "synthetic", fixture)), audnexus.DefaultBaseURL)
+}
+
+func TestClient_Search(t *testing.T) {
+	t.Parallel()
+
+	t.Run("asin", func(t *testing.T) {
+		t.Parallel()
+		client := newClient(t, "book_B08G9PRS1K.json")
+
+		results, err := client.Search(context.Background(), "asin:b08g9prs1k", bookid.SearchOptions{IncludeRaw: true})
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+
+		r := results[0]
+		assert.Equal(t, "Project Hail Mary", r.Title)
+		assert.Equal(t, []string{"Andy Weir"}, r.Authors)
+		assert.Equal(t, []bookid.Contributor{{Name: "Ray Porter", Role: bookid.ContributorRoleNarrator}}, r.Contributors)
+		assert.Equal(t, "B08G9PRS1K", r.ASIN)
+		assert.Equal(t, "9781603935470", r.ISBN13)
+		assert.Equal(t, "Audible Studios", r.Publisher)
+		assert.Equal(t, 2021, r.PublishedYear)
+		assert.Equal(t, "en", r.Language)
+		assert.Equal(t, bookid.BindingAudiobook, r.Binding)
+		assert.Equal(t, 970, r.DurationMinutes)
+		assert.Equal(t, "Winner of the 2022 Audie Awards' Audiobook of the Year", r.Description)
+		assert.Equal(t, []string{"Science Fiction & Fantasy"}, r.Subjects)
+		assert.Equal(t, "unabridged", r.Metadata["format_type"])
+		assert.Equal(t, audnexus.ProviderName, r.Provider)
+		assert.Equal(t, bookid.SearchTypeASIN, r.SearchType)
+		assert.InDelta(t, 0.95, r.Confidence, 0.01)
+		assert.NotEmpty(t, r.ProviderData)
+	})
+
+	t.Run("region", func(t *testing.T) {
+		t.Parallel()
+		// The fixture only answers requests for the UK store.
+		client := newClient(t, "book_B08G9PRS1K_uk.json")
+		client.Region = "uk"
+
+		results, err := client.Search(context.Background(), "B08G9PRS1K", bookid.SearchOptions{})
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+	})
+
+	t.Run("not_asin", func(t *testing.T) {
+		t.Parallel()
+		client := audnexus.NewClientWithBaseURL(http.DefaultClient, "http://127.0.0.1:0")
+
+		results, err := client.Search(context.Background(), "project hail mary", bookid.SearchOptions{})
+		require.NoError(t, err)
+		assert.Empty(t, results)
+	})
+
+	t.Run("not_found", func(t *testing.T) {
+		t.Parallel()
+		client := newClient(t, "book_not_found.json")
+
+		results, err := client.Search(context.Background(), "B000000000", bookid.SearchOptions{})
+		require.NoError(t, err)
+		assert.Empty(t, results)
+	})
+
+	t.Run("unavailable", func(t *testing.T) {
+		t.Parallel()
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		t.Cleanup(srv.Close)
+		client := audnexus.NewClientWithBaseURL(srv.Client(), srv.URL)
+
+		_, err := client.Search(context.Background(), "B08G9PRS1K", bookid.SearchOptions{})
+		assert.Equal(t, bookid.EUNAVAILABLE, bookid.ErrorCode(err))
+	})
+}
@@ -0,0 +1,82 @@
+// Package authority resolves catalog authors against name authority files,
+// which assign stable identifiers to people, so that authors sharing a name
+// such as "John Smith" can be told apart.
+package authority
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/match"
+)
+
+// Linker links catalog authors to their authority records.
+type Linker struct {
+	AuthorService   bookid.AuthorService
+	AuthorityFinder bookid.AuthorityFinder
+}
+
+// Link looks up an author in the authority file and returns the records whose
+// headings match the author's name. If exactly one record matches, the author
+// is linked to it; otherwise the author is returned unchanged so the caller
+// can choose among the candidates. Returns ENOTFOUND if the author does not
+// exist.
+func (l *Linker) Link(ctx context.Context, authorID int64) (*bookid.Author, []*bookid.AuthorityRecord, error) {
+	author, err := l.AuthorService.FindAuthorByID(ctx, authorID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	records, err := l.AuthorityFinder.FindAuthorities(ctx, author.Name)
+	if err != nil {
+		return nil, nil, err
+	}
+	candidates := make([]*bookid.AuthorityRecord, 0, len(records))
+	for _, r := range records {
+		if NamesMatch(r.Name, author.Name) {
+			candidates = append(candidates, r)
+		}
+	}
+
+	if len(candidates) == 1 {
+		if author, err = l.AuthorService.UpdateAuthor(ctx, authorID, bookid.AuthorUpdate{
+			VIAFID:     &candidates[0].VIAFID,
+			WikidataID: &candidates[0].WikidataID,
+		}); err != nil {
+			return nil, nil, err
+		}
+	}
+	return author, candidates, nil
+}
+
+// NamesMatch reports whether an authority heading such as "Lem, Stanisław,
+// 1921-2006" names the author name, e.g. "Stanislaw Lem". Every word of the
+// name must appear in the heading, regardless of order, case and diacritics;
+// dates in the heading are ignored.
+func NamesMatch(heading, name string) bool {
+	words := nameWords(heading)
+	want := nameWords(name)
+	if len(want) == 0 {
+		return false
+	}
+	for _, w := range want {
+		if !slices.Contains(words, w) {
+			return false
+		}
+	}
+	return true
+}
+
+// nameWords returns the normalized words of a name without dates.
+func nameWords(name string) []string {
+	var words []string
+	for _, w := range strings.Fields(match.Normalize(name)) {
+		if !strings.ContainsFunc(w, unicode.IsDigit) {
+			words = append(words, w)
+		}
+	}
+	return words
+}
@@ -0,0 +1,92 @@
+package authority_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/authority"
+	"github.com/fwojciec/bookid/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// finderFunc adapts a function to the AuthorityFinder interface.
+type finderFunc func(ctx context.Context, name string) ([]*bookid.AuthorityRecord, error)
+
+func (f finderFunc) FindAuthorities(ctx context.Context, name string) ([]*bookid.AuthorityRecord, error) {
+	return f(ctx, name)
+}
+
+func TestLinker_Link(t *testing.T) {
+	t.Parallel()
+
+	records := finderFunc(func(context.Context, string) ([]*bookid.AuthorityRecord, error) {
+		return []*bookid.AuthorityRecord{
+			{VIAFID: "49222207", WikidataID: "Q44245", Name: "Lem, Stanisław, 1921-2006"},
+			{VIAFID: "56614011", WikidataID: "Q228024", Name: "Smith, John, 1580-1631"},
+			{VIAFID: "45102577", Name: "Smith, John, 1938-1994"},
+		}, nil
+	})
+
+	t.Run("single match", func(t *testing.T) {
+		t.Parallel()
+		authors := newAuthorService(t)
+		lem := &bookid.Author{Name: "Stanislaw Lem"}
+		require.NoError(t, authors.CreateAuthor(context.Background(), lem))
+
+		linker := &authority.Linker{AuthorService: authors, AuthorityFinder: records}
+		author, candidates, err := linker.Link(context.Background(), lem.ID)
+		require.NoError(t, err)
+		assert.Len(t, candidates, 1)
+		assert.Equal(t, "49222207", author.VIAFID)
+		assert.Equal(t, "Q44245", author.WikidataID)
+	})
+
+	t.Run("ambiguous", func(t *testing.T) {
+		t.Parallel()
+		authors := newAuthorService(t)
+		smith := &bookid.Author{Name: "John Smith"}
+		require.NoError(t, authors.CreateAuthor(context.Background(), smith))
+
+		linker := &authority.Linker{AuthorService: authors, AuthorityFinder: records}
+		author, candidates, err := linker.Link(context.Background(), smith.ID)
+		require.NoError(t, err)
+		assert.Len(t, candidates, 2)
+		assert.Empty(t, author.VIAFID, "namesakes are left for the caller to choose")
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		t.Parallel()
+		linker := &authority.Linker{AuthorService: newAuthorService(t), AuthorityFinder: records}
+		_, _, err := linker.Link(context.Background(), 1)
+		assert.Equal(t, bookid.ENOTFOUND, bookid.ErrorCode(err))
+	})
+}
+
+func TestNamesMatch(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		heading, name string
+		want          bool
+	}{
+		{"Lem, Stanisław, 1921-2006", "Stanislaw Lem", true},
+		{"Lem, Stanisław, 1921-2006", "LEM, Stanisław", true},
+		{"Smith, John C.", "John Smith", true},
+		{"Smith, John, 1938-1994", "John C. Smith", false},
+		{"Lem, Stanisław, 1921-2006", "Michał Lem", false},
+		{"Lem, Stanisław", "1921", false},
+	} {
+		assert.Equal(t, tt.want, authority.NamesMatch(tt.heading, tt.name), "%q, %q", tt.heading, tt.name)
+	}
+}
+
+// newAuthorService returns an author service backed by a new database.
+func newAuthorService(t *testing.T) *sqlite.AuthorService {
+	t.Helper()
+	db := sqlite.NewDB(":memory:")
+	require.NoError(t, db.Open())
+	t.Cleanup(func() { _ = db.Close() })
+	return sqlite.NewAuthorService(db)
+}
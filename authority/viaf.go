@@ -0,0 +1,99 @@
+package authority
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/internal/httpclient"
+)
+
+// ProviderName identifies records found by this package.
+const ProviderName = "viaf"
+
+// DefaultBaseURL is the root of the VIAF API.
+const DefaultBaseURL = "https://viaf.org/viaf"
+
+// Ensure client implements interface.
+var _ bookid.AuthorityFinder = (*Client)(nil)
+
+// Client implements the AuthorityFinder interface for the Virtual
+// International Authority File, which clusters the name authority records of
+// national libraries and links them to Wikidata.
+type Client struct {
+	httpClient *http.Client
+	baseURL    string
+}
+
+// NewClient creates a new VIAF API client. VIAF does not require an API key.
+func NewClient() *Client {
+	return NewClientWithBaseURL(http.DefaultClient, DefaultBaseURL)
+}
+
+// NewClientWithBaseURL creates a new client against a custom endpoint (for testing)
+func NewClientWithBaseURL(httpClient *http.Client, baseURL string) *Client {
+	return &Client{
+		httpClient: httpClient,
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+	}
+}
+
+// FindAuthorities returns the personal name records suggested by VIAF for
+// name, in the order VIAF ranks them. Corporate, geographic and work records
+// are skipped.
+func (c *Client) FindAuthorities(ctx context.Context, name string) ([]*bookid.AuthorityRecord, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, errors.New("name cannot be empty")
+	}
+
+	var resp struct {
+		Result []suggestion `json:"result"` // Null if nothing matches
+	}
+	if err := c.get(ctx, "/AutoSuggest", url.Values{"query": {name}}, &resp); err != nil {
+		return nil, err
+	}
+
+	records := make([]*bookid.AuthorityRecord, 0, len(resp.Result))
+	seen := make(map[string]bool)
+	for _, s := range resp.Result {
+		// A cluster is suggested once per matching heading.
+		if s.NameType != "personal" || s.VIAFID == "" || seen[s.VIAFID] {
+			continue
+		}
+		seen[s.VIAFID] = true
+		records = append(records, s.toRecord())
+	}
+	return records, nil
+}
+
+// get fetches path with the given parameters and decodes the JSON response
+// into v.
+func (c *Client) get(ctx context.Context, path string, params url.Values, v any) error {
+	return httpclient.GetJSON(ctx, c.httpClient, "VIAF", c.baseURL+path+"?"+params.Encode(), nil, v)
+}
+
+// suggestion is a single heading suggested by the AutoSuggest API.
+type suggestion struct {
+	Term        string `json:"term"`        // Lowercased heading
+	DisplayForm string `json:"displayForm"` // Heading as cataloged
+	NameType    string `json:"nametype"`
+	VIAFID      string `json:"viafid"`
+	Wikidata    string `json:"wkp"` // Wikidata item, if VIAF links one
+}
+
+// toRecord converts a suggestion to our AuthorityRecord.
+func (s *suggestion) toRecord() *bookid.AuthorityRecord {
+	name := s.DisplayForm
+	if name == "" {
+		name = s.Term
+	}
+	return &bookid.AuthorityRecord{
+		VIAFID:     s.VIAFID,
+		WikidataID: strings.ToUpper(s.Wikidata),
+		Name:       strings.TrimSpace(name),
+	}
+}
@@ -0,0 +1,84 @@
+package authority_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/authority"
+	"github.com/fwojciec/bookid/internal/httptestutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newClient returns a client replaying the given synthetic fixture of VIAF
+// AutoSuggest responses, written by hand rather than recorded. The URL of the
+// last request is stored in lastURL, if set.
+func newClient(t *testing.T, fixture string, lastURL *string) *authority.Client {
+	t.Helper()
+	httpClient := httptestutil.Replay(t, filepath.Join("testdata", "synthetic", fixture))
+	if lastURL != nil {
+		httpClient = httptestutil.LastURL(httpClient, lastURL)
+	}
+	return authority.NewClientWithBaseURL(httpClient, authority.DefaultBaseURL)
+}
+
+func TestClient_FindAuthorities(t *testing.T) {
+	t.Parallel()
+
+	t.Run("personal names", func(t *testing.T) {
+		t.Parallel()
+		var lastURL string
+		client := newClient(t, "autosuggest_john_smith.json", &lastURL)
+
+		records, err := client.FindAuthorities(context.Background(), " John Smith ")
+		require.NoError(t, err)
+		assert.Equal(t, authority.DefaultBaseURL+"/AutoSuggest?query=John+Smith", lastURL)
+
+		// Duplicate headings of a cluster and corporate names are skipped.
+		assert.Equal(t, []*bookid.AuthorityRecord{
+			{VIAFID: "56614011", WikidataID: "Q228024", Name: "Smith, John, 1580-1631"},
+			{VIAFID: "45102577", WikidataID: "Q335865", Name: "Smith, John, 1938-1994"},
+			{VIAFID: "9847119", Name: "Smith, John C."},
+		}, records)
+	})
+
+	t.Run("no matches", func(t *testing.T) {
+		t.Parallel()
+		client := newClient(t, "autosuggest_zzxq.json", nil)
+
+		records, err := client.FindAuthorities(context.Background(), "zzxq")
+		require.NoError(t, err)
+		assert.Empty(t, records)
+	})
+
+	t.Run("empty name", func(t *testing.T) {
+		t.Parallel()
+		client := authority.NewClientWithBaseURL(http.DefaultClient, "http://invalid")
+		_, err := client.FindAuthorities(context.Background(), " ")
+		require.Error(t, err)
+	})
+}
+
+func TestFormatError(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		status int
+		code   string
+	}{
+		{http.StatusTooManyRequests, bookid.ERATELIMIT},
+		{http.StatusServiceUnavailable, bookid.EUNAVAILABLE},
+	} {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(tt.status)
+		}))
+		client := authority.NewClientWithBaseURL(srv.Client(), srv.URL)
+		_, err := client.FindAuthorities(context.Background(), "John Smith")
+		srv.Close()
+		assert.Equal(t, tt.code, bookid.ErrorCode(err), "status %d", tt.status)
+	}
+}
@@ -0,0 +1,304 @@
+// Package barcode reads EAN-13 barcodes, such as the ISBN barcodes printed on
+// book covers, from photos.
+//
+// Images are read along evenly spaced horizontal and vertical scan lines in
+// both directions, so barcodes may be photographed sideways or upside down.
+// Each line is binarized against its local brightness to tolerate uneven
+// lighting, and the code read from the most lines wins.
+package barcode
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"strings"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/isbn"
+)
+
+// scanLines is the number of lines read across each dimension of an image.
+const scanLines = 64
+
+// minContrast is the smallest difference in luminance between the darkest
+// and lightest pixels of a scan line that may hold a barcode.
+const minContrast = 32
+
+// maxVariance is the largest average difference, in modules, between the bar
+// and space widths of a digit and its pattern for the digit to be accepted.
+const maxVariance = 0.45
+
+// Decode returns the EAN-13 code of the barcode in img. Returns ENOTFOUND if
+// no barcode can be read.
+func Decode(img image.Image) (string, error) {
+	votes := make(map[string]int)
+	var best string
+	for _, line := range lines(img) {
+		runs := runLengths(line)
+		for _, rs := range [][]float64{runs, reversed(runs)} {
+			code, ok := decodeRuns(rs)
+			if !ok {
+				continue
+			}
+			votes[code]++
+			if votes[code] > votes[best] {
+				best = code
+			}
+		}
+	}
+	if best == "" {
+		return "", bookid.Errorf(bookid.ENOTFOUND, "No barcode found.")
+	}
+	return best, nil
+}
+
+// DecodeISBN returns the ISBN-13 encoded by the barcode in img. Returns
+// ENOTFOUND if no barcode can be read and EINVALID if the barcode is not an
+// ISBN, such as the ISSN barcode of a magazine.
+func DecodeISBN(img image.Image) (string, error) {
+	code, err := Decode(img)
+	if err != nil {
+		return "", err
+	} else if !isbn.Valid13(code) {
+		return "", bookid.Errorf(bookid.EINVALID, "Barcode %s is not an ISBN.", code)
+	}
+	return code, nil
+}
+
+// lines returns the luminance of the pixels along the horizontal and vertical
+// scan lines of img.
+func lines(img image.Image) [][]float64 {
+	b := img.Bounds()
+	var out [][]float64
+	for i, n := 1, min(scanLines, b.Dy()); i <= n; i++ {
+		y := b.Min.Y + b.Dy()*i/(n+1)
+		line := make([]float64, 0, b.Dx())
+		for x := b.Min.X; x < b.Max.X; x++ {
+			line = append(line, luminance(img.At(x, y)))
+		}
+		out = append(out, line)
+	}
+	for i, n := 1, min(scanLines, b.Dx()); i <= n; i++ {
+		x := b.Min.X + b.Dx()*i/(n+1)
+		line := make([]float64, 0, b.Dy())
+		for y := b.Min.Y; y < b.Max.Y; y++ {
+			line = append(line, luminance(img.At(x, y)))
+		}
+		out = append(out, line)
+	}
+	return out
+}
+
+// luminance returns the brightness of c from 0 (black) to 255 (white).
+func luminance(c color.Color) float64 {
+	return float64(color.GrayModel.Convert(c).(color.Gray).Y)
+}
+
+// runLengths binarizes a scan line and returns the widths of its alternating
+// light and dark runs, starting with a light one. A pixel is dark if it is
+// darker than the average of its neighborhood, which spans several of the
+// widest bars. Flat neighborhoods, such as the margins around a barcode, are
+// compared with the midpoint of the whole line instead so that noise does not
+// split them into runs.
+func runLengths(line []float64) []float64 {
+	if len(line) == 0 {
+		return nil
+	}
+
+	lo, hi := line[0], line[0]
+	sums := make([]float64, len(line)+1)
+	squares := make([]float64, len(line)+1)
+	for i, v := range line {
+		lo, hi = min(lo, v), max(hi, v)
+		sums[i+1] = sums[i] + v
+		squares[i+1] = squares[i] + v*v
+	}
+	if hi-lo < minContrast {
+		return nil
+	}
+	radius := max(8, len(line)/16)
+
+	runs := []float64{0}
+	dark := false
+	for i, v := range line {
+		from, to := max(0, i-radius), min(len(line), i+radius+1)
+		n := float64(to - from)
+		mean := (sums[to] - sums[from]) / n
+		stddev := math.Sqrt(max(0, (squares[to]-squares[from])/n-mean*mean))
+		threshold := mean
+		if stddev < (hi-lo)/8 {
+			threshold = (lo + hi) / 2
+		}
+		if isDark := v < threshold; isDark != dark {
+			runs = append(runs, 0)
+			dark = isDark
+		}
+		runs[len(runs)-1]++
+	}
+	return runs
+}
+
+// reversed returns the runs of a line read backwards, starting with a light
+// run.
+func reversed(runs []float64) []float64 {
+	out := make([]float64, 0, len(runs)+1)
+	if len(runs)%2 == 0 {
+		out = append(out, 0) // The line ended with a dark run.
+	}
+	for i := len(runs) - 1; i >= 0; i-- {
+		out = append(out, runs[i])
+	}
+	return out
+}
+
+// decodeRuns returns the first EAN-13 code with a valid check digit found in
+// the runs of a scan line.
+func decodeRuns(runs []float64) (string, bool) {
+	// A barcode is 59 runs: start guard, six digits, middle guard, six
+	// digits and end guard. Runs alternate starting with a light one, so
+	// bars are at odd indexes.
+	for start := 1; start+59 <= len(runs); start += 2 {
+		if code, ok := decodeAt(runs, start); ok {
+			return code, true
+		}
+	}
+	return "", false
+}
+
+// decodeAt decodes the barcode whose start guard is the bar at runs[start].
+func decodeAt(runs []float64, start int) (string, bool) {
+	module, ok := guard(runs[start : start+3])
+	if !ok || runs[start-1] < 3*module { // Quiet zone
+		return "", false
+	}
+
+	var b strings.Builder
+	var parity []bool
+	pos := start + 3
+	for range 6 {
+		digit, even, ok := decodeDigit(runs[pos:pos+4], true)
+		if !ok {
+			return "", false
+		}
+		b.WriteByte('0' + byte(digit))
+		parity = append(parity, even)
+		pos += 4
+	}
+	if _, ok := guard(runs[pos : pos+5]); !ok {
+		return "", false
+	}
+	pos += 5
+	for range 6 {
+		digit, _, ok := decodeDigit(runs[pos:pos+4], false)
+		if !ok {
+			return "", false
+		}
+		b.WriteByte('0' + byte(digit))
+		pos += 4
+	}
+	if _, ok := guard(runs[pos : pos+3]); !ok {
+		return "", false
+	}
+
+	// The first digit is encoded in the parity of the left half.
+	first := -1
+	for d, p := range parityPatterns() {
+		if p == parityString(parity) {
+			first = d
+		}
+	}
+	if first < 0 {
+		return "", false
+	}
+	code := string(rune('0'+first)) + b.String()
+	return code, checkDigit(code)
+}
+
+// guard reports whether runs are a guard pattern of one-module bars and
+// spaces and returns the module width.
+func guard(runs []float64) (float64, bool) {
+	var total float64
+	for _, r := range runs {
+		total += r
+	}
+	module := total / float64(len(runs))
+	for _, r := range runs {
+		if math.Abs(r-module) > module*0.5+0.5 {
+			return 0, false
+		}
+	}
+	return module, true
+}
+
+// decodeDigit returns the digit whose pattern best matches its four runs.
+// In the left half digits have odd (L) or even (G) parity; in the right half
+// they are always odd.
+func decodeDigit(runs []float64, left bool) (digit int, even, ok bool) {
+	var total float64
+	for _, r := range runs {
+		total += r
+	}
+
+	best := math.Inf(1)
+	for d, widths := range digitWidths() {
+		candidates := [][4]int{widths}
+		if left {
+			candidates = append(candidates, [4]int{widths[3], widths[2], widths[1], widths[0]})
+		}
+		for i, w := range candidates {
+			var variance float64
+			for j, r := range runs {
+				variance += math.Abs(r*7/total - float64(w[j]))
+			}
+			if variance < best {
+				best, digit, even = variance, d, i == 1
+			}
+		}
+	}
+	return digit, even, best/4 <= maxVariance
+}
+
+// digitWidths returns the widths, in modules, of the four runs of each digit
+// in odd parity, which start with a space in the left half and with a bar in
+// the right half. Even-parity digits are their mirror image.
+func digitWidths() [10][4]int {
+	return [10][4]int{
+		{3, 2, 1, 1}, {2, 2, 2, 1}, {2, 1, 2, 2}, {1, 4, 1, 1}, {1, 1, 3, 2},
+		{1, 2, 3, 1}, {1, 1, 1, 4}, {1, 3, 1, 2}, {1, 2, 1, 3}, {3, 1, 1, 2},
+	}
+}
+
+// parityPatterns returns the parity of the left-half digits for each first
+// digit, with "G" for even parity.
+func parityPatterns() [10]string {
+	return [10]string{
+		"LLLLLL", "LLGLGG", "LLGGLG", "LLGGGL", "LGLLGG",
+		"LGGLLG", "LGGGLG", "LGLGLG", "LGLGGL", "LGGLGL",
+	}
+}
+
+// parityString formats the parity of decoded digits like parityPatterns.
+func parityString(even []bool) string {
+	var b strings.Builder
+	for _, e := range even {
+		if e {
+			b.WriteByte('G')
+		} else {
+			b.WriteByte('L')
+		}
+	}
+	return b.String()
+}
+
+// checkDigit reports whether the last digit of a 13-digit code is correct.
+func checkDigit(code string) bool {
+	sum := 0
+	for i := range 12 {
+		d := int(code[i] - '0')
+		if i%2 == 1 {
+			d *= 3
+		}
+		sum += d
+	}
+	return int(code[12]-'0') == (10-sum%10)%10
+}
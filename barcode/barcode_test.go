@@ -0,0 +1,147 @@
+package barcode_test
+
+import (
+	"image"
+	"image/color"
+	"math/rand/v2"
+	"strings"
+	"testing"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/barcode"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/image/draw"
+)
+
+// ean13Modules returns the bars of an EAN-13 code as '1' (bar) and '0'
+// (space) modules, without quiet zones.
+func ean13Modules(code string) string {
+	l := []string{"0001101", "0011001", "0010011", "0111101", "0100011", "0110001", "0101111", "0111011", "0110111", "0001011"}
+	g := []string{"0100111", "0110011", "0011011", "0100001", "0011101", "0111001", "0000101", "0010001", "0001001", "0010111"}
+	r := []string{"1110010", "1100110", "1101100", "1000010", "1011100", "1001110", "1010000", "1000100", "1001000", "1110100"}
+	parity := []string{"LLLLLL", "LLGLGG", "LLGGLG", "LLGGGL", "LGLLGG", "LGGLLG", "LGGGLG", "LGLGLG", "LGLGGL", "LGGLGL"}
+
+	var b strings.Builder
+	b.WriteString("101")
+	for i, c := range code[1:7] {
+		if parity[code[0]-'0'][i] == 'G' {
+			b.WriteString(g[c-'0'])
+		} else {
+			b.WriteString(l[c-'0'])
+		}
+	}
+	b.WriteString("01010")
+	for _, c := range code[7:] {
+		b.WriteString(r[c-'0'])
+	}
+	b.WriteString("101")
+	return b.String()
+}
+
+// ean13Image draws the barcode of code with bars of the given width in
+// pixels and a ten-module quiet zone on white.
+func ean13Image(code string, module int) *image.Gray {
+	modules := strings.Repeat("0", 10) + ean13Modules(code) + strings.Repeat("0", 10)
+	img := image.NewGray(image.Rect(0, 0, len(modules)*module, 60*module))
+	for x := range img.Bounds().Dx() {
+		shade := uint8(0xff)
+		if modules[x/module] == '1' {
+			shade = 0
+		}
+		for y := range img.Bounds().Dy() {
+			img.SetGray(x, y, color.Gray{Y: shade})
+		}
+	}
+	return img
+}
+
+// photo places img in the middle of a larger image with uneven lighting and
+// noise, scaled by factor.
+func photo(img image.Image, factor float64) *image.Gray {
+	b := img.Bounds()
+	w, h := int(float64(b.Dx())*factor), int(float64(b.Dy())*factor)
+	out := image.NewGray(image.Rect(0, 0, 2*w, 3*h))
+	draw.Draw(out, out.Bounds(), image.NewUniform(color.Gray{Y: 0xc0}), image.Point{}, draw.Src)
+	draw.CatmullRom.Scale(out, image.Rect(w/2, h, w/2+w, 2*h), img, b, draw.Src, nil)
+
+	rnd := rand.New(rand.NewPCG(1, 2))
+	for y := range out.Bounds().Dy() {
+		for x := range out.Bounds().Dx() {
+			// Darken towards the right and add some sensor noise.
+			v := float64(out.GrayAt(x, y).Y)*(1-0.4*float64(x)/float64(2*w)) + rnd.NormFloat64()*6
+			out.SetGray(x, y, color.Gray{Y: uint8(max(0, min(255, v)))})
+		}
+	}
+	return out
+}
+
+// rotate returns img rotated clockwise by a quarter turn turns times.
+func rotate(img *image.Gray, turns int) *image.Gray {
+	for range turns {
+		b := img.Bounds()
+		out := image.NewGray(image.Rect(0, 0, b.Dy(), b.Dx()))
+		for y := range b.Dy() {
+			for x := range b.Dx() {
+				out.SetGray(b.Dy()-1-y, x, img.GrayAt(x, y))
+			}
+		}
+		img = out
+	}
+	return img
+}
+
+func TestDecode(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		name string
+		img  image.Image
+	}{
+		{"Clean", ean13Image("9780156027601", 2)},
+		{"OneModulePerPixel", ean13Image("9780156027601", 1)},
+		{"Photo", photo(ean13Image("9780156027601", 2), 1.7)},
+		{"Sideways", rotate(photo(ean13Image("9780156027601", 2), 1.3), 1)},
+		{"UpsideDown", rotate(photo(ean13Image("9780156027601", 3), 1.1), 2)},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			code, err := barcode.Decode(tt.img)
+			require.NoError(t, err)
+			assert.Equal(t, "9780156027601", code)
+		})
+	}
+
+	t.Run("AllDigits", func(t *testing.T) {
+		t.Parallel()
+		// Every digit in both halves and every first digit's parity.
+		for _, want := range []string{"0123456789012", "1987654321097", "2555555555553", "3141592653582", "4000000000006", "5012345678900", "6901234567892", "7300000000004", "8712345678906", "9780441172719"} {
+			code, err := barcode.Decode(ean13Image(want, 2))
+			if assert.NoError(t, err, want) {
+				assert.Equal(t, want, code)
+			}
+		}
+	})
+
+	t.Run("ErrNotFound", func(t *testing.T) {
+		t.Parallel()
+		_, err := barcode.Decode(image.NewGray(image.Rect(0, 0, 200, 100)))
+		assert.Equal(t, bookid.ENOTFOUND, bookid.ErrorCode(err))
+
+		// A wrong check digit is not a barcode.
+		_, err = barcode.Decode(ean13Image("9780156027602", 2))
+		assert.Equal(t, bookid.ENOTFOUND, bookid.ErrorCode(err))
+	})
+}
+
+func TestDecodeISBN(t *testing.T) {
+	t.Parallel()
+
+	code, err := barcode.DecodeISBN(ean13Image("9780441172719", 2))
+	require.NoError(t, err)
+	assert.Equal(t, "9780441172719", code)
+
+	// An ISSN barcode is an EAN-13 but not an ISBN.
+	_, err = barcode.DecodeISBN(ean13Image("9770028083002", 2))
+	assert.Equal(t, bookid.EINVALID, bookid.ErrorCode(err))
+}
@@ -0,0 +1,52 @@
+package bookid
+
+import "context"
+
+// BatchFinder searches for many books at once, running the searches
+// concurrently.
+type BatchFinder interface {
+	// SearchAll searches for each query and returns one result per query, in
+	// the order of queries. A failed search is recorded in its result and
+	// does not stop the others. Returns the results so far along with the
+	// context's error if it is canceled.
+	SearchAll(ctx context.Context, queries []string, opts BatchOptions) ([]BatchResult, error)
+}
+
+// BatchOptions controls how a BatchFinder runs its searches.
+type BatchOptions struct {
+	// Options of each search.
+	Search SearchOptions
+
+	// Number of concurrent searches. Zero uses the finder's default.
+	Workers int
+
+	// Called after each search completes, if set. Calls are never
+	// concurrent, so the function need not be safe for concurrent use.
+	Progress func(BatchProgress)
+}
+
+// Validate returns an error if the options contain invalid values.
+func (o BatchOptions) Validate() error {
+	if o.Workers < 0 {
+		return Errorf(EINVALID, "Workers must not be negative.")
+	}
+	return o.Search.Validate()
+}
+
+// BatchResult represents the outcome of a single search of a batch.
+type BatchResult struct {
+	Query   string
+	Results []BookResult
+	Err     error
+}
+
+// BatchProgress reports a completed search along with the progress of the
+// whole batch.
+type BatchProgress struct {
+	Index  int // Index of the completed query
+	Result BatchResult
+
+	Done   int // Searches completed so far, including failed ones
+	Failed int
+	Total  int
+}
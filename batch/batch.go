@@ -0,0 +1,106 @@
+// Package batch implements a BatchFinder running the searches of a batch on a
+// bounded pool of workers.
+package batch
+
+import (
+	"context"
+	"sync"
+
+	"github.com/fwojciec/bookid"
+)
+
+// DefaultWorkers is the number of concurrent searches of a new BatchFinder.
+const DefaultWorkers = 4
+
+// Ensure type implements interface.
+var _ bookid.BatchFinder = (*BatchFinder)(nil)
+
+// BatchFinder searches for many queries with a BookFinder, running at most
+// Workers searches at a time so that providers are not flooded.
+type BatchFinder struct {
+	finder bookid.BookFinder
+
+	// Number of concurrent searches used when the options do not set one.
+	Workers int
+}
+
+// NewBatchFinder returns a BatchFinder searching with finder.
+func NewBatchFinder(finder bookid.BookFinder) *BatchFinder {
+	return &BatchFinder{
+		finder:  finder,
+		Workers: DefaultWorkers,
+	}
+}
+
+// SearchAll searches for each query on a pool of workers. Results are
+// returned in the order of queries regardless of the order searches complete
+// in. If ctx is canceled, the queries not yet searched fail with its error.
+func (f *BatchFinder) SearchAll(ctx context.Context, queries []string, opts bookid.BatchOptions) ([]bookid.BatchResult, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	workers := opts.Workers
+	if workers == 0 {
+		workers = max(f.Workers, 1)
+	}
+	workers = min(workers, len(queries))
+
+	results := make([]bookid.BatchResult, len(queries))
+	started := make([]bool, len(queries))
+	for i, query := range queries {
+		results[i].Query = query
+	}
+
+	// Progress is updated and reported under the lock, so that reports are
+	// never concurrent and always in the order of their counts.
+	var mu sync.Mutex
+	progress := bookid.BatchProgress{Total: len(queries)}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				r := &results[i]
+				r.Results, r.Err = f.finder.Search(ctx, r.Query, opts.Search)
+
+				mu.Lock()
+				progress.Done++
+				if r.Err != nil {
+					progress.Failed++
+				}
+				if opts.Progress != nil {
+					p := progress
+					p.Index, p.Result = i, *r
+					opts.Progress(p)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+feed:
+	for i := range queries {
+		select {
+		case jobs <- i:
+			started[i] = true
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		for i := range results {
+			if !started[i] {
+				results[i].Err = err
+			}
+		}
+		return results, err
+	}
+	return results, nil
+}
@@ -0,0 +1,102 @@
+package batch_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/batch"
+	"github.com/fwojciec/bookid/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatchFinder_SearchAll(t *testing.T) {
+	t.Parallel()
+
+	t.Run("InOrder", func(t *testing.T) {
+		t.Parallel()
+		// Later queries finish first.
+		f := batch.NewBatchFinder(&mock.BookFinder{SearchFn: func(_ context.Context, query string, opts bookid.SearchOptions) ([]bookid.BookResult, error) {
+			assert.Equal(t, 1, opts.MaxResults)
+			if query == "fail" {
+				return nil, errors.New("boom")
+			}
+			time.Sleep(time.Duration(10-len(query)) * time.Millisecond)
+			return []bookid.BookResult{{Title: query}}, nil
+		}})
+
+		var reports []bookid.BatchProgress
+		results, err := f.SearchAll(context.Background(), []string{"a", "bb", "fail", "dddd"}, bookid.BatchOptions{
+			Search:   bookid.SearchOptions{MaxResults: 1},
+			Progress: func(p bookid.BatchProgress) { reports = append(reports, p) },
+		})
+		require.NoError(t, err)
+		require.Len(t, results, 4)
+		for i, query := range []string{"a", "bb"} {
+			assert.Equal(t, query, results[i].Query)
+			assert.Equal(t, query, results[i].Results[0].Title)
+		}
+		require.EqualError(t, results[2].Err, "boom")
+		assert.Equal(t, "dddd", results[3].Results[0].Title)
+
+		require.Len(t, reports, 4)
+		last := reports[3]
+		assert.Equal(t, 4, last.Done)
+		assert.Equal(t, 1, last.Failed)
+		assert.Equal(t, 4, last.Total)
+		for i, p := range reports {
+			assert.Equal(t, i+1, p.Done)
+			assert.Equal(t, results[p.Index].Query, p.Result.Query)
+		}
+	})
+
+	t.Run("BoundedParallelism", func(t *testing.T) {
+		t.Parallel()
+		var running, peak atomic.Int32
+		f := batch.NewBatchFinder(&mock.BookFinder{SearchFn: func(context.Context, string, bookid.SearchOptions) ([]bookid.BookResult, error) {
+			n := running.Add(1)
+			defer running.Add(-1)
+			for {
+				if p := peak.Load(); n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			return nil, nil
+		}})
+
+		results, err := f.SearchAll(context.Background(), make([]string, 20), bookid.BatchOptions{Workers: 3})
+		require.NoError(t, err)
+		assert.Len(t, results, 20)
+		assert.LessOrEqual(t, peak.Load(), int32(3))
+		assert.Positive(t, peak.Load())
+	})
+
+	t.Run("Canceled", func(t *testing.T) {
+		t.Parallel()
+		ctx, cancel := context.WithCancel(context.Background())
+		f := batch.NewBatchFinder(&mock.BookFinder{SearchFn: func(ctx context.Context, _ string, _ bookid.SearchOptions) ([]bookid.BookResult, error) {
+			cancel()
+			return nil, ctx.Err()
+		}})
+		f.Workers = 1
+
+		results, err := f.SearchAll(ctx, []string{"a", "b", "c"}, bookid.BatchOptions{})
+		require.ErrorIs(t, err, context.Canceled)
+		require.Len(t, results, 3)
+		for _, r := range results {
+			assert.ErrorIs(t, r.Err, context.Canceled)
+		}
+	})
+
+	t.Run("ErrInvalidOptions", func(t *testing.T) {
+		t.Parallel()
+		f := batch.NewBatchFinder(&mock.BookFinder{})
+		_, err := f.SearchAll(context.Background(), []string{"a"}, bookid.BatchOptions{Workers: -1})
+		assert.Equal(t, bookid.EINVALID, bookid.ErrorCode(err))
+	})
+}
@@ -0,0 +1,46 @@
+// Package binding detects the binding of a publication from the format
+// labels of providers and booksellers, such as "Mass Market Paperback",
+// "Kindle Edition" or "Audio CD".
+package binding
+
+import (
+	"strings"
+
+	"github.com/fwojciec/bookid"
+)
+
+// Parse returns the binding a format label describes, ignoring case, spaces
+// and hyphens. Returns an empty binding for labels it does not recognize,
+// such as "Spiral-bound" or "Map".
+func Parse(s string) bookid.Binding {
+	key := strings.NewReplacer(" ", "", "-", "", "_", "").Replace(strings.ToLower(s))
+	if key == "" {
+		return ""
+	}
+	// Audio and electronic formats are checked first, since labels such as
+	// "Audio CD (Hardcover)" name the packaging as well.
+	for _, f := range formats() {
+		for _, word := range f.words {
+			if strings.Contains(key, word) {
+				return f.binding
+			}
+		}
+	}
+	return ""
+}
+
+// format is a binding along with the words of the labels that describe it.
+type format struct {
+	binding bookid.Binding
+	words   []string
+}
+
+// formats returns the words of each binding, in the order they are checked.
+func formats() []format {
+	return []format{
+		{bookid.BindingAudiobook, []string{"audio", "mp3", "audible", "cassette"}},
+		{bookid.BindingEbook, []string{"ebook", "kindle", "epub", "electronic", "digital", "nook", "pdf"}},
+		{bookid.BindingHardcover, []string{"hardcover", "hardback", "hardbound", "librarybinding", "boardbook", "cloth"}},
+		{bookid.BindingPaperback, []string{"paperback", "softcover", "softback", "massmarket", "tradepaper", "paperbound"}},
+	}
+}
@@ -0,0 +1,35 @@
+package binding_test
+
+import (
+	"testing"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/binding"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	for label, want := range map[string]bookid.Binding{
+		"Hardcover":             bookid.BindingHardcover,
+		"hardback":              bookid.BindingHardcover,
+		"Library Binding":       bookid.BindingHardcover,
+		"Board book":            bookid.BindingHardcover,
+		"Paperback":             bookid.BindingPaperback,
+		"Mass Market Paperback": bookid.BindingPaperback,
+		"Trade Paperback":       bookid.BindingPaperback,
+		"Soft-cover":            bookid.BindingPaperback,
+		"Kindle Edition":        bookid.BindingEbook,
+		"E-Book":                bookid.BindingEbook,
+		"ePub":                  bookid.BindingEbook,
+		"Audio CD":              bookid.BindingAudiobook,
+		"MP3 CD":                bookid.BindingAudiobook,
+		"Audible Audiobook":     bookid.BindingAudiobook,
+		"Spiral-bound":          "",
+		"":                      "",
+	} {
+		if got := binding.Parse(label); got != want {
+			t.Errorf("Parse(%q)=%q, want %q", label, got, want)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+// Package bisac maps the categories of providers to BISAC subject codes, the
+// subject scheme of the North American book trade, e.g. "Fiction / Science
+// Fiction / General" to "FIC028000". Retailers expect products to carry these
+// codes rather than free-text categories.
+package bisac
+
+import (
+	_ "embed"
+	"slices"
+	"strings"
+)
+
+// headings holds the embedded table of codes and headings used by Code and
+// Heading.
+//
+//go:embed headings.tsv
+var headings string
+
+// Code returns the BISAC code of a category, or an empty string if it has
+// none. Categories match headings regardless of case and spacing, and a
+// category naming a broader heading, such as Google Books' "Fiction", matches
+// its "General" heading.
+func Code(category string) string {
+	k := key(category)
+	if k == "" {
+		return ""
+	}
+	var general string
+	for code, heading := range entries() {
+		switch key(heading) {
+		case k:
+			return code
+		case k + " / general":
+			general = code
+		}
+	}
+	return general
+}
+
+// Codes returns the BISAC codes of categories without duplicates and in the
+// order given. Categories without a code are skipped.
+func Codes(categories []string) []string {
+	var codes []string
+	for _, c := range categories {
+		if code := Code(c); code != "" && !slices.Contains(codes, code) {
+			codes = append(codes, code)
+		}
+	}
+	return codes
+}
+
+// Heading returns the heading of a BISAC code, e.g. "FICTION / Science
+// Fiction / General", or an empty string if the code is unknown.
+func Heading(code string) string {
+	return entries()[strings.ToUpper(strings.TrimSpace(code))]
+}
+
+// entries returns the embedded headings by code.
+func entries() map[string]string {
+	m := make(map[string]string)
+	for _, line := range strings.Split(headings, "\n") {
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		if code, heading, ok := strings.Cut(line, "\t"); ok {
+			m[code] = heading
+		}
+	}
+	return m
+}
+
+// key returns the form of a category or heading compared by Code: lowercase,
+// with its parts separated by " / ".
+func key(s string) string {
+	parts := strings.Split(strings.ToLower(s), "/")
+	for i, p := range parts {
+		parts[i] = strings.Join(strings.Fields(p), " ")
+	}
+	return strings.Trim(strings.Join(parts, " / "), " /")
+}
@@ -0,0 +1,49 @@
+package bisac_test
+
+import (
+	"testing"
+
+	"github.com/fwojciec/bookid/bisac"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCode(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		category string
+		want     string
+	}{
+		{"Fiction / Science Fiction / General", "FIC028000"},
+		{"fiction/science fiction/general", "FIC028000"},
+		{"Fiction / Science Fiction", "FIC028000"},
+		{"Fiction", "FIC000000"},
+		{"Juvenile Fiction", "JUV000000"},
+		{"Biography & Autobiography", "BIO000000"},
+		{"FICTION / Classics", "FIC004000"},
+		{"Married people", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.category, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.want, bisac.Code(tt.category))
+		})
+	}
+}
+
+func TestCodes(t *testing.T) {
+	t.Parallel()
+
+	got := bisac.Codes([]string{"Fiction", "Space opera", "fiction", "Fiction / Classics"})
+	assert.Equal(t, []string{"FIC000000", "FIC004000"}, got)
+	assert.Nil(t, bisac.Codes([]string{"Space opera"}))
+}
+
+func TestHeading(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "FICTION / Science Fiction / General", bisac.Heading("FIC028000"))
+	assert.Equal(t, "FICTION / Science Fiction / General", bisac.Heading(" fic028000 "))
+	assert.Empty(t, bisac.Heading("XXX000000"))
+}
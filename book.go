@@ -1,40 +1,637 @@
 package bookid
 
-import "time"
+import (
+	"context"
+	"strings"
+	"time"
+)
 
 // Work represents the abstract creative work (the "platonic" book)
 type Work struct {
-	ID        int64  // Simple auto-increment ID
-	Title     string // As it appears on the title page
-	Author    string // As credited on the title page
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	ID     int64  `json:"id"`     // Simple auto-increment ID
+	UID    string `json:"uid"`    // Random ID, the same in every synced catalog
+	Title  string `json:"title"`  // As it appears on the title page
+	Author string `json:"author"` // As credited on the title page
+
+	// Set on translations: the title and ISO 639-1 language of the
+	// original, which may not be cataloged.
+	OriginalTitle    string `json:"original_title,omitempty"`
+	OriginalLanguage string `json:"original_language,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	DeletedAt time.Time `json:"deleted_at,omitzero"` // Set while in the trash
+
+	// How well the work matches the query of a catalog search, higher being
+	// better. Zero outside search results.
+	Relevance float64 `json:"relevance,omitempty"`
+}
+
+// Validate returns an error if the work contains invalid fields.
+func (w *Work) Validate() error {
+	if strings.TrimSpace(w.Title) == "" {
+		return Errorf(EINVALID, "Work title required.")
+	}
+	return nil
+}
+
+// WorkService represents a service for managing works.
+type WorkService interface {
+	// FindWorkByID retrieves a single work by ID.
+	// Returns ENOTFOUND if the work does not exist.
+	FindWorkByID(ctx context.Context, id int64) (*Work, error)
+
+	// FindWorks retrieves a list of works matching the filter along with the
+	// total number of matches, ignoring Offset and Limit.
+	FindWorks(ctx context.Context, filter WorkFilter) ([]*Work, int, error)
+
+	// CreateWork creates a new work.
+	CreateWork(ctx context.Context, work *Work) error
+
+	// UpdateWork updates an existing work. Returns the updated work.
+	// Returns ENOTFOUND if the work does not exist.
+	UpdateWork(ctx context.Context, id int64, upd WorkUpdate) (*Work, error)
+
+	// DeleteWork moves a work along with its publications to the trash.
+	// Returns ENOTFOUND if the work does not exist or is already deleted.
+	DeleteWork(ctx context.Context, id int64) error
+
+	// RestoreWork restores a deleted work from the trash along with the
+	// publications deleted with it. Returns ENOTFOUND if the work is not in
+	// the trash.
+	RestoreWork(ctx context.Context, id int64) error
+
+	// PurgeWork permanently removes a deleted work along with its
+	// publications. Returns ENOTFOUND if the work is not in the trash.
+	PurgeWork(ctx context.Context, id int64) error
+
+	// MergeWorks merges duplicate source works into the target in a single
+	// transaction: their publications, author links, relations, series
+	// links, subjects and collection memberships move to the target and the
+	// sources are deleted. Returns ENOTFOUND if any work
+	// does not exist and EINVALID if no sources are given or the target is
+	// among them.
+	MergeWorks(ctx context.Context, targetID int64, sourceIDs ...int64) error
+}
+
+// WorkFilter represents a filter used by FindWorks.
+type WorkFilter struct {
+	// Filtering fields. Title and Author match exactly, ignoring case.
+	ID     *int64
+	UID    *string
+	Title  *string
+	Author *string
+
+	// Query matches works whose title or author contains the text.
+	Query *string
+
+	// Language matches works with a publication in a language or its
+	// regional variants, as PublicationFilter.Language does.
+	Language *string
+
+	// Binding matches works with a publication in a binding.
+	Binding *Binding
+
+	// Series matches works in the series with the name, ignoring case. They
+	// are listed in volume order, with works of unknown position last.
+	Series *string
+
+	// Subject matches works tagged with a subject, by normalized name.
+	Subject *string
+
+	// CollectionID matches the works in a collection.
+	CollectionID *int64
+
+	// Works in the trash are left out unless IncludeDeleted is set.
+	// OnlyDeleted restricts results to them.
+	IncludeDeleted bool
+	OnlyDeleted    bool
+
+	// Restrict to subset of results.
+	Offset int
+	Limit  int
+}
+
+// WorkUpdate represents a set of fields to be updated via UpdateWork.
+type WorkUpdate struct {
+	Title            *string
+	Author           *string
+	OriginalTitle    *string
+	OriginalLanguage *string
+}
+
+// CatalogService represents a service for adding identified books to the
+// catalog.
+type CatalogService interface {
+	// SaveResult saves a search result as a publication of a work along with
+	// the work's authors, series and subjects and returns their IDs. A
+	// publication already cataloged under the same ISBN-13 or Google Books
+	// volume ID is refreshed in place. Otherwise the publication is clustered
+	// with the other editions of its work: it joins an existing work whose
+	// title and authors closely match the result, and a new work is created
+	// only if none does.
+	SaveResult(ctx context.Context, result BookResult) (workID, publicationID int64, err error)
+}
+
+// WorkRebuildService represents a service for re-clustering the publications
+// of the catalog into works, such as after the matching rules improved.
+type WorkRebuildService interface {
+	// RebuildWorks clusters every publication afresh with the current
+	// matching rules, as if the catalog were saved anew, and returns the
+	// works that change. Publications are moved, works are created and
+	// merged and author links are rewritten only if apply is set.
+	RebuildWorks(ctx context.Context, apply bool) ([]*WorkCluster, error)
+}
+
+// WorkCluster represents a work as rebuilt from the publications clustered
+// into it.
+type WorkCluster struct {
+	WorkID         int64    `json:"work_id"` // Zero until a new work is created
+	Title          string   `json:"title"`
+	Authors        []string `json:"authors"`
+	PublicationIDs []int64  `json:"publication_ids"`
+
+	// Works the publications are moved from, and the works left without
+	// publications that are merged into this one.
+	MovedFrom []int64 `json:"moved_from,omitempty"`
+	Merged    []int64 `json:"merged,omitempty"`
+}
+
+// CatalogSearchService represents a service for full-text search of the
+// catalog.
+type CatalogSearchService interface {
+	// SearchCatalog retrieves the works whose title, authors or publications
+	// contain every term of the query, along with the total number of
+	// matches, ignoring Offset and Limit. Terms ending in "*" match as
+	// prefixes. Returns EINVALID if the query has no terms.
+	SearchCatalog(ctx context.Context, filter CatalogSearchFilter) ([]*Work, int, error)
+}
+
+// CatalogSearchFilter represents a filter used by SearchCatalog.
+type CatalogSearchFilter struct {
+	Query string
+
+	// Restrict to subset of results.
+	Offset int
+	Limit  int
 }
 
 // Author represents a person who created works
 type Author struct {
-	ID   int64  // Simple auto-increment ID
-	Name string // Normalized name for deduplication
+	ID   int64  `json:"id"`   // Simple auto-increment ID
+	Name string `json:"name"` // Normalized name for deduplication
+
+	// Identifiers of the author's authority records, which tell apart
+	// authors with the same name. Empty until the author is linked.
+	VIAFID     string `json:"viaf_id,omitempty"`     // Virtual International Authority File
+	WikidataID string `json:"wikidata_id,omitempty"` // Wikidata item, e.g. "Q42"
+
+	// Role in the work the author is listed for; empty otherwise.
+	Role ContributorRole `json:"role,omitempty"`
+}
+
+// Validate returns an error if the author contains invalid fields.
+func (a *Author) Validate() error {
+	if strings.TrimSpace(a.Name) == "" {
+		return Errorf(EINVALID, "Author name required.")
+	} else if a.VIAFID != "" && !isDigits(a.VIAFID) {
+		return Errorf(EINVALID, "Invalid VIAF ID.")
+	} else if a.WikidataID != "" && (a.WikidataID[0] != 'Q' || !isDigits(a.WikidataID[1:])) {
+		return Errorf(EINVALID, "Invalid Wikidata ID.")
+	}
+	return nil
+}
+
+// isDigits reports whether s is a non-empty string of ASCII digits.
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// AuthorService represents a service for managing authors and their links to
+// works.
+type AuthorService interface {
+	// FindAuthorByID retrieves a single author by ID.
+	// Returns ENOTFOUND if the author does not exist.
+	FindAuthorByID(ctx context.Context, id int64) (*Author, error)
+
+	// FindAuthors retrieves a list of authors matching the filter along with
+	// the total number of matches, ignoring Offset and Limit.
+	FindAuthors(ctx context.Context, filter AuthorFilter) ([]*Author, int, error)
+
+	// CreateAuthor creates a new author. Names are normalized first so that
+	// "Lem, Stanisław" and "stanislaw lem" resolve to the same author; if a
+	// matching author already exists, author is populated from it instead.
+	//
+	// An author with authority identifiers matches only the author with the
+	// same identifiers, so namesakes are kept apart. An author without them
+	// matches the unlinked author of that name, or the only linked one.
+	CreateAuthor(ctx context.Context, author *Author) error
+
+	// UpdateAuthor updates an existing author, e.g. to link it to its
+	// authority records. Returns ENOTFOUND if the author does not exist and
+	// ECONFLICT if another author is linked to the same record.
+	UpdateAuthor(ctx context.Context, id int64, upd AuthorUpdate) (*Author, error)
+
+	// DeleteAuthor permanently removes an author and their work links.
+	// Returns ENOTFOUND if the author does not exist.
+	DeleteAuthor(ctx context.Context, id int64) error
+
+	// AddWorkAuthor links an author to a work in a role, by default as its
+	// author. Linking an already linked author is a no-op, whatever the
+	// role. Returns ENOTFOUND if either side does not exist and EINVALID if
+	// the role is unknown.
+	AddWorkAuthor(ctx context.Context, wa *WorkAuthor) error
+
+	// RemoveWorkAuthor unlinks an author from a work.
+	// Returns ENOTFOUND if the link does not exist.
+	RemoveWorkAuthor(ctx context.Context, wa *WorkAuthor) error
+}
+
+// AuthorFilter represents a filter used by FindAuthors.
+type AuthorFilter struct {
+	ID *int64
+
+	// Name matches authors by normalized name, so "LEM, Stanislaw" finds
+	// "Stanisław Lem".
+	Name *string
+
+	// WorkID restricts results to the authors linked to a work.
+	WorkID *int64
+
+	VIAFID     *string
+	WikidataID *string
+
+	// Restrict to subset of results.
+	Offset int
+	Limit  int
+}
+
+// AuthorUpdate represents a set of fields to be updated via UpdateAuthor.
+type AuthorUpdate struct {
+	VIAFID     *string
+	WikidataID *string
+}
+
+// AuthorityRecord is a person's record in an authority file, such as VIAF,
+// which assigns stable identifiers to people so that namesakes can be told
+// apart.
+type AuthorityRecord struct {
+	VIAFID     string `json:"viaf_id"`
+	WikidataID string `json:"wikidata_id,omitempty"`
+	Name       string `json:"name"` // Authorized heading, e.g. "Lem, Stanisław, 1921-2006"
+}
+
+// AuthorityFinder looks up people in an authority file.
+type AuthorityFinder interface {
+	// FindAuthorities returns the records of people with names matching
+	// name, best match first.
+	FindAuthorities(ctx context.Context, name string) ([]*AuthorityRecord, error)
 }
 
 // WorkAuthor links works to their authors (for searching/indexing)
 type WorkAuthor struct {
-	WorkID   int64
-	AuthorID int64
+	WorkID   int64           `json:"work_id"`
+	AuthorID int64           `json:"author_id"`
+	Role     ContributorRole `json:"role,omitempty"` // Author if empty
+}
+
+// ContributorRole represents the part a person had in a work. A person has a
+// single role per work.
+type ContributorRole string
+
+// Contributor roles.
+const (
+	ContributorRoleAuthor      ContributorRole = "author"
+	ContributorRoleTranslator  ContributorRole = "translator"
+	ContributorRoleEditor      ContributorRole = "editor"
+	ContributorRoleIllustrator ContributorRole = "illustrator"
+	ContributorRoleNarrator    ContributorRole = "narrator"
+)
+
+// Valid returns true if r is a known contributor role.
+func (r ContributorRole) Valid() bool {
+	switch r {
+	case ContributorRoleAuthor, ContributorRoleTranslator, ContributorRoleEditor, ContributorRoleIllustrator, ContributorRoleNarrator:
+		return true
+	default:
+		return false
+	}
+}
+
+// Contributor represents a person credited for a book other than as its
+// author.
+type Contributor struct {
+	Name string          `json:"name"`
+	Role ContributorRole `json:"role"`
 }
 
 // Publication represents a specific published edition of a Work
 type Publication struct {
-	ID                  int64
-	WorkID              int64
-	ISBN10              string
-	ISBN13              string
-	Publisher           string
-	PublishedYear       int
-	Language            string
-	GoogleBooksVolumeID string
-	ThumbnailURL        string
-	GoogleBooksData     string
-	CreatedAt           time.Time
-	UpdatedAt           time.Time
+	ID                  int64          `json:"id"`
+	UID                 string         `json:"uid"` // Random ID, the same in every synced catalog
+	WorkID              int64          `json:"work_id"`
+	ISBN10              string         `json:"isbn10,omitempty"`
+	ISBN13              string         `json:"isbn13,omitempty"`
+	Publisher           string         `json:"publisher,omitempty"`    // Normalized name of the publisher
+	PublisherID         int64          `json:"publisher_id,omitempty"` // Set from Publisher when saved
+	PublishedYear       int            `json:"published_year,omitempty"`
+	Language            string         `json:"language,omitempty"` // BCP-47 tag, e.g. "en" or "pt-BR"
+	Binding             Binding        `json:"binding,omitempty"`  // Empty if unknown
+	PageCount           int            `json:"page_count,omitempty"`
+	DurationMinutes     int            `json:"duration_minutes,omitempty"` // Running time of audiobooks
+	Dimensions          Dimensions     `json:"dimensions,omitzero"`
+	Access              AccessInfo     `json:"access,omitzero"`         // Online reading, as told by Google Books
+	Classification      Classification `json:"classification,omitzero"` // Dewey and LC numbers, for shelving
+	GoogleBooksVolumeID string         `json:"google_books_volume_id,omitempty"`
+	OCLCNumber          string         `json:"oclc_number,omitempty"` // WorldCat record number
+	LCCN                string         `json:"lccn,omitempty"`        // Library of Congress Control Number, normalized
+	DOI                 string         `json:"doi,omitempty"`         // Digital Object Identifier, lowercased
+	ASIN                string         `json:"asin,omitempty"`        // Amazon Standard Identification Number, uppercased
+	ThumbnailURL        string         `json:"thumbnail_url,omitempty"`
+	CoverPath           string         `json:"cover_path,omitempty"`        // Stored cover image, relative to the cover store
+	Description         string         `json:"description,omitempty"`       // Plain text summary
+	TableOfContents     []string       `json:"table_of_contents,omitempty"` // Chapter or part titles
+	GoogleBooksData     string         `json:"-"`                           // Raw API response, omitted from output
+	CreatedAt           time.Time      `json:"created_at"`
+	UpdatedAt           time.Time      `json:"updated_at"`
+	RefreshedAt         time.Time      `json:"refreshed_at,omitzero"` // Last re-fetched from its provider
+	DeletedAt           time.Time      `json:"deleted_at,omitzero"`   // Set while in the trash
+
+	// Provider that supplied each field, keyed by JSON name, e.g.
+	// "publisher". Fields set by the user have no entry.
+	Provenance map[string]string `json:"provenance,omitempty"`
+
+	// Personal metadata about the owned copy; never set from providers.
+	ReadingStatus ReadingStatus `json:"reading_status,omitempty"`
+	Rating        int           `json:"rating,omitempty"` // 1 to 5, zero if unrated
+	Notes         string        `json:"notes,omitempty"`
+	AcquiredAt    time.Time     `json:"acquired_at,omitzero"`
+	Location      string        `json:"location,omitempty"` // Where the copy is kept, e.g. a shelf code
+}
+
+// Validate returns an error if the publication contains invalid fields.
+func (p *Publication) Validate() error {
+	if p.WorkID == 0 {
+		return Errorf(EINVALID, "Publication work required.")
+	} else if p.PageCount < 0 {
+		return Errorf(EINVALID, "Page count must not be negative.")
+	} else if p.DurationMinutes < 0 {
+		return Errorf(EINVALID, "Duration must not be negative.")
+	} else if p.Dimensions.Height < 0 || p.Dimensions.Width < 0 || p.Dimensions.Thickness < 0 || p.Dimensions.Weight < 0 {
+		return Errorf(EINVALID, "Dimensions must not be negative.")
+	} else if p.Binding != "" && !p.Binding.Valid() {
+		return Errorf(EINVALID, "Invalid binding %q.", p.Binding)
+	} else if !p.ReadingStatus.Valid() {
+		return Errorf(EINVALID, "Invalid reading status %q.", p.ReadingStatus)
+	} else if p.Rating < 0 || p.Rating > MaxRating {
+		return Errorf(EINVALID, "Rating must be between 1 and %d.", MaxRating)
+	}
+	return nil
+}
+
+// Binding is the physical or digital format of a publication.
+type Binding string
+
+// Bindings of publications. Hardcovers include library and board bindings,
+// paperbacks include mass market and trade editions.
+const (
+	BindingHardcover Binding = "hardcover"
+	BindingPaperback Binding = "paperback"
+	BindingEbook     Binding = "ebook"
+	BindingAudiobook Binding = "audiobook"
+)
+
+// Valid returns true if the binding is one of the known bindings.
+func (b Binding) Valid() bool {
+	switch b {
+	case BindingHardcover, BindingPaperback, BindingEbook, BindingAudiobook:
+		return true
+	}
+	return false
+}
+
+// Dimensions are the physical size and weight of a publication, as used to
+// estimate shipping. Zero values are unknown.
+type Dimensions struct {
+	Height    float64 `json:"height_mm,omitempty"`    // Millimeters
+	Width     float64 `json:"width_mm,omitempty"`     // Millimeters
+	Thickness float64 `json:"thickness_mm,omitempty"` // Millimeters
+	Weight    float64 `json:"weight_g,omitempty"`     // Grams
+}
+
+// IsZero returns true if none of the dimensions are known.
+func (d Dimensions) IsZero() bool {
+	return d == Dimensions{}
+}
+
+// MaxRating is the highest rating of a publication.
+const MaxRating = 5
+
+// ReadingStatus represents how far the owner is with reading a publication.
+type ReadingStatus string
+
+// Reading statuses. The zero value means no status is recorded.
+const (
+	ReadingStatusWantToRead ReadingStatus = "want_to_read"
+	ReadingStatusReading    ReadingStatus = "reading"
+	ReadingStatusRead       ReadingStatus = "read"
+	ReadingStatusAbandoned  ReadingStatus = "abandoned"
+)
+
+// Valid returns true if s is empty or a known reading status.
+func (s ReadingStatus) Valid() bool {
+	switch s {
+	case "", ReadingStatusWantToRead, ReadingStatusReading, ReadingStatusRead, ReadingStatusAbandoned:
+		return true
+	default:
+		return false
+	}
+}
+
+// PublicationService represents a service for managing publications.
+type PublicationService interface {
+	// FindPublicationByID retrieves a single publication by ID.
+	// Returns ENOTFOUND if the publication does not exist.
+	FindPublicationByID(ctx context.Context, id int64) (*Publication, error)
+
+	// FindPublications retrieves a list of publications matching the filter
+	// along with the total number of matches, ignoring Offset and Limit.
+	FindPublications(ctx context.Context, filter PublicationFilter) ([]*Publication, int, error)
+
+	// CreatePublication creates a new publication. Returns ECONFLICT if a
+	// publication with the same ISBN-13 or Google Books volume ID exists.
+	CreatePublication(ctx context.Context, pub *Publication) error
+
+	// UpsertPublication creates a publication, or updates the existing one
+	// with the same ISBN-13 or Google Books volume ID. Non-empty fields of pub
+	// overwrite stored values; the stored work is kept. An existing
+	// publication in the trash is restored. On return pub holds the stored
+	// publication.
+	UpsertPublication(ctx context.Context, pub *Publication) error
+
+	// UpdatePublication updates an existing publication. Returns the updated
+	// publication. Returns ENOTFOUND if the publication does not exist.
+	UpdatePublication(ctx context.Context, id int64, upd PublicationUpdate) (*Publication, error)
+
+	// DeletePublication moves a publication to the trash. Returns ENOTFOUND
+	// if the publication does not exist or is already deleted.
+	DeletePublication(ctx context.Context, id int64) error
+
+	// RestorePublication restores a deleted publication from the trash,
+	// along with its work if that is deleted too. Returns ENOTFOUND if the
+	// publication is not in the trash.
+	RestorePublication(ctx context.Context, id int64) error
+
+	// PurgePublication permanently removes a deleted publication. Returns
+	// ENOTFOUND if the publication is not in the trash.
+	PurgePublication(ctx context.Context, id int64) error
+}
+
+// PublicationFilter represents a filter used by FindPublications.
+type PublicationFilter struct {
+	ID     *int64
+	UID    *string
+	WorkID *int64
+
+	// ISBN matches either the ISBN-10 or ISBN-13, ignoring hyphens.
+	ISBN *string
+
+	GoogleBooksVolumeID *string
+	OCLCNumber          *string
+	LCCN                *string // Normalized before matching
+	DOI                 *string // Normalized before matching
+	ASIN                *string // Normalized before matching
+	Publisher           *string // Normalized before matching, ignoring case
+	PublisherID         *int64
+	PublishedYear       *int
+
+	// Language matches publications in a language or its regional variants,
+	// so "en" finds "en-GB" but "en-GB" finds only "en-GB".
+	Language *string
+
+	Binding *Binding
+
+	// HasCover restricts results to publications with or without a stored
+	// cover image.
+	HasCover *bool
+
+	// HasClassification restricts results to publications with or without
+	// a Dewey or LC classification number.
+	HasClassification *bool
+
+	// RefreshedBefore restricts results to publications last refreshed, or
+	// created if never refreshed, before the given time.
+	RefreshedBefore *time.Time
+
+	// Publications in the trash are left out unless IncludeDeleted is set.
+	// OnlyDeleted restricts results to them.
+	IncludeDeleted bool
+	OnlyDeleted    bool
+
+	// Restrict to subset of results.
+	Offset int
+	Limit  int
+}
+
+// PublicationUpdate represents a set of fields to be updated via
+// UpdatePublication.
+type PublicationUpdate struct {
+	WorkID          *int64
+	ISBN10          *string
+	ISBN13          *string
+	Publisher       *string
+	PublishedYear   *int
+	Language        *string
+	Binding         *Binding
+	PageCount       *int
+	DurationMinutes *int
+	Dimensions      *Dimensions
+	Access          *AccessInfo
+	Classification  *Classification
+	OCLCNumber      *string
+	LCCN            *string
+	DOI             *string
+	ASIN            *string
+	ThumbnailURL    *string
+	CoverPath       *string
+	Description     *string
+	TableOfContents *[]string
+
+	// Personal metadata about the owned copy.
+	ReadingStatus *ReadingStatus
+	Rating        *int
+	Notes         *string
+	AcquiredAt    *time.Time
+	Location      *string
+
+	// Set by refreshes from the provider.
+	GoogleBooksData *string
+	RefreshedAt     *time.Time
+
+	// Providers of the updated fields by JSON name, added to those of the
+	// other fields.
+	Provenance map[string]string
+}
+
+// CoverService represents a service for storing the cover images of
+// publications locally.
+type CoverService interface {
+	// FetchCover downloads the cover of a publication, stores it and records
+	// its path on the publication. A stored cover is kept. Returns ENOTFOUND
+	// if the publication does not exist or no cover is available for it.
+	FetchCover(ctx context.Context, publicationID int64) (*Publication, error)
+
+	// FindCover returns the stored cover of a publication scaled to size and
+	// encoded in format, which default to CoverSizeMedium and
+	// CoverFormatJPEG. Returns ENOTFOUND if the publication has no stored
+	// cover and EINVALID if the size or format is unknown.
+	FindCover(ctx context.Context, publicationID int64, size, format string) (*Cover, error)
+}
+
+// RefreshService represents a service for keeping cataloged publications
+// current with their providers.
+type RefreshService interface {
+	// RefreshPublication re-fetches a publication from its provider, updates
+	// the fields whose values changed and records the time of the refresh.
+	// Returns ENOTFOUND if the publication does not exist or its provider no
+	// longer has it.
+	RefreshPublication(ctx context.Context, id int64) (*PublicationRefresh, error)
+}
+
+// PublicationRefresh represents the outcome of refreshing a publication.
+type PublicationRefresh struct {
+	Publication *Publication `json:"publication"`
+
+	// Changed fields by JSON name; empty if the provider's data matched.
+	Changes map[string]AuditChange `json:"changes"`
+
+	// Values held back by merge policies for review rather than applied.
+	Conflicts []*Conflict `json:"conflicts,omitempty"`
+}
+
+// Cover sizes.
+const (
+	CoverSizeSmall  = "small"
+	CoverSizeMedium = "medium"
+	CoverSizeLarge  = "large"
+)
+
+// Cover image formats.
+const (
+	CoverFormatJPEG = "jpeg"
+	CoverFormatWebP = "webp"
+)
+
+// Cover represents a cover image rendered for display.
+type Cover struct {
+	ContentType string
+	Data        []byte
 }
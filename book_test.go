@@ -29,6 +29,20 @@ func TestWork(t *testing.T) {
 	})
 }
 
+func TestWork_Validate(t *testing.T) {
+	t.Parallel()
+	t.Run("valid work", func(t *testing.T) {
+		t.Parallel()
+		work := &bookid.Work{Title: "The Go Programming Language"}
+		assert.NoError(t, work.Validate())
+	})
+	t.Run("missing title", func(t *testing.T) {
+		t.Parallel()
+		work := &bookid.Work{Title: "  ", Author: "Brian W. Kernighan"}
+		assert.Equal(t, bookid.EINVALID, bookid.ErrorCode(work.Validate()))
+	})
+}
+
 func TestAuthor(t *testing.T) {
 	t.Parallel()
 	t.Run("create author with all fields", func(t *testing.T) {
@@ -3,13 +3,122 @@ package bookid
 import (
 	"context"
 	"encoding/json"
+	"time"
 )
 
 // BookFinder searches for books and returns detailed results
 type BookFinder interface {
 	// Search performs a book search based on the provided query
 	// Returns a list of BookResult with confidence scores
-	Search(ctx context.Context, query string) ([]BookResult, error)
+	Search(ctx context.Context, query string, opts SearchOptions) ([]BookResult, error)
+}
+
+// BookGetter fetches a single book by the ID of the provider's own record,
+// for re-fetching a known book exactly. Implemented by providers with a
+// direct lookup, such as Google Books.
+type BookGetter interface {
+	// GetByID returns the book with the given provider ID.
+	// Returns ENOTFOUND if there is no such book.
+	GetByID(ctx context.Context, id string) (*BookResult, error)
+}
+
+// SearchOptions controls which results a BookFinder returns. The zero value
+// returns the provider's default page of results without raw data.
+type SearchOptions struct {
+	// Maximum number of results. Zero uses the provider's default.
+	MaxResults int
+
+	// Zero-based index of the first result, for paging through results.
+	StartIndex int
+
+	// Restricts results to a language, given as an ISO 639-1 code or BCP-47
+	// tag (e.g. "en" or "pt-BR"). Providers restrict by the primary
+	// language, so "en-GB" also returns American editions.
+	Language string
+
+	// Country to search as, given as an ISO 3166-1 alpha-2 code (e.g.
+	// "PL"), for providers whose results and prices depend on where the
+	// request comes from. Empty uses the provider's configured country or
+	// lets it tell from the request.
+	Country string
+
+	// Restricts results to a kind of publication. Empty means all.
+	PrintType PrintType
+
+	// Restricts results to a binding, such as ebooks. Results whose
+	// binding the provider does not tell are kept. Empty means all.
+	Binding Binding
+
+	// Order of results. Empty means by relevance.
+	OrderBy OrderBy
+
+	// Results with a lower confidence are dropped.
+	MinConfidence float64
+
+	// Restricts results to books that can be read in full for free, such
+	// as public domain editions. Results whose access the provider does not
+	// tell are dropped.
+	FreeOnly bool
+
+	// Keep the raw provider responses in GoogleBooksData and ProviderData.
+	IncludeRaw bool
+}
+
+// Validate returns an error if the options contain invalid values.
+func (o SearchOptions) Validate() error {
+	if o.MaxResults < 0 {
+		return Errorf(EINVALID, "Max results must not be negative.")
+	} else if o.StartIndex < 0 {
+		return Errorf(EINVALID, "Start index must not be negative.")
+	} else if o.MinConfidence < 0 || o.MinConfidence > 1 {
+		return Errorf(EINVALID, "Min confidence must be between 0 and 1.")
+	} else if o.PrintType != "" && !o.PrintType.Valid() {
+		return Errorf(EINVALID, "Invalid print type %q.", o.PrintType)
+	} else if o.Binding != "" && !o.Binding.Valid() {
+		return Errorf(EINVALID, "Invalid binding %q.", o.Binding)
+	} else if o.OrderBy != "" && !o.OrderBy.Valid() {
+		return Errorf(EINVALID, "Invalid order %q.", o.OrderBy)
+	} else if o.Country != "" && !ValidCountry(o.Country) {
+		return Errorf(EINVALID, "Invalid country %q.", o.Country)
+	}
+	return nil
+}
+
+// ValidCountry returns true if code has the form of an ISO 3166-1 alpha-2
+// country code, two letters in either case.
+func ValidCountry(code string) bool {
+	if len(code) != 2 {
+		return false
+	}
+	for _, r := range code {
+		if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') {
+			return false
+		}
+	}
+	return true
+}
+
+// Apply returns a copy of results with the options applied: results below
+// MinConfidence or in another binding are dropped, at most MaxResults are
+// kept and raw provider data is stripped unless IncludeRaw is set.
+func (o SearchOptions) Apply(results []BookResult) []BookResult {
+	other := make([]BookResult, 0, len(results))
+	for _, r := range results {
+		if o.MaxResults > 0 && len(other) == o.MaxResults {
+			break
+		} else if r.Confidence < o.MinConfidence {
+			continue
+		} else if o.Binding != "" && r.Binding != "" && r.Binding != o.Binding {
+			continue
+		} else if o.FreeOnly && !r.Access.Free() {
+			continue
+		}
+		if !o.IncludeRaw {
+			r.GoogleBooksData, r.ProviderData = nil, nil
+		}
+		other = append(other, r)
+	}
+	return other
 }
 
 // BookResult contains all information needed to create Work, Author, and Publication
@@ -18,27 +127,270 @@ type BookResult struct {
 	Title   string   `json:"title"`
 	Authors []string `json:"authors"`
 
+	// People credited other than the authors, such as translators, when
+	// the provider tells their roles.
+	Contributors []Contributor `json:"contributors,omitempty"`
+
 	// For Publication creation
 	ISBN10              string          `json:"isbn10,omitempty"`
 	ISBN13              string          `json:"isbn13,omitempty"`
 	Publisher           string          `json:"publisher,omitempty"`
 	PublishedYear       int             `json:"published_year,omitempty"`
 	Language            string          `json:"language,omitempty"`
+	Binding             Binding         `json:"binding,omitempty"`
+	PageCount           int             `json:"page_count,omitempty"`
+	DurationMinutes     int             `json:"duration_minutes,omitempty"` // Running time of audiobooks
+	Dimensions          Dimensions      `json:"dimensions,omitzero"`
 	GoogleBooksVolumeID string          `json:"google_books_volume_id,omitempty"`
+	OCLCNumber          string          `json:"oclc_number,omitempty"`
+	LCCN                string          `json:"lccn,omitempty"`
+	DOI                 string          `json:"doi,omitempty"`
+	ASIN                string          `json:"asin,omitempty"`
 	ThumbnailURL        string          `json:"thumbnail_url,omitempty"`
 	GoogleBooksData     json.RawMessage `json:"google_books_data,omitempty"` // Raw API response
 
+	// For translations, the title and ISO 639-1 language of the original
+	// when the provider records them
+	OriginalTitle    string `json:"original_title,omitempty"`
+	OriginalLanguage string `json:"original_language,omitempty"`
+
+	// For linking the work to its series, when the provider names one
+	Series       string  `json:"series,omitempty"`
+	SeriesVolume float64 `json:"series_volume,omitempty"` // Zero if unknown
+
+	// Categories or subjects as given by the provider, e.g. "Fiction /
+	// Classics". Normalized by the subject package when saved.
+	Subjects []string `json:"subjects,omitempty"`
+
+	// BISAC subject codes of the categories that have one, e.g.
+	// "FIC028000", so exports to retailers carry proper subject codes.
+	BISACCodes []string `json:"bisac_codes,omitempty"`
+
+	// Summary of the book as plain text, and the titles of its chapters or
+	// parts when the provider lists them.
+	Description     string   `json:"description,omitempty"`
+	TableOfContents []string `json:"table_of_contents,omitempty"`
+
+	// Whether and for how much the provider sells the book, and how its
+	// content can be read, when the provider tells; nil otherwise.
+	Sale   *SaleInfo   `json:"sale,omitempty"`
+	Access *AccessInfo `json:"access,omitempty"`
+
+	// Dewey and LC classification numbers, when the provider records them;
+	// nil otherwise.
+	Classification *Classification `json:"classification,omitempty"`
+
+	// Provenance
+	Provider     string          `json:"provider,omitempty"`      // Name of the BookFinder that produced the result
+	ProviderData json.RawMessage `json:"provider_data,omitempty"` // Raw response from providers other than Google Books
+
+	// Provider of each field of a result merged from several providers,
+	// keyed by JSON name, e.g. "publisher". Fields without an entry come
+	// from Provider.
+	Provenance map[string]string `json:"provenance,omitempty"`
+
+	// Values of other providers that a merge policy held back for review.
+	Conflicts []FieldConflict `json:"conflicts,omitempty"`
+
+	// Providers of a result merged from several that did not answer in
+	// time, so their values are missing from it.
+	TimedOut []string `json:"timed_out,omitempty"`
+
+	// Provider-specific details without a dedicated field, e.g. edition or
+	// list price, keyed by snake_case name. Lists are separated by "; ".
+	Metadata map[string]string `json:"metadata,omitempty"`
+
 	// Search metadata
 	Confidence float64    `json:"confidence"` // 0.0 to 1.0
 	SearchType SearchType `json:"search_type"`
 }
 
+// FieldProvider returns the name of the provider that supplied a field of
+// the result, given by JSON name.
+func (r *BookResult) FieldProvider(field string) string {
+	if p := r.Provenance[field]; p != "" {
+		return p
+	}
+	return r.Provider
+}
+
+// SaleInfo describes whether a provider sells a book, in the country it
+// placed the request in.
+type SaleInfo struct {
+	Country      string       `json:"country,omitempty"` // ISO 3166-1 alpha-2 code, e.g. "US"
+	Availability Availability `json:"availability,omitempty"`
+	Currency     string       `json:"currency,omitempty"`   // ISO 4217 code, e.g. "USD"
+	ListPrice    float64      `json:"list_price,omitempty"` // Zero if unknown
+}
+
+// AccessInfo describes the digital editions of a book a provider offers and
+// how much of it can be read online.
+type AccessInfo struct {
+	EPUBAvailable bool   `json:"epub_available,omitempty"`
+	PDFAvailable  bool   `json:"pdf_available,omitempty"`
+	PublicDomain  bool   `json:"public_domain,omitempty"`
+	FullView      bool   `json:"full_view,omitempty"`      // All pages can be read online
+	WebReaderURL  string `json:"web_reader_url,omitempty"` // Where the book can be read online
+	PreviewURL    string `json:"preview_url,omitempty"`    // Where the book can be previewed
+}
+
+// IsZero returns true if nothing is known about access to the book.
+func (a AccessInfo) IsZero() bool {
+	return a == AccessInfo{}
+}
+
+// Free returns true if the book is in the public domain or can be read in
+// full online, legally and for free.
+func (a *AccessInfo) Free() bool {
+	return a != nil && (a.PublicDomain || a.FullView)
+}
+
 // SearchType indicates how the search was performed
 type SearchType string
 
 const (
 	SearchTypeISBN         SearchType = "isbn"
+	SearchTypeLCCN         SearchType = "lccn"
+	SearchTypeDOI          SearchType = "doi"
+	SearchTypeASIN         SearchType = "asin"
+	SearchTypeProviderID   SearchType = "provider_id" // Looked up by the provider's own ID
 	SearchTypeTitleAuthor  SearchType = "title_author"
 	SearchTypeTitle        SearchType = "title"
 	SearchTypeGeneralQuery SearchType = "general"
+	SearchTypeLocal        SearchType = "local" // Matched in the full-text index of the catalog
 )
+
+// ParsedQuery represents a search query broken into fields and identifiers
+// by the query package. Providers build their native query syntax from it.
+type ParsedQuery struct {
+	// Free text left after taking out fields and identifiers. Quoted phrases
+	// keep their quotes.
+	Terms string
+
+	Title     string
+	Author    string
+	Publisher string
+	Year      int
+	Language  string // ISO 639-1 code
+
+	// Normalized identifiers in the order given.
+	ISBNs []string
+	LCCNs []string
+	DOIs  []string
+
+	// Amazon Standard Identification Numbers that are not ISBNs, such as
+	// those of Audible audiobooks. Only providers of audiobook metadata
+	// search them.
+	ASINs []string
+
+	// IDs of books on the sites of pasted links, looked up directly by the
+	// providers that know them.
+	GoogleBooksIDs []string // Google Books volume IDs, e.g. "iXn5U2IzVH0C"
+	OpenLibraryIDs []string // Open Library edition IDs, e.g. "OL7353617M"
+	GoodreadsIDs   []string // Goodreads book IDs, e.g. "4671"
+}
+
+// SearchType returns how the query identifies a book: by DOI, ISBN, LCCN or
+// ASIN in that order of precedence, otherwise by its title and author fields or
+// as a general query.
+func (q ParsedQuery) SearchType() SearchType {
+	switch {
+	case len(q.DOIs) > 0:
+		return SearchTypeDOI
+	case len(q.ISBNs) > 0:
+		return SearchTypeISBN
+	case len(q.LCCNs) > 0:
+		return SearchTypeLCCN
+	case len(q.ASINs) > 0:
+		return SearchTypeASIN
+	case q.Title != "" && q.Author != "":
+		return SearchTypeTitleAuthor
+	case q.Title != "" && q.Terms == "":
+		return SearchTypeTitle
+	}
+	return SearchTypeGeneralQuery
+}
+
+// HasIdentifiers returns true if the query has an ISBN, LCCN, DOI or the ID
+// of a book on a provider's site.
+func (q ParsedQuery) HasIdentifiers() bool {
+	return len(q.ISBNs) > 0 || len(q.LCCNs) > 0 || len(q.DOIs) > 0 ||
+		len(q.GoogleBooksIDs) > 0 || len(q.OpenLibraryIDs) > 0 || len(q.GoodreadsIDs) > 0
+}
+
+// HasText returns true if the query has free text, a title, an author or a
+// publisher to search besides its identifiers.
+func (q ParsedQuery) HasText() bool {
+	return q.Terms != "" || q.Title != "" || q.Author != "" || q.Publisher != ""
+}
+
+// WithoutIdentifiers returns a copy of the query without its identifiers,
+// for searching the rest of the query when the identifiers find nothing.
+func (q ParsedQuery) WithoutIdentifiers() ParsedQuery {
+	q.ISBNs, q.LCCNs, q.DOIs, q.ASINs = nil, nil, nil, nil
+	q.GoogleBooksIDs, q.OpenLibraryIDs, q.GoodreadsIDs = nil, nil, nil
+	return q
+}
+
+// ISBN returns the first ISBN of the query, or an empty string if it has
+// none.
+func (q ParsedQuery) ISBN() string {
+	if len(q.ISBNs) == 0 {
+		return ""
+	}
+	return q.ISBNs[0]
+}
+
+// SearchCacheEntry holds the results of a single search.
+type SearchCacheEntry struct {
+	Key       string       // Normalized query
+	Results   []BookResult // Results as returned by the BookFinder
+	CreatedAt time.Time    // When the results were fetched
+}
+
+// SearchCache stores search results so repeated queries don't need to hit
+// the provider. Implementations may evict entries at any time.
+type SearchCache interface {
+	// FindSearchCacheEntry retrieves the entry stored under key.
+	// Returns ENOTFOUND if there is no such entry.
+	FindSearchCacheEntry(ctx context.Context, key string) (*SearchCacheEntry, error)
+
+	// SetSearchCacheEntry stores entry under entry.Key, replacing any
+	// existing entry with the same key.
+	SetSearchCacheEntry(ctx context.Context, entry *SearchCacheEntry) error
+}
+
+// PrintType restricts a search to a kind of publication.
+type PrintType string
+
+const (
+	PrintTypeAll       PrintType = "all"
+	PrintTypeBooks     PrintType = "books"
+	PrintTypeMagazines PrintType = "magazines"
+)
+
+// Valid returns true if the print type is one of the known types.
+func (t PrintType) Valid() bool {
+	switch t {
+	case PrintTypeAll, PrintTypeBooks, PrintTypeMagazines:
+		return true
+	}
+	return false
+}
+
+// OrderBy is the order in which search results are returned.
+type OrderBy string
+
+const (
+	OrderByRelevance OrderBy = "relevance"
+	OrderByNewest    OrderBy = "newest"
+)
+
+// Valid returns true if the order is one of the known orders.
+func (o OrderBy) Valid() bool {
+	switch o {
+	case OrderByRelevance, OrderByNewest:
+		return true
+	}
+	return false
+}
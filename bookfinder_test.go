@@ -0,0 +1,92 @@
+package bookid_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/fwojciec/bookid"
+)
+
+func TestSearchOptions_Apply(t *testing.T) {
+	t.Parallel()
+
+	results := []bookid.BookResult{
+		{Title: "Dune", Confidence: 0.9, GoogleBooksData: json.RawMessage(`{}`)},
+		{Title: "Dune Messiah", Confidence: 0.4},
+		{Title: "Children of Dune", Confidence: 0.8, ProviderData: json.RawMessage(`{}`)},
+	}
+
+	t.Run("Default", func(t *testing.T) {
+		t.Parallel()
+		got := bookid.SearchOptions{}.Apply(results)
+		if len(got) != 3 {
+			t.Fatalf("len=%d, want 3", len(got))
+		} else if got[0].GoogleBooksData != nil || got[2].ProviderData != nil {
+			t.Fatal("expected raw data to be stripped")
+		} else if results[0].GoogleBooksData == nil {
+			t.Fatal("expected input to be left unmodified")
+		}
+	})
+
+	t.Run("Filter", func(t *testing.T) {
+		t.Parallel()
+		got := bookid.SearchOptions{MaxResults: 2, MinConfidence: 0.5, IncludeRaw: true}.Apply(results)
+		if len(got) != 2 {
+			t.Fatalf("len=%d, want 2", len(got))
+		} else if got[1].Title != "Children of Dune" {
+			t.Fatalf("Title=%q, want %q", got[1].Title, "Children of Dune")
+		} else if got[0].GoogleBooksData == nil {
+			t.Fatal("expected raw data to be kept")
+		}
+	})
+
+	t.Run("Binding", func(t *testing.T) {
+		t.Parallel()
+		got := bookid.SearchOptions{Binding: bookid.BindingEbook}.Apply([]bookid.BookResult{
+			{Title: "Dune", Binding: bookid.BindingPaperback},
+			{Title: "Dune", Binding: bookid.BindingEbook},
+			{Title: "Dune"},
+		})
+		if len(got) != 2 {
+			t.Fatalf("len=%d, want 2", len(got))
+		} else if got[0].Binding != bookid.BindingEbook || got[1].Binding != "" {
+			t.Fatalf("unexpected results: %+v", got)
+		}
+	})
+
+	t.Run("FreeOnly", func(t *testing.T) {
+		t.Parallel()
+		got := bookid.SearchOptions{FreeOnly: true}.Apply([]bookid.BookResult{
+			{Title: "Dune", Access: &bookid.AccessInfo{EPUBAvailable: true}},
+			{Title: "Pride and Prejudice", Access: &bookid.AccessInfo{PublicDomain: true}},
+			{Title: "Little Brother", Access: &bookid.AccessInfo{FullView: true}},
+			{Title: "Unknown"},
+		})
+		if len(got) != 2 {
+			t.Fatalf("len=%d, want 2", len(got))
+		} else if got[0].Title != "Pride and Prejudice" || got[1].Title != "Little Brother" {
+			t.Fatalf("unexpected results: %+v", got)
+		}
+	})
+}
+
+func TestSearchOptions_Validate(t *testing.T) {
+	t.Parallel()
+
+	if err := (bookid.SearchOptions{PrintType: bookid.PrintTypeBooks, OrderBy: bookid.OrderByNewest, Country: "pl"}).Validate(); err != nil {
+		t.Fatal(err)
+	}
+	for _, opts := range []bookid.SearchOptions{
+		{MaxResults: -1},
+		{StartIndex: -1},
+		{MinConfidence: 1.5},
+		{PrintType: "comics"},
+		{Binding: "scroll"},
+		{OrderBy: "oldest"},
+		{Country: "POL"},
+	} {
+		if code := bookid.ErrorCode(opts.Validate()); code != bookid.EINVALID {
+			t.Fatalf("ErrorCode(%+v)=%q, want %q", opts, code, bookid.EINVALID)
+		}
+	}
+}
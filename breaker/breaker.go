@@ -0,0 +1,162 @@
+// Package breaker implements a BookFinder decorator that stops searching a
+// provider after repeated failures and tries it again after a cool-down, so
+// a provider that is down fails fast instead of holding every search up
+// until its timeout.
+package breaker
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/fwojciec/bookid"
+)
+
+// Default breaker settings used by NewFinder.
+const (
+	DefaultThreshold = 5
+	DefaultCooldown  = time.Minute
+)
+
+// Ensure type implements interface.
+var _ bookid.BookFinder = (*Finder)(nil)
+
+// State is the state of a circuit breaker.
+type State string
+
+const (
+	// StateClosed passes searches to the provider.
+	StateClosed State = "closed"
+
+	// StateOpen fails searches without asking the provider until the
+	// cool-down has passed.
+	StateOpen State = "open"
+
+	// StateHalfOpen passes a single trial search to the provider, which
+	// closes the breaker if it succeeds and opens it again if it fails.
+	StateHalfOpen State = "half_open"
+)
+
+// Finder wraps a BookFinder, failing searches with EUNAVAILABLE without
+// asking it once Threshold searches in a row have failed. After Cooldown a
+// single search is let through to probe the provider.
+type Finder struct {
+	finder bookid.BookFinder
+
+	mu       sync.Mutex
+	state    State
+	failures int       // consecutive failures while closed
+	openedAt time.Time // time the breaker last opened
+
+	// Number of consecutive failures that opens the breaker. Zero or less
+	// never opens it.
+	Threshold int
+
+	// Time the breaker stays open before a trial search.
+	Cooldown time.Duration
+
+	// Reports whether an error counts as a failure of the provider.
+	// Defaults to IsFailure.
+	Failure func(err error) bool
+
+	// Receives debug logs of state changes. Defaults to discarding them.
+	Logger *slog.Logger
+
+	// Returns the current time. Defaults to time.Now().
+	// Can be mocked for tests.
+	Now func() time.Time
+}
+
+// NewFinder returns a Finder guarding finder with the default settings.
+func NewFinder(finder bookid.BookFinder) *Finder {
+	return &Finder{
+		finder:    finder,
+		state:     StateClosed,
+		Threshold: DefaultThreshold,
+		Cooldown:  DefaultCooldown,
+		Failure:   IsFailure,
+		Logger:    slog.New(slog.DiscardHandler),
+		Now:       time.Now,
+	}
+}
+
+// Search searches the wrapped finder unless the breaker is open, in which
+// case it returns EUNAVAILABLE at once.
+func (f *Finder) Search(ctx context.Context, query string, opts bookid.SearchOptions) ([]bookid.BookResult, error) {
+	if err := f.allow(ctx); err != nil {
+		return nil, err
+	}
+	results, err := f.finder.Search(ctx, query, opts)
+	f.record(ctx, err)
+	return results, err
+}
+
+// State returns the current state of the breaker. An open breaker whose
+// cool-down has passed is reported as half open.
+func (f *Finder) State() State {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.state == StateOpen && f.Now().Sub(f.openedAt) >= f.Cooldown {
+		return StateHalfOpen
+	}
+	return f.state
+}
+
+// allow returns an error if a search may not be passed to the provider. Once
+// the cool-down has passed, the first caller is let through as the trial and
+// the others keep failing until it finishes.
+func (f *Finder) allow(ctx context.Context) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch f.state {
+	case StateOpen:
+		if f.Now().Sub(f.openedAt) >= f.Cooldown {
+			f.state = StateHalfOpen
+			f.Logger.DebugContext(ctx, "circuit half open, trying provider")
+			return nil
+		}
+	case StateHalfOpen:
+	default:
+		return nil
+	}
+	return bookid.Errorf(bookid.EUNAVAILABLE, "Provider failed repeatedly and is skipped until %s.", f.openedAt.Add(f.Cooldown).Format(time.TimeOnly))
+}
+
+// record updates the state of the breaker with the outcome of a search.
+func (f *Finder) record(ctx context.Context, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.state == StateHalfOpen && errors.Is(err, context.Canceled) {
+		// The trial told nothing, so the next search tries again.
+		f.state = StateOpen
+		return
+	} else if err == nil || !f.Failure(err) {
+		if f.state == StateHalfOpen {
+			f.Logger.DebugContext(ctx, "circuit closed")
+		}
+		f.state, f.failures = StateClosed, 0
+		return
+	}
+
+	f.failures++
+	if f.state == StateHalfOpen || (f.Threshold > 0 && f.failures >= f.Threshold) {
+		f.state, f.openedAt, f.failures = StateOpen, f.Now(), 0
+		f.Logger.DebugContext(ctx, "circuit opened", "cooldown", f.Cooldown, "error", err)
+	}
+}
+
+// IsFailure returns true for errors that suggest the provider is unwell. A
+// query that is invalid or finds nothing and a search canceled by the caller
+// are not failures; timeouts are.
+func IsFailure(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	switch bookid.ErrorCode(err) {
+	case bookid.EINVALID, bookid.ENOTFOUND, bookid.ECONFLICT:
+		return false
+	}
+	return true
+}
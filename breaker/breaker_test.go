@@ -0,0 +1,130 @@
+package breaker_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/breaker"
+	"github.com/fwojciec/bookid/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newFinder returns a breaker opening after two failures for a minute, over
+// a finder failing with *err while counting its calls, at the time *now.
+func newFinder(err *error, calls *int, now *time.Time) *breaker.Finder {
+	f := breaker.NewFinder(&mock.BookFinder{
+		SearchFn: func(context.Context, string, bookid.SearchOptions) ([]bookid.BookResult, error) {
+			*calls++
+			if *err != nil {
+				return nil, *err
+			}
+			return []bookid.BookResult{{Title: "Dune"}}, nil
+		},
+	})
+	f.Threshold = 2
+	f.Cooldown = time.Minute
+	f.Now = func() time.Time { return *now }
+	return f
+}
+
+func TestFinder_Search(t *testing.T) {
+	t.Parallel()
+
+	t.Run("opens", func(t *testing.T) {
+		t.Parallel()
+		var err error = bookid.Errorf(bookid.EUNAVAILABLE, "Down.")
+		var calls int
+		now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		f := newFinder(&err, &calls, &now)
+		ctx := context.Background()
+
+		for range 2 {
+			_, searchErr := f.Search(ctx, "dune", bookid.SearchOptions{})
+			require.ErrorIs(t, searchErr, err)
+		}
+		assert.Equal(t, breaker.StateOpen, f.State())
+
+		_, searchErr := f.Search(ctx, "dune", bookid.SearchOptions{})
+		assert.Equal(t, bookid.EUNAVAILABLE, bookid.ErrorCode(searchErr))
+		assert.NotErrorIs(t, searchErr, err)
+		assert.Equal(t, 2, calls, "fails fast while open")
+	})
+
+	t.Run("recovers", func(t *testing.T) {
+		t.Parallel()
+		err := fmt.Errorf("searching: %w", context.DeadlineExceeded)
+		var calls int
+		now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		f := newFinder(&err, &calls, &now)
+		ctx := context.Background()
+
+		for range 2 {
+			_, _ = f.Search(ctx, "dune", bookid.SearchOptions{})
+		}
+		now = now.Add(time.Minute)
+		assert.Equal(t, breaker.StateHalfOpen, f.State())
+
+		// A failed trial opens the breaker for another cool-down.
+		_, searchErr := f.Search(ctx, "dune", bookid.SearchOptions{})
+		require.ErrorIs(t, searchErr, context.DeadlineExceeded)
+		assert.Equal(t, breaker.StateOpen, f.State())
+		assert.Equal(t, 3, calls)
+
+		now, err = now.Add(time.Minute), nil
+		results, searchErr := f.Search(ctx, "dune", bookid.SearchOptions{})
+		require.NoError(t, searchErr)
+		assert.Len(t, results, 1)
+		assert.Equal(t, breaker.StateClosed, f.State())
+	})
+
+	t.Run("resets", func(t *testing.T) {
+		t.Parallel()
+		var err error
+		var calls int
+		now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		f := newFinder(&err, &calls, &now)
+		ctx := context.Background()
+
+		// Failures must be consecutive to open the breaker.
+		for _, e := range []error{errors.New("reset"), nil, errors.New("reset")} {
+			err = e
+			_, _ = f.Search(ctx, "dune", bookid.SearchOptions{})
+		}
+		assert.Equal(t, breaker.StateClosed, f.State())
+	})
+
+	t.Run("not_failures", func(t *testing.T) {
+		t.Parallel()
+		var err error = bookid.Errorf(bookid.ENOTFOUND, "Not found.")
+		var calls int
+		now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		f := newFinder(&err, &calls, &now)
+		ctx := context.Background()
+
+		for range 3 {
+			_, _ = f.Search(ctx, "dune", bookid.SearchOptions{})
+		}
+		err = context.Canceled
+		for range 3 {
+			_, _ = f.Search(ctx, "dune", bookid.SearchOptions{})
+		}
+		assert.Equal(t, breaker.StateClosed, f.State())
+		assert.Equal(t, 6, calls)
+	})
+}
+
+func TestIsFailure(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, breaker.IsFailure(errors.New("connection refused")))
+	assert.True(t, breaker.IsFailure(bookid.Errorf(bookid.ERATELIMIT, "Slow down.")))
+	assert.True(t, breaker.IsFailure(context.DeadlineExceeded))
+	assert.False(t, breaker.IsFailure(nil))
+	assert.False(t, breaker.IsFailure(context.Canceled))
+	assert.False(t, breaker.IsFailure(bookid.Errorf(bookid.EINVALID, "Bad query.")))
+}
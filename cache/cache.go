@@ -0,0 +1,147 @@
+// Package cache implements a BookFinder decorator that memoizes search
+// results in a bookid.SearchCache.
+package cache
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/isbn"
+)
+
+// DefaultTTL is how long cached results are served before being refreshed.
+const DefaultTTL = 24 * time.Hour
+
+// Ensure type implements interface.
+var _ bookid.BookFinder = (*CachingFinder)(nil)
+
+// CachingFinder wraps a BookFinder and serves repeated queries from a cache.
+type CachingFinder struct {
+	finder bookid.BookFinder
+	store  bookid.SearchCache
+
+	hits, misses atomic.Int64
+
+	// How long results are served from the cache. Defaults to DefaultTTL.
+	TTL time.Duration
+
+	// Receives debug logs of cache hits and misses. Defaults to discarding
+	// them.
+	Logger *slog.Logger
+
+	// Returns the current time. Defaults to time.Now().
+	// Can be mocked for tests.
+	Now func() time.Time
+}
+
+// NewCachingFinder returns a CachingFinder serving finder results from store.
+func NewCachingFinder(finder bookid.BookFinder, store bookid.SearchCache) *CachingFinder {
+	return &CachingFinder{
+		finder: finder,
+		store:  store,
+		TTL:    DefaultTTL,
+		Logger: slog.New(slog.DiscardHandler),
+		Now:    time.Now,
+	}
+}
+
+// Search returns cached results for query if they are younger than the TTL,
+// otherwise it searches the wrapped finder and caches the results. Errors are
+// never cached, and a failing store degrades to an uncached search.
+func (f *CachingFinder) Search(ctx context.Context, query string, opts bookid.SearchOptions) ([]bookid.BookResult, error) {
+	key := Key(query, opts)
+
+	if entry, err := f.store.FindSearchCacheEntry(ctx, key); err == nil && f.Now().Sub(entry.CreatedAt) < f.TTL {
+		f.hits.Add(1)
+		f.Logger.DebugContext(ctx, "search cache hit", "key", key, "age", f.Now().Sub(entry.CreatedAt))
+		return entry.Results, nil
+	}
+	f.misses.Add(1)
+	f.Logger.DebugContext(ctx, "search cache miss", "key", key)
+
+	results, err := f.finder.Search(ctx, query, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// Results missing providers that timed out are not cached, so that the
+	// next search asks them again.
+	if slices.ContainsFunc(results, func(r bookid.BookResult) bool { return len(r.TimedOut) > 0 }) {
+		return results, nil
+	}
+	_ = f.store.SetSearchCacheEntry(ctx, &bookid.SearchCacheEntry{
+		Key:       key,
+		Results:   results,
+		CreatedAt: f.Now(),
+	})
+	return results, nil
+}
+
+// Stats represents the cache lookups of a CachingFinder.
+type Stats struct {
+	Hits   int64
+	Misses int64
+}
+
+// HitRatio returns the fraction of lookups served from the cache, or zero if
+// there were none.
+func (s Stats) HitRatio() float64 {
+	if s.Hits+s.Misses == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(s.Hits+s.Misses)
+}
+
+// Stats returns the number of searches served from the cache and passed to
+// the wrapped finder since f was created. Expired entries count as misses.
+func (f *CachingFinder) Stats() Stats {
+	return Stats{Hits: f.hits.Load(), Misses: f.misses.Load()}
+}
+
+// Key returns the cache key for query and opts. Queries differing only in
+// case and whitespace share a key, as do the ISBN-10 and ISBN-13 forms of a
+// book. Non-default options are appended so they are cached separately.
+func Key(query string, opts bookid.SearchOptions) string {
+	key := strings.ToLower(strings.Join(strings.Fields(query), " "))
+	if code, err := isbn.To13(strings.TrimPrefix(key, "isbn:")); err == nil {
+		key = "isbn:" + code
+	}
+
+	if opts.MaxResults > 0 {
+		key += "|max=" + strconv.Itoa(opts.MaxResults)
+	}
+	if opts.StartIndex > 0 {
+		key += "|start=" + strconv.Itoa(opts.StartIndex)
+	}
+	if opts.Language != "" {
+		key += "|lang=" + opts.Language
+	}
+	if opts.Country != "" {
+		key += "|country=" + strings.ToUpper(opts.Country)
+	}
+	if opts.PrintType != "" {
+		key += "|type=" + string(opts.PrintType)
+	}
+	if opts.Binding != "" {
+		key += "|binding=" + string(opts.Binding)
+	}
+	if opts.FreeOnly {
+		key += "|free"
+	}
+	if opts.OrderBy != "" {
+		key += "|order=" + string(opts.OrderBy)
+	}
+	if opts.MinConfidence > 0 {
+		key += "|min=" + strconv.FormatFloat(opts.MinConfidence, 'g', -1, 64)
+	}
+	if opts.IncludeRaw {
+		key += "|raw"
+	}
+	return key
+}
@@ -0,0 +1,153 @@
+package cache_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/cache"
+	"github.com/fwojciec/bookid/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingFinder returns one result titled after the query and counts calls.
+type countingFinder struct {
+	calls int
+	err   error
+}
+
+func (f *countingFinder) Search(_ context.Context, query string, _ bookid.SearchOptions) ([]bookid.BookResult, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	return []bookid.BookResult{{Title: query}}, nil
+}
+
+func TestCachingFinder_Search(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Hit", func(t *testing.T) {
+		t.Parallel()
+		finder := &countingFinder{}
+		f := cache.NewCachingFinder(finder, cache.NewMemoryStore(0))
+		ctx := context.Background()
+
+		first, err := f.Search(ctx, "Dune", bookid.SearchOptions{})
+		require.NoError(t, err)
+		second, err := f.Search(ctx, "  dune ", bookid.SearchOptions{})
+		require.NoError(t, err)
+
+		assert.Equal(t, 1, finder.calls)
+		assert.Equal(t, first, second)
+		assert.Equal(t, cache.Stats{Hits: 1, Misses: 1}, f.Stats())
+		assert.InDelta(t, 0.5, f.Stats().HitRatio(), 0.001)
+	})
+
+	t.Run("Logger", func(t *testing.T) {
+		t.Parallel()
+		var buf bytes.Buffer
+		f := cache.NewCachingFinder(&countingFinder{}, cache.NewMemoryStore(0))
+		f.Logger = slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+		ctx := context.Background()
+
+		_, err := f.Search(ctx, "Dune", bookid.SearchOptions{})
+		require.NoError(t, err)
+		assert.Contains(t, buf.String(), `msg="search cache miss"`)
+		_, err = f.Search(ctx, "Dune", bookid.SearchOptions{})
+		require.NoError(t, err)
+		assert.Contains(t, buf.String(), `msg="search cache hit"`)
+	})
+
+	t.Run("Expired", func(t *testing.T) {
+		t.Parallel()
+		finder := &countingFinder{}
+		f := cache.NewCachingFinder(finder, cache.NewMemoryStore(0))
+		f.TTL = time.Hour
+		now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		f.Now = func() time.Time { return now }
+		ctx := context.Background()
+
+		_, err := f.Search(ctx, "Dune", bookid.SearchOptions{})
+		require.NoError(t, err)
+		now = now.Add(time.Hour)
+		_, err = f.Search(ctx, "Dune", bookid.SearchOptions{})
+		require.NoError(t, err)
+
+		assert.Equal(t, 2, finder.calls)
+	})
+
+	t.Run("ErrNotCached", func(t *testing.T) {
+		t.Parallel()
+		finder := &countingFinder{err: errors.New("quota exceeded")}
+		f := cache.NewCachingFinder(finder, cache.NewMemoryStore(0))
+		ctx := context.Background()
+
+		_, err := f.Search(ctx, "Dune", bookid.SearchOptions{})
+		require.Error(t, err)
+		_, err = f.Search(ctx, "Dune", bookid.SearchOptions{})
+		require.Error(t, err)
+
+		assert.Equal(t, 2, finder.calls)
+	})
+
+	t.Run("PartialNotCached", func(t *testing.T) {
+		t.Parallel()
+		var calls int
+		finder := &mock.BookFinder{SearchFn: func(context.Context, string, bookid.SearchOptions) ([]bookid.BookResult, error) {
+			calls++
+			return []bookid.BookResult{{Title: "Dune", TimedOut: []string{"worldcat"}}}, nil
+		}}
+		f := cache.NewCachingFinder(finder, cache.NewMemoryStore(0))
+		ctx := context.Background()
+
+		for range 2 {
+			_, err := f.Search(ctx, "Dune", bookid.SearchOptions{})
+			require.NoError(t, err)
+		}
+		assert.Equal(t, 2, calls)
+	})
+}
+
+func TestKey(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "dune frank herbert", cache.Key("  Dune   Frank HERBERT ", bookid.SearchOptions{}))
+	assert.Equal(t, "isbn:9780743273565", cache.Key("0-7432-7356-7", bookid.SearchOptions{}))
+	assert.Equal(t, "isbn:9780743273565", cache.Key("ISBN:9780743273565", bookid.SearchOptions{}))
+	assert.Equal(t, "dune|max=5|min=0.8|raw", cache.Key("Dune", bookid.SearchOptions{MaxResults: 5, MinConfidence: 0.8, IncludeRaw: true}))
+	assert.Equal(t, "dune|lang=pl|country=PL", cache.Key("Dune", bookid.SearchOptions{Language: "pl", Country: "pl"}))
+}
+
+func TestMemoryStore(t *testing.T) {
+	t.Parallel()
+
+	t.Run("NotFound", func(t *testing.T) {
+		t.Parallel()
+		_, err := cache.NewMemoryStore(0).FindSearchCacheEntry(context.Background(), "dune")
+		assert.Equal(t, bookid.ENOTFOUND, bookid.ErrorCode(err))
+	})
+
+	t.Run("EvictLeastRecentlyUsed", func(t *testing.T) {
+		t.Parallel()
+		s := cache.NewMemoryStore(2)
+		ctx := context.Background()
+
+		require.NoError(t, s.SetSearchCacheEntry(ctx, &bookid.SearchCacheEntry{Key: "a"}))
+		require.NoError(t, s.SetSearchCacheEntry(ctx, &bookid.SearchCacheEntry{Key: "b"}))
+		_, err := s.FindSearchCacheEntry(ctx, "a")
+		require.NoError(t, err)
+		require.NoError(t, s.SetSearchCacheEntry(ctx, &bookid.SearchCacheEntry{Key: "c"}))
+
+		_, err = s.FindSearchCacheEntry(ctx, "b")
+		assert.Equal(t, bookid.ENOTFOUND, bookid.ErrorCode(err))
+		_, err = s.FindSearchCacheEntry(ctx, "a")
+		require.NoError(t, err)
+		_, err = s.FindSearchCacheEntry(ctx, "c")
+		require.NoError(t, err)
+	})
+}
@@ -0,0 +1,75 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+
+	"github.com/fwojciec/bookid"
+)
+
+// Ensure type implements interface.
+var _ bookid.SearchCache = (*MemoryStore)(nil)
+
+// MemoryStore is an in-memory SearchCache that evicts the least recently
+// used entry once it holds maxEntries entries. It is safe for concurrent use.
+type MemoryStore struct {
+	mu         sync.Mutex
+	maxEntries int
+	order      *list.List // of *bookid.SearchCacheEntry, most recently used first
+	entries    map[string]*list.Element
+}
+
+// NewMemoryStore returns a MemoryStore holding at most maxEntries entries.
+// A maxEntries of zero or less means no limit.
+func NewMemoryStore(maxEntries int) *MemoryStore {
+	return &MemoryStore{
+		maxEntries: maxEntries,
+		order:      list.New(),
+		entries:    make(map[string]*list.Element),
+	}
+}
+
+// FindSearchCacheEntry retrieves the entry stored under key.
+// Returns ENOTFOUND if there is no such entry.
+func (s *MemoryStore) FindSearchCacheEntry(_ context.Context, key string) (*bookid.SearchCacheEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	elem, ok := s.entries[key]
+	if !ok {
+		return nil, bookid.Errorf(bookid.ENOTFOUND, "Search cache entry not found.")
+	}
+	s.order.MoveToFront(elem)
+
+	entry := *entryOf(elem)
+	return &entry, nil
+}
+
+// SetSearchCacheEntry stores entry under entry.Key, evicting the least
+// recently used entry if the store is full.
+func (s *MemoryStore) SetSearchCacheEntry(_ context.Context, entry *bookid.SearchCacheEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	other := *entry
+	if elem, ok := s.entries[entry.Key]; ok {
+		elem.Value = &other
+		s.order.MoveToFront(elem)
+		return nil
+	}
+	s.entries[entry.Key] = s.order.PushFront(&other)
+
+	for s.maxEntries > 0 && s.order.Len() > s.maxEntries {
+		oldest := s.order.Back()
+		s.order.Remove(oldest)
+		delete(s.entries, entryOf(oldest).Key)
+	}
+	return nil
+}
+
+// entryOf returns the entry held by a list element.
+func entryOf(elem *list.Element) *bookid.SearchCacheEntry {
+	entry, _ := elem.Value.(*bookid.SearchCacheEntry)
+	return entry
+}
@@ -0,0 +1,110 @@
+// Package catalogsync syncs two catalogs both ways, such as those of a laptop
+// and a home server. The changes of each catalog since they were last synced
+// are read before either is written, then applied to the other, so a row
+// changed in only one catalog is copied to the other and a row changed in
+// both is a conflict resolved the same way in each.
+package catalogsync
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/fwojciec/bookid"
+)
+
+// Prefer represents which catalog wins the conflicts of a sync.
+type Prefer string
+
+// Conflict preferences.
+const (
+	PreferNewer  Prefer = "newer"  // The row changed last wins
+	PreferLocal  Prefer = "local"  // The local catalog's row wins
+	PreferRemote Prefer = "remote" // The remote catalog's row wins
+)
+
+// Valid returns true if the preference is known.
+func (p Prefer) Valid() bool {
+	switch p {
+	case PreferNewer, PreferLocal, PreferRemote:
+		return true
+	}
+	return false
+}
+
+// Result represents the outcome of a sync.
+type Result struct {
+	Local    *bookid.SyncReport `json:"local"`  // Changes made to the local catalog
+	Remote   *bookid.SyncReport `json:"remote"` // Changes made to the remote catalog
+	SyncedAt time.Time          `json:"synced_at"`
+}
+
+// Syncer syncs a local catalog with a remote one.
+type Syncer struct {
+	Local  bookid.SyncService
+	Remote bookid.SyncService
+
+	// Which catalog wins conflicts. Defaults to PreferNewer, which resolves
+	// them by the times rows changed and so depends on the clocks of the two
+	// machines roughly agreeing.
+	Prefer Prefer
+
+	// Returns the current time. Defaults to time.Now().
+	Now func() time.Time
+}
+
+// NewSyncer returns a new instance of Syncer.
+func NewSyncer(local, remote bookid.SyncService) *Syncer {
+	return &Syncer{
+		Local:  local,
+		Remote: remote,
+		Prefer: PreferNewer,
+		Now:    time.Now,
+	}
+}
+
+// Sync exchanges the changes the catalogs made since they were last synced
+// at since, the zero time if they never were. The time it returns is the
+// since of the next sync; it is taken before any changes are read, so rows
+// changed during the sync are sent again next time rather than missed.
+func (s *Syncer) Sync(ctx context.Context, since time.Time) (*Result, error) {
+	if s.Prefer != "" && !s.Prefer.Valid() {
+		return nil, bookid.Errorf(bookid.EINVALID, "Invalid sync preference %q.", s.Prefer)
+	}
+	now := time.Now
+	if s.Now != nil {
+		now = s.Now
+	}
+	// Catalogs record times to the second, so rows changed later in the
+	// current second are only after the previous one.
+	syncedAt := now().UTC().Truncate(time.Second).Add(-time.Second)
+
+	localChanges, err := s.Local.FindChanges(ctx, since)
+	if err != nil {
+		return nil, fmt.Errorf("find local changes: %w", err)
+	}
+	remoteChanges, err := s.Remote.FindChanges(ctx, since)
+	if err != nil {
+		return nil, fmt.Errorf("find remote changes: %w", err)
+	}
+
+	// Ties under PreferNewer go to the remote row on both sides, so the
+	// catalogs end up the same.
+	localOpts := bookid.SyncOptions{Since: since, Rule: bookid.SyncRuleNewer, IncomingWinsTies: true}
+	remoteOpts := bookid.SyncOptions{Since: since, Rule: bookid.SyncRuleNewer}
+	switch s.Prefer {
+	case PreferLocal:
+		localOpts.Rule, remoteOpts.Rule = bookid.SyncRuleLocal, bookid.SyncRuleIncoming
+	case PreferRemote:
+		localOpts.Rule, remoteOpts.Rule = bookid.SyncRuleIncoming, bookid.SyncRuleLocal
+	}
+
+	result := &Result{SyncedAt: syncedAt}
+	if result.Remote, err = s.Remote.ApplyChanges(ctx, localChanges, remoteOpts); err != nil {
+		return nil, fmt.Errorf("apply local changes to remote: %w", err)
+	}
+	if result.Local, err = s.Local.ApplyChanges(ctx, remoteChanges, localOpts); err != nil {
+		return nil, fmt.Errorf("apply remote changes to local: %w", err)
+	}
+	return result, nil
+}
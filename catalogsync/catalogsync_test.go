@@ -0,0 +1,104 @@
+package catalogsync_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/catalogsync"
+	"github.com/fwojciec/bookid/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// service returns a mock catalog with changes, recording the changes and
+// options applied to it.
+func service(changes *bookid.SyncChanges, applied *[]*bookid.SyncChanges, opts *bookid.SyncOptions) *mock.SyncService {
+	return &mock.SyncService{
+		FindChangesFn: func(context.Context, time.Time) (*bookid.SyncChanges, error) {
+			return changes, nil
+		},
+		ApplyChangesFn: func(_ context.Context, c *bookid.SyncChanges, o bookid.SyncOptions) (*bookid.SyncReport, error) {
+			*applied = append(*applied, c)
+			*opts = o
+			return &bookid.SyncReport{Created: len(c.Records)}, nil
+		},
+	}
+}
+
+func TestSyncer_Sync(t *testing.T) {
+	t.Parallel()
+
+	since := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := since.Add(time.Hour + 500*time.Millisecond)
+
+	t.Run("Exchange", func(t *testing.T) {
+		t.Parallel()
+		localChanges := &bookid.SyncChanges{Records: []*bookid.SyncRecord{{Work: &bookid.Work{UID: "a"}}}}
+		remoteChanges := &bookid.SyncChanges{Records: []*bookid.SyncRecord{{Work: &bookid.Work{UID: "b"}}, {Work: &bookid.Work{UID: "c"}}}}
+		var localApplied, remoteApplied []*bookid.SyncChanges
+		var localOpts, remoteOpts bookid.SyncOptions
+
+		s := catalogsync.NewSyncer(
+			service(localChanges, &localApplied, &localOpts),
+			service(remoteChanges, &remoteApplied, &remoteOpts),
+		)
+		s.Now = func() time.Time { return now }
+		result, err := s.Sync(context.Background(), since)
+		require.NoError(t, err)
+
+		assert.Equal(t, []*bookid.SyncChanges{remoteChanges}, localApplied)
+		assert.Equal(t, []*bookid.SyncChanges{localChanges}, remoteApplied)
+		assert.Equal(t, 2, result.Local.Created)
+		assert.Equal(t, 1, result.Remote.Created)
+		assert.Equal(t, since.Add(time.Hour-time.Second), result.SyncedAt)
+
+		// Both catalogs resolve ties in favor of the remote row.
+		assert.Equal(t, bookid.SyncOptions{Since: since, Rule: bookid.SyncRuleNewer, IncomingWinsTies: true}, localOpts)
+		assert.Equal(t, bookid.SyncOptions{Since: since, Rule: bookid.SyncRuleNewer}, remoteOpts)
+	})
+
+	t.Run("Prefer", func(t *testing.T) {
+		t.Parallel()
+		for prefer, want := range map[catalogsync.Prefer][2]bookid.SyncRule{
+			catalogsync.PreferLocal:  {bookid.SyncRuleLocal, bookid.SyncRuleIncoming},
+			catalogsync.PreferRemote: {bookid.SyncRuleIncoming, bookid.SyncRuleLocal},
+		} {
+			var applied []*bookid.SyncChanges
+			var localOpts, remoteOpts bookid.SyncOptions
+			s := catalogsync.NewSyncer(
+				service(&bookid.SyncChanges{}, &applied, &localOpts),
+				service(&bookid.SyncChanges{}, &applied, &remoteOpts),
+			)
+			s.Prefer = prefer
+			_, err := s.Sync(context.Background(), since)
+			require.NoError(t, err)
+			assert.Equal(t, want, [2]bookid.SyncRule{localOpts.Rule, remoteOpts.Rule}, prefer)
+		}
+	})
+
+	t.Run("ErrInvalidPrefer", func(t *testing.T) {
+		t.Parallel()
+		s := catalogsync.NewSyncer(&mock.SyncService{}, &mock.SyncService{})
+		s.Prefer = "mine"
+		_, err := s.Sync(context.Background(), since)
+		assert.Equal(t, bookid.EINVALID, bookid.ErrorCode(err))
+	})
+
+	t.Run("ErrRemoteUnreachable", func(t *testing.T) {
+		t.Parallel()
+		var applied []*bookid.SyncChanges
+		var opts bookid.SyncOptions
+		s := catalogsync.NewSyncer(
+			service(&bookid.SyncChanges{}, &applied, &opts),
+			&mock.SyncService{FindChangesFn: func(context.Context, time.Time) (*bookid.SyncChanges, error) {
+				return nil, errors.New("connection refused")
+			}},
+		)
+		_, err := s.Sync(context.Background(), since)
+		require.Error(t, err)
+		assert.Empty(t, applied, "nothing is applied unless both catalogs were read")
+	})
+}
@@ -0,0 +1,248 @@
+// Package citation formats books as bibliography entries for reference
+// managers: BibTeX for LaTeX, RIS for Zotero, EndNote and Mendeley, and
+// CSL-JSON for citeproc processors such as pandoc.
+package citation
+
+import (
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/fwojciec/bookid"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Citation styles supported by Write.
+const (
+	StyleBibTeX  = "bibtex"
+	StyleRIS     = "ris"
+	StyleCSLJSON = "csl-json"
+)
+
+// Styles returns the names of the supported citation styles.
+func Styles() []string {
+	return []string{StyleBibTeX, StyleRIS, StyleCSLJSON}
+}
+
+// Entry is a book to be cited.
+type Entry struct {
+	Key            string   // Citation key, generated by Write if empty
+	Title          string   // Including the subtitle after a colon
+	Authors        []string // In display order, e.g. "F. Scott Fitzgerald"
+	Editors        []string // In display order
+	Translators    []string // In display order
+	Edition        string
+	Publisher      string
+	PublisherPlace string
+	Year           int
+	ISBN           string
+	DOI            string
+	Language       string // ISO 639-1
+}
+
+// FromResult returns the entry for a search result. Editors and translators
+// are taken from the result's contributors, edition and place of publication
+// from provider metadata when available. Editors credited as the authors of
+// an edited volume are cited as editors only.
+func FromResult(r bookid.BookResult) *Entry {
+	e := &Entry{
+		Title:          r.Title,
+		Authors:        r.Authors,
+		Edition:        r.Metadata["edition"],
+		Publisher:      r.Publisher,
+		PublisherPlace: r.Metadata["publication_place"],
+		Year:           r.PublishedYear,
+		ISBN:           r.ISBN13,
+		DOI:            r.DOI,
+		Language:       r.Language,
+	}
+	if e.ISBN == "" {
+		e.ISBN = r.ISBN10
+	}
+	for _, c := range r.Contributors {
+		e.addContributor(c.Name, c.Role)
+	}
+	if len(e.Editors) > 0 && slices.Equal(e.Authors, e.Editors) {
+		e.Authors = nil
+	}
+	return e
+}
+
+// FromPublication returns the entry for a cataloged publication of work.
+// Authors are the work's linked authors; if there are none the work's
+// credited author is used. Linked editors and translators are cited as such.
+func FromPublication(work *bookid.Work, authors []*bookid.Author, pub *bookid.Publication) *Entry {
+	e := &Entry{
+		Title:     work.Title,
+		Publisher: pub.Publisher,
+		Year:      pub.PublishedYear,
+		ISBN:      pub.ISBN13,
+		DOI:       pub.DOI,
+		Language:  pub.Language,
+	}
+	for _, a := range authors {
+		e.addContributor(a.Name, a.Role)
+	}
+	if len(authors) == 0 && work.Author != "" {
+		e.Authors = []string{work.Author}
+	}
+	if e.ISBN == "" {
+		e.ISBN = pub.ISBN10
+	}
+	return e
+}
+
+// addContributor adds a name to the entry's list for role. Illustrators and
+// narrators are not cited.
+func (e *Entry) addContributor(name string, role bookid.ContributorRole) {
+	switch role {
+	case "", bookid.ContributorRoleAuthor:
+		e.Authors = append(e.Authors, name)
+	case bookid.ContributorRoleEditor:
+		e.Editors = append(e.Editors, name)
+	case bookid.ContributorRoleTranslator:
+		e.Translators = append(e.Translators, name)
+	}
+}
+
+// Key returns the citation key of the entry: the first author's family name
+// and the year, e.g. "fitzgerald2004". Entries without authors use the first
+// editor, or failing that the first word of the title.
+func Key(e *Entry) string {
+	var base string
+	if len(e.Authors) > 0 {
+		base = keyWord(familyName(e.Authors[0]))
+	} else if len(e.Editors) > 0 {
+		base = keyWord(familyName(e.Editors[0]))
+	}
+	if base == "" {
+		for _, w := range strings.Fields(e.Title) {
+			if base = keyWord(w); base != "" && !isArticle(base) {
+				break
+			}
+		}
+	}
+	if base == "" {
+		base = "anon"
+	}
+	if e.Year != 0 {
+		base += strconv.Itoa(e.Year)
+	}
+	return base
+}
+
+// Write writes entries to w in the given style: BibTeX and RIS records are
+// separated by blank lines, CSL-JSON items form a single array. Missing keys
+// are generated with Key; entries that would share a key get suffixes "a",
+// "b" and so on. Returns EINVALID for unknown styles.
+func Write(w io.Writer, style string, entries []*Entry) error {
+	var format func(*Entry) string
+	switch style {
+	case StyleBibTeX:
+		format = BibTeX
+	case StyleRIS:
+		format = RIS
+	case StyleCSLJSON:
+		assignKeys(entries)
+		return writeCSLJSON(w, entries)
+	default:
+		return bookid.Errorf(bookid.EINVALID, "Unknown citation style %q, must be one of: %s.", style, strings.Join(Styles(), ", "))
+	}
+
+	assignKeys(entries)
+	for i, e := range entries {
+		if i > 0 {
+			if _, err := io.WriteString(w, "\n"); err != nil {
+				return err
+			}
+		}
+		if _, err := io.WriteString(w, format(e)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// assignKeys generates missing keys and disambiguates duplicates.
+func assignKeys(entries []*Entry) {
+	counts := make(map[string]int)
+	for _, e := range entries {
+		if e.Key == "" {
+			e.Key = Key(e)
+		}
+		counts[e.Key]++
+	}
+
+	seen := make(map[string]int)
+	for _, e := range entries {
+		if counts[e.Key] < 2 {
+			continue
+		}
+		key := e.Key
+		e.Key += string(rune('a' + seen[key]%26))
+		seen[key]++
+	}
+}
+
+// familyName returns the family name of a name in display order, or the part
+// before the comma of an inverted name.
+func familyName(name string) string {
+	if family, _, ok := strings.Cut(name, ","); ok {
+		return family
+	}
+	fields := strings.Fields(name)
+	if len(fields) == 0 {
+		return ""
+	}
+	return fields[len(fields)-1]
+}
+
+// invertName converts a name in display order such as "F. Scott Fitzgerald"
+// to "Fitzgerald, F. Scott", the form reference managers parse reliably.
+func invertName(name string) string {
+	name = strings.TrimSpace(name)
+	if strings.Contains(name, ",") {
+		return name
+	}
+	i := strings.LastIndexFunc(name, unicode.IsSpace)
+	if i < 0 {
+		return name
+	}
+	return name[i+1:] + ", " + strings.TrimSpace(name[:i])
+}
+
+// keyWord lowercases s and reduces it to ASCII letters and digits, stripping
+// diacritics, so "Lem" and "Żuławski" become "lem" and "zulawski".
+func keyWord(s string) string {
+	var b strings.Builder
+	for _, r := range norm.NFKD.String(s) {
+		r = unicode.ToLower(foldLetter(r))
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// foldLetter maps letters that have no Unicode decomposition to their closest
+// ASCII equivalent.
+func foldLetter(r rune) rune {
+	switch r {
+	case 'ł', 'Ł':
+		return 'l'
+	case 'ø', 'Ø':
+		return 'o'
+	case 'đ', 'Đ':
+		return 'd'
+	case 'ß':
+		return 's'
+	}
+	return r
+}
+
+// isArticle reports whether a lowercased word is an English article.
+func isArticle(w string) bool {
+	return w == "the" || w == "a" || w == "an"
+}
@@ -0,0 +1,178 @@
+package citation_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/citation"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKey(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		entry citation.Entry
+		want  string
+	}{
+		{"author_year", citation.Entry{Authors: []string{"F. Scott Fitzgerald"}, Year: 2004}, "fitzgerald2004"},
+		{"diacritics", citation.Entry{Authors: []string{"Jerzy Żuławski"}, Year: 1903}, "zulawski1903"},
+		{"inverted", citation.Entry{Authors: []string{"Lem, Stanisław"}}, "lem"},
+		{"title", citation.Entry{Title: "The Federalist Papers", Year: 1788}, "federalist1788"},
+		{"anonymous", citation.Entry{}, "anon"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.want, citation.Key(&tt.entry))
+		})
+	}
+}
+
+func TestFromResult(t *testing.T) {
+	t.Parallel()
+
+	e := citation.FromResult(bookid.BookResult{
+		Title:         "The Great Gatsby",
+		Authors:       []string{"F. Scott Fitzgerald"},
+		ISBN10:        "0743273567",
+		PublishedYear: 2004,
+		Metadata:      map[string]string{"edition": "1st Scribner trade pbk. ed", "publication_place": "New York"},
+	})
+	assert.Equal(t, "0743273567", e.ISBN, "falls back to the ISBN-10")
+	assert.Equal(t, "1st Scribner trade pbk. ed", e.Edition)
+	assert.Equal(t, "New York", e.PublisherPlace)
+}
+
+func TestFromPublication(t *testing.T) {
+	t.Parallel()
+
+	e := citation.FromPublication(
+		&bookid.Work{Title: "Solaris", Author: "Stanisław Lem"},
+		nil,
+		&bookid.Publication{ISBN13: "9780156027601", Publisher: "Harcourt", PublishedYear: 2002, Language: "en"},
+	)
+	assert.Equal(t, []string{"Stanisław Lem"}, e.Authors)
+	assert.Equal(t, "9780156027601", e.ISBN)
+	assert.Equal(t, "lem2002", citation.Key(e))
+}
+
+func newEntries() []*citation.Entry {
+	return []*citation.Entry{
+		{
+			Title:          "The Great Gatsby",
+			Authors:        []string{"F. Scott Fitzgerald", "Matthew J. Bruccoli"},
+			Publisher:      "Scribner",
+			PublisherPlace: "New York",
+			Year:           2004,
+			ISBN:           "9780743273565",
+			Language:       "en",
+		},
+		{
+			Title:   "Tender Is the Night & Other Stories",
+			Authors: []string{"F. Scott Fitzgerald"},
+			Year:    2004,
+			DOI:     "10.5555/tender_night",
+		},
+	}
+}
+
+func TestWrite_BibTeX(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	require.NoError(t, citation.Write(&buf, citation.StyleBibTeX, newEntries()))
+	assert.Equal(t, `@book{fitzgerald2004a,
+  author = {Fitzgerald, F. Scott and Bruccoli, Matthew J.},
+  title = {{The Great Gatsby}},
+  publisher = {Scribner},
+  address = {New York},
+  year = {2004},
+  isbn = {9780743273565},
+  language = {english},
+}
+
+@book{fitzgerald2004b,
+  author = {Fitzgerald, F. Scott},
+  title = {{Tender Is the Night \& Other Stories}},
+  year = {2004},
+  doi = {10.5555/tender_night},
+}
+`, buf.String())
+}
+
+func TestWrite_RIS(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	require.NoError(t, citation.Write(&buf, citation.StyleRIS, newEntries()[:1]))
+	assert.Equal(t, `TY  - BOOK
+ID  - fitzgerald2004
+AU  - Fitzgerald, F. Scott
+AU  - Bruccoli, Matthew J.
+TI  - The Great Gatsby
+PB  - Scribner
+CY  - New York
+PY  - 2004
+SN  - 9780743273565
+LA  - English
+ER  - 
+`, buf.String())
+}
+
+func TestWrite_UnknownStyle(t *testing.T) {
+	t.Parallel()
+
+	err := citation.Write(&bytes.Buffer{}, "apa", nil)
+	assert.Equal(t, bookid.EINVALID, bookid.ErrorCode(err))
+}
+
+func TestWrite_CSLJSON(t *testing.T) {
+	t.Parallel()
+
+	edited := citation.FromResult(bookid.BookResult{
+		Title:         "Handbook of Learning",
+		Authors:       []string{"Alan Editor"},
+		Publisher:     "Springer",
+		PublishedYear: 2019,
+		Contributors: []bookid.Contributor{
+			{Name: "Alan Editor", Role: bookid.ContributorRoleEditor},
+			{Name: "Tina Translator", Role: bookid.ContributorRoleTranslator},
+		},
+		Metadata: map[string]string{"edition": "2nd ed."},
+	})
+	assert.Empty(t, edited.Authors, "editors of an edited volume are not its authors")
+	assert.Equal(t, []string{"Alan Editor"}, edited.Editors)
+
+	var buf bytes.Buffer
+	require.NoError(t, citation.Write(&buf, citation.StyleCSLJSON, append(newEntries()[:1], edited)))
+	assert.JSONEq(t, `[
+		{
+			"id": "fitzgerald2004",
+			"type": "book",
+			"title": "The Great Gatsby",
+			"author": [
+				{"family": "Fitzgerald", "given": "F. Scott"},
+				{"family": "Bruccoli", "given": "Matthew J."}
+			],
+			"publisher": "Scribner",
+			"publisher-place": "New York",
+			"issued": {"date-parts": [[2004]]},
+			"ISBN": "9780743273565",
+			"language": "en"
+		},
+		{
+			"id": "editor2019",
+			"type": "book",
+			"title": "Handbook of Learning",
+			"editor": [{"family": "Editor", "given": "Alan"}],
+			"translator": [{"family": "Translator", "given": "Tina"}],
+			"edition": "2nd ed.",
+			"publisher": "Springer",
+			"issued": {"date-parts": [[2019]]}
+		}
+	]`, buf.String())
+}
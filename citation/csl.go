@@ -0,0 +1,94 @@
+package citation
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+)
+
+// cslItem is a bibliographic item in the CSL-JSON schema used by citeproc
+// processors such as Zotero and pandoc.
+type cslItem struct {
+	ID             string    `json:"id"`
+	Type           string    `json:"type"`
+	Title          string    `json:"title,omitempty"`
+	Author         []cslName `json:"author,omitempty"`
+	Editor         []cslName `json:"editor,omitempty"`
+	Translator     []cslName `json:"translator,omitempty"`
+	Edition        string    `json:"edition,omitempty"`
+	Publisher      string    `json:"publisher,omitempty"`
+	PublisherPlace string    `json:"publisher-place,omitempty"`
+	Issued         *cslDate  `json:"issued,omitempty"`
+	ISBN           string    `json:"ISBN,omitempty"`
+	DOI            string    `json:"DOI,omitempty"`
+	Language       string    `json:"language,omitempty"`
+}
+
+// cslName is a person's name split into parts, or a literal name for
+// organizations and single-word names.
+type cslName struct {
+	Family  string `json:"family,omitempty"`
+	Given   string `json:"given,omitempty"`
+	Literal string `json:"literal,omitempty"`
+}
+
+// cslDate is a date as a list of date parts, of which we only know the year.
+type cslDate struct {
+	DateParts [][]int `json:"date-parts"`
+}
+
+// writeCSLJSON writes entries as a pretty-printed CSL-JSON array.
+func writeCSLJSON(w io.Writer, entries []*Entry) error {
+	items := make([]cslItem, 0, len(entries))
+	for _, e := range entries {
+		items = append(items, newCSLItem(e))
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(items); err != nil {
+		return fmt.Errorf("encoding CSL-JSON: %w", err)
+	}
+	return nil
+}
+
+// newCSLItem converts an entry to a CSL-JSON item.
+func newCSLItem(e *Entry) cslItem {
+	item := cslItem{
+		ID:             e.Key,
+		Type:           "book",
+		Title:          e.Title,
+		Author:         cslNames(e.Authors),
+		Editor:         cslNames(e.Editors),
+		Translator:     cslNames(e.Translators),
+		Edition:        e.Edition,
+		Publisher:      e.Publisher,
+		PublisherPlace: e.PublisherPlace,
+		ISBN:           e.ISBN,
+		DOI:            e.DOI,
+		Language:       e.Language,
+	}
+	if e.Year != 0 {
+		item.Issued = &cslDate{DateParts: [][]int{{e.Year}}}
+	}
+	return item
+}
+
+// cslNames splits names in display order into CSL name parts.
+func cslNames(names []string) []cslName {
+	var parts []cslName
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if family, given, ok := strings.Cut(name, ","); ok {
+			parts = append(parts, cslName{Family: strings.TrimSpace(family), Given: strings.TrimSpace(given)})
+		} else if i := strings.LastIndexFunc(name, unicode.IsSpace); i >= 0 {
+			parts = append(parts, cslName{Family: name[i+1:], Given: strings.TrimSpace(name[:i])})
+		} else if name != "" {
+			parts = append(parts, cslName{Literal: name})
+		}
+	}
+	return parts
+}
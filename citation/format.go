@@ -0,0 +1,111 @@
+package citation
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/fwojciec/bookid/language"
+)
+
+// BibTeX returns the entry as a BibTeX @book record.
+func BibTeX(e *Entry) string {
+	var b strings.Builder
+	b.WriteString("@book{" + e.Key + ",\n")
+	field := func(name, value string) {
+		if value != "" {
+			b.WriteString("  " + name + " = {" + value + "},\n")
+		}
+	}
+
+	authors := make([]string, 0, len(e.Authors))
+	for _, a := range e.Authors {
+		authors = append(authors, escapeBibTeX(invertName(a)))
+	}
+	field("author", strings.Join(authors, " and "))
+	editors := make([]string, 0, len(e.Editors))
+	for _, name := range e.Editors {
+		editors = append(editors, escapeBibTeX(invertName(name)))
+	}
+	field("editor", strings.Join(editors, " and "))
+	translators := make([]string, 0, len(e.Translators))
+	for _, name := range e.Translators {
+		translators = append(translators, escapeBibTeX(invertName(name)))
+	}
+	field("translator", strings.Join(translators, " and ")) // biblatex
+	// Double braces keep the title's capitalization in every bibliography
+	// style.
+	if e.Title != "" {
+		field("title", "{"+escapeBibTeX(e.Title)+"}")
+	}
+	field("edition", escapeBibTeX(e.Edition))
+	field("publisher", escapeBibTeX(e.Publisher))
+	field("address", escapeBibTeX(e.PublisherPlace))
+	if e.Year != 0 {
+		field("year", strconv.Itoa(e.Year))
+	}
+	field("isbn", e.ISBN)
+	field("doi", e.DOI) // Verbatim in biblatex
+	if e.Language != "" {
+		field("language", strings.ToLower(language.Name(e.Language)))
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// escapeBibTeX escapes the characters with a special meaning in BibTeX
+// field values.
+func escapeBibTeX(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch r {
+		case '\\':
+			b.WriteString(`\textbackslash{}`)
+		case '{', '}', '&', '%', '$', '#', '_':
+			b.WriteRune('\\')
+			b.WriteRune(r)
+		case '~':
+			b.WriteString(`\textasciitilde{}`)
+		case '^':
+			b.WriteString(`\textasciicircum{}`)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// RIS returns the entry as a RIS record of type BOOK.
+func RIS(e *Entry) string {
+	var b strings.Builder
+	tag := func(name, value string) {
+		if value = strings.TrimSpace(value); value != "" {
+			b.WriteString(name + "  - " + value + "\n")
+		}
+	}
+
+	tag("TY", "BOOK")
+	tag("ID", e.Key)
+	for _, a := range e.Authors {
+		tag("AU", invertName(a))
+	}
+	for _, name := range e.Editors {
+		tag("ED", invertName(name))
+	}
+	for _, name := range e.Translators {
+		tag("A4", invertName(name))
+	}
+	tag("TI", e.Title)
+	tag("ET", e.Edition)
+	tag("PB", e.Publisher)
+	tag("CY", e.PublisherPlace)
+	if e.Year != 0 {
+		tag("PY", strconv.Itoa(e.Year))
+	}
+	tag("SN", e.ISBN)
+	tag("DO", e.DOI)
+	if e.Language != "" {
+		tag("LA", language.Name(e.Language))
+	}
+	b.WriteString("ER  - \n")
+	return b.String()
+}
@@ -0,0 +1,35 @@
+package bookid
+
+import "context"
+
+// Classification holds the numbers a publication is shelved by in library
+// classification schemes. Empty numbers are unknown.
+type Classification struct {
+	Dewey string `json:"dewey,omitempty"` // Dewey Decimal Classification, e.g. "813.52"
+	LCC   string `json:"lcc,omitempty"`   // Library of Congress Classification, e.g. "PS3511.I9 G7 2004"
+}
+
+// IsZero returns true if no classification number is known.
+func (c Classification) IsZero() bool {
+	return c == Classification{}
+}
+
+// ClassificationFinder looks up the classification numbers of a book.
+// Implemented by providers that record them, such as Open Library and the
+// Library of Congress.
+type ClassificationFinder interface {
+	// LookupClassification returns the classification numbers the provider
+	// has for the book with the given ISBN. Returns a zero Classification if
+	// it has none.
+	LookupClassification(ctx context.Context, isbn string) (Classification, error)
+}
+
+// ClassificationService represents a service for enriching cataloged
+// publications with the classification numbers of the providers that record
+// them.
+type ClassificationService interface {
+	// Classify looks up the classification numbers of a publication by ISBN
+	// and stores the ones it lacks. Returns ENOTFOUND if the publication does
+	// not exist and EINVALID if it has no ISBN.
+	Classify(ctx context.Context, publicationID int64) (*Publication, error)
+}
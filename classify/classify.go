@@ -0,0 +1,151 @@
+// Package classify enriches cataloged publications with their Dewey Decimal
+// and Library of Congress classification numbers, looked up by ISBN with the
+// providers that record them, so small libraries can shelve their books.
+package classify
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/fwojciec/bookid"
+)
+
+// pageSize is the number of publications read from the catalog at a time
+// while classifying all of them.
+const pageSize = 100
+
+// Ensure service implements interface.
+var _ bookid.ClassificationService = (*Service)(nil)
+
+// Provider represents a classification finder along with its name, which is
+// recorded as the provenance of the numbers it finds.
+type Provider struct {
+	Name   string
+	Finder bookid.ClassificationFinder
+}
+
+// Service implements the ClassificationService interface by looking
+// publications up with a set of classification finders.
+type Service struct {
+	PublicationService bookid.PublicationService
+
+	// Classification providers, most preferred first. Providers are asked in
+	// order until both numbers are known; one that fails is skipped.
+	Providers []Provider
+
+	// Called by Backfill after each publication with the number done so far
+	// and the total, if set. An error stops the backfill.
+	Progress func(done, total int) error
+}
+
+// NewService returns a new instance of Service asking providers in order.
+func NewService(pubs bookid.PublicationService, providers ...Provider) *Service {
+	return &Service{
+		PublicationService: pubs,
+		Providers:          providers,
+	}
+}
+
+// Classify looks up the classification numbers of a publication by its
+// ISBN-13, or ISBN-10 if it has none, and stores the ones it lacks. Numbers
+// already stored are kept, and those of more preferred providers win.
+// Returns ENOTFOUND if no provider has a missing number, and the errors of
+// the providers if every one of them failed.
+func (s *Service) Classify(ctx context.Context, publicationID int64) (*bookid.Publication, error) {
+	pub, err := s.PublicationService.FindPublicationByID(ctx, publicationID)
+	if err != nil {
+		return nil, err
+	} else if pub.Classification.Dewey != "" && pub.Classification.LCC != "" {
+		return pub, nil
+	}
+	code := pub.ISBN13
+	if code == "" {
+		code = pub.ISBN10
+	}
+	if code == "" {
+		return nil, bookid.Errorf(bookid.EINVALID, "Publication %d has no ISBN.", pub.ID)
+	}
+
+	c := pub.Classification
+	var provider string
+	var errs []error
+	for _, p := range s.Providers {
+		found, err := p.Finder.LookupClassification(ctx, code)
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		} else if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", p.Name, err))
+			continue
+		}
+		found = Normalize(found)
+		if (c.Dewey == "" && found.Dewey != "") || (c.LCC == "" && found.LCC != "") {
+			c.Dewey, c.LCC = cmp.Or(c.Dewey, found.Dewey), cmp.Or(c.LCC, found.LCC)
+			if provider == "" {
+				provider = p.Name
+			}
+		}
+		if c.Dewey != "" && c.LCC != "" {
+			break
+		}
+	}
+	if provider == "" && len(errs) > 0 && len(errs) == len(s.Providers) {
+		return nil, errors.Join(errs...)
+	} else if provider == "" {
+		return nil, bookid.Errorf(bookid.ENOTFOUND, "No classification found for publication %d.", pub.ID)
+	}
+
+	return s.PublicationService.UpdatePublication(ctx, pub.ID, bookid.PublicationUpdate{
+		Classification: &c,
+		Provenance:     map[string]string{"classification": provider},
+	})
+}
+
+// Backfill classifies all publications without a classification number.
+// Returns the number of publications classified and the IDs of those without
+// an ISBN or that no provider has numbers for.
+func (s *Service) Backfill(ctx context.Context) (classified int, missing []int64, err error) {
+	hasClassification := false
+	for {
+		// Classified publications drop out of the filter, so only the
+		// missing ones need to be skipped.
+		pubs, n, err := s.PublicationService.FindPublications(ctx, bookid.PublicationFilter{
+			HasClassification: &hasClassification,
+			Offset:            len(missing),
+			Limit:             pageSize,
+		})
+		if err != nil {
+			return classified, missing, err
+		} else if len(pubs) == 0 {
+			return classified, missing, nil
+		}
+
+		// Unclassified publications include the missing ones.
+		total := classified + n
+		for _, pub := range pubs {
+			if _, err := s.Classify(ctx, pub.ID); bookid.ErrorCode(err) == bookid.ENOTFOUND || bookid.ErrorCode(err) == bookid.EINVALID {
+				missing = append(missing, pub.ID)
+			} else if err != nil {
+				return classified, missing, fmt.Errorf("publication %d: %w", pub.ID, err)
+			} else {
+				classified++
+			}
+			if s.Progress != nil {
+				if err := s.Progress(classified+len(missing), total); err != nil {
+					return classified, missing, err
+				}
+			}
+		}
+	}
+}
+
+// Normalize returns c with its numbers in a consistent form: runs of spaces
+// collapsed and, in Dewey numbers, the prime marks that segment them removed,
+// so Open Library's "813/.52" becomes "813.52".
+func Normalize(c bookid.Classification) bookid.Classification {
+	c.Dewey = strings.NewReplacer("/", "", "'", "").Replace(strings.Join(strings.Fields(c.Dewey), " "))
+	c.LCC = strings.Join(strings.Fields(c.LCC), " ")
+	return c
+}
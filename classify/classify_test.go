@@ -0,0 +1,185 @@
+package classify_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/classify"
+	"github.com/fwojciec/bookid/mock"
+	"github.com/fwojciec/bookid/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newClassifyService returns a classification service over an in-memory
+// catalog holding one publication with an ISBN and one without.
+func newClassifyService(t *testing.T, providers ...classify.Provider) (*classify.Service, []*bookid.Publication) {
+	t.Helper()
+	db := sqlite.NewDB(":memory:")
+	require.NoError(t, db.Open())
+	t.Cleanup(func() { _ = db.Close() })
+
+	ctx := context.Background()
+	work := &bookid.Work{Title: "The Great Gatsby"}
+	require.NoError(t, sqlite.NewWorkService(db).CreateWork(ctx, work))
+	pubs := sqlite.NewPublicationService(db)
+	withISBN := &bookid.Publication{WorkID: work.ID, ISBN13: "9780743273565"}
+	require.NoError(t, pubs.CreatePublication(ctx, withISBN))
+	withoutISBN := &bookid.Publication{WorkID: work.ID}
+	require.NoError(t, pubs.CreatePublication(ctx, withoutISBN))
+
+	return classify.NewService(pubs, providers...), []*bookid.Publication{withISBN, withoutISBN}
+}
+
+func TestService_Classify(t *testing.T) {
+	t.Parallel()
+
+	t.Run("combines", func(t *testing.T) {
+		t.Parallel()
+		var looked []string
+		providers := []classify.Provider{
+			{Name: "openlibrary", Finder: &mock.ClassificationFinder{LookupClassificationFn: func(_ context.Context, isbn string) (bookid.Classification, error) {
+				looked = append(looked, "openlibrary:"+isbn)
+				return bookid.Classification{Dewey: "813/.52"}, nil
+			}}},
+			{Name: "sru", Finder: &mock.ClassificationFinder{LookupClassificationFn: func(_ context.Context, isbn string) (bookid.Classification, error) {
+				looked = append(looked, "sru:"+isbn)
+				return bookid.Classification{Dewey: "813.5", LCC: "PS3511.I9 G7 2004"}, nil
+			}}},
+		}
+		s, pubs := newClassifyService(t, providers...)
+
+		pub, err := s.Classify(context.Background(), pubs[0].ID)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"openlibrary:9780743273565", "sru:9780743273565"}, looked)
+		assert.Equal(t, bookid.Classification{Dewey: "813.52", LCC: "PS3511.I9 G7 2004"}, pub.Classification, "the first provider's numbers win")
+		assert.Equal(t, "openlibrary", pub.Provenance["classification"])
+	})
+
+	t.Run("stops_when_complete", func(t *testing.T) {
+		t.Parallel()
+		providers := []classify.Provider{
+			{Name: "openlibrary", Finder: &mock.ClassificationFinder{LookupClassificationFn: func(context.Context, string) (bookid.Classification, error) {
+				return bookid.Classification{Dewey: "813.52", LCC: "PS3511.I9 G7 2004"}, nil
+			}}},
+			{Name: "sru", Finder: &mock.ClassificationFinder{LookupClassificationFn: func(context.Context, string) (bookid.Classification, error) {
+				return bookid.Classification{}, errors.New("not asked")
+			}}},
+		}
+		s, pubs := newClassifyService(t, providers...)
+
+		_, err := s.Classify(context.Background(), pubs[0].ID)
+		require.NoError(t, err)
+	})
+
+	t.Run("configured_order", func(t *testing.T) {
+		t.Parallel()
+		var looked []string
+		providers := []classify.Provider{
+			{Name: "sru", Finder: &mock.ClassificationFinder{LookupClassificationFn: func(context.Context, string) (bookid.Classification, error) {
+				looked = append(looked, "sru")
+				return bookid.Classification{Dewey: "813.5"}, nil
+			}}},
+			{Name: "openlibrary", Finder: &mock.ClassificationFinder{LookupClassificationFn: func(context.Context, string) (bookid.Classification, error) {
+				looked = append(looked, "openlibrary")
+				return bookid.Classification{Dewey: "813.52", LCC: "PS3511.I9 G7 2004"}, nil
+			}}},
+		}
+		s, pubs := newClassifyService(t, providers...)
+
+		pub, err := s.Classify(context.Background(), pubs[0].ID)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"sru", "openlibrary"}, looked)
+		assert.Equal(t, bookid.Classification{Dewey: "813.5", LCC: "PS3511.I9 G7 2004"}, pub.Classification)
+		assert.Equal(t, "sru", pub.Provenance["classification"])
+	})
+
+	t.Run("skips_failing_provider", func(t *testing.T) {
+		t.Parallel()
+		providers := []classify.Provider{
+			{Name: "openlibrary", Finder: &mock.ClassificationFinder{LookupClassificationFn: func(context.Context, string) (bookid.Classification, error) {
+				return bookid.Classification{}, errors.New("503 Service Unavailable")
+			}}},
+			{Name: "sru", Finder: &mock.ClassificationFinder{LookupClassificationFn: func(context.Context, string) (bookid.Classification, error) {
+				return bookid.Classification{Dewey: "813.52", LCC: "PS3511.I9 G7 2004"}, nil
+			}}},
+		}
+		s, pubs := newClassifyService(t, providers...)
+
+		pub, err := s.Classify(context.Background(), pubs[0].ID)
+		require.NoError(t, err)
+		assert.Equal(t, bookid.Classification{Dewey: "813.52", LCC: "PS3511.I9 G7 2004"}, pub.Classification)
+		assert.Equal(t, "sru", pub.Provenance["classification"])
+	})
+
+	t.Run("all_providers_fail", func(t *testing.T) {
+		t.Parallel()
+		providers := []classify.Provider{
+			{Name: "openlibrary", Finder: &mock.ClassificationFinder{LookupClassificationFn: func(context.Context, string) (bookid.Classification, error) {
+				return bookid.Classification{}, errors.New("503 Service Unavailable")
+			}}},
+			{Name: "sru", Finder: &mock.ClassificationFinder{LookupClassificationFn: func(context.Context, string) (bookid.Classification, error) {
+				return bookid.Classification{}, errors.New("connection refused")
+			}}},
+		}
+		s, pubs := newClassifyService(t, providers...)
+
+		_, err := s.Classify(context.Background(), pubs[0].ID)
+		require.Error(t, err)
+		assert.NotEqual(t, bookid.ENOTFOUND, bookid.ErrorCode(err))
+		assert.ErrorContains(t, err, "openlibrary: 503 Service Unavailable")
+		assert.ErrorContains(t, err, "sru: connection refused")
+	})
+
+	t.Run("not_found", func(t *testing.T) {
+		t.Parallel()
+		providers := []classify.Provider{
+			{Name: "openlibrary", Finder: &mock.ClassificationFinder{LookupClassificationFn: func(context.Context, string) (bookid.Classification, error) {
+				return bookid.Classification{}, nil
+			}}},
+		}
+		s, pubs := newClassifyService(t, providers...)
+
+		_, err := s.Classify(context.Background(), pubs[0].ID)
+		assert.Equal(t, bookid.ENOTFOUND, bookid.ErrorCode(err))
+	})
+
+	t.Run("no_isbn", func(t *testing.T) {
+		t.Parallel()
+		s, pubs := newClassifyService(t)
+		_, err := s.Classify(context.Background(), pubs[1].ID)
+		assert.Equal(t, bookid.EINVALID, bookid.ErrorCode(err))
+	})
+}
+
+func TestService_Backfill(t *testing.T) {
+	t.Parallel()
+
+	providers := []classify.Provider{
+		{Name: "openlibrary", Finder: &mock.ClassificationFinder{LookupClassificationFn: func(context.Context, string) (bookid.Classification, error) {
+			return bookid.Classification{Dewey: "813.52"}, nil
+		}}},
+	}
+	s, pubs := newClassifyService(t, providers...)
+	var progress []int
+	s.Progress = func(done, total int) error {
+		progress = append(progress, done)
+		assert.Equal(t, 2, total)
+		return nil
+	}
+
+	classified, missing, err := s.Backfill(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, classified)
+	assert.Equal(t, []int64{pubs[1].ID}, missing)
+	assert.Equal(t, []int{1, 2}, progress)
+}
+
+func TestNormalize(t *testing.T) {
+	t.Parallel()
+
+	got := classify.Normalize(bookid.Classification{Dewey: " 813/.52 ", LCC: "PS3511.I9\tG7  2004"})
+	assert.Equal(t, bookid.Classification{Dewey: "813.52", LCC: "PS3511.I9 G7 2004"}, got)
+}
@@ -0,0 +1,229 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/sqlite"
+)
+
+// gzipMagic starts every gzip stream.
+const gzipMagic = "\x1f\x8b"
+
+// BackupCommand represents a command for snapshotting the catalog database.
+type BackupCommand struct {
+	Config Config
+	Stdout io.Writer
+}
+
+// Run executes the command.
+func (c *BackupCommand) Run(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("bookid-backup", flag.ContinueOnError)
+	compress := fs.Bool("gzip", false, "compress the backup with gzip (default if the path ends in .gz)")
+	fs.Usage = func() { c.usage(fs) }
+	if err := parseFlags(ctx, fs, args); err != nil {
+		return err
+	} else if fs.NArg() != 1 {
+		return fmt.Errorf("usage: bookid backup [-gzip] <path>")
+	}
+	path := fs.Arg(0)
+	*compress = *compress || strings.HasSuffix(path, ".gz")
+
+	db, err := openDB(c.Config)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	if !*compress {
+		if err := db.Backup(ctx, path); err != nil {
+			return err
+		}
+	} else if err := backupGzip(ctx, db, path); err != nil {
+		return err
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	return writeJSON(c.Stdout, struct {
+		Path  string `json:"path"`
+		Bytes int64  `json:"bytes"`
+		Gzip  bool   `json:"gzip"`
+	}{path, info.Size(), *compress})
+}
+
+// backupGzip backs db up to a temporary file and compresses it to path,
+// replacing path only once the compressed backup is complete.
+func backupGzip(ctx context.Context, db *sqlite.DB, path string) (err error) {
+	dir, err := os.MkdirTemp("", "bookid-backup-")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	plain := filepath.Join(dir, "catalog.db")
+	if err := db.Backup(ctx, plain); err != nil {
+		return err
+	}
+	src, err := os.Open(plain)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = dst.Close()
+			_ = os.Remove(dst.Name())
+		}
+	}()
+
+	zw := gzip.NewWriter(dst)
+	zw.Name = filepath.Base(strings.TrimSuffix(path, ".gz"))
+	if _, err := io.Copy(zw, src); err != nil {
+		return err
+	} else if err := zw.Close(); err != nil {
+		return err
+	} else if err := dst.Close(); err != nil {
+		return err
+	}
+	return os.Rename(dst.Name(), path)
+}
+
+// usage prints the help text for the command.
+func (c *BackupCommand) usage(fs *flag.FlagSet) {
+	fmt.Fprintln(os.Stderr, strings.TrimSpace(`
+Writes a consistent copy of the catalog database to a file with the SQLite
+online backup API, so it can be taken while other commands or the server are
+using the catalog. An existing file at the path is replaced once the backup
+is complete. Prints the path and size of the backup.
+
+The backup is compressed with gzip if -gzip is given or the path ends in
+".gz". Restore it with "bookid restore".
+
+Usage:
+
+	bookid backup [flags] <path>
+
+Flags:
+`))
+	fs.PrintDefaults()
+}
+
+// RestoreCommand represents a command for replacing the catalog database with
+// a backup.
+type RestoreCommand struct {
+	Config Config
+	Stdout io.Writer
+}
+
+// Run executes the command.
+func (c *RestoreCommand) Run(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("bookid-restore", flag.ContinueOnError)
+	fs.Usage = func() { c.usage(fs) }
+	if err := parseFlags(ctx, fs, args); err != nil {
+		return err
+	} else if fs.NArg() != 1 {
+		return fmt.Errorf("usage: bookid restore <path>")
+	}
+	path := fs.Arg(0)
+
+	plain, cleanup, err := gunzipBackup(path)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	db, err := openDB(c.Config)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	if err := db.Restore(ctx, plain); err != nil {
+		return err
+	}
+	_, n, err := sqlite.NewWorkService(db).FindWorks(ctx, bookid.WorkFilter{Limit: 1})
+	if err != nil {
+		return err
+	}
+	return writeJSON(c.Stdout, struct {
+		Path  string `json:"path"`
+		Works int    `json:"works"`
+	}{path, n})
+}
+
+// gunzipBackup returns the path of the uncompressed backup at path: path
+// itself unless it is gzipped, in which case it is decompressed to a
+// temporary file removed by cleanup.
+func gunzipBackup(path string) (plain string, cleanup func(), err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", nil, err
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	if magic, _ := r.Peek(len(gzipMagic)); !bytes.Equal(magic, []byte(gzipMagic)) {
+		return path, func() {}, nil
+	}
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return "", nil, bookid.Errorf(bookid.EINVALID, "Backup is not valid gzip: %s.", err)
+	}
+
+	dir, err := os.MkdirTemp("", "bookid-restore-")
+	if err != nil {
+		return "", nil, err
+	}
+	cleanup = func() { _ = os.RemoveAll(dir) }
+	plain = filepath.Join(dir, "catalog.db")
+	dst, err := os.Create(plain)
+	if err != nil {
+		cleanup()
+		return "", nil, err
+	}
+	defer dst.Close()
+	if _, err := io.Copy(dst, zr); err != nil {
+		cleanup()
+		return "", nil, bookid.Errorf(bookid.EINVALID, "Backup is not valid gzip: %s.", err)
+	} else if err := dst.Close(); err != nil {
+		cleanup()
+		return "", nil, err
+	}
+	return plain, cleanup, nil
+}
+
+// usage prints the help text for the command.
+func (c *RestoreCommand) usage(fs *flag.FlagSet) {
+	fmt.Fprintln(os.Stderr, strings.TrimSpace(`
+Replaces the contents of the catalog database with a backup made by "bookid
+backup", gzipped or not, and prints the number of works restored.
+
+The backup is checked for corruption before anything is replaced, and the
+catalog afterwards. Backups made by older versions of bookid are brought up
+to date. Everything saved since the backup was taken is lost.
+
+Usage:
+
+	bookid restore <path>
+
+Flags:
+`))
+	fs.PrintDefaults()
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mustCreateCatalog creates a catalog database at path holding a work for
+// each of titles.
+func mustCreateCatalog(tb testing.TB, path string, titles ...string) {
+	tb.Helper()
+	db := sqlite.NewDB(path)
+	require.NoError(tb, db.Open())
+	for _, title := range titles {
+		require.NoError(tb, sqlite.NewWorkService(db).CreateWork(context.Background(), &bookid.Work{Title: title}))
+	}
+	require.NoError(tb, db.Close())
+}
+
+// countWorks returns the number of works in the catalog database at path.
+func countWorks(tb testing.TB, path string) int {
+	tb.Helper()
+	db := sqlite.NewDB(path)
+	require.NoError(tb, db.Open())
+	defer db.Close()
+	_, n, err := sqlite.NewWorkService(db).FindWorks(context.Background(), bookid.WorkFilter{Limit: 1})
+	require.NoError(tb, err)
+	return n
+}
+
+// mustBackupGzip backs the catalog at dbPath up to a gzipped file in dir and
+// returns its path.
+func mustBackupGzip(tb testing.TB, dbPath, dir string) string {
+	tb.Helper()
+	path := filepath.Join(dir, "catalog.db.gz")
+	cmd := &BackupCommand{Config: Config{DBPath: dbPath}, Stdout: &bytes.Buffer{}}
+	require.NoError(tb, cmd.Run(context.Background(), []string{path}))
+	return path
+}
+
+func TestBackupRestore_Gzip(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "catalog.db")
+	mustCreateCatalog(t, dbPath, "The Great Gatsby", "Dune")
+
+	backupDir := t.TempDir()
+	var buf bytes.Buffer
+	path := filepath.Join(backupDir, "catalog.db.gz")
+	require.NoError(t, (&BackupCommand{Config: Config{DBPath: dbPath}, Stdout: &buf}).Run(ctx, []string{path}))
+	var backup struct {
+		Gzip bool `json:"gzip"`
+	}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &backup))
+	assert.True(t, backup.Gzip, "paths ending in .gz are compressed")
+
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, gzipMagic, string(b[:2]))
+	entries, err := os.ReadDir(backupDir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1, "no temporary files are left behind")
+
+	// Restore into a catalog that has changed since the backup.
+	mustCreateCatalog(t, dbPath, "Neuromancer")
+	buf.Reset()
+	require.NoError(t, (&RestoreCommand{Config: Config{DBPath: dbPath}, Stdout: &buf}).Run(ctx, []string{path}))
+	assert.JSONEq(t, `{"path":"`+path+`","works":2}`, buf.String())
+	assert.Equal(t, 2, countWorks(t, dbPath))
+}
+
+func TestRestore_InvalidGzip(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		name    string
+		corrupt func(b []byte) []byte
+	}{
+		{"corrupt", func(b []byte) []byte { return append([]byte(gzipMagic), bytes.Repeat([]byte{0xff}, 64)...) }},
+		{"truncated", func(b []byte) []byte { return b[:len(b)/2] }},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			dbPath := filepath.Join(t.TempDir(), "catalog.db")
+			mustCreateCatalog(t, dbPath, "The Great Gatsby")
+
+			path := mustBackupGzip(t, dbPath, t.TempDir())
+			b, err := os.ReadFile(path)
+			require.NoError(t, err)
+			require.NoError(t, os.WriteFile(path, tt.corrupt(b), 0o600))
+
+			mustCreateCatalog(t, dbPath, "Dune")
+			cmd := &RestoreCommand{Config: Config{DBPath: dbPath}, Stdout: &bytes.Buffer{}}
+			err = cmd.Run(context.Background(), []string{path})
+			assert.Equal(t, bookid.EINVALID, bookid.ErrorCode(err))
+			assert.Equal(t, 2, countWorks(t, dbPath), "the catalog is left untouched")
+		})
+	}
+}
+
+func TestGunzipBackup(t *testing.T) {
+	// TMPDIR is set to check that temporary files are removed, so this test
+	// cannot run in parallel.
+	tmp := t.TempDir()
+	t.Setenv("TMPDIR", tmp)
+
+	dbPath := filepath.Join(t.TempDir(), "catalog.db")
+	mustCreateCatalog(t, dbPath, "The Great Gatsby")
+
+	t.Run("plain", func(t *testing.T) {
+		plain, cleanup, err := gunzipBackup(dbPath)
+		require.NoError(t, err)
+		cleanup()
+		assert.Equal(t, dbPath, plain, "uncompressed backups are used as they are")
+		assert.FileExists(t, dbPath)
+	})
+
+	t.Run("gzip", func(t *testing.T) {
+		path := mustBackupGzip(t, dbPath, t.TempDir())
+		plain, cleanup, err := gunzipBackup(path)
+		require.NoError(t, err)
+		assert.Equal(t, 1, countWorks(t, plain))
+		cleanup()
+		assert.NoFileExists(t, plain)
+	})
+
+	t.Run("truncated", func(t *testing.T) {
+		path := mustBackupGzip(t, dbPath, t.TempDir())
+		b, err := os.ReadFile(path)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(path, b[:len(b)/2], 0o600))
+		_, _, err = gunzipBackup(path)
+		assert.Equal(t, bookid.EINVALID, bookid.ErrorCode(err))
+	})
+
+	entries, err := os.ReadDir(tmp)
+	require.NoError(t, err)
+	assert.Empty(t, entries, "temporary files are removed")
+}
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/batch"
+	"github.com/fwojciec/bookid/importer"
+	"github.com/fwojciec/bookid/sqlite"
+)
+
+// BatchCommand represents a command for identifying many books at once.
+type BatchCommand struct {
+	Config Config
+	Stdin  io.Reader
+	Stdout io.Writer
+
+	// Finder identifies the books. If nil, the providers of Config are used.
+	Finder bookid.BookFinder
+}
+
+// batchLine is a single NDJSON record emitted by BatchCommand.
+type batchLine struct {
+	Line   int                `json:"line"`
+	Query  string             `json:"query"`
+	Result *bookid.BookResult `json:"result"`
+	Error  string             `json:"error,omitempty"`
+
+	// Set with -save, depending on the confidence of the result.
+	WorkID        int64 `json:"work_id,omitempty"`
+	PublicationID int64 `json:"publication_id,omitempty"`
+	ReviewID      int64 `json:"review_id,omitempty"`
+}
+
+// Run executes the command.
+func (c *BatchCommand) Run(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("bookid-batch", flag.ContinueOnError)
+	workers := fs.Int("workers", batch.DefaultWorkers, "number of concurrent searches")
+	progress := fs.Bool("progress", false, "report progress on stderr")
+	stream := fs.Bool("stream", false, "emit each record as soon as its search is done rather than in input order")
+	save := fs.Bool("save", false, "save confident results to the catalog and queue the others for review")
+	minConfidence := fs.Float64("min-confidence", 0.5, "minimum confidence of results saved with -save")
+	fs.Usage = func() { c.usage(fs) }
+	if err := parseFlags(ctx, fs, args); err != nil {
+		return err
+	} else if fs.NArg() > 1 {
+		return fmt.Errorf("usage: bookid batch [flags] [file]")
+	} else if *workers < 1 {
+		return bookid.Errorf(bookid.EINVALID, "Workers must be at least 1.")
+	} else if *minConfidence < 0 || *minConfidence > 1 {
+		return bookid.Errorf(bookid.EINVALID, "Minimum confidence must be between 0 and 1.")
+	}
+
+	// Read queries from the named file, or stdin if none is given.
+	r := c.Stdin
+	if path := fs.Arg(0); path != "" && path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	lines, err := readQueries(r)
+	if err != nil {
+		return err
+	}
+
+	db, err := openDB(c.Config)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	finder := c.Finder
+	if finder == nil {
+		if finder, err = newFinder(c.Config, db); err != nil {
+			return err
+		}
+	}
+
+	queries := make([]string, len(lines))
+	for i, line := range lines {
+		queries[i] = line.Query
+	}
+
+	// Keep the runners-up of searches to save as candidates for review.
+	opts := bookid.SearchOptions{MaxResults: 1}
+	if *save {
+		opts = bookid.SearchOptions{MaxResults: importer.ReviewCandidates, IncludeRaw: true}
+	}
+	catalog, reviews := sqlite.NewCatalogService(db), sqlite.NewReviewService(db)
+
+	// Search concurrently but emit records in input order as soon as each
+	// one and all of its predecessors are done, or with -stream as soon as
+	// each one is done. Progress is reported one search at a time, so no
+	// locking is needed here.
+	enc := json.NewEncoder(c.Stdout)
+	enc.SetEscapeHTML(false)
+	var encodeErr, saveErr error
+	finished, next := make([]bool, len(lines)), 0
+	_, err = batch.NewBatchFinder(finder).SearchAll(ctx, queries, bookid.BatchOptions{
+		Search:  opts,
+		Workers: *workers,
+		Progress: func(p bookid.BatchProgress) {
+			if *progress {
+				fmt.Fprintf(os.Stderr, "%d/%d done, %d failed\n", p.Done, p.Total, p.Failed)
+			}
+			lines[p.Index].record(p.Result)
+			if *save && saveErr == nil {
+				saveErr = lines[p.Index].save(ctx, catalog, reviews, p.Result, *minConfidence)
+			}
+			if *stream {
+				if encodeErr == nil {
+					encodeErr = enc.Encode(lines[p.Index])
+				}
+				return
+			}
+			finished[p.Index] = true
+			for ; next < len(lines) && finished[next]; next++ {
+				if encodeErr == nil {
+					encodeErr = enc.Encode(lines[next])
+				}
+			}
+		},
+	})
+	if err != nil {
+		return err
+	} else if saveErr != nil {
+		return saveErr
+	}
+	return encodeErr
+}
+
+// record sets the top result or the error of a search on the line.
+func (line *batchLine) record(r bookid.BatchResult) {
+	if r.Err != nil {
+		line.Error = errorMessage(r.Err)
+	} else if len(r.Results) > 0 {
+		line.Result = &r.Results[0]
+	}
+}
+
+// save catalogs the top result of a search on the line if it is confident
+// enough, or queues all of its results for review otherwise.
+func (line *batchLine) save(ctx context.Context, catalog bookid.CatalogService, reviews bookid.ReviewService, r bookid.BatchResult, minConfidence float64) (err error) {
+	if r.Err != nil || len(r.Results) == 0 {
+		return nil
+	} else if r.Results[0].Confidence >= minConfidence {
+		line.WorkID, line.PublicationID, err = catalog.SaveResult(ctx, r.Results[0])
+		return err
+	}
+
+	review := &bookid.Review{Source: "batch", Line: line.Line, Query: line.Query, Candidates: r.Results}
+	if err := reviews.CreateReview(ctx, review); err != nil {
+		return err
+	}
+	line.ReviewID = review.ID
+	return nil
+}
+
+// readQueries returns one record per non-empty line of r. Lines starting
+// with '#' are treated as comments.
+func readQueries(r io.Reader) ([]*batchLine, error) {
+	var lines []*batchLine
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		query := strings.TrimSpace(scanner.Text())
+		if query == "" || strings.HasPrefix(query, "#") {
+			continue
+		}
+		lines = append(lines, &batchLine{Line: n, Query: query})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading queries: %w", err)
+	}
+	return lines, nil
+}
+
+// usage prints the help text for the command.
+func (c *BatchCommand) usage(fs *flag.FlagSet) {
+	fmt.Fprintln(os.Stderr, strings.TrimSpace(`
+Identifies one book per line of the given file, or stdin if no file is given,
+and prints one JSON record per query (NDJSON) in input order. With -stream,
+records are printed in the order the searches finish instead; use their
+"line" field to match them to the input. Failed lookups are reported in the
+record's "error" field instead of aborting the batch.
+
+With -save, results of at least -min-confidence are also saved to the
+catalog, and the records report their "work_id" and "publication_id". The
+candidates of less confident searches are queued for "bookid review"
+instead, and their records report the "review_id".
+
+Usage:
+
+	bookid batch [flags] [file]
+
+Flags:
+`))
+	fs.PrintDefaults()
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readBatchLines decodes the NDJSON records written by BatchCommand.
+func readBatchLines(tb testing.TB, b []byte) []batchLine {
+	tb.Helper()
+	var lines []batchLine
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	for scanner.Scan() {
+		var line batchLine
+		require.NoError(tb, json.Unmarshal(scanner.Bytes(), &line))
+		lines = append(lines, line)
+	}
+	require.NoError(tb, scanner.Err())
+	return lines
+}
+
+// signalWriter closes written on the first write to it.
+type signalWriter struct {
+	bytes.Buffer
+	once    sync.Once
+	written chan struct{}
+}
+
+func (w *signalWriter) Write(p []byte) (int, error) {
+	defer w.once.Do(func() { close(w.written) })
+	return w.Buffer.Write(p)
+}
+
+func TestBatchCommand_Stream(t *testing.T) {
+	t.Parallel()
+
+	// The search of the first query only finishes once the record of the
+	// second has been written.
+	stdout := &signalWriter{written: make(chan struct{})}
+	finder := &mock.BookFinder{SearchFn: func(ctx context.Context, query string, _ bookid.SearchOptions) ([]bookid.BookResult, error) {
+		if query == "dune" {
+			<-stdout.written
+		}
+		return []bookid.BookResult{{Title: query}}, nil
+	}}
+	cmd := &BatchCommand{
+		Config: Config{DBPath: filepath.Join(t.TempDir(), "catalog.db")},
+		Stdin:  strings.NewReader("dune\n\ngatsby\n"),
+		Stdout: stdout,
+		Finder: finder,
+	}
+	require.NoError(t, cmd.Run(context.Background(), []string{"-stream", "-workers", "2"}))
+
+	lines := readBatchLines(t, stdout.Bytes())
+	require.Len(t, lines, 2)
+	assert.Equal(t, 3, lines[0].Line)
+	assert.Equal(t, "gatsby", lines[0].Query)
+	assert.Equal(t, "gatsby", lines[0].Result.Title)
+	assert.Equal(t, 1, lines[1].Line)
+	assert.Equal(t, "dune", lines[1].Query)
+	assert.Equal(t, "dune", lines[1].Result.Title)
+}
+
+func TestBatchCommand(t *testing.T) {
+	t.Parallel()
+
+	finder := &mock.BookFinder{SearchFn: func(_ context.Context, query string, _ bookid.SearchOptions) ([]bookid.BookResult, error) {
+		switch query {
+		case "gatsby":
+			// Finish last to check that records are still in input order.
+			time.Sleep(10 * time.Millisecond)
+			return []bookid.BookResult{{Title: "The Great Gatsby", Confidence: 0.9}}, nil
+		case "nonsense":
+			return nil, bookid.Errorf(bookid.EUNAVAILABLE, "Google Books is unavailable.")
+		default:
+			return nil, nil
+		}
+	}}
+	var buf bytes.Buffer
+	cmd := &BatchCommand{
+		Config: Config{DBPath: filepath.Join(t.TempDir(), "catalog.db")},
+		Stdin:  strings.NewReader("gatsby\n# a comment\nnonsense\n\nunknown\n"),
+		Stdout: &buf,
+		Finder: finder,
+	}
+	require.NoError(t, cmd.Run(context.Background(), []string{"-workers", "3"}), "failed lookups do not abort the batch")
+
+	lines := readBatchLines(t, buf.Bytes())
+	require.Len(t, lines, 3)
+
+	assert.Equal(t, 1, lines[0].Line)
+	assert.Equal(t, "gatsby", lines[0].Query)
+	require.NotNil(t, lines[0].Result)
+	assert.Equal(t, "The Great Gatsby", lines[0].Result.Title)
+	assert.Empty(t, lines[0].Error)
+
+	assert.Equal(t, 3, lines[1].Line)
+	assert.Equal(t, "nonsense", lines[1].Query)
+	assert.Nil(t, lines[1].Result)
+	assert.Equal(t, "Google Books is unavailable.", lines[1].Error)
+
+	assert.Equal(t, 5, lines[2].Line)
+	assert.Nil(t, lines[2].Result)
+	assert.Empty(t, lines[2].Error)
+
+	// The error field is left out of records without one.
+	first, _, _ := strings.Cut(buf.String(), "\n")
+	assert.NotContains(t, first, `"error"`)
+}
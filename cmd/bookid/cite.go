@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/citation"
+)
+
+// CiteCommand represents a command for identifying a book and printing a
+// bibliography entry for it.
+type CiteCommand struct {
+	Config Config
+	Stdout io.Writer
+}
+
+// Run executes the command.
+func (c *CiteCommand) Run(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("bookid-cite", flag.ContinueOnError)
+	style := fs.String("style", citation.StyleBibTeX, "citation style: "+strings.Join(citation.Styles(), ", "))
+	key := fs.String("key", "", "citation key; generated from the author and year if empty")
+	lowConfidence := fs.Float64("low-confidence", defaultLowConfidence, "exit with status 3 if the best result's confidence is below this")
+	fs.Usage = func() { c.usage(fs) }
+	if err := parseFlags(ctx, fs, args); err != nil {
+		return err
+	} else if fs.NArg() == 0 {
+		return fmt.Errorf("usage: bookid cite [flags] <query>")
+	} else if !slices.Contains(citation.Styles(), *style) {
+		return bookid.Errorf(bookid.EINVALID, "Unknown citation style %q, must be one of: %s.", *style, strings.Join(citation.Styles(), ", "))
+	}
+	query := strings.Join(fs.Args(), " ")
+
+	db, err := openDB(c.Config)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	finder, err := newFinder(c.Config, db)
+	if err != nil {
+		return err
+	}
+
+	results, err := finder.Search(ctx, query, bookid.SearchOptions{MaxResults: 1})
+	if err != nil {
+		return err
+	} else if len(results) == 0 {
+		return noResults(query)
+	}
+
+	entry := citation.FromResult(results[0])
+	entry.Key = *key
+	if err := citation.Write(c.Stdout, *style, []*citation.Entry{entry}); err != nil {
+		return err
+	}
+	return checkConfidence(results, *lowConfidence)
+}
+
+// usage prints the help text for the command.
+func (c *CiteCommand) usage(fs *flag.FlagSet) {
+	fmt.Fprintln(os.Stderr, strings.TrimSpace(`
+Identifies a book and prints a bibliography entry for the top result, ready
+to paste into a BibTeX file, import into a reference manager or pass to a
+citeproc processor such as pandoc.
+
+Usage:
+
+	bookid cite [flags] <query>
+
+Flags:
+`))
+	fs.PrintDefaults()
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/classify"
+	"github.com/fwojciec/bookid/sqlite"
+)
+
+// ClassifyCommand represents a command for looking up the Dewey and LC
+// classification numbers of publications.
+type ClassifyCommand struct {
+	Config Config
+	Stdout io.Writer
+}
+
+// Run executes the command.
+func (c *ClassifyCommand) Run(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("bookid-classify", flag.ContinueOnError)
+	fs.Usage = func() { c.usage(fs) }
+	if err := parseFlags(ctx, fs, args); err != nil {
+		return err
+	}
+
+	ids, err := parseIDs(fs.Args())
+	if err != nil {
+		return err
+	}
+
+	db, err := openDB(c.Config)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	providers, err := c.newClassificationProviders()
+	if err != nil {
+		return err
+	}
+	s := classify.NewService(sqlite.NewPublicationService(db), providers...)
+
+	// Without IDs, classify the whole catalog.
+	if len(ids) == 0 {
+		classified, missing, err := s.Backfill(ctx)
+		if err != nil {
+			return err
+		}
+		if missing == nil {
+			missing = []int64{}
+		}
+		return writeJSON(c.Stdout, struct {
+			Classified int     `json:"classified"`
+			Missing    []int64 `json:"missing"`
+		}{classified, missing})
+	}
+
+	pubs := make([]*bookid.Publication, 0, len(ids))
+	for _, id := range ids {
+		pub, err := s.Classify(ctx, id)
+		if err != nil {
+			return fmt.Errorf("publication %d: %w", id, err)
+		}
+		pubs = append(pubs, pub)
+	}
+	return writeJSON(c.Stdout, pubs)
+}
+
+// newClassificationProviders returns the registered providers that record
+// classification numbers: the configured ones in order of preference, then
+// the others by name. Providers without credentials or configuration are
+// skipped.
+func (c *ClassifyCommand) newClassificationProviders() ([]classify.Provider, error) {
+	if err := requireOnline(c.Config); err != nil {
+		return nil, err
+	}
+	names := slices.Clone(c.Config.Providers)
+	for _, name := range bookid.Finders() {
+		if !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+
+	var providers []classify.Provider
+	for _, name := range names {
+		profile := c.Config.Profiles[name]
+		profile.Logger = c.Config.logger()
+		finder, err := bookid.NewFinder(name, profile)
+		if bookid.ErrorCode(err) == bookid.EUNAUTHORIZED {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		if f, ok := finder.(bookid.ClassificationFinder); ok {
+			providers = append(providers, classify.Provider{Name: name, Finder: f})
+		}
+	}
+	if len(providers) == 0 {
+		return nil, bookid.Errorf(bookid.EINVALID, "No configured providers record classifications.")
+	}
+	return providers, nil
+}
+
+// usage prints the help text for the command.
+func (c *ClassifyCommand) usage(fs *flag.FlagSet) {
+	fmt.Fprintln(os.Stderr, strings.TrimSpace(`
+Looks up the Dewey Decimal and Library of Congress classification numbers of
+the given publications, or of every cataloged publication without either, and
+stores them for shelving. Publications are looked up by ISBN in Open Library
+and, when an SRU endpoint such as the Library of Congress's is configured, in
+its catalog. Numbers already stored are kept.
+
+The numbers are exported in the dewey and lcc columns of csv and xlsx exports
+and as call numbers (050 and 082) of MARC exports.
+
+Usage:
+
+	bookid classify [publication-id...]
+`))
+	fs.PrintDefaults()
+}
@@ -0,0 +1,221 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/sqlite"
+)
+
+// CollectionCommand represents a command for organizing cataloged works into
+// user-defined collections.
+type CollectionCommand struct {
+	Config Config
+	Stdout io.Writer
+}
+
+// Run executes the command.
+func (c *CollectionCommand) Run(ctx context.Context, args []string) error {
+	var cmd string
+	if len(args) > 0 {
+		cmd, args = args[0], args[1:]
+	}
+
+	switch cmd {
+	case "create":
+		return c.runCreate(ctx, args)
+	case "add":
+		return c.runMembers(ctx, "add", args)
+	case "remove":
+		return c.runMembers(ctx, "remove", args)
+	case "list":
+		return c.runList(ctx, args)
+	case "delete":
+		return c.runDelete(ctx, args)
+	case "", "-h", "-help", "--help", "help":
+		c.usage()
+		return flag.ErrHelp
+	default:
+		return fmt.Errorf("bookid collection %s: unknown command", cmd)
+	}
+}
+
+// runCreate creates a collection.
+func (c *CollectionCommand) runCreate(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("bookid-collection-create", flag.ContinueOnError)
+	description := fs.String("description", "", "what the collection is for")
+	fs.Usage = c.usage
+	if err := parseFlags(ctx, fs, args); err != nil {
+		return err
+	} else if fs.NArg() != 1 {
+		return fmt.Errorf("usage: bookid collection create [-description text] <name>")
+	}
+
+	db, err := openDB(c.Config)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	collection := &bookid.Collection{Name: fs.Arg(0), Description: *description}
+	if err := sqlite.NewCollectionService(db).CreateCollection(ctx, collection); err != nil {
+		return err
+	}
+	return writeJSON(c.Stdout, collection)
+}
+
+// runMembers adds works to a collection or removes them from it.
+func (c *CollectionCommand) runMembers(ctx context.Context, cmd string, args []string) error {
+	fs := flag.NewFlagSet("bookid-collection-"+cmd, flag.ContinueOnError)
+	fs.Usage = c.usage
+	if err := parseFlags(ctx, fs, args); err != nil {
+		return err
+	} else if fs.NArg() < 2 {
+		return fmt.Errorf("usage: bookid collection %s <name> <work-id>...", cmd)
+	}
+
+	ids, err := parseIDs(fs.Args()[1:])
+	if err != nil {
+		return err
+	}
+
+	db, err := openDB(c.Config)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	s := sqlite.NewCollectionService(db)
+
+	collection, err := findCollectionByName(ctx, s, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		if cmd == "add" {
+			err = s.AddCollectionWork(ctx, collection.ID, id)
+		} else {
+			err = s.RemoveCollectionWork(ctx, collection.ID, id)
+		}
+		if err != nil {
+			return fmt.Errorf("work %d: %w", id, err)
+		}
+	}
+	return c.writeCollection(ctx, db, collection)
+}
+
+// runList prints the collections or, given a name, the works in one.
+func (c *CollectionCommand) runList(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("bookid-collection-list", flag.ContinueOnError)
+	fs.Usage = c.usage
+	if err := parseFlags(ctx, fs, args); err != nil {
+		return err
+	} else if fs.NArg() > 1 {
+		return fmt.Errorf("usage: bookid collection list [name]")
+	}
+
+	db, err := openDB(c.Config)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	s := sqlite.NewCollectionService(db)
+
+	if fs.NArg() == 1 {
+		collection, err := findCollectionByName(ctx, s, fs.Arg(0))
+		if err != nil {
+			return err
+		}
+		return c.writeCollection(ctx, db, collection)
+	}
+
+	collections, n, err := s.FindCollections(ctx, bookid.CollectionFilter{})
+	if err != nil {
+		return err
+	}
+	return writeJSON(c.Stdout, struct {
+		Collections []*bookid.Collection `json:"collections"`
+		Total       int                  `json:"total"`
+	}{collections, n})
+}
+
+// runDelete deletes a collection, keeping its works.
+func (c *CollectionCommand) runDelete(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("bookid-collection-delete", flag.ContinueOnError)
+	fs.Usage = c.usage
+	if err := parseFlags(ctx, fs, args); err != nil {
+		return err
+	} else if fs.NArg() != 1 {
+		return fmt.Errorf("usage: bookid collection delete <name>")
+	}
+
+	db, err := openDB(c.Config)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	s := sqlite.NewCollectionService(db)
+
+	collection, err := findCollectionByName(ctx, s, fs.Arg(0))
+	if err != nil {
+		return err
+	} else if err := s.DeleteCollection(ctx, collection.ID); err != nil {
+		return err
+	}
+	return writeJSON(c.Stdout, struct {
+		Deleted *bookid.Collection `json:"deleted"`
+	}{collection})
+}
+
+// writeCollection prints a collection along with its works.
+func (c *CollectionCommand) writeCollection(ctx context.Context, db *sqlite.DB, collection *bookid.Collection) error {
+	works, _, err := sqlite.NewWorkService(db).FindWorks(ctx, bookid.WorkFilter{CollectionID: &collection.ID})
+	if err != nil {
+		return err
+	}
+	return writeJSON(c.Stdout, struct {
+		*bookid.Collection
+		Works []*bookid.Work `json:"works"`
+	}{collection, works})
+}
+
+// findCollectionByName returns the collection with a name, ignoring case.
+// Returns ENOTFOUND if there is none.
+func findCollectionByName(ctx context.Context, s bookid.CollectionService, name string) (*bookid.Collection, error) {
+	collections, _, err := s.FindCollections(ctx, bookid.CollectionFilter{Name: &name})
+	if err != nil {
+		return nil, err
+	} else if len(collections) == 0 {
+		return nil, bookid.Errorf(bookid.ENOTFOUND, "Collection %q not found.", name)
+	}
+	return collections[0], nil
+}
+
+// usage prints the help text for the command.
+func (c *CollectionCommand) usage() {
+	fmt.Fprintln(os.Stderr, strings.TrimSpace(`
+Organizes works of the catalog into collections, such as "to-read" or
+"office library". A work may be in any number of collections. Collection
+names are matched ignoring case.
+
+Usage:
+
+	bookid collection create [-description text] <name>
+	bookid collection add <name> <work-id>...
+	bookid collection remove <name> <work-id>...
+	bookid collection list [name]
+	bookid collection delete <name>
+
+The commands are:
+
+	create  create an empty collection
+	add     add works to a collection
+	remove  remove works from a collection
+	list    list the collections, or the works in the named one
+	delete  delete a collection; its works stay in the catalog
+`))
+}
@@ -0,0 +1,293 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// runner is implemented by the commands of the CLI.
+type runner interface {
+	Run(ctx context.Context, args []string) error
+}
+
+// command describes a command of the CLI. The dispatcher, the top-level help
+// text, shell completion and the man page are all built from the list
+// returned by commands, and the flags of each command are read from its own
+// flag set by describe.
+type command struct {
+	Name    string
+	Summary string
+
+	// Subcommands of commands, such as "collection", that take the name of
+	// one as their first argument. Subcommands have no New function.
+	Subcommands []*command
+
+	// Returns the command, writing its output to stdout.
+	New func(config Config, stdout io.Writer) runner
+}
+
+// commands returns the commands of the CLI in the order they are listed in
+// the help text.
+func commands() []*command {
+	return []*command{
+		{Name: "search", Summary: "identify a book and print the top result", New: func(config Config, stdout io.Writer) runner {
+			return &SearchCommand{Config: config, Stdout: stdout}
+		}},
+		{Name: "save", Summary: "identify a book and save the top result to the catalog", New: func(config Config, stdout io.Writer) runner {
+			return &SaveCommand{Config: config, Stdout: stdout}
+		}},
+		{Name: "update", Summary: "record reading status, rating and notes on a publication", New: func(config Config, stdout io.Writer) runner {
+			return &UpdateCommand{Config: config, Stdout: stdout}
+		}},
+		{Name: "cite", Summary: "identify a book and print a citation (BibTeX, RIS, CSL-JSON)", New: func(config Config, stdout io.Writer) runner {
+			return &CiteCommand{Config: config, Stdout: stdout}
+		}},
+		{Name: "compare", Summary: "compare the top results of every provider field by field", New: func(config Config, stdout io.Writer) runner {
+			return &CompareCommand{Config: config, Stdout: stdout}
+		}},
+		{Name: "batch", Summary: "identify one book per line of a file or stdin", New: func(config Config, stdout io.Writer) runner {
+			return &BatchCommand{Config: config, Stdin: os.Stdin, Stdout: stdout}
+		}},
+		{Name: "scan", Summary: "identify books from photos of their ISBN barcodes", New: func(config Config, stdout io.Writer) runner {
+			return &ScanCommand{Config: config, Stdout: stdout}
+		}},
+		{Name: "list", Summary: "list works in the catalog", New: func(config Config, stdout io.Writer) runner {
+			return &ListCommand{Config: config, Stdout: stdout}
+		}},
+		{Name: "show", Summary: "show a work with its authors and publications", New: func(config Config, stdout io.Writer) runner {
+			return &ShowCommand{Config: config, Stdout: stdout}
+		}},
+		{
+			Name:        "collection",
+			Summary:     `organize works into collections such as "to-read"`,
+			Subcommands: subcommands("create", "add", "remove", "list", "delete"),
+			New: func(config Config, stdout io.Writer) runner {
+				return &CollectionCommand{Config: config, Stdout: stdout}
+			},
+		},
+		{
+			Name:        "loan",
+			Summary:     "check copies out to borrowers and list overdue loans",
+			Subcommands: subcommands("checkout", "return", "list", "overdue"),
+			New: func(config Config, stdout io.Writer) runner {
+				return &LoanCommand{Config: config, Stdout: stdout}
+			},
+		},
+		{Name: "history", Summary: "show the changes made to a work and its publications", New: func(config Config, stdout io.Writer) runner {
+			return &HistoryCommand{Config: config, Stdout: stdout}
+		}},
+		{Name: "export", Summary: "export the catalog for library systems and publishers", New: func(config Config, stdout io.Writer) runner {
+			return &ExportCommand{Config: config, Stdout: stdout}
+		}},
+		{Name: "import", Summary: "add records from other systems to the catalog", New: func(config Config, stdout io.Writer) runner {
+			return &ImportCommand{Config: config, Stdin: os.Stdin, Stdout: stdout}
+		}},
+		{Name: "dedup", Summary: "find and merge duplicate works in the catalog", New: func(config Config, stdout io.Writer) runner {
+			return &DedupCommand{Config: config, Stdout: stdout}
+		}},
+		{Name: "rebuild-works", Summary: "re-cluster publications into works with the current matching rules", New: func(config Config, stdout io.Writer) runner {
+			return &RebuildWorksCommand{Config: config, Stdout: stdout}
+		}},
+		{
+			Name:        "trash",
+			Summary:     "list, restore and purge deleted works and publications",
+			Subcommands: subcommands("list", "restore", "purge"),
+			New: func(config Config, stdout io.Writer) runner {
+				return &TrashCommand{Config: config, Stdout: stdout}
+			},
+		},
+		{Name: "backup", Summary: "copy the catalog database to a file, optionally gzipped", New: func(config Config, stdout io.Writer) runner {
+			return &BackupCommand{Config: config, Stdout: stdout}
+		}},
+		{Name: "restore", Summary: "replace the catalog database with a backup", New: func(config Config, stdout io.Writer) runner {
+			return &RestoreCommand{Config: config, Stdout: stdout}
+		}},
+		{Name: "sync", Summary: "sync the catalog both ways with another database or server", New: func(config Config, stdout io.Writer) runner {
+			return &SyncCommand{Config: config, Stdout: stdout}
+		}},
+		{Name: "covers", Summary: "download and store cover images of publications", New: func(config Config, stdout io.Writer) runner {
+			return &CoversCommand{Config: config, Stdout: stdout}
+		}},
+		{Name: "classify", Summary: "look up Dewey and LC classification numbers of publications", New: func(config Config, stdout io.Writer) runner {
+			return &ClassifyCommand{Config: config, Stdout: stdout}
+		}},
+		{Name: "refresh", Summary: "re-fetch stale publications from their providers", New: func(config Config, stdout io.Writer) runner {
+			return &RefreshCommand{Config: config, Stdout: stdout}
+		}},
+		{
+			Name:        "conflicts",
+			Summary:     "review field values that providers disagree on",
+			Subcommands: subcommands("list", "accept", "reject"),
+			New: func(config Config, stdout io.Writer) runner {
+				return &ConflictsCommand{Config: config, Stdout: stdout}
+			},
+		},
+		{
+			Name:        "translations",
+			Summary:     "link translated works to their originals",
+			Subcommands: subcommands("list", "suggest", "link"),
+			New: func(config Config, stdout io.Writer) runner {
+				return &TranslationsCommand{Config: config, Stdout: stdout}
+			},
+		},
+		{
+			Name:        "publishers",
+			Summary:     "list publishers and merge variants of their names",
+			Subcommands: subcommands("list", "merge"),
+			New: func(config Config, stdout io.Writer) runner {
+				return &PublishersCommand{Config: config, Stdout: stdout}
+			},
+		},
+		{
+			Name:        "offers",
+			Summary:     "fetch and list the prices and availability of publications",
+			Subcommands: subcommands("fetch", "list"),
+			New: func(config Config, stdout io.Writer) runner {
+				return &OffersCommand{Config: config, Stdout: stdout}
+			},
+		},
+		{Name: "review", Summary: "pick the right candidate of books identified with low confidence", New: func(config Config, stdout io.Writer) runner {
+			return &ReviewCommand{Config: config, Stdin: os.Stdin, Stdout: stdout}
+		}},
+		{Name: "link", Summary: "link an author to their VIAF and Wikidata records", New: func(config Config, stdout io.Writer) runner {
+			return &LinkCommand{Config: config, Stdout: stdout}
+		}},
+		{
+			Name:    "jobs",
+			Summary: "queue long-running imports, refreshes and cover backfills",
+			Subcommands: append(
+				[]*command{{Name: "add", Subcommands: subcommands("import", "refresh", "covers")}},
+				subcommands("run", "list", "status", "cancel")...,
+			),
+			New: func(config Config, stdout io.Writer) runner {
+				return &JobsCommand{Config: config, Stdout: stdout}
+			},
+		},
+		{Name: "serve", Summary: "run the HTTP API server and, optionally, the gRPC server", New: func(config Config, stdout io.Writer) runner {
+			return &ServeCommand{Config: config, Stdout: stdout}
+		}},
+		{Name: "mcp", Summary: "serve bookid tools to LLM agents over the Model Context Protocol", New: func(config Config, _ io.Writer) runner {
+			return &MCPCommand{Config: config}
+		}},
+		{Name: "completion", Summary: "print a shell completion script for bash, zsh or fish", New: func(config Config, stdout io.Writer) runner {
+			return &CompletionCommand{Config: config, Stdout: stdout}
+		}},
+		{Name: "man", Summary: "print the manual page", New: func(config Config, stdout io.Writer) runner {
+			return &ManCommand{Config: config, Stdout: stdout}
+		}},
+	}
+}
+
+// subcommands returns subcommands with the given names.
+func subcommands(names ...string) []*command {
+	cmds := make([]*command, len(names))
+	for i, name := range names {
+		cmds[i] = &command{Name: name}
+	}
+	return cmds
+}
+
+// findCommand returns the command with a name, or nil if there is none.
+func findCommand(name string) *command {
+	for _, c := range commands() {
+		if c.Name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+// contextKey represents an internal key for adding context fields.
+type contextKey int
+
+// List of context keys.
+const (
+	// Stores the flag set of a command run by describe.
+	flagSetContextKey = contextKey(iota + 1)
+)
+
+// errDescribed is returned by parseFlags to stop a command run by describe.
+var errDescribed = errors.New("command described")
+
+// parseFlags parses the arguments of a command with its flag set. Commands
+// must parse their flags with it before doing anything else: a command run
+// by describe stops here, handing over its flag set instead.
+func parseFlags(ctx context.Context, fs *flag.FlagSet, args []string) error {
+	if described, ok := ctx.Value(flagSetContextKey).(**flag.FlagSet); ok {
+		*described = fs
+		return errDescribed
+	}
+	return fs.Parse(args)
+}
+
+// commandDoc documents a command, or a subcommand, for shell completion and
+// the man page.
+type commandDoc struct {
+	// Names of the command and its parent commands, e.g. "jobs add import".
+	// Empty for bookid itself.
+	Path    []string
+	Summary string
+
+	Flags       []*flag.Flag
+	Subcommands []*command
+}
+
+// Name returns the full name of the command, e.g. "bookid jobs add import".
+func (d *commandDoc) Name() string {
+	return strings.Join(append([]string{"bookid"}, d.Path...), " ")
+}
+
+// describe returns the documentation of bookid and of every command and
+// subcommand, parents first. Flags are read by running each command that has
+// no subcommands until it parses its arguments.
+func describe(ctx context.Context, config Config) ([]*commandDoc, error) {
+	var globals globalFlags
+	root := &commandDoc{Flags: flagsOf(newGlobalFlagSet(&globals)), Subcommands: commands()}
+	docs := []*commandDoc{root}
+
+	var walk func(top *command, c *command, path []string) error
+	walk = func(top *command, c *command, path []string) error {
+		doc := &commandDoc{Path: path, Summary: c.Summary, Subcommands: c.Subcommands}
+		docs = append(docs, doc)
+		if len(c.Subcommands) > 0 {
+			for _, sub := range c.Subcommands {
+				if err := walk(top, sub, append(path[:len(path):len(path)], sub.Name)); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+
+		var fs *flag.FlagSet
+		describeCtx := context.WithValue(ctx, flagSetContextKey, &fs)
+		if err := top.New(config, io.Discard).Run(describeCtx, path[1:]); !errors.Is(err, errDescribed) {
+			return fmt.Errorf("describing %s: command did not parse its flags: %v", doc.Name(), err)
+		}
+		doc.Flags = flagsOf(fs)
+		return nil
+	}
+	for _, c := range commands() {
+		if err := walk(c, c, []string{c.Name}); err != nil {
+			return nil, err
+		}
+	}
+	return docs, nil
+}
+
+// flagsOf returns the flags defined on fs in lexical order.
+func flagsOf(fs *flag.FlagSet) []*flag.Flag {
+	var flags []*flag.Flag
+	fs.VisitAll(func(f *flag.Flag) { flags = append(flags, f) })
+	return flags
+}
+
+// isBoolFlag returns true if f takes no value.
+func isBoolFlag(f *flag.Flag) bool {
+	b, ok := f.Value.(interface{ IsBoolFlag() bool })
+	return ok && b.IsBoolFlag()
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/compare"
+	"github.com/fwojciec/bookid/language"
+	"github.com/fwojciec/bookid/match"
+	"github.com/fwojciec/bookid/render"
+)
+
+// CompareCommand represents a command for comparing the top results of all
+// configured providers for a query.
+type CompareCommand struct {
+	Config Config
+	Stdout io.Writer
+}
+
+// Run executes the command.
+func (c *CompareCommand) Run(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("bookid-compare", flag.ContinueOnError)
+	format := fs.String("format", render.FormatTable, "output format: table, json")
+	var opts bookid.SearchOptions
+	fs.StringVar(&opts.Language, "lang", "", "restrict results to a language, e.g. en or pt-BR")
+	fs.Usage = func() { c.usage(fs) }
+	if err := parseFlags(ctx, fs, args); err != nil {
+		return err
+	} else if fs.NArg() == 0 {
+		return fmt.Errorf("usage: bookid compare [flags] <query>")
+	} else if *format != render.FormatTable && *format != render.FormatJSON {
+		return bookid.Errorf(bookid.EINVALID, "Unknown format %q, must be one of: table, json.", *format)
+	} else if err := opts.Validate(); err != nil {
+		return err
+	}
+	query := strings.Join(fs.Args(), " ")
+
+	// Providers are searched directly, bypassing the search cache, with
+	// their results scored against the query as usual.
+	logger := c.Config.logger()
+	fallbackProviders, err := newProviders(c.Config, logger, nil)
+	if err != nil {
+		return err
+	}
+	providers := make([]compare.Provider, len(fallbackProviders))
+	for i, p := range fallbackProviders {
+		timeout := p.Timeout
+		if timeout == 0 {
+			timeout = c.Config.Timeout
+		}
+		providers[i] = compare.Provider{
+			Name:    p.Name,
+			Finder:  match.NewFinder(language.NewFinder(p.Finder)),
+			Timeout: timeout,
+		}
+	}
+
+	comparison, err := compare.Search(ctx, providers, query, opts)
+	if err != nil {
+		return err
+	}
+	if *format == render.FormatJSON {
+		err = writeJSON(c.Stdout, comparison)
+	} else {
+		err = writeComparison(c.Stdout, comparison)
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, a := range comparison.Answers {
+		if a.Result != nil {
+			return nil
+		}
+	}
+	return errNoResults
+}
+
+// writeComparison writes a comparison as a table with a column per provider
+// and a row per field. Fields the providers disagree on are marked with an
+// asterisk, and providers without a result are listed below the table.
+func writeComparison(w io.Writer, c *compare.Comparison) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	header := []string{"FIELD"}
+	for _, a := range c.Answers {
+		header = append(header, strings.ToUpper(a.Provider))
+	}
+	fmt.Fprintln(tw, strings.Join(header, "\t"))
+
+	for _, f := range c.Fields {
+		name := f.Name
+		if f.Disagree {
+			name += " *"
+		}
+		row := []string{name}
+		for i, a := range c.Answers {
+			v := f.Values[i]
+			if a.Result == nil {
+				v = "-"
+			}
+			row = append(row, v)
+		}
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	var notes []string
+	if len(c.Disagreements()) > 0 {
+		notes = append(notes, "* providers disagree")
+	}
+	for _, a := range c.Answers {
+		if a.Error != "" {
+			notes = append(notes, fmt.Sprintf("%s: %s", a.Provider, a.Error))
+		} else if a.Result == nil {
+			notes = append(notes, fmt.Sprintf("%s: no result", a.Provider))
+		}
+	}
+	if len(notes) > 0 {
+		if _, err := fmt.Fprintf(w, "\n%s\n", strings.Join(notes, "\n")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// usage prints the help text for the command.
+func (c *CompareCommand) usage(fs *flag.FlagSet) {
+	fmt.Fprintln(os.Stderr, strings.TrimSpace(`
+Searches every configured provider for a book at the same time and compares
+their top results field by field: title, authors, year, publisher, ISBNs and
+language. Fields the providers disagree on are marked, ignoring differences
+of case, punctuation and the order of authors, to help judge the quality of
+each provider's metadata before saving the book.
+
+Usage:
+
+	bookid compare [flags] <query>
+
+Flags:
+`))
+	fs.PrintDefaults()
+}
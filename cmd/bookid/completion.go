@@ -0,0 +1,238 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/fwojciec/bookid"
+)
+
+// CompletionCommand represents a command for printing shell completion
+// scripts.
+type CompletionCommand struct {
+	Config Config
+	Stdout io.Writer
+}
+
+// Run executes the command.
+func (c *CompletionCommand) Run(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("bookid-completion", flag.ContinueOnError)
+	fs.Usage = c.usage
+	if err := parseFlags(ctx, fs, args); err != nil {
+		return err
+	} else if fs.NArg() != 1 {
+		return fmt.Errorf("usage: bookid completion bash|zsh|fish")
+	}
+
+	var write func(w io.Writer, docs []*commandDoc) error
+	switch shell := fs.Arg(0); shell {
+	case "bash":
+		write = writeBashCompletion
+	case "zsh":
+		write = writeZshCompletion
+	case "fish":
+		write = writeFishCompletion
+	default:
+		return bookid.Errorf(bookid.EINVALID, "Unknown shell %q, must be one of: bash, zsh, fish.", shell)
+	}
+
+	docs, err := describe(ctx, c.Config)
+	if err != nil {
+		return err
+	}
+	return write(c.Stdout, docs)
+}
+
+// completionWords returns the words completed for a command: the names of
+// its subcommands, its flags, and those of its flags that take a value.
+func completionWords(doc *commandDoc) (subs, flags, valueFlags string) {
+	var s, f, v []string
+	for _, sub := range doc.Subcommands {
+		s = append(s, sub.Name)
+	}
+	for _, fl := range doc.Flags {
+		f = append(f, "-"+fl.Name)
+		if !isBoolFlag(fl) {
+			v = append(v, "-"+fl.Name)
+		}
+	}
+	return strings.Join(s, " "), strings.Join(f, " "), strings.Join(v, " ")
+}
+
+// commandPaths returns a shell case pattern matching the paths of all
+// commands and subcommands, e.g. "search"|"jobs add import".
+func commandPaths(docs []*commandDoc) string {
+	var paths []string
+	for _, doc := range docs[1:] {
+		paths = append(paths, `"`+strings.Join(doc.Path, " ")+`"`)
+	}
+	return strings.Join(paths, "|")
+}
+
+// writeBashCompletion writes the bash completion script. The command being
+// completed is found by matching the words typed so far against the paths
+// of the commands, skipping flags and their values.
+func writeBashCompletion(w io.Writer, docs []*commandDoc) error {
+	var b strings.Builder
+	b.WriteString(`# bash completion for bookid. Generated by "bookid completion bash";
+# load it with: source <(bookid completion bash)
+
+_bookid() {
+	local cur prev word candidate cmdpath="" subs="" opts="" valopts=""
+	cur="${COMP_WORDS[COMP_CWORD]}"
+	prev="${COMP_WORDS[COMP_CWORD-1]}"
+	for word in "${COMP_WORDS[@]:1:COMP_CWORD-1}"; do
+		[[ $word == -* ]] && continue
+		candidate="${cmdpath:+$cmdpath }$word"
+		case "$candidate" in
+		` + commandPaths(docs) + `) cmdpath="$candidate" ;;
+		esac
+	done
+
+	case "$cmdpath" in
+`)
+	for _, doc := range docs {
+		subs, flags, valueFlags := completionWords(doc)
+		fmt.Fprintf(&b, "\t%q) subs=%q opts=%q valopts=%q ;;\n", strings.Join(doc.Path, " "), subs, flags, valueFlags)
+	}
+	b.WriteString(`	esac
+
+	if [[ " $valopts " == *" $prev "* ]]; then
+		COMPREPLY=($(compgen -f -- "$cur"))
+	elif [[ $cur == -* ]]; then
+		COMPREPLY=($(compgen -W "$opts" -- "$cur"))
+	elif [[ -n $subs ]]; then
+		COMPREPLY=($(compgen -W "$subs" -- "$cur"))
+	else
+		COMPREPLY=($(compgen -f -- "$cur"))
+	fi
+}
+
+complete -F _bookid bookid
+`)
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeZshCompletion writes the zsh completion script, which finds the
+// command being completed as the bash script does.
+func writeZshCompletion(w io.Writer, docs []*commandDoc) error {
+	var b strings.Builder
+	b.WriteString(`#compdef bookid
+# zsh completion for bookid. Generated by "bookid completion zsh";
+# load it with: source <(bookid completion zsh)
+
+_bookid() {
+	local word candidate cmdpath="" subs="" opts="" valopts=""
+	for word in "${(@)words[2,CURRENT-1]}"; do
+		[[ $word == -* ]] && continue
+		candidate="${cmdpath:+$cmdpath }$word"
+		case "$candidate" in
+		` + commandPaths(docs) + `) cmdpath="$candidate" ;;
+		esac
+	done
+
+	case "$cmdpath" in
+`)
+	for _, doc := range docs {
+		subs, flags, valueFlags := completionWords(doc)
+		fmt.Fprintf(&b, "\t%q) subs=%q opts=%q valopts=%q ;;\n", strings.Join(doc.Path, " "), subs, flags, valueFlags)
+	}
+	b.WriteString(`	esac
+
+	if [[ " $valopts " == *" ${words[CURRENT-1]} "* ]]; then
+		_files
+	elif [[ ${words[CURRENT]} == -* ]]; then
+		compadd -- ${=opts}
+	elif [[ -n $subs ]]; then
+		compadd -- ${=subs}
+	else
+		_files
+	fi
+}
+
+compdef _bookid bookid
+`)
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeFishCompletion writes the fish completion script. Commands and flags
+// are described with their summaries and usage.
+func writeFishCompletion(w io.Writer, docs []*commandDoc) error {
+	var paths []string
+	for _, doc := range docs[1:] {
+		paths = append(paths, fishQuote(strings.Join(doc.Path, " ")))
+	}
+
+	var b strings.Builder
+	b.WriteString(`# fish completion for bookid. Generated by "bookid completion fish";
+# load it with: bookid completion fish | source
+
+# __bookid_path prints the command being completed, e.g. "bookid jobs add".
+function __bookid_path
+	set -l cmdpath
+	for word in (commandline -opc)[2..-1]
+		string match -q -- '-*' $word; and continue
+		set -l candidate (string join ' ' $cmdpath $word)
+		if contains -- $candidate ` + strings.Join(paths, " ") + `
+			set cmdpath $candidate
+		end
+	end
+	string join ' ' bookid $cmdpath
+end
+
+function __bookid_at
+	test (__bookid_path) = $argv[1]
+end
+
+`)
+	for _, doc := range docs {
+		cond := fishQuote("__bookid_at " + fishQuote(doc.Name()))
+		for _, sub := range doc.Subcommands {
+			fmt.Fprintf(&b, "complete -c bookid -f -n %s -a %s", cond, fishQuote(sub.Name))
+			if sub.Summary != "" {
+				fmt.Fprintf(&b, " -d %s", fishQuote(sub.Summary))
+			}
+			b.WriteString("\n")
+		}
+		for _, f := range doc.Flags {
+			fmt.Fprintf(&b, "complete -c bookid -n %s -o %s", cond, fishQuote(f.Name))
+			if !isBoolFlag(f) {
+				b.WriteString(" -r")
+			}
+			_, usage := flag.UnquoteUsage(f)
+			fmt.Fprintf(&b, " -d %s\n", fishQuote(usage))
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// fishQuote returns s as a single-quoted fish string.
+func fishQuote(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s) + "'"
+}
+
+// usage prints the help text for the command.
+func (c *CompletionCommand) usage() {
+	fmt.Fprintln(os.Stderr, strings.TrimSpace(`
+Prints a script completing the commands, subcommands and flags of bookid in
+the given shell. The script is generated from the commands themselves, so it
+stays in step with the installed version.
+
+To load completions in the current shell:
+
+	bash   source <(bookid completion bash)
+	zsh    source <(bookid completion zsh)
+	fish   bookid completion fish | source
+
+Usage:
+
+	bookid completion bash|zsh|fish
+`))
+}
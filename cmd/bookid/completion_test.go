@@ -0,0 +1,58 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// update rewrites the golden files with the current output.
+var update = flag.Bool("update", false, "update golden files")
+
+// assertGolden compares got with the golden file testdata/name, rewriting it
+// instead when run with -update. Adding, renaming or removing commands or
+// flags shows up as a change of the golden files.
+func assertGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		require.NoError(t, os.WriteFile(path, got, 0o644))
+	}
+	want, err := os.ReadFile(path)
+	require.NoError(t, err, "run go test ./cmd/bookid -update to create the golden files")
+	assert.Equal(t, string(want), string(got), "run go test ./cmd/bookid -update if the change is intended")
+}
+
+func TestCompletionCommand(t *testing.T) {
+	t.Parallel()
+
+	for _, shell := range []string{"bash", "zsh", "fish"} {
+		t.Run(shell, func(t *testing.T) {
+			t.Parallel()
+			var buf bytes.Buffer
+			cmd := &CompletionCommand{Stdout: &buf}
+			require.NoError(t, cmd.Run(context.Background(), []string{shell}))
+			assertGolden(t, "completion."+shell, buf.Bytes())
+		})
+	}
+
+	t.Run("ErrUnknownShell", func(t *testing.T) {
+		t.Parallel()
+		cmd := &CompletionCommand{Stdout: &bytes.Buffer{}}
+		assert.Error(t, cmd.Run(context.Background(), []string{"powershell"}))
+	})
+}
+
+func TestManCommand(t *testing.T) {
+	t.Parallel()
+	var buf bytes.Buffer
+	cmd := &ManCommand{Stdout: &buf}
+	require.NoError(t, cmd.Run(context.Background(), nil))
+	assertGolden(t, "bookid.1", buf.Bytes())
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/sqlite"
+)
+
+// ConflictsCommand represents a command for reviewing the values of
+// publication fields that merge policies held back.
+type ConflictsCommand struct {
+	Config Config
+	Stdout io.Writer
+}
+
+// Run executes the command.
+func (c *ConflictsCommand) Run(ctx context.Context, args []string) error {
+	var cmd string
+	if len(args) > 0 {
+		cmd, args = args[0], args[1:]
+	}
+
+	switch cmd {
+	case "list":
+		return c.runList(ctx, args)
+	case "accept":
+		return c.runResolve(ctx, "accept", true, args)
+	case "reject":
+		return c.runResolve(ctx, "reject", false, args)
+	case "", "-h", "-help", "--help", "help":
+		c.usage()
+		return flag.ErrHelp
+	default:
+		return fmt.Errorf("bookid conflicts %s: unknown command", cmd)
+	}
+}
+
+// runList prints the open conflicts, or all of them with -all.
+func (c *ConflictsCommand) runList(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("bookid-conflicts-list", flag.ContinueOnError)
+	all := fs.Bool("all", false, "include resolved conflicts")
+	publication := fs.Int64("publication", 0, "only list conflicts of this publication")
+	fs.Usage = c.usage
+	if err := parseFlags(ctx, fs, args); err != nil {
+		return err
+	} else if fs.NArg() != 0 {
+		return fmt.Errorf("usage: bookid conflicts list [-all] [-publication id]")
+	}
+
+	db, err := openDB(c.Config)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	filter := bookid.ConflictFilter{Open: !*all}
+	if *publication != 0 {
+		filter.PublicationID = publication
+	}
+	conflicts, _, err := sqlite.NewConflictService(db).FindConflicts(ctx, filter)
+	if err != nil {
+		return err
+	}
+	return writeJSON(c.Stdout, conflicts)
+}
+
+// runResolve accepts or rejects conflicts by ID.
+func (c *ConflictsCommand) runResolve(ctx context.Context, name string, accept bool, args []string) error {
+	fs := flag.NewFlagSet("bookid-conflicts-"+name, flag.ContinueOnError)
+	fs.Usage = c.usage
+	if err := parseFlags(ctx, fs, args); err != nil {
+		return err
+	} else if fs.NArg() == 0 {
+		return fmt.Errorf("usage: bookid conflicts %s <id>...", name)
+	}
+
+	ids, err := parseIDs(fs.Args())
+	if err != nil {
+		return err
+	}
+
+	db, err := openDB(c.Config)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	resolved := make([]*bookid.Conflict, 0, len(ids))
+	for _, id := range ids {
+		conflict, err := sqlite.NewConflictService(db).ResolveConflict(ctx, id, accept)
+		if err != nil {
+			return fmt.Errorf("conflict %d: %w", id, err)
+		}
+		resolved = append(resolved, conflict)
+	}
+	return writeJSON(c.Stdout, resolved)
+}
+
+// usage prints the help text for the command.
+func (c *ConflictsCommand) usage() {
+	fmt.Fprintln(os.Stderr, strings.TrimSpace(`
+Reviews the values of publication fields that providers disagree on and
+that a "review" merge policy in the configuration file held back, e.g.:
+
+	[policies.publisher]
+	rule = "review"
+
+Conflicts are queued when a merged search result is saved or a publication
+is refreshed, and hold the current value and the proposed one along with
+the providers that supplied them.
+
+Usage:
+
+	bookid conflicts list [-all] [-publication id]
+	bookid conflicts accept <id>...
+	bookid conflicts reject <id>...
+
+The commands are:
+
+	list    list the open conflicts, or all of them with -all
+	accept  replace the current values with the proposed ones
+	reject  keep the current values
+`))
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/covers"
+	"github.com/fwojciec/bookid/sqlite"
+)
+
+// CoversCommand represents a command for downloading cover images.
+type CoversCommand struct {
+	Config Config
+	Stdout io.Writer
+}
+
+// Run executes the command.
+func (c *CoversCommand) Run(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("bookid-covers", flag.ContinueOnError)
+	fs.Usage = func() { c.usage(fs) }
+	if err := parseFlags(ctx, fs, args); err != nil {
+		return err
+	}
+
+	ids := make([]int64, 0, fs.NArg())
+	for _, arg := range fs.Args() {
+		id, err := strconv.ParseInt(arg, 10, 64)
+		if err != nil {
+			return bookid.Errorf(bookid.EINVALID, "Invalid publication ID %q.", arg)
+		}
+		ids = append(ids, id)
+	}
+	if err := requireOnline(c.Config); err != nil {
+		return err
+	}
+
+	db, err := openDB(c.Config)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	s := covers.NewService(
+		&http.Client{Timeout: c.Config.Timeout},
+		sqlite.NewPublicationService(db),
+		covers.NewStore(c.Config.CoverDir),
+	)
+
+	// Without IDs, backfill the whole catalog.
+	if len(ids) == 0 {
+		fetched, missing, err := s.Backfill(ctx)
+		if err != nil {
+			return err
+		}
+		if missing == nil {
+			missing = []int64{}
+		}
+		return writeJSON(c.Stdout, struct {
+			Fetched int     `json:"fetched"`
+			Missing []int64 `json:"missing"`
+		}{fetched, missing})
+	}
+
+	pubs := make([]*bookid.Publication, 0, len(ids))
+	for _, id := range ids {
+		pub, err := s.FetchCover(ctx, id)
+		if err != nil {
+			return fmt.Errorf("publication %d: %w", id, err)
+		}
+		pubs = append(pubs, pub)
+	}
+	return writeJSON(c.Stdout, pubs)
+}
+
+// usage prints the help text for the command.
+func (c *CoversCommand) usage(fs *flag.FlagSet) {
+	fmt.Fprintln(os.Stderr, strings.TrimSpace(`
+Downloads the cover images of the given publications, or of every cataloged
+publication without one, and stores them locally. The provider's thumbnail is
+used when it has one; otherwise the cover is looked up in Open Library.
+
+Covers are stored next to the catalog database, or in the directory named by
+BOOKID_COVERS.
+
+Usage:
+
+	bookid covers [publication-id...]
+`))
+	fs.PrintDefaults()
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/fwojciec/bookid/dedup"
+	"github.com/fwojciec/bookid/sqlite"
+)
+
+// DedupCommand represents a command for finding and merging duplicate works.
+type DedupCommand struct {
+	Config Config
+	Stdout io.Writer
+}
+
+// Run executes the command.
+func (c *DedupCommand) Run(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("bookid-dedup", flag.ContinueOnError)
+	apply := fs.Bool("apply", false, "merge the duplicates instead of only listing them")
+	fs.Usage = func() { c.usage(fs) }
+	if err := parseFlags(ctx, fs, args); err != nil {
+		return err
+	} else if fs.NArg() != 0 {
+		return fmt.Errorf("usage: bookid dedup [flags]")
+	}
+
+	db, err := openDB(c.Config)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	works := sqlite.NewWorkService(db)
+	detector := &dedup.Detector{
+		WorkService:         works,
+		AuthorService:       sqlite.NewAuthorService(db),
+		PublicationService:  sqlite.NewPublicationService(db),
+		WorkRelationService: sqlite.NewWorkRelationService(db),
+	}
+	merges, err := detector.Detect(ctx)
+	if err != nil {
+		return err
+	}
+
+	if *apply {
+		for _, m := range merges {
+			if err := works.MergeWorks(ctx, m.TargetID, m.SourceIDs...); err != nil {
+				return fmt.Errorf("merging into work %d: %w", m.TargetID, err)
+			}
+		}
+	}
+
+	if merges == nil {
+		merges = []*dedup.Merge{}
+	}
+	return writeJSON(c.Stdout, struct {
+		Merges  []*dedup.Merge `json:"merges"`
+		Applied bool           `json:"applied"`
+	}{merges, *apply})
+}
+
+// usage prints the help text for the command.
+func (c *DedupCommand) usage(fs *flag.FlagSet) {
+	fmt.Fprintln(os.Stderr, strings.TrimSpace(`
+Finds works cataloged more than once: works sharing an ISBN, and works with
+the same title and an author in common unless their publications are in
+different languages. Each group of duplicates is proposed to be merged into
+the work cataloged first, which takes over the publications, authors and
+relations of the others.
+
+Usage:
+
+	bookid dedup [flags]
+
+Flags:
+`))
+	fs.PrintDefaults()
+}
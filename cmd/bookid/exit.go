@@ -0,0 +1,90 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/fwojciec/bookid"
+)
+
+// Exit statuses of the process, so that scripts can tell a failed command
+// from a search that found nothing, or nothing certain.
+const (
+	exitOK            = 0
+	exitError         = 1
+	exitNoResults     = 2
+	exitLowConfidence = 3
+)
+
+// defaultLowConfidence is the confidence below which the best result of a
+// search exits with exitLowConfidence.
+const defaultLowConfidence = 0.5
+
+// errNoResults and errLowConfidence end a search command with
+// exitNoResults and exitLowConfidence. Returned as they are, after the
+// command's output is written, they are not reported as errors.
+var (
+	errNoResults     = errors.New("no results")
+	errLowConfidence = errors.New("low confidence")
+)
+
+// Formats of the errors written to stderr.
+const (
+	errorsText = "text"
+	errorsJSON = "json"
+)
+
+// noResults returns the error of a search for query that found no books.
+func noResults(query string) error {
+	return fmt.Errorf("%w: %w", errNoResults, bookid.Errorf(bookid.ENOTFOUND, "No books found for %q.", query))
+}
+
+// checkConfidence returns errLowConfidence if the best of results, which
+// comes first, has a confidence below threshold.
+func checkConfidence(results []bookid.BookResult, threshold float64) error {
+	if len(results) > 0 && results[0].Confidence < threshold {
+		return errLowConfidence
+	}
+	return nil
+}
+
+// exitCode returns the exit status of a command that returned err. Asking
+// for help with -h succeeds.
+func exitCode(err error) int {
+	switch {
+	case err == nil, errors.Is(err, flag.ErrHelp):
+		return exitOK
+	case errors.Is(err, errNoResults):
+		return exitNoResults
+	case errors.Is(err, errLowConfidence):
+		return exitLowConfidence
+	default:
+		return exitError
+	}
+}
+
+// writeError reports err on w in the given format, unless it only sets the
+// exit status.
+func writeError(w io.Writer, format string, err error) {
+	if err == nil || errors.Is(err, flag.ErrHelp) || err == errNoResults || err == errLowConfidence {
+		return
+	}
+
+	if format != errorsJSON {
+		fmt.Fprintf(w, "error: %s\n", errorMessage(err))
+		return
+	}
+	type errorObject struct {
+		Code     string `json:"code"`
+		Message  string `json:"message"`
+		ExitCode int    `json:"exit_code"`
+	}
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(struct {
+		Error errorObject `json:"error"`
+	}{errorObject{Code: bookid.ErrorCode(err), Message: errorMessage(err), ExitCode: exitCode(err)}})
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"testing"
+
+	"github.com/fwojciec/bookid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExitCode(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"nil", nil, exitOK},
+		{"no results", noResults("dune"), exitNoResults},
+		{"wrapped no results", fmt.Errorf("searching: %w", noResults("dune")), exitNoResults},
+		{"low confidence", errLowConfidence, exitLowConfidence},
+		{"help", flag.ErrHelp, exitOK},
+		{"error", errors.New("connection refused"), exitError},
+		{"bookid error", bookid.Errorf(bookid.EINVALID, "Invalid query."), exitError},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.want, exitCode(tt.err))
+		})
+	}
+}
+
+func TestWriteError(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		name   string
+		format string
+		err    error
+		want   string
+	}{
+		{"nil text", errorsText, nil, ""},
+		{"nil json", errorsJSON, nil, ""},
+		{"help text", errorsText, flag.ErrHelp, ""},
+		{"help json", errorsJSON, flag.ErrHelp, ""},
+		{"low confidence text", errorsText, errLowConfidence, ""},
+		{"low confidence json", errorsJSON, errLowConfidence, ""},
+		{"no results text", errorsText, noResults("dune"), "error: No books found for \"dune\".\n"},
+		{"no results json", errorsJSON, fmt.Errorf("searching: %w", noResults("dune")),
+			`{"error":{"code":"not_found","message":"No books found for \"dune\".","exit_code":2}}` + "\n"},
+		{"error text", errorsText, errors.New("connection refused"), "error: connection refused\n"},
+		{"error json", errorsJSON, errors.New("connection refused"),
+			`{"error":{"code":"internal","message":"connection refused","exit_code":1}}` + "\n"},
+		{"bookid error json", errorsJSON, bookid.Errorf(bookid.EINVALID, "Limit <1> too low."),
+			`{"error":{"code":"invalid","message":"Limit <1> too low.","exit_code":1}}` + "\n"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var buf bytes.Buffer
+			writeError(&buf, tt.format, tt.err)
+			assert.Equal(t, tt.want, buf.String())
+		})
+	}
+}
@@ -0,0 +1,476 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/goodreads"
+	"github.com/fwojciec/bookid/isbn"
+	"github.com/fwojciec/bookid/marc"
+	"github.com/fwojciec/bookid/onix"
+	"github.com/fwojciec/bookid/sqlite"
+	"github.com/fwojciec/bookid/xlsx"
+)
+
+// exportPageSize is the number of works read from the catalog at a time.
+const exportPageSize = 100
+
+// ExportCommand represents a command for exporting the catalog in formats
+// other systems can import.
+type ExportCommand struct {
+	Config Config
+	Stdout io.Writer
+}
+
+// Run executes the command.
+func (c *ExportCommand) Run(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("bookid-export", flag.ContinueOnError)
+	format := fs.String("format", "marcxml", "output format: marc, marcxml, onix, csv, xlsx, ndjson or goodreads-csv")
+	query := fs.String("query", "", "only works whose title or author contains text")
+	columns := fs.String("columns", strings.Join(defaultExportColumns(), ","), "comma-separated columns (csv and xlsx)")
+	fs.Usage = func() { c.usage(fs) }
+	if err := parseFlags(ctx, fs, args); err != nil {
+		return err
+	} else if fs.NArg() != 0 {
+		return fmt.Errorf("usage: bookid export [flags]")
+	}
+
+	db, err := openDB(c.Config)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	w, err := newExportWriter(ctx, db, *format, strings.Split(*columns, ","), c.Stdout)
+	if err != nil {
+		return err
+	}
+
+	filter := bookid.WorkFilter{Limit: exportPageSize}
+	if *query != "" {
+		filter.Query = query
+	}
+	if err := exportWorks(ctx, db, filter, w); err != nil {
+		return err
+	}
+	return w.Close()
+}
+
+// exportWorks writes every work matching filter, one entry per publication,
+// paging through the catalog. Works without publications are written alone.
+func exportWorks(ctx context.Context, db *sqlite.DB, filter bookid.WorkFilter, w exportWriter) error {
+	workService := sqlite.NewWorkService(db)
+	authorService := sqlite.NewAuthorService(db)
+	subjectService := sqlite.NewSubjectService(db)
+	pubService := sqlite.NewPublicationService(db)
+
+	for {
+		works, n, err := workService.FindWorks(ctx, filter)
+		if err != nil {
+			return err
+		}
+		for _, work := range works {
+			authors, _, err := authorService.FindAuthors(ctx, bookid.AuthorFilter{WorkID: &work.ID})
+			if err != nil {
+				return err
+			}
+			subjects, _, err := subjectService.FindSubjects(ctx, bookid.SubjectFilter{WorkID: &work.ID})
+			if err != nil {
+				return err
+			}
+			pubs, _, err := pubService.FindPublications(ctx, bookid.PublicationFilter{WorkID: &work.ID})
+			if err != nil {
+				return err
+			}
+			if len(pubs) == 0 {
+				pubs = []*bookid.Publication{nil}
+			}
+			for _, pub := range pubs {
+				if err := w.Write(work, authors, subjects, pub); err != nil {
+					return fmt.Errorf("exporting work %d: %w", work.ID, err)
+				}
+			}
+		}
+
+		filter.Offset += len(works)
+		if len(works) == 0 || filter.Offset >= n {
+			return nil
+		}
+	}
+}
+
+// exportWriter writes catalog entries in an export format. Subjects are the
+// work's subjects, and pub is nil for works without publications.
+type exportWriter interface {
+	Write(work *bookid.Work, authors []*bookid.Author, subjects []*bookid.Subject, pub *bookid.Publication) error
+	Close() error
+}
+
+// newExportWriter returns the exportWriter for the named format. columns
+// selects the columns of tabular formats and is ignored by the others. db is
+// read for the personal data of formats such as goodreads-csv.
+func newExportWriter(ctx context.Context, db *sqlite.DB, format string, columns []string, w io.Writer) (exportWriter, error) {
+	switch format {
+	case "csv", "xlsx":
+		cols, err := findExportColumns(columns)
+		if err != nil {
+			return nil, err
+		}
+		if format == "xlsx" {
+			xw := xlsx.NewWriter(w)
+			xw.SheetName = "Catalog"
+			return &tableExportWriter{columns: cols, write: xw.Write, close: xw.Close}, nil
+		}
+		cw := csv.NewWriter(w)
+		return &tableExportWriter{columns: cols, write: cw.Write, close: func() error {
+			cw.Flush()
+			return cw.Error()
+		}}, nil
+	case "marc":
+		return &marcExportWriter{w: marc.NewWriter(w)}, nil
+	case "marcxml":
+		return &marcXMLExportWriter{w: marc.NewXMLWriter(w)}, nil
+	case "onix":
+		return &onixExportWriter{w: onix.NewWriter(w)}, nil
+	case "ndjson":
+		enc := json.NewEncoder(w)
+		enc.SetEscapeHTML(false)
+		return &ndjsonExportWriter{enc: enc}, nil
+	case "goodreads-csv":
+		return &goodreadsExportWriter{
+			ctx:         ctx,
+			w:           goodreads.NewWriter(w),
+			collections: sqlite.NewCollectionService(db),
+			audit:       sqlite.NewAuditService(db),
+		}, nil
+	default:
+		return nil, bookid.Errorf(bookid.EINVALID, "Invalid export format %q.", format)
+	}
+}
+
+// marcExportWriter writes entries as MARC 21 binary records.
+type marcExportWriter struct {
+	w *marc.Writer
+}
+
+func (w *marcExportWriter) Write(work *bookid.Work, authors []*bookid.Author, subjects []*bookid.Subject, pub *bookid.Publication) error {
+	r := marc.NewRecord(work, authors, pub)
+	r.AddSubjects(subjects)
+	return w.w.Write(r)
+}
+
+func (w *marcExportWriter) Close() error { return nil }
+
+// marcXMLExportWriter writes entries as a MARCXML collection.
+type marcXMLExportWriter struct {
+	w *marc.XMLWriter
+}
+
+func (w *marcXMLExportWriter) Write(work *bookid.Work, authors []*bookid.Author, subjects []*bookid.Subject, pub *bookid.Publication) error {
+	r := marc.NewRecord(work, authors, pub)
+	r.AddSubjects(subjects)
+	return w.w.Write(r)
+}
+
+func (w *marcXMLExportWriter) Close() error { return w.w.Close() }
+
+// onixExportWriter writes entries as an ONIX 3.0 message.
+type onixExportWriter struct {
+	w *onix.Writer
+}
+
+func (w *onixExportWriter) Write(work *bookid.Work, authors []*bookid.Author, subjects []*bookid.Subject, pub *bookid.Publication) error {
+	p := onix.NewProduct(work, authors, pub)
+	p.AddSubjects(subjects)
+	return w.w.Write(p)
+}
+
+func (w *onixExportWriter) Close() error { return w.w.Close() }
+
+// ndjsonExportWriter writes each entry as a JSON object on its own line as
+// soon as it is read from the catalog.
+type ndjsonExportWriter struct {
+	enc *json.Encoder
+}
+
+func (w *ndjsonExportWriter) Write(work *bookid.Work, authors []*bookid.Author, subjects []*bookid.Subject, pub *bookid.Publication) error {
+	return w.enc.Encode(struct {
+		Work        *bookid.Work        `json:"work"`
+		Authors     []*bookid.Author    `json:"authors"`
+		Subjects    []*bookid.Subject   `json:"subjects"`
+		Publication *bookid.Publication `json:"publication"`
+	}{work, authors, subjects, pub})
+}
+
+func (w *ndjsonExportWriter) Close() error { return nil }
+
+// goodreadsExportWriter writes entries as a Goodreads library export, with
+// the rating, reading status and notes of each publication, the collections
+// of its work as shelves and the date it was last marked read.
+type goodreadsExportWriter struct {
+	ctx         context.Context
+	w           *goodreads.Writer
+	collections bookid.CollectionService
+	audit       bookid.AuditService
+}
+
+func (w *goodreadsExportWriter) Write(work *bookid.Work, authors []*bookid.Author, _ []*bookid.Subject, pub *bookid.Publication) error {
+	book := goodreads.NewBook(work, pub)
+	for _, a := range authors {
+		if (a.Role == "" || a.Role == bookid.ContributorRoleAuthor) && a.Name != work.Author {
+			book.AdditionalAuthors = append(book.AdditionalAuthors, a.Name)
+		}
+	}
+
+	collections, _, err := w.collections.FindCollections(w.ctx, bookid.CollectionFilter{WorkID: &work.ID})
+	if err != nil {
+		return err
+	}
+	for _, c := range collections {
+		book.Bookshelves = append(book.Bookshelves, c.Name)
+	}
+
+	if pub != nil && pub.ReadingStatus == bookid.ReadingStatusRead {
+		if book.DateRead, err = readAt(w.ctx, w.audit, pub.ID); err != nil {
+			return err
+		}
+	}
+	return w.w.Write(book)
+}
+
+func (w *goodreadsExportWriter) Close() error { return w.w.Flush() }
+
+// readAt returns the time a publication was last marked read, from the audit
+// log, or the zero time if the log does not record it.
+func readAt(ctx context.Context, audit bookid.AuditService, pubID int64) (time.Time, error) {
+	entity := bookid.AuditEntityPublication
+	entries, _, err := audit.FindAuditEntries(ctx, bookid.AuditFilter{EntityType: &entity, EntityID: &pubID})
+	if err != nil {
+		return time.Time{}, err
+	}
+	var t time.Time
+	for _, e := range entries {
+		if change, ok := e.Diff["reading_status"]; ok && change.New == string(bookid.ReadingStatusRead) {
+			t = e.CreatedAt
+		}
+	}
+	return t, nil
+}
+
+// exportColumn is a column of a tabular export.
+type exportColumn struct {
+	name  string
+	value func(work *bookid.Work, authors []*bookid.Author, pub *bookid.Publication) string
+}
+
+// exportColumns returns the columns available to tabular exports. Columns
+// are named after the JSON fields of the catalog types.
+func exportColumns() []exportColumn {
+	// pubField guards the publication columns of works without publications.
+	pubField := func(f func(pub *bookid.Publication) string) func(*bookid.Work, []*bookid.Author, *bookid.Publication) string {
+		return func(_ *bookid.Work, _ []*bookid.Author, pub *bookid.Publication) string {
+			if pub == nil {
+				return ""
+			}
+			return f(pub)
+		}
+	}
+	formatInt := func(v int64) string {
+		if v == 0 {
+			return ""
+		}
+		return strconv.FormatInt(v, 10)
+	}
+	formatFloat := func(v float64) string {
+		if v == 0 {
+			return ""
+		}
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	formatBool := func(v bool) string {
+		if !v {
+			return ""
+		}
+		return strconv.FormatBool(v)
+	}
+
+	return []exportColumn{
+		{"work_id", func(work *bookid.Work, _ []*bookid.Author, _ *bookid.Publication) string {
+			return strconv.FormatInt(work.ID, 10)
+		}},
+		{"title", func(work *bookid.Work, _ []*bookid.Author, _ *bookid.Publication) string { return work.Title }},
+		{"author", func(work *bookid.Work, _ []*bookid.Author, _ *bookid.Publication) string { return work.Author }},
+		{"authors", func(_ *bookid.Work, authors []*bookid.Author, _ *bookid.Publication) string {
+			names := make([]string, 0, len(authors))
+			for _, a := range authors {
+				if a.Role == "" || a.Role == bookid.ContributorRoleAuthor {
+					names = append(names, a.Name)
+				}
+			}
+			return strings.Join(names, "; ")
+		}},
+		{"contributors", func(_ *bookid.Work, authors []*bookid.Author, _ *bookid.Publication) string {
+			names := make([]string, 0, len(authors))
+			for _, a := range authors {
+				if a.Role != "" && a.Role != bookid.ContributorRoleAuthor {
+					names = append(names, a.Name+" ("+string(a.Role)+")")
+				}
+			}
+			return strings.Join(names, "; ")
+		}},
+		{"publication_id", pubField(func(pub *bookid.Publication) string { return formatInt(pub.ID) })},
+		{"isbn13", pubField(func(pub *bookid.Publication) string { return pub.ISBN13 })},
+		{"isbn10", pubField(func(pub *bookid.Publication) string { return pub.ISBN10 })},
+		{"isbn13_hyphenated", pubField(func(pub *bookid.Publication) string { return isbn.Format(pub.ISBN13) })},
+		{"isbn10_hyphenated", pubField(func(pub *bookid.Publication) string { return isbn.Format(pub.ISBN10) })},
+		{"publisher", pubField(func(pub *bookid.Publication) string { return pub.Publisher })},
+		{"published_year", pubField(func(pub *bookid.Publication) string { return formatInt(int64(pub.PublishedYear)) })},
+		{"language", pubField(func(pub *bookid.Publication) string { return pub.Language })},
+		{"binding", pubField(func(pub *bookid.Publication) string { return string(pub.Binding) })},
+		{"page_count", pubField(func(pub *bookid.Publication) string { return formatInt(int64(pub.PageCount)) })},
+		{"duration_minutes", pubField(func(pub *bookid.Publication) string { return formatInt(int64(pub.DurationMinutes)) })},
+		{"height_mm", pubField(func(pub *bookid.Publication) string { return formatFloat(pub.Dimensions.Height) })},
+		{"width_mm", pubField(func(pub *bookid.Publication) string { return formatFloat(pub.Dimensions.Width) })},
+		{"thickness_mm", pubField(func(pub *bookid.Publication) string { return formatFloat(pub.Dimensions.Thickness) })},
+		{"weight_g", pubField(func(pub *bookid.Publication) string { return formatFloat(pub.Dimensions.Weight) })},
+		{"public_domain", pubField(func(pub *bookid.Publication) string { return formatBool(pub.Access.PublicDomain) })},
+		{"full_view", pubField(func(pub *bookid.Publication) string { return formatBool(pub.Access.FullView) })},
+		{"web_reader_url", pubField(func(pub *bookid.Publication) string { return pub.Access.WebReaderURL })},
+		{"preview_url", pubField(func(pub *bookid.Publication) string { return pub.Access.PreviewURL })},
+		{"dewey", pubField(func(pub *bookid.Publication) string { return pub.Classification.Dewey })},
+		{"lcc", pubField(func(pub *bookid.Publication) string { return pub.Classification.LCC })},
+		{"google_books_volume_id", pubField(func(pub *bookid.Publication) string { return pub.GoogleBooksVolumeID })},
+		{"oclc_number", pubField(func(pub *bookid.Publication) string { return pub.OCLCNumber })},
+		{"lccn", pubField(func(pub *bookid.Publication) string { return pub.LCCN })},
+		{"doi", pubField(func(pub *bookid.Publication) string { return pub.DOI })},
+		{"asin", pubField(func(pub *bookid.Publication) string { return pub.ASIN })},
+		{"thumbnail_url", pubField(func(pub *bookid.Publication) string { return pub.ThumbnailURL })},
+		{"description", pubField(func(pub *bookid.Publication) string { return pub.Description })},
+		{"table_of_contents", pubField(func(pub *bookid.Publication) string { return strings.Join(pub.TableOfContents, "; ") })},
+		{"created_at", func(work *bookid.Work, _ []*bookid.Author, _ *bookid.Publication) string {
+			return work.CreatedAt.UTC().Format(time.RFC3339)
+		}},
+	}
+}
+
+// defaultExportColumns returns the columns of tabular exports unless the
+// -columns flag is given.
+func defaultExportColumns() []string {
+	return []string{"work_id", "title", "authors", "isbn13", "isbn10", "publisher", "published_year", "language"}
+}
+
+// findExportColumns returns the named columns in the given order. Returns
+// EINVALID for unknown names.
+func findExportColumns(names []string) ([]exportColumn, error) {
+	available := exportColumns()
+	columns := make([]exportColumn, 0, len(names))
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		i := slices.IndexFunc(available, func(c exportColumn) bool { return c.name == name })
+		if i < 0 {
+			valid := make([]string, 0, len(available))
+			for _, c := range available {
+				valid = append(valid, c.name)
+			}
+			return nil, bookid.Errorf(bookid.EINVALID, "Unknown export column %q, must be one of: %s.", name, strings.Join(valid, ", "))
+		}
+		columns = append(columns, available[i])
+	}
+	return columns, nil
+}
+
+// tableExportWriter writes entries as rows of a table, preceded by a header
+// row with the column names.
+type tableExportWriter struct {
+	columns     []exportColumn
+	write       func(row []string) error
+	close       func() error
+	wroteHeader bool
+}
+
+func (w *tableExportWriter) Write(work *bookid.Work, authors []*bookid.Author, _ []*bookid.Subject, pub *bookid.Publication) error {
+	if err := w.writeHeader(); err != nil {
+		return err
+	}
+	row := make([]string, len(w.columns))
+	for i, c := range w.columns {
+		row[i] = c.value(work, authors, pub)
+	}
+	return w.write(row)
+}
+
+// Close writes the header if no entries were written, so empty exports still
+// describe their columns.
+func (w *tableExportWriter) Close() error {
+	if err := w.writeHeader(); err != nil {
+		return err
+	}
+	return w.close()
+}
+
+func (w *tableExportWriter) writeHeader() error {
+	if w.wroteHeader {
+		return nil
+	}
+	w.wroteHeader = true
+	header := make([]string, len(w.columns))
+	for i, c := range w.columns {
+		header[i] = c.name
+	}
+	return w.write(header)
+}
+
+// usage prints the help text for the command.
+func (c *ExportCommand) usage(fs *flag.FlagSet) {
+	fmt.Fprintln(os.Stderr, strings.TrimSpace(`
+Exports the catalog to standard output, one record per publication.
+
+Formats:
+
+	marc      MARC 21 binary (ISO 2709), for import into library systems
+	marcxml   MARC 21 as MARCXML
+	onix      ONIX for Books 3.0, for publisher and distributor workflows
+	csv       Comma-separated values, one row per publication
+	xlsx      Excel spreadsheet, one row per publication
+	ndjson    One JSON object per line with the work, its authors and
+	          subjects and the publication, written as the catalog is read
+	goodreads-csv
+	          Goodreads library export, for Goodreads, StoryGraph and other
+	          reading trackers, with ratings, reading status as the
+	          exclusive shelf, collections as shelves and read dates
+
+MARC and ONIX exports carry the BISAC subject codes of subjects that have
+one, recorded from the categories of Google Books, so retailers receive
+proper subject codes; other subjects are exported as keywords.
+
+Columns of csv and xlsx exports:
+
+	work_id, title, author, authors, contributors, publication_id, isbn13,
+	isbn10, isbn13_hyphenated, isbn10_hyphenated, publisher, published_year,
+	language, binding, page_count, duration_minutes, height_mm, width_mm,
+	thickness_mm, weight_g, public_domain, full_view, web_reader_url,
+	preview_url, dewey, lcc, google_books_volume_id, oclc_number, lccn, doi,
+	asin, thumbnail_url, description, table_of_contents, created_at
+
+The authors column lists the authors only; translators, editors and other
+contributors are listed with their role in the contributors column, e.g.
+"Gregory Rabassa (translator)".
+
+Usage:
+
+	bookid export [flags]
+
+Flags:
+`))
+	fs.PrintDefaults()
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportCommand_GoodreadsCSV(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "catalog.db")
+
+	db := sqlite.NewDB(path)
+	now := time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC)
+	db.Now = func() time.Time { return now }
+	require.NoError(t, db.Open())
+
+	works := sqlite.NewWorkService(db)
+	pubs := sqlite.NewPublicationService(db)
+	collections := sqlite.NewCollectionService(db)
+	gatsby := &bookid.Work{Title: "The Great Gatsby", Author: "F. Scott Fitzgerald"}
+	require.NoError(t, works.CreateWork(ctx, gatsby))
+	pub := &bookid.Publication{WorkID: gatsby.ID, ISBN13: "9780743273565", PageCount: 180, ReadingStatus: bookid.ReadingStatusReading}
+	require.NoError(t, pubs.CreatePublication(ctx, pub))
+	for _, name := range []string{"classics", "favorites"} {
+		c := &bookid.Collection{Name: name}
+		require.NoError(t, collections.CreateCollection(ctx, c))
+		require.NoError(t, collections.AddCollectionWork(ctx, c.ID, gatsby.ID))
+	}
+
+	// Finished and rated later.
+	now = time.Date(2024, 3, 4, 21, 0, 0, 0, time.UTC)
+	read, rating := bookid.ReadingStatusRead, 5
+	_, err := pubs.UpdatePublication(ctx, pub.ID, bookid.PublicationUpdate{ReadingStatus: &read, Rating: &rating})
+	require.NoError(t, err)
+
+	dune := &bookid.Work{Title: "Dune", Author: "Frank Herbert"}
+	require.NoError(t, works.CreateWork(ctx, dune))
+	want := bookid.ReadingStatusWantToRead
+	require.NoError(t, pubs.CreatePublication(ctx, &bookid.Publication{WorkID: dune.ID, ISBN13: "9780441172719", ReadingStatus: want}))
+	require.NoError(t, db.Close())
+
+	var buf bytes.Buffer
+	cmd := &ExportCommand{Config: Config{DBPath: path}, Stdout: &buf}
+	require.NoError(t, cmd.Run(ctx, []string{"-format", "goodreads-csv"}))
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+	rows := make(map[string]map[string]string)
+	for _, record := range records[1:] {
+		row := make(map[string]string)
+		for i, name := range records[0] {
+			row[name] = record[i]
+		}
+		rows[row["Title"]] = row
+	}
+
+	assert.Equal(t, "5", rows["The Great Gatsby"]["My Rating"])
+	assert.Equal(t, "2024/03/04", rows["The Great Gatsby"]["Date Read"])
+	assert.Equal(t, "2024/01/02", rows["The Great Gatsby"]["Date Added"])
+	assert.Equal(t, "read", rows["The Great Gatsby"]["Exclusive Shelf"])
+	assert.Equal(t, "classics, favorites", rows["The Great Gatsby"]["Bookshelves"])
+	assert.Equal(t, "180", rows["The Great Gatsby"]["Number of Pages"])
+
+	assert.Equal(t, "0", rows["Dune"]["My Rating"])
+	assert.Empty(t, rows["Dune"]["Date Read"])
+	assert.Equal(t, "to-read", rows["Dune"]["Exclusive Shelf"])
+	assert.Empty(t, rows["Dune"]["Bookshelves"])
+}
+
+func TestExportCommand_HyphenatedColumns(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "catalog.db")
+
+	db := sqlite.NewDB(path)
+	require.NoError(t, db.Open())
+	work := &bookid.Work{Title: "Il nome della rosa"}
+	require.NoError(t, sqlite.NewWorkService(db).CreateWork(ctx, work))
+	require.NoError(t, sqlite.NewPublicationService(db).CreatePublication(ctx, &bookid.Publication{WorkID: work.ID, ISBN13: "9788845292613"}))
+	require.NoError(t, db.Close())
+
+	var buf bytes.Buffer
+	cmd := &ExportCommand{Config: Config{DBPath: path}, Stdout: &buf}
+	require.NoError(t, cmd.Run(ctx, []string{"-format", "csv", "-columns", "isbn13,isbn13_hyphenated"}))
+	assert.Equal(t, "isbn13,isbn13_hyphenated\n9788845292613,978-88-452-9261-3\n", buf.String())
+}
+
+func TestExportCommand_NDJSON(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "catalog.db")
+
+	db := sqlite.NewDB(path)
+	require.NoError(t, db.Open())
+	works := sqlite.NewWorkService(db)
+	gatsby := &bookid.Work{Title: "The Great Gatsby"}
+	require.NoError(t, works.CreateWork(ctx, gatsby))
+	authors := sqlite.NewAuthorService(db)
+	fitzgerald := &bookid.Author{Name: "F. Scott Fitzgerald"}
+	require.NoError(t, authors.CreateAuthor(ctx, fitzgerald))
+	require.NoError(t, authors.AddWorkAuthor(ctx, &bookid.WorkAuthor{WorkID: gatsby.ID, AuthorID: fitzgerald.ID}))
+	require.NoError(t, sqlite.NewPublicationService(db).CreatePublication(ctx, &bookid.Publication{WorkID: gatsby.ID, ISBN13: "9780743273565"}))
+	require.NoError(t, works.CreateWork(ctx, &bookid.Work{Title: "Dune"}))
+	require.NoError(t, db.Close())
+
+	var buf bytes.Buffer
+	cmd := &ExportCommand{Config: Config{DBPath: path}, Stdout: &buf}
+	require.NoError(t, cmd.Run(ctx, []string{"-format", "ndjson"}))
+
+	type entry struct {
+		Work        *bookid.Work        `json:"work"`
+		Authors     []*bookid.Author    `json:"authors"`
+		Publication *bookid.Publication `json:"publication"`
+	}
+	entries := make(map[string]entry)
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	require.Len(t, lines, 2, "one line per publication, and one for the work without")
+	for _, line := range lines {
+		var e entry
+		require.NoError(t, json.Unmarshal([]byte(line), &e))
+		entries[e.Work.Title] = e
+	}
+
+	require.Len(t, entries["The Great Gatsby"].Authors, 1)
+	assert.Equal(t, "F. Scott Fitzgerald", entries["The Great Gatsby"].Authors[0].Name)
+	require.NotNil(t, entries["The Great Gatsby"].Publication)
+	assert.Equal(t, "9780743273565", entries["The Great Gatsby"].Publication.ISBN13)
+	assert.Nil(t, entries["Dune"].Publication)
+}
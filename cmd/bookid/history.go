@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/sqlite"
+)
+
+// HistoryCommand represents a command for reviewing the changes made to a
+// work.
+type HistoryCommand struct {
+	Config Config
+	Stdout io.Writer
+}
+
+// Run executes the command.
+func (c *HistoryCommand) Run(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("bookid-history", flag.ContinueOnError)
+	fs.Usage = c.usage
+	if err := parseFlags(ctx, fs, args); err != nil {
+		return err
+	} else if fs.NArg() != 1 {
+		return fmt.Errorf("usage: bookid history <work-id>")
+	}
+
+	id, err := strconv.ParseInt(fs.Arg(0), 10, 64)
+	if err != nil {
+		return bookid.Errorf(bookid.EINVALID, "Invalid work ID %q.", fs.Arg(0))
+	}
+
+	db, err := openDB(c.Config)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	entries, _, err := sqlite.NewAuditService(db).FindAuditEntries(ctx, bookid.AuditFilter{WorkID: &id})
+	if err != nil {
+		return err
+	} else if len(entries) == 0 {
+		return bookid.Errorf(bookid.ENOTFOUND, "No history of work %d.", id)
+	}
+	return writeJSON(c.Stdout, entries)
+}
+
+// usage prints the help text for the command.
+func (c *HistoryCommand) usage() {
+	fmt.Fprintln(os.Stderr, strings.TrimSpace(`
+Shows the changes made to a work, its publications and its author links,
+oldest first, with who made each change and the fields it changed. Merges of
+other works into it are included, and a deleted work's history is kept.
+
+Changes are attributed to the current user unless the actor setting of the
+configuration file or BOOKID_ACTOR names someone else.
+
+Usage:
+
+	bookid history <work-id>
+`))
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/importer"
+	"github.com/fwojciec/bookid/onix"
+	"github.com/fwojciec/bookid/sqlite"
+)
+
+// ImportCommand represents a command for adding records from other systems
+// to the catalog.
+type ImportCommand struct {
+	Config Config
+	Stdin  io.Reader
+	Stdout io.Writer
+}
+
+// Run executes the command.
+func (c *ImportCommand) Run(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("bookid-import", flag.ContinueOnError)
+	format := fs.String("format", "onix", "input format: onix, goodreads")
+	minConfidence := fs.Float64("min-confidence", 0.5, "minimum confidence of title matches (goodreads)")
+	fs.Usage = func() { c.usage(fs) }
+	if err := parseFlags(ctx, fs, args); err != nil {
+		return err
+	} else if fs.NArg() > 1 {
+		return fmt.Errorf("usage: bookid import [flags] [file]")
+	} else if *format != "onix" && *format != "goodreads" {
+		return bookid.Errorf(bookid.EINVALID, "Invalid import format %q.", *format)
+	}
+
+	// Read from the named file, or stdin if none is given.
+	r := c.Stdin
+	if path := fs.Arg(0); path != "" && path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	db, err := openDB(c.Config)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	switch *format {
+	case "goodreads":
+		return c.importGoodreads(ctx, db, r, *minConfidence)
+	default:
+		return c.importONIX(ctx, db, r)
+	}
+}
+
+// importONIX catalogs the products of an ONIX message as they are.
+func (c *ImportCommand) importONIX(ctx context.Context, db *sqlite.DB, r io.Reader) error {
+	report, err := importProducts(ctx, sqlite.NewCatalogService(db), r, 0, nil)
+	if err != nil {
+		return err
+	}
+	return writeJSON(c.Stdout, report)
+}
+
+// onixReport summarizes an import of ONIX products.
+type onixReport struct {
+	Imported int `json:"imported"`
+	Skipped  int `json:"skipped"`
+}
+
+// importProducts saves the products of an ONIX message read from r, passing
+// over the first skip of them. Progress, if set, is called after each product
+// with the number done so far.
+func importProducts(ctx context.Context, catalog bookid.CatalogService, r io.Reader, skip int, progress func(done int) error) (*onixReport, error) {
+	report := &onixReport{}
+	reader := onix.NewReader(r)
+	for done := 1; ; done++ {
+		product, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return report, nil
+		} else if err != nil {
+			return nil, err
+		} else if done <= skip {
+			continue
+		}
+
+		// Products without a title cannot be cataloged as works.
+		if result := product.BookResult(); result.Title == "" {
+			report.Skipped++
+		} else if _, _, err := catalog.SaveResult(ctx, result); err != nil {
+			return nil, fmt.Errorf("importing product %q: %w", product.RecordReference, err)
+		} else {
+			report.Imported++
+		}
+
+		if progress != nil {
+			if err := progress(done); err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+// importGoodreads identifies the books of a Goodreads or StoryGraph export
+// with the configured providers and catalogs the matches.
+func (c *ImportCommand) importGoodreads(ctx context.Context, db *sqlite.DB, r io.Reader, minConfidence float64) error {
+	finder, err := newFinder(c.Config, db)
+	if err != nil {
+		return err
+	}
+
+	imp := &importer.Importer{
+		Finder:         finder,
+		CatalogService: sqlite.NewCatalogService(db),
+		MinConfidence:  minConfidence,
+		ReviewService:  sqlite.NewReviewService(db),
+	}
+	report, err := imp.Import(ctx, r)
+	if err != nil {
+		return err
+	}
+	return writeJSON(c.Stdout, report)
+}
+
+// usage prints the help text for the command.
+func (c *ImportCommand) usage(fs *flag.FlagSet) {
+	fmt.Fprintln(os.Stderr, strings.TrimSpace(`
+Imports records from a file, or stdin if none is given, into the catalog as
+works with their authors and publications. Records of already cataloged
+publications refresh them.
+
+Reading-tracker exports are identified with the configured providers first,
+by ISBN where the row has one and by title and author otherwise. Title and
+author matches below -min-confidence are queued for "bookid review" with the
+other candidates found instead of being saved. Rows that cannot be identified
+at all are listed as unmatched.
+
+Formats:
+
+	onix        ONIX for Books 3.0 with reference tags
+	goodreads   Goodreads or StoryGraph library export (CSV)
+
+Usage:
+
+	bookid import [flags] [file]
+
+Flags:
+`))
+	fs.PrintDefaults()
+}
@@ -0,0 +1,363 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/covers"
+	"github.com/fwojciec/bookid/importer"
+	"github.com/fwojciec/bookid/jobs"
+	"github.com/fwojciec/bookid/sqlite"
+)
+
+// JobsCommand represents a command for queuing long-running imports,
+// refreshes and cover backfills, running them and following their progress.
+type JobsCommand struct {
+	Config Config
+	Stdout io.Writer
+}
+
+// importParams are the arguments of an import job.
+type importParams struct {
+	Path          string  `json:"path"`
+	Format        string  `json:"format"`
+	MinConfidence float64 `json:"min_confidence,omitempty"`
+}
+
+// refreshParams are the arguments of a refresh job.
+type refreshParams struct {
+	Before time.Time `json:"before"`
+}
+
+// Run executes the command.
+func (c *JobsCommand) Run(ctx context.Context, args []string) error {
+	var cmd string
+	if len(args) > 0 {
+		cmd, args = args[0], args[1:]
+	}
+
+	switch cmd {
+	case "add":
+		return c.runAdd(ctx, args)
+	case "run":
+		return c.runRun(ctx, args)
+	case "list":
+		return c.runList(ctx, args)
+	case "status":
+		return c.runJob(ctx, "status", args)
+	case "cancel":
+		return c.runJob(ctx, "cancel", args)
+	case "", "-h", "-help", "--help", "help":
+		c.usage()
+		return flag.ErrHelp
+	default:
+		return fmt.Errorf("bookid jobs %s: unknown command", cmd)
+	}
+}
+
+// runAdd queues a job.
+func (c *JobsCommand) runAdd(ctx context.Context, args []string) error {
+	var kind string
+	if len(args) > 0 {
+		kind, args = args[0], args[1:]
+	}
+
+	job := &bookid.Job{Kind: bookid.JobKind(kind)}
+	var params any
+	switch job.Kind {
+	case bookid.JobKindImport:
+		fs := flag.NewFlagSet("bookid-jobs-add-import", flag.ContinueOnError)
+		format := fs.String("format", "onix", "input format: onix, goodreads")
+		minConfidence := fs.Float64("min-confidence", 0.5, "minimum confidence of title matches (goodreads)")
+		fs.Usage = c.usage
+		if err := parseFlags(ctx, fs, args); err != nil {
+			return err
+		} else if fs.NArg() != 1 {
+			return fmt.Errorf("usage: bookid jobs add import [flags] <file>")
+		} else if *format != "onix" && *format != "goodreads" {
+			return bookid.Errorf(bookid.EINVALID, "Invalid import format %q.", *format)
+		}
+
+		// The job may run from another directory.
+		path, err := filepath.Abs(fs.Arg(0))
+		if err != nil {
+			return err
+		} else if _, err := os.Stat(path); err != nil {
+			return err
+		}
+		params = importParams{Path: path, Format: *format, MinConfidence: *minConfidence}
+
+	case bookid.JobKindRefresh:
+		fs := flag.NewFlagSet("bookid-jobs-add-refresh", flag.ContinueOnError)
+		olderThan := fs.String("older-than", "90d", "refresh publications not refreshed for this long, e.g. 90d or 12h")
+		fs.Usage = c.usage
+		if err := parseFlags(ctx, fs, args); err != nil {
+			return err
+		}
+		age, err := parseAge(*olderThan)
+		if err != nil {
+			return err
+		}
+
+		// Fixed when queued so that a resumed job does not pick up
+		// publications refreshed by its first run.
+		params = refreshParams{Before: time.Now().Add(-age).UTC()}
+
+	case bookid.JobKindCovers:
+		fs := flag.NewFlagSet("bookid-jobs-add-covers", flag.ContinueOnError)
+		fs.Usage = c.usage
+		if err := parseFlags(ctx, fs, args); err != nil {
+			return err
+		}
+
+	case "-h", "-help", "--help", "help":
+		c.usage()
+		return flag.ErrHelp
+
+	default:
+		return fmt.Errorf("usage: bookid jobs add import|refresh|covers [flags]")
+	}
+
+	if params != nil {
+		buf, err := json.Marshal(params)
+		if err != nil {
+			return err
+		}
+		job.Params = buf
+	}
+
+	db, err := openDB(c.Config)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	if err := sqlite.NewJobService(db).CreateJob(ctx, job); err != nil {
+		return err
+	}
+	return writeJSON(c.Stdout, job)
+}
+
+// runRun runs the pending jobs, resuming any interrupted one first.
+func (c *JobsCommand) runRun(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("bookid-jobs-run", flag.ContinueOnError)
+	fs.Usage = c.usage
+	if err := parseFlags(ctx, fs, args); err != nil {
+		return err
+	} else if fs.NArg() > 0 {
+		return fmt.Errorf("usage: bookid jobs run")
+	}
+
+	db, err := openDB(c.Config)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	r := jobs.NewRunner(sqlite.NewJobService(db))
+	r.Logger = c.Config.logger()
+	r.Handle(bookid.JobKindImport, jobs.HandlerFunc(func(ctx context.Context, job *bookid.Job, progress jobs.Progress) (any, error) {
+		return c.runImport(ctx, db, job, progress)
+	}))
+	r.Handle(bookid.JobKindRefresh, jobs.HandlerFunc(func(ctx context.Context, job *bookid.Job, progress jobs.Progress) (any, error) {
+		return c.runRefresh(ctx, db, job, progress)
+	}))
+	r.Handle(bookid.JobKindCovers, jobs.HandlerFunc(func(ctx context.Context, _ *bookid.Job, progress jobs.Progress) (any, error) {
+		return c.runCovers(ctx, db, progress)
+	}))
+
+	ran, err := r.RunPending(ctx)
+	if err != nil {
+		return err
+	}
+	return writeJSON(c.Stdout, ran)
+}
+
+// runImport imports the file of an import job, passing over the records a
+// previous run already imported. The total is not known up front.
+func (c *JobsCommand) runImport(ctx context.Context, db *sqlite.DB, job *bookid.Job, progress jobs.Progress) (any, error) {
+	var params importParams
+	if err := json.Unmarshal(job.Params, &params); err != nil {
+		return nil, bookid.Errorf(bookid.EINVALID, "Invalid import job parameters.")
+	}
+
+	f, err := os.Open(params.Path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	catalog := sqlite.NewCatalogService(db)
+	report := func(done int) error { return progress(done, 0) }
+	if params.Format == "goodreads" {
+		finder, err := newFinder(c.Config, db)
+		if err != nil {
+			return nil, err
+		}
+		imp := &importer.Importer{
+			Finder:         finder,
+			CatalogService: catalog,
+			MinConfidence:  params.MinConfidence,
+			ReviewService:  sqlite.NewReviewService(db),
+			Skip:           job.Done,
+			Progress:       report,
+		}
+		return imp.Import(ctx, f)
+	}
+	return importProducts(ctx, catalog, f, job.Done, report)
+}
+
+// runRefresh refreshes the publications that were stale when the job was
+// queued. Publications refreshed by an interrupted run are no longer stale.
+func (c *JobsCommand) runRefresh(ctx context.Context, db *sqlite.DB, job *bookid.Job, progress jobs.Progress) (any, error) {
+	var params refreshParams
+	if err := json.Unmarshal(job.Params, &params); err != nil {
+		return nil, bookid.Errorf(bookid.EINVALID, "Invalid refresh job parameters.")
+	}
+
+	s, err := (&RefreshCommand{Config: c.Config}).newService(db)
+	if err != nil {
+		return nil, err
+	}
+	s.Progress = progress
+
+	refreshed, missing, err := s.RefreshStale(ctx, params.Before)
+	if err != nil {
+		return nil, err
+	}
+	return struct {
+		Refreshed int     `json:"refreshed"`
+		Missing   []int64 `json:"missing,omitempty"`
+	}{len(refreshed), missing}, nil
+}
+
+// runCovers backfills the covers of the catalog. Publications given a cover
+// by an interrupted run are not fetched again.
+func (c *JobsCommand) runCovers(ctx context.Context, db *sqlite.DB, progress jobs.Progress) (any, error) {
+	s := covers.NewService(
+		&http.Client{Timeout: c.Config.Timeout},
+		sqlite.NewPublicationService(db),
+		covers.NewStore(c.Config.CoverDir),
+	)
+	s.Progress = progress
+
+	fetched, missing, err := s.Backfill(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return struct {
+		Fetched int     `json:"fetched"`
+		Missing []int64 `json:"missing,omitempty"`
+	}{fetched, missing}, nil
+}
+
+// runList prints the jobs, newest first.
+func (c *JobsCommand) runList(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("bookid-jobs-list", flag.ContinueOnError)
+	state := fs.String("state", "", "only list jobs in this state: queued, running, done, failed, canceled")
+	limit := fs.Int("limit", 20, "maximum number of jobs to list")
+	fs.Usage = c.usage
+	if err := parseFlags(ctx, fs, args); err != nil {
+		return err
+	} else if fs.NArg() > 0 {
+		return fmt.Errorf("usage: bookid jobs list [-state state] [-limit n]")
+	}
+
+	filter := bookid.JobFilter{Limit: *limit}
+	if *state != "" {
+		s := bookid.JobState(*state)
+		if !s.Valid() {
+			return bookid.Errorf(bookid.EINVALID, "Invalid job state %q.", *state)
+		}
+		filter.State = &s
+	}
+
+	db, err := openDB(c.Config)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	list, n, err := sqlite.NewJobService(db).FindJobs(ctx, filter)
+	if err != nil {
+		return err
+	}
+	return writeJSON(c.Stdout, struct {
+		Jobs  []*bookid.Job `json:"jobs"`
+		Total int           `json:"total"`
+	}{list, n})
+}
+
+// runJob prints the status of a job or cancels it.
+func (c *JobsCommand) runJob(ctx context.Context, cmd string, args []string) error {
+	fs := flag.NewFlagSet("bookid-jobs-"+cmd, flag.ContinueOnError)
+	fs.Usage = c.usage
+	if err := parseFlags(ctx, fs, args); err != nil {
+		return err
+	} else if fs.NArg() != 1 {
+		return fmt.Errorf("usage: bookid jobs %s <job-id>", cmd)
+	}
+
+	ids, err := parseIDs(fs.Args())
+	if err != nil {
+		return err
+	}
+
+	db, err := openDB(c.Config)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	s := sqlite.NewJobService(db)
+
+	var job *bookid.Job
+	if cmd == "cancel" {
+		job, err = s.CancelJob(ctx, ids[0])
+	} else {
+		job, err = s.FindJobByID(ctx, ids[0])
+	}
+	if err != nil {
+		return err
+	}
+	return writeJSON(c.Stdout, job)
+}
+
+// usage prints the help text for the command.
+func (c *JobsCommand) usage() {
+	fmt.Fprintln(os.Stderr, strings.TrimSpace(`
+Queues long-running work to run in the background and follows its progress.
+Jobs are stored in the catalog database and run one at a time by "bookid jobs
+run", which runs until the queue is empty. A run that is interrupted leaves
+its job running; the next run resumes it from its recorded progress before
+starting queued jobs.
+
+A running job that is canceled stops the next time it records its progress.
+
+Usage:
+
+	bookid jobs add import [-format onix|goodreads] [-min-confidence n] <file>
+	bookid jobs add refresh [-older-than 90d]
+	bookid jobs add covers
+	bookid jobs run
+	bookid jobs list [-state state] [-limit n]
+	bookid jobs status <job-id>
+	bookid jobs cancel <job-id>
+
+The commands are:
+
+	add     queue an import of a file, a refresh or a cover backfill
+	run     run the pending jobs and print them once finished
+	list    list the jobs, newest first
+	status  show the state and progress of a job
+	cancel  cancel a queued or running job
+`))
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/authority"
+	"github.com/fwojciec/bookid/sqlite"
+)
+
+// LinkCommand represents a command for linking an author to their authority
+// records.
+type LinkCommand struct {
+	Config Config
+	Stdout io.Writer
+}
+
+// Run executes the command.
+func (c *LinkCommand) Run(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("bookid-link", flag.ContinueOnError)
+	viafID := fs.String("viaf", "", "link to this VIAF ID instead of searching VIAF")
+	wikidataID := fs.String("wikidata", "", "link to this Wikidata item (with -viaf)")
+	fs.Usage = func() { c.usage(fs) }
+	if err := parseFlags(ctx, fs, args); err != nil {
+		return err
+	} else if fs.NArg() != 1 {
+		return fmt.Errorf("usage: bookid link [flags] <author-id>")
+	} else if *wikidataID != "" && *viafID == "" {
+		return bookid.Errorf(bookid.EINVALID, "The -wikidata flag requires -viaf.")
+	}
+
+	id, err := strconv.ParseInt(fs.Arg(0), 10, 64)
+	if err != nil {
+		return bookid.Errorf(bookid.EINVALID, "Invalid author ID %q.", fs.Arg(0))
+	}
+
+	db, err := openDB(c.Config)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	authors := sqlite.NewAuthorService(db)
+	var author *bookid.Author
+	candidates := []*bookid.AuthorityRecord{}
+	if *viafID != "" {
+		author, err = authors.UpdateAuthor(ctx, id, bookid.AuthorUpdate{VIAFID: viafID, WikidataID: wikidataID})
+	} else {
+		linker := &authority.Linker{AuthorService: authors, AuthorityFinder: authority.NewClient()}
+		author, candidates, err = linker.Link(ctx, id)
+	}
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(c.Stdout, struct {
+		Author     *bookid.Author            `json:"author"`
+		Candidates []*bookid.AuthorityRecord `json:"candidates"`
+	}{author, candidates})
+}
+
+// usage prints the help text for the command.
+func (c *LinkCommand) usage(fs *flag.FlagSet) {
+	fmt.Fprintln(os.Stderr, strings.TrimSpace(`
+Links a cataloged author to their VIAF and Wikidata authority records, which
+tell apart authors with the same name.
+
+The author's name is searched in VIAF. If exactly one personal name record
+matches, the author is linked to it. Otherwise the author is left unlinked
+and the matching records are listed; link one of them with -viaf.
+
+Usage:
+
+	bookid link [flags] <author-id>
+
+Flags:
+`))
+	fs.PrintDefaults()
+}
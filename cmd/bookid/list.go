@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/sqlite"
+)
+
+// ListCommand represents a command for listing cataloged works.
+type ListCommand struct {
+	Config Config
+	Stdout io.Writer
+}
+
+// Run executes the command.
+func (c *ListCommand) Run(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("bookid-list", flag.ContinueOnError)
+	query := fs.String("query", "", "only works whose title or author contains text")
+	search := fs.String("search", "", "full-text search of titles, authors, publishers and identifiers")
+	lang := fs.String("lang", "", "only works with a publication in a language, e.g. en or pt-BR")
+	bind := fs.String("binding", "", "only works with a publication in a binding: hardcover, paperback, ebook or audiobook")
+	series := fs.String("series", "", "only works in a series, in volume order")
+	subj := fs.String("subject", "", "only works tagged with a subject, e.g. \"science fiction\"")
+	limit := fs.Int("limit", 0, "maximum number of works to list")
+	offset := fs.Int("offset", 0, "number of works to skip")
+	fs.Usage = func() { c.usage(fs) }
+	if err := parseFlags(ctx, fs, args); err != nil {
+		return err
+	} else if fs.NArg() != 0 {
+		return fmt.Errorf("usage: bookid list [flags]")
+	} else if *query != "" && *search != "" {
+		return bookid.Errorf(bookid.EINVALID, "The -query and -search flags cannot be combined.")
+	} else if *lang != "" && *search != "" {
+		return bookid.Errorf(bookid.EINVALID, "The -lang and -search flags cannot be combined.")
+	} else if *bind != "" && *search != "" {
+		return bookid.Errorf(bookid.EINVALID, "The -binding and -search flags cannot be combined.")
+	} else if *bind != "" && !bookid.Binding(*bind).Valid() {
+		return bookid.Errorf(bookid.EINVALID, "Invalid binding %q.", *bind)
+	} else if *series != "" && *search != "" {
+		return bookid.Errorf(bookid.EINVALID, "The -series and -search flags cannot be combined.")
+	} else if *subj != "" && *search != "" {
+		return bookid.Errorf(bookid.EINVALID, "The -subject and -search flags cannot be combined.")
+	}
+
+	db, err := openDB(c.Config)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	var works []*bookid.Work
+	var n int
+	if *search != "" {
+		works, n, err = sqlite.NewCatalogSearchService(db).SearchCatalog(ctx, bookid.CatalogSearchFilter{
+			Query:  *search,
+			Limit:  *limit,
+			Offset: *offset,
+		})
+	} else {
+		filter := bookid.WorkFilter{Limit: *limit, Offset: *offset}
+		if *query != "" {
+			filter.Query = query
+		}
+		if *lang != "" {
+			filter.Language = lang
+		}
+		if *bind != "" {
+			filter.Binding = (*bookid.Binding)(bind)
+		}
+		if *series != "" {
+			filter.Series = series
+		}
+		if *subj != "" {
+			filter.Subject = subj
+		}
+		works, n, err = sqlite.NewWorkService(db).FindWorks(ctx, filter)
+	}
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(c.Stdout, struct {
+		Works []*bookid.Work `json:"works"`
+		Total int            `json:"total"`
+	}{works, n})
+}
+
+// usage prints the help text for the command.
+func (c *ListCommand) usage(fs *flag.FlagSet) {
+	fmt.Fprintln(os.Stderr, strings.TrimSpace(`
+Lists works in the catalog.
+
+The -search flag matches works containing every given term in their title,
+authors, publishers or identifiers such as ISBNs, ignoring case and accents.
+A term ending in "*" matches words starting with it. The best matches come
+first, weighing matches in titles above authors and authors above the rest.
+
+The -lang flag matches works with a publication in the language or, for a
+language without a region such as "en", any of its regional variants.
+
+The -binding flag matches works with a publication in the binding, as told
+by providers such as ISBNdb and Open Library when the publication was saved.
+
+The -series flag lists the works of a series, such as "Discworld", in volume
+order, with works of unknown position last.
+
+The -subject flag matches works tagged with the subject when saved from the
+categories of their providers. Subjects are normalized, so "Sci-Fi" matches
+works tagged "science fiction".
+
+Usage:
+
+	bookid list [flags]
+
+Flags:
+`))
+	fs.PrintDefaults()
+}
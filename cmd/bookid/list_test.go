@@ -0,0 +1,74 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListCommand(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	db, config := MustOpenDB(t)
+	works := sqlite.NewWorkService(db)
+	for _, w := range []*bookid.Work{
+		{Title: "Dune", Author: "Frank Herbert"},
+		{Title: "Dune Messiah", Author: "Frank Herbert"},
+		{Title: "The Great Gatsby", Author: "F. Scott Fitzgerald"},
+	} {
+		require.NoError(t, works.CreateWork(ctx, w))
+	}
+	MustCloseDB(t, db)
+
+	// list runs the command with args and returns the titles listed and the
+	// total number of matching works.
+	list := func(t *testing.T, args ...string) ([]string, int) {
+		t.Helper()
+		var buf bytes.Buffer
+		require.NoError(t, (&ListCommand{Config: config, Stdout: &buf}).Run(ctx, args))
+		var got struct {
+			Works []*bookid.Work `json:"works"`
+			Total int            `json:"total"`
+		}
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &got))
+		titles := make([]string, len(got.Works))
+		for i, w := range got.Works {
+			titles[i] = w.Title
+		}
+		return titles, got.Total
+	}
+
+	t.Run("All", func(t *testing.T) {
+		t.Parallel()
+		titles, total := list(t)
+		assert.ElementsMatch(t, []string{"Dune", "Dune Messiah", "The Great Gatsby"}, titles)
+		assert.Equal(t, 3, total)
+	})
+
+	t.Run("Query", func(t *testing.T) {
+		t.Parallel()
+		titles, total := list(t, "-query", "herbert")
+		assert.ElementsMatch(t, []string{"Dune", "Dune Messiah"}, titles)
+		assert.Equal(t, 2, total)
+	})
+
+	t.Run("Limit", func(t *testing.T) {
+		t.Parallel()
+		titles, total := list(t, "-limit", "1")
+		assert.Len(t, titles, 1)
+		assert.Equal(t, 3, total, "the total counts every match")
+	})
+
+	t.Run("InvalidFlags", func(t *testing.T) {
+		t.Parallel()
+		err := (&ListCommand{Config: config, Stdout: &bytes.Buffer{}}).Run(ctx, []string{"-query", "dune", "-search", "dune"})
+		assert.Equal(t, bookid.EINVALID, bookid.ErrorCode(err))
+	})
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/sqlite"
+)
+
+// LoanCommand represents a command for checking items of the catalog out to
+// borrowers and back in.
+type LoanCommand struct {
+	Config Config
+	Stdout io.Writer
+}
+
+// Run executes the command.
+func (c *LoanCommand) Run(ctx context.Context, args []string) error {
+	var cmd string
+	if len(args) > 0 {
+		cmd, args = args[0], args[1:]
+	}
+
+	switch cmd {
+	case "checkout":
+		return c.runCheckout(ctx, args)
+	case "return":
+		return c.runReturn(ctx, args)
+	case "list":
+		return c.runList(ctx, "list", args)
+	case "overdue":
+		return c.runList(ctx, "overdue", args)
+	case "", "-h", "-help", "--help", "help":
+		c.usage()
+		return flag.ErrHelp
+	default:
+		return fmt.Errorf("bookid loan %s: unknown command", cmd)
+	}
+}
+
+// runCheckout lends an item to a borrower.
+func (c *LoanCommand) runCheckout(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("bookid-loan-checkout", flag.ContinueOnError)
+	due := fs.String("due", "14d", "due date as YYYY-MM-DD, or a loan period such as 14d; empty for none")
+	fs.Usage = c.usage
+	if err := parseFlags(ctx, fs, args); err != nil {
+		return err
+	} else if fs.NArg() < 2 {
+		return fmt.Errorf("usage: bookid loan checkout [-due date] <item> <borrower>")
+	}
+
+	loan := &bookid.Loan{Borrower: strings.Join(fs.Args()[1:], " ")}
+	if *due != "" {
+		if t, err := time.Parse(time.DateOnly, *due); err == nil {
+			loan.DueAt = t
+		} else if age, err := parseAge(*due); err == nil {
+			loan.DueAt = time.Now().Add(age)
+		} else {
+			return bookid.Errorf(bookid.EINVALID, "Invalid due date %q; use YYYY-MM-DD or e.g. 14d.", *due)
+		}
+	}
+
+	db, err := openDB(c.Config)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	item, err := findItem(ctx, db, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	loan.ItemID = item.ID
+	if err := sqlite.NewLoanService(db).CheckoutItem(ctx, loan); err != nil {
+		return err
+	}
+	return writeJSON(c.Stdout, loan)
+}
+
+// runReturn checks items back in.
+func (c *LoanCommand) runReturn(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("bookid-loan-return", flag.ContinueOnError)
+	fs.Usage = c.usage
+	if err := parseFlags(ctx, fs, args); err != nil {
+		return err
+	} else if fs.NArg() == 0 {
+		return fmt.Errorf("usage: bookid loan return <item>...")
+	}
+
+	db, err := openDB(c.Config)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	s := sqlite.NewLoanService(db)
+
+	loans := make([]*bookid.Loan, 0, fs.NArg())
+	for _, arg := range fs.Args() {
+		item, err := findItem(ctx, db, arg)
+		if err != nil {
+			return err
+		}
+		loan, err := s.ReturnItem(ctx, item.ID)
+		if err != nil {
+			return fmt.Errorf("item %s: %w", arg, err)
+		}
+		loans = append(loans, loan)
+	}
+	return writeJSON(c.Stdout, struct {
+		Returned []*bookid.Loan `json:"returned"`
+	}{loans})
+}
+
+// runList prints the open loans, all loans or only the overdue ones.
+func (c *LoanCommand) runList(ctx context.Context, cmd string, args []string) error {
+	fs := flag.NewFlagSet("bookid-loan-"+cmd, flag.ContinueOnError)
+	borrower := fs.String("borrower", "", "only loans to this borrower")
+	all := fs.Bool("all", false, "include returned loans")
+	fs.Usage = c.usage
+	if err := parseFlags(ctx, fs, args); err != nil {
+		return err
+	} else if fs.NArg() > 0 {
+		return fmt.Errorf("usage: bookid loan %s [-borrower name] [-all]", cmd)
+	}
+
+	filter := bookid.LoanFilter{Open: !*all, Overdue: cmd == "overdue"}
+	if *borrower != "" {
+		filter.Borrower = borrower
+	}
+
+	db, err := openDB(c.Config)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	loans, n, err := sqlite.NewLoanService(db).FindLoans(ctx, filter)
+	if err != nil {
+		return err
+	}
+	return writeJSON(c.Stdout, struct {
+		Loans []*bookid.Loan `json:"loans"`
+		Total int            `json:"total"`
+	}{loans, n})
+}
+
+// findItem returns the item with a barcode or, failing that, an ID.
+// Returns ENOTFOUND if there is none.
+func findItem(ctx context.Context, db *sqlite.DB, arg string) (*bookid.Item, error) {
+	s := sqlite.NewItemService(db)
+	items, _, err := s.FindItems(ctx, bookid.ItemFilter{Barcode: &arg})
+	if err != nil {
+		return nil, err
+	} else if len(items) > 0 {
+		return items[0], nil
+	}
+
+	id, err := strconv.ParseInt(arg, 10, 64)
+	if err != nil {
+		return nil, bookid.Errorf(bookid.ENOTFOUND, "Item %q not found.", arg)
+	}
+	return s.FindItemByID(ctx, id)
+}
+
+// usage prints the help text for the command.
+func (c *LoanCommand) usage() {
+	fmt.Fprintln(os.Stderr, strings.TrimSpace(`
+Tracks who has which items of the catalog. Items are the physical copies of
+publications and are given by barcode or item ID. An item on loan cannot be
+checked out again until it is returned.
+
+Usage:
+
+	bookid loan checkout [-due date] <item> <borrower>
+	bookid loan return <item>...
+	bookid loan list [-borrower name] [-all]
+	bookid loan overdue [-borrower name]
+
+The commands are:
+
+	checkout  lend an item, due in 14 days unless -due says otherwise
+	return    check items back in
+	list      list the open loans, or all loans with -all
+	overdue   list the open loans past their due date
+`))
+}
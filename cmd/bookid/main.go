@@ -3,109 +3,754 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"flag"
 	"fmt"
+	"io"
+	"log/slog"
 	"os"
+	"os/signal"
+	"os/user"
+	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/aggregate"
+	"github.com/fwojciec/bookid/audnexus"
+	"github.com/fwojciec/bookid/breaker"
+	"github.com/fwojciec/bookid/cache"
+	"github.com/fwojciec/bookid/config"
+	"github.com/fwojciec/bookid/crossref"
+	"github.com/fwojciec/bookid/description"
+	"github.com/fwojciec/bookid/fallback"
 	"github.com/fwojciec/bookid/googlebooks"
+	"github.com/fwojciec/bookid/isbndb"
+	"github.com/fwojciec/bookid/language"
+	"github.com/fwojciec/bookid/loc"
+	"github.com/fwojciec/bookid/local"
+	"github.com/fwojciec/bookid/match"
+	"github.com/fwojciec/bookid/metrics"
+	"github.com/fwojciec/bookid/openlibrary"
+	"github.com/fwojciec/bookid/policy"
+	"github.com/fwojciec/bookid/query"
+	"github.com/fwojciec/bookid/ratelimit"
+	"github.com/fwojciec/bookid/render"
+	"github.com/fwojciec/bookid/sqlite"
+	"github.com/fwojciec/bookid/sru"
+	"github.com/fwojciec/bookid/tracing"
+	"github.com/fwojciec/bookid/worldcat"
 )
 
 const (
-	defaultTimeout = 30 * time.Second
+	defaultTimeout  = 30 * time.Second
+	defaultCacheTTL = cache.DefaultTTL
+
+	// defaultRateLimit is the maximum number of requests per second sent to
+	// each provider.
+	defaultRateLimit = 2
 )
 
+// Config represents the settings of all commands, from defaults overridden by
+// the configuration file and then the environment.
 type Config struct {
-	GoogleBooksAPIKey string
-	Timeout           time.Duration
+	// Providers searched for books, most preferred first.
+	Providers []string
+
+	// Search all providers at once and merge their results.
+	Merge bool
+
+	// Merge policies of fields providers disagree on, applied when merging
+	// results and refreshing publications.
+	Policies policy.Policies
+
+	// Default output format of commands that support several.
+	Format string
+
+	// Credentials and endpoints of the providers by name.
+	Profiles map[string]bookid.ProviderConfig
+
+	Timeout   time.Duration
+	DBPath    string
+	CoverDir  string
+	CacheTTL  time.Duration
+	RateLimit float64
+
+	// Failed searches in a row after which a provider is skipped for the
+	// cool-down.
+	BreakerThreshold int
+	BreakerCooldown  time.Duration
+
+	// Longest description kept from providers, in characters. Zero keeps
+	// descriptions whole.
+	DescriptionLength int
+
+	// Minimum level of the log written to stderr.
+	LogLevel slog.Level
+
+	// Exporter of OpenTelemetry spans. Tracing is disabled if empty.
+	TraceExporter string
+
+	// Name recorded in the audit log as making changes to the catalog.
+	Actor string
+
+	// Resolve queries from the catalog and search cache instead of the
+	// providers, making no network calls.
+	Offline bool
+
+	// Resolve queries from the catalog and search cache when they have a
+	// good match, and from the providers otherwise.
+	LocalFirst bool
 }
 
 func main() {
-	if err := run(); err != nil {
-		fmt.Fprintf(os.Stderr, "error: %v\n", err)
-		os.Exit(1)
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	globals, args, err := parseGlobalFlags(os.Args[1:])
+	if err == nil {
+		err = run(ctx, globals, args, os.Stdout)
 	}
+	writeError(os.Stderr, globals.Errors, err)
+	os.Exit(exitCode(err))
 }
 
-func run() error {
-	if len(os.Args) < 2 {
-		return fmt.Errorf("usage: bookid <search query>")
-	}
+// globalFlags are the flags given before the command.
+type globalFlags struct {
+	// Log debug messages to stderr.
+	Verbose bool
+
+	// Resolve queries from the catalog and search cache only.
+	Offline bool
+
+	// Format of errors written to stderr: text or json.
+	Errors string
+}
 
-	// Combine all arguments after the program name as the search query
-	query := strings.Join(os.Args[1:], " ")
+// newGlobalFlagSet returns the flag set of the global flags, which are
+// stored in globals.
+func newGlobalFlagSet(globals *globalFlags) *flag.FlagSet {
+	fs := flag.NewFlagSet("bookid", flag.ContinueOnError)
+	fs.BoolVar(&globals.Verbose, "verbose", false, "log queries, provider latencies, cache hits and database operations to stderr")
+	fs.BoolVar(&globals.Verbose, "v", false, "shorthand for -verbose")
+	fs.BoolVar(&globals.Offline, "offline", false, "resolve queries from the catalog and cached searches without network calls")
+	fs.StringVar(&globals.Errors, "errors", errorsText, "format of errors written to stderr: text or json")
+	return fs
+}
 
-	// Load configuration
-	config := loadConfig()
+// parseGlobalFlags returns the global flags at the start of args along with
+// the command and its arguments.
+func parseGlobalFlags(args []string) (globalFlags, []string, error) {
+	var globals globalFlags
+	fs := newGlobalFlagSet(&globals)
+	fs.Usage = func() { fmt.Fprintln(os.Stderr, usage()) }
+	if err := fs.Parse(args); err != nil {
+		return globals, nil, err
+	} else if globals.Errors != errorsText && globals.Errors != errorsJSON {
+		return globals, nil, bookid.Errorf(bookid.EINVALID, "Invalid error format %q, must be text or json.", globals.Errors)
+	}
+	return globals, fs.Args(), nil
+}
 
-	// Create Google Books client
-	client, err := googlebooks.NewClient(config.GoogleBooksAPIKey)
+// run executes the subcommand named by the first argument.
+func run(ctx context.Context, globals globalFlags, args []string, stdout io.Writer) error {
+	config, err := loadConfig()
 	if err != nil {
-		return fmt.Errorf("creating Google Books client: %w", err)
+		return err
+	}
+	if globals.Verbose {
+		config.LogLevel = slog.LevelDebug
+	}
+	if globals.Offline {
+		config.Offline = true
 	}
 
-	// Create context with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), config.Timeout)
-	defer cancel()
+	var cmd string
+	if len(args) > 0 {
+		cmd, args = args[0], args[1:]
+	}
+	ctx = bookid.NewContextWithActor(ctx, config.Actor)
+	config.logger().Debug("running command",
+		"command", cmd, "providers", config.Providers, "timeout", config.Timeout, "db", config.DBPath)
+
+	// Spans are written to stderr by the stdout exporter as stdout holds
+	// the command's output.
+	if config.TraceExporter != "" {
+		tp, err := tracing.NewTracerProvider(ctx, config.TraceExporter, os.Stderr)
+		if err != nil {
+			return fmt.Errorf("setting up tracing: %w", err)
+		}
+		tracing.SetTracerProvider(tp)
+		defer func() { _ = tp.Shutdown(context.Background()) }()
+	}
+
+	// Asking for help succeeds, but a missing command is an error.
+	switch cmd {
+	case "":
+		fmt.Fprintln(os.Stderr, usage())
+		return bookid.Errorf(bookid.EINVALID, "No command given.")
+	case "-h", "-help", "--help", "help":
+		fmt.Fprintln(os.Stderr, usage())
+		return flag.ErrHelp
+	}
+	c := findCommand(cmd)
+	if c == nil {
+		return fmt.Errorf("bookid %s: unknown command\n%s", cmd, usage())
+	}
+	return c.New(config, stdout).Run(ctx, args)
+}
+
+// usage returns the top-level help text.
+func usage() string {
+	// Align the summaries two spaces past the longest command name.
+	cmds := commands()
+	width := 0
+	for _, c := range cmds {
+		width = max(width, len(c.Name))
+	}
+	var list strings.Builder
+	for _, c := range cmds {
+		fmt.Fprintf(&list, "\t%-*s  %s\n", width, c.Name, c.Summary)
+	}
+
+	return fmt.Sprintf(strings.TrimSpace(`
+bookid identifies books and keeps a local catalog of them.
+
+Usage:
+
+	bookid [-verbose] [-offline] [-errors json] <command> [arguments]
+
+The commands are:
+
+%s
+Settings are read from ~/.config/bookid/config.toml, or the TOML or YAML file
+named by BOOKID_CONFIG, and environment variables take precedence over it.
+Use -verbose or BOOKID_LOG_LEVEL=debug to log queries, provider latencies,
+cache hits and database operations to stderr, and BOOKID_TRACE_EXPORTER=otlp
+to send OpenTelemetry traces to the collector at OTEL_EXPORTER_OTLP_ENDPOINT.
+
+With -offline or BOOKID_OFFLINE=true, no network calls are made: queries are
+resolved from earlier searches in the cache, whatever their age, and from the
+publications in the catalog. Commands that need the providers themselves, such
+as offers and classify, fail.
+
+Errors are written to stderr, or with -errors json as a JSON object with the
+error code, message and exit status. The exit status is 0 on success, 1 on
+error, 2 if a search found no books and 3 if the best result of search, save
+or cite has a confidence below -low-confidence.
+`), list.String())
+}
+
+// loadConfig returns the configuration of the commands. The configuration
+// file is read from BOOKID_CONFIG, if set, or from the default location, if
+// it exists, and environment variables take precedence over it.
+func loadConfig() (Config, error) {
+	c := Config{
+		Providers: defaultProviders(),
+		Profiles:  make(map[string]bookid.ProviderConfig),
+		Format:    render.FormatJSON,
+		Timeout:   defaultTimeout,
+		DBPath:    defaultDBPath(),
+		CacheTTL:  defaultCacheTTL,
+		RateLimit: defaultRateLimit,
+		LogLevel:  slog.LevelWarn,
+		Actor:     defaultActor(),
+
+		BreakerThreshold: breaker.DefaultThreshold,
+		BreakerCooldown:  breaker.DefaultCooldown,
+	}
 
-	// Perform search
-	results, err := client.Search(ctx, query)
+	var file *config.Config
+	var err error
+	if path := os.Getenv("BOOKID_CONFIG"); path != "" {
+		file, err = config.Load(path)
+	} else {
+		file, err = config.LoadDefault()
+	}
 	if err != nil {
-		return fmt.Errorf("searching for books: %w", err)
+		return c, fmt.Errorf("loading configuration: %w", err)
 	}
+	c.applyFile(file)
+	c.applyEnv()
 
-	// Return just the top result if any results were found
-	if len(results) == 0 {
-		// Return empty response
-		response := struct {
-			Query  string             `json:"query"`
-			Result *bookid.BookResult `json:"result"`
-		}{
-			Query:  query,
-			Result: nil,
+	for name := range c.Profiles {
+		if !slices.Contains(bookid.Finders(), name) {
+			return c, bookid.Errorf(bookid.EINVALID, "Unknown provider profile %q.", name)
 		}
-		encoder := json.NewEncoder(os.Stdout)
-		encoder.SetIndent("", "  ")
-		encoder.SetEscapeHTML(false)
-		return encoder.Encode(response)
 	}
+	if err := c.Policies.Validate(); err != nil {
+		return c, err
+	}
+	return c, nil
+}
 
-	// Get the top result and strip out the raw Google Books data
-	topResult := results[0]
-	topResult.GoogleBooksData = nil
+// defaultProviders returns the names of the providers searched unless
+// configured otherwise, most preferred first. Providers without credentials
+// are skipped.
+func defaultProviders() []string {
+	return []string{googlebooks.ProviderName, isbndb.ProviderName, worldcat.ProviderName, sru.ProviderName, openlibrary.ProviderName}
+}
 
-	response := struct {
-		Query  string            `json:"query"`
-		Result bookid.BookResult `json:"result"`
-	}{
-		Query:  query,
-		Result: topResult,
+// applyFile overrides c with the settings of a configuration file.
+func (c *Config) applyFile(file *config.Config) {
+	if len(file.Providers) > 0 {
+		c.Providers = file.Providers
+	}
+	if file.Format != "" {
+		c.Format = file.Format
+	}
+	c.Merge = file.Merge
+	c.LocalFirst = file.LocalFirst
+	if file.Timeout > 0 {
+		c.Timeout = time.Duration(file.Timeout)
+	}
+	if file.CacheTTL > 0 {
+		c.CacheTTL = time.Duration(file.CacheTTL)
+	}
+	if file.RateLimit > 0 {
+		c.RateLimit = file.RateLimit
+	}
+	if file.BreakerThreshold > 0 {
+		c.BreakerThreshold = file.BreakerThreshold
+	}
+	if file.BreakerCooldown > 0 {
+		c.BreakerCooldown = time.Duration(file.BreakerCooldown)
+	}
+	if file.DescriptionLength > 0 {
+		c.DescriptionLength = file.DescriptionLength
 	}
+	if file.DBPath != "" {
+		c.DBPath = file.DBPath
+	}
+	if file.TraceExporter != "" {
+		c.TraceExporter = file.TraceExporter
+	}
+	if file.Actor != "" {
+		c.Actor = file.Actor
+	}
+	c.CoverDir = file.CoverDir
 
-	// Output as pretty-printed JSON without HTML escaping
-	encoder := json.NewEncoder(os.Stdout)
-	encoder.SetIndent("", "  ")
-	encoder.SetEscapeHTML(false)
-	if err := encoder.Encode(response); err != nil {
-		return fmt.Errorf("encoding JSON output: %w", err)
+	for name, p := range file.Policies {
+		if c.Policies == nil {
+			c.Policies = make(policy.Policies)
+		}
+		c.Policies[name] = policy.Policy{Rule: policy.Rule(p.Rule), Providers: p.Providers}
 	}
 
-	return nil
+	for name, profile := range file.Profiles {
+		c.Profiles[name] = bookid.ProviderConfig{
+			APIKey:       profile.APIKey,
+			ClientID:     profile.ClientID,
+			ClientSecret: profile.ClientSecret,
+			URL:          profile.URL,
+			RecordSchema: profile.RecordSchema,
+			Country:      profile.Country,
+			Timeout:      time.Duration(profile.Timeout),
+		}
+	}
 }
 
-func loadConfig() Config {
-	config := Config{
-		GoogleBooksAPIKey: os.Getenv("GOOGLE_BOOKS_API_KEY"),
-		Timeout:           defaultTimeout,
+// applyEnv overrides c with the settings of environment variables.
+func (c *Config) applyEnv() {
+	for _, env := range []struct {
+		name     string
+		provider string
+		field    func(*bookid.ProviderConfig) *string
+	}{
+		{"GOOGLE_BOOKS_API_KEY", googlebooks.ProviderName, func(p *bookid.ProviderConfig) *string { return &p.APIKey }},
+		{"ISBNDB_API_KEY", isbndb.ProviderName, func(p *bookid.ProviderConfig) *string { return &p.APIKey }},
+		{"WORLDCAT_CLIENT_ID", worldcat.ProviderName, func(p *bookid.ProviderConfig) *string { return &p.ClientID }},
+		{"WORLDCAT_CLIENT_SECRET", worldcat.ProviderName, func(p *bookid.ProviderConfig) *string { return &p.ClientSecret }},
+		{"BOOKID_SRU_URL", sru.ProviderName, func(p *bookid.ProviderConfig) *string { return &p.URL }},
+		{"BOOKID_SRU_SCHEMA", sru.ProviderName, func(p *bookid.ProviderConfig) *string { return &p.RecordSchema }},
+		{"GOOGLE_BOOKS_COUNTRY", googlebooks.ProviderName, func(p *bookid.ProviderConfig) *string { return &p.Country }},
+	} {
+		if v := os.Getenv(env.name); v != "" {
+			profile := c.Profiles[env.provider]
+			*env.field(&profile) = v
+			c.Profiles[env.provider] = profile
+		}
+	}
+
+	if format := os.Getenv("BOOKID_FORMAT"); format != "" {
+		c.Format = format
+	}
+
+	// Allow provider priority override as a comma-separated list
+	if providers := os.Getenv("BOOKID_PROVIDERS"); providers != "" {
+		c.Providers = nil
+		for _, name := range strings.Split(providers, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				c.Providers = append(c.Providers, name)
+			}
+		}
+	}
+
+	// Allow merging of provider results to be switched via environment variable
+	if mergeStr := os.Getenv("BOOKID_MERGE"); mergeStr != "" {
+		if merge, err := strconv.ParseBool(mergeStr); err == nil {
+			c.Merge = merge
+		}
+	}
+
+	// Allow local-first lookups to be switched via environment variable
+	if localFirstStr := os.Getenv("BOOKID_LOCAL_FIRST"); localFirstStr != "" {
+		if localFirst, err := strconv.ParseBool(localFirstStr); err == nil {
+			c.LocalFirst = localFirst
+		}
+	}
+
+	// Allow offline mode to be switched on via environment variable
+	if offlineStr := os.Getenv("BOOKID_OFFLINE"); offlineStr != "" {
+		if offline, err := strconv.ParseBool(offlineStr); err == nil {
+			c.Offline = offline
+		}
 	}
 
 	// Allow timeout override via environment variable
 	if timeoutStr := os.Getenv("BOOKID_TIMEOUT"); timeoutStr != "" {
 		if timeout, err := time.ParseDuration(timeoutStr); err == nil {
-			config.Timeout = timeout
+			c.Timeout = timeout
+		}
+	}
+
+	// Allow search cache TTL override via environment variable; zero disables caching
+	if ttlStr := os.Getenv("BOOKID_CACHE_TTL"); ttlStr != "" {
+		if ttl, err := time.ParseDuration(ttlStr); err == nil {
+			c.CacheTTL = ttl
 		}
 	}
 
-	return config
+	// Allow provider rate limit override via environment variable; zero disables throttling
+	if rateStr := os.Getenv("BOOKID_RATE_LIMIT"); rateStr != "" {
+		if rate, err := strconv.ParseFloat(rateStr, 64); err == nil {
+			c.RateLimit = rate
+		}
+	}
+
+	// Allow description truncation override via environment variable; zero keeps descriptions whole
+	if lengthStr := os.Getenv("BOOKID_DESCRIPTION_LENGTH"); lengthStr != "" {
+		if length, err := strconv.Atoi(lengthStr); err == nil && length >= 0 {
+			c.DescriptionLength = length
+		}
+	}
+
+	// Allow log level override via environment variable, e.g. "debug"
+	if levelStr := os.Getenv("BOOKID_LOG_LEVEL"); levelStr != "" {
+		var level slog.Level
+		if err := level.UnmarshalText([]byte(levelStr)); err == nil {
+			c.LogLevel = level
+		}
+	}
+
+	// Allow trace exporter override via environment variable; "none" disables tracing
+	if exporter := os.Getenv("BOOKID_TRACE_EXPORTER"); exporter == "none" {
+		c.TraceExporter = ""
+	} else if exporter != "" {
+		c.TraceExporter = exporter
+	}
+
+	// Allow audit log actor override via environment variable
+	if actor := os.Getenv("BOOKID_ACTOR"); actor != "" {
+		c.Actor = actor
+	}
+
+	// Allow database location override via environment variable
+	if dbPath := os.Getenv("BOOKID_DB"); dbPath != "" {
+		c.DBPath = dbPath
+	}
+
+	// Covers are stored next to the database unless overridden
+	if coverDir := os.Getenv("BOOKID_COVERS"); coverDir != "" {
+		c.CoverDir = coverDir
+	} else if c.CoverDir == "" {
+		c.CoverDir = filepath.Join(filepath.Dir(c.DBPath), "covers")
+	}
+}
+
+// defaultActor returns the name of the current user, recorded in the audit
+// log unless configured otherwise.
+func defaultActor() string {
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return os.Getenv("USER")
+}
+
+// defaultDBPath returns the catalog location in the user's home directory,
+// falling back to the working directory if it cannot be determined.
+func defaultDBPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(".bookid", "db")
+	}
+	return filepath.Join(home, ".bookid", "db")
+}
+
+// logger returns the logger of the commands, writing to stderr.
+func (c *Config) logger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: c.LogLevel}))
+}
+
+// openDB opens the catalog database described by config.
+func openDB(cfg Config) (*sqlite.DB, error) {
+	db := sqlite.NewDB(cfg.DBPath)
+	db.Logger = cfg.logger()
+	if err := db.Open(); err != nil {
+		return nil, fmt.Errorf("opening catalog database %q: %w", cfg.DBPath, err)
+	}
+	return db, nil
+}
+
+// newFinder returns the BookFinder used by the commands: the configured
+// providers from the provider registry in priority order, by default Google
+// Books falling back to ISBNdb and WorldCat, if credentials are configured,
+// an SRU catalog, if an endpoint is configured, and then Open Library, each
+// rate limited and retrying transient failures and moving on to the next
+// when it fails, finds nothing or exceeds its timeout, with result languages
+// normalized to BCP-47 tags and results re-ranked against the query and
+// memoized in the catalog's search cache unless caching is disabled, and
+// descriptions truncated to the configured length. LCCN queries go to the
+// Library of Congress first, DOI queries to Crossref and ASIN queries to
+// Audnexus. In offline mode, queries are resolved from the catalog and the
+// search cache instead, and in local-first mode from them before the
+// providers.
+func newFinder(cfg Config, db *sqlite.DB) (bookid.BookFinder, error) {
+	return newInstrumentedFinder(cfg, db, nil)
+}
+
+// newInstrumentedFinder returns the finder of newFinder, recording provider
+// searches and search cache lookups to m unless it is nil.
+func newInstrumentedFinder(cfg Config, db *sqlite.DB, m *metrics.Metrics) (bookid.BookFinder, error) {
+	if cfg.Offline {
+		return newLocalFinder(db), nil
+	}
+
+	logger := cfg.logger()
+	providers, err := newProviders(cfg, logger, m)
+	if err != nil {
+		return nil, err
+	}
+
+	// Search providers one at a time within the overall timeout, or all at
+	// once merging their results. LCCN, DOI and ASIN queries try their
+	// specialist provider before the others.
+	var finder bookid.BookFinder = newFallbackFinder(cfg, logger, providers...)
+	if cfg.Merge {
+		finder = newAggregateFinder(cfg, logger, providers...)
+	}
+	routes := []route{
+		{match: isLCCN, provider: loc.ProviderName},
+		{match: isDOI, provider: crossref.ProviderName},
+		{match: isASIN, provider: audnexus.ProviderName},
+	}
+	for i, r := range routes {
+		provider, err := newProvider(r.provider, cfg, logger, m)
+		if err != nil {
+			return nil, err
+		}
+		routes[i].finder = newFallbackFinder(cfg, logger, append([]fallback.Provider{{
+			Name:    r.provider,
+			Finder:  guardFinder(cfg, logger, r.provider, provider),
+			Timeout: cfg.Profiles[r.provider].Timeout,
+		}}, providers...)...)
+	}
+	finder = &routeFinder{routes: routes, finder: finder}
+	finder = match.NewFinder(language.NewFinder(finder))
+	if cfg.LocalFirst {
+		// Results of the providers are cached by the local-first finder,
+		// which serves them whatever their age.
+		localFirst := local.NewLocalFirstFinder(newLocalFinder(db), finder, sqlite.NewSearchCache(db))
+		localFirst.Logger = logger
+		return traceFinder(cfg, description.NewFinder(localFirst, cfg.DescriptionLength), ""), nil
+	}
+	if cfg.CacheTTL <= 0 {
+		return traceFinder(cfg, description.NewFinder(finder, cfg.DescriptionLength), ""), nil
+	}
+
+	cachingFinder := cache.NewCachingFinder(finder, sqlite.NewSearchCache(db))
+	cachingFinder.TTL = cfg.CacheTTL
+	cachingFinder.Logger = logger
+	if m != nil {
+		m.RegisterCache(cachingFinder.Stats)
+	}
+	return traceFinder(cfg, description.NewFinder(cachingFinder, cfg.DescriptionLength), ""), nil
+}
+
+// newProviders returns the configured providers, most preferred first,
+// guarded by guardFinder. Providers come from the registry; those without
+// credentials are skipped. Returns EINVALID in offline mode.
+func newProviders(cfg Config, logger *slog.Logger, m *metrics.Metrics) ([]fallback.Provider, error) {
+	if err := requireOnline(cfg); err != nil {
+		return nil, err
+	}
+	var providers []fallback.Provider
+	for _, name := range cfg.Providers {
+		provider, err := newProvider(name, cfg, logger, m)
+		if bookid.ErrorCode(err) == bookid.EUNAUTHORIZED {
+			logger.Debug("skipping provider without credentials", "provider", name)
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		providers = append(providers, fallback.Provider{
+			Name:    name,
+			Finder:  guardFinder(cfg, logger, name, provider),
+			Timeout: cfg.Profiles[name].Timeout,
+		})
+	}
+	if len(providers) == 0 {
+		return nil, bookid.Errorf(bookid.EINVALID, "No configured providers; set their credentials or change the provider list.")
+	}
+	return providers, nil
+}
+
+// newLocalFinder returns a finder resolving queries from the catalog and the
+// search cache in db, for offline mode.
+func newLocalFinder(db *sqlite.DB) *local.LocalFinder {
+	return local.NewLocalFinder(
+		sqlite.NewWorkService(db),
+		sqlite.NewAuthorService(db),
+		sqlite.NewPublicationService(db),
+		sqlite.NewCatalogSearchService(db),
+		sqlite.NewSearchCache(db),
+	)
+}
+
+// requireOnline returns EINVALID in offline mode, for commands that need
+// the providers themselves rather than any finder.
+func requireOnline(cfg Config) error {
+	if cfg.Offline {
+		return bookid.Errorf(bookid.EINVALID, "Providers cannot be reached in offline mode.")
+	}
+	return nil
+}
+
+// guardFinder returns the finder of the provider name rate limited and
+// behind a circuit breaker, so that a provider failing repeatedly is skipped
+// for a while instead of holding up every search.
+func guardFinder(cfg Config, logger *slog.Logger, name string, finder bookid.BookFinder) bookid.BookFinder {
+	b := breaker.NewFinder(ratelimit.NewFinder(finder, cfg.RateLimit))
+	b.Threshold, b.Cooldown = cfg.BreakerThreshold, cfg.BreakerCooldown
+	b.Logger = logger.With("provider", name)
+	return b
+}
+
+// traceFinder returns finder recording its searches as spans named after
+// provider, if tracing is enabled.
+func traceFinder(cfg Config, finder bookid.BookFinder, provider string) bookid.BookFinder {
+	if cfg.TraceExporter == "" {
+		return finder
+	}
+	return tracing.NewFinder(finder, provider)
+}
+
+// newProvider returns the finder of the provider registered under name,
+// configured by its profile and logging to logger, with its searches
+// recorded to m unless it is nil and traced if tracing is enabled.
+func newProvider(name string, cfg Config, logger *slog.Logger, m *metrics.Metrics) (bookid.BookFinder, error) {
+	profile := cfg.Profiles[name]
+	profile.Logger = logger
+	if cfg.TraceExporter != "" {
+		profile.HTTPClient = tracing.NewHTTPClient()
+	}
+	finder, err := bookid.NewFinder(name, profile)
+	if err != nil {
+		return nil, err
+	} else if m != nil {
+		finder = metrics.NewFinder(finder, m, name)
+	}
+	return traceFinder(cfg, finder, name), nil
+}
+
+// newFallbackFinder returns a finder searching providers in order within the
+// overall timeout.
+func newFallbackFinder(cfg Config, logger *slog.Logger, providers ...fallback.Provider) *fallback.FallbackFinder {
+	finder := fallback.NewFallbackFinder(providers...)
+	finder.Timeout = cfg.Timeout
+	finder.Logger = logger
+	return finder
+}
+
+// newAggregateFinder returns a finder merging the results of all providers
+// within the configured timeout.
+func newAggregateFinder(cfg Config, logger *slog.Logger, providers ...fallback.Provider) *aggregate.Finder {
+	ps := make([]aggregate.Provider, len(providers))
+	for i, p := range providers {
+		ps[i] = aggregate.Provider(p)
+	}
+	finder := aggregate.NewFinder(ps...)
+	finder.Policies = cfg.Policies
+	finder.Timeout = cfg.Timeout
+	finder.Logger = logger
+	return finder
+}
+
+// routeFinder sends queries to the finder of the first route that matches
+// them and everything else to finder. A query of nothing but an ASIN that
+// finds nothing returns EINVALID, as it could not be searched by other means.
+type routeFinder struct {
+	routes []route
+	finder bookid.BookFinder
+}
+
+// route pairs a query predicate with the provider handling matching queries.
+type route struct {
+	match    func(query string) bool
+	provider string
+	finder   bookid.BookFinder
+}
+
+// Search implements bookid.BookFinder.
+func (f *routeFinder) Search(ctx context.Context, q string, opts bookid.SearchOptions) ([]bookid.BookResult, error) {
+	finder := f.finder
+	for _, r := range f.routes {
+		if r.match(q) {
+			finder = r.finder
+			break
+		}
+	}
+	results, err := finder.Search(ctx, q, opts)
+	if err == nil && len(results) == 0 {
+		err = query.Validate(query.Parse(q))
+	}
+	return results, err
+}
+
+// isLCCN reports whether q is searched by Library of Congress Control
+// Number.
+func isLCCN(q string) bool {
+	return query.Parse(q).SearchType() == bookid.SearchTypeLCCN
+}
+
+// isDOI reports whether q is searched by Digital Object Identifier.
+func isDOI(q string) bool {
+	return query.Parse(q).SearchType() == bookid.SearchTypeDOI
+}
+
+// isASIN reports whether q is searched by Amazon Standard Identification
+// Number.
+func isASIN(q string) bool {
+	return query.Parse(q).SearchType() == bookid.SearchTypeASIN
+}
+
+// errorMessage returns the user-facing message for err. Application errors
+// carry a message meant for the user; anything else is reported verbatim.
+func errorMessage(err error) string {
+	if bookid.ErrorCode(err) != bookid.EINTERNAL {
+		return bookid.ErrorMessage(err)
+	}
+	return err.Error()
+}
+
+// writeJSON writes v as pretty-printed JSON without HTML escaping.
+func writeJSON(w io.Writer, v any) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(v); err != nil {
+		return fmt.Errorf("encoding JSON output: %w", err)
+	}
+	return nil
 }
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/mock"
+	"github.com/fwojciec/bookid/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// MustOpenDB returns an open catalog database in a temporary directory along
+// with a configuration using it. Close the database before running commands
+// against it.
+func MustOpenDB(tb testing.TB) (*sqlite.DB, Config) {
+	tb.Helper()
+	db := sqlite.NewDB(filepath.Join(tb.TempDir(), "catalog.db"))
+	require.NoError(tb, db.Open())
+	return db, Config{DBPath: db.DSN}
+}
+
+// MustCloseDB closes the database.
+func MustCloseDB(tb testing.TB, db *sqlite.DB) {
+	tb.Helper()
+	require.NoError(tb, db.Close())
+}
+
+func TestUsage(t *testing.T) {
+	t.Parallel()
+
+	help := usage()
+	for _, c := range commands() {
+		var line string
+		for _, l := range strings.Split(help, "\n") {
+			if strings.HasPrefix(l, "\t"+c.Name+" ") {
+				line = l
+				break
+			}
+		}
+		if assert.NotEmpty(t, line, "no help line for %s", c.Name) {
+			assert.Equal(t, c.Summary, strings.TrimSpace(strings.TrimPrefix(line, "\t"+c.Name)), "the name and summary of %s are separated", c.Name)
+		}
+	}
+}
+
+func TestUsage_LongNames(t *testing.T) {
+	t.Parallel()
+
+	// The names of rebuild-works and translations are longer than the
+	// column once reserved for command names.
+	help := usage()
+	assert.Regexp(t, regexp.MustCompile(`\n\trebuild-works +re-cluster`), help)
+	assert.Regexp(t, regexp.MustCompile(`\n\ttranslations +link translated`), help)
+}
+
+func TestRun_UnknownCommand(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	err := run(context.Background(), globalFlags{}, []string{"frobnicate"}, &buf)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "bookid frobnicate: unknown command")
+	assert.Contains(t, err.Error(), "The commands are:", "the error lists the commands")
+	assert.Equal(t, exitError, exitCode(err))
+	assert.Empty(t, buf.String())
+}
+
+func TestRun_Help(t *testing.T) {
+	t.Parallel()
+
+	for _, args := range [][]string{{"help"}, {"-h"}, {"--help"}} {
+		err := run(context.Background(), globalFlags{}, args, &bytes.Buffer{})
+		assert.ErrorIs(t, err, flag.ErrHelp)
+		assert.Equal(t, exitOK, exitCode(err), "%v", args)
+	}
+}
+
+func TestRun_NoCommand(t *testing.T) {
+	t.Parallel()
+
+	err := run(context.Background(), globalFlags{}, nil, &bytes.Buffer{})
+	assert.Equal(t, bookid.EINVALID, bookid.ErrorCode(err))
+	assert.Equal(t, exitError, exitCode(err), "a missing command fails")
+}
+
+func TestRun_Dispatch(t *testing.T) {
+	t.Parallel()
+
+	// Commands are found by name and run with the remaining arguments.
+	err := run(context.Background(), globalFlags{}, []string{"show", "1", "2"}, &bytes.Buffer{})
+	assert.EqualError(t, err, "usage: bookid show <id>")
+}
+
+func TestCommands_Help(t *testing.T) {
+	t.Parallel()
+
+	// paths returns the arguments naming c and each of its subcommands.
+	var paths func(prefix []string, cmds []*command) [][]string
+	paths = func(prefix []string, cmds []*command) [][]string {
+		var out [][]string
+		for _, c := range cmds {
+			path := append(append([]string(nil), prefix...), c.Name)
+			out = append(out, path)
+			out = append(out, paths(path, c.Subcommands)...)
+		}
+		return out
+	}
+
+	for _, c := range commands() {
+		for _, path := range paths(nil, []*command{c}) {
+			t.Run(strings.Join(path, " "), func(t *testing.T) {
+				t.Parallel()
+				config := Config{DBPath: filepath.Join(t.TempDir(), "catalog.db")}
+				var buf bytes.Buffer
+				err := c.New(config, &buf).Run(context.Background(), append(path[1:], "-h"))
+				assert.ErrorIs(t, err, flag.ErrHelp)
+				assert.Equal(t, exitOK, exitCode(err))
+				assert.Empty(t, buf.String(), "help is written to stderr")
+			})
+		}
+	}
+}
+
+func TestRouteFinder(t *testing.T) {
+	t.Parallel()
+
+	// finder returns a finder that records the queries it is asked in
+	// searched and finds nothing.
+	finder := func(searched *[]string) bookid.BookFinder {
+		return &mock.BookFinder{SearchFn: func(_ context.Context, query string, _ bookid.SearchOptions) ([]bookid.BookResult, error) {
+			*searched = append(*searched, query)
+			return nil, nil
+		}}
+	}
+	var routed, other []string
+	f := &routeFinder{
+		routes: []route{{match: isASIN, provider: "audnexus", finder: finder(&routed)}},
+		finder: finder(&other),
+	}
+
+	for _, q := range []string{"B00K0OI42W", "https://www.amazon.com/dp/B00K0OI42W"} {
+		_, err := f.Search(context.Background(), q, bookid.SearchOptions{})
+		assert.Equal(t, bookid.EINVALID, bookid.ErrorCode(err), q)
+		assert.Contains(t, bookid.ErrorMessage(err), "ASIN B00K0OI42W cannot be searched", q)
+	}
+	results, err := f.Search(context.Background(), "dune", bookid.SearchOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, results)
+	assert.Equal(t, []string{"B00K0OI42W", "https://www.amazon.com/dp/B00K0OI42W"}, routed)
+	assert.Equal(t, []string{"dune"}, other)
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// ManCommand represents a command for printing the manual page.
+type ManCommand struct {
+	Config Config
+	Stdout io.Writer
+}
+
+// Run executes the command.
+func (c *ManCommand) Run(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("bookid-man", flag.ContinueOnError)
+	fs.Usage = c.usage
+	if err := parseFlags(ctx, fs, args); err != nil {
+		return err
+	} else if fs.NArg() != 0 {
+		return fmt.Errorf("usage: bookid man")
+	}
+
+	docs, err := describe(ctx, c.Config)
+	if err != nil {
+		return err
+	}
+	return writeManPage(c.Stdout, docs)
+}
+
+// writeManPage writes the manual page of bookid in roff, documenting every
+// command and subcommand along with its flags.
+func writeManPage(w io.Writer, docs []*commandDoc) error {
+	var b strings.Builder
+	b.WriteString(`.TH BOOKID 1 "" "bookid" "User Commands"
+.SH NAME
+bookid \- identify books and keep a local catalog of them
+.SH SYNOPSIS
+.B bookid
+[\fB\-verbose\fR] [\fB\-errors\fR \fIjson\fR] \fIcommand\fR [\fIarguments\fR]
+.SH DESCRIPTION
+bookid identifies books by ISBN, identifier, link or free text with online
+providers such as Google Books and Open Library, and keeps a local catalog of
+the works and publications it saves.
+Run \fBbookid\fR \fIcommand\fR \fB\-h\fR for the full help of a command.
+.SH OPTIONS
+`)
+	writeManFlags(&b, docs[0].Flags)
+
+	b.WriteString(".SH COMMANDS\n")
+	for _, doc := range docs[1:] {
+		fmt.Fprintf(&b, ".SS %s\n", roffEscape(doc.Name()))
+		if doc.Summary != "" {
+			fmt.Fprintf(&b, "%s.\n", roffEscape(strings.ToUpper(doc.Summary[:1])+doc.Summary[1:]))
+		}
+		if len(doc.Subcommands) > 0 {
+			names := make([]string, len(doc.Subcommands))
+			for i, sub := range doc.Subcommands {
+				names[i] = `\fB` + roffEscape(sub.Name) + `\fR`
+			}
+			fmt.Fprintf(&b, ".PP\nSubcommands: %s.\n", strings.Join(names, ", "))
+		}
+		writeManFlags(&b, doc.Flags)
+	}
+
+	b.WriteString(`.SH EXIT STATUS
+.TP
+.B 0
+The command succeeded.
+.TP
+.B 1
+The command failed.
+.TP
+.B 2
+A search found no books.
+.TP
+.B 3
+The best result of a search has a confidence below \fB\-low\-confidence\fR.
+.SH ENVIRONMENT
+Environment variables take precedence over the configuration file.
+.TP
+.B BOOKID_CONFIG
+Path of the TOML or YAML configuration file.
+.TP
+.B BOOKID_DB
+Path of the catalog database.
+.TP
+.B BOOKID_COVERS
+Directory cover images are stored in.
+.TP
+.B BOOKID_PROVIDERS
+Comma-separated providers searched for books, most preferred first.
+.TP
+.B BOOKID_MERGE
+Search all providers at once and merge their results for the same book.
+.TP
+.B BOOKID_FORMAT
+Default output format of commands that support several.
+.TP
+.B BOOKID_TIMEOUT
+Timeout of requests to providers.
+.TP
+.B BOOKID_CACHE_TTL
+How long search results are cached.
+.TP
+.B BOOKID_RATE_LIMIT
+Maximum number of requests per second sent to each provider.
+.TP
+.B BOOKID_DESCRIPTION_LENGTH
+Longest book description kept from providers, in characters; zero keeps them whole.
+.TP
+.B BOOKID_LOG_LEVEL
+Minimum level of the log written to stderr.
+.TP
+.B BOOKID_TRACE_EXPORTER
+Exporter of OpenTelemetry spans; tracing is disabled if empty.
+.TP
+.B BOOKID_ACTOR
+Name recorded in the audit log as making changes to the catalog.
+.SH FILES
+.TP
+.I ~/.config/bookid/config.toml
+Default configuration file.
+`)
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeManFlags writes a tagged paragraph per flag with its usage and
+// default value.
+func writeManFlags(b *strings.Builder, flags []*flag.Flag) {
+	for _, f := range flags {
+		name, usage := flag.UnquoteUsage(f)
+		fmt.Fprintf(b, ".TP\n\\fB\\-%s\\fR", roffEscape(f.Name))
+		if name != "" {
+			fmt.Fprintf(b, " \\fI%s\\fR", roffEscape(name))
+		}
+		b.WriteString("\n" + roffEscape(usage))
+		if f.DefValue != "" && f.DefValue != "false" && f.DefValue != "0" {
+			fmt.Fprintf(b, " (default %s)", roffEscape(f.DefValue))
+		}
+		b.WriteString("\n")
+	}
+}
+
+// roffEscape escapes text for use in roff, so that backslashes, hyphens and
+// leading control characters are printed as they are.
+func roffEscape(s string) string {
+	s = strings.NewReplacer(`\`, `\e`, "-", `\-`).Replace(s)
+	if strings.HasPrefix(s, ".") || strings.HasPrefix(s, "'") {
+		s = `\&` + s
+	}
+	return s
+}
+
+// usage prints the help text for the command.
+func (c *ManCommand) usage() {
+	fmt.Fprintln(os.Stderr, strings.TrimSpace(`
+Prints the manual page of bookid in roff, generated from the commands and
+their flags. To read it, or install it for man(1):
+
+	bookid man | man -l -
+	bookid man > /usr/local/share/man/man1/bookid.1
+
+Usage:
+
+	bookid man
+`))
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/fwojciec/bookid/mcp"
+	"github.com/fwojciec/bookid/sqlite"
+)
+
+// MCPCommand represents a command for serving bookid tools to LLM agents
+// over the Model Context Protocol.
+type MCPCommand struct {
+	Config Config
+}
+
+// Run executes the command. It serves the tools over stdin and stdout until
+// the client disconnects or ctx is canceled.
+func (c *MCPCommand) Run(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("bookid-mcp", flag.ContinueOnError)
+	fs.Usage = c.usage
+	if err := parseFlags(ctx, fs, args); err != nil {
+		return err
+	} else if fs.NArg() != 0 {
+		return fmt.Errorf("usage: bookid mcp")
+	}
+
+	db, err := openDB(c.Config)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	finder, err := newFinder(c.Config, db)
+	if err != nil {
+		return err
+	}
+
+	server := mcp.NewServer()
+	server.BookFinder = finder
+	server.CatalogService = sqlite.NewCatalogService(db)
+	server.CatalogSearchService = sqlite.NewCatalogSearchService(db)
+	return server.Run(ctx)
+}
+
+// usage prints the help text for the command.
+func (c *MCPCommand) usage() {
+	fmt.Fprintln(os.Stderr, strings.TrimSpace(`
+Serves bookid tools to LLM agents over the Model Context Protocol on stdin and
+stdout, until the client disconnects. The tools are:
+
+	identify_book   identify a book from an ISBN, DOI, LCCN or title and author
+	save_book       save an identified book to the catalog
+	search_catalog  search the catalog for works
+
+Configure the agent to start "bookid mcp" as a stdio MCP server.
+
+Usage:
+
+	bookid mcp
+`))
+}
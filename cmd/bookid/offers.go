@@ -0,0 +1,177 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/pricing"
+	"github.com/fwojciec/bookid/sqlite"
+)
+
+// OffersCommand represents a command for fetching and listing the prices and
+// availability of publications.
+type OffersCommand struct {
+	Config Config
+	Stdout io.Writer
+}
+
+// Run executes the command.
+func (c *OffersCommand) Run(ctx context.Context, args []string) error {
+	var cmd string
+	if len(args) > 0 {
+		cmd, args = args[0], args[1:]
+	}
+
+	switch cmd {
+	case "fetch":
+		return c.runFetch(ctx, args)
+	case "list":
+		return c.runList(ctx, args)
+	case "", "-h", "-help", "--help", "help":
+		c.usage()
+		return flag.ErrHelp
+	default:
+		return fmt.Errorf("bookid offers %s: unknown command", cmd)
+	}
+}
+
+// runFetch fetches the offers of the given publications, or of every
+// publication with an ISBN.
+func (c *OffersCommand) runFetch(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("bookid-offers-fetch", flag.ContinueOnError)
+	fs.Usage = c.usage
+	if err := parseFlags(ctx, fs, args); err != nil {
+		return err
+	}
+
+	ids, err := parseIDs(fs.Args())
+	if err != nil {
+		return err
+	}
+
+	db, err := openDB(c.Config)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	finders, err := c.newOfferFinders()
+	if err != nil {
+		return err
+	}
+	s := pricing.NewService(sqlite.NewPublicationService(db), sqlite.NewOfferService(db), finders)
+
+	// Without IDs, fetch the offers of the whole catalog.
+	if len(ids) == 0 {
+		fetched, skipped, err := s.FetchAll(ctx)
+		if err != nil {
+			return err
+		}
+		if skipped == nil {
+			skipped = []int64{}
+		}
+		return writeJSON(c.Stdout, struct {
+			Fetched int     `json:"fetched"`
+			Skipped []int64 `json:"skipped"`
+		}{fetched, skipped})
+	}
+
+	offers := []*bookid.Offer{}
+	for _, id := range ids {
+		found, err := s.FetchOffers(ctx, id)
+		if err != nil {
+			return fmt.Errorf("publication %d: %w", id, err)
+		}
+		offers = append(offers, found...)
+	}
+	return writeJSON(c.Stdout, offers)
+}
+
+// newOfferFinders returns the registered providers that report prices, by
+// name. Providers without credentials are skipped.
+func (c *OffersCommand) newOfferFinders() (map[string]bookid.OfferFinder, error) {
+	if err := requireOnline(c.Config); err != nil {
+		return nil, err
+	}
+	finders := make(map[string]bookid.OfferFinder)
+	for _, name := range bookid.Finders() {
+		profile := c.Config.Profiles[name]
+		profile.Logger = c.Config.logger()
+		finder, err := bookid.NewFinder(name, profile)
+		if bookid.ErrorCode(err) == bookid.EUNAUTHORIZED {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		if f, ok := finder.(bookid.OfferFinder); ok {
+			finders[name] = f
+		}
+	}
+	if len(finders) == 0 {
+		return nil, bookid.Errorf(bookid.EINVALID, "No configured providers report prices.")
+	}
+	return finders, nil
+}
+
+// runList prints the recorded offers of a publication, most recent first.
+func (c *OffersCommand) runList(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("bookid-offers-list", flag.ContinueOnError)
+	latest := fs.Bool("latest", false, "list only the most recent offer of each provider")
+	provider := fs.String("provider", "", "list only the offers of this provider")
+	limit := fs.Int("limit", 0, "list at most this many offers")
+	fs.Usage = c.usage
+	if err := parseFlags(ctx, fs, args); err != nil {
+		return err
+	} else if fs.NArg() != 1 {
+		return fmt.Errorf("usage: bookid offers list [-latest] [-provider name] [-limit n] <publication-id>")
+	}
+
+	ids, err := parseIDs(fs.Args())
+	if err != nil {
+		return err
+	}
+
+	db, err := openDB(c.Config)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	filter := bookid.OfferFilter{PublicationID: &ids[0], Latest: *latest, Limit: *limit}
+	if *provider != "" {
+		filter.Provider = provider
+	}
+	offers, _, err := sqlite.NewOfferService(db).FindOffers(ctx, filter)
+	if err != nil {
+		return err
+	}
+	return writeJSON(c.Stdout, offers)
+}
+
+// usage prints the help text for the command.
+func (c *OffersCommand) usage() {
+	fmt.Fprintln(os.Stderr, strings.TrimSpace(`
+Fetches and lists the prices and availability of publications, as reported by
+the providers that list them for sale: Google Books (Google Play) and, with an
+API key, ISBNdb's suggested retail price.
+
+Every fetch is recorded with its time, so running fetch regularly builds a
+price history of each publication.
+
+Usage:
+
+	bookid offers fetch [publication-id...]
+	bookid offers list [-latest] [-provider name] [-limit n] <publication-id>
+
+The commands are:
+
+	fetch  fetch the offers of the given publications, or of every publication
+	       with an ISBN
+	list   list the recorded offers of a publication, most recent first
+`))
+}
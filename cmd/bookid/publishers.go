@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/sqlite"
+)
+
+// PublishersCommand represents a command for listing publishers and merging
+// the variants of a publisher's name.
+type PublishersCommand struct {
+	Config Config
+	Stdout io.Writer
+}
+
+// Run executes the command.
+func (c *PublishersCommand) Run(ctx context.Context, args []string) error {
+	var cmd string
+	if len(args) > 0 {
+		cmd, args = args[0], args[1:]
+	}
+
+	switch cmd {
+	case "list":
+		return c.runList(ctx, args)
+	case "merge":
+		return c.runMerge(ctx, args)
+	case "", "-h", "-help", "--help", "help":
+		c.usage()
+		return flag.ErrHelp
+	default:
+		return fmt.Errorf("bookid publishers %s: unknown command", cmd)
+	}
+}
+
+// runList prints the publishers with the number of publications of each,
+// those with the most first.
+func (c *PublishersCommand) runList(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("bookid-publishers-list", flag.ContinueOnError)
+	limit := fs.Int("limit", 0, "list at most this many publishers")
+	fs.Usage = c.usage
+	if err := parseFlags(ctx, fs, args); err != nil {
+		return err
+	} else if fs.NArg() != 0 {
+		return fmt.Errorf("usage: bookid publishers list [-limit n]")
+	}
+
+	db, err := openDB(c.Config)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	publishers, _, err := sqlite.NewPublisherService(db).FindPublishers(ctx, bookid.PublisherFilter{Limit: *limit})
+	if err != nil {
+		return err
+	}
+	return writeJSON(c.Stdout, publishers)
+}
+
+// runMerge merges publishers into the first one given.
+func (c *PublishersCommand) runMerge(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("bookid-publishers-merge", flag.ContinueOnError)
+	fs.Usage = c.usage
+	if err := parseFlags(ctx, fs, args); err != nil {
+		return err
+	} else if fs.NArg() < 2 {
+		return fmt.Errorf("usage: bookid publishers merge <target-id> <source-id>...")
+	}
+
+	ids, err := parseIDs(fs.Args())
+	if err != nil {
+		return err
+	}
+
+	db, err := openDB(c.Config)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	s := sqlite.NewPublisherService(db)
+	if err := s.MergePublishers(ctx, ids[0], ids[1:]...); err != nil {
+		return err
+	}
+	publisher, err := s.FindPublisherByID(ctx, ids[0])
+	if err != nil {
+		return err
+	}
+	return writeJSON(c.Stdout, publisher)
+}
+
+// usage prints the help text for the command.
+func (c *PublishersCommand) usage() {
+	fmt.Fprintln(os.Stderr, strings.TrimSpace(`
+Lists the publishers of the catalog and merges the variants of a publisher's
+name that normalization does not catch.
+
+Publisher names from providers are normalized when publications are saved,
+so "Penguin Books Ltd" and "Penguin" are the same publisher. Merging moves
+the publications of the source publishers to the target, which gives them
+its name, and deletes the sources.
+
+Usage:
+
+	bookid publishers list [-limit n]
+	bookid publishers merge <target-id> <source-id>...
+
+The commands are:
+
+	list   list the publishers, those with the most publications first
+	merge  merge publishers into the target
+`))
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/sqlite"
+)
+
+// RebuildWorksCommand represents a command for re-clustering the
+// publications of the catalog into works.
+type RebuildWorksCommand struct {
+	Config Config
+	Stdout io.Writer
+}
+
+// Run executes the command.
+func (c *RebuildWorksCommand) Run(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("bookid-rebuild-works", flag.ContinueOnError)
+	apply := fs.Bool("apply", false, "rewrite the works instead of only listing the changes")
+	fs.Usage = func() { c.usage(fs) }
+	if err := parseFlags(ctx, fs, args); err != nil {
+		return err
+	} else if fs.NArg() != 0 {
+		return fmt.Errorf("usage: bookid rebuild-works [flags]")
+	}
+
+	db, err := openDB(c.Config)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	works, err := sqlite.NewCatalogService(db).RebuildWorks(ctx, *apply)
+	if err != nil {
+		return err
+	}
+	return writeJSON(c.Stdout, struct {
+		Works   []*bookid.WorkCluster `json:"works"`
+		Applied bool                  `json:"applied"`
+	}{works, *apply})
+}
+
+// usage prints the help text for the command.
+func (c *RebuildWorksCommand) usage(fs *flag.FlagSet) {
+	fmt.Fprintln(os.Stderr, strings.TrimSpace(`
+Clusters every publication into works afresh with the current matching
+rules, as if the catalog were saved anew, and lists the works that change.
+Run it after upgrading bookid when the matching rules have improved.
+
+Publications are matched by the title and authors of their raw Google Books
+data where they have it, and by those of their current work otherwise.
+Publications that no longer match their work are moved to the work they
+match or to a new one, works left without publications are merged into the
+work that took over their first publication, and the author links of every
+changed work are rewritten from its publications. Nothing is changed unless
+-apply is given, and then all changes are made in a single transaction.
+
+Usage:
+
+	bookid rebuild-works [flags]
+
+Flags:
+`))
+	fs.PrintDefaults()
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/googlebooks"
+	"github.com/fwojciec/bookid/refresh"
+	"github.com/fwojciec/bookid/sqlite"
+)
+
+// RefreshCommand represents a command for re-fetching cataloged publications
+// from their providers.
+type RefreshCommand struct {
+	Config Config
+	Stdout io.Writer
+}
+
+// Run executes the command.
+func (c *RefreshCommand) Run(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("bookid-refresh", flag.ContinueOnError)
+	olderThan := fs.String("older-than", "90d", "refresh publications not refreshed for this long, e.g. 90d or 12h")
+	fs.Usage = func() { c.usage(fs) }
+	if err := parseFlags(ctx, fs, args); err != nil {
+		return err
+	}
+
+	age, err := parseAge(*olderThan)
+	if err != nil {
+		return err
+	}
+
+	ids := make([]int64, 0, fs.NArg())
+	for _, arg := range fs.Args() {
+		id, err := strconv.ParseInt(arg, 10, 64)
+		if err != nil {
+			return bookid.Errorf(bookid.EINVALID, "Invalid publication ID %q.", arg)
+		}
+		ids = append(ids, id)
+	}
+
+	db, err := openDB(c.Config)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	s, err := c.newService(db)
+	if err != nil {
+		return err
+	}
+
+	// Without IDs, refresh the stale publications of the whole catalog.
+	if len(ids) == 0 {
+		refreshed, missing, err := s.RefreshStale(ctx, time.Now().Add(-age))
+		if err != nil {
+			return err
+		}
+		if refreshed == nil {
+			refreshed = []*bookid.PublicationRefresh{}
+		}
+		if missing == nil {
+			missing = []int64{}
+		}
+		return writeJSON(c.Stdout, struct {
+			Refreshed []*bookid.PublicationRefresh `json:"refreshed"`
+			Missing   []int64                      `json:"missing"`
+		}{refreshed, missing})
+	}
+
+	refreshes := make([]*bookid.PublicationRefresh, 0, len(ids))
+	for _, id := range ids {
+		r, err := s.RefreshPublication(ctx, id)
+		if err != nil {
+			return fmt.Errorf("publication %d: %w", id, err)
+		}
+		refreshes = append(refreshes, r)
+	}
+	return writeJSON(c.Stdout, refreshes)
+}
+
+// newService returns the refresh service of the command. Volumes are
+// re-fetched from Google Books and ISBNs searched with the configured
+// providers, bypassing the search cache and the catalog.
+func (c *RefreshCommand) newService(db *sqlite.DB) (*refresh.Service, error) {
+	cfg := c.Config
+	if err := requireOnline(cfg); err != nil {
+		return nil, err
+	}
+	cfg.CacheTTL, cfg.LocalFirst = 0, false
+	finder, err := newFinder(cfg, db)
+	if err != nil {
+		return nil, err
+	}
+
+	profile := cfg.Profiles[googlebooks.ProviderName]
+	profile.Logger = cfg.logger()
+	client, err := bookid.NewFinder(googlebooks.ProviderName, profile)
+	if err != nil {
+		return nil, err
+	}
+	getter, _ := client.(bookid.BookGetter)
+
+	s := refresh.NewService(sqlite.NewPublicationService(db), getter, finder)
+	s.Policies = cfg.Policies
+	s.ConflictService = sqlite.NewConflictService(db)
+	s.Logger = cfg.logger()
+	return s, nil
+}
+
+// parseAge parses a duration that may also be given in days, e.g. "90d".
+func parseAge(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n >= 0 {
+			return time.Duration(n) * 24 * time.Hour, nil
+		}
+	} else if d, err := time.ParseDuration(s); err == nil && d >= 0 {
+		return d, nil
+	}
+	return 0, bookid.Errorf(bookid.EINVALID, "Invalid age %q; use e.g. 90d or 12h.", s)
+}
+
+// usage prints the help text for the command.
+func (c *RefreshCommand) usage(fs *flag.FlagSet) {
+	fmt.Fprintln(os.Stderr, strings.TrimSpace(`
+Re-fetches the given publications, or every cataloged publication not
+refreshed for longer than -older-than, from their providers and updates the
+fields whose values changed. Publications are looked up by Google Books volume
+ID, or else searched by ISBN; values a provider no longer has are kept.
+Fields with a merge policy in the configuration file only change if the
+policy lets the new value win; values held back by a "review" policy are
+queued for "bookid conflicts".
+
+Prints the changes made to each publication and the conflicts queued, along
+with the IDs of stale publications no provider has anymore.
+
+Usage:
+
+	bookid refresh [-older-than 90d] [publication-id...]
+`))
+	fs.PrintDefaults()
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/sqlite"
+)
+
+// ReviewCommand represents a command for choosing among the candidates of
+// books that batch and import could not identify with enough confidence.
+type ReviewCommand struct {
+	Config Config
+	Stdin  io.Reader
+	Stdout io.Writer
+}
+
+// Run executes the command.
+func (c *ReviewCommand) Run(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("bookid-review", flag.ContinueOnError)
+	list := fs.Bool("list", false, "print the pending reviews as JSON instead of walking through them")
+	all := fs.Bool("all", false, "include closed reviews with -list")
+	fs.Usage = func() { c.usage(fs) }
+	if err := parseFlags(ctx, fs, args); err != nil {
+		return err
+	} else if fs.NArg() != 0 {
+		return fmt.Errorf("usage: bookid review [flags]")
+	}
+
+	db, err := openDB(c.Config)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	s := sqlite.NewReviewService(db)
+	var filter bookid.ReviewFilter
+	if pending := bookid.ReviewStatePending; !*list || !*all {
+		filter.State = &pending
+	}
+	reviews, _, err := s.FindReviews(ctx, filter)
+	if err != nil {
+		return err
+	} else if *list {
+		return writeJSON(c.Stdout, reviews)
+	}
+	return c.walk(ctx, s, reviews)
+}
+
+// walk asks the user to pick a candidate of each review in turn, until all
+// are done or the user quits.
+func (c *ReviewCommand) walk(ctx context.Context, s bookid.ReviewService, reviews []*bookid.Review) error {
+	var saved, dismissed, skipped int
+	defer func() {
+		fmt.Fprintf(c.Stdout, "%d saved, %d dismissed, %d skipped\n", saved, dismissed, skipped)
+	}()
+
+	in := bufio.NewScanner(c.Stdin)
+	for i, review := range reviews {
+		fmt.Fprintf(c.Stdout, "\n[%d/%d] %q (%s, line %d)\n", i+1, len(reviews), review.Query, review.Source, review.Line)
+		for j, candidate := range review.Candidates {
+			fmt.Fprintf(c.Stdout, "  %d) %s\n", j+1, candidateLine(candidate))
+		}
+
+		for {
+			fmt.Fprintf(c.Stdout, "Choose 1-%d, s to skip, d to dismiss, q to quit: ", len(review.Candidates))
+			if !in.Scan() {
+				fmt.Fprintln(c.Stdout)
+				return in.Err()
+			}
+
+			answer := strings.TrimSpace(in.Text())
+			switch answer {
+			case "s", "":
+				skipped++
+			case "d":
+				if _, err := s.DismissReview(ctx, review.ID); err != nil {
+					return err
+				}
+				dismissed++
+			case "q":
+				return nil
+			default:
+				choice, err := strconv.Atoi(answer)
+				if err != nil || choice < 1 || choice > len(review.Candidates) {
+					continue
+				}
+				done, err := s.SaveReview(ctx, review.ID, choice)
+				if err != nil {
+					return err
+				}
+				fmt.Fprintf(c.Stdout, "Saved as publication %d of work %d.\n", done.PublicationID, done.WorkID)
+				saved++
+			}
+			break
+		}
+	}
+	return nil
+}
+
+// candidateLine returns a one-line summary of a candidate, such as
+// "Dune by Frank Herbert (Ace, 1990) ISBN 9780441172719 [googlebooks 0.42]".
+func candidateLine(r bookid.BookResult) string {
+	var b strings.Builder
+	b.WriteString(r.Title)
+	if len(r.Authors) > 0 {
+		b.WriteString(" by " + strings.Join(r.Authors, ", "))
+	}
+
+	var imprint []string
+	if r.Publisher != "" {
+		imprint = append(imprint, r.Publisher)
+	}
+	if r.PublishedYear != 0 {
+		imprint = append(imprint, strconv.Itoa(r.PublishedYear))
+	}
+	if len(imprint) > 0 {
+		b.WriteString(" (" + strings.Join(imprint, ", ") + ")")
+	}
+
+	if isbn := cmp.Or(r.ISBN13, r.ISBN10); isbn != "" {
+		b.WriteString(" ISBN " + isbn)
+	}
+	fmt.Fprintf(&b, " [%s]", strings.TrimSpace(r.Provider+" "+strconv.FormatFloat(r.Confidence, 'f', 2, 64)))
+	return b.String()
+}
+
+// usage prints the help text for the command.
+func (c *ReviewCommand) usage(fs *flag.FlagSet) {
+	fmt.Fprintln(os.Stderr, strings.TrimSpace(`
+Walks through the books that "bookid batch -save" and "bookid import" could
+not identify with enough confidence to save, showing the search results
+kept as candidates for each. Enter the number of the right candidate to save
+it to the catalog, "d" to dismiss them all, "s" or nothing to leave the book
+for later, or "q" to stop.
+
+Usage:
+
+	bookid review [flags]
+
+Flags:
+`))
+	fs.PrintDefaults()
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/sqlite"
+)
+
+// SaveCommand represents a command for identifying a book and saving the top
+// result to the catalog.
+type SaveCommand struct {
+	Config Config
+	Stdout io.Writer
+
+	// Finder identifies the books. If nil, the providers of Config are used.
+	Finder bookid.BookFinder
+}
+
+// Run executes the command.
+func (c *SaveCommand) Run(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("bookid-save", flag.ContinueOnError)
+	personal := addPersonalFlags(fs)
+	lowConfidence := fs.Float64("low-confidence", defaultLowConfidence, "exit with status 3 if the best result's confidence is below this")
+	fs.Usage = func() { c.usage(fs) }
+	if err := parseFlags(ctx, fs, args); err != nil {
+		return err
+	} else if fs.NArg() == 0 {
+		return fmt.Errorf("usage: bookid save [flags] <query>")
+	}
+	query := strings.Join(fs.Args(), " ")
+
+	// Check the personal metadata before looking up the book.
+	upd, hasPersonal, err := personal.update()
+	if err != nil {
+		return err
+	}
+
+	db, err := openDB(c.Config)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	finder := c.Finder
+	if finder == nil {
+		if finder, err = newFinder(c.Config, db); err != nil {
+			return err
+		}
+	}
+
+	// Keep the raw provider data; it is stored with the publication.
+	results, err := finder.Search(ctx, query, bookid.SearchOptions{MaxResults: 1, IncludeRaw: true})
+	if err != nil {
+		return err
+	} else if len(results) == 0 {
+		return noResults(query)
+	}
+
+	workID, pubID, err := sqlite.NewCatalogService(db).SaveResult(ctx, results[0])
+	if err != nil {
+		return fmt.Errorf("saving result: %w", err)
+	}
+	if hasPersonal {
+		if _, err := sqlite.NewPublicationService(db).UpdatePublication(ctx, pubID, upd); err != nil {
+			return err
+		}
+	}
+
+	if err := writeSaved(ctx, c.Stdout, db, workID, pubID); err != nil {
+		return err
+	}
+	return checkConfidence(results, *lowConfidence)
+}
+
+// writeSaved writes a saved work and publication as JSON.
+func writeSaved(ctx context.Context, w io.Writer, db *sqlite.DB, workID, pubID int64) error {
+	work, err := sqlite.NewWorkService(db).FindWorkByID(ctx, workID)
+	if err != nil {
+		return err
+	}
+	pub, err := sqlite.NewPublicationService(db).FindPublicationByID(ctx, pubID)
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(w, struct {
+		Work        *bookid.Work        `json:"work"`
+		Publication *bookid.Publication `json:"publication"`
+	}{work, pub})
+}
+
+// usage prints the help text for the command.
+func (c *SaveCommand) usage(fs *flag.FlagSet) {
+	fmt.Fprintln(os.Stderr, strings.TrimSpace(`
+Identifies a book and saves the top result to the catalog as a work with its
+authors and publication. Saving an already cataloged publication refreshes it,
+and a new edition of a cataloged work is added to that work.
+
+The flags record personal metadata on the saved publication, as with
+bookid update.
+
+Usage:
+
+	bookid save [flags] <query>
+`))
+	fs.PrintDefaults()
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/mock"
+	"github.com/fwojciec/bookid/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newResultFinder returns a finder returning results for every query.
+func newResultFinder(results ...bookid.BookResult) *mock.BookFinder {
+	return &mock.BookFinder{SearchFn: func(context.Context, string, bookid.SearchOptions) ([]bookid.BookResult, error) {
+		return results, nil
+	}}
+}
+
+func TestSaveCommand(t *testing.T) {
+	t.Parallel()
+
+	t.Run("OK", func(t *testing.T) {
+		t.Parallel()
+		ctx := context.Background()
+		db, config := MustOpenDB(t)
+		MustCloseDB(t, db)
+
+		finder := newResultFinder(bookid.BookResult{
+			Title:      "The Great Gatsby",
+			Authors:    []string{"F. Scott Fitzgerald"},
+			ISBN13:     "9780743273565",
+			Publisher:  "Scribner",
+			Confidence: 0.95,
+		})
+		var buf bytes.Buffer
+		cmd := &SaveCommand{Config: config, Stdout: &buf, Finder: finder}
+		require.NoError(t, cmd.Run(ctx, []string{"-status", "read", "-rating", "5", "gatsby"}))
+
+		var got struct {
+			Work        bookid.Work        `json:"work"`
+			Publication bookid.Publication `json:"publication"`
+		}
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &got))
+		assert.Equal(t, "The Great Gatsby", got.Work.Title)
+		assert.Equal(t, "9780743273565", got.Publication.ISBN13)
+		assert.Equal(t, bookid.ReadingStatusRead, got.Publication.ReadingStatus)
+		assert.Equal(t, 5, got.Publication.Rating)
+
+		db = sqlite.NewDB(config.DBPath)
+		require.NoError(t, db.Open())
+		defer MustCloseDB(t, db)
+		pub, err := sqlite.NewPublicationService(db).FindPublicationByID(ctx, got.Publication.ID)
+		require.NoError(t, err)
+		assert.Equal(t, got.Work.ID, pub.WorkID)
+		assert.Equal(t, "Scribner", pub.Publisher)
+	})
+
+	t.Run("LowConfidence", func(t *testing.T) {
+		t.Parallel()
+		db, config := MustOpenDB(t)
+		MustCloseDB(t, db)
+
+		cmd := &SaveCommand{Config: config, Stdout: &bytes.Buffer{}, Finder: newResultFinder(bookid.BookResult{Title: "Gatsby", Confidence: 0.2})}
+		err := cmd.Run(context.Background(), []string{"gatsby"})
+		assert.Equal(t, exitLowConfidence, exitCode(err), "the result is saved but reported")
+	})
+
+	t.Run("NoResults", func(t *testing.T) {
+		t.Parallel()
+		db, config := MustOpenDB(t)
+		MustCloseDB(t, db)
+
+		cmd := &SaveCommand{Config: config, Stdout: &bytes.Buffer{}, Finder: newResultFinder()}
+		err := cmd.Run(context.Background(), []string{"nonsense"})
+		assert.Equal(t, exitNoResults, exitCode(err))
+	})
+
+	t.Run("InvalidStatus", func(t *testing.T) {
+		t.Parallel()
+		db, config := MustOpenDB(t)
+		MustCloseDB(t, db)
+
+		finder := &mock.BookFinder{SearchFn: func(context.Context, string, bookid.SearchOptions) ([]bookid.BookResult, error) {
+			panic("not searched")
+		}}
+		cmd := &SaveCommand{Config: config, Stdout: &bytes.Buffer{}, Finder: finder}
+		err := cmd.Run(context.Background(), []string{"-status", "skimmed", "gatsby"})
+		assert.Equal(t, bookid.EINVALID, bookid.ErrorCode(err))
+	})
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"image"
+	_ "image/gif" // Register image decoders for scanned photos
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/barcode"
+	_ "golang.org/x/image/webp"
+)
+
+// ScanCommand represents a command for identifying books from photos of
+// their barcodes.
+type ScanCommand struct {
+	Config Config
+	Stdout io.Writer
+}
+
+// scanLine is a single NDJSON record emitted by ScanCommand.
+type scanLine struct {
+	Image  string             `json:"image"`
+	ISBN   string             `json:"isbn,omitempty"`
+	Result *bookid.BookResult `json:"result"`
+	Error  string             `json:"error,omitempty"`
+}
+
+// Run executes the command.
+func (c *ScanCommand) Run(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("bookid-scan", flag.ContinueOnError)
+	fs.Usage = func() { c.usage(fs) }
+	if err := parseFlags(ctx, fs, args); err != nil {
+		return err
+	} else if fs.NArg() == 0 {
+		return fmt.Errorf("usage: bookid scan [flags] <image>...")
+	}
+
+	db, err := openDB(c.Config)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	finder, err := newFinder(c.Config, db)
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(c.Stdout)
+	enc.SetEscapeHTML(false)
+	for _, path := range fs.Args() {
+		line := &scanLine{Image: path}
+		if line.ISBN, err = scanISBN(path); err != nil {
+			line.Error = errorMessage(err)
+		} else {
+			// The ISBN is searched like any other query so that results
+			// come from the same providers, cache and ranking.
+			results, err := finder.Search(ctx, line.ISBN, bookid.SearchOptions{MaxResults: 1})
+			if err != nil {
+				line.Error = errorMessage(err)
+			} else if len(results) > 0 {
+				line.Result = &results[0]
+			}
+		}
+		if err := enc.Encode(line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// scanISBN reads the ISBN barcode from the image at path.
+func scanISBN(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	img, _, err := image.Decode(f)
+	if err != nil {
+		return "", bookid.Errorf(bookid.EINVALID, "Cannot decode image %s: %s.", path, err)
+	}
+	return barcode.DecodeISBN(img)
+}
+
+// usage prints the help text for the command.
+func (c *ScanCommand) usage(fs *flag.FlagSet) {
+	fmt.Fprintln(os.Stderr, strings.TrimSpace(`
+Reads the ISBN barcode from each photo (JPEG, PNG, GIF or WebP), identifies
+the book and prints one JSON record per image (NDJSON). Images without a
+readable ISBN barcode are reported in the record's "error" field.
+
+Usage:
+
+	bookid scan [flags] <image>...
+
+Flags:
+`))
+	fs.PrintDefaults()
+}
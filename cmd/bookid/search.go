@@ -0,0 +1,185 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/render"
+	"github.com/fwojciec/bookid/sqlite"
+	"github.com/fwojciec/bookid/subject"
+	"github.com/fwojciec/bookid/tui"
+	"golang.org/x/term"
+)
+
+// SearchCommand represents a command for identifying a book.
+type SearchCommand struct {
+	Config Config
+	Stdout io.Writer
+}
+
+// Run executes the command.
+func (c *SearchCommand) Run(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("bookid-search", flag.ContinueOnError)
+	format := fs.String("format", c.Config.Format, "output format: "+strings.Join(render.Formats(), ", "))
+	fields := fs.String("fields", "", "comma-separated result fields to emit: "+strings.Join(render.Fields(), ", "))
+	var opts bookid.SearchOptions
+	fs.IntVar(&opts.MaxResults, "limit", 0, "maximum number of results; 0 returns a page of the provider's default size")
+	fs.IntVar(&opts.StartIndex, "start", 0, "zero-based index of the first result, for paging")
+	fs.StringVar(&opts.Language, "lang", "", "restrict results to a language, e.g. en or pt-BR")
+	fs.StringVar(&opts.Country, "country", "", "search as from a country, e.g. PL, for its editions and prices; overrides the provider's configured country")
+	fs.Func("print-type", "restrict results to all, books or magazines", func(s string) error {
+		opts.PrintType = bookid.PrintType(s)
+		return nil
+	})
+	fs.Func("binding", "restrict results to hardcover, paperback, ebook or audiobook, keeping those of unknown binding", func(s string) error {
+		opts.Binding = bookid.Binding(s)
+		return nil
+	})
+	fs.BoolVar(&opts.FreeOnly, "free-only", false, "only books that can be read in full for free, such as public domain editions")
+	orderBy := func(s string) error {
+		opts.OrderBy = bookid.OrderBy(s)
+		return nil
+	}
+	fs.Func("order-by", "order results by relevance or newest", orderBy)
+	fs.Func("order", "shorthand for -order-by", orderBy)
+	subj := fs.String("subject", "", "only results the provider files under a subject, e.g. \"science fiction\"")
+	fs.Float64Var(&opts.MinConfidence, "min-confidence", 0, "drop results with a lower confidence (0.0 to 1.0)")
+	fs.BoolVar(&opts.IncludeRaw, "raw", false, "include raw provider data in JSON output")
+	lowConfidence := fs.Float64("low-confidence", defaultLowConfidence, "exit with status 3 if the best result's confidence is below this")
+	interactive := fs.Bool("interactive", false, "choose a result in a terminal UI and save it to the catalog")
+	fs.Usage = func() { c.usage(fs) }
+	if err := parseFlags(ctx, fs, args); err != nil {
+		return err
+	} else if fs.NArg() == 0 {
+		return fmt.Errorf("usage: bookid search [flags] <query>")
+	} else if err := opts.Validate(); err != nil {
+		return err
+	} else if *subj != "" && subject.Name(*subj) == "" {
+		return bookid.Errorf(bookid.EINVALID, "Invalid subject %q.", *subj)
+	}
+
+	renderer, err := render.New(*format, render.ParseFields(*fields))
+	if err != nil {
+		return err
+	}
+
+	// Combine all remaining arguments as the search query
+	query := strings.Join(fs.Args(), " ")
+
+	db, err := openDB(c.Config)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	finder, err := newFinder(c.Config, db)
+	if err != nil {
+		return err
+	}
+
+	if *interactive {
+		// Keep the raw provider data; it is stored with the publication.
+		opts.IncludeRaw = true
+	}
+	results, err := finder.Search(ctx, query, opts)
+	if err != nil {
+		return err
+	}
+	if *subj != "" {
+		results = slices.DeleteFunc(results, func(r bookid.BookResult) bool { return !subject.Has(r.Subjects, *subj) })
+	}
+	if *interactive {
+		return c.pick(ctx, db, query, results)
+	}
+	if err := renderer.Render(c.Stdout, query, results); err != nil {
+		return err
+	} else if len(results) == 0 {
+		return errNoResults
+	}
+	return checkConfidence(results, *lowConfidence)
+}
+
+// pick lets the user choose one of results in a terminal UI and saves it to
+// the catalog. The UI is drawn on stderr so that stdout only receives the
+// saved work and publication.
+func (c *SearchCommand) pick(ctx context.Context, db *sqlite.DB, query string, results []bookid.BookResult) error {
+	if len(results) == 0 {
+		return noResults(query)
+	}
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) || !term.IsTerminal(int(os.Stderr.Fd())) {
+		return bookid.Errorf(bookid.EINVALID, "Interactive mode requires a terminal.")
+	}
+
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return fmt.Errorf("entering raw mode: %w", err)
+	}
+	picker := tui.NewPicker(os.Stdin, os.Stderr)
+	if width, height, err := term.GetSize(int(os.Stderr.Fd())); err == nil {
+		picker.Width, picker.Height = width, height
+	}
+	i, err := picker.Pick(query, results)
+	if err := term.Restore(fd, state); err != nil {
+		return fmt.Errorf("leaving raw mode: %w", err)
+	}
+	if err != nil {
+		return err
+	}
+
+	workID, pubID, err := sqlite.NewCatalogService(db).SaveResult(ctx, results[i])
+	if err != nil {
+		return fmt.Errorf("saving result: %w", err)
+	}
+	return writeSaved(ctx, c.Stdout, db, workID, pubID)
+}
+
+// usage prints the help text for the command.
+func (c *SearchCommand) usage(fs *flag.FlagSet) {
+	fmt.Fprintln(os.Stderr, strings.TrimSpace(`
+Identifies a book and prints the matching results, best match first.
+
+The query is free text, an identifier such as an ISBN, LCCN, DOI or the ASIN
+of an Audible audiobook, a link to a book on Amazon, Google Books, Open
+Library or Goodreads, or fields given with operators: title:"The Hobbit"
+author:Tolkien publisher:"Allen & Unwin" year:1937 lang:en. Quote values that
+contain spaces.
+
+The -subject flag keeps the results whose provider categories include the
+subject, e.g. "science fiction" for Google Books' "Fiction / Science Fiction /
+General". Results of providers without categories are dropped.
+
+The -free-only flag keeps the books that can legally be read in full for
+free, such as public domain editions, as told by Google Books; results of
+other providers are dropped. The web_reader_url field links to where each can
+be read.
+
+The -limit flag sets how many results to fetch, e.g. -limit 40; Google Books
+returns 10 by default and pages through its results for more than 40. The
+-order flag, e.g. -order newest, orders them by publication date instead of
+relevance where the provider supports it.
+
+With -format ndjson, each result is printed as a compact JSON object on its
+own line, without the enclosing query, for piping into tools such as jq.
+Results are ranked across all providers before any is printed, so search
+cannot stream them as providers answer; use batch -stream to print each
+query's record as soon as its search is done.
+
+With -interactive, the results are listed in a terminal UI instead. Use the
+arrow keys to compare candidates and Enter to save the highlighted one to the
+catalog, or q to quit without saving.
+
+Usage:
+
+	bookid search [flags] <query>
+
+Flags:
+`))
+	fs.PrintDefaults()
+}
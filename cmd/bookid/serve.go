@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	nethttp "net/http"
+	"os"
+	"strings"
+
+	"github.com/fwojciec/bookid/batch"
+	"github.com/fwojciec/bookid/covers"
+	"github.com/fwojciec/bookid/graphql"
+	"github.com/fwojciec/bookid/grpc"
+	"github.com/fwojciec/bookid/http"
+	"github.com/fwojciec/bookid/metrics"
+	"github.com/fwojciec/bookid/sqlite"
+)
+
+// ServeCommand represents a command for running the HTTP API server.
+type ServeCommand struct {
+	Config Config
+	Stdout io.Writer
+}
+
+// Run executes the command. It blocks until ctx is canceled.
+func (c *ServeCommand) Run(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("bookid-serve", flag.ContinueOnError)
+	addr := fs.String("addr", ":8080", "bind address")
+	withMetrics := fs.Bool("metrics", false, "expose Prometheus metrics at /metrics")
+	withGraphQL := fs.Bool("graphql", false, "expose the GraphQL API at /graphql")
+	withSync := fs.Bool("sync", false, "let \"bookid sync\" read and write the catalog at /sync/changes")
+	grpcAddr := fs.String("grpc", "", "bind address of the gRPC server, e.g. :9090; disabled if empty")
+	fs.Usage = func() { c.usage(fs) }
+	if err := parseFlags(ctx, fs, args); err != nil {
+		return err
+	} else if fs.NArg() != 0 {
+		return fmt.Errorf("usage: bookid serve [flags]")
+	}
+
+	db, err := openDB(c.Config)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	var m *metrics.Metrics
+	if *withMetrics {
+		m = metrics.New()
+	}
+	finder, err := newInstrumentedFinder(c.Config, db, m)
+	if err != nil {
+		return err
+	}
+
+	server := http.NewServer()
+	server.Addr = *addr
+	server.BookFinder = finder
+	server.BatchFinder = batch.NewBatchFinder(finder)
+	server.WorkService = sqlite.NewWorkService(db)
+	server.WorkRelationService = sqlite.NewWorkRelationService(db)
+	server.PublicationService = sqlite.NewPublicationService(db)
+	server.CoverService = covers.NewService(
+		&nethttp.Client{Timeout: c.Config.Timeout},
+		server.PublicationService,
+		covers.NewStore(c.Config.CoverDir),
+	)
+	if m != nil {
+		server.MetricsHandler = m.Handler()
+	}
+	if *withSync {
+		server.SyncService = sqlite.NewSyncService(db)
+	}
+	if *withGraphQL {
+		h := graphql.NewHandler()
+		h.BookFinder = finder
+		h.WorkService = server.WorkService
+		h.AuthorService = sqlite.NewAuthorService(db)
+		h.PublicationService = server.PublicationService
+		server.GraphQLHandler = h
+	}
+
+	if err := server.Open(); err != nil {
+		return fmt.Errorf("starting server: %w", err)
+	}
+	fmt.Fprintf(c.Stdout, "listening on %s\n", server.URL())
+
+	if *grpcAddr != "" {
+		grpcServer := grpc.NewServer()
+		grpcServer.Addr = *grpcAddr
+		grpcServer.BookFinder = finder
+		grpcServer.CatalogService = sqlite.NewCatalogService(db)
+		grpcServer.WorkService = server.WorkService
+		grpcServer.PublicationService = server.PublicationService
+		if err := grpcServer.Open(); err != nil {
+			_ = server.Close()
+			return fmt.Errorf("starting grpc server: %w", err)
+		}
+		defer grpcServer.Close()
+		fmt.Fprintf(c.Stdout, "grpc listening on %s\n", *grpcAddr)
+	}
+
+	<-ctx.Done()
+	return server.Close()
+}
+
+// usage prints the help text for the command.
+func (c *ServeCommand) usage(fs *flag.FlagSet) {
+	fmt.Fprintln(os.Stderr, strings.TrimSpace(`
+Runs the HTTP API server, and optionally the gRPC server, until interrupted.
+The HTTP server exposes:
+
+	GET  /search?q=<query>
+	POST /search/batch
+	POST /works
+	GET  /works/{id}
+	GET  /publications/{id}
+	GET  /covers/{id}?size=small|medium|large&format=jpeg|webp
+	GET  /metrics (with -metrics)
+	POST /graphql (with -graphql)
+	GET  /sync/changes?since=<time> (with -sync)
+	POST /sync/changes (with -sync)
+
+With -grpc, the BookID gRPC service defined in grpc/bookidpb/bookid.proto is
+served as well.
+
+The sync routes let any client change the catalog; only enable -sync on a
+trusted network.
+
+Usage:
+
+	bookid serve [flags]
+
+Flags:
+`))
+	fs.PrintDefaults()
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/isbn"
+	"github.com/fwojciec/bookid/sqlite"
+)
+
+// ShowCommand represents a command for displaying a single work.
+type ShowCommand struct {
+	Config Config
+	Stdout io.Writer
+}
+
+// Run executes the command.
+func (c *ShowCommand) Run(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("bookid-show", flag.ContinueOnError)
+	fs.Usage = c.usage
+	if err := parseFlags(ctx, fs, args); err != nil {
+		return err
+	} else if fs.NArg() != 1 {
+		return fmt.Errorf("usage: bookid show <id>")
+	}
+
+	id, err := strconv.ParseInt(fs.Arg(0), 10, 64)
+	if err != nil {
+		return bookid.Errorf(bookid.EINVALID, "Invalid work ID %q.", fs.Arg(0))
+	}
+
+	db, err := openDB(c.Config)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	work, err := sqlite.NewWorkService(db).FindWorkByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	authors, _, err := sqlite.NewAuthorService(db).FindAuthors(ctx, bookid.AuthorFilter{WorkID: &id})
+	if err != nil {
+		return err
+	}
+	pubs, _, err := sqlite.NewPublicationService(db).FindPublications(ctx, bookid.PublicationFilter{WorkID: &id})
+	if err != nil {
+		return err
+	}
+
+	shown := make([]showPublication, len(pubs))
+	for i, pub := range pubs {
+		shown[i] = showPublication{Publication: pub}
+		if pub.ISBN13 != "" {
+			shown[i].ISBN13Hyphenated = isbn.Format(pub.ISBN13)
+		}
+		if pub.ISBN10 != "" {
+			shown[i].ISBN10Hyphenated = isbn.Format(pub.ISBN10)
+		}
+	}
+
+	return writeJSON(c.Stdout, struct {
+		Work         *bookid.Work      `json:"work"`
+		Authors      []*bookid.Author  `json:"authors"`
+		Publications []showPublication `json:"publications"`
+	}{work, authors, shown})
+}
+
+// showPublication is a publication with its ISBNs hyphenated for display.
+type showPublication struct {
+	*bookid.Publication
+	ISBN13Hyphenated string `json:"isbn13_hyphenated,omitempty"`
+	ISBN10Hyphenated string `json:"isbn10_hyphenated,omitempty"`
+}
+
+// usage prints the help text for the command.
+func (c *ShowCommand) usage() {
+	fmt.Fprintln(os.Stderr, strings.TrimSpace(`
+Shows a cataloged work with its authors and publications.
+
+Usage:
+
+	bookid show <id>
+`))
+}
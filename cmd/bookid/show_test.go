@@ -0,0 +1,56 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strconv"
+	"testing"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShowCommand(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	db, config := MustOpenDB(t)
+	work := &bookid.Work{Title: "The Great Gatsby", Author: "F. Scott Fitzgerald"}
+	require.NoError(t, sqlite.NewWorkService(db).CreateWork(ctx, work))
+	require.NoError(t, sqlite.NewPublicationService(db).CreatePublication(ctx, &bookid.Publication{WorkID: work.ID, ISBN13: "9780743273565", ISBN10: "0743273567"}))
+	MustCloseDB(t, db)
+
+	var buf bytes.Buffer
+	cmd := &ShowCommand{Config: config, Stdout: &buf}
+	require.NoError(t, cmd.Run(ctx, []string{strconv.FormatInt(work.ID, 10)}))
+
+	var got struct {
+		Work         bookid.Work `json:"work"`
+		Publications []struct {
+			ISBN13           string `json:"isbn13"`
+			ISBN13Hyphenated string `json:"isbn13_hyphenated"`
+			ISBN10Hyphenated string `json:"isbn10_hyphenated"`
+		} `json:"publications"`
+	}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &got))
+	assert.Equal(t, "The Great Gatsby", got.Work.Title)
+	require.Len(t, got.Publications, 1)
+	assert.Equal(t, "9780743273565", got.Publications[0].ISBN13)
+	assert.Equal(t, "978-0-7432-7356-5", got.Publications[0].ISBN13Hyphenated)
+	assert.Equal(t, "0-7432-7356-7", got.Publications[0].ISBN10Hyphenated)
+}
+
+func TestShowCommand_NotFound(t *testing.T) {
+	t.Parallel()
+	db, config := MustOpenDB(t)
+	MustCloseDB(t, db)
+
+	cmd := &ShowCommand{Config: config, Stdout: &bytes.Buffer{}}
+	err := cmd.Run(context.Background(), []string{"42"})
+	assert.Equal(t, bookid.ENOTFOUND, bookid.ErrorCode(err))
+
+	err = cmd.Run(context.Background(), []string{"gatsby"})
+	assert.Equal(t, bookid.EINVALID, bookid.ErrorCode(err))
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/catalogsync"
+	"github.com/fwojciec/bookid/http"
+	"github.com/fwojciec/bookid/sqlite"
+)
+
+// SyncCommand represents a command for syncing the catalog with another.
+type SyncCommand struct {
+	Config Config
+	Stdout io.Writer
+}
+
+// Run executes the command.
+func (c *SyncCommand) Run(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("bookid-sync", flag.ContinueOnError)
+	prefer := fs.String("prefer", string(catalogsync.PreferNewer), "which row wins conflicts: newer, local or remote")
+	full := fs.Bool("full", false, "compare every row rather than those changed since the last sync")
+	fs.Usage = func() { c.usage(fs) }
+	if err := parseFlags(ctx, fs, args); err != nil {
+		return err
+	} else if fs.NArg() != 1 {
+		return fmt.Errorf("usage: bookid sync [flags] <remote-db-or-url>")
+	} else if !catalogsync.Prefer(*prefer).Valid() {
+		return bookid.Errorf(bookid.EINVALID, "Invalid -prefer %q, want newer, local or remote.", *prefer)
+	}
+
+	db, err := openDB(c.Config)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	local := sqlite.NewSyncService(db)
+
+	// The peer is remembered by URL or absolute path to find the time of the
+	// last sync with it.
+	peer := fs.Arg(0)
+	var remote bookid.SyncService
+	if strings.HasPrefix(peer, "http://") || strings.HasPrefix(peer, "https://") {
+		peer = strings.TrimSuffix(peer, "/")
+		remote = http.NewSyncClient(peer, c.Config.Timeout)
+	} else {
+		if peer, err = filepath.Abs(peer); err != nil {
+			return err
+		} else if _, err := os.Stat(peer); err != nil {
+			return err
+		}
+		remoteDB := sqlite.NewDB(peer)
+		remoteDB.Logger = c.Config.logger()
+		if err := remoteDB.Open(); err != nil {
+			return fmt.Errorf("opening remote catalog database %q: %w", peer, err)
+		}
+		defer remoteDB.Close()
+		remote = sqlite.NewSyncService(remoteDB)
+	}
+
+	var since time.Time
+	if !*full {
+		if since, err = local.FindLastSync(ctx, peer); err != nil {
+			return err
+		}
+	}
+
+	syncer := catalogsync.NewSyncer(local, remote)
+	syncer.Prefer = catalogsync.Prefer(*prefer)
+	result, err := syncer.Sync(ctx, since)
+	if err != nil {
+		return err
+	} else if err := local.SetLastSync(ctx, peer, result.SyncedAt); err != nil {
+		return err
+	}
+	return writeJSON(c.Stdout, result)
+}
+
+// usage prints the help text for the command.
+func (c *SyncCommand) usage(fs *flag.FlagSet) {
+	fmt.Fprintln(os.Stderr, strings.TrimSpace(`
+Syncs the works, authors and publications of the catalog both ways with
+another catalog: a database file, or a server run with "bookid serve -sync"
+given by its URL. Rows changed in only one catalog since the last sync with
+the other are copied to it, and works and publications permanently removed
+from one are removed from the other. Rows are matched by UID, and by ISBN-13,
+Google Books volume ID or ASIN the first time, so the same book saved in
+both catalogs is not duplicated. Prints the changes made to each catalog and
+the conflicts resolved.
+
+A row changed in both catalogs since the last sync is a conflict. By default
+the row changed last wins, which relies on the clocks of the two machines
+roughly agreeing; -prefer local or -prefer remote makes one catalog win every
+conflict instead.
+
+Subjects, series, collections, copies, loans and covers are not synced.
+
+Usage:
+
+	bookid sync [flags] <remote-db-or-url>
+
+Flags:
+`))
+	fs.PrintDefaults()
+}
@@ -0,0 +1,95 @@
+# bash completion for bookid. Generated by "bookid completion bash";
+# load it with: source <(bookid completion bash)
+
+_bookid() {
+	local cur prev word candidate cmdpath="" subs="" opts="" valopts=""
+	cur="${COMP_WORDS[COMP_CWORD]}"
+	prev="${COMP_WORDS[COMP_CWORD-1]}"
+	for word in "${COMP_WORDS[@]:1:COMP_CWORD-1}"; do
+		[[ $word == -* ]] && continue
+		candidate="${cmdpath:+$cmdpath }$word"
+		case "$candidate" in
+		"search"|"save"|"update"|"cite"|"compare"|"batch"|"scan"|"list"|"show"|"collection"|"collection create"|"collection add"|"collection remove"|"collection list"|"collection delete"|"loan"|"loan checkout"|"loan return"|"loan list"|"loan overdue"|"history"|"export"|"import"|"dedup"|"rebuild-works"|"trash"|"trash list"|"trash restore"|"trash purge"|"backup"|"restore"|"sync"|"covers"|"classify"|"refresh"|"conflicts"|"conflicts list"|"conflicts accept"|"conflicts reject"|"translations"|"translations list"|"translations suggest"|"translations link"|"publishers"|"publishers list"|"publishers merge"|"offers"|"offers fetch"|"offers list"|"review"|"link"|"jobs"|"jobs add"|"jobs add import"|"jobs add refresh"|"jobs add covers"|"jobs run"|"jobs list"|"jobs status"|"jobs cancel"|"serve"|"mcp"|"completion"|"man") cmdpath="$candidate" ;;
+		esac
+	done
+
+	case "$cmdpath" in
+	"") subs="search save update cite compare batch scan list show collection loan history export import dedup rebuild-works trash backup restore sync covers classify refresh conflicts translations publishers offers review link jobs serve mcp completion man" opts="-errors -offline -v -verbose" valopts="-errors" ;;
+	"search") subs="" opts="-binding -country -fields -format -free-only -interactive -lang -limit -low-confidence -min-confidence -order -order-by -print-type -raw -start -subject" valopts="-binding -country -fields -format -lang -limit -low-confidence -min-confidence -order -order-by -print-type -start -subject" ;;
+	"save") subs="" opts="-acquired -location -low-confidence -notes -rating -status" valopts="-acquired -location -low-confidence -notes -rating -status" ;;
+	"update") subs="" opts="-acquired -location -notes -rating -status" valopts="-acquired -location -notes -rating -status" ;;
+	"cite") subs="" opts="-key -low-confidence -style" valopts="-key -low-confidence -style" ;;
+	"compare") subs="" opts="-format -lang" valopts="-format -lang" ;;
+	"batch") subs="" opts="-min-confidence -progress -save -stream -workers" valopts="-min-confidence -workers" ;;
+	"scan") subs="" opts="" valopts="" ;;
+	"list") subs="" opts="-binding -lang -limit -offset -query -search -series -subject" valopts="-binding -lang -limit -offset -query -search -series -subject" ;;
+	"show") subs="" opts="" valopts="" ;;
+	"collection") subs="create add remove list delete" opts="" valopts="" ;;
+	"collection create") subs="" opts="-description" valopts="-description" ;;
+	"collection add") subs="" opts="" valopts="" ;;
+	"collection remove") subs="" opts="" valopts="" ;;
+	"collection list") subs="" opts="" valopts="" ;;
+	"collection delete") subs="" opts="" valopts="" ;;
+	"loan") subs="checkout return list overdue" opts="" valopts="" ;;
+	"loan checkout") subs="" opts="-due" valopts="-due" ;;
+	"loan return") subs="" opts="" valopts="" ;;
+	"loan list") subs="" opts="-all -borrower" valopts="-borrower" ;;
+	"loan overdue") subs="" opts="-all -borrower" valopts="-borrower" ;;
+	"history") subs="" opts="" valopts="" ;;
+	"export") subs="" opts="-columns -format -query" valopts="-columns -format -query" ;;
+	"import") subs="" opts="-format -min-confidence" valopts="-format -min-confidence" ;;
+	"dedup") subs="" opts="-apply" valopts="" ;;
+	"rebuild-works") subs="" opts="-apply" valopts="" ;;
+	"trash") subs="list restore purge" opts="" valopts="" ;;
+	"trash list") subs="" opts="" valopts="" ;;
+	"trash restore") subs="" opts="-publication" valopts="" ;;
+	"trash purge") subs="" opts="-all -publication" valopts="" ;;
+	"backup") subs="" opts="-gzip" valopts="" ;;
+	"restore") subs="" opts="" valopts="" ;;
+	"sync") subs="" opts="-full -prefer" valopts="-prefer" ;;
+	"covers") subs="" opts="" valopts="" ;;
+	"classify") subs="" opts="" valopts="" ;;
+	"refresh") subs="" opts="-older-than" valopts="-older-than" ;;
+	"conflicts") subs="list accept reject" opts="" valopts="" ;;
+	"conflicts list") subs="" opts="-all -publication" valopts="-publication" ;;
+	"conflicts accept") subs="" opts="" valopts="" ;;
+	"conflicts reject") subs="" opts="" valopts="" ;;
+	"translations") subs="list suggest link" opts="" valopts="" ;;
+	"translations list") subs="" opts="-work" valopts="-work" ;;
+	"translations suggest") subs="" opts="" valopts="" ;;
+	"translations link") subs="" opts="-translator" valopts="-translator" ;;
+	"publishers") subs="list merge" opts="" valopts="" ;;
+	"publishers list") subs="" opts="-limit" valopts="-limit" ;;
+	"publishers merge") subs="" opts="" valopts="" ;;
+	"offers") subs="fetch list" opts="" valopts="" ;;
+	"offers fetch") subs="" opts="" valopts="" ;;
+	"offers list") subs="" opts="-latest -limit -provider" valopts="-limit -provider" ;;
+	"review") subs="" opts="-all -list" valopts="" ;;
+	"link") subs="" opts="-viaf -wikidata" valopts="-viaf -wikidata" ;;
+	"jobs") subs="add run list status cancel" opts="" valopts="" ;;
+	"jobs add") subs="import refresh covers" opts="" valopts="" ;;
+	"jobs add import") subs="" opts="-format -min-confidence" valopts="-format -min-confidence" ;;
+	"jobs add refresh") subs="" opts="-older-than" valopts="-older-than" ;;
+	"jobs add covers") subs="" opts="" valopts="" ;;
+	"jobs run") subs="" opts="" valopts="" ;;
+	"jobs list") subs="" opts="-limit -state" valopts="-limit -state" ;;
+	"jobs status") subs="" opts="" valopts="" ;;
+	"jobs cancel") subs="" opts="" valopts="" ;;
+	"serve") subs="" opts="-addr -graphql -grpc -metrics -sync" valopts="-addr -grpc" ;;
+	"mcp") subs="" opts="" valopts="" ;;
+	"completion") subs="" opts="" valopts="" ;;
+	"man") subs="" opts="" valopts="" ;;
+	esac
+
+	if [[ " $valopts " == *" $prev "* ]]; then
+		COMPREPLY=($(compgen -f -- "$cur"))
+	elif [[ $cur == -* ]]; then
+		COMPREPLY=($(compgen -W "$opts" -- "$cur"))
+	elif [[ -n $subs ]]; then
+		COMPREPLY=($(compgen -W "$subs" -- "$cur"))
+	else
+		COMPREPLY=($(compgen -f -- "$cur"))
+	fi
+}
+
+complete -F _bookid bookid
@@ -0,0 +1,175 @@
+# fish completion for bookid. Generated by "bookid completion fish";
+# load it with: bookid completion fish | source
+
+# __bookid_path prints the command being completed, e.g. "bookid jobs add".
+function __bookid_path
+	set -l cmdpath
+	for word in (commandline -opc)[2..-1]
+		string match -q -- '-*' $word; and continue
+		set -l candidate (string join ' ' $cmdpath $word)
+		if contains -- $candidate 'search' 'save' 'update' 'cite' 'compare' 'batch' 'scan' 'list' 'show' 'collection' 'collection create' 'collection add' 'collection remove' 'collection list' 'collection delete' 'loan' 'loan checkout' 'loan return' 'loan list' 'loan overdue' 'history' 'export' 'import' 'dedup' 'rebuild-works' 'trash' 'trash list' 'trash restore' 'trash purge' 'backup' 'restore' 'sync' 'covers' 'classify' 'refresh' 'conflicts' 'conflicts list' 'conflicts accept' 'conflicts reject' 'translations' 'translations list' 'translations suggest' 'translations link' 'publishers' 'publishers list' 'publishers merge' 'offers' 'offers fetch' 'offers list' 'review' 'link' 'jobs' 'jobs add' 'jobs add import' 'jobs add refresh' 'jobs add covers' 'jobs run' 'jobs list' 'jobs status' 'jobs cancel' 'serve' 'mcp' 'completion' 'man'
+			set cmdpath $candidate
+		end
+	end
+	string join ' ' bookid $cmdpath
+end
+
+function __bookid_at
+	test (__bookid_path) = $argv[1]
+end
+
+complete -c bookid -f -n '__bookid_at \'bookid\'' -a 'search' -d 'identify a book and print the top result'
+complete -c bookid -f -n '__bookid_at \'bookid\'' -a 'save' -d 'identify a book and save the top result to the catalog'
+complete -c bookid -f -n '__bookid_at \'bookid\'' -a 'update' -d 'record reading status, rating and notes on a publication'
+complete -c bookid -f -n '__bookid_at \'bookid\'' -a 'cite' -d 'identify a book and print a citation (BibTeX, RIS, CSL-JSON)'
+complete -c bookid -f -n '__bookid_at \'bookid\'' -a 'compare' -d 'compare the top results of every provider field by field'
+complete -c bookid -f -n '__bookid_at \'bookid\'' -a 'batch' -d 'identify one book per line of a file or stdin'
+complete -c bookid -f -n '__bookid_at \'bookid\'' -a 'scan' -d 'identify books from photos of their ISBN barcodes'
+complete -c bookid -f -n '__bookid_at \'bookid\'' -a 'list' -d 'list works in the catalog'
+complete -c bookid -f -n '__bookid_at \'bookid\'' -a 'show' -d 'show a work with its authors and publications'
+complete -c bookid -f -n '__bookid_at \'bookid\'' -a 'collection' -d 'organize works into collections such as "to-read"'
+complete -c bookid -f -n '__bookid_at \'bookid\'' -a 'loan' -d 'check copies out to borrowers and list overdue loans'
+complete -c bookid -f -n '__bookid_at \'bookid\'' -a 'history' -d 'show the changes made to a work and its publications'
+complete -c bookid -f -n '__bookid_at \'bookid\'' -a 'export' -d 'export the catalog for library systems and publishers'
+complete -c bookid -f -n '__bookid_at \'bookid\'' -a 'import' -d 'add records from other systems to the catalog'
+complete -c bookid -f -n '__bookid_at \'bookid\'' -a 'dedup' -d 'find and merge duplicate works in the catalog'
+complete -c bookid -f -n '__bookid_at \'bookid\'' -a 'rebuild-works' -d 're-cluster publications into works with the current matching rules'
+complete -c bookid -f -n '__bookid_at \'bookid\'' -a 'trash' -d 'list, restore and purge deleted works and publications'
+complete -c bookid -f -n '__bookid_at \'bookid\'' -a 'backup' -d 'copy the catalog database to a file, optionally gzipped'
+complete -c bookid -f -n '__bookid_at \'bookid\'' -a 'restore' -d 'replace the catalog database with a backup'
+complete -c bookid -f -n '__bookid_at \'bookid\'' -a 'sync' -d 'sync the catalog both ways with another database or server'
+complete -c bookid -f -n '__bookid_at \'bookid\'' -a 'covers' -d 'download and store cover images of publications'
+complete -c bookid -f -n '__bookid_at \'bookid\'' -a 'classify' -d 'look up Dewey and LC classification numbers of publications'
+complete -c bookid -f -n '__bookid_at \'bookid\'' -a 'refresh' -d 're-fetch stale publications from their providers'
+complete -c bookid -f -n '__bookid_at \'bookid\'' -a 'conflicts' -d 'review field values that providers disagree on'
+complete -c bookid -f -n '__bookid_at \'bookid\'' -a 'translations' -d 'link translated works to their originals'
+complete -c bookid -f -n '__bookid_at \'bookid\'' -a 'publishers' -d 'list publishers and merge variants of their names'
+complete -c bookid -f -n '__bookid_at \'bookid\'' -a 'offers' -d 'fetch and list the prices and availability of publications'
+complete -c bookid -f -n '__bookid_at \'bookid\'' -a 'review' -d 'pick the right candidate of books identified with low confidence'
+complete -c bookid -f -n '__bookid_at \'bookid\'' -a 'link' -d 'link an author to their VIAF and Wikidata records'
+complete -c bookid -f -n '__bookid_at \'bookid\'' -a 'jobs' -d 'queue long-running imports, refreshes and cover backfills'
+complete -c bookid -f -n '__bookid_at \'bookid\'' -a 'serve' -d 'run the HTTP API server and, optionally, the gRPC server'
+complete -c bookid -f -n '__bookid_at \'bookid\'' -a 'mcp' -d 'serve bookid tools to LLM agents over the Model Context Protocol'
+complete -c bookid -f -n '__bookid_at \'bookid\'' -a 'completion' -d 'print a shell completion script for bash, zsh or fish'
+complete -c bookid -f -n '__bookid_at \'bookid\'' -a 'man' -d 'print the manual page'
+complete -c bookid -n '__bookid_at \'bookid\'' -o 'errors' -r -d 'format of errors written to stderr: text or json'
+complete -c bookid -n '__bookid_at \'bookid\'' -o 'offline' -d 'resolve queries from the catalog and cached searches without network calls'
+complete -c bookid -n '__bookid_at \'bookid\'' -o 'v' -d 'shorthand for -verbose'
+complete -c bookid -n '__bookid_at \'bookid\'' -o 'verbose' -d 'log queries, provider latencies, cache hits and database operations to stderr'
+complete -c bookid -n '__bookid_at \'bookid search\'' -o 'binding' -r -d 'restrict results to hardcover, paperback, ebook or audiobook, keeping those of unknown binding'
+complete -c bookid -n '__bookid_at \'bookid search\'' -o 'country' -r -d 'search as from a country, e.g. PL, for its editions and prices; overrides the provider\'s configured country'
+complete -c bookid -n '__bookid_at \'bookid search\'' -o 'fields' -r -d 'comma-separated result fields to emit: title, authors, isbn10, isbn13, publisher, published_year, language, binding, page_count, duration_minutes, google_books_volume_id, oclc_number, lccn, doi, asin, thumbnail_url, web_reader_url, dewey, lcc, provider, confidence, search_type'
+complete -c bookid -n '__bookid_at \'bookid search\'' -o 'format' -r -d 'output format: json, ndjson, table, yaml, csv'
+complete -c bookid -n '__bookid_at \'bookid search\'' -o 'free-only' -d 'only books that can be read in full for free, such as public domain editions'
+complete -c bookid -n '__bookid_at \'bookid search\'' -o 'interactive' -d 'choose a result in a terminal UI and save it to the catalog'
+complete -c bookid -n '__bookid_at \'bookid search\'' -o 'lang' -r -d 'restrict results to a language, e.g. en or pt-BR'
+complete -c bookid -n '__bookid_at \'bookid search\'' -o 'limit' -r -d 'maximum number of results; 0 returns a page of the provider\'s default size'
+complete -c bookid -n '__bookid_at \'bookid search\'' -o 'low-confidence' -r -d 'exit with status 3 if the best result\'s confidence is below this'
+complete -c bookid -n '__bookid_at \'bookid search\'' -o 'min-confidence' -r -d 'drop results with a lower confidence (0.0 to 1.0)'
+complete -c bookid -n '__bookid_at \'bookid search\'' -o 'order' -r -d 'shorthand for -order-by'
+complete -c bookid -n '__bookid_at \'bookid search\'' -o 'order-by' -r -d 'order results by relevance or newest'
+complete -c bookid -n '__bookid_at \'bookid search\'' -o 'print-type' -r -d 'restrict results to all, books or magazines'
+complete -c bookid -n '__bookid_at \'bookid search\'' -o 'raw' -d 'include raw provider data in JSON output'
+complete -c bookid -n '__bookid_at \'bookid search\'' -o 'start' -r -d 'zero-based index of the first result, for paging'
+complete -c bookid -n '__bookid_at \'bookid search\'' -o 'subject' -r -d 'only results the provider files under a subject, e.g. "science fiction"'
+complete -c bookid -n '__bookid_at \'bookid save\'' -o 'acquired' -r -d 'date the copy was acquired, as YYYY-MM-DD'
+complete -c bookid -n '__bookid_at \'bookid save\'' -o 'location' -r -d 'where the copy is kept, e.g. a shelf code'
+complete -c bookid -n '__bookid_at \'bookid save\'' -o 'low-confidence' -r -d 'exit with status 3 if the best result\'s confidence is below this'
+complete -c bookid -n '__bookid_at \'bookid save\'' -o 'notes' -r -d 'personal notes'
+complete -c bookid -n '__bookid_at \'bookid save\'' -o 'rating' -r -d 'rating from 1 to 5; 0 clears it'
+complete -c bookid -n '__bookid_at \'bookid save\'' -o 'status' -r -d 'reading status: want_to_read, reading, read or abandoned'
+complete -c bookid -n '__bookid_at \'bookid update\'' -o 'acquired' -r -d 'date the copy was acquired, as YYYY-MM-DD'
+complete -c bookid -n '__bookid_at \'bookid update\'' -o 'location' -r -d 'where the copy is kept, e.g. a shelf code'
+complete -c bookid -n '__bookid_at \'bookid update\'' -o 'notes' -r -d 'personal notes'
+complete -c bookid -n '__bookid_at \'bookid update\'' -o 'rating' -r -d 'rating from 1 to 5; 0 clears it'
+complete -c bookid -n '__bookid_at \'bookid update\'' -o 'status' -r -d 'reading status: want_to_read, reading, read or abandoned'
+complete -c bookid -n '__bookid_at \'bookid cite\'' -o 'key' -r -d 'citation key; generated from the author and year if empty'
+complete -c bookid -n '__bookid_at \'bookid cite\'' -o 'low-confidence' -r -d 'exit with status 3 if the best result\'s confidence is below this'
+complete -c bookid -n '__bookid_at \'bookid cite\'' -o 'style' -r -d 'citation style: bibtex, ris, csl-json'
+complete -c bookid -n '__bookid_at \'bookid compare\'' -o 'format' -r -d 'output format: table, json'
+complete -c bookid -n '__bookid_at \'bookid compare\'' -o 'lang' -r -d 'restrict results to a language, e.g. en or pt-BR'
+complete -c bookid -n '__bookid_at \'bookid batch\'' -o 'min-confidence' -r -d 'minimum confidence of results saved with -save'
+complete -c bookid -n '__bookid_at \'bookid batch\'' -o 'progress' -d 'report progress on stderr'
+complete -c bookid -n '__bookid_at \'bookid batch\'' -o 'save' -d 'save confident results to the catalog and queue the others for review'
+complete -c bookid -n '__bookid_at \'bookid batch\'' -o 'stream' -d 'emit each record as soon as its search is done rather than in input order'
+complete -c bookid -n '__bookid_at \'bookid batch\'' -o 'workers' -r -d 'number of concurrent searches'
+complete -c bookid -n '__bookid_at \'bookid list\'' -o 'binding' -r -d 'only works with a publication in a binding: hardcover, paperback, ebook or audiobook'
+complete -c bookid -n '__bookid_at \'bookid list\'' -o 'lang' -r -d 'only works with a publication in a language, e.g. en or pt-BR'
+complete -c bookid -n '__bookid_at \'bookid list\'' -o 'limit' -r -d 'maximum number of works to list'
+complete -c bookid -n '__bookid_at \'bookid list\'' -o 'offset' -r -d 'number of works to skip'
+complete -c bookid -n '__bookid_at \'bookid list\'' -o 'query' -r -d 'only works whose title or author contains text'
+complete -c bookid -n '__bookid_at \'bookid list\'' -o 'search' -r -d 'full-text search of titles, authors, publishers and identifiers'
+complete -c bookid -n '__bookid_at \'bookid list\'' -o 'series' -r -d 'only works in a series, in volume order'
+complete -c bookid -n '__bookid_at \'bookid list\'' -o 'subject' -r -d 'only works tagged with a subject, e.g. "science fiction"'
+complete -c bookid -f -n '__bookid_at \'bookid collection\'' -a 'create'
+complete -c bookid -f -n '__bookid_at \'bookid collection\'' -a 'add'
+complete -c bookid -f -n '__bookid_at \'bookid collection\'' -a 'remove'
+complete -c bookid -f -n '__bookid_at \'bookid collection\'' -a 'list'
+complete -c bookid -f -n '__bookid_at \'bookid collection\'' -a 'delete'
+complete -c bookid -n '__bookid_at \'bookid collection create\'' -o 'description' -r -d 'what the collection is for'
+complete -c bookid -f -n '__bookid_at \'bookid loan\'' -a 'checkout'
+complete -c bookid -f -n '__bookid_at \'bookid loan\'' -a 'return'
+complete -c bookid -f -n '__bookid_at \'bookid loan\'' -a 'list'
+complete -c bookid -f -n '__bookid_at \'bookid loan\'' -a 'overdue'
+complete -c bookid -n '__bookid_at \'bookid loan checkout\'' -o 'due' -r -d 'due date as YYYY-MM-DD, or a loan period such as 14d; empty for none'
+complete -c bookid -n '__bookid_at \'bookid loan list\'' -o 'all' -d 'include returned loans'
+complete -c bookid -n '__bookid_at \'bookid loan list\'' -o 'borrower' -r -d 'only loans to this borrower'
+complete -c bookid -n '__bookid_at \'bookid loan overdue\'' -o 'all' -d 'include returned loans'
+complete -c bookid -n '__bookid_at \'bookid loan overdue\'' -o 'borrower' -r -d 'only loans to this borrower'
+complete -c bookid -n '__bookid_at \'bookid export\'' -o 'columns' -r -d 'comma-separated columns (csv and xlsx)'
+complete -c bookid -n '__bookid_at \'bookid export\'' -o 'format' -r -d 'output format: marc, marcxml, onix, csv, xlsx, ndjson or goodreads-csv'
+complete -c bookid -n '__bookid_at \'bookid export\'' -o 'query' -r -d 'only works whose title or author contains text'
+complete -c bookid -n '__bookid_at \'bookid import\'' -o 'format' -r -d 'input format: onix, goodreads'
+complete -c bookid -n '__bookid_at \'bookid import\'' -o 'min-confidence' -r -d 'minimum confidence of title matches (goodreads)'
+complete -c bookid -n '__bookid_at \'bookid dedup\'' -o 'apply' -d 'merge the duplicates instead of only listing them'
+complete -c bookid -n '__bookid_at \'bookid rebuild-works\'' -o 'apply' -d 'rewrite the works instead of only listing the changes'
+complete -c bookid -f -n '__bookid_at \'bookid trash\'' -a 'list'
+complete -c bookid -f -n '__bookid_at \'bookid trash\'' -a 'restore'
+complete -c bookid -f -n '__bookid_at \'bookid trash\'' -a 'purge'
+complete -c bookid -n '__bookid_at \'bookid trash restore\'' -o 'publication' -d 'restore publications instead of works'
+complete -c bookid -n '__bookid_at \'bookid trash purge\'' -o 'all' -d 'empty the trash'
+complete -c bookid -n '__bookid_at \'bookid trash purge\'' -o 'publication' -d 'purge publications instead of works'
+complete -c bookid -n '__bookid_at \'bookid backup\'' -o 'gzip' -d 'compress the backup with gzip (default if the path ends in .gz)'
+complete -c bookid -n '__bookid_at \'bookid sync\'' -o 'full' -d 'compare every row rather than those changed since the last sync'
+complete -c bookid -n '__bookid_at \'bookid sync\'' -o 'prefer' -r -d 'which row wins conflicts: newer, local or remote'
+complete -c bookid -n '__bookid_at \'bookid refresh\'' -o 'older-than' -r -d 'refresh publications not refreshed for this long, e.g. 90d or 12h'
+complete -c bookid -f -n '__bookid_at \'bookid conflicts\'' -a 'list'
+complete -c bookid -f -n '__bookid_at \'bookid conflicts\'' -a 'accept'
+complete -c bookid -f -n '__bookid_at \'bookid conflicts\'' -a 'reject'
+complete -c bookid -n '__bookid_at \'bookid conflicts list\'' -o 'all' -d 'include resolved conflicts'
+complete -c bookid -n '__bookid_at \'bookid conflicts list\'' -o 'publication' -r -d 'only list conflicts of this publication'
+complete -c bookid -f -n '__bookid_at \'bookid translations\'' -a 'list'
+complete -c bookid -f -n '__bookid_at \'bookid translations\'' -a 'suggest'
+complete -c bookid -f -n '__bookid_at \'bookid translations\'' -a 'link'
+complete -c bookid -n '__bookid_at \'bookid translations list\'' -o 'work' -r -d 'only list the links of this work, as translation or original'
+complete -c bookid -n '__bookid_at \'bookid translations link\'' -o 'translator' -r -d 'link a translator of the work; may be repeated'
+complete -c bookid -f -n '__bookid_at \'bookid publishers\'' -a 'list'
+complete -c bookid -f -n '__bookid_at \'bookid publishers\'' -a 'merge'
+complete -c bookid -n '__bookid_at \'bookid publishers list\'' -o 'limit' -r -d 'list at most this many publishers'
+complete -c bookid -f -n '__bookid_at \'bookid offers\'' -a 'fetch'
+complete -c bookid -f -n '__bookid_at \'bookid offers\'' -a 'list'
+complete -c bookid -n '__bookid_at \'bookid offers list\'' -o 'latest' -d 'list only the most recent offer of each provider'
+complete -c bookid -n '__bookid_at \'bookid offers list\'' -o 'limit' -r -d 'list at most this many offers'
+complete -c bookid -n '__bookid_at \'bookid offers list\'' -o 'provider' -r -d 'list only the offers of this provider'
+complete -c bookid -n '__bookid_at \'bookid review\'' -o 'all' -d 'include closed reviews with -list'
+complete -c bookid -n '__bookid_at \'bookid review\'' -o 'list' -d 'print the pending reviews as JSON instead of walking through them'
+complete -c bookid -n '__bookid_at \'bookid link\'' -o 'viaf' -r -d 'link to this VIAF ID instead of searching VIAF'
+complete -c bookid -n '__bookid_at \'bookid link\'' -o 'wikidata' -r -d 'link to this Wikidata item (with -viaf)'
+complete -c bookid -f -n '__bookid_at \'bookid jobs\'' -a 'add'
+complete -c bookid -f -n '__bookid_at \'bookid jobs\'' -a 'run'
+complete -c bookid -f -n '__bookid_at \'bookid jobs\'' -a 'list'
+complete -c bookid -f -n '__bookid_at \'bookid jobs\'' -a 'status'
+complete -c bookid -f -n '__bookid_at \'bookid jobs\'' -a 'cancel'
+complete -c bookid -f -n '__bookid_at \'bookid jobs add\'' -a 'import'
+complete -c bookid -f -n '__bookid_at \'bookid jobs add\'' -a 'refresh'
+complete -c bookid -f -n '__bookid_at \'bookid jobs add\'' -a 'covers'
+complete -c bookid -n '__bookid_at \'bookid jobs add import\'' -o 'format' -r -d 'input format: onix, goodreads'
+complete -c bookid -n '__bookid_at \'bookid jobs add import\'' -o 'min-confidence' -r -d 'minimum confidence of title matches (goodreads)'
+complete -c bookid -n '__bookid_at \'bookid jobs add refresh\'' -o 'older-than' -r -d 'refresh publications not refreshed for this long, e.g. 90d or 12h'
+complete -c bookid -n '__bookid_at \'bookid jobs list\'' -o 'limit' -r -d 'maximum number of jobs to list'
+complete -c bookid -n '__bookid_at \'bookid jobs list\'' -o 'state' -r -d 'only list jobs in this state: queued, running, done, failed, canceled'
+complete -c bookid -n '__bookid_at \'bookid serve\'' -o 'addr' -r -d 'bind address'
+complete -c bookid -n '__bookid_at \'bookid serve\'' -o 'graphql' -d 'expose the GraphQL API at /graphql'
+complete -c bookid -n '__bookid_at \'bookid serve\'' -o 'grpc' -r -d 'bind address of the gRPC server, e.g. :9090; disabled if empty'
+complete -c bookid -n '__bookid_at \'bookid serve\'' -o 'metrics' -d 'expose Prometheus metrics at /metrics'
+complete -c bookid -n '__bookid_at \'bookid serve\'' -o 'sync' -d 'let "bookid sync" read and write the catalog at /sync/changes'
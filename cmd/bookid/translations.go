@@ -0,0 +1,174 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/sqlite"
+)
+
+// TranslationsCommand represents a command for linking translated works to
+// their original-language works.
+type TranslationsCommand struct {
+	Config Config
+	Stdout io.Writer
+}
+
+// Run executes the command.
+func (c *TranslationsCommand) Run(ctx context.Context, args []string) error {
+	var cmd string
+	if len(args) > 0 {
+		cmd, args = args[0], args[1:]
+	}
+
+	switch cmd {
+	case "list":
+		return c.runList(ctx, args)
+	case "suggest":
+		return c.runSuggest(ctx, args)
+	case "link":
+		return c.runLink(ctx, args)
+	case "", "-h", "-help", "--help", "help":
+		c.usage()
+		return flag.ErrHelp
+	default:
+		return fmt.Errorf("bookid translations %s: unknown command", cmd)
+	}
+}
+
+// runList prints the translation links, or those of one work with -work.
+func (c *TranslationsCommand) runList(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("bookid-translations-list", flag.ContinueOnError)
+	work := fs.Int64("work", 0, "only list the links of this work, as translation or original")
+	fs.Usage = c.usage
+	if err := parseFlags(ctx, fs, args); err != nil {
+		return err
+	} else if fs.NArg() != 0 {
+		return fmt.Errorf("usage: bookid translations list [-work id]")
+	}
+
+	db, err := openDB(c.Config)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	s := sqlite.NewWorkRelationService(db)
+	typ := bookid.WorkRelationTranslationOf
+	if *work == 0 {
+		rels, _, err := s.FindWorkRelations(ctx, bookid.WorkRelationFilter{Type: &typ})
+		if err != nil {
+			return err
+		}
+		return writeJSON(c.Stdout, rels)
+	}
+
+	translations, _, err := s.FindWorkRelations(ctx, bookid.WorkRelationFilter{WorkID: work, Type: &typ})
+	if err != nil {
+		return err
+	}
+	originals, _, err := s.FindWorkRelations(ctx, bookid.WorkRelationFilter{RelatedWorkID: work, Type: &typ})
+	if err != nil {
+		return err
+	}
+	return writeJSON(c.Stdout, append(translations, originals...))
+}
+
+// runSuggest prints the translation links the catalog suggests.
+func (c *TranslationsCommand) runSuggest(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("bookid-translations-suggest", flag.ContinueOnError)
+	fs.Usage = c.usage
+	if err := parseFlags(ctx, fs, args); err != nil {
+		return err
+	} else if fs.NArg() != 0 {
+		return fmt.Errorf("usage: bookid translations suggest")
+	}
+
+	db, err := openDB(c.Config)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	suggestions, err := sqlite.NewWorkRelationService(db).SuggestTranslations(ctx)
+	if err != nil {
+		return err
+	} else if suggestions == nil {
+		suggestions = []*bookid.WorkRelationSuggestion{}
+	}
+	return writeJSON(c.Stdout, suggestions)
+}
+
+// runLink links a work to the original it translates.
+func (c *TranslationsCommand) runLink(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("bookid-translations-link", flag.ContinueOnError)
+	var translators []string
+	fs.Func("translator", "link a translator of the work; may be repeated", func(s string) error {
+		if s = strings.TrimSpace(s); s == "" {
+			return bookid.Errorf(bookid.EINVALID, "Translator name required.")
+		}
+		translators = append(translators, s)
+		return nil
+	})
+	fs.Usage = c.usage
+	if err := parseFlags(ctx, fs, args); err != nil {
+		return err
+	} else if fs.NArg() != 2 {
+		return fmt.Errorf("usage: bookid translations link [-translator name]... <work-id> <original-work-id>")
+	}
+
+	ids, err := parseIDs(fs.Args())
+	if err != nil {
+		return err
+	}
+
+	db, err := openDB(c.Config)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	rel, err := sqlite.NewWorkRelationService(db).LinkTranslation(ctx, ids[0], ids[1], translators)
+	if err != nil {
+		return err
+	}
+	work, err := sqlite.NewWorkService(db).FindWorkByID(ctx, ids[0])
+	if err != nil {
+		return err
+	}
+	return writeJSON(c.Stdout, struct {
+		Relation *bookid.WorkRelation `json:"relation"`
+		Work     *bookid.Work         `json:"work"`
+	}{rel, work})
+}
+
+// usage prints the help text for the command.
+func (c *TranslationsCommand) usage() {
+	fmt.Fprintln(os.Stderr, strings.TrimSpace(`
+Links translated works to the works they translate, so editions in every
+language can be found from the original.
+
+Linking a translation records the title and language of the original on it
+and links the translators given with -translator. The catalog suggests links
+between works with the same title published in different languages, and
+from works whose original title, as recorded by providers such as library
+catalogs, is the title of another work.
+
+Usage:
+
+	bookid translations list [-work id]
+	bookid translations suggest
+	bookid translations link [-translator name]... <work-id> <original-work-id>
+
+The commands are:
+
+	list     list the translation links, or those of one work
+	suggest  list likely translation links that are not yet made
+	link     link a translation to its original
+`))
+}
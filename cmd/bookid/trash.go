@@ -0,0 +1,229 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/sqlite"
+)
+
+// TrashCommand represents a command for reviewing, restoring and purging
+// deleted works and publications.
+type TrashCommand struct {
+	Config Config
+	Stdout io.Writer
+}
+
+// Run executes the command.
+func (c *TrashCommand) Run(ctx context.Context, args []string) error {
+	var cmd string
+	if len(args) > 0 {
+		cmd, args = args[0], args[1:]
+	}
+
+	switch cmd {
+	case "list":
+		return c.runList(ctx, args)
+	case "restore":
+		return c.runRestore(ctx, args)
+	case "purge":
+		return c.runPurge(ctx, args)
+	case "", "-h", "-help", "--help", "help":
+		c.usage()
+		return flag.ErrHelp
+	default:
+		return fmt.Errorf("bookid trash %s: unknown command", cmd)
+	}
+}
+
+// runList prints the works and publications in the trash.
+func (c *TrashCommand) runList(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("bookid-trash-list", flag.ContinueOnError)
+	fs.Usage = c.usage
+	if err := parseFlags(ctx, fs, args); err != nil {
+		return err
+	} else if fs.NArg() != 0 {
+		return fmt.Errorf("usage: bookid trash list")
+	}
+
+	db, err := openDB(c.Config)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	works, _, err := sqlite.NewWorkService(db).FindWorks(ctx, bookid.WorkFilter{OnlyDeleted: true})
+	if err != nil {
+		return err
+	}
+	pubs, _, err := sqlite.NewPublicationService(db).FindPublications(ctx, bookid.PublicationFilter{OnlyDeleted: true})
+	if err != nil {
+		return err
+	}
+
+	return writeJSON(c.Stdout, struct {
+		Works        []*bookid.Work        `json:"works"`
+		Publications []*bookid.Publication `json:"publications"`
+	}{works, pubs})
+}
+
+// runRestore restores works or publications from the trash.
+func (c *TrashCommand) runRestore(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("bookid-trash-restore", flag.ContinueOnError)
+	publication := fs.Bool("publication", false, "restore publications instead of works")
+	fs.Usage = c.usage
+	if err := parseFlags(ctx, fs, args); err != nil {
+		return err
+	} else if fs.NArg() == 0 {
+		return fmt.Errorf("usage: bookid trash restore [-publication] <id>...")
+	}
+
+	ids, err := parseIDs(fs.Args())
+	if err != nil {
+		return err
+	}
+
+	db, err := openDB(c.Config)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	result := trashResult{Works: []int64{}, Publications: []int64{}}
+	for _, id := range ids {
+		if *publication {
+			if err := sqlite.NewPublicationService(db).RestorePublication(ctx, id); err != nil {
+				return fmt.Errorf("restoring publication %d: %w", id, err)
+			}
+			result.Publications = append(result.Publications, id)
+		} else {
+			if err := sqlite.NewWorkService(db).RestoreWork(ctx, id); err != nil {
+				return fmt.Errorf("restoring work %d: %w", id, err)
+			}
+			result.Works = append(result.Works, id)
+		}
+	}
+	return writeJSON(c.Stdout, struct {
+		Restored trashResult `json:"restored"`
+	}{result})
+}
+
+// runPurge permanently removes works or publications from the trash.
+func (c *TrashCommand) runPurge(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("bookid-trash-purge", flag.ContinueOnError)
+	publication := fs.Bool("publication", false, "purge publications instead of works")
+	all := fs.Bool("all", false, "empty the trash")
+	fs.Usage = c.usage
+	if err := parseFlags(ctx, fs, args); err != nil {
+		return err
+	} else if *all == (fs.NArg() > 0) {
+		return fmt.Errorf("usage: bookid trash purge [-publication] <id>... | -all")
+	}
+
+	db, err := openDB(c.Config)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	works, pubs := sqlite.NewWorkService(db), sqlite.NewPublicationService(db)
+
+	// Emptying the trash purges works first, as their publications go with
+	// them.
+	result := trashResult{Works: []int64{}, Publications: []int64{}}
+	if *all {
+		deletedWorks, _, err := works.FindWorks(ctx, bookid.WorkFilter{OnlyDeleted: true})
+		if err != nil {
+			return err
+		}
+		for _, work := range deletedWorks {
+			if err := works.PurgeWork(ctx, work.ID); err != nil {
+				return fmt.Errorf("purging work %d: %w", work.ID, err)
+			}
+			result.Works = append(result.Works, work.ID)
+		}
+
+		deletedPubs, _, err := pubs.FindPublications(ctx, bookid.PublicationFilter{OnlyDeleted: true})
+		if err != nil {
+			return err
+		}
+		for _, pub := range deletedPubs {
+			if err := pubs.PurgePublication(ctx, pub.ID); err != nil {
+				return fmt.Errorf("purging publication %d: %w", pub.ID, err)
+			}
+			result.Publications = append(result.Publications, pub.ID)
+		}
+	} else {
+		ids, err := parseIDs(fs.Args())
+		if err != nil {
+			return err
+		}
+		for _, id := range ids {
+			if *publication {
+				if err := pubs.PurgePublication(ctx, id); err != nil {
+					return fmt.Errorf("purging publication %d: %w", id, err)
+				}
+				result.Publications = append(result.Publications, id)
+			} else {
+				if err := works.PurgeWork(ctx, id); err != nil {
+					return fmt.Errorf("purging work %d: %w", id, err)
+				}
+				result.Works = append(result.Works, id)
+			}
+		}
+	}
+	return writeJSON(c.Stdout, struct {
+		Purged trashResult `json:"purged"`
+	}{result})
+}
+
+// trashResult lists the IDs of the works and publications a command acted on.
+type trashResult struct {
+	Works        []int64 `json:"works"`
+	Publications []int64 `json:"publications"`
+}
+
+// parseIDs parses the IDs given as arguments.
+func parseIDs(args []string) ([]int64, error) {
+	ids := make([]int64, 0, len(args))
+	for _, arg := range args {
+		id, err := strconv.ParseInt(arg, 10, 64)
+		if err != nil {
+			return nil, bookid.Errorf(bookid.EINVALID, "Invalid ID %q.", arg)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// usage prints the help text for the command.
+func (c *TrashCommand) usage() {
+	fmt.Fprintln(os.Stderr, strings.TrimSpace(`
+Manages deleted works and publications. Deleting moves them to the trash,
+where they are hidden from the catalog until restored or purged. Deleting a
+work moves its publications to the trash with it.
+
+Usage:
+
+	bookid trash list
+	bookid trash restore [-publication] <id>...
+	bookid trash purge [-publication] <id>...
+	bookid trash purge -all
+
+The commands are:
+
+	list     list the works and publications in the trash
+	restore  restore works, or publications with -publication, and the
+	         publications deleted with a work; restoring a publication
+	         restores its work if that is deleted too
+	purge    permanently remove works, or publications with -publication,
+	         from the trash, or everything in it with -all
+
+Saving a book that is in the trash restores it.
+`))
+}
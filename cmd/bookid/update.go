@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/sqlite"
+)
+
+// UpdateCommand represents a command for recording personal metadata, such
+// as the reading status or shelf location, on a cataloged publication.
+type UpdateCommand struct {
+	Config Config
+	Stdout io.Writer
+}
+
+// Run executes the command.
+func (c *UpdateCommand) Run(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("bookid-update", flag.ContinueOnError)
+	personal := addPersonalFlags(fs)
+	fs.Usage = func() { c.usage(fs) }
+	if err := parseFlags(ctx, fs, args); err != nil {
+		return err
+	} else if fs.NArg() != 1 {
+		return fmt.Errorf("usage: bookid update [flags] <publication-id>")
+	}
+
+	id, err := strconv.ParseInt(fs.Arg(0), 10, 64)
+	if err != nil {
+		return bookid.Errorf(bookid.EINVALID, "Invalid publication ID %q.", fs.Arg(0))
+	}
+	upd, ok, err := personal.update()
+	if err != nil {
+		return err
+	} else if !ok {
+		return bookid.Errorf(bookid.EINVALID, "Nothing to update; set at least one flag.")
+	}
+
+	db, err := openDB(c.Config)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	pub, err := sqlite.NewPublicationService(db).UpdatePublication(ctx, id, upd)
+	if err != nil {
+		return err
+	}
+	return writeSaved(ctx, c.Stdout, db, pub.WorkID, pub.ID)
+}
+
+// personalFlags represents the flags setting personal metadata on a
+// publication. Only the flags given on the command line are applied.
+type personalFlags struct {
+	fs       *flag.FlagSet
+	status   string
+	rating   int
+	notes    string
+	acquired string
+	location string
+}
+
+// addPersonalFlags defines the personal metadata flags on fs.
+func addPersonalFlags(fs *flag.FlagSet) *personalFlags {
+	f := &personalFlags{fs: fs}
+	fs.StringVar(&f.status, "status", "", "reading status: want_to_read, reading, read or abandoned")
+	fs.IntVar(&f.rating, "rating", 0, "rating from 1 to 5; 0 clears it")
+	fs.StringVar(&f.notes, "notes", "", "personal notes")
+	fs.StringVar(&f.acquired, "acquired", "", "date the copy was acquired, as YYYY-MM-DD")
+	fs.StringVar(&f.location, "location", "", "where the copy is kept, e.g. a shelf code")
+	return f
+}
+
+// update returns the publication update for the flags that were set, and
+// whether any were.
+func (f *personalFlags) update() (upd bookid.PublicationUpdate, ok bool, err error) {
+	f.fs.Visit(func(fl *flag.Flag) {
+		switch fl.Name {
+		case "status":
+			status := bookid.ReadingStatus(f.status)
+			upd.ReadingStatus, ok = &status, true
+		case "rating":
+			upd.Rating, ok = &f.rating, true
+		case "notes":
+			upd.Notes, ok = &f.notes, true
+		case "acquired":
+			var t time.Time
+			if f.acquired != "" {
+				if t, err = time.Parse(time.DateOnly, f.acquired); err != nil {
+					err = bookid.Errorf(bookid.EINVALID, "Invalid acquisition date %q; use YYYY-MM-DD.", f.acquired)
+				}
+			}
+			upd.AcquiredAt, ok = &t, true
+		case "location":
+			upd.Location, ok = &f.location, true
+		}
+	})
+	if err != nil {
+		return upd, false, err
+	} else if v := upd.ReadingStatus; v != nil && !v.Valid() {
+		return upd, false, bookid.Errorf(bookid.EINVALID, "Invalid reading status %q.", *v)
+	}
+	return upd, ok, nil
+}
+
+// usage prints the help text for the command.
+func (c *UpdateCommand) usage(fs *flag.FlagSet) {
+	fmt.Fprintln(os.Stderr, strings.TrimSpace(`
+Records personal metadata on a cataloged publication: the reading status,
+a rating, notes, the date the copy was acquired and where it is kept. Only the
+given flags are changed; an empty value clears a field. Provider data never
+overwrites these fields.
+
+Usage:
+
+	bookid update [flags] <publication-id>
+`))
+	fs.PrintDefaults()
+}
@@ -0,0 +1,80 @@
+package bookid
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// Collection represents a user-defined shelf of works, such as "to-read" or
+// "office library".
+type Collection struct {
+	ID          int64     `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// Validate returns an error if the collection contains invalid fields.
+func (c *Collection) Validate() error {
+	if strings.TrimSpace(c.Name) == "" {
+		return Errorf(EINVALID, "Collection name required.")
+	}
+	return nil
+}
+
+// CollectionService represents a service for managing collections and the
+// works in them.
+type CollectionService interface {
+	// FindCollectionByID retrieves a single collection by ID.
+	// Returns ENOTFOUND if the collection does not exist.
+	FindCollectionByID(ctx context.Context, id int64) (*Collection, error)
+
+	// FindCollections retrieves a list of collections matching the filter
+	// along with the total number of matches, ignoring Offset and Limit.
+	FindCollections(ctx context.Context, filter CollectionFilter) ([]*Collection, int, error)
+
+	// CreateCollection creates a new collection. Returns ECONFLICT if a
+	// collection with the same name, ignoring case, already exists.
+	CreateCollection(ctx context.Context, collection *Collection) error
+
+	// UpdateCollection updates an existing collection. Returns the updated
+	// collection. Returns ENOTFOUND if the collection does not exist.
+	UpdateCollection(ctx context.Context, id int64, upd CollectionUpdate) (*Collection, error)
+
+	// DeleteCollection permanently removes a collection. Its works are kept.
+	// Returns ENOTFOUND if the collection does not exist.
+	DeleteCollection(ctx context.Context, id int64) error
+
+	// AddCollectionWork adds a work to a collection. Adding a work already in
+	// the collection is not an error. Returns ENOTFOUND if the collection or
+	// work does not exist.
+	AddCollectionWork(ctx context.Context, collectionID, workID int64) error
+
+	// RemoveCollectionWork removes a work from a collection.
+	// Returns ENOTFOUND if the work is not in the collection.
+	RemoveCollectionWork(ctx context.Context, collectionID, workID int64) error
+}
+
+// CollectionFilter represents a filter used by FindCollections.
+type CollectionFilter struct {
+	ID *int64
+
+	// Name matches collections by name, ignoring case.
+	Name *string
+
+	// WorkID restricts results to the collections a work is in.
+	WorkID *int64
+
+	// Restrict to subset of results.
+	Offset int
+	Limit  int
+}
+
+// CollectionUpdate represents a set of fields to be updated via
+// UpdateCollection.
+type CollectionUpdate struct {
+	Name        *string
+	Description *string
+}
@@ -0,0 +1,192 @@
+// Package compare searches every provider for the same query and compares
+// their top results field by field, so that the quality of each provider's
+// metadata can be judged before a book is saved.
+package compare
+
+import (
+	"context"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/isbn"
+	"github.com/fwojciec/bookid/language"
+	"github.com/fwojciec/bookid/match"
+)
+
+// Provider represents a provider whose top result is compared.
+type Provider struct {
+	Name   string
+	Finder bookid.BookFinder
+
+	// Longest time the search of the provider may take. Zero leaves only
+	// the deadline of the caller's context.
+	Timeout time.Duration
+}
+
+// Answer is the top result of a provider, or the reason it has none.
+type Answer struct {
+	Provider string             `json:"provider"`
+	Result   *bookid.BookResult `json:"result"`          // Nil if nothing was found
+	Error    string             `json:"error,omitempty"` // Set if the search failed
+}
+
+// Field is a compared field with the value of each provider's top result,
+// in the order of the answers.
+type Field struct {
+	Name   string   `json:"name"`
+	Values []string `json:"values"`
+
+	// Set if providers give different values once normalized. Providers
+	// without a value do not count as disagreeing.
+	Disagree bool `json:"disagree"`
+}
+
+// Comparison is the field-by-field comparison of the top results of several
+// providers for a query.
+type Comparison struct {
+	Query   string   `json:"query"`
+	Answers []Answer `json:"answers"`
+	Fields  []Field  `json:"fields"`
+}
+
+// Disagreements returns the names of the fields providers disagree on.
+func (c *Comparison) Disagreements() []string {
+	names := make([]string, 0)
+	for _, f := range c.Fields {
+		if f.Disagree {
+			names = append(names, f.Name)
+		}
+	}
+	return names
+}
+
+// field is a compared field of book results.
+type field struct {
+	name string
+
+	// Returns the value of the field as displayed.
+	value func(r *bookid.BookResult) string
+
+	// Returns the value compared between providers.
+	key func(r *bookid.BookResult) string
+}
+
+// fields returns the compared fields in display order.
+func fields() []field {
+	return []field{
+		{"title", func(r *bookid.BookResult) string { return r.Title }, func(r *bookid.BookResult) string {
+			return match.Normalize(r.Title)
+		}},
+		{"authors", func(r *bookid.BookResult) string { return strings.Join(r.Authors, "; ") }, func(r *bookid.BookResult) string {
+			// Order of authors is not compared.
+			names := make([]string, len(r.Authors))
+			for i, a := range r.Authors {
+				names[i] = match.Normalize(a)
+			}
+			slices.Sort(names)
+			return strings.Join(names, ";")
+		}},
+		{"published_year", func(r *bookid.BookResult) string {
+			if r.PublishedYear == 0 {
+				return ""
+			}
+			return strconv.Itoa(r.PublishedYear)
+		}, nil},
+		{"publisher", func(r *bookid.BookResult) string { return r.Publisher }, func(r *bookid.BookResult) string {
+			return match.Normalize(r.Publisher)
+		}},
+		{"isbn13", func(r *bookid.BookResult) string { return r.ISBN13 }, func(r *bookid.BookResult) string {
+			return isbn.Normalize(r.ISBN13)
+		}},
+		{"isbn10", func(r *bookid.BookResult) string { return r.ISBN10 }, func(r *bookid.BookResult) string {
+			return isbn.Normalize(r.ISBN10)
+		}},
+		{"language", func(r *bookid.BookResult) string { return r.Language }, func(r *bookid.BookResult) string {
+			return language.Normalize(r.Language)
+		}},
+	}
+}
+
+// Fields returns the names of the compared fields in display order.
+func Fields() []string {
+	fs := fields()
+	names := make([]string, len(fs))
+	for i, f := range fs {
+		names[i] = f.name
+	}
+	return names
+}
+
+// Search searches every provider for query at the same time and compares
+// their top results. A provider that fails is reported in its answer rather
+// than failing the comparison; only a canceled ctx returns an error.
+func Search(ctx context.Context, providers []Provider, query string, opts bookid.SearchOptions) (*Comparison, error) {
+	opts.MaxResults = 1
+
+	answers := make([]Answer, len(providers))
+	var wg sync.WaitGroup
+	for i, p := range providers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			answers[i] = search(ctx, p, query, opts)
+		}()
+	}
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return Compare(query, answers), nil
+}
+
+// search returns the answer of a single provider.
+func search(ctx context.Context, p Provider, query string, opts bookid.SearchOptions) Answer {
+	if p.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.Timeout)
+		defer cancel()
+	}
+
+	answer := Answer{Provider: p.Name}
+	results, err := p.Finder.Search(ctx, query, opts)
+	if err != nil {
+		answer.Error = bookid.ErrorMessage(err)
+		if bookid.ErrorCode(err) == bookid.EINTERNAL {
+			answer.Error = err.Error()
+		}
+	} else if len(results) > 0 {
+		answer.Result = &results[0]
+	}
+	return answer
+}
+
+// Compare compares the results of answers field by field.
+func Compare(query string, answers []Answer) *Comparison {
+	c := &Comparison{Query: query, Answers: answers, Fields: make([]Field, 0)}
+	for _, f := range fields() {
+		cf := Field{Name: f.name, Values: make([]string, len(answers))}
+		keys := make(map[string]bool)
+		for i, a := range answers {
+			if a.Result == nil {
+				continue
+			}
+			cf.Values[i] = f.value(a.Result)
+
+			key := cf.Values[i]
+			if f.key != nil {
+				key = f.key(a.Result)
+			}
+			if key != "" {
+				keys[key] = true
+			}
+		}
+		cf.Disagree = len(keys) > 1
+		c.Fields = append(c.Fields, cf)
+	}
+	return c
+}
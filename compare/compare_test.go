@@ -0,0 +1,78 @@
+package compare_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/compare"
+	"github.com/fwojciec/bookid/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// provider returns a provider finding results, or failing with err.
+func provider(name string, err error, results ...bookid.BookResult) compare.Provider {
+	return compare.Provider{Name: name, Finder: &mock.BookFinder{
+		SearchFn: func(_ context.Context, _ string, opts bookid.SearchOptions) ([]bookid.BookResult, error) {
+			if opts.MaxResults != 1 {
+				return nil, bookid.Errorf(bookid.EINVALID, "Expected a single result.")
+			}
+			return results, err
+		},
+	}}
+}
+
+func TestSearch(t *testing.T) {
+	t.Parallel()
+
+	c, err := compare.Search(context.Background(), []compare.Provider{
+		provider("googlebooks", nil, bookid.BookResult{
+			Title:         "The Hobbit",
+			Authors:       []string{"J. R. R. Tolkien", "Christopher Tolkien"},
+			ISBN13:        "978-0-261-10221-7",
+			Publisher:     "HarperCollins",
+			PublishedYear: 1991,
+		}),
+		provider("openlibrary", nil, bookid.BookResult{
+			Title:         "The hobbit!",
+			Authors:       []string{"Christopher Tolkien", "J.R.R. Tolkien"},
+			ISBN13:        "9780261102217",
+			Publisher:     "Unwin",
+			PublishedYear: 1991,
+			Language:      "en",
+		}),
+		provider("isbndb", nil),
+		provider("worldcat", bookid.Errorf(bookid.EUNAVAILABLE, "WorldCat is unavailable.")),
+	}, "the hobbit", bookid.SearchOptions{})
+	require.NoError(t, err)
+
+	require.Len(t, c.Answers, 4)
+	assert.Equal(t, "The Hobbit", c.Answers[0].Result.Title)
+	assert.Nil(t, c.Answers[2].Result)
+	assert.Empty(t, c.Answers[2].Error)
+	assert.Equal(t, "WorldCat is unavailable.", c.Answers[3].Error)
+
+	assert.Equal(t, compare.Fields(), fieldNames(c))
+	assert.Equal(t, []string{"publisher"}, c.Disagreements())
+	assert.Equal(t, []string{"HarperCollins", "Unwin", "", ""}, c.Fields[3].Values)
+	assert.Equal(t, []string{"", "en", "", ""}, c.Fields[6].Values)
+}
+
+func TestSearch_Canceled(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := compare.Search(ctx, []compare.Provider{provider("googlebooks", nil)}, "dune", bookid.SearchOptions{})
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+// fieldNames returns the names of the compared fields of c.
+func fieldNames(c *compare.Comparison) []string {
+	names := make([]string, len(c.Fields))
+	for i, f := range c.Fields {
+		names[i] = f.Name
+	}
+	return names
+}
@@ -0,0 +1,258 @@
+// Package config loads the bookid configuration file.
+//
+// The file is TOML by default, or YAML if its name ends in ".yaml" or ".yml".
+// It sets the database location, default output format, timeouts and the
+// providers used to identify books, in priority order, with a profile of
+// credentials and endpoints for each:
+//
+//	db = "~/.bookid/db"
+//	format = "table"
+//	timeout = "10s"
+//	providers = ["isbndb", "googlebooks", "openlibrary"]
+//	merge = true
+//	breaker_threshold = 5
+//	breaker_cooldown = "1m"
+//
+//	[profiles.googlebooks]
+//	api_key = "..."
+//	country = "PL"
+//
+//	[profiles.isbndb]
+//	api_key = "..."
+//	timeout = "3s"
+//
+// With local_first set, queries are answered from the catalog and earlier
+// searches whenever they have a good match, and only sent to the providers
+// otherwise.
+//
+// With merge set, all providers are searched at once and their results for
+// the same book merged, recording which provider supplied each field. A
+// provider whose searches fail breaker_threshold times in a row is skipped
+// for breaker_cooldown before it is tried again.
+// Policies decide between the values of fields that providers disagree on,
+// both when merging and when refreshing saved publications:
+//
+//	[policies.publisher]
+//	rule = "prefer"
+//	providers = ["isbndb", "googlebooks"]
+//
+//	[policies.published_year]
+//	rule = "newest"
+//
+//	[policies.title]
+//	rule = "longest"
+//
+//	[policies.language]
+//	rule = "review"
+//
+// The "review" rule keeps the current value and queues the other for review
+// with "bookid conflicts".
+//
+// Settings left out of the file keep their defaults, and environment
+// variables override the file.
+package config
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/fwojciec/bookid"
+	"gopkg.in/yaml.v3"
+)
+
+// Config represents the contents of a configuration file. Zero values mean
+// the setting is not configured.
+type Config struct {
+	// Location of the catalog database and of stored cover images.
+	DBPath   string `toml:"db" yaml:"db"`
+	CoverDir string `toml:"covers" yaml:"covers"`
+
+	// Default output format of commands that support several.
+	Format string `toml:"format" yaml:"format"`
+
+	Timeout   Duration `toml:"timeout" yaml:"timeout"`
+	CacheTTL  Duration `toml:"cache_ttl" yaml:"cache_ttl"`
+	RateLimit float64  `toml:"rate_limit" yaml:"rate_limit"`
+
+	// Number of failed searches in a row after which a provider is skipped,
+	// and for how long before it is tried again.
+	BreakerThreshold int      `toml:"breaker_threshold" yaml:"breaker_threshold"`
+	BreakerCooldown  Duration `toml:"breaker_cooldown" yaml:"breaker_cooldown"`
+
+	// Longest description of a book kept from providers, in characters.
+	// Longer descriptions are cut at a word and end with an ellipsis.
+	DescriptionLength int `toml:"description_length" yaml:"description_length"`
+
+	// Exporter of OpenTelemetry spans, "otlp" or "stdout". Tracing is
+	// disabled if empty.
+	TraceExporter string `toml:"trace_exporter" yaml:"trace_exporter"`
+
+	// Name recorded in the audit log as making changes to the catalog.
+	// Defaults to the name of the current user.
+	Actor string `toml:"actor" yaml:"actor"`
+
+	// Names of the providers to search, most preferred first, as registered
+	// with bookid.RegisterFinder. Providers left out are not used.
+	Providers []string `toml:"providers" yaml:"providers"`
+
+	// Search all providers at once and merge their results for the same
+	// book, rather than stopping at the first provider that finds any.
+	Merge bool `toml:"merge" yaml:"merge"`
+
+	// Search the catalog and cached searches of any age first, and the
+	// providers only when they have no good match.
+	LocalFirst bool `toml:"local_first" yaml:"local_first"`
+
+	// Credentials and endpoints of each provider by name.
+	Profiles map[string]Profile `toml:"profiles" yaml:"profiles"`
+
+	// Merge policies by field name, e.g. "publisher".
+	Policies map[string]Policy `toml:"policies" yaml:"policies"`
+}
+
+// Policy represents the merge policy of a field: a rule, one of "prefer",
+// "newest", "longest" or "review", and for "prefer" the providers in order
+// of preference.
+type Policy struct {
+	Rule      string   `toml:"rule" yaml:"rule"`
+	Providers []string `toml:"providers" yaml:"providers"`
+}
+
+// Profile represents the settings of a single provider. Each provider uses
+// the fields it needs: Google Books and ISBNdb an API key, WorldCat a client
+// ID and secret, and SRU a URL and optionally a record schema. Google Books
+// also accepts a URL replacing its endpoint, such as that of a proxy, and the
+// country to search as, which sets the editions and prices returned.
+type Profile struct {
+	APIKey       string `toml:"api_key" yaml:"api_key"`
+	ClientID     string `toml:"client_id" yaml:"client_id"`
+	ClientSecret string `toml:"client_secret" yaml:"client_secret"`
+	URL          string `toml:"url" yaml:"url"`
+	RecordSchema string `toml:"record_schema" yaml:"record_schema"`
+	Country      string `toml:"country" yaml:"country"`
+
+	// Longest time a search of the provider may take before the next one is
+	// tried. Zero shares the overall timeout among the providers.
+	Timeout Duration `toml:"timeout" yaml:"timeout"`
+}
+
+// Duration is a time.Duration written as a string such as "30s" or "24h".
+type Duration time.Duration
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (d *Duration) UnmarshalText(text []byte) error {
+	v, err := time.ParseDuration(string(text))
+	if err != nil {
+		return bookid.Errorf(bookid.EINVALID, "Invalid duration %q.", text)
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// DefaultPath returns the location of the configuration file in the user's
+// configuration directory, e.g. ~/.config/bookid/config.toml on Linux.
+func DefaultPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "bookid", "config.toml"), nil
+}
+
+// Load reads and validates the configuration file at path. A leading "~/" in
+// the database and cover paths is expanded to the user's home directory.
+// Returns EINVALID if the file cannot be parsed, has unknown settings or is
+// otherwise invalid.
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var c Config
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		if err := dec.Decode(&c); err != nil && !errors.Is(err, io.EOF) {
+			return nil, bookid.Errorf(bookid.EINVALID, "Invalid configuration file %s: %s.", path, err)
+		}
+	default:
+		md, err := toml.Decode(string(data), &c)
+		if err != nil {
+			return nil, bookid.Errorf(bookid.EINVALID, "Invalid configuration file %s: %s.", path, err)
+		} else if undecoded := md.Undecoded(); len(undecoded) > 0 {
+			return nil, bookid.Errorf(bookid.EINVALID, "Unknown setting %q in %s.", undecoded[0].String(), path)
+		}
+	}
+
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	if c.DBPath, err = expandHome(c.DBPath); err != nil {
+		return nil, err
+	} else if c.CoverDir, err = expandHome(c.CoverDir); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// LoadDefault reads the configuration file at DefaultPath. A missing file is
+// not an error and yields an empty configuration.
+func LoadDefault() (*Config, error) {
+	path, err := DefaultPath()
+	if err != nil {
+		return &Config{}, nil
+	}
+	c, err := Load(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return &Config{}, nil
+	}
+	return c, err
+}
+
+// Validate returns EINVALID if c lists a provider twice or has negative
+// limits. Provider names are checked against the provider registry when the
+// providers are created.
+func (c *Config) Validate() error {
+	seen := make(map[string]bool)
+	for _, name := range c.Providers {
+		if seen[name] {
+			return bookid.Errorf(bookid.EINVALID, "Provider %q is listed twice.", name)
+		}
+		seen[name] = true
+	}
+
+	if c.Timeout < 0 || c.CacheTTL < 0 || c.RateLimit < 0 || c.DescriptionLength < 0 {
+		return bookid.Errorf(bookid.EINVALID, "Timeout, cache TTL, rate limit and description length must not be negative.")
+	} else if c.BreakerThreshold < 0 || c.BreakerCooldown < 0 {
+		return bookid.Errorf(bookid.EINVALID, "Breaker threshold and cool-down must not be negative.")
+	}
+	for name, profile := range c.Profiles {
+		if profile.Timeout < 0 {
+			return bookid.Errorf(bookid.EINVALID, "Timeout of provider %q must not be negative.", name)
+		} else if profile.Country != "" && !bookid.ValidCountry(profile.Country) {
+			return bookid.Errorf(bookid.EINVALID, "Invalid country %q of provider %q.", profile.Country, name)
+		}
+	}
+	return nil
+}
+
+// expandHome replaces a leading "~/" in path with the user's home directory.
+func expandHome(path string) (string, error) {
+	if !strings.HasPrefix(path, "~/") {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, path[2:]), nil
+}
@@ -0,0 +1,163 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeFile writes a configuration file with the given name and contents to
+// a temporary directory and returns its path.
+func writeFile(t *testing.T, name, contents string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(contents), 0o600))
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	t.Parallel()
+
+	want := &config.Config{
+		DBPath:            "/var/lib/bookid/db",
+		Format:            "table",
+		Timeout:           config.Duration(10 * time.Second),
+		CacheTTL:          config.Duration(time.Hour),
+		RateLimit:         5,
+		BreakerThreshold:  3,
+		BreakerCooldown:   config.Duration(2 * time.Minute),
+		DescriptionLength: 500,
+		TraceExporter:     "otlp",
+		Actor:             "librarian",
+		Providers:         []string{"isbndb", "googlebooks"},
+		Merge:             true,
+		LocalFirst:        true,
+		Profiles: map[string]config.Profile{
+			"isbndb":   {APIKey: "secret", Timeout: config.Duration(3 * time.Second)},
+			"worldcat": {ClientID: "id", ClientSecret: "shh"},
+		},
+		Policies: map[string]config.Policy{
+			"publisher":      {Rule: "prefer", Providers: []string{"isbndb"}},
+			"published_year": {Rule: "newest"},
+		},
+	}
+
+	t.Run("TOML", func(t *testing.T) {
+		t.Parallel()
+		c, err := config.Load(writeFile(t, "config.toml", `
+db = "/var/lib/bookid/db"
+format = "table"
+timeout = "10s"
+cache_ttl = "1h"
+rate_limit = 5
+breaker_threshold = 3
+breaker_cooldown = "2m"
+description_length = 500
+trace_exporter = "otlp"
+actor = "librarian"
+providers = ["isbndb", "googlebooks"]
+merge = true
+local_first = true
+
+[profiles.isbndb]
+api_key = "secret"
+timeout = "3s"
+
+[profiles.worldcat]
+client_id = "id"
+client_secret = "shh"
+
+[policies.publisher]
+rule = "prefer"
+providers = ["isbndb"]
+
+[policies.published_year]
+rule = "newest"
+`))
+		require.NoError(t, err)
+		assert.Equal(t, want, c)
+	})
+
+	t.Run("YAML", func(t *testing.T) {
+		t.Parallel()
+		c, err := config.Load(writeFile(t, "config.yaml", `
+db: /var/lib/bookid/db
+format: table
+timeout: 10s
+cache_ttl: 1h
+rate_limit: 5
+breaker_threshold: 3
+breaker_cooldown: 2m
+description_length: 500
+trace_exporter: otlp
+actor: librarian
+providers: [isbndb, googlebooks]
+merge: true
+local_first: true
+profiles:
+  isbndb:
+    api_key: secret
+    timeout: 3s
+  worldcat:
+    client_id: id
+    client_secret: shh
+policies:
+  publisher:
+    rule: prefer
+    providers: [isbndb]
+  published_year:
+    rule: newest
+`))
+		require.NoError(t, err)
+		assert.Equal(t, want, c)
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		t.Parallel()
+		for _, name := range []string{"config.toml", "config.yml"} {
+			c, err := config.Load(writeFile(t, name, ""))
+			require.NoError(t, err)
+			assert.Equal(t, &config.Config{}, c)
+		}
+	})
+
+	t.Run("ExpandsHome", func(t *testing.T) {
+		t.Parallel()
+		home, err := os.UserHomeDir()
+		require.NoError(t, err)
+		c, err := config.Load(writeFile(t, "config.toml", `db = "~/books/db"`))
+		require.NoError(t, err)
+		assert.Equal(t, filepath.Join(home, "books", "db"), c.DBPath)
+	})
+
+	t.Run("ErrNotExist", func(t *testing.T) {
+		t.Parallel()
+		_, err := config.Load(filepath.Join(t.TempDir(), "missing.toml"))
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+
+	for _, tt := range []struct{ name, file, contents string }{
+		{"ErrSyntax", "config.toml", `db = `},
+		{"ErrUnknownSetting", "config.toml", `database = "db"`},
+		{"ErrUnknownYAMLSetting", "config.yaml", `database: db`},
+		{"ErrDuration", "config.toml", `timeout = "soon"`},
+		{"ErrNegative", "config.toml", `rate_limit = -1`},
+		{"ErrNegativeDescription", "config.toml", `description_length = -1`},
+		{"ErrNegativeBreaker", "config.toml", `breaker_threshold = -1`},
+		{"ErrNegativeProviderTimeout", "config.toml", "[profiles.isbndb]\ntimeout = \"-1s\""},
+		{"ErrCountry", "config.toml", "[profiles.googlebooks]\ncountry = \"Poland\""},
+		{"ErrDuplicateProvider", "config.toml", `providers = ["sru", "sru"]`},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := config.Load(writeFile(t, tt.file, tt.contents))
+			assert.Equal(t, bookid.EINVALID, bookid.ErrorCode(err))
+		})
+	}
+}
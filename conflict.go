@@ -0,0 +1,88 @@
+package bookid
+
+import (
+	"context"
+	"time"
+)
+
+// ConflictResolution represents how a conflict was resolved.
+type ConflictResolution string
+
+// Conflict resolutions.
+const (
+	ConflictResolutionAccepted ConflictResolution = "accepted" // The proposed value replaced the current one
+	ConflictResolutionRejected ConflictResolution = "rejected" // The current value was kept
+)
+
+// Conflict represents a value of a publication's field proposed by a
+// provider that a merge policy held back for the user to review, rather than
+// replacing or discarding it.
+type Conflict struct {
+	ID            int64  `json:"id"`
+	PublicationID int64  `json:"publication_id"`
+	Field         string `json:"field"` // JSON name, e.g. "publisher"
+
+	CurrentValue     string `json:"current_value"`
+	CurrentProvider  string `json:"current_provider,omitempty"` // Empty if set by the user or unknown
+	ProposedValue    string `json:"proposed_value"`
+	ProposedProvider string `json:"proposed_provider"`
+
+	CreatedAt  time.Time          `json:"created_at"`
+	ResolvedAt time.Time          `json:"resolved_at,omitzero"` // Zero while open
+	Resolution ConflictResolution `json:"resolution,omitempty"`
+}
+
+// Validate returns an error if the conflict contains invalid fields.
+func (c *Conflict) Validate() error {
+	if c.PublicationID == 0 {
+		return Errorf(EINVALID, "Conflict publication required.")
+	} else if c.Field == "" {
+		return Errorf(EINVALID, "Conflict field required.")
+	} else if c.ProposedValue == "" {
+		return Errorf(EINVALID, "Conflict proposed value required.")
+	}
+	return nil
+}
+
+// FieldConflict is a value of a field of a merged result that a merge policy
+// held back for review in favor of the value of another provider.
+type FieldConflict struct {
+	Field    string `json:"field"` // JSON name, e.g. "publisher"
+	Value    string `json:"value"`
+	Provider string `json:"provider"`
+}
+
+// ConflictService represents a service for managing the queue of conflicts
+// between providers awaiting review.
+type ConflictService interface {
+	// FindConflictByID retrieves a single conflict by ID.
+	// Returns ENOTFOUND if the conflict does not exist.
+	FindConflictByID(ctx context.Context, id int64) (*Conflict, error)
+
+	// FindConflicts retrieves a list of conflicts matching the filter along
+	// with the total number of matches, ignoring Offset and Limit.
+	FindConflicts(ctx context.Context, filter ConflictFilter) ([]*Conflict, int, error)
+
+	// CreateConflict queues a conflict for review. A conflict proposing the
+	// same value for the same field as an open one is not queued again, and
+	// the open conflict is returned in its place.
+	CreateConflict(ctx context.Context, conflict *Conflict) error
+
+	// ResolveConflict closes an open conflict, applying the proposed value
+	// to the publication if accept is set. Returns ENOTFOUND if the conflict
+	// does not exist and ECONFLICT if it is already resolved.
+	ResolveConflict(ctx context.Context, id int64, accept bool) (*Conflict, error)
+}
+
+// ConflictFilter represents a filter used by FindConflicts.
+type ConflictFilter struct {
+	ID            *int64
+	PublicationID *int64
+
+	// Open restricts results to conflicts not yet resolved.
+	Open bool
+
+	// Restrict to subset of results.
+	Offset int
+	Limit  int
+}
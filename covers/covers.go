@@ -0,0 +1,182 @@
+// Package covers downloads the cover images of cataloged publications and
+// keeps them on disk, so covers can be shown without depending on provider
+// image hosts. Publications whose provider has no cover are backfilled from
+// the Open Library Covers API. Thumbnails of stored covers are rendered on
+// demand as JPEG or WebP.
+package covers
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/internal/httpclient"
+)
+
+// DefaultOpenLibraryURL is the root of the Open Library Covers API.
+const DefaultOpenLibraryURL = "https://covers.openlibrary.org"
+
+// maxImageSize is the largest cover image downloaded, in bytes.
+const maxImageSize = 10 << 20
+
+// pageSize is the number of publications read from the catalog at a time
+// while backfilling.
+const pageSize = 100
+
+// Ensure service implements interface.
+var _ bookid.CoverService = (*Service)(nil)
+
+// Service implements the CoverService interface by downloading covers into a
+// Store.
+type Service struct {
+	httpClient *http.Client
+
+	PublicationService bookid.PublicationService
+	Store              *Store
+
+	// Root of the Open Library Covers API, used when the provider's
+	// thumbnail is missing or gone.
+	OpenLibraryURL string
+
+	// Longest side of the thumbnails of each cover size, in pixels.
+	Sizes map[string]int
+
+	// Quality of JPEG thumbnails, from 1 to 100.
+	JPEGQuality int
+
+	// Called by Backfill after each publication with the number done so far
+	// and the total, if set. An error stops the backfill.
+	Progress func(done, total int) error
+}
+
+// NewService returns a new instance of Service.
+func NewService(httpClient *http.Client, pubs bookid.PublicationService, store *Store) *Service {
+	return &Service{
+		httpClient:         httpClient,
+		PublicationService: pubs,
+		Store:              store,
+		OpenLibraryURL:     DefaultOpenLibraryURL,
+		Sizes:              DefaultSizes(),
+		JPEGQuality:        DefaultJPEGQuality,
+	}
+}
+
+// FetchCover downloads and stores the cover of a publication. The provider's
+// thumbnail is tried first, then Open Library's large cover by ISBN, OCLC
+// number and LCCN.
+func (s *Service) FetchCover(ctx context.Context, publicationID int64) (*bookid.Publication, error) {
+	pub, err := s.PublicationService.FindPublicationByID(ctx, publicationID)
+	if err != nil {
+		return nil, err
+	} else if s.Store.Exists(pub.CoverPath) {
+		return pub, nil
+	}
+
+	for _, u := range s.sources(pub) {
+		data, err := s.download(ctx, u)
+		if isMissing(err) || bookid.ErrorCode(err) == bookid.EINVALID {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+
+		key, err := s.Store.Put(data)
+		if bookid.ErrorCode(err) == bookid.EINVALID {
+			continue // Not an image, e.g. an HTML error page
+		} else if err != nil {
+			return nil, err
+		}
+		return s.PublicationService.UpdatePublication(ctx, pub.ID, bookid.PublicationUpdate{CoverPath: &key})
+	}
+	return nil, bookid.Errorf(bookid.ENOTFOUND, "No cover found for publication %d.", pub.ID)
+}
+
+// Backfill fetches the covers of all publications without a stored cover.
+// Returns the number of covers fetched and the IDs of the publications no
+// cover was found for.
+func (s *Service) Backfill(ctx context.Context) (fetched int, missing []int64, err error) {
+	hasCover := false
+	for {
+		// Fetched publications drop out of the filter, so only the missing
+		// ones need to be skipped.
+		pubs, n, err := s.PublicationService.FindPublications(ctx, bookid.PublicationFilter{
+			HasCover: &hasCover,
+			Offset:   len(missing),
+			Limit:    pageSize,
+		})
+		if err != nil {
+			return fetched, missing, err
+		} else if len(pubs) == 0 {
+			return fetched, missing, nil
+		}
+
+		// Publications without a cover include the missing ones.
+		total := fetched + n
+		for _, pub := range pubs {
+			if _, err := s.FetchCover(ctx, pub.ID); bookid.ErrorCode(err) == bookid.ENOTFOUND {
+				missing = append(missing, pub.ID)
+			} else if err != nil {
+				return fetched, missing, fmt.Errorf("publication %d: %w", pub.ID, err)
+			} else {
+				fetched++
+			}
+			if s.Progress != nil {
+				if err := s.Progress(fetched+len(missing), total); err != nil {
+					return fetched, missing, err
+				}
+			}
+		}
+	}
+}
+
+// sources returns the URLs a publication's cover may be downloaded from, best
+// first.
+func (s *Service) sources(pub *bookid.Publication) []string {
+	var urls []string
+	if pub.ThumbnailURL != "" {
+		urls = append(urls, pub.ThumbnailURL)
+	}
+	base := strings.TrimSuffix(s.OpenLibraryURL, "/")
+	for _, id := range []struct{ key, value string }{
+		{"isbn", pub.ISBN13},
+		{"isbn", pub.ISBN10},
+		{"oclc", pub.OCLCNumber},
+		{"lccn", pub.LCCN},
+	} {
+		if id.value != "" {
+			// Without default=false, missing covers are a blank image.
+			urls = append(urls, base+"/b/"+id.key+"/"+url.PathEscape(id.value)+"-L.jpg?default=false")
+		}
+	}
+	return urls
+}
+
+// download fetches an image, reading at most maxImageSize bytes.
+func (s *Service) download(ctx context.Context, u string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := httpclient.Do(s.httpClient, "Cover host", req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxImageSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("covers: reading %s: %w", u, err)
+	} else if len(data) > maxImageSize {
+		return nil, bookid.Errorf(bookid.EINVALID, "Cover image exceeds %d bytes.", maxImageSize)
+	}
+	return data, nil
+}
+
+// isMissing reports whether err means an image host has no such image.
+func isMissing(err error) bool {
+	return httpclient.IsStatus(err, http.StatusNotFound, http.StatusGone)
+}
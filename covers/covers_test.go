@@ -0,0 +1,272 @@
+package covers_test
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/covers"
+	"github.com/fwojciec/bookid/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/image/webp"
+)
+
+// newImageServer returns a server that answers the given paths with data and
+// everything else with 404, recording the requested paths.
+func newImageServer(t *testing.T, images map[string][]byte) (*httptest.Server, func() []string) {
+	t.Helper()
+	var mu sync.Mutex
+	var requested []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requested = append(requested, r.URL.Path)
+		mu.Unlock()
+		if data, ok := images[r.URL.Path]; ok {
+			_, _ = w.Write(data)
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), requested...)
+	}
+}
+
+// newCoverService returns a cover service storing images in a temporary
+// directory and fetching Open Library covers from srv.
+func newCoverService(t *testing.T, srv *httptest.Server) (*covers.Service, *sqlite.DB) {
+	t.Helper()
+	db := sqlite.NewDB(":memory:")
+	require.NoError(t, db.Open())
+	t.Cleanup(func() { _ = db.Close() })
+
+	s := covers.NewService(srv.Client(), sqlite.NewPublicationService(db), covers.NewStore(t.TempDir()))
+	s.OpenLibraryURL = srv.URL
+	return s, db
+}
+
+// createPublication saves a publication of a new work.
+func createPublication(t *testing.T, db *sqlite.DB, pub *bookid.Publication) *bookid.Publication {
+	t.Helper()
+	ctx := context.Background()
+	work := &bookid.Work{Title: "Solaris"}
+	require.NoError(t, sqlite.NewWorkService(db).CreateWork(ctx, work))
+	pub.WorkID = work.ID
+	require.NoError(t, sqlite.NewPublicationService(db).CreatePublication(ctx, pub))
+	return pub
+}
+
+// pngImage returns a 1x1 PNG of the given shade of gray.
+func pngImage(t *testing.T, shade uint8) []byte {
+	t.Helper()
+	img := image.NewGray(image.Rect(0, 0, 1, 1))
+	img.SetGray(0, 0, color.Gray{Y: shade})
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+func TestService_FetchCover(t *testing.T) {
+	t.Parallel()
+
+	t.Run("provider thumbnail", func(t *testing.T) {
+		t.Parallel()
+		cover := pngImage(t, 1)
+		srv, requested := newImageServer(t, map[string][]byte{"/thumb": cover})
+		s, db := newCoverService(t, srv)
+		pub := createPublication(t, db, &bookid.Publication{ISBN13: "9780156027601", ThumbnailURL: srv.URL + "/thumb"})
+
+		got, err := s.FetchCover(context.Background(), pub.ID)
+		require.NoError(t, err)
+		require.NotEmpty(t, got.CoverPath)
+		data, err := os.ReadFile(s.Store.Path(got.CoverPath))
+		require.NoError(t, err)
+		assert.Equal(t, cover, data)
+
+		// A stored cover is not downloaded again.
+		_, err = s.FetchCover(context.Background(), pub.ID)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"/thumb"}, requested())
+	})
+
+	t.Run("open library backfill", func(t *testing.T) {
+		t.Parallel()
+		srv, requested := newImageServer(t, map[string][]byte{
+			"/b/oclc/12345-L.jpg": pngImage(t, 2),
+		})
+		s, db := newCoverService(t, srv)
+		pub := createPublication(t, db, &bookid.Publication{
+			ISBN13:       "9780156027601",
+			OCLCNumber:   "12345",
+			ThumbnailURL: srv.URL + "/gone",
+		})
+
+		got, err := s.FetchCover(context.Background(), pub.ID)
+		require.NoError(t, err)
+		assert.True(t, s.Store.Exists(got.CoverPath))
+		assert.Equal(t, []string{"/gone", "/b/isbn/9780156027601-L.jpg", "/b/oclc/12345-L.jpg"}, requested())
+	})
+
+	t.Run("content addressed", func(t *testing.T) {
+		t.Parallel()
+		srv, _ := newImageServer(t, map[string][]byte{"/b/isbn/9780156027601-L.jpg": pngImage(t, 3)})
+		s, db := newCoverService(t, srv)
+		a := createPublication(t, db, &bookid.Publication{ISBN13: "9780156027601"})
+		b := createPublication(t, db, &bookid.Publication{ISBN10: "0156027607", ThumbnailURL: srv.URL + "/b/isbn/9780156027601-L.jpg"})
+
+		gotA, err := s.FetchCover(context.Background(), a.ID)
+		require.NoError(t, err)
+		gotB, err := s.FetchCover(context.Background(), b.ID)
+		require.NoError(t, err)
+		assert.Equal(t, gotA.CoverPath, gotB.CoverPath)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		t.Parallel()
+		srv, _ := newImageServer(t, map[string][]byte{"/html": []byte("<html>Not found</html>")})
+		s, db := newCoverService(t, srv)
+		pub := createPublication(t, db, &bookid.Publication{ISBN13: "9780156027601", ThumbnailURL: srv.URL + "/html"})
+
+		_, err := s.FetchCover(context.Background(), pub.ID)
+		assert.Equal(t, bookid.ENOTFOUND, bookid.ErrorCode(err))
+	})
+
+	t.Run("unavailable", func(t *testing.T) {
+		t.Parallel()
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusBadGateway)
+		}))
+		t.Cleanup(srv.Close)
+		s, db := newCoverService(t, srv)
+		pub := createPublication(t, db, &bookid.Publication{ISBN13: "9780156027601"})
+
+		_, err := s.FetchCover(context.Background(), pub.ID)
+		assert.Equal(t, bookid.EUNAVAILABLE, bookid.ErrorCode(err))
+	})
+}
+
+func TestService_FindCover(t *testing.T) {
+	t.Parallel()
+
+	// storeCover saves a publication with img as its stored cover.
+	storeCover := func(t *testing.T, img image.Image) (*covers.Service, *bookid.Publication) {
+		t.Helper()
+		var buf bytes.Buffer
+		require.NoError(t, png.Encode(&buf, img))
+		srv, _ := newImageServer(t, map[string][]byte{"/thumb": buf.Bytes()})
+		s, db := newCoverService(t, srv)
+		pub := createPublication(t, db, &bookid.Publication{ThumbnailURL: srv.URL + "/thumb"})
+		pub, err := s.FetchCover(context.Background(), pub.ID)
+		require.NoError(t, err)
+		return s, pub
+	}
+
+	t.Run("jpeg", func(t *testing.T) {
+		t.Parallel()
+		s, pub := storeCover(t, image.NewGray(image.Rect(0, 0, 600, 900)))
+
+		cover, err := s.FindCover(context.Background(), pub.ID, bookid.CoverSizeSmall, bookid.CoverFormatJPEG)
+		require.NoError(t, err)
+		assert.Equal(t, "image/jpeg", cover.ContentType)
+		img, err := jpeg.Decode(bytes.NewReader(cover.Data))
+		require.NoError(t, err)
+		assert.Equal(t, image.Rect(0, 0, 107, 160), img.Bounds())
+
+		// Thumbnails are kept, so the original is no longer needed.
+		require.NoError(t, os.Remove(s.Store.Path(pub.CoverPath)))
+		again, err := s.FindCover(context.Background(), pub.ID, bookid.CoverSizeSmall, bookid.CoverFormatJPEG)
+		require.NoError(t, err)
+		assert.Equal(t, cover, again)
+	})
+
+	t.Run("webp", func(t *testing.T) {
+		t.Parallel()
+
+		// Covers smaller than the size are kept as they are, so the lossless
+		// WebP decodes to the original pixels.
+		want := image.NewNRGBA(image.Rect(0, 0, 37, 53))
+		for i := range want.Pix {
+			want.Pix[i] = uint8(i*7919 + i/148*31)
+		}
+		s, pub := storeCover(t, want)
+
+		cover, err := s.FindCover(context.Background(), pub.ID, bookid.CoverSizeLarge, bookid.CoverFormatWebP)
+		require.NoError(t, err)
+		assert.Equal(t, "image/webp", cover.ContentType)
+		img, err := webp.Decode(bytes.NewReader(cover.Data))
+		require.NoError(t, err)
+		require.Equal(t, want.Bounds(), img.Bounds())
+		for y := 0; y < 53; y++ {
+			for x := 0; x < 37; x++ {
+				require.Equal(t, want.NRGBAAt(x, y), color.NRGBAModel.Convert(img.At(x, y)), "pixel (%d, %d)", x, y)
+			}
+		}
+	})
+
+	t.Run("ErrInvalid", func(t *testing.T) {
+		t.Parallel()
+		s, pub := storeCover(t, image.NewGray(image.Rect(0, 0, 1, 1)))
+
+		_, err := s.FindCover(context.Background(), pub.ID, "huge", bookid.CoverFormatJPEG)
+		assert.Equal(t, bookid.EINVALID, bookid.ErrorCode(err))
+		_, err = s.FindCover(context.Background(), pub.ID, bookid.CoverSizeSmall, "bmp")
+		assert.Equal(t, bookid.EINVALID, bookid.ErrorCode(err))
+	})
+
+	t.Run("ErrNotFound", func(t *testing.T) {
+		t.Parallel()
+		srv, _ := newImageServer(t, nil)
+		s, db := newCoverService(t, srv)
+		pub := createPublication(t, db, &bookid.Publication{ISBN13: "9780156027601"})
+
+		_, err := s.FindCover(context.Background(), pub.ID, "", "")
+		assert.Equal(t, bookid.ENOTFOUND, bookid.ErrorCode(err))
+	})
+}
+
+func TestService_Backfill(t *testing.T) {
+	t.Parallel()
+
+	srv, _ := newImageServer(t, map[string][]byte{
+		"/b/isbn/9780156027601-L.jpg": pngImage(t, 4),
+		"/b/isbn/9780441172719-L.jpg": pngImage(t, 5),
+	})
+	s, db := newCoverService(t, srv)
+	createPublication(t, db, &bookid.Publication{ISBN13: "9780156027601"})
+	missing := createPublication(t, db, &bookid.Publication{ISBN13: "9780743273565"})
+	createPublication(t, db, &bookid.Publication{ISBN13: "9780441172719"})
+
+	fetched, notFound, err := s.Backfill(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, fetched)
+	assert.Equal(t, []int64{missing.ID}, notFound)
+}
+
+func TestStore_Put(t *testing.T) {
+	t.Parallel()
+
+	store := covers.NewStore(t.TempDir())
+	key, err := store.Put(pngImage(t, 6))
+	require.NoError(t, err)
+	assert.Regexp(t, `^[0-9a-f]{2}/[0-9a-f]{64}\.png$`, key)
+	assert.True(t, store.Exists(key))
+
+	_, err = store.Put([]byte("not an image"))
+	assert.Equal(t, bookid.EINVALID, bookid.ErrorCode(err))
+
+	// Keys cannot escape the store directory.
+	assert.Equal(t, store.Path("etc/passwd"), store.Path("../../etc/passwd"))
+}
@@ -0,0 +1,112 @@
+package covers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/fwojciec/bookid"
+)
+
+// Store keeps cover images on disk under the SHA-256 of their content, so an
+// image shared by several publications is stored once. Images are sharded
+// into subdirectories by the first two characters of their hash.
+type Store struct {
+	Dir string
+}
+
+// NewStore returns a store of images under dir.
+func NewStore(dir string) *Store {
+	return &Store{Dir: dir}
+}
+
+// Put stores an image and returns its key, a path relative to the store
+// directory. Returns EINVALID if data is not a JPEG, PNG, GIF or WebP image.
+func (s *Store) Put(data []byte) (string, error) {
+	ext, ok := imageExtensions()[http.DetectContentType(data)]
+	if !ok {
+		return "", bookid.Errorf(bookid.EINVALID, "Unsupported cover image format.")
+	}
+
+	sum := sha256.Sum256(data)
+	name := hex.EncodeToString(sum[:])
+	key := path.Join(name[:2], name+ext)
+	if s.Exists(key) {
+		return key, nil
+	}
+	if err := s.write(key, data); err != nil {
+		return "", err
+	}
+	return key, nil
+}
+
+// Get returns the stored image with the given key. Returns ENOTFOUND if it
+// is not stored.
+func (s *Store) Get(key string) ([]byte, error) {
+	if strings.TrimSpace(key) == "" {
+		return nil, bookid.Errorf(bookid.ENOTFOUND, "Cover image not found.")
+	}
+	data, err := os.ReadFile(s.Path(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, bookid.Errorf(bookid.ENOTFOUND, "Cover image not found.")
+	} else if err != nil {
+		return nil, fmt.Errorf("reading cover file: %w", err)
+	}
+	return data, nil
+}
+
+// write stores data under key, replacing any image stored there.
+func (s *Store) write(key string, data []byte) error {
+	// Write to a temporary file first so readers never see a partial image.
+	filename := s.Path(key)
+	if err := os.MkdirAll(filepath.Dir(filename), 0o755); err != nil {
+		return fmt.Errorf("creating cover directory: %w", err)
+	}
+	f, err := os.CreateTemp(filepath.Dir(filename), ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("creating cover file: %w", err)
+	}
+	defer func() { _ = os.Remove(f.Name()) }()
+	if _, err := f.Write(data); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("writing cover file: %w", err)
+	} else if err := f.Close(); err != nil {
+		return fmt.Errorf("writing cover file: %w", err)
+	} else if err := os.Rename(f.Name(), filename); err != nil {
+		return fmt.Errorf("storing cover file: %w", err)
+	}
+	return nil
+}
+
+// Path returns the location of the image with the given key on disk. Keys
+// are slash-separated and cannot refer outside the store directory.
+func (s *Store) Path(key string) string {
+	return filepath.Join(s.Dir, filepath.FromSlash(path.Clean("/"+key)))
+}
+
+// Exists reports whether the image with the given key is stored.
+func (s *Store) Exists(key string) bool {
+	if strings.TrimSpace(key) == "" {
+		return false
+	}
+	_, err := os.Stat(s.Path(key))
+	return err == nil
+}
+
+// imageExtensions returns the file extensions of the supported image types
+// by MIME type.
+func imageExtensions() map[string]string {
+	return map[string]string{
+		"image/jpeg": ".jpg",
+		"image/png":  ".png",
+		"image/gif":  ".gif",
+		"image/webp": ".webp",
+	}
+}
@@ -0,0 +1,136 @@
+package covers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif" // Register the GIF decoder for stored covers
+	"image/jpeg"
+	_ "image/png" // Register the PNG decoder for stored covers
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/fwojciec/bookid"
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp" // Register the WebP decoder for stored covers
+)
+
+// DefaultJPEGQuality is the quality of JPEG thumbnails, from 1 to 100.
+const DefaultJPEGQuality = 85
+
+// DefaultSizes returns the longest side, in pixels, of the thumbnails of each
+// cover size.
+func DefaultSizes() map[string]int {
+	return map[string]int{
+		bookid.CoverSizeSmall:  160,
+		bookid.CoverSizeMedium: 320,
+		bookid.CoverSizeLarge:  640,
+	}
+}
+
+// coverFormat describes how thumbnails in a format are stored and served.
+type coverFormat struct {
+	ext         string
+	contentType string
+}
+
+// coverFormats returns the supported thumbnail formats by name.
+func coverFormats() map[string]coverFormat {
+	return map[string]coverFormat{
+		bookid.CoverFormatJPEG: {ext: ".jpg", contentType: "image/jpeg"},
+		bookid.CoverFormatWebP: {ext: ".webp", contentType: "image/webp"},
+	}
+}
+
+// FindCover returns the stored cover of a publication scaled to fit size and
+// encoded in format. Thumbnails are rendered from the stored original on first
+// use and kept in the Store, so covers are never downloaded again. Covers
+// smaller than size are not enlarged.
+func (s *Service) FindCover(ctx context.Context, publicationID int64, size, format string) (*bookid.Cover, error) {
+	if size == "" {
+		size = bookid.CoverSizeMedium
+	}
+	if format == "" {
+		format = bookid.CoverFormatJPEG
+	}
+	px, ok := s.Sizes[size]
+	if !ok {
+		return nil, bookid.Errorf(bookid.EINVALID, "Unknown cover size %q.", size)
+	}
+	f, ok := coverFormats()[format]
+	if !ok {
+		return nil, bookid.Errorf(bookid.EINVALID, "Unknown cover format %q.", format)
+	}
+
+	pub, err := s.PublicationService.FindPublicationByID(ctx, publicationID)
+	if err != nil {
+		return nil, err
+	} else if pub.CoverPath == "" {
+		return nil, bookid.Errorf(bookid.ENOTFOUND, "Publication %d has no stored cover.", pub.ID)
+	}
+
+	// Thumbnails are named after their original, so they are shared by the
+	// same publications.
+	name := strings.TrimSuffix(path.Base(pub.CoverPath), path.Ext(pub.CoverPath))
+	key := path.Join("sizes", strconv.Itoa(px), path.Dir(pub.CoverPath), name+f.ext)
+	if data, err := s.Store.Get(key); err == nil {
+		return &bookid.Cover{ContentType: f.contentType, Data: data}, nil
+	} else if bookid.ErrorCode(err) != bookid.ENOTFOUND {
+		return nil, err
+	}
+
+	original, err := s.Store.Get(pub.CoverPath)
+	if err != nil {
+		return nil, err
+	}
+	data, err := s.render(original, px, format)
+	if err != nil {
+		return nil, fmt.Errorf("publication %d: %w", pub.ID, err)
+	} else if err := s.Store.write(key, data); err != nil {
+		return nil, err
+	}
+	return &bookid.Cover{ContentType: f.contentType, Data: data}, nil
+}
+
+// render decodes an image, scales it down so that its longest side is at
+// most px pixels and encodes it in format.
+func (s *Service) render(data []byte, px int, format string) ([]byte, error) {
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("decoding cover: %w", err)
+	}
+
+	sb := src.Bounds()
+	width, height := sb.Dx(), sb.Dy()
+	if longest := max(width, height); longest > px {
+		width = max(1, (width*px+longest/2)/longest)
+		height = max(1, (height*px+longest/2)/longest)
+	}
+	dst := image.NewNRGBA(image.Rect(0, 0, width, height))
+
+	// JPEG has no transparency, so transparent covers are put on white.
+	op := draw.Src
+	if format == bookid.CoverFormatJPEG {
+		draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+		op = draw.Over
+	}
+	if width == sb.Dx() && height == sb.Dy() {
+		draw.Draw(dst, dst.Bounds(), src, sb.Min, op)
+	} else {
+		draw.CatmullRom.Scale(dst, dst.Bounds(), src, sb, op, nil)
+	}
+
+	var buf bytes.Buffer
+	if format == bookid.CoverFormatWebP {
+		err = encodeWebP(&buf, dst)
+	} else {
+		err = jpeg.Encode(&buf, dst, &jpeg.Options{Quality: s.JPEGQuality})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("encoding cover: %w", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,492 @@
+package covers
+
+import (
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"io"
+	"math/bits"
+	"slices"
+)
+
+// VP8L limits, from the WebP lossless bitstream specification.
+const (
+	webpMaxDimension  = 1 << 14
+	webpMaxCodeLength = 15 // Longest Huffman code of a pixel channel
+	webpMaxCLLength   = 7  // Longest Huffman code of the code length code
+	webpPredictorBits = 9  // log2 of the predictor tile size, the largest allowed
+	webpPredictorMode = 12 // ClampAddSubtractFull(L, T, TL)
+)
+
+// Transform types.
+const (
+	webpTransformPredictor     = 0
+	webpTransformSubtractGreen = 2
+)
+
+// Alphabet sizes of the green (literals and lengths), red, blue, alpha and
+// distance codes. Green has 24 length codes after its 256 literals.
+const (
+	webpGreenAlphabet    = 256 + 24
+	webpLiteralAlphabet  = 256
+	webpDistanceAlphabet = 40
+)
+
+// webpCodeLengthOrder is the order in which the lengths of the code length
+// code are written.
+func webpCodeLengthOrder() []int {
+	return []int{17, 18, 0, 1, 2, 3, 4, 5, 16, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15}
+}
+
+// encodeWebP writes img as a lossless WebP image.
+//
+// Go only decodes WebP, so this is a minimal VP8L encoder: the subtract-green
+// transform and a single gradient predictor decorrelate the pixels, whose
+// residuals are then Huffman coded, with runs copied from the pixel to the
+// left or the row above. It uses no color cache or per-tile codes, which
+// keeps it short at the cost of some size.
+func encodeWebP(w io.Writer, img image.Image) error {
+	b := img.Bounds()
+	width, height := b.Dx(), b.Dy()
+	if width < 1 || height < 1 || width > webpMaxDimension || height > webpMaxDimension {
+		return errors.New("webp: invalid image dimensions")
+	}
+
+	// Collect the pixels as R, G, B, A bytes, noting whether any is not
+	// opaque.
+	pix := make([]byte, 0, 4*width*height)
+	alpha := false
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+			pix = append(pix, c.R, c.G, c.B, c.A)
+			alpha = alpha || c.A != 0xff
+		}
+	}
+	residuals := predictResiduals(subtractGreen(pix), width, height)
+
+	bw := &bitWriter{}
+	bw.writeBits(0x2f, 8) // Signature
+	bw.writeBits(uint32(width-1), 14)
+	bw.writeBits(uint32(height-1), 14)
+	bw.writeBits(boolBit(alpha), 1)
+	bw.writeBits(0, 3) // Version
+
+	// Transforms are undone in reverse order, so the predictor comes last.
+	bw.writeBits(1, 1)
+	bw.writeBits(webpTransformSubtractGreen, 2)
+	bw.writeBits(1, 1)
+	bw.writeBits(webpTransformPredictor, 2)
+	bw.writeBits(webpPredictorBits-2, 3)
+	writePredictorImage(bw)
+	bw.writeBits(0, 1) // No more transforms
+
+	bw.writeBits(0, 1) // No color cache
+	bw.writeBits(0, 1) // A single group of Huffman codes for the image
+
+	// Green (with copy lengths), red, blue, alpha and distance are coded
+	// separately.
+	tokens := webpTokens(residuals, width)
+	histograms := [5][]int{
+		make([]int, webpGreenAlphabet),
+		make([]int, webpLiteralAlphabet),
+		make([]int, webpLiteralAlphabet),
+		make([]int, webpLiteralAlphabet),
+		make([]int, webpDistanceAlphabet),
+	}
+	for _, t := range tokens {
+		if t.length == 0 {
+			histograms[0][residuals[t.pos+1]]++
+			histograms[1][residuals[t.pos+0]]++
+			histograms[2][residuals[t.pos+2]]++
+			histograms[3][residuals[t.pos+3]]++
+			continue
+		}
+		lengthCode, _, _ := prefixEncode(t.length)
+		distanceCode, _, _ := prefixEncode(t.distance)
+		histograms[0][webpLiteralAlphabet+lengthCode]++
+		histograms[4][distanceCode]++
+	}
+	var codes [5]huffmanCode
+	for i, h := range histograms {
+		codes[i] = writeHuffmanCode(bw, h)
+	}
+
+	for _, t := range tokens {
+		if t.length == 0 {
+			codes[0].write(bw, int(residuals[t.pos+1]))
+			codes[1].write(bw, int(residuals[t.pos+0]))
+			codes[2].write(bw, int(residuals[t.pos+2]))
+			codes[3].write(bw, int(residuals[t.pos+3]))
+			continue
+		}
+		code, n, extra := prefixEncode(t.length)
+		codes[0].write(bw, webpLiteralAlphabet+code)
+		bw.writeBits(extra, n)
+		code, n, extra = prefixEncode(t.distance)
+		codes[4].write(bw, code)
+		bw.writeBits(extra, n)
+	}
+	data := bw.bytes()
+
+	// Wrap the bitstream in a RIFF container, padded to an even size.
+	size := len(data) + len(data)%2
+	header := make([]byte, 20, 20+size)
+	copy(header[0:], "RIFF")
+	binary.LittleEndian.PutUint32(header[4:], uint32(4+8+size))
+	copy(header[8:], "WEBPVP8L")
+	binary.LittleEndian.PutUint32(header[16:], uint32(len(data)))
+	out := append(header, data...)
+	if len(data)%2 == 1 {
+		out = append(out, 0)
+	}
+	_, err := w.Write(out)
+	return err
+}
+
+// webpToken is either a literal pixel or a copy of earlier pixels.
+type webpToken struct {
+	pos      int // Byte offset of the pixel
+	length   int // Pixels copied, zero for a literal
+	distance int // Distance code of the copied pixels
+}
+
+// Backward references used by webpTokens, as distance codes: the pixel to
+// the left and the pixel above.
+const (
+	webpDistanceLeft  = 2
+	webpDistanceAbove = 1
+)
+
+// webpMinCopy and webpMaxCopy bound the number of pixels copied at once.
+const (
+	webpMinCopy = 3
+	webpMaxCopy = 4096
+)
+
+// webpTokens splits pixels into literals and runs repeating the pixel to the
+// left or the row above, which are common in the residuals of covers with
+// flat backgrounds.
+func webpTokens(pix []byte, width int) []webpToken {
+	n := len(pix) / 4
+	same := func(i, j int) bool {
+		return pix[4*i] == pix[4*j] && pix[4*i+1] == pix[4*j+1] && pix[4*i+2] == pix[4*j+2] && pix[4*i+3] == pix[4*j+3]
+	}
+	run := func(i, back int) int {
+		k := 0
+		for i >= back && i+k < n && k < webpMaxCopy && same(i+k, i+k-back) {
+			k++
+		}
+		return k
+	}
+
+	var tokens []webpToken
+	for i := 0; i < n; {
+		left, above := run(i, 1), run(i, width)
+		switch {
+		case max(left, above) < webpMinCopy:
+			tokens = append(tokens, webpToken{pos: 4 * i})
+			i++
+		case left >= above:
+			tokens = append(tokens, webpToken{pos: 4 * i, length: left, distance: webpDistanceLeft})
+			i += left
+		default:
+			tokens = append(tokens, webpToken{pos: 4 * i, length: above, distance: webpDistanceAbove})
+			i += above
+		}
+	}
+	return tokens
+}
+
+// prefixEncode returns the prefix code of a copy length or distance code,
+// along with the number and value of its extra bits.
+func prefixEncode(v int) (code int, nbits uint, extra uint32) {
+	x := v - 1
+	if x < 4 {
+		return x, 0, 0
+	}
+	high := bits.Len(uint(x)) - 1
+	second := (x >> (high - 1)) & 1
+	nbits = uint(high - 1)
+	return 2*high + second, nbits, uint32(x) & (1<<nbits - 1)
+}
+
+// subtractGreen subtracts the green value of each pixel from its red and blue
+// values in place and returns pix.
+func subtractGreen(pix []byte) []byte {
+	for p := 0; p < len(pix); p += 4 {
+		pix[p+0] -= pix[p+1]
+		pix[p+2] -= pix[p+1]
+	}
+	return pix
+}
+
+// predictResiduals returns the difference between each pixel and its
+// prediction. The first pixel is predicted as opaque black, the rest of the
+// first row from the left and the first column from the top, as the format
+// requires; all other pixels use the gradient predictor.
+func predictResiduals(pix []byte, width, height int) []byte {
+	out := make([]byte, len(pix))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			p := 4 * (y*width + x)
+			for c := 0; c < 4; c++ {
+				var prediction byte
+				switch {
+				case x == 0 && y == 0:
+					if c == 3 {
+						prediction = 0xff
+					}
+				case y == 0:
+					prediction = pix[p-4+c]
+				case x == 0:
+					prediction = pix[p-4*width+c]
+				default:
+					prediction = clampAddSubtract(pix[p-4+c], pix[p-4*width+c], pix[p-4*width-4+c])
+				}
+				out[p+c] = pix[p+c] - prediction
+			}
+		}
+	}
+	return out
+}
+
+// clampAddSubtract returns a+b-c clamped to a byte.
+func clampAddSubtract(a, b, c byte) byte {
+	return byte(min(max(int(a)+int(b)-int(c), 0), 255))
+}
+
+// writePredictorImage writes the sub-image of predictor modes with the same
+// mode for every tile. Every channel has a single symbol, so the pixels
+// themselves take no bits.
+func writePredictorImage(bw *bitWriter) {
+	bw.writeBits(0, 1) // No color cache
+	writeSingleSymbolCode(bw, webpPredictorMode)
+	for range 4 {
+		writeSingleSymbolCode(bw, 0)
+	}
+}
+
+// writeSingleSymbolCode writes a simple code for a single symbol below 256.
+func writeSingleSymbolCode(bw *bitWriter, symbol int) {
+	bw.writeBits(1, 1) // Simple code
+	bw.writeBits(0, 1) // One symbol
+	if symbol < 2 {
+		bw.writeBits(0, 1)
+		bw.writeBits(uint32(symbol), 1)
+	} else {
+		bw.writeBits(1, 1)
+		bw.writeBits(uint32(symbol), 8)
+	}
+}
+
+// huffmanCode holds the canonical code and length of each symbol, with the
+// bits of each code reversed for writing.
+type huffmanCode struct {
+	codes   []uint32
+	lengths []int
+}
+
+// write writes the code of a symbol.
+func (h huffmanCode) write(bw *bitWriter, symbol int) {
+	if n := h.lengths[symbol]; n > 0 {
+		bw.writeBits(h.codes[symbol], uint(n))
+	}
+}
+
+// writeHuffmanCode writes the Huffman code for a histogram and returns it.
+// Codes of one or two symbols below 256 are written as simple codes.
+func writeHuffmanCode(bw *bitWriter, histogram []int) huffmanCode {
+	var used []int
+	for symbol, n := range histogram {
+		if n > 0 {
+			used = append(used, symbol)
+		}
+	}
+
+	switch {
+	case len(used) == 0:
+		writeSingleSymbolCode(bw, 0)
+		return huffmanCode{codes: make([]uint32, len(histogram)), lengths: make([]int, len(histogram))}
+	case len(used) == 1 && used[0] < 256:
+		writeSingleSymbolCode(bw, used[0])
+		return huffmanCode{codes: make([]uint32, len(histogram)), lengths: make([]int, len(histogram))}
+	case len(used) == 2 && used[1] < 256:
+		bw.writeBits(1, 1) // Simple code
+		bw.writeBits(1, 1) // Two symbols
+		bw.writeBits(1, 1) // 8-bit first symbol
+		bw.writeBits(uint32(used[0]), 8)
+		bw.writeBits(uint32(used[1]), 8)
+		h := huffmanCode{codes: make([]uint32, len(histogram)), lengths: make([]int, len(histogram))}
+		h.codes[used[1]] = 1
+		h.lengths[used[0]], h.lengths[used[1]] = 1, 1
+		return h
+	}
+
+	lengths := huffmanLengths(histogram, webpMaxCodeLength)
+
+	// The code lengths are themselves Huffman coded, one symbol per length.
+	clHistogram := make([]int, 19)
+	for _, n := range lengths {
+		clHistogram[n]++
+	}
+	clLengths := huffmanLengths(clHistogram, webpMaxCLLength)
+	clCode := newHuffmanCode(clLengths)
+
+	order := webpCodeLengthOrder()
+	n := 4
+	for i, symbol := range order {
+		if clLengths[symbol] > 0 {
+			n = max(n, i+1)
+		}
+	}
+	bw.writeBits(0, 1) // Normal code
+	bw.writeBits(uint32(n-4), 4)
+	for _, symbol := range order[:n] {
+		bw.writeBits(uint32(clLengths[symbol]), 3)
+	}
+	bw.writeBits(0, 1) // Lengths of all symbols follow
+	for _, n := range lengths {
+		clCode.write(bw, n)
+	}
+	return newHuffmanCode(lengths)
+}
+
+// newHuffmanCode returns the canonical Huffman code with the given lengths.
+// A code with a single symbol takes no bits.
+func newHuffmanCode(lengths []int) huffmanCode {
+	h := huffmanCode{codes: make([]uint32, len(lengths)), lengths: slices.Clone(lengths)}
+	var count [webpMaxCodeLength + 1]uint32
+	used := 0
+	for _, n := range lengths {
+		if n > 0 {
+			count[n]++
+			used++
+		}
+	}
+	if used == 1 {
+		clear(h.lengths)
+		return h
+	}
+
+	var next [webpMaxCodeLength + 1]uint32
+	code := uint32(0)
+	for n := 1; n <= webpMaxCodeLength; n++ {
+		code = (code + count[n-1]) << 1
+		next[n] = code
+	}
+	for symbol, n := range lengths {
+		if n > 0 {
+			h.codes[symbol] = reverseBits(next[n], n)
+			next[n]++
+		}
+	}
+	return h
+}
+
+// reverseBits returns the low n bits of v in reverse order. Codes are read
+// from their most significant bit while bits are written least significant
+// first.
+func reverseBits(v uint32, n int) uint32 {
+	var r uint32
+	for range n {
+		r = r<<1 | v&1
+		v >>= 1
+	}
+	return r
+}
+
+// huffmanLengths returns Huffman code lengths of at most limit bits for the
+// symbols of a histogram. Symbols that do not occur get no code. If the
+// optimal code is too deep, the counts are flattened until it fits.
+func huffmanLengths(histogram []int, limit int) []int {
+	counts := slices.Clone(histogram)
+	for {
+		lengths := optimalLengths(counts)
+		if slices.Max(lengths) <= limit {
+			return lengths
+		}
+		for i, n := range counts {
+			if n > 0 {
+				counts[i] = max(n/2, 1)
+			}
+		}
+	}
+}
+
+// optimalLengths returns unrestricted Huffman code lengths for counts. A
+// single occurring symbol gets a length of 1.
+func optimalLengths(counts []int) []int {
+	type node struct {
+		weight int
+		parent int
+	}
+	var nodes []node
+	var leaves []int // Symbol of each leaf node
+	for symbol, n := range counts {
+		if n > 0 {
+			nodes = append(nodes, node{weight: n, parent: -1})
+			leaves = append(leaves, symbol)
+		}
+	}
+	lengths := make([]int, len(counts))
+	if len(leaves) == 1 {
+		lengths[leaves[0]] = 1
+		return lengths
+	}
+
+	// Repeatedly join the two lightest parentless nodes.
+	active := make([]int, len(nodes))
+	for i := range active {
+		active[i] = i
+	}
+	for len(active) > 1 {
+		slices.SortStableFunc(active, func(a, b int) int { return nodes[a].weight - nodes[b].weight })
+		a, b := active[0], active[1]
+		nodes = append(nodes, node{weight: nodes[a].weight + nodes[b].weight, parent: -1})
+		nodes[a].parent, nodes[b].parent = len(nodes)-1, len(nodes)-1
+		active = append(active[2:], len(nodes)-1)
+	}
+
+	for i, symbol := range leaves {
+		for p := nodes[i].parent; p >= 0; p = nodes[p].parent {
+			lengths[symbol]++
+		}
+	}
+	return lengths
+}
+
+// bitWriter packs bits least significant first.
+type bitWriter struct {
+	buf   []byte
+	acc   uint64
+	nbits uint
+}
+
+// writeBits writes the low n bits of v, n <= 32.
+func (w *bitWriter) writeBits(v uint32, n uint) {
+	w.acc |= uint64(v&(1<<n-1)) << w.nbits
+	w.nbits += n
+	for w.nbits >= 8 {
+		w.buf = append(w.buf, byte(w.acc))
+		w.acc >>= 8
+		w.nbits -= 8
+	}
+}
+
+// bytes returns the written bits, padding the last byte with zeros.
+func (w *bitWriter) bytes() []byte {
+	if w.nbits > 0 {
+		return append(w.buf, byte(w.acc))
+	}
+	return w.buf
+}
+
+// boolBit returns 1 for true and 0 for false.
+func boolBit(b bool) uint32 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,328 @@
+// Package crossref implements the BookFinder interface on top of the Crossref
+// REST API. DOI queries are resolved to a single work, everything else is
+// searched among books, monographs and book chapters.
+package crossref
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/doi"
+	"github.com/fwojciec/bookid/internal/httpclient"
+	"github.com/fwojciec/bookid/isbn"
+	bookidquery "github.com/fwojciec/bookid/query"
+	"github.com/fwojciec/bookid/scoring"
+)
+
+// ProviderName identifies results produced by this package.
+const ProviderName = "crossref"
+
+// DefaultBaseURL is the root of the Crossref REST API.
+const DefaultBaseURL = "https://api.crossref.org"
+
+// Page sizes for the works search. The API rejects more than 1000.
+const (
+	defaultMaxResults = 10
+	maxMaxResults     = 1000
+)
+
+// bookFilter restricts searches to book-like work types. Crossref ORs
+// repeated filters of the same name.
+const bookFilter = "type:book,type:monograph,type:edited-book,type:reference-book,type:book-chapter"
+
+// Client implements the BookFinder interface for the Crossref REST API.
+type Client struct {
+	httpClient *http.Client
+	baseURL    string
+
+	// Contact address sent with every request so Crossref routes them to
+	// its faster "polite" pool. Optional.
+	Mailto string
+
+	// Computes the confidence of each result.
+	Scorer *scoring.Scorer
+}
+
+// Register the provider so it can be enabled by name.
+func init() {
+	bookid.RegisterFinder(ProviderName, func(config bookid.ProviderConfig) (bookid.BookFinder, error) {
+		return NewClientWithBaseURL(config.Client(), DefaultBaseURL), nil
+	})
+}
+
+// NewClient creates a new Crossref API client. Crossref does not require an
+// API key.
+func NewClient() *Client {
+	return NewClientWithBaseURL(http.DefaultClient, DefaultBaseURL)
+}
+
+// NewClientWithBaseURL creates a new client against a custom endpoint (for testing)
+func NewClientWithBaseURL(httpClient *http.Client, baseURL string) *Client {
+	return &Client{
+		httpClient: httpClient,
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		Scorer:     scoring.Default(),
+	}
+}
+
+// Search performs a book search based on the provided query. Language and
+// print type are ignored as Crossref supports neither as a filter.
+func (c *Client) Search(ctx context.Context, query string, opts bookid.SearchOptions) ([]bookid.BookResult, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, errors.New("query cannot be empty")
+	} else if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	var results []bookid.BookResult
+	var err error
+	if parsed := bookidquery.Parse(query); len(parsed.DOIs) > 0 {
+		results, err = c.searchDOI(ctx, parsed.DOIs[0])
+	} else {
+		results, err = c.searchGeneral(ctx, query, parsed, opts)
+	}
+	if err != nil {
+		return nil, err
+	}
+	for i := range results {
+		results[i].Confidence = c.Scorer.Score(scoring.Input{Query: query, Options: opts, Result: results[i]})
+	}
+	return opts.Apply(results), nil
+}
+
+// searchDOI looks up a single work by DOI.
+func (c *Client) searchDOI(ctx context.Context, code string) ([]bookid.BookResult, error) {
+	var resp struct {
+		Message json.RawMessage `json:"message"`
+	}
+	if err := c.get(ctx, "/works/"+url.PathEscape(code), url.Values{}, &resp); err != nil {
+		if httpclient.IsStatus(err, http.StatusNotFound) {
+			return []bookid.BookResult{}, nil
+		}
+		return nil, err
+	}
+
+	var w work
+	if err := json.Unmarshal(resp.Message, &w); err != nil {
+		return nil, fmt.Errorf("decoding crossref work: %w", err)
+	}
+	result := w.toBookResult(bookid.SearchTypeDOI)
+	result.ProviderData = resp.Message
+	return []bookid.BookResult{result}, nil
+}
+
+// searchGeneral performs a bibliographic search over book-like works. The
+// author and year of q are searched by their own parameters and everything
+// else bibliographically, falling back to the whole query if that leaves
+// nothing.
+func (c *Client) searchGeneral(ctx context.Context, query string, q bookid.ParsedQuery, opts bookid.SearchOptions) ([]bookid.BookResult, error) {
+	words := slices.DeleteFunc(append([]string{q.Terms, q.Title, q.Publisher}, q.ISBNs...), func(s string) bool { return s == "" })
+	bibliographic := strings.Join(words, " ")
+	if bibliographic == "" && q.Author == "" {
+		bibliographic = query
+	}
+	filter := bookFilter
+	if q.Year != 0 {
+		year := strconv.Itoa(q.Year)
+		filter += ",from-pub-date:" + year + ",until-pub-date:" + year
+	}
+	params := url.Values{
+		"filter": {filter},
+		"rows":   {strconv.Itoa(pageSize(opts.MaxResults))},
+	}
+	if bibliographic != "" {
+		params.Set("query.bibliographic", bibliographic)
+	}
+	if q.Author != "" {
+		params.Set("query.author", q.Author)
+	}
+	if opts.StartIndex > 0 {
+		params.Set("offset", strconv.Itoa(opts.StartIndex))
+	}
+	if opts.OrderBy == bookid.OrderByNewest {
+		params.Set("sort", "published")
+		params.Set("order", "desc")
+	}
+
+	var resp struct {
+		Message struct {
+			Items []json.RawMessage `json:"items"`
+		} `json:"message"`
+	}
+	if err := c.get(ctx, "/works", params, &resp); err != nil {
+		return nil, err
+	}
+
+	results := make([]bookid.BookResult, 0, len(resp.Message.Items))
+	for _, raw := range resp.Message.Items {
+		var w work
+		if err := json.Unmarshal(raw, &w); err != nil {
+			return nil, fmt.Errorf("decoding crossref search result: %w", err)
+		}
+		result := w.toBookResult(bookid.SearchTypeGeneralQuery)
+		result.ProviderData = raw
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// pageSize returns the number of works to request for maxResults.
+func pageSize(maxResults int) int {
+	if maxResults <= 0 {
+		return defaultMaxResults
+	}
+	return min(maxResults, maxMaxResults)
+}
+
+// get performs a GET request against the API and decodes the JSON response into v.
+func (c *Client) get(ctx context.Context, path string, params url.Values, v any) error {
+	if c.Mailto != "" {
+		params.Set("mailto", c.Mailto)
+	}
+	u := c.baseURL + path
+	if len(params) > 0 {
+		u += "?" + params.Encode()
+	}
+	return httpclient.GetJSON(ctx, c.httpClient, "Crossref", u, nil, v)
+}
+
+// work is a single work of a Crossref response.
+type work struct {
+	DOI            string   `json:"DOI"`
+	Type           string   `json:"type"`
+	Title          []string `json:"title"`
+	Subtitle       []string `json:"subtitle"`
+	ContainerTitle []string `json:"container-title"`
+	Author         []person `json:"author"`
+	Editor         []person `json:"editor"`
+	Translator     []person `json:"translator"`
+	Publisher      string   `json:"publisher"`
+	Language       string   `json:"language"`
+	ISBN           []string `json:"ISBN"`
+	ISBNType       []struct {
+		Type  string `json:"type"`
+		Value string `json:"value"`
+	} `json:"isbn-type"`
+	Published struct {
+		DateParts [][]int `json:"date-parts"`
+	} `json:"published"`
+	Issued struct {
+		DateParts [][]int `json:"date-parts"`
+	} `json:"issued"`
+}
+
+// person is an author, editor or translator of a work.
+type person struct {
+	Given  string `json:"given"`
+	Family string `json:"family"`
+	Name   string `json:"name"` // Organizations only have a name
+}
+
+// String returns the person's name in display order.
+func (p person) String() string {
+	if p.Name != "" {
+		return p.Name
+	}
+	return strings.TrimSpace(p.Given + " " + p.Family)
+}
+
+// toBookResult converts a work to our BookResult. Book chapters are reported
+// as the book they belong to with the chapter title kept in Metadata, since
+// the book is what gets cataloged.
+func (w *work) toBookResult(searchType bookid.SearchType) bookid.BookResult {
+	result := bookid.BookResult{
+		Title:      first(w.Title),
+		Authors:    []string{},
+		DOI:        doi.Normalize(w.DOI),
+		Publisher:  w.Publisher,
+		Language:   w.Language,
+		Provider:   ProviderName,
+		SearchType: searchType,
+		Metadata:   map[string]string{"type": w.Type},
+	}
+	if subtitle := first(w.Subtitle); subtitle != "" && result.Title != "" {
+		result.Title += ": " + subtitle
+	}
+	if w.Type == "book-chapter" && first(w.ContainerTitle) != "" {
+		result.Metadata["chapter_title"] = result.Title
+		result.Title = first(w.ContainerTitle)
+	}
+
+	for _, p := range w.Author {
+		if name := p.String(); name != "" {
+			result.Authors = append(result.Authors, name)
+		}
+	}
+	for _, c := range []struct {
+		people []person
+		role   bookid.ContributorRole
+	}{
+		{w.Editor, bookid.ContributorRoleEditor},
+		{w.Translator, bookid.ContributorRoleTranslator},
+	} {
+		for _, p := range c.people {
+			if name := p.String(); name != "" {
+				result.Contributors = append(result.Contributors, bookid.Contributor{Name: name, Role: c.role})
+			}
+		}
+	}
+	// Edited volumes have editors but no authors; credit the editors so the
+	// work has someone to file it under.
+	if len(result.Authors) == 0 {
+		for _, c := range result.Contributors {
+			if c.Role == bookid.ContributorRoleEditor {
+				result.Authors = append(result.Authors, c.Name)
+			}
+		}
+	}
+
+	// Prefer the print ISBN over the electronic one.
+	codes := w.ISBN
+	for _, t := range w.ISBNType {
+		if t.Type == "print" {
+			codes = append([]string{t.Value}, codes...)
+		}
+	}
+	for _, code := range codes {
+		code = isbn.Normalize(code)
+		switch {
+		case result.ISBN13 == "" && isbn.Valid13(code):
+			result.ISBN13 = code
+		case result.ISBN10 == "" && isbn.Valid10(code):
+			result.ISBN10 = code
+		}
+	}
+
+	if year := firstYear(w.Published.DateParts); year != 0 {
+		result.PublishedYear = year
+	} else {
+		result.PublishedYear = firstYear(w.Issued.DateParts)
+	}
+	return result
+}
+
+// first returns the first element of ss, or an empty string.
+func first(ss []string) string {
+	if len(ss) == 0 {
+		return ""
+	}
+	return strings.TrimSpace(ss[0])
+}
+
+// firstYear returns the year of the first date in a Crossref date-parts
+// array, or zero.
+func firstYear(parts [][]int) int {
+	if len(parts) == 0 || len(parts[0]) == 0 {
+		return 0
+	}
+	return parts[0][0]
+}
@@ -0,0 +1,145 @@
+package crossref_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/crossref"
+	"github.com/fwojciec/bookid/internal/httptestutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newClient returns a client replaying the given synthetic fixture, written
+// by hand after the Crossref REST API documentation rather than recorded. The
+// URL of the last request is stored in lastURL, if set.
+func newClient(t *testing.T, fixture string, lastURL *string) *crossref.Client {
+	t.Helper()
+	httpClient := httptestutil.Replay(t, filepath.Join("testdata", "synthetic", fixture))
+	if lastURL != nil {
+		httpClient = httptestutil.LastURL(httpClient, lastURL)
+	}
+	return crossref.NewClientWithBaseURL(httpClient, crossref.DefaultBaseURL)
+}
+
+func TestClient_Search(t *testing.T) {
+	t.Parallel()
+
+	t.Run("doi", func(t *testing.T) {
+		t.Parallel()
+		var lastURL string
+		client := newClient(t, "work_10.1017_9781108555807.json", &lastURL)
+		client.Mailto = "books@example.com"
+
+		results, err := client.Search(context.Background(), "https://doi.org/10.1017/9781108555807", bookid.SearchOptions{IncludeRaw: true})
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		assert.Equal(t, crossref.DefaultBaseURL+"/works/10.1017%2F9781108555807?mailto=books%40example.com", lastURL)
+
+		r := results[0]
+		assert.Equal(t, "Reinforcement Learning: Theory and Algorithms", r.Title)
+		assert.Equal(t, []string{"Ada Example", "Grace Sample"}, r.Authors)
+		assert.Equal(t, "10.1017/9781108555807", r.DOI)
+		assert.Equal(t, "9781108470001", r.ISBN13, "print ISBN is preferred")
+		assert.Equal(t, "Cambridge University Press", r.Publisher)
+		assert.Equal(t, 2021, r.PublishedYear)
+		assert.Equal(t, "en", r.Language)
+		assert.Equal(t, "monograph", r.Metadata["type"])
+		assert.Equal(t, crossref.ProviderName, r.Provider)
+		assert.Equal(t, bookid.SearchTypeDOI, r.SearchType)
+		assert.InDelta(t, 0.95, r.Confidence, 0.01)
+		assert.NotEmpty(t, r.ProviderData)
+	})
+
+	t.Run("general", func(t *testing.T) {
+		t.Parallel()
+		var lastURL string
+		client := newClient(t, "search_learning.json", &lastURL)
+
+		results, err := client.Search(context.Background(), "handbook of learning", bookid.SearchOptions{
+			MaxResults: 5,
+			StartIndex: 10,
+			OrderBy:    bookid.OrderByNewest,
+		})
+		require.NoError(t, err)
+		require.Len(t, results, 2)
+		for _, want := range []string{"/works?", "query.bibliographic=handbook+of+learning", "filter=type%3Abook%2C", "rows=5", "offset=10", "sort=published", "order=desc"} {
+			assert.Contains(t, lastURL, want)
+		}
+
+		chapter := results[0]
+		assert.Equal(t, "Handbook of Learning", chapter.Title)
+		assert.Equal(t, "Policy Gradients", chapter.Metadata["chapter_title"])
+		assert.Equal(t, []string{"Alan Editor"}, chapter.Authors)
+		assert.Equal(t, []bookid.Contributor{{Name: "Alan Editor", Role: bookid.ContributorRoleEditor}}, chapter.Contributors)
+		assert.Equal(t, "10.1007/978-3-030-00001-1_3", chapter.DOI)
+		assert.Equal(t, "9783030000011", chapter.ISBN13)
+		assert.Equal(t, 2019, chapter.PublishedYear)
+		assert.Equal(t, bookid.SearchTypeGeneralQuery, chapter.SearchType)
+		assert.Nil(t, chapter.ProviderData, "raw data is only kept when requested")
+
+		assert.Equal(t, []string{"Example Consortium"}, results[1].Authors)
+		assert.Equal(t, "10.5555/org-report", results[1].DOI)
+	})
+
+	t.Run("fields", func(t *testing.T) {
+		t.Parallel()
+		var lastURL string
+		client := newClient(t, "search_learning_2019.json", &lastURL)
+
+		_, err := client.Search(context.Background(), `title:"Handbook of Learning" author:editor year:2019`, bookid.SearchOptions{})
+		require.NoError(t, err)
+		u, err := url.Parse(lastURL)
+		require.NoError(t, err)
+		assert.Equal(t, "Handbook of Learning", u.Query().Get("query.bibliographic"))
+		assert.Equal(t, "editor", u.Query().Get("query.author"))
+		assert.True(t, strings.HasSuffix(u.Query().Get("filter"), ",from-pub-date:2019,until-pub-date:2019"))
+	})
+
+	t.Run("not_found", func(t *testing.T) {
+		t.Parallel()
+		client := newClient(t, "work_10.5555_missing.json", nil)
+
+		results, err := client.Search(context.Background(), "10.5555/missing", bookid.SearchOptions{})
+		require.NoError(t, err)
+		assert.Empty(t, results)
+	})
+}
+
+func TestClient_Search_Errors(t *testing.T) {
+	t.Parallel()
+
+	t.Run("empty_query", func(t *testing.T) {
+		t.Parallel()
+		_, err := crossref.NewClient().Search(context.Background(), "  ", bookid.SearchOptions{})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "query cannot be empty")
+	})
+
+	tests := []struct {
+		status int
+		code   string
+	}{
+		{http.StatusTooManyRequests, bookid.ERATELIMIT},
+		{http.StatusServiceUnavailable, bookid.EUNAVAILABLE},
+	}
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			t.Parallel()
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(tt.status)
+			}))
+			t.Cleanup(srv.Close)
+			client := crossref.NewClientWithBaseURL(srv.Client(), srv.URL)
+
+			_, err := client.Search(context.Background(), "dune", bookid.SearchOptions{})
+			assert.Equal(t, tt.code, bookid.ErrorCode(err))
+		})
+	}
+}
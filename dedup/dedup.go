@@ -0,0 +1,288 @@
+// Package dedup detects works that were cataloged more than once, e.g. by
+// saving two editions found under slightly different titles, and proposes
+// merging them.
+package dedup
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/isbn"
+	"github.com/fwojciec/bookid/language"
+	"github.com/fwojciec/bookid/match"
+)
+
+// pageSize is the number of works read from the catalog at a time.
+const pageSize = 100
+
+// Candidate is a cataloged work with the details duplicates are detected by.
+type Candidate struct {
+	Work         *bookid.Work
+	Authors      []*bookid.Author
+	Publications []*bookid.Publication
+
+	// RelatedIDs are the works this work is related to, e.g. as a
+	// translation. A relation keeps two works from being detected as
+	// duplicates of each other.
+	RelatedIDs []int64
+}
+
+// Merge is a proposal to merge duplicate works into the target work.
+type Merge struct {
+	TargetID  int64    `json:"target_id"`
+	SourceIDs []int64  `json:"source_ids"`
+	Reasons   []string `json:"reasons"` // Why the works are considered duplicates
+}
+
+// Find returns the merges proposed for candidates, ordered by target.
+//
+// Two works are duplicates if they have a publication ISBN in common, with
+// ISBN-10s compared as ISBN-13s, or if their normalized titles are identical
+// and they share an author. Works with the same title whose publications are
+// all in different languages are likely translations and are not merged.
+// Duplicates are grouped transitively and merged into the work that was
+// cataloged first.
+func Find(candidates []*Candidate) []*Merge {
+	candidates = slices.Clone(candidates)
+	slices.SortFunc(candidates, func(a, b *Candidate) int { return cmp.Compare(a.Work.ID, b.Work.ID) })
+
+	groups := newUnionFind(len(candidates))
+	reasons := make(map[int][]string)
+	link := func(i, j int, reason string) {
+		if slices.Contains(candidates[i].RelatedIDs, candidates[j].Work.ID) ||
+			slices.Contains(candidates[j].RelatedIDs, candidates[i].Work.ID) {
+			return
+		}
+		groups.union(i, j)
+		reasons[i] = append(reasons[i], reason)
+	}
+
+	// Works sharing an ISBN.
+	byISBN := make(map[string]int)
+	for i, c := range candidates {
+		for _, code := range isbns(c) {
+			if j, ok := byISBN[code]; !ok {
+				byISBN[code] = i
+			} else if j != i {
+				link(j, i, fmt.Sprintf("Shared ISBN %s.", code))
+			}
+		}
+	}
+
+	// Works with the same title and an author in common.
+	byTitle := make(map[string][]int)
+	var titles []string
+	for i, c := range candidates {
+		title := match.Normalize(c.Work.Title)
+		if title == "" {
+			continue
+		} else if _, ok := byTitle[title]; !ok {
+			titles = append(titles, title)
+		}
+		byTitle[title] = append(byTitle[title], i)
+	}
+	for _, title := range titles {
+		indexes := byTitle[title]
+		for x, i := range indexes {
+			for _, j := range indexes[x+1:] {
+				a, b := candidates[i], candidates[j]
+				author, ok := commonAuthor(a, b)
+				if !ok || differentLanguages(a, b) {
+					continue
+				}
+				link(i, j, fmt.Sprintf("Same title %q by %s.", a.Work.Title, author))
+			}
+		}
+	}
+
+	// Collect the groups, each keyed by its earliest work.
+	members := make(map[int][]int)
+	var roots []int
+	for i := range candidates {
+		root := groups.find(i)
+		if _, ok := members[root]; !ok {
+			roots = append(roots, root)
+		}
+		members[root] = append(members[root], i)
+	}
+
+	var merges []*Merge
+	for _, root := range roots {
+		group := members[root]
+		if len(group) < 2 {
+			continue
+		}
+		m := &Merge{TargetID: candidates[group[0]].Work.ID}
+		for _, i := range group {
+			if i != group[0] {
+				m.SourceIDs = append(m.SourceIDs, candidates[i].Work.ID)
+			}
+			for _, reason := range reasons[i] {
+				if !slices.Contains(m.Reasons, reason) {
+					m.Reasons = append(m.Reasons, reason)
+				}
+			}
+		}
+		merges = append(merges, m)
+	}
+	slices.SortFunc(merges, func(a, b *Merge) int { return cmp.Compare(a.TargetID, b.TargetID) })
+	return merges
+}
+
+// isbns returns the ISBN-13s of a candidate's publications.
+func isbns(c *Candidate) []string {
+	var codes []string
+	for _, pub := range c.Publications {
+		code := pub.ISBN13
+		if code == "" && pub.ISBN10 != "" {
+			code, _ = isbn.To13(pub.ISBN10)
+		}
+		if code != "" && !slices.Contains(codes, code) {
+			codes = append(codes, code)
+		}
+	}
+	return codes
+}
+
+// commonAuthor returns an author the two works share, comparing the linked
+// authors and the credited author of each work by name regardless of word
+// order, diacritics and script, so "Michal Ajvaz" is "Michał Ajvaz" and
+// "Fyodor Dostoevsky" is "Фёдор Достоевский".
+func commonAuthor(a, b *Candidate) (string, bool) {
+	names := authorNames(b)
+	for _, name := range authorNames(a) {
+		if slices.ContainsFunc(names, func(n string) bool { return match.SameName(n, name) }) {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// authorNames returns the names of a candidate's linked and credited authors.
+func authorNames(c *Candidate) []string {
+	var names []string
+	for _, a := range c.Authors {
+		names = append(names, a.Name)
+	}
+	for _, name := range strings.Split(c.Work.Author, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// differentLanguages reports whether both works have publications with a
+// known language but none in a common one.
+func differentLanguages(a, b *Candidate) bool {
+	langs := languages(b)
+	if len(langs) == 0 {
+		return false
+	}
+	known := false
+	for _, lang := range languages(a) {
+		if slices.Contains(langs, lang) {
+			return false
+		}
+		known = true
+	}
+	return known
+}
+
+// languages returns the languages of a candidate's publications.
+func languages(c *Candidate) []string {
+	var langs []string
+	for _, pub := range c.Publications {
+		if pub.Language != "" {
+			langs = append(langs, language.Base(pub.Language))
+		}
+	}
+	return langs
+}
+
+// Detector loads the catalog to find duplicate works.
+type Detector struct {
+	WorkService         bookid.WorkService
+	AuthorService       bookid.AuthorService
+	PublicationService  bookid.PublicationService
+	WorkRelationService bookid.WorkRelationService
+}
+
+// Detect returns the merges proposed for the whole catalog.
+func (d *Detector) Detect(ctx context.Context) ([]*Merge, error) {
+	rels, _, err := d.WorkRelationService.FindWorkRelations(ctx, bookid.WorkRelationFilter{})
+	if err != nil {
+		return nil, err
+	}
+	related := make(map[int64][]int64)
+	for _, rel := range rels {
+		related[rel.WorkID] = append(related[rel.WorkID], rel.RelatedWorkID)
+		related[rel.RelatedWorkID] = append(related[rel.RelatedWorkID], rel.WorkID)
+	}
+
+	var candidates []*Candidate
+	filter := bookid.WorkFilter{Limit: pageSize}
+	for {
+		works, n, err := d.WorkService.FindWorks(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+		for _, work := range works {
+			authors, _, err := d.AuthorService.FindAuthors(ctx, bookid.AuthorFilter{WorkID: &work.ID})
+			if err != nil {
+				return nil, err
+			}
+			pubs, _, err := d.PublicationService.FindPublications(ctx, bookid.PublicationFilter{WorkID: &work.ID})
+			if err != nil {
+				return nil, err
+			}
+			candidates = append(candidates, &Candidate{
+				Work:         work,
+				Authors:      authors,
+				Publications: pubs,
+				RelatedIDs:   related[work.ID],
+			})
+		}
+
+		filter.Offset += len(works)
+		if len(works) == 0 || filter.Offset >= n {
+			break
+		}
+	}
+	return Find(candidates), nil
+}
+
+// unionFind groups indexes into disjoint sets. The root of each set is its
+// lowest index.
+type unionFind []int
+
+// newUnionFind returns n singleton sets.
+func newUnionFind(n int) unionFind {
+	u := make(unionFind, n)
+	for i := range u {
+		u[i] = i
+	}
+	return u
+}
+
+// find returns the root of the set containing i.
+func (u unionFind) find(i int) int {
+	for u[i] != i {
+		u[i] = u[u[i]]
+		i = u[i]
+	}
+	return i
+}
+
+// union joins the sets containing i and j.
+func (u unionFind) union(i, j int) {
+	a, b := u.find(i), u.find(j)
+	if a > b {
+		a, b = b, a
+	}
+	u[b] = a
+}
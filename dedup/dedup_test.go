@@ -0,0 +1,129 @@
+package dedup_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/dedup"
+	"github.com/fwojciec/bookid/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// candidate returns a candidate for a work with the given publications.
+func candidate(id int64, title, author string, pubs ...*bookid.Publication) *dedup.Candidate {
+	return &dedup.Candidate{
+		Work:         &bookid.Work{ID: id, Title: title, Author: author},
+		Publications: pubs,
+	}
+}
+
+func TestFind(t *testing.T) {
+	t.Parallel()
+
+	t.Run("shared ISBN", func(t *testing.T) {
+		t.Parallel()
+		merges := dedup.Find([]*dedup.Candidate{
+			candidate(2, "The Great Gatsby", "", &bookid.Publication{ISBN10: "0743273567"}),
+			candidate(1, "Great Gatsby", "F. Scott Fitzgerald", &bookid.Publication{ISBN13: "9780743273565"}),
+			candidate(3, "Dune", "Frank Herbert"),
+		})
+		assert.Equal(t, []*dedup.Merge{{
+			TargetID:  1,
+			SourceIDs: []int64{2},
+			Reasons:   []string{"Shared ISBN 9780743273565."},
+		}}, merges)
+	})
+
+	t.Run("same title and author", func(t *testing.T) {
+		t.Parallel()
+		solaris := candidate(2, "Solaris", "")
+		solaris.Authors = []*bookid.Author{{ID: 1, Name: "Stanisław Lem"}}
+		merges := dedup.Find([]*dedup.Candidate{
+			candidate(1, "SOLARIS!", "Stanislaw Lem"),
+			solaris,
+			candidate(3, "Solaris", "Steven Soderbergh"),
+		})
+		assert.Equal(t, []*dedup.Merge{{
+			TargetID:  1,
+			SourceIDs: []int64{2},
+			Reasons:   []string{`Same title "SOLARIS!" by Stanislaw Lem.`},
+		}}, merges)
+	})
+
+	t.Run("same author across scripts", func(t *testing.T) {
+		t.Parallel()
+		merges := dedup.Find([]*dedup.Candidate{
+			candidate(1, "Идиот", "Фёдор Достоевский"),
+			candidate(2, "Idiot", "Fyodor Dostoyevsky"),
+			candidate(3, "Druhé město", "Michal Ajvaz"),
+			candidate(4, "Druhe mesto", "Michał Ajvaz"),
+		})
+		assert.Equal(t, []*dedup.Merge{
+			{TargetID: 1, SourceIDs: []int64{2}, Reasons: []string{`Same title "Идиот" by Фёдор Достоевский.`}},
+			{TargetID: 3, SourceIDs: []int64{4}, Reasons: []string{`Same title "Druhé město" by Michal Ajvaz.`}},
+		}, merges)
+	})
+
+	t.Run("groups are transitive", func(t *testing.T) {
+		t.Parallel()
+		merges := dedup.Find([]*dedup.Candidate{
+			candidate(1, "Dune", "Frank Herbert"),
+			candidate(2, "Dune", "Frank Herbert", &bookid.Publication{ISBN13: "9780441013593"}),
+			candidate(3, "Dune (40th Anniversary)", "", &bookid.Publication{ISBN10: "0441013597"}),
+		})
+		require.Len(t, merges, 1)
+		assert.Equal(t, int64(1), merges[0].TargetID)
+		assert.Equal(t, []int64{2, 3}, merges[0].SourceIDs)
+	})
+
+	t.Run("translations are not duplicates", func(t *testing.T) {
+		t.Parallel()
+		merges := dedup.Find([]*dedup.Candidate{
+			candidate(1, "Solaris", "Stanisław Lem", &bookid.Publication{Language: "pl"}),
+			candidate(2, "Solaris", "Stanisław Lem", &bookid.Publication{Language: "en"}),
+		})
+		assert.Empty(t, merges)
+	})
+
+	t.Run("related works are not duplicates", func(t *testing.T) {
+		t.Parallel()
+		original := candidate(1, "Dune", "Frank Herbert")
+		abridged := candidate(2, "Dune", "Frank Herbert")
+		abridged.RelatedIDs = []int64{1}
+		assert.Empty(t, dedup.Find([]*dedup.Candidate{original, abridged}))
+	})
+}
+
+func TestDetector_Detect(t *testing.T) {
+	t.Parallel()
+
+	db := sqlite.NewDB(":memory:")
+	require.NoError(t, db.Open())
+	defer db.Close()
+	ctx := context.Background()
+
+	works := sqlite.NewWorkService(db)
+	pubs := sqlite.NewPublicationService(db)
+	for _, w := range []*bookid.Work{
+		{Title: "The Great Gatsby", Author: "F. Scott Fitzgerald"},
+		{Title: "Great Gatsby"},
+	} {
+		require.NoError(t, works.CreateWork(ctx, w))
+	}
+	require.NoError(t, pubs.CreatePublication(ctx, &bookid.Publication{WorkID: 1, ISBN13: "9780743273565"}))
+	require.NoError(t, pubs.CreatePublication(ctx, &bookid.Publication{WorkID: 2, ISBN10: "0743273567"}))
+
+	d := &dedup.Detector{
+		WorkService:         works,
+		AuthorService:       sqlite.NewAuthorService(db),
+		PublicationService:  pubs,
+		WorkRelationService: sqlite.NewWorkRelationService(db),
+	}
+	merges, err := d.Detect(ctx)
+	require.NoError(t, err)
+	require.Len(t, merges, 1)
+	assert.Equal(t, int64(1), merges[0].TargetID)
+	assert.Equal(t, []int64{2}, merges[0].SourceIDs)
+}
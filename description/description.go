@@ -0,0 +1,105 @@
+// Package description cleans up the descriptions of books given by providers,
+// which often come as HTML, and shortens them to a configured length.
+package description
+
+import (
+	"context"
+	"html"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/fwojciec/bookid"
+)
+
+// ellipsis marks a truncated description.
+const ellipsis = "…"
+
+// tagPattern matches HTML tags. Block-level tags are captured so they can be
+// replaced with line breaks.
+func tagPattern() *regexp.Regexp {
+	return regexp.MustCompile(`(?i)<\s*(/?\s*(?:p|br|div|li|h[1-6])\b)?[^>]*>`)
+}
+
+// Clean returns s as plain text: HTML tags are dropped, with paragraphs and
+// line breaks kept as line breaks, entities are unescaped and runs of spaces
+// collapsed. Paragraphs are separated by a single blank line.
+func Clean(s string) string {
+	re := tagPattern()
+	s = re.ReplaceAllStringFunc(s, func(tag string) string {
+		if re.FindStringSubmatch(tag)[1] != "" {
+			return "\n"
+		}
+		return ""
+	})
+	s = html.UnescapeString(s)
+
+	var paragraphs []string
+	for _, line := range strings.Split(s, "\n") {
+		if line = strings.Join(strings.Fields(line), " "); line != "" {
+			paragraphs = append(paragraphs, line)
+		}
+	}
+	return strings.Join(paragraphs, "\n\n")
+}
+
+// Truncate shortens s to at most n characters, cutting at the end of a word
+// and marking the cut with an ellipsis. Returns s unchanged if it fits or n
+// is zero or negative.
+func Truncate(s string, n int) string {
+	if n <= 0 || utf8.RuneCountInString(s) <= n {
+		return s
+	}
+
+	// Leave room for the ellipsis, then back up to the last word boundary,
+	// unless the cut falls on one or backing up would drop most of the text.
+	all := []rune(s)
+	runes := all[:n-1]
+	if i := lastSpace(runes); !unicode.IsSpace(all[n-1]) && i > len(runes)/2 {
+		runes = runes[:i]
+	}
+	return strings.TrimRightFunc(string(runes), func(r rune) bool {
+		return unicode.IsSpace(r) || unicode.IsPunct(r)
+	}) + ellipsis
+}
+
+// lastSpace returns the index of the last whitespace in runes, or -1.
+func lastSpace(runes []rune) int {
+	for i := len(runes) - 1; i >= 0; i-- {
+		if unicode.IsSpace(runes[i]) {
+			return i
+		}
+	}
+	return -1
+}
+
+// Finder wraps a BookFinder and truncates the descriptions of its results.
+type Finder struct {
+	finder bookid.BookFinder
+
+	// Longest description kept, in characters. Zero keeps descriptions
+	// whole.
+	MaxLength int
+}
+
+// NewFinder returns a Finder truncating the descriptions of finder's results
+// to maxLength characters.
+func NewFinder(finder bookid.BookFinder, maxLength int) *Finder {
+	return &Finder{finder: finder, MaxLength: maxLength}
+}
+
+// Search searches the wrapped finder and truncates the descriptions of the
+// results.
+func (f *Finder) Search(ctx context.Context, query string, opts bookid.SearchOptions) ([]bookid.BookResult, error) {
+	results, err := f.finder.Search(ctx, query, opts)
+	if err != nil {
+		return nil, err
+	}
+	other := make([]bookid.BookResult, 0, len(results))
+	for _, r := range results {
+		r.Description = Truncate(r.Description, f.MaxLength)
+		other = append(other, r)
+	}
+	return other, nil
+}
@@ -0,0 +1,89 @@
+package description_test
+
+import (
+	"context"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/description"
+	"github.com/fwojciec/bookid/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClean(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"plain", "A novel of the Jazz Age.", "A novel of the Jazz Age."},
+		{"empty", "", ""},
+		{"inline_tags", "<b>Winner</b> of the <i>Audie</i> Award", "Winner of the Audie Award"},
+		{"paragraphs", "<p>First  paragraph.</p><p>Second<br>line.</p>", "First paragraph.\n\nSecond\n\nline."},
+		{"entities", "Tom &amp; Daisy&#39;s &quot;story&quot;", `Tom & Daisy's "story"`},
+		{"whitespace", "  Spread\n\n\n  over   lines  ", "Spread\n\nover lines"},
+		{"pre_is_not_paragraph", "a<pre>b</pre>c", "abc"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.want, description.Clean(tt.in))
+		})
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	t.Parallel()
+
+	const s = "In my younger and more vulnerable years my father gave me some advice."
+
+	t.Run("fits", func(t *testing.T) {
+		t.Parallel()
+		assert.Equal(t, s, description.Truncate(s, len(s)))
+		assert.Equal(t, s, description.Truncate(s, 0), "zero keeps the text whole")
+	})
+
+	t.Run("word_boundary", func(t *testing.T) {
+		t.Parallel()
+		got := description.Truncate(s, 30)
+		assert.Equal(t, "In my younger and more…", got)
+		assert.LessOrEqual(t, utf8.RuneCountInString(got), 30)
+	})
+
+	t.Run("trailing_punctuation", func(t *testing.T) {
+		t.Parallel()
+		assert.Equal(t, "One, two…", description.Truncate("One, two, three, four", 11))
+	})
+
+	t.Run("long_word", func(t *testing.T) {
+		t.Parallel()
+		assert.Equal(t, "Pneumonoul…", description.Truncate("Pneumonoultramicroscopic", 11))
+	})
+
+	t.Run("multibyte", func(t *testing.T) {
+		t.Parallel()
+		got := description.Truncate("Zażółć gęślą jaźń i tak dalej", 14)
+		assert.Equal(t, "Zażółć gęślą…", got)
+	})
+}
+
+func TestFinder_Search(t *testing.T) {
+	t.Parallel()
+
+	results := []bookid.BookResult{{Title: "Dune", Description: "A desert planet and the spice that rules it."}}
+	finder := description.NewFinder(&mock.BookFinder{
+		SearchFn: func(context.Context, string, bookid.SearchOptions) ([]bookid.BookResult, error) {
+			return results, nil
+		},
+	}, 20)
+
+	got, err := finder.Search(context.Background(), "dune", bookid.SearchOptions{})
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.Equal(t, "A desert planet and…", got[0].Description)
+	assert.Equal(t, "A desert planet and the spice that rules it.", results[0].Description, "results of the wrapped finder are unchanged")
+}
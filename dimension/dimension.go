@@ -0,0 +1,111 @@
+// Package dimension parses the sizes and weights of publications as given by
+// providers, such as "24.00 cm", "8.2 x 5.4 x 0.6 inches" or "0.4 Pounds",
+// into millimeters and grams.
+package dimension
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Length returns the length s gives, such as "24 cm" or "8.25 Inches", in
+// millimeters. Returns zero if s has no number or no known unit.
+func Length(s string) float64 {
+	n, unit, ok := split(s)
+	if !ok {
+		return 0
+	}
+	return convert(n, lengthUnits()[unit])
+}
+
+// Lengths returns the lengths of a size such as "8.2 x 5.4 x 0.6 inches" in
+// millimeters, in the order given. Lengths without a unit take that of the
+// last length. Returns nil if any length cannot be parsed.
+func Lengths(s string) []float64 {
+	parts := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool { return r == 'x' || r == '×' })
+	if len(parts) == 0 {
+		return nil
+	}
+	_, last, ok := split(parts[len(parts)-1])
+	if !ok {
+		return nil
+	}
+
+	lengths := make([]float64, len(parts))
+	for i, part := range parts {
+		n, unit, ok := split(part)
+		if !ok {
+			return nil
+		} else if unit == "" {
+			unit = last
+		}
+		if lengths[i] = convert(n, lengthUnits()[unit]); lengths[i] == 0 {
+			return nil
+		}
+	}
+	return lengths
+}
+
+// Weight returns the weight s gives, such as "300 g" or "0.4 Pounds", in
+// grams. Returns zero if s has no number or no known unit.
+func Weight(s string) float64 {
+	n, unit, ok := split(s)
+	if !ok {
+		return 0
+	}
+	return convert(n, weightUnits()[unit])
+}
+
+// split returns the number s starts with and the unit following it,
+// lowercased. A decimal comma is read as a point.
+func split(s string) (float64, string, bool) {
+	s = strings.TrimSpace(strings.ToLower(s))
+	i := strings.IndexFunc(s, func(r rune) bool { return !unicode.IsDigit(r) && r != '.' && r != ',' })
+	if i < 0 {
+		i = len(s)
+	}
+	n, err := strconv.ParseFloat(strings.ReplaceAll(s[:i], ",", "."), 64)
+	if err != nil || n <= 0 {
+		return 0, "", false
+	}
+	return n, strings.TrimRight(strings.TrimSpace(s[i:]), "."), true
+}
+
+// convert returns n in a unit of the given size, rounded to a tenth of the
+// base unit. Returns zero for unknown units, whose size is zero.
+func convert(n, size float64) float64 {
+	return math.Round(n*size*10) / 10
+}
+
+// lengthUnits returns the size of each length unit in millimeters.
+func lengthUnits() map[string]float64 {
+	return map[string]float64{
+		"mm":     1,
+		"cm":     10,
+		"m":      1000,
+		"in":     25.4,
+		"inch":   25.4,
+		"inches": 25.4,
+		`"`:      25.4,
+	}
+}
+
+// weightUnits returns the size of each weight unit in grams.
+func weightUnits() map[string]float64 {
+	return map[string]float64{
+		"g":      1,
+		"gr":     1,
+		"gram":   1,
+		"grams":  1,
+		"kg":     1000,
+		"oz":     28.3495,
+		"ounce":  28.3495,
+		"ounces": 28.3495,
+		"lb":     453.592,
+		"lbs":    453.592,
+		"pound":  453.592,
+		"pounds": 453.592,
+	}
+}
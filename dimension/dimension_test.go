@@ -0,0 +1,56 @@
+package dimension_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/fwojciec/bookid/dimension"
+)
+
+func TestLength(t *testing.T) {
+	t.Parallel()
+
+	for s, want := range map[string]float64{
+		"24.00 cm":    240,
+		"8.25 Inches": 209.6,
+		"210mm":       210,
+		"5,5 cm":      55,
+		`9"`:          228.6,
+		"24":          0, // no unit
+		"24 parsecs":  0,
+		"":            0,
+	} {
+		if got := dimension.Length(s); got != want {
+			t.Errorf("Length(%q)=%v, want %v", s, got, want)
+		}
+	}
+}
+
+func TestLengths(t *testing.T) {
+	t.Parallel()
+
+	if got, want := dimension.Lengths("8.2 x 5.4 x 0.6 inches"), []float64{208.3, 137.2, 15.2}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("Lengths()=%v, want %v", got, want)
+	} else if got, want := dimension.Lengths("20 cm × 130 mm"), []float64{200, 130}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("Lengths()=%v, want %v", got, want)
+	} else if got := dimension.Lengths("8.2 x 5.4 x 0.6"); got != nil {
+		t.Fatalf("Lengths()=%v, want nil", got)
+	}
+}
+
+func TestWeight(t *testing.T) {
+	t.Parallel()
+
+	for s, want := range map[string]float64{
+		"0.4 Pounds":  181.4,
+		"6.4 ounces":  181.4,
+		"300 g":       300,
+		"1.2 kg":      1200,
+		"heavy":       0,
+		"12 furlongs": 0,
+	} {
+		if got := dimension.Weight(s); got != want {
+			t.Errorf("Weight(%q)=%v, want %v", s, got, want)
+		}
+	}
+}
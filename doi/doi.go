@@ -0,0 +1,44 @@
+// Package doi detects and normalizes Digital Object Identifiers.
+package doi
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Prefix marks a query as a DOI, e.g. "doi:10.1017/9781108555807".
+const Prefix = "doi:"
+
+// pattern matches a DOI: the "10." directory indicator, a registrant code
+// and a suffix that runs up to the next whitespace.
+var pattern = regexp.MustCompile(`^10\.\d{4,9}/\S+$`)
+
+// Normalize removes a "doi:" prefix or doi.org resolver URL and lowercases
+// the DOI, as DOIs are case-insensitive. It does not validate the result.
+func Normalize(s string) string {
+	s = strings.TrimSpace(s)
+	lower := strings.ToLower(s)
+	for _, prefix := range []string{Prefix, "https://doi.org/", "http://doi.org/", "https://dx.doi.org/", "http://dx.doi.org/"} {
+		if strings.HasPrefix(lower, prefix) {
+			lower = lower[len(prefix):]
+			break
+		}
+	}
+	return strings.TrimRight(strings.TrimSpace(lower), ".,;")
+}
+
+// Valid returns true if s is a normalized DOI.
+func Valid(s string) bool {
+	return pattern.MatchString(s)
+}
+
+// Parse returns the normalized DOI in query if the whole query is a DOI,
+// optionally marked with the "doi:" prefix or given as a doi.org URL.
+// Returns false otherwise.
+func Parse(query string) (string, bool) {
+	s := Normalize(query)
+	if !Valid(s) {
+		return "", false
+	}
+	return s, true
+}
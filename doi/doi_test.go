@@ -0,0 +1,28 @@
+package doi_test
+
+import (
+	"testing"
+
+	"github.com/fwojciec/bookid/doi"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	for in, want := range map[string]string{
+		"10.1017/9781108555807":                             "10.1017/9781108555807",
+		"DOI: 10.1007/978-3-030-12345-6":                    "10.1007/978-3-030-12345-6",
+		"https://doi.org/10.7551/MITPRESS/1234.001.0001":    "10.7551/mitpress/1234.001.0001",
+		" http://dx.doi.org/10.1093/oso/9780198.001.0001. ": "10.1093/oso/9780198.001.0001",
+	} {
+		got, ok := doi.Parse(in)
+		assert.True(t, ok, in)
+		assert.Equal(t, want, got, in)
+	}
+
+	for _, in := range []string{"", "the great gatsby", "10.1/short", "11.1017/9781108555807", "see 10.1017/9781108555807"} {
+		_, ok := doi.Parse(in)
+		assert.False(t, ok, in)
+	}
+}
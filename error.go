@@ -16,7 +16,9 @@ const (
 	EINVALID        = "invalid"
 	ENOTFOUND       = "not_found"
 	ENOTIMPLEMENTED = "not_implemented"
+	ERATELIMIT      = "rate_limit"
 	EUNAUTHORIZED   = "unauthorized"
+	EUNAVAILABLE    = "unavailable"
 )
 
 // Error represents an application-specific error. Application errors can be
@@ -0,0 +1,75 @@
+package bookid
+
+import "context"
+
+// Event types published when the catalog changes.
+const (
+	EventTypeWorkCreated        = "work:created"
+	EventTypePublicationCreated = "publication:created"
+	EventTypeWorkMerged         = "work:merged"
+)
+
+// Event represents a change to the catalog. Events are published once the
+// change is committed, so subscribers such as webhooks, cache invalidation
+// and audit logging never see changes that were rolled back.
+type Event struct {
+	// Specifies the type of event that is occurring.
+	Type string `json:"type"`
+
+	// The actual data of the event, one of the payload types below matching
+	// the event type.
+	Payload any `json:"payload"`
+}
+
+// WorkCreatedPayload is the payload of a EventTypeWorkCreated event.
+type WorkCreatedPayload struct {
+	Work *Work `json:"work"`
+}
+
+// PublicationCreatedPayload is the payload of a EventTypePublicationCreated
+// event. It is not published when an existing publication is refreshed.
+type PublicationCreatedPayload struct {
+	Publication *Publication `json:"publication"`
+}
+
+// WorkMergedPayload is the payload of a EventTypeWorkMerged event. The
+// source works no longer exist; their publications, author links and
+// relations now belong to the target.
+type WorkMergedPayload struct {
+	TargetID  int64   `json:"target_id"`
+	SourceIDs []int64 `json:"source_ids"`
+}
+
+// EventService represents a service for publishing catalog events to
+// subscribers.
+type EventService interface {
+	// PublishEvent publishes an event to all current subscribers. It does
+	// not block on slow subscribers.
+	PublishEvent(event Event)
+
+	// Subscribe creates a subscription to all future events. The
+	// subscription is closed when ctx is done or Close is called.
+	Subscribe(ctx context.Context) (Subscription, error)
+}
+
+// Subscription represents a stream of events.
+type Subscription interface {
+	// C returns the event stream. It is closed when the subscription is.
+	C() <-chan Event
+
+	// Close unsubscribes and closes the event stream.
+	Close() error
+}
+
+// NopEventService returns an event service that discards published events
+// and does not support subscriptions.
+func NopEventService() EventService { return nopEventService{} }
+
+// nopEventService implements a no-op EventService.
+type nopEventService struct{}
+
+func (nopEventService) PublishEvent(Event) {}
+
+func (nopEventService) Subscribe(context.Context) (Subscription, error) {
+	return nil, Errorf(ENOTIMPLEMENTED, "Event subscriptions are not supported.")
+}
@@ -0,0 +1,110 @@
+// Package fallback implements a BookFinder decorator that searches providers
+// one at a time in order of preference, moving to the next only when a
+// provider fails or finds nothing.
+package fallback
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/fwojciec/bookid"
+)
+
+// Ensure type implements interface.
+var _ bookid.BookFinder = (*FallbackFinder)(nil)
+
+// Provider represents a provider searched by a FallbackFinder.
+type Provider struct {
+	Name   string
+	Finder bookid.BookFinder
+
+	// Longest time a search of the provider may take before moving on to the
+	// next one. Zero gives the provider an equal share of the time left
+	// before the deadline among it and the providers after it, or no limit if
+	// there is no deadline.
+	Timeout time.Duration
+}
+
+// FallbackFinder searches providers sequentially, most preferred first, and
+// returns the results of the first one that finds anything. Unlike fanning
+// out to all providers at once, the provider answering a query is
+// deterministic and less preferred providers are only queried when needed.
+type FallbackFinder struct {
+	providers []Provider
+
+	// Longest time a whole search may take, shared among the providers.
+	// Zero leaves only the deadline of the caller's context.
+	Timeout time.Duration
+
+	// Receives debug logs of each provider's outcome and latency. Defaults
+	// to discarding them.
+	Logger *slog.Logger
+
+	// Returns the current time. Defaults to time.Now().
+	// Can be mocked for tests.
+	Now func() time.Time
+}
+
+// NewFallbackFinder returns a FallbackFinder searching providers in order.
+func NewFallbackFinder(providers ...Provider) *FallbackFinder {
+	return &FallbackFinder{
+		providers: providers,
+		Logger:    slog.New(slog.DiscardHandler),
+		Now:       time.Now,
+	}
+}
+
+// Search searches each provider in turn until one returns results. A provider
+// that fails or runs out of time is skipped. Returns no results and no error
+// if at least one provider answered without finding anything, and the last
+// provider's error if every provider failed. Stops early, returning the
+// context's error, when ctx is done.
+func (f *FallbackFinder) Search(ctx context.Context, query string, opts bookid.SearchOptions) ([]bookid.BookResult, error) {
+	if f.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, f.Timeout)
+		defer cancel()
+	}
+
+	var lastErr error
+	answered := false
+	for i, p := range f.providers {
+		results, err := f.search(ctx, p, len(f.providers)-i, query, opts)
+		if err == nil && len(results) > 0 {
+			return results, nil
+		} else if ctx.Err() != nil {
+			return nil, ctx.Err()
+		} else if err != nil {
+			lastErr = fmt.Errorf("searching %s: %w", p.Name, err)
+			continue
+		}
+		answered = true
+	}
+
+	if answered || lastErr == nil {
+		return nil, nil
+	}
+	return nil, lastErr
+}
+
+// search searches a single provider within its time budget. remaining is the
+// number of providers left to search, including p.
+func (f *FallbackFinder) search(ctx context.Context, p Provider, remaining int, query string, opts bookid.SearchOptions) ([]bookid.BookResult, error) {
+	budget := p.Timeout
+	if deadline, ok := ctx.Deadline(); ok && budget == 0 {
+		budget = deadline.Sub(f.Now()) / time.Duration(remaining)
+	}
+	if budget > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, budget)
+		defer cancel()
+	}
+
+	start := f.Now()
+	results, err := p.Finder.Search(ctx, query, opts)
+	f.Logger.DebugContext(ctx, "searched provider",
+		"provider", p.Name, "budget", budget, "duration", f.Now().Sub(start), "results", len(results), "error", err)
+	return results, err
+}
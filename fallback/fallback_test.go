@@ -0,0 +1,137 @@
+package fallback_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/fallback"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubFinder returns fixed results or an error and records its calls and the
+// time left before the deadline of each search.
+type stubFinder struct {
+	results []bookid.BookResult
+	err     error
+	block   bool // Wait until the context is done.
+
+	calls    int
+	deadline time.Duration
+}
+
+func (f *stubFinder) Search(ctx context.Context, _ string, _ bookid.SearchOptions) ([]bookid.BookResult, error) {
+	f.calls++
+	if deadline, ok := ctx.Deadline(); ok {
+		f.deadline = time.Until(deadline)
+	}
+	if f.block {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	return f.results, f.err
+}
+
+func found(title string) *stubFinder {
+	return &stubFinder{results: []bookid.BookResult{{Title: title}}}
+}
+
+func TestFallbackFinder_Search(t *testing.T) {
+	t.Parallel()
+
+	t.Run("FirstWithResults", func(t *testing.T) {
+		t.Parallel()
+		empty, failing, first, second := &stubFinder{}, &stubFinder{err: errors.New("boom")}, found("Dune"), found("Emma")
+		f := fallback.NewFallbackFinder(
+			fallback.Provider{Name: "empty", Finder: empty},
+			fallback.Provider{Name: "failing", Finder: failing},
+			fallback.Provider{Name: "first", Finder: first},
+			fallback.Provider{Name: "second", Finder: second},
+		)
+
+		results, err := f.Search(context.Background(), "dune", bookid.SearchOptions{})
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		assert.Equal(t, "Dune", results[0].Title)
+		assert.Equal(t, []int{1, 1, 1, 0}, []int{empty.calls, failing.calls, first.calls, second.calls})
+	})
+
+	t.Run("NoResults", func(t *testing.T) {
+		t.Parallel()
+		f := fallback.NewFallbackFinder(
+			fallback.Provider{Name: "failing", Finder: &stubFinder{err: errors.New("boom")}},
+			fallback.Provider{Name: "empty", Finder: &stubFinder{}},
+		)
+
+		results, err := f.Search(context.Background(), "dune", bookid.SearchOptions{})
+		require.NoError(t, err)
+		assert.Empty(t, results)
+	})
+
+	t.Run("AllFailed", func(t *testing.T) {
+		t.Parallel()
+		f := fallback.NewFallbackFinder(
+			fallback.Provider{Name: "first", Finder: &stubFinder{err: errors.New("boom")}},
+			fallback.Provider{Name: "second", Finder: &stubFinder{err: bookid.Errorf(bookid.ERATELIMIT, "Slow down.")}},
+		)
+
+		_, err := f.Search(context.Background(), "dune", bookid.SearchOptions{})
+		require.Error(t, err)
+		assert.Equal(t, bookid.ERATELIMIT, bookid.ErrorCode(err))
+		assert.Contains(t, err.Error(), "searching second")
+	})
+
+	t.Run("ProviderTimeout", func(t *testing.T) {
+		t.Parallel()
+		slow, next := &stubFinder{block: true}, found("Dune")
+		f := fallback.NewFallbackFinder(
+			fallback.Provider{Name: "slow", Finder: slow, Timeout: 10 * time.Millisecond},
+			fallback.Provider{Name: "next", Finder: next},
+		)
+
+		results, err := f.Search(context.Background(), "dune", bookid.SearchOptions{})
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		assert.Equal(t, 1, slow.calls)
+		assert.LessOrEqual(t, slow.deadline, 10*time.Millisecond)
+	})
+
+	t.Run("SharedBudget", func(t *testing.T) {
+		t.Parallel()
+		// Without provider timeouts, each provider gets an equal share of
+		// the time left, and time unused by one carries over to the next.
+		now := time.Now()
+		first, second, third := &stubFinder{}, &stubFinder{}, found("Dune")
+		f := fallback.NewFallbackFinder(
+			fallback.Provider{Name: "first", Finder: first},
+			fallback.Provider{Name: "second", Finder: second},
+			fallback.Provider{Name: "third", Finder: third},
+		)
+		f.Timeout = 3 * time.Hour
+		f.Now = func() time.Time { return now }
+
+		_, err := f.Search(context.Background(), "dune", bookid.SearchOptions{})
+		require.NoError(t, err)
+		assert.InDelta(t, time.Hour, first.deadline, float64(time.Minute))
+		assert.InDelta(t, 3*time.Hour/2, second.deadline, float64(time.Minute))
+		assert.InDelta(t, 3*time.Hour, third.deadline, float64(time.Minute))
+	})
+
+	t.Run("Canceled", func(t *testing.T) {
+		t.Parallel()
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		next := found("Dune")
+		f := fallback.NewFallbackFinder(
+			fallback.Provider{Name: "slow", Finder: &stubFinder{block: true}},
+			fallback.Provider{Name: "next", Finder: next},
+		)
+
+		_, err := f.Search(ctx, "dune", bookid.SearchOptions{})
+		require.ErrorIs(t, err, context.Canceled)
+		assert.Zero(t, next.calls)
+	})
+}
@@ -0,0 +1,239 @@
+// Package goodreads reads and writes the Goodreads library CSV format, which
+// is also accepted by StoryGraph and most other reading trackers.
+package goodreads
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fwojciec/bookid"
+)
+
+// DateFormat is the date layout used by Goodreads exports.
+const DateFormat = "2006/01/02"
+
+// Exclusive shelves recognized by Goodreads. Every book sits on exactly one.
+const (
+	ShelfRead             = "read"
+	ShelfCurrentlyReading = "currently-reading"
+	ShelfToRead           = "to-read"
+	ShelfDidNotFinish     = "did-not-finish" // As exported by StoryGraph
+)
+
+// Header returns the column layout of a Goodreads library export.
+func Header() []string {
+	return []string{
+		"Book Id",
+		"Title",
+		"Author",
+		"Author l-f",
+		"Additional Authors",
+		"ISBN",
+		"ISBN13",
+		"My Rating",
+		"Average Rating",
+		"Publisher",
+		"Binding",
+		"Number of Pages",
+		"Year Published",
+		"Original Publication Year",
+		"Date Read",
+		"Date Added",
+		"Bookshelves",
+		"Bookshelves with positions",
+		"Exclusive Shelf",
+		"My Review",
+		"Spoiler",
+		"Private Notes",
+		"Read Count",
+		"Owned Copies",
+	}
+}
+
+// Book represents a single row of a Goodreads library file.
+type Book struct {
+	Title                   string
+	Author                  string
+	AdditionalAuthors       []string
+	ISBN                    string
+	ISBN13                  string
+	MyRating                int // 1-5, zero if unrated
+	Publisher               string
+	Binding                 string
+	NumberOfPages           int
+	YearPublished           int
+	OriginalPublicationYear int
+	DateRead                time.Time
+	DateAdded               time.Time
+	Bookshelves             []string // Non-exclusive shelves (collections, tags)
+	ExclusiveShelf          string   // One of read, currently-reading, to-read, did-not-finish
+	MyReview                string
+	PrivateNotes            string
+}
+
+// NewBook returns a Book populated from a catalog work and one of its
+// publications, including the rating, notes and reading status recorded on
+// the publication. The read date and non-exclusive shelves are left for the
+// caller to fill in.
+func NewBook(work *bookid.Work, pub *bookid.Publication) *Book {
+	b := &Book{
+		Title:     work.Title,
+		Author:    work.Author,
+		DateAdded: work.CreatedAt,
+	}
+	if pub != nil {
+		b.ISBN = pub.ISBN10
+		b.ISBN13 = pub.ISBN13
+		b.Publisher = pub.Publisher
+		b.YearPublished = pub.PublishedYear
+		b.NumberOfPages = pub.PageCount
+		b.Binding = bindingLabel(pub.Binding)
+		b.MyRating = pub.Rating
+		b.PrivateNotes = pub.Notes
+		b.ExclusiveShelf = exclusiveShelf(pub.ReadingStatus)
+	}
+	return b
+}
+
+// exclusiveShelf returns the exclusive shelf of a reading status, or an empty
+// string if there is none.
+func exclusiveShelf(s bookid.ReadingStatus) string {
+	switch s {
+	case bookid.ReadingStatusWantToRead:
+		return ShelfToRead
+	case bookid.ReadingStatusReading:
+		return ShelfCurrentlyReading
+	case bookid.ReadingStatusRead:
+		return ShelfRead
+	case bookid.ReadingStatusAbandoned:
+		return ShelfDidNotFinish
+	default:
+		return ""
+	}
+}
+
+// bindingLabel returns the Goodreads label of a binding, or an empty string
+// if it is unknown.
+func bindingLabel(b bookid.Binding) string {
+	switch b {
+	case bookid.BindingHardcover:
+		return "Hardcover"
+	case bookid.BindingPaperback:
+		return "Paperback"
+	case bookid.BindingEbook:
+		return "ebook"
+	case bookid.BindingAudiobook:
+		return "Audiobook"
+	default:
+		return ""
+	}
+}
+
+// Writer writes books as Goodreads CSV rows.
+type Writer struct {
+	w           *csv.Writer
+	wroteHeader bool
+}
+
+// NewWriter returns a new Writer that writes to w.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: csv.NewWriter(w)}
+}
+
+// Write writes a single book, preceded by the header row on first use.
+func (w *Writer) Write(b *Book) error {
+	if !w.wroteHeader {
+		if err := w.w.Write(Header()); err != nil {
+			return err
+		}
+		w.wroteHeader = true
+	}
+
+	exclusiveShelf := b.ExclusiveShelf
+	if exclusiveShelf == "" {
+		exclusiveShelf = ShelfToRead
+		if !b.DateRead.IsZero() {
+			exclusiveShelf = ShelfRead
+		}
+	}
+
+	readCount := 0
+	if exclusiveShelf == ShelfRead {
+		readCount = 1
+	}
+
+	return w.w.Write([]string{
+		"",
+		b.Title,
+		b.Author,
+		authorLastFirst(b.Author),
+		strings.Join(b.AdditionalAuthors, ", "),
+		formatISBN(b.ISBN),
+		formatISBN(b.ISBN13),
+		strconv.Itoa(b.MyRating),
+		"",
+		b.Publisher,
+		b.Binding,
+		formatInt(b.NumberOfPages),
+		formatInt(b.YearPublished),
+		formatInt(b.OriginalPublicationYear),
+		formatDate(b.DateRead),
+		formatDate(b.DateAdded),
+		strings.Join(b.Bookshelves, ", "),
+		"",
+		exclusiveShelf,
+		b.MyReview,
+		"",
+		b.PrivateNotes,
+		strconv.Itoa(readCount),
+		"0",
+	})
+}
+
+// Flush writes any buffered data to the underlying writer. If no books were
+// written, the header row is still emitted so the file is importable.
+func (w *Writer) Flush() error {
+	if !w.wroteHeader {
+		if err := w.w.Write(Header()); err != nil {
+			return err
+		}
+		w.wroteHeader = true
+	}
+	w.w.Flush()
+	return w.w.Error()
+}
+
+// formatISBN wraps an ISBN in the ="..." spreadsheet formula Goodreads uses
+// so leading zeros survive a round-trip through spreadsheet software.
+func formatISBN(isbn string) string {
+	return `="` + isbn + `"`
+}
+
+// formatInt returns an empty string for zero values.
+func formatInt(v int) string {
+	if v == 0 {
+		return ""
+	}
+	return strconv.Itoa(v)
+}
+
+// formatDate returns an empty string for zero times.
+func formatDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(DateFormat)
+}
+
+// authorLastFirst converts "F. Scott Fitzgerald" to "Fitzgerald, F. Scott".
+func authorLastFirst(name string) string {
+	name = strings.TrimSpace(name)
+	i := strings.LastIndex(name, " ")
+	if i == -1 || strings.Contains(name, ",") {
+		return name
+	}
+	return name[i+1:] + ", " + name[:i]
+}
@@ -0,0 +1,168 @@
+package goodreads_test
+
+import (
+	"bytes"
+	"encoding/csv"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/goodreads"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriter(t *testing.T) {
+	t.Parallel()
+
+	t.Run("write book", func(t *testing.T) {
+		t.Parallel()
+		var buf bytes.Buffer
+		w := goodreads.NewWriter(&buf)
+
+		book := goodreads.NewBook(
+			&bookid.Work{Title: "The Great Gatsby", Author: "F. Scott Fitzgerald", CreatedAt: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)},
+			&bookid.Publication{ISBN10: "0743273567", ISBN13: "9780743273565", Publisher: "Scribner", PublishedYear: 2004, Binding: bookid.BindingPaperback},
+		)
+		book.MyRating = 5
+		book.Bookshelves = []string{"classics", "favorites"}
+		book.DateRead = time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC)
+		require.NoError(t, w.Write(book))
+		require.NoError(t, w.Flush())
+
+		records, err := csv.NewReader(&buf).ReadAll()
+		require.NoError(t, err)
+		require.Len(t, records, 2)
+		assert.Equal(t, goodreads.Header(), records[0])
+
+		row := make(map[string]string)
+		for i, name := range records[0] {
+			row[name] = records[1][i]
+		}
+		assert.Equal(t, "The Great Gatsby", row["Title"])
+		assert.Equal(t, "Fitzgerald, F. Scott", row["Author l-f"])
+		assert.Equal(t, `="0743273567"`, row["ISBN"])
+		assert.Equal(t, `="9780743273565"`, row["ISBN13"])
+		assert.Equal(t, "5", row["My Rating"])
+		assert.Equal(t, "2004", row["Year Published"])
+		assert.Equal(t, "Paperback", row["Binding"])
+		assert.Equal(t, "2024/03/04", row["Date Read"])
+		assert.Equal(t, "2024/01/02", row["Date Added"])
+		assert.Equal(t, "classics, favorites", row["Bookshelves"])
+		assert.Equal(t, goodreads.ShelfRead, row["Exclusive Shelf"])
+		assert.Equal(t, "1", row["Read Count"])
+	})
+
+	t.Run("personal fields", func(t *testing.T) {
+		t.Parallel()
+		for status, want := range map[bookid.ReadingStatus]string{
+			"":                             "",
+			bookid.ReadingStatusWantToRead: goodreads.ShelfToRead,
+			bookid.ReadingStatusReading:    goodreads.ShelfCurrentlyReading,
+			bookid.ReadingStatusRead:       goodreads.ShelfRead,
+			bookid.ReadingStatusAbandoned:  goodreads.ShelfDidNotFinish,
+		} {
+			book := goodreads.NewBook(&bookid.Work{Title: "Dune"}, &bookid.Publication{PageCount: 412, ReadingStatus: status, Rating: 4, Notes: "Signed"})
+			assert.Equal(t, want, book.ExclusiveShelf, status)
+			assert.Equal(t, 4, book.MyRating)
+			assert.Equal(t, 412, book.NumberOfPages)
+			assert.Equal(t, "Signed", book.PrivateNotes)
+		}
+	})
+
+	t.Run("unread books default to to-read", func(t *testing.T) {
+		t.Parallel()
+		var buf bytes.Buffer
+		w := goodreads.NewWriter(&buf)
+		require.NoError(t, w.Write(&goodreads.Book{Title: "Dune", Author: "Frank Herbert"}))
+		require.NoError(t, w.Flush())
+
+		records, err := csv.NewReader(&buf).ReadAll()
+		require.NoError(t, err)
+		require.Len(t, records, 2)
+		assert.Equal(t, goodreads.ShelfToRead, records[1][18])
+	})
+
+	t.Run("empty export still has header", func(t *testing.T) {
+		t.Parallel()
+		var buf bytes.Buffer
+		require.NoError(t, goodreads.NewWriter(&buf).Flush())
+
+		records, err := csv.NewReader(&buf).ReadAll()
+		require.NoError(t, err)
+		require.Len(t, records, 1)
+		assert.Equal(t, goodreads.Header(), records[0])
+	})
+}
+
+func TestReader(t *testing.T) {
+	t.Parallel()
+
+	t.Run("round trip", func(t *testing.T) {
+		t.Parallel()
+		var buf bytes.Buffer
+		w := goodreads.NewWriter(&buf)
+		want := &goodreads.Book{
+			Title:             "Good Omens",
+			Author:            "Terry Pratchett",
+			AdditionalAuthors: []string{"Neil Gaiman"},
+			ISBN:              "0060853980",
+			ISBN13:            "9780060853983",
+			MyRating:          4,
+			Publisher:         "William Morrow",
+			NumberOfPages:     432,
+			YearPublished:     2006,
+			DateRead:          time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC),
+			Bookshelves:       []string{"fantasy", "humor"},
+			ExclusiveShelf:    goodreads.ShelfRead,
+		}
+		require.NoError(t, w.Write(want))
+		require.NoError(t, w.Flush())
+
+		r := goodreads.NewReader(&buf)
+		got, err := r.Read()
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+		assert.Equal(t, 2, r.Line())
+
+		_, err = r.Read()
+		assert.ErrorIs(t, err, io.EOF)
+	})
+
+	t.Run("storygraph export", func(t *testing.T) {
+		t.Parallel()
+		r := goodreads.NewReader(strings.NewReader("\ufeff" +
+			"Title,Authors,Contributors,ISBN/UID,Format,Read Status,Date Added,Last Date Read,Star Rating,Review,Tags\n" +
+			"Solaris,Stanisław Lem,,9780156027601,paperback,read,2023/05/01,2023-06-12,4.5,,\"sci-fi, classics\"\n"))
+
+		got, err := r.Read()
+		require.NoError(t, err)
+		assert.Equal(t, "Solaris", got.Title)
+		assert.Equal(t, "Stanisław Lem", got.Author)
+		assert.Empty(t, got.ISBN)
+		assert.Equal(t, "9780156027601", got.ISBN13)
+		assert.Equal(t, "paperback", got.Binding)
+		assert.Equal(t, goodreads.ShelfRead, got.ExclusiveShelf)
+		assert.Equal(t, time.Date(2023, 6, 12, 0, 0, 0, 0, time.UTC), got.DateRead)
+		assert.Equal(t, 5, got.MyRating)
+		assert.Equal(t, []string{"sci-fi", "classics"}, got.Bookshelves)
+	})
+
+	t.Run("invalid value", func(t *testing.T) {
+		t.Parallel()
+		r := goodreads.NewReader(strings.NewReader("Title,Number of Pages\nDune,many\n"))
+
+		_, err := r.Read()
+		assert.ErrorContains(t, err, "goodreads: line 2: Number of Pages")
+	})
+
+	t.Run("missing title column", func(t *testing.T) {
+		t.Parallel()
+		r := goodreads.NewReader(strings.NewReader("Name,Author\nDune,Frank Herbert\n"))
+
+		_, err := r.Read()
+		assert.ErrorContains(t, err, "missing Title column")
+	})
+}
@@ -0,0 +1,192 @@
+package goodreads
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// columnAliases maps the column names of other trackers' exports to their
+// Goodreads equivalents. StoryGraph uses ISBN/UID for either ISBN.
+func columnAliases() map[string]string {
+	return map[string]string{
+		"ISBN/UID":       "ISBN",
+		"Star Rating":    "My Rating",
+		"Read Status":    "Exclusive Shelf",
+		"Last Date Read": "Date Read",
+		"Tags":           "Bookshelves",
+		"Review":         "My Review",
+		"Format":         "Binding",
+	}
+}
+
+// Reader reads books from a Goodreads library export or a StoryGraph export,
+// which uses different column names for the same data.
+type Reader struct {
+	r       *csv.Reader
+	columns map[string]int
+}
+
+// NewReader returns a new Reader that reads from r.
+func NewReader(r io.Reader) *Reader {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	return &Reader{r: cr}
+}
+
+// Line returns the line number of the last row returned by Read.
+func (r *Reader) Line() int {
+	line, _ := r.r.FieldPos(0)
+	return line
+}
+
+// Read returns the next book. It returns io.EOF when there are no more rows.
+// Rows are matched to columns by name, so the column order does not matter.
+func (r *Reader) Read() (*Book, error) {
+	if r.columns == nil {
+		if err := r.readHeader(); err != nil {
+			return nil, err
+		}
+	}
+
+	record, err := r.r.Read()
+	if err != nil {
+		return nil, err
+	}
+	get := func(name string) string {
+		if i, ok := r.columns[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	b := &Book{
+		Title:          get("Title"),
+		Publisher:      get("Publisher"),
+		Binding:        get("Binding"),
+		ExclusiveShelf: get("Exclusive Shelf"),
+		MyReview:       get("My Review"),
+		PrivateNotes:   get("Private Notes"),
+	}
+
+	// StoryGraph lists all authors in a single column.
+	b.Author = get("Author")
+	if authors := splitList(get("Authors")); b.Author == "" && len(authors) > 0 {
+		b.Author, b.AdditionalAuthors = authors[0], authors[1:]
+	}
+	b.AdditionalAuthors = append(b.AdditionalAuthors, splitList(get("Additional Authors"))...)
+	b.Bookshelves = splitList(get("Bookshelves"))
+
+	// A single ISBN column may hold either form.
+	isbn, isbn13 := parseISBN(get("ISBN")), parseISBN(get("ISBN13"))
+	if len(isbn) == 13 && isbn13 == "" {
+		isbn, isbn13 = "", isbn
+	}
+	b.ISBN, b.ISBN13 = isbn, isbn13
+
+	if b.MyRating, err = parseRating(get("My Rating")); err != nil {
+		return nil, r.rowError("My Rating", err)
+	}
+	if b.NumberOfPages, err = parseInt(get("Number of Pages")); err != nil {
+		return nil, r.rowError("Number of Pages", err)
+	}
+	if b.YearPublished, err = parseInt(get("Year Published")); err != nil {
+		return nil, r.rowError("Year Published", err)
+	}
+	if b.OriginalPublicationYear, err = parseInt(get("Original Publication Year")); err != nil {
+		return nil, r.rowError("Original Publication Year", err)
+	}
+	if b.DateRead, err = parseDate(get("Date Read")); err != nil {
+		return nil, r.rowError("Date Read", err)
+	}
+	if b.DateAdded, err = parseDate(get("Date Added")); err != nil {
+		return nil, r.rowError("Date Added", err)
+	}
+	return b, nil
+}
+
+// readHeader reads the header row and records the position of each known
+// column.
+func (r *Reader) readHeader() error {
+	header, err := r.r.Read()
+	if errors.Is(err, io.EOF) {
+		return io.EOF
+	} else if err != nil {
+		return fmt.Errorf("goodreads: reading header: %w", err)
+	}
+
+	aliases := columnAliases()
+	r.columns = make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))
+		if alias, ok := aliases[name]; ok {
+			name = alias
+		}
+		if _, ok := r.columns[name]; !ok {
+			r.columns[name] = i
+		}
+	}
+	if _, ok := r.columns["Title"]; !ok {
+		return errors.New("goodreads: missing Title column")
+	}
+	return nil
+}
+
+// rowError annotates a parse error with the current line and column.
+func (r *Reader) rowError(column string, err error) error {
+	return fmt.Errorf("goodreads: line %d: %s: %w", r.Line(), column, err)
+}
+
+// parseISBN strips the ="..." formula wrapper Goodreads puts around ISBNs.
+func parseISBN(s string) string {
+	s = strings.TrimPrefix(s, "=")
+	return strings.Trim(s, `"`)
+}
+
+// parseInt parses an optional integer.
+func parseInt(s string) (int, error) {
+	if s == "" {
+		return 0, nil
+	}
+	return strconv.Atoi(s)
+}
+
+// parseRating parses a rating, rounding the fractional star ratings of
+// StoryGraph to whole stars.
+func parseRating(s string) (int, error) {
+	if s == "" {
+		return 0, nil
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, err
+	}
+	return int(v + 0.5), nil
+}
+
+// parseDate parses an optional date in the Goodreads layout, also accepting
+// the ISO layout of StoryGraph.
+func parseDate(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(DateFormat, s); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.DateOnly, s)
+}
+
+// splitList splits a comma-separated list, dropping empty items.
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
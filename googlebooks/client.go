@@ -1,88 +1,398 @@
 package googlebooks
 
 import (
+	"cmp"
 	"context"
 	"encoding/json"
 	"errors"
+	"log/slog"
+	"net/http"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/bisac"
+	"github.com/fwojciec/bookid/description"
+	"github.com/fwojciec/bookid/dimension"
+	bookidquery "github.com/fwojciec/bookid/query"
+	"github.com/fwojciec/bookid/scoring"
 	"google.golang.org/api/books/v1"
+	"google.golang.org/api/googleapi"
+	gtransport "google.golang.org/api/googleapi/transport"
 	"google.golang.org/api/option"
 )
 
+// ProviderName identifies results produced by this package.
+const ProviderName = "googlebooks"
+
+// Page sizes for the volumes.list call. The API rejects more than 40.
+const (
+	defaultMaxResults = 10
+	maxMaxResults     = 40
+)
+
 // Client implements the BookFinder interface for Google Books API
 type Client struct {
 	service *books.Service
+
+	// Computes the confidence of each result.
+	Scorer *scoring.Scorer
+
+	// ISO 3166-1 alpha-2 code of the country to search as unless a search
+	// sets one, e.g. "PL". Sets which editions are returned and the prices
+	// of offers. Empty lets Google Books tell from the request's address,
+	// which fails for some regions.
+	Country string
+
+	// Receives debug logs of query parsing and API latency. Defaults to
+	// discarding them.
+	Logger *slog.Logger
+}
+
+// Register the provider so it can be enabled by name. The profile's URL, if
+// set, replaces the Google Books endpoint, and its country is searched as.
+func init() {
+	bookid.RegisterFinder(ProviderName, func(config bookid.ProviderConfig) (bookid.BookFinder, error) {
+		opts := []Option{WithHTTPClient(config.HTTPClient)}
+		if config.URL != "" {
+			opts = append(opts, WithEndpoint(config.URL))
+		}
+		client, err := NewClient(config.APIKey, opts...)
+		if err != nil {
+			return nil, err
+		}
+		if config.Logger != nil {
+			client.Logger = config.Logger
+		}
+		client.Country = config.Country
+		return client, nil
+	})
+}
+
+// Option configures a client created by NewClient.
+type Option func(*clientOptions)
+
+// clientOptions holds the settings of NewClient.
+type clientOptions struct {
+	httpClient *http.Client
+	endpoint   string
 }
 
-// NewClient creates a new Google Books API client
-func NewClient(apiKey string) (*Client, error) {
-	ctx := context.Background()
+// WithHTTPClient sends requests through httpClient, such as one with a custom
+// transport or proxy, instead of the default client. Nil keeps the default.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(o *clientOptions) { o.httpClient = httpClient }
+}
+
+// WithEndpoint sends requests to endpoint, the base URL of the API such as
+// that of a local mock server or a caching proxy, instead of Google's.
+func WithEndpoint(endpoint string) Option {
+	return func(o *clientOptions) { o.endpoint = endpoint }
+}
+
+// NewClient creates a new Google Books API client. Requests are sent without
+// authentication if apiKey is empty.
+func NewClient(apiKey string, opts ...Option) (*Client, error) {
+	var o clientOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
 
-	opts := []option.ClientOption{}
-	if apiKey != "" {
-		opts = append(opts, option.WithAPIKey(apiKey))
+	var serviceOpts []option.ClientOption
+	if httpClient := o.httpClient; httpClient != nil {
+		// A custom client replaces authentication, so the API key is
+		// added by its transport instead.
+		if apiKey != "" {
+			transport := httpClient.Transport
+			if transport == nil {
+				transport = http.DefaultTransport
+			}
+			httpClient = &http.Client{Transport: &gtransport.APIKey{Key: apiKey, Transport: transport}, Timeout: httpClient.Timeout}
+		}
+		serviceOpts = append(serviceOpts, option.WithHTTPClient(httpClient))
+	} else if apiKey != "" {
+		serviceOpts = append(serviceOpts, option.WithAPIKey(apiKey))
 	} else {
 		// Explicitly disable authentication when no API key is provided
-		opts = append(opts, option.WithoutAuthentication())
+		serviceOpts = append(serviceOpts, option.WithoutAuthentication())
+	}
+	if o.endpoint != "" {
+		serviceOpts = append(serviceOpts, option.WithEndpoint(o.endpoint))
 	}
 
-	service, err := books.NewService(ctx, opts...)
+	service, err := books.NewService(context.Background(), serviceOpts...)
 	if err != nil {
 		return nil, err
 	}
 
-	return &Client{
-		service: service,
-	}, nil
+	return NewClientWithService(service), nil
 }
 
 // NewClientWithService creates a new client with a custom service (for testing)
 func NewClientWithService(service *books.Service) *Client {
 	return &Client{
 		service: service,
+		Scorer:  scoring.Default(),
+		Logger:  slog.New(slog.DiscardHandler),
 	}
 }
 
-// Search performs a book search based on the provided query
-func (c *Client) Search(ctx context.Context, query string) ([]bookid.BookResult, error) {
+// Search performs a book search based on the provided query. Links to
+// Google Books pages are resolved to their volume directly. If the
+// identifiers of the query find nothing, the rest of it is searched instead.
+// The API returns at most 40 volumes at a time, so more than that are paged
+// through. Results are ranked by confidence unless the newest are asked for.
+func (c *Client) Search(ctx context.Context, query string, opts bookid.SearchOptions) ([]bookid.BookResult, error) {
 	if query == "" {
 		return nil, errors.New("query cannot be empty")
+	} else if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	parsed := bookidquery.Parse(query)
+	if len(parsed.GoogleBooksIDs) > 0 {
+		volume, err := c.getVolume(ctx, parsed.GoogleBooksIDs[0])
+		if err == nil {
+			return opts.Apply([]bookid.BookResult{c.toBookResult(query, volume, bookid.SearchTypeProviderID, "", opts)}), nil
+		} else if bookid.ErrorCode(err) != bookid.ENOTFOUND {
+			return nil, err
+		}
+		parsed.GoogleBooksIDs = nil
+	}
+	if opts.MaxResults > maxMaxResults {
+		return c.searchPages(ctx, query, opts)
+	}
+
+	results, _, err := c.search(ctx, query, parsed, opts)
+	if err != nil {
+		return nil, err
+	} else if len(results) == 0 && parsed.HasIdentifiers() && parsed.HasText() {
+		c.Logger.DebugContext(ctx, "identifiers found nothing, searching the rest of the query", "provider", ProviderName)
+		if results, _, err = c.search(ctx, query, parsed.WithoutIdentifiers(), opts); err != nil {
+			return nil, err
+		}
+	}
+	return opts.Apply(rank(results, opts)), nil
+}
+
+// searchPages returns up to opts.MaxResults results of query, fetched a page
+// at a time and ranked together as a single page is.
+func (c *Client) searchPages(ctx context.Context, query string, opts bookid.SearchOptions) ([]bookid.BookResult, error) {
+	it := c.SearchIter(ctx, query)
+	it.Limit, it.Options = opts.MaxResults, opts
+	results := make([]bookid.BookResult, 0, maxMaxResults)
+	for it.Next() {
+		results = append(results, it.Result())
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+	return rank(results, opts), nil
+}
+
+// rank drops the volumes Google Books repeats across pages and orders the
+// rest by confidence, best first, keeping the API's order among equals. The
+// API's order is kept as it is if the newest volumes were asked for.
+func rank(results []bookid.BookResult, opts bookid.SearchOptions) []bookid.BookResult {
+	seen := make(map[string]bool, len(results))
+	results = slices.DeleteFunc(results, func(r bookid.BookResult) bool {
+		if r.GoogleBooksVolumeID == "" {
+			return false
+		} else if seen[r.GoogleBooksVolumeID] {
+			return true
+		}
+		seen[r.GoogleBooksVolumeID] = true
+		return false
+	})
+	if opts.OrderBy != bookid.OrderByNewest {
+		slices.SortStableFunc(results, func(a, b bookid.BookResult) int {
+			return cmp.Compare(b.Confidence, a.Confidence)
+		})
+	}
+	return results
+}
+
+// GetByID returns the volume with the given Google Books volume ID, e.g.
+// "iXn5U2IzVH0C", for re-fetching a known volume. The raw volume is kept in
+// GoogleBooksData. Returns ENOTFOUND if there is no such volume.
+func (c *Client) GetByID(ctx context.Context, id string) (*bookid.BookResult, error) {
+	if id == "" {
+		return nil, bookid.Errorf(bookid.EINVALID, "Volume ID required.")
+	}
+	volume, err := c.getVolume(ctx, id)
+	if err != nil {
+		return nil, err
 	}
+	result := c.toBookResult(id, volume, bookid.SearchTypeProviderID, "", bookid.SearchOptions{})
+	return &result, nil
+}
 
-	// Parse the query to determine search type
-	searchQuery, searchType, detectedISBN := ParseQuery(query)
+// LookupOffers returns the Google Play offers of the volumes with the given
+// ISBN, one for each volume with sale information. Prices are those of the
+// client's Country, or of the country Google Books places the request in.
+func (c *Client) LookupOffers(ctx context.Context, isbn string) ([]bookid.Offer, error) {
+	if isbn == "" {
+		return nil, bookid.Errorf(bookid.EINVALID, "ISBN required.")
+	}
+	start := time.Now()
+	resp, err := c.service.Volumes.List("isbn:" + isbn).Context(ctx).Do(c.country("")...)
+	c.Logger.DebugContext(ctx, "listed volume offers",
+		"provider", ProviderName, "isbn", isbn, "duration", time.Since(start), "error", err)
+	if err != nil {
+		return nil, FormatError(err)
+	}
+
+	offers := make([]bookid.Offer, 0, len(resp.Items))
+	for _, volume := range resp.Items {
+		if offer, ok := saleOffer(volume.SaleInfo); ok {
+			offers = append(offers, offer)
+		}
+	}
+	return offers, nil
+}
+
+// getVolume fetches the volume with the given ID. Returns ENOTFOUND if there
+// is no such volume.
+func (c *Client) getVolume(ctx context.Context, id string) (*books.Volume, error) {
+	start := time.Now()
+	volume, err := c.service.Volumes.Get(id).Context(ctx).Do(c.country("")...)
+	c.Logger.DebugContext(ctx, "got volume",
+		"provider", ProviderName, "id", id, "duration", time.Since(start), "error", err)
+	if err != nil {
+		if err := FormatError(err); bookid.ErrorCode(err) != bookid.ENOTFOUND {
+			return nil, err
+		}
+		return nil, bookid.Errorf(bookid.ENOTFOUND, "Google Books volume %q not found.", id)
+	}
+	return volume, nil
+}
+
+// search lists the volumes matching parsed, the parsed form of query, along
+// with the total number of matching volumes Google Books reports. Returns no
+// results if parsed has nothing Google Books can search, such as only an ASIN.
+func (c *Client) search(ctx context.Context, query string, parsed bookid.ParsedQuery, opts bookid.SearchOptions) ([]bookid.BookResult, int, error) {
+	// Build the native query and determine the search type
+	searchQuery, searchType, detectedISBN := FormatQuery(parsed), parsed.SearchType(), parsed.ISBN()
+	if searchType == bookid.SearchTypeASIN {
+		// ASINs cannot be searched, so only the rest of the query is.
+		searchType = parsed.WithoutIdentifiers().SearchType()
+	}
+	c.Logger.DebugContext(ctx, "parsed query",
+		"provider", ProviderName, "query", query, "q", searchQuery, "search_type", searchType, "isbn", detectedISBN)
+	if searchQuery == "" {
+		return []bookid.BookResult{}, 0, nil
+	}
 
 	// Build and execute the search
 	call := c.service.Volumes.List(searchQuery)
-	call.MaxResults(10)
+	call.MaxResults(int64(pageSize(opts.MaxResults)))
+	if opts.StartIndex > 0 {
+		call.StartIndex(int64(opts.StartIndex))
+	}
+	if lang := cmp.Or(opts.Language, parsed.Language); lang != "" {
+		call.LangRestrict(lang)
+	}
+	if opts.PrintType != "" {
+		call.PrintType(string(opts.PrintType))
+	}
+	if opts.OrderBy != "" {
+		call.OrderBy(string(opts.OrderBy))
+	}
+	if opts.FreeOnly {
+		call.Filter("full")
+	}
 	call.Context(ctx)
 
-	resp, err := call.Do()
+	start := time.Now()
+	resp, err := call.Do(c.country(opts.Country)...)
+	c.Logger.DebugContext(ctx, "listed volumes",
+		"provider", ProviderName, "duration", time.Since(start), "error", err)
 	if err != nil {
-		// Return the original error to preserve context
-		// Callers can check for googleapi.Error if they need specific handling
-		return nil, err
+		// Translate API failures into application errors so callers can
+		// branch on error codes instead of inspecting googleapi.Error.
+		return nil, 0, FormatError(err)
 	}
 
 	// Convert to BookResult
 	results := make([]bookid.BookResult, 0, len(resp.Items))
 	for _, volume := range resp.Items {
-		// Marshal the volume to JSON for GoogleBooksData field
-		volumeJSON, err := json.Marshal(volume)
-		if err != nil {
-			// Log error but continue - don't fail the whole search
-			volumeJSON = nil
-		}
+		results = append(results, c.toBookResult(query, volume, searchType, detectedISBN, opts))
+	}
+	return results, int(resp.TotalItems), nil
+}
 
-		result := volumeToBookResult(volume, searchType, detectedISBN)
-		result.GoogleBooksData = volumeJSON
-		results = append(results, result)
+// toBookResult converts a volume found by query to a scored BookResult.
+func (c *Client) toBookResult(query string, volume *books.Volume, searchType bookid.SearchType, detectedISBN string, opts bookid.SearchOptions) bookid.BookResult {
+	// Marshal the volume to JSON for GoogleBooksData field
+	volumeJSON, err := json.Marshal(volume)
+	if err != nil {
+		// Log error but continue - don't fail the whole search
+		volumeJSON = nil
 	}
 
-	return results, nil
+	result := volumeToBookResult(volume, searchType, detectedISBN)
+	result.GoogleBooksData = volumeJSON
+	result.Confidence = c.Scorer.Score(scoring.Input{Query: query, Options: opts, Result: result})
+	return result
+}
+
+// country returns the call options searching as the given country, or as the
+// client's Country if empty, and none if both are empty. The volumes.list
+// call has no setter for the parameter, though the API accepts it as it does
+// for volumes.get.
+func (c *Client) country(country string) []googleapi.CallOption {
+	country = cmp.Or(country, c.Country)
+	if country == "" {
+		return nil
+	}
+	return []googleapi.CallOption{googleapi.QueryParameter("country", strings.ToUpper(country))}
+}
+
+// pageSize returns the number of volumes to request for maxResults.
+func pageSize(maxResults int) int {
+	if maxResults <= 0 {
+		return defaultMaxResults
+	}
+	return min(maxResults, maxMaxResults)
+}
+
+// FormatError returns err as a bookid error if it is a googleapi.Error with a
+// status we can classify. Otherwise returns the original error.
+//
+//   - 429, and 403 with a quota or rate limit reason: ERATELIMIT
+//   - 404: ENOTFOUND
+//   - 5xx: EUNAVAILABLE
+func FormatError(err error) error {
+	var apiErr *googleapi.Error
+	if !errors.As(err, &apiErr) {
+		return err
+	}
+
+	switch {
+	case apiErr.Code == http.StatusTooManyRequests:
+		return bookid.Errorf(bookid.ERATELIMIT, "Google Books rate limit exceeded.")
+	case apiErr.Code == http.StatusForbidden && isQuotaError(apiErr):
+		return bookid.Errorf(bookid.ERATELIMIT, "Google Books quota exceeded.")
+	case apiErr.Code == http.StatusNotFound:
+		return bookid.Errorf(bookid.ENOTFOUND, "Google Books resource not found.")
+	case apiErr.Code >= http.StatusInternalServerError:
+		return bookid.Errorf(bookid.EUNAVAILABLE, "Google Books is unavailable (status %d).", apiErr.Code)
+	}
+	return err
+}
+
+// isQuotaError reports whether a 403 response was caused by exhausted quota
+// rather than missing permissions.
+func isQuotaError(apiErr *googleapi.Error) bool {
+	for _, item := range apiErr.Errors {
+		switch item.Reason {
+		case "rateLimitExceeded", "userRateLimitExceeded", "dailyLimitExceeded", "quotaExceeded":
+			return true
+		}
+	}
+	return strings.Contains(strings.ToLower(apiErr.Message), "quota")
 }
 
 // volumeToBookResult converts a Google Books Volume to our BookResult
@@ -91,6 +401,7 @@ func volumeToBookResult(volume *books.Volume, searchType bookid.SearchType, dete
 		Title:               volume.VolumeInfo.Title,
 		Authors:             volume.VolumeInfo.Authors,
 		GoogleBooksVolumeID: volume.Id,
+		Provider:            ProviderName,
 		SearchType:          searchType,
 	}
 
@@ -117,6 +428,17 @@ func volumeToBookResult(volume *books.Volume, searchType bookid.SearchType, dete
 	// Extract publication details
 	result.Publisher = volume.VolumeInfo.Publisher
 	result.Language = volume.VolumeInfo.Language
+	result.Subjects = volume.VolumeInfo.Categories
+	result.BISACCodes = bisac.Codes(volume.VolumeInfo.Categories)
+	result.Description = description.Clean(volume.VolumeInfo.Description)
+	result.PageCount = int(volume.VolumeInfo.PageCount)
+	if d := volume.VolumeInfo.Dimensions; d != nil {
+		result.Dimensions = bookid.Dimensions{
+			Height:    dimension.Length(d.Height),
+			Width:     dimension.Length(d.Width),
+			Thickness: dimension.Length(d.Thickness),
+		}
+	}
 
 	// Parse published year
 	if volume.VolumeInfo.PublishedDate != "" {
@@ -124,6 +446,30 @@ func volumeToBookResult(volume *books.Volume, searchType bookid.SearchType, dete
 		result.PublishedYear = year
 	}
 
+	if info := volume.SaleInfo; info != nil && info.Saleability != "" {
+		result.Sale = &bookid.SaleInfo{
+			Country:      info.Country,
+			Availability: saleability(info.Saleability),
+		}
+		if p := info.ListPrice; p != nil {
+			result.Sale.ListPrice, result.Sale.Currency = p.Amount, p.CurrencyCode
+		}
+	}
+	if info := volume.AccessInfo; info != nil {
+		result.Access = &bookid.AccessInfo{
+			PublicDomain: info.PublicDomain,
+			FullView:     info.Viewability == "ALL_PAGES",
+			WebReaderURL: ensureHTTPS(info.WebReaderLink),
+			PreviewURL:   ensureHTTPS(volume.VolumeInfo.PreviewLink),
+		}
+		if info.Epub != nil {
+			result.Access.EPUBAvailable = info.Epub.IsAvailable
+		}
+		if info.Pdf != nil {
+			result.Access.PDFAvailable = info.Pdf.IsAvailable
+		}
+	}
+
 	// Extract thumbnail URL and ensure HTTPS
 	if volume.VolumeInfo.ImageLinks != nil {
 		if volume.VolumeInfo.ImageLinks.Thumbnail != "" {
@@ -133,53 +479,44 @@ func volumeToBookResult(volume *books.Volume, searchType bookid.SearchType, dete
 		}
 	}
 
-	// Calculate confidence score
-	result.Confidence = calculateConfidence(searchType, volume)
-
 	return result
 }
 
-// calculateConfidence determines the confidence score based on search type and result quality
-func calculateConfidence(searchType bookid.SearchType, volume *books.Volume) float64 {
-	baseConfidence := map[bookid.SearchType]float64{
-		bookid.SearchTypeISBN:         0.95,
-		bookid.SearchTypeGeneralQuery: 0.70,
+// saleOffer converts the sale information of a volume to an offer. Returns
+// false if the volume has none.
+func saleOffer(info *books.VolumeSaleInfo) (bookid.Offer, bool) {
+	if info == nil || info.Saleability == "" {
+		return bookid.Offer{}, false
 	}
-
-	confidence := baseConfidence[searchType]
-
-	// Adjust based on data completeness
-	if volume.VolumeInfo != nil {
-		dataPoints := 0
-		totalPoints := 0
-
-		// Check important fields
-		if volume.VolumeInfo.Title != "" {
-			dataPoints++
-		}
-		totalPoints++
-
-		if len(volume.VolumeInfo.Authors) > 0 {
-			dataPoints++
-		}
-		totalPoints++
-
-		if len(volume.VolumeInfo.IndustryIdentifiers) > 0 {
-			dataPoints++
-		}
-		totalPoints++
-
-		if volume.VolumeInfo.Publisher != "" {
-			dataPoints++
-		}
-		totalPoints++
-
-		// Adjust confidence based on data completeness
-		completeness := float64(dataPoints) / float64(totalPoints)
-		confidence = confidence * (0.7 + 0.3*completeness)
+	offer := bookid.Offer{
+		Provider:     ProviderName,
+		Country:      info.Country,
+		Availability: saleability(info.Saleability),
+		URL:          info.BuyLink,
+	}
+	if p := info.ListPrice; p != nil {
+		offer.ListPrice, offer.Currency = p.Amount, p.CurrencyCode
 	}
+	if p := info.RetailPrice; p != nil {
+		offer.RetailPrice, offer.Currency = p.Amount, cmp.Or(p.CurrencyCode, offer.Currency)
+	}
+	return offer, true
+}
 
-	return confidence
+// saleability converts a Google Books saleability, such as "FOR_SALE", to an
+// availability. Returns an empty availability for unknown values.
+func saleability(s string) bookid.Availability {
+	switch s {
+	case "FOR_SALE", "FOR_SALE_AND_RENTAL", "FOR_RENTAL_ONLY":
+		return bookid.AvailabilityForSale
+	case "FREE":
+		return bookid.AvailabilityFree
+	case "FOR_PREORDER":
+		return bookid.AvailabilityPreorder
+	case "NOT_FOR_SALE":
+		return bookid.AvailabilityNotForSale
+	}
+	return ""
 }
 
 // extractYear extracts the year from various date formats
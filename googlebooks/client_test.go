@@ -2,7 +2,10 @@ package googlebooks_test
 
 import (
 	"context"
-	"encoding/json"
+	"flag"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
@@ -10,43 +13,48 @@ import (
 
 	"github.com/fwojciec/bookid"
 	"github.com/fwojciec/bookid/googlebooks"
+	"github.com/fwojciec/bookid/internal/httptestutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
-// TestGoldenFiles validates that the golden files contain the expected data structure
-// This serves as a contract test - when golden files are updated with -update flag,
-// this test ensures the API responses still contain the fields we depend on
-func TestGoldenFiles(t *testing.T) {
-	t.Parallel()
+// record makes TestClient_Search send its requests to the Google Books API
+// and save them as fixtures, using GOOGLE_BOOKS_API_KEY if set. The key is
+// scrubbed from the fixtures.
+var record = flag.Bool("record", false, "record Google Books responses into fixtures")
 
-	// Check if golden files exist - if not, skip validation
-	// This allows golden file generation to happen independently
-	testDataPath := filepath.Join("testdata", "isbn_9780743273565.json")
-	if _, err := os.Stat(testDataPath); os.IsNotExist(err) {
-		t.Skip("Golden files not found - run tests with -update flag in the googlebooks package first")
-	}
+// TestClient_Search runs searches against recorded Google Books responses.
+// It doubles as a contract test: when the fixtures are re-recorded with the
+// -record flag, it checks the API still returns the fields we depend on.
+func TestClient_Search(t *testing.T) {
+	t.Parallel()
 
 	tests := []struct {
 		name               string
-		goldenFile         string
+		query              string
+		fixture            string
 		expectedResults    int
 		expectedFirstTitle string
 		validateFields     func(t *testing.T, result bookid.BookResult)
 	}{
 		{
 			name:               "isbn_search",
-			goldenFile:         "isbn_9780743273565.json",
+			query:              "9780743273565",
+			fixture:            "isbn_9780743273565.json",
 			expectedResults:    1,
 			expectedFirstTitle: "The Great Gatsby",
 			validateFields: func(t *testing.T, result bookid.BookResult) {
 				t.Helper()
-				assert.NotEmpty(t, result.ISBN10)
-				assert.NotEmpty(t, result.ISBN13)
+				assert.Equal(t, "0743273567", result.ISBN10)
+				assert.Equal(t, "9780743273565", result.ISBN13)
 				assert.NotEmpty(t, result.Publisher)
 				assert.NotEmpty(t, result.GoogleBooksVolumeID)
 				assert.Equal(t, bookid.SearchTypeISBN, result.SearchType)
+				assert.Equal(t, []string{"Fiction"}, result.Subjects)
+				assert.Equal(t, []string{"FIC000000"}, result.BISACCodes)
+				assert.Equal(t, 208, result.PageCount)
 				assert.InDelta(t, 0.95, result.Confidence, 0.01)
+				assert.NotEmpty(t, result.GoogleBooksData)
 				// Verify thumbnail URL uses HTTPS
 				if result.ThumbnailURL != "" {
 					assert.True(t, strings.HasPrefix(result.ThumbnailURL, "https://"), "Thumbnail URL should use HTTPS")
@@ -55,13 +63,17 @@ func TestGoldenFiles(t *testing.T) {
 		},
 		{
 			name:               "title_author_search",
-			goldenFile:         "title_author_gatsby.json",
+			query:              "The Great Gatsby by F. Scott Fitzgerald",
+			fixture:            "title_author_gatsby.json",
 			expectedResults:    10,
 			expectedFirstTitle: "The Great Gatsby",
 			validateFields: func(t *testing.T, result bookid.BookResult) {
 				t.Helper()
 				assert.NotEmpty(t, result.Authors)
 				assert.Equal(t, bookid.SearchTypeGeneralQuery, result.SearchType)
+				// Results are ranked, so a complete edition comes before the
+				// volume the API lists first, which has no ISBN.
+				assert.NotEmpty(t, result.ISBN13)
 				assert.InDelta(t, 0.70, result.Confidence, 0.01)
 				// Verify thumbnail URL uses HTTPS
 				if result.ThumbnailURL != "" {
@@ -71,14 +83,18 @@ func TestGoldenFiles(t *testing.T) {
 		},
 		{
 			name:               "title_only_search",
-			goldenFile:         "title_only_pride.json",
+			query:              "Pride and Prejudice",
+			fixture:            "title_only_pride.json",
 			expectedResults:    10,
 			expectedFirstTitle: "Pride and Prejudice",
 			validateFields: func(t *testing.T, result bookid.BookResult) {
 				t.Helper()
 				assert.NotEmpty(t, result.Title)
 				assert.Equal(t, bookid.SearchTypeGeneralQuery, result.SearchType)
-				assert.InDelta(t, 0.70, result.Confidence, 0.06)
+				// Results are ranked, so a complete edition comes before the
+				// volume the API lists first, which has no ISBN or publisher.
+				assert.NotEmpty(t, result.ISBN13)
+				assert.InDelta(t, 0.70, result.Confidence, 0.01)
 				// Verify thumbnail URL uses HTTPS
 				if result.ThumbnailURL != "" {
 					assert.True(t, strings.HasPrefix(result.ThumbnailURL, "https://"), "Thumbnail URL should use HTTPS")
@@ -87,7 +103,8 @@ func TestGoldenFiles(t *testing.T) {
 		},
 		{
 			name:            "no_results",
-			goldenFile:      "no_results.json",
+			query:           "nonexistentbook12345",
+			fixture:         "no_results.json",
 			expectedResults: 0,
 		},
 	}
@@ -96,17 +113,15 @@ func TestGoldenFiles(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
 
-			// Load golden file
-			goldenPath := filepath.Join("testdata", tt.goldenFile)
-			data, err := os.ReadFile(goldenPath)
-			require.NoError(t, err, "golden file should exist: %s", goldenPath)
+			client, err := bookid.NewFinder(googlebooks.ProviderName, bookid.ProviderConfig{
+				APIKey:     os.Getenv("GOOGLE_BOOKS_API_KEY"),
+				HTTPClient: httptestutil.NewClient(t, filepath.Join("testdata", tt.fixture), *record),
+			})
+			require.NoError(t, err)
 
-			var results []bookid.BookResult
-			err = json.Unmarshal(data, &results)
-			require.NoError(t, err, "golden file should contain valid BookResult array")
-
-			// Validate structure
-			assert.Len(t, results, tt.expectedResults)
+			results, err := client.Search(context.Background(), tt.query, bookid.SearchOptions{IncludeRaw: true})
+			require.NoError(t, err)
+			require.Len(t, results, tt.expectedResults)
 
 			if tt.expectedResults > 0 {
 				assert.Equal(t, tt.expectedFirstTitle, results[0].Title)
@@ -129,7 +144,360 @@ func TestClient_Search_Errors(t *testing.T) {
 	require.NoError(t, err)
 
 	// Test empty query
-	_, err = client.Search(context.Background(), "")
+	_, err = client.Search(context.Background(), "", bookid.SearchOptions{})
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "query cannot be empty")
 }
+
+// TestClient_Search_ErrorCodes tests that failed responses are translated to bookid error codes
+func TestClient_Search_ErrorCodes(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		want   string
+	}{
+		{"rate_limit", http.StatusTooManyRequests, `{"error":{"code":429,"message":"Rate Limit Exceeded"}}`, bookid.ERATELIMIT},
+		{"quota", http.StatusForbidden, `{"error":{"code":403,"message":"Quota exceeded","errors":[{"reason":"dailyLimitExceeded"}]}}`, bookid.ERATELIMIT},
+		{"forbidden", http.StatusForbidden, `{"error":{"code":403,"message":"The caller does not have permission"}}`, bookid.EINTERNAL},
+		{"not_found", http.StatusNotFound, `{"error":{"code":404,"message":"Not Found"}}`, bookid.ENOTFOUND},
+		{"unavailable", http.StatusServiceUnavailable, `{"error":{"code":503,"message":"Backend Error"}}`, bookid.EUNAVAILABLE},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			t.Cleanup(srv.Close)
+
+			client, err := googlebooks.NewClient("",
+				googlebooks.WithEndpoint(srv.URL),
+				googlebooks.WithHTTPClient(srv.Client()),
+			)
+			require.NoError(t, err)
+
+			_, err = client.Search(context.Background(), "dune", bookid.SearchOptions{})
+			require.Error(t, err)
+			assert.Equal(t, tt.want, bookid.ErrorCode(err))
+		})
+	}
+}
+
+// TestClient_Search_Options tests that search options are mapped to API parameters
+func TestClient_Search_Options(t *testing.T) {
+	t.Parallel()
+
+	var params url.Values
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		params = r.URL.Query()
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"kind":"books#volumes","totalItems":0}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	client, err := googlebooks.NewClient("",
+		googlebooks.WithEndpoint(srv.URL),
+		googlebooks.WithHTTPClient(srv.Client()),
+	)
+	require.NoError(t, err)
+
+	_, err = client.Search(context.Background(), "dune", bookid.SearchOptions{
+		MaxResults: 100,
+		StartIndex: 40,
+		Language:   "pl",
+		PrintType:  bookid.PrintTypeBooks,
+		OrderBy:    bookid.OrderByNewest,
+		FreeOnly:   true,
+		Country:    "pl",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "40", params.Get("maxResults"))
+	assert.Equal(t, "40", params.Get("startIndex"))
+	assert.Equal(t, "pl", params.Get("langRestrict"))
+	assert.Equal(t, "books", params.Get("printType"))
+	assert.Equal(t, "newest", params.Get("orderBy"))
+	assert.Equal(t, "full", params.Get("filter"))
+	assert.Equal(t, "PL", params.Get("country"))
+
+	_, err = client.Search(context.Background(), "dune", bookid.SearchOptions{OrderBy: "oldest"})
+	assert.Equal(t, bookid.EINVALID, bookid.ErrorCode(err))
+}
+
+// TestClient_Country tests that requests are made as from the client's
+// country unless a search sets another
+func TestClient_Country(t *testing.T) {
+	t.Parallel()
+
+	var countries []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		countries = append(countries, r.URL.Query().Get("country"))
+		w.Header().Set("Content-Type", "application/json")
+		if strings.Contains(r.URL.Path, "/volumes/") {
+			_, _ = w.Write([]byte(`{"kind":"books#volume","id":"abc","volumeInfo":{"title":"Dune"}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"kind":"books#volumes","totalItems":0}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	client, err := googlebooks.NewClient("",
+		googlebooks.WithEndpoint(srv.URL),
+		googlebooks.WithHTTPClient(srv.Client()),
+	)
+	require.NoError(t, err)
+
+	_, err = client.Search(context.Background(), "dune", bookid.SearchOptions{})
+	require.NoError(t, err)
+	client.Country = "DE"
+	_, err = client.Search(context.Background(), "dune", bookid.SearchOptions{})
+	require.NoError(t, err)
+	_, err = client.Search(context.Background(), "dune", bookid.SearchOptions{Country: "pl"})
+	require.NoError(t, err)
+	_, err = client.GetByID(context.Background(), "abc")
+	require.NoError(t, err)
+	_, err = client.LookupOffers(context.Background(), "9780441172719")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"", "DE", "PL", "DE", "DE"}, countries)
+}
+
+// TestClient_Search_Pages tests that more results than fit in a page are
+// paged through
+func TestClient_Search_Pages(t *testing.T) {
+	t.Parallel()
+
+	var requests []string
+	client := newPagingServer(t, 1000, &requests)
+
+	results, err := client.Search(context.Background(), "dune", bookid.SearchOptions{MaxResults: 60, OrderBy: bookid.OrderByNewest})
+	require.NoError(t, err)
+	assert.Len(t, results, 60)
+	assert.Equal(t, []string{"+40", "40+20"}, requests)
+}
+
+// TestClient_Search_RankedPages tests that results paged through are ranked
+// together, as a single page is, without the volumes repeated across pages.
+// The pages are synthetic, as real ones do not reliably repeat volumes.
+func TestClient_Search_RankedPages(t *testing.T) {
+	t.Parallel()
+
+	client, err := bookid.NewFinder(googlebooks.ProviderName, bookid.ProviderConfig{
+		APIKey:     "KEY",
+		HTTPClient: httptestutil.Replay(t, filepath.Join("testdata", "synthetic", "pages_dune.json")),
+	})
+	require.NoError(t, err)
+
+	results, err := client.Search(context.Background(), "Dune by Frank Herbert", bookid.SearchOptions{MaxResults: 41})
+	require.NoError(t, err)
+	var ids []string
+	for i, r := range results {
+		ids = append(ids, r.GoogleBooksVolumeID)
+		if i > 0 {
+			assert.GreaterOrEqual(t, results[i-1].Confidence, r.Confidence)
+		}
+	}
+	assert.Equal(t, []string{"B1hSG45JCX4C", "aOYFAQAAMAAJ", "k9BqAAAAMAAJ"}, ids)
+
+	// Asking for the newest keeps the API's order.
+	results, err = client.Search(context.Background(), "Dune by Frank Herbert", bookid.SearchOptions{MaxResults: 41, OrderBy: bookid.OrderByNewest})
+	require.NoError(t, err)
+	ids = ids[:0]
+	for _, r := range results {
+		ids = append(ids, r.GoogleBooksVolumeID)
+	}
+	assert.Equal(t, []string{"k9BqAAAAMAAJ", "aOYFAQAAMAAJ", "B1hSG45JCX4C"}, ids)
+}
+
+// TestNewClient_Options tests that requests go through the injected HTTP
+// client to the injected endpoint, carrying the API key
+func TestNewClient_Options(t *testing.T) {
+	t.Parallel()
+
+	var key string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key = r.URL.Query().Get("key")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"kind":"books#volumes","totalItems":1,"items":[{"id":"abc","volumeInfo":{"title":"Dune"}}]}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	var requests int
+	httpClient := srv.Client()
+	transport := httpClient.Transport
+	httpClient.Transport = roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		requests++
+		return transport.RoundTrip(r)
+	})
+
+	client, err := googlebooks.NewClient("secret",
+		googlebooks.WithEndpoint(srv.URL),
+		googlebooks.WithHTTPClient(httpClient),
+	)
+	require.NoError(t, err)
+
+	results, err := client.Search(context.Background(), "dune", bookid.SearchOptions{})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "abc", results[0].GoogleBooksVolumeID)
+	assert.Equal(t, "secret", key)
+	assert.Equal(t, 1, requests)
+}
+
+// TestClient_Search_Fallback tests that the rest of the query is searched
+// when its ISBN finds nothing
+func TestClient_Search_Fallback(t *testing.T) {
+	t.Parallel()
+
+	var queries []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query().Get("q")
+		queries = append(queries, q)
+		w.Header().Set("Content-Type", "application/json")
+		if strings.HasPrefix(q, "isbn:") {
+			_, _ = w.Write([]byte(`{"kind":"books#volumes","totalItems":0}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"kind":"books#volumes","totalItems":1,"items":[{"id":"abc","volumeInfo":{"title":"The Great Gatsby"}}]}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	client, err := googlebooks.NewClient("",
+		googlebooks.WithEndpoint(srv.URL),
+		googlebooks.WithHTTPClient(srv.Client()),
+	)
+	require.NoError(t, err)
+
+	results, err := client.Search(context.Background(), "https://www.amazon.com/dp/0743273567 The Great Gatsby", bookid.SearchOptions{})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, []string{"isbn:0743273567", "The Great Gatsby"}, queries)
+	assert.Equal(t, bookid.SearchTypeGeneralQuery, results[0].SearchType)
+}
+
+// TestClient_Search_VolumeLink tests that links to Google Books pages are
+// resolved to their volume
+func TestClient_Search_VolumeLink(t *testing.T) {
+	t.Parallel()
+
+	var path string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"kind":"books#volume","id":"iXn5U2IzVH0C","volumeInfo":{"title":"The Great Gatsby","authors":["F. Scott Fitzgerald"]}}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	client, err := googlebooks.NewClient("",
+		googlebooks.WithEndpoint(srv.URL),
+		googlebooks.WithHTTPClient(srv.Client()),
+	)
+	require.NoError(t, err)
+
+	results, err := client.Search(context.Background(), "https://books.google.com/books?id=iXn5U2IzVH0C", bookid.SearchOptions{})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "/books/v1/volumes/iXn5U2IzVH0C", path)
+	assert.Equal(t, "iXn5U2IzVH0C", results[0].GoogleBooksVolumeID)
+	assert.Equal(t, bookid.SearchTypeProviderID, results[0].SearchType)
+}
+
+func TestClient_GetByID(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/books/v1/volumes/iXn5U2IzVH0C" {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":{"code":404,"message":"The volume ID could not be found."}}`))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"kind":"books#volume","id":"iXn5U2IzVH0C","volumeInfo":{"title":"The Great Gatsby","authors":["F. Scott Fitzgerald"],"industryIdentifiers":[{"type":"ISBN_13","identifier":"9780743273565"}],"dimensions":{"height":"21.00 cm","width":"13.50 cm","thickness":"1.30 cm"},"previewLink":"http://books.google.com/books?id=iXn5U2IzVH0C","description":"<p>A <b>classic</b> of the Jazz Age.</p>"},"saleInfo":{"country":"US","saleability":"FOR_SALE","listPrice":{"amount":17,"currencyCode":"USD"}},"accessInfo":{"viewability":"PARTIAL","publicDomain":false,"epub":{"isAvailable":true},"pdf":{"isAvailable":false},"webReaderLink":"http://play.google.com/books/reader?id=iXn5U2IzVH0C"}}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	client, err := googlebooks.NewClient("",
+		googlebooks.WithEndpoint(srv.URL),
+		googlebooks.WithHTTPClient(srv.Client()),
+	)
+	require.NoError(t, err)
+
+	t.Run("found", func(t *testing.T) {
+		t.Parallel()
+		result, err := client.GetByID(context.Background(), "iXn5U2IzVH0C")
+		require.NoError(t, err)
+		assert.Equal(t, "The Great Gatsby", result.Title)
+		assert.Equal(t, "9780743273565", result.ISBN13)
+		assert.Equal(t, bookid.Dimensions{Height: 210, Width: 135, Thickness: 13}, result.Dimensions)
+		assert.Equal(t, "A classic of the Jazz Age.", result.Description)
+		assert.Equal(t, &bookid.SaleInfo{Country: "US", Availability: bookid.AvailabilityForSale, Currency: "USD", ListPrice: 17}, result.Sale)
+		assert.Equal(t, &bookid.AccessInfo{
+			EPUBAvailable: true,
+			WebReaderURL:  "https://play.google.com/books/reader?id=iXn5U2IzVH0C",
+			PreviewURL:    "https://books.google.com/books?id=iXn5U2IzVH0C",
+		}, result.Access)
+		assert.False(t, result.Access.Free())
+		assert.Equal(t, bookid.SearchTypeProviderID, result.SearchType)
+		assert.NotEmpty(t, result.GoogleBooksData)
+	})
+
+	t.Run("not_found", func(t *testing.T) {
+		t.Parallel()
+		_, err := client.GetByID(context.Background(), "xxxxxxxxxxxx")
+		assert.Equal(t, bookid.ENOTFOUND, bookid.ErrorCode(err))
+	})
+
+	t.Run("empty_id", func(t *testing.T) {
+		t.Parallel()
+		_, err := client.GetByID(context.Background(), "")
+		assert.Equal(t, bookid.EINVALID, bookid.ErrorCode(err))
+	})
+}
+
+func TestClient_LookupOffers(t *testing.T) {
+	t.Parallel()
+
+	var query string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query().Get("q")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"kind":"books#volumes","totalItems":2,"items":[` +
+			`{"id":"iXn5U2IzVH0C","saleInfo":{"country":"US","saleability":"FOR_SALE","listPrice":{"amount":17,"currencyCode":"USD"},"retailPrice":{"amount":11.99,"currencyCode":"USD"},"buyLink":"https://play.google.com/store/books/details?id=iXn5U2IzVH0C"}},` +
+			`{"id":"fIlQDwAAQBAJ","saleInfo":{"country":"US","saleability":"NOT_FOR_SALE"}},` +
+			`{"id":"noSaleInfo"}]}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	client, err := googlebooks.NewClient("",
+		googlebooks.WithEndpoint(srv.URL),
+		googlebooks.WithHTTPClient(srv.Client()),
+	)
+	require.NoError(t, err)
+
+	offers, err := client.LookupOffers(context.Background(), "9780743273565")
+	require.NoError(t, err)
+	assert.Equal(t, "isbn:9780743273565", query)
+	assert.Equal(t, []bookid.Offer{
+		{
+			Provider:     googlebooks.ProviderName,
+			Country:      "US",
+			Currency:     "USD",
+			ListPrice:    17,
+			RetailPrice:  11.99,
+			Availability: bookid.AvailabilityForSale,
+			URL:          "https://play.google.com/store/books/details?id=iXn5U2IzVH0C",
+		},
+		{Provider: googlebooks.ProviderName, Country: "US", Availability: bookid.AvailabilityNotForSale},
+	}, offers)
+}
+
+// roundTripperFunc adapts a function to http.RoundTripper.
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
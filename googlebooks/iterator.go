@@ -0,0 +1,138 @@
+package googlebooks
+
+import (
+	"context"
+	"errors"
+
+	"github.com/fwojciec/bookid"
+	bookidquery "github.com/fwojciec/bookid/query"
+)
+
+// defaultIteratorLimit is the number of results a ResultIterator returns at
+// most unless its Limit is set.
+const defaultIteratorLimit = 200
+
+// ResultIterator returns the results of a search one at a time, fetching
+// the pages of the Google Books API as they are needed. Results are returned
+// until the API has no more or Limit is reached.
+//
+//	it := client.SearchIter(ctx, "dune")
+//	for it.Next() {
+//		result := it.Result()
+//		...
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type ResultIterator struct {
+	// Maximum number of results to return. Defaults to 200. Must be set
+	// before the first call to Next.
+	Limit int
+
+	// Options of the search, such as the language or order. MaxResults is
+	// ignored in favor of Limit and results are fetched from StartIndex on.
+	// Must be set before the first call to Next.
+	Options bookid.SearchOptions
+
+	ctx    context.Context
+	client *Client
+	query  string
+	parsed bookid.ParsedQuery
+
+	page    []bookid.BookResult // fetched results not yet returned
+	result  bookid.BookResult   // current result
+	n       int                 // number of results returned
+	fetched int                 // number of volumes fetched
+	started bool
+	done    bool
+	err     error
+}
+
+// SearchIter returns an iterator over the results of query, for callers who
+// need more than the single page returned by Search. Unlike Search, links to
+// Google Books pages are searched rather than resolved to their volume.
+func (c *Client) SearchIter(ctx context.Context, query string) *ResultIterator {
+	return &ResultIterator{
+		Limit:  defaultIteratorLimit,
+		ctx:    ctx,
+		client: c,
+		query:  query,
+	}
+}
+
+// Next advances the iterator to the next result, which is then available
+// through Result. Returns false when there are no more results or an error
+// occurred, which is then available through Err.
+func (it *ResultIterator) Next() bool {
+	if !it.started {
+		it.start()
+	}
+	for len(it.page) == 0 {
+		if it.done || it.err != nil {
+			return false
+		}
+		it.fetch()
+	}
+	if it.n >= it.Limit {
+		it.page, it.done = nil, true
+		return false
+	}
+	it.result, it.page = it.page[0], it.page[1:]
+	it.n++
+	return true
+}
+
+// Result returns the current result.
+func (it *ResultIterator) Result() bookid.BookResult {
+	return it.result
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (it *ResultIterator) Err() error {
+	return it.err
+}
+
+// start validates the search and parses its query.
+func (it *ResultIterator) start() {
+	it.started = true
+	if it.query == "" {
+		it.err = errors.New("query cannot be empty")
+	} else if err := it.Options.Validate(); err != nil {
+		it.err = err
+	}
+	it.parsed = bookidquery.Parse(it.query)
+	it.parsed.GoogleBooksIDs = nil
+}
+
+// fetch fetches the next page of results. The iteration is done when the API
+// returns an empty page or all the volumes it reports are fetched. As in
+// Search, the rest of the query is searched if its identifiers find nothing.
+func (it *ResultIterator) fetch() {
+	opts := it.Options
+	opts.MaxResults = min(it.Limit-it.n, maxMaxResults)
+	opts.StartIndex = it.Options.StartIndex + it.fetched
+	if opts.MaxResults <= 0 {
+		it.done = true
+		return
+	}
+
+	results, total, err := it.client.search(it.ctx, it.query, it.parsed, opts)
+	if err != nil {
+		it.err = err
+		return
+	} else if len(results) == 0 && it.fetched == 0 && it.parsed.HasIdentifiers() && it.parsed.HasText() {
+		it.client.Logger.DebugContext(it.ctx, "identifiers found nothing, searching the rest of the query", "provider", ProviderName)
+		it.parsed = it.parsed.WithoutIdentifiers()
+		results, total, err = it.client.search(it.ctx, it.query, it.parsed, opts)
+		if err != nil {
+			it.err = err
+			return
+		}
+	}
+	it.fetched += len(results)
+	it.done = len(results) == 0 || opts.StartIndex+len(results) >= total
+
+	// Filter without truncating, as Limit caps the results instead.
+	opts.MaxResults = 0
+	it.page = opts.Apply(results)
+}
@@ -0,0 +1,120 @@
+package googlebooks_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/googlebooks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newPagingServer returns a client of a server with total volumes matching
+// any query, served in the pages requested. Received startIndex and
+// maxResults pairs are appended to requests.
+func newPagingServer(t *testing.T, total int, requests *[]string) *googlebooks.Client {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		params := r.URL.Query()
+		*requests = append(*requests, params.Get("startIndex")+"+"+params.Get("maxResults"))
+		start, _ := strconv.Atoi(params.Get("startIndex"))
+		limit, _ := strconv.Atoi(params.Get("maxResults"))
+		items := make([]string, 0, limit)
+		for i := start; i < min(start+limit, total); i++ {
+			items = append(items, fmt.Sprintf(`{"id":"v%d","volumeInfo":{"title":"Dune %d"}}`, i, i))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = fmt.Fprintf(w, `{"kind":"books#volumes","totalItems":%d,"items":[%s]}`, total, strings.Join(items, ","))
+	}))
+	t.Cleanup(srv.Close)
+
+	client, err := googlebooks.NewClient("",
+		googlebooks.WithEndpoint(srv.URL),
+		googlebooks.WithHTTPClient(srv.Client()),
+	)
+	require.NoError(t, err)
+	return client
+}
+
+func TestClient_SearchIter(t *testing.T) {
+	t.Parallel()
+
+	t.Run("all_pages", func(t *testing.T) {
+		t.Parallel()
+		var requests []string
+		client := newPagingServer(t, 90, &requests)
+
+		it := client.SearchIter(context.Background(), "dune")
+		var ids []string
+		for it.Next() {
+			ids = append(ids, it.Result().GoogleBooksVolumeID)
+		}
+		require.NoError(t, it.Err())
+		require.Len(t, ids, 90)
+		assert.Equal(t, "v0", ids[0])
+		assert.Equal(t, "v89", ids[89])
+		assert.Equal(t, []string{"+40", "40+40", "80+40"}, requests)
+		assert.False(t, it.Next(), "stays done")
+	})
+
+	t.Run("limit", func(t *testing.T) {
+		t.Parallel()
+		var requests []string
+		client := newPagingServer(t, 1000, &requests)
+
+		it := client.SearchIter(context.Background(), "dune")
+		it.Limit = 50
+		it.Options = bookid.SearchOptions{StartIndex: 10}
+		var n int
+		for it.Next() {
+			n++
+		}
+		require.NoError(t, it.Err())
+		assert.Equal(t, 50, n)
+		assert.Equal(t, "v59", it.Result().GoogleBooksVolumeID)
+		assert.Equal(t, []string{"10+40", "50+10"}, requests)
+	})
+
+	t.Run("lazy", func(t *testing.T) {
+		t.Parallel()
+		var requests []string
+		client := newPagingServer(t, 90, &requests)
+
+		it := client.SearchIter(context.Background(), "dune")
+		assert.Empty(t, requests)
+		require.True(t, it.Next())
+		assert.Len(t, requests, 1)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		t.Parallel()
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write([]byte(`{"error":{"code":429,"message":"Rate Limit Exceeded"}}`))
+		}))
+		t.Cleanup(srv.Close)
+		client, err := googlebooks.NewClient("",
+			googlebooks.WithEndpoint(srv.URL),
+			googlebooks.WithHTTPClient(srv.Client()),
+		)
+		require.NoError(t, err)
+
+		it := client.SearchIter(context.Background(), "dune")
+		assert.False(t, it.Next())
+		assert.Equal(t, bookid.ERATELIMIT, bookid.ErrorCode(it.Err()))
+	})
+
+	t.Run("empty_query", func(t *testing.T) {
+		t.Parallel()
+		it := (&googlebooks.Client{}).SearchIter(context.Background(), "")
+		assert.False(t, it.Next())
+		assert.Error(t, it.Err())
+	})
+}
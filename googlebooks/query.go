@@ -0,0 +1,50 @@
+package googlebooks
+
+import (
+	"strings"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/lccn"
+)
+
+// FormatQuery returns the Google Books API query for q. Identifiers take
+// precedence over fields, and only the first one is searched as the API
+// cannot OR them. Google Books has no DOI operator, so a DOI is searched
+// bare and matches identifiers in the full text index. The year has no
+// operator either and is left out; the language is applied by Search.
+func FormatQuery(q bookid.ParsedQuery) string {
+	switch {
+	case len(q.DOIs) > 0:
+		return q.DOIs[0]
+	case len(q.ISBNs) > 0:
+		return "isbn:" + q.ISBNs[0]
+	case len(q.LCCNs) > 0:
+		return lccn.Prefix + q.LCCNs[0]
+	}
+
+	// Free text is left to natural language search, which handles
+	// variations in title/author spelling and formatting better than
+	// strict operators.
+	parts := make([]string, 0, 4)
+	if q.Terms != "" {
+		parts = append(parts, q.Terms)
+	}
+	for _, field := range []struct{ operator, value string }{
+		{"intitle:", q.Title},
+		{"inauthor:", q.Author},
+		{"inpublisher:", q.Publisher},
+	} {
+		if field.value != "" {
+			parts = append(parts, field.operator+quote(field.value))
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// quote returns s in double quotes if it has more than one word.
+func quote(s string) string {
+	if !strings.ContainsAny(s, " \t") {
+		return s
+	}
+	return `"` + strings.ReplaceAll(s, `"`, "") + `"`
+}
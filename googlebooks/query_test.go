@@ -5,10 +5,11 @@ import (
 
 	"github.com/fwojciec/bookid"
 	"github.com/fwojciec/bookid/googlebooks"
+	"github.com/fwojciec/bookid/query"
 	"github.com/stretchr/testify/assert"
 )
 
-func TestParseQuery(t *testing.T) {
+func TestFormatQuery(t *testing.T) {
 	t.Parallel()
 	tests := []struct {
 		name          string
@@ -52,6 +53,36 @@ func TestParseQuery(t *testing.T) {
 			expectedType:  bookid.SearchTypeISBN,
 			expectedISBN:  "9780743273565",
 		},
+		{
+			name:          "lccn_prefixed",
+			input:         "lccn:2004-111282",
+			expectedQuery: "lccn:2004111282",
+			expectedType:  bookid.SearchTypeLCCN,
+		},
+		{
+			name:          "lccn_bare",
+			input:         "n78-890351",
+			expectedQuery: "lccn:n78890351",
+			expectedType:  bookid.SearchTypeLCCN,
+		},
+		{
+			name:          "doi_url",
+			input:         "https://doi.org/10.1007/978-3-030-00001-1_3",
+			expectedQuery: "10.1007/978-3-030-00001-1_3",
+			expectedType:  bookid.SearchTypeDOI,
+		},
+		{
+			name:          "isbn10_bad_checksum",
+			input:         "1234567890",
+			expectedQuery: "1234567890",
+			expectedType:  bookid.SearchTypeGeneralQuery,
+		},
+		{
+			name:          "isbn13_bad_checksum",
+			input:         "9780743273566",
+			expectedQuery: "9780743273566",
+			expectedType:  bookid.SearchTypeGeneralQuery,
+		},
 		{
 			name:          "title_and_author",
 			input:         "The Great Gatsby by F. Scott Fitzgerald",
@@ -64,6 +95,25 @@ func TestParseQuery(t *testing.T) {
 			expectedQuery: "The Great Gatsby",
 			expectedType:  bookid.SearchTypeGeneralQuery,
 		},
+		{
+			name:          "fields",
+			input:         `title:"The Great Gatsby" author:Fitzgerald year:1925`,
+			expectedQuery: `intitle:"The Great Gatsby" inauthor:Fitzgerald`,
+			expectedType:  bookid.SearchTypeTitleAuthor,
+		},
+		{
+			name:          "fields_and_terms",
+			input:         `gatsby publisher:"Charles Scribner's Sons"`,
+			expectedQuery: `gatsby inpublisher:"Charles Scribner's Sons"`,
+			expectedType:  bookid.SearchTypeGeneralQuery,
+		},
+		{
+			name:          "multiple_isbns",
+			input:         "isbn:9780743273565 isbn:0743273567",
+			expectedQuery: "isbn:9780743273565",
+			expectedType:  bookid.SearchTypeISBN,
+			expectedISBN:  "9780743273565",
+		},
 		{
 			name:          "general_query",
 			input:         "classic american literature 1920s",
@@ -75,11 +125,11 @@ func TestParseQuery(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
-			query, searchType, isbn := googlebooks.ParseQuery(tt.input)
-			assert.Equal(t, tt.expectedQuery, query)
-			assert.Equal(t, tt.expectedType, searchType)
+			parsed := query.Parse(tt.input)
+			assert.Equal(t, tt.expectedQuery, googlebooks.FormatQuery(parsed))
+			assert.Equal(t, tt.expectedType, parsed.SearchType())
 			if tt.expectedISBN != "" {
-				assert.Equal(t, tt.expectedISBN, isbn)
+				assert.Equal(t, tt.expectedISBN, parsed.ISBN())
 			}
 		})
 	}
@@ -0,0 +1,99 @@
+// Package graphql exposes the book finder and the catalog over a GraphQL
+// API, so that a library UI can fetch a work with its authors and
+// publications in a single request. The schema is defined in schema.graphql.
+package graphql
+
+import (
+	_ "embed"
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/fwojciec/bookid"
+	gql "github.com/graph-gophers/graphql-go"
+	gqlerrors "github.com/graph-gophers/graphql-go/errors"
+)
+
+// MaxDepth is the maximum nesting of fields in a query. It bounds queries
+// following publications back to their works.
+const MaxDepth = 10
+
+//go:embed schema.graphql
+var schema string //nolint:gochecknoglobals // embedded schema
+
+// Handler serves GraphQL queries posted as JSON, with "query",
+// "operationName" and "variables" fields.
+type Handler struct {
+	schema *gql.Schema
+
+	// Services used by the resolvers.
+	BookFinder         bookid.BookFinder
+	WorkService        bookid.WorkService
+	AuthorService      bookid.AuthorService
+	PublicationService bookid.PublicationService
+}
+
+// NewHandler returns a new instance of Handler.
+func NewHandler() *Handler {
+	h := &Handler{}
+	h.schema = gql.MustParseSchema(schema, &queryResolver{h: h}, gql.MaxDepth(MaxDepth))
+	return h
+}
+
+// request represents the JSON body of a GraphQL request.
+type request struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+}
+
+// ServeHTTP executes the query of the request. Errors of resolvers are
+// reported in the "errors" field of the response along with their bookid
+// error code, in the "code" extension.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req request
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, r, http.StatusBadRequest, &gql.Response{
+			Errors: []*gqlerrors.QueryError{{Message: "Invalid JSON body.", Extensions: map[string]any{"code": bookid.EINVALID}}},
+		})
+		return
+	}
+
+	resp := h.schema.Exec(r.Context(), req.Query, req.OperationName, req.Variables)
+	writeJSON(w, r, http.StatusOK, resp)
+}
+
+// Error returns err as a GraphQL error carrying its bookid error code.
+// Internal errors are logged; their details are not shown to the caller.
+func Error(err error) error {
+	code, message := bookid.ErrorCode(err), bookid.ErrorMessage(err)
+	if code == bookid.EINTERNAL {
+		log.Printf("[graphql] error: %s", err)
+	}
+	return &queryError{code: code, message: message}
+}
+
+// queryError is an error reported to the caller with its bookid error code.
+type queryError struct {
+	code    string
+	message string
+}
+
+// Error implements the error interface.
+func (e *queryError) Error() string {
+	return e.message
+}
+
+// Extensions returns the extensions of the error in the response.
+func (e *queryError) Extensions() map[string]any {
+	return map[string]any{"code": e.code}
+}
+
+// writeJSON writes v as the JSON response body with the given status code.
+func writeJSON(w http.ResponseWriter, r *http.Request, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("[graphql] error: %s %s: encoding response: %s", r.Method, r.URL.Path, err)
+	}
+}
@@ -0,0 +1,176 @@
+package graphql_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/graphql"
+	"github.com/fwojciec/bookid/mock"
+	"github.com/fwojciec/bookid/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// MustOpenHandler returns a handler backed by an in-memory catalog, along
+// with the catalog service to populate it.
+func MustOpenHandler(tb testing.TB, finder bookid.BookFinder) (*graphql.Handler, bookid.CatalogService) {
+	tb.Helper()
+	db := sqlite.NewDB(":memory:")
+	require.NoError(tb, db.Open())
+	tb.Cleanup(func() { _ = db.Close() })
+
+	h := graphql.NewHandler()
+	h.BookFinder = finder
+	h.WorkService = sqlite.NewWorkService(db)
+	h.AuthorService = sqlite.NewAuthorService(db)
+	h.PublicationService = sqlite.NewPublicationService(db)
+	return h, sqlite.NewCatalogService(db)
+}
+
+// response is the JSON body of a GraphQL response.
+type response struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []struct {
+		Message    string            `json:"message"`
+		Extensions map[string]string `json:"extensions"`
+	} `json:"errors"`
+}
+
+// exec posts query with variables to h and decodes the response.
+func exec(tb testing.TB, h http.Handler, query string, variables map[string]any) response {
+	tb.Helper()
+	body, err := json.Marshal(map[string]any{"query": query, "variables": variables})
+	require.NoError(tb, err)
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(string(body))))
+	require.Equal(tb, http.StatusOK, w.Code)
+	assert.Equal(tb, "application/json", w.Header().Get("Content-Type"))
+
+	var resp response
+	require.NoError(tb, json.NewDecoder(w.Body).Decode(&resp))
+	return resp
+}
+
+func TestHandler_Search(t *testing.T) {
+	t.Parallel()
+
+	t.Run("OK", func(t *testing.T) {
+		t.Parallel()
+		h, _ := MustOpenHandler(t, &mock.BookFinder{SearchFn: func(_ context.Context, query string, opts bookid.SearchOptions) ([]bookid.BookResult, error) {
+			assert.Equal(t, "dune", query)
+			assert.Equal(t, bookid.SearchOptions{MaxResults: 3}, opts)
+			return []bookid.BookResult{{
+				Title:      "Dune",
+				Authors:    []string{"Frank Herbert"},
+				ISBN13:     "9780441172719",
+				Metadata:   map[string]string{"pages": "604", "binding": "Paperback"},
+				Confidence: 0.9,
+				SearchType: bookid.SearchTypeTitle,
+			}}, nil
+		}})
+
+		resp := exec(t, h, `query($q: String!) {
+			search(query: $q, maxResults: 3) { title authors isbn10 isbn13 metadata { key value } confidence searchType }
+		}`, map[string]any{"q": "dune"})
+		require.Empty(t, resp.Errors)
+		assert.JSONEq(t, `{"search": [{
+			"title": "Dune",
+			"authors": ["Frank Herbert"],
+			"isbn10": null,
+			"isbn13": "9780441172719",
+			"metadata": [{"key": "binding", "value": "Paperback"}, {"key": "pages", "value": "604"}],
+			"confidence": 0.9,
+			"searchType": "title"
+		}]}`, string(resp.Data))
+	})
+
+	t.Run("ErrInvalid", func(t *testing.T) {
+		t.Parallel()
+		h, _ := MustOpenHandler(t, nil)
+
+		resp := exec(t, h, `{ search(query: "") { title } }`, nil)
+		require.Len(t, resp.Errors, 1)
+		assert.Equal(t, "Query required.", resp.Errors[0].Message)
+		assert.Equal(t, bookid.EINVALID, resp.Errors[0].Extensions["code"])
+	})
+
+	t.Run("ErrInternal", func(t *testing.T) {
+		t.Parallel()
+		h, _ := MustOpenHandler(t, &mock.BookFinder{SearchFn: func(context.Context, string, bookid.SearchOptions) ([]bookid.BookResult, error) {
+			return nil, assert.AnError
+		}})
+
+		resp := exec(t, h, `{ search(query: "dune") { title } }`, nil)
+		require.Len(t, resp.Errors, 1)
+		assert.Equal(t, "Internal error.", resp.Errors[0].Message)
+		assert.Equal(t, bookid.EINTERNAL, resp.Errors[0].Extensions["code"])
+	})
+}
+
+func TestHandler_Work(t *testing.T) {
+	t.Parallel()
+	h, catalog := MustOpenHandler(t, nil)
+	workID, _, err := catalog.SaveResult(context.Background(), bookid.BookResult{
+		Title:         "Dune",
+		Authors:       []string{"Frank Herbert"},
+		ISBN13:        "9780441172719",
+		Publisher:     "Ace",
+		PublishedYear: 1990,
+	})
+	require.NoError(t, err)
+
+	t.Run("OK", func(t *testing.T) {
+		t.Parallel()
+		resp := exec(t, h, `query($id: ID!) {
+			work(id: $id) {
+				title
+				authors { name viafId }
+				publications { isbn13 publisher publishedYear work { title } }
+			}
+		}`, map[string]any{"id": strconv.FormatInt(workID, 10)})
+		require.Empty(t, resp.Errors)
+		assert.JSONEq(t, `{"work": {
+			"title": "Dune",
+			"authors": [{"name": "Frank Herbert", "viafId": null}],
+			"publications": [{"isbn13": "9780441172719", "publisher": "Ace", "publishedYear": 1990, "work": {"title": "Dune"}}]
+		}}`, string(resp.Data))
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		t.Parallel()
+		resp := exec(t, h, `{ work(id: "999") { title } }`, nil)
+		require.Empty(t, resp.Errors)
+		assert.JSONEq(t, `{"work": null}`, string(resp.Data))
+	})
+
+	t.Run("ErrInvalidID", func(t *testing.T) {
+		t.Parallel()
+		resp := exec(t, h, `{ work(id: "abc") { title } }`, nil)
+		require.Len(t, resp.Errors, 1)
+		assert.Equal(t, bookid.EINVALID, resp.Errors[0].Extensions["code"])
+	})
+
+	t.Run("Works", func(t *testing.T) {
+		t.Parallel()
+		resp := exec(t, h, `{ works(filter: {query: "dun", limit: 10}) { total works { title } } }`, nil)
+		require.Empty(t, resp.Errors)
+		assert.JSONEq(t, `{"works": {"total": 1, "works": [{"title": "Dune"}]}}`, string(resp.Data))
+	})
+}
+
+func TestHandler_ServeHTTP(t *testing.T) {
+	t.Parallel()
+	h, _ := MustOpenHandler(t, nil)
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader("{")))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "Invalid JSON body.")
+}
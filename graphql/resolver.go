@@ -0,0 +1,379 @@
+package graphql
+
+import (
+	"context"
+	"sort"
+	"strconv"
+
+	"github.com/fwojciec/bookid"
+	gql "github.com/graph-gophers/graphql-go"
+)
+
+// queryResolver resolves the fields of the Query type.
+type queryResolver struct {
+	h *Handler
+}
+
+// Search resolves Query.search.
+func (q *queryResolver) Search(ctx context.Context, args struct {
+	Query         string
+	MaxResults    *int32
+	Language      *string
+	MinConfidence *float64
+},
+) ([]*bookResultResolver, error) {
+	if args.Query == "" {
+		return nil, Error(bookid.Errorf(bookid.EINVALID, "Query required."))
+	}
+
+	var opts bookid.SearchOptions
+	if args.MaxResults != nil {
+		if *args.MaxResults < 0 {
+			return nil, Error(bookid.Errorf(bookid.EINVALID, "Invalid max results."))
+		}
+		opts.MaxResults = int(*args.MaxResults)
+	}
+	if args.Language != nil {
+		opts.Language = *args.Language
+	}
+	if args.MinConfidence != nil {
+		opts.MinConfidence = *args.MinConfidence
+	}
+	if err := opts.Validate(); err != nil {
+		return nil, Error(err)
+	}
+
+	results, err := q.h.BookFinder.Search(ctx, args.Query, opts)
+	if err != nil {
+		return nil, Error(err)
+	}
+	rs := make([]*bookResultResolver, len(results))
+	for i := range results {
+		rs[i] = &bookResultResolver{result: &results[i]}
+	}
+	return rs, nil
+}
+
+// Work resolves Query.work. A work that does not exist resolves to null.
+func (q *queryResolver) Work(ctx context.Context, args struct{ ID gql.ID }) (*workResolver, error) {
+	id, err := parseID(args.ID)
+	if err != nil {
+		return nil, Error(err)
+	}
+
+	work, err := q.h.WorkService.FindWorkByID(ctx, id)
+	if bookid.ErrorCode(err) == bookid.ENOTFOUND {
+		return nil, nil //nolint:nilnil // null in the response
+	} else if err != nil {
+		return nil, Error(err)
+	}
+	return &workResolver{h: q.h, work: work}, nil
+}
+
+// workFilterInput is the WorkFilter input type.
+type workFilterInput struct {
+	Title    *string
+	Author   *string
+	Query    *string
+	Language *string
+	Series   *string
+	Subject  *string
+	Offset   *int32
+	Limit    *int32
+}
+
+// Works resolves Query.works.
+func (q *queryResolver) Works(ctx context.Context, args struct{ Filter *workFilterInput }) (*workConnectionResolver, error) {
+	var filter bookid.WorkFilter
+	if f := args.Filter; f != nil {
+		filter.Title, filter.Author, filter.Query, filter.Language = f.Title, f.Author, f.Query, f.Language
+		filter.Series, filter.Subject = f.Series, f.Subject
+		if (f.Offset != nil && *f.Offset < 0) || (f.Limit != nil && *f.Limit < 0) {
+			return nil, Error(bookid.Errorf(bookid.EINVALID, "Offset and limit must not be negative."))
+		}
+		if f.Offset != nil {
+			filter.Offset = int(*f.Offset)
+		}
+		if f.Limit != nil {
+			filter.Limit = int(*f.Limit)
+		}
+	}
+
+	works, n, err := q.h.WorkService.FindWorks(ctx, filter)
+	if err != nil {
+		return nil, Error(err)
+	}
+	conn := &workConnectionResolver{works: make([]*workResolver, len(works)), total: int32(n)}
+	for i, work := range works {
+		conn.works[i] = &workResolver{h: q.h, work: work}
+	}
+	return conn, nil
+}
+
+// workConnectionResolver resolves the fields of the WorkConnection type.
+type workConnectionResolver struct {
+	works []*workResolver
+	total int32
+}
+
+// Works resolves WorkConnection.works.
+func (c *workConnectionResolver) Works() []*workResolver { return c.works }
+
+// Total resolves WorkConnection.total.
+func (c *workConnectionResolver) Total() int32 { return c.total }
+
+// workResolver resolves the fields of the Work type.
+type workResolver struct {
+	h    *Handler
+	work *bookid.Work
+}
+
+// ID resolves Work.id.
+func (r *workResolver) ID() gql.ID { return marshalID(r.work.ID) }
+
+// Title resolves Work.title.
+func (r *workResolver) Title() string { return r.work.Title }
+
+// Author resolves Work.author.
+func (r *workResolver) Author() string { return r.work.Author }
+
+// CreatedAt resolves Work.createdAt.
+func (r *workResolver) CreatedAt() gql.Time { return gql.Time{Time: r.work.CreatedAt} }
+
+// UpdatedAt resolves Work.updatedAt.
+func (r *workResolver) UpdatedAt() gql.Time { return gql.Time{Time: r.work.UpdatedAt} }
+
+// Authors resolves Work.authors.
+func (r *workResolver) Authors(ctx context.Context) ([]*authorResolver, error) {
+	authors, _, err := r.h.AuthorService.FindAuthors(ctx, bookid.AuthorFilter{WorkID: &r.work.ID})
+	if err != nil {
+		return nil, Error(err)
+	}
+	rs := make([]*authorResolver, len(authors))
+	for i, author := range authors {
+		rs[i] = &authorResolver{author: author}
+	}
+	return rs, nil
+}
+
+// Publications resolves Work.publications.
+func (r *workResolver) Publications(ctx context.Context) ([]*publicationResolver, error) {
+	pubs, _, err := r.h.PublicationService.FindPublications(ctx, bookid.PublicationFilter{WorkID: &r.work.ID})
+	if err != nil {
+		return nil, Error(err)
+	}
+	rs := make([]*publicationResolver, len(pubs))
+	for i, pub := range pubs {
+		rs[i] = &publicationResolver{h: r.h, pub: pub}
+	}
+	return rs, nil
+}
+
+// authorResolver resolves the fields of the Author type.
+type authorResolver struct {
+	author *bookid.Author
+}
+
+// ID resolves Author.id.
+func (r *authorResolver) ID() gql.ID { return marshalID(r.author.ID) }
+
+// Name resolves Author.name.
+func (r *authorResolver) Name() string { return r.author.Name }
+
+// VIAFID resolves Author.viafId.
+func (r *authorResolver) VIAFID() *string { return optional(r.author.VIAFID) }
+
+// WikidataID resolves Author.wikidataId.
+func (r *authorResolver) WikidataID() *string { return optional(r.author.WikidataID) }
+
+// publicationResolver resolves the fields of the Publication type.
+type publicationResolver struct {
+	h   *Handler
+	pub *bookid.Publication
+}
+
+// ID resolves Publication.id.
+func (r *publicationResolver) ID() gql.ID { return marshalID(r.pub.ID) }
+
+// Work resolves Publication.work.
+func (r *publicationResolver) Work(ctx context.Context) (*workResolver, error) {
+	work, err := r.h.WorkService.FindWorkByID(ctx, r.pub.WorkID)
+	if err != nil {
+		return nil, Error(err)
+	}
+	return &workResolver{h: r.h, work: work}, nil
+}
+
+// ISBN10 resolves Publication.isbn10.
+func (r *publicationResolver) ISBN10() *string { return optional(r.pub.ISBN10) }
+
+// ISBN13 resolves Publication.isbn13.
+func (r *publicationResolver) ISBN13() *string { return optional(r.pub.ISBN13) }
+
+// Publisher resolves Publication.publisher.
+func (r *publicationResolver) Publisher() *string { return optional(r.pub.Publisher) }
+
+// PublishedYear resolves Publication.publishedYear.
+func (r *publicationResolver) PublishedYear() *int32 { return optionalInt(r.pub.PublishedYear) }
+
+// Language resolves Publication.language.
+func (r *publicationResolver) Language() *string { return optional(r.pub.Language) }
+
+// GoogleBooksVolumeID resolves Publication.googleBooksVolumeId.
+func (r *publicationResolver) GoogleBooksVolumeID() *string {
+	return optional(r.pub.GoogleBooksVolumeID)
+}
+
+// OCLCNumber resolves Publication.oclcNumber.
+func (r *publicationResolver) OCLCNumber() *string { return optional(r.pub.OCLCNumber) }
+
+// LCCN resolves Publication.lccn.
+func (r *publicationResolver) LCCN() *string { return optional(r.pub.LCCN) }
+
+// DOI resolves Publication.doi.
+func (r *publicationResolver) DOI() *string { return optional(r.pub.DOI) }
+
+// ThumbnailURL resolves Publication.thumbnailUrl.
+func (r *publicationResolver) ThumbnailURL() *string { return optional(r.pub.ThumbnailURL) }
+
+// CreatedAt resolves Publication.createdAt.
+func (r *publicationResolver) CreatedAt() gql.Time { return gql.Time{Time: r.pub.CreatedAt} }
+
+// UpdatedAt resolves Publication.updatedAt.
+func (r *publicationResolver) UpdatedAt() gql.Time { return gql.Time{Time: r.pub.UpdatedAt} }
+
+// bookResultResolver resolves the fields of the BookResult type.
+type bookResultResolver struct {
+	result *bookid.BookResult
+}
+
+// Title resolves BookResult.title.
+func (r *bookResultResolver) Title() string { return r.result.Title }
+
+// Authors resolves BookResult.authors.
+func (r *bookResultResolver) Authors() []string {
+	if r.result.Authors == nil {
+		return []string{}
+	}
+	return r.result.Authors
+}
+
+// ISBN10 resolves BookResult.isbn10.
+func (r *bookResultResolver) ISBN10() *string { return optional(r.result.ISBN10) }
+
+// ISBN13 resolves BookResult.isbn13.
+func (r *bookResultResolver) ISBN13() *string { return optional(r.result.ISBN13) }
+
+// Publisher resolves BookResult.publisher.
+func (r *bookResultResolver) Publisher() *string { return optional(r.result.Publisher) }
+
+// PublishedYear resolves BookResult.publishedYear.
+func (r *bookResultResolver) PublishedYear() *int32 { return optionalInt(r.result.PublishedYear) }
+
+// Language resolves BookResult.language.
+func (r *bookResultResolver) Language() *string { return optional(r.result.Language) }
+
+// GoogleBooksVolumeID resolves BookResult.googleBooksVolumeId.
+func (r *bookResultResolver) GoogleBooksVolumeID() *string {
+	return optional(r.result.GoogleBooksVolumeID)
+}
+
+// OCLCNumber resolves BookResult.oclcNumber.
+func (r *bookResultResolver) OCLCNumber() *string { return optional(r.result.OCLCNumber) }
+
+// LCCN resolves BookResult.lccn.
+func (r *bookResultResolver) LCCN() *string { return optional(r.result.LCCN) }
+
+// DOI resolves BookResult.doi.
+func (r *bookResultResolver) DOI() *string { return optional(r.result.DOI) }
+
+// ThumbnailURL resolves BookResult.thumbnailUrl.
+func (r *bookResultResolver) ThumbnailURL() *string { return optional(r.result.ThumbnailURL) }
+
+// Series resolves BookResult.series.
+func (r *bookResultResolver) Series() *string { return optional(r.result.Series) }
+
+// SeriesVolume resolves BookResult.seriesVolume.
+func (r *bookResultResolver) SeriesVolume() *float64 {
+	if r.result.SeriesVolume == 0 {
+		return nil
+	}
+	return &r.result.SeriesVolume
+}
+
+// Subjects resolves BookResult.subjects.
+func (r *bookResultResolver) Subjects() []string {
+	if r.result.Subjects == nil {
+		return []string{}
+	}
+	return r.result.Subjects
+}
+
+// BISACCodes resolves BookResult.bisacCodes.
+func (r *bookResultResolver) BISACCodes() []string {
+	if r.result.BISACCodes == nil {
+		return []string{}
+	}
+	return r.result.BISACCodes
+}
+
+// Provider resolves BookResult.provider.
+func (r *bookResultResolver) Provider() *string { return optional(r.result.Provider) }
+
+// Metadata resolves BookResult.metadata.
+func (r *bookResultResolver) Metadata() []*metadataEntryResolver {
+	rs := make([]*metadataEntryResolver, 0, len(r.result.Metadata))
+	for k, v := range r.result.Metadata {
+		rs = append(rs, &metadataEntryResolver{key: k, value: v})
+	}
+	sort.Slice(rs, func(i, j int) bool { return rs[i].key < rs[j].key })
+	return rs
+}
+
+// Confidence resolves BookResult.confidence.
+func (r *bookResultResolver) Confidence() float64 { return r.result.Confidence }
+
+// SearchType resolves BookResult.searchType.
+func (r *bookResultResolver) SearchType() string { return string(r.result.SearchType) }
+
+// metadataEntryResolver resolves the fields of the MetadataEntry type.
+type metadataEntryResolver struct {
+	key, value string
+}
+
+// Key resolves MetadataEntry.key.
+func (r *metadataEntryResolver) Key() string { return r.key }
+
+// Value resolves MetadataEntry.value.
+func (r *metadataEntryResolver) Value() string { return r.value }
+
+// marshalID returns the GraphQL ID of a catalog ID.
+func marshalID(id int64) gql.ID {
+	return gql.ID(strconv.FormatInt(id, 10))
+}
+
+// parseID parses a GraphQL ID into a catalog ID.
+func parseID(id gql.ID) (int64, error) {
+	v, err := strconv.ParseInt(string(id), 10, 64)
+	if err != nil {
+		return 0, bookid.Errorf(bookid.EINVALID, "Invalid ID format.")
+	}
+	return v, nil
+}
+
+// optional returns a pointer to s, or nil if s is empty, for nullable fields.
+func optional(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+// optionalInt returns a pointer to n, or nil if n is zero, for nullable fields.
+func optionalInt(n int) *int32 {
+	if n == 0 {
+		return nil
+	}
+	v := int32(n)
+	return &v
+}
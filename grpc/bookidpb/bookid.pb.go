@@ -0,0 +1,993 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: grpc/bookidpb/bookid.proto
+
+package bookidpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type SearchRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Query string                 `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	// Maximum number of results. Zero uses the provider's default.
+	MaxResults int32 `protobuf:"varint,2,opt,name=max_results,json=maxResults,proto3" json:"max_results,omitempty"`
+	// Zero-based index of the first result, for paging through results.
+	StartIndex int32 `protobuf:"varint,3,opt,name=start_index,json=startIndex,proto3" json:"start_index,omitempty"`
+	// Restricts results to an ISO 639-1 language code, e.g. "en".
+	Language string `protobuf:"bytes,4,opt,name=language,proto3" json:"language,omitempty"`
+	// Restricts results to "books" or "magazines". Empty means all.
+	PrintType string `protobuf:"bytes,5,opt,name=print_type,json=printType,proto3" json:"print_type,omitempty"`
+	// Order of results, "relevance" or "newest". Empty means by relevance.
+	OrderBy string `protobuf:"bytes,6,opt,name=order_by,json=orderBy,proto3" json:"order_by,omitempty"`
+	// Results with a lower confidence are dropped.
+	MinConfidence float64 `protobuf:"fixed64,7,opt,name=min_confidence,json=minConfidence,proto3" json:"min_confidence,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchRequest) Reset() {
+	*x = SearchRequest{}
+	mi := &file_grpc_bookidpb_bookid_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchRequest) ProtoMessage() {}
+
+func (x *SearchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_grpc_bookidpb_bookid_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchRequest.ProtoReflect.Descriptor instead.
+func (*SearchRequest) Descriptor() ([]byte, []int) {
+	return file_grpc_bookidpb_bookid_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *SearchRequest) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
+func (x *SearchRequest) GetMaxResults() int32 {
+	if x != nil {
+		return x.MaxResults
+	}
+	return 0
+}
+
+func (x *SearchRequest) GetStartIndex() int32 {
+	if x != nil {
+		return x.StartIndex
+	}
+	return 0
+}
+
+func (x *SearchRequest) GetLanguage() string {
+	if x != nil {
+		return x.Language
+	}
+	return ""
+}
+
+func (x *SearchRequest) GetPrintType() string {
+	if x != nil {
+		return x.PrintType
+	}
+	return ""
+}
+
+func (x *SearchRequest) GetOrderBy() string {
+	if x != nil {
+		return x.OrderBy
+	}
+	return ""
+}
+
+func (x *SearchRequest) GetMinConfidence() float64 {
+	if x != nil {
+		return x.MinConfidence
+	}
+	return 0
+}
+
+type SearchResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Results       []*BookResult          `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchResponse) Reset() {
+	*x = SearchResponse{}
+	mi := &file_grpc_bookidpb_bookid_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchResponse) ProtoMessage() {}
+
+func (x *SearchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_grpc_bookidpb_bookid_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchResponse.ProtoReflect.Descriptor instead.
+func (*SearchResponse) Descriptor() ([]byte, []int) {
+	return file_grpc_bookidpb_bookid_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *SearchResponse) GetResults() []*BookResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+// BookResult is a book identified by a provider.
+type BookResult struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	Title               string                 `protobuf:"bytes,1,opt,name=title,proto3" json:"title,omitempty"`
+	Authors             []string               `protobuf:"bytes,2,rep,name=authors,proto3" json:"authors,omitempty"`
+	Isbn10              string                 `protobuf:"bytes,3,opt,name=isbn10,proto3" json:"isbn10,omitempty"`
+	Isbn13              string                 `protobuf:"bytes,4,opt,name=isbn13,proto3" json:"isbn13,omitempty"`
+	Publisher           string                 `protobuf:"bytes,5,opt,name=publisher,proto3" json:"publisher,omitempty"`
+	PublishedYear       int32                  `protobuf:"varint,6,opt,name=published_year,json=publishedYear,proto3" json:"published_year,omitempty"`
+	Language            string                 `protobuf:"bytes,7,opt,name=language,proto3" json:"language,omitempty"`
+	GoogleBooksVolumeId string                 `protobuf:"bytes,8,opt,name=google_books_volume_id,json=googleBooksVolumeId,proto3" json:"google_books_volume_id,omitempty"`
+	OclcNumber          string                 `protobuf:"bytes,9,opt,name=oclc_number,json=oclcNumber,proto3" json:"oclc_number,omitempty"`
+	Lccn                string                 `protobuf:"bytes,10,opt,name=lccn,proto3" json:"lccn,omitempty"`
+	Doi                 string                 `protobuf:"bytes,11,opt,name=doi,proto3" json:"doi,omitempty"`
+	ThumbnailUrl        string                 `protobuf:"bytes,12,opt,name=thumbnail_url,json=thumbnailUrl,proto3" json:"thumbnail_url,omitempty"`
+	// Name of the provider that produced the result.
+	Provider string `protobuf:"bytes,13,opt,name=provider,proto3" json:"provider,omitempty"`
+	// Provider-specific details without a dedicated field, e.g. binding or
+	// page count, keyed by snake_case name.
+	Metadata map[string]string `protobuf:"bytes,14,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// Confidence that the result is the queried book, from 0 to 1.
+	Confidence float64 `protobuf:"fixed64,15,opt,name=confidence,proto3" json:"confidence,omitempty"`
+	// Kind of search the query was identified as, e.g. "isbn" or "title".
+	SearchType    string `protobuf:"bytes,16,opt,name=search_type,json=searchType,proto3" json:"search_type,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BookResult) Reset() {
+	*x = BookResult{}
+	mi := &file_grpc_bookidpb_bookid_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BookResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BookResult) ProtoMessage() {}
+
+func (x *BookResult) ProtoReflect() protoreflect.Message {
+	mi := &file_grpc_bookidpb_bookid_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BookResult.ProtoReflect.Descriptor instead.
+func (*BookResult) Descriptor() ([]byte, []int) {
+	return file_grpc_bookidpb_bookid_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *BookResult) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *BookResult) GetAuthors() []string {
+	if x != nil {
+		return x.Authors
+	}
+	return nil
+}
+
+func (x *BookResult) GetIsbn10() string {
+	if x != nil {
+		return x.Isbn10
+	}
+	return ""
+}
+
+func (x *BookResult) GetIsbn13() string {
+	if x != nil {
+		return x.Isbn13
+	}
+	return ""
+}
+
+func (x *BookResult) GetPublisher() string {
+	if x != nil {
+		return x.Publisher
+	}
+	return ""
+}
+
+func (x *BookResult) GetPublishedYear() int32 {
+	if x != nil {
+		return x.PublishedYear
+	}
+	return 0
+}
+
+func (x *BookResult) GetLanguage() string {
+	if x != nil {
+		return x.Language
+	}
+	return ""
+}
+
+func (x *BookResult) GetGoogleBooksVolumeId() string {
+	if x != nil {
+		return x.GoogleBooksVolumeId
+	}
+	return ""
+}
+
+func (x *BookResult) GetOclcNumber() string {
+	if x != nil {
+		return x.OclcNumber
+	}
+	return ""
+}
+
+func (x *BookResult) GetLccn() string {
+	if x != nil {
+		return x.Lccn
+	}
+	return ""
+}
+
+func (x *BookResult) GetDoi() string {
+	if x != nil {
+		return x.Doi
+	}
+	return ""
+}
+
+func (x *BookResult) GetThumbnailUrl() string {
+	if x != nil {
+		return x.ThumbnailUrl
+	}
+	return ""
+}
+
+func (x *BookResult) GetProvider() string {
+	if x != nil {
+		return x.Provider
+	}
+	return ""
+}
+
+func (x *BookResult) GetMetadata() map[string]string {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+func (x *BookResult) GetConfidence() float64 {
+	if x != nil {
+		return x.Confidence
+	}
+	return 0
+}
+
+func (x *BookResult) GetSearchType() string {
+	if x != nil {
+		return x.SearchType
+	}
+	return ""
+}
+
+type SaveResultRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Result        *BookResult            `protobuf:"bytes,1,opt,name=result,proto3" json:"result,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SaveResultRequest) Reset() {
+	*x = SaveResultRequest{}
+	mi := &file_grpc_bookidpb_bookid_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SaveResultRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SaveResultRequest) ProtoMessage() {}
+
+func (x *SaveResultRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_grpc_bookidpb_bookid_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SaveResultRequest.ProtoReflect.Descriptor instead.
+func (*SaveResultRequest) Descriptor() ([]byte, []int) {
+	return file_grpc_bookidpb_bookid_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *SaveResultRequest) GetResult() *BookResult {
+	if x != nil {
+		return x.Result
+	}
+	return nil
+}
+
+type SaveResultResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	WorkId        int64                  `protobuf:"varint,1,opt,name=work_id,json=workId,proto3" json:"work_id,omitempty"`
+	PublicationId int64                  `protobuf:"varint,2,opt,name=publication_id,json=publicationId,proto3" json:"publication_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SaveResultResponse) Reset() {
+	*x = SaveResultResponse{}
+	mi := &file_grpc_bookidpb_bookid_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SaveResultResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SaveResultResponse) ProtoMessage() {}
+
+func (x *SaveResultResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_grpc_bookidpb_bookid_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SaveResultResponse.ProtoReflect.Descriptor instead.
+func (*SaveResultResponse) Descriptor() ([]byte, []int) {
+	return file_grpc_bookidpb_bookid_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *SaveResultResponse) GetWorkId() int64 {
+	if x != nil {
+		return x.WorkId
+	}
+	return 0
+}
+
+func (x *SaveResultResponse) GetPublicationId() int64 {
+	if x != nil {
+		return x.PublicationId
+	}
+	return 0
+}
+
+type GetWorkRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetWorkRequest) Reset() {
+	*x = GetWorkRequest{}
+	mi := &file_grpc_bookidpb_bookid_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetWorkRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetWorkRequest) ProtoMessage() {}
+
+func (x *GetWorkRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_grpc_bookidpb_bookid_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetWorkRequest.ProtoReflect.Descriptor instead.
+func (*GetWorkRequest) Descriptor() ([]byte, []int) {
+	return file_grpc_bookidpb_bookid_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *GetWorkRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+// Work is an abstract creative work, published in one or more editions.
+type Work struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Title         string                 `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
+	Author        string                 `protobuf:"bytes,3,opt,name=author,proto3" json:"author,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt     *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Work) Reset() {
+	*x = Work{}
+	mi := &file_grpc_bookidpb_bookid_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Work) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Work) ProtoMessage() {}
+
+func (x *Work) ProtoReflect() protoreflect.Message {
+	mi := &file_grpc_bookidpb_bookid_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Work.ProtoReflect.Descriptor instead.
+func (*Work) Descriptor() ([]byte, []int) {
+	return file_grpc_bookidpb_bookid_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Work) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Work) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *Work) GetAuthor() string {
+	if x != nil {
+		return x.Author
+	}
+	return ""
+}
+
+func (x *Work) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Work) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+type ListPublicationsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Only list publications of this work, if not zero.
+	WorkId int64 `protobuf:"varint,1,opt,name=work_id,json=workId,proto3" json:"work_id,omitempty"`
+	// Only list publications with this ISBN-10 or ISBN-13, if not empty.
+	Isbn   string `protobuf:"bytes,2,opt,name=isbn,proto3" json:"isbn,omitempty"`
+	Offset int32  `protobuf:"varint,3,opt,name=offset,proto3" json:"offset,omitempty"`
+	// Maximum number of publications. Zero means no limit.
+	Limit         int32 `protobuf:"varint,4,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListPublicationsRequest) Reset() {
+	*x = ListPublicationsRequest{}
+	mi := &file_grpc_bookidpb_bookid_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListPublicationsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListPublicationsRequest) ProtoMessage() {}
+
+func (x *ListPublicationsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_grpc_bookidpb_bookid_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListPublicationsRequest.ProtoReflect.Descriptor instead.
+func (*ListPublicationsRequest) Descriptor() ([]byte, []int) {
+	return file_grpc_bookidpb_bookid_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ListPublicationsRequest) GetWorkId() int64 {
+	if x != nil {
+		return x.WorkId
+	}
+	return 0
+}
+
+func (x *ListPublicationsRequest) GetIsbn() string {
+	if x != nil {
+		return x.Isbn
+	}
+	return ""
+}
+
+func (x *ListPublicationsRequest) GetOffset() int32 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *ListPublicationsRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type ListPublicationsResponse struct {
+	state        protoimpl.MessageState `protogen:"open.v1"`
+	Publications []*Publication         `protobuf:"bytes,1,rep,name=publications,proto3" json:"publications,omitempty"`
+	// Number of matching publications, ignoring offset and limit.
+	Total         int32 `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListPublicationsResponse) Reset() {
+	*x = ListPublicationsResponse{}
+	mi := &file_grpc_bookidpb_bookid_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListPublicationsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListPublicationsResponse) ProtoMessage() {}
+
+func (x *ListPublicationsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_grpc_bookidpb_bookid_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListPublicationsResponse.ProtoReflect.Descriptor instead.
+func (*ListPublicationsResponse) Descriptor() ([]byte, []int) {
+	return file_grpc_bookidpb_bookid_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ListPublicationsResponse) GetPublications() []*Publication {
+	if x != nil {
+		return x.Publications
+	}
+	return nil
+}
+
+func (x *ListPublicationsResponse) GetTotal() int32 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+// Publication is a specific published edition of a work.
+type Publication struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	Id                  int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	WorkId              int64                  `protobuf:"varint,2,opt,name=work_id,json=workId,proto3" json:"work_id,omitempty"`
+	Isbn10              string                 `protobuf:"bytes,3,opt,name=isbn10,proto3" json:"isbn10,omitempty"`
+	Isbn13              string                 `protobuf:"bytes,4,opt,name=isbn13,proto3" json:"isbn13,omitempty"`
+	Publisher           string                 `protobuf:"bytes,5,opt,name=publisher,proto3" json:"publisher,omitempty"`
+	PublishedYear       int32                  `protobuf:"varint,6,opt,name=published_year,json=publishedYear,proto3" json:"published_year,omitempty"`
+	Language            string                 `protobuf:"bytes,7,opt,name=language,proto3" json:"language,omitempty"`
+	GoogleBooksVolumeId string                 `protobuf:"bytes,8,opt,name=google_books_volume_id,json=googleBooksVolumeId,proto3" json:"google_books_volume_id,omitempty"`
+	OclcNumber          string                 `protobuf:"bytes,9,opt,name=oclc_number,json=oclcNumber,proto3" json:"oclc_number,omitempty"`
+	Lccn                string                 `protobuf:"bytes,10,opt,name=lccn,proto3" json:"lccn,omitempty"`
+	Doi                 string                 `protobuf:"bytes,11,opt,name=doi,proto3" json:"doi,omitempty"`
+	ThumbnailUrl        string                 `protobuf:"bytes,12,opt,name=thumbnail_url,json=thumbnailUrl,proto3" json:"thumbnail_url,omitempty"`
+	CreatedAt           *timestamppb.Timestamp `protobuf:"bytes,13,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt           *timestamppb.Timestamp `protobuf:"bytes,14,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *Publication) Reset() {
+	*x = Publication{}
+	mi := &file_grpc_bookidpb_bookid_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Publication) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Publication) ProtoMessage() {}
+
+func (x *Publication) ProtoReflect() protoreflect.Message {
+	mi := &file_grpc_bookidpb_bookid_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Publication.ProtoReflect.Descriptor instead.
+func (*Publication) Descriptor() ([]byte, []int) {
+	return file_grpc_bookidpb_bookid_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *Publication) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Publication) GetWorkId() int64 {
+	if x != nil {
+		return x.WorkId
+	}
+	return 0
+}
+
+func (x *Publication) GetIsbn10() string {
+	if x != nil {
+		return x.Isbn10
+	}
+	return ""
+}
+
+func (x *Publication) GetIsbn13() string {
+	if x != nil {
+		return x.Isbn13
+	}
+	return ""
+}
+
+func (x *Publication) GetPublisher() string {
+	if x != nil {
+		return x.Publisher
+	}
+	return ""
+}
+
+func (x *Publication) GetPublishedYear() int32 {
+	if x != nil {
+		return x.PublishedYear
+	}
+	return 0
+}
+
+func (x *Publication) GetLanguage() string {
+	if x != nil {
+		return x.Language
+	}
+	return ""
+}
+
+func (x *Publication) GetGoogleBooksVolumeId() string {
+	if x != nil {
+		return x.GoogleBooksVolumeId
+	}
+	return ""
+}
+
+func (x *Publication) GetOclcNumber() string {
+	if x != nil {
+		return x.OclcNumber
+	}
+	return ""
+}
+
+func (x *Publication) GetLccn() string {
+	if x != nil {
+		return x.Lccn
+	}
+	return ""
+}
+
+func (x *Publication) GetDoi() string {
+	if x != nil {
+		return x.Doi
+	}
+	return ""
+}
+
+func (x *Publication) GetThumbnailUrl() string {
+	if x != nil {
+		return x.ThumbnailUrl
+	}
+	return ""
+}
+
+func (x *Publication) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Publication) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+var File_grpc_bookidpb_bookid_proto protoreflect.FileDescriptor
+
+const file_grpc_bookidpb_bookid_proto_rawDesc = "" +
+	"\n" +
+	"\x1agrpc/bookidpb/bookid.proto\x12\tbookid.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\xe4\x01\n" +
+	"\rSearchRequest\x12\x14\n" +
+	"\x05query\x18\x01 \x01(\tR\x05query\x12\x1f\n" +
+	"\vmax_results\x18\x02 \x01(\x05R\n" +
+	"maxResults\x12\x1f\n" +
+	"\vstart_index\x18\x03 \x01(\x05R\n" +
+	"startIndex\x12\x1a\n" +
+	"\blanguage\x18\x04 \x01(\tR\blanguage\x12\x1d\n" +
+	"\n" +
+	"print_type\x18\x05 \x01(\tR\tprintType\x12\x19\n" +
+	"\border_by\x18\x06 \x01(\tR\aorderBy\x12%\n" +
+	"\x0emin_confidence\x18\a \x01(\x01R\rminConfidence\"A\n" +
+	"\x0eSearchResponse\x12/\n" +
+	"\aresults\x18\x01 \x03(\v2\x15.bookid.v1.BookResultR\aresults\"\xc9\x04\n" +
+	"\n" +
+	"BookResult\x12\x14\n" +
+	"\x05title\x18\x01 \x01(\tR\x05title\x12\x18\n" +
+	"\aauthors\x18\x02 \x03(\tR\aauthors\x12\x16\n" +
+	"\x06isbn10\x18\x03 \x01(\tR\x06isbn10\x12\x16\n" +
+	"\x06isbn13\x18\x04 \x01(\tR\x06isbn13\x12\x1c\n" +
+	"\tpublisher\x18\x05 \x01(\tR\tpublisher\x12%\n" +
+	"\x0epublished_year\x18\x06 \x01(\x05R\rpublishedYear\x12\x1a\n" +
+	"\blanguage\x18\a \x01(\tR\blanguage\x123\n" +
+	"\x16google_books_volume_id\x18\b \x01(\tR\x13googleBooksVolumeId\x12\x1f\n" +
+	"\voclc_number\x18\t \x01(\tR\n" +
+	"oclcNumber\x12\x12\n" +
+	"\x04lccn\x18\n" +
+	" \x01(\tR\x04lccn\x12\x10\n" +
+	"\x03doi\x18\v \x01(\tR\x03doi\x12#\n" +
+	"\rthumbnail_url\x18\f \x01(\tR\fthumbnailUrl\x12\x1a\n" +
+	"\bprovider\x18\r \x01(\tR\bprovider\x12?\n" +
+	"\bmetadata\x18\x0e \x03(\v2#.bookid.v1.BookResult.MetadataEntryR\bmetadata\x12\x1e\n" +
+	"\n" +
+	"confidence\x18\x0f \x01(\x01R\n" +
+	"confidence\x12\x1f\n" +
+	"\vsearch_type\x18\x10 \x01(\tR\n" +
+	"searchType\x1a;\n" +
+	"\rMetadataEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"B\n" +
+	"\x11SaveResultRequest\x12-\n" +
+	"\x06result\x18\x01 \x01(\v2\x15.bookid.v1.BookResultR\x06result\"T\n" +
+	"\x12SaveResultResponse\x12\x17\n" +
+	"\awork_id\x18\x01 \x01(\x03R\x06workId\x12%\n" +
+	"\x0epublication_id\x18\x02 \x01(\x03R\rpublicationId\" \n" +
+	"\x0eGetWorkRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\"\xba\x01\n" +
+	"\x04Work\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x14\n" +
+	"\x05title\x18\x02 \x01(\tR\x05title\x12\x16\n" +
+	"\x06author\x18\x03 \x01(\tR\x06author\x129\n" +
+	"\n" +
+	"created_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"updated_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\"t\n" +
+	"\x17ListPublicationsRequest\x12\x17\n" +
+	"\awork_id\x18\x01 \x01(\x03R\x06workId\x12\x12\n" +
+	"\x04isbn\x18\x02 \x01(\tR\x04isbn\x12\x16\n" +
+	"\x06offset\x18\x03 \x01(\x05R\x06offset\x12\x14\n" +
+	"\x05limit\x18\x04 \x01(\x05R\x05limit\"l\n" +
+	"\x18ListPublicationsResponse\x12:\n" +
+	"\fpublications\x18\x01 \x03(\v2\x16.bookid.v1.PublicationR\fpublications\x12\x14\n" +
+	"\x05total\x18\x02 \x01(\x05R\x05total\"\xde\x03\n" +
+	"\vPublication\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x17\n" +
+	"\awork_id\x18\x02 \x01(\x03R\x06workId\x12\x16\n" +
+	"\x06isbn10\x18\x03 \x01(\tR\x06isbn10\x12\x16\n" +
+	"\x06isbn13\x18\x04 \x01(\tR\x06isbn13\x12\x1c\n" +
+	"\tpublisher\x18\x05 \x01(\tR\tpublisher\x12%\n" +
+	"\x0epublished_year\x18\x06 \x01(\x05R\rpublishedYear\x12\x1a\n" +
+	"\blanguage\x18\a \x01(\tR\blanguage\x123\n" +
+	"\x16google_books_volume_id\x18\b \x01(\tR\x13googleBooksVolumeId\x12\x1f\n" +
+	"\voclc_number\x18\t \x01(\tR\n" +
+	"oclcNumber\x12\x12\n" +
+	"\x04lccn\x18\n" +
+	" \x01(\tR\x04lccn\x12\x10\n" +
+	"\x03doi\x18\v \x01(\tR\x03doi\x12#\n" +
+	"\rthumbnail_url\x18\f \x01(\tR\fthumbnailUrl\x129\n" +
+	"\n" +
+	"created_at\x18\r \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"updated_at\x18\x0e \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt2\xa6\x02\n" +
+	"\x06BookID\x12=\n" +
+	"\x06Search\x12\x18.bookid.v1.SearchRequest\x1a\x19.bookid.v1.SearchResponse\x12I\n" +
+	"\n" +
+	"SaveResult\x12\x1c.bookid.v1.SaveResultRequest\x1a\x1d.bookid.v1.SaveResultResponse\x125\n" +
+	"\aGetWork\x12\x19.bookid.v1.GetWorkRequest\x1a\x0f.bookid.v1.Work\x12[\n" +
+	"\x10ListPublications\x12\".bookid.v1.ListPublicationsRequest\x1a#.bookid.v1.ListPublicationsResponseB*Z(github.com/fwojciec/bookid/grpc/bookidpbb\x06proto3"
+
+var (
+	file_grpc_bookidpb_bookid_proto_rawDescOnce sync.Once
+	file_grpc_bookidpb_bookid_proto_rawDescData []byte
+)
+
+func file_grpc_bookidpb_bookid_proto_rawDescGZIP() []byte {
+	file_grpc_bookidpb_bookid_proto_rawDescOnce.Do(func() {
+		file_grpc_bookidpb_bookid_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_grpc_bookidpb_bookid_proto_rawDesc), len(file_grpc_bookidpb_bookid_proto_rawDesc)))
+	})
+	return file_grpc_bookidpb_bookid_proto_rawDescData
+}
+
+var file_grpc_bookidpb_bookid_proto_msgTypes = make([]protoimpl.MessageInfo, 11)
+var file_grpc_bookidpb_bookid_proto_goTypes = []any{
+	(*SearchRequest)(nil),            // 0: bookid.v1.SearchRequest
+	(*SearchResponse)(nil),           // 1: bookid.v1.SearchResponse
+	(*BookResult)(nil),               // 2: bookid.v1.BookResult
+	(*SaveResultRequest)(nil),        // 3: bookid.v1.SaveResultRequest
+	(*SaveResultResponse)(nil),       // 4: bookid.v1.SaveResultResponse
+	(*GetWorkRequest)(nil),           // 5: bookid.v1.GetWorkRequest
+	(*Work)(nil),                     // 6: bookid.v1.Work
+	(*ListPublicationsRequest)(nil),  // 7: bookid.v1.ListPublicationsRequest
+	(*ListPublicationsResponse)(nil), // 8: bookid.v1.ListPublicationsResponse
+	(*Publication)(nil),              // 9: bookid.v1.Publication
+	nil,                              // 10: bookid.v1.BookResult.MetadataEntry
+	(*timestamppb.Timestamp)(nil),    // 11: google.protobuf.Timestamp
+}
+var file_grpc_bookidpb_bookid_proto_depIdxs = []int32{
+	2,  // 0: bookid.v1.SearchResponse.results:type_name -> bookid.v1.BookResult
+	10, // 1: bookid.v1.BookResult.metadata:type_name -> bookid.v1.BookResult.MetadataEntry
+	2,  // 2: bookid.v1.SaveResultRequest.result:type_name -> bookid.v1.BookResult
+	11, // 3: bookid.v1.Work.created_at:type_name -> google.protobuf.Timestamp
+	11, // 4: bookid.v1.Work.updated_at:type_name -> google.protobuf.Timestamp
+	9,  // 5: bookid.v1.ListPublicationsResponse.publications:type_name -> bookid.v1.Publication
+	11, // 6: bookid.v1.Publication.created_at:type_name -> google.protobuf.Timestamp
+	11, // 7: bookid.v1.Publication.updated_at:type_name -> google.protobuf.Timestamp
+	0,  // 8: bookid.v1.BookID.Search:input_type -> bookid.v1.SearchRequest
+	3,  // 9: bookid.v1.BookID.SaveResult:input_type -> bookid.v1.SaveResultRequest
+	5,  // 10: bookid.v1.BookID.GetWork:input_type -> bookid.v1.GetWorkRequest
+	7,  // 11: bookid.v1.BookID.ListPublications:input_type -> bookid.v1.ListPublicationsRequest
+	1,  // 12: bookid.v1.BookID.Search:output_type -> bookid.v1.SearchResponse
+	4,  // 13: bookid.v1.BookID.SaveResult:output_type -> bookid.v1.SaveResultResponse
+	6,  // 14: bookid.v1.BookID.GetWork:output_type -> bookid.v1.Work
+	8,  // 15: bookid.v1.BookID.ListPublications:output_type -> bookid.v1.ListPublicationsResponse
+	12, // [12:16] is the sub-list for method output_type
+	8,  // [8:12] is the sub-list for method input_type
+	8,  // [8:8] is the sub-list for extension type_name
+	8,  // [8:8] is the sub-list for extension extendee
+	0,  // [0:8] is the sub-list for field type_name
+}
+
+func init() { file_grpc_bookidpb_bookid_proto_init() }
+func file_grpc_bookidpb_bookid_proto_init() {
+	if File_grpc_bookidpb_bookid_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_grpc_bookidpb_bookid_proto_rawDesc), len(file_grpc_bookidpb_bookid_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   11,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_grpc_bookidpb_bookid_proto_goTypes,
+		DependencyIndexes: file_grpc_bookidpb_bookid_proto_depIdxs,
+		MessageInfos:      file_grpc_bookidpb_bookid_proto_msgTypes,
+	}.Build()
+	File_grpc_bookidpb_bookid_proto = out.File
+	file_grpc_bookidpb_bookid_proto_goTypes = nil
+	file_grpc_bookidpb_bookid_proto_depIdxs = nil
+}
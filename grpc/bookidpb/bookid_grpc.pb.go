@@ -0,0 +1,259 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: grpc/bookidpb/bookid.proto
+
+package bookidpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	BookID_Search_FullMethodName           = "/bookid.v1.BookID/Search"
+	BookID_SaveResult_FullMethodName       = "/bookid.v1.BookID/SaveResult"
+	BookID_GetWork_FullMethodName          = "/bookid.v1.BookID/GetWork"
+	BookID_ListPublications_FullMethodName = "/bookid.v1.BookID/ListPublications"
+)
+
+// BookIDClient is the client API for BookID service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// BookID identifies books and keeps a local catalog of them.
+//
+// Errors carry a gRPC status code matching the bookid error code, and the
+// bookid error code itself in the "bookid-error-code" trailer.
+type BookIDClient interface {
+	// Search identifies a query, such as an ISBN, DOI, LCCN or a title and
+	// author, using the configured providers.
+	Search(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*SearchResponse, error)
+	// SaveResult saves a search result to the catalog, adding its work and
+	// publication unless they already exist.
+	SaveResult(ctx context.Context, in *SaveResultRequest, opts ...grpc.CallOption) (*SaveResultResponse, error)
+	// GetWork returns a work of the catalog by ID.
+	GetWork(ctx context.Context, in *GetWorkRequest, opts ...grpc.CallOption) (*Work, error)
+	// ListPublications lists publications of the catalog, optionally only
+	// those of a work or with an ISBN.
+	ListPublications(ctx context.Context, in *ListPublicationsRequest, opts ...grpc.CallOption) (*ListPublicationsResponse, error)
+}
+
+type bookIDClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewBookIDClient(cc grpc.ClientConnInterface) BookIDClient {
+	return &bookIDClient{cc}
+}
+
+func (c *bookIDClient) Search(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*SearchResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SearchResponse)
+	err := c.cc.Invoke(ctx, BookID_Search_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *bookIDClient) SaveResult(ctx context.Context, in *SaveResultRequest, opts ...grpc.CallOption) (*SaveResultResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SaveResultResponse)
+	err := c.cc.Invoke(ctx, BookID_SaveResult_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *bookIDClient) GetWork(ctx context.Context, in *GetWorkRequest, opts ...grpc.CallOption) (*Work, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Work)
+	err := c.cc.Invoke(ctx, BookID_GetWork_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *bookIDClient) ListPublications(ctx context.Context, in *ListPublicationsRequest, opts ...grpc.CallOption) (*ListPublicationsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListPublicationsResponse)
+	err := c.cc.Invoke(ctx, BookID_ListPublications_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// BookIDServer is the server API for BookID service.
+// All implementations must embed UnimplementedBookIDServer
+// for forward compatibility.
+//
+// BookID identifies books and keeps a local catalog of them.
+//
+// Errors carry a gRPC status code matching the bookid error code, and the
+// bookid error code itself in the "bookid-error-code" trailer.
+type BookIDServer interface {
+	// Search identifies a query, such as an ISBN, DOI, LCCN or a title and
+	// author, using the configured providers.
+	Search(context.Context, *SearchRequest) (*SearchResponse, error)
+	// SaveResult saves a search result to the catalog, adding its work and
+	// publication unless they already exist.
+	SaveResult(context.Context, *SaveResultRequest) (*SaveResultResponse, error)
+	// GetWork returns a work of the catalog by ID.
+	GetWork(context.Context, *GetWorkRequest) (*Work, error)
+	// ListPublications lists publications of the catalog, optionally only
+	// those of a work or with an ISBN.
+	ListPublications(context.Context, *ListPublicationsRequest) (*ListPublicationsResponse, error)
+	mustEmbedUnimplementedBookIDServer()
+}
+
+// UnimplementedBookIDServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedBookIDServer struct{}
+
+func (UnimplementedBookIDServer) Search(context.Context, *SearchRequest) (*SearchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Search not implemented")
+}
+func (UnimplementedBookIDServer) SaveResult(context.Context, *SaveResultRequest) (*SaveResultResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SaveResult not implemented")
+}
+func (UnimplementedBookIDServer) GetWork(context.Context, *GetWorkRequest) (*Work, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetWork not implemented")
+}
+func (UnimplementedBookIDServer) ListPublications(context.Context, *ListPublicationsRequest) (*ListPublicationsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListPublications not implemented")
+}
+func (UnimplementedBookIDServer) mustEmbedUnimplementedBookIDServer() {}
+func (UnimplementedBookIDServer) testEmbeddedByValue()                {}
+
+// UnsafeBookIDServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to BookIDServer will
+// result in compilation errors.
+type UnsafeBookIDServer interface {
+	mustEmbedUnimplementedBookIDServer()
+}
+
+func RegisterBookIDServer(s grpc.ServiceRegistrar, srv BookIDServer) {
+	// If the following call pancis, it indicates UnimplementedBookIDServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&BookID_ServiceDesc, srv)
+}
+
+func _BookID_Search_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BookIDServer).Search(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BookID_Search_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BookIDServer).Search(ctx, req.(*SearchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BookID_SaveResult_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SaveResultRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BookIDServer).SaveResult(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BookID_SaveResult_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BookIDServer).SaveResult(ctx, req.(*SaveResultRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BookID_GetWork_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetWorkRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BookIDServer).GetWork(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BookID_GetWork_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BookIDServer).GetWork(ctx, req.(*GetWorkRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BookID_ListPublications_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListPublicationsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BookIDServer).ListPublications(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BookID_ListPublications_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BookIDServer).ListPublications(ctx, req.(*ListPublicationsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// BookID_ServiceDesc is the grpc.ServiceDesc for BookID service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var BookID_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "bookid.v1.BookID",
+	HandlerType: (*BookIDServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Search",
+			Handler:    _BookID_Search_Handler,
+		},
+		{
+			MethodName: "SaveResult",
+			Handler:    _BookID_SaveResult_Handler,
+		},
+		{
+			MethodName: "GetWork",
+			Handler:    _BookID_GetWork_Handler,
+		},
+		{
+			MethodName: "ListPublications",
+			Handler:    _BookID_ListPublications_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "grpc/bookidpb/bookid.proto",
+}
@@ -0,0 +1,284 @@
+// Package grpc exposes the book finder and the catalog over gRPC, for
+// services written in other languages. The service is defined in
+// bookidpb/bookid.proto; run "make proto" to regenerate its Go code.
+package grpc
+
+import (
+	"context"
+	"log"
+	"net"
+	"time"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/grpc/bookidpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// ShutdownTimeout is the time given for outstanding calls to finish before shutdown.
+const ShutdownTimeout = 1 * time.Second
+
+// ErrorCodeTrailer is the trailer carrying the bookid error code of a failed call.
+const ErrorCodeTrailer = "bookid-error-code"
+
+// Server represents a gRPC server. It is meant to wrap all gRPC functionality
+// used by the application so that dependent packages (such as cmd/bookid) do
+// not need to reference the gRPC packages at all.
+type Server struct {
+	ln     net.Listener
+	server *grpc.Server
+
+	// Bind address to open.
+	Addr string
+
+	// Services used by the RPCs.
+	BookFinder         bookid.BookFinder
+	CatalogService     bookid.CatalogService
+	WorkService        bookid.WorkService
+	PublicationService bookid.PublicationService
+}
+
+// NewServer returns a new instance of Server.
+func NewServer() *Server {
+	s := &Server{server: grpc.NewServer()}
+	bookidpb.RegisterBookIDServer(s.server, &bookIDServer{s: s})
+	return s
+}
+
+// Open begins listening on the bind address.
+func (s *Server) Open() (err error) {
+	if s.ln, err = net.Listen("tcp", s.Addr); err != nil {
+		return err
+	}
+	go func() { _ = s.server.Serve(s.ln) }()
+	return nil
+}
+
+// Serve accepts connections on ln until the server is closed. It is useful
+// in tests with an in-memory listener.
+func (s *Server) Serve(ln net.Listener) error {
+	return s.server.Serve(ln)
+}
+
+// Close gracefully shuts down the server, canceling calls still running
+// after ShutdownTimeout.
+func (s *Server) Close() error {
+	done := make(chan struct{})
+	go func() {
+		s.server.GracefulStop()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(ShutdownTimeout):
+		s.server.Stop()
+	}
+	return nil
+}
+
+// Port returns the TCP port for the running server.
+// This is useful in tests where we allocate a random port by using ":0".
+func (s *Server) Port() int {
+	if s.ln == nil {
+		return 0
+	}
+	return s.ln.Addr().(*net.TCPAddr).Port
+}
+
+// Error returns err as a gRPC status error with the status code associated
+// with its bookid error code, which is also set as the ErrorCodeTrailer
+// trailer. Internal errors are logged; their details are not shown to the
+// caller.
+func Error(ctx context.Context, method string, err error) error {
+	code, message := bookid.ErrorCode(err), bookid.ErrorMessage(err)
+	if code == bookid.EINTERNAL {
+		log.Printf("[grpc] error: %s: %s", method, err)
+	}
+	_ = grpc.SetTrailer(ctx, metadata.Pairs(ErrorCodeTrailer, code))
+	return status.Error(ErrorStatusCode(code), message)
+}
+
+// ErrorStatusCode returns the associated gRPC status code for a bookid error code.
+func ErrorStatusCode(code string) codes.Code {
+	switch code {
+	case bookid.ECONFLICT:
+		return codes.AlreadyExists
+	case bookid.EINVALID:
+		return codes.InvalidArgument
+	case bookid.ENOTFOUND:
+		return codes.NotFound
+	case bookid.ENOTIMPLEMENTED:
+		return codes.Unimplemented
+	case bookid.ERATELIMIT:
+		return codes.ResourceExhausted
+	case bookid.EUNAUTHORIZED:
+		return codes.Unauthenticated
+	case bookid.EUNAVAILABLE:
+		return codes.Unavailable
+	}
+	return codes.Internal
+}
+
+// bookIDServer implements the BookID service by delegating to the services
+// of the server.
+type bookIDServer struct {
+	bookidpb.UnimplementedBookIDServer
+	s *Server
+}
+
+// Search implements bookidpb.BookIDServer.
+func (b *bookIDServer) Search(ctx context.Context, req *bookidpb.SearchRequest) (*bookidpb.SearchResponse, error) {
+	if req.GetQuery() == "" {
+		return nil, Error(ctx, "Search", bookid.Errorf(bookid.EINVALID, "Query required."))
+	}
+
+	opts := bookid.SearchOptions{
+		MaxResults:    int(req.GetMaxResults()),
+		StartIndex:    int(req.GetStartIndex()),
+		Language:      req.GetLanguage(),
+		PrintType:     bookid.PrintType(req.GetPrintType()),
+		OrderBy:       bookid.OrderBy(req.GetOrderBy()),
+		MinConfidence: req.GetMinConfidence(),
+	}
+	if opts.MaxResults < 0 {
+		return nil, Error(ctx, "Search", bookid.Errorf(bookid.EINVALID, "Invalid max results."))
+	} else if err := opts.Validate(); err != nil {
+		return nil, Error(ctx, "Search", err)
+	}
+
+	results, err := b.s.BookFinder.Search(ctx, req.GetQuery(), opts)
+	if err != nil {
+		return nil, Error(ctx, "Search", err)
+	}
+
+	resp := &bookidpb.SearchResponse{Results: make([]*bookidpb.BookResult, len(results))}
+	for i := range results {
+		resp.Results[i] = marshalBookResult(&results[i])
+	}
+	return resp, nil
+}
+
+// SaveResult implements bookidpb.BookIDServer.
+func (b *bookIDServer) SaveResult(ctx context.Context, req *bookidpb.SaveResultRequest) (*bookidpb.SaveResultResponse, error) {
+	if req.GetResult() == nil {
+		return nil, Error(ctx, "SaveResult", bookid.Errorf(bookid.EINVALID, "Result required."))
+	}
+
+	workID, publicationID, err := b.s.CatalogService.SaveResult(ctx, unmarshalBookResult(req.GetResult()))
+	if err != nil {
+		return nil, Error(ctx, "SaveResult", err)
+	}
+	return &bookidpb.SaveResultResponse{WorkId: workID, PublicationId: publicationID}, nil
+}
+
+// GetWork implements bookidpb.BookIDServer.
+func (b *bookIDServer) GetWork(ctx context.Context, req *bookidpb.GetWorkRequest) (*bookidpb.Work, error) {
+	work, err := b.s.WorkService.FindWorkByID(ctx, req.GetId())
+	if err != nil {
+		return nil, Error(ctx, "GetWork", err)
+	}
+	return &bookidpb.Work{
+		Id:        work.ID,
+		Title:     work.Title,
+		Author:    work.Author,
+		CreatedAt: timestamppb.New(work.CreatedAt),
+		UpdatedAt: timestamppb.New(work.UpdatedAt),
+	}, nil
+}
+
+// ListPublications implements bookidpb.BookIDServer.
+func (b *bookIDServer) ListPublications(ctx context.Context, req *bookidpb.ListPublicationsRequest) (*bookidpb.ListPublicationsResponse, error) {
+	if req.GetOffset() < 0 || req.GetLimit() < 0 {
+		return nil, Error(ctx, "ListPublications", bookid.Errorf(bookid.EINVALID, "Offset and limit must not be negative."))
+	}
+
+	filter := bookid.PublicationFilter{Offset: int(req.GetOffset()), Limit: int(req.GetLimit())}
+	if id := req.GetWorkId(); id != 0 {
+		filter.WorkID = &id
+	}
+	if isbn := req.GetIsbn(); isbn != "" {
+		filter.ISBN = &isbn
+	}
+
+	pubs, n, err := b.s.PublicationService.FindPublications(ctx, filter)
+	if err != nil {
+		return nil, Error(ctx, "ListPublications", err)
+	}
+
+	resp := &bookidpb.ListPublicationsResponse{
+		Publications: make([]*bookidpb.Publication, len(pubs)),
+		Total:        int32(n),
+	}
+	for i, pub := range pubs {
+		resp.Publications[i] = marshalPublication(pub)
+	}
+	return resp, nil
+}
+
+// marshalBookResult converts a search result to its protobuf message. Raw
+// provider responses are not included.
+func marshalBookResult(r *bookid.BookResult) *bookidpb.BookResult {
+	return &bookidpb.BookResult{
+		Title:               r.Title,
+		Authors:             r.Authors,
+		Isbn10:              r.ISBN10,
+		Isbn13:              r.ISBN13,
+		Publisher:           r.Publisher,
+		PublishedYear:       int32(r.PublishedYear),
+		Language:            r.Language,
+		GoogleBooksVolumeId: r.GoogleBooksVolumeID,
+		OclcNumber:          r.OCLCNumber,
+		Lccn:                r.LCCN,
+		Doi:                 r.DOI,
+		ThumbnailUrl:        r.ThumbnailURL,
+		Provider:            r.Provider,
+		Metadata:            r.Metadata,
+		Confidence:          r.Confidence,
+		SearchType:          string(r.SearchType),
+	}
+}
+
+// unmarshalBookResult converts a protobuf search result to a bookid one.
+func unmarshalBookResult(r *bookidpb.BookResult) bookid.BookResult {
+	return bookid.BookResult{
+		Title:               r.GetTitle(),
+		Authors:             r.GetAuthors(),
+		ISBN10:              r.GetIsbn10(),
+		ISBN13:              r.GetIsbn13(),
+		Publisher:           r.GetPublisher(),
+		PublishedYear:       int(r.GetPublishedYear()),
+		Language:            r.GetLanguage(),
+		GoogleBooksVolumeID: r.GetGoogleBooksVolumeId(),
+		OCLCNumber:          r.GetOclcNumber(),
+		LCCN:                r.GetLccn(),
+		DOI:                 r.GetDoi(),
+		ThumbnailURL:        r.GetThumbnailUrl(),
+		Provider:            r.GetProvider(),
+		Metadata:            r.GetMetadata(),
+		Confidence:          r.GetConfidence(),
+		SearchType:          bookid.SearchType(r.GetSearchType()),
+	}
+}
+
+// marshalPublication converts a publication to its protobuf message.
+func marshalPublication(p *bookid.Publication) *bookidpb.Publication {
+	return &bookidpb.Publication{
+		Id:                  p.ID,
+		WorkId:              p.WorkID,
+		Isbn10:              p.ISBN10,
+		Isbn13:              p.ISBN13,
+		Publisher:           p.Publisher,
+		PublishedYear:       int32(p.PublishedYear),
+		Language:            p.Language,
+		GoogleBooksVolumeId: p.GoogleBooksVolumeID,
+		OclcNumber:          p.OCLCNumber,
+		Lccn:                p.LCCN,
+		Doi:                 p.DOI,
+		ThumbnailUrl:        p.ThumbnailURL,
+		CreatedAt:           timestamppb.New(p.CreatedAt),
+		UpdatedAt:           timestamppb.New(p.UpdatedAt),
+	}
+}
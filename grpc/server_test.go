@@ -0,0 +1,144 @@
+package grpc_test
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/fwojciec/bookid"
+	bookidgrpc "github.com/fwojciec/bookid/grpc"
+	"github.com/fwojciec/bookid/grpc/bookidpb"
+	"github.com/fwojciec/bookid/mock"
+	"github.com/fwojciec/bookid/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// MustOpenServer serves a server backed by an in-memory catalog and finder
+// over an in-memory connection and returns a client of it.
+func MustOpenServer(tb testing.TB, finder bookid.BookFinder) bookidpb.BookIDClient {
+	tb.Helper()
+	db := sqlite.NewDB(":memory:")
+	require.NoError(tb, db.Open())
+	tb.Cleanup(func() { _ = db.Close() })
+
+	s := bookidgrpc.NewServer()
+	s.BookFinder = finder
+	s.CatalogService = sqlite.NewCatalogService(db)
+	s.WorkService = sqlite.NewWorkService(db)
+	s.PublicationService = sqlite.NewPublicationService(db)
+
+	ln := bufconn.Listen(1 << 20)
+	go func() { _ = s.Serve(ln) }()
+	tb.Cleanup(func() { _ = s.Close() })
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return ln.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(tb, err)
+	tb.Cleanup(func() { _ = conn.Close() })
+	return bookidpb.NewBookIDClient(conn)
+}
+
+func dune() bookid.BookResult {
+	return bookid.BookResult{
+		Title:         "Dune",
+		Authors:       []string{"Frank Herbert"},
+		ISBN13:        "9780441172719",
+		Publisher:     "Ace",
+		PublishedYear: 1990,
+		Provider:      "googlebooks",
+		Confidence:    0.95,
+		SearchType:    bookid.SearchTypeISBN,
+	}
+}
+
+func TestServer_Search(t *testing.T) {
+	t.Parallel()
+
+	t.Run("OK", func(t *testing.T) {
+		t.Parallel()
+		client := MustOpenServer(t, &mock.BookFinder{SearchFn: func(_ context.Context, query string, opts bookid.SearchOptions) ([]bookid.BookResult, error) {
+			assert.Equal(t, "9780441172719", query)
+			assert.Equal(t, bookid.SearchOptions{MaxResults: 5, Language: "en"}, opts)
+			return []bookid.BookResult{dune()}, nil
+		}})
+
+		resp, err := client.Search(context.Background(), &bookidpb.SearchRequest{Query: "9780441172719", MaxResults: 5, Language: "en"})
+		require.NoError(t, err)
+		require.Len(t, resp.GetResults(), 1)
+		result := resp.GetResults()[0]
+		assert.Equal(t, "Dune", result.GetTitle())
+		assert.Equal(t, []string{"Frank Herbert"}, result.GetAuthors())
+		assert.Equal(t, "isbn", result.GetSearchType())
+		assert.InDelta(t, 0.95, result.GetConfidence(), 0.001)
+	})
+
+	t.Run("ErrInvalid", func(t *testing.T) {
+		t.Parallel()
+		client := MustOpenServer(t, nil)
+
+		var trailer metadata.MD
+		_, err := client.Search(context.Background(), &bookidpb.SearchRequest{}, grpc.Trailer(&trailer))
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		assert.Equal(t, []string{bookid.EINVALID}, trailer.Get(bookidgrpc.ErrorCodeTrailer))
+	})
+
+	t.Run("ErrRateLimit", func(t *testing.T) {
+		t.Parallel()
+		client := MustOpenServer(t, &mock.BookFinder{SearchFn: func(context.Context, string, bookid.SearchOptions) ([]bookid.BookResult, error) {
+			return nil, bookid.Errorf(bookid.ERATELIMIT, "Slow down.")
+		}})
+
+		_, err := client.Search(context.Background(), &bookidpb.SearchRequest{Query: "dune"})
+		assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+		assert.Equal(t, "Slow down.", status.Convert(err).Message())
+	})
+}
+
+func TestServer_Catalog(t *testing.T) {
+	t.Parallel()
+	client := MustOpenServer(t, nil)
+	ctx := context.Background()
+
+	result := dune()
+	saved, err := client.SaveResult(ctx, &bookidpb.SaveResultRequest{Result: &bookidpb.BookResult{
+		Title:         result.Title,
+		Authors:       result.Authors,
+		Isbn13:        result.ISBN13,
+		Publisher:     result.Publisher,
+		PublishedYear: int32(result.PublishedYear),
+	}})
+	require.NoError(t, err)
+	assert.NotZero(t, saved.GetWorkId())
+	assert.NotZero(t, saved.GetPublicationId())
+
+	work, err := client.GetWork(ctx, &bookidpb.GetWorkRequest{Id: saved.GetWorkId()})
+	require.NoError(t, err)
+	assert.Equal(t, "Dune", work.GetTitle())
+	assert.False(t, work.GetCreatedAt().AsTime().IsZero())
+
+	pubs, err := client.ListPublications(ctx, &bookidpb.ListPublicationsRequest{WorkId: saved.GetWorkId()})
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), pubs.GetTotal())
+	require.Len(t, pubs.GetPublications(), 1)
+	assert.Equal(t, saved.GetPublicationId(), pubs.GetPublications()[0].GetId())
+	assert.Equal(t, "9780441172719", pubs.GetPublications()[0].GetIsbn13())
+
+	pubs, err = client.ListPublications(ctx, &bookidpb.ListPublicationsRequest{Isbn: "978-0-441-17271-9"})
+	require.NoError(t, err)
+	assert.Len(t, pubs.GetPublications(), 1)
+
+	_, err = client.GetWork(ctx, &bookidpb.GetWorkRequest{Id: 999})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = client.SaveResult(ctx, &bookidpb.SaveResultRequest{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
@@ -0,0 +1,50 @@
+package http
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/fwojciec/bookid"
+)
+
+// coverMaxAge is how long clients may cache cover images, in seconds. Stored
+// covers are kept once fetched, so they rarely change.
+const coverMaxAge = 24 * 60 * 60
+
+// handleCoverView handles the "GET /covers/{id}" route. It responds with the
+// publication's cover in the size given by the "size" query parameter. The
+// format is taken from the "format" query parameter or, without one, is WebP
+// if the client accepts it and JPEG otherwise.
+func (s *Server) handleCoverView(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		Error(w, r, err)
+		return
+	}
+
+	q := r.URL.Query()
+	format := q.Get("format")
+	if format == "" {
+		w.Header().Set("Vary", "Accept")
+		format = bookid.CoverFormatJPEG
+		if strings.Contains(r.Header.Get("Accept"), "image/webp") {
+			format = bookid.CoverFormatWebP
+		}
+	}
+
+	cover, err := s.CoverService.FindCover(r.Context(), id, q.Get("size"), format)
+	if err != nil {
+		Error(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", cover.ContentType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(cover.Data)))
+	w.Header().Set("Cache-Control", "public, max-age="+strconv.Itoa(coverMaxAge))
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(cover.Data); err != nil {
+		log.Printf("[http] error: %s %s: writing cover: %s", r.Method, r.URL.Path, err)
+	}
+}
@@ -0,0 +1,61 @@
+// Package http exposes the book finder and the catalog over a JSON REST API.
+package http
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/fwojciec/bookid"
+)
+
+// ErrorResponse represents a JSON structure for error output.
+type ErrorResponse struct {
+	Code  string `json:"code"`
+	Error string `json:"error"`
+}
+
+// Error writes an API error message to the response and logs internal errors.
+func Error(w http.ResponseWriter, r *http.Request, err error) {
+	// Extract error code & message.
+	code, message := bookid.ErrorCode(err), bookid.ErrorMessage(err)
+
+	// Log internal errors; their details are not shown to the user.
+	if code == bookid.EINTERNAL {
+		log.Printf("[http] error: %s %s: %s", r.Method, r.URL.Path, err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(ErrorStatusCode(code))
+	_ = json.NewEncoder(w).Encode(&ErrorResponse{Code: code, Error: message})
+}
+
+// ErrorStatusCode returns the associated HTTP status code for a bookid error code.
+func ErrorStatusCode(code string) int {
+	switch code {
+	case bookid.ECONFLICT:
+		return http.StatusConflict
+	case bookid.EINVALID:
+		return http.StatusBadRequest
+	case bookid.ENOTFOUND:
+		return http.StatusNotFound
+	case bookid.ENOTIMPLEMENTED:
+		return http.StatusNotImplemented
+	case bookid.ERATELIMIT:
+		return http.StatusTooManyRequests
+	case bookid.EUNAUTHORIZED:
+		return http.StatusUnauthorized
+	case bookid.EUNAVAILABLE:
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusInternalServerError
+}
+
+// writeJSON writes v as the JSON response body with the given status code.
+func writeJSON(w http.ResponseWriter, r *http.Request, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("[http] error: %s %s: encoding response: %s", r.Method, r.URL.Path, err)
+	}
+}
@@ -0,0 +1,21 @@
+package http
+
+import (
+	"net/http"
+)
+
+// handlePublicationView handles the "GET /publications/{id}" route.
+func (s *Server) handlePublicationView(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		Error(w, r, err)
+		return
+	}
+
+	pub, err := s.PublicationService.FindPublicationByID(r.Context(), id)
+	if err != nil {
+		Error(w, r, err)
+		return
+	}
+	writeJSON(w, r, http.StatusOK, pub)
+}
@@ -0,0 +1,63 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/fwojciec/bookid"
+)
+
+// handleWorkTranslations handles the "GET /works/{id}/translations" route. It
+// responds with the translation links of the work, both those to its
+// original and those from its translations.
+func (s *Server) handleWorkTranslations(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		Error(w, r, err)
+		return
+	} else if _, err := s.WorkService.FindWorkByID(r.Context(), id); err != nil {
+		Error(w, r, err)
+		return
+	}
+
+	typ := bookid.WorkRelationTranslationOf
+	originals, _, err := s.WorkRelationService.FindWorkRelations(r.Context(), bookid.WorkRelationFilter{WorkID: &id, Type: &typ})
+	if err != nil {
+		Error(w, r, err)
+		return
+	}
+	translations, _, err := s.WorkRelationService.FindWorkRelations(r.Context(), bookid.WorkRelationFilter{RelatedWorkID: &id, Type: &typ})
+	if err != nil {
+		Error(w, r, err)
+		return
+	}
+	writeJSON(w, r, http.StatusOK, append(originals, translations...))
+}
+
+// handleWorkTranslationOf handles the "POST /works/{id}/translation-of"
+// route. It links the work to the original named in the JSON request body,
+// e.g. {"original_work_id": 1, "translators": ["Bill Johnston"]}, and
+// responds with the created relation.
+func (s *Server) handleWorkTranslationOf(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		Error(w, r, err)
+		return
+	}
+
+	var req struct {
+		OriginalWorkID int64    `json:"original_work_id"`
+		Translators    []string `json:"translators"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		Error(w, r, bookid.Errorf(bookid.EINVALID, "Invalid JSON body."))
+		return
+	}
+
+	rel, err := s.WorkRelationService.LinkTranslation(r.Context(), id, req.OriginalWorkID, req.Translators)
+	if err != nil {
+		Error(w, r, err)
+		return
+	}
+	writeJSON(w, r, http.StatusCreated, rel)
+}
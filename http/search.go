@@ -0,0 +1,143 @@
+package http
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/fwojciec/bookid"
+)
+
+// handleSearch handles the "GET /search?q=" route. It identifies the query
+// with the book finder and returns the results. The optional "limit",
+// "start", "lang", "country", "print_type", "binding", "order_by",
+// "min_confidence" and "raw" parameters map to bookid.SearchOptions.
+func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	if query == "" {
+		Error(w, r, bookid.Errorf(bookid.EINVALID, "Query required."))
+		return
+	}
+
+	opts, err := searchOptions(r)
+	if err != nil {
+		Error(w, r, err)
+		return
+	}
+
+	results, err := s.BookFinder.Search(r.Context(), query, opts)
+	if err != nil {
+		Error(w, r, err)
+		return
+	}
+
+	writeJSON(w, r, http.StatusOK, struct {
+		Results []bookid.BookResult `json:"results"`
+	}{results})
+}
+
+// MaxBatchQueries is the most queries accepted by one batch search request.
+const MaxBatchQueries = 100
+
+// batchSearchResult is the outcome of one query of a batch search.
+type batchSearchResult struct {
+	Query   string              `json:"query"`
+	Results []bookid.BookResult `json:"results"`
+	Error   *ErrorResponse      `json:"error,omitempty"`
+}
+
+// handleBatchSearch handles the "POST /search/batch" route. It identifies
+// each query of the JSON body, such as {"queries": ["dune", "emma"]}, with
+// the batch finder and returns the results in the order of the queries. A
+// failed query is reported in its result rather than failing the request.
+// Search options are given as query parameters, as for "GET /search".
+func (s *Server) handleBatchSearch(w http.ResponseWriter, r *http.Request) {
+	if s.BatchFinder == nil {
+		s.handleNotFound(w, r)
+		return
+	}
+
+	var body struct {
+		Queries []string `json:"queries"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		Error(w, r, bookid.Errorf(bookid.EINVALID, "Invalid JSON body."))
+		return
+	} else if len(body.Queries) == 0 {
+		Error(w, r, bookid.Errorf(bookid.EINVALID, "Queries required."))
+		return
+	} else if len(body.Queries) > MaxBatchQueries {
+		Error(w, r, bookid.Errorf(bookid.EINVALID, "At most %d queries allowed.", MaxBatchQueries))
+		return
+	}
+	for i, query := range body.Queries {
+		if body.Queries[i] = strings.TrimSpace(query); body.Queries[i] == "" {
+			Error(w, r, bookid.Errorf(bookid.EINVALID, "Query %d is empty.", i+1))
+			return
+		}
+	}
+
+	opts, err := searchOptions(r)
+	if err != nil {
+		Error(w, r, err)
+		return
+	}
+
+	results, err := s.BatchFinder.SearchAll(r.Context(), body.Queries, bookid.BatchOptions{Search: opts})
+	if err != nil {
+		Error(w, r, err)
+		return
+	}
+
+	resp := make([]batchSearchResult, len(results))
+	for i, result := range results {
+		resp[i] = batchSearchResult{Query: result.Query, Results: result.Results}
+		if resp[i].Results == nil {
+			resp[i].Results = []bookid.BookResult{}
+		}
+		if err := result.Err; err != nil {
+			code := bookid.ErrorCode(err)
+			if code == bookid.EINTERNAL {
+				log.Printf("[http] error: %s %s: %q: %s", r.Method, r.URL.Path, result.Query, err)
+			}
+			resp[i].Error = &ErrorResponse{Code: code, Error: bookid.ErrorMessage(err)}
+		}
+	}
+
+	writeJSON(w, r, http.StatusOK, struct {
+		Results []batchSearchResult `json:"results"`
+	}{resp})
+}
+
+// searchOptions parses search options from the request's query parameters.
+func searchOptions(r *http.Request) (opts bookid.SearchOptions, err error) {
+	params := r.URL.Query()
+	if v := params.Get("limit"); v != "" {
+		if opts.MaxResults, err = strconv.Atoi(v); err != nil || opts.MaxResults < 0 {
+			return opts, bookid.Errorf(bookid.EINVALID, "Invalid limit.")
+		}
+	}
+	if v := params.Get("start"); v != "" {
+		if opts.StartIndex, err = strconv.Atoi(v); err != nil {
+			return opts, bookid.Errorf(bookid.EINVALID, "Invalid start.")
+		}
+	}
+	opts.Language = params.Get("lang")
+	opts.Country = params.Get("country")
+	opts.PrintType = bookid.PrintType(params.Get("print_type"))
+	opts.Binding = bookid.Binding(params.Get("binding"))
+	opts.OrderBy = bookid.OrderBy(params.Get("order_by"))
+	if v := params.Get("min_confidence"); v != "" {
+		if opts.MinConfidence, err = strconv.ParseFloat(v, 64); err != nil {
+			return opts, bookid.Errorf(bookid.EINVALID, "Invalid min_confidence.")
+		}
+	}
+	if v := params.Get("raw"); v != "" {
+		if opts.IncludeRaw, err = strconv.ParseBool(v); err != nil {
+			return opts, bookid.Errorf(bookid.EINVALID, "Invalid raw flag.")
+		}
+	}
+	return opts, opts.Validate()
+}
@@ -0,0 +1,146 @@
+package http
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/tracing"
+)
+
+// ShutdownTimeout is the time given for outstanding requests to finish before shutdown.
+const ShutdownTimeout = 1 * time.Second
+
+// Server represents an HTTP server. It is meant to wrap all HTTP functionality
+// used by the application so that dependent packages (such as cmd/bookid) do
+// not need to reference the "net/http" package at all.
+type Server struct {
+	ln     net.Listener
+	server *http.Server
+	router *http.ServeMux
+
+	// Bind address to open.
+	Addr string
+
+	// Services used by the various HTTP routes.
+	BookFinder          bookid.BookFinder
+	BatchFinder         bookid.BatchFinder
+	WorkService         bookid.WorkService
+	WorkRelationService bookid.WorkRelationService
+	PublicationService  bookid.PublicationService
+	CoverService        bookid.CoverService
+
+	// Serves the sync routes used by "bookid sync", if set. They are not
+	// found otherwise.
+	SyncService bookid.SyncService
+
+	// Serves GET /metrics, if set, such as the Prometheus handler of the
+	// metrics package. The endpoint is not found otherwise.
+	MetricsHandler http.Handler
+
+	// Serves POST /graphql, if set, such as the handler of the graphql
+	// package. The endpoint is not found otherwise.
+	GraphQLHandler http.Handler
+}
+
+// NewServer returns a new instance of Server.
+func NewServer() *Server {
+	s := &Server{
+		server: &http.Server{ReadHeaderTimeout: 10 * time.Second},
+		router: http.NewServeMux(),
+	}
+	s.server.Handler = tracing.NewHandler(s.router)
+
+	s.router.HandleFunc("GET /search", s.handleSearch)
+	s.router.HandleFunc("POST /search/batch", s.handleBatchSearch)
+	s.router.HandleFunc("POST /works", s.handleWorkCreate)
+	s.router.HandleFunc("GET /works/{id}", s.handleWorkView)
+	s.router.HandleFunc("GET /works/{id}/translations", s.handleWorkTranslations)
+	s.router.HandleFunc("POST /works/{id}/translation-of", s.handleWorkTranslationOf)
+	s.router.HandleFunc("GET /publications/{id}", s.handlePublicationView)
+	s.router.HandleFunc("GET /covers/{id}", s.handleCoverView)
+	s.router.HandleFunc("GET /sync/changes", s.handleSyncChangesView)
+	s.router.HandleFunc("POST /sync/changes", s.handleSyncChangesApply)
+	s.router.HandleFunc("GET /metrics", s.handleMetrics)
+	s.router.HandleFunc("POST /graphql", s.handleGraphQL)
+	s.router.HandleFunc("/", s.handleNotFound)
+
+	return s
+}
+
+// Open validates the server options and begins listening on the bind address.
+func (s *Server) Open() (err error) {
+	if s.ln, err = net.Listen("tcp", s.Addr); err != nil {
+		return err
+	}
+
+	// Begin serving requests on the listener. We use Serve() instead of
+	// ListenAndServe() because it allows us to check for listen errors (such
+	// as trying to use an already open port) synchronously.
+	go func() { _ = s.server.Serve(s.ln) }()
+
+	return nil
+}
+
+// Close gracefully shuts down the server.
+func (s *Server) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), ShutdownTimeout)
+	defer cancel()
+	return s.server.Shutdown(ctx)
+}
+
+// Port returns the TCP port for the running server.
+// This is useful in tests where we allocate a random port by using ":0".
+func (s *Server) Port() int {
+	if s.ln == nil {
+		return 0
+	}
+	return s.ln.Addr().(*net.TCPAddr).Port
+}
+
+// URL returns the local base URL of the running server.
+func (s *Server) URL() string {
+	return "http://localhost:" + strconv.Itoa(s.Port())
+}
+
+// ServeHTTP traces the request and routes it to the matching handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.server.Handler.ServeHTTP(w, r)
+}
+
+// handleNotFound reports unknown routes as a JSON error.
+func (s *Server) handleNotFound(w http.ResponseWriter, r *http.Request) {
+	Error(w, r, bookid.Errorf(bookid.ENOTFOUND, "Route not found."))
+}
+
+// handleMetrics handles the "GET /metrics" route by delegating to the
+// metrics handler, if metrics are enabled.
+func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	if s.MetricsHandler == nil {
+		s.handleNotFound(w, r)
+		return
+	}
+	s.MetricsHandler.ServeHTTP(w, r)
+}
+
+// handleGraphQL handles the "POST /graphql" route by delegating to the
+// GraphQL handler, if the GraphQL API is enabled.
+func (s *Server) handleGraphQL(w http.ResponseWriter, r *http.Request) {
+	if s.GraphQLHandler == nil {
+		s.handleNotFound(w, r)
+		return
+	}
+	s.GraphQLHandler.ServeHTTP(w, r)
+}
+
+// pathID parses the "id" path value of the request.
+func pathID(r *http.Request) (int64, error) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		return 0, bookid.Errorf(bookid.EINVALID, "Invalid ID format.")
+	}
+	return id, nil
+}
@@ -0,0 +1,391 @@
+package http_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/fwojciec/bookid"
+	bookidhttp "github.com/fwojciec/bookid/http"
+	"github.com/fwojciec/bookid/mock"
+	"github.com/fwojciec/bookid/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// MustOpenServer returns a server backed by an in-memory catalog and finder.
+func MustOpenServer(tb testing.TB, finder bookid.BookFinder) (*bookidhttp.Server, *sqlite.DB) {
+	tb.Helper()
+	db := sqlite.NewDB(":memory:")
+	require.NoError(tb, db.Open())
+	tb.Cleanup(func() { _ = db.Close() })
+
+	s := bookidhttp.NewServer()
+	s.BookFinder = finder
+	s.WorkService = sqlite.NewWorkService(db)
+	s.WorkRelationService = sqlite.NewWorkRelationService(db)
+	s.PublicationService = sqlite.NewPublicationService(db)
+	return s, db
+}
+
+// serve performs a request against s and returns the recorded response.
+func serve(s *bookidhttp.Server, method, target, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, httptest.NewRequest(method, target, strings.NewReader(body)))
+	return w
+}
+
+// decodeError decodes the error response body.
+func decodeError(tb testing.TB, w *httptest.ResponseRecorder) bookidhttp.ErrorResponse {
+	tb.Helper()
+	var resp bookidhttp.ErrorResponse
+	require.NoError(tb, json.NewDecoder(w.Body).Decode(&resp))
+	return resp
+}
+
+func TestServer_Search(t *testing.T) {
+	t.Parallel()
+
+	t.Run("OK", func(t *testing.T) {
+		t.Parallel()
+		s, _ := MustOpenServer(t, &mock.BookFinder{SearchFn: func(_ context.Context, query string, opts bookid.SearchOptions) ([]bookid.BookResult, error) {
+			assert.Equal(t, "dune herbert", query)
+			assert.Equal(t, bookid.SearchOptions{MaxResults: 5, MinConfidence: 0.5, IncludeRaw: true}, opts)
+			return []bookid.BookResult{{Title: "Dune", Authors: []string{"Frank Herbert"}}}, nil
+		}})
+
+		w := serve(s, http.MethodGet, "/search?q=dune+herbert&limit=5&min_confidence=0.5&raw=true", "")
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var resp struct {
+			Results []bookid.BookResult `json:"results"`
+		}
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		require.Len(t, resp.Results, 1)
+		assert.Equal(t, "Dune", resp.Results[0].Title)
+	})
+
+	t.Run("ErrQueryRequired", func(t *testing.T) {
+		t.Parallel()
+		s, _ := MustOpenServer(t, nil)
+
+		w := serve(s, http.MethodGet, "/search", "")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, bookid.EINVALID, decodeError(t, w).Code)
+	})
+
+	t.Run("ErrInvalidOptions", func(t *testing.T) {
+		t.Parallel()
+		s, _ := MustOpenServer(t, nil)
+
+		w := serve(s, http.MethodGet, "/search?q=dune&limit=many", "")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, bookid.EINVALID, decodeError(t, w).Code)
+	})
+
+	t.Run("ErrInternal", func(t *testing.T) {
+		t.Parallel()
+		s, _ := MustOpenServer(t, &mock.BookFinder{SearchFn: func(context.Context, string, bookid.SearchOptions) ([]bookid.BookResult, error) {
+			return nil, errors.New("connection refused")
+		}})
+
+		w := serve(s, http.MethodGet, "/search?q=dune", "")
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		resp := decodeError(t, w)
+		assert.Equal(t, bookid.EINTERNAL, resp.Code)
+		assert.Equal(t, "Internal error.", resp.Error)
+	})
+}
+
+func TestServer_BatchSearch(t *testing.T) {
+	t.Parallel()
+
+	t.Run("OK", func(t *testing.T) {
+		t.Parallel()
+		s, _ := MustOpenServer(t, nil)
+		s.BatchFinder = &mock.BatchFinder{SearchAllFn: func(_ context.Context, queries []string, opts bookid.BatchOptions) ([]bookid.BatchResult, error) {
+			assert.Equal(t, []string{"dune", "emma"}, queries)
+			assert.Equal(t, 1, opts.Search.MaxResults)
+			return []bookid.BatchResult{
+				{Query: "dune", Results: []bookid.BookResult{{Title: "Dune"}}},
+				{Query: "emma", Err: bookid.Errorf(bookid.ERATELIMIT, "Rate limit exceeded.")},
+			}, nil
+		}}
+
+		w := serve(s, http.MethodPost, "/search/batch?limit=1", `{"queries": ["dune", " emma "]}`)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var resp struct {
+			Results []struct {
+				Query   string                    `json:"query"`
+				Results []bookid.BookResult       `json:"results"`
+				Error   *bookidhttp.ErrorResponse `json:"error"`
+			} `json:"results"`
+		}
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		require.Len(t, resp.Results, 2)
+		assert.Equal(t, "Dune", resp.Results[0].Results[0].Title)
+		assert.Nil(t, resp.Results[0].Error)
+		assert.Empty(t, resp.Results[1].Results)
+		require.NotNil(t, resp.Results[1].Error)
+		assert.Equal(t, bookid.ERATELIMIT, resp.Results[1].Error.Code)
+	})
+
+	t.Run("ErrQueriesRequired", func(t *testing.T) {
+		t.Parallel()
+		s, _ := MustOpenServer(t, nil)
+		s.BatchFinder = &mock.BatchFinder{}
+
+		w := serve(s, http.MethodPost, "/search/batch", `{"queries": []}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, bookid.EINVALID, decodeError(t, w).Code)
+	})
+
+	t.Run("ErrNotFoundWithoutBatchFinder", func(t *testing.T) {
+		t.Parallel()
+		s, _ := MustOpenServer(t, nil)
+
+		w := serve(s, http.MethodPost, "/search/batch", `{"queries": ["dune"]}`)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestServer_Works(t *testing.T) {
+	t.Parallel()
+
+	t.Run("CreateAndView", func(t *testing.T) {
+		t.Parallel()
+		s, _ := MustOpenServer(t, nil)
+
+		w := serve(s, http.MethodPost, "/works", `{"title":"Solaris","author":"Stanisław Lem"}`)
+		require.Equal(t, http.StatusCreated, w.Code)
+		var created bookid.Work
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&created))
+		assert.NotZero(t, created.ID)
+
+		w = serve(s, http.MethodGet, "/works/1", "")
+		require.Equal(t, http.StatusOK, w.Code)
+		var got bookid.Work
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&got))
+		assert.Equal(t, "Solaris", got.Title)
+		assert.Equal(t, "Stanisław Lem", got.Author)
+	})
+
+	t.Run("ErrInvalid", func(t *testing.T) {
+		t.Parallel()
+		s, _ := MustOpenServer(t, nil)
+
+		w := serve(s, http.MethodPost, "/works", `{"author":"Anonymous"}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, bookid.EINVALID, decodeError(t, w).Code)
+
+		w = serve(s, http.MethodPost, "/works", `not json`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("ErrNotFound", func(t *testing.T) {
+		t.Parallel()
+		s, _ := MustOpenServer(t, nil)
+
+		w := serve(s, http.MethodGet, "/works/100", "")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Equal(t, bookid.ENOTFOUND, decodeError(t, w).Code)
+
+		w = serve(s, http.MethodGet, "/works/abc", "")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func TestServer_Translations(t *testing.T) {
+	t.Parallel()
+
+	t.Run("LinkAndList", func(t *testing.T) {
+		t.Parallel()
+		s, db := MustOpenServer(t, nil)
+		ctx := context.Background()
+
+		works := sqlite.NewWorkService(db)
+		original := &bookid.Work{Title: "Solaris"}
+		require.NoError(t, works.CreateWork(ctx, original))
+		translation := &bookid.Work{Title: "Solaris"}
+		require.NoError(t, works.CreateWork(ctx, translation))
+
+		w := serve(s, http.MethodPost, "/works/2/translation-of", `{"original_work_id":1,"translators":["Bill Johnston"]}`)
+		require.Equal(t, http.StatusCreated, w.Code)
+		var rel bookid.WorkRelation
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&rel))
+		assert.Equal(t, translation.ID, rel.WorkID)
+		assert.Equal(t, original.ID, rel.RelatedWorkID)
+
+		w = serve(s, http.MethodGet, "/works/1/translations", "")
+		require.Equal(t, http.StatusOK, w.Code)
+		var rels []bookid.WorkRelation
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&rels))
+		require.Len(t, rels, 1)
+		assert.Equal(t, rel.ID, rels[0].ID)
+
+		got, err := works.FindWorkByID(ctx, translation.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Solaris", got.OriginalTitle)
+	})
+
+	t.Run("ErrNotFound", func(t *testing.T) {
+		t.Parallel()
+		s, _ := MustOpenServer(t, nil)
+
+		w := serve(s, http.MethodGet, "/works/1/translations", "")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+
+		w = serve(s, http.MethodPost, "/works/1/translation-of", `{"original_work_id":2}`)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestServer_Publications(t *testing.T) {
+	t.Parallel()
+
+	t.Run("View", func(t *testing.T) {
+		t.Parallel()
+		s, db := MustOpenServer(t, nil)
+		ctx := context.Background()
+
+		work := &bookid.Work{Title: "Dune"}
+		require.NoError(t, sqlite.NewWorkService(db).CreateWork(ctx, work))
+		pub := &bookid.Publication{WorkID: work.ID, ISBN13: "9780441172719"}
+		require.NoError(t, sqlite.NewPublicationService(db).CreatePublication(ctx, pub))
+
+		w := serve(s, http.MethodGet, "/publications/1", "")
+		require.Equal(t, http.StatusOK, w.Code)
+		var got bookid.Publication
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&got))
+		assert.Equal(t, "9780441172719", got.ISBN13)
+		assert.Equal(t, work.ID, got.WorkID)
+	})
+
+	t.Run("ErrNotFound", func(t *testing.T) {
+		t.Parallel()
+		s, _ := MustOpenServer(t, nil)
+
+		w := serve(s, http.MethodGet, "/publications/1", "")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+// coverService returns a cover describing the requested size and format, or
+// ENOTFOUND for unknown publications.
+type coverService struct{}
+
+func (coverService) FetchCover(context.Context, int64) (*bookid.Publication, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (coverService) FindCover(_ context.Context, id int64, size, format string) (*bookid.Cover, error) {
+	if id != 1 {
+		return nil, bookid.Errorf(bookid.ENOTFOUND, "Publication %d has no stored cover.", id)
+	}
+	return &bookid.Cover{ContentType: "image/" + format, Data: []byte(size + "/" + format)}, nil
+}
+
+func TestServer_Covers(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		name, target, accept string
+		wantType, wantBody   string
+	}{
+		{"Default", "/covers/1", "", "image/jpeg", "/jpeg"},
+		{"Size", "/covers/1?size=small", "", "image/jpeg", "small/jpeg"},
+		{"AcceptWebP", "/covers/1?size=large", "image/avif,image/webp,*/*", "image/webp", "large/webp"},
+		{"Format", "/covers/1?format=jpeg", "image/webp", "image/jpeg", "/jpeg"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			s, _ := MustOpenServer(t, nil)
+			s.CoverService = coverService{}
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			r.Header.Set("Accept", tt.accept)
+			s.ServeHTTP(w, r)
+			require.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, tt.wantType, w.Header().Get("Content-Type"))
+			assert.NotEmpty(t, w.Header().Get("Cache-Control"))
+			assert.Equal(t, tt.wantBody, w.Body.String())
+		})
+	}
+
+	t.Run("ErrNotFound", func(t *testing.T) {
+		t.Parallel()
+		s, _ := MustOpenServer(t, nil)
+		s.CoverService = coverService{}
+
+		w := serve(s, http.MethodGet, "/covers/2", "")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Equal(t, bookid.ENOTFOUND, decodeError(t, w).Code)
+	})
+}
+
+func TestServer_Metrics(t *testing.T) {
+	t.Parallel()
+
+	t.Run("OK", func(t *testing.T) {
+		t.Parallel()
+		s, _ := MustOpenServer(t, nil)
+		s.MetricsHandler = http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte("bookid_up 1\n"))
+		})
+
+		w := serve(s, http.MethodGet, "/metrics", "")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "bookid_up 1\n", w.Body.String())
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		t.Parallel()
+		s, _ := MustOpenServer(t, nil)
+
+		w := serve(s, http.MethodGet, "/metrics", "")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestServer_GraphQL(t *testing.T) {
+	t.Parallel()
+
+	t.Run("OK", func(t *testing.T) {
+		t.Parallel()
+		s, _ := MustOpenServer(t, nil)
+		s.GraphQLHandler = http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte(`{"data":{}}`))
+		})
+
+		w := serve(s, http.MethodPost, "/graphql", `{"query":"{ works { total } }"}`)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"data":{}}`, w.Body.String())
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		t.Parallel()
+		s, _ := MustOpenServer(t, nil)
+
+		w := serve(s, http.MethodPost, "/graphql", `{"query":"{ works { total } }"}`)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func TestServer_Open(t *testing.T) {
+	t.Parallel()
+	s, _ := MustOpenServer(t, nil)
+	s.Addr = "localhost:0"
+	require.NoError(t, s.Open())
+	defer func() { require.NoError(t, s.Close()) }()
+
+	resp, err := http.Get(s.URL() + "/unknown")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
@@ -0,0 +1,154 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/fwojciec/bookid"
+)
+
+// handleSyncChangesView handles the "GET /sync/changes" route. It responds
+// with the changes of the catalog since the RFC 3339 time in the "since"
+// query parameter, or all of them without one.
+func (s *Server) handleSyncChangesView(w http.ResponseWriter, r *http.Request) {
+	if s.SyncService == nil {
+		s.handleNotFound(w, r)
+		return
+	}
+
+	var since time.Time
+	if v := r.URL.Query().Get("since"); v != "" {
+		var err error
+		if since, err = time.Parse(time.RFC3339, v); err != nil {
+			Error(w, r, bookid.Errorf(bookid.EINVALID, "Invalid since time, want RFC 3339."))
+			return
+		}
+	}
+
+	changes, err := s.SyncService.FindChanges(r.Context(), since)
+	if err != nil {
+		Error(w, r, err)
+		return
+	}
+	writeJSON(w, r, http.StatusOK, changes)
+}
+
+// syncApplyRequest represents the JSON request body of "POST /sync/changes".
+type syncApplyRequest struct {
+	Changes *bookid.SyncChanges `json:"changes"`
+	Options bookid.SyncOptions  `json:"options"`
+}
+
+// handleSyncChangesApply handles the "POST /sync/changes" route. It applies
+// the changes of another catalog in the JSON request body, e.g.
+// {"changes": {...}, "options": {"since": "...", "rule": "newer"}}, and
+// responds with the report.
+func (s *Server) handleSyncChangesApply(w http.ResponseWriter, r *http.Request) {
+	if s.SyncService == nil {
+		s.handleNotFound(w, r)
+		return
+	}
+
+	var req syncApplyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Changes == nil {
+		Error(w, r, bookid.Errorf(bookid.EINVALID, "Invalid JSON body."))
+		return
+	}
+
+	report, err := s.SyncService.ApplyChanges(r.Context(), req.Changes, req.Options)
+	if err != nil {
+		Error(w, r, err)
+		return
+	}
+	writeJSON(w, r, http.StatusOK, report)
+}
+
+// Ensure type implements interface.
+var _ bookid.SyncService = (*SyncClient)(nil)
+
+// SyncClient implements bookid.SyncService over the sync routes of a remote
+// server, so a catalog can be synced with one served by "bookid serve".
+type SyncClient struct {
+	// Base URL of the server, e.g. "http://server:8080".
+	URL string
+
+	HTTPClient *http.Client
+}
+
+// NewSyncClient returns a new instance of SyncClient for the server at
+// baseURL.
+func NewSyncClient(baseURL string, timeout time.Duration) *SyncClient {
+	return &SyncClient{
+		URL:        strings.TrimSuffix(baseURL, "/"),
+		HTTPClient: &http.Client{Timeout: timeout},
+	}
+}
+
+// FindChanges retrieves the changes of the remote catalog since a time.
+func (c *SyncClient) FindChanges(ctx context.Context, since time.Time) (*bookid.SyncChanges, error) {
+	u := c.URL + "/sync/changes"
+	if !since.IsZero() {
+		u += "?since=" + url.QueryEscape(since.UTC().Format(time.RFC3339))
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes bookid.SyncChanges
+	if err := c.do(req, &changes); err != nil {
+		return nil, err
+	}
+	return &changes, nil
+}
+
+// ApplyChanges applies changes to the remote catalog.
+func (c *SyncClient) ApplyChanges(ctx context.Context, changes *bookid.SyncChanges, opts bookid.SyncOptions) (*bookid.SyncReport, error) {
+	body, err := json.Marshal(syncApplyRequest{Changes: changes, Options: opts})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.URL+"/sync/changes", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	var report bookid.SyncReport
+	if err := c.do(req, &report); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// do sends req and decodes the JSON response into v. Error responses are
+// returned as bookid errors with the code and message the server sent.
+func (c *SyncClient) do(req *http.Request, v any) error {
+	client := c.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var e ErrorResponse
+		if err := json.NewDecoder(resp.Body).Decode(&e); err != nil || e.Code == "" {
+			return fmt.Errorf("%s %s: unexpected status %d", req.Method, req.URL, resp.StatusCode)
+		}
+		return bookid.Errorf(e.Code, "%s", e.Error)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("%s %s: decoding response: %w", req.Method, req.URL, err)
+	}
+	return nil
+}
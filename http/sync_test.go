@@ -0,0 +1,84 @@
+package http_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/fwojciec/bookid"
+	bookidhttp "github.com/fwojciec/bookid/http"
+	"github.com/fwojciec/bookid/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSyncClient(t *testing.T) {
+	t.Parallel()
+
+	since := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	s, _ := MustOpenServer(t, nil)
+	s.SyncService = &mock.SyncService{
+		FindChangesFn: func(_ context.Context, got time.Time) (*bookid.SyncChanges, error) {
+			assert.True(t, got.Equal(since))
+			return &bookid.SyncChanges{
+				Records:    []*bookid.SyncRecord{{Work: &bookid.Work{UID: "a1", Title: "Dune"}}},
+				Tombstones: []*bookid.Tombstone{{UID: "b2", Entity: bookid.AuditEntityWork, DeletedAt: since}},
+			}, nil
+		},
+		ApplyChangesFn: func(_ context.Context, changes *bookid.SyncChanges, opts bookid.SyncOptions) (*bookid.SyncReport, error) {
+			if len(changes.Records) == 0 {
+				return nil, bookid.Errorf(bookid.EINVALID, "Nothing to apply.")
+			}
+			assert.Equal(t, "Dune", changes.Records[0].Work.Title)
+			assert.Equal(t, bookid.SyncRuleLocal, opts.Rule)
+			assert.True(t, opts.Since.Equal(since))
+			return &bookid.SyncReport{Created: 1}, nil
+		},
+	}
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+	c := bookidhttp.NewSyncClient(ts.URL+"/", time.Second)
+	ctx := context.Background()
+
+	changes, err := c.FindChanges(ctx, since)
+	require.NoError(t, err)
+	require.Len(t, changes.Records, 1)
+	assert.Equal(t, "a1", changes.Records[0].Work.UID)
+	require.Len(t, changes.Tombstones, 1)
+	assert.True(t, changes.Tombstones[0].DeletedAt.Equal(since))
+
+	report, err := c.ApplyChanges(ctx, changes, bookid.SyncOptions{Since: since, Rule: bookid.SyncRuleLocal})
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.Created)
+
+	// Errors keep the code the server responded with.
+	_, err = c.ApplyChanges(ctx, &bookid.SyncChanges{}, bookid.SyncOptions{})
+	assert.Equal(t, bookid.EINVALID, bookid.ErrorCode(err))
+	assert.Equal(t, "Nothing to apply.", bookid.ErrorMessage(err))
+}
+
+func TestServer_Sync(t *testing.T) {
+	t.Parallel()
+
+	t.Run("ErrInvalidSince", func(t *testing.T) {
+		t.Parallel()
+		s, _ := MustOpenServer(t, nil)
+		s.SyncService = &mock.SyncService{}
+
+		w := serve(s, http.MethodGet, "/sync/changes?since=yesterday", "")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, bookid.EINVALID, decodeError(t, w).Code)
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		t.Parallel()
+		s, _ := MustOpenServer(t, nil)
+
+		w := serve(s, http.MethodGet, "/sync/changes", "")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		w = serve(s, http.MethodPost, "/sync/changes", `{"changes": {}}`)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
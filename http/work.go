@@ -0,0 +1,40 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/fwojciec/bookid"
+)
+
+// handleWorkView handles the "GET /works/{id}" route.
+func (s *Server) handleWorkView(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		Error(w, r, err)
+		return
+	}
+
+	work, err := s.WorkService.FindWorkByID(r.Context(), id)
+	if err != nil {
+		Error(w, r, err)
+		return
+	}
+	writeJSON(w, r, http.StatusOK, work)
+}
+
+// handleWorkCreate handles the "POST /works" route. It reads the work from
+// the JSON request body and responds with the created work.
+func (s *Server) handleWorkCreate(w http.ResponseWriter, r *http.Request) {
+	var work bookid.Work
+	if err := json.NewDecoder(r.Body).Decode(&work); err != nil {
+		Error(w, r, bookid.Errorf(bookid.EINVALID, "Invalid JSON body."))
+		return
+	}
+
+	if err := s.WorkService.CreateWork(r.Context(), &work); err != nil {
+		Error(w, r, err)
+		return
+	}
+	writeJSON(w, r, http.StatusCreated, &work)
+}
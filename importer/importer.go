@@ -0,0 +1,173 @@
+// Package importer migrates reading-tracker exports into the catalog. Each
+// row is identified with a BookFinder, preferring its ISBN over its title
+// and author, and matched books are saved; rows that cannot be identified
+// are reported so they can be fixed by hand, and weak title matches can be
+// queued for review.
+package importer
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/binding"
+	"github.com/fwojciec/bookid/goodreads"
+)
+
+// ReviewCandidates is the number of search results queued for review when a
+// title and author match is too weak to save.
+const ReviewCandidates = 5
+
+// Importer identifies and saves the books of a Goodreads or StoryGraph CSV
+// export.
+type Importer struct {
+	Finder         bookid.BookFinder
+	CatalogService bookid.CatalogService
+
+	// Title and author matches below this confidence are reported as
+	// unmatched rather than saved. ISBN matches are always saved.
+	MinConfidence float64
+
+	// If set, title and author matches below MinConfidence are queued here
+	// with up to ReviewCandidates results for the user to choose from,
+	// rather than reported as unmatched.
+	ReviewService bookid.ReviewService
+
+	// Number of books at the start of the export to pass over, such as the
+	// ones an interrupted import already saved.
+	Skip int
+
+	// Called after each book with the number of books done so far, including
+	// skipped ones, if set. An error stops the import.
+	Progress func(done int) error
+}
+
+// Report summarizes an import.
+type Report struct {
+	Imported  int   `json:"imported"`
+	Queued    int   `json:"queued"` // Rows queued for review
+	Unmatched []Row `json:"unmatched"`
+}
+
+// Row is a row of the export that could not be identified.
+type Row struct {
+	Line   int    `json:"line"`
+	Title  string `json:"title"`
+	Author string `json:"author,omitempty"`
+	ISBN   string `json:"isbn,omitempty"`
+	Error  string `json:"error,omitempty"` // Set if identification failed
+}
+
+// Import reads an export from r, saving every row that can be identified.
+// Rows whose search fails are reported as unmatched along with the error;
+// errors reading the export, saving a book or a canceled ctx stop the import.
+func (imp *Importer) Import(ctx context.Context, r io.Reader) (*Report, error) {
+	report := &Report{Unmatched: []Row{}}
+	reader := goodreads.NewReader(r)
+	for done := 1; ; done++ {
+		book, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return report, nil
+		} else if err != nil {
+			return nil, err
+		} else if done <= imp.Skip {
+			continue
+		}
+
+		if err := imp.importBook(ctx, report, reader.Line(), book); err != nil {
+			return nil, err
+		} else if imp.Progress != nil {
+			if err := imp.Progress(done); err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+// importBook identifies and saves a single book, adding it to the report of
+// unmatched rows if it cannot be identified.
+func (imp *Importer) importBook(ctx context.Context, report *Report, line int, book *goodreads.Book) error {
+	row := Row{Line: line, Title: book.Title, Author: book.Author, ISBN: book.ISBN13}
+	if row.ISBN == "" {
+		row.ISBN = book.ISBN
+	}
+
+	result, candidates, err := imp.identify(ctx, book)
+	if ctx.Err() != nil {
+		return ctx.Err()
+	} else if err != nil {
+		row.Error = bookid.ErrorMessage(err)
+		report.Unmatched = append(report.Unmatched, row)
+		return nil
+	} else if result == nil && len(candidates) > 0 {
+		if err := imp.ReviewService.CreateReview(ctx, &bookid.Review{
+			Source:     "import",
+			Line:       line,
+			Query:      titleQuery(book),
+			Candidates: candidates,
+		}); err != nil {
+			return err
+		}
+		report.Queued++
+		return nil
+	} else if result == nil {
+		report.Unmatched = append(report.Unmatched, row)
+		return nil
+	}
+
+	if _, _, err := imp.CatalogService.SaveResult(ctx, *result); err != nil {
+		return err
+	}
+	report.Imported++
+	return nil
+}
+
+// identify returns the best match for book, or nil if there is none. The
+// ISBN is searched first, and its match takes the binding of the exported
+// copy if the provider does not tell it; title and author are the fallback. With a review
+// service, title and author matches too weak to save are returned as
+// candidates instead.
+func (imp *Importer) identify(ctx context.Context, book *goodreads.Book) (*bookid.BookResult, []bookid.BookResult, error) {
+	for _, isbn := range []string{book.ISBN13, book.ISBN} {
+		if isbn == "" {
+			continue
+		}
+		results, err := imp.Finder.Search(ctx, isbn, bookid.SearchOptions{MaxResults: 1, IncludeRaw: true})
+		if err != nil {
+			return nil, nil, err
+		} else if len(results) > 0 {
+			if results[0].Binding == "" {
+				results[0].Binding = binding.Parse(book.Binding)
+			}
+			return &results[0], nil, nil
+		}
+	}
+
+	query := titleQuery(book)
+	if query == "" {
+		return nil, nil, nil
+	}
+	opts := bookid.SearchOptions{MaxResults: 1, MinConfidence: imp.MinConfidence, IncludeRaw: true}
+	if imp.ReviewService != nil {
+		opts.MaxResults, opts.MinConfidence = ReviewCandidates, 0
+	}
+	results, err := imp.Finder.Search(ctx, query, opts)
+	if err != nil {
+		return nil, nil, err
+	} else if len(results) == 0 {
+		return nil, nil, nil
+	} else if results[0].Confidence < imp.MinConfidence {
+		if imp.ReviewService == nil {
+			return nil, nil, nil
+		}
+		return nil, results, nil
+	}
+	return &results[0], nil, nil
+}
+
+// titleQuery returns the title and author search query of book.
+func titleQuery(book *goodreads.Book) string {
+	return strings.TrimSpace(book.Title + " " + book.Author)
+}
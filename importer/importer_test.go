@@ -0,0 +1,203 @@
+package importer_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/importer"
+	"github.com/fwojciec/bookid/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mapFinder returns the results registered for a query and records the
+// queries it was asked.
+type mapFinder struct {
+	results map[string][]bookid.BookResult
+	errs    map[string]error
+	queries []string
+}
+
+func (f *mapFinder) Search(_ context.Context, query string, _ bookid.SearchOptions) ([]bookid.BookResult, error) {
+	f.queries = append(f.queries, query)
+	if err := f.errs[query]; err != nil {
+		return nil, err
+	}
+	return f.results[query], nil
+}
+
+// catalog records the titles and bindings of saved results, failing with
+// err if set.
+type catalog struct {
+	saved    []string
+	bindings []bookid.Binding
+	err      error
+}
+
+func (c *catalog) SaveResult(_ context.Context, result bookid.BookResult) (int64, int64, error) {
+	if c.err != nil {
+		return 0, 0, c.err
+	}
+	c.saved = append(c.saved, result.Title)
+	c.bindings = append(c.bindings, result.Binding)
+	return int64(len(c.saved)), int64(len(c.saved)), nil
+}
+
+const export = `Book Id,Title,Author,ISBN,ISBN13
+1,The Great Gatsby,F. Scott Fitzgerald,"=""0743273567""","=""9780743273565"""
+2,Solaris,Stanisław Lem,"=""""","=""0156027607"""
+3,Dune,Frank Herbert,"=""""","="""""
+4,Untraceable,Nobody,"=""""","="""""
+5,Flaky,Someone,"=""""","="""""
+`
+
+func TestImporter_Import(t *testing.T) {
+	t.Parallel()
+
+	finder := &mapFinder{
+		results: map[string][]bookid.BookResult{
+			"9780743273565":      {{Title: "The Great Gatsby", ISBN13: "9780743273565"}},
+			"0156027607":         {{Title: "Solaris", ISBN10: "0156027607"}},
+			"Dune Frank Herbert": {{Title: "Dune", Authors: []string{"Frank Herbert"}}},
+		},
+		errs: map[string]error{
+			"Flaky Someone": bookid.Errorf(bookid.EUNAVAILABLE, "Provider unavailable."),
+		},
+	}
+	cat := &catalog{}
+	imp := &importer.Importer{Finder: finder, CatalogService: cat}
+
+	report, err := imp.Import(context.Background(), strings.NewReader(export))
+	require.NoError(t, err)
+	assert.Equal(t, 3, report.Imported)
+	assert.Equal(t, []string{"The Great Gatsby", "Solaris", "Dune"}, cat.saved)
+	assert.Equal(t, []importer.Row{
+		{Line: 5, Title: "Untraceable", Author: "Nobody"},
+		{Line: 6, Title: "Flaky", Author: "Someone", Error: "Provider unavailable."},
+	}, report.Unmatched)
+
+	// ISBNs are preferred; title and author are searched only without one.
+	assert.Equal(t, []string{
+		"9780743273565",
+		"0156027607",
+		"Dune Frank Herbert",
+		"Untraceable Nobody",
+		"Flaky Someone",
+	}, finder.queries)
+}
+
+func TestImporter_Import_Skip(t *testing.T) {
+	t.Parallel()
+
+	finder := &mapFinder{results: map[string][]bookid.BookResult{
+		"Dune Frank Herbert": {{Title: "Dune"}},
+	}}
+	var progress []int
+	imp := &importer.Importer{
+		Finder:         finder,
+		CatalogService: &catalog{},
+		Skip:           3,
+		Progress: func(done int) error {
+			progress = append(progress, done)
+			return nil
+		},
+	}
+
+	report, err := imp.Import(context.Background(), strings.NewReader(export))
+	require.NoError(t, err)
+	assert.Equal(t, 0, report.Imported)
+	assert.Equal(t, []string{"Untraceable Nobody", "Flaky Someone"}, finder.queries)
+	assert.Equal(t, []int{4, 5}, progress)
+}
+
+func TestImporter_Import_FallsBackToTitle(t *testing.T) {
+	t.Parallel()
+
+	finder := &mapFinder{results: map[string][]bookid.BookResult{
+		"The Great Gatsby F. Scott Fitzgerald": {{Title: "The Great Gatsby"}},
+	}}
+	imp := &importer.Importer{Finder: finder, CatalogService: &catalog{}}
+
+	report, err := imp.Import(context.Background(), strings.NewReader(
+		"Title,Author,ISBN13\nThe Great Gatsby,F. Scott Fitzgerald,9780743273565\n"))
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.Imported)
+	assert.Equal(t, []string{"9780743273565", "The Great Gatsby F. Scott Fitzgerald"}, finder.queries)
+}
+
+func TestImporter_Import_Binding(t *testing.T) {
+	t.Parallel()
+
+	finder := &mapFinder{results: map[string][]bookid.BookResult{
+		"9780743273565":         {{Title: "The Great Gatsby"}},
+		"9780441172719":         {{Title: "Dune", Binding: bookid.BindingPaperback}},
+		"Solaris Stanisław Lem": {{Title: "Solaris"}},
+	}}
+	cat := &catalog{}
+	imp := &importer.Importer{Finder: finder, CatalogService: cat}
+
+	_, err := imp.Import(context.Background(), strings.NewReader(
+		"Title,Author,ISBN13,Binding\n"+
+			"The Great Gatsby,F. Scott Fitzgerald,9780743273565,Kindle Edition\n"+
+			"Dune,Frank Herbert,9780441172719,Hardcover\n"+
+			"Solaris,Stanisław Lem,,Hardcover\n"))
+	require.NoError(t, err)
+	assert.Equal(t, []bookid.Binding{bookid.BindingEbook, bookid.BindingPaperback, ""}, cat.bindings,
+		"the binding of the export fills in for ISBN matches only")
+}
+
+func TestImporter_Import_SaveError(t *testing.T) {
+	t.Parallel()
+
+	errSave := errors.New("disk full")
+	imp := &importer.Importer{
+		Finder: &mapFinder{results: map[string][]bookid.BookResult{
+			"Dune Frank Herbert": {{Title: "Dune"}},
+		}},
+		CatalogService: &catalog{err: errSave},
+	}
+
+	_, err := imp.Import(context.Background(), strings.NewReader("Title,Author\nDune,Frank Herbert\n"))
+	assert.ErrorIs(t, err, errSave)
+}
+
+func TestImporter_Import_Review(t *testing.T) {
+	t.Parallel()
+
+	finder := &mapFinder{results: map[string][]bookid.BookResult{
+		"Dune Frank Herbert": {
+			{Title: "Dune", Confidence: 0.4},
+			{Title: "Dune Messiah", Confidence: 0.3},
+		},
+		"Solaris Stanisław Lem": {{Title: "Solaris", Confidence: 0.9}},
+	}}
+	var queued []*bookid.Review
+	cat := &catalog{}
+	imp := &importer.Importer{
+		Finder:         finder,
+		CatalogService: cat,
+		MinConfidence:  0.5,
+		ReviewService: &mock.ReviewService{
+			CreateReviewFn: func(_ context.Context, review *bookid.Review) error {
+				queued = append(queued, review)
+				return nil
+			},
+		},
+	}
+
+	report, err := imp.Import(context.Background(), strings.NewReader(
+		"Title,Author\nDune,Frank Herbert\nSolaris,Stanisław Lem\nUntraceable,Nobody\n"))
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.Imported)
+	assert.Equal(t, 1, report.Queued)
+	assert.Equal(t, []string{"Solaris"}, cat.saved)
+	assert.Equal(t, []importer.Row{{Line: 4, Title: "Untraceable", Author: "Nobody"}}, report.Unmatched)
+	require.Len(t, queued, 1)
+	assert.Equal(t, "import", queued[0].Source)
+	assert.Equal(t, 2, queued[0].Line)
+	assert.Equal(t, "Dune Frank Herbert", queued[0].Query)
+	assert.Len(t, queued[0].Candidates, 2)
+}
@@ -0,0 +1,101 @@
+// Package inmem implements bookid services in memory, for a single process.
+package inmem
+
+import (
+	"context"
+	"sync"
+
+	"github.com/fwojciec/bookid"
+)
+
+// DefaultBufferSize is the number of events buffered for each subscription.
+const DefaultBufferSize = 100
+
+// Ensure type implements interface.
+var _ bookid.EventService = (*EventService)(nil)
+
+// EventService represents a service for publishing events to subscribers in
+// the same process.
+type EventService struct {
+	mu   sync.Mutex
+	subs map[*Subscription]struct{}
+
+	// Number of events buffered for each new subscription. A subscriber
+	// falling further behind is unsubscribed, closing its stream, so that
+	// publishing never blocks.
+	BufferSize int
+}
+
+// NewEventService returns a new instance of EventService.
+func NewEventService() *EventService {
+	return &EventService{
+		subs:       make(map[*Subscription]struct{}),
+		BufferSize: DefaultBufferSize,
+	}
+}
+
+// PublishEvent publishes event to all current subscribers.
+func (s *EventService) PublishEvent(event bookid.Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for sub := range s.subs {
+		select {
+		case sub.c <- event:
+		default:
+			s.unsubscribe(sub)
+		}
+	}
+}
+
+// Subscribe creates a subscription to all future events. The subscription
+// is closed when ctx is done.
+func (s *EventService) Subscribe(ctx context.Context) (bookid.Subscription, error) {
+	sub := &Subscription{service: s, c: make(chan bookid.Event, s.BufferSize)}
+
+	s.mu.Lock()
+	s.subs[sub] = struct{}{}
+	s.mu.Unlock()
+
+	sub.stop = context.AfterFunc(ctx, func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.unsubscribe(sub)
+	})
+	return sub, nil
+}
+
+// unsubscribe removes sub and closes its stream. Must be called with the
+// lock held.
+func (s *EventService) unsubscribe(sub *Subscription) {
+	if _, ok := s.subs[sub]; !ok {
+		return
+	}
+	delete(s.subs, sub)
+	close(sub.c)
+}
+
+// Ensure type implements interface.
+var _ bookid.Subscription = (*Subscription)(nil)
+
+// Subscription represents a stream of events published to an EventService.
+type Subscription struct {
+	service *EventService
+	c       chan bookid.Event
+	stop    func() bool // stops closing on context cancellation
+}
+
+// C returns the event stream.
+func (sub *Subscription) C() <-chan bookid.Event {
+	return sub.c
+}
+
+// Close unsubscribes and closes the event stream. Closing an already closed
+// subscription is a no-op.
+func (sub *Subscription) Close() error {
+	sub.stop()
+	sub.service.mu.Lock()
+	defer sub.service.mu.Unlock()
+	sub.service.unsubscribe(sub)
+	return nil
+}
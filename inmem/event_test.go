@@ -0,0 +1,66 @@
+package inmem_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/inmem"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventService(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Publish", func(t *testing.T) {
+		t.Parallel()
+		s := inmem.NewEventService()
+		sub0, err := s.Subscribe(context.Background())
+		require.NoError(t, err)
+		sub1, err := s.Subscribe(context.Background())
+		require.NoError(t, err)
+
+		event := bookid.Event{Type: bookid.EventTypeWorkCreated, Payload: &bookid.WorkCreatedPayload{Work: &bookid.Work{ID: 1}}}
+		s.PublishEvent(event)
+		assert.Equal(t, event, <-sub0.C())
+		assert.Equal(t, event, <-sub1.C())
+
+		// Closed subscriptions no longer receive events.
+		require.NoError(t, sub0.Close())
+		require.NoError(t, sub0.Close())
+		s.PublishEvent(event)
+		_, ok := <-sub0.C()
+		assert.False(t, ok)
+		assert.Equal(t, event, <-sub1.C())
+	})
+
+	t.Run("ContextDone", func(t *testing.T) {
+		t.Parallel()
+		s := inmem.NewEventService()
+		ctx, cancel := context.WithCancel(context.Background())
+		sub, err := s.Subscribe(ctx)
+		require.NoError(t, err)
+
+		cancel()
+		_, ok := <-sub.C()
+		assert.False(t, ok)
+		require.NoError(t, sub.Close())
+	})
+
+	t.Run("SlowSubscriber", func(t *testing.T) {
+		t.Parallel()
+		s := inmem.NewEventService()
+		s.BufferSize = 1
+		sub, err := s.Subscribe(context.Background())
+		require.NoError(t, err)
+
+		// The second event does not fit the buffer so the subscriber is
+		// dropped rather than blocking the publisher.
+		s.PublishEvent(bookid.Event{Type: bookid.EventTypeWorkCreated})
+		s.PublishEvent(bookid.Event{Type: bookid.EventTypeWorkMerged})
+		assert.Equal(t, bookid.EventTypeWorkCreated, (<-sub.C()).Type)
+		_, ok := <-sub.C()
+		assert.False(t, ok)
+	})
+}
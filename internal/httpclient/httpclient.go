@@ -0,0 +1,117 @@
+// Package httpclient sends the HTTP requests of the provider clients and
+// reports unexpected responses alike for all of them: statuses that tell a
+// provider is rate limiting, unavailable or rejecting its credentials are
+// mapped to bookid error codes, and other statuses are returned as a
+// StatusError.
+package httpclient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/fwojciec/bookid"
+)
+
+// StatusError reports an unexpected HTTP status from a service.
+type StatusError struct {
+	Service string
+	Code    int
+
+	// URL of the request without its query, which may carry credentials.
+	URL string
+}
+
+// Error implements the error interface.
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s: unexpected status %d for %s", e.Service, e.Code, e.URL)
+}
+
+// StatusCode returns the HTTP status code of the response.
+func (e *StatusError) StatusCode() int { return e.Code }
+
+// IsStatus reports whether err is a StatusError with one of codes.
+func IsStatus(err error, codes ...int) bool {
+	var statusErr *StatusError
+	return errors.As(err, &statusErr) && slices.Contains(codes, statusErr.Code)
+}
+
+// FormatError returns err as a bookid error if it is a StatusError with a
+// status we can classify. Otherwise returns the original error.
+//
+//   - 401 and 403: EUNAUTHORIZED
+//   - 429: ERATELIMIT
+//   - 5xx: EUNAVAILABLE
+func FormatError(err error) error {
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) {
+		return err
+	}
+
+	switch {
+	case statusErr.Code == http.StatusUnauthorized || statusErr.Code == http.StatusForbidden:
+		return bookid.Errorf(bookid.EUNAUTHORIZED, "%s denied access (status %d).", statusErr.Service, statusErr.Code)
+	case statusErr.Code == http.StatusTooManyRequests:
+		return bookid.Errorf(bookid.ERATELIMIT, "%s rate limit exceeded.", statusErr.Service)
+	case statusErr.Code >= http.StatusInternalServerError:
+		return bookid.Errorf(bookid.EUNAVAILABLE, "%s is unavailable (status %d).", statusErr.Service, statusErr.Code)
+	}
+	return err
+}
+
+// Do sends req with client and returns the response if its status is 200 OK.
+// Otherwise the response is closed and its status returned as an error
+// formatted by FormatError. service names the service in errors, e.g.
+// "Crossref".
+func Do(client *http.Client, service string, req *http.Request) (*http.Response, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	} else if resp.StatusCode != http.StatusOK {
+		_ = resp.Body.Close()
+		u := *req.URL
+		u.RawQuery = ""
+		return nil, FormatError(&StatusError{Service: service, Code: resp.StatusCode, URL: u.String()})
+	}
+	return resp, nil
+}
+
+// GetJSON sends a GET request for u with the given headers, which may be nil,
+// and decodes the JSON response into v. See Do for the errors returned.
+func GetJSON(ctx context.Context, client *http.Client, service, u string, header http.Header, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := Do(client, service, req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("%s: decoding response: %w", service, err)
+	}
+	return nil
+}
+
+// EnsureHTTPS returns u with an http or scheme-relative URL upgraded to
+// https, as providers often link images over plain HTTP.
+func EnsureHTTPS(u string) string {
+	switch {
+	case strings.HasPrefix(u, "http://"):
+		return "https://" + strings.TrimPrefix(u, "http://")
+	case strings.HasPrefix(u, "//"):
+		return "https:" + u
+	}
+	return u
+}
@@ -0,0 +1,93 @@
+package httpclient_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/internal/httpclient"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetJSON(t *testing.T) {
+	t.Parallel()
+
+	t.Run("OK", func(t *testing.T) {
+		t.Parallel()
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "application/json", r.Header.Get("Accept"))
+			assert.Equal(t, "KEY", r.Header.Get("Authorization"))
+			_, _ = w.Write([]byte(`{"title":"Dune"}`))
+		}))
+		t.Cleanup(srv.Close)
+
+		var v struct {
+			Title string `json:"title"`
+		}
+		err := httpclient.GetJSON(context.Background(), srv.Client(), "Test", srv.URL+"/books?q=dune", http.Header{"Authorization": {"KEY"}}, &v)
+		require.NoError(t, err)
+		assert.Equal(t, "Dune", v.Title)
+	})
+
+	t.Run("InvalidJSON", func(t *testing.T) {
+		t.Parallel()
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte(`<html>`))
+		}))
+		t.Cleanup(srv.Close)
+
+		var v any
+		err := httpclient.GetJSON(context.Background(), srv.Client(), "Test", srv.URL, nil, &v)
+		assert.ErrorContains(t, err, "Test: decoding response")
+	})
+
+	for _, tt := range []struct {
+		status int
+		code   string
+	}{
+		{http.StatusUnauthorized, bookid.EUNAUTHORIZED},
+		{http.StatusForbidden, bookid.EUNAUTHORIZED},
+		{http.StatusTooManyRequests, bookid.ERATELIMIT},
+		{http.StatusInternalServerError, bookid.EUNAVAILABLE},
+		{http.StatusServiceUnavailable, bookid.EUNAVAILABLE},
+	} {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			t.Parallel()
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(tt.status)
+			}))
+			t.Cleanup(srv.Close)
+
+			var v any
+			err := httpclient.GetJSON(context.Background(), srv.Client(), "Test", srv.URL, nil, &v)
+			assert.Equal(t, tt.code, bookid.ErrorCode(err))
+			assert.Contains(t, bookid.ErrorMessage(err), "Test")
+		})
+	}
+
+	t.Run("OtherStatus", func(t *testing.T) {
+		t.Parallel()
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		}))
+		t.Cleanup(srv.Close)
+
+		var v any
+		err := httpclient.GetJSON(context.Background(), srv.Client(), "Test", srv.URL+"/books/1?key=secret", nil, &v)
+		assert.True(t, httpclient.IsStatus(err, http.StatusGone, http.StatusNotFound))
+		assert.False(t, httpclient.IsStatus(err, http.StatusBadRequest))
+		assert.EqualError(t, err, "Test: unexpected status 404 for "+srv.URL+"/books/1", "the query is left out")
+	})
+}
+
+func TestEnsureHTTPS(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "https://example.com/a.jpg?u=http://x", httpclient.EnsureHTTPS("http://example.com/a.jpg?u=http://x"))
+	assert.Equal(t, "https://example.com/a.jpg", httpclient.EnsureHTTPS("//example.com/a.jpg"))
+	assert.Equal(t, "https://example.com/a.jpg", httpclient.EnsureHTTPS("https://example.com/a.jpg"))
+	assert.Equal(t, "", httpclient.EnsureHTTPS(""))
+}
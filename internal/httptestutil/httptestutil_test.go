@@ -0,0 +1,134 @@
+package httptestutil_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fwojciec/bookid/internal/httptestutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordReplay(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/text" {
+			_, _ = io.WriteString(w, "plain text")
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"q":"`+r.URL.Query().Get("q")+`"}`)
+	}))
+	t.Cleanup(srv.Close)
+	path := filepath.Join(t.TempDir(), "fixture.json")
+
+	r := &httptestutil.Recorder{Fixture: &httptestutil.Fixture{}}
+	for _, u := range []string{srv.URL + "/search?q=dune&key=secret", srv.URL + "/text"} {
+		resp, err := (&http.Client{Transport: r}).Get(u)
+		require.NoError(t, err)
+		_ = resp.Body.Close()
+	}
+	require.NoError(t, r.Fixture.Save(path))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "secret")
+
+	client := httptestutil.NewClient(t, path, false)
+	resp, err := client.Get(srv.URL + "/search?key=other&q=dune")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+	assert.JSONEq(t, `{"q":"dune"}`, readBody(t, resp))
+
+	resp, err = client.Get(srv.URL + "/text")
+	require.NoError(t, err)
+	assert.Equal(t, "plain text", readBody(t, resp))
+
+	_, err = client.Get(srv.URL + "/search?q=emma")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no recorded response")
+}
+
+func TestReplay(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "fixture.json")
+	f := &httptestutil.Fixture{Interactions: []*httptestutil.Interaction{{
+		Request:  httptestutil.Request{Method: http.MethodGet, URL: "https://example.com/search?q=dune"},
+		Response: httptestutil.Response{StatusCode: http.StatusTooManyRequests, Body: []byte(`"slow down"`)},
+	}}}
+	require.NoError(t, f.Save(path))
+
+	resp, err := httptestutil.Replay(t, path).Get("https://example.com/search?q=dune")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	assert.Equal(t, "slow down", readBody(t, resp))
+}
+
+func TestNewClientWithTransport(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"authorization":"`+r.Header.Get("Authorization")+`"}`)
+	}))
+	t.Cleanup(srv.Close)
+	path := filepath.Join(t.TempDir(), "fixture.json")
+
+	// Not parallel, as the fixture is saved when the subtest ends.
+	t.Run("record", func(t *testing.T) {
+		auth := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			req = req.Clone(req.Context())
+			req.Header.Set("Authorization", "Bearer token")
+			return http.DefaultTransport.RoundTrip(req)
+		})
+		client := httptestutil.NewClientWithTransport(t, path, true, auth)
+		resp, err := client.Get(srv.URL + "/search")
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"authorization":"Bearer token"}`, readBody(t, resp))
+	})
+
+	f, err := httptestutil.Load(path)
+	require.NoError(t, err)
+	require.Len(t, f.Interactions, 1)
+	assert.Equal(t, srv.URL+"/search", f.Interactions[0].Request.URL)
+}
+
+func TestLastURL(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	t.Cleanup(srv.Close)
+
+	var lastURL string
+	client := httptestutil.LastURL(srv.Client(), &lastURL)
+	for _, u := range []string{srv.URL + "/a", srv.URL + "/b?q=dune"} {
+		resp, err := client.Get(u)
+		require.NoError(t, err)
+		_ = resp.Body.Close()
+	}
+	assert.Equal(t, srv.URL+"/b?q=dune", lastURL)
+}
+
+// roundTripperFunc is an http.RoundTripper calling a function.
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+// RoundTrip calls f.
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// readBody reads and closes the body of resp.
+func readBody(tb testing.TB, resp *http.Response) string {
+	tb.Helper()
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(tb, err)
+	return string(body)
+}
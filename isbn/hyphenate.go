@@ -0,0 +1,181 @@
+package isbn
+
+import (
+	_ "embed"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/fwojciec/bookid"
+)
+
+// rangeMessage holds the embedded International ISBN Agency range message
+// used by Hyphenate.
+//
+//go:generate curl -sSfLo RangeMessage.xml https://www.isbn-international.org/export_rangemessage.xml
+//go:embed RangeMessage.xml
+var rangeMessage string
+
+// defaultRanges parses the embedded range message on first use.
+var defaultRanges = sync.OnceValues(func() (*Ranges, error) {
+	return ParseRanges(strings.NewReader(rangeMessage))
+})
+
+// Ranges holds the registration group and registrant ranges used to split an
+// ISBN into its prefix, registration group, registrant, publication and check
+// digit elements.
+type Ranges struct {
+	prefixes map[string][]rangeRule // keyed by EAN prefix, e.g. "978"
+	groups   map[string][]rangeRule // keyed by prefix and group, e.g. "978-0"
+}
+
+// rangeRule maps a range of seven digit values following a prefix to the
+// length of the next ISBN element. A zero length marks an undefined range.
+type rangeRule struct {
+	from, to int
+	length   int
+}
+
+// DefaultRanges returns the ranges embedded in the package. The range data is
+// parsed once and shared by all callers.
+func DefaultRanges() (*Ranges, error) {
+	return defaultRanges()
+}
+
+// ParseRanges reads ranges in the International ISBN Agency RangeMessage.xml
+// format from r.
+func ParseRanges(r io.Reader) (*Ranges, error) {
+	type rules struct {
+		Rule []struct {
+			Range  string `xml:"Range"`
+			Length int    `xml:"Length"`
+		} `xml:"Rule"`
+	}
+	var msg struct {
+		Prefixes []struct {
+			Prefix string `xml:"Prefix"`
+			Rules  rules  `xml:"Rules"`
+		} `xml:"EAN.UCCPrefixes>EAN.UCC"`
+		Groups []struct {
+			Prefix string `xml:"Prefix"`
+			Rules  rules  `xml:"Rules"`
+		} `xml:"RegistrationGroups>Group"`
+	}
+	if err := xml.NewDecoder(r).Decode(&msg); err != nil {
+		return nil, fmt.Errorf("decoding ISBN range message: %w", err)
+	}
+
+	parse := func(prefix string, rs rules) ([]rangeRule, error) {
+		out := make([]rangeRule, 0, len(rs.Rule))
+		for _, r := range rs.Rule {
+			lo, hi, ok := strings.Cut(r.Range, "-")
+			from, err1 := strconv.Atoi(lo)
+			to, err2 := strconv.Atoi(hi)
+			if !ok || err1 != nil || err2 != nil {
+				return nil, fmt.Errorf("invalid ISBN range %q for prefix %s", r.Range, prefix)
+			}
+			out = append(out, rangeRule{from: from, to: to, length: r.Length})
+		}
+		return out, nil
+	}
+
+	ranges := &Ranges{
+		prefixes: make(map[string][]rangeRule, len(msg.Prefixes)),
+		groups:   make(map[string][]rangeRule, len(msg.Groups)),
+	}
+	for _, p := range msg.Prefixes {
+		rules, err := parse(p.Prefix, p.Rules)
+		if err != nil {
+			return nil, err
+		}
+		ranges.prefixes[p.Prefix] = rules
+	}
+	for _, g := range msg.Groups {
+		rules, err := parse(g.Prefix, g.Rules)
+		if err != nil {
+			return nil, err
+		}
+		ranges.groups[g.Prefix] = rules
+	}
+	return ranges, nil
+}
+
+// Hyphenate returns the canonical hyphenated form of an ISBN-10 or ISBN-13
+// using the embedded range data, e.g. "9780743273565" becomes
+// "978-0-7432-7356-5". ISBN-10s are returned in ISBN-10 form.
+//
+// Returns EINVALID if s is not a valid ISBN and ENOTFOUND if its registration
+// group or registrant range is not defined.
+func Hyphenate(s string) (string, error) {
+	ranges, err := DefaultRanges()
+	if err != nil {
+		return "", err
+	}
+	return ranges.Hyphenate(s)
+}
+
+// Format returns s hyphenated for display, or s unchanged if it cannot be
+// hyphenated.
+func Format(s string) string {
+	if h, err := Hyphenate(s); err == nil {
+		return h
+	}
+	return s
+}
+
+// Hyphenate returns the canonical hyphenated form of an ISBN-10 or ISBN-13.
+// See the package-level Hyphenate for details.
+func (r *Ranges) Hyphenate(s string) (string, error) {
+	s = Normalize(s)
+
+	var code string
+	switch {
+	case Valid13(s):
+		code = s
+	case Valid10(s):
+		code = "978" + s[:9] + string(checkDigit13("978"+s[:9]))
+	default:
+		return "", bookid.Errorf(bookid.EINVALID, "Invalid ISBN %q.", s)
+	}
+
+	prefix, body := code[:3], code[3:12]
+
+	// Registration group length is determined by the digits after the prefix.
+	groupLen := lookupRange(r.prefixes[prefix], body)
+	if groupLen == 0 || groupLen >= len(body) {
+		return "", bookid.Errorf(bookid.ENOTFOUND, "No registration group defined for ISBN %q.", s)
+	}
+	group, rest := body[:groupLen], body[groupLen:]
+
+	// Registrant length is determined by the digits after the group.
+	registrantLen := lookupRange(r.groups[prefix+"-"+group], rest)
+	if registrantLen == 0 || registrantLen >= len(rest) {
+		return "", bookid.Errorf(bookid.ENOTFOUND, "No registrant range defined for ISBN %q.", s)
+	}
+	registrant, publication := rest[:registrantLen], rest[registrantLen:]
+
+	if len(s) == 10 {
+		return strings.Join([]string{group, registrant, publication, s[9:]}, "-"), nil
+	}
+	return strings.Join([]string{prefix, group, registrant, publication, code[12:]}, "-"), nil
+}
+
+// lookupRange returns the element length for the rule whose range contains
+// the first seven digits of s, right-padded with zeros. Returns zero if no
+// rule matches.
+func lookupRange(rules []rangeRule, s string) int {
+	s = (s + "0000000")[:7]
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0
+	}
+	for _, rule := range rules {
+		if v >= rule.from && v <= rule.to {
+			return rule.length
+		}
+	}
+	return 0
+}
@@ -0,0 +1,122 @@
+// Package isbn validates, normalizes and converts International Standard
+// Book Numbers.
+package isbn
+
+import (
+	"strings"
+
+	"github.com/fwojciec/bookid"
+)
+
+// Normalize removes hyphens and spaces and upper-cases a trailing 'x' check
+// digit. It does not validate the result.
+func Normalize(s string) string {
+	return strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(strings.TrimSpace(s)))
+}
+
+// Valid returns true if s is a valid ISBN-10 or ISBN-13, ignoring hyphens
+// and spaces.
+func Valid(s string) bool {
+	s = Normalize(s)
+	return Valid10(s) || Valid13(s)
+}
+
+// Valid10 returns true if s is exactly ten characters forming an ISBN-10
+// with a correct check digit. The check digit may be 'X' (ten).
+func Valid10(s string) bool {
+	if len(s) != 10 || !isDigits(s[:9]) {
+		return false
+	}
+	return checkDigit10(s[:9]) == s[9]
+}
+
+// Valid13 returns true if s is exactly thirteen digits forming an ISBN-13
+// (Bookland EAN prefix 978 or 979) with a correct check digit.
+func Valid13(s string) bool {
+	if len(s) != 13 || !isDigits(s) {
+		return false
+	} else if !strings.HasPrefix(s, "978") && !strings.HasPrefix(s, "979") {
+		return false
+	}
+	return checkDigit13(s[:12]) == s[12]
+}
+
+// To13 converts an ISBN-10 to its ISBN-13 form. Valid ISBN-13s are returned
+// normalized. Returns EINVALID for anything else.
+func To13(s string) (string, error) {
+	s = Normalize(s)
+	if Valid13(s) {
+		return s, nil
+	} else if !Valid10(s) {
+		return "", bookid.Errorf(bookid.EINVALID, "Invalid ISBN %q.", s)
+	}
+	prefix := "978" + s[:9]
+	return prefix + string(checkDigit13(prefix)), nil
+}
+
+// To10 converts an ISBN-13 to its ISBN-10 form. Only 978-prefixed ISBN-13s
+// have an ISBN-10 equivalent. Valid ISBN-10s are returned normalized.
+// Returns EINVALID for anything else.
+func To10(s string) (string, error) {
+	s = Normalize(s)
+	if Valid10(s) {
+		return s, nil
+	} else if !Valid13(s) {
+		return "", bookid.Errorf(bookid.EINVALID, "Invalid ISBN %q.", s)
+	} else if !strings.HasPrefix(s, "978") {
+		return "", bookid.Errorf(bookid.EINVALID, "ISBN %q has no ISBN-10 form.", s)
+	}
+	body := s[3:12]
+	return body + string(checkDigit10(body)), nil
+}
+
+// FromSBN converts a nine digit Standard Book Number, the predecessor of
+// ISBN used until 1974, to its ISBN-10 form by prefixing a zero. Valid
+// ISBN-10s are returned normalized. Returns EINVALID for anything else.
+func FromSBN(s string) (string, error) {
+	s = Normalize(s)
+	if len(s) == 9 {
+		s = "0" + s
+	}
+	if !Valid10(s) {
+		return "", bookid.Errorf(bookid.EINVALID, "Invalid SBN %q.", s)
+	}
+	return s, nil
+}
+
+// checkDigit10 computes the ISBN-10 check digit for nine digits.
+func checkDigit10(s string) byte {
+	sum := 0
+	for i := 0; i < 9; i++ {
+		sum += int(s[i]-'0') * (10 - i)
+	}
+	switch d := (11 - sum%11) % 11; d {
+	case 10:
+		return 'X'
+	default:
+		return byte('0' + d)
+	}
+}
+
+// checkDigit13 computes the EAN-13 check digit for twelve digits.
+func checkDigit13(s string) byte {
+	sum := 0
+	for i := 0; i < 12; i++ {
+		d := int(s[i] - '0')
+		if i%2 == 1 {
+			d *= 3
+		}
+		sum += d
+	}
+	return byte('0' + (10-sum%10)%10)
+}
+
+// isDigits checks if string contains only ASCII digits.
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
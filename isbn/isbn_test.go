@@ -0,0 +1,188 @@
+package isbn_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/isbn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValid(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		input string
+		want  bool
+	}{
+		{"0743273567", true},
+		{"0-7432-7356-7", true},
+		{"9780743273565", true},
+		{"978-0-7432-7356-5", true},
+		{"080442957X", true},
+		{"080442957x", true},
+		{"9791032305690", true},
+		{"1234567890", false},    // bad check digit
+		{"9780743273566", false}, // bad check digit
+		{"9770743273565", false}, // not a Bookland prefix
+		{"074327356", false},
+		{"07432735X7", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.want, isbn.Valid(tt.input))
+		})
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "080442957X", isbn.Normalize(" 0-8044-2957-x "))
+	assert.Equal(t, "9780743273565", isbn.Normalize("978 0 7432 7356 5"))
+}
+
+func TestTo13(t *testing.T) {
+	t.Parallel()
+
+	t.Run("convert", func(t *testing.T) {
+		t.Parallel()
+		got, err := isbn.To13("0-7432-7356-7")
+		require.NoError(t, err)
+		assert.Equal(t, "9780743273565", got)
+
+		got, err = isbn.To13("080442957X")
+		require.NoError(t, err)
+		assert.Equal(t, "9780804429573", got)
+	})
+
+	t.Run("already isbn13", func(t *testing.T) {
+		t.Parallel()
+		got, err := isbn.To13("978-0-7432-7356-5")
+		require.NoError(t, err)
+		assert.Equal(t, "9780743273565", got)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		t.Parallel()
+		_, err := isbn.To13("1234567890")
+		assert.Equal(t, bookid.EINVALID, bookid.ErrorCode(err))
+	})
+}
+
+func TestTo10(t *testing.T) {
+	t.Parallel()
+
+	t.Run("convert", func(t *testing.T) {
+		t.Parallel()
+		got, err := isbn.To10("9780743273565")
+		require.NoError(t, err)
+		assert.Equal(t, "0743273567", got)
+
+		got, err = isbn.To10("9780804429573")
+		require.NoError(t, err)
+		assert.Equal(t, "080442957X", got)
+	})
+
+	t.Run("979 prefix has no isbn10", func(t *testing.T) {
+		t.Parallel()
+		_, err := isbn.To10("9791032305690")
+		assert.Equal(t, bookid.EINVALID, bookid.ErrorCode(err))
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		t.Parallel()
+		_, err := isbn.To10("9780743273566")
+		assert.Equal(t, bookid.EINVALID, bookid.ErrorCode(err))
+	})
+}
+
+func TestFromSBN(t *testing.T) {
+	t.Parallel()
+
+	got, err := isbn.FromSBN("345-24223-8")
+	require.NoError(t, err)
+	assert.Equal(t, "0345242238", got)
+
+	got, err = isbn.FromSBN("0345242238")
+	require.NoError(t, err)
+	assert.Equal(t, "0345242238", got)
+
+	_, err = isbn.FromSBN("345242239")
+	assert.Equal(t, bookid.EINVALID, bookid.ErrorCode(err))
+}
+
+func TestHyphenate(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"9780743273565", "978-0-7432-7356-5"},
+		{"0743273567", "0-7432-7356-7"},
+		{"9780134190440", "978-0-13-419044-0"},
+		{"9780441172719", "978-0-441-17271-9"},
+		{"9781402894626", "978-1-4028-9462-6"},
+		{"9783161484100", "978-3-16-148410-0"},
+		{"9782070368228", "978-2-07-036822-8"},
+		{"080442957X", "0-8044-2957-X"},
+		{"978-0-7432-7356-5", "978-0-7432-7356-5"},
+		{"9788845292613", "978-88-452-9261-3"},
+		{"9789021400723", "978-90-214-0072-3"},
+		{"9788420412146", "978-84-204-1214-6"},
+		{"9788202038151", "978-82-02-03815-1"},
+		{"9788937000843", "978-89-370-0084-3"},
+		{"9788770542517", "978-87-7054-251-7"},
+		{"9789460581236", "978-94-6058-123-6"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			t.Parallel()
+			got, err := isbn.Hyphenate(tt.input)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	t.Run("invalid", func(t *testing.T) {
+		t.Parallel()
+		_, err := isbn.Hyphenate("9780743273566")
+		assert.Equal(t, bookid.EINVALID, bookid.ErrorCode(err))
+	})
+
+	t.Run("undefined range", func(t *testing.T) {
+		t.Parallel()
+		// 979-0 is reserved for printed music and has no ISBN groups.
+		_, err := isbn.Hyphenate("9790000000001")
+		assert.Equal(t, bookid.ENOTFOUND, bookid.ErrorCode(err))
+	})
+}
+
+func TestFormat(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "978-0-7432-7356-5", isbn.Format("9780743273565"))
+	assert.Equal(t, "9790000000001", isbn.Format("9790000000001"), "undefined ranges are kept as is")
+	assert.Equal(t, "", isbn.Format(""))
+}
+
+func TestDefaultRanges(t *testing.T) {
+	t.Parallel()
+
+	a, err := isbn.DefaultRanges()
+	require.NoError(t, err)
+	b, err := isbn.DefaultRanges()
+	require.NoError(t, err)
+	assert.Same(t, a, b, "the range message is parsed once")
+}
+
+func TestParseRanges(t *testing.T) {
+	t.Parallel()
+
+	_, err := isbn.ParseRanges(strings.NewReader(`<ISBNRangeMessage><EAN.UCCPrefixes><EAN.UCC><Prefix>978</Prefix><Rules><Rule><Range>bad</Range><Length>1</Length></Rule></Rules></EAN.UCC></EAN.UCCPrefixes></ISBNRangeMessage>`))
+	require.Error(t, err)
+}
@@ -0,0 +1,368 @@
+// Package isbndb implements the BookFinder interface on top of the ISBNdb
+// REST API. ISBNdb requires an API key and has better coverage of recent
+// trade editions than the free providers.
+package isbndb
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/binding"
+	"github.com/fwojciec/bookid/description"
+	"github.com/fwojciec/bookid/dimension"
+	"github.com/fwojciec/bookid/internal/httpclient"
+	"github.com/fwojciec/bookid/isbn"
+	bookidquery "github.com/fwojciec/bookid/query"
+	"github.com/fwojciec/bookid/scoring"
+)
+
+// ProviderName identifies results produced by this package.
+const ProviderName = "isbndb"
+
+// DefaultBaseURL is the root of the ISBNdb API.
+const DefaultBaseURL = "https://api2.isbndb.com"
+
+// Page sizes for the books search. The API rejects more than 1000.
+const (
+	defaultMaxResults = 10
+	maxMaxResults     = 1000
+)
+
+// Client implements the BookFinder interface for the ISBNdb API.
+type Client struct {
+	httpClient *http.Client
+	baseURL    string
+	apiKey     string
+
+	// Computes the confidence of each result.
+	Scorer *scoring.Scorer
+}
+
+// Register the provider so it can be enabled by name.
+func init() {
+	bookid.RegisterFinder(ProviderName, func(config bookid.ProviderConfig) (bookid.BookFinder, error) {
+		if config.APIKey == "" {
+			return nil, bookid.Errorf(bookid.EUNAUTHORIZED, "ISBNdb API key is not configured.")
+		}
+		return NewClientWithBaseURL(config.Client(), DefaultBaseURL, config.APIKey), nil
+	})
+}
+
+// NewClient creates a new ISBNdb API client authenticating with apiKey.
+func NewClient(apiKey string) *Client {
+	return NewClientWithBaseURL(http.DefaultClient, DefaultBaseURL, apiKey)
+}
+
+// NewClientWithBaseURL creates a new client against a custom endpoint (for testing)
+func NewClientWithBaseURL(httpClient *http.Client, baseURL, apiKey string) *Client {
+	return &Client{
+		httpClient: httpClient,
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		apiKey:     apiKey,
+		Scorer:     scoring.Default(),
+	}
+}
+
+// Search performs a book search based on the provided query. ISBN queries are
+// resolved with a single book lookup, everything else through the books
+// search, which also searches the rest of a query whose ISBN is not found.
+// Print type and order are ignored as ISBNdb supports neither.
+func (c *Client) Search(ctx context.Context, query string, opts bookid.SearchOptions) ([]bookid.BookResult, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, errors.New("query cannot be empty")
+	} else if c.apiKey == "" {
+		return nil, bookid.Errorf(bookid.EUNAUTHORIZED, "ISBNdb API key is required.")
+	} else if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	var results []bookid.BookResult
+	var err error
+	parsed := bookidquery.Parse(query)
+	if parsed.ISBN() != "" {
+		results, err = c.searchISBN(ctx, parsed.ISBN())
+		if err == nil && len(results) == 0 && parsed.HasText() {
+			results, err = c.searchGeneral(ctx, query, parsed, opts)
+		}
+	} else {
+		results, err = c.searchGeneral(ctx, query, parsed, opts)
+	}
+	if err != nil {
+		return nil, err
+	}
+	for i := range results {
+		results[i].Confidence = c.Scorer.Score(scoring.Input{Query: query, Options: opts, Result: results[i]})
+	}
+	return opts.Apply(results), nil
+}
+
+// searchISBN looks up a single book by ISBN.
+func (c *Client) searchISBN(ctx context.Context, code string) ([]bookid.BookResult, error) {
+	var resp struct {
+		Book json.RawMessage `json:"book"`
+	}
+	if err := c.get(ctx, "/book/"+url.PathEscape(code), nil, &resp); err != nil {
+		if httpclient.IsStatus(err, http.StatusNotFound) {
+			return []bookid.BookResult{}, nil
+		}
+		return nil, err
+	} else if len(resp.Book) == 0 {
+		return []bookid.BookResult{}, nil
+	}
+
+	var b book
+	if err := json.Unmarshal(resp.Book, &b); err != nil {
+		return nil, fmt.Errorf("decoding isbndb book: %w", err)
+	}
+	result := b.toBookResult(bookid.SearchTypeISBN)
+	result.ProviderData = resp.Book
+	return []bookid.BookResult{result}, nil
+}
+
+// LookupOffers returns the publisher's suggested retail price of the book
+// with the given ISBN as an offer in US dollars, the currency ISBNdb reports
+// prices in. Returns no offers if ISBNdb has no price for the book.
+func (c *Client) LookupOffers(ctx context.Context, code string) ([]bookid.Offer, error) {
+	if code == "" {
+		return nil, bookid.Errorf(bookid.EINVALID, "ISBN required.")
+	} else if c.apiKey == "" {
+		return nil, bookid.Errorf(bookid.EUNAUTHORIZED, "ISBNdb API key is required.")
+	}
+
+	var resp struct {
+		Book book `json:"book"`
+	}
+	if err := c.get(ctx, "/book/"+url.PathEscape(code), nil, &resp); err != nil {
+		if httpclient.IsStatus(err, http.StatusNotFound) {
+			return []bookid.Offer{}, nil
+		}
+		return nil, err
+	}
+
+	price, err := strconv.ParseFloat(string(resp.Book.MSRP), 64)
+	if err != nil || price <= 0 {
+		return []bookid.Offer{}, nil
+	}
+	return []bookid.Offer{{Provider: ProviderName, Country: "US", Currency: "USD", ListPrice: price}}, nil
+}
+
+// searchGeneral performs a free-text search over books. ISBNdb pages by page
+// number, so StartIndex is rounded down to the start of its page.
+func (c *Client) searchGeneral(ctx context.Context, query string, q bookid.ParsedQuery, opts bookid.SearchOptions) ([]bookid.BookResult, error) {
+	size := pageSize(opts.MaxResults)
+	params := url.Values{
+		"page":     {strconv.Itoa(opts.StartIndex/size + 1)},
+		"pageSize": {strconv.Itoa(size)},
+	}
+	if lang := cmp.Or(opts.Language, q.Language); lang != "" {
+		params.Set("language", lang)
+	}
+	if q.Year != 0 {
+		params.Set("year", strconv.Itoa(q.Year))
+	}
+	text, column := formatQuery(q)
+	if text == "" {
+		text = query // only identifiers ISBNdb cannot search
+	} else if column != "" {
+		params.Set("column", column)
+	}
+
+	var resp struct {
+		Books []json.RawMessage `json:"books"`
+	}
+	if err := c.get(ctx, "/books/"+url.PathEscape(text), params, &resp); err != nil {
+		if httpclient.IsStatus(err, http.StatusNotFound) {
+			return []bookid.BookResult{}, nil
+		}
+		return nil, err
+	}
+
+	results := make([]bookid.BookResult, 0, len(resp.Books))
+	for _, raw := range resp.Books {
+		var b book
+		if err := json.Unmarshal(raw, &b); err != nil {
+			return nil, fmt.Errorf("decoding isbndb search result: %w", err)
+		}
+		result := b.toBookResult(bookid.SearchTypeGeneralQuery)
+		result.ProviderData = raw
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// formatQuery returns the search text for q along with the column to search
+// it in, which is empty to search all of them. A column is only used when q
+// has a single field and no free text, as the API searches one column at a
+// time.
+func formatQuery(q bookid.ParsedQuery) (text, column string) {
+	var parts []string
+	for _, field := range []struct{ column, value string }{
+		{"", q.Terms},
+		{"title", q.Title},
+		{"author", q.Author},
+		{"publisher", q.Publisher},
+	} {
+		if field.value != "" {
+			parts, column = append(parts, field.value), field.column
+		}
+	}
+	if len(parts) != 1 {
+		column = ""
+	}
+	return strings.Join(parts, " "), column
+}
+
+// pageSize returns the number of books to request for maxResults.
+func pageSize(maxResults int) int {
+	if maxResults <= 0 {
+		return defaultMaxResults
+	}
+	return min(maxResults, maxMaxResults)
+}
+
+// get performs an authenticated GET request against the API and decodes the
+// JSON response into v.
+func (c *Client) get(ctx context.Context, path string, params url.Values, v any) error {
+	u := c.baseURL + path
+	if len(params) > 0 {
+		u += "?" + params.Encode()
+	}
+	return httpclient.GetJSON(ctx, c.httpClient, "ISBNdb", u, http.Header{"Authorization": {c.apiKey}}, v)
+}
+
+// book is a single book of an ISBNdb response.
+type book struct {
+	Title         string   `json:"title"`
+	TitleLong     string   `json:"title_long"`
+	ISBN          string   `json:"isbn"`
+	ISBN13        string   `json:"isbn13"`
+	ISBN10        string   `json:"isbn10"`
+	Authors       []string `json:"authors"`
+	Publisher     string   `json:"publisher"`
+	Language      string   `json:"language"`
+	DatePublished string   `json:"date_published"`
+	Edition       string   `json:"edition"`
+	Binding       string   `json:"binding"`
+	Pages         int      `json:"pages"`
+	Dimensions    string   `json:"dimensions"`
+	MSRP          number   `json:"msrp"`
+	Image         string   `json:"image"`
+	Synopsis      string   `json:"synopsis"` // May contain HTML
+	Overview      string   `json:"overview"` // May contain HTML
+}
+
+// toBookResult converts a book to our BookResult. Details without a
+// dedicated BookResult field are kept in Metadata.
+func (b *book) toBookResult(searchType bookid.SearchType) bookid.BookResult {
+	result := bookid.BookResult{
+		Title:         b.Title,
+		Authors:       b.Authors,
+		Publisher:     b.Publisher,
+		PublishedYear: extractYear(b.DatePublished),
+		Language:      strings.ReplaceAll(b.Language, "_", "-"),
+		Binding:       binding.Parse(b.Binding),
+		PageCount:     b.Pages,
+		Dimensions:    parseDimensions(b.Dimensions),
+		ThumbnailURL:  httpclient.EnsureHTTPS(b.Image),
+		Description:   description.Clean(cmp.Or(b.Synopsis, b.Overview)),
+		Provider:      ProviderName,
+		SearchType:    searchType,
+	}
+	if result.Title == "" {
+		result.Title = b.TitleLong
+	}
+	if result.Authors == nil {
+		result.Authors = []string{}
+	}
+
+	// ISBNdb reports the ISBN-10 in "isbn" on older responses.
+	for _, code := range []string{b.ISBN13, b.ISBN10, b.ISBN} {
+		code = isbn.Normalize(code)
+		switch {
+		case result.ISBN13 == "" && isbn.Valid13(code):
+			result.ISBN13 = code
+		case result.ISBN10 == "" && isbn.Valid10(code):
+			result.ISBN10 = code
+		}
+	}
+
+	// Bindings without a counterpart, such as "Spiral-bound", are kept as
+	// given.
+	metadata := map[string]string{
+		"edition": b.Edition,
+		"msrp":    string(b.MSRP),
+	}
+	if result.Binding == "" {
+		metadata["binding"] = b.Binding
+	}
+	for k, v := range metadata {
+		if v == "" || (k == "msrp" && v == "0") {
+			delete(metadata, k)
+		}
+	}
+	if len(metadata) > 0 {
+		result.Metadata = metadata
+	}
+	return result
+}
+
+// parseDimensions parses dimensions such as "Height: 8.25 Inches, Length:
+// 5.5 Inches, Weight: 0.4 Pounds, Width: 0.5 Inches". ISBNdb follows Amazon,
+// where the length is the width of the cover and the width the thickness of
+// the book.
+func parseDimensions(s string) bookid.Dimensions {
+	var d bookid.Dimensions
+	for _, part := range strings.Split(s, ",") {
+		name, value, ok := strings.Cut(part, ":")
+		if !ok {
+			continue
+		}
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "height":
+			d.Height = dimension.Length(value)
+		case "length":
+			d.Width = dimension.Length(value)
+		case "width":
+			d.Thickness = dimension.Length(value)
+		case "weight":
+			d.Weight = dimension.Weight(value)
+		}
+	}
+	return d
+}
+
+// number is a JSON number that ISBNdb sometimes sends as a string.
+type number string
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (n *number) UnmarshalJSON(data []byte) error {
+	data = bytes.Trim(data, `"`)
+	if string(data) == "null" {
+		*n = ""
+		return nil
+	}
+	*n = number(data)
+	return nil
+}
+
+// extractYear extracts the year from dates such as "2004" or "2004-09-30".
+func extractYear(date string) int {
+	if len(date) < 4 {
+		return 0
+	}
+	year, err := strconv.Atoi(date[:4])
+	if err != nil || year < 1000 || year > 2999 {
+		return 0
+	}
+	return year
+}
@@ -0,0 +1,215 @@
+package isbndb_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"testing"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/internal/httptestutil"
+	"github.com/fwojciec/bookid/isbndb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newClient returns a client replaying the given synthetic fixture, written
+// by hand after the API documentation rather than recorded. The URL of the
+// last request is stored in lastURL, if set.
+func newClient(t *testing.T, fixture string, lastURL *string) *isbndb.Client {
+	t.Helper()
+	httpClient := httptestutil.Replay(t, filepath.Join("testdata", "synthetic", fixture))
+	if lastURL != nil {
+		httpClient = httptestutil.LastURL(httpClient, lastURL)
+	}
+	return isbndb.NewClientWithBaseURL(httpClient, isbndb.DefaultBaseURL, "KEY")
+}
+
+func TestClient_Search(t *testing.T) {
+	t.Parallel()
+
+	t.Run("isbn", func(t *testing.T) {
+		t.Parallel()
+		var lastURL string
+		client := newClient(t, "book_9780743273565.json", &lastURL)
+
+		results, err := client.Search(context.Background(), "978-0-7432-7356-5", bookid.SearchOptions{IncludeRaw: true})
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		assert.Equal(t, isbndb.DefaultBaseURL+"/book/9780743273565", lastURL)
+
+		r := results[0]
+		assert.Equal(t, "The Great Gatsby", r.Title)
+		assert.Equal(t, []string{"F. Scott Fitzgerald"}, r.Authors)
+		assert.Equal(t, "0743273567", r.ISBN10)
+		assert.Equal(t, "9780743273565", r.ISBN13)
+		assert.Equal(t, "Scribner", r.Publisher)
+		assert.Equal(t, 2004, r.PublishedYear)
+		assert.Equal(t, "en-US", r.Language)
+		assert.Equal(t, bookid.BindingPaperback, r.Binding)
+		assert.Equal(t, 180, r.PageCount)
+		assert.Equal(t, bookid.Dimensions{Height: 209.6, Width: 139.7, Thickness: 12.7, Weight: 181.4}, r.Dimensions)
+		assert.Equal(t, "https://images.isbndb.com/covers/35/65/9780743273565.jpg", r.ThumbnailURL)
+		assert.Equal(t, isbndb.ProviderName, r.Provider)
+		assert.Equal(t, bookid.SearchTypeISBN, r.SearchType)
+		assert.InDelta(t, 0.95, r.Confidence, 0.01)
+		assert.NotEmpty(t, r.ProviderData)
+		assert.Equal(t, map[string]string{
+			"edition": "Reprint",
+			"msrp":    "17.00",
+		}, r.Metadata)
+	})
+
+	t.Run("general", func(t *testing.T) {
+		t.Parallel()
+		var lastURL string
+		client := newClient(t, "search_gatsby.json", &lastURL)
+
+		results, err := client.Search(context.Background(), "the great gatsby", bookid.SearchOptions{
+			MaxResults: 5,
+			StartIndex: 10,
+			Language:   "en",
+		})
+		require.NoError(t, err)
+		require.Len(t, results, 2)
+		u, err := url.Parse(lastURL)
+		require.NoError(t, err)
+		assert.Equal(t, "/books/the great gatsby", u.Path)
+		assert.Equal(t, "3", u.Query().Get("page"))
+		assert.Equal(t, "5", u.Query().Get("pageSize"))
+		assert.Equal(t, "en", u.Query().Get("language"))
+
+		r := results[0]
+		assert.Equal(t, "9780743273565", r.ISBN13)
+		assert.Equal(t, "0743273567", r.ISBN10)
+		assert.Equal(t, "17", r.Metadata["msrp"])
+		assert.Equal(t, bookid.SearchTypeGeneralQuery, r.SearchType)
+		assert.Nil(t, r.ProviderData, "raw data is only kept when requested")
+
+		assert.Empty(t, results[1].Authors)
+		assert.Equal(t, bookid.BindingPaperback, results[1].Binding)
+		assert.Nil(t, results[1].Metadata, "recognized bindings are not kept as metadata")
+		assert.Less(t, results[1].Confidence, r.Confidence)
+	})
+
+	t.Run("fields", func(t *testing.T) {
+		t.Parallel()
+		var lastURL string
+		client := newClient(t, "search_fitzgerald_2004.json", &lastURL)
+
+		_, err := client.Search(context.Background(), `author:"F. Scott Fitzgerald" year:2004`, bookid.SearchOptions{})
+		require.NoError(t, err)
+		u, err := url.Parse(lastURL)
+		require.NoError(t, err)
+		assert.Equal(t, "/books/F. Scott Fitzgerald", u.Path)
+		assert.Equal(t, "author", u.Query().Get("column"))
+		assert.Equal(t, "2004", u.Query().Get("year"))
+	})
+
+	t.Run("not_found", func(t *testing.T) {
+		t.Parallel()
+		client := newClient(t, "book_9780000000002.json", nil)
+
+		results, err := client.Search(context.Background(), "9780000000002", bookid.SearchOptions{})
+		require.NoError(t, err)
+		assert.Empty(t, results)
+	})
+
+	t.Run("api_key", func(t *testing.T) {
+		t.Parallel()
+		var auth string
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			auth = r.Header.Get("Authorization")
+			http.NotFound(w, r)
+		}))
+		t.Cleanup(srv.Close)
+		client := isbndb.NewClientWithBaseURL(srv.Client(), srv.URL, "KEY")
+
+		_, err := client.Search(context.Background(), "9780743273565", bookid.SearchOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, "KEY", auth)
+	})
+}
+
+func TestClient_Search_Errors(t *testing.T) {
+	t.Parallel()
+
+	t.Run("empty_query", func(t *testing.T) {
+		t.Parallel()
+		_, err := isbndb.NewClient("KEY").Search(context.Background(), "  ", bookid.SearchOptions{})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "query cannot be empty")
+	})
+
+	t.Run("missing_key", func(t *testing.T) {
+		t.Parallel()
+		_, err := isbndb.NewClient("").Search(context.Background(), "dune", bookid.SearchOptions{})
+		assert.Equal(t, bookid.EUNAUTHORIZED, bookid.ErrorCode(err))
+	})
+
+	tests := []struct {
+		status int
+		code   string
+	}{
+		{http.StatusUnauthorized, bookid.EUNAUTHORIZED},
+		{http.StatusForbidden, bookid.EUNAUTHORIZED},
+		{http.StatusTooManyRequests, bookid.ERATELIMIT},
+		{http.StatusServiceUnavailable, bookid.EUNAVAILABLE},
+	}
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			t.Parallel()
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(tt.status)
+			}))
+			t.Cleanup(srv.Close)
+			client := isbndb.NewClientWithBaseURL(srv.Client(), srv.URL, "KEY")
+
+			_, err := client.Search(context.Background(), "dune", bookid.SearchOptions{})
+			assert.Equal(t, tt.code, bookid.ErrorCode(err))
+		})
+	}
+}
+
+func TestClient_LookupOffers(t *testing.T) {
+	t.Parallel()
+
+	t.Run("msrp", func(t *testing.T) {
+		t.Parallel()
+		client := newClient(t, "book_9780743273565.json", nil)
+
+		offers, err := client.LookupOffers(context.Background(), "9780743273565")
+		require.NoError(t, err)
+		require.Len(t, offers, 1)
+		assert.Equal(t, bookid.Offer{Provider: isbndb.ProviderName, Country: "US", Currency: "USD", ListPrice: 17}, offers[0])
+	})
+
+	t.Run("not_found", func(t *testing.T) {
+		t.Parallel()
+		client := newClient(t, "book_9780000000002.json", nil)
+
+		offers, err := client.LookupOffers(context.Background(), "9780000000002")
+		require.NoError(t, err)
+		assert.Empty(t, offers)
+	})
+
+	t.Run("missing_key", func(t *testing.T) {
+		t.Parallel()
+		_, err := isbndb.NewClient("").LookupOffers(context.Background(), "9780743273565")
+		assert.Equal(t, bookid.EUNAUTHORIZED, bookid.ErrorCode(err))
+	})
+}
+
+func TestRegisterFinder(t *testing.T) {
+	t.Parallel()
+
+	finder, err := bookid.NewFinder(isbndb.ProviderName, bookid.ProviderConfig{APIKey: "key"})
+	require.NoError(t, err)
+	assert.IsType(t, &isbndb.Client{}, finder)
+
+	// Without an API key the provider is not set up.
+	_, err = bookid.NewFinder(isbndb.ProviderName, bookid.ProviderConfig{})
+	assert.Equal(t, bookid.EUNAUTHORIZED, bookid.ErrorCode(err))
+}
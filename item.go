@@ -0,0 +1,120 @@
+package bookid
+
+import (
+	"context"
+	"time"
+)
+
+// Item represents a physical copy of a publication, for catalogs that own
+// more than one copy of the same edition.
+type Item struct {
+	ID            int64         `json:"id"`
+	PublicationID int64         `json:"publication_id"`
+	Barcode       string        `json:"barcode,omitempty"` // Label on the copy; unique when set
+	Condition     ItemCondition `json:"condition,omitempty"`
+	Location      string        `json:"location,omitempty"` // Where the copy is kept, e.g. a shelf code
+	LoanStatus    LoanStatus    `json:"loan_status"`
+	CreatedAt     time.Time     `json:"created_at"`
+	UpdatedAt     time.Time     `json:"updated_at"`
+}
+
+// Validate returns an error if the item contains invalid fields.
+func (i *Item) Validate() error {
+	if i.PublicationID == 0 {
+		return Errorf(EINVALID, "Item publication required.")
+	} else if !i.Condition.Valid() {
+		return Errorf(EINVALID, "Invalid item condition %q.", i.Condition)
+	} else if !i.LoanStatus.Valid() {
+		return Errorf(EINVALID, "Invalid loan status %q.", i.LoanStatus)
+	}
+	return nil
+}
+
+// ItemCondition represents the physical condition of an item, using the
+// grades common in the book trade.
+type ItemCondition string
+
+// Item conditions. The zero value means the condition is not recorded.
+const (
+	ItemConditionNew      ItemCondition = "new"
+	ItemConditionFine     ItemCondition = "fine"
+	ItemConditionVeryGood ItemCondition = "very_good"
+	ItemConditionGood     ItemCondition = "good"
+	ItemConditionFair     ItemCondition = "fair"
+	ItemConditionPoor     ItemCondition = "poor"
+)
+
+// Valid returns true if c is empty or a known condition.
+func (c ItemCondition) Valid() bool {
+	switch c {
+	case "", ItemConditionNew, ItemConditionFine, ItemConditionVeryGood, ItemConditionGood, ItemConditionFair, ItemConditionPoor:
+		return true
+	default:
+		return false
+	}
+}
+
+// LoanStatus represents whether an item is on the shelf.
+type LoanStatus string
+
+// Loan statuses.
+const (
+	LoanStatusAvailable LoanStatus = "available"
+	LoanStatusOnLoan    LoanStatus = "on_loan"
+	LoanStatusMissing   LoanStatus = "missing"
+)
+
+// Valid returns true if s is a known loan status.
+func (s LoanStatus) Valid() bool {
+	switch s {
+	case LoanStatusAvailable, LoanStatusOnLoan, LoanStatusMissing:
+		return true
+	default:
+		return false
+	}
+}
+
+// ItemService represents a service for managing the physical copies of
+// publications.
+type ItemService interface {
+	// FindItemByID retrieves a single item by ID.
+	// Returns ENOTFOUND if the item does not exist.
+	FindItemByID(ctx context.Context, id int64) (*Item, error)
+
+	// FindItems retrieves a list of items matching the filter along with the
+	// total number of matches, ignoring Offset and Limit.
+	FindItems(ctx context.Context, filter ItemFilter) ([]*Item, int, error)
+
+	// CreateItem creates a new item. An empty loan status is set to
+	// available. Returns ENOTFOUND if the publication does not exist and
+	// ECONFLICT if another item has the same barcode.
+	CreateItem(ctx context.Context, item *Item) error
+
+	// UpdateItem updates an existing item. Returns the updated item.
+	// Returns ENOTFOUND if the item does not exist.
+	UpdateItem(ctx context.Context, id int64, upd ItemUpdate) (*Item, error)
+
+	// DeleteItem permanently removes an item.
+	// Returns ENOTFOUND if the item does not exist.
+	DeleteItem(ctx context.Context, id int64) error
+}
+
+// ItemFilter represents a filter used by FindItems.
+type ItemFilter struct {
+	ID            *int64
+	PublicationID *int64
+	Barcode       *string
+	LoanStatus    *LoanStatus
+
+	// Restrict to subset of results.
+	Offset int
+	Limit  int
+}
+
+// ItemUpdate represents a set of fields to be updated via UpdateItem.
+type ItemUpdate struct {
+	Barcode    *string
+	Condition  *ItemCondition
+	Location   *string
+	LoanStatus *LoanStatus
+}
@@ -0,0 +1,125 @@
+package bookid
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// JobKind represents the kind of work a job does.
+type JobKind string
+
+// Job kinds.
+const (
+	JobKindImport  JobKind = "import"  // Import a file into the catalog
+	JobKindRefresh JobKind = "refresh" // Refresh stale publications
+	JobKindCovers  JobKind = "covers"  // Backfill cover images
+)
+
+// JobState represents the state of a job in the queue.
+type JobState string
+
+// Job states. Queued and running jobs are pending; the others are finished.
+const (
+	JobStateQueued   JobState = "queued"
+	JobStateRunning  JobState = "running"
+	JobStateDone     JobState = "done"
+	JobStateFailed   JobState = "failed"
+	JobStateCanceled JobState = "canceled"
+)
+
+// Valid returns true if s is a known job state.
+func (s JobState) Valid() bool {
+	switch s {
+	case JobStateQueued, JobStateRunning, JobStateDone, JobStateFailed, JobStateCanceled:
+		return true
+	default:
+		return false
+	}
+}
+
+// Finished returns true if a job in state s will not run again.
+func (s JobState) Finished() bool {
+	return s == JobStateDone || s == JobStateFailed || s == JobStateCanceled
+}
+
+// Job represents a long-running task, such as importing a file, queued to run
+// in the background. Its progress is stored so that a job interrupted by a
+// restart resumes where it left off.
+type Job struct {
+	ID         int64           `json:"id"`
+	Kind       JobKind         `json:"kind"`
+	Params     json.RawMessage `json:"params,omitempty"` // Arguments of the job, by kind
+	State      JobState        `json:"state"`
+	Done       int             `json:"done"`             // Units of work completed
+	Total      int             `json:"total,omitempty"`  // Zero if not known
+	Result     json.RawMessage `json:"result,omitempty"` // Report of a finished job, by kind
+	Error      string          `json:"error,omitempty"`  // Set if the job failed
+	CreatedAt  time.Time       `json:"created_at"`
+	UpdatedAt  time.Time       `json:"updated_at"`
+	StartedAt  time.Time       `json:"started_at,omitzero"`
+	FinishedAt time.Time       `json:"finished_at,omitzero"`
+}
+
+// Validate returns an error if the job contains invalid fields.
+func (j *Job) Validate() error {
+	if j.Kind == "" {
+		return Errorf(EINVALID, "Job kind required.")
+	} else if !j.State.Valid() {
+		return Errorf(EINVALID, "Invalid job state %q.", j.State)
+	} else if j.Done < 0 || j.Total < 0 {
+		return Errorf(EINVALID, "Job progress must not be negative.")
+	}
+	return nil
+}
+
+// JobService represents a service for managing the queue of jobs.
+type JobService interface {
+	// FindJobByID retrieves a single job by ID.
+	// Returns ENOTFOUND if the job does not exist.
+	FindJobByID(ctx context.Context, id int64) (*Job, error)
+
+	// FindJobs retrieves a list of jobs matching the filter along with the
+	// total number of matches, ignoring Offset and Limit.
+	FindJobs(ctx context.Context, filter JobFilter) ([]*Job, int, error)
+
+	// CreateJob adds a job to the end of the queue.
+	CreateJob(ctx context.Context, job *Job) error
+
+	// ClaimJob marks the next pending job as running and returns it. Jobs
+	// left running by an interrupted process come first, so that they resume
+	// before new ones start; a queue should therefore have a single runner.
+	// Returns ENOTFOUND if no job is pending.
+	ClaimJob(ctx context.Context) (*Job, error)
+
+	// UpdateJob records the progress or outcome of a job. Setting a finished
+	// state records the time the job finished. Returns ENOTFOUND if the job
+	// does not exist and ECONFLICT if it is already finished.
+	UpdateJob(ctx context.Context, id int64, upd JobUpdate) (*Job, error)
+
+	// CancelJob cancels a pending job. A running job stops the next time it
+	// records its progress. Returns ENOTFOUND if the job does not exist and
+	// ECONFLICT if it is already finished.
+	CancelJob(ctx context.Context, id int64) (*Job, error)
+}
+
+// JobFilter represents a filter used by FindJobs. Jobs are returned newest
+// first.
+type JobFilter struct {
+	ID    *int64
+	Kind  *JobKind
+	State *JobState
+
+	// Restrict to subset of results.
+	Offset int
+	Limit  int
+}
+
+// JobUpdate represents a set of fields to be updated via UpdateJob.
+type JobUpdate struct {
+	State  *JobState
+	Done   *int
+	Total  *int
+	Result json.RawMessage
+	Error  *string
+}
@@ -0,0 +1,146 @@
+// Package jobs runs the queued jobs of a JobService. Each kind of job is run
+// by a Handler, which records its progress as it goes; a job interrupted by a
+// restart is claimed again by the next run and resumes from its recorded
+// progress.
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/fwojciec/bookid"
+)
+
+// ErrCanceled is returned by Progress when the job was canceled while it was
+// running. Handlers should stop and return it.
+var ErrCanceled = errors.New("jobs: job canceled")
+
+// Progress records that done out of total units of a running job are
+// complete. Total is zero if not known. Returns ErrCanceled if the job has
+// been canceled.
+type Progress func(done, total int) error
+
+// Handler runs the jobs of one kind.
+type Handler interface {
+	// RunJob does the work of job and returns its report. A resumed job has
+	// its recorded progress in job.Done, so the units already done can be
+	// passed over.
+	RunJob(ctx context.Context, job *bookid.Job, progress Progress) (result any, err error)
+}
+
+// HandlerFunc is an adapter to allow the use of ordinary functions as
+// handlers.
+type HandlerFunc func(ctx context.Context, job *bookid.Job, progress Progress) (any, error)
+
+// RunJob calls f(ctx, job, progress).
+func (f HandlerFunc) RunJob(ctx context.Context, job *bookid.Job, progress Progress) (any, error) {
+	return f(ctx, job, progress)
+}
+
+// Runner claims pending jobs and runs them with the handler of their kind.
+type Runner struct {
+	JobService bookid.JobService
+
+	handlers map[bookid.JobKind]Handler
+
+	// Receives debug logs of each job's outcome. Defaults to discarding
+	// them.
+	Logger *slog.Logger
+}
+
+// NewRunner returns a Runner for the jobs of s.
+func NewRunner(s bookid.JobService) *Runner {
+	return &Runner{
+		JobService: s,
+		handlers:   make(map[bookid.JobKind]Handler),
+		Logger:     slog.New(slog.DiscardHandler),
+	}
+}
+
+// Handle registers the handler for jobs of a kind.
+func (r *Runner) Handle(kind bookid.JobKind, h Handler) {
+	r.handlers[kind] = h
+}
+
+// RunPending runs jobs until none is pending and returns them in the order
+// they ran, in their final state. If ctx is canceled, the job being run is
+// left running so that the next run resumes it, and ctx's error is returned.
+func (r *Runner) RunPending(ctx context.Context) ([]*bookid.Job, error) {
+	jobs := make([]*bookid.Job, 0)
+	for {
+		job, err := r.JobService.ClaimJob(ctx)
+		if bookid.ErrorCode(err) == bookid.ENOTFOUND {
+			return jobs, nil
+		} else if err != nil {
+			return jobs, err
+		}
+
+		if job, err = r.run(ctx, job); err != nil {
+			return jobs, err
+		}
+		jobs = append(jobs, job)
+	}
+}
+
+// run runs a claimed job and records its outcome. Returns the finished job.
+func (r *Runner) run(ctx context.Context, job *bookid.Job) (*bookid.Job, error) {
+	h, ok := r.handlers[job.Kind]
+	if !ok {
+		return r.finish(ctx, job, nil, bookid.Errorf(bookid.EINVALID, "No handler for %s jobs.", job.Kind))
+	}
+
+	// Stop the handler's work as soon as a cancellation is noticed.
+	jobCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	progress := func(done, total int) error {
+		if _, err := r.JobService.UpdateJob(ctx, job.ID, bookid.JobUpdate{Done: &done, Total: &total}); bookid.ErrorCode(err) == bookid.ECONFLICT {
+			cancel()
+			return ErrCanceled
+		} else if err != nil {
+			return err
+		}
+		job.Done, job.Total = done, total
+		return nil
+	}
+
+	result, err := h.RunJob(jobCtx, job, progress)
+	if ctx.Err() != nil {
+		return job, ctx.Err()
+	} else if errors.Is(err, ErrCanceled) {
+		r.Logger.DebugContext(ctx, "job canceled", "id", job.ID, "kind", job.Kind, "done", job.Done)
+		return r.JobService.FindJobByID(ctx, job.ID)
+	}
+	return r.finish(ctx, job, result, err)
+}
+
+// finish records the outcome of a job: done with its result, or failed with
+// the message of err.
+func (r *Runner) finish(ctx context.Context, job *bookid.Job, result any, err error) (*bookid.Job, error) {
+	state, upd := bookid.JobStateDone, bookid.JobUpdate{}
+	if err != nil {
+		state = bookid.JobStateFailed
+		msg := bookid.ErrorMessage(err)
+		if bookid.ErrorCode(err) == bookid.EINTERNAL {
+			msg = err.Error()
+		}
+		upd.Error = &msg
+	} else if result != nil {
+		buf, err := json.Marshal(result)
+		if err != nil {
+			return job, fmt.Errorf("encoding result of job %d: %w", job.ID, err)
+		}
+		upd.Result = buf
+	}
+	upd.State = &state
+
+	r.Logger.DebugContext(ctx, "job finished", "id", job.ID, "kind", job.Kind, "state", state, "error", upd.Error)
+	if job, err := r.JobService.UpdateJob(ctx, job.ID, upd); bookid.ErrorCode(err) != bookid.ECONFLICT {
+		return job, err
+	}
+
+	// Canceled after the handler's last progress report.
+	return r.JobService.FindJobByID(ctx, job.ID)
+}
@@ -0,0 +1,135 @@
+package jobs_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/jobs"
+	"github.com/fwojciec/bookid/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// MustOpenJobService returns a job service backed by an in-memory database.
+func MustOpenJobService(tb testing.TB) *sqlite.JobService {
+	tb.Helper()
+	db := sqlite.NewDB(":memory:")
+	require.NoError(tb, db.Open())
+	tb.Cleanup(func() { _ = db.Close() })
+	return sqlite.NewJobService(db)
+}
+
+// enqueue adds a job of a kind to the queue of s.
+func enqueue(tb testing.TB, s bookid.JobService, kind bookid.JobKind) *bookid.Job {
+	tb.Helper()
+	job := &bookid.Job{Kind: kind}
+	require.NoError(tb, s.CreateJob(context.Background(), job))
+	return job
+}
+
+func TestRunner_RunPending(t *testing.T) {
+	t.Parallel()
+
+	t.Run("OK", func(t *testing.T) {
+		t.Parallel()
+		s := MustOpenJobService(t)
+		enqueue(t, s, bookid.JobKindCovers)
+		enqueue(t, s, bookid.JobKindRefresh)
+		enqueue(t, s, "unknown")
+
+		r := jobs.NewRunner(s)
+		r.Handle(bookid.JobKindCovers, jobs.HandlerFunc(func(_ context.Context, _ *bookid.Job, progress jobs.Progress) (any, error) {
+			for i := range 3 {
+				if err := progress(i+1, 3); err != nil {
+					return nil, err
+				}
+			}
+			return map[string]int{"fetched": 3}, nil
+		}))
+		r.Handle(bookid.JobKindRefresh, jobs.HandlerFunc(func(context.Context, *bookid.Job, jobs.Progress) (any, error) {
+			return nil, bookid.Errorf(bookid.EUNAVAILABLE, "Provider unavailable.")
+		}))
+
+		ran, err := r.RunPending(context.Background())
+		require.NoError(t, err)
+		require.Len(t, ran, 3)
+
+		assert.Equal(t, bookid.JobStateDone, ran[0].State)
+		assert.Equal(t, 3, ran[0].Done)
+		assert.Equal(t, 3, ran[0].Total)
+		assert.JSONEq(t, `{"fetched": 3}`, string(ran[0].Result))
+		assert.False(t, ran[0].FinishedAt.IsZero())
+
+		assert.Equal(t, bookid.JobStateFailed, ran[1].State)
+		assert.Equal(t, "Provider unavailable.", ran[1].Error)
+
+		assert.Equal(t, bookid.JobStateFailed, ran[2].State)
+		assert.Equal(t, "No handler for unknown jobs.", ran[2].Error)
+	})
+
+	t.Run("Canceled", func(t *testing.T) {
+		t.Parallel()
+		s := MustOpenJobService(t)
+		job := enqueue(t, s, bookid.JobKindImport)
+
+		r := jobs.NewRunner(s)
+		r.Handle(bookid.JobKindImport, jobs.HandlerFunc(func(ctx context.Context, job *bookid.Job, progress jobs.Progress) (any, error) {
+			if err := progress(1, 0); err != nil {
+				return nil, err
+			}
+			// Canceled from elsewhere, e.g. "bookid jobs cancel".
+			if _, err := s.CancelJob(ctx, job.ID); err != nil {
+				return nil, err
+			}
+			err := progress(2, 0)
+			assert.ErrorIs(t, err, jobs.ErrCanceled)
+			assert.Error(t, ctx.Err())
+			return nil, err
+		}))
+
+		ran, err := r.RunPending(context.Background())
+		require.NoError(t, err)
+		require.Len(t, ran, 1)
+		assert.Equal(t, job.ID, ran[0].ID)
+		assert.Equal(t, bookid.JobStateCanceled, ran[0].State)
+		assert.Equal(t, 1, ran[0].Done)
+	})
+
+	t.Run("ResumesInterrupted", func(t *testing.T) {
+		t.Parallel()
+		s := MustOpenJobService(t)
+		enqueue(t, s, bookid.JobKindImport)
+		ctx, cancel := context.WithCancel(context.Background())
+
+		var resumedAt []int
+		r := jobs.NewRunner(s)
+		r.Handle(bookid.JobKindImport, jobs.HandlerFunc(func(ctx context.Context, job *bookid.Job, progress jobs.Progress) (any, error) {
+			resumedAt = append(resumedAt, job.Done)
+			for i := job.Done; i < 4; i++ {
+				if err := progress(i+1, 4); err != nil {
+					return nil, err
+				}
+				// The first run is interrupted halfway.
+				if i == 1 && len(resumedAt) == 1 {
+					cancel()
+					return nil, ctx.Err()
+				}
+			}
+			return nil, nil
+		}))
+
+		_, err := r.RunPending(ctx)
+		require.ErrorIs(t, err, context.Canceled)
+		pending, _, err := s.FindJobs(context.Background(), bookid.JobFilter{})
+		require.NoError(t, err)
+		assert.Equal(t, bookid.JobStateRunning, pending[0].State)
+
+		ran, err := r.RunPending(context.Background())
+		require.NoError(t, err)
+		require.Len(t, ran, 1)
+		assert.Equal(t, bookid.JobStateDone, ran[0].State)
+		assert.Equal(t, 4, ran[0].Done)
+		assert.Equal(t, []int{0, 2}, resumedAt)
+	})
+}
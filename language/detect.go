@@ -0,0 +1,133 @@
+package language
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// Detect returns the ISO 639-1 code of the language a title is most likely
+// written in, or an empty string if it cannot tell. Titles are short, so only
+// clear signals are used: the script of titles not written in Latin letters,
+// and otherwise letters and short words specific to a language, such as "ñ"
+// or "the". Ambiguous titles such as "Solaris" are left undetected.
+func Detect(title string) string {
+	if code := detectScript(title); code != "" {
+		return code
+	}
+
+	scores := make(map[string]int)
+	lower := strings.ToLower(title)
+	for _, r := range lower {
+		for _, h := range letterHints() {
+			if strings.ContainsRune(h.text, r) {
+				scores[h.code] += 2
+			}
+		}
+	}
+	words := strings.FieldsFunc(lower, func(r rune) bool { return !unicode.IsLetter(r) })
+	for _, h := range wordHints() {
+		for _, word := range strings.Fields(h.text) {
+			if slices.Contains(words, word) {
+				scores[h.code]++
+			}
+		}
+	}
+
+	// Only a clear winner counts.
+	var best string
+	bestScore, tie := 0, false
+	for code, score := range scores {
+		if score > bestScore {
+			best, bestScore, tie = code, score, false
+		} else if score == bestScore {
+			tie = true
+		}
+	}
+	if tie {
+		return ""
+	}
+	return best
+}
+
+// detectScript returns the language of a title written mostly in a script
+// used by one major language, or an empty string if it is written in Latin
+// letters or no such script.
+func detectScript(title string) string {
+	counts := make(map[string]int)
+	var latin int
+	var ukrainian bool
+	for _, r := range title {
+		switch {
+		case unicode.In(r, unicode.Hiragana, unicode.Katakana):
+			return "ja" // Kanji alone cannot be told apart from Chinese
+		case unicode.Is(unicode.Hangul, r):
+			counts["ko"]++
+		case unicode.Is(unicode.Han, r):
+			counts["zh"]++
+		case unicode.Is(unicode.Cyrillic, r):
+			counts["ru"]++
+			ukrainian = ukrainian || strings.ContainsRune("ґєії", unicode.ToLower(r))
+		case unicode.Is(unicode.Greek, r):
+			counts["el"]++
+		case unicode.Is(unicode.Arabic, r):
+			counts["ar"]++
+		case unicode.Is(unicode.Hebrew, r):
+			counts["he"]++
+		case unicode.Is(unicode.Thai, r):
+			counts["th"]++
+		case unicode.Is(unicode.Devanagari, r):
+			counts["hi"]++
+		case unicode.Is(unicode.Latin, r):
+			latin++
+		}
+	}
+
+	var best string
+	bestCount := latin
+	for code, n := range counts {
+		if n > bestCount || (n == bestCount && best != "" && code < best) {
+			best, bestCount = code, n
+		}
+	}
+	if best == "ru" && ukrainian {
+		return "uk" // Cyrillic with letters only Ukrainian uses
+	}
+	return best
+}
+
+// hint is text suggesting that a title is in the language with the given
+// ISO 639-1 code.
+type hint struct {
+	code, text string
+}
+
+// letterHints returns letters used by a single language of those we detect.
+func letterHints() []hint {
+	return []hint{
+		{"pl", "ąćęłńśźż"},
+		{"cs", "řůě"},
+		{"de", "ß"},
+		{"es", "ñ¿¡"},
+		{"fr", "œèêëîç"},
+		{"pt", "ãõ"},
+		{"sv", "å"},
+	}
+}
+
+// wordHints returns short words, mostly articles and prepositions, that are
+// common in titles in a language and rare in those of the others we detect.
+func wordHints() []hint {
+	return []hint{
+		{"en", "the of and with from"},
+		{"fr", "le les des du et une au aux"},
+		{"de", "der die das und ein eine einer vom zum im"},
+		{"es", "el los las del y con"},
+		{"it", "il lo gli della delle dei di e nel"},
+		{"pt", "os do dos das um uma em"},
+		{"pl", "i w z na się jak"},
+		{"nl", "het een van en voor"},
+		{"sv", "och ett av på till"},
+		{"cs", "v se ve ze"},
+	}
+}
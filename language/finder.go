@@ -0,0 +1,46 @@
+package language
+
+import (
+	"context"
+
+	"github.com/fwojciec/bookid"
+)
+
+// Ensure type implements interface.
+var _ bookid.BookFinder = (*Finder)(nil)
+
+// Finder wraps a BookFinder and normalizes the languages of its results to
+// BCP-47 tags, detecting them from the title when the provider omits them.
+type Finder struct {
+	finder bookid.BookFinder
+}
+
+// NewFinder returns a Finder normalizing the results of finder.
+func NewFinder(finder bookid.BookFinder) *Finder {
+	return &Finder{finder: finder}
+}
+
+// Search passes the primary subtag of the requested language, which is what
+// providers restrict by, to the wrapped finder and drops the results known
+// to be in another language, as not every provider can restrict searches.
+func (f *Finder) Search(ctx context.Context, query string, opts bookid.SearchOptions) ([]bookid.BookResult, error) {
+	want := Base(opts.Language)
+	opts.Language = want
+
+	results, err := f.finder.Search(ctx, query, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	other := make([]bookid.BookResult, 0, len(results))
+	for _, r := range results {
+		if r.Language = Normalize(r.Language); r.Language == "" {
+			r.Language = Detect(r.Title)
+		}
+		if want != "" && r.Language != "" && Base(r.Language) != want {
+			continue
+		}
+		other = append(other, r)
+	}
+	return other, nil
+}
@@ -0,0 +1,119 @@
+// Package language translates between the language codes used by providers:
+// ISO 639-1 codes (e.g. "en") reported by Google Books and the MARC 21 codes
+// (e.g. "eng") used by library catalogs such as Open Library and WorldCat.
+// Provider values are normalized to canonical BCP-47 tags (e.g. "pt-BR"),
+// and the language of titles is detected when a provider omits it.
+package language
+
+import (
+	"strings"
+
+	bcp47 "golang.org/x/text/language"
+)
+
+// Parse returns the canonical BCP-47 tag of a language code or tag in any
+// common form, e.g. "en" for "eng", "pt-BR" for "pt_br" and "zh-Hant" for
+// "ZH-hant", or of an English language name such as "French". Returns false
+// if s is not a known language or is undetermined ("und").
+func Parse(s string) (string, bool) {
+	s = strings.ReplaceAll(strings.TrimSpace(s), "_", "-")
+	if code := FromName(s); code != s {
+		return code, true
+	}
+	tag, err := bcp47.Parse(s)
+	if err != nil || tag == bcp47.Und {
+		return "", false
+	}
+	return tag.String(), true
+}
+
+// Normalize returns the canonical BCP-47 tag of a language value as returned
+// by Parse. Undetermined values are returned as an empty string and unknown
+// values unchanged.
+func Normalize(s string) string {
+	if tag, ok := Parse(s); ok {
+		return tag
+	} else if strings.EqualFold(strings.TrimSpace(s), "und") {
+		return ""
+	}
+	return strings.TrimSpace(s)
+}
+
+// Base returns the primary language subtag of a normalized tag, e.g. "en"
+// for "en-GB" or "eng", which is what providers restrict searches by.
+func Base(s string) string {
+	base, _, _ := strings.Cut(Normalize(s), "-")
+	return strings.ToLower(base)
+}
+
+// FromMARC converts a MARC 21 or ISO 639-2/T language code to its ISO 639-1
+// code. Unknown codes are returned unchanged.
+func FromMARC(code string) string {
+	for _, l := range languages() {
+		if code == l.marc || code == l.alt {
+			return l.iso
+		}
+	}
+	return code
+}
+
+// FromName converts an English language name such as "English" to its ISO
+// 639-1 code, ignoring case. Unknown names are returned unchanged.
+func FromName(name string) string {
+	for _, l := range languages() {
+		if strings.EqualFold(name, l.name) {
+			return l.iso
+		}
+	}
+	return name
+}
+
+// Name returns the English name of an ISO 639-1 language code or a tag
+// starting with one, e.g. "English" for "en" or "en-GB". Unknown codes are
+// returned unchanged.
+func Name(code string) string {
+	base, _, _ := strings.Cut(code, "-")
+	for _, l := range languages() {
+		if base == l.iso {
+			return l.name
+		}
+	}
+	return code
+}
+
+// ToMARC converts an ISO 639-1 language code, or a tag starting with one, to
+// its MARC 21 code. Unknown codes are returned unchanged.
+func ToMARC(code string) string {
+	base, _, _ := strings.Cut(code, "-")
+	for _, l := range languages() {
+		if base == l.iso {
+			return l.marc
+		}
+	}
+	return code
+}
+
+// language maps an ISO 639-1 code to its MARC 21 code, its ISO 639-2/T code
+// where it differs and its English name.
+type language struct {
+	iso, marc, alt, name string
+}
+
+// languages returns the language codes we translate.
+func languages() []language {
+	return []language{
+		{"en", "eng", "", "English"},
+		{"fr", "fre", "fra", "French"},
+		{"de", "ger", "deu", "German"},
+		{"es", "spa", "", "Spanish"},
+		{"it", "ita", "", "Italian"},
+		{"pt", "por", "", "Portuguese"},
+		{"pl", "pol", "", "Polish"},
+		{"ru", "rus", "", "Russian"},
+		{"ja", "jpn", "", "Japanese"},
+		{"zh", "chi", "zho", "Chinese"},
+		{"nl", "dut", "nld", "Dutch"},
+		{"sv", "swe", "", "Swedish"},
+		{"cs", "cze", "ces", "Czech"},
+	}
+}
@@ -0,0 +1,122 @@
+package language_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/language"
+	"github.com/fwojciec/bookid/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromMARC(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "en", language.FromMARC("eng"))
+	assert.Equal(t, "de", language.FromMARC("ger"))
+	assert.Equal(t, "de", language.FromMARC("deu"))
+	assert.Equal(t, "xxx", language.FromMARC("xxx"))
+}
+
+func TestFromName(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "en", language.FromName("english"))
+	assert.Equal(t, "pl", language.FromName("Polish"))
+	assert.Equal(t, "Klingon", language.FromName("Klingon"))
+}
+
+func TestName(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "English", language.Name("en"))
+	assert.Equal(t, "xx", language.Name("xx"))
+}
+
+func TestToMARC(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "eng", language.ToMARC("en"))
+	assert.Equal(t, "chi", language.ToMARC("zh"))
+	assert.Equal(t, "xx", language.ToMARC("xx"))
+}
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+	for input, want := range map[string]string{
+		"en":      "en",
+		"eng":     "en",
+		"fre":     "fr",
+		"EN-us":   "en-US",
+		"pt_br":   "pt-BR",
+		"zh-hant": "zh-Hant",
+		"iw":      "he",
+		"French":  "fr",
+	} {
+		got, ok := language.Parse(input)
+		assert.True(t, ok, input)
+		assert.Equal(t, want, got, input)
+	}
+	for _, input := range []string{"", "und", "xx", "Klingon"} {
+		_, ok := language.Parse(input)
+		assert.False(t, ok, input)
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "de", language.Normalize("ger"))
+	assert.Equal(t, "", language.Normalize("und"))
+	assert.Equal(t, "Klingon", language.Normalize(" Klingon "))
+}
+
+func TestBase(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "en", language.Base("en-GB"))
+	assert.Equal(t, "pt", language.Base("pt_br"))
+	assert.Equal(t, "zh", language.Base("chi"))
+	assert.Equal(t, "", language.Base(""))
+}
+
+func TestDetect(t *testing.T) {
+	t.Parallel()
+	for title, want := range map[string]string{
+		"The Lord of the Rings":                       "en",
+		"Der Herr der Ringe":                          "de",
+		"Cien años de soledad":                        "es",
+		"Le Petit Prince":                             "fr",
+		"Il nome della rosa":                          "it",
+		"Ogniem i mieczem":                            "pl",
+		"Pan Tadeusz, czyli ostatni zajazd na Litwie": "pl",
+		"Война и мир":                                 "ru",
+		"Кобзар і думи":                               "uk",
+		"ノルウェイの森":                                     "ja",
+		"红楼梦":                                         "zh",
+		"채식주의자":                                       "ko",
+		"Solaris":                                     "",
+		"":                                            "",
+	} {
+		assert.Equal(t, want, language.Detect(title), title)
+	}
+}
+
+func TestFinder_Search(t *testing.T) {
+	t.Parallel()
+
+	finder := language.NewFinder(&mock.BookFinder{
+		SearchFn: func(_ context.Context, _ string, opts bookid.SearchOptions) ([]bookid.BookResult, error) {
+			assert.Equal(t, "en", opts.Language, "providers get the primary language")
+			return []bookid.BookResult{
+				{Title: "The Great Gatsby", Language: "eng"},
+				{Title: "Der große Gatsby", Language: "ger"},
+				{Title: "The Great Gatsby: Annotated"},
+				{Title: "Gatsby"},
+			}, nil
+		},
+	})
+
+	results, err := finder.Search(context.Background(), "gatsby", bookid.SearchOptions{Language: "en-GB"})
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+	assert.Equal(t, "en", results[0].Language)
+	assert.Equal(t, "en", results[1].Language, "detected from the title")
+	assert.Equal(t, "", results[2].Language, "unknown languages are kept")
+}
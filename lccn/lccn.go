@@ -0,0 +1,74 @@
+// Package lccn validates and normalizes Library of Congress Control Numbers
+// following the Library of Congress normalization rules
+// (https://www.loc.gov/marc/lccn-namespace.html).
+package lccn
+
+import (
+	"strings"
+)
+
+// Prefix marks a query as an LCCN, e.g. "lccn:2004111282".
+const Prefix = "lccn:"
+
+// Normalize returns the normalized form of an LCCN: blanks are removed, any
+// forward slash and the characters following it are dropped and a hyphenated
+// serial number is left-padded with zeros to six digits, so "2001-1234"
+// becomes "2001001234". Prefixes are lowercased. It does not validate the
+// result.
+func Normalize(s string) string {
+	s = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(s), " ", ""))
+	s, _, _ = strings.Cut(s, "/")
+	if head, serial, ok := strings.Cut(s, "-"); ok {
+		if len(serial) < 6 && isDigits(serial) {
+			serial = strings.Repeat("0", 6-len(serial)) + serial
+		}
+		s = head + serial
+	}
+	return s
+}
+
+// Valid returns true if s is a normalized LCCN: an alphabetic prefix of up to
+// three letters followed by a two digit year and six digit serial number, or
+// a prefix of up to two letters followed by a four digit year and six digit
+// serial number. Four digit years were introduced in 2001.
+func Valid(s string) bool {
+	digits := strings.TrimLeft(s, "abcdefghijklmnopqrstuvwxyz")
+	prefix := len(s) - len(digits)
+	if !isDigits(digits) {
+		return false
+	}
+	switch len(digits) {
+	case 8:
+		return prefix <= 3
+	case 10:
+		return prefix <= 2 && digits[:2] == "20"
+	}
+	return false
+}
+
+// Parse returns the normalized LCCN in query if the whole query is an LCCN,
+// optionally marked with the "lccn:" prefix. Returns false otherwise.
+func Parse(query string) (string, bool) {
+	query = strings.TrimSpace(query)
+	if len(query) >= len(Prefix) && strings.EqualFold(query[:len(Prefix)], Prefix) {
+		query = query[len(Prefix):]
+	}
+	s := Normalize(query)
+	if !Valid(s) {
+		return "", false
+	}
+	return s, true
+}
+
+// isDigits returns true if s is non-empty and contains only ASCII digits.
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
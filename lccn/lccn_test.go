@@ -0,0 +1,54 @@
+package lccn_test
+
+import (
+	"testing"
+
+	"github.com/fwojciec/bookid/lccn"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalize(t *testing.T) {
+	t.Parallel()
+
+	for in, want := range map[string]string{
+		"n78-890351":         "n78890351",
+		"n78-89035":          "n78089035",
+		"n 78890351 ":        "n78890351",
+		" 85000002 ":         "85000002",
+		"85-2 ":              "85000002",
+		"2001-000002":        "2001000002",
+		"75-425165//r75":     "75425165",
+		" 79139101 /AC/r932": "79139101",
+		"SH85-26239":         "sh85026239",
+	} {
+		assert.Equal(t, want, lccn.Normalize(in), in)
+	}
+}
+
+func TestValid(t *testing.T) {
+	t.Parallel()
+
+	for _, s := range []string{"n78890351", "85000002", "2001000002", "sh85026239", "abc12345678"} {
+		assert.True(t, lccn.Valid(s), s)
+	}
+	for _, s := range []string{"", "1984", "abcd12345678", "1234567890", "abc2001000002", "78-890351", "n7889035x"} {
+		assert.False(t, lccn.Valid(s), s)
+	}
+}
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	got, ok := lccn.Parse("LCCN: 2004-111282")
+	assert.True(t, ok)
+	assert.Equal(t, "2004111282", got)
+
+	got, ok = lccn.Parse("2004111282")
+	assert.True(t, ok)
+	assert.Equal(t, "2004111282", got)
+
+	_, ok = lccn.Parse("the great gatsby")
+	assert.False(t, ok)
+	_, ok = lccn.Parse("1984")
+	assert.False(t, ok)
+}
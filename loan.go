@@ -0,0 +1,72 @@
+package bookid
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// Loan represents an item checked out to a borrower.
+type Loan struct {
+	ID         int64     `json:"id"`
+	ItemID     int64     `json:"item_id"`
+	Borrower   string    `json:"borrower"`
+	LoanedAt   time.Time `json:"loaned_at"`
+	DueAt      time.Time `json:"due_at,omitzero"`      // Zero if the loan has no due date
+	ReturnedAt time.Time `json:"returned_at,omitzero"` // Zero while the item is out
+}
+
+// Validate returns an error if the loan contains invalid fields.
+func (l *Loan) Validate() error {
+	if l.ItemID == 0 {
+		return Errorf(EINVALID, "Loan item required.")
+	} else if strings.TrimSpace(l.Borrower) == "" {
+		return Errorf(EINVALID, "Loan borrower required.")
+	}
+	return nil
+}
+
+// Overdue returns true if the item is still out after its due date at now.
+func (l *Loan) Overdue(now time.Time) bool {
+	return l.ReturnedAt.IsZero() && !l.DueAt.IsZero() && l.DueAt.Before(now)
+}
+
+// LoanService represents a service for checking items out to borrowers and
+// back in.
+type LoanService interface {
+	// FindLoanByID retrieves a single loan by ID.
+	// Returns ENOTFOUND if the loan does not exist.
+	FindLoanByID(ctx context.Context, id int64) (*Loan, error)
+
+	// FindLoans retrieves a list of loans matching the filter along with the
+	// total number of matches, ignoring Offset and Limit.
+	FindLoans(ctx context.Context, filter LoanFilter) ([]*Loan, int, error)
+
+	// CheckoutItem lends an item to a borrower and marks the item as on loan.
+	// Returns ENOTFOUND if the item does not exist and ECONFLICT if it is not
+	// available.
+	CheckoutItem(ctx context.Context, loan *Loan) error
+
+	// ReturnItem ends the open loan of an item and marks the item as
+	// available. Returns the returned loan. Returns ENOTFOUND if the item is
+	// not on loan.
+	ReturnItem(ctx context.Context, itemID int64) (*Loan, error)
+}
+
+// LoanFilter represents a filter used by FindLoans.
+type LoanFilter struct {
+	ID     *int64
+	ItemID *int64
+
+	// Borrower matches loans by borrower, ignoring case.
+	Borrower *string
+
+	// Open restricts results to loans not yet returned; Overdue further
+	// restricts them to loans past their due date.
+	Open    bool
+	Overdue bool
+
+	// Restrict to subset of results.
+	Offset int
+	Limit  int
+}
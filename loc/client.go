@@ -0,0 +1,355 @@
+// Package loc implements the BookFinder interface on top of the Library of
+// Congress loc.gov JSON API. LCCN queries are resolved to a single catalog
+// item, everything else is searched in the Books collection.
+package loc
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/internal/httpclient"
+	"github.com/fwojciec/bookid/isbn"
+	"github.com/fwojciec/bookid/language"
+	"github.com/fwojciec/bookid/lccn"
+	bookidquery "github.com/fwojciec/bookid/query"
+	"github.com/fwojciec/bookid/scoring"
+)
+
+// ProviderName identifies results produced by this package.
+const ProviderName = "loc"
+
+// DefaultBaseURL is the root of the public loc.gov API.
+const DefaultBaseURL = "https://www.loc.gov"
+
+// Page sizes for the collection search. The API rejects more than 1000.
+const (
+	defaultMaxResults = 10
+	maxMaxResults     = 1000
+)
+
+// Client implements the BookFinder interface for the loc.gov API.
+type Client struct {
+	httpClient *http.Client
+	baseURL    string
+
+	// Computes the confidence of each result.
+	Scorer *scoring.Scorer
+}
+
+// Register the provider so it can be enabled by name.
+func init() {
+	bookid.RegisterFinder(ProviderName, func(config bookid.ProviderConfig) (bookid.BookFinder, error) {
+		return NewClientWithBaseURL(config.Client(), DefaultBaseURL), nil
+	})
+}
+
+// NewClient creates a new loc.gov API client. The API does not require an
+// API key.
+func NewClient() *Client {
+	return NewClientWithBaseURL(http.DefaultClient, DefaultBaseURL)
+}
+
+// NewClientWithBaseURL creates a new client against a custom endpoint (for testing)
+func NewClientWithBaseURL(httpClient *http.Client, baseURL string) *Client {
+	return &Client{
+		httpClient: httpClient,
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		Scorer:     scoring.Default(),
+	}
+}
+
+// Search performs a book search based on the provided query. Print type and
+// order are ignored as the Books collection only holds books.
+func (c *Client) Search(ctx context.Context, query string, opts bookid.SearchOptions) ([]bookid.BookResult, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, errors.New("query cannot be empty")
+	} else if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	var results []bookid.BookResult
+	var err error
+	if parsed := bookidquery.Parse(query); len(parsed.LCCNs) > 0 {
+		results, err = c.searchLCCN(ctx, parsed.LCCNs[0])
+	} else {
+		results, err = c.searchGeneral(ctx, query, parsed, opts)
+	}
+	if err != nil {
+		return nil, err
+	}
+	for i := range results {
+		results[i].Confidence = c.Scorer.Score(scoring.Input{Query: query, Options: opts, Result: results[i]})
+	}
+	return opts.Apply(results), nil
+}
+
+// searchLCCN looks up the catalog item identified by an LCCN.
+func (c *Client) searchLCCN(ctx context.Context, code string) ([]bookid.BookResult, error) {
+	var resp struct {
+		Item json.RawMessage `json:"item"`
+	}
+	if err := c.get(ctx, "/item/"+url.PathEscape(code)+"/", nil, &resp); err != nil {
+		if httpclient.IsStatus(err, http.StatusNotFound) {
+			return []bookid.BookResult{}, nil
+		}
+		return nil, err
+	} else if len(resp.Item) == 0 {
+		return []bookid.BookResult{}, nil
+	}
+
+	var rec record
+	if err := json.Unmarshal(resp.Item, &rec); err != nil {
+		return nil, fmt.Errorf("decoding loc item: %w", err)
+	}
+	result := rec.toBookResult(bookid.SearchTypeLCCN)
+	if result.LCCN == "" {
+		result.LCCN = code
+	}
+	result.ProviderData = resp.Item
+	return []bookid.BookResult{result}, nil
+}
+
+// searchGeneral performs a free-text search of the Books collection. The API
+// pages by page number, so StartIndex is rounded down to the start of its
+// page. Languages are matched by their English name. The collection has no
+// field search, so the fields of q are searched as keywords, falling back to
+// the whole query if q has only identifiers.
+func (c *Client) searchGeneral(ctx context.Context, query string, q bookid.ParsedQuery, opts bookid.SearchOptions) ([]bookid.BookResult, error) {
+	words := slices.DeleteFunc(append([]string{q.Terms, q.Title, q.Author, q.Publisher}, q.ISBNs...), func(s string) bool { return s == "" })
+	keywords := cmp.Or(strings.Join(words, " "), query)
+
+	size := pageSize(opts.MaxResults)
+	params := url.Values{
+		"q":  {keywords},
+		"c":  {strconv.Itoa(size)},
+		"sp": {strconv.Itoa(opts.StartIndex/size + 1)},
+	}
+	if lang := cmp.Or(opts.Language, q.Language); lang != "" {
+		params.Set("fa", "language:"+strings.ToLower(language.Name(lang)))
+	}
+	if q.Year != 0 {
+		params.Set("dates", strconv.Itoa(q.Year)+"/"+strconv.Itoa(q.Year))
+	}
+
+	var resp struct {
+		Results []json.RawMessage `json:"results"`
+	}
+	if err := c.get(ctx, "/books/", params, &resp); err != nil {
+		return nil, err
+	}
+
+	results := make([]bookid.BookResult, 0, len(resp.Results))
+	for _, raw := range resp.Results {
+		var rec record
+		if err := json.Unmarshal(raw, &rec); err != nil {
+			return nil, fmt.Errorf("decoding loc search result: %w", err)
+		}
+		result := rec.toBookResult(bookid.SearchTypeGeneralQuery)
+		result.ProviderData = raw
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// pageSize returns the number of results to request for maxResults.
+func pageSize(maxResults int) int {
+	if maxResults <= 0 {
+		return defaultMaxResults
+	}
+	return min(maxResults, maxMaxResults)
+}
+
+// get performs a GET request against the API and decodes the JSON response
+// into v. JSON output is requested with the fo parameter.
+func (c *Client) get(ctx context.Context, path string, params url.Values, v any) error {
+	if params == nil {
+		params = url.Values{}
+	}
+	params.Set("fo", "json")
+	return httpclient.GetJSON(ctx, c.httpClient, "Library of Congress", c.baseURL+path+"?"+params.Encode(), nil, v)
+}
+
+// record is a catalog record as returned by both the item and search
+// endpoints. Search results nest publication details under "item".
+type record struct {
+	Title            string  `json:"title"`
+	Contributor      list    `json:"contributor"`
+	ContributorNames list    `json:"contributor_names"`
+	Date             string  `json:"date"`
+	Language         list    `json:"language"`
+	LCCN             list    `json:"number_lccn"`
+	ISBN             list    `json:"number_isbn"`
+	CreatedPublished list    `json:"created_published"`
+	ImageURL         list    `json:"image_url"`
+	Item             *record `json:"item"`
+}
+
+// toBookResult converts a record to our BookResult.
+func (r *record) toBookResult(searchType bookid.SearchType) bookid.BookResult {
+	// Fill gaps from the nested item of search results.
+	if r.Item != nil {
+		if len(r.ContributorNames) == 0 {
+			r.ContributorNames = r.Item.ContributorNames
+		}
+		if len(r.CreatedPublished) == 0 {
+			r.CreatedPublished = r.Item.CreatedPublished
+		}
+		if len(r.ISBN) == 0 {
+			r.ISBN = r.Item.ISBN
+		}
+	}
+
+	result := bookid.BookResult{
+		Title:         cleanTitle(r.Title),
+		Authors:       []string{},
+		PublishedYear: extractYear(r.Date),
+		Provider:      ProviderName,
+		SearchType:    searchType,
+	}
+
+	names := r.ContributorNames
+	if len(names) == 0 {
+		names = r.Contributor
+	}
+	for _, name := range names {
+		if name = invertName(name); name != "" {
+			result.Authors = append(result.Authors, name)
+		}
+	}
+
+	for _, code := range r.LCCN {
+		if code = lccn.Normalize(code); lccn.Valid(code) {
+			result.LCCN = code
+			break
+		}
+	}
+	for _, code := range r.ISBN {
+		// Drop qualifiers such as "(pbk.)".
+		code, _, _ = strings.Cut(strings.TrimSpace(code), " ")
+		code = isbn.Normalize(code)
+		switch {
+		case result.ISBN13 == "" && isbn.Valid13(code):
+			result.ISBN13 = code
+		case result.ISBN10 == "" && isbn.Valid10(code):
+			result.ISBN10 = code
+		}
+	}
+
+	if len(r.Language) > 0 {
+		result.Language = language.FromName(r.Language[0])
+	}
+	if len(r.CreatedPublished) > 0 {
+		result.Publisher = publisher(r.CreatedPublished[0])
+		if result.PublishedYear == 0 {
+			result.PublishedYear = extractYear(r.CreatedPublished[0])
+		}
+	}
+	if len(r.ImageURL) > 0 {
+		result.ThumbnailURL = httpclient.EnsureHTTPS(r.ImageURL[0])
+	}
+	return result
+}
+
+// list is a JSON string array that the API sometimes sends as a single string.
+type list []string
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (l *list) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*l = list{s}
+		return nil
+	}
+	var ss []string
+	if err := json.Unmarshal(data, &ss); err != nil {
+		return err
+	}
+	*l = ss
+	return nil
+}
+
+// cleanTitle strips the statement of responsibility and trailing ISBD
+// punctuation from a catalog title, e.g. "The great Gatsby / F. Scott
+// Fitzgerald." becomes "The great Gatsby".
+func cleanTitle(title string) string {
+	title, _, _ = strings.Cut(title, " / ")
+	return strings.TrimRight(strings.TrimSpace(title), " /:;.,")
+}
+
+// invertName converts a catalog heading such as "Fitzgerald, F. Scott
+// (Francis Scott), 1896-1940." to "F. Scott Fitzgerald". Lowercased headings
+// from search results are title-cased.
+func invertName(heading string) string {
+	// Drop fuller forms of the name in parentheses.
+	if i := strings.Index(heading, "("); i >= 0 {
+		if j := strings.Index(heading[i:], ")"); j >= 0 {
+			heading = heading[:i] + heading[i+j+1:]
+		}
+	}
+
+	var parts []string
+	for _, part := range strings.Split(heading, ",") {
+		part = strings.TrimSpace(part)
+		// Drop dates and relator terms such as "author".
+		if part == "" || strings.ContainsAny(part, "0123456789") || part == "author" || part == "editor" {
+			continue
+		}
+		parts = append(parts, part)
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+
+	name := parts[0]
+	if len(parts) > 1 {
+		name = parts[1] + " " + parts[0]
+	}
+	name = strings.Join(strings.Fields(strings.TrimRight(name, ".")), " ")
+	if name == strings.ToLower(name) {
+		name = titleCase(name)
+	}
+	return name
+}
+
+// titleCase upper-cases the first letter of every word in s.
+func titleCase(s string) string {
+	words := strings.Fields(s)
+	for i, w := range words {
+		words[i] = strings.ToUpper(w[:1]) + w[1:]
+	}
+	return strings.Join(words, " ")
+}
+
+// publisher extracts the publisher from an imprint statement such as
+// "New York : Scribner, c2004.".
+func publisher(imprint string) string {
+	_, name, ok := strings.Cut(imprint, " : ")
+	if !ok {
+		return ""
+	}
+	if i := strings.LastIndex(name, ","); i >= 0 {
+		name = name[:i]
+	}
+	return strings.TrimSpace(strings.TrimRight(name, " ,.;"))
+}
+
+// extractYear returns the first four-digit year in a catalog date such as
+// "2004", "c2004" or "[2004]".
+func extractYear(date string) int {
+	for i := 0; i+4 <= len(date); i++ {
+		if year, err := strconv.Atoi(date[i : i+4]); err == nil && year >= 1000 && year <= 2999 {
+			return year
+		}
+	}
+	return 0
+}
@@ -0,0 +1,133 @@
+package loc_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"testing"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/internal/httptestutil"
+	"github.com/fwojciec/bookid/loc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newClient returns a client replaying the given synthetic fixture, modeled
+// by hand on loc.gov JSON responses rather than recorded. The URL of the last
+// request is stored in lastURL, if set.
+func newClient(t *testing.T, fixture string, lastURL *string) *loc.Client {
+	t.Helper()
+	httpClient := httptestutil.Replay(t, filepath.Join("testdata", "synthetic", fixture))
+	if lastURL != nil {
+		httpClient = httptestutil.LastURL(httpClient, lastURL)
+	}
+	return loc.NewClientWithBaseURL(httpClient, loc.DefaultBaseURL)
+}
+
+func TestClient_Search(t *testing.T) {
+	t.Parallel()
+
+	t.Run("lccn", func(t *testing.T) {
+		t.Parallel()
+		var lastURL string
+		client := newClient(t, "item_2004111282.json", &lastURL)
+
+		results, err := client.Search(context.Background(), "lccn:2004-111282", bookid.SearchOptions{IncludeRaw: true})
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		assert.Equal(t, loc.DefaultBaseURL+"/item/2004111282/?fo=json", lastURL)
+
+		r := results[0]
+		assert.Equal(t, "The great Gatsby", r.Title)
+		assert.Equal(t, []string{"F. Scott Fitzgerald"}, r.Authors)
+		assert.Equal(t, "2004111282", r.LCCN)
+		assert.Equal(t, "0743273567", r.ISBN10)
+		assert.Equal(t, "9780743273565", r.ISBN13)
+		assert.Equal(t, "Scribner", r.Publisher)
+		assert.Equal(t, 2004, r.PublishedYear)
+		assert.Equal(t, "en", r.Language)
+		assert.Equal(t, loc.ProviderName, r.Provider)
+		assert.Equal(t, bookid.SearchTypeLCCN, r.SearchType)
+		assert.InDelta(t, 0.95, r.Confidence, 0.01)
+		assert.NotEmpty(t, r.ProviderData)
+	})
+
+	t.Run("general", func(t *testing.T) {
+		t.Parallel()
+		var lastURL string
+		client := newClient(t, "search_gatsby.json", &lastURL)
+
+		results, err := client.Search(context.Background(), "the great gatsby", bookid.SearchOptions{
+			MaxResults: 5,
+			StartIndex: 5,
+			Language:   "en",
+		})
+		require.NoError(t, err)
+		require.Len(t, results, 2)
+		for _, want := range []string{"/books/?", "q=the+great+gatsby", "c=5", "sp=2", "fa=language%3Aenglish", "fo=json"} {
+			assert.Contains(t, lastURL, want)
+		}
+
+		r := results[0]
+		assert.Equal(t, "The great Gatsby", r.Title)
+		assert.Equal(t, []string{"F. Scott Fitzgerald"}, r.Authors)
+		assert.Equal(t, "2004111282", r.LCCN)
+		assert.Equal(t, "9780743273565", r.ISBN13)
+		assert.Equal(t, "Scribner", r.Publisher)
+		assert.Equal(t, "https://tile.loc.gov/image-services/gatsby.gif", r.ThumbnailURL)
+		assert.Equal(t, bookid.SearchTypeGeneralQuery, r.SearchType)
+		assert.Nil(t, r.ProviderData, "raw data is only kept when requested")
+
+		assert.Equal(t, "25010460", results[1].LCCN)
+		assert.Equal(t, 1925, results[1].PublishedYear)
+	})
+
+	t.Run("fields", func(t *testing.T) {
+		t.Parallel()
+		var lastURL string
+		client := newClient(t, "search_gatsby_1925.json", &lastURL)
+
+		_, err := client.Search(context.Background(), `title:"The Great Gatsby" author:Fitzgerald year:1925 lang:en`, bookid.SearchOptions{})
+		require.NoError(t, err)
+		u, err := url.Parse(lastURL)
+		require.NoError(t, err)
+		assert.Equal(t, "The Great Gatsby Fitzgerald", u.Query().Get("q"))
+		assert.Equal(t, "1925/1925", u.Query().Get("dates"))
+		assert.Equal(t, "language:english", u.Query().Get("fa"))
+	})
+
+	t.Run("not_found", func(t *testing.T) {
+		t.Parallel()
+		client := newClient(t, "item_n78890351.json", nil)
+
+		results, err := client.Search(context.Background(), "n78-890351", bookid.SearchOptions{})
+		require.NoError(t, err)
+		assert.Empty(t, results)
+	})
+}
+
+func TestClient_Search_Errors(t *testing.T) {
+	t.Parallel()
+
+	t.Run("empty_query", func(t *testing.T) {
+		t.Parallel()
+		_, err := loc.NewClient().Search(context.Background(), "  ", bookid.SearchOptions{})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "query cannot be empty")
+	})
+
+	t.Run("server_error", func(t *testing.T) {
+		t.Parallel()
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		t.Cleanup(srv.Close)
+		client := loc.NewClientWithBaseURL(srv.Client(), srv.URL)
+
+		_, err := client.Search(context.Background(), "dune", bookid.SearchOptions{})
+		assert.Equal(t, bookid.EUNAVAILABLE, bookid.ErrorCode(err))
+	})
+}
@@ -0,0 +1,87 @@
+package local
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/cache"
+)
+
+// DefaultMinConfidence is the confidence a local result needs for a
+// LocalFirstFinder to skip the providers.
+const DefaultMinConfidence = 0.5
+
+// Ensure type implements interface.
+var _ bookid.BookFinder = (*LocalFirstFinder)(nil)
+
+// LocalFirstFinder searches the catalog and search cache first and the
+// providers only when they have no good enough result, saving what the
+// providers return in the search cache. Unlike a CachingFinder, cached
+// results are served whatever their age, which cuts the requests sent to
+// providers for books that are looked up again and again.
+type LocalFirstFinder struct {
+	local  bookid.BookFinder
+	remote bookid.BookFinder
+
+	// Stores the results of the providers, so the next search of the same
+	// query finds them locally. Nil stores nothing.
+	SearchCache bookid.SearchCache
+
+	// Local results with a lower confidence count as a miss.
+	MinConfidence float64
+
+	// Receives debug logs of local hits and misses. Defaults to discarding
+	// them.
+	Logger *slog.Logger
+
+	// Returns the current time. Defaults to time.Now().
+	// Can be mocked for tests.
+	Now func() time.Time
+}
+
+// NewLocalFirstFinder returns a LocalFirstFinder searching local first and
+// remote on a miss, storing the results of remote in searchCache, which may
+// be nil.
+func NewLocalFirstFinder(local, remote bookid.BookFinder, searchCache bookid.SearchCache) *LocalFirstFinder {
+	return &LocalFirstFinder{
+		local:         local,
+		remote:        remote,
+		SearchCache:   searchCache,
+		MinConfidence: DefaultMinConfidence,
+		Logger:        slog.New(slog.DiscardHandler),
+		Now:           time.Now,
+	}
+}
+
+// Search returns the local results of query if any is confident enough, and
+// otherwise those of the providers. A failed local search falls back to the
+// providers as well.
+func (f *LocalFirstFinder) Search(ctx context.Context, query string, opts bookid.SearchOptions) ([]bookid.BookResult, error) {
+	results, err := f.local.Search(ctx, query, opts)
+	if err == nil && slices.ContainsFunc(results, func(r bookid.BookResult) bool { return r.Confidence >= f.MinConfidence }) {
+		f.Logger.DebugContext(ctx, "found locally", "query", query, "results", len(results))
+		return results, nil
+	} else if err != nil {
+		f.Logger.DebugContext(ctx, "local search failed", "query", query, "error", err)
+	} else {
+		f.Logger.DebugContext(ctx, "not found locally", "query", query)
+	}
+
+	if results, err = f.remote.Search(ctx, query, opts); err != nil {
+		return nil, err
+	}
+
+	// Results missing providers that timed out are not stored, so that the
+	// next search asks them again.
+	if f.SearchCache != nil && !slices.ContainsFunc(results, func(r bookid.BookResult) bool { return len(r.TimedOut) > 0 }) {
+		_ = f.SearchCache.SetSearchCacheEntry(ctx, &bookid.SearchCacheEntry{
+			Key:       cache.Key(query, opts),
+			Results:   results,
+			CreatedAt: f.Now(),
+		})
+	}
+	return results, nil
+}
@@ -0,0 +1,107 @@
+package local_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/cache"
+	"github.com/fwojciec/bookid/local"
+	"github.com/fwojciec/bookid/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// finder returns a finder returning results or err, counting its calls.
+func finder(calls *int, err error, results ...bookid.BookResult) *mock.BookFinder {
+	return &mock.BookFinder{SearchFn: func(context.Context, string, bookid.SearchOptions) ([]bookid.BookResult, error) {
+		*calls++
+		return results, err
+	}}
+}
+
+func TestLocalFirstFinder_Search(t *testing.T) {
+	t.Parallel()
+
+	t.Run("local_hit", func(t *testing.T) {
+		t.Parallel()
+		var localCalls, remoteCalls int
+		f := local.NewLocalFirstFinder(
+			finder(&localCalls, nil, bookid.BookResult{Title: "Dune", Provider: local.ProviderName, Confidence: 0.9}),
+			finder(&remoteCalls, nil),
+			nil,
+		)
+
+		results, err := f.Search(context.Background(), "dune", bookid.SearchOptions{})
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		assert.Equal(t, local.ProviderName, results[0].Provider)
+		assert.Equal(t, 0, remoteCalls)
+	})
+
+	t.Run("miss_is_cached", func(t *testing.T) {
+		t.Parallel()
+		store := cache.NewMemoryStore(0)
+		var remoteCalls int
+		remote := finder(&remoteCalls, nil, bookid.BookResult{Title: "Neuromancer", Provider: "googlebooks", Confidence: 0.9})
+		f := local.NewLocalFirstFinder(newLocalFinder(t, store), remote, store)
+		ctx := context.Background()
+
+		// Only the first search reaches the providers; the second is
+		// answered from the cache.
+		for range 2 {
+			results, err := f.Search(ctx, "9780441569595", bookid.SearchOptions{})
+			require.NoError(t, err)
+			require.Len(t, results, 1)
+			assert.Equal(t, "googlebooks", results[0].Provider)
+		}
+		assert.Equal(t, 1, remoteCalls)
+	})
+
+	t.Run("low_confidence", func(t *testing.T) {
+		t.Parallel()
+		var localCalls, remoteCalls int
+		f := local.NewLocalFirstFinder(
+			finder(&localCalls, nil, bookid.BookResult{Title: "Dune Messiah", Confidence: 0.3}),
+			finder(&remoteCalls, nil, bookid.BookResult{Title: "Dune", Confidence: 0.9}),
+			nil,
+		)
+
+		results, err := f.Search(context.Background(), "dune", bookid.SearchOptions{})
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		assert.Equal(t, "Dune", results[0].Title)
+		assert.Equal(t, 1, remoteCalls)
+	})
+
+	t.Run("local_error", func(t *testing.T) {
+		t.Parallel()
+		var localCalls, remoteCalls int
+		f := local.NewLocalFirstFinder(
+			finder(&localCalls, errors.New("database is locked")),
+			finder(&remoteCalls, nil, bookid.BookResult{Title: "Dune", Confidence: 0.9}),
+			nil,
+		)
+
+		results, err := f.Search(context.Background(), "dune", bookid.SearchOptions{})
+		require.NoError(t, err)
+		assert.Len(t, results, 1)
+	})
+
+	t.Run("partial_not_cached", func(t *testing.T) {
+		t.Parallel()
+		store := cache.NewMemoryStore(0)
+		var localCalls, remoteCalls int
+		f := local.NewLocalFirstFinder(
+			finder(&localCalls, nil),
+			finder(&remoteCalls, nil, bookid.BookResult{Title: "Dune", Confidence: 0.9, TimedOut: []string{"worldcat"}}),
+			store,
+		)
+
+		_, err := f.Search(context.Background(), "dune", bookid.SearchOptions{})
+		require.NoError(t, err)
+		_, err = store.FindSearchCacheEntry(context.Background(), cache.Key("dune", bookid.SearchOptions{}))
+		assert.Equal(t, bookid.ENOTFOUND, bookid.ErrorCode(err))
+	})
+}
@@ -0,0 +1,254 @@
+// Package local implements a BookFinder over the local catalog and search
+// cache, so queries resolve against books saved or searched before without
+// any network calls, such as on planes or in air-gapped environments, and a
+// BookFinder decorator searching them before the providers.
+package local
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"slices"
+	"strings"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/cache"
+	bookidquery "github.com/fwojciec/bookid/query"
+	"github.com/fwojciec/bookid/scoring"
+)
+
+// ProviderName identifies results produced from the catalog.
+const ProviderName = "local"
+
+// defaultMaxResults is the number of cataloged works searched unless the
+// options ask for more.
+const defaultMaxResults = 10
+
+// relevanceWeight is the share of confidence a work found by text can lose
+// for matching the query less well, by BM25, than the best match.
+const relevanceWeight = 0.2
+
+// Ensure type implements interface.
+var _ bookid.BookFinder = (*LocalFinder)(nil)
+
+// LocalFinder resolves queries from the search cache and the catalog. Cached
+// results of a search with the same query and options are returned whatever
+// their age, keeping the provider they came from. Otherwise identifiers in
+// the query are looked up among the cataloged publications and the rest of
+// it searched in the full-text index of the catalog.
+//
+// Results are scored like those of the providers, so the two can be ranked
+// together. Those found by text have SearchType "local" and lose some
+// confidence the further their BM25 relevance is from the best match.
+type LocalFinder struct {
+	WorkService          bookid.WorkService
+	AuthorService        bookid.AuthorService
+	PublicationService   bookid.PublicationService
+	CatalogSearchService bookid.CatalogSearchService
+
+	// Results of earlier searches. Nil searches only the catalog.
+	SearchCache bookid.SearchCache
+
+	// Computes the confidence of each cataloged result.
+	Scorer *scoring.Scorer
+}
+
+// NewLocalFinder returns a LocalFinder over the catalog services and the
+// search cache, which may be nil.
+func NewLocalFinder(works bookid.WorkService, authors bookid.AuthorService, pubs bookid.PublicationService, search bookid.CatalogSearchService, searchCache bookid.SearchCache) *LocalFinder {
+	return &LocalFinder{
+		WorkService:          works,
+		AuthorService:        authors,
+		PublicationService:   pubs,
+		CatalogSearchService: search,
+		SearchCache:          searchCache,
+		Scorer:               scoring.Default(),
+	}
+}
+
+// Search returns the cached results of query, or the cataloged publications
+// matching it. Returns no results and no error if nothing matches.
+func (f *LocalFinder) Search(ctx context.Context, query string, opts bookid.SearchOptions) ([]bookid.BookResult, error) {
+	if query == "" {
+		return nil, errors.New("query cannot be empty")
+	} else if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	if f.SearchCache != nil {
+		if entry, err := f.SearchCache.FindSearchCacheEntry(ctx, cache.Key(query, opts)); err == nil {
+			return opts.Apply(entry.Results), nil
+		} else if bookid.ErrorCode(err) != bookid.ENOTFOUND {
+			return nil, err
+		}
+	}
+
+	parsed := bookidquery.Parse(query)
+	searchType := parsed.SearchType()
+	var relevance map[int64]float64
+	pubs, err := f.findByIdentifiers(ctx, parsed, opts)
+	if err != nil {
+		return nil, err
+	} else if len(pubs) == 0 && parsed.HasText() {
+		if pubs, relevance, err = f.findByText(ctx, parsed, opts); err != nil {
+			return nil, err
+		}
+		searchType = bookid.SearchTypeLocal
+	}
+
+	var best float64
+	for _, r := range relevance {
+		best = max(best, r)
+	}
+
+	results := make([]bookid.BookResult, 0, len(pubs))
+	for _, pub := range pubs {
+		result, err := f.toBookResult(ctx, pub)
+		if err != nil {
+			return nil, err
+		}
+		result.SearchType = searchType
+		result.Confidence = f.Scorer.Score(scoring.Input{Query: query, Options: opts, Result: result})
+		if best > 0 {
+			result.Confidence *= 1 - relevanceWeight + relevanceWeight*relevance[pub.WorkID]/best
+		}
+		results = append(results, result)
+	}
+	slices.SortStableFunc(results, func(a, b bookid.BookResult) int {
+		return cmp.Compare(b.Confidence, a.Confidence)
+	})
+	return opts.Apply(results), nil
+}
+
+// findByIdentifiers returns the publications with any of the identifiers of
+// the query, in the language searched if one is given.
+func (f *LocalFinder) findByIdentifiers(ctx context.Context, parsed bookid.ParsedQuery, opts bookid.SearchOptions) ([]*bookid.Publication, error) {
+	var filters []bookid.PublicationFilter
+	for _, code := range parsed.ISBNs {
+		filters = append(filters, bookid.PublicationFilter{ISBN: &code})
+	}
+	for _, code := range parsed.LCCNs {
+		filters = append(filters, bookid.PublicationFilter{LCCN: &code})
+	}
+	for _, code := range parsed.DOIs {
+		filters = append(filters, bookid.PublicationFilter{DOI: &code})
+	}
+	for _, code := range parsed.ASINs {
+		filters = append(filters, bookid.PublicationFilter{ASIN: &code})
+	}
+	for _, id := range parsed.GoogleBooksIDs {
+		filters = append(filters, bookid.PublicationFilter{GoogleBooksVolumeID: &id})
+	}
+
+	var pubs []*bookid.Publication
+	seen := make(map[int64]bool)
+	for _, filter := range filters {
+		if lang := cmp.Or(opts.Language, parsed.Language); lang != "" {
+			filter.Language = &lang
+		}
+		found, _, err := f.PublicationService.FindPublications(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+		for _, pub := range found {
+			if !seen[pub.ID] {
+				seen[pub.ID] = true
+				pubs = append(pubs, pub)
+			}
+		}
+	}
+	return pubs, nil
+}
+
+// findByText returns the publications of the works matching the text of the
+// query in the full-text index, in the language searched if one is given,
+// and the relevance of each work by ID.
+func (f *LocalFinder) findByText(ctx context.Context, parsed bookid.ParsedQuery, opts bookid.SearchOptions) ([]*bookid.Publication, map[int64]float64, error) {
+	var terms []string
+	for _, s := range []string{parsed.Terms, parsed.Title, parsed.Author, parsed.Publisher} {
+		if s != "" {
+			terms = append(terms, s)
+		}
+	}
+	works, _, err := f.CatalogSearchService.SearchCatalog(ctx, bookid.CatalogSearchFilter{
+		Query: strings.Join(terms, " "),
+		Limit: max(opts.MaxResults, defaultMaxResults),
+	})
+	if bookid.ErrorCode(err) == bookid.EINVALID {
+		// Nothing in the text can be searched, such as only punctuation.
+		return nil, nil, nil
+	} else if err != nil {
+		return nil, nil, err
+	}
+
+	var pubs []*bookid.Publication
+	relevance := make(map[int64]float64, len(works))
+	for _, work := range works {
+		relevance[work.ID] = work.Relevance
+		filter := bookid.PublicationFilter{WorkID: &work.ID}
+		if lang := cmp.Or(opts.Language, parsed.Language); lang != "" {
+			filter.Language = &lang
+		}
+		if parsed.Year != 0 {
+			filter.PublishedYear = &parsed.Year
+		}
+		found, _, err := f.PublicationService.FindPublications(ctx, filter)
+		if err != nil {
+			return nil, nil, err
+		}
+		pubs = append(pubs, found...)
+	}
+	return pubs, relevance, nil
+}
+
+// toBookResult returns pub as a result along with the title, authors and
+// other contributors of its work.
+func (f *LocalFinder) toBookResult(ctx context.Context, pub *bookid.Publication) (bookid.BookResult, error) {
+	work, err := f.WorkService.FindWorkByID(ctx, pub.WorkID)
+	if err != nil {
+		return bookid.BookResult{}, err
+	}
+	authors, _, err := f.AuthorService.FindAuthors(ctx, bookid.AuthorFilter{WorkID: &work.ID})
+	if err != nil {
+		return bookid.BookResult{}, err
+	}
+
+	result := bookid.BookResult{
+		Title:               work.Title,
+		Authors:             make([]string, 0, len(authors)),
+		ISBN10:              pub.ISBN10,
+		ISBN13:              pub.ISBN13,
+		Publisher:           pub.Publisher,
+		PublishedYear:       pub.PublishedYear,
+		Language:            pub.Language,
+		Binding:             pub.Binding,
+		PageCount:           pub.PageCount,
+		DurationMinutes:     pub.DurationMinutes,
+		Dimensions:          pub.Dimensions,
+		GoogleBooksVolumeID: pub.GoogleBooksVolumeID,
+		OCLCNumber:          pub.OCLCNumber,
+		LCCN:                pub.LCCN,
+		DOI:                 pub.DOI,
+		ASIN:                pub.ASIN,
+		ThumbnailURL:        pub.ThumbnailURL,
+		OriginalTitle:       work.OriginalTitle,
+		OriginalLanguage:    work.OriginalLanguage,
+		Description:         pub.Description,
+		TableOfContents:     pub.TableOfContents,
+		Provider:            ProviderName,
+	}
+	for _, author := range authors {
+		if author.Role == "" || author.Role == bookid.ContributorRoleAuthor {
+			result.Authors = append(result.Authors, author.Name)
+		} else {
+			result.Contributors = append(result.Contributors, bookid.Contributor{Name: author.Name, Role: author.Role})
+		}
+	}
+	if !pub.Access.IsZero() {
+		result.Access = &pub.Access
+	}
+	if !pub.Classification.IsZero() {
+		result.Classification = &pub.Classification
+	}
+	return result, nil
+}
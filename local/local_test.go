@@ -0,0 +1,120 @@
+package local_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/cache"
+	"github.com/fwojciec/bookid/local"
+	"github.com/fwojciec/bookid/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newLocalFinder returns a finder over an in-memory catalog holding Dune, its
+// Polish translation and any other results, and searchCache.
+func newLocalFinder(t *testing.T, searchCache bookid.SearchCache, other ...bookid.BookResult) *local.LocalFinder {
+	t.Helper()
+	db := sqlite.NewDB(":memory:")
+	require.NoError(t, db.Open())
+	t.Cleanup(func() { _ = db.Close() })
+
+	ctx := context.Background()
+	catalog := sqlite.NewCatalogService(db)
+	for _, result := range append([]bookid.BookResult{
+		{Title: "Dune", Authors: []string{"Frank Herbert"}, ISBN13: "9780441172719", Publisher: "Ace", PublishedYear: 1990, Language: "en"},
+		{Title: "Dune", Authors: []string{"Frank Herbert"}, Contributors: []bookid.Contributor{{Name: "Marek Marszał", Role: bookid.ContributorRoleTranslator}}, ISBN13: "9788382154651", Language: "pl"},
+	}, other...) {
+		_, _, err := catalog.SaveResult(ctx, result)
+		require.NoError(t, err)
+	}
+
+	return local.NewLocalFinder(
+		sqlite.NewWorkService(db),
+		sqlite.NewAuthorService(db),
+		sqlite.NewPublicationService(db),
+		sqlite.NewCatalogSearchService(db),
+		searchCache,
+	)
+}
+
+func TestLocalFinder_Search(t *testing.T) {
+	t.Parallel()
+
+	t.Run("isbn", func(t *testing.T) {
+		t.Parallel()
+		f := newLocalFinder(t, nil)
+
+		results, err := f.Search(context.Background(), "978-0-441-17271-9", bookid.SearchOptions{})
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		assert.Equal(t, "Dune", results[0].Title)
+		assert.Equal(t, []string{"Frank Herbert"}, results[0].Authors)
+		assert.Equal(t, "Ace", results[0].Publisher)
+		assert.Equal(t, local.ProviderName, results[0].Provider)
+		assert.Equal(t, bookid.SearchTypeISBN, results[0].SearchType)
+		assert.Positive(t, results[0].Confidence)
+	})
+
+	t.Run("text", func(t *testing.T) {
+		t.Parallel()
+		f := newLocalFinder(t, nil)
+
+		results, err := f.Search(context.Background(), "dune herbert", bookid.SearchOptions{})
+		require.NoError(t, err)
+		require.Len(t, results, 2)
+		assert.Equal(t, bookid.SearchTypeLocal, results[0].SearchType)
+
+		results, err = f.Search(context.Background(), "dune herbert", bookid.SearchOptions{Language: "pl"})
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		assert.Equal(t, "9788382154651", results[0].ISBN13)
+		assert.Equal(t, []bookid.Contributor{{Name: "Marek Marszał", Role: bookid.ContributorRoleTranslator}}, results[0].Contributors)
+	})
+
+	t.Run("ranked", func(t *testing.T) {
+		t.Parallel()
+		f := newLocalFinder(t, nil,
+			bookid.BookResult{Title: "The Road to Dune", Authors: []string{"Frank Herbert", "Brian Herbert", "Kevin J. Anderson"}, ISBN13: "9780765353719", Publisher: "Tor", Language: "en"},
+		)
+
+		results, err := f.Search(context.Background(), "dune", bookid.SearchOptions{Language: "en"})
+		require.NoError(t, err)
+		require.Len(t, results, 2)
+		assert.Equal(t, "Dune", results[0].Title)
+		assert.Equal(t, "The Road to Dune", results[1].Title)
+		assert.Greater(t, results[0].Confidence, results[1].Confidence)
+		assert.LessOrEqual(t, results[0].Confidence, 1.0)
+	})
+
+	t.Run("cached", func(t *testing.T) {
+		t.Parallel()
+		store := cache.NewMemoryStore(0)
+		require.NoError(t, store.SetSearchCacheEntry(context.Background(), &bookid.SearchCacheEntry{
+			Key:       cache.Key("Neuromancer", bookid.SearchOptions{}),
+			Results:   []bookid.BookResult{{Title: "Neuromancer", Provider: "googlebooks"}},
+			CreatedAt: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+		}))
+		f := newLocalFinder(t, store)
+
+		results, err := f.Search(context.Background(), "  neuromancer", bookid.SearchOptions{})
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		assert.Equal(t, "googlebooks", results[0].Provider, "stale entries are served")
+	})
+
+	t.Run("nothing_found", func(t *testing.T) {
+		t.Parallel()
+		f := newLocalFinder(t, cache.NewMemoryStore(0))
+
+		results, err := f.Search(context.Background(), "neuromancer", bookid.SearchOptions{})
+		require.NoError(t, err)
+		assert.Empty(t, results)
+
+		results, err = f.Search(context.Background(), "9780441569595", bookid.SearchOptions{})
+		require.NoError(t, err)
+		assert.Empty(t, results)
+	})
+}
@@ -0,0 +1,340 @@
+// Package marc reads and writes MARC 21 bibliographic records, the exchange
+// format of library catalogs and integrated library systems (ILS). Records
+// are written either as ISO 2709 binary ("MARC") or as MARCXML.
+package marc
+
+import (
+	"encoding/xml"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/language"
+)
+
+// Namespace is the XML namespace of MARCXML documents.
+const Namespace = "http://www.loc.gov/MARC21/slim"
+
+// OrganizationCode identifies bookid as the source of control numbers in
+// field 003.
+const OrganizationCode = "bookid"
+
+// Record is a MARC 21 record. Control fields always precede data fields.
+type Record struct {
+	XMLName       xml.Name       `xml:"record"`
+	Leader        string         `xml:"leader"`
+	ControlFields []ControlField `xml:"controlfield"`
+	DataFields    []DataField    `xml:"datafield"`
+}
+
+// ControlField is a fixed-length field (tags 001-009) without indicators or
+// subfields.
+type ControlField struct {
+	Tag   string `xml:"tag,attr"`
+	Value string `xml:",chardata"`
+}
+
+// DataField is a variable field with two indicators and a list of subfields.
+type DataField struct {
+	Tag       string     `xml:"tag,attr"`
+	Ind1      string     `xml:"ind1,attr"`
+	Ind2      string     `xml:"ind2,attr"`
+	Subfields []Subfield `xml:"subfield"`
+}
+
+// Subfield is a coded element of a data field.
+type Subfield struct {
+	Code  string `xml:"code,attr"`
+	Value string `xml:",chardata"`
+}
+
+// ControlField returns the value of the first control field with the given
+// tag, or an empty string.
+func (r *Record) ControlField(tag string) string {
+	for _, f := range r.ControlFields {
+		if f.Tag == tag {
+			return f.Value
+		}
+	}
+	return ""
+}
+
+// Fields returns the data fields with any of the given tags, in record order.
+func (r *Record) Fields(tags ...string) []DataField {
+	var fields []DataField
+	for _, f := range r.DataFields {
+		for _, tag := range tags {
+			if f.Tag == tag {
+				fields = append(fields, f)
+			}
+		}
+	}
+	return fields
+}
+
+// Subfield returns the trimmed value of the first subfield with the given
+// code, or an empty string.
+func (f DataField) Subfield(code string) string {
+	for _, s := range f.Subfields {
+		if s.Code == code {
+			return strings.TrimSpace(s.Value)
+		}
+	}
+	return ""
+}
+
+// Values returns the trimmed values of every subfield with the given code,
+// in order.
+func (f DataField) Values(code string) []string {
+	var values []string
+	for _, s := range f.Subfields {
+		if s.Code == code {
+			values = append(values, strings.TrimSpace(s.Value))
+		}
+	}
+	return values
+}
+
+// addControlField appends a control field unless value is empty.
+func (r *Record) addControlField(tag, value string) {
+	if value != "" {
+		r.ControlFields = append(r.ControlFields, ControlField{Tag: tag, Value: value})
+	}
+}
+
+// addDataField appends a data field, dropping empty subfields. Fields
+// without any subfields are not added.
+func (r *Record) addDataField(tag, ind1, ind2 string, subfields ...Subfield) {
+	f := DataField{Tag: tag, Ind1: ind1, Ind2: ind2}
+	for _, s := range subfields {
+		if s.Value != "" {
+			f.Subfields = append(f.Subfields, s)
+		}
+	}
+	if len(f.Subfields) > 0 {
+		r.DataFields = append(r.DataFields, f)
+	}
+}
+
+// NewRecord returns a minimal-level bibliographic record for a publication
+// of work. Authors are the work's linked authors, with translators and other
+// contributors given added entries after the authors; if there are none the
+// work's credited author is used. pub may be nil for works without
+// publications, in which case the record describes the work alone.
+func NewRecord(work *bookid.Work, authors []*bookid.Author, pub *bookid.Publication) *Record {
+	// Leader: new language material, monograph, Unicode, minimal level,
+	// non-ISBD punctuation. Lengths are filled in when writing binary.
+	r := &Record{Leader: "00000nam a2200000 7 4500"}
+
+	var names []string
+	var contributors []*bookid.Author
+	for _, a := range authors {
+		if role := RelatorTerm(a.Role); role == "author" {
+			names = append(names, a.Name)
+		} else {
+			contributors = append(contributors, a)
+		}
+	}
+	if len(names) == 0 && len(contributors) == 0 && work.Author != "" {
+		names = append(names, work.Author)
+	}
+
+	var year int
+	var lang string
+	updated, created := work.UpdatedAt, work.CreatedAt
+	if pub != nil {
+		year, lang = pub.PublishedYear, pub.Language
+		updated, created = pub.UpdatedAt, pub.CreatedAt
+		r.addControlField("001", strconv.FormatInt(pub.ID, 10))
+	} else {
+		r.addControlField("001", "w"+strconv.FormatInt(work.ID, 10))
+	}
+	r.addControlField("003", OrganizationCode)
+	if !updated.IsZero() {
+		r.addControlField("005", updated.UTC().Format("20060102150405")+".0")
+	}
+	r.addControlField("008", fixedData(created.UTC().Format("060102"), year, lang))
+
+	if pub != nil {
+		r.addDataField("010", " ", " ", Subfield{"a", pub.LCCN})
+		r.addDataField("020", " ", " ", Subfield{"a", pub.ISBN13})
+		r.addDataField("020", " ", " ", Subfield{"a", pub.ISBN10})
+		if pub.DOI != "" {
+			r.addDataField("024", "7", " ", Subfield{"a", pub.DOI}, Subfield{"2", "doi"})
+		}
+		if pub.OCLCNumber != "" {
+			r.addDataField("035", " ", " ", Subfield{"a", "(OCoLC)" + pub.OCLCNumber})
+		}
+		// Call numbers assigned by other agencies than LC.
+		r.addDataField("050", " ", "4", Subfield{"a", pub.Classification.LCC})
+		r.addDataField("082", "0", "4", Subfield{"a", pub.Classification.Dewey})
+	}
+
+	mainEntry := "0"
+	if len(names) > 0 {
+		mainEntry = "1"
+		r.addDataField("100", "1", " ", Subfield{"a", invertName(names[0])}, Subfield{"e", "author"}, Subfield{"4", "aut"})
+	}
+
+	title, subtitle, _ := strings.Cut(work.Title, ": ")
+	r.addDataField("245", mainEntry, nonfilingChars(title),
+		Subfield{"a", title},
+		Subfield{"b", subtitle},
+		Subfield{"c", work.Author},
+	)
+
+	if pub != nil {
+		var date string
+		if pub.PublishedYear != 0 {
+			date = strconv.Itoa(pub.PublishedYear)
+		}
+		r.addDataField("264", " ", "1", Subfield{"b", pub.Publisher}, Subfield{"c", date})
+		r.addDataField("300", " ", " ", Subfield{"a", extent(pub.PageCount)}, Subfield{"c", height(pub.Dimensions.Height)})
+		r.addDataField("505", "0", " ", Subfield{"a", strings.Join(pub.TableOfContents, " -- ")})
+		r.addDataField("520", " ", " ", Subfield{"a", pub.Description})
+	}
+
+	for _, name := range names[min(1, len(names)):] {
+		r.addDataField("700", "1", " ", Subfield{"a", invertName(name)}, Subfield{"e", "author"}, Subfield{"4", "aut"})
+	}
+	for _, a := range contributors {
+		r.addDataField("700", "1", " ", Subfield{"a", invertName(a.Name)}, Subfield{"e", RelatorTerm(a.Role)}, Subfield{"4", RelatorCode(a.Role)})
+	}
+
+	if pub != nil && pub.ThumbnailURL != "" {
+		r.addDataField("856", "4", "2", Subfield{"3", "Cover image"}, Subfield{"u", pub.ThumbnailURL})
+	}
+	return r
+}
+
+// AddSubjects adds the subjects of the record's work: a subject category
+// code (072) for each subject with a BISAC code and an uncontrolled index
+// term (653) for each subject. Data fields are kept in tag order.
+func (r *Record) AddSubjects(subjects []*bookid.Subject) {
+	for _, s := range subjects {
+		if len(s.BISACCode) > 3 {
+			r.addDataField("072", " ", "7", Subfield{"a", s.BISACCode[:3]}, Subfield{"x", s.BISACCode[3:]}, Subfield{"2", "bisacsh"})
+		}
+	}
+	for _, s := range subjects {
+		r.addDataField("653", " ", " ", Subfield{"a", s.Name})
+	}
+	slices.SortStableFunc(r.DataFields, func(a, b DataField) int { return strings.Compare(a.Tag, b.Tag) })
+}
+
+// RelatorTerm returns the MARC relator term for a contributor role, which is
+// "author" if the role is empty.
+func RelatorTerm(role bookid.ContributorRole) string {
+	if role == "" {
+		return string(bookid.ContributorRoleAuthor)
+	}
+	// The role names are the relator terms.
+	return string(role)
+}
+
+// relatorCodes maps contributor roles to MARC relator codes.
+var relatorCodes = map[bookid.ContributorRole]string{
+	bookid.ContributorRoleAuthor:      "aut",
+	bookid.ContributorRoleTranslator:  "trl",
+	bookid.ContributorRoleEditor:      "edt",
+	bookid.ContributorRoleIllustrator: "ill",
+	bookid.ContributorRoleNarrator:    "nrt",
+}
+
+// RelatorCode returns the MARC relator code for a contributor role, which is
+// "aut" if the role is empty.
+func RelatorCode(role bookid.ContributorRole) string {
+	if role == "" {
+		role = bookid.ContributorRoleAuthor
+	}
+	return relatorCodes[role]
+}
+
+// RelatorRole returns the contributor role named by the relator term
+// (subfield $e) or code (subfield $4) of a name field, or an empty role if
+// neither names a known role.
+func RelatorRole(term, code string) bookid.ContributorRole {
+	for role, c := range relatorCodes {
+		if code == c {
+			return role
+		}
+	}
+	// Terms are often followed by punctuation, e.g. "editor,".
+	term = strings.ToLower(strings.TrimSpace(term))
+	for _, role := range []bookid.ContributorRole{
+		bookid.ContributorRoleAuthor,
+		bookid.ContributorRoleTranslator,
+		bookid.ContributorRoleEditor,
+		bookid.ContributorRoleIllustrator,
+		bookid.ContributorRoleNarrator,
+	} {
+		if strings.HasPrefix(term, string(role)) {
+			return role
+		}
+	}
+	return ""
+}
+
+// fixedData returns the 40 character fixed-length data elements (field 008)
+// of a book record.
+func fixedData(entered string, year int, lang string) string {
+	dateType, date := "n", "uuuu"
+	if year > 0 && year <= 9999 {
+		dateType, date = "s", fmt.Sprintf("%04d", year)
+	}
+	code := "und"
+	if lang != "" {
+		if marc := language.ToMARC(lang); len(marc) == 3 {
+			code = marc
+		}
+	}
+	// Place of publication unknown ("xx "), book-specific elements not coded.
+	return entered + dateType + date + "    " + "xx " + "                 " + code + " d"
+}
+
+// nonfilingChars returns the second indicator of field 245: the number of
+// characters of a leading English article to skip when sorting.
+func nonfilingChars(title string) string {
+	for _, article := range []string{"The ", "An ", "A "} {
+		if strings.HasPrefix(title, article) {
+			return strconv.Itoa(len(article))
+		}
+	}
+	return "0"
+}
+
+// extent formats a page count for field 300 $a, e.g. "180 pages".
+func extent(pages int) string {
+	if pages <= 0 {
+		return ""
+	}
+	return strconv.Itoa(pages) + " pages"
+}
+
+// height formats a height in millimeters for field 300 $c, rounded up to
+// whole centimeters as cataloging rules require, e.g. "21 cm".
+func height(mm float64) string {
+	if mm <= 0 {
+		return ""
+	}
+	return strconv.Itoa(int(math.Ceil(mm/10))) + " cm"
+}
+
+// invertName converts a name in display order such as "F. Scott Fitzgerald"
+// to the "Fitzgerald, F. Scott" form of catalog headings. Names that are
+// already inverted or consist of a single word are returned unchanged.
+func invertName(name string) string {
+	name = strings.TrimSpace(name)
+	if strings.Contains(name, ",") {
+		return name
+	}
+	i := strings.LastIndexFunc(name, unicode.IsSpace)
+	if i < 0 {
+		return name
+	}
+	return name[i+1:] + ", " + strings.TrimSpace(name[:i])
+}
@@ -0,0 +1,226 @@
+package marc_test
+
+import (
+	"bytes"
+	"encoding/xml"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/marc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newGatsby() (*bookid.Work, []*bookid.Author, *bookid.Publication) {
+	created := time.Date(2024, 3, 9, 10, 30, 0, 0, time.UTC)
+	work := &bookid.Work{ID: 7, Title: "The Great Gatsby: A Novel", Author: "F. Scott Fitzgerald", CreatedAt: created, UpdatedAt: created}
+	authors := []*bookid.Author{{ID: 1, Name: "F. Scott Fitzgerald"}, {ID: 2, Name: "Matthew J. Bruccoli"}}
+	pub := &bookid.Publication{
+		ID:              42,
+		WorkID:          7,
+		ISBN10:          "0743273567",
+		ISBN13:          "9780743273565",
+		Publisher:       "Scribner",
+		PublishedYear:   2004,
+		Language:        "en",
+		OCLCNumber:      "54005413",
+		LCCN:            "2004111282",
+		PageCount:       180,
+		Dimensions:      bookid.Dimensions{Height: 203.2, Width: 134.6},
+		Description:     "The story of Jay Gatsby.",
+		TableOfContents: []string{"The great Gatsby", "Explanatory notes"},
+		Classification:  bookid.Classification{Dewey: "813.52", LCC: "PS3511.I9 G7 2004"},
+		CreatedAt:       created,
+		UpdatedAt:       created,
+	}
+	return work, authors, pub
+}
+
+func TestNewRecord(t *testing.T) {
+	t.Parallel()
+
+	t.Run("publication", func(t *testing.T) {
+		t.Parallel()
+		r := marc.NewRecord(newGatsby())
+
+		assert.Equal(t, "42", r.ControlField("001"))
+		assert.Equal(t, marc.OrganizationCode, r.ControlField("003"))
+		assert.Equal(t, "20240309103000.0", r.ControlField("005"))
+		f008 := r.ControlField("008")
+		require.Len(t, f008, 40)
+		assert.Equal(t, "240309s2004", f008[:11])
+		assert.Equal(t, "eng", f008[35:38])
+
+		assert.Equal(t, "2004111282", first(t, r, "010").Subfield("a"))
+		isbns := r.Fields("020")
+		require.Len(t, isbns, 2)
+		assert.Equal(t, "9780743273565", isbns[0].Subfield("a"))
+		assert.Equal(t, "(OCoLC)54005413", first(t, r, "035").Subfield("a"))
+		assert.Equal(t, "PS3511.I9 G7 2004", first(t, r, "050").Subfield("a"))
+		assert.Equal(t, "813.52", first(t, r, "082").Subfield("a"))
+		assert.Equal(t, "Fitzgerald, F. Scott", first(t, r, "100").Subfield("a"))
+
+		title := first(t, r, "245")
+		assert.Equal(t, "1", title.Ind1)
+		assert.Equal(t, "4", title.Ind2, "skips the leading article")
+		assert.Equal(t, "The Great Gatsby", title.Subfield("a"))
+		assert.Equal(t, "A Novel", title.Subfield("b"))
+
+		imprint := first(t, r, "264")
+		assert.Equal(t, "Scribner", imprint.Subfield("b"))
+		assert.Equal(t, "2004", imprint.Subfield("c"))
+		description := first(t, r, "300")
+		assert.Equal(t, "180 pages", description.Subfield("a"))
+		assert.Equal(t, "21 cm", description.Subfield("c"))
+		assert.Equal(t, "The great Gatsby -- Explanatory notes", first(t, r, "505").Subfield("a"))
+		assert.Equal(t, "The story of Jay Gatsby.", first(t, r, "520").Subfield("a"))
+		assert.Equal(t, "Bruccoli, Matthew J.", first(t, r, "700").Subfield("a"))
+		assert.Empty(t, r.Fields("024", "856"))
+	})
+
+	t.Run("work_only", func(t *testing.T) {
+		t.Parallel()
+		work := &bookid.Work{ID: 3, Title: "Solaris", Author: "Stanisław Lem"}
+		r := marc.NewRecord(work, nil, nil)
+
+		assert.Equal(t, "w3", r.ControlField("001"))
+		assert.Equal(t, "nuuuu", r.ControlField("008")[6:11])
+		assert.Equal(t, "und", r.ControlField("008")[35:38])
+		assert.Equal(t, "Lem, Stanisław", first(t, r, "100").Subfield("a"))
+		assert.Equal(t, "0", first(t, r, "245").Ind2)
+		assert.Empty(t, r.Fields("020", "264", "700"))
+	})
+	t.Run("contributors", func(t *testing.T) {
+		t.Parallel()
+		work := &bookid.Work{ID: 5, Title: "Solaris", Author: "Stanisław Lem"}
+		authors := []*bookid.Author{
+			{ID: 1, Name: "Bill Johnston", Role: bookid.ContributorRoleTranslator},
+			{ID: 2, Name: "Stanisław Lem", Role: bookid.ContributorRoleAuthor},
+			{ID: 3, Name: "Daniel Mróz", Role: bookid.ContributorRoleIllustrator},
+		}
+		r := marc.NewRecord(work, authors, nil)
+
+		main := first(t, r, "100")
+		assert.Equal(t, "Lem, Stanisław", main.Subfield("a"))
+		assert.Equal(t, "author", main.Subfield("e"))
+		assert.Equal(t, "aut", main.Subfield("4"))
+		added := r.Fields("700")
+		require.Len(t, added, 2)
+		assert.Equal(t, "Johnston, Bill", added[0].Subfield("a"))
+		assert.Equal(t, "translator", added[0].Subfield("e"))
+		assert.Equal(t, "trl", added[0].Subfield("4"))
+		assert.Equal(t, "Mróz, Daniel", added[1].Subfield("a"))
+		assert.Equal(t, "illustrator", added[1].Subfield("e"))
+		assert.Equal(t, "ill", added[1].Subfield("4"))
+	})
+}
+
+func TestRelatorRole(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		term, code string
+		want       bookid.ContributorRole
+	}{
+		{"translator.", "", bookid.ContributorRoleTranslator},
+		{"", "trl", bookid.ContributorRoleTranslator},
+		{"Illustrator,", "", bookid.ContributorRoleIllustrator},
+		{"", "ill", bookid.ContributorRoleIllustrator},
+		{"author", "edt", bookid.ContributorRoleEditor},
+		{"honoree", "hnr", ""},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, marc.RelatorRole(tt.term, tt.code), "%q %q", tt.term, tt.code)
+	}
+}
+
+func TestRecord_AddSubjects(t *testing.T) {
+	t.Parallel()
+
+	r := marc.NewRecord(newGatsby())
+	r.AddSubjects([]*bookid.Subject{{Name: "fiction", BISACCode: "FIC004000"}, {Name: "wealth"}})
+
+	category := first(t, r, "072")
+	assert.Equal(t, "7", category.Ind2)
+	assert.Equal(t, "FIC", category.Subfield("a"))
+	assert.Equal(t, "004000", category.Subfield("x"))
+	assert.Equal(t, "bisacsh", category.Subfield("2"))
+	terms := r.Fields("653")
+	require.Len(t, terms, 2)
+	assert.Equal(t, "wealth", terms[1].Subfield("a"))
+
+	var tags []string
+	for _, f := range r.DataFields {
+		tags = append(tags, f.Tag)
+	}
+	assert.True(t, slices.IsSorted(tags), "fields stay in tag order")
+}
+
+func TestRecord_MarshalBinary(t *testing.T) {
+	t.Parallel()
+
+	r := &marc.Record{
+		Leader:        "00000nam a2200000 7 4500",
+		ControlFields: []marc.ControlField{{Tag: "001", Value: "42"}},
+		DataFields: []marc.DataField{
+			{Tag: "245", Ind1: "0", Ind2: "0", Subfields: []marc.Subfield{{Code: "a", Value: "Łódź"}}},
+		},
+	}
+	data, err := r.MarshalBinary()
+	require.NoError(t, err)
+
+	// Leader, two directory entries, the directory terminator, "42" and the
+	// 245 field, whose length counts bytes rather than characters.
+	want := "00065nam a2200049 7 4500" +
+		"001000300000" + "245001200003" + "\x1e" +
+		"42\x1e" +
+		"00\x1faŁódź\x1e" +
+		"\x1d"
+	assert.Equal(t, want, string(data))
+	assert.Len(t, data, 65)
+
+	_, err = (&marc.Record{DataFields: []marc.DataField{{Tag: "24"}}}).MarshalBinary()
+	assert.Error(t, err)
+}
+
+func TestXMLWriter(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	w := marc.NewXMLWriter(&buf)
+	require.NoError(t, w.Write(marc.NewRecord(newGatsby())))
+	require.NoError(t, w.Close())
+
+	out := buf.String()
+	assert.True(t, strings.HasPrefix(out, xml.Header+`<collection xmlns="http://www.loc.gov/MARC21/slim">`))
+	assert.Contains(t, out, `<datafield tag="245" ind1="1" ind2="4">`)
+	assert.Contains(t, out, `<subfield code="a">The Great Gatsby</subfield>`)
+
+	// The output reads back into the same record.
+	var doc struct {
+		XMLName xml.Name      `xml:"http://www.loc.gov/MARC21/slim collection"`
+		Records []marc.Record `xml:"record"`
+	}
+	require.NoError(t, xml.Unmarshal(buf.Bytes(), &doc))
+	require.Len(t, doc.Records, 1)
+	assert.Equal(t, "9780743273565", doc.Records[0].Fields("020")[0].Subfield("a"))
+
+	t.Run("empty", func(t *testing.T) {
+		t.Parallel()
+		var buf bytes.Buffer
+		require.NoError(t, marc.NewXMLWriter(&buf).Close())
+		assert.NoError(t, xml.Unmarshal(buf.Bytes(), new(struct{})))
+	})
+}
+
+// first returns the first field with the given tag, failing the test if
+// there is none.
+func first(t *testing.T, r *marc.Record, tag string) marc.DataField {
+	t.Helper()
+	fields := r.Fields(tag)
+	require.NotEmpty(t, fields, tag)
+	return fields[0]
+}
@@ -0,0 +1,149 @@
+package marc
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+)
+
+// ISO 2709 structural characters.
+const (
+	subfieldDelimiter = 0x1f
+	fieldTerminator   = 0x1e
+	recordTerminator  = 0x1d
+)
+
+// maxRecordLength is the largest record ISO 2709 can describe, as lengths
+// and offsets are written with five digits.
+const maxRecordLength = 99999
+
+// Writer writes records in ISO 2709 binary format.
+type Writer struct {
+	w io.Writer
+}
+
+// NewWriter returns a new Writer that writes to w.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: w}
+}
+
+// Write writes a single record.
+func (w *Writer) Write(r *Record) error {
+	data, err := r.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	_, err = w.w.Write(data)
+	return err
+}
+
+// MarshalBinary encodes the record in ISO 2709 format. The record length and
+// base address of data in the leader are computed from the fields.
+func (r *Record) MarshalBinary() ([]byte, error) {
+	var directory, data bytes.Buffer
+	addField := func(tag string, body []byte) error {
+		if len(tag) != 3 {
+			return fmt.Errorf("marc: invalid tag %q", tag)
+		}
+		fmt.Fprintf(&directory, "%s%04d%05d", tag, len(body)+1, data.Len())
+		data.Write(body)
+		data.WriteByte(fieldTerminator)
+		return nil
+	}
+
+	for _, f := range r.ControlFields {
+		if err := addField(f.Tag, []byte(f.Value)); err != nil {
+			return nil, err
+		}
+	}
+	for _, f := range r.DataFields {
+		var body bytes.Buffer
+		body.WriteString(indicator(f.Ind1) + indicator(f.Ind2))
+		for _, s := range f.Subfields {
+			body.WriteByte(subfieldDelimiter)
+			body.WriteString(s.Code)
+			body.WriteString(s.Value)
+		}
+		if err := addField(f.Tag, body.Bytes()); err != nil {
+			return nil, err
+		}
+	}
+	directory.WriteByte(fieldTerminator)
+
+	leader := []byte(fmt.Sprintf("%-24s", r.Leader))[:24]
+	base := len(leader) + directory.Len()
+	length := base + data.Len() + 1
+	if length > maxRecordLength {
+		return nil, fmt.Errorf("marc: record length %d exceeds %d", length, maxRecordLength)
+	}
+	copy(leader[0:5], fmt.Sprintf("%05d", length))
+	copy(leader[12:17], fmt.Sprintf("%05d", base))
+
+	buf := make([]byte, 0, length)
+	buf = append(buf, leader...)
+	buf = append(buf, directory.Bytes()...)
+	buf = append(buf, data.Bytes()...)
+	return append(buf, recordTerminator), nil
+}
+
+// indicator returns ind as a single character, blank if unset.
+func indicator(ind string) string {
+	if len(ind) != 1 {
+		return " "
+	}
+	return ind
+}
+
+// XMLWriter writes records as a MARCXML collection.
+type XMLWriter struct {
+	w           io.Writer
+	enc         *xml.Encoder
+	wroteHeader bool
+	wroteRecord bool
+}
+
+// NewXMLWriter returns a new XMLWriter that writes to w.
+func NewXMLWriter(w io.Writer) *XMLWriter {
+	enc := xml.NewEncoder(w)
+	enc.Indent("  ", "  ")
+	return &XMLWriter{w: w, enc: enc}
+}
+
+// Write writes a single record, preceded by the start of the collection on
+// first use.
+func (w *XMLWriter) Write(r *Record) error {
+	if err := w.writeHeader(); err != nil {
+		return err
+	}
+	w.wroteRecord = true
+	return w.enc.Encode(r)
+}
+
+// Close writes the end of the collection. If no records were written, an
+// empty collection is still emitted so the document is well-formed. Close
+// does not close the underlying writer.
+func (w *XMLWriter) Close() error {
+	if err := w.writeHeader(); err != nil {
+		return err
+	} else if err := w.enc.Flush(); err != nil {
+		return err
+	}
+	end := "</collection>\n"
+	if w.wroteRecord {
+		end = "\n" + end
+	}
+	_, err := io.WriteString(w.w, end)
+	return err
+}
+
+// writeHeader writes the XML declaration and the start of the collection
+// once. Records inherit the collection's default namespace.
+func (w *XMLWriter) writeHeader() error {
+	if w.wroteHeader {
+		return nil
+	}
+	w.wroteHeader = true
+	_, err := io.WriteString(w.w, xml.Header+`<collection xmlns="`+Namespace+`">`+"\n")
+	return err
+}
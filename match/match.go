@@ -0,0 +1,172 @@
+// Package match scores how well book results match the query that produced
+// them and re-ranks results accordingly.
+package match
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// fuzzyThreshold is the minimum similarity for two tokens to be considered
+// the same word, tolerating typos such as "gatsbi" for "gatsby".
+const fuzzyThreshold = 0.8
+
+// Normalize lowercases s, strips diacritics, transliterates Cyrillic and
+// Greek letters to Latin ones and replaces punctuation with spaces so that
+// "The Great Gatsby!" and "the great gatsby", "Michał" and "Michal" or
+// "Фёдор" and "Fyodor" compare equal.
+func Normalize(s string) string {
+	var b strings.Builder
+	for _, r := range norm.NFKD.String(Transliterate(s)) {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+			// Drop combining marks left over from decomposition.
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			b.WriteRune(foldLetter(unicode.ToLower(r)))
+		default:
+			b.WriteRune(' ')
+		}
+	}
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+// foldLetter maps lowercase letters whose diacritics have no Unicode
+// decomposition, such as the Polish "ł", to their base letter.
+func foldLetter(r rune) rune {
+	switch r {
+	case 'ł':
+		return 'l'
+	case 'ø':
+		return 'o'
+	case 'đ':
+		return 'd'
+	}
+	return r
+}
+
+// Tokens returns the normalized words of s without articles and other
+// stop words, unless s consists only of stop words.
+func Tokens(s string) []string {
+	words := strings.Fields(Normalize(s))
+	tokens := make([]string, 0, len(words))
+	for _, w := range words {
+		if !isStopWord(w) {
+			tokens = append(tokens, w)
+		}
+	}
+	if len(tokens) == 0 {
+		return words
+	}
+	return tokens
+}
+
+// isStopWord reports whether w is an article or conjunction that carries
+// no meaning when comparing titles.
+func isStopWord(w string) bool {
+	switch w {
+	case "a", "an", "and", "of", "the", "le", "la", "les", "der", "die", "das", "el", "il":
+		return true
+	}
+	return false
+}
+
+// Levenshtein returns the edit distance between a and b in runes.
+func Levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
+
+// LevenshteinSimilarity returns the edit distance between the normalized
+// forms of a and b scaled to 0.0 (nothing in common) to 1.0 (equal).
+func LevenshteinSimilarity(a, b string) float64 {
+	a, b = Normalize(a), Normalize(b)
+	n := max(len([]rune(a)), len([]rune(b)))
+	if n == 0 {
+		return 1
+	}
+	return 1 - float64(Levenshtein(a, b))/float64(n)
+}
+
+// TokenSetSimilarity compares the sets of words in a and b, ignoring order
+// and repetition, as the share of words of the smaller set found in the
+// larger one. Words match if their similarity is at least 0.8.
+func TokenSetSimilarity(a, b string) float64 {
+	ta, tb := Tokens(a), Tokens(b)
+	if len(ta) == 0 || len(tb) == 0 {
+		if len(ta) == len(tb) {
+			return 1
+		}
+		return 0
+	}
+	if len(ta) > len(tb) {
+		ta, tb = tb, ta
+	}
+	return Coverage(ta, tb)
+}
+
+// Similarity returns the better of the Levenshtein and token-set
+// similarities of a and b.
+func Similarity(a, b string) float64 {
+	return max(LevenshteinSimilarity(a, b), TokenSetSimilarity(a, b))
+}
+
+// Coverage returns the share of distinct tokens in want that fuzzily match a
+// token in have. An empty want is fully covered.
+func Coverage(want, have []string) float64 {
+	seen := make(map[string]bool, len(want))
+	var n, found int
+	for _, w := range want {
+		if seen[w] {
+			continue
+		}
+		seen[w] = true
+		n++
+		if containsToken(have, w) {
+			found++
+		}
+	}
+	if n == 0 {
+		return 1
+	}
+	return float64(found) / float64(n)
+}
+
+// containsToken reports whether tokens contains a word similar to w.
+func containsToken(tokens []string, w string) bool {
+	for _, t := range tokens {
+		if t == w {
+			return true
+		}
+		if n := max(len([]rune(t)), len([]rune(w))); 1-float64(Levenshtein(t, w))/float64(n) >= fuzzyThreshold {
+			return true
+		}
+	}
+	return false
+}
+
+// SameName reports whether two person names are likely spellings of the same
+// name: their normalized words match one to one in any order, tolerating
+// small differences such as those between transliterations. "Lem,
+// Stanisław", "Stanislaw Lem" and "Станислав Лем" are the same name.
+func SameName(a, b string) bool {
+	wa, wb := strings.Fields(Normalize(a)), strings.Fields(Normalize(b))
+	return len(wa) > 0 && len(wa) == len(wb) && Coverage(wa, wb) == 1 && Coverage(wb, wa) == 1
+}
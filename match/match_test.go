@@ -0,0 +1,127 @@
+package match_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/match"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalize(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "the great gatsby", match.Normalize("  The Great   Gatsby! "))
+	assert.Equal(t, "stanislaw lem", match.Normalize("Stanislaw Lém"))
+	assert.Equal(t, "lodz", match.Normalize("Łódź"))
+	assert.Equal(t, "l etranger", match.Normalize("L'Étranger"))
+	assert.Equal(t, "voyna i mir", match.Normalize("Война и мир"))
+	assert.Equal(t, "strasse", match.Normalize("Straße"))
+}
+
+func TestTransliterate(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "fyodor dostoevskiy", match.Transliterate("Фёдор Достоевский"))
+	assert.Equal(t, "kobzar", match.Transliterate("Кобзар"))
+	assert.Equal(t, "odysseia", match.Transliterate("Οδύσσεια"))
+	assert.Equal(t, "aesop", match.Transliterate("Æsop"))
+	assert.Equal(t, "Michał", match.Transliterate("Michał"))
+}
+
+func TestSameName(t *testing.T) {
+	t.Parallel()
+	assert.True(t, match.SameName("Michal Ajvaz", "Michał Ajvaz"))
+	assert.True(t, match.SameName("Lem, Stanisław", "Станислав Лем"))
+	assert.True(t, match.SameName("Fyodor Dostoyevsky", "Фёдор Достоевский"))
+	assert.False(t, match.SameName("Frank Herbert", "Brian Herbert"))
+	assert.False(t, match.SameName("Herbert", "Frank Herbert"))
+	assert.False(t, match.SameName("", ""))
+}
+
+func TestLevenshtein(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, 0, match.Levenshtein("dune", "dune"))
+	assert.Equal(t, 3, match.Levenshtein("kitten", "sitting"))
+	assert.Equal(t, 4, match.Levenshtein("", "dune"))
+	assert.Equal(t, 1, match.Levenshtein("żółw", "zółw"))
+}
+
+func TestTokens(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, []string{"great", "gatsby"}, match.Tokens("The Great Gatsby"))
+	assert.Equal(t, []string{"the", "the"}, match.Tokens("The The"))
+}
+
+func TestSimilarity(t *testing.T) {
+	t.Parallel()
+	assert.InDelta(t, 1.0, match.Similarity("The Great Gatsby", "great gatsby, the"), 0.001)
+	assert.InDelta(t, 1.0, match.Similarity("Gatsbi", "gatsby"), 0.2)
+	assert.Less(t, match.Similarity("Dune", "The Great Gatsby"), 0.3)
+	assert.InDelta(t, 1.0, match.TokenSetSimilarity("Dune", "Dune Messiah"), 0.001)
+}
+
+func TestScore(t *testing.T) {
+	t.Parallel()
+	gatsby := bookid.BookResult{Title: "The Great Gatsby", Authors: []string{"F. Scott Fitzgerald"}}
+	guide := bookid.BookResult{Title: "Study Guide: The Great Gatsby", Authors: []string{"SparkNotes"}}
+	girl := bookid.BookResult{Title: "Gatsby's Girl", Authors: []string{"Caroline Preston"}}
+
+	assert.InDelta(t, 1.0, match.Score("the great gatsby", gatsby), 0.001)
+	assert.InDelta(t, 1.0, match.Score("great gatsby fitzgerald", gatsby), 0.001)
+	assert.Greater(t, match.Score("the great gatsby", gatsby), match.Score("the great gatsby", guide))
+	assert.Greater(t, match.Score("the great gatsby", guide), match.Score("the great gatsby", girl))
+	assert.Zero(t, match.Score("", gatsby))
+}
+
+func TestRerank(t *testing.T) {
+	t.Parallel()
+
+	results := []bookid.BookResult{
+		{Title: "Gatsby's Girl", Authors: []string{"Caroline Preston"}, Confidence: 0.7},
+		{Title: "The Great Gatsby", Authors: []string{"F. Scott Fitzgerald"}, Confidence: 0.7},
+	}
+	got := match.Rerank("the great gatsby", results)
+	require.Len(t, got, 2)
+	assert.Equal(t, "The Great Gatsby", got[0].Title)
+	assert.InDelta(t, 0.7, got[1].Confidence, 0.001, "confidence must not change")
+	assert.Equal(t, "Gatsby's Girl", results[0].Title, "input must not be reordered")
+
+	// Confidence takes precedence over the match score.
+	results[0].Confidence = 0.8
+	got = match.Rerank("the great gatsby", results)
+	assert.Equal(t, "Gatsby's Girl", got[0].Title)
+
+	// ISBN searches are left alone.
+	isbnResults := []bookid.BookResult{{Title: "Unrelated", Confidence: 0.95, SearchType: bookid.SearchTypeISBN}}
+	assert.Equal(t, isbnResults, match.Rerank("the great gatsby", isbnResults))
+}
+
+// stubFinder returns fixed results and records the options it was called with.
+type stubFinder struct {
+	results []bookid.BookResult
+	opts    bookid.SearchOptions
+}
+
+func (f *stubFinder) Search(_ context.Context, _ string, opts bookid.SearchOptions) ([]bookid.BookResult, error) {
+	f.opts = opts
+	return f.results, nil
+}
+
+func TestFinder_Search(t *testing.T) {
+	t.Parallel()
+
+	stub := &stubFinder{results: []bookid.BookResult{
+		{Title: "SparkNotes: Dune", Confidence: 0.7},
+		{Title: "Dune", Authors: []string{"Frank Herbert"}, Confidence: 0.7},
+	}}
+	got, err := match.NewFinder(stub).Search(context.Background(), "dune herbert", bookid.SearchOptions{MaxResults: 1, MinConfidence: 0.5})
+	require.NoError(t, err)
+
+	// The wrapped finder is asked for a full page without a threshold.
+	assert.Equal(t, 10, stub.opts.MaxResults)
+	assert.Zero(t, stub.opts.MinConfidence)
+
+	require.Len(t, got, 1)
+	assert.Equal(t, "Dune", got[0].Title)
+}
@@ -0,0 +1,126 @@
+package match
+
+import (
+	"cmp"
+	"context"
+	"slices"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/isbn"
+)
+
+// Weights of the components of Score.
+const (
+	titleWeight  = 0.6
+	authorWeight = 0.2
+	queryWeight  = 0.2
+)
+
+// minPageSize is the number of results requested from the wrapped finder
+// so there is something to re-rank even when the caller wants fewer.
+const minPageSize = 10
+
+// Score returns how well result matches a free-text query, from 0.0 to 1.0.
+// Queries usually mix title and author words ("dune herbert"), so it combines
+// how much of the title appears in the query, whether an author is named in
+// it, and how much of the query is explained by the title and authors
+// together.
+func Score(query string, result bookid.BookResult) float64 {
+	q := Tokens(query)
+	if len(q) == 0 {
+		return 0
+	}
+
+	title := Tokens(result.Title)
+	titleScore := Coverage(title, q)
+	if len(title) == 0 {
+		titleScore = 0
+	}
+
+	known := title
+	var authorScore float64
+	for _, author := range result.Authors {
+		tokens := Tokens(author)
+		known = append(known, tokens...)
+		if NamesAuthor(q, tokens) {
+			authorScore = 1
+		}
+	}
+
+	// Don't penalize title-only queries for not naming the author.
+	queryScore := Coverage(q, known)
+	if Coverage(q, title) == 1 {
+		authorScore = 1
+	}
+
+	return titleWeight*titleScore + authorWeight*authorScore + queryWeight*queryScore
+}
+
+// NamesAuthor reports whether the query tokens contain any of the author's
+// name tokens, ignoring initials.
+func NamesAuthor(query, author []string) bool {
+	for _, t := range author {
+		if len([]rune(t)) > 2 && containsToken(query, t) {
+			return true
+		}
+	}
+	return false
+}
+
+// Rerank returns a copy of results ordered by confidence, breaking ties by
+// how well each result matches query so that equally scored results from
+// different providers end up in a sensible order. Results of ISBN searches
+// are returned unchanged as the identifier already pins down the book.
+func Rerank(query string, results []bookid.BookResult) []bookid.BookResult {
+	if isbn.Valid(query) {
+		return slices.Clone(results)
+	}
+
+	type scored struct {
+		result bookid.BookResult
+		score  float64
+	}
+	ranked := make([]scored, len(results))
+	for i, r := range results {
+		ranked[i] = scored{result: r, score: Score(query, r)}
+	}
+	slices.SortStableFunc(ranked, func(a, b scored) int {
+		if c := cmp.Compare(b.result.Confidence, a.result.Confidence); c != 0 {
+			return c
+		}
+		return cmp.Compare(b.score, a.score)
+	})
+
+	other := make([]bookid.BookResult, len(ranked))
+	for i := range ranked {
+		other[i] = ranked[i].result
+	}
+	return other
+}
+
+// Ensure type implements interface.
+var _ bookid.BookFinder = (*Finder)(nil)
+
+// Finder wraps a BookFinder and re-ranks its results against the query.
+type Finder struct {
+	finder bookid.BookFinder
+}
+
+// NewFinder returns a Finder re-ranking the results of finder.
+func NewFinder(finder bookid.BookFinder) *Finder {
+	return &Finder{finder: finder}
+}
+
+// Search requests at least a full page from the wrapped finder, re-ranks the
+// results and only then applies the caller's limit and confidence threshold.
+func (f *Finder) Search(ctx context.Context, query string, opts bookid.SearchOptions) ([]bookid.BookResult, error) {
+	inner := opts
+	inner.MaxResults = max(opts.MaxResults, minPageSize)
+	inner.MinConfidence = 0
+
+	results, err := f.finder.Search(ctx, query, inner)
+	if err != nil {
+		return nil, err
+	}
+	return opts.Apply(Rerank(query, results)), nil
+}
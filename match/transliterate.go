@@ -0,0 +1,87 @@
+package match
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Transliterate replaces the Cyrillic and Greek letters of s with lowercase
+// Latin ones and spells out ligatures such as "ß" and "æ", so that names and
+// titles can be compared across scripts. The romanization is a simple
+// phonetic one close to common English spellings, e.g. "Достоевский" becomes
+// "dostoevskiy"; other characters are kept.
+func Transliterate(s string) string {
+	var b strings.Builder
+	for _, r := range norm.NFC.String(s) {
+		if latin, ok := romanize(unicode.ToLower(r)); ok {
+			b.WriteString(latin)
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// romanize returns the Latin spelling of a lowercase Cyrillic or Greek letter
+// or a ligature. Accented Greek letters are spelled as their base letter.
+func romanize(r rune) (string, bool) {
+	cyrillic := [...]string{
+		"a", "b", "v", "g", "d", "e", "zh", "z", "i", "y", "k", "l", "m", "n", "o", "p", // а-п
+		"r", "s", "t", "u", "f", "kh", "ts", "ch", "sh", "shch", "", "y", "", "e", "yu", "ya", // р-я
+	}
+	greek := [...]string{
+		"a", "v", "g", "d", "e", "z", "i", "th", "i", "k", "l", "m", "n", "x", "o", "p", // α-π
+		"r", "s", "s", "t", "y", "f", "ch", "ps", "o", // ρ-ω, with final sigma
+	}
+
+	switch {
+	case r >= 'а' && r <= 'я':
+		return cyrillic[r-'а'], true
+	case r >= 'α' && r <= 'ω':
+		return greek[r-'α'], true
+	case unicode.Is(unicode.Greek, r):
+		if base := []rune(norm.NFD.String(string(r)))[0]; base != r && base >= 'α' && base <= 'ω' {
+			return greek[base-'α'], true
+		}
+	}
+
+	switch r {
+	case 'ё':
+		return "yo", true
+	case 'і':
+		return "i", true
+	case 'ї':
+		return "yi", true
+	case 'є':
+		return "ye", true
+	case 'ґ':
+		return "g", true
+	case 'ў':
+		return "u", true
+	case 'ј':
+		return "j", true
+	case 'љ':
+		return "lj", true
+	case 'њ':
+		return "nj", true
+	case 'ђ':
+		return "d", true
+	case 'ћ':
+		return "c", true
+	case 'џ':
+		return "dz", true
+	case 'ß':
+		return "ss", true
+	case 'æ':
+		return "ae", true
+	case 'œ':
+		return "oe", true
+	case 'þ':
+		return "th", true
+	case 'ð':
+		return "d", true
+	}
+	return "", false
+}
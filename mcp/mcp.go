@@ -0,0 +1,292 @@
+// Package mcp exposes the book finder and the catalog as Model Context
+// Protocol tools, so that LLM agents can identify books and catalog them.
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"runtime/debug"
+
+	"github.com/fwojciec/bookid"
+	mcpsdk "github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// DefaultMaxResults is the number of results returned by identify_book when
+// the caller does not limit them.
+const DefaultMaxResults = 5
+
+// DefaultLimit is the number of works returned by search_catalog when the
+// caller does not limit them.
+const DefaultLimit = 20
+
+// Server represents an MCP server. It is meant to wrap all MCP functionality
+// used by the application so that dependent packages (such as cmd/bookid) do
+// not need to reference the MCP SDK at all.
+type Server struct {
+	server *mcpsdk.Server
+
+	// Services used by the tools.
+	BookFinder           bookid.BookFinder
+	CatalogService       bookid.CatalogService
+	CatalogSearchService bookid.CatalogSearchService
+}
+
+// NewServer returns a new instance of Server.
+func NewServer() *Server {
+	s := &Server{
+		server: mcpsdk.NewServer(&mcpsdk.Implementation{Name: "bookid", Version: version()}, nil),
+	}
+
+	mcpsdk.AddTool(s.server, &mcpsdk.Tool{
+		Name: "identify_book",
+		Description: "Identifies a book from an ISBN, DOI, LCCN, ASIN, or a title and author, " +
+			"returning matching editions best match first with a confidence from 0 to 1.",
+	}, s.identifyBook)
+	mcpsdk.AddTool(s.server, &mcpsdk.Tool{
+		Name: "save_book",
+		Description: "Saves a book, such as a result of identify_book, to the catalog as a " +
+			"publication of a work. Saving a cataloged publication refreshes it, and a new " +
+			"edition of a cataloged work is added to that work.",
+	}, s.saveBook)
+	mcpsdk.AddTool(s.server, &mcpsdk.Tool{
+		Name: "search_catalog",
+		Description: "Searches the catalog for works whose title, authors or publications " +
+			`contain every term of the query. Terms ending in "*" match as prefixes.`,
+	}, s.searchCatalog)
+
+	return s
+}
+
+// Run serves the tools over stdin and stdout until the client disconnects or
+// ctx is canceled.
+func (s *Server) Run(ctx context.Context) error {
+	return s.server.Run(ctx, &mcpsdk.StdioTransport{})
+}
+
+// Serve serves the tools over t until the client disconnects or ctx is
+// canceled. It is useful in tests with an in-memory transport.
+func (s *Server) Serve(ctx context.Context, t mcpsdk.Transport) error {
+	return s.server.Run(ctx, t)
+}
+
+// Error returns err as a tool error, reported to the agent along with its
+// bookid error code. Internal errors are logged; their details are not shown
+// to the agent.
+func Error(tool string, err error) error {
+	code, message := bookid.ErrorCode(err), bookid.ErrorMessage(err)
+	if code == bookid.EINTERNAL {
+		log.Printf("[mcp] error: %s: %s", tool, err)
+	}
+	return fmt.Errorf("%s: %s", code, message)
+}
+
+// Book is a book identified by a provider, as passed to and from the tools.
+type Book struct {
+	Title               string                 `json:"title"`
+	Authors             []string               `json:"authors"`
+	ISBN10              string                 `json:"isbn10,omitempty"`
+	ISBN13              string                 `json:"isbn13,omitempty"`
+	Publisher           string                 `json:"publisher,omitempty"`
+	PublishedYear       int                    `json:"published_year,omitempty"`
+	Language            string                 `json:"language,omitempty" jsonschema:"ISO 639-1 language code, e.g. en"`
+	Binding             string                 `json:"binding,omitempty" jsonschema:"hardcover, paperback, ebook or audiobook"`
+	PageCount           int                    `json:"page_count,omitempty"`
+	DurationMinutes     int                    `json:"duration_minutes,omitempty" jsonschema:"running time of audiobooks"`
+	Dimensions          bookid.Dimensions      `json:"dimensions,omitzero" jsonschema:"height, width and thickness in millimeters and weight in grams"`
+	GoogleBooksVolumeID string                 `json:"google_books_volume_id,omitempty"`
+	OCLCNumber          string                 `json:"oclc_number,omitempty" jsonschema:"WorldCat record number"`
+	LCCN                string                 `json:"lccn,omitempty" jsonschema:"Library of Congress Control Number"`
+	DOI                 string                 `json:"doi,omitempty"`
+	ASIN                string                 `json:"asin,omitempty" jsonschema:"Amazon Standard Identification Number, e.g. of an Audible audiobook"`
+	ThumbnailURL        string                 `json:"thumbnail_url,omitempty"`
+	Description         string                 `json:"description,omitempty" jsonschema:"summary of the book as plain text"`
+	TableOfContents     []string               `json:"table_of_contents,omitempty" jsonschema:"titles of the chapters or parts of the book"`
+	Sale                *bookid.SaleInfo       `json:"sale,omitempty" jsonschema:"whether and for how much the provider sells the book"`
+	Access              *bookid.AccessInfo     `json:"access,omitempty" jsonschema:"digital editions the provider offers, and whether the book is in the public domain"`
+	Classification      *bookid.Classification `json:"classification,omitempty" jsonschema:"Dewey Decimal and Library of Congress classification numbers, for shelving"`
+	Provider            string                 `json:"provider,omitempty" jsonschema:"name of the provider that identified the book"`
+	Metadata            map[string]string      `json:"metadata,omitempty" jsonschema:"provider-specific details, e.g. edition or list price"`
+	Confidence          float64                `json:"confidence,omitempty" jsonschema:"confidence that the book is the queried one, from 0 to 1"`
+	SearchType          string                 `json:"search_type,omitempty" jsonschema:"kind of search the query was identified as, e.g. isbn or title"`
+}
+
+// identifyBookInput represents the arguments of identify_book.
+type identifyBookInput struct {
+	Query         string  `json:"query" jsonschema:"ISBN, DOI, LCCN, ASIN, or title and author of the book"`
+	MaxResults    int     `json:"max_results,omitempty" jsonschema:"maximum number of results, 5 by default"`
+	MinConfidence float64 `json:"min_confidence,omitempty" jsonschema:"drop results with a lower confidence, from 0 to 1"`
+}
+
+// identifyBookOutput represents the result of identify_book.
+type identifyBookOutput struct {
+	Books []Book `json:"books"`
+}
+
+// identifyBook implements the identify_book tool.
+func (s *Server) identifyBook(ctx context.Context, _ *mcpsdk.CallToolRequest, in identifyBookInput) (*mcpsdk.CallToolResult, identifyBookOutput, error) {
+	if in.Query == "" {
+		return nil, identifyBookOutput{}, Error("identify_book", bookid.Errorf(bookid.EINVALID, "Query required."))
+	} else if in.MaxResults < 0 {
+		return nil, identifyBookOutput{}, Error("identify_book", bookid.Errorf(bookid.EINVALID, "Invalid max results."))
+	}
+
+	opts := bookid.SearchOptions{MaxResults: in.MaxResults, MinConfidence: in.MinConfidence}
+	if opts.MaxResults == 0 {
+		opts.MaxResults = DefaultMaxResults
+	}
+	if err := opts.Validate(); err != nil {
+		return nil, identifyBookOutput{}, Error("identify_book", err)
+	}
+
+	results, err := s.BookFinder.Search(ctx, in.Query, opts)
+	if err != nil {
+		return nil, identifyBookOutput{}, Error("identify_book", err)
+	}
+
+	out := identifyBookOutput{Books: make([]Book, len(results))}
+	for i := range results {
+		out.Books[i] = marshalBook(&results[i])
+	}
+	return nil, out, nil
+}
+
+// saveBookInput represents the arguments of save_book.
+type saveBookInput struct {
+	Book Book `json:"book" jsonschema:"book to save, such as a result of identify_book"`
+}
+
+// saveBookOutput represents the result of save_book.
+type saveBookOutput struct {
+	WorkID        int64 `json:"work_id"`
+	PublicationID int64 `json:"publication_id"`
+}
+
+// saveBook implements the save_book tool.
+func (s *Server) saveBook(ctx context.Context, _ *mcpsdk.CallToolRequest, in saveBookInput) (*mcpsdk.CallToolResult, saveBookOutput, error) {
+	workID, pubID, err := s.CatalogService.SaveResult(ctx, unmarshalBook(&in.Book))
+	if err != nil {
+		return nil, saveBookOutput{}, Error("save_book", err)
+	}
+	return nil, saveBookOutput{WorkID: workID, PublicationID: pubID}, nil
+}
+
+// searchCatalogInput represents the arguments of search_catalog.
+type searchCatalogInput struct {
+	Query  string `json:"query" jsonschema:"terms to search for in titles, authors and publications"`
+	Offset int    `json:"offset,omitempty" jsonschema:"number of works to skip, for paging"`
+	Limit  int    `json:"limit,omitempty" jsonschema:"maximum number of works, 20 by default"`
+}
+
+// searchCatalogOutput represents the result of search_catalog.
+type searchCatalogOutput struct {
+	Works []Work `json:"works"`
+	Total int    `json:"total" jsonschema:"number of matching works, ignoring offset and limit"`
+}
+
+// Work is a work of the catalog, as returned by search_catalog.
+type Work struct {
+	ID     int64  `json:"id"`
+	Title  string `json:"title"`
+	Author string `json:"author"`
+}
+
+// searchCatalog implements the search_catalog tool.
+func (s *Server) searchCatalog(ctx context.Context, _ *mcpsdk.CallToolRequest, in searchCatalogInput) (*mcpsdk.CallToolResult, searchCatalogOutput, error) {
+	if in.Offset < 0 || in.Limit < 0 {
+		return nil, searchCatalogOutput{}, Error("search_catalog", bookid.Errorf(bookid.EINVALID, "Offset and limit must not be negative."))
+	}
+
+	filter := bookid.CatalogSearchFilter{Query: in.Query, Offset: in.Offset, Limit: in.Limit}
+	if filter.Limit == 0 {
+		filter.Limit = DefaultLimit
+	}
+	works, n, err := s.CatalogSearchService.SearchCatalog(ctx, filter)
+	if err != nil {
+		return nil, searchCatalogOutput{}, Error("search_catalog", err)
+	}
+
+	out := searchCatalogOutput{Works: make([]Work, len(works)), Total: n}
+	for i, work := range works {
+		out.Works[i] = Work{ID: work.ID, Title: work.Title, Author: work.Author}
+	}
+	return nil, out, nil
+}
+
+// marshalBook converts a search result to a tool book. Raw provider
+// responses are not included.
+func marshalBook(r *bookid.BookResult) Book {
+	authors := r.Authors
+	if authors == nil {
+		authors = []string{}
+	}
+	return Book{
+		Title:               r.Title,
+		Authors:             authors,
+		ISBN10:              r.ISBN10,
+		ISBN13:              r.ISBN13,
+		Publisher:           r.Publisher,
+		PublishedYear:       r.PublishedYear,
+		Language:            r.Language,
+		Binding:             string(r.Binding),
+		PageCount:           r.PageCount,
+		DurationMinutes:     r.DurationMinutes,
+		Dimensions:          r.Dimensions,
+		GoogleBooksVolumeID: r.GoogleBooksVolumeID,
+		OCLCNumber:          r.OCLCNumber,
+		LCCN:                r.LCCN,
+		DOI:                 r.DOI,
+		ASIN:                r.ASIN,
+		ThumbnailURL:        r.ThumbnailURL,
+		Description:         r.Description,
+		TableOfContents:     r.TableOfContents,
+		Sale:                r.Sale,
+		Access:              r.Access,
+		Classification:      r.Classification,
+		Provider:            r.Provider,
+		Metadata:            r.Metadata,
+		Confidence:          r.Confidence,
+		SearchType:          string(r.SearchType),
+	}
+}
+
+// unmarshalBook converts a tool book to a search result.
+func unmarshalBook(b *Book) bookid.BookResult {
+	return bookid.BookResult{
+		Title:               b.Title,
+		Authors:             b.Authors,
+		ISBN10:              b.ISBN10,
+		ISBN13:              b.ISBN13,
+		Publisher:           b.Publisher,
+		PublishedYear:       b.PublishedYear,
+		Language:            b.Language,
+		Binding:             bookid.Binding(b.Binding),
+		PageCount:           b.PageCount,
+		DurationMinutes:     b.DurationMinutes,
+		Dimensions:          b.Dimensions,
+		GoogleBooksVolumeID: b.GoogleBooksVolumeID,
+		OCLCNumber:          b.OCLCNumber,
+		LCCN:                b.LCCN,
+		DOI:                 b.DOI,
+		ASIN:                b.ASIN,
+		ThumbnailURL:        b.ThumbnailURL,
+		Description:         b.Description,
+		TableOfContents:     b.TableOfContents,
+		Sale:                b.Sale,
+		Access:              b.Access,
+		Classification:      b.Classification,
+		Provider:            b.Provider,
+		Metadata:            b.Metadata,
+		Confidence:          b.Confidence,
+		SearchType:          bookid.SearchType(b.SearchType),
+	}
+}
+
+// version returns the module version of the binary, or "(devel)" for
+// builds from a working tree.
+func version() string {
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" {
+		return info.Main.Version
+	}
+	return "(devel)"
+}
@@ -0,0 +1,149 @@
+package mcp_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/mcp"
+	"github.com/fwojciec/bookid/mock"
+	"github.com/fwojciec/bookid/sqlite"
+	mcpsdk "github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// MustConnect serves a server backed by an in-memory catalog and finder over
+// an in-memory transport and returns a client session connected to it.
+func MustConnect(tb testing.TB, finder bookid.BookFinder) *mcpsdk.ClientSession {
+	tb.Helper()
+	db := sqlite.NewDB(":memory:")
+	require.NoError(tb, db.Open())
+	tb.Cleanup(func() { _ = db.Close() })
+
+	s := mcp.NewServer()
+	s.BookFinder = finder
+	s.CatalogService = sqlite.NewCatalogService(db)
+	s.CatalogSearchService = sqlite.NewCatalogSearchService(db)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	tb.Cleanup(cancel)
+	serverTransport, clientTransport := mcpsdk.NewInMemoryTransports()
+	go func() { _ = s.Serve(ctx, serverTransport) }()
+
+	client := mcpsdk.NewClient(&mcpsdk.Implementation{Name: "test", Version: "v1"}, nil)
+	session, err := client.Connect(ctx, clientTransport, nil)
+	require.NoError(tb, err)
+	tb.Cleanup(func() { _ = session.Close() })
+	return session
+}
+
+// call calls a tool and decodes its structured output into v, failing if
+// the tool reports an error.
+func call(tb testing.TB, session *mcpsdk.ClientSession, tool string, args, v any) {
+	tb.Helper()
+	res, err := session.CallTool(context.Background(), &mcpsdk.CallToolParams{Name: tool, Arguments: args})
+	require.NoError(tb, err)
+	require.False(tb, res.IsError, "tool error: %v", res.Content)
+
+	data, err := json.Marshal(res.StructuredContent)
+	require.NoError(tb, err)
+	require.NoError(tb, json.Unmarshal(data, v))
+}
+
+// callError calls a tool expected to fail and returns its error text.
+func callError(tb testing.TB, session *mcpsdk.ClientSession, tool string, args any) string {
+	tb.Helper()
+	res, err := session.CallTool(context.Background(), &mcpsdk.CallToolParams{Name: tool, Arguments: args})
+	require.NoError(tb, err)
+	require.True(tb, res.IsError)
+	require.Len(tb, res.Content, 1)
+	return res.Content[0].(*mcpsdk.TextContent).Text
+}
+
+func TestServer_Tools(t *testing.T) {
+	t.Parallel()
+	session := MustConnect(t, nil)
+
+	res, err := session.ListTools(context.Background(), nil)
+	require.NoError(t, err)
+	names := make([]string, len(res.Tools))
+	for i, tool := range res.Tools {
+		names[i] = tool.Name
+	}
+	assert.ElementsMatch(t, []string{"identify_book", "save_book", "search_catalog"}, names)
+}
+
+func TestServer_IdentifyBook(t *testing.T) {
+	t.Parallel()
+
+	t.Run("OK", func(t *testing.T) {
+		t.Parallel()
+		session := MustConnect(t, &mock.BookFinder{SearchFn: func(_ context.Context, query string, opts bookid.SearchOptions) ([]bookid.BookResult, error) {
+			assert.Equal(t, "9780441172719", query)
+			assert.Equal(t, bookid.SearchOptions{MaxResults: mcp.DefaultMaxResults}, opts)
+			return []bookid.BookResult{{
+				Title:      "Dune",
+				Authors:    []string{"Frank Herbert"},
+				ISBN13:     "9780441172719",
+				Confidence: 1,
+				SearchType: bookid.SearchTypeISBN,
+			}}, nil
+		}})
+
+		var out struct {
+			Books []mcp.Book `json:"books"`
+		}
+		call(t, session, "identify_book", map[string]any{"query": "9780441172719"}, &out)
+		require.Len(t, out.Books, 1)
+		assert.Equal(t, mcp.Book{
+			Title:      "Dune",
+			Authors:    []string{"Frank Herbert"},
+			ISBN13:     "9780441172719",
+			Confidence: 1,
+			SearchType: "isbn",
+		}, out.Books[0])
+	})
+
+	t.Run("ErrInvalid", func(t *testing.T) {
+		t.Parallel()
+		session := MustConnect(t, nil)
+		assert.Equal(t, "invalid: Query required.", callError(t, session, "identify_book", map[string]any{"query": ""}))
+	})
+
+	t.Run("ErrInternal", func(t *testing.T) {
+		t.Parallel()
+		session := MustConnect(t, &mock.BookFinder{SearchFn: func(context.Context, string, bookid.SearchOptions) ([]bookid.BookResult, error) {
+			return nil, assert.AnError
+		}})
+		assert.Equal(t, "internal: Internal error.", callError(t, session, "identify_book", map[string]any{"query": "dune"}))
+	})
+}
+
+func TestServer_SaveBook(t *testing.T) {
+	t.Parallel()
+	session := MustConnect(t, nil)
+
+	var saved struct {
+		WorkID        int64 `json:"work_id"`
+		PublicationID int64 `json:"publication_id"`
+	}
+	call(t, session, "save_book", map[string]any{"book": mcp.Book{
+		Title:   "Solaris",
+		Authors: []string{"Stanisław Lem"},
+		ISBN13:  "9780156027601",
+	}}, &saved)
+	assert.NotZero(t, saved.WorkID)
+	assert.NotZero(t, saved.PublicationID)
+
+	var found struct {
+		Works []mcp.Work `json:"works"`
+		Total int        `json:"total"`
+	}
+	call(t, session, "search_catalog", map[string]any{"query": "sola*"}, &found)
+	assert.Equal(t, 1, found.Total)
+	assert.Equal(t, []mcp.Work{{ID: saved.WorkID, Title: "Solaris", Author: "Stanisław Lem"}}, found.Works)
+
+	assert.Equal(t, "invalid: Work title required.", callError(t, session, "save_book", map[string]any{"book": mcp.Book{Authors: []string{}}}))
+}
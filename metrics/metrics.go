@@ -0,0 +1,130 @@
+// Package metrics collects Prometheus metrics of provider searches and
+// search cache performance for operators running bookid as a service.
+//
+// Metrics are kept in a registry owned by Metrics rather than the Prometheus
+// default registry, and exposed in the text exposition format by Handler.
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/cache"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Namespace prefixes the names of all metrics.
+const Namespace = "bookid"
+
+// Metrics represents the metrics of a bookid process.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	searches *prometheus.CounterVec
+	latency  *prometheus.HistogramVec
+	errors   *prometheus.CounterVec
+}
+
+// New returns Metrics registered in a new registry along with the standard
+// Go runtime and process collectors.
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		searches: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "provider_searches_total",
+			Help:      "Number of searches sent to providers by provider and search type.",
+		}, []string{"provider", "search_type"}),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: Namespace,
+			Name:      "provider_search_duration_seconds",
+			Help:      "Latency of provider searches by provider.",
+			Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30},
+		}, []string{"provider"}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "provider_errors_total",
+			Help:      "Number of failed provider searches by provider and error code.",
+		}, []string{"provider", "code"}),
+	}
+	m.registry.MustRegister(
+		m.searches,
+		m.latency,
+		m.errors,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	return m
+}
+
+// Handler returns an HTTP handler serving the metrics.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// Registry returns the registry holding the metrics, for registering
+// additional collectors or gathering metrics in tests.
+func (m *Metrics) Registry() *prometheus.Registry {
+	return m.registry
+}
+
+// RegisterCache exposes the lookups of a search cache as hit and miss
+// counters and a hit ratio. It may be called once per Metrics.
+func (m *Metrics) RegisterCache(stats func() cache.Stats) {
+	m.registry.MustRegister(
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "search_cache_hits_total",
+			Help:      "Number of searches served from the search cache.",
+		}, func() float64 { return float64(stats().Hits) }),
+		prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Name:      "search_cache_misses_total",
+			Help:      "Number of searches not found in the search cache or expired.",
+		}, func() float64 { return float64(stats().Misses) }),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: Namespace,
+			Name:      "search_cache_hit_ratio",
+			Help:      "Fraction of searches served from the search cache.",
+		}, func() float64 { return stats().HitRatio() }),
+	)
+}
+
+// Ensure type implements interface.
+var _ bookid.BookFinder = (*Finder)(nil)
+
+// Finder wraps the BookFinder of a provider and records the number, latency
+// and errors of its searches.
+type Finder struct {
+	finder   bookid.BookFinder
+	metrics  *Metrics
+	provider string
+}
+
+// NewFinder returns a Finder recording the searches of provider to m.
+func NewFinder(finder bookid.BookFinder, m *Metrics, provider string) *Finder {
+	return &Finder{finder: finder, metrics: m, provider: provider}
+}
+
+// Search searches the wrapped finder and records the outcome. Searches are
+// labeled with the search type of their first result, or "none" if there are
+// no results.
+func (f *Finder) Search(ctx context.Context, query string, opts bookid.SearchOptions) ([]bookid.BookResult, error) {
+	start := time.Now()
+	results, err := f.finder.Search(ctx, query, opts)
+	f.metrics.latency.WithLabelValues(f.provider).Observe(time.Since(start).Seconds())
+
+	searchType := "none"
+	if len(results) > 0 && results[0].SearchType != "" {
+		searchType = string(results[0].SearchType)
+	}
+	f.metrics.searches.WithLabelValues(f.provider, searchType).Inc()
+	if err != nil {
+		f.metrics.errors.WithLabelValues(f.provider, bookid.ErrorCode(err)).Inc()
+	}
+	return results, err
+}
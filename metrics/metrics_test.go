@@ -0,0 +1,79 @@
+package metrics_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/cache"
+	"github.com/fwojciec/bookid/metrics"
+	"github.com/fwojciec/bookid/mock"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFinder_Search(t *testing.T) {
+	t.Parallel()
+
+	m := metrics.New()
+	ctx := context.Background()
+	found := metrics.NewFinder(&mock.BookFinder{SearchFn: func(context.Context, string, bookid.SearchOptions) ([]bookid.BookResult, error) {
+		return []bookid.BookResult{{Title: "Dune", SearchType: bookid.SearchTypeISBN}}, nil
+	}}, m, "isbndb")
+	failing := metrics.NewFinder(&mock.BookFinder{SearchFn: func(context.Context, string, bookid.SearchOptions) ([]bookid.BookResult, error) {
+		return nil, bookid.Errorf(bookid.ERATELIMIT, "Slow down.")
+	}}, m, "googlebooks")
+
+	for range 2 {
+		_, err := found.Search(ctx, "9780441172719", bookid.SearchOptions{})
+		require.NoError(t, err)
+	}
+	_, err := failing.Search(ctx, "dune", bookid.SearchOptions{})
+	require.Error(t, err)
+
+	require.NoError(t, testutil.GatherAndCompare(m.Registry(), strings.NewReader(`
+# HELP bookid_provider_searches_total Number of searches sent to providers by provider and search type.
+# TYPE bookid_provider_searches_total counter
+bookid_provider_searches_total{provider="googlebooks",search_type="none"} 1
+bookid_provider_searches_total{provider="isbndb",search_type="isbn"} 2
+# HELP bookid_provider_errors_total Number of failed provider searches by provider and error code.
+# TYPE bookid_provider_errors_total counter
+bookid_provider_errors_total{code="rate_limit",provider="googlebooks"} 1
+`), "bookid_provider_searches_total", "bookid_provider_errors_total"))
+
+	n, err := testutil.GatherAndCount(m.Registry(), "bookid_provider_search_duration_seconds")
+	require.NoError(t, err)
+	assert.Equal(t, 2, n) // One histogram per provider.
+}
+
+func TestMetrics_RegisterCache(t *testing.T) {
+	t.Parallel()
+
+	m := metrics.New()
+	m.RegisterCache(func() cache.Stats { return cache.Stats{Hits: 3, Misses: 1} })
+
+	require.NoError(t, testutil.GatherAndCompare(m.Registry(), strings.NewReader(`
+# HELP bookid_search_cache_hit_ratio Fraction of searches served from the search cache.
+# TYPE bookid_search_cache_hit_ratio gauge
+bookid_search_cache_hit_ratio 0.75
+# HELP bookid_search_cache_hits_total Number of searches served from the search cache.
+# TYPE bookid_search_cache_hits_total counter
+bookid_search_cache_hits_total 3
+# HELP bookid_search_cache_misses_total Number of searches not found in the search cache or expired.
+# TYPE bookid_search_cache_misses_total counter
+bookid_search_cache_misses_total 1
+`), "bookid_search_cache_hit_ratio", "bookid_search_cache_hits_total", "bookid_search_cache_misses_total"))
+}
+
+func TestMetrics_Handler(t *testing.T) {
+	t.Parallel()
+
+	w := httptest.NewRecorder()
+	metrics.New().Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "go_goroutines")
+}
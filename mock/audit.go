@@ -0,0 +1,19 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/fwojciec/bookid"
+)
+
+// Ensure type implements interface.
+var _ bookid.AuditService = (*AuditService)(nil)
+
+// AuditService represents a mock of bookid.AuditService.
+type AuditService struct {
+	FindAuditEntriesFn func(ctx context.Context, filter bookid.AuditFilter) ([]*bookid.AuditEntry, int, error)
+}
+
+func (s *AuditService) FindAuditEntries(ctx context.Context, filter bookid.AuditFilter) ([]*bookid.AuditEntry, int, error) {
+	return s.FindAuditEntriesFn(ctx, filter)
+}
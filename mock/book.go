@@ -0,0 +1,220 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/fwojciec/bookid"
+)
+
+// Ensure type implements interface.
+var _ bookid.WorkService = (*WorkService)(nil)
+
+// WorkService represents a mock of bookid.WorkService.
+type WorkService struct {
+	FindWorkByIDFn func(ctx context.Context, id int64) (*bookid.Work, error)
+	FindWorksFn    func(ctx context.Context, filter bookid.WorkFilter) ([]*bookid.Work, int, error)
+	CreateWorkFn   func(ctx context.Context, work *bookid.Work) error
+	UpdateWorkFn   func(ctx context.Context, id int64, upd bookid.WorkUpdate) (*bookid.Work, error)
+	DeleteWorkFn   func(ctx context.Context, id int64) error
+	RestoreWorkFn  func(ctx context.Context, id int64) error
+	PurgeWorkFn    func(ctx context.Context, id int64) error
+	MergeWorksFn   func(ctx context.Context, targetID int64, sourceIDs ...int64) error
+}
+
+func (s *WorkService) FindWorkByID(ctx context.Context, id int64) (*bookid.Work, error) {
+	return s.FindWorkByIDFn(ctx, id)
+}
+
+func (s *WorkService) FindWorks(ctx context.Context, filter bookid.WorkFilter) ([]*bookid.Work, int, error) {
+	return s.FindWorksFn(ctx, filter)
+}
+
+func (s *WorkService) CreateWork(ctx context.Context, work *bookid.Work) error {
+	return s.CreateWorkFn(ctx, work)
+}
+
+func (s *WorkService) UpdateWork(ctx context.Context, id int64, upd bookid.WorkUpdate) (*bookid.Work, error) {
+	return s.UpdateWorkFn(ctx, id, upd)
+}
+
+func (s *WorkService) DeleteWork(ctx context.Context, id int64) error {
+	return s.DeleteWorkFn(ctx, id)
+}
+
+func (s *WorkService) RestoreWork(ctx context.Context, id int64) error {
+	return s.RestoreWorkFn(ctx, id)
+}
+
+func (s *WorkService) PurgeWork(ctx context.Context, id int64) error {
+	return s.PurgeWorkFn(ctx, id)
+}
+
+func (s *WorkService) MergeWorks(ctx context.Context, targetID int64, sourceIDs ...int64) error {
+	return s.MergeWorksFn(ctx, targetID, sourceIDs...)
+}
+
+// Ensure type implements interface.
+var _ bookid.CatalogService = (*CatalogService)(nil)
+
+// CatalogService represents a mock of bookid.CatalogService.
+type CatalogService struct {
+	SaveResultFn func(ctx context.Context, result bookid.BookResult) (workID, publicationID int64, err error)
+}
+
+func (s *CatalogService) SaveResult(ctx context.Context, result bookid.BookResult) (workID, publicationID int64, err error) {
+	return s.SaveResultFn(ctx, result)
+}
+
+// Ensure type implements interface.
+var _ bookid.WorkRebuildService = (*WorkRebuildService)(nil)
+
+// WorkRebuildService represents a mock of bookid.WorkRebuildService.
+type WorkRebuildService struct {
+	RebuildWorksFn func(ctx context.Context, apply bool) ([]*bookid.WorkCluster, error)
+}
+
+func (s *WorkRebuildService) RebuildWorks(ctx context.Context, apply bool) ([]*bookid.WorkCluster, error) {
+	return s.RebuildWorksFn(ctx, apply)
+}
+
+// Ensure type implements interface.
+var _ bookid.CatalogSearchService = (*CatalogSearchService)(nil)
+
+// CatalogSearchService represents a mock of bookid.CatalogSearchService.
+type CatalogSearchService struct {
+	SearchCatalogFn func(ctx context.Context, filter bookid.CatalogSearchFilter) ([]*bookid.Work, int, error)
+}
+
+func (s *CatalogSearchService) SearchCatalog(ctx context.Context, filter bookid.CatalogSearchFilter) ([]*bookid.Work, int, error) {
+	return s.SearchCatalogFn(ctx, filter)
+}
+
+// Ensure type implements interface.
+var _ bookid.AuthorService = (*AuthorService)(nil)
+
+// AuthorService represents a mock of bookid.AuthorService.
+type AuthorService struct {
+	FindAuthorByIDFn   func(ctx context.Context, id int64) (*bookid.Author, error)
+	FindAuthorsFn      func(ctx context.Context, filter bookid.AuthorFilter) ([]*bookid.Author, int, error)
+	CreateAuthorFn     func(ctx context.Context, author *bookid.Author) error
+	UpdateAuthorFn     func(ctx context.Context, id int64, upd bookid.AuthorUpdate) (*bookid.Author, error)
+	DeleteAuthorFn     func(ctx context.Context, id int64) error
+	AddWorkAuthorFn    func(ctx context.Context, wa *bookid.WorkAuthor) error
+	RemoveWorkAuthorFn func(ctx context.Context, wa *bookid.WorkAuthor) error
+}
+
+func (s *AuthorService) FindAuthorByID(ctx context.Context, id int64) (*bookid.Author, error) {
+	return s.FindAuthorByIDFn(ctx, id)
+}
+
+func (s *AuthorService) FindAuthors(ctx context.Context, filter bookid.AuthorFilter) ([]*bookid.Author, int, error) {
+	return s.FindAuthorsFn(ctx, filter)
+}
+
+func (s *AuthorService) CreateAuthor(ctx context.Context, author *bookid.Author) error {
+	return s.CreateAuthorFn(ctx, author)
+}
+
+func (s *AuthorService) UpdateAuthor(ctx context.Context, id int64, upd bookid.AuthorUpdate) (*bookid.Author, error) {
+	return s.UpdateAuthorFn(ctx, id, upd)
+}
+
+func (s *AuthorService) DeleteAuthor(ctx context.Context, id int64) error {
+	return s.DeleteAuthorFn(ctx, id)
+}
+
+func (s *AuthorService) AddWorkAuthor(ctx context.Context, wa *bookid.WorkAuthor) error {
+	return s.AddWorkAuthorFn(ctx, wa)
+}
+
+func (s *AuthorService) RemoveWorkAuthor(ctx context.Context, wa *bookid.WorkAuthor) error {
+	return s.RemoveWorkAuthorFn(ctx, wa)
+}
+
+// Ensure type implements interface.
+var _ bookid.AuthorityFinder = (*AuthorityFinder)(nil)
+
+// AuthorityFinder represents a mock of bookid.AuthorityFinder.
+type AuthorityFinder struct {
+	FindAuthoritiesFn func(ctx context.Context, name string) ([]*bookid.AuthorityRecord, error)
+}
+
+func (f *AuthorityFinder) FindAuthorities(ctx context.Context, name string) ([]*bookid.AuthorityRecord, error) {
+	return f.FindAuthoritiesFn(ctx, name)
+}
+
+// Ensure type implements interface.
+var _ bookid.PublicationService = (*PublicationService)(nil)
+
+// PublicationService represents a mock of bookid.PublicationService.
+type PublicationService struct {
+	FindPublicationByIDFn func(ctx context.Context, id int64) (*bookid.Publication, error)
+	FindPublicationsFn    func(ctx context.Context, filter bookid.PublicationFilter) ([]*bookid.Publication, int, error)
+	CreatePublicationFn   func(ctx context.Context, pub *bookid.Publication) error
+	UpsertPublicationFn   func(ctx context.Context, pub *bookid.Publication) error
+	UpdatePublicationFn   func(ctx context.Context, id int64, upd bookid.PublicationUpdate) (*bookid.Publication, error)
+	DeletePublicationFn   func(ctx context.Context, id int64) error
+	RestorePublicationFn  func(ctx context.Context, id int64) error
+	PurgePublicationFn    func(ctx context.Context, id int64) error
+}
+
+func (s *PublicationService) FindPublicationByID(ctx context.Context, id int64) (*bookid.Publication, error) {
+	return s.FindPublicationByIDFn(ctx, id)
+}
+
+func (s *PublicationService) FindPublications(ctx context.Context, filter bookid.PublicationFilter) ([]*bookid.Publication, int, error) {
+	return s.FindPublicationsFn(ctx, filter)
+}
+
+func (s *PublicationService) CreatePublication(ctx context.Context, pub *bookid.Publication) error {
+	return s.CreatePublicationFn(ctx, pub)
+}
+
+func (s *PublicationService) UpsertPublication(ctx context.Context, pub *bookid.Publication) error {
+	return s.UpsertPublicationFn(ctx, pub)
+}
+
+func (s *PublicationService) UpdatePublication(ctx context.Context, id int64, upd bookid.PublicationUpdate) (*bookid.Publication, error) {
+	return s.UpdatePublicationFn(ctx, id, upd)
+}
+
+func (s *PublicationService) DeletePublication(ctx context.Context, id int64) error {
+	return s.DeletePublicationFn(ctx, id)
+}
+
+func (s *PublicationService) RestorePublication(ctx context.Context, id int64) error {
+	return s.RestorePublicationFn(ctx, id)
+}
+
+func (s *PublicationService) PurgePublication(ctx context.Context, id int64) error {
+	return s.PurgePublicationFn(ctx, id)
+}
+
+// Ensure type implements interface.
+var _ bookid.CoverService = (*CoverService)(nil)
+
+// CoverService represents a mock of bookid.CoverService.
+type CoverService struct {
+	FetchCoverFn func(ctx context.Context, publicationID int64) (*bookid.Publication, error)
+	FindCoverFn  func(ctx context.Context, publicationID int64, size, format string) (*bookid.Cover, error)
+}
+
+func (s *CoverService) FetchCover(ctx context.Context, publicationID int64) (*bookid.Publication, error) {
+	return s.FetchCoverFn(ctx, publicationID)
+}
+
+func (s *CoverService) FindCover(ctx context.Context, publicationID int64, size, format string) (*bookid.Cover, error) {
+	return s.FindCoverFn(ctx, publicationID, size, format)
+}
+
+// Ensure service implements interface.
+var _ bookid.RefreshService = (*RefreshService)(nil)
+
+// RefreshService represents a mock of bookid.RefreshService.
+type RefreshService struct {
+	RefreshPublicationFn func(ctx context.Context, id int64) (*bookid.PublicationRefresh, error)
+}
+
+func (s *RefreshService) RefreshPublication(ctx context.Context, id int64) (*bookid.PublicationRefresh, error) {
+	return s.RefreshPublicationFn(ctx, id)
+}
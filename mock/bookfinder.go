@@ -0,0 +1,60 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/fwojciec/bookid"
+)
+
+// Ensure type implements interface.
+var _ bookid.BookFinder = (*BookFinder)(nil)
+
+// BookFinder represents a mock of bookid.BookFinder.
+type BookFinder struct {
+	SearchFn func(ctx context.Context, query string, opts bookid.SearchOptions) ([]bookid.BookResult, error)
+}
+
+func (f *BookFinder) Search(ctx context.Context, query string, opts bookid.SearchOptions) ([]bookid.BookResult, error) {
+	return f.SearchFn(ctx, query, opts)
+}
+
+// Ensure type implements interface.
+var _ bookid.BookGetter = (*BookGetter)(nil)
+
+// BookGetter represents a mock of bookid.BookGetter.
+type BookGetter struct {
+	GetByIDFn func(ctx context.Context, id string) (*bookid.BookResult, error)
+}
+
+func (g *BookGetter) GetByID(ctx context.Context, id string) (*bookid.BookResult, error) {
+	return g.GetByIDFn(ctx, id)
+}
+
+// Ensure type implements interface.
+var _ bookid.SearchCache = (*SearchCache)(nil)
+
+// SearchCache represents a mock of bookid.SearchCache.
+type SearchCache struct {
+	FindSearchCacheEntryFn func(ctx context.Context, key string) (*bookid.SearchCacheEntry, error)
+	SetSearchCacheEntryFn  func(ctx context.Context, entry *bookid.SearchCacheEntry) error
+}
+
+func (c *SearchCache) FindSearchCacheEntry(ctx context.Context, key string) (*bookid.SearchCacheEntry, error) {
+	return c.FindSearchCacheEntryFn(ctx, key)
+}
+
+func (c *SearchCache) SetSearchCacheEntry(ctx context.Context, entry *bookid.SearchCacheEntry) error {
+	return c.SetSearchCacheEntryFn(ctx, entry)
+}
+
+// Ensure type implements interface.
+var _ bookid.BatchFinder = (*BatchFinder)(nil)
+
+// BatchFinder represents a mock of bookid.BatchFinder.
+type BatchFinder struct {
+	SearchAllFn func(ctx context.Context, queries []string, opts bookid.BatchOptions) ([]bookid.BatchResult, error)
+}
+
+func (f *BatchFinder) SearchAll(ctx context.Context, queries []string, opts bookid.BatchOptions) ([]bookid.BatchResult, error) {
+	return f.SearchAllFn(ctx, queries, opts)
+}
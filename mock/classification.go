@@ -0,0 +1,19 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/fwojciec/bookid"
+)
+
+// Ensure type implements interface.
+var _ bookid.ClassificationFinder = (*ClassificationFinder)(nil)
+
+// ClassificationFinder represents a mock of bookid.ClassificationFinder.
+type ClassificationFinder struct {
+	LookupClassificationFn func(ctx context.Context, isbn string) (bookid.Classification, error)
+}
+
+func (f *ClassificationFinder) LookupClassification(ctx context.Context, isbn string) (bookid.Classification, error) {
+	return f.LookupClassificationFn(ctx, isbn)
+}
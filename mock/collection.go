@@ -0,0 +1,49 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/fwojciec/bookid"
+)
+
+// Ensure type implements interface.
+var _ bookid.CollectionService = (*CollectionService)(nil)
+
+// CollectionService represents a mock of bookid.CollectionService.
+type CollectionService struct {
+	FindCollectionByIDFn   func(ctx context.Context, id int64) (*bookid.Collection, error)
+	FindCollectionsFn      func(ctx context.Context, filter bookid.CollectionFilter) ([]*bookid.Collection, int, error)
+	CreateCollectionFn     func(ctx context.Context, collection *bookid.Collection) error
+	UpdateCollectionFn     func(ctx context.Context, id int64, upd bookid.CollectionUpdate) (*bookid.Collection, error)
+	DeleteCollectionFn     func(ctx context.Context, id int64) error
+	AddCollectionWorkFn    func(ctx context.Context, collectionID, workID int64) error
+	RemoveCollectionWorkFn func(ctx context.Context, collectionID, workID int64) error
+}
+
+func (s *CollectionService) FindCollectionByID(ctx context.Context, id int64) (*bookid.Collection, error) {
+	return s.FindCollectionByIDFn(ctx, id)
+}
+
+func (s *CollectionService) FindCollections(ctx context.Context, filter bookid.CollectionFilter) ([]*bookid.Collection, int, error) {
+	return s.FindCollectionsFn(ctx, filter)
+}
+
+func (s *CollectionService) CreateCollection(ctx context.Context, collection *bookid.Collection) error {
+	return s.CreateCollectionFn(ctx, collection)
+}
+
+func (s *CollectionService) UpdateCollection(ctx context.Context, id int64, upd bookid.CollectionUpdate) (*bookid.Collection, error) {
+	return s.UpdateCollectionFn(ctx, id, upd)
+}
+
+func (s *CollectionService) DeleteCollection(ctx context.Context, id int64) error {
+	return s.DeleteCollectionFn(ctx, id)
+}
+
+func (s *CollectionService) AddCollectionWork(ctx context.Context, collectionID, workID int64) error {
+	return s.AddCollectionWorkFn(ctx, collectionID, workID)
+}
+
+func (s *CollectionService) RemoveCollectionWork(ctx context.Context, collectionID, workID int64) error {
+	return s.RemoveCollectionWorkFn(ctx, collectionID, workID)
+}
@@ -0,0 +1,34 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/fwojciec/bookid"
+)
+
+// Ensure type implements interface.
+var _ bookid.ConflictService = (*ConflictService)(nil)
+
+// ConflictService represents a mock of bookid.ConflictService.
+type ConflictService struct {
+	FindConflictByIDFn func(ctx context.Context, id int64) (*bookid.Conflict, error)
+	FindConflictsFn    func(ctx context.Context, filter bookid.ConflictFilter) ([]*bookid.Conflict, int, error)
+	CreateConflictFn   func(ctx context.Context, conflict *bookid.Conflict) error
+	ResolveConflictFn  func(ctx context.Context, id int64, accept bool) (*bookid.Conflict, error)
+}
+
+func (s *ConflictService) FindConflictByID(ctx context.Context, id int64) (*bookid.Conflict, error) {
+	return s.FindConflictByIDFn(ctx, id)
+}
+
+func (s *ConflictService) FindConflicts(ctx context.Context, filter bookid.ConflictFilter) ([]*bookid.Conflict, int, error) {
+	return s.FindConflictsFn(ctx, filter)
+}
+
+func (s *ConflictService) CreateConflict(ctx context.Context, conflict *bookid.Conflict) error {
+	return s.CreateConflictFn(ctx, conflict)
+}
+
+func (s *ConflictService) ResolveConflict(ctx context.Context, id int64, accept bool) (*bookid.Conflict, error) {
+	return s.ResolveConflictFn(ctx, id, accept)
+}
@@ -0,0 +1,41 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/fwojciec/bookid"
+)
+
+// Ensure type implements interface.
+var _ bookid.EventService = (*EventService)(nil)
+
+// EventService represents a mock of bookid.EventService.
+type EventService struct {
+	PublishEventFn func(event bookid.Event)
+	SubscribeFn    func(ctx context.Context) (bookid.Subscription, error)
+}
+
+func (s *EventService) PublishEvent(event bookid.Event) {
+	s.PublishEventFn(event)
+}
+
+func (s *EventService) Subscribe(ctx context.Context) (bookid.Subscription, error) {
+	return s.SubscribeFn(ctx)
+}
+
+// Ensure type implements interface.
+var _ bookid.Subscription = (*Subscription)(nil)
+
+// Subscription represents a mock of bookid.Subscription.
+type Subscription struct {
+	CFn     func() <-chan bookid.Event
+	CloseFn func() error
+}
+
+func (s *Subscription) C() <-chan bookid.Event {
+	return s.CFn()
+}
+
+func (s *Subscription) Close() error {
+	return s.CloseFn()
+}
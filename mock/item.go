@@ -0,0 +1,39 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/fwojciec/bookid"
+)
+
+// Ensure type implements interface.
+var _ bookid.ItemService = (*ItemService)(nil)
+
+// ItemService represents a mock of bookid.ItemService.
+type ItemService struct {
+	FindItemByIDFn func(ctx context.Context, id int64) (*bookid.Item, error)
+	FindItemsFn    func(ctx context.Context, filter bookid.ItemFilter) ([]*bookid.Item, int, error)
+	CreateItemFn   func(ctx context.Context, item *bookid.Item) error
+	UpdateItemFn   func(ctx context.Context, id int64, upd bookid.ItemUpdate) (*bookid.Item, error)
+	DeleteItemFn   func(ctx context.Context, id int64) error
+}
+
+func (s *ItemService) FindItemByID(ctx context.Context, id int64) (*bookid.Item, error) {
+	return s.FindItemByIDFn(ctx, id)
+}
+
+func (s *ItemService) FindItems(ctx context.Context, filter bookid.ItemFilter) ([]*bookid.Item, int, error) {
+	return s.FindItemsFn(ctx, filter)
+}
+
+func (s *ItemService) CreateItem(ctx context.Context, item *bookid.Item) error {
+	return s.CreateItemFn(ctx, item)
+}
+
+func (s *ItemService) UpdateItem(ctx context.Context, id int64, upd bookid.ItemUpdate) (*bookid.Item, error) {
+	return s.UpdateItemFn(ctx, id, upd)
+}
+
+func (s *ItemService) DeleteItem(ctx context.Context, id int64) error {
+	return s.DeleteItemFn(ctx, id)
+}
@@ -0,0 +1,44 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/fwojciec/bookid"
+)
+
+// Ensure type implements interface.
+var _ bookid.JobService = (*JobService)(nil)
+
+// JobService represents a mock of bookid.JobService.
+type JobService struct {
+	FindJobByIDFn func(ctx context.Context, id int64) (*bookid.Job, error)
+	FindJobsFn    func(ctx context.Context, filter bookid.JobFilter) ([]*bookid.Job, int, error)
+	CreateJobFn   func(ctx context.Context, job *bookid.Job) error
+	ClaimJobFn    func(ctx context.Context) (*bookid.Job, error)
+	UpdateJobFn   func(ctx context.Context, id int64, upd bookid.JobUpdate) (*bookid.Job, error)
+	CancelJobFn   func(ctx context.Context, id int64) (*bookid.Job, error)
+}
+
+func (s *JobService) FindJobByID(ctx context.Context, id int64) (*bookid.Job, error) {
+	return s.FindJobByIDFn(ctx, id)
+}
+
+func (s *JobService) FindJobs(ctx context.Context, filter bookid.JobFilter) ([]*bookid.Job, int, error) {
+	return s.FindJobsFn(ctx, filter)
+}
+
+func (s *JobService) CreateJob(ctx context.Context, job *bookid.Job) error {
+	return s.CreateJobFn(ctx, job)
+}
+
+func (s *JobService) ClaimJob(ctx context.Context) (*bookid.Job, error) {
+	return s.ClaimJobFn(ctx)
+}
+
+func (s *JobService) UpdateJob(ctx context.Context, id int64, upd bookid.JobUpdate) (*bookid.Job, error) {
+	return s.UpdateJobFn(ctx, id, upd)
+}
+
+func (s *JobService) CancelJob(ctx context.Context, id int64) (*bookid.Job, error) {
+	return s.CancelJobFn(ctx, id)
+}
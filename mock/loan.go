@@ -0,0 +1,34 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/fwojciec/bookid"
+)
+
+// Ensure type implements interface.
+var _ bookid.LoanService = (*LoanService)(nil)
+
+// LoanService represents a mock of bookid.LoanService.
+type LoanService struct {
+	FindLoanByIDFn func(ctx context.Context, id int64) (*bookid.Loan, error)
+	FindLoansFn    func(ctx context.Context, filter bookid.LoanFilter) ([]*bookid.Loan, int, error)
+	CheckoutItemFn func(ctx context.Context, loan *bookid.Loan) error
+	ReturnItemFn   func(ctx context.Context, itemID int64) (*bookid.Loan, error)
+}
+
+func (s *LoanService) FindLoanByID(ctx context.Context, id int64) (*bookid.Loan, error) {
+	return s.FindLoanByIDFn(ctx, id)
+}
+
+func (s *LoanService) FindLoans(ctx context.Context, filter bookid.LoanFilter) ([]*bookid.Loan, int, error) {
+	return s.FindLoansFn(ctx, filter)
+}
+
+func (s *LoanService) CheckoutItem(ctx context.Context, loan *bookid.Loan) error {
+	return s.CheckoutItemFn(ctx, loan)
+}
+
+func (s *LoanService) ReturnItem(ctx context.Context, itemID int64) (*bookid.Loan, error) {
+	return s.ReturnItemFn(ctx, itemID)
+}
@@ -0,0 +1,7 @@
+// Package mock provides manual mocks of the bookid interfaces for testing.
+//
+// Each mock has a function field for every method, named after the method
+// with an Fn suffix, which the method calls. Tests set the fields for the
+// methods they expect to be called; calling a method whose field is unset
+// panics.
+package mock
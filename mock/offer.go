@@ -0,0 +1,48 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/fwojciec/bookid"
+)
+
+// Ensure type implements interface.
+var _ bookid.OfferFinder = (*OfferFinder)(nil)
+
+// OfferFinder represents a mock of bookid.OfferFinder.
+type OfferFinder struct {
+	LookupOffersFn func(ctx context.Context, isbn string) ([]bookid.Offer, error)
+}
+
+func (f *OfferFinder) LookupOffers(ctx context.Context, isbn string) ([]bookid.Offer, error) {
+	return f.LookupOffersFn(ctx, isbn)
+}
+
+// Ensure type implements interface.
+var _ bookid.OfferService = (*OfferService)(nil)
+
+// OfferService represents a mock of bookid.OfferService.
+type OfferService struct {
+	FindOffersFn  func(ctx context.Context, filter bookid.OfferFilter) ([]*bookid.Offer, int, error)
+	CreateOfferFn func(ctx context.Context, offer *bookid.Offer) error
+}
+
+func (s *OfferService) FindOffers(ctx context.Context, filter bookid.OfferFilter) ([]*bookid.Offer, int, error) {
+	return s.FindOffersFn(ctx, filter)
+}
+
+func (s *OfferService) CreateOffer(ctx context.Context, offer *bookid.Offer) error {
+	return s.CreateOfferFn(ctx, offer)
+}
+
+// Ensure type implements interface.
+var _ bookid.PricingService = (*PricingService)(nil)
+
+// PricingService represents a mock of bookid.PricingService.
+type PricingService struct {
+	FetchOffersFn func(ctx context.Context, publicationID int64) ([]*bookid.Offer, error)
+}
+
+func (s *PricingService) FetchOffers(ctx context.Context, publicationID int64) ([]*bookid.Offer, error) {
+	return s.FetchOffersFn(ctx, publicationID)
+}
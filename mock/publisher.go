@@ -0,0 +1,34 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/fwojciec/bookid"
+)
+
+// Ensure type implements interface.
+var _ bookid.PublisherService = (*PublisherService)(nil)
+
+// PublisherService represents a mock of bookid.PublisherService.
+type PublisherService struct {
+	FindPublisherByIDFn func(ctx context.Context, id int64) (*bookid.Publisher, error)
+	FindPublishersFn    func(ctx context.Context, filter bookid.PublisherFilter) ([]*bookid.Publisher, int, error)
+	CreatePublisherFn   func(ctx context.Context, publisher *bookid.Publisher) error
+	MergePublishersFn   func(ctx context.Context, targetID int64, sourceIDs ...int64) error
+}
+
+func (s *PublisherService) FindPublisherByID(ctx context.Context, id int64) (*bookid.Publisher, error) {
+	return s.FindPublisherByIDFn(ctx, id)
+}
+
+func (s *PublisherService) FindPublishers(ctx context.Context, filter bookid.PublisherFilter) ([]*bookid.Publisher, int, error) {
+	return s.FindPublishersFn(ctx, filter)
+}
+
+func (s *PublisherService) CreatePublisher(ctx context.Context, publisher *bookid.Publisher) error {
+	return s.CreatePublisherFn(ctx, publisher)
+}
+
+func (s *PublisherService) MergePublishers(ctx context.Context, targetID int64, sourceIDs ...int64) error {
+	return s.MergePublishersFn(ctx, targetID, sourceIDs...)
+}
@@ -0,0 +1,39 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/fwojciec/bookid"
+)
+
+// Ensure type implements interface.
+var _ bookid.WorkRelationService = (*WorkRelationService)(nil)
+
+// WorkRelationService represents a mock of bookid.WorkRelationService.
+type WorkRelationService struct {
+	CreateWorkRelationFn  func(ctx context.Context, rel *bookid.WorkRelation) error
+	FindWorkRelationsFn   func(ctx context.Context, filter bookid.WorkRelationFilter) ([]*bookid.WorkRelation, int, error)
+	DeleteWorkRelationFn  func(ctx context.Context, id int64) error
+	SuggestTranslationsFn func(ctx context.Context) ([]*bookid.WorkRelationSuggestion, error)
+	LinkTranslationFn     func(ctx context.Context, workID, originalID int64, translators []string) (*bookid.WorkRelation, error)
+}
+
+func (s *WorkRelationService) CreateWorkRelation(ctx context.Context, rel *bookid.WorkRelation) error {
+	return s.CreateWorkRelationFn(ctx, rel)
+}
+
+func (s *WorkRelationService) FindWorkRelations(ctx context.Context, filter bookid.WorkRelationFilter) ([]*bookid.WorkRelation, int, error) {
+	return s.FindWorkRelationsFn(ctx, filter)
+}
+
+func (s *WorkRelationService) DeleteWorkRelation(ctx context.Context, id int64) error {
+	return s.DeleteWorkRelationFn(ctx, id)
+}
+
+func (s *WorkRelationService) SuggestTranslations(ctx context.Context) ([]*bookid.WorkRelationSuggestion, error) {
+	return s.SuggestTranslationsFn(ctx)
+}
+
+func (s *WorkRelationService) LinkTranslation(ctx context.Context, workID, originalID int64, translators []string) (*bookid.WorkRelation, error) {
+	return s.LinkTranslationFn(ctx, workID, originalID, translators)
+}
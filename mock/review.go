@@ -0,0 +1,39 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/fwojciec/bookid"
+)
+
+// Ensure type implements interface.
+var _ bookid.ReviewService = (*ReviewService)(nil)
+
+// ReviewService represents a mock of bookid.ReviewService.
+type ReviewService struct {
+	FindReviewByIDFn func(ctx context.Context, id int64) (*bookid.Review, error)
+	FindReviewsFn    func(ctx context.Context, filter bookid.ReviewFilter) ([]*bookid.Review, int, error)
+	CreateReviewFn   func(ctx context.Context, review *bookid.Review) error
+	SaveReviewFn     func(ctx context.Context, id int64, choice int) (*bookid.Review, error)
+	DismissReviewFn  func(ctx context.Context, id int64) (*bookid.Review, error)
+}
+
+func (s *ReviewService) FindReviewByID(ctx context.Context, id int64) (*bookid.Review, error) {
+	return s.FindReviewByIDFn(ctx, id)
+}
+
+func (s *ReviewService) FindReviews(ctx context.Context, filter bookid.ReviewFilter) ([]*bookid.Review, int, error) {
+	return s.FindReviewsFn(ctx, filter)
+}
+
+func (s *ReviewService) CreateReview(ctx context.Context, review *bookid.Review) error {
+	return s.CreateReviewFn(ctx, review)
+}
+
+func (s *ReviewService) SaveReview(ctx context.Context, id int64, choice int) (*bookid.Review, error) {
+	return s.SaveReviewFn(ctx, id, choice)
+}
+
+func (s *ReviewService) DismissReview(ctx context.Context, id int64) (*bookid.Review, error) {
+	return s.DismissReviewFn(ctx, id)
+}
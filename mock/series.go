@@ -0,0 +1,49 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/fwojciec/bookid"
+)
+
+// Ensure type implements interface.
+var _ bookid.SeriesService = (*SeriesService)(nil)
+
+// SeriesService represents a mock of bookid.SeriesService.
+type SeriesService struct {
+	FindSeriesByIDFn   func(ctx context.Context, id int64) (*bookid.Series, error)
+	FindSeriesFn       func(ctx context.Context, filter bookid.SeriesFilter) ([]*bookid.Series, int, error)
+	CreateSeriesFn     func(ctx context.Context, series *bookid.Series) error
+	DeleteSeriesFn     func(ctx context.Context, id int64) error
+	AddSeriesWorkFn    func(ctx context.Context, sw *bookid.SeriesWork) error
+	RemoveSeriesWorkFn func(ctx context.Context, seriesID, workID int64) error
+	FindSeriesWorksFn  func(ctx context.Context, filter bookid.SeriesWorkFilter) ([]*bookid.SeriesWork, int, error)
+}
+
+func (s *SeriesService) FindSeriesByID(ctx context.Context, id int64) (*bookid.Series, error) {
+	return s.FindSeriesByIDFn(ctx, id)
+}
+
+func (s *SeriesService) FindSeries(ctx context.Context, filter bookid.SeriesFilter) ([]*bookid.Series, int, error) {
+	return s.FindSeriesFn(ctx, filter)
+}
+
+func (s *SeriesService) CreateSeries(ctx context.Context, series *bookid.Series) error {
+	return s.CreateSeriesFn(ctx, series)
+}
+
+func (s *SeriesService) DeleteSeries(ctx context.Context, id int64) error {
+	return s.DeleteSeriesFn(ctx, id)
+}
+
+func (s *SeriesService) AddSeriesWork(ctx context.Context, sw *bookid.SeriesWork) error {
+	return s.AddSeriesWorkFn(ctx, sw)
+}
+
+func (s *SeriesService) RemoveSeriesWork(ctx context.Context, seriesID, workID int64) error {
+	return s.RemoveSeriesWorkFn(ctx, seriesID, workID)
+}
+
+func (s *SeriesService) FindSeriesWorks(ctx context.Context, filter bookid.SeriesWorkFilter) ([]*bookid.SeriesWork, int, error) {
+	return s.FindSeriesWorksFn(ctx, filter)
+}
@@ -0,0 +1,39 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/fwojciec/bookid"
+)
+
+// Ensure type implements interface.
+var _ bookid.SubjectService = (*SubjectService)(nil)
+
+// SubjectService represents a mock of bookid.SubjectService.
+type SubjectService struct {
+	FindSubjectByIDFn   func(ctx context.Context, id int64) (*bookid.Subject, error)
+	FindSubjectsFn      func(ctx context.Context, filter bookid.SubjectFilter) ([]*bookid.Subject, int, error)
+	CreateSubjectFn     func(ctx context.Context, subject *bookid.Subject) error
+	AddWorkSubjectFn    func(ctx context.Context, ws *bookid.WorkSubject) error
+	RemoveWorkSubjectFn func(ctx context.Context, ws *bookid.WorkSubject) error
+}
+
+func (s *SubjectService) FindSubjectByID(ctx context.Context, id int64) (*bookid.Subject, error) {
+	return s.FindSubjectByIDFn(ctx, id)
+}
+
+func (s *SubjectService) FindSubjects(ctx context.Context, filter bookid.SubjectFilter) ([]*bookid.Subject, int, error) {
+	return s.FindSubjectsFn(ctx, filter)
+}
+
+func (s *SubjectService) CreateSubject(ctx context.Context, subject *bookid.Subject) error {
+	return s.CreateSubjectFn(ctx, subject)
+}
+
+func (s *SubjectService) AddWorkSubject(ctx context.Context, ws *bookid.WorkSubject) error {
+	return s.AddWorkSubjectFn(ctx, ws)
+}
+
+func (s *SubjectService) RemoveWorkSubject(ctx context.Context, ws *bookid.WorkSubject) error {
+	return s.RemoveWorkSubjectFn(ctx, ws)
+}
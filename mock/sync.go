@@ -0,0 +1,25 @@
+package mock
+
+import (
+	"context"
+	"time"
+
+	"github.com/fwojciec/bookid"
+)
+
+// Ensure type implements interface.
+var _ bookid.SyncService = (*SyncService)(nil)
+
+// SyncService represents a mock of bookid.SyncService.
+type SyncService struct {
+	FindChangesFn  func(ctx context.Context, since time.Time) (*bookid.SyncChanges, error)
+	ApplyChangesFn func(ctx context.Context, changes *bookid.SyncChanges, opts bookid.SyncOptions) (*bookid.SyncReport, error)
+}
+
+func (s *SyncService) FindChanges(ctx context.Context, since time.Time) (*bookid.SyncChanges, error) {
+	return s.FindChangesFn(ctx, since)
+}
+
+func (s *SyncService) ApplyChanges(ctx context.Context, changes *bookid.SyncChanges, opts bookid.SyncOptions) (*bookid.SyncReport, error) {
+	return s.ApplyChangesFn(ctx, changes, opts)
+}
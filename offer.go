@@ -0,0 +1,103 @@
+package bookid
+
+import (
+	"context"
+	"time"
+)
+
+// Offer represents the price and availability of a publication as reported by
+// a provider at the time it was fetched. Offers are kept as a history, so
+// prices can be monitored over time.
+type Offer struct {
+	ID            int64        `json:"id"`
+	PublicationID int64        `json:"publication_id"`
+	Provider      string       `json:"provider"`
+	Country       string       `json:"country,omitempty"`      // ISO 3166-1 alpha-2 code of the market, e.g. "US"
+	Currency      string       `json:"currency,omitempty"`     // ISO 4217 code, e.g. "USD"
+	ListPrice     float64      `json:"list_price,omitempty"`   // Publisher's suggested price; zero if unknown
+	RetailPrice   float64      `json:"retail_price,omitempty"` // Price the retailer charges; zero if unknown
+	Availability  Availability `json:"availability,omitempty"` // Empty if unknown
+	URL           string       `json:"url,omitempty"`          // Where the publication can be bought
+	FetchedAt     time.Time    `json:"fetched_at"`
+}
+
+// Validate returns an error if the offer contains invalid fields.
+func (o *Offer) Validate() error {
+	if o.PublicationID == 0 {
+		return Errorf(EINVALID, "Offer publication required.")
+	} else if o.Provider == "" {
+		return Errorf(EINVALID, "Offer provider required.")
+	} else if o.ListPrice < 0 || o.RetailPrice < 0 {
+		return Errorf(EINVALID, "Prices must not be negative.")
+	} else if (o.ListPrice != 0 || o.RetailPrice != 0) && o.Currency == "" {
+		return Errorf(EINVALID, "Offer currency required with a price.")
+	} else if o.Availability != "" && !o.Availability.Valid() {
+		return Errorf(EINVALID, "Invalid availability %q.", o.Availability)
+	}
+	return nil
+}
+
+// Availability represents whether a publication can be bought.
+type Availability string
+
+// Availabilities of offers.
+const (
+	AvailabilityForSale    Availability = "for_sale"
+	AvailabilityNotForSale Availability = "not_for_sale"
+	AvailabilityFree       Availability = "free"
+	AvailabilityPreorder   Availability = "preorder"
+)
+
+// Valid returns true if the availability is one of the known availabilities.
+func (a Availability) Valid() bool {
+	switch a {
+	case AvailabilityForSale, AvailabilityNotForSale, AvailabilityFree, AvailabilityPreorder:
+		return true
+	}
+	return false
+}
+
+// OfferFinder looks up the current offers of a book. Implemented by providers
+// that report prices, such as Google Books and ISBNdb.
+type OfferFinder interface {
+	// LookupOffers returns the offers the provider has for the book with
+	// the given ISBN, without IDs or publications. Returns an empty list if
+	// it has none.
+	LookupOffers(ctx context.Context, isbn string) ([]Offer, error)
+}
+
+// OfferService represents a service for recording the offers of
+// publications.
+type OfferService interface {
+	// FindOffers retrieves a list of offers matching the filter, most
+	// recently fetched first, along with the total number of matches,
+	// ignoring Offset and Limit.
+	FindOffers(ctx context.Context, filter OfferFilter) ([]*Offer, int, error)
+
+	// CreateOffer records an offer. Sets the ID, and the fetch time if it is
+	// zero, on success. Returns ENOTFOUND if the publication does not exist.
+	CreateOffer(ctx context.Context, offer *Offer) error
+}
+
+// OfferFilter represents a filter used by FindOffers.
+type OfferFilter struct {
+	PublicationID *int64
+	Provider      *string
+
+	// Latest restricts results to the most recently fetched offer of each
+	// provider for each publication.
+	Latest bool
+
+	// Restrict to subset of results.
+	Offset int
+	Limit  int
+}
+
+// PricingService represents a service for fetching the prices and
+// availability of cataloged publications from the providers that report them.
+type PricingService interface {
+	// FetchOffers looks up the current offers of a publication by ISBN and
+	// records them. Returns ENOTFOUND if the publication does not exist and
+	// EINVALID if it has no ISBN.
+	FetchOffers(ctx context.Context, publicationID int64) ([]*Offer, error)
+}
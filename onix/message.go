@@ -0,0 +1,136 @@
+package onix
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// sentDateTimeFormat is the layout of the message header timestamp.
+const sentDateTimeFormat = "20060102T1504Z"
+
+// Writer writes products as an ONIX 3.0 message.
+type Writer struct {
+	w           io.Writer
+	enc         *xml.Encoder
+	wroteHeader bool
+	wroteRecord bool
+
+	// Sender named in the message header.
+	SenderName string
+
+	// Returns the time stamped on the message header.
+	Now func() time.Time
+}
+
+// NewWriter returns a new Writer that writes to w.
+func NewWriter(w io.Writer) *Writer {
+	enc := xml.NewEncoder(w)
+	enc.Indent("  ", "  ")
+	return &Writer{w: w, enc: enc, SenderName: "bookid", Now: time.Now}
+}
+
+// Write writes a single product, preceded by the message header on first
+// use.
+func (w *Writer) Write(p *Product) error {
+	if err := w.writeHeader(); err != nil {
+		return err
+	}
+	w.wroteRecord = true
+	return w.enc.Encode(p)
+}
+
+// Close writes the end of the message. If no products were written, a
+// message with only a header is still emitted. Close does not close the
+// underlying writer.
+func (w *Writer) Close() error {
+	if err := w.writeHeader(); err != nil {
+		return err
+	} else if err := w.enc.Flush(); err != nil {
+		return err
+	}
+	end := "</ONIXMessage>\n"
+	if w.wroteRecord {
+		end = "\n" + end
+	}
+	_, err := io.WriteString(w.w, end)
+	return err
+}
+
+// writeHeader writes the XML declaration, the start of the message and its
+// header once. Products inherit the message's default namespace.
+func (w *Writer) writeHeader() error {
+	if w.wroteHeader {
+		return nil
+	}
+	w.wroteHeader = true
+
+	if _, err := io.WriteString(w.w, xml.Header+`<ONIXMessage release="`+Release+`" xmlns="`+Namespace+`">`+"\n"); err != nil {
+		return err
+	}
+	var header struct {
+		XMLName      xml.Name `xml:"Header"`
+		SenderName   string   `xml:"Sender>SenderName"`
+		SentDateTime string   `xml:"SentDateTime"`
+	}
+	header.SenderName = w.SenderName
+	header.SentDateTime = w.Now().UTC().Format(sentDateTimeFormat)
+	return w.enc.Encode(header)
+}
+
+// Reader reads products from an ONIX 3.0 message one at a time, so large
+// publisher feeds need not fit in memory.
+type Reader struct {
+	dec *xml.Decoder
+}
+
+// NewReader returns a new Reader that reads from r.
+func NewReader(r io.Reader) *Reader {
+	return &Reader{dec: xml.NewDecoder(r)}
+}
+
+// Read returns the next product of the message. It returns io.EOF when there
+// are no more products.
+func (r *Reader) Read() (*Product, error) {
+	for {
+		tok, err := r.dec.Token()
+		if errors.Is(err, io.EOF) {
+			return nil, io.EOF
+		} else if err != nil {
+			return nil, fmt.Errorf("onix: reading message: %w", err)
+		}
+
+		start, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		switch start.Name.Local {
+		case "ONIXMessage":
+			if release := attr(start, "release"); release != "" && release[0] != '3' {
+				return nil, fmt.Errorf("onix: unsupported release %q", release)
+			}
+		case "Product":
+			var p Product
+			if err := r.dec.DecodeElement(&p, &start); err != nil {
+				return nil, fmt.Errorf("onix: decoding product: %w", err)
+			}
+			return &p, nil
+		case "Header":
+			if err := r.dec.Skip(); err != nil {
+				return nil, fmt.Errorf("onix: reading message: %w", err)
+			}
+		}
+	}
+}
+
+// attr returns the value of the named attribute of an element.
+func attr(start xml.StartElement, name string) string {
+	for _, a := range start.Attr {
+		if a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return ""
+}
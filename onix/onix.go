@@ -0,0 +1,543 @@
+// Package onix reads and writes ONIX for Books 3.0 messages, the metadata
+// format publishers and distributors exchange product information in. Only
+// the reference (long) tag names are supported.
+package onix
+
+import (
+	"cmp"
+	"encoding/xml"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/bisac"
+	"github.com/fwojciec/bookid/dimension"
+	"github.com/fwojciec/bookid/isbn"
+	"github.com/fwojciec/bookid/language"
+)
+
+// ProviderName identifies results read from ONIX messages.
+const ProviderName = "onix"
+
+// Namespace is the XML namespace of ONIX 3.0 messages with reference tags.
+const Namespace = "http://ns.editeur.org/onix/3.0/reference"
+
+// Release is the ONIX release written to messages.
+const Release = "3.0"
+
+// Product identifier types (ONIX code list 5).
+const (
+	IDTypeProprietary = "01"
+	IDTypeISBN10      = "02"
+	IDTypeGTIN13      = "03"
+	IDTypeDOI         = "06"
+	IDTypeLCCN        = "13"
+	IDTypeISBN13      = "15"
+	IDTypeOCLC        = "23"
+)
+
+// Codes from other ONIX code lists used by this package.
+const (
+	notificationConfirmed   = "03"  // List 1: notification confirmed on publication
+	compositionSingleItem   = "00"  // List 2: single-component retail product
+	formBook                = "BA"  // List 150: book, detail unspecified
+	formHardback            = "BB"  // List 150: hardback
+	formPaperback           = "BC"  // List 150: paperback / softback
+	formBoardBook           = "BH"  // List 150: board book
+	formDigitalDownload     = "ED"  // List 150: digital download
+	formDownloadableAudio   = "AJ"  // List 150: downloadable audio file
+	titleTypeDistinctive    = "01"  // List 15: distinctive title
+	titleLevelProduct       = "01"  // List 149: product level
+	roleByAuthor            = "A01" // List 17: by (author)
+	roleIllustratedBy       = "A12" // List 17: illustrated by
+	roleEditedBy            = "B01" // List 17: edited by
+	roleTranslatedBy        = "B06" // List 17: translated by
+	roleReadBy              = "E07" // List 17: read by (narrator)
+	languageRoleText        = "01"  // List 22: language of text
+	publishingRolePublisher = "01"  // List 45: publisher
+	dateRolePublication     = "01"  // List 163: publication date
+	dateFormatYear          = "05"  // List 55: YYYY
+	measureHeight           = "01"  // List 48: height
+	measureWidth            = "02"  // List 48: width
+	measureThickness        = "03"  // List 48: thickness
+	measureWeight           = "08"  // List 48: unit weight
+	extentMainContent       = "00"  // List 23: main content page count
+	extentUnitPages         = "03"  // List 24: pages
+	subjectSchemeBISAC      = "10"  // List 26: BISAC subject heading
+	subjectSchemeKeywords   = "20"  // List 27: keywords
+)
+
+// Product is the subset of an ONIX product record that bookid reads and
+// writes.
+type Product struct {
+	XMLName            xml.Name            `xml:"Product"`
+	RecordReference    string              `xml:"RecordReference"`
+	NotificationType   string              `xml:"NotificationType"`
+	ProductIdentifiers []ProductIdentifier `xml:"ProductIdentifier"`
+	DescriptiveDetail  DescriptiveDetail   `xml:"DescriptiveDetail"`
+	PublishingDetail   PublishingDetail    `xml:"PublishingDetail"`
+}
+
+// ProductIdentifier identifies a product in a given scheme.
+type ProductIdentifier struct {
+	ProductIDType string `xml:"ProductIDType"`
+	IDTypeName    string `xml:"IDTypeName,omitempty"` // Proprietary schemes only
+	IDValue       string `xml:"IDValue"`
+}
+
+// DescriptiveDetail describes the form and content of a product.
+type DescriptiveDetail struct {
+	ProductComposition string        `xml:"ProductComposition"`
+	ProductForm        string        `xml:"ProductForm"`
+	Measures           []Measure     `xml:"Measure"`
+	TitleDetails       []TitleDetail `xml:"TitleDetail"`
+	Contributors       []Contributor `xml:"Contributor"`
+	Languages          []Language    `xml:"Language"`
+	Extents            []Extent      `xml:"Extent"`
+	Subjects           []Subject     `xml:"Subject"`
+}
+
+// Measure is a physical dimension of a product.
+type Measure struct {
+	MeasureType     string  `xml:"MeasureType"`
+	Measurement     float64 `xml:"Measurement"`
+	MeasureUnitCode string  `xml:"MeasureUnitCode"` // List 50, e.g. "mm" or "in"
+}
+
+// Extent is the extent of a product's content, such as its page count.
+type Extent struct {
+	ExtentType  string `xml:"ExtentType"`
+	ExtentValue int    `xml:"ExtentValue"`
+	ExtentUnit  string `xml:"ExtentUnit"`
+}
+
+// Subject is a subject of a product: a code in a subject scheme such as
+// BISAC, or keywords separated by semicolons.
+type Subject struct {
+	MainSubject             *struct{} `xml:"MainSubject,omitempty"`
+	SubjectSchemeIdentifier string    `xml:"SubjectSchemeIdentifier"`
+	SubjectCode             string    `xml:"SubjectCode,omitempty"`
+	SubjectHeadingText      string    `xml:"SubjectHeadingText,omitempty"`
+}
+
+// TitleDetail is a title of a product.
+type TitleDetail struct {
+	TitleType     string         `xml:"TitleType"`
+	TitleElements []TitleElement `xml:"TitleElement"`
+}
+
+// TitleElement is a part of a title. Titles may be sent either as a whole in
+// TitleText or split into a prefix such as "The" and the rest.
+type TitleElement struct {
+	TitleElementLevel  string `xml:"TitleElementLevel"`
+	TitlePrefix        string `xml:"TitlePrefix,omitempty"`
+	TitleWithoutPrefix string `xml:"TitleWithoutPrefix,omitempty"`
+	TitleText          string `xml:"TitleText,omitempty"`
+	Subtitle           string `xml:"Subtitle,omitempty"`
+}
+
+// Contributor is a person or organization credited for a product.
+type Contributor struct {
+	SequenceNumber     int    `xml:"SequenceNumber,omitempty"`
+	ContributorRole    string `xml:"ContributorRole"`
+	PersonName         string `xml:"PersonName,omitempty"`
+	PersonNameInverted string `xml:"PersonNameInverted,omitempty"`
+	CorporateName      string `xml:"CorporateName,omitempty"`
+}
+
+// Language is a language associated with a product.
+type Language struct {
+	LanguageRole string `xml:"LanguageRole"`
+	LanguageCode string `xml:"LanguageCode"` // ISO 639-2/B
+}
+
+// PublishingDetail describes the publisher and publishing dates.
+type PublishingDetail struct {
+	Publishers      []Publisher      `xml:"Publisher"`
+	PublishingDates []PublishingDate `xml:"PublishingDate"`
+}
+
+// Publisher is a publisher of a product.
+type Publisher struct {
+	PublishingRole string `xml:"PublishingRole"`
+	PublisherName  string `xml:"PublisherName"`
+}
+
+// PublishingDate is a date associated with publishing a product.
+type PublishingDate struct {
+	PublishingDateRole string `xml:"PublishingDateRole"`
+	Date               Date   `xml:"Date"`
+}
+
+// Date is an ONIX date with its format code. The default format is YYYYMMDD.
+type Date struct {
+	Format string `xml:"dateformat,attr,omitempty"`
+	Value  string `xml:",chardata"`
+}
+
+// Year returns the year of the date, or zero if it has none.
+func (d Date) Year() int {
+	v := strings.TrimSpace(d.Value)
+	if len(v) < 4 {
+		return 0
+	}
+	year, err := strconv.Atoi(v[:4])
+	if err != nil {
+		return 0
+	}
+	return year
+}
+
+// NewProduct returns a product record for a publication of work. Authors are
+// the work's linked authors and other contributors, in order; if there are
+// none the work's credited author is used. pub may be nil for works without publications.
+func NewProduct(work *bookid.Work, authors []*bookid.Author, pub *bookid.Publication) *Product {
+	p := &Product{
+		NotificationType: notificationConfirmed,
+		DescriptiveDetail: DescriptiveDetail{
+			ProductComposition: compositionSingleItem,
+			ProductForm:        formBook,
+		},
+	}
+
+	if pub != nil {
+		p.DescriptiveDetail.ProductForm = formCode(pub.Binding)
+		p.RecordReference = "bookid-" + strconv.FormatInt(pub.ID, 10)
+		p.addIdentifier(IDTypeISBN13, pub.ISBN13)
+		p.addIdentifier(IDTypeISBN10, pub.ISBN10)
+		p.addIdentifier(IDTypeDOI, pub.DOI)
+		p.addIdentifier(IDTypeLCCN, pub.LCCN)
+		p.addIdentifier(IDTypeOCLC, pub.OCLCNumber)
+	} else {
+		p.RecordReference = "bookid-w" + strconv.FormatInt(work.ID, 10)
+	}
+	// Every product needs an identifier; the record reference always works.
+	p.ProductIdentifiers = append(p.ProductIdentifiers, ProductIdentifier{
+		ProductIDType: IDTypeProprietary,
+		IDTypeName:    "bookid",
+		IDValue:       p.RecordReference,
+	})
+
+	title, subtitle, _ := strings.Cut(work.Title, ": ")
+	p.DescriptiveDetail.TitleDetails = []TitleDetail{{
+		TitleType: titleTypeDistinctive,
+		TitleElements: []TitleElement{{
+			TitleElementLevel: titleLevelProduct,
+			TitleText:         title,
+			Subtitle:          subtitle,
+		}},
+	}}
+
+	for i, a := range authors {
+		p.DescriptiveDetail.Contributors = append(p.DescriptiveDetail.Contributors, Contributor{
+			SequenceNumber:  i + 1,
+			ContributorRole: roleCode(a.Role),
+			PersonName:      a.Name,
+		})
+	}
+	if len(authors) == 0 && work.Author != "" {
+		p.DescriptiveDetail.Contributors = []Contributor{{
+			SequenceNumber:  1,
+			ContributorRole: roleByAuthor,
+			PersonName:      work.Author,
+		}}
+	}
+
+	if pub == nil {
+		return p
+	}
+	if code := language.ToMARC(pub.Language); len(code) == 3 {
+		p.DescriptiveDetail.Languages = []Language{{
+			LanguageRole: languageRoleText,
+			LanguageCode: code,
+		}}
+	}
+	if pub.PageCount > 0 {
+		p.DescriptiveDetail.Extents = []Extent{{
+			ExtentType:  extentMainContent,
+			ExtentValue: pub.PageCount,
+			ExtentUnit:  extentUnitPages,
+		}}
+	}
+	p.addMeasure(measureHeight, pub.Dimensions.Height, "mm")
+	p.addMeasure(measureWidth, pub.Dimensions.Width, "mm")
+	p.addMeasure(measureThickness, pub.Dimensions.Thickness, "mm")
+	p.addMeasure(measureWeight, pub.Dimensions.Weight, "gr")
+	if pub.Publisher != "" {
+		p.PublishingDetail.Publishers = []Publisher{{
+			PublishingRole: publishingRolePublisher,
+			PublisherName:  pub.Publisher,
+		}}
+	}
+	if pub.PublishedYear != 0 {
+		p.PublishingDetail.PublishingDates = []PublishingDate{{
+			PublishingDateRole: dateRolePublication,
+			Date:               Date{Format: dateFormatYear, Value: strconv.Itoa(pub.PublishedYear)},
+		}}
+	}
+	return p
+}
+
+// AddSubjects adds the subjects of the product's work: those with a BISAC
+// code as BISAC subjects and the others as keywords. The main subject is the
+// first with a specific code, as broad codes such as "FIC000000" only name
+// the section of the store.
+func (p *Product) AddSubjects(subjects []*bookid.Subject) {
+	var coded []Subject
+	var keywords []string
+	for _, s := range subjects {
+		if s.BISACCode == "" {
+			keywords = append(keywords, s.Name)
+			continue
+		}
+		coded = append(coded, Subject{SubjectSchemeIdentifier: subjectSchemeBISAC, SubjectCode: s.BISACCode})
+	}
+	slices.SortStableFunc(coded, func(a, b Subject) int {
+		return cmp.Compare(broadBISAC(a.SubjectCode), broadBISAC(b.SubjectCode))
+	})
+	if len(coded) > 0 {
+		coded[0].MainSubject = &struct{}{}
+	}
+	p.DescriptiveDetail.Subjects = append(p.DescriptiveDetail.Subjects, coded...)
+	if len(keywords) > 0 {
+		p.DescriptiveDetail.Subjects = append(p.DescriptiveDetail.Subjects, Subject{
+			SubjectSchemeIdentifier: subjectSchemeKeywords,
+			SubjectHeadingText:      strings.Join(keywords, ";"),
+		})
+	}
+}
+
+// broadBISAC returns 1 for the broad "General" code of a BISAC section, such
+// as "FIC000000", and 0 for specific codes, for sorting.
+func broadBISAC(code string) int {
+	if strings.HasSuffix(code, "000000") {
+		return 1
+	}
+	return 0
+}
+
+// addIdentifier appends an identifier unless value is empty.
+func (p *Product) addIdentifier(typ, value string) {
+	if value != "" {
+		p.ProductIdentifiers = append(p.ProductIdentifiers, ProductIdentifier{ProductIDType: typ, IDValue: value})
+	}
+}
+
+// addMeasure appends a measure unless it is unknown.
+func (p *Product) addMeasure(typ string, v float64, unit string) {
+	if v > 0 {
+		p.DescriptiveDetail.Measures = append(p.DescriptiveDetail.Measures, Measure{MeasureType: typ, Measurement: v, MeasureUnitCode: unit})
+	}
+}
+
+// BookResult converts the product to a BookResult so it can be cataloged like
+// a provider result.
+func (p *Product) BookResult() bookid.BookResult {
+	result := bookid.BookResult{
+		Title:      p.title(),
+		Authors:    []string{},
+		Provider:   ProviderName,
+		Confidence: 1,
+	}
+
+	for _, id := range p.ProductIdentifiers {
+		value := strings.TrimSpace(id.IDValue)
+		switch id.ProductIDType {
+		case IDTypeISBN13, IDTypeGTIN13:
+			if code := isbn.Normalize(value); isbn.Valid13(code) && result.ISBN13 == "" {
+				result.ISBN13 = code
+			}
+		case IDTypeISBN10:
+			if code := isbn.Normalize(value); isbn.Valid10(code) && result.ISBN10 == "" {
+				result.ISBN10 = code
+			}
+		case IDTypeDOI:
+			result.DOI = value
+		case IDTypeLCCN:
+			result.LCCN = value
+		case IDTypeOCLC:
+			result.OCLCNumber = value
+		}
+	}
+
+	for _, c := range p.DescriptiveDetail.Contributors {
+		name := c.name()
+		if name == "" {
+			continue
+		}
+		switch role := contributorRole(c.ContributorRole); role {
+		case bookid.ContributorRoleAuthor:
+			result.Authors = append(result.Authors, name)
+		case "":
+		default:
+			result.Contributors = append(result.Contributors, bookid.Contributor{Name: name, Role: role})
+		}
+	}
+	result.Binding = productBinding(p.DescriptiveDetail.ProductForm)
+	for _, e := range p.DescriptiveDetail.Extents {
+		if e.ExtentType == extentMainContent && e.ExtentUnit == extentUnitPages {
+			result.PageCount = e.ExtentValue
+			break
+		}
+	}
+	for _, m := range p.DescriptiveDetail.Measures {
+		value := strconv.FormatFloat(m.Measurement, 'f', -1, 64) + " " + m.MeasureUnitCode
+		switch m.MeasureType {
+		case measureHeight:
+			result.Dimensions.Height = dimension.Length(value)
+		case measureWidth:
+			result.Dimensions.Width = dimension.Length(value)
+		case measureThickness:
+			result.Dimensions.Thickness = dimension.Length(value)
+		case measureWeight:
+			result.Dimensions.Weight = dimension.Weight(value)
+		}
+	}
+	for _, s := range p.DescriptiveDetail.Subjects {
+		switch s.SubjectSchemeIdentifier {
+		case subjectSchemeBISAC:
+			code := strings.ToUpper(strings.TrimSpace(s.SubjectCode))
+			if code == "" {
+				continue
+			}
+			result.BISACCodes = append(result.BISACCodes, code)
+			if heading := cmp.Or(strings.TrimSpace(s.SubjectHeadingText), bisac.Heading(code)); heading != "" {
+				result.Subjects = append(result.Subjects, heading)
+			}
+		case subjectSchemeKeywords:
+			for _, k := range strings.Split(s.SubjectHeadingText, ";") {
+				if k = strings.TrimSpace(k); k != "" {
+					result.Subjects = append(result.Subjects, k)
+				}
+			}
+		}
+	}
+	for _, l := range p.DescriptiveDetail.Languages {
+		if l.LanguageRole == languageRoleText {
+			result.Language = language.FromMARC(strings.ToLower(l.LanguageCode))
+			break
+		}
+	}
+	for _, pub := range p.PublishingDetail.Publishers {
+		if pub.PublishingRole == publishingRolePublisher {
+			result.Publisher = strings.TrimSpace(pub.PublisherName)
+			break
+		}
+	}
+	for _, d := range p.PublishingDetail.PublishingDates {
+		if d.PublishingDateRole == dateRolePublication {
+			result.PublishedYear = d.Date.Year()
+			break
+		}
+	}
+	return result
+}
+
+// formCode returns the ONIX product form code of a binding.
+func formCode(b bookid.Binding) string {
+	switch b {
+	case bookid.BindingHardcover:
+		return formHardback
+	case bookid.BindingPaperback:
+		return formPaperback
+	case bookid.BindingEbook:
+		return formDigitalDownload
+	case bookid.BindingAudiobook:
+		return formDownloadableAudio
+	default:
+		return formBook
+	}
+}
+
+// productBinding returns the binding of an ONIX product form code, or an
+// empty binding for forms without one. Every audio ("A") and digital ("E")
+// form counts.
+func productBinding(code string) bookid.Binding {
+	switch {
+	case code == formHardback || code == formBoardBook:
+		return bookid.BindingHardcover
+	case code == formPaperback:
+		return bookid.BindingPaperback
+	case strings.HasPrefix(code, "E"):
+		return bookid.BindingEbook
+	case strings.HasPrefix(code, "A"):
+		return bookid.BindingAudiobook
+	default:
+		return ""
+	}
+}
+
+// roleCode returns the ONIX contributor role code of a contributor role.
+func roleCode(role bookid.ContributorRole) string {
+	switch role {
+	case bookid.ContributorRoleTranslator:
+		return roleTranslatedBy
+	case bookid.ContributorRoleEditor:
+		return roleEditedBy
+	case bookid.ContributorRoleIllustrator:
+		return roleIllustratedBy
+	case bookid.ContributorRoleNarrator:
+		return roleReadBy
+	default:
+		return roleByAuthor
+	}
+}
+
+// contributorRole returns the contributor role of an ONIX contributor role
+// code, or an empty role for codes bookid does not record.
+func contributorRole(code string) bookid.ContributorRole {
+	switch code {
+	case roleByAuthor:
+		return bookid.ContributorRoleAuthor
+	case roleTranslatedBy:
+		return bookid.ContributorRoleTranslator
+	case roleEditedBy:
+		return bookid.ContributorRoleEditor
+	case roleIllustratedBy:
+		return bookid.ContributorRoleIllustrator
+	case roleReadBy:
+		return bookid.ContributorRoleNarrator
+	default:
+		return ""
+	}
+}
+
+// title returns the distinctive title of the product, joined with its
+// subtitle.
+func (p *Product) title() string {
+	for _, td := range p.DescriptiveDetail.TitleDetails {
+		if td.TitleType != titleTypeDistinctive {
+			continue
+		}
+		for _, te := range td.TitleElements {
+			if te.TitleElementLevel != titleLevelProduct {
+				continue
+			}
+			title := te.TitleText
+			if title == "" {
+				title = strings.TrimSpace(te.TitlePrefix + " " + te.TitleWithoutPrefix)
+			}
+			if te.Subtitle != "" {
+				title += ": " + te.Subtitle
+			}
+			return strings.TrimSpace(title)
+		}
+	}
+	return ""
+}
+
+// name returns the contributor's name in display order.
+func (c *Contributor) name() string {
+	switch {
+	case c.PersonName != "":
+		return strings.TrimSpace(c.PersonName)
+	case c.PersonNameInverted != "":
+		family, given, ok := strings.Cut(c.PersonNameInverted, ",")
+		if !ok {
+			return strings.TrimSpace(family)
+		}
+		return strings.TrimSpace(given) + " " + strings.TrimSpace(family)
+	}
+	return strings.TrimSpace(c.CorporateName)
+}
@@ -0,0 +1,165 @@
+package onix_test
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/onix"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewProduct(t *testing.T) {
+	t.Parallel()
+
+	t.Run("publication", func(t *testing.T) {
+		t.Parallel()
+		work := &bookid.Work{ID: 7, Title: "The Great Gatsby: A Novel", Author: "F. Scott Fitzgerald"}
+		authors := []*bookid.Author{{Name: "F. Scott Fitzgerald"}}
+		pub := &bookid.Publication{ID: 42, ISBN13: "9780743273565", DOI: "10.5555/gatsby", Publisher: "Scribner", PublishedYear: 2004, Language: "en", Binding: bookid.BindingEbook,
+			PageCount: 180, Dimensions: bookid.Dimensions{Height: 209.6, Width: 139.7, Weight: 181.4}}
+
+		result := onix.NewProduct(work, authors, pub).BookResult()
+		assert.Equal(t, "The Great Gatsby: A Novel", result.Title)
+		assert.Equal(t, []string{"F. Scott Fitzgerald"}, result.Authors)
+		assert.Equal(t, "9780743273565", result.ISBN13)
+		assert.Equal(t, "10.5555/gatsby", result.DOI)
+		assert.Equal(t, "Scribner", result.Publisher)
+		assert.Equal(t, 2004, result.PublishedYear)
+		assert.Equal(t, "en", result.Language)
+		assert.Equal(t, bookid.BindingEbook, result.Binding)
+		assert.Equal(t, 180, result.PageCount)
+		assert.Equal(t, bookid.Dimensions{Height: 209.6, Width: 139.7, Weight: 181.4}, result.Dimensions)
+	})
+
+	t.Run("subjects", func(t *testing.T) {
+		t.Parallel()
+		p := onix.NewProduct(&bookid.Work{ID: 3, Title: "Solaris"}, nil, nil)
+		p.AddSubjects([]*bookid.Subject{
+			{Name: "fiction", BISACCode: "FIC000000"},
+			{Name: "planets"},
+			{Name: "science fiction", BISACCode: "FIC028000"},
+			{Name: "psychological fiction"},
+		})
+
+		subjects := p.DescriptiveDetail.Subjects
+		require.Len(t, subjects, 3)
+		assert.Equal(t, "FIC028000", subjects[0].SubjectCode, "specific codes first")
+		assert.NotNil(t, subjects[0].MainSubject)
+		assert.Nil(t, subjects[1].MainSubject)
+		assert.Equal(t, "planets;psychological fiction", subjects[2].SubjectHeadingText)
+
+		result := p.BookResult()
+		assert.Equal(t, []string{"FIC028000", "FIC000000"}, result.BISACCodes)
+		assert.Equal(t, []string{"FICTION / Science Fiction / General", "FICTION / General", "planets", "psychological fiction"}, result.Subjects)
+	})
+
+	t.Run("contributors", func(t *testing.T) {
+		t.Parallel()
+		work := &bookid.Work{ID: 5, Title: "Solaris", Author: "Stanisław Lem"}
+		authors := []*bookid.Author{
+			{Name: "Stanisław Lem", Role: bookid.ContributorRoleAuthor},
+			{Name: "Bill Johnston", Role: bookid.ContributorRoleTranslator},
+			{Name: "Daniel Mróz", Role: bookid.ContributorRoleIllustrator},
+		}
+		p := onix.NewProduct(work, authors, nil)
+
+		contributors := p.DescriptiveDetail.Contributors
+		require.Len(t, contributors, 3)
+		assert.Equal(t, "A01", contributors[0].ContributorRole)
+		assert.Equal(t, "B06", contributors[1].ContributorRole)
+		assert.Equal(t, "A12", contributors[2].ContributorRole)
+		assert.Equal(t, 3, contributors[2].SequenceNumber)
+
+		result := p.BookResult()
+		assert.Equal(t, []string{"Stanisław Lem"}, result.Authors)
+		assert.Equal(t, []bookid.Contributor{
+			{Name: "Bill Johnston", Role: bookid.ContributorRoleTranslator},
+			{Name: "Daniel Mróz", Role: bookid.ContributorRoleIllustrator},
+		}, result.Contributors)
+	})
+
+	t.Run("work_only", func(t *testing.T) {
+		t.Parallel()
+		p := onix.NewProduct(&bookid.Work{ID: 3, Title: "Solaris", Author: "Stanisław Lem"}, nil, nil)
+
+		assert.Equal(t, "bookid-w3", p.RecordReference)
+		require.Len(t, p.ProductIdentifiers, 1)
+		assert.Equal(t, onix.IDTypeProprietary, p.ProductIdentifiers[0].ProductIDType)
+		assert.Equal(t, []string{"Stanisław Lem"}, p.BookResult().Authors)
+	})
+}
+
+func TestWriter(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	w := onix.NewWriter(&buf)
+	w.Now = func() time.Time { return time.Date(2024, 3, 9, 10, 30, 0, 0, time.UTC) }
+	pub := &bookid.Publication{ID: 42, ISBN13: "9780743273565", PublishedYear: 2004}
+	p := onix.NewProduct(&bookid.Work{Title: "Dune", Author: "Frank Herbert"}, nil, pub)
+	p.AddSubjects([]*bookid.Subject{{Name: "science fiction", BISACCode: "FIC028000"}})
+	require.NoError(t, w.Write(p))
+	require.NoError(t, w.Close())
+
+	out := buf.String()
+	assert.True(t, strings.HasPrefix(out, `<?xml version="1.0" encoding="UTF-8"?>`+"\n"+`<ONIXMessage release="3.0" xmlns="http://ns.editeur.org/onix/3.0/reference">`))
+	assert.Contains(t, out, "<SenderName>bookid</SenderName>")
+	assert.Contains(t, out, "<SentDateTime>20240309T1030Z</SentDateTime>")
+	assert.Contains(t, out, `<Date dateformat="05">2004</Date>`)
+	assert.Contains(t, out, "<SubjectCode>FIC028000</SubjectCode>")
+	assert.True(t, strings.HasSuffix(out, "</ONIXMessage>\n"))
+
+	// What we write reads back.
+	p, err := onix.NewReader(&buf).Read()
+	require.NoError(t, err)
+	assert.Equal(t, "Dune", p.BookResult().Title)
+	assert.Equal(t, "9780743273565", p.BookResult().ISBN13)
+	assert.Equal(t, []string{"FIC028000"}, p.BookResult().BISACCodes)
+}
+
+func TestReader(t *testing.T) {
+	t.Parallel()
+
+	t.Run("feed", func(t *testing.T) {
+		t.Parallel()
+		f, err := os.Open(filepath.Join("testdata", "feed.xml"))
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = f.Close() })
+
+		r := onix.NewReader(f)
+		p, err := r.Read()
+		require.NoError(t, err)
+
+		result := p.BookResult()
+		assert.Equal(t, "The Great Gatsby", result.Title)
+		assert.Equal(t, []string{"F. Scott Fitzgerald"}, result.Authors, "only authors are kept")
+		assert.Equal(t, "9780743273565", result.ISBN13, "GTIN-13 of a book is its ISBN")
+		assert.Equal(t, "Scribner", result.Publisher)
+		assert.Equal(t, 2004, result.PublishedYear)
+		assert.Equal(t, "en", result.Language)
+		assert.Equal(t, bookid.BindingPaperback, result.Binding)
+		assert.Equal(t, 180, result.PageCount)
+		assert.Equal(t, bookid.Dimensions{Height: 209.6}, result.Dimensions, "inches are converted")
+		assert.Equal(t, onix.ProviderName, result.Provider)
+
+		p, err = r.Read()
+		require.NoError(t, err)
+		assert.Empty(t, p.BookResult().Title)
+
+		_, err = r.Read()
+		assert.ErrorIs(t, err, io.EOF)
+	})
+
+	t.Run("unsupported_release", func(t *testing.T) {
+		t.Parallel()
+		_, err := onix.NewReader(strings.NewReader(`<ONIXMessage release="2.1"><Product/></ONIXMessage>`)).Read()
+		assert.ErrorContains(t, err, "unsupported release")
+	})
+}
@@ -0,0 +1,463 @@
+// Package openlibrary implements the BookFinder interface on top of the Open
+// Library Search and Books APIs.
+package openlibrary
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/binding"
+	"github.com/fwojciec/bookid/classify"
+	"github.com/fwojciec/bookid/description"
+	"github.com/fwojciec/bookid/dimension"
+	"github.com/fwojciec/bookid/internal/httpclient"
+	"github.com/fwojciec/bookid/isbn"
+	"github.com/fwojciec/bookid/language"
+	bookidquery "github.com/fwojciec/bookid/query"
+	"github.com/fwojciec/bookid/scoring"
+	"github.com/fwojciec/bookid/series"
+)
+
+// ProviderName identifies results produced by this package.
+const ProviderName = "openlibrary"
+
+// DefaultBaseURL is the root of the public Open Library API.
+const DefaultBaseURL = "https://openlibrary.org"
+
+// CoversBaseURL is the root of the Open Library Covers API.
+const CoversBaseURL = "https://covers.openlibrary.org"
+
+// defaultMaxResults mirrors the page size used by the Google Books client.
+const defaultMaxResults = 10
+
+// searchFields restricts the Search API response to the fields we map.
+const searchFields = "key,title,author_name,isbn,publisher,first_publish_year,language,cover_i,subject"
+
+// Client implements the BookFinder interface for the Open Library API.
+type Client struct {
+	httpClient *http.Client
+	baseURL    string
+
+	// Computes the confidence of each result.
+	Scorer *scoring.Scorer
+}
+
+// Register the provider so it can be enabled by name.
+func init() {
+	bookid.RegisterFinder(ProviderName, func(config bookid.ProviderConfig) (bookid.BookFinder, error) {
+		return NewClientWithBaseURL(config.Client(), DefaultBaseURL), nil
+	})
+}
+
+// NewClient creates a new Open Library API client. Open Library does not
+// require an API key.
+func NewClient() *Client {
+	return &Client{
+		httpClient: http.DefaultClient,
+		baseURL:    DefaultBaseURL,
+		Scorer:     scoring.Default(),
+	}
+}
+
+// NewClientWithBaseURL creates a new client against a custom endpoint (for testing)
+func NewClientWithBaseURL(httpClient *http.Client, baseURL string) *Client {
+	return &Client{
+		httpClient: httpClient,
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		Scorer:     scoring.Default(),
+	}
+}
+
+// Search performs a book search based on the provided query. ISBN queries and
+// links to Open Library editions are resolved through the Books API, links to
+// Goodreads books by the Goodreads IDs of editions and everything else
+// through the Search API, which also searches the rest of a query whose
+// edition is not found.
+func (c *Client) Search(ctx context.Context, query string, opts bookid.SearchOptions) ([]bookid.BookResult, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, errors.New("query cannot be empty")
+	} else if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	var results []bookid.BookResult
+	var err error
+	parsed := bookidquery.Parse(query)
+	switch {
+	case len(parsed.OpenLibraryIDs) > 0:
+		results, err = c.searchEdition(ctx, "OLID:"+parsed.OpenLibraryIDs[0], "", bookid.SearchTypeProviderID)
+	case len(parsed.GoodreadsIDs) > 0:
+		results, err = c.searchGeneral(ctx, bookid.ParsedQuery{GoodreadsIDs: parsed.GoodreadsIDs[:1]}, opts)
+		for i := range results {
+			results[i].SearchType = bookid.SearchTypeProviderID
+		}
+	case parsed.ISBN() != "":
+		results, err = c.searchISBN(ctx, parsed.ISBN())
+	default:
+		results, err = c.searchGeneral(ctx, parsed, opts)
+	}
+	if err == nil && len(results) == 0 && parsed.HasIdentifiers() && parsed.HasText() {
+		results, err = c.searchGeneral(ctx, parsed.WithoutIdentifiers(), opts)
+	}
+	if err != nil {
+		return nil, err
+	}
+	for i := range results {
+		results[i].Confidence = c.Scorer.Score(scoring.Input{Query: query, Options: opts, Result: results[i]})
+	}
+	return opts.Apply(results), nil
+}
+
+// searchISBN looks up a single edition by ISBN.
+func (c *Client) searchISBN(ctx context.Context, code string) ([]bookid.BookResult, error) {
+	return c.searchEdition(ctx, "ISBN:"+code, code, bookid.SearchTypeISBN)
+}
+
+// LookupClassification returns the Dewey and LC classification numbers of
+// the edition with the given ISBN, or a zero Classification if Open Library
+// has no such edition or records neither.
+func (c *Client) LookupClassification(ctx context.Context, code string) (bookid.Classification, error) {
+	code = isbn.Normalize(code)
+	if code == "" {
+		return bookid.Classification{}, bookid.Errorf(bookid.EINVALID, "ISBN required.")
+	}
+	results, err := c.searchISBN(ctx, code)
+	if err != nil || len(results) == 0 || results[0].Classification == nil {
+		return bookid.Classification{}, err
+	}
+	return *results[0].Classification, nil
+}
+
+// searchEdition looks up a single edition by a Books API bibkey such as
+// "ISBN:0743273567" or "OLID:OL7353617M". The ISBN searched for, if any,
+// fills in for editions that don't list it.
+func (c *Client) searchEdition(ctx context.Context, bibkey, code string, searchType bookid.SearchType) ([]bookid.BookResult, error) {
+	params := url.Values{
+		"bibkeys": {bibkey},
+		"format":  {"json"},
+		"jscmd":   {"details"},
+	}
+
+	var resp map[string]json.RawMessage
+	if err := c.get(ctx, "/api/books", params, &resp); err != nil {
+		return nil, err
+	}
+
+	raw, ok := resp[bibkey]
+	if !ok {
+		return []bookid.BookResult{}, nil
+	}
+
+	var book booksAPIEntry
+	if err := json.Unmarshal(raw, &book); err != nil {
+		return nil, fmt.Errorf("decoding open library edition: %w", err)
+	}
+
+	result := book.toBookResult(code)
+	result.SearchType = searchType
+	result.ProviderData = raw
+	return []bookid.BookResult{result}, nil
+}
+
+// searchGeneral performs a free-text search over works. Print type is
+// ignored as Open Library only catalogs books.
+func (c *Client) searchGeneral(ctx context.Context, q bookid.ParsedQuery, opts bookid.SearchOptions) ([]bookid.BookResult, error) {
+	limit := opts.MaxResults
+	if limit <= 0 {
+		limit = defaultMaxResults
+	}
+	query := formatQuery(q, cmp.Or(opts.Language, q.Language))
+	if query == "" {
+		return []bookid.BookResult{}, nil // only identifiers Open Library cannot search
+	}
+	params := url.Values{
+		"q":      {query},
+		"limit":  {strconv.Itoa(limit)},
+		"fields": {searchFields},
+	}
+	if opts.StartIndex > 0 {
+		params.Set("offset", strconv.Itoa(opts.StartIndex))
+	}
+	if opts.OrderBy == bookid.OrderByNewest {
+		params.Set("sort", "new")
+	}
+
+	var resp struct {
+		Docs []json.RawMessage `json:"docs"`
+	}
+	if err := c.get(ctx, "/search.json", params, &resp); err != nil {
+		return nil, err
+	}
+
+	results := make([]bookid.BookResult, 0, len(resp.Docs))
+	for _, raw := range resp.Docs {
+		var doc searchDoc
+		if err := json.Unmarshal(raw, &doc); err != nil {
+			return nil, fmt.Errorf("decoding open library search result: %w", err)
+		}
+		result := doc.toBookResult()
+		result.ProviderData = raw
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// get performs a GET request against the API and decodes the JSON response into v.
+func (c *Client) get(ctx context.Context, path string, params url.Values, v any) error {
+	return httpclient.GetJSON(ctx, c.httpClient, "Open Library", c.baseURL+path+"?"+params.Encode(), nil, v)
+}
+
+// booksAPIEntry is a single entry of a Books API response with jscmd=details.
+type booksAPIEntry struct {
+	InfoURL      string `json:"info_url"`
+	ThumbnailURL string `json:"thumbnail_url"`
+	Details      struct {
+		Title   string `json:"title"`
+		Authors []struct {
+			Name string `json:"name"`
+		} `json:"authors"`
+		Publishers  []string `json:"publishers"`
+		PublishDate string   `json:"publish_date"`
+		ISBN10      []string `json:"isbn_10"`
+		ISBN13      []string `json:"isbn_13"`
+		Languages   []struct {
+			Key string `json:"key"`
+		} `json:"languages"`
+		PhysicalFormat     string     `json:"physical_format"` // e.g. "Paperback" or "E-book"
+		NumberOfPages      int        `json:"number_of_pages"`
+		PhysicalDimensions string     `json:"physical_dimensions"` // e.g. "8.2 x 5.4 x 0.6 inches"
+		Weight             string     `json:"weight"`              // e.g. "6.4 ounces"
+		Covers             []int      `json:"covers"`
+		Series             []string   `json:"series"`
+		Subjects           []string   `json:"subjects"`
+		Description        text       `json:"description"`
+		TableOfContents    []tocEntry `json:"table_of_contents"`
+		DeweyDecimalClass  []string   `json:"dewey_decimal_class"` // e.g. "813/.52"
+		LCClassifications  []string   `json:"lc_classifications"`  // e.g. "PS3511.I9 G7 2004"
+	} `json:"details"`
+}
+
+// text is a text value, given either as a string or as an object such as
+// {"type": "/type/text", "value": "..."}.
+type text string
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (t *text) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*t = text(s)
+		return nil
+	}
+	var v struct {
+		Value string `json:"value"`
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*t = text(v.Value)
+	return nil
+}
+
+// tocEntry is an entry of a table of contents, given either as an object
+// with a title or, in older records, as a string.
+type tocEntry struct {
+	Title string `json:"title"`
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (e *tocEntry) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		e.Title = s
+		return nil
+	}
+	var v struct {
+		Title string `json:"title"`
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	e.Title = v.Title
+	return nil
+}
+
+// toBookResult converts an edition to our BookResult.
+func (e *booksAPIEntry) toBookResult(code string) bookid.BookResult {
+	d := e.Details
+	result := bookid.BookResult{
+		Title:         d.Title,
+		Authors:       make([]string, 0, len(d.Authors)),
+		PublishedYear: extractYear(d.PublishDate),
+		Binding:       binding.Parse(d.PhysicalFormat),
+		PageCount:     d.NumberOfPages,
+		Provider:      ProviderName,
+		SearchType:    bookid.SearchTypeISBN,
+	}
+	for _, a := range d.Authors {
+		result.Authors = append(result.Authors, a.Name)
+	}
+	if len(d.ISBN10) > 0 {
+		result.ISBN10 = d.ISBN10[0]
+	}
+	if len(d.ISBN13) > 0 {
+		result.ISBN13 = d.ISBN13[0]
+	}
+
+	// Fall back to the ISBN we searched for if the edition doesn't list it.
+	if result.ISBN10 == "" && result.ISBN13 == "" && code != "" {
+		if len(code) == 10 {
+			result.ISBN10 = code
+		} else {
+			result.ISBN13 = code
+		}
+	}
+
+	if len(d.Publishers) > 0 {
+		result.Publisher = d.Publishers[0]
+	}
+	if len(d.Languages) > 0 {
+		result.Language = language.FromMARC(strings.TrimPrefix(d.Languages[0].Key, "/languages/"))
+	}
+	if len(d.Series) > 0 {
+		result.Series, result.SeriesVolume = series.Parse(d.Series[0])
+	}
+	// Sizes are given as height by width by thickness.
+	if sizes := dimension.Lengths(d.PhysicalDimensions); len(sizes) == 3 {
+		result.Dimensions.Height, result.Dimensions.Width, result.Dimensions.Thickness = sizes[0], sizes[1], sizes[2]
+	}
+	result.Dimensions.Weight = dimension.Weight(d.Weight)
+	result.Subjects = d.Subjects
+	result.Description = description.Clean(string(d.Description))
+	for _, entry := range d.TableOfContents {
+		if title := strings.TrimSpace(entry.Title); title != "" {
+			result.TableOfContents = append(result.TableOfContents, title)
+		}
+	}
+	var c bookid.Classification
+	if len(d.DeweyDecimalClass) > 0 {
+		c.Dewey = d.DeweyDecimalClass[0]
+	}
+	if len(d.LCClassifications) > 0 {
+		c.LCC = d.LCClassifications[0]
+	}
+	if c = classify.Normalize(c); !c.IsZero() {
+		result.Classification = &c
+	}
+	if len(d.Covers) > 0 && d.Covers[0] > 0 {
+		result.ThumbnailURL = coverURL(d.Covers[0])
+	} else if e.ThumbnailURL != "" {
+		result.ThumbnailURL = httpclient.EnsureHTTPS(e.ThumbnailURL)
+	}
+
+	return result
+}
+
+// searchDoc is a single document of a Search API response.
+type searchDoc struct {
+	Key              string   `json:"key"`
+	Title            string   `json:"title"`
+	AuthorName       []string `json:"author_name"`
+	ISBN             []string `json:"isbn"`
+	Publisher        []string `json:"publisher"`
+	FirstPublishYear int      `json:"first_publish_year"`
+	Language         []string `json:"language"`
+	CoverID          int      `json:"cover_i"`
+	Subject          []string `json:"subject"`
+}
+
+// toBookResult converts a search document to our BookResult.
+func (d *searchDoc) toBookResult() bookid.BookResult {
+	result := bookid.BookResult{
+		Title:         d.Title,
+		Authors:       d.AuthorName,
+		PublishedYear: d.FirstPublishYear,
+		Subjects:      d.Subject,
+		Provider:      ProviderName,
+		SearchType:    bookid.SearchTypeGeneralQuery,
+	}
+	if result.Authors == nil {
+		result.Authors = []string{}
+	}
+
+	// Works aggregate identifiers from all editions so pick the first of each form.
+	for _, code := range d.ISBN {
+		switch {
+		case result.ISBN10 == "" && isbn.Valid10(code):
+			result.ISBN10 = code
+		case result.ISBN13 == "" && isbn.Valid13(code):
+			result.ISBN13 = code
+		}
+	}
+
+	if len(d.Publisher) > 0 {
+		result.Publisher = d.Publisher[0]
+	}
+	if len(d.Language) > 0 {
+		result.Language = language.FromMARC(d.Language[0])
+	}
+	if d.CoverID > 0 {
+		result.ThumbnailURL = coverURL(d.CoverID)
+	}
+
+	return result
+}
+
+// coverURL returns the medium-sized cover image URL for a cover ID.
+func coverURL(id int) string {
+	return fmt.Sprintf("%s/b/id/%d-M.jpg", CoversBaseURL, id)
+}
+
+// formatQuery returns the Search API query for q: its free text followed by
+// Solr field queries, restricted to lang if set. Goodreads IDs are searched
+// by the Goodreads IDs Open Library keeps for its editions.
+func formatQuery(q bookid.ParsedQuery, lang string) string {
+	parts := make([]string, 0, 6)
+	if q.Terms != "" {
+		parts = append(parts, q.Terms)
+	}
+	for _, field := range []struct{ name, value string }{
+		{"title", q.Title},
+		{"author", q.Author},
+		{"publisher", q.Publisher},
+	} {
+		if field.value != "" {
+			parts = append(parts, field.name+":"+strconv.Quote(field.value))
+		}
+	}
+	if q.Year != 0 {
+		parts = append(parts, "first_publish_year:"+strconv.Itoa(q.Year))
+	}
+	for _, code := range q.LCCNs {
+		parts = append(parts, "lccn:"+code)
+	}
+	for _, id := range q.GoodreadsIDs {
+		parts = append(parts, "id_goodreads:"+id)
+	}
+	if len(parts) > 0 && lang != "" {
+		parts = append(parts, "language:"+language.ToMARC(lang))
+	}
+	return strings.Join(parts, " ")
+}
+
+// extractYear extracts a four digit year from free-form dates such as
+// "2004", "April 2004" or "Apr 10, 2004".
+func extractYear(date string) int {
+	for _, field := range strings.FieldsFunc(date, func(r rune) bool { return r < '0' || r > '9' }) {
+		if len(field) != 4 {
+			continue
+		}
+		if year, err := strconv.Atoi(field); err == nil && year > 1000 && year < 3000 {
+			return year
+		}
+	}
+	return 0
+}
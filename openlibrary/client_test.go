@@ -0,0 +1,224 @@
+package openlibrary_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"testing"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/internal/httptestutil"
+	"github.com/fwojciec/bookid/openlibrary"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newClient returns a client replaying the given synthetic fixture, trimmed
+// and edited by hand rather than recorded. The URL of the last request is
+// stored in lastURL, if set.
+func newClient(t *testing.T, fixture string, lastURL *string) *openlibrary.Client {
+	t.Helper()
+	httpClient := httptestutil.Replay(t, filepath.Join("testdata", "synthetic", fixture))
+	if lastURL != nil {
+		httpClient = httptestutil.LastURL(httpClient, lastURL)
+	}
+	return openlibrary.NewClientWithBaseURL(httpClient, openlibrary.DefaultBaseURL)
+}
+
+func TestClient_Search(t *testing.T) {
+	t.Parallel()
+
+	t.Run("isbn", func(t *testing.T) {
+		t.Parallel()
+		var lastURL string
+		client := newClient(t, "isbn_9780743273565.json", &lastURL)
+
+		results, err := client.Search(context.Background(), "978-0-7432-7356-5", bookid.SearchOptions{IncludeRaw: true})
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		assert.Contains(t, lastURL, "/api/books?")
+		assert.Contains(t, lastURL, "bibkeys=ISBN%3A9780743273565")
+
+		r := results[0]
+		assert.Equal(t, "The Great Gatsby", r.Title)
+		assert.Equal(t, []string{"F. Scott Fitzgerald"}, r.Authors)
+		assert.Equal(t, "0743273567", r.ISBN10)
+		assert.Equal(t, "9780743273565", r.ISBN13)
+		assert.Equal(t, "Scribner", r.Publisher)
+		assert.Equal(t, 2004, r.PublishedYear)
+		assert.Equal(t, "en", r.Language)
+		assert.Equal(t, bookid.BindingPaperback, r.Binding)
+		assert.Equal(t, 180, r.PageCount)
+		assert.Equal(t, "The story of the mysteriously wealthy Jay Gatsby and his love for Daisy Buchanan.", r.Description)
+		assert.Equal(t, []string{"Chapter 1", "Chapter 2"}, r.TableOfContents)
+		assert.Equal(t, &bookid.Classification{Dewey: "813.52", LCC: "PS3511.I9 G7 2004"}, r.Classification)
+		assert.Equal(t, "https://covers.openlibrary.org/b/id/8432047-M.jpg", r.ThumbnailURL)
+		assert.Equal(t, openlibrary.ProviderName, r.Provider)
+		assert.Equal(t, bookid.SearchTypeISBN, r.SearchType)
+		assert.InDelta(t, 0.95, r.Confidence, 0.01)
+		assert.NotEmpty(t, r.ProviderData)
+		assert.Empty(t, r.GoogleBooksData)
+	})
+
+	t.Run("options", func(t *testing.T) {
+		t.Parallel()
+		var lastURL string
+		client := newClient(t, "search_gatsby_options.json", &lastURL)
+
+		results, err := client.Search(context.Background(), "the great gatsby", bookid.SearchOptions{
+			MaxResults: 1,
+			StartIndex: 20,
+			Language:   "pl",
+			OrderBy:    bookid.OrderByNewest,
+		})
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		assert.Contains(t, lastURL, "limit=1")
+		assert.Contains(t, lastURL, "offset=20")
+		assert.Contains(t, lastURL, "sort=new")
+		assert.Contains(t, lastURL, "q=the+great+gatsby+language%3Apol")
+		assert.Empty(t, results[0].ProviderData)
+	})
+
+	t.Run("fields", func(t *testing.T) {
+		t.Parallel()
+		var lastURL string
+		client := newClient(t, "search_gatsby_fields.json", &lastURL)
+
+		_, err := client.Search(context.Background(), `title:"The Great Gatsby" author:fitzgerald year:1925 lang:en`, bookid.SearchOptions{})
+		require.NoError(t, err)
+		u, err := url.Parse(lastURL)
+		require.NoError(t, err)
+		assert.Equal(t, `title:"The Great Gatsby" author:"fitzgerald" first_publish_year:1925 language:eng`, u.Query().Get("q"))
+	})
+
+	t.Run("edition_link", func(t *testing.T) {
+		t.Parallel()
+		var lastURL string
+		client := newClient(t, "olid_OL7349155M.json", &lastURL)
+
+		results, err := client.Search(context.Background(), "https://openlibrary.org/books/OL7349155M/The_Great_Gatsby", bookid.SearchOptions{})
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		assert.Contains(t, lastURL, "bibkeys=OLID%3AOL7349155M")
+		assert.Equal(t, "The Great Gatsby", results[0].Title)
+		assert.Equal(t, "9780743273565", results[0].ISBN13)
+		assert.Equal(t, bookid.SearchTypeProviderID, results[0].SearchType)
+	})
+
+	t.Run("goodreads_link", func(t *testing.T) {
+		t.Parallel()
+		var lastURL string
+		client := newClient(t, "search_goodreads_link.json", &lastURL)
+
+		results, err := client.Search(context.Background(), "https://www.goodreads.com/book/show/4671.The_Great_Gatsby", bookid.SearchOptions{})
+		require.NoError(t, err)
+		require.NotEmpty(t, results)
+		u, err := url.Parse(lastURL)
+		require.NoError(t, err)
+		assert.Equal(t, "id_goodreads:4671", u.Query().Get("q"))
+		assert.Equal(t, bookid.SearchTypeProviderID, results[0].SearchType)
+	})
+
+	t.Run("isbn_not_found", func(t *testing.T) {
+		t.Parallel()
+		client := newClient(t, "isbn_not_found.json", nil)
+
+		results, err := client.Search(context.Background(), "9780000000002", bookid.SearchOptions{})
+		require.NoError(t, err)
+		assert.Empty(t, results)
+	})
+
+	t.Run("general", func(t *testing.T) {
+		t.Parallel()
+		var lastURL string
+		client := newClient(t, "search_gatsby.json", &lastURL)
+
+		results, err := client.Search(context.Background(), "the great gatsby", bookid.SearchOptions{IncludeRaw: true})
+		require.NoError(t, err)
+		require.Len(t, results, 2)
+		assert.Contains(t, lastURL, "/search.json?")
+		assert.Contains(t, lastURL, "q=the+great+gatsby")
+		assert.Contains(t, lastURL, "limit=10")
+		assert.Contains(t, lastURL, "subject")
+
+		r := results[0]
+		assert.Equal(t, "The Great Gatsby", r.Title)
+		assert.Equal(t, []string{"F. Scott Fitzgerald"}, r.Authors)
+		assert.Equal(t, "0743273567", r.ISBN10)
+		assert.Equal(t, "9780743273565", r.ISBN13)
+		assert.Equal(t, "Scribner", r.Publisher)
+		assert.Equal(t, 1925, r.PublishedYear)
+		assert.Equal(t, "en", r.Language)
+		assert.Equal(t, "https://covers.openlibrary.org/b/id/10590366-M.jpg", r.ThumbnailURL)
+		assert.Equal(t, []string{"American fiction", "Long Island (N.Y.)", "nyt:combined-print-fiction=2013-05-26"}, r.Subjects)
+		assert.Equal(t, bookid.SearchTypeGeneralQuery, r.SearchType)
+		assert.InDelta(t, 0.70, r.Confidence, 0.01)
+		assert.Contains(t, string(r.ProviderData), "/works/OL468431W")
+
+		// Sparse documents lower the confidence.
+		assert.Less(t, results[1].Confidence, r.Confidence)
+		assert.Empty(t, results[1].ThumbnailURL)
+	})
+
+	t.Run("no_results", func(t *testing.T) {
+		t.Parallel()
+		client := newClient(t, "search_no_results.json", nil)
+
+		results, err := client.Search(context.Background(), "nonexistentbook12345", bookid.SearchOptions{})
+		require.NoError(t, err)
+		assert.Empty(t, results)
+	})
+}
+
+func TestClient_LookupClassification(t *testing.T) {
+	t.Parallel()
+
+	t.Run("found", func(t *testing.T) {
+		t.Parallel()
+		var lastURL string
+		client := newClient(t, "isbn_9780743273565.json", &lastURL)
+
+		c, err := client.LookupClassification(context.Background(), "978-0-7432-7356-5")
+		require.NoError(t, err)
+		assert.Contains(t, lastURL, "bibkeys=ISBN%3A9780743273565")
+		assert.Equal(t, bookid.Classification{Dewey: "813.52", LCC: "PS3511.I9 G7 2004"}, c)
+	})
+
+	t.Run("not_found", func(t *testing.T) {
+		t.Parallel()
+		client := newClient(t, "isbn_not_found.json", nil)
+
+		c, err := client.LookupClassification(context.Background(), "9780000000002")
+		require.NoError(t, err)
+		assert.True(t, c.IsZero())
+	})
+}
+
+func TestClient_Search_Errors(t *testing.T) {
+	t.Parallel()
+
+	t.Run("empty_query", func(t *testing.T) {
+		t.Parallel()
+		_, err := openlibrary.NewClient().Search(context.Background(), "  ", bookid.SearchOptions{})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "query cannot be empty")
+	})
+
+	t.Run("server_error", func(t *testing.T) {
+		t.Parallel()
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		t.Cleanup(srv.Close)
+		client := openlibrary.NewClientWithBaseURL(srv.Client(), srv.URL)
+
+		_, err := client.Search(context.Background(), "dune", bookid.SearchOptions{})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "503")
+
+		assert.Equal(t, bookid.EUNAVAILABLE, bookid.ErrorCode(err))
+	})
+}
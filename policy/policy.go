@@ -0,0 +1,213 @@
+// Package policy resolves conflicting values of a book's fields from
+// different providers according to configured merge policies, such as
+// preferring one provider's publishers or the most recent year.
+//
+// Without a policy for a field, its caller decides: merged search results
+// keep the value of the most preferred provider, and refreshes take the
+// provider's new value.
+package policy
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/asin"
+	"github.com/fwojciec/bookid/doi"
+	"github.com/fwojciec/bookid/isbn"
+	"github.com/fwojciec/bookid/language"
+	"github.com/fwojciec/bookid/lccn"
+	"github.com/fwojciec/bookid/match"
+	"github.com/fwojciec/bookid/publisher"
+)
+
+// Rule represents how a policy chooses between two values of a field.
+type Rule string
+
+// Rules.
+const (
+	RulePrefer  Rule = "prefer"  // Value of the provider listed first; unlisted providers come last
+	RuleNewest  Rule = "newest"  // Most recent year, for published_year only
+	RuleLongest Rule = "longest" // Value with the most characters
+	RuleReview  Rule = "review"  // Keep the current value and queue the other for review
+)
+
+// Rules returns the names of all rules.
+func Rules() []string {
+	return []string{string(RulePrefer), string(RuleNewest), string(RuleLongest), string(RuleReview)}
+}
+
+// Policy represents the merge policy of a field.
+type Policy struct {
+	Rule Rule
+
+	// Providers in order of preference, for RulePrefer.
+	Providers []string
+}
+
+// Policies represents the merge policies of fields by JSON name, e.g.
+// "publisher".
+type Policies map[string]Policy
+
+// Validate returns an error if a policy names an unknown field or rule, or
+// uses a rule the field does not support.
+func (p Policies) Validate() error {
+	for name, policy := range p {
+		field, ok := fields()[name]
+		if !ok {
+			return bookid.Errorf(bookid.EINVALID, "Unknown policy field %q, must be one of: %s.", name, strings.Join(Fields(), ", "))
+		}
+		switch policy.Rule {
+		case RulePrefer:
+			if len(policy.Providers) == 0 {
+				return bookid.Errorf(bookid.EINVALID, "Policy of %s must list the preferred providers.", name)
+			}
+		case RuleNewest:
+			if name != "published_year" {
+				return bookid.Errorf(bookid.EINVALID, "Policy of %s cannot be %q; only published_year can.", name, policy.Rule)
+			}
+		case RuleLongest:
+		case RuleReview:
+			if field.work {
+				return bookid.Errorf(bookid.EINVALID, "Policy of %s cannot be %q as it is a field of works.", name, policy.Rule)
+			}
+		default:
+			return bookid.Errorf(bookid.EINVALID, "Unknown policy rule %q, must be one of: %s.", policy.Rule, strings.Join(Rules(), ", "))
+		}
+	}
+	return nil
+}
+
+// Decision represents the outcome of resolving a conflict.
+type Decision int
+
+// Decisions.
+const (
+	Keep    Decision = iota // Keep the current value
+	Replace                 // Replace it with the candidate
+	Review                  // Keep the current value and queue the candidate for review
+)
+
+// Value is a value of a field with the provider that supplied it. Values of
+// published_year are decimal and authors are separated by "; ".
+type Value struct {
+	Value    string
+	Provider string // Empty if set by the user or unknown
+}
+
+// Resolve decides whether the candidate value of a field replaces the
+// current one. Empty candidates and those equal to the current value once
+// normalized are kept out, and empty current values replaced, whatever the
+// policy. def is the decision for fields without a policy.
+func (p Policies) Resolve(name string, current, candidate Value, def Decision) Decision {
+	field := fields()[name]
+	if candidate.Value == "" || Equal(name, current.Value, candidate.Value) {
+		return Keep
+	} else if current.Value == "" {
+		return Replace
+	}
+
+	policy, ok := p[name]
+	if !ok {
+		return def
+	}
+	switch policy.Rule {
+	case RulePrefer:
+		if rank(policy.Providers, candidate.Provider) < rank(policy.Providers, current.Provider) {
+			return Replace
+		}
+	case RuleNewest:
+		x, _ := strconv.Atoi(current.Value)
+		y, _ := strconv.Atoi(candidate.Value)
+		if y > x {
+			return Replace
+		}
+	case RuleLongest:
+		if utf8.RuneCountInString(candidate.Value) > utf8.RuneCountInString(current.Value) {
+			return Replace
+		}
+	case RuleReview:
+		if !field.work {
+			return Review
+		}
+	}
+	return Keep
+}
+
+// rank returns the position of provider in providers, or the length of
+// providers if it is not listed.
+func rank(providers []string, provider string) int {
+	if i := slices.Index(providers, provider); i >= 0 {
+		return i
+	}
+	return len(providers)
+}
+
+// Equal reports whether two values of a field are the same once normalized,
+// ignoring e.g. the case and punctuation of titles, the hyphens of ISBNs and
+// the order of authors.
+func Equal(name, a, b string) bool {
+	if a == b {
+		return true
+	}
+	normalize := fields()[name].normalize
+	if normalize == nil {
+		return false
+	}
+	return normalize(a) == normalize(b)
+}
+
+// field describes a field merge policies can be set for.
+type field struct {
+	// Set for fields of works rather than publications, which cannot be
+	// queued for review.
+	work bool
+
+	// Returns the value compared between providers, if not the value as is.
+	normalize func(string) string
+}
+
+// fields returns the fields merge policies can be set for by JSON name.
+func fields() map[string]field {
+	return map[string]field{
+		"title": {work: true, normalize: match.Normalize},
+		"authors": {work: true, normalize: func(s string) string {
+			names := strings.Split(s, ";")
+			for i, name := range names {
+				names[i] = match.Normalize(name)
+			}
+			slices.Sort(names)
+			return strings.Join(names, ";")
+		}},
+		"isbn10":           {normalize: isbn.Normalize},
+		"isbn13":           {normalize: isbn.Normalize},
+		"publisher":        {normalize: func(s string) string { return publisher.Key(publisher.Name(s)) }},
+		"published_year":   {},
+		"language":         {normalize: language.Normalize},
+		"binding":          {},
+		"page_count":       {},
+		"duration_minutes": {},
+		"oclc_number":      {},
+		"lccn":             {normalize: lccn.Normalize},
+		"doi":              {normalize: doi.Normalize},
+		"asin":             {normalize: asin.Normalize},
+		"thumbnail_url":    {},
+		"description":      {},
+
+		"original_title":    {work: true, normalize: match.Normalize},
+		"original_language": {work: true, normalize: language.Normalize},
+	}
+}
+
+// Fields returns the JSON names of the fields merge policies can be set for,
+// sorted.
+func Fields() []string {
+	names := make([]string, 0)
+	for name := range fields() {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
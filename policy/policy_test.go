@@ -0,0 +1,65 @@
+package policy_test
+
+import (
+	"testing"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/policy"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPolicies_Validate(t *testing.T) {
+	t.Parallel()
+
+	assert.NoError(t, policy.Policies{
+		"title":          {Rule: policy.RuleLongest},
+		"publisher":      {Rule: policy.RulePrefer, Providers: []string{"isbndb"}},
+		"published_year": {Rule: policy.RuleNewest},
+		"language":       {Rule: policy.RuleReview},
+	}.Validate())
+
+	for name, policies := range map[string]policy.Policies{
+		"unknown field":      {"pages": {Rule: policy.RuleLongest}},
+		"unknown rule":       {"title": {Rule: "shortest"}},
+		"prefer nobody":      {"publisher": {Rule: policy.RulePrefer}},
+		"newest publisher":   {"publisher": {Rule: policy.RuleNewest}},
+		"review work fields": {"authors": {Rule: policy.RuleReview}},
+	} {
+		assert.Equal(t, bookid.EINVALID, bookid.ErrorCode(policies.Validate()), name)
+	}
+}
+
+func TestPolicies_Resolve(t *testing.T) {
+	t.Parallel()
+
+	policies := policy.Policies{
+		"title":          {Rule: policy.RuleLongest},
+		"publisher":      {Rule: policy.RulePrefer, Providers: []string{"isbndb", "openlibrary"}},
+		"published_year": {Rule: policy.RuleNewest},
+		"language":       {Rule: policy.RuleReview},
+	}
+	v := func(value, provider string) policy.Value { return policy.Value{Value: value, Provider: provider} }
+
+	for _, tt := range []struct {
+		name               string
+		field              string
+		current, candidate policy.Value
+		def                policy.Decision
+		want               policy.Decision
+	}{
+		{"empty candidate", "publisher", v("Ace", "googlebooks"), v("", "isbndb"), policy.Replace, policy.Keep},
+		{"empty current", "language", v("", ""), v("en", "openlibrary"), policy.Keep, policy.Replace},
+		{"equal once normalized", "isbn13", v("9780441172719", "googlebooks"), v("978-0-441-17271-9", "isbndb"), policy.Replace, policy.Keep},
+		{"authors in another order", "authors", v("Frank Herbert; Brian Herbert", "googlebooks"), v("brian herbert; Frank Herbert", "isbndb"), policy.Replace, policy.Keep},
+		{"default", "doi", v("10.1000/a", "crossref"), v("10.1000/b", "openlibrary"), policy.Replace, policy.Replace},
+		{"preferred provider", "publisher", v("Ace", "googlebooks"), v("Chilton", "openlibrary"), policy.Keep, policy.Replace},
+		{"less preferred provider", "publisher", v("Ace", "isbndb"), v("Chilton", "openlibrary"), policy.Replace, policy.Keep},
+		{"newer year", "published_year", v("1965", "googlebooks"), v("1990", "isbndb"), policy.Keep, policy.Replace},
+		{"older year", "published_year", v("1990", "googlebooks"), v("1965", "isbndb"), policy.Replace, policy.Keep},
+		{"longer title", "title", v("Dune", "googlebooks"), v("Dune: Deluxe Edition", "isbndb"), policy.Keep, policy.Replace},
+		{"shorter title", "title", v("Dune: Deluxe Edition", "googlebooks"), v("Dune", "isbndb"), policy.Replace, policy.Keep},
+		{"review", "language", v("en", "googlebooks"), v("fr", "isbndb"), policy.Replace, policy.Review},
+	} {
+		assert.Equal(t, tt.want, policies.Resolve(tt.field, tt.current, tt.candidate, tt.def), tt.name)
+	}
+}
@@ -0,0 +1,113 @@
+// Package pricing records the prices and availability of cataloged
+// publications, as reported by the providers that sell or list them, so
+// booksellers can monitor prices over time.
+package pricing
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/fwojciec/bookid"
+)
+
+// pageSize is the number of publications read from the catalog at a time
+// while fetching the offers of all of them.
+const pageSize = 100
+
+// Ensure service implements interface.
+var _ bookid.PricingService = (*Service)(nil)
+
+// Service implements the PricingService interface by looking publications up
+// with a set of offer finders and recording the offers in an OfferService.
+type Service struct {
+	PublicationService bookid.PublicationService
+	OfferService       bookid.OfferService
+
+	// Offer finders by provider name. Providers are asked in name order.
+	Finders map[string]bookid.OfferFinder
+
+	// Called by FetchAll after each publication with the number done so far
+	// and the total, if set. An error stops the fetch.
+	Progress func(done, total int) error
+}
+
+// NewService returns a new instance of Service.
+func NewService(pubs bookid.PublicationService, offers bookid.OfferService, finders map[string]bookid.OfferFinder) *Service {
+	return &Service{
+		PublicationService: pubs,
+		OfferService:       offers,
+		Finders:            finders,
+	}
+}
+
+// FetchOffers looks up the offers of a publication by its ISBN-13, or ISBN-10
+// if it has none, with every finder and records them. Offers are recorded as
+// each finder answers, so offers found before a failing finder are kept.
+func (s *Service) FetchOffers(ctx context.Context, publicationID int64) ([]*bookid.Offer, error) {
+	pub, err := s.PublicationService.FindPublicationByID(ctx, publicationID)
+	if err != nil {
+		return nil, err
+	}
+	code := pub.ISBN13
+	if code == "" {
+		code = pub.ISBN10
+	}
+	if code == "" {
+		return nil, bookid.Errorf(bookid.EINVALID, "Publication %d has no ISBN.", pub.ID)
+	}
+
+	offers := []*bookid.Offer{}
+	for _, name := range slices.Sorted(maps.Keys(s.Finders)) {
+		found, err := s.Finders[name].LookupOffers(ctx, code)
+		if err != nil {
+			return offers, fmt.Errorf("%s: %w", name, err)
+		}
+		for i := range found {
+			offer := &found[i]
+			offer.PublicationID = pub.ID
+			if offer.Provider == "" {
+				offer.Provider = name
+			}
+			if err := s.OfferService.CreateOffer(ctx, offer); err != nil {
+				return offers, err
+			}
+			offers = append(offers, offer)
+		}
+	}
+	return offers, nil
+}
+
+// FetchAll fetches the offers of all publications. Returns the number of
+// offers recorded and the IDs of the publications skipped for lacking an ISBN.
+func (s *Service) FetchAll(ctx context.Context) (fetched int, skipped []int64, err error) {
+	for offset := 0; ; offset += pageSize {
+		pubs, n, err := s.PublicationService.FindPublications(ctx, bookid.PublicationFilter{
+			Offset: offset,
+			Limit:  pageSize,
+		})
+		if err != nil {
+			return fetched, skipped, err
+		} else if len(pubs) == 0 {
+			return fetched, skipped, nil
+		}
+
+		for i, pub := range pubs {
+			if pub.ISBN13 == "" && pub.ISBN10 == "" {
+				skipped = append(skipped, pub.ID)
+			} else {
+				offers, err := s.FetchOffers(ctx, pub.ID)
+				fetched += len(offers)
+				if err != nil {
+					return fetched, skipped, fmt.Errorf("publication %d: %w", pub.ID, err)
+				}
+			}
+			if s.Progress != nil {
+				if err := s.Progress(offset+i+1, n); err != nil {
+					return fetched, skipped, err
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package pricing_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/mock"
+	"github.com/fwojciec/bookid/pricing"
+	"github.com/fwojciec/bookid/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newPricingService returns a pricing service over an in-memory catalog
+// holding one publication with an ISBN and one without.
+func newPricingService(t *testing.T, finders map[string]bookid.OfferFinder) (*pricing.Service, *sqlite.DB, []*bookid.Publication) {
+	t.Helper()
+	db := sqlite.NewDB(":memory:")
+	require.NoError(t, db.Open())
+	t.Cleanup(func() { _ = db.Close() })
+
+	ctx := context.Background()
+	work := &bookid.Work{Title: "Dune"}
+	require.NoError(t, sqlite.NewWorkService(db).CreateWork(ctx, work))
+	pubs := sqlite.NewPublicationService(db)
+	withISBN := &bookid.Publication{WorkID: work.ID, ISBN13: "9780441172719"}
+	require.NoError(t, pubs.CreatePublication(ctx, withISBN))
+	withoutISBN := &bookid.Publication{WorkID: work.ID}
+	require.NoError(t, pubs.CreatePublication(ctx, withoutISBN))
+
+	return pricing.NewService(pubs, sqlite.NewOfferService(db), finders), db, []*bookid.Publication{withISBN, withoutISBN}
+}
+
+func TestService_FetchOffers(t *testing.T) {
+	t.Parallel()
+
+	t.Run("records", func(t *testing.T) {
+		t.Parallel()
+		var looked []string
+		finders := map[string]bookid.OfferFinder{
+			"googlebooks": &mock.OfferFinder{LookupOffersFn: func(_ context.Context, isbn string) ([]bookid.Offer, error) {
+				looked = append(looked, "googlebooks:"+isbn)
+				return []bookid.Offer{{Country: "US", Currency: "USD", RetailPrice: 9.99, Availability: bookid.AvailabilityForSale}}, nil
+			}},
+			"isbndb": &mock.OfferFinder{LookupOffersFn: func(_ context.Context, isbn string) ([]bookid.Offer, error) {
+				looked = append(looked, "isbndb:"+isbn)
+				return []bookid.Offer{{Provider: "isbndb", Currency: "USD", ListPrice: 18}}, nil
+			}},
+		}
+		s, db, pubs := newPricingService(t, finders)
+
+		offers, err := s.FetchOffers(context.Background(), pubs[0].ID)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"googlebooks:9780441172719", "isbndb:9780441172719"}, looked)
+		require.Len(t, offers, 2)
+		assert.Equal(t, "googlebooks", offers[0].Provider)
+		assert.Equal(t, pubs[0].ID, offers[0].PublicationID)
+		assert.NotZero(t, offers[0].ID)
+
+		found, n, err := sqlite.NewOfferService(db).FindOffers(context.Background(), bookid.OfferFilter{PublicationID: &pubs[0].ID})
+		require.NoError(t, err)
+		assert.Equal(t, 2, n)
+		assert.Len(t, found, 2)
+	})
+
+	t.Run("no_isbn", func(t *testing.T) {
+		t.Parallel()
+		s, _, pubs := newPricingService(t, nil)
+		_, err := s.FetchOffers(context.Background(), pubs[1].ID)
+		assert.Equal(t, bookid.EINVALID, bookid.ErrorCode(err))
+	})
+
+	t.Run("not_found", func(t *testing.T) {
+		t.Parallel()
+		s, _, _ := newPricingService(t, nil)
+		_, err := s.FetchOffers(context.Background(), 999)
+		assert.Equal(t, bookid.ENOTFOUND, bookid.ErrorCode(err))
+	})
+
+	t.Run("finder_error", func(t *testing.T) {
+		t.Parallel()
+		finders := map[string]bookid.OfferFinder{
+			"googlebooks": &mock.OfferFinder{LookupOffersFn: func(context.Context, string) ([]bookid.Offer, error) {
+				return []bookid.Offer{{Availability: bookid.AvailabilityNotForSale}}, nil
+			}},
+			"isbndb": &mock.OfferFinder{LookupOffersFn: func(context.Context, string) ([]bookid.Offer, error) {
+				return nil, errors.New("boom")
+			}},
+		}
+		s, _, pubs := newPricingService(t, finders)
+
+		offers, err := s.FetchOffers(context.Background(), pubs[0].ID)
+		require.Error(t, err)
+		assert.Len(t, offers, 1, "offers found before the failure are kept")
+	})
+}
+
+func TestService_FetchAll(t *testing.T) {
+	t.Parallel()
+
+	finders := map[string]bookid.OfferFinder{
+		"googlebooks": &mock.OfferFinder{LookupOffersFn: func(context.Context, string) ([]bookid.Offer, error) {
+			return []bookid.Offer{{Currency: "USD", RetailPrice: 9.99}}, nil
+		}},
+	}
+	s, _, pubs := newPricingService(t, finders)
+	var progress []int
+	s.Progress = func(done, total int) error {
+		progress = append(progress, done)
+		assert.Equal(t, 2, total)
+		return nil
+	}
+
+	fetched, skipped, err := s.FetchAll(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, fetched)
+	assert.Equal(t, []int64{pubs[1].ID}, skipped)
+	assert.Equal(t, []int{1, 2}, progress)
+}
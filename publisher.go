@@ -0,0 +1,63 @@
+package bookid
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// Publisher represents a publisher of publications, e.g. "Penguin". Names are
+// normalized by the publisher package so that the variants providers give,
+// such as "Penguin Books Ltd", share a publisher.
+type Publisher struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+
+	// Number of publications outside the trash. Set by FindPublishers.
+	PublicationCount int `json:"publication_count"`
+}
+
+// Validate returns an error if the publisher contains invalid fields.
+func (p *Publisher) Validate() error {
+	if strings.TrimSpace(p.Name) == "" {
+		return Errorf(EINVALID, "Publisher name required.")
+	}
+	return nil
+}
+
+// PublisherService represents a service for managing publishers.
+type PublisherService interface {
+	// FindPublisherByID retrieves a single publisher by ID.
+	// Returns ENOTFOUND if the publisher does not exist.
+	FindPublisherByID(ctx context.Context, id int64) (*Publisher, error)
+
+	// FindPublishers retrieves a list of publishers matching the filter along
+	// with the total number of matches, ignoring Offset and Limit. Publishers
+	// with the most publications are listed first.
+	FindPublishers(ctx context.Context, filter PublisherFilter) ([]*Publisher, int, error)
+
+	// CreatePublisher creates a new publisher. The name is normalized first,
+	// and if a publisher with the normalized name already exists, publisher
+	// is populated from it instead.
+	CreatePublisher(ctx context.Context, publisher *Publisher) error
+
+	// MergePublishers merges duplicate source publishers into the target in
+	// a single transaction: their publications move to the target and the
+	// sources are deleted. Returns ENOTFOUND if any publisher does not exist
+	// and EINVALID if no sources are given or the target is among them.
+	MergePublishers(ctx context.Context, targetID int64, sourceIDs ...int64) error
+}
+
+// PublisherFilter represents a filter used by FindPublishers.
+type PublisherFilter struct {
+	ID *int64
+
+	// Name matches publishers by normalized name, ignoring case, so
+	// "Penguin Books Ltd" finds "Penguin".
+	Name *string
+
+	// Restrict to subset of results.
+	Offset int
+	Limit  int
+}
@@ -0,0 +1,156 @@
+// Package publisher normalizes the names of publishers as given by providers
+// and transcribed from title pages, so that "Penguin Books Ltd", "Penguin
+// Books" and "Penguin" are all filed as the publisher "Penguin".
+package publisher
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Name returns the normalized name of a publisher. Known publishers get the
+// name of the dictionary whatever variant they are given by; other names
+// lose their corporate suffixes such as "Ltd", "Inc." and "Publishers" and
+// surrounding punctuation, and "Books" or "Group" only after the name of a
+// known publisher, so "Basic Books" stays whole. Returns an empty string for names marking the
+// publisher as unknown, such as "[s.n.]".
+func Name(s string) string {
+	s = strings.Trim(strings.Join(strings.Fields(s), " "), " ,.;:/[]()")
+	switch Key(s) {
+	case "", "sn", "s n", "unknown", "unknown publisher", "na", "publisher not identified":
+		return ""
+	}
+	if alias, ok := aliases()[Key(s)]; ok {
+		return alias
+	}
+
+	// Drop suffixes, but never the whole name: "Books" stays "Books".
+	words := strings.Fields(s)
+	for len(words) > 1 && endsWithSuffix(words) {
+		words = words[:len(words)-1]
+		// "Little, Brown and Co." leaves a dangling "and".
+		for len(words) > 1 && (Key(words[len(words)-1]) == "and" || words[len(words)-1] == "&") {
+			words = words[:len(words)-1]
+		}
+	}
+	name := strings.TrimRight(strings.Join(words, " "), " ,.;:&")
+	if alias, ok := aliases()[Key(name)]; ok {
+		return alias
+	}
+	return name
+}
+
+// Key returns the form names are compared by: lowercase words of letters and
+// digits only, with "&" spelled "and", e.g. "simon and schuster" for "Simon
+// & Schuster". Names are normalized with Name before they are compared.
+func Key(s string) string {
+	words := strings.Fields(strings.ToLower(strings.ReplaceAll(s, "&", " and ")))
+	keys := make([]string, 0, len(words))
+	for _, w := range words {
+		w = strings.Map(func(r rune) rune {
+			if unicode.IsLetter(r) || unicode.IsDigit(r) {
+				return r
+			}
+			return -1
+		}, w)
+		if w != "" {
+			keys = append(keys, w)
+		}
+	}
+	return strings.Join(keys, " ")
+}
+
+// endsWithSuffix reports whether the last of the words of a publisher's name
+// is a suffix to drop. "Books" and "Group" are part of many names, as in
+// "Basic Books", so they are only dropped after a known publisher's name.
+func endsWithSuffix(words []string) bool {
+	last := words[len(words)-1]
+	return isSuffix(last) || (isImprintSuffix(last) && isKnown(words[:len(words)-1]))
+}
+
+// isSuffix reports whether a word of a publisher's name is a corporate
+// suffix carrying no part of the name.
+func isSuffix(word string) bool {
+	switch Key(word) {
+	case "ltd", "limited", "inc", "incorporated", "llc", "plc", "co", "company", "corp", "corporation",
+		"gmbh", "ag", "publishers", "publisher", "publishing", "publications", "verlag":
+		return true
+	default:
+		return false
+	}
+}
+
+// isImprintSuffix reports whether a word of a publisher's name may name an
+// imprint or group of a publisher, as "Books" does in "Penguin Books".
+func isImprintSuffix(word string) bool {
+	switch Key(word) {
+	case "books", "group":
+		return true
+	default:
+		return false
+	}
+}
+
+// isKnown reports whether words are the name of a known publisher.
+func isKnown(words []string) bool {
+	key := Key(strings.Join(words, " "))
+	if _, ok := aliases()[key]; ok {
+		return true
+	}
+	for _, name := range aliases() {
+		if Key(name) == key {
+			return true
+		}
+	}
+	return knownPublishers()[key]
+}
+
+// knownPublishers returns the keys of publishers also known by their name
+// followed by "Books" or "Group", other than those with aliases.
+func knownPublishers() map[string]bool {
+	return map[string]bool{
+		"penguin":              true,
+		"penguin random house": true,
+		"puffin":               true,
+		"bantam":               true,
+		"ballantine":           true,
+		"del rey":              true,
+		"ace":                  true,
+		"tor":                  true,
+		"orbit":                true,
+		"anchor":               true,
+		"picador":              true,
+		"pan":                  true,
+		"macmillan":            true,
+		"hachette":             true,
+		"harper":               true,
+		"avon":                 true,
+		"berkley":              true,
+		"signet":               true,
+		"baen":                 true,
+		"quercus":              true,
+	}
+}
+
+// aliases returns the names of publishers known by several names, keyed by
+// the keys of their variants with and without suffixes.
+func aliases() map[string]string {
+	return map[string]string{
+		"penguin uk":                      "Penguin",
+		"penguin classics":                "Penguin",
+		"charles scribners sons":          "Scribner",
+		"scribners":                       "Scribner",
+		"harper collins":                  "HarperCollins",
+		"harpercollins":                   "HarperCollins",
+		"simon and schuster":              "Simon & Schuster",
+		"little brown":                    "Little, Brown",
+		"oup":                             "Oxford University Press",
+		"oxford univ press":               "Oxford University Press",
+		"cup":                             "Cambridge University Press",
+		"cambridge univ press":            "Cambridge University Press",
+		"random house trade paperbacks":   "Random House",
+		"vintage international":           "Vintage",
+		"farrar straus and giroux":        "Farrar, Straus and Giroux",
+		"houghton mifflin harcourt trade": "Houghton Mifflin Harcourt",
+	}
+}
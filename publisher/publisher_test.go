@@ -0,0 +1,50 @@
+package publisher_test
+
+import (
+	"testing"
+
+	"github.com/fwojciec/bookid/publisher"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestName(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"Penguin Books Ltd", "Penguin"},
+		{"Penguin Books", "Penguin"},
+		{"Tor Books", "Tor"},
+		{"Random House Group Ltd", "Random House"},
+		{"Basic Books", "Basic Books"},
+		{"Basic Books, Inc.", "Basic Books"},
+		{"Perseus Books Group", "Perseus Books Group"},
+		{"penguin", "penguin"},
+		{"Charles Scribner's Sons", "Scribner"},
+		{"Scribner,", "Scribner"},
+		{"Little, Brown and Co.", "Little, Brown"},
+		{"Simon & Schuster, Inc.", "Simon & Schuster"},
+		{"Harper Collins Publishers", "HarperCollins"},
+		{"Oxford University Press", "Oxford University Press"},
+		{"Diogenes Verlag", "Diogenes"},
+		{"Books", "Books"},
+		{"[s.n.]", ""},
+		{"  ", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.want, publisher.Name(tt.name))
+		})
+	}
+}
+
+func TestKey(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "simon and schuster", publisher.Key("Simon & Schuster"))
+	assert.Equal(t, "little brown", publisher.Key("Little, Brown"))
+	assert.Equal(t, publisher.Key("Penguin"), publisher.Key(publisher.Name("PENGUIN BOOKS LTD.")))
+}
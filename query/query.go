@@ -0,0 +1,498 @@
+// Package query parses search queries into fields and identifiers.
+//
+// Fields are given with operators such as title:"The Hobbit", author:Tolkien
+// or year:1937, quoting values that contain spaces. The operators are title,
+// author, publisher, year, lang, isbn, lccn and doi, along with the Google
+// Books style intitle, inauthor and inpublisher, sbn for nine digit Standard
+// Book Numbers and asin for Amazon Standard Identification Numbers. Everything
+// else is free text, in which ISBNs are recognized anywhere, including in the
+// links of bookstores and Google Books, ASINs of products other than books
+// with an ISBN as words, and DOIs and LCCNs when they are the whole text.
+// ISBNs are only taken if their check digit is correct.
+//
+// Amazon product links are replaced by the ISBN or ASIN of the product and
+// the title words of their slug, so a pasted link can be identified without
+// fetching the page.
+package query
+
+import (
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/asin"
+	"github.com/fwojciec/bookid/doi"
+	"github.com/fwojciec/bookid/isbn"
+	"github.com/fwojciec/bookid/language"
+	"github.com/fwojciec/bookid/lccn"
+)
+
+var (
+	// ISBN-10: exactly 10 digits, the last of which may be X (with optional dashes)
+	isbn10Pattern = regexp.MustCompile(`\b(\d{1,5}[-\s]?\d{1,7}[-\s]?\d{1,7}[-\s]?[\dXx])\b`)
+
+	// ISBN-13: exactly 13 digits starting with 978 or 979 (with optional dashes)
+	isbn13Pattern = regexp.MustCompile(`\b(97[89][-\s]?\d{1,5}[-\s]?\d{1,7}[-\s]?\d{1,7}[-\s]?\d)\b`)
+
+	// ASINs of products other than books with an ISBN, e.g. "B00K0OI42W"
+	asinPattern = regexp.MustCompile(`^B0[0-9A-Z]{8}$`)
+
+	// Open Library edition IDs, e.g. "OL7353617M"
+	olidPattern = regexp.MustCompile(`^OL\d+M$`)
+
+	// Google Books volume IDs, e.g. "iXn5U2IzVH0C"
+	volumeIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{12}$`)
+
+	// Labels of ISBNs in free text, e.g. "ISBN-13:"
+	labelPattern = regexp.MustCompile(`(?i)\b(isbn(-?1[03])?|sbn)\b:?`)
+)
+
+// Parse parses a search query. Operators with invalid values, such as an
+// ISBN with a wrong check digit, are kept as free text.
+func Parse(s string) bookid.ParsedQuery {
+	var q bookid.ParsedQuery
+	var terms []string
+	tokens := tokenize(s)
+	for i := 0; i < len(tokens); i++ {
+		tok := tokens[i]
+		if !tok.isField() {
+			if text, ok := parseLink(&q, tok.raw); ok {
+				if text != "" {
+					terms = append(terms, text)
+				}
+				continue
+			} else if asinPattern.MatchString(tok.raw) {
+				q.ASINs = appendUnique(q.ASINs, tok.raw)
+				continue
+			}
+			terms = append(terms, tok.raw)
+			continue
+		}
+
+		// Allow a space after the colon, as in "ISBN: 9780743273565".
+		if tok.value == "" && i+1 < len(tokens) && !tokens[i+1].isField() {
+			i++
+			tok.raw, tok.value = tok.raw+" "+tokens[i].raw, tokens[i].value
+		}
+		if !setField(&q, tok.key, tok.value) {
+			terms = append(terms, tok.raw)
+		}
+	}
+
+	text := strings.Join(terms, " ")
+	if code, ok := doi.Parse(text); ok {
+		q.DOIs = append(q.DOIs, code)
+		return q
+	}
+	// Labels are taken out first so they don't run into the ISBN, as the
+	// "10" of "ISBN-10" would, and only dropped if an ISBN is found.
+	if rest := extractISBNs(&q, labelPattern.ReplaceAllString(text, " "), isbn13Pattern, isbn10Pattern); len(q.ISBNs) > 0 {
+		text = rest
+	}
+	if code, ok := lccn.Parse(text); ok && len(q.ISBNs) == 0 {
+		q.LCCNs = append(q.LCCNs, code)
+		return q
+	} else if code, err := isbn.FromSBN(text); err == nil && len(q.ISBNs) == 0 {
+		q.ISBNs = append(q.ISBNs, code)
+		return q
+	}
+
+	q.Terms = strings.Join(strings.Fields(text), " ")
+	return q
+}
+
+// Validate returns EINVALID if q has nothing to search but ASINs, as from a
+// bare ASIN or an Amazon link without a title slug. Only providers of
+// audiobook metadata can search ASINs, so such a query finds nothing else.
+func Validate(q bookid.ParsedQuery) error {
+	if len(q.ASINs) > 0 && !q.HasIdentifiers() && !q.HasText() {
+		return bookid.Errorf(bookid.EINVALID, "ASIN %s cannot be searched unless it is an audiobook's. Search by ISBN, title or author instead.", q.ASINs[0])
+	}
+	return nil
+}
+
+// setField sets the field of q named by key to value. Returns false if the
+// value is not valid for the field.
+func setField(q *bookid.ParsedQuery, key, value string) bool {
+	if value == "" {
+		return false
+	}
+	switch key {
+	case "title", "intitle":
+		q.Title = join(q.Title, value)
+	case "author", "inauthor":
+		q.Author = join(q.Author, value)
+	case "publisher", "inpublisher":
+		q.Publisher = join(q.Publisher, value)
+	case "year":
+		year, err := strconv.Atoi(value)
+		if err != nil || len(value) != 4 {
+			return false
+		}
+		q.Year = year
+	case "lang", "language":
+		code := parseLanguage(value)
+		if code == "" {
+			return false
+		}
+		q.Language = code
+	case "isbn":
+		code := isbn.Normalize(value)
+		if !isbn.Valid(code) {
+			return false
+		}
+		q.ISBNs = appendUnique(q.ISBNs, code)
+	case "sbn":
+		code, err := isbn.FromSBN(value)
+		if err != nil {
+			return false
+		}
+		q.ISBNs = appendUnique(q.ISBNs, code)
+	case "asin":
+		code := strings.ToUpper(value)
+		if isbn.Valid10(code) {
+			q.ISBNs = appendUnique(q.ISBNs, code)
+		} else if asin.Valid(code) {
+			q.ASINs = appendUnique(q.ASINs, code)
+		} else {
+			return false
+		}
+	case "lccn":
+		code, ok := lccn.Parse(value)
+		if !ok {
+			return false
+		}
+		q.LCCNs = appendUnique(q.LCCNs, code)
+	case "doi":
+		code, ok := doi.Parse(value)
+		if !ok {
+			return false
+		}
+		q.DOIs = appendUnique(q.DOIs, code)
+	default:
+		return false
+	}
+	return true
+}
+
+// parseLanguage returns the ISO 639-1 code of a language code or tag, such
+// as "eng" or "pt-BR", or an English language name, or an empty string if it
+// is not recognized.
+func parseLanguage(s string) string {
+	tag, ok := language.Parse(s)
+	if code := language.Base(tag); ok && len(code) == 2 {
+		return code
+	}
+	return ""
+}
+
+// extractISBNs adds the valid ISBNs matched by patterns in text to q, in
+// order of the patterns, and returns text without them.
+func extractISBNs(q *bookid.ParsedQuery, text string, patterns ...*regexp.Regexp) string {
+	for _, pattern := range patterns {
+		text = pattern.ReplaceAllStringFunc(text, func(match string) string {
+			code := isbn.Normalize(match)
+			if !isbn.Valid(code) {
+				return match
+			}
+			q.ISBNs = appendUnique(q.ISBNs, code)
+			return ""
+		})
+	}
+	return text
+}
+
+// parseLink takes the identifiers out of a link and returns the text to
+// search in their place. Returns false if s is not a link or has none.
+//
+// Links to the pages of a book on Google Books, Open Library and Goodreads
+// give the ID of the book on that site, and Amazon product links their ASIN,
+// which for books is the ISBN-10. Any title in their slug is searched too.
+// Other links give the ISBNs found as path segments or isbn and vid
+// parameters, as in Google Books links by ISBN (...?vid=ISBN9780743273565).
+// DOI links are left to the DOI parser, as DOIs of books often embed their
+// ISBN.
+func parseLink(q *bookid.ParsedQuery, s string) (string, bool) {
+	if !isLink(s) {
+		return "", false
+	} else if _, ok := doi.Parse(s); ok {
+		return "", false
+	}
+	u, err := url.Parse(s)
+	if err != nil {
+		return "", false
+	} else if u.Host == "" {
+		if u, err = url.Parse("https://" + s); err != nil {
+			return "", false
+		}
+	}
+
+	host := strings.TrimPrefix(strings.ToLower(u.Host), "www.")
+	switch {
+	case host == "goodreads.com":
+		if id, slug := goodreadsBook(u.Path); id != "" {
+			q.GoodreadsIDs = appendUnique(q.GoodreadsIDs, id)
+			return slug, true
+		}
+	case host == "openlibrary.org":
+		if id, slug := openLibraryEdition(u.Path); id != "" {
+			q.OpenLibraryIDs = appendUnique(q.OpenLibraryIDs, id)
+			return slug, true
+		}
+	case isGoogle(host):
+		if id, slug := googleVolume(u); id != "" {
+			q.GoogleBooksIDs = appendUnique(q.GoogleBooksIDs, id)
+			return slug, true
+		}
+	case isAmazon(host):
+		if asin, slug := amazonProduct(u.Path); asin != "" {
+			if isbn.Valid10(asin) {
+				q.ISBNs = appendUnique(q.ISBNs, asin)
+			} else {
+				q.ASINs = appendUnique(q.ASINs, asin)
+			}
+			return slug, true
+		}
+	}
+
+	candidates := strings.Split(u.Path, "/")
+	for _, key := range []string{"isbn", "vid"} {
+		candidates = append(candidates, u.Query()[key]...)
+	}
+	var found bool
+	for _, c := range candidates {
+		c = strings.TrimPrefix(strings.ToUpper(c), "ISBN")
+		if code := isbn.Normalize(c); isbn.Valid(code) {
+			q.ISBNs, found = appendUnique(q.ISBNs, code), true
+		}
+	}
+	return "", found
+}
+
+// isLink returns true if s is a URL with a scheme, or starts with the host
+// of a site whose links we recognize.
+func isLink(s string) bool {
+	s = strings.ToLower(s)
+	if strings.Contains(s, "://") {
+		return true
+	}
+	for _, prefix := range []string{"www.", "amazon.", "goodreads.com/", "openlibrary.org/", "books.google."} {
+		if strings.HasPrefix(s, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// goodreadsBook returns the ID of a Goodreads book page path such as
+// /book/show/4671.The_Great_Gatsby or /book/show/4671-the-great-gatsby,
+// along with the words of its title slug. Returns an empty ID for other
+// pages.
+func goodreadsBook(path string) (id, slug string) {
+	rest, ok := strings.CutPrefix(path, "/book/show/")
+	if !ok {
+		return "", ""
+	}
+	rest, _, _ = strings.Cut(rest, "/")
+	end := strings.IndexFunc(rest, func(r rune) bool { return r < '0' || r > '9' })
+	if end == -1 {
+		end = len(rest)
+	}
+	if end == 0 {
+		return "", ""
+	}
+	return rest[:end], slugWords(rest[end:])
+}
+
+// openLibraryEdition returns the edition ID of an Open Library edition page
+// path such as /books/OL7353617M/The_Great_Gatsby, along with the words of
+// its title slug. Returns an empty ID for other pages.
+func openLibraryEdition(path string) (id, slug string) {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	if len(segments) < 2 || segments[0] != "books" || !olidPattern.MatchString(segments[1]) {
+		return "", ""
+	} else if len(segments) > 2 {
+		slug = slugWords(segments[2])
+	}
+	return segments[1], slug
+}
+
+// isGoogle returns true if host serves Google Books pages, such as
+// books.google.co.uk, google.com or play.google.com.
+func isGoogle(host string) bool {
+	return strings.HasPrefix(host, "books.google.") || strings.HasPrefix(host, "google.") ||
+		host == "play.google.com" || host == "books.googleapis.com" || host == "googleapis.com"
+}
+
+// googleVolume returns the volume ID of a Google Books link such as
+// books.google.com/books?id=iXn5U2IzVH0C,
+// google.com/books/edition/The_Great_Gatsby/iXn5U2IzVH0C or
+// play.google.com/store/books/details?id=iXn5U2IzVH0C, along with the words
+// of its title slug. Returns an empty ID for other links.
+func googleVolume(u *url.URL) (id, slug string) {
+	segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+	switch {
+	case len(segments) >= 4 && segments[0] == "books" && segments[1] == "edition":
+		id, slug = segments[3], slugWords(segments[2])
+	case len(segments) >= 4 && segments[0] == "books" && segments[1] == "v1" && segments[2] == "volumes":
+		id = segments[3]
+	case len(segments) >= 1 && (segments[0] == "books" || u.Path == "/store/books/details"):
+		id = u.Query().Get("id")
+	}
+	if !volumeIDPattern.MatchString(id) {
+		return "", ""
+	}
+	return id, slug
+}
+
+// isAmazon returns true if host is an Amazon store, such as amazon.co.uk.
+func isAmazon(host string) bool {
+	return strings.HasPrefix(host, "amazon.") || strings.HasPrefix(host, "smile.amazon.")
+}
+
+// amazonProduct returns the ASIN of an Amazon product page path such as
+// /The-Great-Gatsby/dp/0743273567, /gp/product/0743273567 or
+// /exec/obidos/ASIN/0743273567, along with the words of the title slug
+// preceding "dp", if any. Returns an empty ASIN for other pages.
+func amazonProduct(path string) (asin, slug string) {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i := 0; i+1 < len(segments); i++ {
+		if !slices.Contains([]string{"dp", "product", "asin", "d"}, strings.ToLower(segments[i])) {
+			continue
+		}
+		code := strings.ToUpper(segments[i+1])
+		if len(code) != 10 || !isAlphanumeric(code) {
+			continue
+		}
+		if strings.EqualFold(segments[i], "dp") && i > 0 {
+			words := strings.Fields(slugWords(segments[i-1]))
+			if n := len(words); n > 0 && strings.EqualFold(words[n-1], "ebook") {
+				words = words[:n-1]
+			}
+			slug = strings.Join(words, " ")
+		}
+		return code, slug
+	}
+	return "", ""
+}
+
+// slugWords returns the words of a URL slug such as "The_Great_Gatsby" or
+// ".the-great-gatsby". A lone "_", used by Google Books for pages without a
+// slug, has none.
+func slugWords(slug string) string {
+	return strings.Join(strings.FieldsFunc(slug, func(r rune) bool {
+		return r == '-' || r == '_' || r == '+' || r == '.'
+	}), " ")
+}
+
+// isAlphanumeric returns true if s has only ASCII letters and digits.
+func isAlphanumeric(s string) bool {
+	for _, r := range s {
+		if (r < '0' || r > '9') && (r < 'A' || r > 'Z') && (r < 'a' || r > 'z') {
+			return false
+		}
+	}
+	return true
+}
+
+// join joins two values of a field given more than once.
+func join(a, b string) string {
+	if a == "" {
+		return b
+	}
+	return a + " " + b
+}
+
+// appendUnique appends s to a unless it is already there.
+func appendUnique(a []string, s string) []string {
+	for _, v := range a {
+		if v == s {
+			return a
+		}
+	}
+	return append(a, s)
+}
+
+// token is a word, quoted phrase or operator of a query.
+type token struct {
+	raw   string // As written in the query
+	key   string // Lowercased operator name, if any
+	value string // Operator value or phrase without quotes
+}
+
+// isField returns true if the token is an operator we know.
+func (t token) isField() bool {
+	switch t.key {
+	case "title", "intitle", "author", "inauthor", "publisher", "inpublisher",
+		"year", "lang", "language", "isbn", "sbn", "asin", "lccn", "doi":
+		return true
+	}
+	return false
+}
+
+// tokenize splits s into whitespace separated words and quoted phrases. A word
+// of the form key:value becomes an operator, whose value may be quoted. An
+// unterminated quote runs to the end of s.
+func tokenize(s string) []token {
+	var tokens []token
+	runes := []rune(s)
+	for i := 0; i < len(runes); {
+		if unicode.IsSpace(runes[i]) {
+			i++
+			continue
+		}
+
+		start := i
+		if runes[i] == '"' {
+			phrase, next := readQuoted(runes, i)
+			tokens = append(tokens, token{raw: string(runes[start:next]), value: phrase})
+			i = next
+			continue
+		}
+
+		for i < len(runes) && !unicode.IsSpace(runes[i]) && runes[i] != ':' {
+			i++
+		}
+		if i < len(runes) && runes[i] == ':' && i > start {
+			key := strings.ToLower(string(runes[start:i]))
+			i++ // colon
+			var value string
+			if i < len(runes) && runes[i] == '"' {
+				value, i = readQuoted(runes, i)
+			} else {
+				j := i
+				for i < len(runes) && !unicode.IsSpace(runes[i]) {
+					i++
+				}
+				value = string(runes[j:i])
+			}
+			tokens = append(tokens, token{raw: string(runes[start:i]), key: key, value: value})
+			continue
+		}
+
+		for i < len(runes) && !unicode.IsSpace(runes[i]) {
+			i++
+		}
+		word := string(runes[start:i])
+		tokens = append(tokens, token{raw: word, value: word})
+	}
+	return tokens
+}
+
+// readQuoted reads the quoted phrase starting at runes[i] and returns its
+// text along with the index following the closing quote.
+func readQuoted(runes []rune, i int) (string, int) {
+	j := i + 1
+	for j < len(runes) && runes[j] != '"' {
+		j++
+	}
+	phrase := strings.TrimSpace(string(runes[i+1 : j]))
+	if j < len(runes) {
+		j++ // closing quote
+	}
+	return phrase, j
+}
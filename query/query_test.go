@@ -0,0 +1,220 @@
+package query_test
+
+import (
+	"testing"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/query"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		input string
+		want  bookid.ParsedQuery
+	}{
+		{
+			name:  "free_text",
+			input: "  The Great   Gatsby ",
+			want:  bookid.ParsedQuery{Terms: "The Great Gatsby"},
+		},
+		{
+			name:  "quoted_phrase",
+			input: `"the great gatsby" fitzgerald`,
+			want:  bookid.ParsedQuery{Terms: `"the great gatsby" fitzgerald`},
+		},
+		{
+			name:  "operators",
+			input: `title:"The Hobbit" Author:Tolkien publisher:"Allen & Unwin" year:1937 lang:eng`,
+			want: bookid.ParsedQuery{
+				Title:     "The Hobbit",
+				Author:    "Tolkien",
+				Publisher: "Allen & Unwin",
+				Year:      1937,
+				Language:  "en",
+			},
+		},
+		{
+			name:  "google_operators",
+			input: "intitle:dune inauthor:herbert",
+			want:  bookid.ParsedQuery{Title: "dune", Author: "herbert"},
+		},
+		{
+			name:  "repeated_operator",
+			input: "author:Pratchett author:Gaiman good omens",
+			want:  bookid.ParsedQuery{Terms: "good omens", Author: "Pratchett Gaiman"},
+		},
+		{
+			name:  "language_name",
+			input: "solaris language:Polish",
+			want:  bookid.ParsedQuery{Terms: "solaris", Language: "pl"},
+		},
+		{
+			name:  "language_tag",
+			input: "dom casmurro lang:pt_BR",
+			want:  bookid.ParsedQuery{Terms: "dom casmurro", Language: "pt"},
+		},
+		{
+			name:  "invalid_values_kept_as_text",
+			input: "year:20th lang:klingon isbn:1234567890",
+			want:  bookid.ParsedQuery{Terms: "year:20th lang:klingon isbn:1234567890"},
+		},
+		{
+			name:  "unknown_operator",
+			input: "Star Wars: A New Hope",
+			want:  bookid.ParsedQuery{Terms: "Star Wars: A New Hope"},
+		},
+		{
+			name:  "isbn_with_space_after_colon",
+			input: "The Great Gatsby ISBN: 978-0-7432-7356-5",
+			want:  bookid.ParsedQuery{Terms: "The Great Gatsby", ISBNs: []string{"9780743273565"}},
+		},
+		{
+			name:  "multiple_identifiers",
+			input: "9780743273565 0743273567 isbn:9780743273565 lccn:2004-111282 doi:10.1017/9781108555807",
+			want: bookid.ParsedQuery{
+				ISBNs: []string{"9780743273565", "0743273567"},
+				LCCNs: []string{"2004111282"},
+				DOIs:  []string{"10.1017/9781108555807"},
+			},
+		},
+		{
+			name:  "isbn10_x_check_digit",
+			input: "0-8044-2957-x",
+			want:  bookid.ParsedQuery{ISBNs: []string{"080442957X"}},
+		},
+		{
+			name:  "isbn_label_removed",
+			input: "ISBN-10 080442957X the good soldier",
+			want:  bookid.ParsedQuery{Terms: "the good soldier", ISBNs: []string{"080442957X"}},
+		},
+		{
+			name:  "sbn",
+			input: "345-24223-8",
+			want:  bookid.ParsedQuery{ISBNs: []string{"0345242238"}},
+		},
+		{
+			name:  "sbn_operator",
+			input: "sbn:345242238 the hobbit",
+			want:  bookid.ParsedQuery{Terms: "the hobbit", ISBNs: []string{"0345242238"}},
+		},
+		{
+			name:  "amazon_link",
+			input: "https://www.amazon.com/Great-Gatsby-F-Scott-Fitzgerald/dp/0743273567/ref=sr_1_1",
+			want:  bookid.ParsedQuery{Terms: "Great Gatsby F Scott Fitzgerald", ISBNs: []string{"0743273567"}},
+		},
+		{
+			name:  "amazon_link_kindle",
+			input: "amazon.co.uk/Dune-Frank-Herbert-ebook/dp/B00B7NPRY8?ref_=ast_sto_dp",
+			want:  bookid.ParsedQuery{Terms: "Dune Frank Herbert", ASINs: []string{"B00B7NPRY8"}},
+		},
+		{
+			name:  "amazon_link_without_slug",
+			input: "https://www.amazon.de/gp/product/3257226853 der große gatsby",
+			want:  bookid.ParsedQuery{Terms: "der große gatsby", ISBNs: []string{"3257226853"}},
+		},
+		{
+			name:  "asins",
+			input: "asin:0743273567 asin:b00b7npry8 B00K0OI42W",
+			want:  bookid.ParsedQuery{ISBNs: []string{"0743273567"}, ASINs: []string{"B00B7NPRY8", "B00K0OI42W"}},
+		},
+		{
+			name:  "google_books_link_by_isbn",
+			input: "books.google.com/books?vid=ISBN9780743273565",
+			want:  bookid.ParsedQuery{ISBNs: []string{"9780743273565"}},
+		},
+		{
+			name:  "google_books_volume_link",
+			input: "https://books.google.co.uk/books?id=iXn5U2IzVH0C&printsec=frontcover",
+			want:  bookid.ParsedQuery{GoogleBooksIDs: []string{"iXn5U2IzVH0C"}},
+		},
+		{
+			name:  "google_books_edition_link",
+			input: "https://www.google.com/books/edition/The_Great_Gatsby/iXn5U2IzVH0C?hl=en",
+			want:  bookid.ParsedQuery{Terms: "The Great Gatsby", GoogleBooksIDs: []string{"iXn5U2IzVH0C"}},
+		},
+		{
+			name:  "open_library_link",
+			input: "https://openlibrary.org/books/OL7353617M/The_Great_Gatsby",
+			want:  bookid.ParsedQuery{Terms: "The Great Gatsby", OpenLibraryIDs: []string{"OL7353617M"}},
+		},
+		{
+			name:  "goodreads_link",
+			input: "goodreads.com/book/show/4671.The_Great_Gatsby",
+			want:  bookid.ParsedQuery{Terms: "The Great Gatsby", GoodreadsIDs: []string{"4671"}},
+		},
+		{
+			name:  "goodreads_link_dashed",
+			input: "https://www.goodreads.com/book/show/4671-the-great-gatsby?from_search=true",
+			want:  bookid.ParsedQuery{Terms: "the great gatsby", GoodreadsIDs: []string{"4671"}},
+		},
+		{
+			name:  "google_books_link_with_scheme",
+			input: "https://books.google.com/books?vid=ISBN9780743273565&redir_esc=y",
+			want:  bookid.ParsedQuery{ISBNs: []string{"9780743273565"}},
+		},
+		{
+			name:  "link_without_isbn",
+			input: "https://example.com/books/1234567890",
+			want:  bookid.ParsedQuery{Terms: "https://example.com/books/1234567890"},
+		},
+		{
+			name:  "bare_doi",
+			input: "https://doi.org/10.1007/978-3-030-00001-1_3",
+			want:  bookid.ParsedQuery{DOIs: []string{"10.1007/978-3-030-00001-1_3"}},
+		},
+		{
+			name:  "bare_lccn",
+			input: "n78-890351",
+			want:  bookid.ParsedQuery{LCCNs: []string{"n78890351"}},
+		},
+		{
+			name:  "unterminated_quote",
+			input: `title:"The Name of the Rose`,
+			want:  bookid.ParsedQuery{Title: "The Name of the Rose"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.want, query.Parse(tt.input))
+		})
+	}
+}
+
+func TestParsedQuery_SearchType(t *testing.T) {
+	t.Parallel()
+
+	for input, want := range map[string]bookid.SearchType{
+		"dune":                               bookid.SearchTypeGeneralQuery,
+		"title:dune":                         bookid.SearchTypeTitle,
+		"title:dune messiah":                 bookid.SearchTypeGeneralQuery,
+		"title:dune author:herbert":          bookid.SearchTypeTitleAuthor,
+		"title:dune 9780441013593":           bookid.SearchTypeISBN,
+		"lccn:2004111282 doi:10.1017/123456": bookid.SearchTypeDOI,
+		"2004111282":                         bookid.SearchTypeLCCN,
+		"B08G9PRS1K":                         bookid.SearchTypeASIN,
+		"asin:B08G9PRS1K 9780441013593":      bookid.SearchTypeISBN,
+	} {
+		assert.Equal(t, want, query.Parse(input).SearchType(), input)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	t.Parallel()
+
+	for _, input := range []string{"B00K0OI42W", "amazon.com/dp/B00K0OI42W", "asin:B00K0OI42W year:2014"} {
+		err := query.Validate(query.Parse(input))
+		require.Error(t, err, input)
+		assert.Equal(t, bookid.EINVALID, bookid.ErrorCode(err), input)
+		assert.Contains(t, bookid.ErrorMessage(err), "ASIN B00K0OI42W cannot be searched", input)
+	}
+	for _, input := range []string{"dune", "amazon.com/dp/0743273567", "amazon.com/Dune-ebook/dp/B00B7NPRY8", "B00K0OI42W herbert"} {
+		assert.NoError(t, query.Validate(query.Parse(input)), input)
+	}
+}
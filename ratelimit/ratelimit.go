@@ -0,0 +1,162 @@
+// Package ratelimit implements a BookFinder decorator that throttles requests
+// to a provider and retries transient failures with exponential backoff.
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/fwojciec/bookid"
+)
+
+// Default retry settings used by NewFinder.
+const (
+	DefaultMaxRetries = 3
+	DefaultBaseDelay  = 500 * time.Millisecond
+	DefaultMaxDelay   = 30 * time.Second
+)
+
+// Ensure type implements interface.
+var _ bookid.BookFinder = (*Finder)(nil)
+
+// Finder wraps a BookFinder, spacing requests so no more than
+// RequestsPerSecond are started and retrying retryable errors.
+type Finder struct {
+	finder bookid.BookFinder
+
+	mu   sync.Mutex
+	next time.Time // earliest start of the next request
+
+	// Maximum request rate. Zero or less disables throttling.
+	RequestsPerSecond float64
+
+	// Number of retries after the first attempt, and the bounds of the
+	// exponential backoff between them.
+	MaxRetries int
+	BaseDelay  time.Duration
+	MaxDelay   time.Duration
+
+	// Reports whether a failed search should be retried.
+	// Defaults to IsRetryable.
+	Retryable func(err error) bool
+
+	// Returns the current time and waits for a duration or until ctx is done.
+	// Default to time.Now() and a timer. Can be mocked for tests.
+	Now   func() time.Time
+	Sleep func(ctx context.Context, d time.Duration) error
+}
+
+// NewFinder returns a Finder limiting finder to requestsPerSecond.
+func NewFinder(finder bookid.BookFinder, requestsPerSecond float64) *Finder {
+	return &Finder{
+		finder:            finder,
+		RequestsPerSecond: requestsPerSecond,
+		MaxRetries:        DefaultMaxRetries,
+		BaseDelay:         DefaultBaseDelay,
+		MaxDelay:          DefaultMaxDelay,
+		Retryable:         IsRetryable,
+		Now:               time.Now,
+		Sleep:             sleep,
+	}
+}
+
+// Search waits for a request slot and searches the wrapped finder, retrying
+// retryable errors. It gives up early, returning the last error, when the
+// next attempt could not start before the context deadline.
+func (f *Finder) Search(ctx context.Context, query string, opts bookid.SearchOptions) ([]bookid.BookResult, error) {
+	for attempt := 0; ; attempt++ {
+		if err := f.wait(ctx, f.reserve()); err != nil {
+			return nil, err
+		}
+
+		results, err := f.finder.Search(ctx, query, opts)
+		if err == nil || attempt >= f.MaxRetries || !f.Retryable(err) {
+			return results, err
+		}
+
+		delay := f.backoff(attempt)
+		if deadline, ok := ctx.Deadline(); ok && f.Now().Add(delay).After(deadline) {
+			return nil, err
+		}
+		if sleepErr := f.Sleep(ctx, delay); sleepErr != nil {
+			return nil, err
+		}
+	}
+}
+
+// reserve claims the next request slot and returns the time it starts.
+func (f *Finder) reserve() time.Time {
+	now := f.Now()
+	if f.RequestsPerSecond <= 0 {
+		return now
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	start := f.next
+	if start.Before(now) {
+		start = now
+	}
+	f.next = start.Add(time.Duration(float64(time.Second) / f.RequestsPerSecond))
+	return start
+}
+
+// wait blocks until start, failing fast if ctx expires first.
+func (f *Finder) wait(ctx context.Context, start time.Time) error {
+	d := start.Sub(f.Now())
+	if d <= 0 {
+		return ctx.Err()
+	}
+	if deadline, ok := ctx.Deadline(); ok && start.After(deadline) {
+		return context.DeadlineExceeded
+	}
+	return f.Sleep(ctx, d)
+}
+
+// backoff returns the delay before retry attempt+1: the base delay doubled
+// per attempt, capped at MaxDelay, with jitter in the upper half.
+func (f *Finder) backoff(attempt int) time.Duration {
+	delay := f.BaseDelay << attempt
+	if delay <= 0 || delay > f.MaxDelay {
+		delay = f.MaxDelay
+	}
+	half := delay / 2
+	if half <= 0 {
+		return delay
+	}
+	return half + rand.N(half)
+}
+
+// IsRetryable returns true for ERATELIMIT and EUNAVAILABLE errors, and for
+// errors reporting an HTTP 429 Too Many Requests or 5xx status via a
+// StatusCode method.
+func IsRetryable(err error) bool {
+	switch bookid.ErrorCode(err) {
+	case bookid.ERATELIMIT, bookid.EUNAVAILABLE:
+		return true
+	}
+
+	var e interface{ StatusCode() int }
+	if !errors.As(err, &e) {
+		return false
+	}
+	code := e.StatusCode()
+	return code == http.StatusTooManyRequests || code >= http.StatusInternalServerError
+}
+
+// sleep waits for d or until ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
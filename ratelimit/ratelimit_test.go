@@ -0,0 +1,156 @@
+package ratelimit_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/ratelimit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// statusError is a provider error carrying an HTTP status code.
+type statusError int
+
+func (e statusError) Error() string   { return fmt.Sprintf("status %d", int(e)) }
+func (e statusError) StatusCode() int { return int(e) }
+
+// scriptedFinder returns the scripted errors in order, then succeeds.
+type scriptedFinder struct {
+	errs  []error
+	calls int
+}
+
+func (f *scriptedFinder) Search(context.Context, string, bookid.SearchOptions) ([]bookid.BookResult, error) {
+	f.calls++
+	if f.calls <= len(f.errs) {
+		return nil, f.errs[f.calls-1]
+	}
+	return []bookid.BookResult{{Title: "Dune"}}, nil
+}
+
+// fakeClock implements Now and Sleep without waiting and records sleeps.
+type fakeClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	sleeps []time.Duration
+}
+
+// newFakeClock returns a clock starting at the real current time so context
+// deadlines derived from it are not already expired.
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Now()}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Sleep(_ context.Context, d time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	c.sleeps = append(c.sleeps, d)
+	return nil
+}
+
+// newFinder returns a Finder over finder driven by clock.
+func newFinder(finder bookid.BookFinder, rps float64, clock *fakeClock) *ratelimit.Finder {
+	f := ratelimit.NewFinder(finder, rps)
+	f.Now = clock.Now
+	f.Sleep = clock.Sleep
+	return f
+}
+
+func TestFinder_Search(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Throttle", func(t *testing.T) {
+		t.Parallel()
+		clock := newFakeClock()
+		f := newFinder(&scriptedFinder{}, 2, clock)
+
+		for range 3 {
+			_, err := f.Search(context.Background(), "dune", bookid.SearchOptions{})
+			require.NoError(t, err)
+		}
+		assert.Equal(t, []time.Duration{500 * time.Millisecond, 500 * time.Millisecond}, clock.sleeps)
+	})
+
+	t.Run("RetryTransient", func(t *testing.T) {
+		t.Parallel()
+		clock := newFakeClock()
+		finder := &scriptedFinder{errs: []error{statusError(http.StatusTooManyRequests), statusError(http.StatusServiceUnavailable)}}
+		f := newFinder(finder, 0, clock)
+		f.BaseDelay, f.MaxDelay = time.Second, time.Minute
+
+		results, err := f.Search(context.Background(), "dune", bookid.SearchOptions{})
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		assert.Equal(t, 3, finder.calls)
+
+		// Exponential backoff with jitter in the upper half of each delay.
+		require.Len(t, clock.sleeps, 2)
+		assert.GreaterOrEqual(t, clock.sleeps[0], 500*time.Millisecond)
+		assert.Less(t, clock.sleeps[0], time.Second)
+		assert.GreaterOrEqual(t, clock.sleeps[1], time.Second)
+		assert.Less(t, clock.sleeps[1], 2*time.Second)
+	})
+
+	t.Run("GiveUpAfterMaxRetries", func(t *testing.T) {
+		t.Parallel()
+		clock := newFakeClock()
+		finder := &scriptedFinder{errs: []error{statusError(500), statusError(500), statusError(500)}}
+		f := newFinder(finder, 0, clock)
+		f.MaxRetries = 2
+
+		_, err := f.Search(context.Background(), "dune", bookid.SearchOptions{})
+		require.Error(t, err)
+		assert.Equal(t, 3, finder.calls)
+	})
+
+	t.Run("NoRetryPermanent", func(t *testing.T) {
+		t.Parallel()
+		finder := &scriptedFinder{errs: []error{statusError(http.StatusBadRequest)}}
+		f := newFinder(finder, 0, newFakeClock())
+
+		_, err := f.Search(context.Background(), "dune", bookid.SearchOptions{})
+		require.Error(t, err)
+		assert.Equal(t, 1, finder.calls)
+	})
+
+	t.Run("RespectDeadline", func(t *testing.T) {
+		t.Parallel()
+		clock := newFakeClock()
+		finder := &scriptedFinder{errs: []error{statusError(http.StatusServiceUnavailable)}}
+		f := newFinder(finder, 0, clock)
+		f.BaseDelay = time.Minute
+
+		ctx, cancel := context.WithDeadline(context.Background(), clock.Now().Add(time.Second))
+		defer cancel()
+
+		_, err := f.Search(ctx, "dune", bookid.SearchOptions{})
+		assert.Equal(t, statusError(http.StatusServiceUnavailable), err)
+		assert.Equal(t, 1, finder.calls)
+		assert.Empty(t, clock.sleeps)
+	})
+}
+
+func TestIsRetryable(t *testing.T) {
+	t.Parallel()
+	assert.True(t, ratelimit.IsRetryable(statusError(http.StatusTooManyRequests)))
+	assert.True(t, ratelimit.IsRetryable(fmt.Errorf("wrapped: %w", statusError(http.StatusBadGateway))))
+	assert.True(t, ratelimit.IsRetryable(bookid.Errorf(bookid.ERATELIMIT, "Slow down.")))
+	assert.True(t, ratelimit.IsRetryable(bookid.Errorf(bookid.EUNAVAILABLE, "Try again later.")))
+	assert.False(t, ratelimit.IsRetryable(bookid.Errorf(bookid.ENOTFOUND, "Not found.")))
+	assert.False(t, ratelimit.IsRetryable(statusError(http.StatusNotFound)))
+	assert.False(t, ratelimit.IsRetryable(errors.New("boom")))
+}
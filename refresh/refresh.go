@@ -0,0 +1,306 @@
+// Package refresh keeps the publications of the catalog current with their
+// providers. Publications are re-fetched by Google Books volume ID when they
+// have one and searched by ISBN otherwise; the fields whose values changed
+// are updated and the time of the refresh is recorded, so stale publications
+// can be found later.
+package refresh
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/asin"
+	"github.com/fwojciec/bookid/classify"
+	"github.com/fwojciec/bookid/doi"
+	"github.com/fwojciec/bookid/isbn"
+	"github.com/fwojciec/bookid/lccn"
+	"github.com/fwojciec/bookid/policy"
+)
+
+// pageSize is the number of publications read from the catalog at a time
+// while looking for stale ones.
+const pageSize = 100
+
+// Ensure service implements interface.
+var _ bookid.RefreshService = (*Service)(nil)
+
+// Service implements the RefreshService interface by re-fetching
+// publications from a BookGetter or BookFinder.
+type Service struct {
+	PublicationService bookid.PublicationService
+
+	// Re-fetches publications by Google Books volume ID. Publications are
+	// searched by ISBN instead if nil.
+	Getter bookid.BookGetter
+
+	// Searches for publications by ISBN. Should not serve cached results.
+	Finder bookid.BookFinder
+
+	// Policies deciding whether new values of fields replace the current
+	// ones. Fields without a policy take the provider's new value.
+	Policies policy.Policies
+
+	// Queues the values policies hold back for review. They are only
+	// reported if nil.
+	ConflictService bookid.ConflictService
+
+	// Returns the current time. Defaults to time.Now().
+	Now func() time.Time
+
+	// Called by RefreshStale after each publication with the number done so
+	// far and the total, if set. An error stops the refresh.
+	Progress func(done, total int) error
+
+	Logger *slog.Logger
+}
+
+// NewService returns a new instance of Service.
+func NewService(pubs bookid.PublicationService, getter bookid.BookGetter, finder bookid.BookFinder) *Service {
+	return &Service{
+		PublicationService: pubs,
+		Getter:             getter,
+		Finder:             finder,
+		Now:                time.Now,
+		Logger:             slog.New(slog.DiscardHandler),
+	}
+}
+
+// RefreshPublication re-fetches a publication and updates the fields the
+// provider has new values for, as merge policies allow. Values the provider
+// no longer has are kept.
+func (s *Service) RefreshPublication(ctx context.Context, id int64) (*bookid.PublicationRefresh, error) {
+	pub, err := s.PublicationService.FindPublicationByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.fetch(ctx, pub)
+	if err != nil {
+		return nil, err
+	}
+
+	upd, changes, conflicts := diff(pub, result, s.Policies)
+	now := s.Now()
+	upd.RefreshedAt = &now
+	if pub, err = s.PublicationService.UpdatePublication(ctx, id, upd); err != nil {
+		return nil, err
+	}
+	if s.ConflictService != nil {
+		for _, c := range conflicts {
+			if err := s.ConflictService.CreateConflict(ctx, c); err != nil {
+				return nil, err
+			}
+		}
+	}
+	s.Logger.DebugContext(ctx, "refreshed publication", "id", id, "provider", result.Provider, "changes", len(changes), "conflicts", len(conflicts))
+	return &bookid.PublicationRefresh{Publication: pub, Changes: changes, Conflicts: conflicts}, nil
+}
+
+// RefreshStale refreshes the publications last refreshed, or created if
+// never refreshed, before the given time. Returns the refreshes and the IDs
+// of the publications their provider no longer has.
+func (s *Service) RefreshStale(ctx context.Context, before time.Time) (refreshed []*bookid.PublicationRefresh, missing []int64, err error) {
+	// Collect the IDs first, as refreshed publications drop out of the
+	// filter while paging through it.
+	var ids []int64
+	for {
+		pubs, _, err := s.PublicationService.FindPublications(ctx, bookid.PublicationFilter{
+			RefreshedBefore: &before,
+			Offset:          len(ids),
+			Limit:           pageSize,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, pub := range pubs {
+			ids = append(ids, pub.ID)
+		}
+		if len(pubs) < pageSize {
+			break
+		}
+	}
+
+	for i, id := range ids {
+		if r, err := s.RefreshPublication(ctx, id); bookid.ErrorCode(err) == bookid.ENOTFOUND {
+			missing = append(missing, id)
+		} else if err != nil {
+			return refreshed, missing, fmt.Errorf("publication %d: %w", id, err)
+		} else {
+			refreshed = append(refreshed, r)
+		}
+		if s.Progress != nil {
+			if err := s.Progress(i+1, len(ids)); err != nil {
+				return refreshed, missing, err
+			}
+		}
+	}
+	return refreshed, missing, nil
+}
+
+// fetch returns the provider's current record of pub: the Google Books
+// volume, if it has one, or else the first search result with the same
+// ISBN or, for audiobooks without one, the same ASIN. Returns ENOTFOUND if
+// there is none.
+func (s *Service) fetch(ctx context.Context, pub *bookid.Publication) (*bookid.BookResult, error) {
+	if pub.GoogleBooksVolumeID != "" && s.Getter != nil {
+		result, err := s.Getter.GetByID(ctx, pub.GoogleBooksVolumeID)
+		if err == nil {
+			return result, nil
+		} else if bookid.ErrorCode(err) != bookid.ENOTFOUND {
+			return nil, err
+		}
+	}
+
+	for _, code := range []string{pub.ISBN13, pub.ISBN10} {
+		if code == "" || s.Finder == nil {
+			continue
+		}
+		results, err := s.Finder.Search(ctx, code, bookid.SearchOptions{IncludeRaw: true})
+		if err != nil && bookid.ErrorCode(err) != bookid.ENOTFOUND {
+			return nil, err
+		}
+		for i := range results {
+			if sameISBN(pub, &results[i]) {
+				return &results[i], nil
+			}
+		}
+	}
+	if pub.ASIN != "" && s.Finder != nil {
+		results, err := s.Finder.Search(ctx, "asin:"+pub.ASIN, bookid.SearchOptions{IncludeRaw: true})
+		if err != nil && bookid.ErrorCode(err) != bookid.ENOTFOUND {
+			return nil, err
+		}
+		for i := range results {
+			if asin.Normalize(results[i].ASIN) == pub.ASIN {
+				return &results[i], nil
+			}
+		}
+	}
+	return nil, bookid.Errorf(bookid.ENOTFOUND, "Publication %d not found by its provider.", pub.ID)
+}
+
+// sameISBN reports whether result has the ISBN-13 or ISBN-10 of pub.
+func sameISBN(pub *bookid.Publication, result *bookid.BookResult) bool {
+	return (pub.ISBN13 != "" && isbn.Normalize(result.ISBN13) == pub.ISBN13) ||
+		(pub.ISBN10 != "" && isbn.Normalize(result.ISBN10) == pub.ISBN10)
+}
+
+// diff returns the update setting the fields of pub that result has other
+// values for, along with the changes by JSON name. Empty values of result
+// are ignored. Fields with a merge policy are only updated if it lets the new
+// value replace the current one, and values it holds back for review are
+// returned as conflicts.
+func diff(pub *bookid.Publication, result *bookid.BookResult, policies policy.Policies) (bookid.PublicationUpdate, map[string]bookid.AuditChange, []*bookid.Conflict) {
+	var upd bookid.PublicationUpdate
+	changes := make(map[string]bookid.AuditChange)
+	var conflicts []*bookid.Conflict
+	replace := func(name, old, v string) bool {
+		if v == "" || v == old {
+			return false
+		} else if _, ok := policies[name]; !ok {
+			return true
+		}
+
+		current := policy.Value{Value: old, Provider: pub.Provenance[name]}
+		candidate := policy.Value{Value: v, Provider: result.FieldProvider(name)}
+		switch policies.Resolve(name, current, candidate, policy.Replace) {
+		case policy.Replace:
+			return true
+		case policy.Review:
+			conflicts = append(conflicts, &bookid.Conflict{
+				PublicationID:    pub.ID,
+				Field:            name,
+				CurrentValue:     current.Value,
+				CurrentProvider:  current.Provider,
+				ProposedValue:    candidate.Value,
+				ProposedProvider: candidate.Provider,
+			})
+		}
+		return false
+	}
+	set := func(name, old, v string, field **string) {
+		if replace(name, old, v) {
+			changes[name] = bookid.AuditChange{Old: old, New: v}
+			*field = &v
+		}
+	}
+	set("isbn10", pub.ISBN10, isbn.Normalize(result.ISBN10), &upd.ISBN10)
+	set("isbn13", pub.ISBN13, isbn.Normalize(result.ISBN13), &upd.ISBN13)
+	set("publisher", pub.Publisher, result.Publisher, &upd.Publisher)
+	set("language", pub.Language, result.Language, &upd.Language)
+	if v := result.Binding; replace("binding", string(pub.Binding), string(v)) {
+		changes["binding"] = bookid.AuditChange{Old: pub.Binding, New: v}
+		upd.Binding = &v
+	}
+	if v := result.PageCount; replace("page_count", number(pub.PageCount), number(v)) {
+		changes["page_count"] = bookid.AuditChange{Old: pub.PageCount, New: v}
+		upd.PageCount = &v
+	}
+	if v := result.DurationMinutes; replace("duration_minutes", number(pub.DurationMinutes), number(v)) {
+		changes["duration_minutes"] = bookid.AuditChange{Old: pub.DurationMinutes, New: v}
+		upd.DurationMinutes = &v
+	}
+	if v := result.Dimensions; !v.IsZero() && v != pub.Dimensions {
+		changes["dimensions"] = bookid.AuditChange{Old: pub.Dimensions, New: v}
+		upd.Dimensions = &v
+	}
+	if v := result.Access; v != nil && !v.IsZero() && *v != pub.Access {
+		changes["access"] = bookid.AuditChange{Old: pub.Access, New: *v}
+		upd.Access = v
+	}
+	if v := result.Classification; v != nil {
+		// Numbers the provider doesn't know are kept.
+		c := classify.Normalize(*v)
+		c.Dewey, c.LCC = cmp.Or(c.Dewey, pub.Classification.Dewey), cmp.Or(c.LCC, pub.Classification.LCC)
+		if c != pub.Classification {
+			changes["classification"] = bookid.AuditChange{Old: pub.Classification, New: c}
+			upd.Classification = &c
+		}
+	}
+	set("oclc_number", pub.OCLCNumber, result.OCLCNumber, &upd.OCLCNumber)
+	set("lccn", pub.LCCN, lccn.Normalize(result.LCCN), &upd.LCCN)
+	set("doi", pub.DOI, doi.Normalize(result.DOI), &upd.DOI)
+	set("asin", pub.ASIN, asin.Normalize(result.ASIN), &upd.ASIN)
+	set("thumbnail_url", pub.ThumbnailURL, result.ThumbnailURL, &upd.ThumbnailURL)
+	set("description", pub.Description, result.Description, &upd.Description)
+	if v := result.TableOfContents; len(v) > 0 && !slices.Equal(v, pub.TableOfContents) {
+		changes["table_of_contents"] = bookid.AuditChange{Old: pub.TableOfContents, New: v}
+		upd.TableOfContents = &v
+	}
+
+	if v := result.PublishedYear; replace("published_year", number(pub.PublishedYear), number(v)) {
+		changes["published_year"] = bookid.AuditChange{Old: pub.PublishedYear, New: v}
+		upd.PublishedYear = &v
+	}
+
+	// Record where each changed field came from.
+	for name := range changes {
+		if p := result.FieldProvider(name); p != "" {
+			if upd.Provenance == nil {
+				upd.Provenance = make(map[string]string)
+			}
+			upd.Provenance[name] = p
+		}
+	}
+
+	// The raw response is kept current but not reported.
+	if v := string(result.GoogleBooksData); v != "" && v != pub.GoogleBooksData {
+		upd.GoogleBooksData = &v
+	}
+	return upd, changes, conflicts
+}
+
+// number returns a year or count as merge policies compare it, empty if
+// unknown.
+func number(n int) string {
+	if n == 0 {
+		return ""
+	}
+	return strconv.Itoa(n)
+}
@@ -0,0 +1,209 @@
+package refresh_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/mock"
+	"github.com/fwojciec/bookid/policy"
+	"github.com/fwojciec/bookid/refresh"
+	"github.com/fwojciec/bookid/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newService returns a refresh service over a new catalog, refreshing at
+// now. Publications are created a year earlier.
+func newService(t *testing.T, getter bookid.BookGetter, finder bookid.BookFinder, now time.Time) (*refresh.Service, *sqlite.DB) {
+	t.Helper()
+	db := sqlite.NewDB(":memory:")
+	db.Now = func() time.Time { return now.AddDate(-1, 0, 0) }
+	require.NoError(t, db.Open())
+	t.Cleanup(func() { _ = db.Close() })
+
+	s := refresh.NewService(sqlite.NewPublicationService(db), getter, finder)
+	s.Now = func() time.Time { return now }
+	return s, db
+}
+
+// createPublication adds pub to the catalog under a new work.
+func createPublication(t *testing.T, db *sqlite.DB, pub *bookid.Publication) *bookid.Publication {
+	t.Helper()
+	ctx := context.Background()
+	work := &bookid.Work{Title: "The Great Gatsby"}
+	require.NoError(t, sqlite.NewWorkService(db).CreateWork(ctx, work))
+	pub.WorkID = work.ID
+	require.NoError(t, sqlite.NewPublicationService(db).CreatePublication(ctx, pub))
+	return pub
+}
+
+func TestService_RefreshPublication(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("by_volume_id", func(t *testing.T) {
+		t.Parallel()
+		getter := &mock.BookGetter{GetByIDFn: func(_ context.Context, id string) (*bookid.BookResult, error) {
+			assert.Equal(t, "iXn5U2IzVH0C", id)
+			return &bookid.BookResult{
+				ISBN13:          "9780743273565",
+				Publisher:       "Scribner",
+				PublishedYear:   2004,
+				GoogleBooksData: []byte(`{"id":"iXn5U2IzVH0C"}`),
+				Provider:        "googlebooks",
+				Provenance:      map[string]string{"published_year": "openlibrary"},
+			}, nil
+		}}
+		s, db := newService(t, getter, nil, now)
+		pub := createPublication(t, db, &bookid.Publication{
+			ISBN13:              "9780743273565",
+			Publisher:           "Simon and Schuster",
+			Language:            "en",
+			GoogleBooksVolumeID: "iXn5U2IzVH0C",
+		})
+
+		r, err := s.RefreshPublication(context.Background(), pub.ID)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]bookid.AuditChange{
+			"publisher":      {Old: "Simon & Schuster", New: "Scribner"},
+			"published_year": {Old: 0, New: 2004},
+		}, r.Changes)
+		assert.Equal(t, "Scribner", r.Publication.Publisher)
+		assert.Equal(t, "en", r.Publication.Language, "values missing from the provider are kept")
+		assert.Equal(t, `{"id":"iXn5U2IzVH0C"}`, r.Publication.GoogleBooksData)
+		assert.Equal(t, map[string]string{"publisher": "googlebooks", "published_year": "openlibrary"}, r.Publication.Provenance)
+		assert.Equal(t, now, r.Publication.RefreshedAt)
+	})
+
+	t.Run("policies", func(t *testing.T) {
+		t.Parallel()
+		getter := &mock.BookGetter{GetByIDFn: func(context.Context, string) (*bookid.BookResult, error) {
+			return &bookid.BookResult{
+				Publisher:     "Scribner",
+				PublishedYear: 1925,
+				Language:      "fr",
+				ThumbnailURL:  "https://example.com/new.jpg",
+				Provider:      "googlebooks",
+			}, nil
+		}}
+		s, db := newService(t, getter, nil, now)
+		s.Policies = policy.Policies{
+			"publisher":      {Rule: policy.RulePrefer, Providers: []string{"isbndb", "googlebooks"}},
+			"published_year": {Rule: policy.RuleNewest},
+			"language":       {Rule: policy.RuleReview},
+		}
+		s.ConflictService = sqlite.NewConflictService(db)
+		pub := createPublication(t, db, &bookid.Publication{
+			Publisher:           "Simon and Schuster",
+			PublishedYear:       2004,
+			Language:            "en",
+			ThumbnailURL:        "https://example.com/old.jpg",
+			GoogleBooksVolumeID: "iXn5U2IzVH0C",
+			Provenance:          map[string]string{"publisher": "isbndb"},
+		})
+
+		r, err := s.RefreshPublication(context.Background(), pub.ID)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]bookid.AuditChange{
+			"thumbnail_url": {Old: "https://example.com/old.jpg", New: "https://example.com/new.jpg"},
+		}, r.Changes, "fields without a policy take the new value")
+		assert.Equal(t, "Simon & Schuster", r.Publication.Publisher)
+		assert.Equal(t, 2004, r.Publication.PublishedYear)
+		assert.Equal(t, "en", r.Publication.Language)
+
+		require.Len(t, r.Conflicts, 1)
+		assert.NotZero(t, r.Conflicts[0].ID)
+		assert.Equal(t, "language", r.Conflicts[0].Field)
+		assert.Equal(t, "fr", r.Conflicts[0].ProposedValue)
+		assert.Equal(t, "googlebooks", r.Conflicts[0].ProposedProvider)
+	})
+
+	t.Run("by_isbn", func(t *testing.T) {
+		t.Parallel()
+		finder := &mock.BookFinder{SearchFn: func(_ context.Context, query string, opts bookid.SearchOptions) ([]bookid.BookResult, error) {
+			assert.Equal(t, "9780743273565", query)
+			assert.True(t, opts.IncludeRaw)
+			return []bookid.BookResult{
+				{ISBN13: "9780743273565", Publisher: "Scribner"},
+			}, nil
+		}}
+		s, db := newService(t, nil, finder, now)
+		pub := createPublication(t, db, &bookid.Publication{ISBN13: "9780743273565", Publisher: "Scribner"})
+
+		r, err := s.RefreshPublication(context.Background(), pub.ID)
+		require.NoError(t, err)
+		assert.Empty(t, r.Changes)
+		assert.Equal(t, now, r.Publication.RefreshedAt)
+	})
+
+	t.Run("by_asin", func(t *testing.T) {
+		t.Parallel()
+		finder := &mock.BookFinder{SearchFn: func(_ context.Context, query string, _ bookid.SearchOptions) ([]bookid.BookResult, error) {
+			assert.Equal(t, "asin:B08G9PRS1K", query)
+			return []bookid.BookResult{{ASIN: "B08G9PRS1K", DurationMinutes: 970}}, nil
+		}}
+		s, db := newService(t, nil, finder, now)
+		pub := createPublication(t, db, &bookid.Publication{ASIN: "B08G9PRS1K", Binding: bookid.BindingAudiobook})
+
+		r, err := s.RefreshPublication(context.Background(), pub.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 970, r.Publication.DurationMinutes)
+	})
+
+	t.Run("volume_gone", func(t *testing.T) {
+		t.Parallel()
+		getter := &mock.BookGetter{GetByIDFn: func(context.Context, string) (*bookid.BookResult, error) {
+			return nil, bookid.Errorf(bookid.ENOTFOUND, "Not found.")
+		}}
+		finder := &mock.BookFinder{SearchFn: func(context.Context, string, bookid.SearchOptions) ([]bookid.BookResult, error) {
+			return []bookid.BookResult{{ISBN13: "9780141182636"}}, nil
+		}}
+		s, db := newService(t, getter, finder, now)
+		pub := createPublication(t, db, &bookid.Publication{ISBN13: "9780743273565", GoogleBooksVolumeID: "iXn5U2IzVH0C"})
+
+		_, err := s.RefreshPublication(context.Background(), pub.ID)
+		assert.Equal(t, bookid.ENOTFOUND, bookid.ErrorCode(err), "results for other ISBNs are ignored")
+	})
+
+	t.Run("provider_error", func(t *testing.T) {
+		t.Parallel()
+		getter := &mock.BookGetter{GetByIDFn: func(context.Context, string) (*bookid.BookResult, error) {
+			return nil, bookid.Errorf(bookid.EUNAVAILABLE, "Unavailable.")
+		}}
+		s, db := newService(t, getter, nil, now)
+		pub := createPublication(t, db, &bookid.Publication{GoogleBooksVolumeID: "iXn5U2IzVH0C"})
+
+		_, err := s.RefreshPublication(context.Background(), pub.ID)
+		assert.Equal(t, bookid.EUNAVAILABLE, bookid.ErrorCode(err))
+	})
+}
+
+func TestService_RefreshStale(t *testing.T) {
+	t.Parallel()
+
+	finder := &mock.BookFinder{SearchFn: func(_ context.Context, query string, _ bookid.SearchOptions) ([]bookid.BookResult, error) {
+		if query == "9780743273565" {
+			return []bookid.BookResult{{ISBN13: query, Publisher: "Scribner"}}, nil
+		}
+		return nil, bookid.Errorf(bookid.ENOTFOUND, "No results.")
+	}}
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	s, db := newService(t, nil, finder, now)
+	found := createPublication(t, db, &bookid.Publication{ISBN13: "9780743273565"})
+	gone := createPublication(t, db, &bookid.Publication{ISBN13: "9780141182636"})
+
+	refreshed, missing, err := s.RefreshStale(context.Background(), now)
+	require.NoError(t, err)
+	require.Len(t, refreshed, 1)
+	assert.Equal(t, found.ID, refreshed[0].Publication.ID)
+	assert.Equal(t, []int64{gone.ID}, missing)
+
+	// Refreshed publications are no longer stale.
+	refreshed, missing, err = s.RefreshStale(context.Background(), now)
+	require.NoError(t, err)
+	assert.Empty(t, refreshed)
+	assert.Equal(t, []int64{gone.ID}, missing)
+}
@@ -0,0 +1,104 @@
+package bookid
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ProviderConfig represents the credentials and endpoint of a provider. Each
+// provider uses the fields it needs.
+type ProviderConfig struct {
+	APIKey       string
+	ClientID     string
+	ClientSecret string
+	URL          string
+	RecordSchema string
+
+	// Country the provider searches as unless a search sets one, as an ISO
+	// 3166-1 alpha-2 code, for providers whose results depend on it.
+	Country string
+
+	// Longest time a search of the provider may take before the next
+	// provider is tried. Zero shares the overall timeout among providers.
+	Timeout time.Duration
+
+	// Receives debug logs from providers that support logging. Nil
+	// discards them.
+	Logger *slog.Logger
+
+	// Client used for requests to the provider's API, such as one
+	// instrumented for tracing. Nil uses http.DefaultClient.
+	HTTPClient *http.Client
+}
+
+// Client returns the HTTP client configured for the provider, or
+// http.DefaultClient if there is none.
+func (c ProviderConfig) Client() *http.Client {
+	if c.HTTPClient == nil {
+		return http.DefaultClient
+	}
+	return c.HTTPClient
+}
+
+// FinderFactory creates the BookFinder of a provider from its configuration.
+// Returns EUNAUTHORIZED if required credentials are missing, so that
+// providers which are not set up can be skipped.
+type FinderFactory func(config ProviderConfig) (BookFinder, error)
+
+// finders holds the registered provider factories by name. Provider packages
+// register themselves when imported, like database/sql drivers, so it is the
+// one piece of package-level state.
+//
+//nolint:gochecknoglobals // Populated by provider packages' init functions.
+var finders = struct {
+	sync.RWMutex
+	factories map[string]FinderFactory
+}{factories: make(map[string]FinderFactory)}
+
+// RegisterFinder makes a provider available by name. It is meant to be called
+// from the init function of provider packages and panics if the name is
+// registered twice or factory is nil.
+func RegisterFinder(name string, factory FinderFactory) {
+	finders.Lock()
+	defer finders.Unlock()
+	if factory == nil {
+		panic("bookid: RegisterFinder factory is nil")
+	} else if _, dup := finders.factories[name]; dup {
+		panic("bookid: RegisterFinder called twice for provider " + name)
+	}
+	finders.factories[name] = factory
+}
+
+// NewFinder returns the BookFinder of the provider registered under name.
+// Returns EINVALID if no such provider is registered.
+func NewFinder(name string, config ProviderConfig) (BookFinder, error) {
+	finders.RLock()
+	factory, ok := finders.factories[name]
+	finders.RUnlock()
+	if !ok {
+		return nil, Errorf(EINVALID, "Unknown provider %q; expected one of %s.", name, strings.Join(Finders(), ", "))
+	}
+
+	finder, err := factory(config)
+	if err != nil {
+		return nil, fmt.Errorf("creating %s provider: %w", name, err)
+	}
+	return finder, nil
+}
+
+// Finders returns the names of the registered providers in sorted order.
+func Finders() []string {
+	finders.RLock()
+	defer finders.RUnlock()
+	names := make([]string, 0, len(finders.factories))
+	for name := range finders.factories {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
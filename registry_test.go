@@ -0,0 +1,76 @@
+package bookid_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/fwojciec/bookid"
+)
+
+// staticFinder returns its results for any query.
+type staticFinder []bookid.BookResult
+
+func (f staticFinder) Search(context.Context, string, bookid.SearchOptions) ([]bookid.BookResult, error) {
+	return f, nil
+}
+
+func TestRegisterFinder(t *testing.T) {
+	t.Parallel()
+
+	// The registry is shared, so names are unique to this test.
+	bookid.RegisterFinder("test-static", func(config bookid.ProviderConfig) (bookid.BookFinder, error) {
+		return staticFinder{{Title: config.APIKey}}, nil
+	})
+	bookid.RegisterFinder("test-keyless", func(bookid.ProviderConfig) (bookid.BookFinder, error) {
+		return nil, bookid.Errorf(bookid.EUNAUTHORIZED, "API key is not configured.")
+	})
+
+	t.Run("NewFinder", func(t *testing.T) {
+		t.Parallel()
+		finder, err := bookid.NewFinder("test-static", bookid.ProviderConfig{APIKey: "Dune"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		results, err := finder.Search(context.Background(), "", bookid.SearchOptions{})
+		if err != nil {
+			t.Fatal(err)
+		} else if len(results) != 1 || results[0].Title != "Dune" {
+			t.Fatalf("unexpected results: %#v", results)
+		}
+	})
+
+	t.Run("Finders", func(t *testing.T) {
+		t.Parallel()
+		names := bookid.Finders()
+		if !slices.Contains(names, "test-static") || !slices.Contains(names, "test-keyless") {
+			t.Fatalf("missing registered providers: %v", names)
+		} else if !slices.IsSorted(names) {
+			t.Fatalf("names not sorted: %v", names)
+		}
+	})
+
+	t.Run("ErrFactory", func(t *testing.T) {
+		t.Parallel()
+		if _, err := bookid.NewFinder("test-keyless", bookid.ProviderConfig{}); bookid.ErrorCode(err) != bookid.EUNAUTHORIZED {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("ErrUnknown", func(t *testing.T) {
+		t.Parallel()
+		if _, err := bookid.NewFinder("test-missing", bookid.ProviderConfig{}); bookid.ErrorCode(err) != bookid.EINVALID {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("PanicsOnDuplicate", func(t *testing.T) {
+		t.Parallel()
+		defer func() {
+			if recover() == nil {
+				t.Fatal("expected panic")
+			}
+		}()
+		bookid.RegisterFinder("test-static", func(bookid.ProviderConfig) (bookid.BookFinder, error) { return staticFinder{}, nil })
+	})
+}
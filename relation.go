@@ -0,0 +1,93 @@
+package bookid
+
+import (
+	"context"
+	"time"
+)
+
+// WorkRelationType describes how one work derives from another.
+type WorkRelationType string
+
+const (
+	WorkRelationTranslationOf WorkRelationType = "translation_of"
+	WorkRelationAdaptationOf  WorkRelationType = "adaptation_of"
+	WorkRelationAbridgementOf WorkRelationType = "abridgement_of"
+)
+
+// Valid returns true if t is a known relation type.
+func (t WorkRelationType) Valid() bool {
+	switch t {
+	case WorkRelationTranslationOf, WorkRelationAdaptationOf, WorkRelationAbridgementOf:
+		return true
+	}
+	return false
+}
+
+// WorkRelation links a derived work to the work it derives from, e.g. a
+// Polish translation to its English original.
+type WorkRelation struct {
+	ID            int64            `json:"id"`
+	WorkID        int64            `json:"work_id"`         // The derived work (translation, adaptation, ...)
+	RelatedWorkID int64            `json:"related_work_id"` // The work it derives from
+	Type          WorkRelationType `json:"type"`            // How WorkID relates to RelatedWorkID
+	CreatedAt     time.Time        `json:"created_at"`
+}
+
+// Validate returns an error if the relation contains invalid fields.
+func (r *WorkRelation) Validate() error {
+	if r.WorkID == 0 || r.RelatedWorkID == 0 {
+		return Errorf(EINVALID, "Both works are required.")
+	} else if r.WorkID == r.RelatedWorkID {
+		return Errorf(EINVALID, "A work cannot be related to itself.")
+	} else if !r.Type.Valid() {
+		return Errorf(EINVALID, "Invalid relation type: %q.", r.Type)
+	}
+	return nil
+}
+
+// WorkRelationSuggestion is a relation the catalog believes is likely but
+// which has not been confirmed by the user.
+type WorkRelationSuggestion struct {
+	WorkRelation
+	Reason string `json:"reason"` // Human-readable explanation of why the link was suggested
+}
+
+// WorkRelationFilter represents a filter used by FindWorkRelations.
+type WorkRelationFilter struct {
+	ID            *int64
+	WorkID        *int64
+	RelatedWorkID *int64
+	Type          *WorkRelationType
+
+	// Restrict to subset of results.
+	Offset int
+	Limit  int
+}
+
+// WorkRelationService represents a service for managing links between works.
+type WorkRelationService interface {
+	// CreateWorkRelation links two works. Returns ECONFLICT if the same
+	// relation already exists.
+	CreateWorkRelation(ctx context.Context, rel *WorkRelation) error
+
+	// FindWorkRelations retrieves relations matching the filter along with the
+	// total number of matches, ignoring Offset and Limit.
+	FindWorkRelations(ctx context.Context, filter WorkRelationFilter) ([]*WorkRelation, int, error)
+
+	// DeleteWorkRelation permanently removes a relation.
+	DeleteWorkRelation(ctx context.Context, id int64) error
+
+	// SuggestTranslations proposes translation links between works that share
+	// a title but have publications in different languages, and from works
+	// whose original title names another work. Pairs that are already
+	// related are not suggested again.
+	SuggestTranslations(ctx context.Context) ([]*WorkRelationSuggestion, error)
+
+	// LinkTranslation links a work to the original-language work it
+	// translates in a single transaction: the translation takes the title
+	// and language of the original as its own original title and language,
+	// and the translators are linked to it in their role. Returns ENOTFOUND
+	// if either work does not exist and ECONFLICT if the works are already
+	// linked as such.
+	LinkTranslation(ctx context.Context, workID, originalID int64, translators []string) (*WorkRelation, error)
+}
@@ -0,0 +1,43 @@
+package bookid_test
+
+import (
+	"testing"
+
+	"github.com/fwojciec/bookid"
+)
+
+func TestWorkRelation_Validate(t *testing.T) {
+	t.Parallel()
+
+	t.Run("OK", func(t *testing.T) {
+		t.Parallel()
+		rel := &bookid.WorkRelation{WorkID: 2, RelatedWorkID: 1, Type: bookid.WorkRelationTranslationOf}
+		if err := rel.Validate(); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("ErrWorkRequired", func(t *testing.T) {
+		t.Parallel()
+		rel := &bookid.WorkRelation{WorkID: 2, Type: bookid.WorkRelationTranslationOf}
+		if code := bookid.ErrorCode(rel.Validate()); code != bookid.EINVALID {
+			t.Fatalf("ErrorCode()=%q, want %q", code, bookid.EINVALID)
+		}
+	})
+
+	t.Run("ErrSelfRelation", func(t *testing.T) {
+		t.Parallel()
+		rel := &bookid.WorkRelation{WorkID: 1, RelatedWorkID: 1, Type: bookid.WorkRelationAdaptationOf}
+		if code := bookid.ErrorCode(rel.Validate()); code != bookid.EINVALID {
+			t.Fatalf("ErrorCode()=%q, want %q", code, bookid.EINVALID)
+		}
+	})
+
+	t.Run("ErrUnknownType", func(t *testing.T) {
+		t.Parallel()
+		rel := &bookid.WorkRelation{WorkID: 2, RelatedWorkID: 1, Type: "sequel_of"}
+		if code := bookid.ErrorCode(rel.Validate()); code != bookid.EINVALID {
+			t.Fatalf("ErrorCode()=%q, want %q", code, bookid.EINVALID)
+		}
+	})
+}
@@ -0,0 +1,35 @@
+package render
+
+import (
+	"encoding/csv"
+	"io"
+
+	"github.com/fwojciec/bookid"
+)
+
+// CSVRenderer writes a header row followed by one row per result.
+type CSVRenderer struct {
+	// Fields to emit as columns. If empty, all fields are emitted.
+	Fields []string
+}
+
+// Render implements Renderer.
+func (r *CSVRenderer) Render(w io.Writer, _ string, results []bookid.BookResult) error {
+	fields := orDefault(r.Fields)
+	cw := csv.NewWriter(w)
+
+	if err := cw.Write(fields); err != nil {
+		return err
+	}
+	for i := range results {
+		row := make([]string, len(fields))
+		for j, f := range fields {
+			row[j] = text(&results[i], f)
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
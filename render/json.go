@@ -0,0 +1,41 @@
+package render
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/fwojciec/bookid"
+)
+
+// JSONRenderer writes the query and results as a pretty-printed JSON object.
+type JSONRenderer struct {
+	// Fields to emit. If empty, the full results are emitted.
+	Fields []string
+}
+
+// Render implements Renderer.
+func (r *JSONRenderer) Render(w io.Writer, query string, results []bookid.BookResult) error {
+	var v any = results
+	if len(r.Fields) > 0 {
+		records := make([]record, len(results))
+		for i := range results {
+			records[i] = record{fields: r.Fields, result: &results[i]}
+		}
+		v = records
+	}
+	if results == nil {
+		v = []bookid.BookResult{}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(struct {
+		Query   string `json:"query"`
+		Results any    `json:"results"`
+	}{query, v}); err != nil {
+		return fmt.Errorf("encoding JSON output: %w", err)
+	}
+	return nil
+}
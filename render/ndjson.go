@@ -0,0 +1,33 @@
+package render
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/fwojciec/bookid"
+)
+
+// NDJSONRenderer writes one compact JSON object per result and line, for
+// piping into line-oriented tools such as jq. Each result is written as soon
+// as it is encoded.
+type NDJSONRenderer struct {
+	// Fields to emit. If empty, the full results are emitted.
+	Fields []string
+}
+
+// Render implements Renderer.
+func (r *NDJSONRenderer) Render(w io.Writer, _ string, results []bookid.BookResult) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	for i := range results {
+		var v any = &results[i]
+		if len(r.Fields) > 0 {
+			v = record{fields: r.Fields, result: &results[i]}
+		}
+		if err := encoder.Encode(v); err != nil {
+			return fmt.Errorf("encoding NDJSON output: %w", err)
+		}
+	}
+	return nil
+}
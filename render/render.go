@@ -0,0 +1,225 @@
+// Package render writes book results in the output formats supported by the
+// CLI: pretty JSON, NDJSON, a human-readable table, YAML and CSV.
+package render
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/fwojciec/bookid"
+)
+
+// Output formats supported by New.
+const (
+	FormatJSON   = "json"
+	FormatNDJSON = "ndjson"
+	FormatTable  = "table"
+	FormatYAML   = "yaml"
+	FormatCSV    = "csv"
+)
+
+// Renderer writes the results of a search for query.
+type Renderer interface {
+	Render(w io.Writer, query string, results []bookid.BookResult) error
+}
+
+// Formats returns the names of the supported output formats.
+func Formats() []string {
+	return []string{FormatJSON, FormatNDJSON, FormatTable, FormatYAML, FormatCSV}
+}
+
+// Fields returns the names of the selectable BookResult fields in their
+// default output order. Names match the JSON field names.
+func Fields() []string {
+	return []string{
+		"title",
+		"authors",
+		"isbn10",
+		"isbn13",
+		"publisher",
+		"published_year",
+		"language",
+		"binding",
+		"page_count",
+		"duration_minutes",
+		"google_books_volume_id",
+		"oclc_number",
+		"lccn",
+		"doi",
+		"asin",
+		"thumbnail_url",
+		"web_reader_url",
+		"dewey",
+		"lcc",
+		"provider",
+		"confidence",
+		"search_type",
+	}
+}
+
+// New returns the renderer for format emitting the given fields. If fields is
+// empty, all fields are emitted. Returns EINVALID for unknown formats or
+// fields.
+func New(format string, fields []string) (Renderer, error) {
+	if err := validateFields(fields); err != nil {
+		return nil, err
+	}
+
+	switch format {
+	case FormatJSON:
+		return &JSONRenderer{Fields: fields}, nil
+	case FormatNDJSON:
+		return &NDJSONRenderer{Fields: fields}, nil
+	case FormatTable:
+		return &TableRenderer{Fields: fields}, nil
+	case FormatYAML:
+		return &YAMLRenderer{Fields: fields}, nil
+	case FormatCSV:
+		return &CSVRenderer{Fields: fields}, nil
+	}
+	return nil, bookid.Errorf(bookid.EINVALID, "Unknown format %q, must be one of: %s.", format, strings.Join(Formats(), ", "))
+}
+
+// ParseFields splits a comma-separated list of field names.
+func ParseFields(s string) []string {
+	var fields []string
+	for _, f := range strings.Split(s, ",") {
+		if f = strings.TrimSpace(f); f != "" {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+// validateFields returns EINVALID if any name is not a selectable field.
+func validateFields(fields []string) error {
+	known := make(map[string]bool)
+	for _, f := range Fields() {
+		known[f] = true
+	}
+	for _, f := range fields {
+		if !known[f] {
+			return bookid.Errorf(bookid.EINVALID, "Unknown field %q, must be one of: %s.", f, strings.Join(Fields(), ", "))
+		}
+	}
+	return nil
+}
+
+// orDefault returns fields, or all fields if none are selected.
+func orDefault(fields []string) []string {
+	if len(fields) == 0 {
+		return Fields()
+	}
+	return fields
+}
+
+// value returns the named field of r in its natural type.
+func value(r *bookid.BookResult, field string) any {
+	switch field {
+	case "title":
+		return r.Title
+	case "authors":
+		if r.Authors == nil {
+			return []string{}
+		}
+		return r.Authors
+	case "isbn10":
+		return r.ISBN10
+	case "isbn13":
+		return r.ISBN13
+	case "publisher":
+		return r.Publisher
+	case "published_year":
+		return r.PublishedYear
+	case "language":
+		return r.Language
+	case "binding":
+		return string(r.Binding)
+	case "page_count":
+		return r.PageCount
+	case "duration_minutes":
+		return r.DurationMinutes
+	case "google_books_volume_id":
+		return r.GoogleBooksVolumeID
+	case "oclc_number":
+		return r.OCLCNumber
+	case "lccn":
+		return r.LCCN
+	case "doi":
+		return r.DOI
+	case "asin":
+		return r.ASIN
+	case "thumbnail_url":
+		return r.ThumbnailURL
+	case "web_reader_url":
+		if r.Access == nil {
+			return ""
+		}
+		return r.Access.WebReaderURL
+	case "dewey":
+		if r.Classification == nil {
+			return ""
+		}
+		return r.Classification.Dewey
+	case "lcc":
+		if r.Classification == nil {
+			return ""
+		}
+		return r.Classification.LCC
+	case "provider":
+		return r.Provider
+	case "confidence":
+		return r.Confidence
+	case "search_type":
+		return string(r.SearchType)
+	}
+	return nil
+}
+
+// text returns the named field of r formatted as a single line of text.
+func text(r *bookid.BookResult, field string) string {
+	switch v := value(r, field).(type) {
+	case []string:
+		return strings.Join(v, "; ")
+	case int:
+		if v == 0 {
+			return ""
+		}
+		return strconv.Itoa(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', 2, 64)
+	case string:
+		return v
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// record is an ordered set of selected fields of a result.
+type record struct {
+	fields []string
+	result *bookid.BookResult
+}
+
+// MarshalJSON encodes the selected fields as an object in field order.
+func (rec record) MarshalJSON() ([]byte, error) {
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, f := range rec.fields {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		v, err := json.Marshal(value(rec.result, f))
+		if err != nil {
+			return nil, err
+		}
+		b.WriteString(strconv.Quote(f))
+		b.WriteByte(':')
+		b.Write(v)
+	}
+	b.WriteByte('}')
+	return []byte(b.String()), nil
+}
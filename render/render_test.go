@@ -0,0 +1,130 @@
+package render_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/render"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// results returns sample search results.
+func results() []bookid.BookResult {
+	return []bookid.BookResult{
+		{
+			Title:         "The Great Gatsby",
+			Authors:       []string{"F. Scott Fitzgerald"},
+			ISBN13:        "9780743273565",
+			Publisher:     "Scribner",
+			PublishedYear: 2004,
+			Confidence:    0.95,
+			SearchType:    bookid.SearchTypeISBN,
+		},
+		{
+			Title:         "Gatsby",
+			Authors:       []string{"Nick Carraway", "Jay Gatsby"},
+			PublishedYear: 2013,
+			Confidence:    0.5,
+			SearchType:    bookid.SearchTypeISBN,
+		},
+	}
+}
+
+// mustRender renders results with a new renderer for format and fields.
+func mustRender(tb testing.TB, format string, fields []string, results []bookid.BookResult) string {
+	tb.Helper()
+	r, err := render.New(format, fields)
+	require.NoError(tb, err)
+	var buf bytes.Buffer
+	require.NoError(tb, r.Render(&buf, "gatsby", results))
+	return buf.String()
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	_, err := render.New("xml", nil)
+	assert.Equal(t, bookid.EINVALID, bookid.ErrorCode(err))
+
+	_, err = render.New(render.FormatJSON, []string{"title", "price"})
+	assert.Equal(t, bookid.EINVALID, bookid.ErrorCode(err))
+}
+
+func TestParseFields(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, []string{"title", "isbn13"}, render.ParseFields(" title, ,isbn13 "))
+	assert.Empty(t, render.ParseFields(""))
+}
+
+func TestJSONRenderer(t *testing.T) {
+	t.Parallel()
+
+	t.Run("AllFields", func(t *testing.T) {
+		t.Parallel()
+		got := mustRender(t, render.FormatJSON, nil, results())
+		assert.Contains(t, got, `"query": "gatsby"`)
+		assert.Contains(t, got, `"isbn13": "9780743273565"`)
+		assert.Contains(t, got, `"search_type": "isbn"`)
+	})
+
+	t.Run("SelectedFields", func(t *testing.T) {
+		t.Parallel()
+		got := mustRender(t, render.FormatJSON, []string{"isbn13", "title"}, results()[:1])
+		assert.JSONEq(t, `{"query":"gatsby","results":[{"isbn13":"9780743273565","title":"The Great Gatsby"}]}`, got)
+	})
+
+	t.Run("NoResults", func(t *testing.T) {
+		t.Parallel()
+		got := mustRender(t, render.FormatJSON, nil, nil)
+		assert.JSONEq(t, `{"query":"gatsby","results":[]}`, got)
+	})
+}
+
+func TestNDJSONRenderer(t *testing.T) {
+	t.Parallel()
+
+	got := mustRender(t, render.FormatNDJSON, []string{"title", "published_year"}, results())
+	assert.Equal(t, ""+
+		`{"title":"The Great Gatsby","published_year":2004}`+"\n"+
+		`{"title":"Gatsby","published_year":2013}`+"\n", got)
+
+	assert.Empty(t, mustRender(t, render.FormatNDJSON, nil, nil))
+}
+
+func TestTableRenderer(t *testing.T) {
+	t.Parallel()
+
+	got := mustRender(t, render.FormatTable, []string{"title", "authors", "confidence"}, results())
+	assert.Equal(t, ""+
+		"TITLE             AUTHORS                    CONFIDENCE\n"+
+		"The Great Gatsby  F. Scott Fitzgerald        0.95\n"+
+		"Gatsby            Nick Carraway; Jay Gatsby  0.50\n", got)
+
+	got = mustRender(t, render.FormatTable, []string{"title", "isbn13"}, results())
+	assert.Equal(t, ""+
+		"TITLE             ISBN13\n"+
+		"The Great Gatsby  978-0-7432-7356-5\n"+
+		"Gatsby            \n", got, "ISBNs are hyphenated")
+
+	assert.Equal(t, "No results for \"gatsby\".\n", mustRender(t, render.FormatTable, nil, nil))
+}
+
+func TestYAMLRenderer(t *testing.T) {
+	t.Parallel()
+
+	got := mustRender(t, render.FormatYAML, []string{"title", "authors", "published_year"}, results()[:1])
+	assert.Equal(t, "query: gatsby\nresults:\n  - title: The Great Gatsby\n    authors:\n      - F. Scott Fitzgerald\n    published_year: 2004\n", got)
+
+	assert.Equal(t, "query: gatsby\nresults: []\n", mustRender(t, render.FormatYAML, nil, nil))
+}
+
+func TestCSVRenderer(t *testing.T) {
+	t.Parallel()
+
+	got := mustRender(t, render.FormatCSV, []string{"title", "isbn13", "published_year"}, results())
+	assert.Equal(t, "title,isbn13,published_year\nThe Great Gatsby,9780743273565,2004\nGatsby,,2013\n", got)
+
+	assert.Equal(t, "title\n", mustRender(t, render.FormatCSV, []string{"title"}, nil))
+}
@@ -0,0 +1,47 @@
+package render
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/isbn"
+)
+
+// TableRenderer writes results as aligned columns with a header row. ISBNs are
+// hyphenated for readability.
+type TableRenderer struct {
+	// Fields to emit as columns. If empty, all fields are emitted.
+	Fields []string
+}
+
+// Render implements Renderer.
+func (r *TableRenderer) Render(w io.Writer, query string, results []bookid.BookResult) error {
+	if len(results) == 0 {
+		_, err := fmt.Fprintf(w, "No results for %q.\n", query)
+		return err
+	}
+
+	fields := orDefault(r.Fields)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	header := make([]string, len(fields))
+	for i, f := range fields {
+		header[i] = strings.ToUpper(f)
+	}
+	fmt.Fprintln(tw, strings.Join(header, "\t"))
+
+	for i := range results {
+		row := make([]string, len(fields))
+		for j, f := range fields {
+			row[j] = text(&results[i], f)
+			if f == "isbn13" || f == "isbn10" {
+				row[j] = isbn.Format(row[j])
+			}
+		}
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
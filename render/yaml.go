@@ -0,0 +1,46 @@
+package render
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/fwojciec/bookid"
+	"gopkg.in/yaml.v3"
+)
+
+// YAMLRenderer writes the query and results as a YAML document.
+type YAMLRenderer struct {
+	// Fields to emit. If empty, all fields are emitted.
+	Fields []string
+}
+
+// Render implements Renderer.
+func (r *YAMLRenderer) Render(w io.Writer, query string, results []bookid.BookResult) error {
+	seq := &yaml.Node{Kind: yaml.SequenceNode}
+	for i := range results {
+		m := &yaml.Node{Kind: yaml.MappingNode}
+		for _, f := range orDefault(r.Fields) {
+			var v yaml.Node
+			if err := v.Encode(value(&results[i], f)); err != nil {
+				return fmt.Errorf("encoding YAML field %s: %w", f, err)
+			}
+			m.Content = append(m.Content, scalar(f), &v)
+		}
+		seq.Content = append(seq.Content, m)
+	}
+
+	doc := &yaml.Node{Kind: yaml.MappingNode}
+	doc.Content = append(doc.Content, scalar("query"), scalar(query), scalar("results"), seq)
+
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(doc); err != nil {
+		return fmt.Errorf("encoding YAML output: %w", err)
+	}
+	return encoder.Close()
+}
+
+// scalar returns a string scalar node.
+func scalar(s string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: s}
+}
@@ -0,0 +1,94 @@
+package bookid
+
+import (
+	"context"
+	"time"
+)
+
+// ReviewState represents the state of a review in the queue.
+type ReviewState string
+
+// Review states.
+const (
+	ReviewStatePending   ReviewState = "pending"   // Awaiting a decision
+	ReviewStateSaved     ReviewState = "saved"     // A candidate was chosen and saved
+	ReviewStateDismissed ReviewState = "dismissed" // None of the candidates was right
+)
+
+// Valid returns true if s is a known review state.
+func (s ReviewState) Valid() bool {
+	switch s {
+	case ReviewStatePending, ReviewStateSaved, ReviewStateDismissed:
+		return true
+	default:
+		return false
+	}
+}
+
+// Review represents a book that batch or import could not identify with
+// enough confidence to save it. Its candidates are kept until the user picks
+// the right one or dismisses them all.
+type Review struct {
+	ID         int64        `json:"id"`
+	Source     string       `json:"source"` // What queued the review, e.g. "import"
+	Line       int          `json:"line,omitempty"`
+	Query      string       `json:"query"`
+	Candidates []BookResult `json:"candidates"` // Best first
+	State      ReviewState  `json:"state"`
+
+	// Set once a candidate is saved.
+	Choice        int   `json:"choice,omitempty"` // 1-based index of the candidate
+	WorkID        int64 `json:"work_id,omitempty"`
+	PublicationID int64 `json:"publication_id,omitempty"`
+
+	CreatedAt  time.Time `json:"created_at"`
+	ResolvedAt time.Time `json:"resolved_at,omitzero"` // Zero while pending
+}
+
+// Validate returns an error if the review contains invalid fields.
+func (r *Review) Validate() error {
+	if r.Query == "" {
+		return Errorf(EINVALID, "Review query required.")
+	} else if len(r.Candidates) == 0 {
+		return Errorf(EINVALID, "Review candidates required.")
+	} else if !r.State.Valid() {
+		return Errorf(EINVALID, "Invalid review state %q.", r.State)
+	}
+	return nil
+}
+
+// ReviewService represents a service for managing the queue of
+// low-confidence identifications awaiting review.
+type ReviewService interface {
+	// FindReviewByID retrieves a single review by ID.
+	// Returns ENOTFOUND if the review does not exist.
+	FindReviewByID(ctx context.Context, id int64) (*Review, error)
+
+	// FindReviews retrieves a list of reviews matching the filter along with
+	// the total number of matches, ignoring Offset and Limit.
+	FindReviews(ctx context.Context, filter ReviewFilter) ([]*Review, int, error)
+
+	// CreateReview queues a review in the pending state.
+	CreateReview(ctx context.Context, review *Review) error
+
+	// SaveReview saves the candidate at the 1-based index choice to the
+	// catalog and closes the review. Returns ENOTFOUND if the review does
+	// not exist, EINVALID if there is no such candidate and ECONFLICT if the
+	// review is no longer pending.
+	SaveReview(ctx context.Context, id int64, choice int) (*Review, error)
+
+	// DismissReview closes a review without saving any of its candidates.
+	// Returns ENOTFOUND if the review does not exist and ECONFLICT if it is
+	// no longer pending.
+	DismissReview(ctx context.Context, id int64) (*Review, error)
+}
+
+// ReviewFilter represents a filter used by FindReviews.
+type ReviewFilter struct {
+	ID    *int64
+	State *ReviewState
+
+	// Restrict to subset of results.
+	Offset int
+	Limit  int
+}
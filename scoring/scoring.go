@@ -0,0 +1,86 @@
+// Package scoring computes result confidence from independent, weighted
+// signals such as search type, data completeness and how well the result
+// matches the query.
+package scoring
+
+import "github.com/fwojciec/bookid"
+
+// Input is what a signal scores: a candidate result together with the query
+// and options that produced it.
+type Input struct {
+	Query   string
+	Options bookid.SearchOptions
+	Result  bookid.BookResult
+}
+
+// Signal scores a single aspect of how likely a result is the book the
+// caller is looking for.
+type Signal interface {
+	// Name identifies the signal, e.g. "title_similarity".
+	Name() string
+
+	// Score returns a score from 0.0 to 1.0. Returns false if the signal
+	// does not apply to the input, e.g. year proximity for a query without
+	// a year, in which case it does not affect confidence.
+	Score(in Input) (float64, bool)
+}
+
+// Weighted pairs a signal with its weight.
+//
+// Weight is the share of confidence the signal can take away: a signal with
+// weight 0.3 scoring 0 scales confidence by 0.7, while a weight of 1 makes
+// confidence proportional to the signal's score.
+type Weighted struct {
+	Signal Signal
+	Weight float64
+}
+
+// Scorer combines weighted signals into a confidence score.
+type Scorer struct {
+	Signals []Weighted
+}
+
+// NewScorer returns a Scorer combining signals.
+func NewScorer(signals ...Weighted) *Scorer {
+	return &Scorer{Signals: signals}
+}
+
+// Default returns a Scorer with every built-in signal. The search type sets
+// the baseline and the remaining signals scale it down.
+func Default() *Scorer {
+	return NewScorer(
+		Weighted{Signal: SearchType{}, Weight: 1},
+		Weighted{Signal: Completeness{}, Weight: 0.3},
+		Weighted{Signal: TitleSimilarity{}, Weight: 0.4},
+		Weighted{Signal: AuthorSimilarity{}, Weight: 0.2},
+		Weighted{Signal: YearProximity{}, Weight: 0.2},
+		Weighted{Signal: LanguageMatch{}, Weight: 0.3},
+	)
+}
+
+// Score returns the confidence for in, from 0.0 to 1.0. Each applicable
+// signal scales the confidence by 1 - weight + weight*score.
+func (s *Scorer) Score(in Input) float64 {
+	confidence := 1.0
+	for _, w := range s.Signals {
+		score, ok := w.Signal.Score(in)
+		if !ok {
+			continue
+		}
+		weight := min(max(w.Weight, 0), 1)
+		confidence *= 1 - weight + weight*min(max(score, 0), 1)
+	}
+	return confidence
+}
+
+// Explain returns the score of each applicable signal keyed by name, for
+// debugging and tuning weights.
+func (s *Scorer) Explain(in Input) map[string]float64 {
+	scores := make(map[string]float64, len(s.Signals))
+	for _, w := range s.Signals {
+		if score, ok := w.Signal.Score(in); ok {
+			scores[w.Signal.Name()] = score
+		}
+	}
+	return scores
+}
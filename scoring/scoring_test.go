@@ -0,0 +1,121 @@
+package scoring_test
+
+import (
+	"testing"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/scoring"
+	"github.com/stretchr/testify/assert"
+)
+
+// gatsby is a complete general search result for The Great Gatsby.
+func gatsby() bookid.BookResult {
+	return bookid.BookResult{
+		Title:         "The Great Gatsby",
+		Authors:       []string{"F. Scott Fitzgerald"},
+		ISBN13:        "9780743273565",
+		Publisher:     "Scribner",
+		PublishedYear: 2004,
+		Language:      "en",
+		SearchType:    bookid.SearchTypeGeneralQuery,
+	}
+}
+
+func TestScorer_Score(t *testing.T) {
+	t.Parallel()
+
+	t.Run("matches legacy scale", func(t *testing.T) {
+		t.Parallel()
+		s := scoring.Default()
+
+		r := gatsby()
+		r.SearchType = bookid.SearchTypeISBN
+		assert.InDelta(t, 0.95, s.Score(scoring.Input{Query: "9780743273565", Result: r}), 0.001)
+
+		r = gatsby()
+		assert.InDelta(t, 0.70, s.Score(scoring.Input{Query: "the great gatsby", Result: r}), 0.001)
+		r.SearchType = bookid.SearchTypeLocal
+		assert.InDelta(t, 0.70, s.Score(scoring.Input{Query: "the great gatsby", Result: r}), 0.001)
+		r.SearchType = bookid.SearchTypeGeneralQuery
+
+		// Missing ISBN and publisher scale confidence by 0.7 + 0.3 * 2/4.
+		r.ISBN13, r.Publisher = "", ""
+		assert.InDelta(t, 0.70*0.85, s.Score(scoring.Input{Query: "the great gatsby", Result: r}), 0.001)
+	})
+
+	t.Run("weights", func(t *testing.T) {
+		t.Parallel()
+		in := scoring.Input{Query: "dune", Result: gatsby()}
+
+		full := scoring.NewScorer(scoring.Weighted{Signal: scoring.TitleSimilarity{}, Weight: 1})
+		assert.InDelta(t, 0, full.Score(in), 0.001)
+
+		partial := scoring.NewScorer(scoring.Weighted{Signal: scoring.TitleSimilarity{}, Weight: 0.25})
+		assert.InDelta(t, 0.75, partial.Score(in), 0.001)
+
+		none := scoring.NewScorer()
+		assert.InDelta(t, 1, none.Score(in), 0.001)
+	})
+
+	t.Run("better matches score higher", func(t *testing.T) {
+		t.Parallel()
+		s := scoring.Default()
+		other := gatsby()
+		other.Title, other.Authors = "Gatsby's Girl", []string{"Caroline Preston"}
+
+		query := "the great gatsby fitzgerald"
+		assert.Greater(t,
+			s.Score(scoring.Input{Query: query, Result: gatsby()}),
+			s.Score(scoring.Input{Query: query, Result: other}),
+		)
+	})
+}
+
+func TestScorer_Explain(t *testing.T) {
+	t.Parallel()
+
+	got := scoring.Default().Explain(scoring.Input{Query: "the great gatsby", Result: gatsby()})
+	assert.Equal(t, map[string]float64{
+		"search_type":      0.70,
+		"completeness":     1,
+		"title_similarity": 1,
+	}, got)
+}
+
+func TestSignals(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		signal scoring.Signal
+		in     scoring.Input
+		score  float64
+		ok     bool
+	}{
+		{"search type isbn", scoring.SearchType{}, scoring.Input{Result: bookid.BookResult{SearchType: bookid.SearchTypeISBN}}, 0.95, true},
+		{"search type unknown", scoring.SearchType{}, scoring.Input{}, 0, false},
+		{"completeness empty", scoring.Completeness{}, scoring.Input{}, 0, true},
+		{"completeness full", scoring.Completeness{}, scoring.Input{Result: gatsby()}, 1, true},
+		{"title partial", scoring.TitleSimilarity{}, scoring.Input{Query: "great", Result: gatsby()}, 0.5, true},
+		{"title typo", scoring.TitleSimilarity{}, scoring.Input{Query: "great gatsbi", Result: gatsby()}, 1, true},
+		{"title empty query", scoring.TitleSimilarity{}, scoring.Input{Result: gatsby()}, 0, false},
+		{"author named", scoring.AuthorSimilarity{}, scoring.Input{Query: "gatsby fitzgerald", Result: gatsby()}, 1, true},
+		{"author wrong", scoring.AuthorSimilarity{}, scoring.Input{Query: "gatsby preston", Result: gatsby()}, 0, true},
+		{"author title only", scoring.AuthorSimilarity{}, scoring.Input{Query: "the great gatsby", Result: gatsby()}, 0, false},
+		{"year exact", scoring.YearProximity{}, scoring.Input{Query: "gatsby 2004", Result: gatsby()}, 1, true},
+		{"year close", scoring.YearProximity{}, scoring.Input{Query: "gatsby 2000", Result: gatsby()}, 0.6, true},
+		{"year far", scoring.YearProximity{}, scoring.Input{Query: "gatsby 1925", Result: gatsby()}, 0, true},
+		{"year none", scoring.YearProximity{}, scoring.Input{Query: "gatsby", Result: gatsby()}, 0, false},
+		{"language match", scoring.LanguageMatch{}, scoring.Input{Options: bookid.SearchOptions{Language: "en-US"}, Result: gatsby()}, 1, true},
+		{"language mismatch", scoring.LanguageMatch{}, scoring.Input{Options: bookid.SearchOptions{Language: "pl"}, Result: gatsby()}, 0, true},
+		{"language unset", scoring.LanguageMatch{}, scoring.Input{Result: gatsby()}, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			score, ok := tt.signal.Score(tt.in)
+			assert.Equal(t, tt.ok, ok)
+			assert.InDelta(t, tt.score, score, 0.001)
+		})
+	}
+}
@@ -0,0 +1,173 @@
+package scoring
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/language"
+	"github.com/fwojciec/bookid/match"
+)
+
+// yearTolerance is the distance in years at which YearProximity scores 0.
+const yearTolerance = 10
+
+// SearchType scores results by how precisely the query identified the book.
+// ISBN lookups are near-certain while free-text searches are not.
+type SearchType struct{}
+
+// Name implements Signal.
+func (SearchType) Name() string { return "search_type" }
+
+// Score implements Signal.
+func (SearchType) Score(in Input) (float64, bool) {
+	switch in.Result.SearchType {
+	case bookid.SearchTypeISBN, bookid.SearchTypeLCCN, bookid.SearchTypeDOI, bookid.SearchTypeASIN, bookid.SearchTypeProviderID:
+		return 0.95, true
+	case bookid.SearchTypeTitleAuthor:
+		return 0.85, true
+	case bookid.SearchTypeTitle:
+		return 0.80, true
+	case bookid.SearchTypeGeneralQuery, bookid.SearchTypeLocal:
+		return 0.70, true
+	}
+	return 0, false
+}
+
+// Completeness scores results by the share of identifying fields present:
+// title, authors, ISBN and publisher.
+type Completeness struct{}
+
+// Name implements Signal.
+func (Completeness) Name() string { return "completeness" }
+
+// Score implements Signal.
+func (Completeness) Score(in Input) (float64, bool) {
+	r := in.Result
+	present := 0
+	if r.Title != "" {
+		present++
+	}
+	if len(r.Authors) > 0 {
+		present++
+	}
+	if r.ISBN10 != "" || r.ISBN13 != "" {
+		present++
+	}
+	if r.Publisher != "" {
+		present++
+	}
+	return float64(present) / 4, true
+}
+
+// TitleSimilarity scores results by how much of the title appears in the
+// query. It does not apply to identifier searches.
+type TitleSimilarity struct{}
+
+// Name implements Signal.
+func (TitleSimilarity) Name() string { return "title_similarity" }
+
+// Score implements Signal.
+func (TitleSimilarity) Score(in Input) (float64, bool) {
+	if isIdentifierSearch(in.Result.SearchType) || in.Result.Title == "" {
+		return 0, false
+	}
+	q := match.Tokens(in.Query)
+	if len(q) == 0 {
+		return 0, false
+	}
+	return match.Coverage(match.Tokens(in.Result.Title), q), true
+}
+
+// AuthorSimilarity scores results by whether the query names one of the
+// authors. It does not apply to identifier searches or to queries fully
+// explained by the title, so title-only queries aren't penalized.
+type AuthorSimilarity struct{}
+
+// Name implements Signal.
+func (AuthorSimilarity) Name() string { return "author_similarity" }
+
+// Score implements Signal.
+func (AuthorSimilarity) Score(in Input) (float64, bool) {
+	if isIdentifierSearch(in.Result.SearchType) || len(in.Result.Authors) == 0 {
+		return 0, false
+	}
+	q := match.Tokens(in.Query)
+	if len(q) == 0 || match.Coverage(q, match.Tokens(in.Result.Title)) == 1 {
+		return 0, false
+	}
+	for _, author := range in.Result.Authors {
+		if match.NamesAuthor(q, match.Tokens(author)) {
+			return 1, true
+		}
+	}
+	return 0, true
+}
+
+// isIdentifierSearch reports whether results were looked up by an identifier
+// such as an ISBN, in which case the query text says nothing about the title.
+func isIdentifierSearch(t bookid.SearchType) bool {
+	switch t {
+	case bookid.SearchTypeISBN, bookid.SearchTypeLCCN, bookid.SearchTypeDOI, bookid.SearchTypeASIN, bookid.SearchTypeProviderID:
+		return true
+	}
+	return false
+}
+
+// YearProximity scores results by how close their publication year is to a
+// year mentioned in the query, e.g. "dune 1965". It applies only when both
+// years are known.
+type YearProximity struct{}
+
+// Name implements Signal.
+func (YearProximity) Name() string { return "year_proximity" }
+
+// Score implements Signal.
+func (YearProximity) Score(in Input) (float64, bool) {
+	year := queryYear(in.Query)
+	if year == 0 || in.Result.PublishedYear == 0 {
+		return 0, false
+	}
+	diff := math.Abs(float64(year - in.Result.PublishedYear))
+	return max(0, 1-diff/yearTolerance), true
+}
+
+// queryYear returns the last plausible publication year in query, or zero.
+func queryYear(query string) int {
+	var year int
+	for _, w := range strings.Fields(match.Normalize(query)) {
+		if len(w) != 4 {
+			continue
+		}
+		if n, err := strconv.Atoi(w); err == nil && n >= 1400 && n <= 2999 {
+			year = n
+		}
+	}
+	return year
+}
+
+// LanguageMatch scores results by whether their language matches the one
+// requested in the search options. It applies only when both are known.
+type LanguageMatch struct{}
+
+// Name implements Signal.
+func (LanguageMatch) Name() string { return "language_match" }
+
+// Score implements Signal.
+func (LanguageMatch) Score(in Input) (float64, bool) {
+	want, have := primaryLanguage(in.Options.Language), primaryLanguage(in.Result.Language)
+	if want == "" || have == "" {
+		return 0, false
+	}
+	if want == have {
+		return 1, true
+	}
+	return 0, true
+}
+
+// primaryLanguage returns the lowercased primary subtag of a language tag,
+// e.g. "en" for "en-US".
+func primaryLanguage(tag string) string {
+	return language.Base(tag)
+}
@@ -0,0 +1,102 @@
+package bookid
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// Series represents a sequence of works published under a common name, e.g.
+// "Discworld" or "The Witcher".
+type Series struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Validate returns an error if the series contains invalid fields.
+func (s *Series) Validate() error {
+	if strings.TrimSpace(s.Name) == "" {
+		return Errorf(EINVALID, "Series name required.")
+	}
+	return nil
+}
+
+// SeriesWork links a work to a series it belongs to.
+type SeriesWork struct {
+	SeriesID int64 `json:"series_id"`
+	WorkID   int64 `json:"work_id"`
+
+	// Position of the work in the series, e.g. 1 or 2.5 for a novella
+	// published between the second and third volumes. Zero if unknown.
+	Volume float64 `json:"volume,omitempty"`
+}
+
+// Validate returns an error if the link contains invalid fields.
+func (sw *SeriesWork) Validate() error {
+	if sw.SeriesID == 0 || sw.WorkID == 0 {
+		return Errorf(EINVALID, "Series and work required.")
+	} else if sw.Volume < 0 {
+		return Errorf(EINVALID, "Invalid volume number: %v.", sw.Volume)
+	}
+	return nil
+}
+
+// SeriesService represents a service for managing series and the works in
+// them.
+type SeriesService interface {
+	// FindSeriesByID retrieves a single series by ID.
+	// Returns ENOTFOUND if the series does not exist.
+	FindSeriesByID(ctx context.Context, id int64) (*Series, error)
+
+	// FindSeries retrieves a list of series matching the filter along with
+	// the total number of matches, ignoring Offset and Limit.
+	FindSeries(ctx context.Context, filter SeriesFilter) ([]*Series, int, error)
+
+	// CreateSeries creates a new series. If a series with the same name,
+	// ignoring case, already exists, series is populated from it instead.
+	CreateSeries(ctx context.Context, series *Series) error
+
+	// DeleteSeries permanently removes a series and its work links.
+	// Returns ENOTFOUND if the series does not exist.
+	DeleteSeries(ctx context.Context, id int64) error
+
+	// AddSeriesWork links a work to a series. Linking an already linked work
+	// updates its volume number. Returns ENOTFOUND if the series or work
+	// does not exist.
+	AddSeriesWork(ctx context.Context, sw *SeriesWork) error
+
+	// RemoveSeriesWork unlinks a work from a series.
+	// Returns ENOTFOUND if the link does not exist.
+	RemoveSeriesWork(ctx context.Context, seriesID, workID int64) error
+
+	// FindSeriesWorks retrieves the links matching the filter along with the
+	// total number of matches, ignoring Offset and Limit. Links are ordered
+	// by volume number, with works of unknown position last.
+	FindSeriesWorks(ctx context.Context, filter SeriesWorkFilter) ([]*SeriesWork, int, error)
+}
+
+// SeriesFilter represents a filter used by FindSeries.
+type SeriesFilter struct {
+	ID *int64
+
+	// Name matches series by name, ignoring case.
+	Name *string
+
+	// WorkID restricts results to the series a work belongs to.
+	WorkID *int64
+
+	// Restrict to subset of results.
+	Offset int
+	Limit  int
+}
+
+// SeriesWorkFilter represents a filter used by FindSeriesWorks.
+type SeriesWorkFilter struct {
+	SeriesID *int64
+	WorkID   *int64
+
+	// Restrict to subset of results.
+	Offset int
+	Limit  int
+}
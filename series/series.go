@@ -0,0 +1,81 @@
+// Package series parses the free-form series statements of bibliographic
+// records, such as "Discworld ; 1" or "The Witcher (Book 2)", into a series
+// name and a volume number.
+package series
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Parse returns the name of the series and the volume number in a series
+// statement. The volume is zero if the statement does not number the work,
+// e.g. for "Penguin Modern Classics".
+func Parse(statement string) (name string, volume float64) {
+	s := strings.TrimSpace(statement)
+	if strings.HasPrefix(s, "(") && strings.HasSuffix(s, ")") && strings.Count(s, "(") == 1 {
+		s = s[1 : len(s)-1]
+	}
+
+	// "The Witcher (Book 2)"
+	if i := strings.LastIndex(s, "("); i > 0 && strings.HasSuffix(s, ")") {
+		if v := ParseVolume(s[i+1 : len(s)-1]); v != 0 {
+			return Name(s[:i]), v
+		}
+	}
+	// "Discworld ; 1", "Harry Potter -- 3", "Dune #2" and "Dune, book 2"
+	for _, sep := range []string{";", " -- ", "#", ","} {
+		if i := strings.LastIndex(s, sep); i > 0 {
+			if v := ParseVolume(s[i+len(sep):]); v != 0 {
+				return Name(s[:i]), v
+			}
+		}
+	}
+	// "Discworld book 1"
+	if fields := strings.Fields(s); len(fields) > 2 && isLabel(fields[len(fields)-2]) {
+		if v := ParseVolume(strings.Join(fields[len(fields)-2:], " ")); v != 0 {
+			return Name(strings.Join(fields[:len(fields)-2], " ")), v
+		}
+	}
+	return Name(s), 0
+}
+
+// ParseVolume returns the number in a volume designation such as "3", "v. 3",
+// "Book 3" or "#2.5", or zero if it is not a positive number.
+func ParseVolume(s string) float64 {
+	s = strings.ToLower(strings.TrimSpace(s))
+	s = strings.TrimPrefix(s, "#")
+	if fields := strings.Fields(s); len(fields) == 2 && isLabel(fields[0]) {
+		s = fields[1]
+	} else if i := strings.Index(s, "."); i > 0 && isLabel(s[:i+1]) {
+		s = s[i+1:] // "v.3"
+	}
+	s = strings.TrimRight(strings.TrimPrefix(s, "#"), ".")
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil || v <= 0 {
+		return 0
+	}
+	return v
+}
+
+// Name returns the display form of a series name: surrounding whitespace,
+// ISBD punctuation and a trailing "series" are removed, so "Discworld
+// series ;" becomes "Discworld".
+func Name(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	s = strings.TrimRight(s, " ,;:./-")
+	if i := len(s) - len(" series"); i > 0 && strings.EqualFold(s[i:], " series") {
+		s = s[:i]
+	}
+	return strings.TrimRight(s, " ,;:./-")
+}
+
+// isLabel reports whether s labels a volume number, e.g. "vol." or "book".
+func isLabel(s string) bool {
+	switch strings.ToLower(s) {
+	case "v.", "vol", "vol.", "volume", "no", "no.", "nr", "nr.", "number",
+		"bk", "bk.", "book", "pt", "pt.", "part", "t.", "tome", "tom", "bd.", "band":
+		return true
+	}
+	return false
+}
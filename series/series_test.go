@@ -0,0 +1,66 @@
+package series_test
+
+import (
+	"testing"
+
+	"github.com/fwojciec/bookid/series"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		statement string
+		name      string
+		volume    float64
+	}{
+		{"Discworld ; 1", "Discworld", 1},
+		{"Discworld series ; v. 12", "Discworld", 12},
+		{"(Discworld ; 3)", "Discworld", 3},
+		{"The Witcher (Book 2)", "The Witcher", 2},
+		{"Harry Potter -- 3", "Harry Potter", 3},
+		{"Dune #2", "Dune", 2},
+		{"The Expanse, #2.5", "The Expanse", 2.5},
+		{"Dune, book 2", "Dune", 2},
+		{"Discworld book 4", "Discworld", 4},
+		{"Penguin Modern Classics", "Penguin Modern Classics", 0},
+		{"Penguin classics ;", "Penguin classics", 0},
+		{"Catch-22", "Catch-22", 0},
+		{"", "", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.statement, func(t *testing.T) {
+			t.Parallel()
+			name, volume := series.Parse(tt.statement)
+			assert.Equal(t, tt.name, name)
+			assert.InDelta(t, tt.volume, volume, 0)
+		})
+	}
+}
+
+func TestParseVolume(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		in   string
+		want float64
+	}{
+		{"3", 3},
+		{"v. 3", 3},
+		{"v.3", 3},
+		{"Vol. 3.", 3},
+		{"no. 12", 12},
+		{"Book 1", 1},
+		{"#2.5", 2.5},
+		{"0", 0},
+		{"III", 0},
+		{"", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			t.Parallel()
+			assert.InDelta(t, tt.want, series.ParseVolume(tt.in), 0)
+		})
+	}
+}
@@ -0,0 +1,194 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"reflect"
+	"strings"
+
+	"github.com/fwojciec/bookid"
+)
+
+// Ensure service implements interface.
+var _ bookid.AuditService = (*AuditService)(nil)
+
+// AuditService represents a service for reviewing the audit log.
+type AuditService struct {
+	db *DB
+}
+
+// NewAuditService returns a new instance of AuditService.
+func NewAuditService(db *DB) *AuditService {
+	return &AuditService{db: db}
+}
+
+// FindAuditEntries retrieves audit entries matching the filter.
+func (s *AuditService) FindAuditEntries(ctx context.Context, filter bookid.AuditFilter) ([]*bookid.AuditEntry, int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer func() { _ = tx.Rollback() }()
+	return findAuditEntries(ctx, tx, filter)
+}
+
+// findAuditEntries returns audit entries matching the filter, oldest first.
+func findAuditEntries(ctx context.Context, tx *Tx, filter bookid.AuditFilter) (_ []*bookid.AuditEntry, n int, err error) {
+	where, args := []string{"1 = 1"}, []any{}
+	if v := filter.EntityType; v != nil {
+		where, args = append(where, "entity_type = ?"), append(args, *v)
+	}
+	if v := filter.EntityID; v != nil {
+		where, args = append(where, "entity_id = ?"), append(args, *v)
+	}
+	if v := filter.WorkID; v != nil {
+		where, args = append(where, "work_id = ?"), append(args, *v)
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id, entity_type, entity_id, work_id, action, actor, diff, created_at, COUNT(*) OVER ()
+		FROM audit_log
+		WHERE `+strings.Join(where, " AND ")+`
+		ORDER BY id ASC
+		`+FormatLimitOffset(filter.Limit, filter.Offset),
+		args...,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	entries := make([]*bookid.AuditEntry, 0)
+	for rows.Next() {
+		var entry bookid.AuditEntry
+		var workID sql.NullInt64
+		var diff string
+		if err := rows.Scan(
+			&entry.ID,
+			&entry.EntityType,
+			&entry.EntityID,
+			&workID,
+			&entry.Action,
+			&entry.Actor,
+			&diff,
+			(*NullTime)(&entry.CreatedAt),
+			&n,
+		); err != nil {
+			return nil, 0, err
+		}
+		entry.WorkID = workID.Int64
+		if err := json.Unmarshal([]byte(diff), &entry.Diff); err != nil {
+			return nil, 0, err
+		}
+		entries = append(entries, &entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+	return entries, n, nil
+}
+
+// audit records a change to an entity in the audit log, attributed to the
+// actor of ctx. The diff lists the fields that differ between the JSON
+// encodings of before and after, either of which is nil for created and
+// deleted entities. IDs and timestamps are left out, and updates changing
+// nothing else are not recorded.
+func audit(ctx context.Context, tx *Tx, entityType string, entityID, workID int64, action string, before, after any) error {
+	diff, err := diffFields(before, after)
+	if err != nil {
+		return err
+	} else if len(diff) == 0 && action == bookid.AuditActionUpdate {
+		return nil // nothing changed
+	}
+	return insertAuditEntry(ctx, tx, &bookid.AuditEntry{
+		EntityType: entityType,
+		EntityID:   entityID,
+		WorkID:     workID,
+		Action:     action,
+		Diff:       diff,
+	})
+}
+
+// insertAuditEntry inserts an audit entry attributed to the actor of ctx.
+// Sets the ID, actor and timestamp on success.
+func insertAuditEntry(ctx context.Context, tx *Tx, entry *bookid.AuditEntry) error {
+	entry.Actor = bookid.ActorFromContext(ctx)
+	entry.CreatedAt = tx.now
+	if entry.Diff == nil {
+		entry.Diff = map[string]bookid.AuditChange{}
+	}
+
+	diff, err := json.Marshal(entry.Diff)
+	if err != nil {
+		return err
+	}
+	workID := sql.NullInt64{Int64: entry.WorkID, Valid: entry.WorkID != 0}
+
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO audit_log (entity_type, entity_id, work_id, action, actor, diff, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`,
+		entry.EntityType,
+		entry.EntityID,
+		workID,
+		entry.Action,
+		entry.Actor,
+		string(diff),
+		(*NullTime)(&entry.CreatedAt),
+	)
+	if err != nil {
+		return FormatError(err)
+	}
+
+	if entry.ID, err = result.LastInsertId(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// diffFields returns the fields whose JSON values differ between before and
+// after, ignoring IDs, UIDs and timestamps.
+func diffFields(before, after any) (map[string]bookid.AuditChange, error) {
+	old, err := jsonFields(before)
+	if err != nil {
+		return nil, err
+	}
+	updated, err := jsonFields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := make(map[string]bookid.AuditChange)
+	for name, v := range old {
+		if w := updated[name]; !reflect.DeepEqual(v, w) {
+			diff[name] = bookid.AuditChange{Old: v, New: w}
+		}
+	}
+	for name, w := range updated {
+		if _, ok := old[name]; !ok {
+			diff[name] = bookid.AuditChange{New: w}
+		}
+	}
+	for _, name := range []string{"id", "uid", "created_at", "updated_at", "refreshed_at"} {
+		delete(diff, name)
+	}
+	return diff, nil
+}
+
+// jsonFields returns the fields of the JSON encoding of v, or none if v is
+// nil.
+func jsonFields(v any) (map[string]any, error) {
+	fields := make(map[string]any)
+	if rv := reflect.ValueOf(v); v == nil || (rv.Kind() == reflect.Pointer && rv.IsNil()) {
+		return fields, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
@@ -0,0 +1,99 @@
+package sqlite_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/sqlite"
+)
+
+func TestAuditService_FindAuditEntries(t *testing.T) {
+	t.Parallel()
+
+	t.Run("WorkHistory", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		ctx := bookid.NewContextWithActor(context.Background(), "alice")
+
+		work := MustCreateWork(t, ctx, db, &bookid.Work{Title: "Dune", Author: "Frank Herbert"})
+		other := MustCreateWork(t, ctx, db, &bookid.Work{Title: "Dune (Special Edition)", Author: "Frank Herbert"})
+		pub := MustCreatePublication(t, ctx, db, &bookid.Publication{WorkID: other.ID, ISBN13: "9780441172719"})
+
+		title := "Dune (1965)"
+		if _, err := sqlite.NewWorkService(db).UpdateWork(ctx, work.ID, bookid.WorkUpdate{Title: &title}); err != nil {
+			t.Fatal(err)
+		}
+		// Updates changing nothing are not recorded.
+		if _, err := sqlite.NewWorkService(db).UpdateWork(ctx, work.ID, bookid.WorkUpdate{Title: &title}); err != nil {
+			t.Fatal(err)
+		}
+		if err := sqlite.NewWorkService(db).MergeWorks(ctx, work.ID, other.ID); err != nil {
+			t.Fatal(err)
+		}
+
+		entries, n, err := sqlite.NewAuditService(db).FindAuditEntries(ctx, bookid.AuditFilter{WorkID: &work.ID})
+		if err != nil {
+			t.Fatal(err)
+		} else if got, want := n, 3; got != want {
+			t.Fatalf("n=%d, want %d", got, want)
+		}
+
+		if e := entries[0]; e.Action != bookid.AuditActionCreate || e.EntityType != bookid.AuditEntityWork || e.EntityID != work.ID {
+			t.Fatalf("unexpected entry: %#v", e)
+		} else if got, want := e.Diff["title"], (bookid.AuditChange{New: "Dune"}); !reflect.DeepEqual(got, want) {
+			t.Fatalf("Diff[title]=%#v, want %#v", got, want)
+		} else if _, ok := e.Diff["id"]; ok {
+			t.Fatal("unexpected id in diff")
+		} else if got, want := e.Actor, "alice"; got != want {
+			t.Fatalf("Actor=%q, want %q", got, want)
+		} else if e.CreatedAt.IsZero() {
+			t.Fatal("expected created at")
+		}
+
+		if e := entries[1]; e.Action != bookid.AuditActionUpdate {
+			t.Fatalf("Action=%q, want %q", e.Action, bookid.AuditActionUpdate)
+		} else if want := map[string]bookid.AuditChange{"title": {Old: "Dune", New: "Dune (1965)"}}; !reflect.DeepEqual(e.Diff, want) {
+			t.Fatalf("Diff=%#v, want %#v", e.Diff, want)
+		}
+
+		if e := entries[2]; e.Action != bookid.AuditActionMerge || e.EntityID != other.ID {
+			t.Fatalf("unexpected entry: %#v", e)
+		} else if got, want := e.Diff["merged_into"].New, float64(work.ID); got != want {
+			t.Fatalf("Diff[merged_into]=%v, want %v", got, want)
+		}
+
+		// The publication's history stays with the work it was created in.
+		entityType := bookid.AuditEntityPublication
+		if entries, _, err := sqlite.NewAuditService(db).FindAuditEntries(ctx, bookid.AuditFilter{EntityType: &entityType, EntityID: &pub.ID}); err != nil {
+			t.Fatal(err)
+		} else if len(entries) != 1 || entries[0].WorkID != other.ID {
+			t.Fatalf("unexpected entries: %#v", entries)
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		ctx := context.Background()
+
+		work := MustCreateWork(t, ctx, db, &bookid.Work{Title: "Solaris", Author: "Stanisław Lem"})
+		if err := sqlite.NewWorkService(db).DeleteWork(ctx, work.ID); err != nil {
+			t.Fatal(err)
+		}
+
+		entries, _, err := sqlite.NewAuditService(db).FindAuditEntries(ctx, bookid.AuditFilter{WorkID: &work.ID})
+		if err != nil {
+			t.Fatal(err)
+		} else if len(entries) != 2 {
+			t.Fatalf("len=%d, want 2", len(entries))
+		} else if e := entries[1]; e.Action != bookid.AuditActionDelete || e.Actor != "" {
+			t.Fatalf("unexpected entry: %#v", e)
+		} else if got, want := e.Diff["title"], (bookid.AuditChange{Old: "Solaris"}); !reflect.DeepEqual(got, want) {
+			t.Fatalf("Diff[title]=%#v, want %#v", got, want)
+		}
+	})
+}
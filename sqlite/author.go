@@ -0,0 +1,440 @@
+package sqlite
+
+import (
+	"context"
+	"strings"
+	"unicode"
+
+	"github.com/fwojciec/bookid"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Ensure service implements interface.
+var _ bookid.AuthorService = (*AuthorService)(nil)
+
+// AuthorService represents a service for managing authors.
+type AuthorService struct {
+	db *DB
+}
+
+// NewAuthorService returns a new instance of AuthorService.
+func NewAuthorService(db *DB) *AuthorService {
+	return &AuthorService{db: db}
+}
+
+// FindAuthorByID retrieves a single author by ID.
+// Returns ENOTFOUND if the author does not exist.
+func (s *AuthorService) FindAuthorByID(ctx context.Context, id int64) (*bookid.Author, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+	return findAuthorByID(ctx, tx, id)
+}
+
+// FindAuthors retrieves a list of authors matching the filter.
+func (s *AuthorService) FindAuthors(ctx context.Context, filter bookid.AuthorFilter) ([]*bookid.Author, int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer func() { _ = tx.Rollback() }()
+	return findAuthors(ctx, tx, filter)
+}
+
+// CreateAuthor creates a new author, or populates author from the existing
+// row it resolves to by authority identifiers or normalized name.
+func (s *AuthorService) CreateAuthor(ctx context.Context, author *bookid.Author) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := createAuthor(ctx, tx, author); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// UpdateAuthor updates an existing author.
+// Returns ENOTFOUND if the author does not exist.
+func (s *AuthorService) UpdateAuthor(ctx context.Context, id int64, upd bookid.AuthorUpdate) (*bookid.Author, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	author, err := updateAuthor(ctx, tx, id, upd)
+	if err != nil {
+		return author, err
+	} else if err := tx.Commit(); err != nil {
+		return author, err
+	}
+	return author, nil
+}
+
+// DeleteAuthor permanently removes an author and their work links.
+// Returns ENOTFOUND if the author does not exist.
+func (s *AuthorService) DeleteAuthor(ctx context.Context, id int64) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := deleteAuthor(ctx, tx, id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// AddWorkAuthor links an author to a work.
+func (s *AuthorService) AddWorkAuthor(ctx context.Context, wa *bookid.WorkAuthor) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := addWorkAuthor(ctx, tx, wa); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// RemoveWorkAuthor unlinks an author from a work.
+// Returns ENOTFOUND if the link does not exist.
+func (s *AuthorService) RemoveWorkAuthor(ctx context.Context, wa *bookid.WorkAuthor) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := removeWorkAuthor(ctx, tx, wa); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// findAuthorByID is a helper function to fetch an author by ID.
+// Returns ENOTFOUND if the author does not exist.
+func findAuthorByID(ctx context.Context, tx *Tx, id int64) (*bookid.Author, error) {
+	authors, _, err := findAuthors(ctx, tx, bookid.AuthorFilter{ID: &id})
+	if err != nil {
+		return nil, err
+	} else if len(authors) == 0 {
+		return nil, bookid.Errorf(bookid.ENOTFOUND, "Author not found.")
+	}
+	return authors[0], nil
+}
+
+// findAuthors returns a list of authors matching a filter. Also returns a
+// count of total matching authors which may differ if filter.Limit is set.
+//
+// Authors of a work are returned in the order they were linked.
+func findAuthors(ctx context.Context, tx *Tx, filter bookid.AuthorFilter) (_ []*bookid.Author, n int, err error) {
+	from, orderBy := "authors a", "a.id ASC"
+	where, args := []string{"1 = 1"}, []any{}
+	if v := filter.ID; v != nil {
+		where, args = append(where, "a.id = ?"), append(args, *v)
+	}
+	if v := filter.Name; v != nil {
+		where, args = append(where, "a.name_key = ?"), append(args, authorNameKey(*v))
+	}
+	if v := filter.VIAFID; v != nil {
+		where, args = append(where, "a.viaf_id = ?"), append(args, *v)
+	}
+	if v := filter.WikidataID; v != nil {
+		where, args = append(where, "a.wikidata_id = ?"), append(args, *v)
+	}
+	role := "''"
+	if v := filter.WorkID; v != nil {
+		from, orderBy, role = "authors a JOIN work_authors wa ON wa.author_id = a.id", "wa.rowid ASC", "wa.role"
+		where, args = append(where, "wa.work_id = ?"), append(args, *v)
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT a.id, a.name, a.viaf_id, a.wikidata_id, `+role+`, COUNT(*) OVER ()
+		FROM `+from+`
+		WHERE `+strings.Join(where, " AND ")+`
+		ORDER BY `+orderBy+`
+		`+FormatLimitOffset(filter.Limit, filter.Offset),
+		args...,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	authors := make([]*bookid.Author, 0)
+	for rows.Next() {
+		var author bookid.Author
+		if err := rows.Scan(&author.ID, &author.Name, &author.VIAFID, &author.WikidataID, &author.Role, &n); err != nil {
+			return nil, 0, err
+		}
+		authors = append(authors, &author)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+	return authors, n, nil
+}
+
+// createAuthor normalizes the author's name and inserts a new author unless
+// it resolves to an existing one, in which case author is populated from the
+// existing row.
+func createAuthor(ctx context.Context, tx *Tx, author *bookid.Author) error {
+	author.Name = normalizeAuthorName(author.Name)
+	author.WikidataID = strings.ToUpper(author.WikidataID)
+	if err := author.Validate(); err != nil {
+		return err
+	}
+
+	if existing, err := findExistingAuthor(ctx, tx, author); err != nil {
+		return err
+	} else if existing != nil {
+		*author = *existing
+		return nil
+	}
+
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO authors (name, name_key, viaf_id, wikidata_id)
+		VALUES (?, ?, ?, ?)
+	`,
+		author.Name,
+		authorNameKey(author.Name),
+		author.VIAFID,
+		author.WikidataID,
+	)
+	if err != nil {
+		return FormatError(err)
+	}
+
+	if author.ID, err = result.LastInsertId(); err != nil {
+		return err
+	}
+	return audit(ctx, tx, bookid.AuditEntityAuthor, author.ID, 0, bookid.AuditActionCreate, nil, author)
+}
+
+// findExistingAuthor returns the author that author resolves to, or nil if
+// there is none. An author with authority identifiers resolves only by those
+// identifiers, since namesakes are different people. An author without them
+// resolves to the unlinked author of the same name or, failing that, to the
+// only linked one.
+func findExistingAuthor(ctx context.Context, tx *Tx, author *bookid.Author) (*bookid.Author, error) {
+	if author.VIAFID != "" || author.WikidataID != "" {
+		var filters []bookid.AuthorFilter
+		if author.VIAFID != "" {
+			filters = append(filters, bookid.AuthorFilter{VIAFID: &author.VIAFID})
+		}
+		if author.WikidataID != "" {
+			filters = append(filters, bookid.AuthorFilter{WikidataID: &author.WikidataID})
+		}
+		for _, filter := range filters {
+			if authors, _, err := findAuthors(ctx, tx, filter); err != nil {
+				return nil, err
+			} else if len(authors) > 0 {
+				return authors[0], nil
+			}
+		}
+		return nil, nil
+	}
+
+	authors, _, err := findAuthors(ctx, tx, bookid.AuthorFilter{Name: &author.Name})
+	if err != nil {
+		return nil, err
+	}
+	var linked []*bookid.Author
+	for _, a := range authors {
+		if a.VIAFID == "" && a.WikidataID == "" {
+			return a, nil
+		}
+		linked = append(linked, a)
+	}
+	if len(linked) == 1 {
+		return linked[0], nil
+	}
+	return nil, nil
+}
+
+// updateAuthor updates fields on an author by ID. Returns the updated author.
+func updateAuthor(ctx context.Context, tx *Tx, id int64, upd bookid.AuthorUpdate) (*bookid.Author, error) {
+	author, err := findAuthorByID(ctx, tx, id)
+	if err != nil {
+		return author, err
+	}
+	old := *author
+
+	if v := upd.VIAFID; v != nil {
+		author.VIAFID = strings.TrimSpace(*v)
+	}
+	if v := upd.WikidataID; v != nil {
+		author.WikidataID = strings.ToUpper(strings.TrimSpace(*v))
+	}
+
+	if err := author.Validate(); err != nil {
+		return author, err
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE authors
+		SET viaf_id = ?, wikidata_id = ?
+		WHERE id = ?
+	`,
+		author.VIAFID,
+		author.WikidataID,
+		id,
+	); err != nil {
+		return author, FormatError(err)
+	}
+	return author, audit(ctx, tx, bookid.AuditEntityAuthor, id, 0, bookid.AuditActionUpdate, &old, author)
+}
+
+// deleteAuthor permanently removes an author by ID.
+func deleteAuthor(ctx context.Context, tx *Tx, id int64) error {
+	author, err := findAuthorByID(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM authors WHERE id = ?`, id); err != nil {
+		return FormatError(err)
+	}
+	return audit(ctx, tx, bookid.AuditEntityAuthor, id, 0, bookid.AuditActionDelete, author, nil)
+}
+
+// addWorkAuthor links an author to a work, ignoring existing links.
+func addWorkAuthor(ctx context.Context, tx *Tx, wa *bookid.WorkAuthor) error {
+	if wa.Role == "" {
+		wa.Role = bookid.ContributorRoleAuthor
+	} else if !wa.Role.Valid() {
+		return bookid.Errorf(bookid.EINVALID, "Invalid contributor role %q.", wa.Role)
+	}
+	if _, err := findWorkByID(ctx, tx, wa.WorkID); err != nil {
+		return err
+	} else if _, err := findAuthorByID(ctx, tx, wa.AuthorID); err != nil {
+		return err
+	}
+
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO work_authors (work_id, author_id, role)
+		VALUES (?, ?, ?)
+		ON CONFLICT DO NOTHING
+	`,
+		wa.WorkID,
+		wa.AuthorID,
+		wa.Role,
+	)
+	if err != nil {
+		return FormatError(err)
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return nil // already linked
+	}
+	diff := map[string]bookid.AuditChange{"work_id": {New: wa.WorkID}}
+	if wa.Role != bookid.ContributorRoleAuthor {
+		diff["role"] = bookid.AuditChange{New: wa.Role}
+	}
+	return insertAuditEntry(ctx, tx, &bookid.AuditEntry{
+		EntityType: bookid.AuditEntityAuthor,
+		EntityID:   wa.AuthorID,
+		WorkID:     wa.WorkID,
+		Action:     bookid.AuditActionLink,
+		Diff:       diff,
+	})
+}
+
+// removeWorkAuthor unlinks an author from a work.
+func removeWorkAuthor(ctx context.Context, tx *Tx, wa *bookid.WorkAuthor) error {
+	result, err := tx.ExecContext(ctx, `DELETE FROM work_authors WHERE work_id = ? AND author_id = ?`, wa.WorkID, wa.AuthorID)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return bookid.Errorf(bookid.ENOTFOUND, "Work author not found.")
+	}
+	return insertAuditEntry(ctx, tx, &bookid.AuditEntry{
+		EntityType: bookid.AuditEntityAuthor,
+		EntityID:   wa.AuthorID,
+		WorkID:     wa.WorkID,
+		Action:     bookid.AuditActionUnlink,
+		Diff:       map[string]bookid.AuditChange{"work_id": {Old: wa.WorkID}},
+	})
+}
+
+// normalizeAuthorName returns the display form of an author name: surrounding
+// and repeated whitespace is removed and "Last, First" is reordered to
+// "First Last". Names with more than one comma (e.g. "King, Martin Luther,
+// Jr.") or a generational suffix after the comma are left in place.
+func normalizeAuthorName(name string) string {
+	name = strings.Join(strings.Fields(name), " ")
+
+	parts := strings.Split(name, ",")
+	if len(parts) != 2 {
+		return name
+	}
+	last, first := strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+	if last == "" || first == "" || isNameSuffix(first) {
+		return name
+	}
+	return first + " " + last
+}
+
+// isNameSuffix returns true for generational and academic suffixes that
+// commonly follow a comma in "First Last, Suffix" names.
+func isNameSuffix(s string) bool {
+	switch strings.ToLower(strings.TrimSuffix(s, ".")) {
+	case "jr", "sr", "ii", "iii", "iv", "phd", "md":
+		return true
+	}
+	return false
+}
+
+// authorNameKey returns the deduplication key for an author name. The key is
+// case folded, stripped of diacritics and punctuation, and in "First Last"
+// order, so "LEM, Stanisław" and "Stanislaw Lem" share a key.
+func authorNameKey(name string) string {
+	name = normalizeAuthorName(name)
+
+	var b strings.Builder
+	for _, r := range norm.NFKD.String(name) {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+			// Drop combining marks left over from decomposition.
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			b.WriteRune(unicode.ToLower(foldLetter(r)))
+		default:
+			b.WriteRune(' ')
+		}
+	}
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+// foldLetter maps letters that have no Unicode decomposition to their closest
+// ASCII equivalent.
+func foldLetter(r rune) rune {
+	switch r {
+	case 'ł':
+		return 'l'
+	case 'Ł':
+		return 'L'
+	case 'ø':
+		return 'o'
+	case 'Ø':
+		return 'O'
+	case 'đ':
+		return 'd'
+	case 'Đ':
+		return 'D'
+	case 'ß':
+		return 's'
+	}
+	return r
+}
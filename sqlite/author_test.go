@@ -0,0 +1,326 @@
+package sqlite_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/sqlite"
+)
+
+func TestAuthorService_CreateAuthor(t *testing.T) {
+	t.Parallel()
+
+	t.Run("OK", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewAuthorService(db)
+		ctx := context.Background()
+
+		author := &bookid.Author{Name: "  Lem,   Stanisław "}
+		if err := s.CreateAuthor(ctx, author); err != nil {
+			t.Fatal(err)
+		} else if got, want := author.ID, int64(1); got != want {
+			t.Fatalf("ID=%d, want %d", got, want)
+		} else if got, want := author.Name, "Stanisław Lem"; got != want {
+			t.Fatalf("Name=%q, want %q", got, want)
+		}
+	})
+
+	t.Run("Deduplicate", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewAuthorService(db)
+		ctx := context.Background()
+
+		original := MustCreateAuthor(t, ctx, db, &bookid.Author{Name: "Stanisław Lem"})
+		for _, name := range []string{
+			"stanislaw lem",
+			"LEM, Stanislaw",
+			"Stanisław  Lem",
+			"Stanisław-Lem",
+		} {
+			author := &bookid.Author{Name: name}
+			if err := s.CreateAuthor(ctx, author); err != nil {
+				t.Fatal(err)
+			} else if got, want := author.ID, original.ID; got != want {
+				t.Fatalf("%q: ID=%d, want %d", name, got, want)
+			} else if got, want := author.Name, "Stanisław Lem"; got != want {
+				t.Fatalf("%q: Name=%q, want %q", name, got, want)
+			}
+		}
+
+		if _, n, err := s.FindAuthors(ctx, bookid.AuthorFilter{}); err != nil {
+			t.Fatal(err)
+		} else if got, want := n, 1; got != want {
+			t.Fatalf("n=%d, want %d", got, want)
+		}
+	})
+
+	t.Run("KeepSuffix", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		ctx := context.Background()
+
+		author := MustCreateAuthor(t, ctx, db, &bookid.Author{Name: "Martin Luther King, Jr."})
+		if got, want := author.Name, "Martin Luther King, Jr."; got != want {
+			t.Fatalf("Name=%q, want %q", got, want)
+		}
+	})
+
+	t.Run("Namesakes", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewAuthorService(db)
+		ctx := context.Background()
+
+		// Authority records tell two people with the same name apart.
+		unlinked := MustCreateAuthor(t, ctx, db, &bookid.Author{Name: "John Smith"})
+		first := MustCreateAuthor(t, ctx, db, &bookid.Author{Name: "John Smith", VIAFID: "111"})
+		second := MustCreateAuthor(t, ctx, db, &bookid.Author{Name: "Smith, John", WikidataID: "q222"})
+		if first.ID == unlinked.ID || second.ID == unlinked.ID || first.ID == second.ID {
+			t.Fatalf("unexpected ids: %d, %d, %d", unlinked.ID, first.ID, second.ID)
+		} else if got, want := second.WikidataID, "Q222"; got != want {
+			t.Fatalf("WikidataID=%q, want %q", got, want)
+		}
+
+		// Identifiers resolve to the linked author, and a bare name to the
+		// unlinked one.
+		for _, tt := range []struct {
+			author *bookid.Author
+			want   int64
+		}{
+			{&bookid.Author{Name: "J. Smith", VIAFID: "111"}, first.ID},
+			{&bookid.Author{Name: "John Smith", WikidataID: "Q222"}, second.ID},
+			{&bookid.Author{Name: "john smith"}, unlinked.ID},
+		} {
+			if err := s.CreateAuthor(ctx, tt.author); err != nil {
+				t.Fatal(err)
+			} else if got := tt.author.ID; got != tt.want {
+				t.Fatalf("%#v: ID=%d, want %d", tt.author, got, tt.want)
+			}
+		}
+	})
+
+	t.Run("SingleLinkedNamesake", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		ctx := context.Background()
+
+		lem := MustCreateAuthor(t, ctx, db, &bookid.Author{Name: "Stanisław Lem", VIAFID: "49222207"})
+		if got := MustCreateAuthor(t, ctx, db, &bookid.Author{Name: "Lem, Stanisław"}); got.ID != lem.ID {
+			t.Fatalf("ID=%d, want %d", got.ID, lem.ID)
+		}
+	})
+
+	t.Run("ErrInvalidIdentifier", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewAuthorService(db)
+
+		for _, author := range []*bookid.Author{
+			{Name: "John Smith", VIAFID: "viaf/111"},
+			{Name: "John Smith", WikidataID: "P31"},
+		} {
+			if err := s.CreateAuthor(context.Background(), author); bookid.ErrorCode(err) != bookid.EINVALID {
+				t.Fatalf("unexpected error: %#v", err)
+			}
+		}
+	})
+
+	t.Run("ErrNameRequired", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewAuthorService(db)
+
+		if err := s.CreateAuthor(context.Background(), &bookid.Author{Name: " "}); bookid.ErrorCode(err) != bookid.EINVALID {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+}
+
+func TestAuthorService_UpdateAuthor(t *testing.T) {
+	t.Parallel()
+
+	t.Run("OK", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewAuthorService(db)
+		ctx := context.Background()
+
+		lem := MustCreateAuthor(t, ctx, db, &bookid.Author{Name: "Stanisław Lem"})
+		viafID, wikidataID := "49222207", "q44245"
+		if author, err := s.UpdateAuthor(ctx, lem.ID, bookid.AuthorUpdate{VIAFID: &viafID, WikidataID: &wikidataID}); err != nil {
+			t.Fatal(err)
+		} else if got, want := author.WikidataID, "Q44245"; got != want {
+			t.Fatalf("WikidataID=%q, want %q", got, want)
+		}
+
+		if authors, _, err := s.FindAuthors(ctx, bookid.AuthorFilter{VIAFID: &viafID}); err != nil {
+			t.Fatal(err)
+		} else if len(authors) != 1 || authors[0].ID != lem.ID || authors[0].WikidataID != "Q44245" {
+			t.Fatalf("unexpected authors: %#v", authors)
+		}
+	})
+
+	t.Run("ErrConflict", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewAuthorService(db)
+		ctx := context.Background()
+
+		MustCreateAuthor(t, ctx, db, &bookid.Author{Name: "John Smith", VIAFID: "111"})
+		other := MustCreateAuthor(t, ctx, db, &bookid.Author{Name: "Jon Smith"})
+		viafID := "111"
+		if _, err := s.UpdateAuthor(ctx, other.ID, bookid.AuthorUpdate{VIAFID: &viafID}); bookid.ErrorCode(err) != bookid.ECONFLICT {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+
+	t.Run("ErrNotFound", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewAuthorService(db)
+
+		if _, err := s.UpdateAuthor(context.Background(), 1, bookid.AuthorUpdate{}); bookid.ErrorCode(err) != bookid.ENOTFOUND {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+}
+
+func TestAuthorService_FindAuthors(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Name", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewAuthorService(db)
+		ctx := context.Background()
+
+		MustCreateAuthor(t, ctx, db, &bookid.Author{Name: "Frank Herbert"})
+		lem := MustCreateAuthor(t, ctx, db, &bookid.Author{Name: "Stanisław Lem"})
+
+		name := "lem, stanislaw"
+		if authors, n, err := s.FindAuthors(ctx, bookid.AuthorFilter{Name: &name}); err != nil {
+			t.Fatal(err)
+		} else if got, want := n, 1; got != want {
+			t.Fatalf("n=%d, want %d", got, want)
+		} else if got, want := authors[0].ID, lem.ID; got != want {
+			t.Fatalf("ID=%d, want %d", got, want)
+		}
+	})
+
+	t.Run("ErrNotFound", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewAuthorService(db)
+
+		if _, err := s.FindAuthorByID(context.Background(), 1); bookid.ErrorCode(err) != bookid.ENOTFOUND {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+}
+
+func TestAuthorService_WorkAuthors(t *testing.T) {
+	t.Parallel()
+
+	t.Run("OK", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewAuthorService(db)
+		ctx := context.Background()
+
+		work := MustCreateWork(t, ctx, db, &bookid.Work{Title: "The Go Programming Language"})
+		kernighan := MustCreateAuthor(t, ctx, db, &bookid.Author{Name: "Brian W. Kernighan"})
+		donovan := MustCreateAuthor(t, ctx, db, &bookid.Author{Name: "Alan A. A. Donovan"})
+
+		// Link in credit order, including a duplicate link.
+		for _, wa := range []*bookid.WorkAuthor{
+			{WorkID: work.ID, AuthorID: donovan.ID},
+			{WorkID: work.ID, AuthorID: kernighan.ID},
+			{WorkID: work.ID, AuthorID: donovan.ID},
+		} {
+			if err := s.AddWorkAuthor(ctx, wa); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		authors, n, err := s.FindAuthors(ctx, bookid.AuthorFilter{WorkID: &work.ID})
+		if err != nil {
+			t.Fatal(err)
+		} else if got, want := n, 2; got != want {
+			t.Fatalf("n=%d, want %d", got, want)
+		} else if got, want := authors[0].Name, "Alan A. A. Donovan"; got != want {
+			t.Fatalf("Name=%q, want %q", got, want)
+		}
+
+		if err := s.RemoveWorkAuthor(ctx, &bookid.WorkAuthor{WorkID: work.ID, AuthorID: donovan.ID}); err != nil {
+			t.Fatal(err)
+		} else if err := s.RemoveWorkAuthor(ctx, &bookid.WorkAuthor{WorkID: work.ID, AuthorID: donovan.ID}); bookid.ErrorCode(err) != bookid.ENOTFOUND {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		if authors, _, err := s.FindAuthors(ctx, bookid.AuthorFilter{WorkID: &work.ID}); err != nil {
+			t.Fatal(err)
+		} else if got, want := len(authors), 1; got != want {
+			t.Fatalf("len=%d, want %d", got, want)
+		}
+	})
+
+	t.Run("DeleteAuthorRemovesLinks", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewAuthorService(db)
+		ctx := context.Background()
+
+		work := MustCreateWork(t, ctx, db, &bookid.Work{Title: "Dune"})
+		author := MustCreateAuthor(t, ctx, db, &bookid.Author{Name: "Frank Herbert"})
+		if err := s.AddWorkAuthor(ctx, &bookid.WorkAuthor{WorkID: work.ID, AuthorID: author.ID}); err != nil {
+			t.Fatal(err)
+		} else if err := s.DeleteAuthor(ctx, author.ID); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, n, err := s.FindAuthors(ctx, bookid.AuthorFilter{WorkID: &work.ID}); err != nil {
+			t.Fatal(err)
+		} else if n != 0 {
+			t.Fatalf("n=%d, want 0", n)
+		}
+	})
+
+	t.Run("ErrWorkNotFound", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewAuthorService(db)
+		ctx := context.Background()
+
+		author := MustCreateAuthor(t, ctx, db, &bookid.Author{Name: "Frank Herbert"})
+		if err := s.AddWorkAuthor(ctx, &bookid.WorkAuthor{WorkID: 100, AuthorID: author.ID}); bookid.ErrorCode(err) != bookid.ENOTFOUND {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+}
+
+// MustCreateAuthor creates an author in the database. Fatal on error.
+func MustCreateAuthor(tb testing.TB, ctx context.Context, db *sqlite.DB, author *bookid.Author) *bookid.Author {
+	tb.Helper()
+	if err := sqlite.NewAuthorService(db).CreateAuthor(ctx, author); err != nil {
+		tb.Fatal(err)
+	}
+	return author
+}
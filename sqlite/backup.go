@@ -0,0 +1,162 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fwojciec/bookid"
+	"github.com/mattn/go-sqlite3"
+)
+
+// backupStepPages is the number of pages copied at a time by the online
+// backup API. Between steps the source can be written to and ctx is checked.
+const backupStepPages = 256
+
+// Backup writes a consistent copy of the database to a new file at path with
+// the SQLite online backup API, so the catalog can be snapshotted while in
+// use. The copy is written next to path and renamed over it once complete,
+// so an existing file at path is replaced only by a whole backup.
+func (db *DB) Backup(ctx context.Context, path string) (err error) {
+	if path == "" {
+		return bookid.Errorf(bookid.EINVALID, "Backup path required.")
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	defer func() {
+		if err != nil {
+			_ = os.Remove(tmp)
+		}
+	}()
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	dst, err := sql.Open(driverName, tmp)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = dst.Close() }()
+
+	// The backup leaves WAL mode so that it is a single self-contained file.
+	if err := copyDatabase(ctx, dst, db.db); err != nil {
+		return fmt.Errorf("backup: %w", err)
+	} else if _, err := dst.ExecContext(ctx, `PRAGMA journal_mode = delete;`); err != nil {
+		return fmt.Errorf("disable wal: %w", err)
+	} else if err := dst.Close(); err != nil {
+		return err
+	}
+	db.Logger.DebugContext(ctx, "backed up database", "path", path)
+	return os.Rename(tmp, path)
+}
+
+// Restore replaces the contents of the database with the backup at path and
+// brings its schema up to date. The backup is checked for corruption first,
+// leaving the database untouched if it fails, and the database afterwards.
+// Returns EINVALID if path is not a sound catalog database.
+func (db *DB) Restore(ctx context.Context, path string) error {
+	if path == "" {
+		return bookid.Errorf(bookid.EINVALID, "Backup path required.")
+	} else if _, err := os.Stat(path); err != nil {
+		return err
+	}
+
+	src, err := sql.Open(driverName, "file:"+path+"?mode=ro")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = src.Close() }()
+
+	var n int
+	var sqliteErr sqlite3.Error
+	if err := src.QueryRowContext(ctx, `SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'migrations'`).Scan(&n); errors.As(err, &sqliteErr) && sqliteErr.Code == sqlite3.ErrNotADB {
+		return bookid.Errorf(bookid.EINVALID, "Backup is not a SQLite database.")
+	} else if err != nil {
+		return err
+	} else if n == 0 {
+		return bookid.Errorf(bookid.EINVALID, "Backup is not a catalog database.")
+	} else if err := checkIntegrity(ctx, src); err != nil {
+		return fmt.Errorf("backup: %w", err)
+	}
+
+	if err := copyDatabase(ctx, db.db, src); err != nil {
+		return fmt.Errorf("restore: %w", err)
+	} else if err := db.migrate(); err != nil {
+		return fmt.Errorf("migrate: %w", err)
+	} else if err := checkIntegrity(ctx, db.db); err != nil {
+		return fmt.Errorf("restored database: %w", err)
+	}
+	db.Logger.DebugContext(ctx, "restored database", "path", path)
+	return nil
+}
+
+// copyDatabase copies the main database of src over that of dst with the
+// online backup API, a few pages at a time so ctx can stop it.
+func copyDatabase(ctx context.Context, dst, src *sql.DB) error {
+	dstConn, err := dst.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = dstConn.Close() }()
+	srcConn, err := src.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = srcConn.Close() }()
+
+	return dstConn.Raw(func(dstDriver any) error {
+		return srcConn.Raw(func(srcDriver any) error {
+			backup, err := dstDriver.(*sqlite3.SQLiteConn).Backup("main", srcDriver.(*sqlite3.SQLiteConn), "main")
+			if err != nil {
+				return err
+			}
+			for {
+				if err := ctx.Err(); err != nil {
+					_ = backup.Finish()
+					return err
+				}
+				done, err := backup.Step(backupStepPages)
+				if err != nil {
+					_ = backup.Finish()
+					return err
+				} else if done {
+					return backup.Finish()
+				}
+			}
+		})
+	})
+}
+
+// checkIntegrity returns EINVALID listing the problems PRAGMA integrity_check
+// finds in the database, if any.
+func checkIntegrity(ctx context.Context, db *sql.DB) error {
+	rows, err := db.QueryContext(ctx, `PRAGMA integrity_check`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var problems []string
+	for rows.Next() {
+		var s string
+		if err := rows.Scan(&s); err != nil {
+			return err
+		} else if s != "ok" {
+			problems = append(problems, s)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	} else if len(problems) > 0 {
+		return bookid.Errorf(bookid.EINVALID, "Database is corrupt: %s.", strings.Join(problems, "; "))
+	}
+	return nil
+}
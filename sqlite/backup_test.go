@@ -0,0 +1,109 @@
+package sqlite_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/sqlite"
+)
+
+func TestDB_Backup(t *testing.T) {
+	t.Parallel()
+
+	t.Run("OK", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		ctx := context.Background()
+		works := sqlite.NewWorkService(db)
+
+		dune := &bookid.Work{Title: "Dune", Author: "Frank Herbert"}
+		if err := works.CreateWork(ctx, dune); err != nil {
+			t.Fatal(err)
+		}
+		path := filepath.Join(t.TempDir(), "catalog.db")
+		if err := db.Backup(ctx, path); err != nil {
+			t.Fatal(err)
+		}
+
+		// Changes made after the backup are undone by restoring it.
+		if err := works.CreateWork(ctx, &bookid.Work{Title: "Solaris"}); err != nil {
+			t.Fatal(err)
+		} else if err := db.Restore(ctx, path); err != nil {
+			t.Fatal(err)
+		}
+		if found, n, err := works.FindWorks(ctx, bookid.WorkFilter{}); err != nil {
+			t.Fatal(err)
+		} else if n != 1 || found[0].Title != "Dune" {
+			t.Fatalf("works=%v, want only Dune", found)
+		}
+
+		// The backup can be restored into another database, too.
+		other := MustOpenDB(t)
+		defer MustCloseDB(t, other)
+		if err := other.Restore(ctx, path); err != nil {
+			t.Fatal(err)
+		} else if work, err := sqlite.NewWorkService(other).FindWorkByID(ctx, dune.ID); err != nil {
+			t.Fatal(err)
+		} else if work.Title != "Dune" {
+			t.Fatalf("Title=%q, want %q", work.Title, "Dune")
+		}
+	})
+
+	t.Run("Replace", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		ctx := context.Background()
+
+		path := filepath.Join(t.TempDir(), "catalog.db")
+		if err := os.WriteFile(path, []byte("old"), 0600); err != nil {
+			t.Fatal(err)
+		} else if err := db.Backup(ctx, path); err != nil {
+			t.Fatal(err)
+		} else if err := db.Restore(ctx, path); err != nil {
+			t.Fatal(err)
+		}
+		if entries, err := os.ReadDir(filepath.Dir(path)); err != nil {
+			t.Fatal(err)
+		} else if len(entries) != 1 {
+			t.Fatalf("len(entries)=%d, want 1", len(entries))
+		}
+	})
+}
+
+func TestDB_Restore(t *testing.T) {
+	t.Parallel()
+
+	t.Run("ErrNotDatabase", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+
+		path := filepath.Join(t.TempDir(), "catalog.db")
+		if err := os.WriteFile(path, []byte("definitely not a database, just some text that is long enough"), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if code := bookid.ErrorCode(db.Restore(context.Background(), path)); code != bookid.EINVALID {
+			t.Fatalf("ErrorCode()=%q, want %q", code, bookid.EINVALID)
+		}
+	})
+
+	t.Run("ErrNotCatalog", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+
+		// An empty file is a database without the migrations table.
+		path := filepath.Join(t.TempDir(), "catalog.db")
+		if err := os.WriteFile(path, nil, 0600); err != nil {
+			t.Fatal(err)
+		}
+		if code := bookid.ErrorCode(db.Restore(context.Background(), path)); code != bookid.EINVALID {
+			t.Fatalf("ErrorCode()=%q, want %q", code, bookid.EINVALID)
+		}
+	})
+}
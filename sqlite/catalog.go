@@ -0,0 +1,414 @@
+package sqlite
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/asin"
+	"github.com/fwojciec/bookid/bisac"
+	"github.com/fwojciec/bookid/isbn"
+	"github.com/fwojciec/bookid/language"
+	"github.com/fwojciec/bookid/match"
+	"github.com/fwojciec/bookid/subject"
+)
+
+// DefaultMatchThreshold is the title similarity above which a result joins an
+// existing work. It tolerates small differences such as a missing article or
+// a typo but keeps sequels such as "Dune Messiah" apart from "Dune".
+const DefaultMatchThreshold = 0.85
+
+// maxWorkCandidates is the number of works sharing title words with a result
+// that are compared with it.
+const maxWorkCandidates = 50
+
+// Ensure service implements interface.
+var _ bookid.CatalogService = (*CatalogService)(nil)
+
+// CatalogService represents a service for adding identified books to the
+// catalog.
+type CatalogService struct {
+	db *DB
+
+	// MatchThreshold is the minimum similarity, from 0.0 to 1.0, between the
+	// main titles of a result and an existing work for the result to be
+	// saved as another publication of that work. Subtitles and articles are
+	// ignored, and the work must also share an author with the result.
+	MatchThreshold float64
+}
+
+// NewCatalogService returns a new instance of CatalogService.
+func NewCatalogService(db *DB) *CatalogService {
+	return &CatalogService{db: db, MatchThreshold: DefaultMatchThreshold}
+}
+
+// SaveResult saves a search result as a publication of a new or existing
+// work in a single transaction. Values held back by merge policies are
+// queued for review as conflicts of the publication.
+func (s *CatalogService) SaveResult(ctx context.Context, result bookid.BookResult) (workID, publicationID int64, err error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if workID, publicationID, err = s.saveResult(ctx, tx, result); err != nil {
+		return 0, 0, err
+	} else if err := tx.Commit(); err != nil {
+		return 0, 0, err
+	}
+	return workID, publicationID, nil
+}
+
+// saveResult is a helper function to save a search result as a publication
+// of a new or existing work within tx.
+func (s *CatalogService) saveResult(ctx context.Context, tx *Tx, result bookid.BookResult) (workID, publicationID int64, err error) {
+	pub := &bookid.Publication{
+		ISBN10:              result.ISBN10,
+		ISBN13:              result.ISBN13,
+		Publisher:           result.Publisher,
+		PublishedYear:       result.PublishedYear,
+		Language:            result.Language,
+		Binding:             result.Binding,
+		PageCount:           result.PageCount,
+		DurationMinutes:     result.DurationMinutes,
+		Dimensions:          result.Dimensions,
+		GoogleBooksVolumeID: result.GoogleBooksVolumeID,
+		OCLCNumber:          result.OCLCNumber,
+		LCCN:                result.LCCN,
+		DOI:                 result.DOI,
+		ASIN:                result.ASIN,
+		ThumbnailURL:        result.ThumbnailURL,
+		Description:         result.Description,
+		TableOfContents:     result.TableOfContents,
+		GoogleBooksData:     string(result.GoogleBooksData),
+	}
+	if result.Access != nil {
+		pub.Access = *result.Access
+	}
+	if result.Classification != nil {
+		pub.Classification = *result.Classification
+	}
+	pub.Provenance = publicationProvenance(pub, result)
+
+	// Refresh a cataloged publication in place; otherwise find the work the
+	// new edition belongs to.
+	existing, err := findPublicationByIdentifiers(ctx, tx, isbn.Normalize(pub.ISBN13), pub.GoogleBooksVolumeID, asin.Normalize(pub.ASIN))
+	if err != nil {
+		return 0, 0, err
+	} else if existing != nil {
+		pub.WorkID = existing.WorkID
+	} else if pub.WorkID, err = s.findMatchingWork(ctx, tx, result); err != nil {
+		return 0, 0, err
+	}
+
+	if pub.WorkID == 0 {
+		work := &bookid.Work{
+			Title:            result.Title,
+			Author:           strings.Join(result.Authors, ", "),
+			OriginalTitle:    result.OriginalTitle,
+			OriginalLanguage: result.OriginalLanguage,
+		}
+		if err := createWork(ctx, tx, work); err != nil {
+			return 0, 0, err
+		}
+		for _, name := range result.Authors {
+			author := &bookid.Author{Name: name}
+			if err := createAuthor(ctx, tx, author); err != nil {
+				return 0, 0, err
+			} else if err := addWorkAuthor(ctx, tx, &bookid.WorkAuthor{WorkID: work.ID, AuthorID: author.ID}); err != nil {
+				return 0, 0, err
+			}
+		}
+		pub.WorkID = work.ID
+	} else if err := recordOriginal(ctx, tx, pub.WorkID, result); err != nil {
+		return 0, 0, err
+	}
+
+	if err := linkContributors(ctx, tx, pub.WorkID, result.Contributors); err != nil {
+		return 0, 0, err
+	} else if err := linkSeries(ctx, tx, pub.WorkID, result); err != nil {
+		return 0, 0, err
+	} else if err := tagSubjects(ctx, tx, pub.WorkID, result); err != nil {
+		return 0, 0, err
+	} else if err := upsertPublication(ctx, tx, pub); err != nil {
+		return 0, 0, err
+	} else if err := queueConflicts(ctx, tx, pub, result.Conflicts); err != nil {
+		return 0, 0, err
+	}
+	return pub.WorkID, pub.ID, nil
+}
+
+// publicationProvenance returns the provider of each field of pub taken from
+// result. Results of a single provider name it for every field.
+func publicationProvenance(pub *bookid.Publication, result bookid.BookResult) map[string]string {
+	m := make(map[string]string)
+	for name, set := range map[string]bool{
+		"isbn10":                 pub.ISBN10 != "",
+		"isbn13":                 pub.ISBN13 != "",
+		"publisher":              pub.Publisher != "",
+		"published_year":         pub.PublishedYear != 0,
+		"language":               pub.Language != "",
+		"binding":                pub.Binding != "",
+		"page_count":             pub.PageCount != 0,
+		"duration_minutes":       pub.DurationMinutes != 0,
+		"dimensions":             !pub.Dimensions.IsZero(),
+		"access":                 !pub.Access.IsZero(),
+		"classification":         !pub.Classification.IsZero(),
+		"google_books_volume_id": pub.GoogleBooksVolumeID != "",
+		"oclc_number":            pub.OCLCNumber != "",
+		"lccn":                   pub.LCCN != "",
+		"doi":                    pub.DOI != "",
+		"asin":                   pub.ASIN != "",
+		"thumbnail_url":          pub.ThumbnailURL != "",
+		"description":            pub.Description != "",
+		"table_of_contents":      len(pub.TableOfContents) > 0,
+	} {
+		if p := result.FieldProvider(name); set && p != "" {
+			m[name] = p
+		}
+	}
+	return m
+}
+
+// queueConflicts queues the values held back from a saved publication for
+// review, unless the publication already has them.
+func queueConflicts(ctx context.Context, tx *Tx, pub *bookid.Publication, conflicts []bookid.FieldConflict) error {
+	for _, c := range conflicts {
+		current := publicationValue(pub, c.Field)
+		if current == c.Value {
+			continue
+		}
+		if err := createConflict(ctx, tx, &bookid.Conflict{
+			PublicationID:    pub.ID,
+			Field:            c.Field,
+			CurrentValue:     current,
+			CurrentProvider:  pub.Provenance[c.Field],
+			ProposedValue:    c.Value,
+			ProposedProvider: c.Provider,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// recordOriginal fills in the original title and language of a cataloged
+// work from a result, unless the work already has them.
+func recordOriginal(ctx context.Context, tx *Tx, workID int64, result bookid.BookResult) error {
+	if result.OriginalTitle == "" && result.OriginalLanguage == "" {
+		return nil
+	}
+	work, err := findWorkByID(ctx, tx, workID)
+	if err != nil {
+		return err
+	}
+
+	var upd bookid.WorkUpdate
+	if work.OriginalTitle == "" && result.OriginalTitle != "" {
+		upd.OriginalTitle = &result.OriginalTitle
+	}
+	if work.OriginalLanguage == "" && result.OriginalLanguage != "" {
+		upd.OriginalLanguage = &result.OriginalLanguage
+	}
+	if upd.OriginalTitle == nil && upd.OriginalLanguage == nil {
+		return nil
+	}
+	_, err = updateWork(ctx, tx, workID, upd)
+	return err
+}
+
+// linkContributors links the contributors of a result to a work in their
+// roles. People already linked keep their role, so a translator of one
+// edition who is credited as the author of the work stays its author.
+func linkContributors(ctx context.Context, tx *Tx, workID int64, contributors []bookid.Contributor) error {
+	for _, c := range contributors {
+		if strings.TrimSpace(c.Name) == "" || !c.Role.Valid() {
+			continue
+		}
+		author := &bookid.Author{Name: c.Name}
+		if err := createAuthor(ctx, tx, author); err != nil {
+			return err
+		} else if err := addWorkAuthor(ctx, tx, &bookid.WorkAuthor{WorkID: workID, AuthorID: author.ID, Role: c.Role}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// linkSeries links a work to the series named by result, if any. The volume
+// number of an existing link is only filled in, never replaced, as providers
+// disagree on the numbering of some series.
+func linkSeries(ctx context.Context, tx *Tx, workID int64, result bookid.BookResult) error {
+	if strings.TrimSpace(result.Series) == "" {
+		return nil
+	}
+	series := &bookid.Series{Name: result.Series}
+	if err := createSeries(ctx, tx, series); err != nil {
+		return err
+	}
+
+	links, _, err := findSeriesWorks(ctx, tx, bookid.SeriesWorkFilter{SeriesID: &series.ID, WorkID: &workID})
+	if err != nil {
+		return err
+	} else if len(links) > 0 && (links[0].Volume != 0 || result.SeriesVolume == 0) {
+		return nil
+	}
+	return addSeriesWork(ctx, tx, &bookid.SeriesWork{SeriesID: series.ID, WorkID: workID, Volume: result.SeriesVolume})
+}
+
+// tagSubjects tags a work with the normalized subjects of a result. Each
+// BISAC code of the result is stored on the subject named by the most
+// specific part of its heading, so "FIC028000" ends up on "science fiction".
+func tagSubjects(ctx context.Context, tx *Tx, workID int64, result bookid.BookResult) error {
+	subjects := make([]*bookid.Subject, 0, len(result.Subjects)+len(result.BISACCodes))
+	for _, code := range result.BISACCodes {
+		if names := subject.Normalize([]string{bisac.Heading(code)}); len(names) > 0 {
+			subjects = append(subjects, &bookid.Subject{Name: names[len(names)-1], BISACCode: code})
+		}
+	}
+	for _, name := range subject.Normalize(result.Subjects) {
+		subjects = append(subjects, &bookid.Subject{Name: name})
+	}
+
+	for _, s := range subjects {
+		if err := createSubject(ctx, tx, s); err != nil {
+			return err
+		} else if err := addWorkSubject(ctx, tx, &bookid.WorkSubject{WorkID: workID, SubjectID: s.ID}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// findMatchingWork returns the ID of the cataloged work that result is most
+// likely another edition of, or zero if there is none. Works in other
+// languages only are skipped as they are likely translations, which are
+// separate works.
+func (s *CatalogService) findMatchingWork(ctx context.Context, tx *Tx, result bookid.BookResult) (int64, error) {
+	title := mainTitle(result.Title)
+	if title == "" {
+		return 0, nil
+	}
+
+	// Only works sharing a title word can match. The index holds words in
+	// their original script, so the words of the result's title are looked
+	// up as well as their transliterations. Both are lowercase letters and
+	// digits, so they cannot be mistaken for query operators.
+	var terms []string
+	words := strings.FieldsFunc(strings.ToLower(result.Title), func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) })
+	for _, token := range append(strings.Fields(title), words...) {
+		if term := "title:" + token; !slices.Contains(terms, term) {
+			terms = append(terms, term)
+		}
+	}
+	rows, err := tx.QueryContext(ctx, `
+		SELECT works.id, works.title, works.author
+		FROM catalog_fts
+		JOIN works ON works.id = catalog_fts.docid
+		WHERE catalog_fts MATCH ? AND works.deleted_at IS NULL
+		ORDER BY works.id ASC
+		LIMIT ?
+	`, strings.Join(terms, " OR "), maxWorkCandidates)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	var candidates []*bookid.Work
+	for rows.Next() {
+		var work bookid.Work
+		if err := rows.Scan(&work.ID, &work.Title, &work.Author); err != nil {
+			return 0, err
+		}
+		candidates = append(candidates, &work)
+	}
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	var bestID int64
+	bestScore := s.MatchThreshold
+	for _, work := range candidates {
+		score := match.LevenshteinSimilarity(title, mainTitle(work.Title))
+		if score < bestScore {
+			continue
+		}
+		if ok, err := sharesAuthor(ctx, tx, work, result.Authors); err != nil {
+			return 0, err
+		} else if !ok {
+			continue
+		}
+		if ok, err := inOtherLanguage(ctx, tx, work.ID, result.Language); err != nil {
+			return 0, err
+		} else if ok {
+			continue
+		}
+		// Ties go to the work cataloged first.
+		if score > bestScore || bestID == 0 {
+			bestID, bestScore = work.ID, score
+		}
+	}
+	return bestID, nil
+}
+
+// sharesAuthor reports whether any of names is a linked or credited author of
+// work. Translators and other contributors do not count. Works and results
+// without authors only match each other.
+func sharesAuthor(ctx context.Context, tx *Tx, work *bookid.Work, names []string) (bool, error) {
+	authors, _, err := findAuthors(ctx, tx, bookid.AuthorFilter{WorkID: &work.ID})
+	if err != nil {
+		return false, err
+	}
+
+	keys := make(map[string]bool)
+	for _, a := range authors {
+		if a.Role == bookid.ContributorRoleAuthor {
+			keys[authorNameKey(a.Name)] = true
+		}
+	}
+	for _, name := range strings.Split(work.Author, ", ") {
+		if key := authorNameKey(name); key != "" {
+			keys[key] = true
+		}
+	}
+
+	if len(keys) == 0 || len(names) == 0 {
+		return len(keys) == 0 && len(names) == 0, nil
+	}
+	for _, name := range names {
+		if keys[authorNameKey(name)] {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// inOtherLanguage reports whether all publications of a work have a known
+// language other than lang. Returns false if lang is unknown.
+func inOtherLanguage(ctx context.Context, tx *Tx, workID int64, lang string) (bool, error) {
+	if lang == "" {
+		return false, nil
+	}
+	pubs, _, err := findPublications(ctx, tx, bookid.PublicationFilter{WorkID: &workID})
+	if err != nil {
+		return false, err
+	}
+	other := false
+	for _, pub := range pubs {
+		if pub.Language == "" || language.Base(pub.Language) == language.Base(lang) {
+			return false, nil
+		}
+		other = true
+	}
+	return other, nil
+}
+
+// mainTitle returns the words of a title that identify the work: the title
+// without its subtitle, normalized and without articles.
+func mainTitle(title string) string {
+	title, _, _ = strings.Cut(title, ":")
+	return strings.Join(match.Tokens(title), " ")
+}
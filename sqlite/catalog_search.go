@@ -0,0 +1,155 @@
+package sqlite
+
+import (
+	"context"
+	"encoding/binary"
+	"math"
+	"strings"
+	"unicode"
+
+	"github.com/fwojciec/bookid"
+)
+
+// Ensure service implements interface.
+var _ bookid.CatalogSearchService = (*CatalogSearchService)(nil)
+
+// CatalogSearchService represents a service for full-text search of the
+// catalog. The index is kept up to date by triggers on the catalog tables.
+type CatalogSearchService struct {
+	db *DB
+}
+
+// NewCatalogSearchService returns a new instance of CatalogSearchService.
+func NewCatalogSearchService(db *DB) *CatalogSearchService {
+	return &CatalogSearchService{db: db}
+}
+
+// SearchCatalog retrieves the works matching every term of the query, most
+// relevant first by BM25, with matches in the title weighing the most and
+// those in publications the least. Returns EINVALID if the query has no
+// terms.
+func (s *CatalogSearchService) SearchCatalog(ctx context.Context, filter bookid.CatalogSearchFilter) ([]*bookid.Work, int, error) {
+	match := matchExpression(filter.Query)
+	if match == "" {
+		return nil, 0, bookid.Errorf(bookid.EINVALID, "Search query required.")
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	rows, err := tx.QueryContext(ctx, `
+		WITH matches AS MATERIALIZED (
+			SELECT docid, bm25(matchinfo(catalog_fts, 'pcnalx'), 3.0, 2.0, 1.0) AS relevance
+			FROM catalog_fts
+			WHERE catalog_fts MATCH ?
+		)
+		SELECT works.id, works.title, works.author, works.created_at, works.updated_at, matches.relevance, COUNT(*) OVER ()
+		FROM matches
+		JOIN works ON works.id = matches.docid
+		WHERE works.deleted_at IS NULL
+		ORDER BY matches.relevance DESC, works.id ASC
+		`+FormatLimitOffset(filter.Limit, filter.Offset),
+		match,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var n int
+	works := make([]*bookid.Work, 0)
+	for rows.Next() {
+		var work bookid.Work
+		if err := rows.Scan(
+			&work.ID,
+			&work.Title,
+			&work.Author,
+			(*NullTime)(&work.CreatedAt),
+			(*NullTime)(&work.UpdatedAt),
+			&work.Relevance,
+			&n,
+		); err != nil {
+			return nil, 0, err
+		}
+		works = append(works, &work)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+	return works, n, nil
+}
+
+// matchExpression converts user search terms to an FTS query matching all of
+// them. Each term is quoted so operators and punctuation are matched as text
+// rather than parsed as query syntax; a trailing "*" is kept as a prefix
+// match. Returns an empty string if there are no searchable terms.
+func matchExpression(query string) string {
+	var terms []string
+	for _, term := range strings.Fields(query) {
+		prefix := strings.HasSuffix(term, "*")
+		term = strings.Map(func(r rune) rune {
+			if r == '"' || r == '*' {
+				return -1
+			}
+			return r
+		}, term)
+		if !strings.ContainsFunc(term, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }) {
+			continue
+		}
+		if prefix {
+			term += "*"
+		}
+		terms = append(terms, `"`+term+`"`)
+	}
+	return strings.Join(terms, " ")
+}
+
+// BM25 parameters: k1 limits how much repeated terms add to the score and b
+// how much longer documents are penalized.
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// bm25 implements the bm25(matchinfo, weight...) SQL function, which FTS4
+// lacks. It scores a document from its matchinfo(catalog_fts, 'pcnalx')
+// statistics, each column counting by its weight, or 1 if none is given.
+// Unlike the bm25 of FTS5, higher scores are better and never negative.
+func bm25(matchinfo []byte, weights ...float64) float64 {
+	info := make([]uint32, len(matchinfo)/4)
+	for i := range info {
+		info[i] = binary.NativeEndian.Uint32(matchinfo[i*4:])
+	}
+	if len(info) < 3 {
+		return 0
+	}
+	phrases, cols, rows := int(info[0]), int(info[1]), float64(info[2])
+	if len(info) < 3+2*cols+3*phrases*cols {
+		return 0
+	}
+	avgLen, docLen, hits := info[3:3+cols], info[3+cols:3+2*cols], info[3+2*cols:]
+
+	var score float64
+	for i := range phrases {
+		for j := range cols {
+			tf, docs := float64(hits[3*(i*cols+j)]), float64(hits[3*(i*cols+j)+2])
+			if tf == 0 {
+				continue
+			}
+			weight := 1.0
+			if j < len(weights) {
+				weight = weights[j]
+			}
+			idf := math.Log(1 + (rows-docs+0.5)/(docs+0.5))
+			norm := 1 - bm25B
+			if avgLen[j] > 0 {
+				norm += bm25B * float64(docLen[j]) / float64(avgLen[j])
+			}
+			score += weight * idf * tf * (bm25K1 + 1) / (tf + bm25K1*norm)
+		}
+	}
+	return score
+}
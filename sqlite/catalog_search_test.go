@@ -0,0 +1,187 @@
+package sqlite_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/sqlite"
+)
+
+// searchIDs returns the IDs of the works matching query.
+func searchIDs(tb testing.TB, s *sqlite.CatalogSearchService, query string) []int64 {
+	tb.Helper()
+	works, n, err := s.SearchCatalog(context.Background(), bookid.CatalogSearchFilter{Query: query})
+	if err != nil {
+		tb.Fatal(err)
+	} else if n != len(works) {
+		tb.Fatalf("n=%d, want %d", n, len(works))
+	}
+	ids := make([]int64, 0, len(works))
+	for _, w := range works {
+		ids = append(ids, w.ID)
+	}
+	return ids
+}
+
+func TestCatalogSearchService_SearchCatalog(t *testing.T) {
+	t.Parallel()
+
+	t.Run("OK", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		ctx := context.Background()
+		works := sqlite.NewWorkService(db)
+		authors := sqlite.NewAuthorService(db)
+		pubs := sqlite.NewPublicationService(db)
+		s := sqlite.NewCatalogSearchService(db)
+
+		gatsby := &bookid.Work{Title: "The Great Gatsby", Author: "F. Scott Fitzgerald"}
+		solaris := &bookid.Work{Title: "Solaris"}
+		for _, w := range []*bookid.Work{gatsby, solaris} {
+			if err := works.CreateWork(ctx, w); err != nil {
+				t.Fatal(err)
+			}
+		}
+		lem := &bookid.Author{Name: "Stanisław Lem"}
+		if err := authors.CreateAuthor(ctx, lem); err != nil {
+			t.Fatal(err)
+		} else if err := authors.AddWorkAuthor(ctx, &bookid.WorkAuthor{WorkID: solaris.ID, AuthorID: lem.ID}); err != nil {
+			t.Fatal(err)
+		}
+		if err := pubs.CreatePublication(ctx, &bookid.Publication{WorkID: gatsby.ID, ISBN13: "9780743273565", Publisher: "Scribner"}); err != nil {
+			t.Fatal(err)
+		} else if err := pubs.CreatePublication(ctx, &bookid.Publication{WorkID: solaris.ID, Publisher: "Wydawnictwo Literackie Kraków"}); err != nil {
+			t.Fatal(err)
+		}
+
+		for _, tt := range []struct {
+			query string
+			want  []int64
+		}{
+			{"gatsby", []int64{gatsby.ID}},
+			{"great FITZGERALD", []int64{gatsby.ID}},
+			{"gatsby lem", []int64{}},
+			{"stanisław lem", []int64{solaris.ID}},
+			{"krakow", []int64{solaris.ID}}, // Diacritics are ignored
+			{"sol*", []int64{solaris.ID}},
+			{"scribner", []int64{gatsby.ID}},
+			{"9780743273565", []int64{gatsby.ID}},
+			{`"gatsby" OR -lem`, []int64{}}, // Operators are matched as text
+		} {
+			if got := searchIDs(t, s, tt.query); !slices.Equal(got, tt.want) {
+				t.Errorf("SearchCatalog(%q)=%v, want %v", tt.query, got, tt.want)
+			}
+		}
+	})
+
+	t.Run("IndexFollowsChanges", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		ctx := context.Background()
+		works := sqlite.NewWorkService(db)
+		authors := sqlite.NewAuthorService(db)
+		pubs := sqlite.NewPublicationService(db)
+		s := sqlite.NewCatalogSearchService(db)
+
+		work := &bookid.Work{Title: "Dune"}
+		if err := works.CreateWork(ctx, work); err != nil {
+			t.Fatal(err)
+		}
+		herbert := &bookid.Author{Name: "Frank Herbert"}
+		if err := authors.CreateAuthor(ctx, herbert); err != nil {
+			t.Fatal(err)
+		} else if err := authors.AddWorkAuthor(ctx, &bookid.WorkAuthor{WorkID: work.ID, AuthorID: herbert.ID}); err != nil {
+			t.Fatal(err)
+		}
+		pub := &bookid.Publication{WorkID: work.ID, Publisher: "Chilton"}
+		if err := pubs.CreatePublication(ctx, pub); err != nil {
+			t.Fatal(err)
+		}
+		if got := searchIDs(t, s, "dune herbert chilton"); !slices.Equal(got, []int64{work.ID}) {
+			t.Fatalf("ids=%v, want %v", got, []int64{work.ID})
+		}
+
+		title := "Dune Messiah"
+		if _, err := works.UpdateWork(ctx, work.ID, bookid.WorkUpdate{Title: &title}); err != nil {
+			t.Fatal(err)
+		} else if got := searchIDs(t, s, "messiah"); !slices.Equal(got, []int64{work.ID}) {
+			t.Fatalf("ids=%v, want %v", got, []int64{work.ID})
+		}
+
+		if err := pubs.DeletePublication(ctx, pub.ID); err != nil {
+			t.Fatal(err)
+		} else if got := searchIDs(t, s, "chilton"); len(got) != 0 {
+			t.Fatalf("ids=%v, want none", got)
+		}
+
+		if err := authors.RemoveWorkAuthor(ctx, &bookid.WorkAuthor{WorkID: work.ID, AuthorID: herbert.ID}); err != nil {
+			t.Fatal(err)
+		} else if got := searchIDs(t, s, "herbert"); len(got) != 0 {
+			t.Fatalf("ids=%v, want none", got)
+		}
+
+		// Deleting a work cascades to its publications.
+		if err := pubs.CreatePublication(ctx, &bookid.Publication{WorkID: work.ID, Publisher: "Ace"}); err != nil {
+			t.Fatal(err)
+		} else if err := works.DeleteWork(ctx, work.ID); err != nil {
+			t.Fatal(err)
+		} else if got := searchIDs(t, s, "dune"); len(got) != 0 {
+			t.Fatalf("ids=%v, want none", got)
+		} else if got := searchIDs(t, s, "ace"); len(got) != 0 {
+			t.Fatalf("ids=%v, want none", got)
+		}
+	})
+
+	t.Run("Ranked", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		ctx := context.Background()
+		works := sqlite.NewWorkService(db)
+		pubs := sqlite.NewPublicationService(db)
+		s := sqlite.NewCatalogSearchService(db)
+
+		guide := &bookid.Work{Title: "A Guide to the Deserts of the World"}
+		children := &bookid.Work{Title: "Children of Dune", Author: "Frank Herbert"}
+		dune := &bookid.Work{Title: "Dune", Author: "Frank Herbert"}
+		for _, w := range []*bookid.Work{guide, children, dune} {
+			if err := works.CreateWork(ctx, w); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := pubs.CreatePublication(ctx, &bookid.Publication{WorkID: guide.ID, Publisher: "Dune Press"}); err != nil {
+			t.Fatal(err)
+		}
+
+		// Shorter titles rank above longer ones and titles above publishers.
+		want := []int64{dune.ID, children.ID, guide.ID}
+		if got := searchIDs(t, s, "dune"); !slices.Equal(got, want) {
+			t.Fatalf("ids=%v, want %v", got, want)
+		}
+
+		found, _, err := s.SearchCatalog(ctx, bookid.CatalogSearchFilter{Query: "dune", Limit: 1})
+		if err != nil {
+			t.Fatal(err)
+		} else if len(found) != 1 || found[0].ID != dune.ID {
+			t.Fatalf("works=%v, want only %d", found, dune.ID)
+		} else if found[0].Relevance <= 0 {
+			t.Fatalf("Relevance=%v, want positive", found[0].Relevance)
+		}
+	})
+
+	t.Run("ErrQueryRequired", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewCatalogSearchService(db)
+
+		_, _, err := s.SearchCatalog(context.Background(), bookid.CatalogSearchFilter{Query: ` " * `})
+		if code := bookid.ErrorCode(err); code != bookid.EINVALID {
+			t.Fatalf("ErrorCode()=%q, want %q", code, bookid.EINVALID)
+		}
+	})
+}
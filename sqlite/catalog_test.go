@@ -0,0 +1,391 @@
+package sqlite_test
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/sqlite"
+)
+
+func TestCatalogService_SaveResult(t *testing.T) {
+	t.Parallel()
+
+	gatsby := bookid.BookResult{
+		Title:     "The Great Gatsby",
+		Authors:   []string{"F. Scott Fitzgerald"},
+		ISBN13:    "9780743273565",
+		Publisher: "Scribner",
+		Language:  "en",
+	}
+
+	t.Run("NewWork", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewCatalogService(db)
+		ctx := context.Background()
+
+		workID, pubID, err := s.SaveResult(ctx, gatsby)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if work, err := sqlite.NewWorkService(db).FindWorkByID(ctx, workID); err != nil {
+			t.Fatal(err)
+		} else if got, want := work.Title, "The Great Gatsby"; got != want {
+			t.Fatalf("Title=%q, want %q", got, want)
+		}
+		if pub, err := sqlite.NewPublicationService(db).FindPublicationByID(ctx, pubID); err != nil {
+			t.Fatal(err)
+		} else if pub.WorkID != workID {
+			t.Fatalf("WorkID=%d, want %d", pub.WorkID, workID)
+		}
+		if authors, _, err := sqlite.NewAuthorService(db).FindAuthors(ctx, bookid.AuthorFilter{WorkID: &workID}); err != nil {
+			t.Fatal(err)
+		} else if len(authors) != 1 || authors[0].Name != "F. Scott Fitzgerald" {
+			t.Fatalf("unexpected authors: %#v", authors)
+		}
+	})
+
+	t.Run("RefreshesPublication", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewCatalogService(db)
+		ctx := context.Background()
+
+		workID, pubID, err := s.SaveResult(ctx, gatsby)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// The same ISBN under another title still refreshes the publication.
+		again := gatsby
+		again.Title = "Gatsby"
+		again.PublishedYear = 2004
+		if w, p, err := s.SaveResult(ctx, again); err != nil {
+			t.Fatal(err)
+		} else if w != workID || p != pubID {
+			t.Fatalf("ids=%d/%d, want %d/%d", w, p, workID, pubID)
+		}
+		if pub, err := sqlite.NewPublicationService(db).FindPublicationByID(ctx, pubID); err != nil {
+			t.Fatal(err)
+		} else if got, want := pub.PublishedYear, 2004; got != want {
+			t.Fatalf("PublishedYear=%d, want %d", got, want)
+		}
+	})
+
+	t.Run("RefreshesAudiobookByASIN", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewCatalogService(db)
+		ctx := context.Background()
+
+		audiobook := bookid.BookResult{
+			Title:        "Project Hail Mary",
+			Authors:      []string{"Andy Weir"},
+			Contributors: []bookid.Contributor{{Name: "Ray Porter", Role: bookid.ContributorRoleNarrator}},
+			ASIN:         "B08G9PRS1K",
+			Binding:      bookid.BindingAudiobook,
+			Provider:     "audnexus",
+		}
+		_, pubID, err := s.SaveResult(ctx, audiobook)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		audiobook.ASIN, audiobook.DurationMinutes = "b08g9prs1k", 970
+		if _, p, err := s.SaveResult(ctx, audiobook); err != nil {
+			t.Fatal(err)
+		} else if p != pubID {
+			t.Fatalf("publication=%d, want %d", p, pubID)
+		}
+		if pub, err := sqlite.NewPublicationService(db).FindPublicationByID(ctx, pubID); err != nil {
+			t.Fatal(err)
+		} else if pub.ASIN != "B08G9PRS1K" || pub.DurationMinutes != 970 {
+			t.Fatalf("ASIN=%q DurationMinutes=%d, want B08G9PRS1K 970", pub.ASIN, pub.DurationMinutes)
+		}
+	})
+
+	t.Run("RecordsProvenance", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewCatalogService(db)
+		ctx := context.Background()
+
+		merged := gatsby
+		merged.Provider = "googlebooks"
+		merged.PublishedYear = 2004
+		merged.Provenance = map[string]string{"published_year": "openlibrary"}
+		_, pubID, err := s.SaveResult(ctx, merged)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want := map[string]string{
+			"isbn13":         "googlebooks",
+			"publisher":      "googlebooks",
+			"language":       "googlebooks",
+			"published_year": "openlibrary",
+		}
+		if pub, err := sqlite.NewPublicationService(db).FindPublicationByID(ctx, pubID); err != nil {
+			t.Fatal(err)
+		} else if !reflect.DeepEqual(pub.Provenance, want) {
+			t.Fatalf("Provenance=%v, want %v", pub.Provenance, want)
+		}
+	})
+
+	t.Run("QueuesConflicts", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewCatalogService(db)
+		ctx := context.Background()
+
+		merged := gatsby
+		merged.Provider = "googlebooks"
+		merged.Conflicts = []bookid.FieldConflict{
+			{Field: "publisher", Value: "Penguin", Provider: "openlibrary"},
+			{Field: "language", Value: "en", Provider: "openlibrary"},
+		}
+		_, pubID, err := s.SaveResult(ctx, merged)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if conflicts, _, err := sqlite.NewConflictService(db).FindConflicts(ctx, bookid.ConflictFilter{PublicationID: &pubID}); err != nil {
+			t.Fatal(err)
+		} else if len(conflicts) != 1 {
+			t.Fatalf("unexpected conflicts: %#v", conflicts)
+		} else if c := conflicts[0]; c.CurrentValue != "Scribner" || c.CurrentProvider != "googlebooks" || c.ProposedValue != "Penguin" {
+			t.Fatalf("unexpected conflict: %#v", c)
+		}
+	})
+
+	t.Run("ClustersEditions", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewCatalogService(db)
+		ctx := context.Background()
+
+		workID, pubID, err := s.SaveResult(ctx, gatsby)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Another edition with a subtitle and an inverted author name joins
+		// the work.
+		edition := bookid.BookResult{
+			Title:    "Great Gatsby: A Novel",
+			Authors:  []string{"Fitzgerald, F. Scott"},
+			ISBN13:   "9780141182636",
+			Language: "en",
+		}
+		if w, p, err := s.SaveResult(ctx, edition); err != nil {
+			t.Fatal(err)
+		} else if w != workID {
+			t.Fatalf("WorkID=%d, want %d", w, workID)
+		} else if p == pubID {
+			t.Fatal("expected a new publication")
+		}
+	})
+
+	t.Run("ClustersEditionsInOtherScripts", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewCatalogService(db)
+		ctx := context.Background()
+
+		workID, _, err := s.SaveResult(ctx, bookid.BookResult{Title: "Война и мир", Authors: []string{"Лев Толстой"}, ISBN13: "9785170906307"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if w, _, err := s.SaveResult(ctx, bookid.BookResult{Title: "Война и мир: роман", Authors: []string{"Лев Толстой"}, ISBN13: "9785389062917"}); err != nil {
+			t.Fatal(err)
+		} else if w != workID {
+			t.Fatalf("WorkID=%d, want %d", w, workID)
+		}
+	})
+
+	t.Run("LinksSeries", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewCatalogService(db)
+		ctx := context.Background()
+
+		workID, _, err := s.SaveResult(ctx, bookid.BookResult{Title: "Mort", Authors: []string{"Terry Pratchett"}, ISBN13: "9780552131063", Series: "Discworld"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Another edition fills in the volume number.
+		if _, _, err := s.SaveResult(ctx, bookid.BookResult{Title: "Mort", Authors: []string{"Terry Pratchett"}, ISBN13: "9780062225719", Series: "discworld", SeriesVolume: 4}); err != nil {
+			t.Fatal(err)
+		}
+		// A different numbering does not replace it.
+		if _, _, err := s.SaveResult(ctx, bookid.BookResult{Title: "Mort", Authors: []string{"Terry Pratchett"}, ISBN13: "9780575121133", Series: "Discworld", SeriesVolume: 1}); err != nil {
+			t.Fatal(err)
+		}
+
+		links, _, err := sqlite.NewSeriesService(db).FindSeriesWorks(ctx, bookid.SeriesWorkFilter{WorkID: &workID})
+		if err != nil {
+			t.Fatal(err)
+		} else if got, want := len(links), 1; got != want {
+			t.Fatalf("len=%d, want %d", got, want)
+		} else if got, want := links[0].Volume, 4.0; got != want {
+			t.Fatalf("Volume=%v, want %v", got, want)
+		}
+	})
+
+	t.Run("LinksContributors", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewCatalogService(db)
+		ctx := context.Background()
+
+		workID, _, err := s.SaveResult(ctx, bookid.BookResult{
+			Title:        "One Hundred Years of Solitude",
+			Authors:      []string{"Gabriel García Márquez"},
+			Contributors: []bookid.Contributor{{Name: "Gregory Rabassa", Role: bookid.ContributorRoleTranslator}},
+			ISBN13:       "9780060883287",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		authors, _, err := sqlite.NewAuthorService(db).FindAuthors(ctx, bookid.AuthorFilter{WorkID: &workID})
+		if err != nil {
+			t.Fatal(err)
+		}
+		roles := make(map[string]bookid.ContributorRole)
+		for _, a := range authors {
+			roles[a.Name] = a.Role
+		}
+		if want := map[string]bookid.ContributorRole{
+			"Gabriel García Márquez": bookid.ContributorRoleAuthor,
+			"Gregory Rabassa":        bookid.ContributorRoleTranslator,
+		}; !reflect.DeepEqual(roles, want) {
+			t.Fatalf("roles=%v, want %v", roles, want)
+		}
+
+		// The translator is not an author the original edition shares.
+		otherID, _, err := s.SaveResult(ctx, bookid.BookResult{Title: "One Hundred Years of Solitude", Authors: []string{"Gregory Rabassa"}, ISBN13: "9780141184999"})
+		if err != nil {
+			t.Fatal(err)
+		} else if otherID == workID {
+			t.Fatal("expected a separate work")
+		}
+	})
+
+	t.Run("TagsSubjects", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewCatalogService(db)
+		ctx := context.Background()
+
+		workID, _, err := s.SaveResult(ctx, bookid.BookResult{Title: "Solaris", Authors: []string{"Stanisław Lem"}, ISBN13: "9780156027601", Subjects: []string{"Fiction / Science Fiction / General"}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, _, err := s.SaveResult(ctx, bookid.BookResult{Title: "Solaris", Authors: []string{"Stanisław Lem"}, ISBN13: "9788308049782", Subjects: []string{"Science-fiction", "Planets -- Fiction"}}); err != nil {
+			t.Fatal(err)
+		}
+
+		subjects, _, err := sqlite.NewSubjectService(db).FindSubjects(ctx, bookid.SubjectFilter{WorkID: &workID})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		for _, s := range subjects {
+			names = append(names, s.Name)
+		}
+		if got, want := strings.Join(names, ", "), "fiction, planets, science fiction"; got != want {
+			t.Fatalf("subjects=%q, want %q", got, want)
+		}
+	})
+
+	t.Run("StoresBISACCodes", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewCatalogService(db)
+		ctx := context.Background()
+
+		workID, _, err := s.SaveResult(ctx, bookid.BookResult{Title: "Solaris", Authors: []string{"Stanisław Lem"}, Subjects: []string{"Fiction"}, BISACCodes: []string{"FIC000000", "FIC028000"}})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		subjects, _, err := sqlite.NewSubjectService(db).FindSubjects(ctx, bookid.SubjectFilter{WorkID: &workID})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var codes []string
+		for _, s := range subjects {
+			codes = append(codes, s.Name+"="+s.BISACCode)
+		}
+		if got, want := strings.Join(codes, ", "), "fiction=FIC000000, science fiction=FIC028000"; got != want {
+			t.Fatalf("subjects=%q, want %q", got, want)
+		}
+	})
+
+	t.Run("KeepsDistinctWorksApart", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewCatalogService(db)
+		ctx := context.Background()
+
+		workID, _, err := s.SaveResult(ctx, bookid.BookResult{Title: "Dune", Authors: []string{"Frank Herbert"}, Language: "en"})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, result := range []bookid.BookResult{
+			{Title: "Dune Messiah", Authors: []string{"Frank Herbert"}, Language: "en"},
+			{Title: "Dune", Authors: []string{"Brian Herbert"}, Language: "en"},
+			{Title: "Dune", Authors: []string{"Frank Herbert"}, Language: "pl"}, // A translation
+		} {
+			if w, _, err := s.SaveResult(ctx, result); err != nil {
+				t.Fatal(err)
+			} else if w == workID {
+				t.Fatalf("%q by %v joined work %d", result.Title, result.Authors, workID)
+			}
+		}
+	})
+
+	t.Run("MatchThreshold", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewCatalogService(db)
+		ctx := context.Background()
+
+		workID, _, err := s.SaveResult(ctx, bookid.BookResult{Title: "The Great Gatsby", Authors: []string{"F. Scott Fitzgerald"}})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// A typo is tolerated by default but not by an exact threshold.
+		if w, _, err := s.SaveResult(ctx, bookid.BookResult{Title: "The Great Gatsbi", Authors: []string{"F. Scott Fitzgerald"}}); err != nil {
+			t.Fatal(err)
+		} else if w != workID {
+			t.Fatalf("WorkID=%d, want %d", w, workID)
+		}
+		s.MatchThreshold = 1
+		if w, _, err := s.SaveResult(ctx, bookid.BookResult{Title: "The Great Gatsbyy", Authors: []string{"F. Scott Fitzgerald"}}); err != nil {
+			t.Fatal(err)
+		} else if w == workID {
+			t.Fatal("expected a new work")
+		}
+	})
+}
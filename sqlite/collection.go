@@ -0,0 +1,312 @@
+package sqlite
+
+import (
+	"context"
+	"strings"
+
+	"github.com/fwojciec/bookid"
+)
+
+// Ensure service implements interface.
+var _ bookid.CollectionService = (*CollectionService)(nil)
+
+// CollectionService represents a service for managing collections.
+type CollectionService struct {
+	db *DB
+}
+
+// NewCollectionService returns a new instance of CollectionService.
+func NewCollectionService(db *DB) *CollectionService {
+	return &CollectionService{db: db}
+}
+
+// FindCollectionByID retrieves a single collection by ID.
+// Returns ENOTFOUND if the collection does not exist.
+func (s *CollectionService) FindCollectionByID(ctx context.Context, id int64) (*bookid.Collection, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+	return findCollectionByID(ctx, tx, id)
+}
+
+// FindCollections retrieves a list of collections matching the filter.
+func (s *CollectionService) FindCollections(ctx context.Context, filter bookid.CollectionFilter) ([]*bookid.Collection, int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer func() { _ = tx.Rollback() }()
+	return findCollections(ctx, tx, filter)
+}
+
+// CreateCollection creates a new collection.
+func (s *CollectionService) CreateCollection(ctx context.Context, collection *bookid.Collection) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := createCollection(ctx, tx, collection); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// UpdateCollection updates an existing collection.
+// Returns ENOTFOUND if the collection does not exist.
+func (s *CollectionService) UpdateCollection(ctx context.Context, id int64, upd bookid.CollectionUpdate) (*bookid.Collection, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	collection, err := updateCollection(ctx, tx, id, upd)
+	if err != nil {
+		return collection, err
+	} else if err := tx.Commit(); err != nil {
+		return collection, err
+	}
+	return collection, nil
+}
+
+// DeleteCollection permanently removes a collection, keeping its works.
+// Returns ENOTFOUND if the collection does not exist.
+func (s *CollectionService) DeleteCollection(ctx context.Context, id int64) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := deleteCollection(ctx, tx, id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// AddCollectionWork adds a work to a collection.
+func (s *CollectionService) AddCollectionWork(ctx context.Context, collectionID, workID int64) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := addCollectionWork(ctx, tx, collectionID, workID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// RemoveCollectionWork removes a work from a collection.
+// Returns ENOTFOUND if the work is not in the collection.
+func (s *CollectionService) RemoveCollectionWork(ctx context.Context, collectionID, workID int64) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := removeCollectionWork(ctx, tx, collectionID, workID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// findCollectionByID is a helper function to fetch a collection by ID.
+// Returns ENOTFOUND if the collection does not exist.
+func findCollectionByID(ctx context.Context, tx *Tx, id int64) (*bookid.Collection, error) {
+	collections, _, err := findCollections(ctx, tx, bookid.CollectionFilter{ID: &id})
+	if err != nil {
+		return nil, err
+	} else if len(collections) == 0 {
+		return nil, bookid.Errorf(bookid.ENOTFOUND, "Collection not found.")
+	}
+	return collections[0], nil
+}
+
+// findCollections returns a list of collections matching a filter, ordered
+// by name. Also returns a count of total matching collections which may
+// differ if filter.Limit is set.
+func findCollections(ctx context.Context, tx *Tx, filter bookid.CollectionFilter) (_ []*bookid.Collection, n int, err error) {
+	where, args := []string{"1 = 1"}, []any{}
+	if v := filter.ID; v != nil {
+		where, args = append(where, "id = ?"), append(args, *v)
+	}
+	if v := filter.Name; v != nil {
+		where, args = append(where, "name = ?"), append(args, strings.TrimSpace(*v))
+	}
+	if v := filter.WorkID; v != nil {
+		where, args = append(where, "id IN (SELECT collection_id FROM collection_works WHERE work_id = ?)"), append(args, *v)
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id, name, description, created_at, updated_at, COUNT(*) OVER ()
+		FROM collections
+		WHERE `+strings.Join(where, " AND ")+`
+		ORDER BY name ASC
+		`+FormatLimitOffset(filter.Limit, filter.Offset),
+		args...,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	collections := make([]*bookid.Collection, 0)
+	for rows.Next() {
+		var c bookid.Collection
+		if err := rows.Scan(
+			&c.ID,
+			&c.Name,
+			&c.Description,
+			(*NullTime)(&c.CreatedAt),
+			(*NullTime)(&c.UpdatedAt),
+			&n,
+		); err != nil {
+			return nil, 0, err
+		}
+		collections = append(collections, &c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+	return collections, n, nil
+}
+
+// createCollection creates a new collection. Sets the ID and timestamps on
+// success.
+func createCollection(ctx context.Context, tx *Tx, collection *bookid.Collection) error {
+	collection.Name = strings.Join(strings.Fields(collection.Name), " ")
+	collection.Description = strings.TrimSpace(collection.Description)
+	if err := collection.Validate(); err != nil {
+		return err
+	} else if err := checkCollectionName(ctx, tx, 0, collection.Name); err != nil {
+		return err
+	}
+
+	collection.CreatedAt = tx.now
+	collection.UpdatedAt = collection.CreatedAt
+
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO collections (name, description, created_at, updated_at)
+		VALUES (?, ?, ?, ?)
+	`,
+		collection.Name,
+		collection.Description,
+		(*NullTime)(&collection.CreatedAt),
+		(*NullTime)(&collection.UpdatedAt),
+	)
+	if err != nil {
+		return FormatError(err)
+	}
+
+	if collection.ID, err = result.LastInsertId(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// updateCollection updates fields on a collection by ID. Returns the updated
+// collection.
+func updateCollection(ctx context.Context, tx *Tx, id int64, upd bookid.CollectionUpdate) (*bookid.Collection, error) {
+	collection, err := findCollectionByID(ctx, tx, id)
+	if err != nil {
+		return collection, err
+	}
+
+	if v := upd.Name; v != nil {
+		collection.Name = strings.Join(strings.Fields(*v), " ")
+	}
+	if v := upd.Description; v != nil {
+		collection.Description = strings.TrimSpace(*v)
+	}
+	collection.UpdatedAt = tx.now
+
+	if err := collection.Validate(); err != nil {
+		return collection, err
+	} else if err := checkCollectionName(ctx, tx, id, collection.Name); err != nil {
+		return collection, err
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE collections
+		SET name = ?, description = ?, updated_at = ?
+		WHERE id = ?
+	`,
+		collection.Name,
+		collection.Description,
+		(*NullTime)(&collection.UpdatedAt),
+		id,
+	); err != nil {
+		return collection, FormatError(err)
+	}
+	return collection, nil
+}
+
+// checkCollectionName returns ECONFLICT if a collection other than the one
+// with the given ID is named name.
+func checkCollectionName(ctx context.Context, tx *Tx, id int64, name string) error {
+	collections, _, err := findCollections(ctx, tx, bookid.CollectionFilter{Name: &name})
+	if err != nil {
+		return err
+	}
+	for _, c := range collections {
+		if c.ID != id {
+			return bookid.Errorf(bookid.ECONFLICT, "Collection %q already exists.", c.Name)
+		}
+	}
+	return nil
+}
+
+// deleteCollection permanently removes a collection by ID.
+func deleteCollection(ctx context.Context, tx *Tx, id int64) error {
+	if _, err := findCollectionByID(ctx, tx, id); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM collections WHERE id = ?`, id); err != nil {
+		return FormatError(err)
+	}
+	return nil
+}
+
+// addCollectionWork adds a work to a collection, ignoring works already in
+// it.
+func addCollectionWork(ctx context.Context, tx *Tx, collectionID, workID int64) error {
+	if _, err := findCollectionByID(ctx, tx, collectionID); err != nil {
+		return err
+	} else if _, err := findWorkByID(ctx, tx, workID); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO collection_works (collection_id, work_id, added_at)
+		VALUES (?, ?, ?)
+		ON CONFLICT DO NOTHING
+	`,
+		collectionID,
+		workID,
+		(*NullTime)(&tx.now),
+	); err != nil {
+		return FormatError(err)
+	}
+	return nil
+}
+
+// removeCollectionWork removes a work from a collection.
+func removeCollectionWork(ctx context.Context, tx *Tx, collectionID, workID int64) error {
+	result, err := tx.ExecContext(ctx, `DELETE FROM collection_works WHERE collection_id = ? AND work_id = ?`, collectionID, workID)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return bookid.Errorf(bookid.ENOTFOUND, "Work not in collection.")
+	}
+	return nil
+}
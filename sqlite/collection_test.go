@@ -0,0 +1,176 @@
+package sqlite_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/sqlite"
+)
+
+func TestCollectionService_CreateCollection(t *testing.T) {
+	t.Parallel()
+
+	t.Run("OK", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewCollectionService(db)
+		ctx := context.Background()
+
+		collection := &bookid.Collection{Name: " to-read ", Description: "Next up"}
+		if err := s.CreateCollection(ctx, collection); err != nil {
+			t.Fatal(err)
+		} else if got, want := collection.ID, int64(1); got != want {
+			t.Fatalf("ID=%d, want %d", got, want)
+		} else if got, want := collection.Name, "to-read"; got != want {
+			t.Fatalf("Name=%q, want %q", got, want)
+		} else if collection.CreatedAt.IsZero() || collection.UpdatedAt.IsZero() {
+			t.Fatal("expected timestamps")
+		}
+
+		name := "TO-READ"
+		if collections, _, err := s.FindCollections(ctx, bookid.CollectionFilter{Name: &name}); err != nil {
+			t.Fatal(err)
+		} else if len(collections) != 1 || collections[0].Description != "Next up" {
+			t.Fatalf("unexpected collections: %+v", collections)
+		}
+	})
+
+	t.Run("ErrDuplicate", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewCollectionService(db)
+		ctx := context.Background()
+
+		MustCreateCollection(t, ctx, db, &bookid.Collection{Name: "to-read"})
+		err := s.CreateCollection(ctx, &bookid.Collection{Name: "To-Read"})
+		if code := bookid.ErrorCode(err); code != bookid.ECONFLICT {
+			t.Fatalf("ErrorCode()=%q, want %q", code, bookid.ECONFLICT)
+		}
+	})
+
+	t.Run("ErrNameRequired", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewCollectionService(db)
+
+		err := s.CreateCollection(context.Background(), &bookid.Collection{})
+		if code := bookid.ErrorCode(err); code != bookid.EINVALID {
+			t.Fatalf("ErrorCode()=%q, want %q", code, bookid.EINVALID)
+		}
+	})
+}
+
+func TestCollectionService_UpdateCollection(t *testing.T) {
+	t.Parallel()
+
+	db := MustOpenDB(t)
+	defer MustCloseDB(t, db)
+	s := sqlite.NewCollectionService(db)
+	ctx := context.Background()
+
+	collection := MustCreateCollection(t, ctx, db, &bookid.Collection{Name: "to-read"})
+	MustCreateCollection(t, ctx, db, &bookid.Collection{Name: "office library"})
+
+	if updated, err := s.UpdateCollection(ctx, collection.ID, bookid.CollectionUpdate{Description: ptr("Next up")}); err != nil {
+		t.Fatal(err)
+	} else if got, want := updated.Description, "Next up"; got != want {
+		t.Fatalf("Description=%q, want %q", got, want)
+	} else if got, want := updated.Name, "to-read"; got != want {
+		t.Fatalf("Name=%q, want %q", got, want)
+	}
+
+	if _, err := s.UpdateCollection(ctx, collection.ID, bookid.CollectionUpdate{Name: ptr("Office Library")}); bookid.ErrorCode(err) != bookid.ECONFLICT {
+		t.Fatalf("ErrorCode()=%q, want %q", bookid.ErrorCode(err), bookid.ECONFLICT)
+	}
+}
+
+func TestCollectionService_AddCollectionWork(t *testing.T) {
+	t.Parallel()
+
+	t.Run("OK", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewCollectionService(db)
+		ctx := context.Background()
+
+		collection := MustCreateCollection(t, ctx, db, &bookid.Collection{Name: "to-read"})
+		dune := MustCreateWork(t, ctx, db, &bookid.Work{Title: "Dune"})
+		MustCreateWork(t, ctx, db, &bookid.Work{Title: "Emma"})
+		for range 2 {
+			if err := s.AddCollectionWork(ctx, collection.ID, dune.ID); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		works, n, err := sqlite.NewWorkService(db).FindWorks(ctx, bookid.WorkFilter{CollectionID: &collection.ID})
+		if err != nil {
+			t.Fatal(err)
+		} else if got, want := n, 1; got != want {
+			t.Fatalf("n=%d, want %d", got, want)
+		} else if got, want := works[0].Title, "Dune"; got != want {
+			t.Fatalf("Title=%q, want %q", got, want)
+		}
+
+		if collections, _, err := s.FindCollections(ctx, bookid.CollectionFilter{WorkID: &dune.ID}); err != nil {
+			t.Fatal(err)
+		} else if len(collections) != 1 {
+			t.Fatalf("len=%d, want 1", len(collections))
+		}
+
+		if err := s.RemoveCollectionWork(ctx, collection.ID, dune.ID); err != nil {
+			t.Fatal(err)
+		} else if err := s.RemoveCollectionWork(ctx, collection.ID, dune.ID); bookid.ErrorCode(err) != bookid.ENOTFOUND {
+			t.Fatalf("ErrorCode()=%q, want %q", bookid.ErrorCode(err), bookid.ENOTFOUND)
+		}
+	})
+
+	t.Run("ErrWorkNotFound", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewCollectionService(db)
+		ctx := context.Background()
+
+		collection := MustCreateCollection(t, ctx, db, &bookid.Collection{Name: "to-read"})
+		if err := s.AddCollectionWork(ctx, collection.ID, 100); bookid.ErrorCode(err) != bookid.ENOTFOUND {
+			t.Fatalf("ErrorCode()=%q, want %q", bookid.ErrorCode(err), bookid.ENOTFOUND)
+		}
+	})
+}
+
+func TestCollectionService_DeleteCollection(t *testing.T) {
+	t.Parallel()
+
+	db := MustOpenDB(t)
+	defer MustCloseDB(t, db)
+	s := sqlite.NewCollectionService(db)
+	ctx := context.Background()
+
+	collection := MustCreateCollection(t, ctx, db, &bookid.Collection{Name: "to-read"})
+	work := MustCreateWork(t, ctx, db, &bookid.Work{Title: "Dune"})
+	if err := s.AddCollectionWork(ctx, collection.ID, work.ID); err != nil {
+		t.Fatal(err)
+	} else if err := s.DeleteCollection(ctx, collection.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.FindCollectionByID(ctx, collection.ID); bookid.ErrorCode(err) != bookid.ENOTFOUND {
+		t.Fatalf("ErrorCode()=%q, want %q", bookid.ErrorCode(err), bookid.ENOTFOUND)
+	} else if _, err := sqlite.NewWorkService(db).FindWorkByID(ctx, work.ID); err != nil {
+		t.Fatalf("expected work to be kept: %v", err)
+	}
+}
+
+// MustCreateCollection creates a collection in the database. Fatal on error.
+func MustCreateCollection(tb testing.TB, ctx context.Context, db *sqlite.DB, collection *bookid.Collection) *bookid.Collection {
+	tb.Helper()
+	if err := sqlite.NewCollectionService(db).CreateCollection(ctx, collection); err != nil {
+		tb.Fatal(err)
+	}
+	return collection
+}
@@ -0,0 +1,341 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/fwojciec/bookid"
+)
+
+// Ensure service implements interface.
+var _ bookid.ConflictService = (*ConflictService)(nil)
+
+// ConflictService represents a service for managing the queue of conflicts
+// awaiting review.
+type ConflictService struct {
+	db *DB
+}
+
+// NewConflictService returns a new instance of ConflictService.
+func NewConflictService(db *DB) *ConflictService {
+	return &ConflictService{db: db}
+}
+
+// FindConflictByID retrieves a single conflict by ID.
+// Returns ENOTFOUND if the conflict does not exist.
+func (s *ConflictService) FindConflictByID(ctx context.Context, id int64) (*bookid.Conflict, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+	return findConflictByID(ctx, tx, id)
+}
+
+// FindConflicts retrieves a list of conflicts matching the filter.
+func (s *ConflictService) FindConflicts(ctx context.Context, filter bookid.ConflictFilter) ([]*bookid.Conflict, int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer func() { _ = tx.Rollback() }()
+	return findConflicts(ctx, tx, filter)
+}
+
+// CreateConflict queues a conflict for review, unless the same value is
+// already proposed for the field.
+func (s *ConflictService) CreateConflict(ctx context.Context, conflict *bookid.Conflict) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := createConflict(ctx, tx, conflict); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// ResolveConflict closes an open conflict, applying the proposed value if
+// accept is set.
+func (s *ConflictService) ResolveConflict(ctx context.Context, id int64, accept bool) (*bookid.Conflict, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	conflict, err := resolveConflict(ctx, tx, id, accept)
+	if err != nil {
+		return conflict, err
+	} else if err := tx.Commit(); err != nil {
+		return conflict, err
+	}
+	return conflict, nil
+}
+
+// findConflictByID is a helper function to fetch a conflict by ID.
+// Returns ENOTFOUND if the conflict does not exist.
+func findConflictByID(ctx context.Context, tx *Tx, id int64) (*bookid.Conflict, error) {
+	conflicts, _, err := findConflicts(ctx, tx, bookid.ConflictFilter{ID: &id})
+	if err != nil {
+		return nil, err
+	} else if len(conflicts) == 0 {
+		return nil, bookid.Errorf(bookid.ENOTFOUND, "Conflict not found.")
+	}
+	return conflicts[0], nil
+}
+
+// findConflicts returns a list of conflicts matching a filter, oldest first.
+// Also returns a count of total matching conflicts which may differ if
+// filter.Limit is set.
+func findConflicts(ctx context.Context, tx *Tx, filter bookid.ConflictFilter) (_ []*bookid.Conflict, n int, err error) {
+	where, args := []string{"1 = 1"}, []any{}
+	if v := filter.ID; v != nil {
+		where, args = append(where, "id = ?"), append(args, *v)
+	}
+	if v := filter.PublicationID; v != nil {
+		where, args = append(where, "publication_id = ?"), append(args, *v)
+	}
+	if filter.Open {
+		where = append(where, "resolved_at IS NULL")
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT
+			id,
+			publication_id,
+			field,
+			current_value,
+			current_provider,
+			proposed_value,
+			proposed_provider,
+			created_at,
+			resolved_at,
+			resolution,
+			COUNT(*) OVER ()
+		FROM conflicts
+		WHERE `+strings.Join(where, " AND ")+`
+		ORDER BY id ASC
+		`+FormatLimitOffset(filter.Limit, filter.Offset),
+		args...,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	conflicts := make([]*bookid.Conflict, 0)
+	for rows.Next() {
+		var conflict bookid.Conflict
+		if err := rows.Scan(
+			&conflict.ID,
+			&conflict.PublicationID,
+			&conflict.Field,
+			&conflict.CurrentValue,
+			&conflict.CurrentProvider,
+			&conflict.ProposedValue,
+			&conflict.ProposedProvider,
+			(*NullTime)(&conflict.CreatedAt),
+			(*NullTime)(&conflict.ResolvedAt),
+			&conflict.Resolution,
+			&n,
+		); err != nil {
+			return nil, 0, err
+		}
+		conflicts = append(conflicts, &conflict)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+	return conflicts, n, nil
+}
+
+// createConflict queues a conflict. If the same value is already proposed
+// for the field by an open conflict, conflict is set to that one instead.
+func createConflict(ctx context.Context, tx *Tx, conflict *bookid.Conflict) error {
+	if err := conflict.Validate(); err != nil {
+		return err
+	} else if _, err := conflictUpdate(conflict.Field, conflict.ProposedValue, conflict.ProposedProvider); err != nil {
+		return err
+	} else if _, err := findPublicationByID(ctx, tx, conflict.PublicationID); err != nil {
+		return err
+	}
+
+	var id int64
+	if err := tx.QueryRowContext(ctx, `
+		SELECT id FROM conflicts
+		WHERE publication_id = ? AND field = ? AND proposed_value = ? AND resolved_at IS NULL
+	`, conflict.PublicationID, conflict.Field, conflict.ProposedValue).Scan(&id); err == nil {
+		other, err := findConflictByID(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		*conflict = *other
+		return nil
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+
+	conflict.CreatedAt = tx.now
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO conflicts (
+			publication_id,
+			field,
+			current_value,
+			current_provider,
+			proposed_value,
+			proposed_provider,
+			created_at
+		)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`,
+		conflict.PublicationID,
+		conflict.Field,
+		conflict.CurrentValue,
+		conflict.CurrentProvider,
+		conflict.ProposedValue,
+		conflict.ProposedProvider,
+		(*NullTime)(&conflict.CreatedAt),
+	)
+	if err != nil {
+		return FormatError(err)
+	}
+	conflict.ID, err = result.LastInsertId()
+	return err
+}
+
+// resolveConflict closes an open conflict, updating the publication with the
+// proposed value if accept is set. Returns the resolved conflict.
+func resolveConflict(ctx context.Context, tx *Tx, id int64, accept bool) (*bookid.Conflict, error) {
+	conflict, err := findConflictByID(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	} else if !conflict.ResolvedAt.IsZero() {
+		return conflict, bookid.Errorf(bookid.ECONFLICT, "Conflict is already %s.", conflict.Resolution)
+	}
+
+	conflict.Resolution = bookid.ConflictResolutionRejected
+	if accept {
+		upd, err := conflictUpdate(conflict.Field, conflict.ProposedValue, conflict.ProposedProvider)
+		if err != nil {
+			return conflict, err
+		} else if _, err := updatePublication(ctx, tx, conflict.PublicationID, upd); err != nil {
+			return conflict, err
+		}
+		conflict.Resolution = bookid.ConflictResolutionAccepted
+	}
+	conflict.ResolvedAt = tx.now
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE conflicts SET resolved_at = ?, resolution = ? WHERE id = ?
+	`, (*NullTime)(&conflict.ResolvedAt), conflict.Resolution, id); err != nil {
+		return conflict, FormatError(err)
+	}
+	return conflict, nil
+}
+
+// publicationValue returns the value of a publication's field by JSON name,
+// as conflicts record it.
+func publicationValue(pub *bookid.Publication, field string) string {
+	switch field {
+	case "isbn10":
+		return pub.ISBN10
+	case "isbn13":
+		return pub.ISBN13
+	case "publisher":
+		return pub.Publisher
+	case "published_year":
+		if pub.PublishedYear == 0 {
+			return ""
+		}
+		return strconv.Itoa(pub.PublishedYear)
+	case "language":
+		return pub.Language
+	case "binding":
+		return string(pub.Binding)
+	case "page_count":
+		if pub.PageCount == 0 {
+			return ""
+		}
+		return strconv.Itoa(pub.PageCount)
+	case "duration_minutes":
+		if pub.DurationMinutes == 0 {
+			return ""
+		}
+		return strconv.Itoa(pub.DurationMinutes)
+	case "oclc_number":
+		return pub.OCLCNumber
+	case "lccn":
+		return pub.LCCN
+	case "doi":
+		return pub.DOI
+	case "asin":
+		return pub.ASIN
+	case "thumbnail_url":
+		return pub.ThumbnailURL
+	case "description":
+		return pub.Description
+	default:
+		return ""
+	}
+}
+
+// conflictUpdate returns the update setting a publication's field, by JSON
+// name, to a value supplied by provider. Returns EINVALID if the field
+// cannot be reviewed.
+func conflictUpdate(field, value, provider string) (bookid.PublicationUpdate, error) {
+	upd := bookid.PublicationUpdate{Provenance: map[string]string{field: provider}}
+	switch field {
+	case "isbn10":
+		upd.ISBN10 = &value
+	case "isbn13":
+		upd.ISBN13 = &value
+	case "publisher":
+		upd.Publisher = &value
+	case "published_year":
+		year, err := strconv.Atoi(value)
+		if err != nil {
+			return upd, bookid.Errorf(bookid.EINVALID, "Invalid year %q.", value)
+		}
+		upd.PublishedYear = &year
+	case "language":
+		upd.Language = &value
+	case "binding":
+		upd.Binding = (*bookid.Binding)(&value)
+	case "page_count":
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return upd, bookid.Errorf(bookid.EINVALID, "Invalid page count %q.", value)
+		}
+		upd.PageCount = &n
+	case "duration_minutes":
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return upd, bookid.Errorf(bookid.EINVALID, "Invalid duration %q.", value)
+		}
+		upd.DurationMinutes = &n
+	case "oclc_number":
+		upd.OCLCNumber = &value
+	case "lccn":
+		upd.LCCN = &value
+	case "doi":
+		upd.DOI = &value
+	case "asin":
+		upd.ASIN = &value
+	case "thumbnail_url":
+		upd.ThumbnailURL = &value
+	case "description":
+		upd.Description = &value
+	default:
+		return upd, bookid.Errorf(bookid.EINVALID, "Conflicts of field %q cannot be reviewed.", field)
+	}
+	if provider == "" {
+		upd.Provenance = nil
+	}
+	return upd, nil
+}
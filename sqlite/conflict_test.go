@@ -0,0 +1,162 @@
+package sqlite_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/sqlite"
+)
+
+func TestConflictService_CreateConflict(t *testing.T) {
+	t.Parallel()
+
+	t.Run("OK", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewConflictService(db)
+		ctx := context.Background()
+
+		pub := mustCreateConflictingPublication(t, ctx, db)
+		conflict := &bookid.Conflict{
+			PublicationID:    pub.ID,
+			Field:            "publisher",
+			CurrentValue:     "Ace",
+			CurrentProvider:  "googlebooks",
+			ProposedValue:    "Chilton",
+			ProposedProvider: "openlibrary",
+		}
+		if err := s.CreateConflict(ctx, conflict); err != nil {
+			t.Fatal(err)
+		} else if got, want := conflict.ID, int64(1); got != want {
+			t.Fatalf("ID=%d, want %d", got, want)
+		} else if conflict.CreatedAt.IsZero() {
+			t.Fatal("expected created at")
+		}
+
+		if found, err := s.FindConflictByID(ctx, conflict.ID); err != nil {
+			t.Fatal(err)
+		} else if !reflect.DeepEqual(conflict, found) {
+			t.Fatalf("mismatch: %#v != %#v", conflict, found)
+		}
+
+		// The same proposal is not queued twice while open.
+		again := &bookid.Conflict{PublicationID: pub.ID, Field: "publisher", ProposedValue: "Chilton", ProposedProvider: "isbndb"}
+		if err := s.CreateConflict(ctx, again); err != nil {
+			t.Fatal(err)
+		} else if got, want := again.ID, conflict.ID; got != want {
+			t.Fatalf("ID=%d, want %d", got, want)
+		} else if _, n, err := s.FindConflicts(ctx, bookid.ConflictFilter{}); err != nil {
+			t.Fatal(err)
+		} else if n != 1 {
+			t.Fatalf("n=%d, want 1", n)
+		}
+	})
+
+	t.Run("ErrInvalid", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewConflictService(db)
+		ctx := context.Background()
+
+		pub := mustCreateConflictingPublication(t, ctx, db)
+		if err := s.CreateConflict(ctx, &bookid.Conflict{PublicationID: pub.ID, Field: "publisher"}); bookid.ErrorCode(err) != bookid.EINVALID {
+			t.Fatalf("ErrorCode()=%q, want %q", bookid.ErrorCode(err), bookid.EINVALID)
+		} else if err := s.CreateConflict(ctx, &bookid.Conflict{PublicationID: pub.ID, Field: "title", ProposedValue: "Dune"}); bookid.ErrorCode(err) != bookid.EINVALID {
+			t.Fatalf("ErrorCode()=%q, want %q", bookid.ErrorCode(err), bookid.EINVALID)
+		} else if err := s.CreateConflict(ctx, &bookid.Conflict{PublicationID: 99, Field: "publisher", ProposedValue: "Ace"}); bookid.ErrorCode(err) != bookid.ENOTFOUND {
+			t.Fatalf("ErrorCode()=%q, want %q", bookid.ErrorCode(err), bookid.ENOTFOUND)
+		}
+	})
+}
+
+func TestConflictService_ResolveConflict(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Accept", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewConflictService(db)
+		ctx := context.Background()
+
+		pub := mustCreateConflictingPublication(t, ctx, db)
+		conflict := &bookid.Conflict{PublicationID: pub.ID, Field: "published_year", ProposedValue: "1965", ProposedProvider: "openlibrary"}
+		if err := s.CreateConflict(ctx, conflict); err != nil {
+			t.Fatal(err)
+		}
+
+		if resolved, err := s.ResolveConflict(ctx, conflict.ID, true); err != nil {
+			t.Fatal(err)
+		} else if got, want := resolved.Resolution, bookid.ConflictResolutionAccepted; got != want {
+			t.Fatalf("Resolution=%q, want %q", got, want)
+		} else if resolved.ResolvedAt.IsZero() {
+			t.Fatal("expected resolved at")
+		}
+
+		if found, err := sqlite.NewPublicationService(db).FindPublicationByID(ctx, pub.ID); err != nil {
+			t.Fatal(err)
+		} else if got, want := found.PublishedYear, 1965; got != want {
+			t.Fatalf("PublishedYear=%d, want %d", got, want)
+		} else if got, want := found.Provenance["published_year"], "openlibrary"; got != want {
+			t.Fatalf("provider=%q, want %q", got, want)
+		}
+
+		if _, err := s.ResolveConflict(ctx, conflict.ID, false); bookid.ErrorCode(err) != bookid.ECONFLICT {
+			t.Fatalf("ErrorCode()=%q, want %q", bookid.ErrorCode(err), bookid.ECONFLICT)
+		}
+	})
+
+	t.Run("Reject", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewConflictService(db)
+		ctx := context.Background()
+
+		pub := mustCreateConflictingPublication(t, ctx, db)
+		conflict := &bookid.Conflict{PublicationID: pub.ID, Field: "publisher", ProposedValue: "Chilton", ProposedProvider: "openlibrary"}
+		if err := s.CreateConflict(ctx, conflict); err != nil {
+			t.Fatal(err)
+		} else if _, err := s.ResolveConflict(ctx, conflict.ID, false); err != nil {
+			t.Fatal(err)
+		}
+
+		if found, err := sqlite.NewPublicationService(db).FindPublicationByID(ctx, pub.ID); err != nil {
+			t.Fatal(err)
+		} else if got, want := found.Publisher, "Ace"; got != want {
+			t.Fatalf("Publisher=%q, want %q", got, want)
+		}
+		if open, _, err := s.FindConflicts(ctx, bookid.ConflictFilter{Open: true}); err != nil {
+			t.Fatal(err)
+		} else if len(open) != 0 {
+			t.Fatalf("unexpected open conflicts: %#v", open)
+		}
+	})
+
+	t.Run("ErrNotFound", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+
+		if _, err := sqlite.NewConflictService(db).ResolveConflict(context.Background(), 1, true); bookid.ErrorCode(err) != bookid.ENOTFOUND {
+			t.Fatalf("ErrorCode()=%q, want %q", bookid.ErrorCode(err), bookid.ENOTFOUND)
+		}
+	})
+}
+
+// mustCreateConflictingPublication creates a publication of Dune from Google
+// Books to queue conflicts for.
+func mustCreateConflictingPublication(tb testing.TB, ctx context.Context, db *sqlite.DB) *bookid.Publication {
+	tb.Helper()
+	work := MustCreateWork(tb, ctx, db, &bookid.Work{Title: "Dune"})
+	return MustCreatePublication(tb, ctx, db, &bookid.Publication{
+		WorkID:        work.ID,
+		Publisher:     "Ace",
+		PublishedYear: 1990,
+		Provenance:    map[string]string{"publisher": "googlebooks", "published_year": "googlebooks"},
+	})
+}
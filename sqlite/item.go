@@ -0,0 +1,266 @@
+package sqlite
+
+import (
+	"context"
+	"strings"
+
+	"github.com/fwojciec/bookid"
+)
+
+// Ensure service implements interface.
+var _ bookid.ItemService = (*ItemService)(nil)
+
+// ItemService represents a service for managing items.
+type ItemService struct {
+	db *DB
+}
+
+// NewItemService returns a new instance of ItemService.
+func NewItemService(db *DB) *ItemService {
+	return &ItemService{db: db}
+}
+
+// FindItemByID retrieves a single item by ID.
+// Returns ENOTFOUND if the item does not exist.
+func (s *ItemService) FindItemByID(ctx context.Context, id int64) (*bookid.Item, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+	return findItemByID(ctx, tx, id)
+}
+
+// FindItems retrieves a list of items matching the filter.
+func (s *ItemService) FindItems(ctx context.Context, filter bookid.ItemFilter) ([]*bookid.Item, int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer func() { _ = tx.Rollback() }()
+	return findItems(ctx, tx, filter)
+}
+
+// CreateItem creates a new item.
+func (s *ItemService) CreateItem(ctx context.Context, item *bookid.Item) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := createItem(ctx, tx, item); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// UpdateItem updates an existing item.
+// Returns ENOTFOUND if the item does not exist.
+func (s *ItemService) UpdateItem(ctx context.Context, id int64, upd bookid.ItemUpdate) (*bookid.Item, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	item, err := updateItem(ctx, tx, id, upd)
+	if err != nil {
+		return item, err
+	} else if err := tx.Commit(); err != nil {
+		return item, err
+	}
+	return item, nil
+}
+
+// DeleteItem permanently removes an item.
+// Returns ENOTFOUND if the item does not exist.
+func (s *ItemService) DeleteItem(ctx context.Context, id int64) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := deleteItem(ctx, tx, id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// findItemByID is a helper function to fetch an item by ID.
+// Returns ENOTFOUND if the item does not exist.
+func findItemByID(ctx context.Context, tx *Tx, id int64) (*bookid.Item, error) {
+	items, _, err := findItems(ctx, tx, bookid.ItemFilter{ID: &id})
+	if err != nil {
+		return nil, err
+	} else if len(items) == 0 {
+		return nil, bookid.Errorf(bookid.ENOTFOUND, "Item not found.")
+	}
+	return items[0], nil
+}
+
+// findItems returns a list of items matching a filter. Also returns a count
+// of total matching items which may differ if filter.Limit is set.
+func findItems(ctx context.Context, tx *Tx, filter bookid.ItemFilter) (_ []*bookid.Item, n int, err error) {
+	where, args := []string{"1 = 1"}, []any{}
+	if v := filter.ID; v != nil {
+		where, args = append(where, "id = ?"), append(args, *v)
+	}
+	if v := filter.PublicationID; v != nil {
+		where, args = append(where, "publication_id = ?"), append(args, *v)
+	}
+	if v := filter.Barcode; v != nil {
+		where, args = append(where, "barcode = ?"), append(args, strings.TrimSpace(*v))
+	}
+	if v := filter.LoanStatus; v != nil {
+		where, args = append(where, "loan_status = ?"), append(args, *v)
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT
+			id,
+			publication_id,
+			barcode,
+			condition,
+			location,
+			loan_status,
+			created_at,
+			updated_at,
+			COUNT(*) OVER ()
+		FROM items
+		WHERE `+strings.Join(where, " AND ")+`
+		ORDER BY id ASC
+		`+FormatLimitOffset(filter.Limit, filter.Offset),
+		args...,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	items := make([]*bookid.Item, 0)
+	for rows.Next() {
+		var item bookid.Item
+		if err := rows.Scan(
+			&item.ID,
+			&item.PublicationID,
+			&item.Barcode,
+			&item.Condition,
+			&item.Location,
+			&item.LoanStatus,
+			(*NullTime)(&item.CreatedAt),
+			(*NullTime)(&item.UpdatedAt),
+			&n,
+		); err != nil {
+			return nil, 0, err
+		}
+		items = append(items, &item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+	return items, n, nil
+}
+
+// createItem creates a new item. Sets the ID and timestamps on success.
+func createItem(ctx context.Context, tx *Tx, item *bookid.Item) error {
+	item.Barcode, item.Location = strings.TrimSpace(item.Barcode), strings.TrimSpace(item.Location)
+	if item.LoanStatus == "" {
+		item.LoanStatus = bookid.LoanStatusAvailable
+	}
+	if err := item.Validate(); err != nil {
+		return err
+	} else if _, err := findPublicationByID(ctx, tx, item.PublicationID); err != nil {
+		return err
+	}
+
+	item.CreatedAt = tx.now
+	item.UpdatedAt = item.CreatedAt
+
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO items (
+			publication_id,
+			barcode,
+			condition,
+			location,
+			loan_status,
+			created_at,
+			updated_at
+		)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`,
+		item.PublicationID,
+		item.Barcode,
+		item.Condition,
+		item.Location,
+		item.LoanStatus,
+		(*NullTime)(&item.CreatedAt),
+		(*NullTime)(&item.UpdatedAt),
+	)
+	if err != nil {
+		return FormatError(err)
+	}
+
+	if item.ID, err = result.LastInsertId(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// updateItem updates fields on an item by ID. Returns the updated item.
+func updateItem(ctx context.Context, tx *Tx, id int64, upd bookid.ItemUpdate) (*bookid.Item, error) {
+	item, err := findItemByID(ctx, tx, id)
+	if err != nil {
+		return item, err
+	}
+
+	if v := upd.Barcode; v != nil {
+		item.Barcode = strings.TrimSpace(*v)
+	}
+	if v := upd.Condition; v != nil {
+		item.Condition = *v
+	}
+	if v := upd.Location; v != nil {
+		item.Location = strings.TrimSpace(*v)
+	}
+	if v := upd.LoanStatus; v != nil {
+		item.LoanStatus = *v
+	}
+	item.UpdatedAt = tx.now
+
+	if err := item.Validate(); err != nil {
+		return item, err
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE items
+		SET barcode = ?,
+		    condition = ?,
+		    location = ?,
+		    loan_status = ?,
+		    updated_at = ?
+		WHERE id = ?
+	`,
+		item.Barcode,
+		item.Condition,
+		item.Location,
+		item.LoanStatus,
+		(*NullTime)(&item.UpdatedAt),
+		id,
+	); err != nil {
+		return item, FormatError(err)
+	}
+	return item, nil
+}
+
+// deleteItem permanently removes an item by ID.
+func deleteItem(ctx context.Context, tx *Tx, id int64) error {
+	if _, err := findItemByID(ctx, tx, id); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM items WHERE id = ?`, id); err != nil {
+		return FormatError(err)
+	}
+	return nil
+}
@@ -0,0 +1,168 @@
+package sqlite_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/sqlite"
+)
+
+func TestItemService_CreateItem(t *testing.T) {
+	t.Parallel()
+
+	t.Run("OK", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewItemService(db)
+		ctx := context.Background()
+
+		work := MustCreateWork(t, ctx, db, &bookid.Work{Title: "Dune"})
+		pub := MustCreatePublication(t, ctx, db, &bookid.Publication{WorkID: work.ID})
+
+		item := &bookid.Item{PublicationID: pub.ID, Barcode: " 31234000012345 ", Condition: bookid.ItemConditionGood}
+		if err := s.CreateItem(ctx, item); err != nil {
+			t.Fatal(err)
+		} else if got, want := item.ID, int64(1); got != want {
+			t.Fatalf("ID=%d, want %d", got, want)
+		} else if got, want := item.Barcode, "31234000012345"; got != want {
+			t.Fatalf("Barcode=%q, want %q", got, want)
+		} else if got, want := item.LoanStatus, bookid.LoanStatusAvailable; got != want {
+			t.Fatalf("LoanStatus=%q, want %q", got, want)
+		} else if item.CreatedAt.IsZero() || item.UpdatedAt.IsZero() {
+			t.Fatal("expected timestamps")
+		}
+
+		if found, err := s.FindItemByID(ctx, item.ID); err != nil {
+			t.Fatal(err)
+		} else if !reflect.DeepEqual(item, found) {
+			t.Fatalf("mismatch: %#v != %#v", item, found)
+		}
+	})
+
+	t.Run("ErrDuplicateBarcode", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewItemService(db)
+		ctx := context.Background()
+
+		work := MustCreateWork(t, ctx, db, &bookid.Work{Title: "Dune"})
+		pub := MustCreatePublication(t, ctx, db, &bookid.Publication{WorkID: work.ID})
+		MustCreateItem(t, ctx, db, &bookid.Item{PublicationID: pub.ID, Barcode: "A1"})
+
+		err := s.CreateItem(ctx, &bookid.Item{PublicationID: pub.ID, Barcode: "A1"})
+		if code := bookid.ErrorCode(err); code != bookid.ECONFLICT {
+			t.Fatalf("ErrorCode()=%q, want %q", code, bookid.ECONFLICT)
+		}
+	})
+
+	t.Run("EmptyBarcodesAreNotUnique", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		ctx := context.Background()
+
+		work := MustCreateWork(t, ctx, db, &bookid.Work{Title: "Dune"})
+		pub := MustCreatePublication(t, ctx, db, &bookid.Publication{WorkID: work.ID})
+		MustCreateItem(t, ctx, db, &bookid.Item{PublicationID: pub.ID})
+		MustCreateItem(t, ctx, db, &bookid.Item{PublicationID: pub.ID})
+
+		if _, n, err := sqlite.NewItemService(db).FindItems(ctx, bookid.ItemFilter{PublicationID: &pub.ID}); err != nil {
+			t.Fatal(err)
+		} else if got, want := n, 2; got != want {
+			t.Fatalf("n=%d, want %d", got, want)
+		}
+	})
+
+	t.Run("ErrPublicationNotFound", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+
+		err := sqlite.NewItemService(db).CreateItem(context.Background(), &bookid.Item{PublicationID: 100})
+		if code := bookid.ErrorCode(err); code != bookid.ENOTFOUND {
+			t.Fatalf("ErrorCode()=%q, want %q", code, bookid.ENOTFOUND)
+		}
+	})
+
+	t.Run("ErrInvalidCondition", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		ctx := context.Background()
+
+		work := MustCreateWork(t, ctx, db, &bookid.Work{Title: "Dune"})
+		pub := MustCreatePublication(t, ctx, db, &bookid.Publication{WorkID: work.ID})
+
+		err := sqlite.NewItemService(db).CreateItem(ctx, &bookid.Item{PublicationID: pub.ID, Condition: "mint"})
+		if code := bookid.ErrorCode(err); code != bookid.EINVALID {
+			t.Fatalf("ErrorCode()=%q, want %q", code, bookid.EINVALID)
+		}
+	})
+}
+
+func TestItemService_UpdateItem(t *testing.T) {
+	t.Parallel()
+
+	db := MustOpenDB(t)
+	defer MustCloseDB(t, db)
+	s := sqlite.NewItemService(db)
+	ctx := context.Background()
+
+	work := MustCreateWork(t, ctx, db, &bookid.Work{Title: "Dune"})
+	pub := MustCreatePublication(t, ctx, db, &bookid.Publication{WorkID: work.ID})
+	item := MustCreateItem(t, ctx, db, &bookid.Item{PublicationID: pub.ID, Barcode: "A1"})
+	MustCreateItem(t, ctx, db, &bookid.Item{PublicationID: pub.ID, Barcode: "A2"})
+
+	status := bookid.LoanStatusOnLoan
+	if updated, err := s.UpdateItem(ctx, item.ID, bookid.ItemUpdate{LoanStatus: &status, Location: ptr("B3")}); err != nil {
+		t.Fatal(err)
+	} else if got, want := updated.Location, "B3"; got != want {
+		t.Fatalf("Location=%q, want %q", got, want)
+	} else if got, want := updated.Barcode, "A1"; got != want {
+		t.Fatalf("Barcode=%q, want %q", got, want)
+	}
+
+	if items, _, err := s.FindItems(ctx, bookid.ItemFilter{LoanStatus: &status}); err != nil {
+		t.Fatal(err)
+	} else if len(items) != 1 || items[0].ID != item.ID {
+		t.Fatalf("unexpected items: %+v", items)
+	}
+
+	if _, err := s.UpdateItem(ctx, item.ID, bookid.ItemUpdate{Barcode: ptr("A2")}); bookid.ErrorCode(err) != bookid.ECONFLICT {
+		t.Fatalf("ErrorCode()=%q, want %q", bookid.ErrorCode(err), bookid.ECONFLICT)
+	}
+}
+
+func TestItemService_DeleteItem(t *testing.T) {
+	t.Parallel()
+
+	db := MustOpenDB(t)
+	defer MustCloseDB(t, db)
+	s := sqlite.NewItemService(db)
+	ctx := context.Background()
+
+	work := MustCreateWork(t, ctx, db, &bookid.Work{Title: "Dune"})
+	pub := MustCreatePublication(t, ctx, db, &bookid.Publication{WorkID: work.ID})
+	item := MustCreateItem(t, ctx, db, &bookid.Item{PublicationID: pub.ID})
+
+	if err := s.DeleteItem(ctx, item.ID); err != nil {
+		t.Fatal(err)
+	} else if _, err := s.FindItemByID(ctx, item.ID); bookid.ErrorCode(err) != bookid.ENOTFOUND {
+		t.Fatalf("ErrorCode()=%q, want %q", bookid.ErrorCode(err), bookid.ENOTFOUND)
+	} else if err := s.DeleteItem(ctx, item.ID); bookid.ErrorCode(err) != bookid.ENOTFOUND {
+		t.Fatalf("ErrorCode()=%q, want %q", bookid.ErrorCode(err), bookid.ENOTFOUND)
+	}
+}
+
+// MustCreateItem creates an item in the database. Fatal on error.
+func MustCreateItem(tb testing.TB, ctx context.Context, db *sqlite.DB, item *bookid.Item) *bookid.Item {
+	tb.Helper()
+	if err := sqlite.NewItemService(db).CreateItem(ctx, item); err != nil {
+		tb.Fatal(err)
+	}
+	return item
+}
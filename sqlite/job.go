@@ -0,0 +1,312 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+
+	"github.com/fwojciec/bookid"
+)
+
+// Ensure service implements interface.
+var _ bookid.JobService = (*JobService)(nil)
+
+// JobService represents a service for managing jobs.
+type JobService struct {
+	db *DB
+}
+
+// NewJobService returns a new instance of JobService.
+func NewJobService(db *DB) *JobService {
+	return &JobService{db: db}
+}
+
+// FindJobByID retrieves a single job by ID.
+// Returns ENOTFOUND if the job does not exist.
+func (s *JobService) FindJobByID(ctx context.Context, id int64) (*bookid.Job, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+	return findJobByID(ctx, tx, id)
+}
+
+// FindJobs retrieves a list of jobs matching the filter.
+func (s *JobService) FindJobs(ctx context.Context, filter bookid.JobFilter) ([]*bookid.Job, int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer func() { _ = tx.Rollback() }()
+	return findJobs(ctx, tx, filter)
+}
+
+// CreateJob adds a job to the queue.
+func (s *JobService) CreateJob(ctx context.Context, job *bookid.Job) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := createJob(ctx, tx, job); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// ClaimJob marks the next pending job as running and returns it.
+// Returns ENOTFOUND if no job is pending.
+func (s *JobService) ClaimJob(ctx context.Context) (*bookid.Job, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	job, err := claimJob(ctx, tx)
+	if err != nil {
+		return job, err
+	} else if err := tx.Commit(); err != nil {
+		return job, err
+	}
+	return job, nil
+}
+
+// UpdateJob records the progress or outcome of a job.
+// Returns ENOTFOUND if the job does not exist.
+func (s *JobService) UpdateJob(ctx context.Context, id int64, upd bookid.JobUpdate) (*bookid.Job, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	job, err := updateJob(ctx, tx, id, upd)
+	if err != nil {
+		return job, err
+	} else if err := tx.Commit(); err != nil {
+		return job, err
+	}
+	return job, nil
+}
+
+// CancelJob cancels a pending job.
+// Returns ENOTFOUND if the job does not exist.
+func (s *JobService) CancelJob(ctx context.Context, id int64) (*bookid.Job, error) {
+	state := bookid.JobStateCanceled
+	return s.UpdateJob(ctx, id, bookid.JobUpdate{State: &state})
+}
+
+// findJobByID is a helper function to fetch a job by ID.
+// Returns ENOTFOUND if the job does not exist.
+func findJobByID(ctx context.Context, tx *Tx, id int64) (*bookid.Job, error) {
+	jobs, _, err := findJobs(ctx, tx, bookid.JobFilter{ID: &id})
+	if err != nil {
+		return nil, err
+	} else if len(jobs) == 0 {
+		return nil, bookid.Errorf(bookid.ENOTFOUND, "Job not found.")
+	}
+	return jobs[0], nil
+}
+
+// findJobs returns a list of jobs matching a filter, newest first. Also
+// returns a count of total matching jobs which may differ if filter.Limit is
+// set.
+func findJobs(ctx context.Context, tx *Tx, filter bookid.JobFilter) (_ []*bookid.Job, n int, err error) {
+	where, args := []string{"1 = 1"}, []any{}
+	if v := filter.ID; v != nil {
+		where, args = append(where, "id = ?"), append(args, *v)
+	}
+	if v := filter.Kind; v != nil {
+		where, args = append(where, "kind = ?"), append(args, *v)
+	}
+	if v := filter.State; v != nil {
+		where, args = append(where, "state = ?"), append(args, *v)
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT
+			id,
+			kind,
+			params,
+			state,
+			done,
+			total,
+			result,
+			error,
+			created_at,
+			updated_at,
+			started_at,
+			finished_at,
+			COUNT(*) OVER ()
+		FROM jobs
+		WHERE `+strings.Join(where, " AND ")+`
+		ORDER BY id DESC
+		`+FormatLimitOffset(filter.Limit, filter.Offset),
+		args...,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	jobs := make([]*bookid.Job, 0)
+	for rows.Next() {
+		var job bookid.Job
+		var params, result string
+		if err := rows.Scan(
+			&job.ID,
+			&job.Kind,
+			&params,
+			&job.State,
+			&job.Done,
+			&job.Total,
+			&result,
+			&job.Error,
+			(*NullTime)(&job.CreatedAt),
+			(*NullTime)(&job.UpdatedAt),
+			(*NullTime)(&job.StartedAt),
+			(*NullTime)(&job.FinishedAt),
+			&n,
+		); err != nil {
+			return nil, 0, err
+		}
+		if params != "" {
+			job.Params = []byte(params)
+		}
+		if result != "" {
+			job.Result = []byte(result)
+		}
+		jobs = append(jobs, &job)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+	return jobs, n, nil
+}
+
+// createJob adds a queued job. Sets the ID, state and timestamps on success.
+func createJob(ctx context.Context, tx *Tx, job *bookid.Job) error {
+	job.State = bookid.JobStateQueued
+	job.Done, job.Total = 0, 0
+	job.Result, job.Error = nil, ""
+	job.CreatedAt = tx.now
+	job.UpdatedAt = job.CreatedAt
+	if err := job.Validate(); err != nil {
+		return err
+	}
+
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO jobs (kind, params, state, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?)
+	`,
+		job.Kind,
+		string(job.Params),
+		job.State,
+		(*NullTime)(&job.CreatedAt),
+		(*NullTime)(&job.UpdatedAt),
+	)
+	if err != nil {
+		return FormatError(err)
+	}
+
+	if job.ID, err = result.LastInsertId(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// claimJob marks the next pending job as running: the oldest running job
+// left by an interrupted process, or else the oldest queued job.
+func claimJob(ctx context.Context, tx *Tx) (*bookid.Job, error) {
+	var id int64
+	if err := tx.QueryRowContext(ctx, `
+		SELECT id
+		FROM jobs
+		WHERE state IN (?, ?)
+		ORDER BY state = ? DESC, id ASC
+		LIMIT 1
+	`, bookid.JobStateQueued, bookid.JobStateRunning, bookid.JobStateRunning).Scan(&id); errors.Is(err, sql.ErrNoRows) {
+		return nil, bookid.Errorf(bookid.ENOTFOUND, "No job pending.")
+	} else if err != nil {
+		return nil, err
+	}
+
+	job, err := findJobByID(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+	job.State = bookid.JobStateRunning
+	if job.StartedAt.IsZero() {
+		job.StartedAt = tx.now
+	}
+	job.UpdatedAt = tx.now
+	return job, saveJob(ctx, tx, job)
+}
+
+// updateJob updates fields on a pending job by ID. Returns the updated job.
+func updateJob(ctx context.Context, tx *Tx, id int64, upd bookid.JobUpdate) (*bookid.Job, error) {
+	job, err := findJobByID(ctx, tx, id)
+	if err != nil {
+		return job, err
+	} else if job.State.Finished() {
+		return job, bookid.Errorf(bookid.ECONFLICT, "Job is already %s.", job.State)
+	}
+
+	if v := upd.State; v != nil {
+		job.State = *v
+		if job.State.Finished() {
+			job.FinishedAt = tx.now
+		}
+	}
+	if v := upd.Done; v != nil {
+		job.Done = *v
+	}
+	if v := upd.Total; v != nil {
+		job.Total = *v
+	}
+	if v := upd.Result; v != nil {
+		job.Result = v
+	}
+	if v := upd.Error; v != nil {
+		job.Error = *v
+	}
+	job.UpdatedAt = tx.now
+
+	if err := job.Validate(); err != nil {
+		return job, err
+	}
+	return job, saveJob(ctx, tx, job)
+}
+
+// saveJob writes every mutable field of job to its row.
+func saveJob(ctx context.Context, tx *Tx, job *bookid.Job) error {
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE jobs
+		SET state = ?,
+		    done = ?,
+		    total = ?,
+		    result = ?,
+		    error = ?,
+		    updated_at = ?,
+		    started_at = ?,
+		    finished_at = ?
+		WHERE id = ?
+	`,
+		job.State,
+		job.Done,
+		job.Total,
+		string(job.Result),
+		job.Error,
+		(*NullTime)(&job.UpdatedAt),
+		(*NullTime)(&job.StartedAt),
+		(*NullTime)(&job.FinishedAt),
+		job.ID,
+	); err != nil {
+		return FormatError(err)
+	}
+	return nil
+}
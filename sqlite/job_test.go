@@ -0,0 +1,162 @@
+package sqlite_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/sqlite"
+)
+
+func TestJobService_CreateJob(t *testing.T) {
+	t.Parallel()
+
+	t.Run("OK", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewJobService(db)
+		ctx := context.Background()
+
+		job := &bookid.Job{Kind: bookid.JobKindImport, Params: json.RawMessage(`{"path":"books.csv"}`)}
+		if err := s.CreateJob(ctx, job); err != nil {
+			t.Fatal(err)
+		} else if got, want := job.ID, int64(1); got != want {
+			t.Fatalf("ID=%d, want %d", got, want)
+		} else if got, want := job.State, bookid.JobStateQueued; got != want {
+			t.Fatalf("State=%q, want %q", got, want)
+		}
+
+		if found, err := s.FindJobByID(ctx, job.ID); err != nil {
+			t.Fatal(err)
+		} else if got, want := string(found.Params), `{"path":"books.csv"}`; got != want {
+			t.Fatalf("Params=%s, want %s", got, want)
+		} else if found.Result != nil {
+			t.Fatalf("Result=%s, want nil", found.Result)
+		}
+	})
+
+	t.Run("ErrKindRequired", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+
+		err := sqlite.NewJobService(db).CreateJob(context.Background(), &bookid.Job{})
+		if code := bookid.ErrorCode(err); code != bookid.EINVALID {
+			t.Fatalf("ErrorCode()=%q, want %q", code, bookid.EINVALID)
+		}
+	})
+}
+
+func TestJobService_ClaimJob(t *testing.T) {
+	t.Parallel()
+
+	db := MustOpenDB(t)
+	defer MustCloseDB(t, db)
+	s := sqlite.NewJobService(db)
+	ctx := context.Background()
+
+	first := MustCreateJob(t, ctx, db, bookid.JobKindImport)
+	second := MustCreateJob(t, ctx, db, bookid.JobKindCovers)
+
+	if job, err := s.ClaimJob(ctx); err != nil {
+		t.Fatal(err)
+	} else if job.ID != first.ID || job.State != bookid.JobStateRunning || job.StartedAt.IsZero() {
+		t.Fatalf("unexpected job: %+v", job)
+	}
+
+	// The running job, left by an interrupted process, is claimed again
+	// before the queued one.
+	if job, err := s.ClaimJob(ctx); err != nil {
+		t.Fatal(err)
+	} else if got, want := job.ID, first.ID; got != want {
+		t.Fatalf("ID=%d, want %d", got, want)
+	}
+
+	state := bookid.JobStateDone
+	if _, err := s.UpdateJob(ctx, first.ID, bookid.JobUpdate{State: &state}); err != nil {
+		t.Fatal(err)
+	} else if job, err := s.ClaimJob(ctx); err != nil {
+		t.Fatal(err)
+	} else if got, want := job.ID, second.ID; got != want {
+		t.Fatalf("ID=%d, want %d", got, want)
+	}
+
+	if _, err := s.UpdateJob(ctx, second.ID, bookid.JobUpdate{State: &state}); err != nil {
+		t.Fatal(err)
+	} else if _, err := s.ClaimJob(ctx); bookid.ErrorCode(err) != bookid.ENOTFOUND {
+		t.Fatalf("ErrorCode()=%q, want %q", bookid.ErrorCode(err), bookid.ENOTFOUND)
+	}
+}
+
+func TestJobService_UpdateJob(t *testing.T) {
+	t.Parallel()
+
+	db := MustOpenDB(t)
+	defer MustCloseDB(t, db)
+	s := sqlite.NewJobService(db)
+	ctx := context.Background()
+
+	job := MustCreateJob(t, ctx, db, bookid.JobKindRefresh)
+	state := bookid.JobStateDone
+	updated, err := s.UpdateJob(ctx, job.ID, bookid.JobUpdate{
+		State:  &state,
+		Done:   ptr(10),
+		Total:  ptr(10),
+		Result: json.RawMessage(`{"refreshed":10}`),
+	})
+	if err != nil {
+		t.Fatal(err)
+	} else if updated.FinishedAt.IsZero() {
+		t.Fatal("expected finish time")
+	}
+
+	if found, err := s.FindJobByID(ctx, job.ID); err != nil {
+		t.Fatal(err)
+	} else if found.Done != 10 || found.Total != 10 || string(found.Result) != `{"refreshed":10}` {
+		t.Fatalf("unexpected job: %+v", found)
+	}
+
+	// Finished jobs cannot be changed or canceled.
+	if _, err := s.UpdateJob(ctx, job.ID, bookid.JobUpdate{Done: ptr(11)}); bookid.ErrorCode(err) != bookid.ECONFLICT {
+		t.Fatalf("ErrorCode()=%q, want %q", bookid.ErrorCode(err), bookid.ECONFLICT)
+	} else if _, err := s.CancelJob(ctx, job.ID); bookid.ErrorCode(err) != bookid.ECONFLICT {
+		t.Fatalf("ErrorCode()=%q, want %q", bookid.ErrorCode(err), bookid.ECONFLICT)
+	}
+}
+
+func TestJobService_CancelJob(t *testing.T) {
+	t.Parallel()
+
+	db := MustOpenDB(t)
+	defer MustCloseDB(t, db)
+	s := sqlite.NewJobService(db)
+	ctx := context.Background()
+
+	job := MustCreateJob(t, ctx, db, bookid.JobKindCovers)
+	if canceled, err := s.CancelJob(ctx, job.ID); err != nil {
+		t.Fatal(err)
+	} else if got, want := canceled.State, bookid.JobStateCanceled; got != want {
+		t.Fatalf("State=%q, want %q", got, want)
+	}
+
+	state := bookid.JobStateCanceled
+	if jobs, _, err := s.FindJobs(ctx, bookid.JobFilter{State: &state}); err != nil {
+		t.Fatal(err)
+	} else if len(jobs) != 1 {
+		t.Fatalf("len=%d, want 1", len(jobs))
+	} else if _, err := s.ClaimJob(ctx); bookid.ErrorCode(err) != bookid.ENOTFOUND {
+		t.Fatalf("ErrorCode()=%q, want %q", bookid.ErrorCode(err), bookid.ENOTFOUND)
+	}
+}
+
+// MustCreateJob queues a job of a kind in the database. Fatal on error.
+func MustCreateJob(tb testing.TB, ctx context.Context, db *sqlite.DB, kind bookid.JobKind) *bookid.Job {
+	tb.Helper()
+	job := &bookid.Job{Kind: kind}
+	if err := sqlite.NewJobService(db).CreateJob(ctx, job); err != nil {
+		tb.Fatal(err)
+	}
+	return job
+}
@@ -0,0 +1,208 @@
+package sqlite
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/fwojciec/bookid"
+)
+
+// Ensure service implements interface.
+var _ bookid.LoanService = (*LoanService)(nil)
+
+// LoanService represents a service for managing loans.
+type LoanService struct {
+	db *DB
+}
+
+// NewLoanService returns a new instance of LoanService.
+func NewLoanService(db *DB) *LoanService {
+	return &LoanService{db: db}
+}
+
+// FindLoanByID retrieves a single loan by ID.
+// Returns ENOTFOUND if the loan does not exist.
+func (s *LoanService) FindLoanByID(ctx context.Context, id int64) (*bookid.Loan, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+	return findLoanByID(ctx, tx, id)
+}
+
+// FindLoans retrieves a list of loans matching the filter.
+func (s *LoanService) FindLoans(ctx context.Context, filter bookid.LoanFilter) ([]*bookid.Loan, int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer func() { _ = tx.Rollback() }()
+	return findLoans(ctx, tx, filter)
+}
+
+// CheckoutItem lends an item to a borrower.
+func (s *LoanService) CheckoutItem(ctx context.Context, loan *bookid.Loan) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := checkoutItem(ctx, tx, loan); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// ReturnItem ends the open loan of an item.
+// Returns ENOTFOUND if the item is not on loan.
+func (s *LoanService) ReturnItem(ctx context.Context, itemID int64) (*bookid.Loan, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	loan, err := returnItem(ctx, tx, itemID)
+	if err != nil {
+		return loan, err
+	} else if err := tx.Commit(); err != nil {
+		return loan, err
+	}
+	return loan, nil
+}
+
+// findLoanByID is a helper function to fetch a loan by ID.
+// Returns ENOTFOUND if the loan does not exist.
+func findLoanByID(ctx context.Context, tx *Tx, id int64) (*bookid.Loan, error) {
+	loans, _, err := findLoans(ctx, tx, bookid.LoanFilter{ID: &id})
+	if err != nil {
+		return nil, err
+	} else if len(loans) == 0 {
+		return nil, bookid.Errorf(bookid.ENOTFOUND, "Loan not found.")
+	}
+	return loans[0], nil
+}
+
+// findLoans returns a list of loans matching a filter, ordered by when they
+// were made. Also returns a count of total matching loans which may differ if
+// filter.Limit is set.
+func findLoans(ctx context.Context, tx *Tx, filter bookid.LoanFilter) (_ []*bookid.Loan, n int, err error) {
+	where, args := []string{"1 = 1"}, []any{}
+	if v := filter.ID; v != nil {
+		where, args = append(where, "id = ?"), append(args, *v)
+	}
+	if v := filter.ItemID; v != nil {
+		where, args = append(where, "item_id = ?"), append(args, *v)
+	}
+	if v := filter.Borrower; v != nil {
+		where, args = append(where, "borrower = ?"), append(args, strings.TrimSpace(*v))
+	}
+	if filter.Open || filter.Overdue {
+		where = append(where, "returned_at IS NULL")
+	}
+	if filter.Overdue {
+		where, args = append(where, "due_at < ?"), append(args, (*NullTime)(&tx.now))
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id, item_id, borrower, loaned_at, due_at, returned_at, COUNT(*) OVER ()
+		FROM loans
+		WHERE `+strings.Join(where, " AND ")+`
+		ORDER BY loaned_at ASC, id ASC
+		`+FormatLimitOffset(filter.Limit, filter.Offset),
+		args...,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	loans := make([]*bookid.Loan, 0)
+	for rows.Next() {
+		var loan bookid.Loan
+		if err := rows.Scan(
+			&loan.ID,
+			&loan.ItemID,
+			&loan.Borrower,
+			(*NullTime)(&loan.LoanedAt),
+			(*NullTime)(&loan.DueAt),
+			(*NullTime)(&loan.ReturnedAt),
+			&n,
+		); err != nil {
+			return nil, 0, err
+		}
+		loans = append(loans, &loan)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+	return loans, n, nil
+}
+
+// checkoutItem creates an open loan for an available item and marks the item
+// as on loan. Sets the ID and loan time on success.
+func checkoutItem(ctx context.Context, tx *Tx, loan *bookid.Loan) error {
+	loan.Borrower = strings.Join(strings.Fields(loan.Borrower), " ")
+	if err := loan.Validate(); err != nil {
+		return err
+	}
+
+	item, err := findItemByID(ctx, tx, loan.ItemID)
+	if err != nil {
+		return err
+	} else if item.LoanStatus != bookid.LoanStatusAvailable {
+		return bookid.Errorf(bookid.ECONFLICT, "Item is not available (%s).", item.LoanStatus)
+	}
+
+	loan.LoanedAt = tx.now
+	loan.ReturnedAt = time.Time{}
+	if !loan.DueAt.IsZero() {
+		loan.DueAt = loan.DueAt.UTC().Truncate(time.Second)
+	}
+
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO loans (item_id, borrower, loaned_at, due_at)
+		VALUES (?, ?, ?, ?)
+	`,
+		loan.ItemID,
+		loan.Borrower,
+		(*NullTime)(&loan.LoanedAt),
+		(*NullTime)(&loan.DueAt),
+	)
+	if err != nil {
+		return FormatError(err)
+	}
+	if loan.ID, err = result.LastInsertId(); err != nil {
+		return err
+	}
+
+	status := bookid.LoanStatusOnLoan
+	_, err = updateItem(ctx, tx, item.ID, bookid.ItemUpdate{LoanStatus: &status})
+	return err
+}
+
+// returnItem closes the open loan of an item and marks the item as
+// available. Returns the returned loan.
+func returnItem(ctx context.Context, tx *Tx, itemID int64) (*bookid.Loan, error) {
+	loans, _, err := findLoans(ctx, tx, bookid.LoanFilter{ItemID: &itemID, Open: true})
+	if err != nil {
+		return nil, err
+	} else if len(loans) == 0 {
+		return nil, bookid.Errorf(bookid.ENOTFOUND, "Item is not on loan.")
+	}
+	loan := loans[0]
+	loan.ReturnedAt = tx.now
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE loans SET returned_at = ? WHERE id = ?
+	`, (*NullTime)(&loan.ReturnedAt), loan.ID); err != nil {
+		return loan, FormatError(err)
+	}
+
+	status := bookid.LoanStatusAvailable
+	_, err = updateItem(ctx, tx, itemID, bookid.ItemUpdate{LoanStatus: &status})
+	return loan, err
+}
@@ -0,0 +1,176 @@
+package sqlite_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/sqlite"
+)
+
+func TestLoanService_CheckoutItem(t *testing.T) {
+	t.Parallel()
+
+	t.Run("OK", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewLoanService(db)
+		ctx := context.Background()
+
+		item := mustCreateLoanableItem(t, ctx, db)
+		due := time.Now().Add(14 * 24 * time.Hour)
+		loan := &bookid.Loan{ItemID: item.ID, Borrower: "  Ada  Lovelace ", DueAt: due}
+		if err := s.CheckoutItem(ctx, loan); err != nil {
+			t.Fatal(err)
+		} else if got, want := loan.ID, int64(1); got != want {
+			t.Fatalf("ID=%d, want %d", got, want)
+		} else if got, want := loan.Borrower, "Ada Lovelace"; got != want {
+			t.Fatalf("Borrower=%q, want %q", got, want)
+		} else if loan.LoanedAt.IsZero() {
+			t.Fatal("expected loan time")
+		}
+
+		if found, err := s.FindLoanByID(ctx, loan.ID); err != nil {
+			t.Fatal(err)
+		} else if !found.DueAt.Equal(due.Truncate(time.Second)) {
+			t.Fatalf("DueAt=%v, want %v", found.DueAt, due)
+		} else if !found.ReturnedAt.IsZero() {
+			t.Fatalf("ReturnedAt=%v, want zero", found.ReturnedAt)
+		}
+
+		if item, err := sqlite.NewItemService(db).FindItemByID(ctx, item.ID); err != nil {
+			t.Fatal(err)
+		} else if got, want := item.LoanStatus, bookid.LoanStatusOnLoan; got != want {
+			t.Fatalf("LoanStatus=%q, want %q", got, want)
+		}
+	})
+
+	t.Run("ErrOnLoan", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewLoanService(db)
+		ctx := context.Background()
+
+		item := mustCreateLoanableItem(t, ctx, db)
+		if err := s.CheckoutItem(ctx, &bookid.Loan{ItemID: item.ID, Borrower: "Ada"}); err != nil {
+			t.Fatal(err)
+		}
+		err := s.CheckoutItem(ctx, &bookid.Loan{ItemID: item.ID, Borrower: "Grace"})
+		if code := bookid.ErrorCode(err); code != bookid.ECONFLICT {
+			t.Fatalf("ErrorCode()=%q, want %q", code, bookid.ECONFLICT)
+		}
+	})
+
+	t.Run("ErrItemNotFound", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+
+		err := sqlite.NewLoanService(db).CheckoutItem(context.Background(), &bookid.Loan{ItemID: 100, Borrower: "Ada"})
+		if code := bookid.ErrorCode(err); code != bookid.ENOTFOUND {
+			t.Fatalf("ErrorCode()=%q, want %q", code, bookid.ENOTFOUND)
+		}
+	})
+
+	t.Run("ErrBorrowerRequired", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		ctx := context.Background()
+
+		item := mustCreateLoanableItem(t, ctx, db)
+		err := sqlite.NewLoanService(db).CheckoutItem(ctx, &bookid.Loan{ItemID: item.ID, Borrower: " "})
+		if code := bookid.ErrorCode(err); code != bookid.EINVALID {
+			t.Fatalf("ErrorCode()=%q, want %q", code, bookid.EINVALID)
+		}
+	})
+}
+
+func TestLoanService_ReturnItem(t *testing.T) {
+	t.Parallel()
+
+	db := MustOpenDB(t)
+	defer MustCloseDB(t, db)
+	s := sqlite.NewLoanService(db)
+	ctx := context.Background()
+
+	item := mustCreateLoanableItem(t, ctx, db)
+	if err := s.CheckoutItem(ctx, &bookid.Loan{ItemID: item.ID, Borrower: "Ada"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if loan, err := s.ReturnItem(ctx, item.ID); err != nil {
+		t.Fatal(err)
+	} else if loan.ReturnedAt.IsZero() {
+		t.Fatal("expected return time")
+	} else if _, err := s.ReturnItem(ctx, item.ID); bookid.ErrorCode(err) != bookid.ENOTFOUND {
+		t.Fatalf("ErrorCode()=%q, want %q", bookid.ErrorCode(err), bookid.ENOTFOUND)
+	}
+
+	// The item can be checked out again once returned.
+	if err := s.CheckoutItem(ctx, &bookid.Loan{ItemID: item.ID, Borrower: "Grace"}); err != nil {
+		t.Fatal(err)
+	} else if _, n, err := s.FindLoans(ctx, bookid.LoanFilter{ItemID: &item.ID}); err != nil {
+		t.Fatal(err)
+	} else if got, want := n, 2; got != want {
+		t.Fatalf("n=%d, want %d", got, want)
+	}
+}
+
+func TestLoanService_FindLoans(t *testing.T) {
+	t.Parallel()
+
+	db := MustOpenDB(t)
+	defer MustCloseDB(t, db)
+	s := sqlite.NewLoanService(db)
+	ctx := context.Background()
+
+	late := mustCreateLoanableItem(t, ctx, db)
+	onTime := mustCreateLoanableItem(t, ctx, db)
+	returned := mustCreateLoanableItem(t, ctx, db)
+	for _, loan := range []*bookid.Loan{
+		{ItemID: late.ID, Borrower: "Ada", DueAt: time.Now().Add(24 * time.Hour)},
+		{ItemID: onTime.ID, Borrower: "ada", DueAt: time.Now().Add(30 * 24 * time.Hour)},
+		{ItemID: returned.ID, Borrower: "Grace", DueAt: time.Now().Add(24 * time.Hour)},
+	} {
+		if err := s.CheckoutItem(ctx, loan); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := s.ReturnItem(ctx, returned.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	// A week later, only the loan due after a day is overdue.
+	db.Now = func() time.Time { return time.Now().Add(7 * 24 * time.Hour) }
+	if loans, _, err := s.FindLoans(ctx, bookid.LoanFilter{Overdue: true}); err != nil {
+		t.Fatal(err)
+	} else if len(loans) != 1 || loans[0].ItemID != late.ID {
+		t.Fatalf("unexpected loans: %+v", loans)
+	} else if !loans[0].Overdue(db.Now()) {
+		t.Fatal("expected loan to be overdue")
+	}
+
+	if _, n, err := s.FindLoans(ctx, bookid.LoanFilter{Open: true}); err != nil {
+		t.Fatal(err)
+	} else if got, want := n, 2; got != want {
+		t.Fatalf("n=%d, want %d", got, want)
+	}
+
+	if _, n, err := s.FindLoans(ctx, bookid.LoanFilter{Borrower: ptr("ADA")}); err != nil {
+		t.Fatal(err)
+	} else if got, want := n, 2; got != want {
+		t.Fatalf("n=%d, want %d", got, want)
+	}
+}
+
+// mustCreateLoanableItem creates an item of a new publication. Fatal on error.
+func mustCreateLoanableItem(tb testing.TB, ctx context.Context, db *sqlite.DB) *bookid.Item {
+	tb.Helper()
+	work := MustCreateWork(tb, ctx, db, &bookid.Work{Title: "Dune"})
+	pub := MustCreatePublication(tb, ctx, db, &bookid.Publication{WorkID: work.ID})
+	return MustCreateItem(tb, ctx, db, &bookid.Item{PublicationID: pub.ID})
+}
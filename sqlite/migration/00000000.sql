@@ -1,14 +1,7 @@
 -- Initial migration
--- This file exists to demonstrate the migration pattern.
 -- The migrations table is created automatically by the migration system.
-
--- Add your initial schema here when ready.
--- For example:
--- CREATE TABLE books (
---     id INTEGER PRIMARY KEY AUTOINCREMENT,
---     title TEXT NOT NULL,
---     author TEXT NOT NULL,
---     isbn TEXT,
---     created_at DATETIME NOT NULL,
---     updated_at DATETIME NOT NULL
--- );
\ No newline at end of file
+--
+-- Schema changes are added as new files named with the next eight digit
+-- sequence number (e.g. 00000005.sql). Files run once, in lexicographical
+-- order, each inside its own transaction. Never edit a migration that has
+-- been released; add a new one instead.
@@ -0,0 +1,36 @@
+CREATE TABLE works (
+	id         INTEGER PRIMARY KEY AUTOINCREMENT,
+	title      TEXT NOT NULL,
+	author     TEXT NOT NULL DEFAULT '',
+	created_at TEXT NOT NULL,
+	updated_at TEXT NOT NULL
+);
+
+CREATE TABLE publications (
+	id                     INTEGER PRIMARY KEY AUTOINCREMENT,
+	work_id                INTEGER NOT NULL REFERENCES works (id) ON DELETE CASCADE,
+	isbn10                 TEXT NOT NULL DEFAULT '',
+	isbn13                 TEXT NOT NULL DEFAULT '',
+	publisher              TEXT NOT NULL DEFAULT '',
+	published_year         INTEGER NOT NULL DEFAULT 0,
+	language               TEXT NOT NULL DEFAULT '',
+	google_books_volume_id TEXT NOT NULL DEFAULT '',
+	thumbnail_url          TEXT NOT NULL DEFAULT '',
+	google_books_data      TEXT NOT NULL DEFAULT '',
+	created_at             TEXT NOT NULL,
+	updated_at             TEXT NOT NULL
+);
+
+CREATE INDEX publications_work_id_idx ON publications (work_id);
+
+CREATE TABLE work_relations (
+	id              INTEGER PRIMARY KEY AUTOINCREMENT,
+	work_id         INTEGER NOT NULL REFERENCES works (id) ON DELETE CASCADE,
+	related_work_id INTEGER NOT NULL REFERENCES works (id) ON DELETE CASCADE,
+	type            TEXT NOT NULL,
+	created_at      TEXT NOT NULL,
+
+	UNIQUE (work_id, related_work_id, type)
+);
+
+CREATE INDEX work_relations_related_work_id_idx ON work_relations (related_work_id);
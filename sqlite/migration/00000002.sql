@@ -0,0 +1,2 @@
+CREATE INDEX works_title_idx ON works (title COLLATE NOCASE);
+CREATE INDEX works_author_idx ON works (author COLLATE NOCASE);
@@ -0,0 +1,14 @@
+CREATE TABLE authors (
+	id       INTEGER PRIMARY KEY AUTOINCREMENT,
+	name     TEXT NOT NULL,
+	name_key TEXT NOT NULL UNIQUE
+);
+
+CREATE TABLE work_authors (
+	work_id   INTEGER NOT NULL REFERENCES works (id) ON DELETE CASCADE,
+	author_id INTEGER NOT NULL REFERENCES authors (id) ON DELETE CASCADE,
+
+	PRIMARY KEY (work_id, author_id)
+);
+
+CREATE INDEX work_authors_author_id_idx ON work_authors (author_id);
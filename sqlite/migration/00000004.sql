@@ -0,0 +1,4 @@
+CREATE UNIQUE INDEX publications_isbn13_idx ON publications (isbn13) WHERE isbn13 <> '';
+CREATE UNIQUE INDEX publications_google_books_volume_id_idx ON publications (google_books_volume_id) WHERE google_books_volume_id <> '';
+CREATE INDEX publications_isbn10_idx ON publications (isbn10);
+CREATE INDEX publications_publisher_idx ON publications (publisher COLLATE NOCASE);
@@ -0,0 +1,7 @@
+CREATE TABLE search_cache (
+	key        TEXT PRIMARY KEY,
+	results    TEXT NOT NULL,
+	created_at TEXT NOT NULL
+);
+
+CREATE INDEX search_cache_created_at_idx ON search_cache (created_at);
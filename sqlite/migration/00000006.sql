@@ -0,0 +1,3 @@
+ALTER TABLE publications ADD COLUMN oclc_number TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX publications_oclc_number_idx ON publications (oclc_number);
@@ -0,0 +1,3 @@
+ALTER TABLE publications ADD COLUMN lccn TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX publications_lccn_idx ON publications (lccn);
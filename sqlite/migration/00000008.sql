@@ -0,0 +1,3 @@
+ALTER TABLE publications ADD COLUMN doi TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX publications_doi_idx ON publications (doi);
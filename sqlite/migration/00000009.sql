@@ -0,0 +1,84 @@
+-- Full-text index of the catalog, one document per work. FTS4 is used rather
+-- than FTS5 because go-sqlite3 only compiles FTS5 in with a build tag.
+CREATE VIRTUAL TABLE catalog_fts USING fts4 (
+	title,
+	authors,
+	publications,
+	tokenize=unicode61 "remove_diacritics=1"
+);
+
+-- catalog_documents renders the indexed document of each work: its title, its
+-- credited and linked authors, and the publishers and identifiers of its
+-- publications.
+CREATE VIEW catalog_documents AS
+SELECT
+	w.id AS work_id,
+	w.title AS title,
+	w.author || ' ' || COALESCE((
+		SELECT group_concat(a.name, ' ')
+		FROM work_authors wa
+		JOIN authors a ON a.id = wa.author_id
+		WHERE wa.work_id = w.id
+	), '') AS authors,
+	COALESCE((
+		SELECT group_concat(p.publisher || ' ' || p.isbn13 || ' ' || p.isbn10 || ' ' || p.lccn || ' ' || p.doi || ' ' || p.oclc_number, ' ')
+		FROM publications p
+		WHERE p.work_id = w.id
+	), '') AS publications
+FROM works w;
+
+INSERT INTO catalog_fts (docid, title, authors, publications)
+SELECT work_id, title, authors, publications FROM catalog_documents;
+
+-- Each trigger re-renders the documents of the affected works.
+CREATE TRIGGER works_fts_insert AFTER INSERT ON works BEGIN
+	INSERT INTO catalog_fts (docid, title, authors, publications)
+	SELECT work_id, title, authors, publications FROM catalog_documents WHERE work_id = NEW.id;
+END;
+
+CREATE TRIGGER works_fts_update AFTER UPDATE ON works BEGIN
+	DELETE FROM catalog_fts WHERE docid = OLD.id;
+	INSERT INTO catalog_fts (docid, title, authors, publications)
+	SELECT work_id, title, authors, publications FROM catalog_documents WHERE work_id = NEW.id;
+END;
+
+CREATE TRIGGER works_fts_delete AFTER DELETE ON works BEGIN
+	DELETE FROM catalog_fts WHERE docid = OLD.id;
+END;
+
+CREATE TRIGGER work_authors_fts_insert AFTER INSERT ON work_authors BEGIN
+	DELETE FROM catalog_fts WHERE docid = NEW.work_id;
+	INSERT INTO catalog_fts (docid, title, authors, publications)
+	SELECT work_id, title, authors, publications FROM catalog_documents WHERE work_id = NEW.work_id;
+END;
+
+CREATE TRIGGER work_authors_fts_delete AFTER DELETE ON work_authors BEGIN
+	DELETE FROM catalog_fts WHERE docid = OLD.work_id;
+	INSERT INTO catalog_fts (docid, title, authors, publications)
+	SELECT work_id, title, authors, publications FROM catalog_documents WHERE work_id = OLD.work_id;
+END;
+
+CREATE TRIGGER authors_fts_update AFTER UPDATE OF name ON authors BEGIN
+	DELETE FROM catalog_fts WHERE docid IN (SELECT work_id FROM work_authors WHERE author_id = NEW.id);
+	INSERT INTO catalog_fts (docid, title, authors, publications)
+	SELECT work_id, title, authors, publications FROM catalog_documents
+	WHERE work_id IN (SELECT work_id FROM work_authors WHERE author_id = NEW.id);
+END;
+
+CREATE TRIGGER publications_fts_insert AFTER INSERT ON publications BEGIN
+	DELETE FROM catalog_fts WHERE docid = NEW.work_id;
+	INSERT INTO catalog_fts (docid, title, authors, publications)
+	SELECT work_id, title, authors, publications FROM catalog_documents WHERE work_id = NEW.work_id;
+END;
+
+CREATE TRIGGER publications_fts_update AFTER UPDATE ON publications BEGIN
+	DELETE FROM catalog_fts WHERE docid IN (OLD.work_id, NEW.work_id);
+	INSERT INTO catalog_fts (docid, title, authors, publications)
+	SELECT work_id, title, authors, publications FROM catalog_documents WHERE work_id IN (OLD.work_id, NEW.work_id);
+END;
+
+CREATE TRIGGER publications_fts_delete AFTER DELETE ON publications BEGIN
+	DELETE FROM catalog_fts WHERE docid = OLD.work_id;
+	INSERT INTO catalog_fts (docid, title, authors, publications)
+	SELECT work_id, title, authors, publications FROM catalog_documents WHERE work_id = OLD.work_id;
+END;
@@ -0,0 +1,112 @@
+-- Authors gain the identifiers of their authority records. Two authors may now
+-- share a name as long as authority records tell them apart, so the unique
+-- name_key constraint is replaced by partial unique indexes. SQLite cannot
+-- drop a constraint, so authors and work_authors are rebuilt, and the
+-- full-text triggers and view referring to them are recreated afterwards.
+DROP TRIGGER works_fts_insert;
+DROP TRIGGER works_fts_update;
+DROP TRIGGER publications_fts_insert;
+DROP TRIGGER publications_fts_update;
+DROP TRIGGER publications_fts_delete;
+DROP VIEW catalog_documents;
+
+CREATE TABLE authors_new (
+	id          INTEGER PRIMARY KEY AUTOINCREMENT,
+	name        TEXT NOT NULL,
+	name_key    TEXT NOT NULL,
+	viaf_id     TEXT NOT NULL DEFAULT '',
+	wikidata_id TEXT NOT NULL DEFAULT ''
+);
+
+INSERT INTO authors_new (id, name, name_key)
+SELECT id, name, name_key FROM authors;
+
+CREATE TABLE work_authors_new (
+	work_id   INTEGER NOT NULL REFERENCES works (id) ON DELETE CASCADE,
+	author_id INTEGER NOT NULL REFERENCES authors_new (id) ON DELETE CASCADE,
+
+	PRIMARY KEY (work_id, author_id)
+);
+
+-- Keep rowids, which order the authors of a work.
+INSERT INTO work_authors_new (rowid, work_id, author_id)
+SELECT rowid, work_id, author_id FROM work_authors;
+
+DROP TABLE work_authors;
+DROP TABLE authors;
+ALTER TABLE authors_new RENAME TO authors;
+ALTER TABLE work_authors_new RENAME TO work_authors;
+
+CREATE INDEX work_authors_author_id_idx ON work_authors (author_id);
+CREATE INDEX authors_name_key_idx ON authors (name_key);
+
+-- Authors without authority records are still deduplicated by name.
+CREATE UNIQUE INDEX authors_unresolved_name_key_idx ON authors (name_key) WHERE viaf_id = '' AND wikidata_id = '';
+CREATE UNIQUE INDEX authors_viaf_id_idx ON authors (viaf_id) WHERE viaf_id <> '';
+CREATE UNIQUE INDEX authors_wikidata_id_idx ON authors (wikidata_id) WHERE wikidata_id <> '';
+
+-- Recreated unchanged from 00000009.sql.
+CREATE VIEW catalog_documents AS
+SELECT
+	w.id AS work_id,
+	w.title AS title,
+	w.author || ' ' || COALESCE((
+		SELECT group_concat(a.name, ' ')
+		FROM work_authors wa
+		JOIN authors a ON a.id = wa.author_id
+		WHERE wa.work_id = w.id
+	), '') AS authors,
+	COALESCE((
+		SELECT group_concat(p.publisher || ' ' || p.isbn13 || ' ' || p.isbn10 || ' ' || p.lccn || ' ' || p.doi || ' ' || p.oclc_number, ' ')
+		FROM publications p
+		WHERE p.work_id = w.id
+	), '') AS publications
+FROM works w;
+
+CREATE TRIGGER works_fts_insert AFTER INSERT ON works BEGIN
+	INSERT INTO catalog_fts (docid, title, authors, publications)
+	SELECT work_id, title, authors, publications FROM catalog_documents WHERE work_id = NEW.id;
+END;
+
+CREATE TRIGGER works_fts_update AFTER UPDATE ON works BEGIN
+	DELETE FROM catalog_fts WHERE docid = OLD.id;
+	INSERT INTO catalog_fts (docid, title, authors, publications)
+	SELECT work_id, title, authors, publications FROM catalog_documents WHERE work_id = NEW.id;
+END;
+
+CREATE TRIGGER work_authors_fts_insert AFTER INSERT ON work_authors BEGIN
+	DELETE FROM catalog_fts WHERE docid = NEW.work_id;
+	INSERT INTO catalog_fts (docid, title, authors, publications)
+	SELECT work_id, title, authors, publications FROM catalog_documents WHERE work_id = NEW.work_id;
+END;
+
+CREATE TRIGGER work_authors_fts_delete AFTER DELETE ON work_authors BEGIN
+	DELETE FROM catalog_fts WHERE docid = OLD.work_id;
+	INSERT INTO catalog_fts (docid, title, authors, publications)
+	SELECT work_id, title, authors, publications FROM catalog_documents WHERE work_id = OLD.work_id;
+END;
+
+CREATE TRIGGER authors_fts_update AFTER UPDATE OF name ON authors BEGIN
+	DELETE FROM catalog_fts WHERE docid IN (SELECT work_id FROM work_authors WHERE author_id = NEW.id);
+	INSERT INTO catalog_fts (docid, title, authors, publications)
+	SELECT work_id, title, authors, publications FROM catalog_documents
+	WHERE work_id IN (SELECT work_id FROM work_authors WHERE author_id = NEW.id);
+END;
+
+CREATE TRIGGER publications_fts_insert AFTER INSERT ON publications BEGIN
+	DELETE FROM catalog_fts WHERE docid = NEW.work_id;
+	INSERT INTO catalog_fts (docid, title, authors, publications)
+	SELECT work_id, title, authors, publications FROM catalog_documents WHERE work_id = NEW.work_id;
+END;
+
+CREATE TRIGGER publications_fts_update AFTER UPDATE ON publications BEGIN
+	DELETE FROM catalog_fts WHERE docid IN (OLD.work_id, NEW.work_id);
+	INSERT INTO catalog_fts (docid, title, authors, publications)
+	SELECT work_id, title, authors, publications FROM catalog_documents WHERE work_id IN (OLD.work_id, NEW.work_id);
+END;
+
+CREATE TRIGGER publications_fts_delete AFTER DELETE ON publications BEGIN
+	DELETE FROM catalog_fts WHERE docid = OLD.work_id;
+	INSERT INTO catalog_fts (docid, title, authors, publications)
+	SELECT work_id, title, authors, publications FROM catalog_documents WHERE work_id = OLD.work_id;
+END;
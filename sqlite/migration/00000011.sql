@@ -0,0 +1,2 @@
+-- Path of the publication's cover image in the cover store, if downloaded.
+ALTER TABLE publications ADD COLUMN cover_path TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,16 @@
+-- Every change to works, authors and publications, for reviewing the history
+-- of the catalog. Entries outlive the entities they record, so there are no
+-- foreign keys.
+CREATE TABLE audit_log (
+	id          INTEGER PRIMARY KEY AUTOINCREMENT,
+	entity_type TEXT NOT NULL,
+	entity_id   INTEGER NOT NULL,
+	work_id     INTEGER,
+	action      TEXT NOT NULL,
+	actor       TEXT NOT NULL DEFAULT '',
+	diff        TEXT NOT NULL,
+	created_at  TEXT NOT NULL
+);
+
+CREATE INDEX audit_log_entity_idx ON audit_log (entity_type, entity_id);
+CREATE INDEX audit_log_work_id_idx ON audit_log (work_id);
@@ -0,0 +1,26 @@
+-- Deleted works and publications are kept in the trash, marked with the time
+-- they were deleted, until restored or purged.
+ALTER TABLE works ADD COLUMN deleted_at TEXT;
+ALTER TABLE publications ADD COLUMN deleted_at TEXT;
+
+-- Publications in the trash are no longer searchable. Deleted works are
+-- filtered out by the search queries, so that restoring them needs no
+-- reindexing. The view is recreated as in 00000010.sql otherwise.
+DROP VIEW catalog_documents;
+
+CREATE VIEW catalog_documents AS
+SELECT
+	w.id AS work_id,
+	w.title AS title,
+	w.author || ' ' || COALESCE((
+		SELECT group_concat(a.name, ' ')
+		FROM work_authors wa
+		JOIN authors a ON a.id = wa.author_id
+		WHERE wa.work_id = w.id
+	), '') AS authors,
+	COALESCE((
+		SELECT group_concat(p.publisher || ' ' || p.isbn13 || ' ' || p.isbn10 || ' ' || p.lccn || ' ' || p.doi || ' ' || p.oclc_number, ' ')
+		FROM publications p
+		WHERE p.work_id = w.id AND p.deleted_at IS NULL
+	), '') AS publications
+FROM works w;
@@ -0,0 +1,2 @@
+-- Time the publication was last re-fetched from its provider, if ever.
+ALTER TABLE publications ADD COLUMN refreshed_at TEXT;
@@ -0,0 +1,17 @@
+-- Series of works, such as "Discworld", and the position of each work in
+-- them. A volume of 0 means the position is unknown.
+CREATE TABLE series (
+	id         INTEGER PRIMARY KEY AUTOINCREMENT,
+	name       TEXT NOT NULL UNIQUE COLLATE NOCASE,
+	created_at TEXT NOT NULL
+);
+
+CREATE TABLE series_works (
+	series_id INTEGER NOT NULL REFERENCES series (id) ON DELETE CASCADE,
+	work_id   INTEGER NOT NULL REFERENCES works (id) ON DELETE CASCADE,
+	volume    REAL NOT NULL DEFAULT 0,
+
+	PRIMARY KEY (series_id, work_id)
+);
+
+CREATE INDEX series_works_work_id_idx ON series_works (work_id);
@@ -0,0 +1,14 @@
+-- Subjects, such as "science fiction", and the works tagged with them.
+CREATE TABLE subjects (
+	id   INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT NOT NULL UNIQUE
+);
+
+CREATE TABLE work_subjects (
+	work_id    INTEGER NOT NULL REFERENCES works (id) ON DELETE CASCADE,
+	subject_id INTEGER NOT NULL REFERENCES subjects (id) ON DELETE CASCADE,
+
+	PRIMARY KEY (work_id, subject_id)
+);
+
+CREATE INDEX work_subjects_subject_id_idx ON work_subjects (subject_id);
@@ -0,0 +1,18 @@
+-- User-defined collections of works, such as "to-read" or "office library".
+CREATE TABLE collections (
+	id          INTEGER PRIMARY KEY AUTOINCREMENT,
+	name        TEXT NOT NULL UNIQUE COLLATE NOCASE,
+	description TEXT NOT NULL DEFAULT '',
+	created_at  TEXT NOT NULL,
+	updated_at  TEXT NOT NULL
+);
+
+CREATE TABLE collection_works (
+	collection_id INTEGER NOT NULL REFERENCES collections (id) ON DELETE CASCADE,
+	work_id       INTEGER NOT NULL REFERENCES works (id) ON DELETE CASCADE,
+	added_at      TEXT NOT NULL,
+
+	PRIMARY KEY (collection_id, work_id)
+);
+
+CREATE INDEX collection_works_work_id_idx ON collection_works (work_id);
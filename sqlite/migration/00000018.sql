@@ -0,0 +1,6 @@
+-- Personal metadata about the owned copy of a publication.
+ALTER TABLE publications ADD COLUMN reading_status TEXT NOT NULL DEFAULT '';
+ALTER TABLE publications ADD COLUMN rating INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE publications ADD COLUMN notes TEXT NOT NULL DEFAULT '';
+ALTER TABLE publications ADD COLUMN acquired_at TEXT;
+ALTER TABLE publications ADD COLUMN location TEXT NOT NULL DEFAULT '';
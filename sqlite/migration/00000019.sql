@@ -0,0 +1,14 @@
+-- Physical copies of publications. Barcodes are unique when set.
+CREATE TABLE items (
+	id             INTEGER PRIMARY KEY AUTOINCREMENT,
+	publication_id INTEGER NOT NULL REFERENCES publications (id) ON DELETE CASCADE,
+	barcode        TEXT NOT NULL DEFAULT '',
+	condition      TEXT NOT NULL DEFAULT '',
+	location       TEXT NOT NULL DEFAULT '',
+	loan_status    TEXT NOT NULL,
+	created_at     TEXT NOT NULL,
+	updated_at     TEXT NOT NULL
+);
+
+CREATE INDEX items_publication_id_idx ON items (publication_id);
+CREATE UNIQUE INDEX items_barcode_idx ON items (barcode) WHERE barcode <> '';
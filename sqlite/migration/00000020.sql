@@ -0,0 +1,12 @@
+-- Loans of items to borrowers. An item has at most one open loan.
+CREATE TABLE loans (
+	id          INTEGER PRIMARY KEY AUTOINCREMENT,
+	item_id     INTEGER NOT NULL REFERENCES items (id) ON DELETE CASCADE,
+	borrower    TEXT NOT NULL COLLATE NOCASE,
+	loaned_at   TEXT NOT NULL,
+	due_at      TEXT,
+	returned_at TEXT
+);
+
+CREATE INDEX loans_borrower_idx ON loans (borrower);
+CREATE UNIQUE INDEX loans_open_item_id_idx ON loans (item_id) WHERE returned_at IS NULL;
@@ -0,0 +1,18 @@
+-- Queue of long-running jobs, such as imports, with their progress so that
+-- interrupted jobs can resume.
+CREATE TABLE jobs (
+	id          INTEGER PRIMARY KEY AUTOINCREMENT,
+	kind        TEXT NOT NULL,
+	params      TEXT NOT NULL DEFAULT '',
+	state       TEXT NOT NULL,
+	done        INTEGER NOT NULL DEFAULT 0,
+	total       INTEGER NOT NULL DEFAULT 0,
+	result      TEXT NOT NULL DEFAULT '',
+	error       TEXT NOT NULL DEFAULT '',
+	created_at  TEXT NOT NULL,
+	updated_at  TEXT NOT NULL,
+	started_at  TEXT,
+	finished_at TEXT
+);
+
+CREATE INDEX jobs_state_idx ON jobs (state);
@@ -0,0 +1,3 @@
+-- Provider that supplied each field of a publication, as a JSON object keyed
+-- by field name.
+ALTER TABLE publications ADD COLUMN provenance TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,18 @@
+-- Values of publication fields proposed by providers and held back by merge
+-- policies for review. A field has at most one open conflict per proposed
+-- value.
+CREATE TABLE conflicts (
+	id                INTEGER PRIMARY KEY AUTOINCREMENT,
+	publication_id    INTEGER NOT NULL REFERENCES publications (id) ON DELETE CASCADE,
+	field             TEXT NOT NULL,
+	current_value     TEXT NOT NULL,
+	current_provider  TEXT NOT NULL,
+	proposed_value    TEXT NOT NULL,
+	proposed_provider TEXT NOT NULL,
+	created_at        TEXT NOT NULL,
+	resolved_at       TEXT,
+	resolution        TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX conflicts_publication_id_idx ON conflicts (publication_id);
+CREATE UNIQUE INDEX conflicts_open_idx ON conflicts (publication_id, field, proposed_value) WHERE resolved_at IS NULL;
@@ -0,0 +1,17 @@
+-- Books that batch or import could not identify with enough confidence to
+-- save, with the search results to choose from as JSON.
+CREATE TABLE reviews (
+	id             INTEGER PRIMARY KEY AUTOINCREMENT,
+	source         TEXT NOT NULL,
+	line           INTEGER NOT NULL DEFAULT 0,
+	query          TEXT NOT NULL,
+	candidates     TEXT NOT NULL,
+	state          TEXT NOT NULL,
+	choice         INTEGER NOT NULL DEFAULT 0,
+	work_id        INTEGER REFERENCES works (id) ON DELETE SET NULL,
+	publication_id INTEGER REFERENCES publications (id) ON DELETE SET NULL,
+	created_at     TEXT NOT NULL,
+	resolved_at    TEXT
+);
+
+CREATE INDEX reviews_state_idx ON reviews (state);
@@ -0,0 +1,4 @@
+-- Links of works to the people credited for them record their role, so
+-- translators, editors, illustrators and narrators are kept apart from the
+-- authors.
+ALTER TABLE work_authors ADD COLUMN role TEXT NOT NULL DEFAULT 'author';
@@ -0,0 +1,4 @@
+-- Translations record the title and language of their original, as given
+-- by providers or taken from the original work when the two are linked.
+ALTER TABLE works ADD COLUMN original_title TEXT NOT NULL DEFAULT '';
+ALTER TABLE works ADD COLUMN original_language TEXT NOT NULL DEFAULT '';
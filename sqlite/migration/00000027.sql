@@ -0,0 +1,22 @@
+-- Publishers become entities that publications refer to, so the variants of
+-- a publisher's name can be merged and publications counted by publisher.
+-- The name is kept on publications as well for search and filtering.
+CREATE TABLE publishers (
+	id         INTEGER PRIMARY KEY AUTOINCREMENT,
+	name       TEXT NOT NULL UNIQUE COLLATE NOCASE,
+	created_at TEXT NOT NULL
+);
+
+INSERT INTO publishers (name, created_at)
+SELECT TRIM(publisher), MIN(created_at)
+FROM publications
+WHERE TRIM(publisher) <> ''
+GROUP BY TRIM(publisher) COLLATE NOCASE;
+
+ALTER TABLE publications ADD COLUMN publisher_id INTEGER REFERENCES publishers (id) ON DELETE SET NULL;
+
+UPDATE publications
+SET publisher_id = (SELECT id FROM publishers WHERE name = TRIM(publications.publisher))
+WHERE TRIM(publisher) <> '';
+
+CREATE INDEX publications_publisher_id_idx ON publications (publisher_id);
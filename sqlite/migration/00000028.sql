@@ -0,0 +1,5 @@
+-- Binding of each publication, such as hardcover or ebook, when a provider
+-- tells it. Empty if unknown.
+ALTER TABLE publications ADD COLUMN binding TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX publications_binding_idx ON publications (binding);
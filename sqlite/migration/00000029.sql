@@ -0,0 +1,7 @@
+-- Page count and physical dimensions of each publication, in millimeters
+-- and grams. Zero if unknown.
+ALTER TABLE publications ADD COLUMN page_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE publications ADD COLUMN height_mm REAL NOT NULL DEFAULT 0;
+ALTER TABLE publications ADD COLUMN width_mm REAL NOT NULL DEFAULT 0;
+ALTER TABLE publications ADD COLUMN thickness_mm REAL NOT NULL DEFAULT 0;
+ALTER TABLE publications ADD COLUMN weight_g REAL NOT NULL DEFAULT 0;
@@ -0,0 +1,7 @@
+-- Amazon Standard Identification Number of each publication, identifying
+-- audiobooks and other editions without an ISBN, and the running time of
+-- audiobooks in minutes. Empty or zero if unknown.
+ALTER TABLE publications ADD COLUMN asin TEXT NOT NULL DEFAULT '';
+ALTER TABLE publications ADD COLUMN duration_minutes INTEGER NOT NULL DEFAULT 0;
+
+CREATE INDEX publications_asin_idx ON publications (asin);
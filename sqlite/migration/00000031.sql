@@ -0,0 +1,16 @@
+-- Prices and availability of publications as reported by providers, kept as
+-- a history for monitoring prices over time.
+CREATE TABLE publication_offers (
+	id             INTEGER PRIMARY KEY AUTOINCREMENT,
+	publication_id INTEGER NOT NULL REFERENCES publications (id) ON DELETE CASCADE,
+	provider       TEXT NOT NULL,
+	country        TEXT NOT NULL DEFAULT '',
+	currency       TEXT NOT NULL DEFAULT '',
+	list_price     REAL NOT NULL DEFAULT 0,
+	retail_price   REAL NOT NULL DEFAULT 0,
+	availability   TEXT NOT NULL DEFAULT '',
+	url            TEXT NOT NULL DEFAULT '',
+	fetched_at     TEXT NOT NULL
+);
+
+CREATE INDEX publication_offers_publication_id_idx ON publication_offers (publication_id, provider, fetched_at);
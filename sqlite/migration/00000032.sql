@@ -0,0 +1,10 @@
+-- How the content of each publication can be accessed online, as told by
+-- Google Books: whether it is in the public domain or readable in full, the
+-- digital formats offered, and links to read and preview it. False or empty
+-- if unknown.
+ALTER TABLE publications ADD COLUMN public_domain INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE publications ADD COLUMN full_view INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE publications ADD COLUMN epub_available INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE publications ADD COLUMN pdf_available INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE publications ADD COLUMN web_reader_url TEXT NOT NULL DEFAULT '';
+ALTER TABLE publications ADD COLUMN preview_url TEXT NOT NULL DEFAULT '';
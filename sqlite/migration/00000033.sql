@@ -0,0 +1,4 @@
+-- Summary of each publication as plain text and the titles of its chapters
+-- or parts as a JSON array, when providers give them. Empty if unknown.
+ALTER TABLE publications ADD COLUMN description TEXT NOT NULL DEFAULT '';
+ALTER TABLE publications ADD COLUMN table_of_contents TEXT NOT NULL DEFAULT '';
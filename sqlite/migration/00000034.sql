@@ -0,0 +1,2 @@
+-- BISAC subject codes of subjects, e.g. "FIC028000" for science fiction.
+ALTER TABLE subjects ADD COLUMN bisac_code TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,4 @@
+-- Dewey Decimal and Library of Congress classification numbers of each
+-- publication, for shelving. Empty if unknown.
+ALTER TABLE publications ADD COLUMN dewey_decimal TEXT NOT NULL DEFAULT '';
+ALTER TABLE publications ADD COLUMN lc_classification TEXT NOT NULL DEFAULT '';
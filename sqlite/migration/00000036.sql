@@ -0,0 +1,26 @@
+-- Works and publications are identified across catalogs by a random UID, so
+-- that two catalogs can be synced although their IDs differ. Works and
+-- publications permanently removed leave a tombstone, so that sync removes
+-- them from the other catalog as well.
+ALTER TABLE works ADD COLUMN uid TEXT NOT NULL DEFAULT '';
+UPDATE works SET uid = lower(hex(randomblob(16)));
+CREATE UNIQUE INDEX works_uid_idx ON works (uid);
+
+ALTER TABLE publications ADD COLUMN uid TEXT NOT NULL DEFAULT '';
+UPDATE publications SET uid = lower(hex(randomblob(16)));
+CREATE UNIQUE INDEX publications_uid_idx ON publications (uid);
+
+CREATE TABLE tombstones (
+	uid        TEXT PRIMARY KEY,
+	entity     TEXT NOT NULL,
+	deleted_at TEXT NOT NULL
+);
+
+CREATE INDEX tombstones_deleted_at_idx ON tombstones (deleted_at);
+
+-- Time each catalog synced with was last synced, by the path or URL it was
+-- given by.
+CREATE TABLE sync_peers (
+	peer      TEXT PRIMARY KEY,
+	synced_at TEXT NOT NULL
+);
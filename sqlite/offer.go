@@ -0,0 +1,164 @@
+package sqlite
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/fwojciec/bookid"
+)
+
+// Ensure service implements interface.
+var _ bookid.OfferService = (*OfferService)(nil)
+
+// OfferService represents a service for recording the offers of
+// publications.
+type OfferService struct {
+	db *DB
+}
+
+// NewOfferService returns a new instance of OfferService.
+func NewOfferService(db *DB) *OfferService {
+	return &OfferService{db: db}
+}
+
+// FindOffers retrieves a list of offers matching the filter.
+func (s *OfferService) FindOffers(ctx context.Context, filter bookid.OfferFilter) ([]*bookid.Offer, int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer func() { _ = tx.Rollback() }()
+	return findOffers(ctx, tx, filter)
+}
+
+// CreateOffer records an offer of a publication.
+func (s *OfferService) CreateOffer(ctx context.Context, offer *bookid.Offer) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := createOffer(ctx, tx, offer); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// findOffers returns a list of offers matching a filter, most recently
+// fetched first. Also returns a count of total matching offers which may
+// differ if filter.Limit is set.
+func findOffers(ctx context.Context, tx *Tx, filter bookid.OfferFilter) (_ []*bookid.Offer, n int, err error) {
+	where, args := []string{"1 = 1"}, []any{}
+	if v := filter.PublicationID; v != nil {
+		where, args = append(where, "publication_id = ?"), append(args, *v)
+	}
+	if v := filter.Provider; v != nil {
+		where, args = append(where, "provider = ?"), append(args, *v)
+	}
+	if filter.Latest {
+		where = append(where, `id IN (
+			SELECT id FROM (
+				SELECT id, ROW_NUMBER() OVER (PARTITION BY publication_id, provider ORDER BY fetched_at DESC, id DESC) AS n
+				FROM publication_offers
+			)
+			WHERE n = 1
+		)`)
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT
+			id,
+			publication_id,
+			provider,
+			country,
+			currency,
+			list_price,
+			retail_price,
+			availability,
+			url,
+			fetched_at,
+			COUNT(*) OVER ()
+		FROM publication_offers
+		WHERE `+strings.Join(where, " AND ")+`
+		ORDER BY fetched_at DESC, id DESC
+		`+FormatLimitOffset(filter.Limit, filter.Offset),
+		args...,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	offers := make([]*bookid.Offer, 0)
+	for rows.Next() {
+		var offer bookid.Offer
+		if err := rows.Scan(
+			&offer.ID,
+			&offer.PublicationID,
+			&offer.Provider,
+			&offer.Country,
+			&offer.Currency,
+			&offer.ListPrice,
+			&offer.RetailPrice,
+			&offer.Availability,
+			&offer.URL,
+			(*NullTime)(&offer.FetchedAt),
+			&n,
+		); err != nil {
+			return nil, 0, err
+		}
+		offers = append(offers, &offer)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+	return offers, n, nil
+}
+
+// createOffer records an offer of an existing publication. Sets the ID, and
+// the fetch time if it is zero, on success.
+func createOffer(ctx context.Context, tx *Tx, offer *bookid.Offer) error {
+	offer.Country, offer.Currency = strings.ToUpper(offer.Country), strings.ToUpper(offer.Currency)
+	if offer.FetchedAt.IsZero() {
+		offer.FetchedAt = tx.now
+	} else {
+		offer.FetchedAt = offer.FetchedAt.UTC().Truncate(time.Second)
+	}
+	if err := offer.Validate(); err != nil {
+		return err
+	} else if _, err := findPublicationByID(ctx, tx, offer.PublicationID); err != nil {
+		return err
+	}
+
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO publication_offers (
+			publication_id,
+			provider,
+			country,
+			currency,
+			list_price,
+			retail_price,
+			availability,
+			url,
+			fetched_at
+		)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`,
+		offer.PublicationID,
+		offer.Provider,
+		offer.Country,
+		offer.Currency,
+		offer.ListPrice,
+		offer.RetailPrice,
+		offer.Availability,
+		offer.URL,
+		(*NullTime)(&offer.FetchedAt),
+	)
+	if err != nil {
+		return FormatError(err)
+	}
+	offer.ID, err = result.LastInsertId()
+	return err
+}
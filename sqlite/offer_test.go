@@ -0,0 +1,120 @@
+package sqlite_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/sqlite"
+)
+
+func TestOfferService_CreateOffer(t *testing.T) {
+	t.Parallel()
+
+	t.Run("OK", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewOfferService(db)
+		ctx := context.Background()
+
+		work := MustCreateWork(t, ctx, db, &bookid.Work{Title: "The Great Gatsby"})
+		pub := MustCreatePublication(t, ctx, db, &bookid.Publication{WorkID: work.ID, ISBN13: "9780743273565"})
+
+		offer := &bookid.Offer{
+			PublicationID: pub.ID,
+			Provider:      "googlebooks",
+			Country:       "us",
+			Currency:      "usd",
+			ListPrice:     17,
+			RetailPrice:   11.99,
+			Availability:  bookid.AvailabilityForSale,
+		}
+		if err := s.CreateOffer(ctx, offer); err != nil {
+			t.Fatal(err)
+		} else if offer.ID == 0 || offer.FetchedAt.IsZero() {
+			t.Fatalf("ID=%d FetchedAt=%v, want both set", offer.ID, offer.FetchedAt)
+		}
+
+		if offers, n, err := s.FindOffers(ctx, bookid.OfferFilter{PublicationID: &pub.ID}); err != nil {
+			t.Fatal(err)
+		} else if n != 1 {
+			t.Fatalf("n=%d, want 1", n)
+		} else if got := offers[0]; got.Country != "US" || got.Currency != "USD" || got.RetailPrice != 11.99 {
+			t.Fatalf("offer=%+v", got)
+		}
+	})
+
+	t.Run("ErrPublicationNotFound", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewOfferService(db)
+
+		err := s.CreateOffer(context.Background(), &bookid.Offer{PublicationID: 1, Provider: "isbndb"})
+		if bookid.ErrorCode(err) != bookid.ENOTFOUND {
+			t.Fatalf("ErrorCode()=%q, want %q", bookid.ErrorCode(err), bookid.ENOTFOUND)
+		}
+	})
+
+	t.Run("ErrPriceWithoutCurrency", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewOfferService(db)
+		ctx := context.Background()
+
+		work := MustCreateWork(t, ctx, db, &bookid.Work{Title: "The Great Gatsby"})
+		pub := MustCreatePublication(t, ctx, db, &bookid.Publication{WorkID: work.ID})
+		err := s.CreateOffer(ctx, &bookid.Offer{PublicationID: pub.ID, Provider: "isbndb", ListPrice: 17})
+		if bookid.ErrorCode(err) != bookid.EINVALID {
+			t.Fatalf("ErrorCode()=%q, want %q", bookid.ErrorCode(err), bookid.EINVALID)
+		}
+	})
+}
+
+func TestOfferService_FindOffers(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Latest", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewOfferService(db)
+		ctx := context.Background()
+
+		work := MustCreateWork(t, ctx, db, &bookid.Work{Title: "The Great Gatsby"})
+		pub := MustCreatePublication(t, ctx, db, &bookid.Publication{WorkID: work.ID, ISBN13: "9780743273565"})
+		day := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+		for _, offer := range []*bookid.Offer{
+			{PublicationID: pub.ID, Provider: "googlebooks", Currency: "USD", RetailPrice: 12.99, FetchedAt: day},
+			{PublicationID: pub.ID, Provider: "googlebooks", Currency: "USD", RetailPrice: 9.99, FetchedAt: day.AddDate(0, 0, 7)},
+			{PublicationID: pub.ID, Provider: "isbndb", Currency: "USD", ListPrice: 17, FetchedAt: day},
+		} {
+			if err := s.CreateOffer(ctx, offer); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		if offers, n, err := s.FindOffers(ctx, bookid.OfferFilter{PublicationID: &pub.ID}); err != nil {
+			t.Fatal(err)
+		} else if n != 3 {
+			t.Fatalf("n=%d, want 3", n)
+		} else if got := offers[0].RetailPrice; got != 9.99 {
+			t.Fatalf("RetailPrice=%v, want newest first", got)
+		}
+
+		offers, n, err := s.FindOffers(ctx, bookid.OfferFilter{PublicationID: &pub.ID, Latest: true})
+		if err != nil {
+			t.Fatal(err)
+		} else if n != 2 {
+			t.Fatalf("n=%d, want 2", n)
+		}
+		for _, offer := range offers {
+			if offer.Provider == "googlebooks" && offer.RetailPrice != 9.99 {
+				t.Fatalf("RetailPrice=%v, want latest 9.99", offer.RetailPrice)
+			}
+		}
+	})
+}
@@ -0,0 +1,958 @@
+package sqlite
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/asin"
+	"github.com/fwojciec/bookid/classify"
+	"github.com/fwojciec/bookid/doi"
+	"github.com/fwojciec/bookid/isbn"
+	"github.com/fwojciec/bookid/language"
+	"github.com/fwojciec/bookid/lccn"
+	"github.com/fwojciec/bookid/publisher"
+)
+
+// Ensure service implements interface.
+var _ bookid.PublicationService = (*PublicationService)(nil)
+
+// PublicationService represents a service for managing publications.
+type PublicationService struct {
+	db *DB
+}
+
+// NewPublicationService returns a new instance of PublicationService.
+func NewPublicationService(db *DB) *PublicationService {
+	return &PublicationService{db: db}
+}
+
+// FindPublicationByID retrieves a single publication by ID.
+// Returns ENOTFOUND if the publication does not exist.
+func (s *PublicationService) FindPublicationByID(ctx context.Context, id int64) (*bookid.Publication, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+	return findPublicationByID(ctx, tx, id)
+}
+
+// FindPublications retrieves a list of publications matching the filter.
+func (s *PublicationService) FindPublications(ctx context.Context, filter bookid.PublicationFilter) ([]*bookid.Publication, int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer func() { _ = tx.Rollback() }()
+	return findPublications(ctx, tx, filter)
+}
+
+// CreatePublication creates a new publication.
+func (s *PublicationService) CreatePublication(ctx context.Context, pub *bookid.Publication) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := createPublication(ctx, tx, pub); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// UpsertPublication creates a publication, or updates the existing one with
+// the same ISBN-13, Google Books volume ID or ASIN. Returns ECONFLICT if
+// those match different publications.
+func (s *PublicationService) UpsertPublication(ctx context.Context, pub *bookid.Publication) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := upsertPublication(ctx, tx, pub); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// UpdatePublication updates an existing publication.
+// Returns ENOTFOUND if the publication does not exist.
+func (s *PublicationService) UpdatePublication(ctx context.Context, id int64, upd bookid.PublicationUpdate) (*bookid.Publication, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	pub, err := updatePublication(ctx, tx, id, upd)
+	if err != nil {
+		return pub, err
+	} else if err := tx.Commit(); err != nil {
+		return pub, err
+	}
+	return pub, nil
+}
+
+// DeletePublication moves a publication to the trash. Returns ENOTFOUND if
+// the publication does not exist or is already deleted.
+func (s *PublicationService) DeletePublication(ctx context.Context, id int64) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := deletePublication(ctx, tx, id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// RestorePublication restores a deleted publication along with its work, if
+// that is deleted too. Returns ENOTFOUND if the publication is not in the
+// trash.
+func (s *PublicationService) RestorePublication(ctx context.Context, id int64) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := restorePublication(ctx, tx, id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// PurgePublication permanently removes a deleted publication. Returns
+// ENOTFOUND if the publication is not in the trash.
+func (s *PublicationService) PurgePublication(ctx context.Context, id int64) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := purgePublication(ctx, tx, id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// findPublicationByID is a helper function to fetch a publication by ID.
+// Returns ENOTFOUND if the publication does not exist.
+func findPublicationByID(ctx context.Context, tx *Tx, id int64) (*bookid.Publication, error) {
+	pubs, _, err := findPublications(ctx, tx, bookid.PublicationFilter{ID: &id})
+	if err != nil {
+		return nil, err
+	} else if len(pubs) == 0 {
+		return nil, bookid.Errorf(bookid.ENOTFOUND, "Publication not found.")
+	}
+	return pubs[0], nil
+}
+
+// findDeletedPublicationByID is a helper function to fetch a publication in
+// the trash by ID. Returns ENOTFOUND if the publication is not in the trash.
+func findDeletedPublicationByID(ctx context.Context, tx *Tx, id int64) (*bookid.Publication, error) {
+	pubs, _, err := findPublications(ctx, tx, bookid.PublicationFilter{ID: &id, OnlyDeleted: true})
+	if err != nil {
+		return nil, err
+	} else if len(pubs) == 0 {
+		return nil, bookid.Errorf(bookid.ENOTFOUND, "Publication not found in trash.")
+	}
+	return pubs[0], nil
+}
+
+// findPublications returns a list of publications matching a filter. Also
+// returns a count of total matching publications which may differ if
+// filter.Limit is set.
+func findPublications(ctx context.Context, tx *Tx, filter bookid.PublicationFilter) (_ []*bookid.Publication, n int, err error) {
+	where, args := []string{"1 = 1"}, []any{}
+	if v := filter.ID; v != nil {
+		where, args = append(where, "id = ?"), append(args, *v)
+	}
+	if v := filter.UID; v != nil {
+		where, args = append(where, "uid = ?"), append(args, *v)
+	}
+	if v := filter.WorkID; v != nil {
+		where, args = append(where, "work_id = ?"), append(args, *v)
+	}
+	if v := filter.ISBN; v != nil {
+		code := isbn.Normalize(*v)
+		where, args = append(where, "(isbn10 = ? OR isbn13 = ?)"), append(args, code, code)
+	}
+	if v := filter.GoogleBooksVolumeID; v != nil {
+		where, args = append(where, "google_books_volume_id = ?"), append(args, *v)
+	}
+	if v := filter.OCLCNumber; v != nil {
+		where, args = append(where, "oclc_number = ?"), append(args, *v)
+	}
+	if v := filter.LCCN; v != nil {
+		where, args = append(where, "lccn = ?"), append(args, lccn.Normalize(*v))
+	}
+	if v := filter.DOI; v != nil {
+		where, args = append(where, "doi = ?"), append(args, doi.Normalize(*v))
+	}
+	if v := filter.ASIN; v != nil {
+		where, args = append(where, "asin = ?"), append(args, asin.Normalize(*v))
+	}
+	if v := filter.Publisher; v != nil {
+		where, args = append(where, "publisher = ? COLLATE NOCASE"), append(args, publisher.Name(*v))
+	}
+	if v := filter.PublisherID; v != nil {
+		where, args = append(where, "publisher_id = ?"), append(args, *v)
+	}
+	if v := filter.Language; v != nil {
+		where, args = append(where, languageCondition("language")), append(args, languageArgs(*v)...)
+	}
+	if v := filter.PublishedYear; v != nil {
+		where, args = append(where, "published_year = ?"), append(args, *v)
+	}
+	if v := filter.Binding; v != nil {
+		where, args = append(where, "binding = ?"), append(args, *v)
+	}
+	if v := filter.HasCover; v != nil {
+		if *v {
+			where = append(where, "cover_path <> ''")
+		} else {
+			where = append(where, "cover_path = ''")
+		}
+	}
+	if v := filter.HasClassification; v != nil {
+		if *v {
+			where = append(where, "(dewey_decimal <> '' OR lc_classification <> '')")
+		} else {
+			where = append(where, "dewey_decimal = '' AND lc_classification = ''")
+		}
+	}
+	if v := filter.RefreshedBefore; v != nil {
+		where, args = append(where, "COALESCE(refreshed_at, created_at) < ?"), append(args, (*NullTime)(v))
+	}
+	if filter.OnlyDeleted {
+		where = append(where, "deleted_at IS NOT NULL")
+	} else if !filter.IncludeDeleted {
+		where = append(where, "deleted_at IS NULL")
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT
+			id,
+			uid,
+			work_id,
+			isbn10,
+			isbn13,
+			publisher,
+			publisher_id,
+			published_year,
+			language,
+			binding,
+			page_count,
+			duration_minutes,
+			height_mm,
+			width_mm,
+			thickness_mm,
+			weight_g,
+			public_domain,
+			full_view,
+			epub_available,
+			pdf_available,
+			web_reader_url,
+			preview_url,
+			dewey_decimal,
+			lc_classification,
+			google_books_volume_id,
+			oclc_number,
+			lccn,
+			doi,
+			asin,
+			thumbnail_url,
+			cover_path,
+			description,
+			table_of_contents,
+			google_books_data,
+			created_at,
+			updated_at,
+			refreshed_at,
+			deleted_at,
+			reading_status,
+			rating,
+			notes,
+			acquired_at,
+			location,
+			provenance,
+			COUNT(*) OVER ()
+		FROM publications
+		WHERE `+strings.Join(where, " AND ")+`
+		ORDER BY id ASC
+		`+FormatLimitOffset(filter.Limit, filter.Offset),
+		args...,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	pubs := make([]*bookid.Publication, 0)
+	for rows.Next() {
+		var pub bookid.Publication
+		var publisherID sql.NullInt64
+		if err := rows.Scan(
+			&pub.ID,
+			&pub.UID,
+			&pub.WorkID,
+			&pub.ISBN10,
+			&pub.ISBN13,
+			&pub.Publisher,
+			&publisherID,
+			&pub.PublishedYear,
+			&pub.Language,
+			&pub.Binding,
+			&pub.PageCount,
+			&pub.DurationMinutes,
+			&pub.Dimensions.Height,
+			&pub.Dimensions.Width,
+			&pub.Dimensions.Thickness,
+			&pub.Dimensions.Weight,
+			&pub.Access.PublicDomain,
+			&pub.Access.FullView,
+			&pub.Access.EPUBAvailable,
+			&pub.Access.PDFAvailable,
+			&pub.Access.WebReaderURL,
+			&pub.Access.PreviewURL,
+			&pub.Classification.Dewey,
+			&pub.Classification.LCC,
+			&pub.GoogleBooksVolumeID,
+			&pub.OCLCNumber,
+			&pub.LCCN,
+			&pub.DOI,
+			&pub.ASIN,
+			&pub.ThumbnailURL,
+			&pub.CoverPath,
+			&pub.Description,
+			(*StringSlice)(&pub.TableOfContents),
+			&pub.GoogleBooksData,
+			(*NullTime)(&pub.CreatedAt),
+			(*NullTime)(&pub.UpdatedAt),
+			(*NullTime)(&pub.RefreshedAt),
+			(*NullTime)(&pub.DeletedAt),
+			&pub.ReadingStatus,
+			&pub.Rating,
+			&pub.Notes,
+			(*NullTime)(&pub.AcquiredAt),
+			&pub.Location,
+			(*StringMap)(&pub.Provenance),
+			&n,
+		); err != nil {
+			return nil, 0, err
+		}
+		pub.PublisherID = publisherID.Int64
+		pubs = append(pubs, &pub)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+	return pubs, n, nil
+}
+
+// createPublication creates a new publication. Sets the ID, the UID unless
+// one is given and timestamps on success.
+func createPublication(ctx context.Context, tx *Tx, pub *bookid.Publication) error {
+	// Set timestamps to the current time.
+	pub.CreatedAt = tx.now
+	pub.UpdatedAt = pub.CreatedAt
+	if pub.UID == "" {
+		pub.UID = newUID()
+	}
+
+	pub.ISBN10, pub.ISBN13 = isbn.Normalize(pub.ISBN10), isbn.Normalize(pub.ISBN13)
+	pub.LCCN, pub.DOI, pub.ASIN = lccn.Normalize(pub.LCCN), doi.Normalize(pub.DOI), asin.Normalize(pub.ASIN)
+	pub.Language = language.Normalize(pub.Language)
+	pub.Notes, pub.Location = strings.TrimSpace(pub.Notes), strings.TrimSpace(pub.Location)
+	pub.Description = strings.TrimSpace(pub.Description)
+	pub.Classification = classify.Normalize(pub.Classification)
+	pub.AcquiredAt = acquiredDate(pub.AcquiredAt)
+	if err := pub.Validate(); err != nil {
+		return err
+	} else if _, err := findWorkByID(ctx, tx, pub.WorkID); err != nil {
+		return err
+	} else if err := linkPublisher(ctx, tx, pub); err != nil {
+		return err
+	}
+
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO publications (
+			uid,
+			work_id,
+			isbn10,
+			isbn13,
+			publisher,
+			publisher_id,
+			published_year,
+			language,
+			binding,
+			page_count,
+			duration_minutes,
+			height_mm,
+			width_mm,
+			thickness_mm,
+			weight_g,
+			public_domain,
+			full_view,
+			epub_available,
+			pdf_available,
+			web_reader_url,
+			preview_url,
+			dewey_decimal,
+			lc_classification,
+			google_books_volume_id,
+			oclc_number,
+			lccn,
+			doi,
+			asin,
+			thumbnail_url,
+			cover_path,
+			description,
+			table_of_contents,
+			google_books_data,
+			created_at,
+			updated_at,
+			refreshed_at,
+			reading_status,
+			rating,
+			notes,
+			acquired_at,
+			location,
+			provenance
+		)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`,
+		pub.UID,
+		pub.WorkID,
+		pub.ISBN10,
+		pub.ISBN13,
+		pub.Publisher,
+		sql.NullInt64{Int64: pub.PublisherID, Valid: pub.PublisherID != 0},
+		pub.PublishedYear,
+		pub.Language,
+		pub.Binding,
+		pub.PageCount,
+		pub.DurationMinutes,
+		pub.Dimensions.Height,
+		pub.Dimensions.Width,
+		pub.Dimensions.Thickness,
+		pub.Dimensions.Weight,
+		pub.Access.PublicDomain,
+		pub.Access.FullView,
+		pub.Access.EPUBAvailable,
+		pub.Access.PDFAvailable,
+		pub.Access.WebReaderURL,
+		pub.Access.PreviewURL,
+		pub.Classification.Dewey,
+		pub.Classification.LCC,
+		pub.GoogleBooksVolumeID,
+		pub.OCLCNumber,
+		pub.LCCN,
+		pub.DOI,
+		pub.ASIN,
+		pub.ThumbnailURL,
+		pub.CoverPath,
+		pub.Description,
+		(*StringSlice)(&pub.TableOfContents),
+		pub.GoogleBooksData,
+		(*NullTime)(&pub.CreatedAt),
+		(*NullTime)(&pub.UpdatedAt),
+		(*NullTime)(&pub.RefreshedAt),
+		pub.ReadingStatus,
+		pub.Rating,
+		pub.Notes,
+		(*NullTime)(&pub.AcquiredAt),
+		pub.Location,
+		(*StringMap)(&pub.Provenance),
+	)
+	if err != nil {
+		return FormatError(err)
+	}
+
+	if pub.ID, err = result.LastInsertId(); err != nil {
+		return err
+	}
+
+	if err := audit(ctx, tx, bookid.AuditEntityPublication, pub.ID, pub.WorkID, bookid.AuditActionCreate, nil, pub); err != nil {
+		return err
+	}
+
+	other := *pub
+	tx.publish(bookid.EventTypePublicationCreated, &bookid.PublicationCreatedPayload{Publication: &other})
+	return nil
+}
+
+// upsertPublication inserts pub, or merges it into the publication that
+// shares its ISBN-13, Google Books volume ID or ASIN, restoring that from the
+// trash if needed. Returns ECONFLICT if its identifiers belong to different
+// publications.
+func upsertPublication(ctx context.Context, tx *Tx, pub *bookid.Publication) error {
+	existing, err := findPublicationByIdentifiers(ctx, tx, isbn.Normalize(pub.ISBN13), pub.GoogleBooksVolumeID, asin.Normalize(pub.ASIN))
+	if err != nil {
+		return err
+	} else if existing == nil {
+		return createPublication(ctx, tx, pub)
+	} else if !existing.DeletedAt.IsZero() {
+		if err := restorePublication(ctx, tx, existing.ID); err != nil {
+			return err
+		}
+		existing.DeletedAt = time.Time{}
+	}
+	old := *existing
+
+	// Non-empty incoming values overwrite what we have stored. Personal
+	// metadata only comes from the user and is kept as it is.
+	if v := isbn.Normalize(pub.ISBN10); v != "" {
+		existing.ISBN10 = v
+	}
+	if v := isbn.Normalize(pub.ISBN13); v != "" {
+		existing.ISBN13 = v
+	}
+	if v := publisher.Name(pub.Publisher); v != "" {
+		existing.Publisher = v
+	}
+	if pub.PublishedYear != 0 {
+		existing.PublishedYear = pub.PublishedYear
+	}
+	if v := language.Normalize(pub.Language); v != "" {
+		existing.Language = v
+	}
+	if pub.Binding != "" {
+		existing.Binding = pub.Binding
+	}
+	if pub.PageCount != 0 {
+		existing.PageCount = pub.PageCount
+	}
+	if pub.DurationMinutes != 0 {
+		existing.DurationMinutes = pub.DurationMinutes
+	}
+	if !pub.Dimensions.IsZero() {
+		existing.Dimensions = pub.Dimensions
+	}
+	if !pub.Access.IsZero() {
+		existing.Access = pub.Access
+	}
+	if v := classify.Normalize(pub.Classification); !v.IsZero() {
+		existing.Classification.Dewey = cmp.Or(v.Dewey, existing.Classification.Dewey)
+		existing.Classification.LCC = cmp.Or(v.LCC, existing.Classification.LCC)
+	}
+	if pub.GoogleBooksVolumeID != "" {
+		existing.GoogleBooksVolumeID = pub.GoogleBooksVolumeID
+	}
+	if pub.OCLCNumber != "" {
+		existing.OCLCNumber = pub.OCLCNumber
+	}
+	if v := lccn.Normalize(pub.LCCN); v != "" {
+		existing.LCCN = v
+	}
+	if v := doi.Normalize(pub.DOI); v != "" {
+		existing.DOI = v
+	}
+	if v := asin.Normalize(pub.ASIN); v != "" {
+		existing.ASIN = v
+	}
+	if pub.ThumbnailURL != "" {
+		existing.ThumbnailURL = pub.ThumbnailURL
+	}
+	if pub.CoverPath != "" {
+		existing.CoverPath = pub.CoverPath
+	}
+	if v := strings.TrimSpace(pub.Description); v != "" {
+		existing.Description = v
+	}
+	if len(pub.TableOfContents) > 0 {
+		existing.TableOfContents = pub.TableOfContents
+	}
+	if pub.GoogleBooksData != "" {
+		existing.GoogleBooksData = pub.GoogleBooksData
+	}
+	existing.Provenance = mergeProvenance(existing.Provenance, pub.Provenance, changedFields(&old, existing)...)
+	existing.UpdatedAt = tx.now
+
+	if err := savePublication(ctx, tx, existing); err != nil {
+		return err
+	} else if err := audit(ctx, tx, bookid.AuditEntityPublication, existing.ID, existing.WorkID, bookid.AuditActionUpdate, &old, existing); err != nil {
+		return err
+	}
+	*pub = *existing
+	return nil
+}
+
+// findPublicationByIdentifiers returns the publication with the given
+// ISBN-13, Google Books volume ID or ASIN, which may be in the trash. Returns
+// nil if none matches, and ECONFLICT if the identifiers match different
+// publications. Empty identifiers are ignored.
+func findPublicationByIdentifiers(ctx context.Context, tx *Tx, isbn13, volumeID, asinCode string) (*bookid.Publication, error) {
+	var found *bookid.Publication
+	var foundBy string
+	for _, f := range []struct {
+		name, value string
+		filter      bookid.PublicationFilter
+	}{
+		{"ISBN-13", isbn13, bookid.PublicationFilter{ISBN: &isbn13}},
+		{"Google Books volume ID", volumeID, bookid.PublicationFilter{GoogleBooksVolumeID: &volumeID}},
+		{"ASIN", asinCode, bookid.PublicationFilter{ASIN: &asinCode}},
+	} {
+		if f.value == "" {
+			continue
+		}
+		f.filter.IncludeDeleted, f.filter.Limit = true, 1
+		pubs, _, err := findPublications(ctx, tx, f.filter)
+		if err != nil {
+			return nil, err
+		} else if len(pubs) == 0 {
+			continue
+		} else if found == nil {
+			found, foundBy = pubs[0], f.name+" "+f.value
+		} else if pubs[0].ID != found.ID {
+			return nil, bookid.Errorf(bookid.ECONFLICT, "Identifiers match different publications: %s is publication %d, %s is publication %d.", foundBy, found.ID, f.name+" "+f.value, pubs[0].ID)
+		}
+	}
+	return found, nil
+}
+
+// updatePublication updates fields on a publication by ID. Returns the
+// updated publication.
+func updatePublication(ctx context.Context, tx *Tx, id int64, upd bookid.PublicationUpdate) (*bookid.Publication, error) {
+	pub, err := findPublicationByID(ctx, tx, id)
+	if err != nil {
+		return pub, err
+	}
+	old := *pub
+
+	if v := upd.WorkID; v != nil {
+		if _, err := findWorkByID(ctx, tx, *v); err != nil {
+			return pub, err
+		}
+		pub.WorkID = *v
+	}
+	if v := upd.ISBN10; v != nil {
+		pub.ISBN10 = isbn.Normalize(*v)
+	}
+	if v := upd.ISBN13; v != nil {
+		pub.ISBN13 = isbn.Normalize(*v)
+	}
+	if v := upd.Publisher; v != nil {
+		pub.Publisher = publisher.Name(*v)
+	}
+	if v := upd.PublishedYear; v != nil {
+		pub.PublishedYear = *v
+	}
+	if v := upd.Language; v != nil {
+		pub.Language = language.Normalize(*v)
+	}
+	if v := upd.Binding; v != nil {
+		pub.Binding = *v
+	}
+	if v := upd.PageCount; v != nil {
+		pub.PageCount = *v
+	}
+	if v := upd.DurationMinutes; v != nil {
+		pub.DurationMinutes = *v
+	}
+	if v := upd.Dimensions; v != nil {
+		pub.Dimensions = *v
+	}
+	if v := upd.Access; v != nil {
+		pub.Access = *v
+	}
+	if v := upd.Classification; v != nil {
+		pub.Classification = classify.Normalize(*v)
+	}
+	if v := upd.OCLCNumber; v != nil {
+		pub.OCLCNumber = *v
+	}
+	if v := upd.LCCN; v != nil {
+		pub.LCCN = lccn.Normalize(*v)
+	}
+	if v := upd.DOI; v != nil {
+		pub.DOI = doi.Normalize(*v)
+	}
+	if v := upd.ASIN; v != nil {
+		pub.ASIN = asin.Normalize(*v)
+	}
+	if v := upd.ThumbnailURL; v != nil {
+		pub.ThumbnailURL = *v
+	}
+	if v := upd.CoverPath; v != nil {
+		pub.CoverPath = *v
+	}
+	if v := upd.Description; v != nil {
+		pub.Description = strings.TrimSpace(*v)
+	}
+	if v := upd.TableOfContents; v != nil {
+		pub.TableOfContents = *v
+	}
+	if v := upd.ReadingStatus; v != nil {
+		pub.ReadingStatus = *v
+	}
+	if v := upd.Rating; v != nil {
+		pub.Rating = *v
+	}
+	if v := upd.Notes; v != nil {
+		pub.Notes = strings.TrimSpace(*v)
+	}
+	if v := upd.AcquiredAt; v != nil {
+		pub.AcquiredAt = acquiredDate(*v)
+	}
+	if v := upd.Location; v != nil {
+		pub.Location = strings.TrimSpace(*v)
+	}
+	if v := upd.GoogleBooksData; v != nil {
+		pub.GoogleBooksData = *v
+	}
+	if v := upd.RefreshedAt; v != nil {
+		pub.RefreshedAt = v.UTC().Truncate(time.Second)
+	}
+	pub.Provenance = mergeProvenance(pub.Provenance, upd.Provenance, changedFields(&old, pub)...)
+	pub.UpdatedAt = tx.now
+
+	if err := pub.Validate(); err != nil {
+		return pub, err
+	}
+	if err := savePublication(ctx, tx, pub); err != nil {
+		return pub, err
+	}
+	return pub, audit(ctx, tx, bookid.AuditEntityPublication, id, pub.WorkID, bookid.AuditActionUpdate, &old, pub)
+}
+
+// mergeProvenance returns the providers of the fields of a publication with
+// those of updated fields replaced. Changed fields without a provider were
+// set by the user and lose theirs. The stored map is not modified, so that
+// the audit log sees the old value.
+func mergeProvenance(stored, updated map[string]string, changed ...string) map[string]string {
+	m := maps.Clone(stored)
+	for _, name := range changed {
+		if updated[name] == "" {
+			delete(m, name)
+		}
+	}
+	if len(updated) > 0 && m == nil {
+		m = make(map[string]string, len(updated))
+	}
+	maps.Copy(m, updated)
+	if len(m) == 0 {
+		return nil
+	}
+	return m
+}
+
+// changedFields returns the JSON names of the fields supplied by providers
+// that differ between two versions of a publication.
+func changedFields(old, pub *bookid.Publication) []string {
+	var names []string
+	for name, changed := range map[string]bool{
+		"isbn10":                 old.ISBN10 != pub.ISBN10,
+		"isbn13":                 old.ISBN13 != pub.ISBN13,
+		"publisher":              old.Publisher != pub.Publisher,
+		"published_year":         old.PublishedYear != pub.PublishedYear,
+		"language":               old.Language != pub.Language,
+		"binding":                old.Binding != pub.Binding,
+		"page_count":             old.PageCount != pub.PageCount,
+		"duration_minutes":       old.DurationMinutes != pub.DurationMinutes,
+		"dimensions":             old.Dimensions != pub.Dimensions,
+		"access":                 old.Access != pub.Access,
+		"classification":         old.Classification != pub.Classification,
+		"google_books_volume_id": old.GoogleBooksVolumeID != pub.GoogleBooksVolumeID,
+		"oclc_number":            old.OCLCNumber != pub.OCLCNumber,
+		"lccn":                   old.LCCN != pub.LCCN,
+		"doi":                    old.DOI != pub.DOI,
+		"asin":                   old.ASIN != pub.ASIN,
+		"thumbnail_url":          old.ThumbnailURL != pub.ThumbnailURL,
+		"description":            old.Description != pub.Description,
+		"table_of_contents":      !slices.Equal(old.TableOfContents, pub.TableOfContents),
+	} {
+		if changed {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// acquiredDate truncates t to the date in UTC, as acquisition times are only
+// tracked to the day.
+func acquiredDate(t time.Time) time.Time {
+	if t.IsZero() {
+		return t
+	}
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// savePublication writes every mutable field of pub to its row, linking it
+// to its publisher first.
+func savePublication(ctx context.Context, tx *Tx, pub *bookid.Publication) error {
+	if err := linkPublisher(ctx, tx, pub); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE publications
+		SET work_id = ?,
+		    isbn10 = ?,
+		    isbn13 = ?,
+		    publisher = ?,
+		    publisher_id = ?,
+		    published_year = ?,
+		    language = ?,
+		    binding = ?,
+		    page_count = ?,
+		    duration_minutes = ?,
+		    height_mm = ?,
+		    width_mm = ?,
+		    thickness_mm = ?,
+		    weight_g = ?,
+		    public_domain = ?,
+		    full_view = ?,
+		    epub_available = ?,
+		    pdf_available = ?,
+		    web_reader_url = ?,
+		    preview_url = ?,
+		    dewey_decimal = ?,
+		    lc_classification = ?,
+		    google_books_volume_id = ?,
+		    oclc_number = ?,
+		    lccn = ?,
+		    doi = ?,
+		    asin = ?,
+		    thumbnail_url = ?,
+		    cover_path = ?,
+		    description = ?,
+		    table_of_contents = ?,
+		    google_books_data = ?,
+		    updated_at = ?,
+		    refreshed_at = ?,
+		    reading_status = ?,
+		    rating = ?,
+		    notes = ?,
+		    acquired_at = ?,
+		    location = ?,
+		    provenance = ?
+		WHERE id = ?
+	`,
+		pub.WorkID,
+		pub.ISBN10,
+		pub.ISBN13,
+		pub.Publisher,
+		sql.NullInt64{Int64: pub.PublisherID, Valid: pub.PublisherID != 0},
+		pub.PublishedYear,
+		pub.Language,
+		pub.Binding,
+		pub.PageCount,
+		pub.DurationMinutes,
+		pub.Dimensions.Height,
+		pub.Dimensions.Width,
+		pub.Dimensions.Thickness,
+		pub.Dimensions.Weight,
+		pub.Access.PublicDomain,
+		pub.Access.FullView,
+		pub.Access.EPUBAvailable,
+		pub.Access.PDFAvailable,
+		pub.Access.WebReaderURL,
+		pub.Access.PreviewURL,
+		pub.Classification.Dewey,
+		pub.Classification.LCC,
+		pub.GoogleBooksVolumeID,
+		pub.OCLCNumber,
+		pub.LCCN,
+		pub.DOI,
+		pub.ASIN,
+		pub.ThumbnailURL,
+		pub.CoverPath,
+		pub.Description,
+		(*StringSlice)(&pub.TableOfContents),
+		pub.GoogleBooksData,
+		(*NullTime)(&pub.UpdatedAt),
+		(*NullTime)(&pub.RefreshedAt),
+		pub.ReadingStatus,
+		pub.Rating,
+		pub.Notes,
+		(*NullTime)(&pub.AcquiredAt),
+		pub.Location,
+		(*StringMap)(&pub.Provenance),
+		pub.ID,
+	); err != nil {
+		return FormatError(err)
+	}
+	return nil
+}
+
+// deletePublication moves a publication by ID to the trash.
+func deletePublication(ctx context.Context, tx *Tx, id int64) error {
+	pub, err := findPublicationByID(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE publications SET deleted_at = ? WHERE id = ?
+	`, (*NullTime)(&tx.now), id); err != nil {
+		return FormatError(err)
+	}
+	return audit(ctx, tx, bookid.AuditEntityPublication, id, pub.WorkID, bookid.AuditActionDelete, pub, nil)
+}
+
+// restorePublication restores a publication by ID from the trash, first
+// restoring its work if that is deleted too. Restoring counts as a change,
+// so it is synced.
+func restorePublication(ctx context.Context, tx *Tx, id int64) error {
+	pub, err := findDeletedPublicationByID(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+	old := *pub
+	pub.DeletedAt, pub.UpdatedAt = time.Time{}, tx.now
+
+	if works, _, err := findWorks(ctx, tx, bookid.WorkFilter{ID: &pub.WorkID, OnlyDeleted: true}); err != nil {
+		return err
+	} else if len(works) > 0 {
+		if err := restoreWork(ctx, tx, pub.WorkID); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE publications SET deleted_at = NULL, updated_at = ? WHERE id = ?
+	`, (*NullTime)(&tx.now), id); err != nil {
+		return FormatError(err)
+	}
+	return audit(ctx, tx, bookid.AuditEntityPublication, id, pub.WorkID, bookid.AuditActionRestore, &old, pub)
+}
+
+// purgePublication permanently removes a publication by ID from the trash.
+func purgePublication(ctx context.Context, tx *Tx, id int64) error {
+	pub, err := findDeletedPublicationByID(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+	if err := insertTombstones(ctx, tx, bookid.AuditEntityPublication, `SELECT uid FROM publications WHERE id = ?`, id); err != nil {
+		return err
+	} else if _, err := tx.ExecContext(ctx, `DELETE FROM publications WHERE id = ?`, id); err != nil {
+		return FormatError(err)
+	}
+	return audit(ctx, tx, bookid.AuditEntityPublication, id, pub.WorkID, bookid.AuditActionPurge, pub, nil)
+}
+
+// languageCondition returns a SQL condition matching the values of column in
+// a language or its regional variants, taking the arguments returned by
+// languageArgs.
+func languageCondition(column string) string {
+	return "(" + column + " = ? OR " + column + " LIKE ? ESCAPE '\\')"
+}
+
+// languageArgs returns the arguments of languageCondition for a language
+// code or tag, normalized first.
+func languageArgs(lang string) []any {
+	tag := language.Normalize(lang)
+	return []any{tag, strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(tag) + "-%"}
+}
@@ -0,0 +1,771 @@
+package sqlite_test
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/sqlite"
+)
+
+func TestPublicationService_CreatePublication(t *testing.T) {
+	t.Parallel()
+
+	t.Run("OK", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewPublicationService(db)
+		ctx := context.Background()
+
+		work := MustCreateWork(t, ctx, db, &bookid.Work{Title: "The Go Programming Language"})
+		pub := &bookid.Publication{
+			WorkID:              work.ID,
+			ISBN10:              "0134190440",
+			ISBN13:              "978-0134190440",
+			Publisher:           "Addison-Wesley Professional",
+			PublishedYear:       2015,
+			Language:            "en",
+			GoogleBooksVolumeID: "SJHvCgAAQBAJ",
+			OCLCNumber:          "907205584",
+			ThumbnailURL:        "https://books.google.com/books/content?id=SJHvCgAAQBAJ",
+			GoogleBooksData:     `{"id":"SJHvCgAAQBAJ"}`,
+		}
+		if err := s.CreatePublication(ctx, pub); err != nil {
+			t.Fatal(err)
+		} else if got, want := pub.ID, int64(1); got != want {
+			t.Fatalf("ID=%d, want %d", got, want)
+		} else if got, want := pub.ISBN13, "9780134190440"; got != want {
+			t.Fatalf("ISBN13=%q, want %q", got, want)
+		} else if pub.CreatedAt.IsZero() || pub.UpdatedAt.IsZero() {
+			t.Fatal("expected timestamps")
+		}
+
+		if other, err := s.FindPublicationByID(ctx, pub.ID); err != nil {
+			t.Fatal(err)
+		} else if !reflect.DeepEqual(pub, other) {
+			t.Fatalf("mismatch: %#v != %#v", pub, other)
+		}
+	})
+
+	t.Run("ErrDuplicateISBN13", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewPublicationService(db)
+		ctx := context.Background()
+
+		work := MustCreateWork(t, ctx, db, &bookid.Work{Title: "Dune"})
+		MustCreatePublication(t, ctx, db, &bookid.Publication{WorkID: work.ID, ISBN13: "9780441172719"})
+		err := s.CreatePublication(ctx, &bookid.Publication{WorkID: work.ID, ISBN13: "978-0-441-17271-9"})
+		if code := bookid.ErrorCode(err); code != bookid.ECONFLICT {
+			t.Fatalf("ErrorCode()=%q, want %q", code, bookid.ECONFLICT)
+		}
+	})
+
+	t.Run("ErrDuplicateVolumeID", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewPublicationService(db)
+		ctx := context.Background()
+
+		work := MustCreateWork(t, ctx, db, &bookid.Work{Title: "Dune"})
+		MustCreatePublication(t, ctx, db, &bookid.Publication{WorkID: work.ID, GoogleBooksVolumeID: "B1hSG45JCX4C"})
+		err := s.CreatePublication(ctx, &bookid.Publication{WorkID: work.ID, GoogleBooksVolumeID: "B1hSG45JCX4C"})
+		if code := bookid.ErrorCode(err); code != bookid.ECONFLICT {
+			t.Fatalf("ErrorCode()=%q, want %q", code, bookid.ECONFLICT)
+		}
+	})
+
+	t.Run("EmptyIdentifiersAreNotUnique", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		ctx := context.Background()
+
+		work := MustCreateWork(t, ctx, db, &bookid.Work{Title: "Dune"})
+		MustCreatePublication(t, ctx, db, &bookid.Publication{WorkID: work.ID})
+		MustCreatePublication(t, ctx, db, &bookid.Publication{WorkID: work.ID})
+	})
+
+	t.Run("ErrWorkNotFound", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewPublicationService(db)
+
+		if err := s.CreatePublication(context.Background(), &bookid.Publication{WorkID: 100}); bookid.ErrorCode(err) != bookid.ENOTFOUND {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+
+	t.Run("ErrWorkRequired", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewPublicationService(db)
+
+		if err := s.CreatePublication(context.Background(), &bookid.Publication{}); bookid.ErrorCode(err) != bookid.EINVALID {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+}
+
+func TestPublicationService_UpsertPublication(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Insert", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewPublicationService(db)
+		ctx := context.Background()
+
+		work := MustCreateWork(t, ctx, db, &bookid.Work{Title: "Dune"})
+		pub := &bookid.Publication{WorkID: work.ID, ISBN13: "9780441172719"}
+		if err := s.UpsertPublication(ctx, pub); err != nil {
+			t.Fatal(err)
+		} else if pub.ID == 0 {
+			t.Fatal("expected ID")
+		}
+	})
+
+	t.Run("UpdateExisting", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewPublicationService(db)
+		ctx := context.Background()
+
+		work := MustCreateWork(t, ctx, db, &bookid.Work{Title: "Dune"})
+		other := MustCreateWork(t, ctx, db, &bookid.Work{Title: "Dune (duplicate)"})
+		existing := MustCreatePublication(t, ctx, db, &bookid.Publication{
+			WorkID:    work.ID,
+			ISBN13:    "9780441172719",
+			Publisher: "Ace",
+			Language:  "en",
+		})
+
+		pub := &bookid.Publication{
+			WorkID:        other.ID,
+			ISBN13:        "978-0-441-17271-9",
+			Publisher:     "Ace Books",
+			PublishedYear: 1990,
+		}
+		if err := s.UpsertPublication(ctx, pub); err != nil {
+			t.Fatal(err)
+		} else if got, want := pub.ID, existing.ID; got != want {
+			t.Fatalf("ID=%d, want %d", got, want)
+		} else if got, want := pub.WorkID, work.ID; got != want {
+			t.Fatalf("WorkID=%d, want %d", got, want)
+		} else if got, want := pub.Publisher, "Ace"; got != want {
+			t.Fatalf("Publisher=%q, want %q", got, want)
+		} else if got, want := pub.PublishedYear, 1990; got != want {
+			t.Fatalf("PublishedYear=%d, want %d", got, want)
+		} else if got, want := pub.Language, "en"; got != want {
+			t.Fatalf("Language=%q, want %q", got, want)
+		}
+
+		if _, n, err := s.FindPublications(ctx, bookid.PublicationFilter{}); err != nil {
+			t.Fatal(err)
+		} else if got, want := n, 1; got != want {
+			t.Fatalf("n=%d, want %d", got, want)
+		}
+	})
+
+	t.Run("MatchVolumeID", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewPublicationService(db)
+		ctx := context.Background()
+
+		work := MustCreateWork(t, ctx, db, &bookid.Work{Title: "Dune"})
+		existing := MustCreatePublication(t, ctx, db, &bookid.Publication{WorkID: work.ID, GoogleBooksVolumeID: "B1hSG45JCX4C"})
+
+		pub := &bookid.Publication{WorkID: work.ID, GoogleBooksVolumeID: "B1hSG45JCX4C", ISBN13: "9780441172719"}
+		if err := s.UpsertPublication(ctx, pub); err != nil {
+			t.Fatal(err)
+		} else if got, want := pub.ID, existing.ID; got != want {
+			t.Fatalf("ID=%d, want %d", got, want)
+		} else if got, want := pub.ISBN13, "9780441172719"; got != want {
+			t.Fatalf("ISBN13=%q, want %q", got, want)
+		}
+	})
+
+	t.Run("ErrConflictingIdentifiers", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewPublicationService(db)
+		ctx := context.Background()
+
+		work := MustCreateWork(t, ctx, db, &bookid.Work{Title: "Dune"})
+		paperback := MustCreatePublication(t, ctx, db, &bookid.Publication{WorkID: work.ID, ISBN13: "9780441172719"})
+		ebook := MustCreatePublication(t, ctx, db, &bookid.Publication{WorkID: work.ID, GoogleBooksVolumeID: "B1hSG45JCX4C"})
+
+		pub := &bookid.Publication{WorkID: work.ID, ISBN13: "9780441172719", GoogleBooksVolumeID: "B1hSG45JCX4C"}
+		if err := s.UpsertPublication(ctx, pub); bookid.ErrorCode(err) != bookid.ECONFLICT {
+			t.Fatalf("ErrorCode()=%q, want %q (err=%v)", bookid.ErrorCode(err), bookid.ECONFLICT, err)
+		} else if got, want := bookid.ErrorMessage(err), fmt.Sprintf("Identifiers match different publications: ISBN-13 9780441172719 is publication %d, Google Books volume ID B1hSG45JCX4C is publication %d.", paperback.ID, ebook.ID); got != want {
+			t.Fatalf("ErrorMessage()=%q, want %q", got, want)
+		}
+
+		if got, err := s.FindPublicationByID(ctx, paperback.ID); err != nil {
+			t.Fatal(err)
+		} else if got.GoogleBooksVolumeID != "" {
+			t.Fatalf("GoogleBooksVolumeID=%q, want it left alone", got.GoogleBooksVolumeID)
+		}
+	})
+
+	t.Run("RestoreDeleted", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewPublicationService(db)
+		ctx := context.Background()
+
+		work := MustCreateWork(t, ctx, db, &bookid.Work{Title: "Dune"})
+		existing := MustCreatePublication(t, ctx, db, &bookid.Publication{WorkID: work.ID, ISBN13: "9780441172719"})
+		if err := sqlite.NewWorkService(db).DeleteWork(ctx, work.ID); err != nil {
+			t.Fatal(err)
+		}
+
+		pub := &bookid.Publication{WorkID: work.ID, ISBN13: "9780441172719", Publisher: "Ace"}
+		if err := s.UpsertPublication(ctx, pub); err != nil {
+			t.Fatal(err)
+		} else if got, want := pub.ID, existing.ID; got != want {
+			t.Fatalf("ID=%d, want %d", got, want)
+		} else if !pub.DeletedAt.IsZero() {
+			t.Fatalf("DeletedAt=%v, want zero", pub.DeletedAt)
+		} else if _, err := sqlite.NewWorkService(db).FindWorkByID(ctx, work.ID); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+func TestPublicationService_FindPublications(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Filters", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewPublicationService(db)
+		ctx := context.Background()
+
+		dune := MustCreateWork(t, ctx, db, &bookid.Work{Title: "Dune"})
+		solaris := MustCreateWork(t, ctx, db, &bookid.Work{Title: "Solaris"})
+		MustCreatePublication(t, ctx, db, &bookid.Publication{WorkID: dune.ID, ISBN10: "0441172717", ISBN13: "9780441172719", Publisher: "Ace", PublishedYear: 1990})
+		MustCreatePublication(t, ctx, db, &bookid.Publication{WorkID: dune.ID, ISBN13: "9780593099322", Publisher: "Ace", PublishedYear: 2019})
+		MustCreatePublication(t, ctx, db, &bookid.Publication{WorkID: solaris.ID, ISBN13: "9780156027601", Publisher: "Harvest", PublishedYear: 2002, OCLCNumber: "50143186", LCCN: "2001-5360", DOI: "10.5555/SOLARIS", CoverPath: "ab/abcd.jpg"})
+
+		for _, tt := range []struct {
+			name   string
+			filter bookid.PublicationFilter
+			want   int
+		}{
+			{"work", bookid.PublicationFilter{WorkID: &dune.ID}, 2},
+			{"isbn10", bookid.PublicationFilter{ISBN: ptr("0-441-17271-7")}, 1},
+			{"isbn13", bookid.PublicationFilter{ISBN: ptr("9780156027601")}, 1},
+			{"publisher", bookid.PublicationFilter{Publisher: ptr("ACE")}, 2},
+			{"year", bookid.PublicationFilter{PublishedYear: ptr(2019)}, 1},
+			{"oclc", bookid.PublicationFilter{OCLCNumber: ptr("50143186")}, 1},
+			{"lccn", bookid.PublicationFilter{LCCN: ptr("2001005360")}, 1},
+			{"doi", bookid.PublicationFilter{DOI: ptr("https://doi.org/10.5555/solaris")}, 1},
+			{"with cover", bookid.PublicationFilter{HasCover: ptr(true)}, 1},
+			{"without cover", bookid.PublicationFilter{HasCover: ptr(false)}, 2},
+			{"combined", bookid.PublicationFilter{Publisher: ptr("ace"), PublishedYear: ptr(2002)}, 0},
+		} {
+			if _, n, err := s.FindPublications(ctx, tt.filter); err != nil {
+				t.Fatal(err)
+			} else if n != tt.want {
+				t.Fatalf("%s: n=%d, want %d", tt.name, n, tt.want)
+			}
+		}
+	})
+
+	t.Run("Language", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewPublicationService(db)
+		works := sqlite.NewWorkService(db)
+		ctx := context.Background()
+
+		gatsby := MustCreateWork(t, ctx, db, &bookid.Work{Title: "The Great Gatsby"})
+		solaris := MustCreateWork(t, ctx, db, &bookid.Work{Title: "Solaris"})
+		pub := MustCreatePublication(t, ctx, db, &bookid.Publication{WorkID: gatsby.ID, ISBN13: "9780743273565", Language: "eng"})
+		MustCreatePublication(t, ctx, db, &bookid.Publication{WorkID: gatsby.ID, ISBN13: "9780141182636", Language: "en_GB"})
+		MustCreatePublication(t, ctx, db, &bookid.Publication{WorkID: solaris.ID, ISBN13: "9788308049430", Language: "pol"})
+
+		if got, want := pub.Language, "en"; got != want {
+			t.Fatalf("Language=%q, want %q", got, want)
+		}
+
+		for _, tt := range []struct {
+			lang         string
+			pubs, nworks int
+		}{
+			{"en", 2, 1},
+			{"en-GB", 1, 1},
+			{"English", 2, 1},
+			{"pl", 1, 1},
+			{"de", 0, 0},
+		} {
+			if _, n, err := s.FindPublications(ctx, bookid.PublicationFilter{Language: &tt.lang}); err != nil {
+				t.Fatal(err)
+			} else if n != tt.pubs {
+				t.Fatalf("%s: publications=%d, want %d", tt.lang, n, tt.pubs)
+			}
+			if _, n, err := works.FindWorks(ctx, bookid.WorkFilter{Language: &tt.lang}); err != nil {
+				t.Fatal(err)
+			} else if n != tt.nworks {
+				t.Fatalf("%s: works=%d, want %d", tt.lang, n, tt.nworks)
+			}
+		}
+	})
+
+	t.Run("Binding", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewPublicationService(db)
+		works := sqlite.NewWorkService(db)
+		ctx := context.Background()
+
+		gatsby := MustCreateWork(t, ctx, db, &bookid.Work{Title: "The Great Gatsby"})
+		solaris := MustCreateWork(t, ctx, db, &bookid.Work{Title: "Solaris"})
+		MustCreatePublication(t, ctx, db, &bookid.Publication{WorkID: gatsby.ID, ISBN13: "9780743273565", Binding: bookid.BindingPaperback})
+		MustCreatePublication(t, ctx, db, &bookid.Publication{WorkID: gatsby.ID, ISBN13: "9781439567234", Binding: bookid.BindingEbook})
+		MustCreatePublication(t, ctx, db, &bookid.Publication{WorkID: solaris.ID, ISBN13: "9788308049430"})
+
+		for _, tt := range []struct {
+			binding      bookid.Binding
+			pubs, nworks int
+		}{
+			{bookid.BindingPaperback, 1, 1},
+			{bookid.BindingEbook, 1, 1},
+			{bookid.BindingHardcover, 0, 0},
+		} {
+			if _, n, err := s.FindPublications(ctx, bookid.PublicationFilter{Binding: &tt.binding}); err != nil {
+				t.Fatal(err)
+			} else if n != tt.pubs {
+				t.Fatalf("%s: publications=%d, want %d", tt.binding, n, tt.pubs)
+			}
+			if _, n, err := works.FindWorks(ctx, bookid.WorkFilter{Binding: &tt.binding}); err != nil {
+				t.Fatal(err)
+			} else if n != tt.nworks {
+				t.Fatalf("%s: works=%d, want %d", tt.binding, n, tt.nworks)
+			}
+		}
+
+		err := s.CreatePublication(ctx, &bookid.Publication{WorkID: solaris.ID, Binding: "scroll"})
+		if bookid.ErrorCode(err) != bookid.EINVALID {
+			t.Fatalf("ErrorCode()=%q, want %q", bookid.ErrorCode(err), bookid.EINVALID)
+		}
+	})
+
+	t.Run("PhysicalDescription", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewPublicationService(db)
+		ctx := context.Background()
+
+		work := MustCreateWork(t, ctx, db, &bookid.Work{Title: "The Great Gatsby"})
+		size := bookid.Dimensions{Height: 203.2, Width: 134.6, Thickness: 12.7}
+		pub := MustCreatePublication(t, ctx, db, &bookid.Publication{WorkID: work.ID, ISBN13: "9780743273565", PageCount: 180, Dimensions: size})
+
+		if other, err := s.FindPublicationByID(ctx, pub.ID); err != nil {
+			t.Fatal(err)
+		} else if other.PageCount != 180 || other.Dimensions != size {
+			t.Fatalf("PageCount=%d Dimensions=%+v, want 180 %+v", other.PageCount, other.Dimensions, size)
+		}
+
+		size.Weight = 181.4
+		if updated, err := s.UpdatePublication(ctx, pub.ID, bookid.PublicationUpdate{PageCount: ptr(192), Dimensions: &size}); err != nil {
+			t.Fatal(err)
+		} else if updated.PageCount != 192 || updated.Dimensions != size {
+			t.Fatalf("PageCount=%d Dimensions=%+v, want 192 %+v", updated.PageCount, updated.Dimensions, size)
+		}
+
+		err := s.CreatePublication(ctx, &bookid.Publication{WorkID: work.ID, PageCount: -1})
+		if bookid.ErrorCode(err) != bookid.EINVALID {
+			t.Fatalf("ErrorCode()=%q, want %q", bookid.ErrorCode(err), bookid.EINVALID)
+		}
+	})
+
+	t.Run("Access", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewPublicationService(db)
+		ctx := context.Background()
+
+		work := MustCreateWork(t, ctx, db, &bookid.Work{Title: "Pride and Prejudice"})
+		access := bookid.AccessInfo{
+			PublicDomain:  true,
+			FullView:      true,
+			EPUBAvailable: true,
+			WebReaderURL:  "https://play.google.com/books/reader?id=s1gVAAAAYAAJ",
+			PreviewURL:    "https://books.google.com/books?id=s1gVAAAAYAAJ",
+		}
+		pub := MustCreatePublication(t, ctx, db, &bookid.Publication{WorkID: work.ID, Access: access})
+
+		if other, err := s.FindPublicationByID(ctx, pub.ID); err != nil {
+			t.Fatal(err)
+		} else if other.Access != access {
+			t.Fatalf("Access=%+v, want %+v", other.Access, access)
+		}
+
+		access.PDFAvailable = true
+		if updated, err := s.UpdatePublication(ctx, pub.ID, bookid.PublicationUpdate{Access: &access}); err != nil {
+			t.Fatal(err)
+		} else if updated.Access != access {
+			t.Fatalf("Access=%+v, want %+v", updated.Access, access)
+		}
+	})
+
+	t.Run("Classification", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewPublicationService(db)
+		ctx := context.Background()
+
+		work := MustCreateWork(t, ctx, db, &bookid.Work{Title: "The Great Gatsby"})
+		pub := MustCreatePublication(t, ctx, db, &bookid.Publication{WorkID: work.ID, Classification: bookid.Classification{Dewey: "813/.52"}})
+		MustCreatePublication(t, ctx, db, &bookid.Publication{WorkID: work.ID})
+
+		if other, err := s.FindPublicationByID(ctx, pub.ID); err != nil {
+			t.Fatal(err)
+		} else if got, want := other.Classification, (bookid.Classification{Dewey: "813.52"}); got != want {
+			t.Fatalf("Classification=%+v, want %+v", got, want)
+		}
+
+		c := bookid.Classification{Dewey: "813.52", LCC: "PS3511.I9  G7 2004"}
+		if updated, err := s.UpdatePublication(ctx, pub.ID, bookid.PublicationUpdate{Classification: &c}); err != nil {
+			t.Fatal(err)
+		} else if got, want := updated.Classification.LCC, "PS3511.I9 G7 2004"; got != want {
+			t.Fatalf("LCC=%q, want %q", got, want)
+		}
+
+		hasClassification := false
+		if pubs, n, err := s.FindPublications(ctx, bookid.PublicationFilter{HasClassification: &hasClassification}); err != nil {
+			t.Fatal(err)
+		} else if n != 1 || pubs[0].ID == pub.ID {
+			t.Fatalf("n=%d, want only the unclassified publication", n)
+		}
+	})
+
+	t.Run("Description", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewPublicationService(db)
+		ctx := context.Background()
+
+		work := MustCreateWork(t, ctx, db, &bookid.Work{Title: "The Great Gatsby"})
+		toc := []string{"Chapter 1", "Chapter 2"}
+		pub := MustCreatePublication(t, ctx, db, &bookid.Publication{WorkID: work.ID, Description: " A novel of the Jazz Age. ", TableOfContents: toc})
+
+		if other, err := s.FindPublicationByID(ctx, pub.ID); err != nil {
+			t.Fatal(err)
+		} else if other.Description != "A novel of the Jazz Age." || !slices.Equal(other.TableOfContents, toc) {
+			t.Fatalf("Description=%q TableOfContents=%q", other.Description, other.TableOfContents)
+		}
+
+		if updated, err := s.UpdatePublication(ctx, pub.ID, bookid.PublicationUpdate{Description: ptr(""), TableOfContents: &[]string{}}); err != nil {
+			t.Fatal(err)
+		} else if updated.Description != "" || len(updated.TableOfContents) != 0 {
+			t.Fatalf("Description=%q TableOfContents=%q, want empty", updated.Description, updated.TableOfContents)
+		}
+	})
+
+	t.Run("RefreshedBefore", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewPublicationService(db)
+		ctx := context.Background()
+
+		created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		db.Now = func() time.Time { return created }
+		work := MustCreateWork(t, ctx, db, &bookid.Work{Title: "Dune"})
+		stale := MustCreatePublication(t, ctx, db, &bookid.Publication{WorkID: work.ID, ISBN13: "9780441172719"})
+		fresh := MustCreatePublication(t, ctx, db, &bookid.Publication{WorkID: work.ID, ISBN13: "9780593099322"})
+		MustCreatePublication(t, ctx, db, &bookid.Publication{WorkID: work.ID, ISBN13: "9780156027601"})
+
+		refreshed := created.AddDate(0, 6, 0)
+		if _, err := s.UpdatePublication(ctx, stale.ID, bookid.PublicationUpdate{RefreshedAt: ptr(created.AddDate(0, 1, 0))}); err != nil {
+			t.Fatal(err)
+		} else if pub, err := s.UpdatePublication(ctx, fresh.ID, bookid.PublicationUpdate{RefreshedAt: &refreshed}); err != nil {
+			t.Fatal(err)
+		} else if !pub.RefreshedAt.Equal(refreshed) {
+			t.Fatalf("RefreshedAt=%v, want %v", pub.RefreshedAt, refreshed)
+		}
+
+		// Publications never refreshed count from their creation.
+		before := created.AddDate(0, 3, 0)
+		if pubs, n, err := s.FindPublications(ctx, bookid.PublicationFilter{RefreshedBefore: &before}); err != nil {
+			t.Fatal(err)
+		} else if n != 2 {
+			t.Fatalf("n=%d, want 2", n)
+		} else if pubs[0].ID != stale.ID {
+			t.Fatalf("ID=%d, want %d", pubs[0].ID, stale.ID)
+		}
+	})
+}
+
+func TestPublicationService_UpdatePublication(t *testing.T) {
+	t.Parallel()
+
+	t.Run("OK", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewPublicationService(db)
+		ctx := context.Background()
+
+		work := MustCreateWork(t, ctx, db, &bookid.Work{Title: "Dune"})
+		other := MustCreateWork(t, ctx, db, &bookid.Work{Title: "Dune Messiah"})
+		pub := MustCreatePublication(t, ctx, db, &bookid.Publication{WorkID: work.ID, Publisher: "Ace"})
+
+		updated, err := s.UpdatePublication(ctx, pub.ID, bookid.PublicationUpdate{
+			WorkID:    &other.ID,
+			Publisher: ptr("Chilton"),
+			ISBN13:    ptr("978-0-441-17271-9"),
+		})
+		if err != nil {
+			t.Fatal(err)
+		} else if got, want := updated.WorkID, other.ID; got != want {
+			t.Fatalf("WorkID=%d, want %d", got, want)
+		} else if got, want := updated.ISBN13, "9780441172719"; got != want {
+			t.Fatalf("ISBN13=%q, want %q", got, want)
+		}
+
+		if found, err := s.FindPublicationByID(ctx, pub.ID); err != nil {
+			t.Fatal(err)
+		} else if !reflect.DeepEqual(updated, found) {
+			t.Fatalf("mismatch: %#v != %#v", updated, found)
+		}
+	})
+
+	t.Run("PersonalMetadata", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewPublicationService(db)
+		ctx := context.Background()
+
+		work := MustCreateWork(t, ctx, db, &bookid.Work{Title: "Dune"})
+		pub := MustCreatePublication(t, ctx, db, &bookid.Publication{WorkID: work.ID, ISBN13: "9780441172719"})
+
+		status := bookid.ReadingStatusRead
+		acquired := time.Date(2024, 3, 9, 18, 30, 0, 0, time.FixedZone("", 2*60*60))
+		updated, err := s.UpdatePublication(ctx, pub.ID, bookid.PublicationUpdate{
+			ReadingStatus: &status,
+			Rating:        ptr(4),
+			Notes:         ptr(" Reread the appendices. "),
+			AcquiredAt:    &acquired,
+			Location:      ptr("B3"),
+		})
+		if err != nil {
+			t.Fatal(err)
+		} else if got, want := updated.Notes, "Reread the appendices."; got != want {
+			t.Fatalf("Notes=%q, want %q", got, want)
+		} else if got, want := updated.AcquiredAt, time.Date(2024, 3, 9, 0, 0, 0, 0, time.UTC); !got.Equal(want) {
+			t.Fatalf("AcquiredAt=%v, want %v", got, want)
+		}
+
+		if found, err := s.FindPublicationByID(ctx, pub.ID); err != nil {
+			t.Fatal(err)
+		} else if !reflect.DeepEqual(updated, found) {
+			t.Fatalf("mismatch: %#v != %#v", updated, found)
+		}
+
+		// Saving the publication again from a provider keeps the metadata.
+		if err := s.UpsertPublication(ctx, &bookid.Publication{WorkID: work.ID, ISBN13: "9780441172719", Publisher: "Ace"}); err != nil {
+			t.Fatal(err)
+		} else if found, err := s.FindPublicationByID(ctx, pub.ID); err != nil {
+			t.Fatal(err)
+		} else if found.ReadingStatus != status || found.Rating != 4 || found.Location != "B3" {
+			t.Fatalf("unexpected personal metadata: %#v", found)
+		}
+	})
+
+	t.Run("Provenance", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewPublicationService(db)
+		ctx := context.Background()
+
+		work := MustCreateWork(t, ctx, db, &bookid.Work{Title: "Dune"})
+		pub := MustCreatePublication(t, ctx, db, &bookid.Publication{
+			WorkID:        work.ID,
+			Publisher:     "Ace",
+			PublishedYear: 1990,
+			Language:      "en",
+			Provenance:    map[string]string{"publisher": "googlebooks", "published_year": "googlebooks", "language": "googlebooks"},
+		})
+
+		// Fields from providers replace the providers of those fields, and
+		// fields set by the user lose theirs.
+		updated, err := s.UpdatePublication(ctx, pub.ID, bookid.PublicationUpdate{
+			Publisher:     ptr("Chilton"),
+			PublishedYear: ptr(1965),
+			Provenance:    map[string]string{"publisher": "openlibrary"},
+		})
+		if err != nil {
+			t.Fatal(err)
+		} else if got, want := updated.Provenance, map[string]string{"publisher": "openlibrary", "language": "googlebooks"}; !reflect.DeepEqual(got, want) {
+			t.Fatalf("Provenance=%v, want %v", got, want)
+		}
+
+		if found, err := s.FindPublicationByID(ctx, pub.ID); err != nil {
+			t.Fatal(err)
+		} else if !reflect.DeepEqual(updated, found) {
+			t.Fatalf("mismatch: %#v != %#v", updated, found)
+		}
+	})
+
+	t.Run("ErrInvalidPersonalMetadata", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewPublicationService(db)
+		ctx := context.Background()
+
+		work := MustCreateWork(t, ctx, db, &bookid.Work{Title: "Dune"})
+		pub := MustCreatePublication(t, ctx, db, &bookid.Publication{WorkID: work.ID})
+
+		status := bookid.ReadingStatus("skimmed")
+		if _, err := s.UpdatePublication(ctx, pub.ID, bookid.PublicationUpdate{ReadingStatus: &status}); bookid.ErrorCode(err) != bookid.EINVALID {
+			t.Fatalf("ErrorCode()=%q, want %q", bookid.ErrorCode(err), bookid.EINVALID)
+		} else if _, err := s.UpdatePublication(ctx, pub.ID, bookid.PublicationUpdate{Rating: ptr(6)}); bookid.ErrorCode(err) != bookid.EINVALID {
+			t.Fatalf("ErrorCode()=%q, want %q", bookid.ErrorCode(err), bookid.EINVALID)
+		}
+	})
+
+	t.Run("ErrNotFound", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewPublicationService(db)
+
+		if _, err := s.UpdatePublication(context.Background(), 1, bookid.PublicationUpdate{}); bookid.ErrorCode(err) != bookid.ENOTFOUND {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+}
+
+func TestPublicationService_DeletePublication(t *testing.T) {
+	t.Parallel()
+
+	t.Run("OK", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewPublicationService(db)
+		ctx := context.Background()
+
+		work := MustCreateWork(t, ctx, db, &bookid.Work{Title: "Dune"})
+		pub := MustCreatePublication(t, ctx, db, &bookid.Publication{WorkID: work.ID})
+		if err := s.DeletePublication(ctx, pub.ID); err != nil {
+			t.Fatal(err)
+		} else if _, err := s.FindPublicationByID(ctx, pub.ID); bookid.ErrorCode(err) != bookid.ENOTFOUND {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+
+	t.Run("ErrNotFound", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewPublicationService(db)
+
+		if err := s.DeletePublication(context.Background(), 1); bookid.ErrorCode(err) != bookid.ENOTFOUND {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+}
+
+func TestPublicationService_RestorePublication(t *testing.T) {
+	t.Parallel()
+
+	t.Run("RestoresWork", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewPublicationService(db)
+		works := sqlite.NewWorkService(db)
+		ctx := context.Background()
+
+		work := MustCreateWork(t, ctx, db, &bookid.Work{Title: "Dune"})
+		pub := MustCreatePublication(t, ctx, db, &bookid.Publication{WorkID: work.ID})
+		if err := works.DeleteWork(ctx, work.ID); err != nil {
+			t.Fatal(err)
+		} else if err := s.RestorePublication(ctx, pub.ID); err != nil {
+			t.Fatal(err)
+		} else if _, err := s.FindPublicationByID(ctx, pub.ID); err != nil {
+			t.Fatal(err)
+		} else if _, err := works.FindWorkByID(ctx, work.ID); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("ErrNotFound", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewPublicationService(db)
+
+		if err := s.RestorePublication(context.Background(), 1); bookid.ErrorCode(err) != bookid.ENOTFOUND {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+}
+
+func TestPublicationService_PurgePublication(t *testing.T) {
+	t.Parallel()
+
+	t.Run("OK", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewPublicationService(db)
+		ctx := context.Background()
+
+		work := MustCreateWork(t, ctx, db, &bookid.Work{Title: "Dune"})
+		pub := MustCreatePublication(t, ctx, db, &bookid.Publication{WorkID: work.ID, ISBN13: "9780441172719"})
+		if err := s.DeletePublication(ctx, pub.ID); err != nil {
+			t.Fatal(err)
+		} else if err := s.PurgePublication(ctx, pub.ID); err != nil {
+			t.Fatal(err)
+		}
+
+		// The ISBN is free to be cataloged again.
+		MustCreatePublication(t, ctx, db, &bookid.Publication{WorkID: work.ID, ISBN13: "9780441172719"})
+	})
+}
+
+// MustCreatePublication creates a publication in the database. Fatal on error.
+func MustCreatePublication(tb testing.TB, ctx context.Context, db *sqlite.DB, pub *bookid.Publication) *bookid.Publication {
+	tb.Helper()
+	if err := sqlite.NewPublicationService(db).CreatePublication(ctx, pub); err != nil {
+		tb.Fatal(err)
+	}
+	return pub
+}
+
+// ptr returns a pointer to v.
+func ptr[T any](v T) *T {
+	return &v
+}
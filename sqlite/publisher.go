@@ -0,0 +1,202 @@
+package sqlite
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/publisher"
+)
+
+// Ensure service implements interface.
+var _ bookid.PublisherService = (*PublisherService)(nil)
+
+// PublisherService represents a service for managing publishers.
+type PublisherService struct {
+	db *DB
+}
+
+// NewPublisherService returns a new instance of PublisherService.
+func NewPublisherService(db *DB) *PublisherService {
+	return &PublisherService{db: db}
+}
+
+// FindPublisherByID retrieves a single publisher by ID.
+// Returns ENOTFOUND if the publisher does not exist.
+func (s *PublisherService) FindPublisherByID(ctx context.Context, id int64) (*bookid.Publisher, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+	return findPublisherByID(ctx, tx, id)
+}
+
+// FindPublishers retrieves a list of publishers matching the filter.
+func (s *PublisherService) FindPublishers(ctx context.Context, filter bookid.PublisherFilter) ([]*bookid.Publisher, int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer func() { _ = tx.Rollback() }()
+	return findPublishers(ctx, tx, filter)
+}
+
+// CreatePublisher creates a new publisher, or populates publisher from the
+// existing one with the same normalized name.
+func (s *PublisherService) CreatePublisher(ctx context.Context, publisher *bookid.Publisher) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := createPublisher(ctx, tx, publisher); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// MergePublishers merges duplicate source publishers into the target.
+func (s *PublisherService) MergePublishers(ctx context.Context, targetID int64, sourceIDs ...int64) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := mergePublishers(ctx, tx, targetID, sourceIDs); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// findPublisherByID is a helper function to fetch a publisher by ID.
+// Returns ENOTFOUND if the publisher does not exist.
+func findPublisherByID(ctx context.Context, tx *Tx, id int64) (*bookid.Publisher, error) {
+	publishers, _, err := findPublishers(ctx, tx, bookid.PublisherFilter{ID: &id})
+	if err != nil {
+		return nil, err
+	} else if len(publishers) == 0 {
+		return nil, bookid.Errorf(bookid.ENOTFOUND, "Publisher not found.")
+	}
+	return publishers[0], nil
+}
+
+// findPublishers returns a list of publishers matching a filter, those with
+// the most publications first. Also returns a count of total matching
+// publishers which may differ if filter.Limit is set.
+func findPublishers(ctx context.Context, tx *Tx, filter bookid.PublisherFilter) (_ []*bookid.Publisher, n int, err error) {
+	where, args := []string{"1 = 1"}, []any{}
+	if v := filter.ID; v != nil {
+		where, args = append(where, "p.id = ?"), append(args, *v)
+	}
+	if v := filter.Name; v != nil {
+		where, args = append(where, "p.name = ?"), append(args, publisher.Name(*v))
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT
+			p.id,
+			p.name,
+			p.created_at,
+			(SELECT COUNT(*) FROM publications WHERE publisher_id = p.id AND deleted_at IS NULL) AS publication_count,
+			COUNT(*) OVER ()
+		FROM publishers p
+		WHERE `+strings.Join(where, " AND ")+`
+		ORDER BY publication_count DESC, p.name ASC
+		`+FormatLimitOffset(filter.Limit, filter.Offset),
+		args...,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	publishers := make([]*bookid.Publisher, 0)
+	for rows.Next() {
+		var p bookid.Publisher
+		if err := rows.Scan(&p.ID, &p.Name, (*NullTime)(&p.CreatedAt), &p.PublicationCount, &n); err != nil {
+			return nil, 0, err
+		}
+		publishers = append(publishers, &p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+	return publishers, n, nil
+}
+
+// createPublisher normalizes the publisher's name and inserts a new
+// publisher unless one with the same name exists, in which case p is
+// populated from the existing row.
+func createPublisher(ctx context.Context, tx *Tx, p *bookid.Publisher) error {
+	p.Name = publisher.Name(p.Name)
+	if err := p.Validate(); err != nil {
+		return err
+	}
+
+	if existing, _, err := findPublishers(ctx, tx, bookid.PublisherFilter{Name: &p.Name}); err != nil {
+		return err
+	} else if len(existing) > 0 {
+		*p = *existing[0]
+		return nil
+	}
+
+	p.CreatedAt, p.PublicationCount = tx.now, 0
+	result, err := tx.ExecContext(ctx, `INSERT INTO publishers (name, created_at) VALUES (?, ?)`, p.Name, (*NullTime)(&p.CreatedAt))
+	if err != nil {
+		return FormatError(err)
+	}
+	if p.ID, err = result.LastInsertId(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// linkPublisher links a publication to the publisher named by its Publisher
+// field, creating the publisher if needed, and gives the publication the
+// publisher's name.
+func linkPublisher(ctx context.Context, tx *Tx, pub *bookid.Publication) error {
+	if pub.Publisher = publisher.Name(pub.Publisher); pub.Publisher == "" {
+		pub.PublisherID = 0
+		return nil
+	}
+	p := &bookid.Publisher{Name: pub.Publisher}
+	if err := createPublisher(ctx, tx, p); err != nil {
+		return err
+	}
+	pub.Publisher, pub.PublisherID = p.Name, p.ID
+	return nil
+}
+
+// mergePublishers moves the publications of each source publisher to the
+// target, renaming them, and deletes the sources.
+func mergePublishers(ctx context.Context, tx *Tx, targetID int64, sourceIDs []int64) error {
+	if len(sourceIDs) == 0 {
+		return bookid.Errorf(bookid.EINVALID, "Publishers to merge required.")
+	} else if slices.Contains(sourceIDs, targetID) {
+		return bookid.Errorf(bookid.EINVALID, "A publisher cannot be merged into itself.")
+	}
+	sourceIDs = slices.Compact(slices.Sorted(slices.Values(sourceIDs)))
+
+	target, err := findPublisherByID(ctx, tx, targetID)
+	if err != nil {
+		return err
+	}
+	for _, id := range sourceIDs {
+		if _, err := findPublisherByID(ctx, tx, id); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE publications SET publisher_id = ?, publisher = ?, updated_at = ? WHERE publisher_id = ?
+		`, targetID, target.Name, (*NullTime)(&tx.now), id); err != nil {
+			return FormatError(err)
+		}
+		if _, err := tx.ExecContext(ctx, `DELETE FROM publishers WHERE id = ?`, id); err != nil {
+			return FormatError(err)
+		}
+	}
+	return nil
+}
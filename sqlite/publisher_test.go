@@ -0,0 +1,143 @@
+package sqlite_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/sqlite"
+)
+
+func TestPublisherService_CreatePublisher(t *testing.T) {
+	t.Parallel()
+
+	t.Run("OK", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewPublisherService(db)
+		ctx := context.Background()
+
+		p := &bookid.Publisher{Name: "Penguin Books Ltd"}
+		if err := s.CreatePublisher(ctx, p); err != nil {
+			t.Fatal(err)
+		} else if got, want := p.Name, "Penguin"; got != want {
+			t.Fatalf("Name=%q, want %q", got, want)
+		} else if p.ID == 0 || p.CreatedAt.IsZero() {
+			t.Fatal("expected ID and created at")
+		}
+
+		// Variants of the name return the existing publisher.
+		other := &bookid.Publisher{Name: "PENGUIN"}
+		if err := s.CreatePublisher(ctx, other); err != nil {
+			t.Fatal(err)
+		} else if got, want := other.ID, p.ID; got != want {
+			t.Fatalf("ID=%d, want %d", got, want)
+		}
+	})
+
+	t.Run("ErrInvalid", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewPublisherService(db)
+
+		if err := s.CreatePublisher(context.Background(), &bookid.Publisher{Name: "[s.n.]"}); bookid.ErrorCode(err) != bookid.EINVALID {
+			t.Fatalf("ErrorCode()=%q, want %q", bookid.ErrorCode(err), bookid.EINVALID)
+		}
+	})
+}
+
+func TestPublisherService_FindPublishers(t *testing.T) {
+	t.Parallel()
+	db := MustOpenDB(t)
+	defer MustCloseDB(t, db)
+	s := sqlite.NewPublisherService(db)
+	ctx := context.Background()
+
+	work := MustCreateWork(t, ctx, db, &bookid.Work{Title: "Dune"})
+	first := MustCreatePublication(t, ctx, db, &bookid.Publication{WorkID: work.ID, ISBN13: "9780441172719", Publisher: "Ace Books"})
+	MustCreatePublication(t, ctx, db, &bookid.Publication{WorkID: work.ID, ISBN13: "9780441013593", Publisher: "ace"})
+	MustCreatePublication(t, ctx, db, &bookid.Publication{WorkID: work.ID, ISBN13: "9780340960196", Publisher: "Hodder & Stoughton Ltd"})
+
+	if got, want := first.Publisher, "Ace"; got != want {
+		t.Fatalf("Publisher=%q, want %q", got, want)
+	} else if first.PublisherID == 0 {
+		t.Fatal("expected publisher ID")
+	}
+
+	publishers, n, err := s.FindPublishers(ctx, bookid.PublisherFilter{})
+	if err != nil {
+		t.Fatal(err)
+	} else if got, want := n, 2; got != want {
+		t.Fatalf("n=%d, want %d", got, want)
+	} else if got, want := publishers[0].Name, "Ace"; got != want {
+		t.Fatalf("Name=%q, want %q", got, want)
+	} else if got, want := publishers[0].PublicationCount, 2; got != want {
+		t.Fatalf("PublicationCount=%d, want %d", got, want)
+	} else if got, want := publishers[1].Name, "Hodder & Stoughton"; got != want {
+		t.Fatalf("Name=%q, want %q", got, want)
+	}
+
+	pubs, _, err := sqlite.NewPublicationService(db).FindPublications(ctx, bookid.PublicationFilter{PublisherID: &first.PublisherID})
+	if err != nil {
+		t.Fatal(err)
+	} else if got, want := len(pubs), 2; got != want {
+		t.Fatalf("len=%d, want %d", got, want)
+	}
+}
+
+func TestPublisherService_MergePublishers(t *testing.T) {
+	t.Parallel()
+
+	t.Run("OK", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewPublisherService(db)
+		ctx := context.Background()
+
+		work := MustCreateWork(t, ctx, db, &bookid.Work{Title: "Dune"})
+		target := MustCreatePublication(t, ctx, db, &bookid.Publication{WorkID: work.ID, ISBN13: "9780441172719", Publisher: "Ace"})
+		source := MustCreatePublication(t, ctx, db, &bookid.Publication{WorkID: work.ID, ISBN13: "9780441013593", Publisher: "Ace Science Fiction"})
+
+		if err := s.MergePublishers(ctx, target.PublisherID, source.PublisherID); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := s.FindPublisherByID(ctx, source.PublisherID); bookid.ErrorCode(err) != bookid.ENOTFOUND {
+			t.Fatalf("ErrorCode()=%q, want %q", bookid.ErrorCode(err), bookid.ENOTFOUND)
+		}
+		if p, err := s.FindPublisherByID(ctx, target.PublisherID); err != nil {
+			t.Fatal(err)
+		} else if got, want := p.PublicationCount, 2; got != want {
+			t.Fatalf("PublicationCount=%d, want %d", got, want)
+		}
+		if pub, err := sqlite.NewPublicationService(db).FindPublicationByID(ctx, source.ID); err != nil {
+			t.Fatal(err)
+		} else if got, want := pub.Publisher, "Ace"; got != want {
+			t.Fatalf("Publisher=%q, want %q", got, want)
+		} else if got, want := pub.PublisherID, target.PublisherID; got != want {
+			t.Fatalf("PublisherID=%d, want %d", got, want)
+		}
+	})
+
+	t.Run("ErrInvalid", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewPublisherService(db)
+		ctx := context.Background()
+
+		p := &bookid.Publisher{Name: "Ace"}
+		if err := s.CreatePublisher(ctx, p); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.MergePublishers(ctx, p.ID); bookid.ErrorCode(err) != bookid.EINVALID {
+			t.Fatalf("ErrorCode()=%q, want %q", bookid.ErrorCode(err), bookid.EINVALID)
+		} else if err := s.MergePublishers(ctx, p.ID, p.ID); bookid.ErrorCode(err) != bookid.EINVALID {
+			t.Fatalf("ErrorCode()=%q, want %q", bookid.ErrorCode(err), bookid.EINVALID)
+		} else if err := s.MergePublishers(ctx, p.ID, 99); bookid.ErrorCode(err) != bookid.ENOTFOUND {
+			t.Fatalf("ErrorCode()=%q, want %q", bookid.ErrorCode(err), bookid.ENOTFOUND)
+		}
+	})
+}
@@ -0,0 +1,380 @@
+package sqlite
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"slices"
+	"strings"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/language"
+	"github.com/fwojciec/bookid/match"
+)
+
+// Ensure service implements interface.
+var _ bookid.WorkRebuildService = (*CatalogService)(nil)
+
+// RebuildWorks clusters every publication afresh with the current matching
+// rules in a single transaction and returns the works that change. The
+// changes are only written if apply is set.
+//
+// Publications are described by the title and authors of their raw Google
+// Books data where they have it, and by those of their work otherwise, and
+// are clustered in the order they were cataloged. Each cluster keeps the
+// work of its earliest publication that no earlier cluster kept; clusters
+// left without one get a new work. Works left without publications are
+// merged into the work that took over their first publication, which keeps
+// their relations, series, subjects and collections.
+func (s *CatalogService) RebuildWorks(ctx context.Context, apply bool) ([]*bookid.WorkCluster, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	pubs, links, err := findRebuildPublications(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+	clusters := s.clusterPublications(pubs)
+	planWorks(clusters, links)
+
+	changed := make([]*workCluster, 0, len(clusters))
+	for _, c := range clusters {
+		if c.changed() {
+			changed = append(changed, c)
+		}
+	}
+	if apply {
+		for _, c := range changed {
+			if err := rebuildWork(ctx, tx, c); err != nil {
+				return nil, err
+			}
+		}
+		if err := tx.Commit(); err != nil {
+			return nil, err
+		}
+	}
+
+	works := make([]*bookid.WorkCluster, len(changed))
+	for i, c := range changed {
+		works[i] = &c.WorkCluster
+	}
+	return works, nil
+}
+
+// rebuildPublication is a publication along with the title and authors it is
+// clustered by.
+type rebuildPublication struct {
+	pub     *bookid.Publication
+	work    *bookid.Work
+	title   string
+	authors []*bookid.Author // Linked authors have an ID
+}
+
+// findRebuildPublications returns the publications of all works, in the order
+// they were cataloged, along with the IDs of the authors linked to each work.
+// Works in the trash are left alone.
+func findRebuildPublications(ctx context.Context, tx *Tx) ([]*rebuildPublication, map[int64][]int64, error) {
+	works, _, err := findWorks(ctx, tx, bookid.WorkFilter{})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var pubs []*rebuildPublication
+	links := make(map[int64][]int64)
+	for _, work := range works {
+		linked, _, err := findAuthors(ctx, tx, bookid.AuthorFilter{WorkID: &work.ID})
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, a := range linked {
+			links[work.ID] = append(links[work.ID], a.ID)
+		}
+		authors := linked
+		if len(authors) == 0 {
+			authors = namedAuthors(strings.Split(work.Author, ", "), nil)
+		}
+
+		workPubs, _, err := findPublications(ctx, tx, bookid.PublicationFilter{WorkID: &work.ID})
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, pub := range workPubs {
+			p := &rebuildPublication{pub: pub, work: work, title: work.Title, authors: authors}
+			if title, names := rawTitle(pub.GoogleBooksData); title != "" {
+				p.title, p.authors = title, namedAuthors(names, linked)
+				// Raw data names the authors only.
+				for _, a := range linked {
+					if a.Role != bookid.ContributorRoleAuthor {
+						p.authors = append(p.authors, a)
+					}
+				}
+			}
+			pubs = append(pubs, p)
+		}
+	}
+	slices.SortFunc(pubs, func(a, b *rebuildPublication) int { return cmp.Compare(a.pub.ID, b.pub.ID) })
+	return pubs, links, nil
+}
+
+// namedAuthors returns the authors with the given names, resolving each to
+// the linked author of the same name, if any.
+func namedAuthors(names []string, linked []*bookid.Author) []*bookid.Author {
+	var authors []*bookid.Author
+	for _, name := range names {
+		key := authorNameKey(name)
+		if key == "" {
+			continue
+		}
+		author := &bookid.Author{Name: strings.TrimSpace(name)}
+		for _, a := range linked {
+			if authorNameKey(a.Name) == key {
+				author = a
+				break
+			}
+		}
+		authors = append(authors, author)
+	}
+	return authors
+}
+
+// rawTitle returns the title and authors of a raw Google Books volume, or an
+// empty title if there is none.
+func rawTitle(data string) (string, []string) {
+	var volume struct {
+		VolumeInfo struct {
+			Title   string   `json:"title"`
+			Authors []string `json:"authors"`
+		} `json:"volumeInfo"`
+	}
+	if data == "" || json.Unmarshal([]byte(data), &volume) != nil {
+		return "", nil
+	}
+	return strings.TrimSpace(volume.VolumeInfo.Title), volume.VolumeInfo.Authors
+}
+
+// workCluster is a cluster of publications of the same work.
+type workCluster struct {
+	bookid.WorkCluster
+
+	title   string // Main title of the first publication
+	keys    map[string]bool
+	authors []*bookid.Author
+	pubs    []*rebuildPublication
+
+	// IDs of the authors linked to the kept work.
+	links []int64
+}
+
+// clusterPublications groups publications into works as findMatchingWork
+// does: a publication joins the cluster whose main title is most similar,
+// provided it shares an author and is not in another language only.
+// Publications without a main title stay with the other publications of
+// their work.
+func (s *CatalogService) clusterPublications(pubs []*rebuildPublication) []*workCluster {
+	var clusters []*workCluster
+	for _, p := range pubs {
+		title := mainTitle(p.title)
+
+		var best *workCluster
+		bestScore := s.MatchThreshold
+		for _, c := range clusters {
+			if title == "" || c.title == "" {
+				if title == c.title && c.pubs[0].pub.WorkID == p.pub.WorkID {
+					best = c
+					break
+				}
+				continue
+			}
+			score := match.LevenshteinSimilarity(title, c.title)
+			if score < bestScore || !c.sharesAuthor(p.authors) || c.inOtherLanguage(p.pub.Language) {
+				continue
+			}
+			// Ties go to the cluster started first.
+			if score > bestScore || best == nil {
+				best, bestScore = c, score
+			}
+		}
+
+		if best == nil {
+			best = &workCluster{title: title, keys: make(map[string]bool)}
+			best.Title = p.title
+			clusters = append(clusters, best)
+		}
+		best.add(p)
+	}
+	return clusters
+}
+
+// add adds a publication and its authors to the cluster.
+func (c *workCluster) add(p *rebuildPublication) {
+	c.pubs = append(c.pubs, p)
+	c.PublicationIDs = append(c.PublicationIDs, p.pub.ID)
+	for _, a := range p.authors {
+		key := authorNameKey(a.Name)
+		if key == "" {
+			continue
+		} else if !c.keys[key] {
+			c.keys[key] = true
+			c.authors = append(c.authors, a)
+			continue
+		}
+		// Prefer the author already linked over a name from raw data.
+		for i, other := range c.authors {
+			if authorNameKey(other.Name) == key && other.ID == 0 {
+				c.authors[i] = a
+			}
+		}
+	}
+}
+
+// sharesAuthor reports whether any of authors is an author of the cluster.
+// Translators and other contributors do not count. Clusters and publications
+// without authors only match each other.
+func (c *workCluster) sharesAuthor(authors []*bookid.Author) bool {
+	keys := make(map[string]bool)
+	for _, a := range c.authors {
+		if isAuthor(a) {
+			keys[authorNameKey(a.Name)] = true
+		}
+	}
+	var names []string
+	for _, a := range authors {
+		if isAuthor(a) {
+			names = append(names, a.Name)
+		}
+	}
+
+	if len(keys) == 0 || len(names) == 0 {
+		return len(keys) == 0 && len(names) == 0
+	}
+	for _, name := range names {
+		if keys[authorNameKey(name)] {
+			return true
+		}
+	}
+	return false
+}
+
+// isAuthor reports whether a is an author rather than another contributor.
+// Names without a role come from raw data, which only names authors.
+func isAuthor(a *bookid.Author) bool {
+	return a.Role == "" || a.Role == bookid.ContributorRoleAuthor
+}
+
+// inOtherLanguage reports whether all publications of the cluster have a
+// known language other than lang. Returns false if lang is unknown.
+func (c *workCluster) inOtherLanguage(lang string) bool {
+	if lang == "" {
+		return false
+	}
+	for _, p := range c.pubs {
+		if p.pub.Language == "" || language.Base(p.pub.Language) == language.Base(lang) {
+			return false
+		}
+	}
+	return true
+}
+
+// planWorks decides the work each cluster keeps and the works merged into
+// it. Links holds the IDs of the authors linked to each work.
+func planWorks(clusters []*workCluster, links map[int64][]int64) {
+	kept := make(map[int64]bool)
+	for _, c := range clusters {
+		for _, p := range c.pubs {
+			if !kept[p.pub.WorkID] {
+				c.WorkID, c.Title, kept[p.pub.WorkID] = p.work.ID, p.work.Title, true
+				break
+			}
+		}
+	}
+
+	// Works that are not kept are merged into the work that takes over
+	// their first publication.
+	merged := make(map[int64]bool)
+	for _, c := range clusters {
+		for _, p := range c.pubs {
+			if id := p.pub.WorkID; id != c.WorkID && !slices.Contains(c.MovedFrom, id) {
+				c.MovedFrom = append(c.MovedFrom, id)
+			}
+			if id := p.pub.WorkID; !kept[id] && !merged[id] {
+				c.Merged, merged[id] = append(c.Merged, id), true
+			}
+		}
+
+		c.Authors = make([]string, len(c.authors))
+		for i, a := range c.authors {
+			c.Authors[i] = a.Name
+		}
+		c.links = links[c.WorkID]
+	}
+}
+
+// changed reports whether rebuilding the cluster changes the catalog.
+func (c *workCluster) changed() bool {
+	if c.WorkID == 0 || len(c.MovedFrom) > 0 || len(c.links) != len(c.authors) {
+		return true
+	}
+	for _, a := range c.authors {
+		if a.ID == 0 || !slices.Contains(c.links, a.ID) {
+			return true
+		}
+	}
+	return false
+}
+
+// rebuildWork writes a cluster to the catalog: its work is created if new,
+// its publications are moved to it, the works left without publications are
+// merged into it and its author links are rewritten.
+func rebuildWork(ctx context.Context, tx *Tx, c *workCluster) error {
+	if c.WorkID == 0 {
+		work := &bookid.Work{Title: c.Title, Author: strings.Join(c.Authors, ", ")}
+		if err := createWork(ctx, tx, work); err != nil {
+			return err
+		}
+		c.WorkID = work.ID
+	}
+
+	for _, p := range c.pubs {
+		if p.pub.WorkID == c.WorkID {
+			continue
+		}
+		if _, err := updatePublication(ctx, tx, p.pub.ID, bookid.PublicationUpdate{WorkID: &c.WorkID}); err != nil {
+			return err
+		}
+	}
+	if len(c.Merged) > 0 {
+		if err := mergeWorks(ctx, tx, c.WorkID, c.Merged); err != nil {
+			return err
+		}
+	}
+
+	// Merging added the links of the merged works, so the links are read
+	// again before they are rewritten.
+	linked, _, err := findAuthors(ctx, tx, bookid.AuthorFilter{WorkID: &c.WorkID})
+	if err != nil {
+		return err
+	}
+	var want []int64
+	for _, a := range c.authors {
+		if a.ID == 0 {
+			if err := createAuthor(ctx, tx, a); err != nil {
+				return err
+			}
+		}
+		want = append(want, a.ID)
+		if err := addWorkAuthor(ctx, tx, &bookid.WorkAuthor{WorkID: c.WorkID, AuthorID: a.ID, Role: a.Role}); err != nil {
+			return err
+		}
+	}
+	for _, a := range linked {
+		if slices.Contains(want, a.ID) {
+			continue
+		} else if err := removeWorkAuthor(ctx, tx, &bookid.WorkAuthor{WorkID: c.WorkID, AuthorID: a.ID}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
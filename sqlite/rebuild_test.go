@@ -0,0 +1,179 @@
+package sqlite_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/sqlite"
+)
+
+func TestCatalogService_RebuildWorks(t *testing.T) {
+	t.Parallel()
+	db := MustOpenDB(t)
+	defer MustCloseDB(t, db)
+	ctx := context.Background()
+	s := sqlite.NewCatalogService(db)
+
+	// Dune Messiah was once clustered with Dune, and Dune was cataloged
+	// twice.
+	herbert := &bookid.Author{Name: "Frank Herbert"}
+	if err := sqlite.NewAuthorService(db).CreateAuthor(ctx, herbert); err != nil {
+		t.Fatal(err)
+	}
+	dune := mustCreateLinkedWork(t, ctx, db, "Dune", herbert)
+	duplicate := mustCreateLinkedWork(t, ctx, db, "Dune", herbert)
+	first := MustCreatePublication(t, ctx, db, &bookid.Publication{WorkID: dune.ID, ISBN13: "9780441172719"})
+	messiah := MustCreatePublication(t, ctx, db, &bookid.Publication{
+		WorkID:          dune.ID,
+		ISBN13:          "9780441172696",
+		GoogleBooksData: `{"volumeInfo":{"title":"Dune Messiah","authors":["Frank Herbert"]}}`,
+	})
+	second := MustCreatePublication(t, ctx, db, &bookid.Publication{WorkID: duplicate.ID, ISBN13: "9780593099322"})
+	solaris := mustCreateLinkedWork(t, ctx, db, "Solaris", &bookid.Author{Name: "Stanisław Lem"})
+	MustCreatePublication(t, ctx, db, &bookid.Publication{WorkID: solaris.ID})
+
+	want := []*bookid.WorkCluster{
+		{
+			WorkID:         dune.ID,
+			Title:          "Dune",
+			Authors:        []string{"Frank Herbert"},
+			PublicationIDs: []int64{first.ID, second.ID},
+			MovedFrom:      []int64{duplicate.ID},
+			Merged:         []int64{duplicate.ID},
+		},
+		{
+			Title:          "Dune Messiah",
+			Authors:        []string{"Frank Herbert"},
+			PublicationIDs: []int64{messiah.ID},
+			MovedFrom:      []int64{dune.ID},
+		},
+	}
+
+	// A dry run changes nothing.
+	if works, err := s.RebuildWorks(ctx, false); err != nil {
+		t.Fatal(err)
+	} else if !reflect.DeepEqual(works, want) {
+		t.Fatalf("mismatch: %#v != %#v", works, want)
+	} else if _, err := sqlite.NewWorkService(db).FindWorkByID(ctx, duplicate.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	works, err := s.RebuildWorks(ctx, true)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(works) != 2 || works[1].WorkID == 0 {
+		t.Fatalf("unexpected works: %#v", works)
+	}
+	if _, err := sqlite.NewWorkService(db).FindWorkByID(ctx, duplicate.ID); bookid.ErrorCode(err) != bookid.ENOTFOUND {
+		t.Fatalf("ErrorCode()=%q, want %q", bookid.ErrorCode(err), bookid.ENOTFOUND)
+	}
+	if pub, err := sqlite.NewPublicationService(db).FindPublicationByID(ctx, second.ID); err != nil {
+		t.Fatal(err)
+	} else if got, want := pub.WorkID, dune.ID; got != want {
+		t.Fatalf("WorkID=%d, want %d", got, want)
+	}
+	if pub, err := sqlite.NewPublicationService(db).FindPublicationByID(ctx, messiah.ID); err != nil {
+		t.Fatal(err)
+	} else if got, want := pub.WorkID, works[1].WorkID; got != want {
+		t.Fatalf("WorkID=%d, want %d", got, want)
+	}
+	if authors, _, err := sqlite.NewAuthorService(db).FindAuthors(ctx, bookid.AuthorFilter{WorkID: &works[1].WorkID}); err != nil {
+		t.Fatal(err)
+	} else if len(authors) != 1 || authors[0].ID != herbert.ID {
+		t.Fatalf("unexpected authors: %#v", authors)
+	}
+
+	// Rebuilding again finds nothing to change.
+	if works, err := s.RebuildWorks(ctx, false); err != nil {
+		t.Fatal(err)
+	} else if len(works) != 0 {
+		t.Fatalf("unexpected works: %#v", works)
+	}
+}
+
+func TestCatalogService_RebuildWorks_Contributors(t *testing.T) {
+	t.Parallel()
+	db := MustOpenDB(t)
+	defer MustCloseDB(t, db)
+	ctx := context.Background()
+	s := sqlite.NewCatalogService(db)
+	authors := sqlite.NewAuthorService(db)
+
+	// A collection of Lem's novels was cataloged under Solaris, with its
+	// translator and illustrator. Another Solaris shares the translator only.
+	lem := &bookid.Author{Name: "Stanisław Lem"}
+	solaris := mustCreateLinkedWork(t, ctx, db, "Solaris", lem)
+	translator := &bookid.Author{Name: "Bill Johnston"}
+	illustrator := &bookid.Author{Name: "Daniel Mróz"}
+	for _, wa := range []struct {
+		author *bookid.Author
+		role   bookid.ContributorRole
+	}{{translator, bookid.ContributorRoleTranslator}, {illustrator, bookid.ContributorRoleIllustrator}} {
+		if err := authors.CreateAuthor(ctx, wa.author); err != nil {
+			t.Fatal(err)
+		} else if err := authors.AddWorkAuthor(ctx, &bookid.WorkAuthor{WorkID: solaris.ID, AuthorID: wa.author.ID, Role: wa.role}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	MustCreatePublication(t, ctx, db, &bookid.Publication{WorkID: solaris.ID, ISBN13: "9780156027601"})
+	invincible := MustCreatePublication(t, ctx, db, &bookid.Publication{
+		WorkID:          solaris.ID,
+		ISBN13:          "9780262538435",
+		GoogleBooksData: `{"volumeInfo":{"title":"The Invincible","authors":["Stanisław Lem"]}}`,
+	})
+	other := mustCreateLinkedWork(t, ctx, db, "Solaris", &bookid.Author{Name: "Jan Kowalski"})
+	if err := authors.AddWorkAuthor(ctx, &bookid.WorkAuthor{WorkID: other.ID, AuthorID: translator.ID, Role: bookid.ContributorRoleTranslator}); err != nil {
+		t.Fatal(err)
+	}
+	MustCreatePublication(t, ctx, db, &bookid.Publication{WorkID: other.ID})
+
+	works, err := s.RebuildWorks(ctx, true)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(works) != 1 || works[0].Title != "The Invincible" || !reflect.DeepEqual(works[0].PublicationIDs, []int64{invincible.ID}) {
+		t.Fatalf("unexpected works: %#v", works)
+	}
+
+	// The new work keeps the roles of the work it was split from.
+	found, _, err := authors.FindAuthors(ctx, bookid.AuthorFilter{WorkID: &works[0].WorkID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	roles := make(map[string]bookid.ContributorRole)
+	for _, a := range found {
+		roles[a.Name] = a.Role
+	}
+	if want := map[string]bookid.ContributorRole{
+		"Stanisław Lem": bookid.ContributorRoleAuthor,
+		"Bill Johnston": bookid.ContributorRoleTranslator,
+		"Daniel Mróz":   bookid.ContributorRoleIllustrator,
+	}; !reflect.DeepEqual(roles, want) {
+		t.Fatalf("roles=%v, want %v", roles, want)
+	}
+
+	// Rebuilding again finds nothing to change.
+	if works, err := s.RebuildWorks(ctx, false); err != nil {
+		t.Fatal(err)
+	} else if len(works) != 0 {
+		t.Fatalf("unexpected works: %#v", works)
+	}
+}
+
+// mustCreateLinkedWork creates a work linked to author, creating the author
+// if it has no ID.
+func mustCreateLinkedWork(tb testing.TB, ctx context.Context, db *sqlite.DB, title string, author *bookid.Author) *bookid.Work {
+	tb.Helper()
+	work := MustCreateWork(tb, ctx, db, &bookid.Work{Title: title, Author: author.Name})
+	authors := sqlite.NewAuthorService(db)
+	if author.ID == 0 {
+		if err := authors.CreateAuthor(ctx, author); err != nil {
+			tb.Fatal(err)
+		}
+	}
+	if err := authors.AddWorkAuthor(ctx, &bookid.WorkAuthor{WorkID: work.ID, AuthorID: author.ID}); err != nil {
+		tb.Fatal(err)
+	}
+	return work
+}
@@ -0,0 +1,462 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/language"
+)
+
+// Ensure service implements interface.
+var _ bookid.WorkRelationService = (*WorkRelationService)(nil)
+
+// WorkRelationService represents a service for managing links between works.
+type WorkRelationService struct {
+	db *DB
+}
+
+// NewWorkRelationService returns a new instance of WorkRelationService.
+func NewWorkRelationService(db *DB) *WorkRelationService {
+	return &WorkRelationService{db: db}
+}
+
+// CreateWorkRelation links two works.
+func (s *WorkRelationService) CreateWorkRelation(ctx context.Context, rel *bookid.WorkRelation) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := createWorkRelation(ctx, tx, rel); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// FindWorkRelations retrieves relations matching the filter.
+func (s *WorkRelationService) FindWorkRelations(ctx context.Context, filter bookid.WorkRelationFilter) ([]*bookid.WorkRelation, int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer func() { _ = tx.Rollback() }()
+	return findWorkRelations(ctx, tx, filter)
+}
+
+// DeleteWorkRelation permanently removes a relation.
+func (s *WorkRelationService) DeleteWorkRelation(ctx context.Context, id int64) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := deleteWorkRelation(ctx, tx, id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// SuggestTranslations proposes translation links between works that share a
+// title but have publications in different languages, and from works whose
+// original title is the title of another work.
+//
+// The work with the earliest known publication year is assumed to be the
+// original of works sharing a title; ties fall back to the work that was
+// cataloged first. Works named by an original title are only suggested if
+// they have a publication in the original language, when both are known.
+func (s *WorkRelationService) SuggestTranslations(ctx context.Context) ([]*bookid.WorkRelationSuggestion, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	candidates, err := findTranslationCandidates(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	related, err := findRelatedWorkPairs(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Group candidate works by normalized title, preserving catalog order.
+	groups := make(map[string][]*translationCandidate)
+	var keys []string
+	for _, c := range candidates {
+		key := strings.ToLower(strings.TrimSpace(c.title))
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], c)
+	}
+
+	var suggestions []*bookid.WorkRelationSuggestion
+	for _, key := range keys {
+		group := groups[key]
+		for i := 0; i < len(group); i++ {
+			for j := i + 1; j < len(group); j++ {
+				a, b := group[i], group[j]
+				if related[workPair(a.workID, b.workID)] || a.sharesLanguage(b) {
+					continue
+				}
+
+				original, translation := a, b
+				if b.earlierThan(a) {
+					original, translation = b, a
+				}
+
+				related[workPair(a.workID, b.workID)] = true
+				suggestions = append(suggestions, &bookid.WorkRelationSuggestion{
+					WorkRelation: bookid.WorkRelation{
+						WorkID:        translation.workID,
+						RelatedWorkID: original.workID,
+						Type:          bookid.WorkRelationTranslationOf,
+					},
+					Reason: fmt.Sprintf("Same title %q published in %s and %s.",
+						original.title,
+						strings.Join(original.languages, ", "),
+						strings.Join(translation.languages, ", "),
+					),
+				})
+			}
+		}
+	}
+
+	languages := make(map[int64][]string, len(candidates))
+	for _, c := range candidates {
+		languages[c.workID] = c.languages
+	}
+	translations, err := findWorksWithOriginal(ctx, tx)
+	if err != nil {
+		return nil, err
+	}
+	for _, translation := range translations {
+		originals, _, err := findWorks(ctx, tx, bookid.WorkFilter{Title: &translation.OriginalTitle})
+		if err != nil {
+			return nil, err
+		}
+		for _, original := range originals {
+			if original.ID == translation.ID || related[workPair(original.ID, translation.ID)] {
+				continue
+			} else if !inLanguage(languages[original.ID], translation.OriginalLanguage) {
+				continue
+			}
+
+			related[workPair(original.ID, translation.ID)] = true
+			suggestions = append(suggestions, &bookid.WorkRelationSuggestion{
+				WorkRelation: bookid.WorkRelation{
+					WorkID:        translation.ID,
+					RelatedWorkID: original.ID,
+					Type:          bookid.WorkRelationTranslationOf,
+				},
+				Reason: fmt.Sprintf("%q is the original title of %q.", original.Title, translation.Title),
+			})
+		}
+	}
+	return suggestions, nil
+}
+
+// inLanguage returns true if one of languages has the base language of lang,
+// or if either is unknown.
+func inLanguage(languages []string, lang string) bool {
+	if len(languages) == 0 || lang == "" {
+		return true
+	}
+	for _, l := range languages {
+		if language.Base(l) == language.Base(lang) {
+			return true
+		}
+	}
+	return false
+}
+
+// findWorksWithOriginal returns the works outside the trash that record an
+// original title, ordered by ID.
+func findWorksWithOriginal(ctx context.Context, tx *Tx) ([]*bookid.Work, error) {
+	works, _, err := findWorks(ctx, tx, bookid.WorkFilter{})
+	if err != nil {
+		return nil, err
+	}
+	var found []*bookid.Work
+	for _, work := range works {
+		if work.OriginalTitle != "" {
+			found = append(found, work)
+		}
+	}
+	return found, nil
+}
+
+// LinkTranslation links a work to the original-language work it translates,
+// records the original's title and language on it and links its translators.
+// The original language is that of the original's earliest publication.
+func (s *WorkRelationService) LinkTranslation(ctx context.Context, workID, originalID int64, translators []string) (*bookid.WorkRelation, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	original, err := findWorkByID(ctx, tx, originalID)
+	if err != nil {
+		return nil, err
+	}
+	rel := &bookid.WorkRelation{WorkID: workID, RelatedWorkID: originalID, Type: bookid.WorkRelationTranslationOf}
+	if err := createWorkRelation(ctx, tx, rel); err != nil {
+		return nil, err
+	}
+
+	upd := bookid.WorkUpdate{OriginalTitle: &original.Title}
+	if lang, err := originalLanguage(ctx, tx, originalID); err != nil {
+		return nil, err
+	} else if lang != "" {
+		upd.OriginalLanguage = &lang
+	}
+	if _, err := updateWork(ctx, tx, workID, upd); err != nil {
+		return nil, err
+	}
+
+	contributors := make([]bookid.Contributor, 0, len(translators))
+	for _, name := range translators {
+		contributors = append(contributors, bookid.Contributor{Name: name, Role: bookid.ContributorRoleTranslator})
+	}
+	if err := linkContributors(ctx, tx, workID, contributors); err != nil {
+		return nil, err
+	} else if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return rel, nil
+}
+
+// originalLanguage returns the language of the earliest publication of a
+// work with a known language, or an empty string if there is none.
+func originalLanguage(ctx context.Context, tx *Tx, workID int64) (string, error) {
+	var lang string
+	err := tx.QueryRowContext(ctx, `
+		SELECT language
+		FROM publications
+		WHERE work_id = ? AND language <> '' AND deleted_at IS NULL
+		ORDER BY published_year = 0, published_year, id
+		LIMIT 1
+	`, workID).Scan(&lang)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	return lang, err
+}
+
+// translationCandidate summarizes a work's publication languages for
+// translation detection.
+type translationCandidate struct {
+	workID    int64
+	title     string
+	languages []string
+	year      int // earliest known publication year, zero if unknown
+}
+
+// sharesLanguage returns true if both works have a publication in a common language.
+func (c *translationCandidate) sharesLanguage(other *translationCandidate) bool {
+	for _, a := range c.languages {
+		for _, b := range other.languages {
+			if strings.EqualFold(a, b) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// earlierThan returns true if c was most likely published before other.
+func (c *translationCandidate) earlierThan(other *translationCandidate) bool {
+	if c.year != 0 && other.year != 0 && c.year != other.year {
+		return c.year < other.year
+	} else if c.year != 0 && other.year == 0 {
+		return true
+	} else if c.year == 0 && other.year != 0 {
+		return false
+	}
+	return c.workID < other.workID
+}
+
+// findTranslationCandidates returns every work that has at least one
+// publication with a known language outside the trash, ordered by work ID.
+func findTranslationCandidates(ctx context.Context, tx *Tx) ([]*translationCandidate, error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT w.id, w.title, p.language, COALESCE(MIN(NULLIF(p.published_year, 0)), 0)
+		FROM works w
+		JOIN publications p ON p.work_id = w.id
+		WHERE p.language <> '' AND p.deleted_at IS NULL
+		GROUP BY w.id, p.language
+		ORDER BY w.id, p.language
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var candidates []*translationCandidate
+	var last *translationCandidate
+	for rows.Next() {
+		var (
+			id              int64
+			title, language string
+			year            int
+		)
+		if err := rows.Scan(&id, &title, &language, &year); err != nil {
+			return nil, err
+		}
+
+		if last == nil || last.workID != id {
+			last = &translationCandidate{workID: id, title: title}
+			candidates = append(candidates, last)
+		}
+		last.languages = append(last.languages, language)
+		if year != 0 && (last.year == 0 || year < last.year) {
+			last.year = year
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return candidates, nil
+}
+
+// findRelatedWorkPairs returns the set of work pairs that already have a
+// relation of any type, regardless of direction.
+func findRelatedWorkPairs(ctx context.Context, tx *Tx) (map[[2]int64]bool, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT work_id, related_work_id FROM work_relations`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	pairs := make(map[[2]int64]bool)
+	for rows.Next() {
+		var a, b int64
+		if err := rows.Scan(&a, &b); err != nil {
+			return nil, err
+		}
+		pairs[workPair(a, b)] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return pairs, nil
+}
+
+// workPair returns an order-independent key for two work IDs.
+func workPair(a, b int64) [2]int64 {
+	if a > b {
+		a, b = b, a
+	}
+	return [2]int64{a, b}
+}
+
+// createWorkRelation inserts a new relation. Returns ENOTFOUND if either work
+// does not exist.
+func createWorkRelation(ctx context.Context, tx *Tx, rel *bookid.WorkRelation) error {
+	if err := rel.Validate(); err != nil {
+		return err
+	}
+
+	for _, id := range []int64{rel.WorkID, rel.RelatedWorkID} {
+		var n int
+		if err := tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM works WHERE id = ? AND deleted_at IS NULL`, id).Scan(&n); err != nil {
+			return err
+		} else if n == 0 {
+			return bookid.Errorf(bookid.ENOTFOUND, "Work not found.")
+		}
+	}
+
+	rel.CreatedAt = tx.now
+
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO work_relations (work_id, related_work_id, type, created_at)
+		VALUES (?, ?, ?, ?)
+	`,
+		rel.WorkID,
+		rel.RelatedWorkID,
+		rel.Type,
+		(*NullTime)(&rel.CreatedAt),
+	)
+	if err != nil {
+		return FormatError(err)
+	}
+
+	if rel.ID, err = result.LastInsertId(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// findWorkRelations returns relations matching the filter along with the
+// total count of matches.
+func findWorkRelations(ctx context.Context, tx *Tx, filter bookid.WorkRelationFilter) (_ []*bookid.WorkRelation, n int, err error) {
+	where, args := []string{"1 = 1"}, []any{}
+	if v := filter.ID; v != nil {
+		where, args = append(where, "id = ?"), append(args, *v)
+	}
+	if v := filter.WorkID; v != nil {
+		where, args = append(where, "work_id = ?"), append(args, *v)
+	}
+	if v := filter.RelatedWorkID; v != nil {
+		where, args = append(where, "related_work_id = ?"), append(args, *v)
+	}
+	if v := filter.Type; v != nil {
+		where, args = append(where, "type = ?"), append(args, *v)
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id, work_id, related_work_id, type, created_at, COUNT(*) OVER ()
+		FROM work_relations
+		WHERE `+strings.Join(where, " AND ")+`
+		ORDER BY id ASC
+		`+FormatLimitOffset(filter.Limit, filter.Offset),
+		args...,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	rels := make([]*bookid.WorkRelation, 0)
+	for rows.Next() {
+		var rel bookid.WorkRelation
+		if err := rows.Scan(
+			&rel.ID,
+			&rel.WorkID,
+			&rel.RelatedWorkID,
+			&rel.Type,
+			(*NullTime)(&rel.CreatedAt),
+			&n,
+		); err != nil {
+			return nil, 0, err
+		}
+		rels = append(rels, &rel)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+	return rels, n, nil
+}
+
+// deleteWorkRelation permanently removes a relation by ID.
+func deleteWorkRelation(ctx context.Context, tx *Tx, id int64) error {
+	result, err := tx.ExecContext(ctx, `DELETE FROM work_relations WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return bookid.Errorf(bookid.ENOTFOUND, "Work relation not found.")
+	}
+	return nil
+}
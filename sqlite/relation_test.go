@@ -0,0 +1,276 @@
+package sqlite_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/sqlite"
+)
+
+func TestWorkRelationService_CreateWorkRelation(t *testing.T) {
+	t.Parallel()
+
+	t.Run("OK", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewWorkRelationService(db)
+		ctx := context.Background()
+
+		original := MustCreateWork(t, ctx, db, &bookid.Work{Title: "Solaris"}).ID
+		translation := MustCreateWork(t, ctx, db, &bookid.Work{Title: "Solaris"}).ID
+
+		rel := &bookid.WorkRelation{WorkID: translation, RelatedWorkID: original, Type: bookid.WorkRelationTranslationOf}
+		if err := s.CreateWorkRelation(ctx, rel); err != nil {
+			t.Fatal(err)
+		} else if got, want := rel.ID, int64(1); got != want {
+			t.Fatalf("ID=%d, want %d", got, want)
+		} else if rel.CreatedAt.IsZero() {
+			t.Fatal("expected created at")
+		}
+
+		rels, n, err := s.FindWorkRelations(ctx, bookid.WorkRelationFilter{RelatedWorkID: &original})
+		if err != nil {
+			t.Fatal(err)
+		} else if got, want := n, 1; got != want {
+			t.Fatalf("n=%d, want %d", got, want)
+		} else if got, want := rels[0].WorkID, translation; got != want {
+			t.Fatalf("WorkID=%d, want %d", got, want)
+		} else if got, want := rels[0].Type, bookid.WorkRelationTranslationOf; got != want {
+			t.Fatalf("Type=%q, want %q", got, want)
+		}
+	})
+
+	t.Run("ErrDuplicate", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewWorkRelationService(db)
+		ctx := context.Background()
+
+		a, b := MustCreateWork(t, ctx, db, &bookid.Work{Title: "Dune"}).ID, MustCreateWork(t, ctx, db, &bookid.Work{Title: "Diuna"}).ID
+		if err := s.CreateWorkRelation(ctx, &bookid.WorkRelation{WorkID: b, RelatedWorkID: a, Type: bookid.WorkRelationTranslationOf}); err != nil {
+			t.Fatal(err)
+		}
+		err := s.CreateWorkRelation(ctx, &bookid.WorkRelation{WorkID: b, RelatedWorkID: a, Type: bookid.WorkRelationTranslationOf})
+		if code := bookid.ErrorCode(err); code != bookid.ECONFLICT {
+			t.Fatalf("ErrorCode()=%q, want %q", code, bookid.ECONFLICT)
+		}
+	})
+
+	t.Run("ErrWorkNotFound", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewWorkRelationService(db)
+		ctx := context.Background()
+
+		a := MustCreateWork(t, ctx, db, &bookid.Work{Title: "Dune"}).ID
+		err := s.CreateWorkRelation(ctx, &bookid.WorkRelation{WorkID: a, RelatedWorkID: 100, Type: bookid.WorkRelationAdaptationOf})
+		if code := bookid.ErrorCode(err); code != bookid.ENOTFOUND {
+			t.Fatalf("ErrorCode()=%q, want %q", code, bookid.ENOTFOUND)
+		}
+	})
+
+	t.Run("ErrInvalid", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewWorkRelationService(db)
+
+		err := s.CreateWorkRelation(context.Background(), &bookid.WorkRelation{WorkID: 1, RelatedWorkID: 2})
+		if code := bookid.ErrorCode(err); code != bookid.EINVALID {
+			t.Fatalf("ErrorCode()=%q, want %q", code, bookid.EINVALID)
+		}
+	})
+}
+
+func TestWorkRelationService_DeleteWorkRelation(t *testing.T) {
+	t.Parallel()
+
+	t.Run("OK", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewWorkRelationService(db)
+		ctx := context.Background()
+
+		a, b := MustCreateWork(t, ctx, db, &bookid.Work{Title: "Dune"}).ID, MustCreateWork(t, ctx, db, &bookid.Work{Title: "Dune"}).ID
+		rel := &bookid.WorkRelation{WorkID: b, RelatedWorkID: a, Type: bookid.WorkRelationAbridgementOf}
+		if err := s.CreateWorkRelation(ctx, rel); err != nil {
+			t.Fatal(err)
+		} else if err := s.DeleteWorkRelation(ctx, rel.ID); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, n, err := s.FindWorkRelations(ctx, bookid.WorkRelationFilter{}); err != nil {
+			t.Fatal(err)
+		} else if n != 0 {
+			t.Fatalf("n=%d, want 0", n)
+		}
+	})
+
+	t.Run("ErrNotFound", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewWorkRelationService(db)
+
+		err := s.DeleteWorkRelation(context.Background(), 1)
+		if code := bookid.ErrorCode(err); code != bookid.ENOTFOUND {
+			t.Fatalf("ErrorCode()=%q, want %q", code, bookid.ENOTFOUND)
+		}
+	})
+}
+
+func TestWorkRelationService_SuggestTranslations(t *testing.T) {
+	t.Parallel()
+
+	t.Run("OK", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewWorkRelationService(db)
+		ctx := context.Background()
+
+		// Polish original, English translation cataloged first.
+		translation := MustCreateWork(t, ctx, db, &bookid.Work{Title: "Solaris"}).ID
+		MustCreatePublication(t, ctx, db, &bookid.Publication{WorkID: translation, Language: "en", PublishedYear: 1970})
+		original := MustCreateWork(t, ctx, db, &bookid.Work{Title: "solaris "}).ID
+		MustCreatePublication(t, ctx, db, &bookid.Publication{WorkID: original, Language: "pl", PublishedYear: 1961})
+
+		// Same language; not a translation.
+		other := MustCreateWork(t, ctx, db, &bookid.Work{Title: "Solaris"}).ID
+		MustCreatePublication(t, ctx, db, &bookid.Publication{WorkID: other, Language: "en", PublishedYear: 2011})
+
+		suggestions, err := s.SuggestTranslations(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var found bool
+		for _, sug := range suggestions {
+			if sug.WorkID == translation && sug.RelatedWorkID == original {
+				found = true
+				if got, want := sug.Type, bookid.WorkRelationTranslationOf; got != want {
+					t.Fatalf("Type=%q, want %q", got, want)
+				} else if sug.Reason == "" {
+					t.Fatal("expected reason")
+				}
+			}
+			if sug.WorkID == translation && sug.RelatedWorkID == other || sug.WorkID == other && sug.RelatedWorkID == translation {
+				t.Fatal("unexpected suggestion between works sharing a language")
+			}
+		}
+		if !found {
+			t.Fatalf("expected translation suggestion, got %d suggestions", len(suggestions))
+		}
+	})
+
+	t.Run("OriginalTitle", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewWorkRelationService(db)
+		ctx := context.Background()
+
+		original := MustCreateWork(t, ctx, db, &bookid.Work{Title: "Cien años de soledad"}).ID
+		MustCreatePublication(t, ctx, db, &bookid.Publication{WorkID: original, Language: "es", PublishedYear: 1967})
+		translation := MustCreateWork(t, ctx, db, &bookid.Work{
+			Title:            "One Hundred Years of Solitude",
+			OriginalTitle:    "Cien años de soledad",
+			OriginalLanguage: "es",
+		}).ID
+		MustCreatePublication(t, ctx, db, &bookid.Publication{WorkID: translation, Language: "en", PublishedYear: 1970})
+
+		// A work of the title in a language other than the original's is not
+		// the original.
+		MustCreateWork(t, ctx, db, &bookid.Work{Title: "Hundert Jahre Einsamkeit", OriginalTitle: "One Hundred Years of Solitude", OriginalLanguage: "es"})
+
+		suggestions, err := s.SuggestTranslations(ctx)
+		if err != nil {
+			t.Fatal(err)
+		} else if len(suggestions) != 1 {
+			t.Fatalf("len=%d, want 1", len(suggestions))
+		} else if got := suggestions[0]; got.WorkID != translation || got.RelatedWorkID != original {
+			t.Fatalf("unexpected suggestion: %#v", got)
+		}
+	})
+
+	t.Run("SkipRelated", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewWorkRelationService(db)
+		ctx := context.Background()
+
+		a := MustCreateWork(t, ctx, db, &bookid.Work{Title: "Quo Vadis"}).ID
+		MustCreatePublication(t, ctx, db, &bookid.Publication{WorkID: a, Language: "pl", PublishedYear: 1896})
+		b := MustCreateWork(t, ctx, db, &bookid.Work{Title: "Quo Vadis"}).ID
+		MustCreatePublication(t, ctx, db, &bookid.Publication{WorkID: b, Language: "en", PublishedYear: 1897})
+
+		if err := s.CreateWorkRelation(ctx, &bookid.WorkRelation{WorkID: a, RelatedWorkID: b, Type: bookid.WorkRelationAdaptationOf}); err != nil {
+			t.Fatal(err)
+		}
+
+		if suggestions, err := s.SuggestTranslations(ctx); err != nil {
+			t.Fatal(err)
+		} else if len(suggestions) != 0 {
+			t.Fatalf("len=%d, want 0", len(suggestions))
+		}
+	})
+}
+
+func TestWorkRelationService_LinkTranslation(t *testing.T) {
+	t.Parallel()
+
+	t.Run("OK", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewWorkRelationService(db)
+		ctx := context.Background()
+
+		original := MustCreateWork(t, ctx, db, &bookid.Work{Title: "Solaris", Author: "Stanisław Lem"}).ID
+		MustCreatePublication(t, ctx, db, &bookid.Publication{WorkID: original, Language: "pl", PublishedYear: 1961})
+		translation := MustCreateWork(t, ctx, db, &bookid.Work{Title: "Solaris", Author: "Stanisław Lem"}).ID
+
+		rel, err := s.LinkTranslation(ctx, translation, original, []string{"Bill Johnston"})
+		if err != nil {
+			t.Fatal(err)
+		} else if rel.ID == 0 || rel.Type != bookid.WorkRelationTranslationOf {
+			t.Fatalf("unexpected relation: %#v", rel)
+		}
+
+		if work, err := sqlite.NewWorkService(db).FindWorkByID(ctx, translation); err != nil {
+			t.Fatal(err)
+		} else if work.OriginalTitle != "Solaris" || work.OriginalLanguage != "pl" {
+			t.Fatalf("OriginalTitle=%q OriginalLanguage=%q", work.OriginalTitle, work.OriginalLanguage)
+		}
+		if authors, _, err := sqlite.NewAuthorService(db).FindAuthors(ctx, bookid.AuthorFilter{WorkID: &translation}); err != nil {
+			t.Fatal(err)
+		} else if len(authors) != 1 || authors[0].Name != "Bill Johnston" || authors[0].Role != bookid.ContributorRoleTranslator {
+			t.Fatalf("unexpected authors: %#v", authors)
+		}
+
+		if _, err := s.LinkTranslation(ctx, translation, original, nil); bookid.ErrorCode(err) != bookid.ECONFLICT {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+
+	t.Run("ErrNotFound", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewWorkRelationService(db)
+		ctx := context.Background()
+
+		work := MustCreateWork(t, ctx, db, &bookid.Work{Title: "Solaris"}).ID
+		if _, err := s.LinkTranslation(ctx, work, 100, nil); bookid.ErrorCode(err) != bookid.ENOTFOUND {
+			t.Fatalf("unexpected error: %#v", err)
+		} else if _, err := s.LinkTranslation(ctx, 100, work, nil); bookid.ErrorCode(err) != bookid.ENOTFOUND {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+}
@@ -0,0 +1,265 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/fwojciec/bookid"
+)
+
+// Ensure service implements interface.
+var _ bookid.ReviewService = (*ReviewService)(nil)
+
+// ReviewService represents a service for managing the queue of
+// low-confidence identifications awaiting review.
+type ReviewService struct {
+	db *DB
+
+	// Catalog saves the chosen candidates.
+	Catalog *CatalogService
+}
+
+// NewReviewService returns a new instance of ReviewService.
+func NewReviewService(db *DB) *ReviewService {
+	return &ReviewService{db: db, Catalog: NewCatalogService(db)}
+}
+
+// FindReviewByID retrieves a single review by ID.
+// Returns ENOTFOUND if the review does not exist.
+func (s *ReviewService) FindReviewByID(ctx context.Context, id int64) (*bookid.Review, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+	return findReviewByID(ctx, tx, id)
+}
+
+// FindReviews retrieves a list of reviews matching the filter.
+func (s *ReviewService) FindReviews(ctx context.Context, filter bookid.ReviewFilter) ([]*bookid.Review, int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer func() { _ = tx.Rollback() }()
+	return findReviews(ctx, tx, filter)
+}
+
+// CreateReview queues a review in the pending state.
+func (s *ReviewService) CreateReview(ctx context.Context, review *bookid.Review) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := createReview(ctx, tx, review); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// SaveReview saves the chosen candidate of a pending review to the catalog
+// and closes the review in a single transaction.
+func (s *ReviewService) SaveReview(ctx context.Context, id int64, choice int) (*bookid.Review, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	review, err := findPendingReview(ctx, tx, id)
+	if err != nil {
+		return review, err
+	} else if choice < 1 || choice > len(review.Candidates) {
+		return review, bookid.Errorf(bookid.EINVALID, "Review has no candidate %d.", choice)
+	}
+
+	review.WorkID, review.PublicationID, err = s.Catalog.saveResult(ctx, tx, review.Candidates[choice-1])
+	if err != nil {
+		return review, err
+	}
+	review.Choice = choice
+	if err := closeReview(ctx, tx, review, bookid.ReviewStateSaved); err != nil {
+		return review, err
+	} else if err := tx.Commit(); err != nil {
+		return review, err
+	}
+	return review, nil
+}
+
+// DismissReview closes a pending review without saving any candidate.
+func (s *ReviewService) DismissReview(ctx context.Context, id int64) (*bookid.Review, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	review, err := findPendingReview(ctx, tx, id)
+	if err != nil {
+		return review, err
+	} else if err := closeReview(ctx, tx, review, bookid.ReviewStateDismissed); err != nil {
+		return review, err
+	} else if err := tx.Commit(); err != nil {
+		return review, err
+	}
+	return review, nil
+}
+
+// findReviewByID is a helper function to fetch a review by ID.
+// Returns ENOTFOUND if the review does not exist.
+func findReviewByID(ctx context.Context, tx *Tx, id int64) (*bookid.Review, error) {
+	reviews, _, err := findReviews(ctx, tx, bookid.ReviewFilter{ID: &id})
+	if err != nil {
+		return nil, err
+	} else if len(reviews) == 0 {
+		return nil, bookid.Errorf(bookid.ENOTFOUND, "Review not found.")
+	}
+	return reviews[0], nil
+}
+
+// findPendingReview returns a review by ID, or ECONFLICT if it is no longer
+// pending.
+func findPendingReview(ctx context.Context, tx *Tx, id int64) (*bookid.Review, error) {
+	review, err := findReviewByID(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	} else if review.State != bookid.ReviewStatePending {
+		return review, bookid.Errorf(bookid.ECONFLICT, "Review is already %s.", review.State)
+	}
+	return review, nil
+}
+
+// findReviews returns a list of reviews matching a filter, oldest first.
+// Also returns a count of total matching reviews which may differ if
+// filter.Limit is set.
+func findReviews(ctx context.Context, tx *Tx, filter bookid.ReviewFilter) (_ []*bookid.Review, n int, err error) {
+	where, args := []string{"1 = 1"}, []any{}
+	if v := filter.ID; v != nil {
+		where, args = append(where, "id = ?"), append(args, *v)
+	}
+	if v := filter.State; v != nil {
+		where, args = append(where, "state = ?"), append(args, *v)
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT
+			id,
+			source,
+			line,
+			query,
+			candidates,
+			state,
+			choice,
+			work_id,
+			publication_id,
+			created_at,
+			resolved_at,
+			COUNT(*) OVER ()
+		FROM reviews
+		WHERE `+strings.Join(where, " AND ")+`
+		ORDER BY id ASC
+		`+FormatLimitOffset(filter.Limit, filter.Offset),
+		args...,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	reviews := make([]*bookid.Review, 0)
+	for rows.Next() {
+		var review bookid.Review
+		var candidates string
+		var workID, publicationID sql.NullInt64
+		if err := rows.Scan(
+			&review.ID,
+			&review.Source,
+			&review.Line,
+			&review.Query,
+			&candidates,
+			&review.State,
+			&review.Choice,
+			&workID,
+			&publicationID,
+			(*NullTime)(&review.CreatedAt),
+			(*NullTime)(&review.ResolvedAt),
+			&n,
+		); err != nil {
+			return nil, 0, err
+		} else if err := json.Unmarshal([]byte(candidates), &review.Candidates); err != nil {
+			return nil, 0, err
+		}
+		review.WorkID, review.PublicationID = workID.Int64, publicationID.Int64
+		reviews = append(reviews, &review)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+	return reviews, n, nil
+}
+
+// createReview inserts a new pending review.
+func createReview(ctx context.Context, tx *Tx, review *bookid.Review) error {
+	review.State = bookid.ReviewStatePending
+	review.Choice, review.WorkID, review.PublicationID = 0, 0, 0
+	review.ResolvedAt = time.Time{}
+	if err := review.Validate(); err != nil {
+		return err
+	}
+
+	candidates, err := json.Marshal(review.Candidates)
+	if err != nil {
+		return err
+	}
+
+	review.CreatedAt = tx.now
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO reviews (
+			source,
+			line,
+			query,
+			candidates,
+			state,
+			created_at
+		)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`,
+		review.Source,
+		review.Line,
+		review.Query,
+		string(candidates),
+		review.State,
+		(*NullTime)(&review.CreatedAt),
+	)
+	if err != nil {
+		return FormatError(err)
+	}
+	review.ID, err = result.LastInsertId()
+	return err
+}
+
+// closeReview moves a review out of the pending state.
+func closeReview(ctx context.Context, tx *Tx, review *bookid.Review, state bookid.ReviewState) error {
+	review.State = state
+	review.ResolvedAt = tx.now
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE reviews
+		SET state = ?, choice = ?, work_id = ?, publication_id = ?, resolved_at = ?
+		WHERE id = ?
+	`,
+		review.State,
+		review.Choice,
+		sql.NullInt64{Int64: review.WorkID, Valid: review.WorkID != 0},
+		sql.NullInt64{Int64: review.PublicationID, Valid: review.PublicationID != 0},
+		(*NullTime)(&review.ResolvedAt),
+		review.ID,
+	); err != nil {
+		return FormatError(err)
+	}
+	return nil
+}
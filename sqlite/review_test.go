@@ -0,0 +1,164 @@
+package sqlite_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/sqlite"
+)
+
+func TestReviewService_CreateReview(t *testing.T) {
+	t.Parallel()
+
+	t.Run("OK", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewReviewService(db)
+		ctx := context.Background()
+
+		review := mustCreateReview(t, ctx, s)
+		if got, want := review.ID, int64(1); got != want {
+			t.Fatalf("ID=%d, want %d", got, want)
+		} else if got, want := review.State, bookid.ReviewStatePending; got != want {
+			t.Fatalf("State=%q, want %q", got, want)
+		} else if review.CreatedAt.IsZero() {
+			t.Fatal("expected created at")
+		}
+
+		if found, err := s.FindReviewByID(ctx, review.ID); err != nil {
+			t.Fatal(err)
+		} else if !reflect.DeepEqual(review, found) {
+			t.Fatalf("mismatch: %#v != %#v", review, found)
+		}
+	})
+
+	t.Run("ErrInvalid", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewReviewService(db)
+		ctx := context.Background()
+
+		if err := s.CreateReview(ctx, &bookid.Review{Candidates: []bookid.BookResult{{Title: "Dune"}}}); bookid.ErrorCode(err) != bookid.EINVALID {
+			t.Fatalf("ErrorCode()=%q, want %q", bookid.ErrorCode(err), bookid.EINVALID)
+		} else if err := s.CreateReview(ctx, &bookid.Review{Query: "dune"}); bookid.ErrorCode(err) != bookid.EINVALID {
+			t.Fatalf("ErrorCode()=%q, want %q", bookid.ErrorCode(err), bookid.EINVALID)
+		}
+	})
+}
+
+func TestReviewService_SaveReview(t *testing.T) {
+	t.Parallel()
+
+	t.Run("OK", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewReviewService(db)
+		ctx := context.Background()
+
+		review := mustCreateReview(t, ctx, s)
+		saved, err := s.SaveReview(ctx, review.ID, 2)
+		if err != nil {
+			t.Fatal(err)
+		} else if got, want := saved.State, bookid.ReviewStateSaved; got != want {
+			t.Fatalf("State=%q, want %q", got, want)
+		} else if got, want := saved.Choice, 2; got != want {
+			t.Fatalf("Choice=%d, want %d", got, want)
+		} else if saved.ResolvedAt.IsZero() {
+			t.Fatal("expected resolved at")
+		}
+
+		if pub, err := sqlite.NewPublicationService(db).FindPublicationByID(ctx, saved.PublicationID); err != nil {
+			t.Fatal(err)
+		} else if got, want := pub.ISBN13, "9780441172696"; got != want {
+			t.Fatalf("ISBN13=%q, want %q", got, want)
+		} else if got, want := pub.WorkID, saved.WorkID; got != want {
+			t.Fatalf("WorkID=%d, want %d", got, want)
+		}
+
+		if found, err := s.FindReviewByID(ctx, review.ID); err != nil {
+			t.Fatal(err)
+		} else if !reflect.DeepEqual(saved, found) {
+			t.Fatalf("mismatch: %#v != %#v", saved, found)
+		}
+
+		if _, err := s.SaveReview(ctx, review.ID, 1); bookid.ErrorCode(err) != bookid.ECONFLICT {
+			t.Fatalf("ErrorCode()=%q, want %q", bookid.ErrorCode(err), bookid.ECONFLICT)
+		}
+	})
+
+	t.Run("ErrInvalid", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewReviewService(db)
+		ctx := context.Background()
+
+		review := mustCreateReview(t, ctx, s)
+		if _, err := s.SaveReview(ctx, review.ID, 3); bookid.ErrorCode(err) != bookid.EINVALID {
+			t.Fatalf("ErrorCode()=%q, want %q", bookid.ErrorCode(err), bookid.EINVALID)
+		} else if _, err := s.SaveReview(ctx, review.ID, 0); bookid.ErrorCode(err) != bookid.EINVALID {
+			t.Fatalf("ErrorCode()=%q, want %q", bookid.ErrorCode(err), bookid.EINVALID)
+		}
+	})
+
+	t.Run("ErrNotFound", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+
+		if _, err := sqlite.NewReviewService(db).SaveReview(context.Background(), 1, 1); bookid.ErrorCode(err) != bookid.ENOTFOUND {
+			t.Fatalf("ErrorCode()=%q, want %q", bookid.ErrorCode(err), bookid.ENOTFOUND)
+		}
+	})
+}
+
+func TestReviewService_DismissReview(t *testing.T) {
+	t.Parallel()
+	db := MustOpenDB(t)
+	defer MustCloseDB(t, db)
+	s := sqlite.NewReviewService(db)
+	ctx := context.Background()
+
+	review := mustCreateReview(t, ctx, s)
+	mustCreateReview(t, ctx, s)
+	if dismissed, err := s.DismissReview(ctx, review.ID); err != nil {
+		t.Fatal(err)
+	} else if got, want := dismissed.State, bookid.ReviewStateDismissed; got != want {
+		t.Fatalf("State=%q, want %q", got, want)
+	}
+
+	pending := bookid.ReviewStatePending
+	if reviews, n, err := s.FindReviews(ctx, bookid.ReviewFilter{State: &pending}); err != nil {
+		t.Fatal(err)
+	} else if n != 1 || reviews[0].ID != 2 {
+		t.Fatalf("unexpected pending reviews: %#v", reviews)
+	}
+
+	if _, err := s.DismissReview(ctx, review.ID); bookid.ErrorCode(err) != bookid.ECONFLICT {
+		t.Fatalf("ErrorCode()=%q, want %q", bookid.ErrorCode(err), bookid.ECONFLICT)
+	}
+}
+
+// mustCreateReview queues a review of a title search for Dune with two
+// candidates.
+func mustCreateReview(tb testing.TB, ctx context.Context, s *sqlite.ReviewService) *bookid.Review {
+	tb.Helper()
+	review := &bookid.Review{
+		Source: "import",
+		Line:   3,
+		Query:  "Dune Frank Herbert",
+		Candidates: []bookid.BookResult{
+			{Title: "Dune", Authors: []string{"Frank Herbert"}, ISBN13: "9780441172719", Confidence: 0.4},
+			{Title: "Dune Messiah", Authors: []string{"Frank Herbert"}, ISBN13: "9780441172696", Confidence: 0.3},
+		},
+	}
+	if err := s.CreateReview(ctx, review); err != nil {
+		tb.Fatal(err)
+	}
+	return review
+}
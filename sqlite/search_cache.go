@@ -0,0 +1,90 @@
+package sqlite
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/fwojciec/bookid"
+)
+
+// DefaultSearchCacheMaxEntries is the default number of cached searches kept.
+const DefaultSearchCacheMaxEntries = 10000
+
+// Ensure service implements interface.
+var _ bookid.SearchCache = (*SearchCache)(nil)
+
+// SearchCache represents a persistent store for cached search results.
+type SearchCache struct {
+	db *DB
+
+	// Number of entries kept; the oldest entries beyond it are evicted when
+	// a new entry is stored. Zero or less means no limit.
+	MaxEntries int
+}
+
+// NewSearchCache returns a new instance of SearchCache.
+func NewSearchCache(db *DB) *SearchCache {
+	return &SearchCache{db: db, MaxEntries: DefaultSearchCacheMaxEntries}
+}
+
+// FindSearchCacheEntry retrieves the entry stored under key.
+// Returns ENOTFOUND if there is no such entry.
+func (s *SearchCache) FindSearchCacheEntry(ctx context.Context, key string) (*bookid.SearchCacheEntry, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var results string
+	entry := &bookid.SearchCacheEntry{Key: key}
+	if err := tx.QueryRowContext(ctx, `
+		SELECT results, created_at
+		FROM search_cache
+		WHERE key = ?
+	`, key).Scan(&results, (*NullTime)(&entry.CreatedAt)); err != nil {
+		return nil, FormatError(err)
+	}
+	if err := json.Unmarshal([]byte(results), &entry.Results); err != nil {
+		return nil, fmt.Errorf("decoding cached results: %w", err)
+	}
+	return entry, nil
+}
+
+// SetSearchCacheEntry stores entry under entry.Key, replacing any existing
+// entry and evicting the oldest entries beyond MaxEntries.
+func (s *SearchCache) SetSearchCacheEntry(ctx context.Context, entry *bookid.SearchCacheEntry) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	results, err := json.Marshal(entry.Results)
+	if err != nil {
+		return fmt.Errorf("encoding cached results: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO search_cache (key, results, created_at)
+		VALUES (?, ?, ?)
+		ON CONFLICT (key) DO UPDATE SET results = excluded.results, created_at = excluded.created_at
+	`,
+		entry.Key,
+		string(results),
+		(*NullTime)(&entry.CreatedAt),
+	); err != nil {
+		return FormatError(err)
+	}
+
+	if s.MaxEntries > 0 {
+		if _, err := tx.ExecContext(ctx, `
+			DELETE FROM search_cache
+			WHERE key NOT IN (SELECT key FROM search_cache ORDER BY created_at DESC, rowid DESC LIMIT ?)
+		`, s.MaxEntries); err != nil {
+			return FormatError(err)
+		}
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,76 @@
+package sqlite_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/sqlite"
+)
+
+func TestSearchCache(t *testing.T) {
+	t.Parallel()
+
+	t.Run("OK", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewSearchCache(db)
+		ctx := context.Background()
+
+		createdAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		entry := &bookid.SearchCacheEntry{Key: "dune", Results: []bookid.BookResult{{Title: "Dune"}}, CreatedAt: createdAt}
+		if err := s.SetSearchCacheEntry(ctx, entry); err != nil {
+			t.Fatal(err)
+		}
+
+		// Replacing an entry overwrites its results.
+		entry.Results[0].Title = "Dune Messiah"
+		if err := s.SetSearchCacheEntry(ctx, entry); err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := s.FindSearchCacheEntry(ctx, "dune")
+		if err != nil {
+			t.Fatal(err)
+		} else if len(got.Results) != 1 || got.Results[0].Title != "Dune Messiah" {
+			t.Fatalf("Results=%+v, want Dune Messiah", got.Results)
+		} else if !got.CreatedAt.Equal(createdAt) {
+			t.Fatalf("CreatedAt=%s, want %s", got.CreatedAt, createdAt)
+		}
+	})
+
+	t.Run("ErrNotFound", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+
+		_, err := sqlite.NewSearchCache(db).FindSearchCacheEntry(context.Background(), "dune")
+		if code := bookid.ErrorCode(err); code != bookid.ENOTFOUND {
+			t.Fatalf("ErrorCode()=%q, want %q", code, bookid.ENOTFOUND)
+		}
+	})
+
+	t.Run("EvictOldest", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewSearchCache(db)
+		s.MaxEntries = 2
+		ctx := context.Background()
+
+		now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		for i, key := range []string{"a", "b", "c"} {
+			if err := s.SetSearchCacheEntry(ctx, &bookid.SearchCacheEntry{Key: key, CreatedAt: now.Add(time.Duration(i) * time.Minute)}); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		if _, err := s.FindSearchCacheEntry(ctx, "a"); bookid.ErrorCode(err) != bookid.ENOTFOUND {
+			t.Fatalf("expected oldest entry to be evicted, got %v", err)
+		} else if _, err := s.FindSearchCacheEntry(ctx, "c"); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
@@ -0,0 +1,289 @@
+package sqlite
+
+import (
+	"context"
+	"strings"
+
+	"github.com/fwojciec/bookid"
+)
+
+// Ensure service implements interface.
+var _ bookid.SeriesService = (*SeriesService)(nil)
+
+// SeriesService represents a service for managing series and the works in
+// them.
+type SeriesService struct {
+	db *DB
+}
+
+// NewSeriesService returns a new instance of SeriesService.
+func NewSeriesService(db *DB) *SeriesService {
+	return &SeriesService{db: db}
+}
+
+// FindSeriesByID retrieves a single series by ID.
+// Returns ENOTFOUND if the series does not exist.
+func (s *SeriesService) FindSeriesByID(ctx context.Context, id int64) (*bookid.Series, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+	return findSeriesByID(ctx, tx, id)
+}
+
+// FindSeries retrieves a list of series matching the filter.
+func (s *SeriesService) FindSeries(ctx context.Context, filter bookid.SeriesFilter) ([]*bookid.Series, int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer func() { _ = tx.Rollback() }()
+	return findSeries(ctx, tx, filter)
+}
+
+// CreateSeries creates a new series, or populates series from the existing
+// one with the same name.
+func (s *SeriesService) CreateSeries(ctx context.Context, series *bookid.Series) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := createSeries(ctx, tx, series); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// DeleteSeries permanently removes a series and its work links.
+// Returns ENOTFOUND if the series does not exist.
+func (s *SeriesService) DeleteSeries(ctx context.Context, id int64) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := deleteSeries(ctx, tx, id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// AddSeriesWork links a work to a series, updating the volume number of an
+// existing link.
+func (s *SeriesService) AddSeriesWork(ctx context.Context, sw *bookid.SeriesWork) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := addSeriesWork(ctx, tx, sw); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// RemoveSeriesWork unlinks a work from a series.
+// Returns ENOTFOUND if the link does not exist.
+func (s *SeriesService) RemoveSeriesWork(ctx context.Context, seriesID, workID int64) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := removeSeriesWork(ctx, tx, seriesID, workID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// FindSeriesWorks retrieves the links matching the filter in volume order.
+func (s *SeriesService) FindSeriesWorks(ctx context.Context, filter bookid.SeriesWorkFilter) ([]*bookid.SeriesWork, int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer func() { _ = tx.Rollback() }()
+	return findSeriesWorks(ctx, tx, filter)
+}
+
+// findSeriesByID is a helper function to fetch a series by ID.
+// Returns ENOTFOUND if the series does not exist.
+func findSeriesByID(ctx context.Context, tx *Tx, id int64) (*bookid.Series, error) {
+	series, _, err := findSeries(ctx, tx, bookid.SeriesFilter{ID: &id})
+	if err != nil {
+		return nil, err
+	} else if len(series) == 0 {
+		return nil, bookid.Errorf(bookid.ENOTFOUND, "Series not found.")
+	}
+	return series[0], nil
+}
+
+// findSeries returns a list of series matching a filter. Also returns a
+// count of total matching series which may differ if filter.Limit is set.
+func findSeries(ctx context.Context, tx *Tx, filter bookid.SeriesFilter) (_ []*bookid.Series, n int, err error) {
+	where, args := []string{"1 = 1"}, []any{}
+	if v := filter.ID; v != nil {
+		where, args = append(where, "id = ?"), append(args, *v)
+	}
+	if v := filter.Name; v != nil {
+		where, args = append(where, "name = ?"), append(args, strings.TrimSpace(*v))
+	}
+	if v := filter.WorkID; v != nil {
+		where, args = append(where, "id IN (SELECT series_id FROM series_works WHERE work_id = ?)"), append(args, *v)
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id, name, created_at, COUNT(*) OVER ()
+		FROM series
+		WHERE `+strings.Join(where, " AND ")+`
+		ORDER BY id ASC
+		`+FormatLimitOffset(filter.Limit, filter.Offset),
+		args...,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	series := make([]*bookid.Series, 0)
+	for rows.Next() {
+		var s bookid.Series
+		if err := rows.Scan(&s.ID, &s.Name, (*NullTime)(&s.CreatedAt), &n); err != nil {
+			return nil, 0, err
+		}
+		series = append(series, &s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+	return series, n, nil
+}
+
+// createSeries inserts a new series unless one with the same name exists, in
+// which case series is populated from the existing row.
+func createSeries(ctx context.Context, tx *Tx, series *bookid.Series) error {
+	series.Name = strings.Join(strings.Fields(series.Name), " ")
+	if err := series.Validate(); err != nil {
+		return err
+	}
+
+	if existing, _, err := findSeries(ctx, tx, bookid.SeriesFilter{Name: &series.Name}); err != nil {
+		return err
+	} else if len(existing) > 0 {
+		*series = *existing[0]
+		return nil
+	}
+
+	series.CreatedAt = tx.now
+
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO series (name, created_at)
+		VALUES (?, ?)
+	`,
+		series.Name,
+		(*NullTime)(&series.CreatedAt),
+	)
+	if err != nil {
+		return FormatError(err)
+	}
+
+	if series.ID, err = result.LastInsertId(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// deleteSeries permanently removes a series by ID.
+func deleteSeries(ctx context.Context, tx *Tx, id int64) error {
+	if _, err := findSeriesByID(ctx, tx, id); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM series WHERE id = ?`, id); err != nil {
+		return FormatError(err)
+	}
+	return nil
+}
+
+// addSeriesWork links a work to a series, or updates the volume number of an
+// existing link.
+func addSeriesWork(ctx context.Context, tx *Tx, sw *bookid.SeriesWork) error {
+	if err := sw.Validate(); err != nil {
+		return err
+	} else if _, err := findSeriesByID(ctx, tx, sw.SeriesID); err != nil {
+		return err
+	} else if _, err := findWorkByID(ctx, tx, sw.WorkID); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO series_works (series_id, work_id, volume)
+		VALUES (?, ?, ?)
+		ON CONFLICT DO UPDATE SET volume = excluded.volume
+	`,
+		sw.SeriesID,
+		sw.WorkID,
+		sw.Volume,
+	); err != nil {
+		return FormatError(err)
+	}
+	return nil
+}
+
+// removeSeriesWork unlinks a work from a series.
+func removeSeriesWork(ctx context.Context, tx *Tx, seriesID, workID int64) error {
+	result, err := tx.ExecContext(ctx, `DELETE FROM series_works WHERE series_id = ? AND work_id = ?`, seriesID, workID)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return bookid.Errorf(bookid.ENOTFOUND, "Series work not found.")
+	}
+	return nil
+}
+
+// findSeriesWorks returns the links matching a filter in volume order, with
+// works of unknown position last. Also returns a count of total matching
+// links which may differ if filter.Limit is set.
+func findSeriesWorks(ctx context.Context, tx *Tx, filter bookid.SeriesWorkFilter) (_ []*bookid.SeriesWork, n int, err error) {
+	where, args := []string{"1 = 1"}, []any{}
+	if v := filter.SeriesID; v != nil {
+		where, args = append(where, "series_id = ?"), append(args, *v)
+	}
+	if v := filter.WorkID; v != nil {
+		where, args = append(where, "work_id = ?"), append(args, *v)
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT series_id, work_id, volume, COUNT(*) OVER ()
+		FROM series_works
+		WHERE `+strings.Join(where, " AND ")+`
+		ORDER BY series_id ASC, volume = 0, volume ASC, work_id ASC
+		`+FormatLimitOffset(filter.Limit, filter.Offset),
+		args...,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	links := make([]*bookid.SeriesWork, 0)
+	for rows.Next() {
+		var sw bookid.SeriesWork
+		if err := rows.Scan(&sw.SeriesID, &sw.WorkID, &sw.Volume, &n); err != nil {
+			return nil, 0, err
+		}
+		links = append(links, &sw)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+	return links, n, nil
+}
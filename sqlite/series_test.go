@@ -0,0 +1,206 @@
+package sqlite_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/sqlite"
+)
+
+func TestSeriesService_CreateSeries(t *testing.T) {
+	t.Parallel()
+
+	t.Run("OK", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewSeriesService(db)
+		ctx := context.Background()
+
+		series := &bookid.Series{Name: "  Discworld "}
+		if err := s.CreateSeries(ctx, series); err != nil {
+			t.Fatal(err)
+		} else if got, want := series.ID, int64(1); got != want {
+			t.Fatalf("ID=%d, want %d", got, want)
+		} else if got, want := series.Name, "Discworld"; got != want {
+			t.Fatalf("Name=%q, want %q", got, want)
+		} else if series.CreatedAt.IsZero() {
+			t.Fatal("expected created at")
+		}
+
+		if other, err := s.FindSeriesByID(ctx, series.ID); err != nil {
+			t.Fatal(err)
+		} else if got, want := other.Name, "Discworld"; got != want {
+			t.Fatalf("Name=%q, want %q", got, want)
+		}
+	})
+
+	t.Run("SameName", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewSeriesService(db)
+		ctx := context.Background()
+
+		first := MustCreateSeries(t, ctx, db, &bookid.Series{Name: "Discworld"})
+		second := MustCreateSeries(t, ctx, db, &bookid.Series{Name: "DISCWORLD"})
+		if got, want := second.ID, first.ID; got != want {
+			t.Fatalf("ID=%d, want %d", got, want)
+		} else if got, want := second.Name, "Discworld"; got != want {
+			t.Fatalf("Name=%q, want %q", got, want)
+		}
+
+		if _, n, err := s.FindSeries(ctx, bookid.SeriesFilter{}); err != nil {
+			t.Fatal(err)
+		} else if got, want := n, 1; got != want {
+			t.Fatalf("n=%d, want %d", got, want)
+		}
+	})
+
+	t.Run("ErrNameRequired", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewSeriesService(db)
+
+		err := s.CreateSeries(context.Background(), &bookid.Series{Name: " "})
+		if code := bookid.ErrorCode(err); code != bookid.EINVALID {
+			t.Fatalf("ErrorCode()=%q, want %q", code, bookid.EINVALID)
+		}
+	})
+}
+
+func TestSeriesService_AddSeriesWork(t *testing.T) {
+	t.Parallel()
+
+	t.Run("OK", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewSeriesService(db)
+		ctx := context.Background()
+
+		series := MustCreateSeries(t, ctx, db, &bookid.Series{Name: "Discworld"})
+		mort := MustCreateWork(t, ctx, db, &bookid.Work{Title: "Mort"}).ID
+		colour := MustCreateWork(t, ctx, db, &bookid.Work{Title: "The Colour of Magic"}).ID
+		companion := MustCreateWork(t, ctx, db, &bookid.Work{Title: "The Discworld Companion"}).ID
+		for _, sw := range []*bookid.SeriesWork{
+			{SeriesID: series.ID, WorkID: companion},
+			{SeriesID: series.ID, WorkID: mort, Volume: 3},
+			{SeriesID: series.ID, WorkID: colour, Volume: 1},
+			{SeriesID: series.ID, WorkID: mort, Volume: 4}, // Renumbered
+		} {
+			if err := s.AddSeriesWork(ctx, sw); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		links, n, err := s.FindSeriesWorks(ctx, bookid.SeriesWorkFilter{SeriesID: &series.ID})
+		if err != nil {
+			t.Fatal(err)
+		} else if got, want := n, 3; got != want {
+			t.Fatalf("n=%d, want %d", got, want)
+		}
+		for i, want := range []bookid.SeriesWork{
+			{SeriesID: series.ID, WorkID: colour, Volume: 1},
+			{SeriesID: series.ID, WorkID: mort, Volume: 4},
+			{SeriesID: series.ID, WorkID: companion},
+		} {
+			if got := *links[i]; got != want {
+				t.Fatalf("links[%d]=%+v, want %+v", i, got, want)
+			}
+		}
+
+		if found, _, err := s.FindSeries(ctx, bookid.SeriesFilter{WorkID: &mort}); err != nil {
+			t.Fatal(err)
+		} else if len(found) != 1 || found[0].ID != series.ID {
+			t.Fatalf("series=%+v, want %d", found, series.ID)
+		}
+	})
+
+	t.Run("ErrWorkNotFound", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewSeriesService(db)
+		ctx := context.Background()
+
+		series := MustCreateSeries(t, ctx, db, &bookid.Series{Name: "Discworld"})
+		err := s.AddSeriesWork(ctx, &bookid.SeriesWork{SeriesID: series.ID, WorkID: 100})
+		if code := bookid.ErrorCode(err); code != bookid.ENOTFOUND {
+			t.Fatalf("ErrorCode()=%q, want %q", code, bookid.ENOTFOUND)
+		}
+	})
+
+	t.Run("ErrInvalidVolume", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewSeriesService(db)
+		ctx := context.Background()
+
+		series := MustCreateSeries(t, ctx, db, &bookid.Series{Name: "Discworld"})
+		work := MustCreateWork(t, ctx, db, &bookid.Work{Title: "Mort"})
+		err := s.AddSeriesWork(ctx, &bookid.SeriesWork{SeriesID: series.ID, WorkID: work.ID, Volume: -1})
+		if code := bookid.ErrorCode(err); code != bookid.EINVALID {
+			t.Fatalf("ErrorCode()=%q, want %q", code, bookid.EINVALID)
+		}
+	})
+}
+
+func TestSeriesService_RemoveSeriesWork(t *testing.T) {
+	t.Parallel()
+
+	db := MustOpenDB(t)
+	defer MustCloseDB(t, db)
+	s := sqlite.NewSeriesService(db)
+	ctx := context.Background()
+
+	series := MustCreateSeries(t, ctx, db, &bookid.Series{Name: "Discworld"})
+	work := MustCreateWork(t, ctx, db, &bookid.Work{Title: "Mort"})
+	if err := s.AddSeriesWork(ctx, &bookid.SeriesWork{SeriesID: series.ID, WorkID: work.ID, Volume: 4}); err != nil {
+		t.Fatal(err)
+	} else if err := s.RemoveSeriesWork(ctx, series.ID, work.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	err := s.RemoveSeriesWork(ctx, series.ID, work.ID)
+	if code := bookid.ErrorCode(err); code != bookid.ENOTFOUND {
+		t.Fatalf("ErrorCode()=%q, want %q", code, bookid.ENOTFOUND)
+	}
+}
+
+func TestSeriesService_DeleteSeries(t *testing.T) {
+	t.Parallel()
+
+	db := MustOpenDB(t)
+	defer MustCloseDB(t, db)
+	s := sqlite.NewSeriesService(db)
+	ctx := context.Background()
+
+	series := MustCreateSeries(t, ctx, db, &bookid.Series{Name: "Discworld"})
+	work := MustCreateWork(t, ctx, db, &bookid.Work{Title: "Mort"})
+	if err := s.AddSeriesWork(ctx, &bookid.SeriesWork{SeriesID: series.ID, WorkID: work.ID}); err != nil {
+		t.Fatal(err)
+	} else if err := s.DeleteSeries(ctx, series.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.FindSeriesByID(ctx, series.ID); bookid.ErrorCode(err) != bookid.ENOTFOUND {
+		t.Fatalf("ErrorCode()=%q, want %q", bookid.ErrorCode(err), bookid.ENOTFOUND)
+	} else if _, n, err := s.FindSeriesWorks(ctx, bookid.SeriesWorkFilter{WorkID: &work.ID}); err != nil {
+		t.Fatal(err)
+	} else if n != 0 {
+		t.Fatalf("n=%d, want 0", n)
+	}
+}
+
+// MustCreateSeries creates a series in the database. Fatal on error.
+func MustCreateSeries(tb testing.TB, ctx context.Context, db *sqlite.DB, series *bookid.Series) *bookid.Series {
+	tb.Helper()
+	if err := sqlite.NewSeriesService(db).CreateSeries(ctx, series); err != nil {
+		tb.Fatal(err)
+	}
+	return series
+}
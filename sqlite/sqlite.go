@@ -5,20 +5,45 @@ import (
 	"database/sql"
 	"database/sql/driver"
 	"embed"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io/fs"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"runtime"
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/fwojciec/bookid"
-	_ "github.com/mattn/go-sqlite3"
+	"github.com/mattn/go-sqlite3"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
 )
 
+// InstrumentationName identifies the tracer of this package.
+const InstrumentationName = "github.com/fwojciec/bookid/sqlite"
+
 //go:embed migration/*.sql
 var migrationFS embed.FS
 
+// driverName is the database/sql driver of DB: go-sqlite3 with the functions
+// of this package registered on every connection.
+const driverName = "sqlite3_bookid"
+
+func init() {
+	sql.Register(driverName, &sqlite3.SQLiteDriver{
+		ConnectHook: func(conn *sqlite3.SQLiteConn) error {
+			return conn.RegisterFunc("bm25", bm25, true)
+		},
+	})
+}
+
 // DB represents the database connection.
 type DB struct {
 	db     *sql.DB
@@ -31,13 +56,29 @@ type DB struct {
 	// Returns the current time. Defaults to time.Now().
 	// Can be mocked for tests.
 	Now func() time.Time
+
+	// Receives debug logs of migrations, statements and transactions.
+	// Defaults to discarding them.
+	Logger *slog.Logger
+
+	// Traces transactions, named after the service method starting them,
+	// and their statements. Defaults to a tracer of the global provider.
+	Tracer trace.Tracer
+
+	// Receives events of committed changes to the catalog. Defaults to
+	// discarding them.
+	EventService bookid.EventService
 }
 
 // NewDB returns a new instance of DB associated with the given datasource name.
 func NewDB(dsn string) *DB {
 	db := &DB{
-		DSN: dsn,
-		Now: time.Now,
+		DSN:    dsn,
+		Now:    time.Now,
+		Logger: slog.New(slog.DiscardHandler),
+		Tracer: otel.Tracer(InstrumentationName),
+
+		EventService: bookid.NopEventService(),
 	}
 	db.ctx, db.cancel = context.WithCancel(context.Background())
 	return db
@@ -58,10 +99,16 @@ func (db *DB) Open() (err error) {
 	}
 
 	// Connect to the database.
-	if db.db, err = sql.Open("sqlite3", db.DSN); err != nil {
+	if db.db, err = sql.Open(driverName, db.DSN); err != nil {
 		return err
 	}
 
+	// Each connection to an in-memory database gets its own empty database so
+	// restrict the pool to a single connection to share the schema.
+	if db.DSN == ":memory:" {
+		db.db.SetMaxOpenConns(1)
+	}
+
 	// Enable WAL. SQLite performs better with the WAL  because it allows
 	// multiple readers to operate while data is being written.
 	if _, err := db.db.Exec(`PRAGMA journal_mode = wal;`); err != nil {
@@ -86,7 +133,7 @@ func (db *DB) Open() (err error) {
 // migrate sets up migration tracking and executes pending migration files.
 //
 // Migration files are embedded in the sqlite/migration folder and are executed
-// in lexigraphical order.
+// in lexicographical order.
 //
 // Once a migration is run, its name is stored in the 'migrations' table so it
 // is not re-executed. Migrations run in a transaction to prevent partial
@@ -114,7 +161,7 @@ func (db *DB) migrate() error {
 	return nil
 }
 
-// migrate runs a single migration file within a transaction. On success, the
+// migrateFile runs a single migration file within a transaction. On success, the
 // migration file name is saved to the "migrations" table to prevent re-running.
 func (db *DB) migrateFile(name string) error {
 	tx, err := db.db.Begin()
@@ -145,7 +192,11 @@ func (db *DB) migrateFile(name string) error {
 		return err
 	}
 
-	return tx.Commit()
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	db.Logger.Debug("applied migration", "name", name)
+	return nil
 }
 
 // Close closes the database connection.
@@ -164,24 +215,138 @@ func (db *DB) Close() error {
 // provides a reference to the database and a fixed timestamp at the start of
 // the transaction. The timestamp allows us to mock time during tests as well.
 func (db *DB) BeginTx(ctx context.Context, opts *sql.TxOptions) (*Tx, error) {
+	ctx, span := db.Tracer.Start(ctx, callerName(), trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("db.system", "sqlite")))
 	tx, err := db.db.BeginTx(ctx, opts)
 	if err != nil {
+		endSpan(span, err)
 		return nil, err
 	}
 
 	// Return wrapper Tx that includes the transaction start time.
 	return &Tx{
-		Tx:  tx,
-		db:  db,
-		now: db.Now().UTC().Truncate(time.Second),
+		Tx:    tx,
+		db:    db,
+		now:   db.Now().UTC().Truncate(time.Second),
+		begin: time.Now(),
+		span:  span,
 	}, nil
 }
 
+// callerName returns the name of the function calling BeginTx, such as
+// "sqlite.(*WorkService).CreateWork".
+func callerName() string {
+	pc, _, _, ok := runtime.Caller(2)
+	if !ok {
+		return "sqlite.Tx"
+	}
+	name := runtime.FuncForPC(pc).Name()
+	return name[strings.LastIndex(name, "/")+1:]
+}
+
+// endSpan ends span, marking it failed if err is not nil.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
 // Tx wraps the SQL Tx object to provide a timestamp at the start of the transaction.
+// Statements and the outcome of the transaction are logged at debug level.
 type Tx struct {
 	*sql.Tx
-	db  *DB
-	now time.Time
+	db    *DB
+	now   time.Time
+	begin time.Time  // wall clock start, for logging how long it was open
+	span  trace.Span // ended on commit or rollback
+
+	// Events of the changes made in the transaction, published on commit.
+	events []bookid.Event
+}
+
+// ExecContext executes a statement that returns no rows.
+func (tx *Tx) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	ctx, span := tx.startStatement(ctx, query)
+	start := time.Now()
+	result, err := tx.Tx.ExecContext(ctx, query, args...)
+	tx.logStatement(ctx, query, start, err)
+	endSpan(span, err)
+	return result, err
+}
+
+// QueryContext executes a query that returns rows.
+func (tx *Tx) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	ctx, span := tx.startStatement(ctx, query)
+	start := time.Now()
+	rows, err := tx.Tx.QueryContext(ctx, query, args...)
+	tx.logStatement(ctx, query, start, err)
+	endSpan(span, err)
+	return rows, err
+}
+
+// QueryRowContext executes a query that returns at most one row. Errors are
+// deferred until the row is scanned so are not logged.
+func (tx *Tx) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	ctx, span := tx.startStatement(ctx, query)
+	start := time.Now()
+	row := tx.Tx.QueryRowContext(ctx, query, args...)
+	tx.logStatement(ctx, query, start, nil)
+	endSpan(span, nil)
+	return row
+}
+
+// Commit commits the transaction and publishes the events of its changes.
+func (tx *Tx) Commit() error {
+	err := tx.Tx.Commit()
+	tx.db.Logger.Debug("committed transaction", "duration", time.Since(tx.begin), "error", err)
+	endSpan(tx.span, err)
+	if err != nil {
+		return err
+	}
+
+	for _, event := range tx.events {
+		tx.db.EventService.PublishEvent(event)
+	}
+	return nil
+}
+
+// Rollback aborts the transaction. It is a no-op returning sql.ErrTxDone
+// after Commit, which is not logged.
+func (tx *Tx) Rollback() error {
+	err := tx.Tx.Rollback()
+	if !errors.Is(err, sql.ErrTxDone) {
+		tx.db.Logger.Debug("rolled back transaction", "duration", time.Since(tx.begin), "error", err)
+		tx.span.SetAttributes(attribute.Bool("db.rollback", true))
+		endSpan(tx.span, err)
+	}
+	return err
+}
+
+// publish queues an event to be published once the transaction commits.
+func (tx *Tx) publish(typ string, payload any) {
+	tx.events = append(tx.events, bookid.Event{Type: typ, Payload: payload})
+}
+
+// startStatement starts the span of a statement as a child of the
+// transaction's span, as ctx is that of the service method.
+func (tx *Tx) startStatement(ctx context.Context, query string) (context.Context, trace.Span) {
+	if !tx.span.IsRecording() {
+		return ctx, noop.Span{}
+	}
+	return tx.db.Tracer.Start(trace.ContextWithSpan(ctx, tx.span), "sqlite.statement",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("db.system", "sqlite"), attribute.String("db.statement", strings.Join(strings.Fields(query), " "))))
+}
+
+// logStatement logs a statement with its whitespace collapsed onto one line.
+func (tx *Tx) logStatement(ctx context.Context, query string, start time.Time, err error) {
+	if !tx.db.Logger.Enabled(ctx, slog.LevelDebug) {
+		return
+	}
+	tx.db.Logger.DebugContext(ctx, "executed statement",
+		"sql", strings.Join(strings.Fields(query), " "), "duration", time.Since(start), "error", err)
 }
 
 // NullTime represents a helper wrapper for time.Time. It automatically converts
@@ -208,6 +373,58 @@ func (n *NullTime) Value() (driver.Value, error) {
 	return (*time.Time)(n).UTC().Format(time.RFC3339), nil
 }
 
+// StringMap represents a helper wrapper for string maps. It converts them
+// to/from JSON objects, storing empty maps as empty strings.
+type StringMap map[string]string
+
+// Scan reads a JSON object from the database.
+func (m *StringMap) Scan(value any) error {
+	s, ok := value.(string)
+	if value != nil && !ok {
+		return fmt.Errorf("StringMap: cannot scan to map: %T", value)
+	}
+	*m = nil
+	if s == "" {
+		return nil
+	}
+	return json.Unmarshal([]byte(s), (*map[string]string)(m))
+}
+
+// Value formats a map as a JSON object for the database.
+func (m *StringMap) Value() (driver.Value, error) {
+	if m == nil || len(*m) == 0 {
+		return "", nil
+	}
+	data, err := json.Marshal(map[string]string(*m))
+	return string(data), err
+}
+
+// StringSlice represents a helper wrapper for string slices. It converts them
+// to/from JSON arrays, storing empty slices as empty strings.
+type StringSlice []string
+
+// Scan reads a JSON array from the database.
+func (a *StringSlice) Scan(value any) error {
+	s, ok := value.(string)
+	if value != nil && !ok {
+		return fmt.Errorf("StringSlice: cannot scan to slice: %T", value)
+	}
+	*a = nil
+	if s == "" {
+		return nil
+	}
+	return json.Unmarshal([]byte(s), (*[]string)(a))
+}
+
+// Value formats a slice as a JSON array for the database.
+func (a *StringSlice) Value() (driver.Value, error) {
+	if a == nil || len(*a) == 0 {
+		return "", nil
+	}
+	data, err := json.Marshal([]string(*a))
+	return string(data), err
+}
+
 // FormatLimitOffset returns a SQL string for a given limit & offset.
 // Clauses are only added if limit and/or offset are greater than zero.
 func FormatLimitOffset(limit, offset int) string {
@@ -228,16 +445,18 @@ func FormatError(err error) error {
 		return nil
 	}
 
-	// Here you can add specific SQLite error conversions.
-	// Example conversions based on error messages:
-	switch err.Error() {
-	case "UNIQUE constraint failed":
-		return bookid.Errorf(bookid.ECONFLICT, "Resource already exists.")
-	default:
-		// Check if it's a not found error
-		if err == sql.ErrNoRows {
-			return bookid.Errorf(bookid.ENOTFOUND, "Resource not found.")
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) {
+		switch sqliteErr.ExtendedCode {
+		case sqlite3.ErrConstraintUnique, sqlite3.ErrConstraintPrimaryKey:
+			return bookid.Errorf(bookid.ECONFLICT, "Resource already exists.")
+		case sqlite3.ErrConstraintForeignKey:
+			return bookid.Errorf(bookid.ENOTFOUND, "Referenced resource not found.")
 		}
-		return err
 	}
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return bookid.Errorf(bookid.ENOTFOUND, "Resource not found.")
+	}
+	return err
 }
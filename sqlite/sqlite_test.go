@@ -1,12 +1,21 @@
 package sqlite_test
 
 import (
+	"bytes"
+	"context"
 	"flag"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/inmem"
 	"github.com/fwojciec/bookid/sqlite"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 )
 
 var dump = flag.Bool("dump", false, "save work data")
@@ -18,6 +27,146 @@ func TestDB(t *testing.T) {
 	MustCloseDB(t, db)
 }
 
+// Ensure migrations are only applied once when an existing database is reopened.
+func TestDB_Reopen(t *testing.T) {
+	t.Parallel()
+	dsn := filepath.Join(t.TempDir(), "db")
+	ctx := context.Background()
+
+	db := sqlite.NewDB(dsn)
+	if err := db.Open(); err != nil {
+		t.Fatal(err)
+	}
+	work := &bookid.Work{Title: "Dune"}
+	if err := sqlite.NewWorkService(db).CreateWork(ctx, work); err != nil {
+		t.Fatal(err)
+	}
+	MustCloseDB(t, db)
+
+	// Reopening would fail if table-creating migrations were re-executed.
+	db = sqlite.NewDB(dsn)
+	if err := db.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer MustCloseDB(t, db)
+
+	if other, err := sqlite.NewWorkService(db).FindWorkByID(ctx, work.ID); err != nil {
+		t.Fatal(err)
+	} else if got, want := other.Title, "Dune"; got != want {
+		t.Fatalf("Title=%q, want %q", got, want)
+	}
+}
+
+// Ensure migrations, statements and transactions are logged at debug level.
+func TestDB_Logger(t *testing.T) {
+	t.Parallel()
+	var buf bytes.Buffer
+	db := sqlite.NewDB(":memory:")
+	db.Logger = slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	if err := db.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer MustCloseDB(t, db)
+
+	if err := sqlite.NewWorkService(db).CreateWork(context.Background(), &bookid.Work{Title: "Dune"}); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`msg="applied migration" name=migration/`,
+		`msg="executed statement" sql="INSERT INTO works`,
+		`msg="committed transaction"`,
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("log does not contain %q:\n%s", want, buf.String())
+		}
+	}
+	if strings.Contains(buf.String(), "rolled back") {
+		t.Errorf("log reports rollback of committed transaction:\n%s", buf.String())
+	}
+}
+
+// Ensure transactions are traced as spans named after the service method,
+// with a child span for each statement.
+func TestDB_Tracer(t *testing.T) {
+	t.Parallel()
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	db := MustOpenDB(t)
+	defer MustCloseDB(t, db)
+	db.Tracer = tp.Tracer("test")
+
+	if err := sqlite.NewWorkService(db).CreateWork(context.Background(), &bookid.Work{Title: "Dune"}); err != nil {
+		t.Fatal(err)
+	}
+
+	spans := recorder.Ended()
+	if len(spans) < 2 {
+		t.Fatalf("got %d spans, want a transaction and its statements", len(spans))
+	}
+	tx := spans[len(spans)-1]
+	if got, want := tx.Name(), "sqlite.(*WorkService).CreateWork"; got != want {
+		t.Fatalf("Name=%q, want %q", got, want)
+	}
+	for _, span := range spans[:len(spans)-1] {
+		if span.Name() != "sqlite.statement" {
+			t.Errorf("Name=%q, want statement", span.Name())
+		} else if span.Parent().SpanID() != tx.SpanContext().SpanID() {
+			t.Errorf("statement span is not a child of the transaction span")
+		}
+	}
+}
+
+// Ensure events of committed changes are published, and those of rolled
+// back changes are not.
+func TestDB_EventService(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	db := MustOpenDB(t)
+	defer MustCloseDB(t, db)
+	events := inmem.NewEventService()
+	db.EventService = events
+	sub, err := events.Subscribe(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Close()
+
+	workID, pubID, err := sqlite.NewCatalogService(db).SaveResult(ctx, bookid.BookResult{Title: "Dune", ISBN13: "9780441172719"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event := <-sub.C(); event.Type != bookid.EventTypeWorkCreated {
+		t.Fatalf("Type=%q, want work created", event.Type)
+	} else if got := event.Payload.(*bookid.WorkCreatedPayload).Work.ID; got != workID {
+		t.Fatalf("Work.ID=%d, want %d", got, workID)
+	}
+	if event := <-sub.C(); event.Type != bookid.EventTypePublicationCreated {
+		t.Fatalf("Type=%q, want publication created", event.Type)
+	} else if got := event.Payload.(*bookid.PublicationCreatedPayload).Publication.ID; got != pubID {
+		t.Fatalf("Publication.ID=%d, want %d", got, pubID)
+	}
+
+	// A failed transaction publishes nothing.
+	if err := sqlite.NewPublicationService(db).CreatePublication(ctx, &bookid.Publication{WorkID: 100}); bookid.ErrorCode(err) != bookid.ENOTFOUND {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	other := &bookid.Work{Title: "Dune"}
+	if err := sqlite.NewWorkService(db).CreateWork(ctx, other); err != nil {
+		t.Fatal(err)
+	} else if err := sqlite.NewWorkService(db).MergeWorks(ctx, workID, other.ID); err != nil {
+		t.Fatal(err)
+	}
+	if event := <-sub.C(); event.Type != bookid.EventTypeWorkCreated {
+		t.Fatalf("Type=%q, want work created", event.Type)
+	}
+	if event := <-sub.C(); event.Type != bookid.EventTypeWorkMerged {
+		t.Fatalf("Type=%q, want work merged", event.Type)
+	} else if got, want := event.Payload, (&bookid.WorkMergedPayload{TargetID: workID, SourceIDs: []int64{other.ID}}); !reflect.DeepEqual(got, want) {
+		t.Fatalf("Payload=%#v, want %#v", got, want)
+	}
+}
+
 // MustOpenDB returns a new, open DB. Fatal on error.
 func MustOpenDB(tb testing.TB) *sqlite.DB {
 	tb.Helper()
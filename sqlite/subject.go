@@ -0,0 +1,210 @@
+package sqlite
+
+import (
+	"context"
+	"strings"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/subject"
+)
+
+// Ensure service implements interface.
+var _ bookid.SubjectService = (*SubjectService)(nil)
+
+// SubjectService represents a service for managing subjects.
+type SubjectService struct {
+	db *DB
+}
+
+// NewSubjectService returns a new instance of SubjectService.
+func NewSubjectService(db *DB) *SubjectService {
+	return &SubjectService{db: db}
+}
+
+// FindSubjectByID retrieves a single subject by ID.
+// Returns ENOTFOUND if the subject does not exist.
+func (s *SubjectService) FindSubjectByID(ctx context.Context, id int64) (*bookid.Subject, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+	return findSubjectByID(ctx, tx, id)
+}
+
+// FindSubjects retrieves a list of subjects matching the filter.
+func (s *SubjectService) FindSubjects(ctx context.Context, filter bookid.SubjectFilter) ([]*bookid.Subject, int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer func() { _ = tx.Rollback() }()
+	return findSubjects(ctx, tx, filter)
+}
+
+// CreateSubject creates a new subject, or populates subject from the
+// existing one with the same normalized name.
+func (s *SubjectService) CreateSubject(ctx context.Context, subject *bookid.Subject) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := createSubject(ctx, tx, subject); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// AddWorkSubject tags a work with a subject.
+func (s *SubjectService) AddWorkSubject(ctx context.Context, ws *bookid.WorkSubject) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := addWorkSubject(ctx, tx, ws); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// RemoveWorkSubject removes a subject from a work.
+// Returns ENOTFOUND if the work is not tagged with the subject.
+func (s *SubjectService) RemoveWorkSubject(ctx context.Context, ws *bookid.WorkSubject) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := removeWorkSubject(ctx, tx, ws); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// findSubjectByID is a helper function to fetch a subject by ID.
+// Returns ENOTFOUND if the subject does not exist.
+func findSubjectByID(ctx context.Context, tx *Tx, id int64) (*bookid.Subject, error) {
+	subjects, _, err := findSubjects(ctx, tx, bookid.SubjectFilter{ID: &id})
+	if err != nil {
+		return nil, err
+	} else if len(subjects) == 0 {
+		return nil, bookid.Errorf(bookid.ENOTFOUND, "Subject not found.")
+	}
+	return subjects[0], nil
+}
+
+// findSubjects returns a list of subjects matching a filter, ordered by name.
+// Also returns a count of total matching subjects which may differ if
+// filter.Limit is set.
+func findSubjects(ctx context.Context, tx *Tx, filter bookid.SubjectFilter) (_ []*bookid.Subject, n int, err error) {
+	where, args := []string{"1 = 1"}, []any{}
+	if v := filter.ID; v != nil {
+		where, args = append(where, "id = ?"), append(args, *v)
+	}
+	if v := filter.Name; v != nil {
+		where, args = append(where, "name = ?"), append(args, subject.Name(*v))
+	}
+	if v := filter.WorkID; v != nil {
+		where, args = append(where, "id IN (SELECT subject_id FROM work_subjects WHERE work_id = ?)"), append(args, *v)
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id, name, bisac_code, COUNT(*) OVER ()
+		FROM subjects
+		WHERE `+strings.Join(where, " AND ")+`
+		ORDER BY name ASC
+		`+FormatLimitOffset(filter.Limit, filter.Offset),
+		args...,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	subjects := make([]*bookid.Subject, 0)
+	for rows.Next() {
+		var s bookid.Subject
+		if err := rows.Scan(&s.ID, &s.Name, &s.BISACCode, &n); err != nil {
+			return nil, 0, err
+		}
+		subjects = append(subjects, &s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+	return subjects, n, nil
+}
+
+// createSubject normalizes the subject's name and inserts a new subject
+// unless one with the same name exists, in which case subject is populated
+// from the existing row. The BISAC code of subject is recorded on an
+// existing subject without one.
+func createSubject(ctx context.Context, tx *Tx, s *bookid.Subject) error {
+	s.Name = subject.Name(s.Name)
+	s.BISACCode = strings.ToUpper(strings.TrimSpace(s.BISACCode))
+	if err := s.Validate(); err != nil {
+		return err
+	}
+
+	if existing, _, err := findSubjects(ctx, tx, bookid.SubjectFilter{Name: &s.Name}); err != nil {
+		return err
+	} else if len(existing) > 0 {
+		if existing[0].BISACCode == "" && s.BISACCode != "" {
+			if _, err := tx.ExecContext(ctx, `UPDATE subjects SET bisac_code = ? WHERE id = ?`, s.BISACCode, existing[0].ID); err != nil {
+				return FormatError(err)
+			}
+			existing[0].BISACCode = s.BISACCode
+		}
+		*s = *existing[0]
+		return nil
+	}
+
+	result, err := tx.ExecContext(ctx, `INSERT INTO subjects (name, bisac_code) VALUES (?, ?)`, s.Name, s.BISACCode)
+	if err != nil {
+		return FormatError(err)
+	}
+	if s.ID, err = result.LastInsertId(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// addWorkSubject tags a work with a subject, ignoring existing tags.
+func addWorkSubject(ctx context.Context, tx *Tx, ws *bookid.WorkSubject) error {
+	if _, err := findWorkByID(ctx, tx, ws.WorkID); err != nil {
+		return err
+	} else if _, err := findSubjectByID(ctx, tx, ws.SubjectID); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO work_subjects (work_id, subject_id)
+		VALUES (?, ?)
+		ON CONFLICT DO NOTHING
+	`,
+		ws.WorkID,
+		ws.SubjectID,
+	); err != nil {
+		return FormatError(err)
+	}
+	return nil
+}
+
+// removeWorkSubject removes a subject from a work.
+func removeWorkSubject(ctx context.Context, tx *Tx, ws *bookid.WorkSubject) error {
+	result, err := tx.ExecContext(ctx, `DELETE FROM work_subjects WHERE work_id = ? AND subject_id = ?`, ws.WorkID, ws.SubjectID)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return bookid.Errorf(bookid.ENOTFOUND, "Work subject not found.")
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package sqlite_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/sqlite"
+)
+
+func TestSubjectService_CreateSubject(t *testing.T) {
+	t.Parallel()
+
+	t.Run("OK", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewSubjectService(db)
+		ctx := context.Background()
+
+		subject := &bookid.Subject{Name: "Science-Fiction."}
+		if err := s.CreateSubject(ctx, subject); err != nil {
+			t.Fatal(err)
+		} else if got, want := subject.Name, "science fiction"; got != want {
+			t.Fatalf("Name=%q, want %q", got, want)
+		}
+
+		// Variants resolve to the same subject.
+		other := &bookid.Subject{Name: "Sci-Fi"}
+		if err := s.CreateSubject(ctx, other); err != nil {
+			t.Fatal(err)
+		} else if got, want := other.ID, subject.ID; got != want {
+			t.Fatalf("ID=%d, want %d", got, want)
+		}
+	})
+
+	t.Run("BISACCode", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewSubjectService(db)
+		ctx := context.Background()
+
+		subject := MustCreateSubject(t, ctx, db, &bookid.Subject{Name: "science fiction"})
+
+		// The code is recorded on the existing subject, but not replaced.
+		other := &bookid.Subject{Name: "Science Fiction", BISACCode: "fic028000"}
+		if err := s.CreateSubject(ctx, other); err != nil {
+			t.Fatal(err)
+		} else if got, want := other.ID, subject.ID; got != want {
+			t.Fatalf("ID=%d, want %d", got, want)
+		} else if got, want := other.BISACCode, "FIC028000"; got != want {
+			t.Fatalf("BISACCode=%q, want %q", got, want)
+		}
+		if err := s.CreateSubject(ctx, &bookid.Subject{Name: "science fiction", BISACCode: "FIC000000"}); err != nil {
+			t.Fatal(err)
+		}
+
+		found, err := s.FindSubjectByID(ctx, subject.ID)
+		if err != nil {
+			t.Fatal(err)
+		} else if got, want := found.BISACCode, "FIC028000"; got != want {
+			t.Fatalf("BISACCode=%q, want %q", got, want)
+		}
+	})
+
+	t.Run("ErrNameRequired", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewSubjectService(db)
+
+		err := s.CreateSubject(context.Background(), &bookid.Subject{Name: "General"})
+		if code := bookid.ErrorCode(err); code != bookid.EINVALID {
+			t.Fatalf("ErrorCode()=%q, want %q", code, bookid.EINVALID)
+		}
+	})
+}
+
+func TestSubjectService_AddWorkSubject(t *testing.T) {
+	t.Parallel()
+
+	t.Run("OK", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewSubjectService(db)
+		ctx := context.Background()
+
+		work := MustCreateWork(t, ctx, db, &bookid.Work{Title: "Solaris"})
+		for _, name := range []string{"science fiction", "Psychological fiction"} {
+			subject := MustCreateSubject(t, ctx, db, &bookid.Subject{Name: name})
+			ws := &bookid.WorkSubject{WorkID: work.ID, SubjectID: subject.ID}
+			if err := s.AddWorkSubject(ctx, ws); err != nil {
+				t.Fatal(err)
+			} else if err := s.AddWorkSubject(ctx, ws); err != nil {
+				t.Fatal(err) // Already tagged
+			}
+		}
+
+		subjects, n, err := s.FindSubjects(ctx, bookid.SubjectFilter{WorkID: &work.ID})
+		if err != nil {
+			t.Fatal(err)
+		} else if got, want := n, 2; got != want {
+			t.Fatalf("n=%d, want %d", got, want)
+		} else if got, want := subjects[0].Name, "psychological fiction"; got != want {
+			t.Fatalf("Name=%q, want %q", got, want)
+		}
+
+		ws := &bookid.WorkSubject{WorkID: work.ID, SubjectID: subjects[0].ID}
+		if err := s.RemoveWorkSubject(ctx, ws); err != nil {
+			t.Fatal(err)
+		} else if err := s.RemoveWorkSubject(ctx, ws); bookid.ErrorCode(err) != bookid.ENOTFOUND {
+			t.Fatalf("ErrorCode()=%q, want %q", bookid.ErrorCode(err), bookid.ENOTFOUND)
+		}
+	})
+
+	t.Run("ErrSubjectNotFound", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewSubjectService(db)
+		ctx := context.Background()
+
+		work := MustCreateWork(t, ctx, db, &bookid.Work{Title: "Solaris"})
+		err := s.AddWorkSubject(ctx, &bookid.WorkSubject{WorkID: work.ID, SubjectID: 100})
+		if code := bookid.ErrorCode(err); code != bookid.ENOTFOUND {
+			t.Fatalf("ErrorCode()=%q, want %q", code, bookid.ENOTFOUND)
+		}
+	})
+}
+
+// MustCreateSubject creates a subject in the database. Fatal on error.
+func MustCreateSubject(tb testing.TB, ctx context.Context, db *sqlite.DB, subject *bookid.Subject) *bookid.Subject {
+	tb.Helper()
+	if err := sqlite.NewSubjectService(db).CreateSubject(ctx, subject); err != nil {
+		tb.Fatal(err)
+	}
+	return subject
+}
@@ -0,0 +1,648 @@
+package sqlite
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/asin"
+	"github.com/fwojciec/bookid/isbn"
+)
+
+// Ensure service implements interface.
+var _ bookid.SyncService = (*SyncService)(nil)
+
+// SyncService represents a service for syncing the catalog with another.
+type SyncService struct {
+	db *DB
+}
+
+// NewSyncService returns a new instance of SyncService.
+func NewSyncService(db *DB) *SyncService {
+	return &SyncService{db: db}
+}
+
+// FindChanges retrieves the works that changed since a time, along with
+// those whose publications changed, and the tombstones of works and
+// publications removed since. The zero time retrieves everything.
+func (s *SyncService) FindChanges(ctx context.Context, since time.Time) (*bookid.SyncChanges, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+	return findChanges(ctx, tx, since)
+}
+
+// ApplyChanges applies the changes of another catalog in a single
+// transaction, resolving conflicts by the options. Rows are written with the
+// timestamps of the other catalog, so they are not sent back to it.
+func (s *SyncService) ApplyChanges(ctx context.Context, changes *bookid.SyncChanges, opts bookid.SyncOptions) (*bookid.SyncReport, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	report := &bookid.SyncReport{}
+	for _, rec := range changes.Records {
+		if err := applySyncRecord(ctx, tx, rec, opts, report); err != nil {
+			return nil, err
+		}
+	}
+	for _, ts := range changes.Tombstones {
+		if err := applyTombstone(ctx, tx, ts, opts, report); err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// FindLastSync returns the time the catalog was last synced with a peer, or
+// the zero time if it never was.
+func (s *SyncService) FindLastSync(ctx context.Context, peer string) (time.Time, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var t time.Time
+	if err := tx.QueryRowContext(ctx, `SELECT synced_at FROM sync_peers WHERE peer = ?`, peer).Scan((*NullTime)(&t)); errors.Is(err, sql.ErrNoRows) {
+		return time.Time{}, nil
+	} else if err != nil {
+		return time.Time{}, err
+	}
+	return t, nil
+}
+
+// SetLastSync records the time the catalog was synced with a peer.
+func (s *SyncService) SetLastSync(ctx context.Context, peer string, t time.Time) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO sync_peers (peer, synced_at) VALUES (?, ?)
+		ON CONFLICT (peer) DO UPDATE SET synced_at = excluded.synced_at
+	`, peer, (*NullTime)(&t)); err != nil {
+		return FormatError(err)
+	}
+	return tx.Commit()
+}
+
+// findChanges returns the changes of the catalog since a time.
+func findChanges(ctx context.Context, tx *Tx, since time.Time) (*bookid.SyncChanges, error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id FROM works
+		WHERE ?1 IS NULL OR updated_at > ?1 OR deleted_at > ?1 OR id IN (
+			SELECT work_id FROM publications WHERE updated_at > ?1 OR deleted_at > ?1
+		)
+		ORDER BY id ASC
+	`, (*NullTime)(&since))
+	if err != nil {
+		return nil, err
+	}
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	changes := &bookid.SyncChanges{Records: make([]*bookid.SyncRecord, 0, len(ids)), Tombstones: make([]*bookid.Tombstone, 0)}
+	for _, id := range ids {
+		works, _, err := findWorks(ctx, tx, bookid.WorkFilter{ID: &id, IncludeDeleted: true})
+		if err != nil {
+			return nil, err
+		} else if len(works) == 0 {
+			continue
+		}
+		authors, _, err := findAuthors(ctx, tx, bookid.AuthorFilter{WorkID: &id})
+		if err != nil {
+			return nil, err
+		}
+		pubs, _, err := findPublications(ctx, tx, bookid.PublicationFilter{WorkID: &id, IncludeDeleted: true})
+		if err != nil {
+			return nil, err
+		}
+		changes.Records = append(changes.Records, &bookid.SyncRecord{Work: works[0], Authors: authors, Publications: pubs})
+	}
+
+	if changes.Tombstones, err = findTombstones(ctx, tx, since); err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+// findTombstones returns the tombstones of rows removed since a time, or all
+// of them for the zero time. Publications come before the works removed with
+// them, so each is counted when applied.
+func findTombstones(ctx context.Context, tx *Tx, since time.Time) ([]*bookid.Tombstone, error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT uid, entity, deleted_at FROM tombstones
+		WHERE ?1 IS NULL OR deleted_at > ?1
+		ORDER BY deleted_at ASC, entity = ? ASC, uid ASC
+	`, (*NullTime)(&since), bookid.AuditEntityWork)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tombstones := make([]*bookid.Tombstone, 0)
+	for rows.Next() {
+		var ts bookid.Tombstone
+		if err := rows.Scan(&ts.UID, &ts.Entity, (*NullTime)(&ts.DeletedAt)); err != nil {
+			return nil, err
+		}
+		tombstones = append(tombstones, &ts)
+	}
+	return tombstones, rows.Err()
+}
+
+// findTombstone returns the tombstone of a UID, or nil if there is none.
+func findTombstone(ctx context.Context, tx *Tx, uid string) (*bookid.Tombstone, error) {
+	ts := &bookid.Tombstone{UID: uid}
+	if err := tx.QueryRowContext(ctx, `
+		SELECT entity, deleted_at FROM tombstones WHERE uid = ?
+	`, uid).Scan(&ts.Entity, (*NullTime)(&ts.DeletedAt)); errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return ts, nil
+}
+
+// insertTombstones records the rows whose UIDs query selects as removed now.
+func insertTombstones(ctx context.Context, tx *Tx, entity string, query string, args ...any) error {
+	if _, err := tx.ExecContext(ctx, `
+		INSERT OR REPLACE INTO tombstones (uid, entity, deleted_at)
+		SELECT uid, ?, ? FROM (`+query+`)
+	`, append([]any{entity, (*NullTime)(&tx.now)}, args...)...); err != nil {
+		return FormatError(err)
+	}
+	return nil
+}
+
+// deleteTombstone forgets the removal of a row brought back by a sync.
+func deleteTombstone(ctx context.Context, tx *Tx, uid string) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM tombstones WHERE uid = ?`, uid); err != nil {
+		return FormatError(err)
+	}
+	return nil
+}
+
+// applySyncRecord applies an incoming work with its authors and publications.
+func applySyncRecord(ctx context.Context, tx *Tx, rec *bookid.SyncRecord, opts bookid.SyncOptions, report *bookid.SyncReport) error {
+	in := rec.Work
+	if in == nil || in.UID == "" {
+		return bookid.Errorf(bookid.EINVALID, "Sync record work UID required.")
+	}
+	inAt := changedAt(in.UpdatedAt, in.DeletedAt)
+
+	work, err := findSyncWork(ctx, tx, rec)
+	if err != nil {
+		return err
+	}
+
+	if work == nil {
+		if ts, err := findTombstone(ctx, tx, in.UID); err != nil {
+			return err
+		} else if ts != nil {
+			if !inAt.After(opts.Since) {
+				return nil // removed since and not changed by the other catalog
+			}
+			incoming := opts.IncomingWins(ts.DeletedAt, inAt)
+			report.Conflicts = append(report.Conflicts, &bookid.SyncConflict{UID: in.UID, Entity: bookid.AuditEntityWork, Title: in.Title, Incoming: incoming})
+			if !incoming {
+				return nil // stays removed
+			} else if err := deleteTombstone(ctx, tx, in.UID); err != nil {
+				return err
+			}
+		}
+		work = &bookid.Work{UID: in.UID, Title: in.Title, Author: in.Author, OriginalTitle: in.OriginalTitle, OriginalLanguage: in.OriginalLanguage}
+		if err := createWork(ctx, tx, work); err != nil {
+			return err
+		} else if err := linkSyncAuthors(ctx, tx, work.ID, rec.Authors); err != nil {
+			return err
+		}
+		report.Created++
+		if err := applySyncPublications(ctx, tx, work.ID, rec.Publications, opts, report); err != nil {
+			return err
+		}
+		return setSyncTimes(ctx, tx, "works", work.ID, in.CreatedAt, in.UpdatedAt, in.DeletedAt)
+	}
+
+	localAt := changedAt(work.UpdatedAt, work.DeletedAt)
+	take := false
+	if localAt.Equal(inAt) || !inAt.After(opts.Since) {
+		// The same version in both catalogs, or one only sent along with
+		// its changed publications.
+	} else if !localAt.After(opts.Since) {
+		take = true
+	} else {
+		take = opts.IncomingWins(localAt, inAt)
+		report.Conflicts = append(report.Conflicts, &bookid.SyncConflict{UID: work.UID, Entity: bookid.AuditEntityWork, Title: work.Title, Incoming: take})
+	}
+
+	if take {
+		old := *work
+		work.Title, work.Author, work.OriginalTitle, work.OriginalLanguage = in.Title, in.Author, in.OriginalTitle, in.OriginalLanguage
+		if err := work.Validate(); err != nil {
+			return err
+		} else if _, err := tx.ExecContext(ctx, `
+			UPDATE works SET title = ?, author = ?, original_title = ?, original_language = ? WHERE id = ?
+		`, work.Title, work.Author, work.OriginalTitle, work.OriginalLanguage, work.ID); err != nil {
+			return FormatError(err)
+		} else if err := audit(ctx, tx, bookid.AuditEntityWork, work.ID, work.ID, bookid.AuditActionUpdate, &old, work); err != nil {
+			return err
+		} else if err := linkSyncAuthors(ctx, tx, work.ID, rec.Authors); err != nil {
+			return err
+		}
+		report.Updated++
+	}
+
+	if err := applySyncPublications(ctx, tx, work.ID, rec.Publications, opts, report); err != nil {
+		return err
+	} else if take {
+		return setSyncTimes(ctx, tx, "works", work.ID, work.CreatedAt, in.UpdatedAt, in.DeletedAt)
+	}
+	return nil
+}
+
+// findSyncWork returns the local work of an incoming record: the work with
+// its UID or, failing that, the work of a local publication matching one of
+// its publications, which then takes the lower of the two UIDs so both
+// catalogs agree on it. Returns nil if there is none.
+func findSyncWork(ctx context.Context, tx *Tx, rec *bookid.SyncRecord) (*bookid.Work, error) {
+	if works, _, err := findWorks(ctx, tx, bookid.WorkFilter{UID: &rec.Work.UID, IncludeDeleted: true}); err != nil {
+		return nil, err
+	} else if len(works) > 0 {
+		return works[0], nil
+	}
+
+	for _, in := range rec.Publications {
+		pub, err := findSyncPublication(ctx, tx, in)
+		if err != nil {
+			return nil, err
+		} else if pub == nil {
+			continue
+		}
+		works, _, err := findWorks(ctx, tx, bookid.WorkFilter{ID: &pub.WorkID, IncludeDeleted: true})
+		if err != nil {
+			return nil, err
+		} else if len(works) == 0 {
+			continue
+		}
+		work := works[0]
+		if err := adoptUID(ctx, tx, "works", work.ID, &work.UID, rec.Work.UID); err != nil {
+			return nil, err
+		}
+		return work, nil
+	}
+	return nil, nil
+}
+
+// findSyncPublication returns the local publication of an incoming one, by
+// UID or by ISBN-13, Google Books volume ID or ASIN. Returns nil if there is
+// none.
+func findSyncPublication(ctx context.Context, tx *Tx, in *bookid.Publication) (*bookid.Publication, error) {
+	if pubs, _, err := findPublications(ctx, tx, bookid.PublicationFilter{UID: &in.UID, IncludeDeleted: true}); err != nil {
+		return nil, err
+	} else if len(pubs) > 0 {
+		return pubs[0], nil
+	}
+	return findPublicationByIdentifiers(ctx, tx, isbn.Normalize(in.ISBN13), in.GoogleBooksVolumeID, asin.Normalize(in.ASIN))
+}
+
+// applySyncPublications applies the incoming publications of a work.
+func applySyncPublications(ctx context.Context, tx *Tx, workID int64, pubs []*bookid.Publication, opts bookid.SyncOptions, report *bookid.SyncReport) error {
+	for _, in := range pubs {
+		if in.UID == "" {
+			return bookid.Errorf(bookid.EINVALID, "Sync record publication UID required.")
+		}
+		inAt := changedAt(in.UpdatedAt, in.DeletedAt)
+
+		pub, err := findSyncPublication(ctx, tx, in)
+		if err != nil {
+			return err
+		}
+
+		if pub == nil {
+			if ts, err := findTombstone(ctx, tx, in.UID); err != nil {
+				return err
+			} else if ts != nil {
+				if !inAt.After(opts.Since) {
+					continue // removed since and not changed by the other catalog
+				}
+				incoming := opts.IncomingWins(ts.DeletedAt, inAt)
+				report.Conflicts = append(report.Conflicts, &bookid.SyncConflict{UID: in.UID, Entity: bookid.AuditEntityPublication, Incoming: incoming})
+				if !incoming {
+					continue // stays removed
+				} else if err := deleteTombstone(ctx, tx, in.UID); err != nil {
+					return err
+				}
+			}
+			pub = syncedPublication(in, workID)
+			if err := createPublication(ctx, tx, pub); err != nil {
+				return err
+			} else if err := setSyncTimes(ctx, tx, "publications", pub.ID, in.CreatedAt, in.UpdatedAt, in.DeletedAt); err != nil {
+				return err
+			}
+			report.Created++
+			continue
+		}
+
+		if err := adoptUID(ctx, tx, "publications", pub.ID, &pub.UID, in.UID); err != nil {
+			return err
+		}
+		localAt := changedAt(pub.UpdatedAt, pub.DeletedAt)
+		if localAt.Equal(inAt) || !inAt.After(opts.Since) {
+			continue // the same version in both catalogs, or unchanged since
+		} else if localAt.After(opts.Since) {
+			incoming := opts.IncomingWins(localAt, inAt)
+			report.Conflicts = append(report.Conflicts, &bookid.SyncConflict{UID: pub.UID, Entity: bookid.AuditEntityPublication, Incoming: incoming})
+			if !incoming {
+				continue
+			}
+		}
+		if err := updateSyncPublication(ctx, tx, pub, syncedPublication(in, workID)); err != nil {
+			return err
+		}
+		report.Updated++
+	}
+	return nil
+}
+
+// syncedPublication returns a copy of an incoming publication of a local
+// work, without the fields that only make sense in the catalog it came from.
+func syncedPublication(in *bookid.Publication, workID int64) *bookid.Publication {
+	pub := *in
+	pub.ID, pub.WorkID, pub.PublisherID = 0, workID, 0
+	pub.CoverPath, pub.GoogleBooksData = "", ""
+	return &pub
+}
+
+// updateSyncPublication overwrites the synced fields of a local publication
+// with those of an incoming one, keeping its own cover and raw data.
+func updateSyncPublication(ctx context.Context, tx *Tx, pub, in *bookid.Publication) error {
+	if err := linkPublisher(ctx, tx, in); err != nil {
+		return err
+	}
+	in.ID, in.UID, in.CoverPath, in.GoogleBooksData = pub.ID, pub.UID, pub.CoverPath, pub.GoogleBooksData
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE publications
+		SET work_id = ?,
+			isbn10 = ?,
+			isbn13 = ?,
+			publisher = ?,
+			publisher_id = ?,
+			published_year = ?,
+			language = ?,
+			binding = ?,
+			page_count = ?,
+			duration_minutes = ?,
+			height_mm = ?,
+			width_mm = ?,
+			thickness_mm = ?,
+			weight_g = ?,
+			public_domain = ?,
+			full_view = ?,
+			epub_available = ?,
+			pdf_available = ?,
+			web_reader_url = ?,
+			preview_url = ?,
+			dewey_decimal = ?,
+			lc_classification = ?,
+			google_books_volume_id = ?,
+			oclc_number = ?,
+			lccn = ?,
+			doi = ?,
+			asin = ?,
+			thumbnail_url = ?,
+			description = ?,
+			table_of_contents = ?,
+			updated_at = ?,
+			refreshed_at = ?,
+			deleted_at = ?,
+			reading_status = ?,
+			rating = ?,
+			notes = ?,
+			acquired_at = ?,
+			location = ?,
+			provenance = ?
+		WHERE id = ?
+	`,
+		in.WorkID,
+		in.ISBN10,
+		in.ISBN13,
+		in.Publisher,
+		sql.NullInt64{Int64: in.PublisherID, Valid: in.PublisherID != 0},
+		in.PublishedYear,
+		in.Language,
+		in.Binding,
+		in.PageCount,
+		in.DurationMinutes,
+		in.Dimensions.Height,
+		in.Dimensions.Width,
+		in.Dimensions.Thickness,
+		in.Dimensions.Weight,
+		in.Access.PublicDomain,
+		in.Access.FullView,
+		in.Access.EPUBAvailable,
+		in.Access.PDFAvailable,
+		in.Access.WebReaderURL,
+		in.Access.PreviewURL,
+		in.Classification.Dewey,
+		in.Classification.LCC,
+		in.GoogleBooksVolumeID,
+		in.OCLCNumber,
+		in.LCCN,
+		in.DOI,
+		in.ASIN,
+		in.ThumbnailURL,
+		in.Description,
+		(*StringSlice)(&in.TableOfContents),
+		(*NullTime)(&in.UpdatedAt),
+		(*NullTime)(&in.RefreshedAt),
+		(*NullTime)(&in.DeletedAt),
+		in.ReadingStatus,
+		in.Rating,
+		in.Notes,
+		(*NullTime)(&in.AcquiredAt),
+		in.Location,
+		(*StringMap)(&in.Provenance),
+		pub.ID,
+	); err != nil {
+		return FormatError(err)
+	}
+	return audit(ctx, tx, bookid.AuditEntityPublication, pub.ID, in.WorkID, bookid.AuditActionUpdate, pub, in)
+}
+
+// linkSyncAuthors makes the authors linked to a work in their roles exactly
+// the incoming ones.
+func linkSyncAuthors(ctx context.Context, tx *Tx, workID int64, authors []*bookid.Author) error {
+	type link struct {
+		authorID int64
+		role     bookid.ContributorRole
+	}
+	var want []link
+	for _, a := range authors {
+		author := &bookid.Author{Name: a.Name, VIAFID: a.VIAFID, WikidataID: a.WikidataID}
+		if err := createAuthor(ctx, tx, author); err != nil {
+			return err
+		}
+		role := a.Role
+		if role == "" {
+			role = bookid.ContributorRoleAuthor
+		}
+		want = append(want, link{author.ID, role})
+	}
+
+	existing, _, err := findAuthors(ctx, tx, bookid.AuthorFilter{WorkID: &workID})
+	if err != nil {
+		return err
+	}
+	for _, a := range existing {
+		found := false
+		for _, l := range want {
+			found = found || l == link{a.ID, a.Role}
+		}
+		if !found {
+			if err := removeWorkAuthor(ctx, tx, &bookid.WorkAuthor{WorkID: workID, AuthorID: a.ID}); err != nil {
+				return err
+			}
+		}
+	}
+	for _, l := range want {
+		if err := addWorkAuthor(ctx, tx, &bookid.WorkAuthor{WorkID: workID, AuthorID: l.authorID, Role: l.role}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyTombstone removes the local work or publication of an incoming
+// tombstone, unless it changed since the last sync and wins the conflict.
+func applyTombstone(ctx context.Context, tx *Tx, ts *bookid.Tombstone, opts bookid.SyncOptions, report *bookid.SyncReport) error {
+	var id int64
+	var title string
+	var localAt time.Time
+	var deleted bool
+	switch ts.Entity {
+	case bookid.AuditEntityWork:
+		works, _, err := findWorks(ctx, tx, bookid.WorkFilter{UID: &ts.UID, IncludeDeleted: true})
+		if err != nil {
+			return err
+		} else if len(works) > 0 {
+			id, title, localAt, deleted = works[0].ID, works[0].Title, changedAt(works[0].UpdatedAt, works[0].DeletedAt), !works[0].DeletedAt.IsZero()
+		}
+	case bookid.AuditEntityPublication:
+		pubs, _, err := findPublications(ctx, tx, bookid.PublicationFilter{UID: &ts.UID, IncludeDeleted: true})
+		if err != nil {
+			return err
+		} else if len(pubs) > 0 {
+			id, localAt, deleted = pubs[0].ID, changedAt(pubs[0].UpdatedAt, pubs[0].DeletedAt), !pubs[0].DeletedAt.IsZero()
+		}
+	default:
+		return bookid.Errorf(bookid.EINVALID, "Invalid tombstone entity %q.", ts.Entity)
+	}
+
+	if id != 0 && localAt.After(opts.Since) {
+		incoming := opts.IncomingWins(localAt, ts.DeletedAt)
+		report.Conflicts = append(report.Conflicts, &bookid.SyncConflict{UID: ts.UID, Entity: ts.Entity, Title: title, Incoming: incoming})
+		if !incoming {
+			return nil
+		}
+	}
+
+	if id != 0 {
+		var err error
+		switch {
+		case ts.Entity == bookid.AuditEntityWork && !deleted:
+			err = deleteWork(ctx, tx, id)
+		case ts.Entity == bookid.AuditEntityPublication && !deleted:
+			err = deletePublication(ctx, tx, id)
+		}
+		if err != nil {
+			return err
+		}
+		if ts.Entity == bookid.AuditEntityWork {
+			err = purgeWork(ctx, tx, id)
+		} else {
+			err = purgePublication(ctx, tx, id)
+		}
+		if err != nil {
+			return err
+		}
+		report.Deleted++
+	}
+
+	// Keep the time of the removal, so the tombstone is not sent back.
+	if _, err := tx.ExecContext(ctx, `
+		INSERT OR REPLACE INTO tombstones (uid, entity, deleted_at) VALUES (?, ?, ?)
+	`, ts.UID, ts.Entity, (*NullTime)(&ts.DeletedAt)); err != nil {
+		return FormatError(err)
+	}
+	return nil
+}
+
+// adoptUID gives a local row the lower of its UID and the incoming one.
+func adoptUID(ctx context.Context, tx *Tx, table string, id int64, uid *string, incoming string) error {
+	if incoming >= *uid {
+		return nil
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE `+table+` SET uid = ? WHERE id = ?`, incoming, id); err != nil {
+		return FormatError(err)
+	}
+	*uid = incoming
+	return nil
+}
+
+// setSyncTimes sets the timestamps of a synced row to those of the catalog
+// it came from.
+func setSyncTimes(ctx context.Context, tx *Tx, table string, id int64, createdAt, updatedAt, deletedAt time.Time) error {
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE `+table+` SET created_at = COALESCE(?, created_at), updated_at = COALESCE(?, updated_at), deleted_at = ? WHERE id = ?
+	`, (*NullTime)(&createdAt), (*NullTime)(&updatedAt), (*NullTime)(&deletedAt), id); err != nil {
+		return FormatError(err)
+	}
+	return nil
+}
+
+// changedAt returns the time a row last changed: when it was updated or
+// moved to the trash, whichever is later.
+func changedAt(updatedAt, deletedAt time.Time) time.Time {
+	if deletedAt.After(updatedAt) {
+		return deletedAt
+	}
+	return updatedAt
+}
+
+// newUID returns a random UID identifying a row across synced catalogs.
+func newUID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
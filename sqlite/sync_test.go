@@ -0,0 +1,251 @@
+package sqlite_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/sqlite"
+)
+
+// MustSync exchanges the changes of two databases since a time the way
+// "bookid sync" does, with b winning ties. Fatal on error.
+func MustSync(tb testing.TB, a, b *sqlite.DB, since time.Time, rule bookid.SyncRule) (aReport, bReport *bookid.SyncReport) {
+	tb.Helper()
+	ctx := context.Background()
+	as, bs := sqlite.NewSyncService(a), sqlite.NewSyncService(b)
+
+	aChanges, err := as.FindChanges(ctx, since)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	bChanges, err := bs.FindChanges(ctx, since)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	if bReport, err = bs.ApplyChanges(ctx, aChanges, bookid.SyncOptions{Since: since, Rule: rule}); err != nil {
+		tb.Fatal(err)
+	}
+	if aReport, err = as.ApplyChanges(ctx, bChanges, bookid.SyncOptions{Since: since, Rule: rule, IncomingWinsTies: true}); err != nil {
+		tb.Fatal(err)
+	}
+	return aReport, bReport
+}
+
+// clock returns a function setting the time of the databases.
+func clock(dbs ...*sqlite.DB) func(time.Time) {
+	return func(t time.Time) {
+		for _, db := range dbs {
+			db.Now = func() time.Time { return t }
+		}
+	}
+}
+
+func TestSyncService_Sync(t *testing.T) {
+	t.Parallel()
+
+	t.Run("RoundTrip", func(t *testing.T) {
+		t.Parallel()
+		a, b := MustOpenDB(t), MustOpenDB(t)
+		defer MustCloseDB(t, a)
+		defer MustCloseDB(t, b)
+		ctx := context.Background()
+
+		dune := &bookid.Work{Title: "Dune", Author: "Frank Herbert"}
+		if err := sqlite.NewWorkService(a).CreateWork(ctx, dune); err != nil {
+			t.Fatal(err)
+		}
+		herbert := &bookid.Author{Name: "Frank Herbert"}
+		if err := sqlite.NewAuthorService(a).CreateAuthor(ctx, herbert); err != nil {
+			t.Fatal(err)
+		} else if err := sqlite.NewAuthorService(a).AddWorkAuthor(ctx, &bookid.WorkAuthor{WorkID: dune.ID, AuthorID: herbert.ID}); err != nil {
+			t.Fatal(err)
+		}
+		pub := &bookid.Publication{WorkID: dune.ID, ISBN13: "9780441172719", Notes: "Signed"}
+		if err := sqlite.NewPublicationService(a).CreatePublication(ctx, pub); err != nil {
+			t.Fatal(err)
+		}
+		if err := sqlite.NewWorkService(b).CreateWork(ctx, &bookid.Work{Title: "Solaris"}); err != nil {
+			t.Fatal(err)
+		}
+
+		aReport, bReport := MustSync(t, a, b, time.Time{}, "")
+		if aReport.Created != 1 || bReport.Created != 2 {
+			t.Fatalf("Created=%d,%d, want 1,2", aReport.Created, bReport.Created)
+		}
+
+		for _, db := range []*sqlite.DB{a, b} {
+			if _, n, err := sqlite.NewWorkService(db).FindWorks(ctx, bookid.WorkFilter{}); err != nil {
+				t.Fatal(err)
+			} else if n != 2 {
+				t.Fatalf("n=%d, want 2", n)
+			}
+		}
+		works, _, err := sqlite.NewWorkService(b).FindWorks(ctx, bookid.WorkFilter{UID: &dune.UID})
+		if err != nil {
+			t.Fatal(err)
+		} else if len(works) != 1 || works[0].Title != "Dune" || !works[0].UpdatedAt.Equal(dune.UpdatedAt) {
+			t.Fatalf("works=%v, want Dune with the same UID and time", works)
+		}
+		if authors, _, err := sqlite.NewAuthorService(b).FindAuthors(ctx, bookid.AuthorFilter{WorkID: &works[0].ID}); err != nil {
+			t.Fatal(err)
+		} else if len(authors) != 1 || authors[0].Name != "Frank Herbert" {
+			t.Fatalf("authors=%v, want Frank Herbert", authors)
+		}
+		if pubs, _, err := sqlite.NewPublicationService(b).FindPublications(ctx, bookid.PublicationFilter{UID: &pub.UID}); err != nil {
+			t.Fatal(err)
+		} else if len(pubs) != 1 || pubs[0].ISBN13 != "9780441172719" || pubs[0].Notes != "Signed" {
+			t.Fatalf("pubs=%v, want the signed Dune", pubs)
+		}
+
+		// Syncing again changes nothing.
+		if aReport, bReport := MustSync(t, a, b, time.Time{}, ""); aReport.Created+aReport.Updated+bReport.Created+bReport.Updated != 0 || len(aReport.Conflicts)+len(bReport.Conflicts) != 0 {
+			t.Fatalf("reports=%+v,%+v, want no changes", aReport, bReport)
+		}
+	})
+
+	t.Run("MatchByISBN", func(t *testing.T) {
+		t.Parallel()
+		a, b := MustOpenDB(t), MustOpenDB(t)
+		defer MustCloseDB(t, a)
+		defer MustCloseDB(t, b)
+		ctx := context.Background()
+
+		// The same book saved separately in both catalogs.
+		var uids []string
+		for _, db := range []*sqlite.DB{a, b} {
+			work := &bookid.Work{Title: "Dune"}
+			if err := sqlite.NewWorkService(db).CreateWork(ctx, work); err != nil {
+				t.Fatal(err)
+			} else if err := sqlite.NewPublicationService(db).CreatePublication(ctx, &bookid.Publication{WorkID: work.ID, ISBN13: "9780441172719"}); err != nil {
+				t.Fatal(err)
+			}
+			uids = append(uids, work.UID)
+		}
+		MustSync(t, a, b, time.Time{}, "")
+
+		want := min(uids[0], uids[1])
+		for _, db := range []*sqlite.DB{a, b} {
+			if works, n, err := sqlite.NewWorkService(db).FindWorks(ctx, bookid.WorkFilter{}); err != nil {
+				t.Fatal(err)
+			} else if n != 1 || works[0].UID != want {
+				t.Fatalf("works=%v, want one with UID %s", works, want)
+			}
+		}
+	})
+
+	t.Run("Conflict", func(t *testing.T) {
+		t.Parallel()
+		a, b := MustOpenDB(t), MustOpenDB(t)
+		defer MustCloseDB(t, a)
+		defer MustCloseDB(t, b)
+		ctx := context.Background()
+		set := clock(a, b)
+		t0 := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+		set(t0)
+		work := &bookid.Work{Title: "Dune"}
+		if err := sqlite.NewWorkService(a).CreateWork(ctx, work); err != nil {
+			t.Fatal(err)
+		}
+		set(t0.Add(time.Minute))
+		MustSync(t, a, b, time.Time{}, "")
+		since := t0.Add(time.Minute)
+
+		// Both catalogs rename the work; b does so last.
+		bWorks, _, err := sqlite.NewWorkService(b).FindWorks(ctx, bookid.WorkFilter{UID: &work.UID})
+		if err != nil {
+			t.Fatal(err)
+		}
+		set(t0.Add(2 * time.Minute))
+		if _, err := sqlite.NewWorkService(a).UpdateWork(ctx, work.ID, bookid.WorkUpdate{Title: ptr("Dune (A)")}); err != nil {
+			t.Fatal(err)
+		}
+		set(t0.Add(3 * time.Minute))
+		if _, err := sqlite.NewWorkService(b).UpdateWork(ctx, bWorks[0].ID, bookid.WorkUpdate{Title: ptr("Dune (B)")}); err != nil {
+			t.Fatal(err)
+		}
+
+		aReport, bReport := MustSync(t, a, b, since, bookid.SyncRuleNewer)
+		if len(aReport.Conflicts) != 1 || !aReport.Conflicts[0].Incoming {
+			t.Fatalf("a conflicts=%v, want one won by b", aReport.Conflicts)
+		} else if len(bReport.Conflicts) != 1 || bReport.Conflicts[0].Incoming {
+			t.Fatalf("b conflicts=%v, want one won by b", bReport.Conflicts)
+		}
+		for _, db := range []*sqlite.DB{a, b} {
+			if works, _, err := sqlite.NewWorkService(db).FindWorks(ctx, bookid.WorkFilter{UID: &work.UID}); err != nil {
+				t.Fatal(err)
+			} else if works[0].Title != "Dune (B)" {
+				t.Fatalf("Title=%q, want %q", works[0].Title, "Dune (B)")
+			}
+		}
+	})
+
+	t.Run("Tombstone", func(t *testing.T) {
+		t.Parallel()
+		a, b := MustOpenDB(t), MustOpenDB(t)
+		defer MustCloseDB(t, a)
+		defer MustCloseDB(t, b)
+		ctx := context.Background()
+		set := clock(a, b)
+		t0 := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+		set(t0)
+		work := &bookid.Work{Title: "Dune"}
+		if err := sqlite.NewWorkService(a).CreateWork(ctx, work); err != nil {
+			t.Fatal(err)
+		} else if err := sqlite.NewPublicationService(a).CreatePublication(ctx, &bookid.Publication{WorkID: work.ID, ISBN13: "9780441172719"}); err != nil {
+			t.Fatal(err)
+		}
+		set(t0.Add(time.Minute))
+		MustSync(t, a, b, time.Time{}, "")
+		since := t0.Add(time.Minute)
+
+		set(t0.Add(2 * time.Minute))
+		if err := sqlite.NewWorkService(a).DeleteWork(ctx, work.ID); err != nil {
+			t.Fatal(err)
+		} else if err := sqlite.NewWorkService(a).PurgeWork(ctx, work.ID); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, bReport := MustSync(t, a, b, since, ""); bReport.Deleted != 2 {
+			t.Fatalf("Deleted=%d, want 2", bReport.Deleted)
+		}
+		if _, n, err := sqlite.NewWorkService(b).FindWorks(ctx, bookid.WorkFilter{IncludeDeleted: true}); err != nil {
+			t.Fatal(err)
+		} else if n != 0 {
+			t.Fatalf("n=%d, want 0", n)
+		}
+		if _, n, err := sqlite.NewPublicationService(b).FindPublications(ctx, bookid.PublicationFilter{IncludeDeleted: true}); err != nil {
+			t.Fatal(err)
+		} else if n != 0 {
+			t.Fatalf("n=%d, want 0", n)
+		}
+	})
+}
+
+func TestSyncService_LastSync(t *testing.T) {
+	t.Parallel()
+	db := MustOpenDB(t)
+	defer MustCloseDB(t, db)
+	ctx := context.Background()
+	s := sqlite.NewSyncService(db)
+
+	if got, err := s.FindLastSync(ctx, "/tmp/server.db"); err != nil {
+		t.Fatal(err)
+	} else if !got.IsZero() {
+		t.Fatalf("got %v, want zero", got)
+	}
+	want := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, at := range []time.Time{want.Add(-time.Hour), want} {
+		if err := s.SetLastSync(ctx, "/tmp/server.db", at); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got, err := s.FindLastSync(ctx, "/tmp/server.db"); err != nil {
+		t.Fatal(err)
+	} else if !got.Equal(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
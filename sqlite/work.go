@@ -0,0 +1,497 @@
+package sqlite
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/language"
+	"github.com/fwojciec/bookid/subject"
+)
+
+// Ensure service implements interface.
+var _ bookid.WorkService = (*WorkService)(nil)
+
+// WorkService represents a service for managing works.
+type WorkService struct {
+	db *DB
+}
+
+// NewWorkService returns a new instance of WorkService.
+func NewWorkService(db *DB) *WorkService {
+	return &WorkService{db: db}
+}
+
+// FindWorkByID retrieves a single work by ID.
+// Returns ENOTFOUND if the work does not exist.
+func (s *WorkService) FindWorkByID(ctx context.Context, id int64) (*bookid.Work, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+	return findWorkByID(ctx, tx, id)
+}
+
+// FindWorks retrieves a list of works matching the filter.
+func (s *WorkService) FindWorks(ctx context.Context, filter bookid.WorkFilter) ([]*bookid.Work, int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer func() { _ = tx.Rollback() }()
+	return findWorks(ctx, tx, filter)
+}
+
+// CreateWork creates a new work.
+func (s *WorkService) CreateWork(ctx context.Context, work *bookid.Work) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := createWork(ctx, tx, work); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// UpdateWork updates an existing work.
+// Returns ENOTFOUND if the work does not exist.
+func (s *WorkService) UpdateWork(ctx context.Context, id int64, upd bookid.WorkUpdate) (*bookid.Work, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	work, err := updateWork(ctx, tx, id, upd)
+	if err != nil {
+		return work, err
+	} else if err := tx.Commit(); err != nil {
+		return work, err
+	}
+	return work, nil
+}
+
+// DeleteWork moves a work along with its publications to the trash.
+// Returns ENOTFOUND if the work does not exist or is already deleted.
+func (s *WorkService) DeleteWork(ctx context.Context, id int64) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := deleteWork(ctx, tx, id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// RestoreWork restores a deleted work along with the publications deleted
+// with it. Returns ENOTFOUND if the work is not in the trash.
+func (s *WorkService) RestoreWork(ctx context.Context, id int64) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := restoreWork(ctx, tx, id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// PurgeWork permanently removes a deleted work along with its publications.
+// Returns ENOTFOUND if the work is not in the trash.
+func (s *WorkService) PurgeWork(ctx context.Context, id int64) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := purgeWork(ctx, tx, id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// MergeWorks merges the source works into the target.
+func (s *WorkService) MergeWorks(ctx context.Context, targetID int64, sourceIDs ...int64) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := mergeWorks(ctx, tx, targetID, sourceIDs); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// findWorkByID is a helper function to fetch a work by ID.
+// Returns ENOTFOUND if the work does not exist.
+func findWorkByID(ctx context.Context, tx *Tx, id int64) (*bookid.Work, error) {
+	works, _, err := findWorks(ctx, tx, bookid.WorkFilter{ID: &id})
+	if err != nil {
+		return nil, err
+	} else if len(works) == 0 {
+		return nil, bookid.Errorf(bookid.ENOTFOUND, "Work not found.")
+	}
+	return works[0], nil
+}
+
+// findDeletedWorkByID is a helper function to fetch a work in the trash by ID.
+// Returns ENOTFOUND if the work is not in the trash.
+func findDeletedWorkByID(ctx context.Context, tx *Tx, id int64) (*bookid.Work, error) {
+	works, _, err := findWorks(ctx, tx, bookid.WorkFilter{ID: &id, OnlyDeleted: true})
+	if err != nil {
+		return nil, err
+	} else if len(works) == 0 {
+		return nil, bookid.Errorf(bookid.ENOTFOUND, "Work not found in trash.")
+	}
+	return works[0], nil
+}
+
+// findWorks returns a list of works matching a filter. Also returns a count of
+// total matching works which may differ if filter.Limit is set.
+func findWorks(ctx context.Context, tx *Tx, filter bookid.WorkFilter) (_ []*bookid.Work, n int, err error) {
+	from, orderBy := "works", "id ASC"
+	where, args := []string{"1 = 1"}, []any{}
+	if v := filter.Series; v != nil {
+		// Joined first, as its argument precedes those of the conditions.
+		from = `works JOIN (
+			SELECT sw.work_id, sw.volume FROM series_works sw JOIN series s ON s.id = sw.series_id
+			WHERE s.name = ? COLLATE NOCASE
+		) sw ON sw.work_id = id`
+		orderBy = "sw.volume = 0, sw.volume, id ASC"
+		args = append(args, *v)
+	}
+	if v := filter.ID; v != nil {
+		where, args = append(where, "id = ?"), append(args, *v)
+	}
+	if v := filter.UID; v != nil {
+		where, args = append(where, "uid = ?"), append(args, *v)
+	}
+	if v := filter.Title; v != nil {
+		where, args = append(where, "title = ? COLLATE NOCASE"), append(args, *v)
+	}
+	if v := filter.Author; v != nil {
+		where, args = append(where, "author = ? COLLATE NOCASE"), append(args, *v)
+	}
+	if v := filter.Query; v != nil {
+		where, args = append(where, "(title LIKE ? ESCAPE '\\' OR author LIKE ? ESCAPE '\\')"), append(args, likePattern(*v), likePattern(*v))
+	}
+	if v := filter.Language; v != nil {
+		where = append(where, "id IN (SELECT work_id FROM publications WHERE deleted_at IS NULL AND "+languageCondition("language")+")")
+		args = append(args, languageArgs(*v)...)
+	}
+	if v := filter.Binding; v != nil {
+		where, args = append(where, "id IN (SELECT work_id FROM publications WHERE deleted_at IS NULL AND binding = ?)"), append(args, *v)
+	}
+	if v := filter.Subject; v != nil {
+		where = append(where, "id IN (SELECT ws.work_id FROM work_subjects ws JOIN subjects s ON s.id = ws.subject_id WHERE s.name = ?)")
+		args = append(args, subject.Name(*v))
+	}
+	if v := filter.CollectionID; v != nil {
+		where, args = append(where, "id IN (SELECT work_id FROM collection_works WHERE collection_id = ?)"), append(args, *v)
+	}
+	if filter.OnlyDeleted {
+		where = append(where, "deleted_at IS NOT NULL")
+	} else if !filter.IncludeDeleted {
+		where = append(where, "deleted_at IS NULL")
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id, uid, title, author, original_title, original_language, created_at, updated_at, deleted_at, COUNT(*) OVER ()
+		FROM `+from+`
+		WHERE `+strings.Join(where, " AND ")+`
+		ORDER BY `+orderBy+`
+		`+FormatLimitOffset(filter.Limit, filter.Offset),
+		args...,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	works := make([]*bookid.Work, 0)
+	for rows.Next() {
+		var work bookid.Work
+		if err := rows.Scan(
+			&work.ID,
+			&work.UID,
+			&work.Title,
+			&work.Author,
+			&work.OriginalTitle,
+			&work.OriginalLanguage,
+			(*NullTime)(&work.CreatedAt),
+			(*NullTime)(&work.UpdatedAt),
+			(*NullTime)(&work.DeletedAt),
+			&n,
+		); err != nil {
+			return nil, 0, err
+		}
+		works = append(works, &work)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+	return works, n, nil
+}
+
+// createWork creates a new work. Sets the ID, the UID unless one is given and
+// timestamps on success.
+func createWork(ctx context.Context, tx *Tx, work *bookid.Work) error {
+	// Set timestamps to the current time.
+	work.CreatedAt = tx.now
+	work.UpdatedAt = work.CreatedAt
+	work.OriginalLanguage = language.Normalize(work.OriginalLanguage)
+	if work.UID == "" {
+		work.UID = newUID()
+	}
+
+	if err := work.Validate(); err != nil {
+		return err
+	}
+
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO works (uid, title, author, original_title, original_language, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`,
+		work.UID,
+		work.Title,
+		work.Author,
+		work.OriginalTitle,
+		work.OriginalLanguage,
+		(*NullTime)(&work.CreatedAt),
+		(*NullTime)(&work.UpdatedAt),
+	)
+	if err != nil {
+		return FormatError(err)
+	}
+
+	if work.ID, err = result.LastInsertId(); err != nil {
+		return err
+	}
+
+	if err := audit(ctx, tx, bookid.AuditEntityWork, work.ID, work.ID, bookid.AuditActionCreate, nil, work); err != nil {
+		return err
+	}
+
+	other := *work
+	tx.publish(bookid.EventTypeWorkCreated, &bookid.WorkCreatedPayload{Work: &other})
+	return nil
+}
+
+// updateWork updates fields on a work by ID. Returns the updated work.
+func updateWork(ctx context.Context, tx *Tx, id int64, upd bookid.WorkUpdate) (*bookid.Work, error) {
+	work, err := findWorkByID(ctx, tx, id)
+	if err != nil {
+		return work, err
+	}
+	old := *work
+
+	if v := upd.Title; v != nil {
+		work.Title = *v
+	}
+	if v := upd.Author; v != nil {
+		work.Author = *v
+	}
+	if v := upd.OriginalTitle; v != nil {
+		work.OriginalTitle = *v
+	}
+	if v := upd.OriginalLanguage; v != nil {
+		work.OriginalLanguage = language.Normalize(*v)
+	}
+	work.UpdatedAt = tx.now
+
+	if err := work.Validate(); err != nil {
+		return work, err
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE works
+		SET title = ?, author = ?, original_title = ?, original_language = ?, updated_at = ?
+		WHERE id = ?
+	`,
+		work.Title,
+		work.Author,
+		work.OriginalTitle,
+		work.OriginalLanguage,
+		(*NullTime)(&work.UpdatedAt),
+		id,
+	); err != nil {
+		return work, FormatError(err)
+	}
+	return work, audit(ctx, tx, bookid.AuditEntityWork, id, id, bookid.AuditActionUpdate, &old, work)
+}
+
+// deleteWork moves a work by ID to the trash. Its publications are deleted
+// at the same time, which tells them apart from those deleted before.
+func deleteWork(ctx context.Context, tx *Tx, id int64) error {
+	work, err := findWorkByID(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE works SET deleted_at = ? WHERE id = ?
+	`, (*NullTime)(&tx.now), id); err != nil {
+		return FormatError(err)
+	}
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE publications SET deleted_at = ? WHERE work_id = ? AND deleted_at IS NULL
+	`, (*NullTime)(&tx.now), id); err != nil {
+		return FormatError(err)
+	}
+	return audit(ctx, tx, bookid.AuditEntityWork, id, id, bookid.AuditActionDelete, work, nil)
+}
+
+// restoreWork restores a work by ID from the trash along with the
+// publications deleted with it. Restoring counts as a change, so it is
+// synced.
+func restoreWork(ctx context.Context, tx *Tx, id int64) error {
+	work, err := findDeletedWorkByID(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+	old := *work
+	work.DeletedAt, work.UpdatedAt = time.Time{}, tx.now
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE publications SET deleted_at = NULL, updated_at = ? WHERE work_id = ? AND deleted_at = ?
+	`, (*NullTime)(&tx.now), id, (*NullTime)(&old.DeletedAt)); err != nil {
+		return FormatError(err)
+	}
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE works SET deleted_at = NULL, updated_at = ? WHERE id = ?
+	`, (*NullTime)(&tx.now), id); err != nil {
+		return FormatError(err)
+	}
+	return audit(ctx, tx, bookid.AuditEntityWork, id, id, bookid.AuditActionRestore, &old, work)
+}
+
+// purgeWork permanently removes a work by ID from the trash. Its
+// publications cascade away with it, leaving tombstones like the work.
+func purgeWork(ctx context.Context, tx *Tx, id int64) error {
+	work, err := findDeletedWorkByID(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+	if err := insertTombstones(ctx, tx, bookid.AuditEntityPublication, `SELECT uid FROM publications WHERE work_id = ?`, id); err != nil {
+		return err
+	} else if err := insertTombstones(ctx, tx, bookid.AuditEntityWork, `SELECT uid FROM works WHERE id = ?`, id); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM works WHERE id = ?`, id); err != nil {
+		return FormatError(err)
+	}
+	return audit(ctx, tx, bookid.AuditEntityWork, id, id, bookid.AuditActionPurge, work, nil)
+}
+
+// mergeWorks moves the publications, author links and relations of each
+// source work to the target and deletes the sources.
+func mergeWorks(ctx context.Context, tx *Tx, targetID int64, sourceIDs []int64) error {
+	if len(sourceIDs) == 0 {
+		return bookid.Errorf(bookid.EINVALID, "Works to merge required.")
+	} else if slices.Contains(sourceIDs, targetID) {
+		return bookid.Errorf(bookid.EINVALID, "A work cannot be merged into itself.")
+	}
+	sourceIDs = slices.Compact(slices.Sorted(slices.Values(sourceIDs)))
+
+	target, err := findWorkByID(ctx, tx, targetID)
+	if err != nil {
+		return err
+	}
+	old := *target
+
+	for _, id := range sourceIDs {
+		source, err := findWorkByID(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		// Keep the source's credit if the target has none.
+		if target.Author == "" {
+			target.Author = source.Author
+		}
+
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE publications SET work_id = ?, updated_at = ? WHERE work_id = ?
+		`, targetID, (*NullTime)(&tx.now), id); err != nil {
+			return FormatError(err)
+		}
+		if _, err := tx.ExecContext(ctx, `
+			INSERT OR IGNORE INTO work_authors (work_id, author_id)
+			SELECT ?, author_id FROM work_authors WHERE work_id = ?
+		`, targetID, id); err != nil {
+			return FormatError(err)
+		}
+
+		// Relations the target already has are left to cascade away with the
+		// source, and relations between the merged works are dropped.
+		for _, query := range []string{
+			`UPDATE OR IGNORE work_relations SET work_id = ? WHERE work_id = ?`,
+			`UPDATE OR IGNORE work_relations SET related_work_id = ? WHERE related_work_id = ?`,
+		} {
+			if _, err := tx.ExecContext(ctx, query, targetID, id); err != nil {
+				return FormatError(err)
+			}
+		}
+		if _, err := tx.ExecContext(ctx, `DELETE FROM work_relations WHERE work_id = related_work_id`); err != nil {
+			return FormatError(err)
+		}
+		for _, query := range []string{
+			`UPDATE OR IGNORE series_works SET work_id = ? WHERE work_id = ?`,
+			`UPDATE OR IGNORE work_subjects SET work_id = ? WHERE work_id = ?`,
+			`UPDATE OR IGNORE collection_works SET work_id = ? WHERE work_id = ?`,
+		} {
+			if _, err := tx.ExecContext(ctx, query, targetID, id); err != nil {
+				return FormatError(err)
+			}
+		}
+
+		if err := insertTombstones(ctx, tx, bookid.AuditEntityWork, `SELECT uid FROM works WHERE id = ?`, id); err != nil {
+			return err
+		} else if _, err := tx.ExecContext(ctx, `DELETE FROM works WHERE id = ?`, id); err != nil {
+			return FormatError(err)
+		}
+
+		// The source's history continues in the target's.
+		if err := insertAuditEntry(ctx, tx, &bookid.AuditEntry{
+			EntityType: bookid.AuditEntityWork,
+			EntityID:   id,
+			WorkID:     targetID,
+			Action:     bookid.AuditActionMerge,
+			Diff:       map[string]bookid.AuditChange{"merged_into": {New: targetID}},
+		}); err != nil {
+			return err
+		}
+	}
+
+	target.UpdatedAt = tx.now
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE works SET author = ?, updated_at = ? WHERE id = ?
+	`, target.Author, (*NullTime)(&target.UpdatedAt), targetID); err != nil {
+		return FormatError(err)
+	} else if err := audit(ctx, tx, bookid.AuditEntityWork, targetID, targetID, bookid.AuditActionUpdate, &old, target); err != nil {
+		return err
+	}
+
+	tx.publish(bookid.EventTypeWorkMerged, &bookid.WorkMergedPayload{TargetID: targetID, SourceIDs: sourceIDs})
+	return nil
+}
+
+// likePattern returns a LIKE pattern matching any value containing s, with
+// LIKE wildcards in s escaped.
+func likePattern(s string) string {
+	s = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+	return "%" + s + "%"
+}
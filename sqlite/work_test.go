@@ -0,0 +1,495 @@
+package sqlite_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/sqlite"
+)
+
+func TestWorkService_CreateWork(t *testing.T) {
+	t.Parallel()
+
+	t.Run("OK", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewWorkService(db)
+		ctx := context.Background()
+
+		work := &bookid.Work{Title: "The Great Gatsby", Author: "F. Scott Fitzgerald"}
+		if err := s.CreateWork(ctx, work); err != nil {
+			t.Fatal(err)
+		} else if got, want := work.ID, int64(1); got != want {
+			t.Fatalf("ID=%d, want %d", got, want)
+		} else if work.CreatedAt.IsZero() {
+			t.Fatal("expected created at")
+		} else if work.UpdatedAt.IsZero() {
+			t.Fatal("expected updated at")
+		}
+
+		if other, err := s.FindWorkByID(ctx, work.ID); err != nil {
+			t.Fatal(err)
+		} else if !reflect.DeepEqual(work, other) {
+			t.Fatalf("mismatch: %#v != %#v", work, other)
+		}
+	})
+
+	t.Run("ErrTitleRequired", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewWorkService(db)
+
+		err := s.CreateWork(context.Background(), &bookid.Work{Author: "Anonymous"})
+		if code := bookid.ErrorCode(err); code != bookid.EINVALID {
+			t.Fatalf("ErrorCode()=%q, want %q", code, bookid.EINVALID)
+		}
+	})
+}
+
+func TestWorkService_FindWorkByID(t *testing.T) {
+	t.Parallel()
+
+	t.Run("ErrNotFound", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewWorkService(db)
+
+		if _, err := s.FindWorkByID(context.Background(), 1); bookid.ErrorCode(err) != bookid.ENOTFOUND {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+}
+
+func TestWorkService_FindWorks(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Filters", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewWorkService(db)
+		ctx := context.Background()
+
+		MustCreateWork(t, ctx, db, &bookid.Work{Title: "Dune", Author: "Frank Herbert"})
+		MustCreateWork(t, ctx, db, &bookid.Work{Title: "Dune Messiah", Author: "Frank Herbert"})
+		MustCreateWork(t, ctx, db, &bookid.Work{Title: "Solaris", Author: "Stanisław Lem"})
+		MustCreateWork(t, ctx, db, &bookid.Work{Title: "100% Pure", Author: "Anonymous"})
+
+		title := "dune"
+		if works, n, err := s.FindWorks(ctx, bookid.WorkFilter{Title: &title}); err != nil {
+			t.Fatal(err)
+		} else if got, want := n, 1; got != want {
+			t.Fatalf("n=%d, want %d", got, want)
+		} else if got, want := works[0].Title, "Dune"; got != want {
+			t.Fatalf("Title=%q, want %q", got, want)
+		}
+
+		author := "FRANK HERBERT"
+		if _, n, err := s.FindWorks(ctx, bookid.WorkFilter{Author: &author}); err != nil {
+			t.Fatal(err)
+		} else if got, want := n, 2; got != want {
+			t.Fatalf("n=%d, want %d", got, want)
+		}
+
+		query := "lem"
+		if works, n, err := s.FindWorks(ctx, bookid.WorkFilter{Query: &query}); err != nil {
+			t.Fatal(err)
+		} else if got, want := n, 1; got != want {
+			t.Fatalf("n=%d, want %d", got, want)
+		} else if got, want := works[0].Title, "Solaris"; got != want {
+			t.Fatalf("Title=%q, want %q", got, want)
+		}
+
+		// LIKE wildcards in the query are matched literally.
+		query = "%"
+		if _, n, err := s.FindWorks(ctx, bookid.WorkFilter{Query: &query}); err != nil {
+			t.Fatal(err)
+		} else if got, want := n, 1; got != want {
+			t.Fatalf("n=%d, want %d", got, want)
+		}
+	})
+
+	t.Run("Series", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewWorkService(db)
+		ctx := context.Background()
+
+		series := MustCreateSeries(t, ctx, db, &bookid.Series{Name: "Dune"})
+		for _, tt := range []struct {
+			title  string
+			volume float64
+		}{
+			{"Children of Dune", 3},
+			{"Dune", 1},
+			{"The Road to Dune", 0},
+			{"Dune Messiah", 2},
+		} {
+			work := MustCreateWork(t, ctx, db, &bookid.Work{Title: tt.title})
+			if err := sqlite.NewSeriesService(db).AddSeriesWork(ctx, &bookid.SeriesWork{SeriesID: series.ID, WorkID: work.ID, Volume: tt.volume}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		MustCreateWork(t, ctx, db, &bookid.Work{Title: "Solaris"})
+
+		name := "DUNE"
+		works, n, err := s.FindWorks(ctx, bookid.WorkFilter{Series: &name})
+		if err != nil {
+			t.Fatal(err)
+		} else if got, want := n, 4; got != want {
+			t.Fatalf("n=%d, want %d", got, want)
+		}
+		for i, want := range []string{"Dune", "Dune Messiah", "Children of Dune", "The Road to Dune"} {
+			if got := works[i].Title; got != want {
+				t.Fatalf("works[%d].Title=%q, want %q", i, got, want)
+			}
+		}
+	})
+
+	t.Run("Subject", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewWorkService(db)
+		ctx := context.Background()
+
+		solaris := MustCreateWork(t, ctx, db, &bookid.Work{Title: "Solaris"})
+		MustCreateWork(t, ctx, db, &bookid.Work{Title: "Emma"})
+		subject := MustCreateSubject(t, ctx, db, &bookid.Subject{Name: "science fiction"})
+		if err := sqlite.NewSubjectService(db).AddWorkSubject(ctx, &bookid.WorkSubject{WorkID: solaris.ID, SubjectID: subject.ID}); err != nil {
+			t.Fatal(err)
+		}
+
+		name := "Sci-Fi"
+		if works, n, err := s.FindWorks(ctx, bookid.WorkFilter{Subject: &name}); err != nil {
+			t.Fatal(err)
+		} else if got, want := n, 1; got != want {
+			t.Fatalf("n=%d, want %d", got, want)
+		} else if got, want := works[0].Title, "Solaris"; got != want {
+			t.Fatalf("Title=%q, want %q", got, want)
+		}
+	})
+
+	t.Run("LimitOffset", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewWorkService(db)
+		ctx := context.Background()
+
+		MustCreateWork(t, ctx, db, &bookid.Work{Title: "A"})
+		MustCreateWork(t, ctx, db, &bookid.Work{Title: "B"})
+		MustCreateWork(t, ctx, db, &bookid.Work{Title: "C"})
+
+		if works, n, err := s.FindWorks(ctx, bookid.WorkFilter{Limit: 1, Offset: 1}); err != nil {
+			t.Fatal(err)
+		} else if got, want := len(works), 1; got != want {
+			t.Fatalf("len=%d, want %d", got, want)
+		} else if got, want := works[0].Title, "B"; got != want {
+			t.Fatalf("Title=%q, want %q", got, want)
+		} else if got, want := n, 3; got != want {
+			t.Fatalf("n=%d, want %d", got, want)
+		}
+	})
+}
+
+func TestWorkService_UpdateWork(t *testing.T) {
+	t.Parallel()
+
+	t.Run("OK", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewWorkService(db)
+		ctx := context.Background()
+
+		work := MustCreateWork(t, ctx, db, &bookid.Work{Title: "Dune", Author: "Herbert"})
+
+		title, author := "Dune Messiah", "Frank Herbert"
+		updated, err := s.UpdateWork(ctx, work.ID, bookid.WorkUpdate{Title: &title, Author: &author})
+		if err != nil {
+			t.Fatal(err)
+		} else if got, want := updated.Title, title; got != want {
+			t.Fatalf("Title=%q, want %q", got, want)
+		}
+
+		if other, err := s.FindWorkByID(ctx, work.ID); err != nil {
+			t.Fatal(err)
+		} else if !reflect.DeepEqual(updated, other) {
+			t.Fatalf("mismatch: %#v != %#v", updated, other)
+		}
+	})
+
+	t.Run("ErrNotFound", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewWorkService(db)
+
+		title := "Dune"
+		if _, err := s.UpdateWork(context.Background(), 1, bookid.WorkUpdate{Title: &title}); bookid.ErrorCode(err) != bookid.ENOTFOUND {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+
+	t.Run("ErrInvalid", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewWorkService(db)
+		ctx := context.Background()
+
+		work := MustCreateWork(t, ctx, db, &bookid.Work{Title: "Dune"})
+		title := ""
+		if _, err := s.UpdateWork(ctx, work.ID, bookid.WorkUpdate{Title: &title}); bookid.ErrorCode(err) != bookid.EINVALID {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+}
+
+func TestWorkService_DeleteWork(t *testing.T) {
+	t.Parallel()
+
+	t.Run("OK", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewWorkService(db)
+		ctx := context.Background()
+
+		work := MustCreateWork(t, ctx, db, &bookid.Work{Title: "Dune"})
+		MustCreatePublication(t, ctx, db, &bookid.Publication{WorkID: work.ID, Language: "en", PublishedYear: 1965})
+
+		if err := s.DeleteWork(ctx, work.ID); err != nil {
+			t.Fatal(err)
+		} else if _, err := s.FindWorkByID(ctx, work.ID); bookid.ErrorCode(err) != bookid.ENOTFOUND {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+
+	t.Run("ErrNotFound", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewWorkService(db)
+
+		if err := s.DeleteWork(context.Background(), 1); bookid.ErrorCode(err) != bookid.ENOTFOUND {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+}
+
+func TestWorkService_RestoreWork(t *testing.T) {
+	t.Parallel()
+
+	t.Run("OK", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewWorkService(db)
+		pubs := sqlite.NewPublicationService(db)
+		ctx := context.Background()
+
+		work := MustCreateWork(t, ctx, db, &bookid.Work{Title: "Dune"})
+		kept := MustCreatePublication(t, ctx, db, &bookid.Publication{WorkID: work.ID, ISBN13: "9780441172719"})
+		deleted := MustCreatePublication(t, ctx, db, &bookid.Publication{WorkID: work.ID, ISBN13: "9780441013593"})
+
+		// The publication deleted on its own stays in the trash.
+		if err := pubs.DeletePublication(ctx, deleted.ID); err != nil {
+			t.Fatal(err)
+		}
+		db.Now = func() time.Time { return time.Now().Add(time.Hour) }
+		if err := s.DeleteWork(ctx, work.ID); err != nil {
+			t.Fatal(err)
+		}
+
+		if works, _, err := s.FindWorks(ctx, bookid.WorkFilter{OnlyDeleted: true}); err != nil {
+			t.Fatal(err)
+		} else if len(works) != 1 || works[0].ID != work.ID || works[0].DeletedAt.IsZero() {
+			t.Fatalf("unexpected works: %#v", works)
+		} else if _, err := pubs.FindPublicationByID(ctx, kept.ID); bookid.ErrorCode(err) != bookid.ENOTFOUND {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		if err := s.RestoreWork(ctx, work.ID); err != nil {
+			t.Fatal(err)
+		} else if other, err := s.FindWorkByID(ctx, work.ID); err != nil {
+			t.Fatal(err)
+		} else if !other.DeletedAt.IsZero() {
+			t.Fatalf("DeletedAt=%v, want zero", other.DeletedAt)
+		}
+
+		if other, _, err := pubs.FindPublications(ctx, bookid.PublicationFilter{WorkID: &work.ID}); err != nil {
+			t.Fatal(err)
+		} else if len(other) != 1 || other[0].ID != kept.ID {
+			t.Fatalf("unexpected publications: %#v", other)
+		}
+	})
+
+	t.Run("ErrNotFound", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewWorkService(db)
+		ctx := context.Background()
+
+		work := MustCreateWork(t, ctx, db, &bookid.Work{Title: "Dune"})
+		if err := s.RestoreWork(ctx, work.ID); bookid.ErrorCode(err) != bookid.ENOTFOUND {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+}
+
+func TestWorkService_PurgeWork(t *testing.T) {
+	t.Parallel()
+
+	t.Run("OK", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewWorkService(db)
+		ctx := context.Background()
+
+		work := MustCreateWork(t, ctx, db, &bookid.Work{Title: "Dune"})
+		pub := MustCreatePublication(t, ctx, db, &bookid.Publication{WorkID: work.ID})
+
+		if err := s.PurgeWork(ctx, work.ID); bookid.ErrorCode(err) != bookid.ENOTFOUND {
+			t.Fatalf("unexpected error: %#v", err)
+		} else if err := s.DeleteWork(ctx, work.ID); err != nil {
+			t.Fatal(err)
+		} else if err := s.PurgeWork(ctx, work.ID); err != nil {
+			t.Fatal(err)
+		}
+
+		if works, _, err := s.FindWorks(ctx, bookid.WorkFilter{IncludeDeleted: true}); err != nil {
+			t.Fatal(err)
+		} else if len(works) != 0 {
+			t.Fatalf("unexpected works: %#v", works)
+		} else if pubs, _, err := sqlite.NewPublicationService(db).FindPublications(ctx, bookid.PublicationFilter{ID: &pub.ID, IncludeDeleted: true}); err != nil {
+			t.Fatal(err)
+		} else if len(pubs) != 0 {
+			t.Fatalf("unexpected publications: %#v", pubs)
+		}
+	})
+}
+
+func TestWorkService_MergeWorks(t *testing.T) {
+	t.Parallel()
+
+	t.Run("OK", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewWorkService(db)
+		authors := sqlite.NewAuthorService(db)
+		pubs := sqlite.NewPublicationService(db)
+		rels := sqlite.NewWorkRelationService(db)
+		ctx := context.Background()
+
+		target := MustCreateWork(t, ctx, db, &bookid.Work{Title: "Dune"})
+		source := MustCreateWork(t, ctx, db, &bookid.Work{Title: "Dune", Author: "Frank Herbert"})
+		other := MustCreateWork(t, ctx, db, &bookid.Work{Title: "Diuna"})
+		pub := MustCreatePublication(t, ctx, db, &bookid.Publication{WorkID: source.ID, ISBN13: "9780441013593"})
+
+		herbert := &bookid.Author{Name: "Frank Herbert"}
+		if err := authors.CreateAuthor(ctx, herbert); err != nil {
+			t.Fatal(err)
+		}
+		for _, workID := range []int64{target.ID, source.ID} {
+			if err := authors.AddWorkAuthor(ctx, &bookid.WorkAuthor{WorkID: workID, AuthorID: herbert.ID}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		for _, rel := range []*bookid.WorkRelation{
+			{WorkID: other.ID, RelatedWorkID: source.ID, Type: bookid.WorkRelationTranslationOf},
+			{WorkID: source.ID, RelatedWorkID: target.ID, Type: bookid.WorkRelationAbridgementOf},
+		} {
+			if err := rels.CreateWorkRelation(ctx, rel); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		if err := s.MergeWorks(ctx, target.ID, source.ID); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := s.FindWorkByID(ctx, source.ID); bookid.ErrorCode(err) != bookid.ENOTFOUND {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+		if work, err := s.FindWorkByID(ctx, target.ID); err != nil {
+			t.Fatal(err)
+		} else if got, want := work.Author, "Frank Herbert"; got != want {
+			t.Fatalf("Author=%q, want %q", got, want)
+		}
+		if other, err := pubs.FindPublicationByID(ctx, pub.ID); err != nil {
+			t.Fatal(err)
+		} else if got, want := other.WorkID, target.ID; got != want {
+			t.Fatalf("WorkID=%d, want %d", got, want)
+		}
+		if a, _, err := authors.FindAuthors(ctx, bookid.AuthorFilter{WorkID: &target.ID}); err != nil {
+			t.Fatal(err)
+		} else if len(a) != 1 {
+			t.Fatalf("len=%d, want 1", len(a))
+		}
+
+		// The translation now points at the target; the relation between the
+		// merged works is gone.
+		if r, _, err := rels.FindWorkRelations(ctx, bookid.WorkRelationFilter{}); err != nil {
+			t.Fatal(err)
+		} else if len(r) != 1 {
+			t.Fatalf("len=%d, want 1", len(r))
+		} else if r[0].WorkID != other.ID || r[0].RelatedWorkID != target.ID {
+			t.Fatalf("relation=%d->%d, want %d->%d", r[0].WorkID, r[0].RelatedWorkID, other.ID, target.ID)
+		}
+	})
+
+	t.Run("ErrNotFound", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewWorkService(db)
+		ctx := context.Background()
+
+		target := MustCreateWork(t, ctx, db, &bookid.Work{Title: "Dune"})
+		source := MustCreateWork(t, ctx, db, &bookid.Work{Title: "Dune"})
+		if err := s.MergeWorks(ctx, target.ID, source.ID, 100); bookid.ErrorCode(err) != bookid.ENOTFOUND {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+
+		// Nothing is merged if any work is missing.
+		if _, err := s.FindWorkByID(ctx, source.ID); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("ErrInvalid", func(t *testing.T) {
+		t.Parallel()
+		db := MustOpenDB(t)
+		defer MustCloseDB(t, db)
+		s := sqlite.NewWorkService(db)
+		ctx := context.Background()
+
+		work := MustCreateWork(t, ctx, db, &bookid.Work{Title: "Dune"})
+		if err := s.MergeWorks(ctx, work.ID); bookid.ErrorCode(err) != bookid.EINVALID {
+			t.Fatalf("unexpected error: %#v", err)
+		} else if err := s.MergeWorks(ctx, work.ID, work.ID); bookid.ErrorCode(err) != bookid.EINVALID {
+			t.Fatalf("unexpected error: %#v", err)
+		}
+	})
+}
+
+// MustCreateWork creates a work in the database. Fatal on error.
+func MustCreateWork(tb testing.TB, ctx context.Context, db *sqlite.DB, work *bookid.Work) *bookid.Work {
+	tb.Helper()
+	if err := sqlite.NewWorkService(db).CreateWork(ctx, work); err != nil {
+		tb.Fatal(err)
+	}
+	return work
+}
@@ -0,0 +1,289 @@
+// Package sru implements the BookFinder interface over the Search/Retrieve
+// via URL (SRU) protocol. Queries are sent as CQL and records are requested
+// as MARCXML, which lets bookid search the catalogs of national libraries
+// such as the Deutsche Nationalbibliothek or the Library of Congress through
+// a configurable endpoint.
+package sru
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/internal/httpclient"
+	"github.com/fwojciec/bookid/isbn"
+	"github.com/fwojciec/bookid/marc"
+	bookidquery "github.com/fwojciec/bookid/query"
+	"github.com/fwojciec/bookid/scoring"
+)
+
+// ProviderName identifies results produced by this package.
+const ProviderName = "sru"
+
+// Well-known SRU endpoints serving MARC21 records.
+const (
+	// DNBEndpoint is the catalog of the Deutsche Nationalbibliothek. It
+	// requires RecordSchema to be set to "MARC21-xml".
+	DNBEndpoint = "https://services.dnb.de/sru/dnb"
+
+	// LCEndpoint is the catalog of the Library of Congress.
+	LCEndpoint = "http://lx2.loc.gov:210/LCDB"
+)
+
+// Protocol defaults, overridable on the Client.
+const (
+	DefaultVersion      = "1.1"
+	DefaultRecordSchema = "marcxml"
+	DefaultISBNIndex    = "bath.isbn"
+)
+
+// Page sizes for searchRetrieve requests. Servers commonly cap pages at 100.
+const (
+	defaultMaxResults = 10
+	maxMaxResults     = 100
+)
+
+// Diagnostic is an error reported by the server in the body of an otherwise
+// successful response, e.g. an unsupported index or a CQL syntax error.
+type Diagnostic struct {
+	URI     string `xml:"uri"`
+	Details string `xml:"details"`
+	Message string `xml:"message"`
+}
+
+// Error implements the error interface.
+func (d *Diagnostic) Error() string {
+	msg := d.Message
+	if msg == "" {
+		msg = d.URI
+	}
+	if d.Details != "" {
+		msg += ": " + d.Details
+	}
+	return "sru: " + msg
+}
+
+// Client implements the BookFinder interface for an SRU endpoint.
+type Client struct {
+	httpClient *http.Client
+	endpoint   string
+
+	// SRU protocol version sent with every request.
+	Version string
+
+	// Name of the MARCXML record schema on the server. Servers name it
+	// differently, e.g. "marcxml" or "MARC21-xml".
+	RecordSchema string
+
+	// CQL index used for ISBN queries. Everything else is searched with the
+	// server's default index.
+	ISBNIndex string
+
+	// Computes the confidence of each result.
+	Scorer *scoring.Scorer
+}
+
+// Register the provider so it can be enabled by name.
+func init() {
+	bookid.RegisterFinder(ProviderName, func(config bookid.ProviderConfig) (bookid.BookFinder, error) {
+		if config.URL == "" {
+			return nil, bookid.Errorf(bookid.EUNAUTHORIZED, "SRU endpoint is not configured.")
+		}
+		client := NewClientWithBaseURL(config.Client(), config.URL)
+		if config.RecordSchema != "" {
+			client.RecordSchema = config.RecordSchema
+		}
+		return client, nil
+	})
+}
+
+// NewClient creates a new client for the SRU server at endpoint.
+func NewClient(endpoint string) *Client {
+	return NewClientWithBaseURL(http.DefaultClient, endpoint)
+}
+
+// NewClientWithBaseURL creates a new client against a custom endpoint (for testing)
+func NewClientWithBaseURL(httpClient *http.Client, endpoint string) *Client {
+	return &Client{
+		httpClient:   httpClient,
+		endpoint:     endpoint,
+		Version:      DefaultVersion,
+		RecordSchema: DefaultRecordSchema,
+		ISBNIndex:    DefaultISBNIndex,
+		Scorer:       scoring.Default(),
+	}
+}
+
+// Search performs a book search based on the provided query. Language, print
+// type and order are ignored as CQL index names for them vary by server.
+func (c *Client) Search(ctx context.Context, query string, opts bookid.SearchOptions) ([]bookid.BookResult, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, errors.New("query cannot be empty")
+	} else if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	parsed := bookidquery.Parse(query)
+	searchType, cql := c.formatQuery(parsed)
+	if cql == "" {
+		// No index for the identifiers, so search them as keywords.
+		searchType, cql = bookid.SearchTypeGeneralQuery, quote(query)
+	}
+
+	records, err := c.searchRetrieve(ctx, cql, opts)
+	if err != nil {
+		return nil, FormatError(err)
+	}
+
+	results := make([]bookid.BookResult, 0, len(records))
+	for _, rec := range records {
+		result := toBookResult(&rec.Record, searchType)
+		result.ProviderData = rec.raw
+		result.Confidence = c.Scorer.Score(scoring.Input{Query: query, Options: opts, Result: result})
+		results = append(results, result)
+	}
+	return opts.Apply(results), nil
+}
+
+// LookupClassification returns the Dewey and LC classification numbers of
+// the first record with the given ISBN, or a zero Classification if the
+// server has no such record or it records neither.
+func (c *Client) LookupClassification(ctx context.Context, code string) (bookid.Classification, error) {
+	code = isbn.Normalize(code)
+	if code == "" {
+		return bookid.Classification{}, bookid.Errorf(bookid.EINVALID, "ISBN required.")
+	}
+	results, err := c.Search(ctx, code, bookid.SearchOptions{MaxResults: 1})
+	if err != nil || len(results) == 0 || results[0].Classification == nil {
+		return bookid.Classification{}, err
+	}
+	return *results[0].Classification, nil
+}
+
+// record is a MARC record of a response along with its MARCXML, kept as a
+// JSON string for provider data.
+type record struct {
+	marc.Record
+	raw json.RawMessage
+}
+
+// searchRetrieve sends a searchRetrieve request for the CQL query and
+// returns the MARC records of the response.
+func (c *Client) searchRetrieve(ctx context.Context, cql string, opts bookid.SearchOptions) ([]record, error) {
+	params := url.Values{
+		"operation":      {"searchRetrieve"},
+		"version":        {c.Version},
+		"query":          {cql},
+		"recordSchema":   {c.RecordSchema},
+		"recordPacking":  {"xml"},
+		"startRecord":    {strconv.Itoa(opts.StartIndex + 1)},
+		"maximumRecords": {strconv.Itoa(pageSize(opts.MaxResults))},
+	}
+
+	sep := "?"
+	if strings.Contains(c.endpoint, "?") {
+		sep = "&"
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.endpoint+sep+params.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/xml")
+
+	resp, err := httpclient.Do(c.httpClient, "SRU server", req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		Records []struct {
+			Data struct {
+				Inner  string      `xml:",innerxml"`
+				Record marc.Record `xml:"record"`
+			} `xml:"recordData"`
+		} `xml:"records>record"`
+		Diagnostics []Diagnostic `xml:"diagnostics>diagnostic"`
+	}
+	if err := xml.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("sru: decoding response: %w", err)
+	} else if len(body.Diagnostics) > 0 {
+		return nil, &body.Diagnostics[0]
+	}
+
+	records := make([]record, 0, len(body.Records))
+	for _, r := range body.Records {
+		raw, err := json.Marshal(strings.TrimSpace(r.Data.Inner))
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, record{Record: r.Data.Record, raw: raw})
+	}
+	return records, nil
+}
+
+// pageSize returns the number of records to request for maxResults.
+func pageSize(maxResults int) int {
+	if maxResults <= 0 {
+		return defaultMaxResults
+	}
+	return min(maxResults, maxMaxResults)
+}
+
+// formatQuery returns the CQL query for q along with its search type. ISBNs
+// are searched by ISBNIndex, the first one taking precedence, and fields by
+// their Dublin Core indexes. Returns an empty query if q has only identifiers
+// without an index.
+func (c *Client) formatQuery(q bookid.ParsedQuery) (bookid.SearchType, string) {
+	if code := q.ISBN(); code != "" {
+		return bookid.SearchTypeISBN, c.ISBNIndex + "=" + quote(code)
+	}
+
+	var clauses []string
+	if q.Terms != "" {
+		clauses = append(clauses, quote(q.Terms))
+	}
+	for _, field := range []struct{ index, value string }{
+		{"dc.title", q.Title},
+		{"dc.creator", q.Author},
+		{"dc.publisher", q.Publisher},
+	} {
+		if field.value != "" {
+			clauses = append(clauses, field.index+"="+quote(field.value))
+		}
+	}
+	if q.Year != 0 {
+		clauses = append(clauses, "dc.date="+strconv.Itoa(q.Year))
+	}
+
+	searchType := bookid.SearchTypeGeneralQuery
+	if t := q.SearchType(); t == bookid.SearchTypeTitle || t == bookid.SearchTypeTitleAuthor {
+		searchType = t
+	}
+	return searchType, strings.Join(clauses, " and ")
+}
+
+// quote returns s as a quoted CQL term.
+func quote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	return `"` + strings.ReplaceAll(s, `"`, `\"`) + `"`
+}
+
+// FormatError returns err as a bookid error if it is a Diagnostic, which
+// reports a query the server rejected. Otherwise returns the original error;
+// HTTP statuses are classified as the response is read.
+func FormatError(err error) error {
+	var diag *Diagnostic
+	if errors.As(err, &diag) {
+		return bookid.Errorf(bookid.EINVALID, "SRU server rejected the query: %s", strings.TrimPrefix(diag.Error(), "sru: "))
+	}
+	return err
+}
@@ -0,0 +1,177 @@
+package sru_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"testing"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/internal/httptestutil"
+	"github.com/fwojciec/bookid/sru"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newClient returns a client for the SRU server at endpoint replaying the
+// given synthetic fixture, hand-written MARCXML rather than a recording, so
+// it may hold made-up records. The URL of the last request is stored in
+// lastURL, if set.
+func newClient(t *testing.T, endpoint, fixture string, lastURL *string) *sru.Client {
+	t.Helper()
+	httpClient := httptestutil.Replay(t, filepath.Join("testdata", "synthetic", fixture))
+	if lastURL != nil {
+		httpClient = httptestutil.LastURL(httpClient, lastURL)
+	}
+	return sru.NewClientWithBaseURL(httpClient, endpoint)
+}
+
+func TestClient_Search(t *testing.T) {
+	t.Parallel()
+
+	t.Run("isbn", func(t *testing.T) {
+		t.Parallel()
+		var lastURL string
+		client := newClient(t, sru.DNBEndpoint, "search_9780743273565.json", &lastURL)
+		client.RecordSchema = "MARC21-xml"
+
+		results, err := client.Search(context.Background(), "978-0-7432-7356-5", bookid.SearchOptions{MaxResults: 1, IncludeRaw: true})
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		for _, want := range []string{sru.DNBEndpoint + "?", "operation=searchRetrieve", "version=1.1", "query=bath.isbn%3D%229780743273565%22", "recordSchema=MARC21-xml", "startRecord=1", "maximumRecords=1"} {
+			assert.Contains(t, lastURL, want)
+		}
+
+		r := results[0]
+		assert.Equal(t, "The great Gatsby", r.Title)
+		assert.Equal(t, []string{"F. Scott Fitzgerald"}, r.Authors, "editors are not authors")
+		assert.Equal(t, "0743273567", r.ISBN10)
+		assert.Equal(t, "9780743273565", r.ISBN13)
+		assert.Equal(t, "2004111282", r.LCCN)
+		assert.Equal(t, "54005413", r.OCLCNumber)
+		assert.Equal(t, &bookid.Classification{Dewey: "813.52", LCC: "PS3511.I9 G7 2004"}, r.Classification)
+		assert.Equal(t, "Scribner", r.Publisher)
+		assert.Equal(t, 2004, r.PublishedYear)
+		assert.Equal(t, "en", r.Language)
+		assert.Equal(t, "New York", r.Metadata["publication_place"])
+		assert.Equal(t, "1st Scribner trade pbk. ed", r.Metadata["edition"])
+		assert.Equal(t, 180, r.PageCount)
+		assert.Equal(t, bookid.Dimensions{Height: 210}, r.Dimensions)
+		assert.Equal(t, []string{"The great Gatsby", "Explanatory notes"}, r.TableOfContents)
+		assert.Equal(t, "The story of Jay Gatsby and his love for Daisy Buchanan.", r.Description)
+		assert.NotContains(t, r.Metadata, "extent")
+		assert.Equal(t, []bookid.Contributor{{Name: "Matthew J. Bruccoli", Role: bookid.ContributorRoleEditor}}, r.Contributors)
+		assert.Equal(t, sru.ProviderName, r.Provider)
+		assert.Equal(t, bookid.SearchTypeISBN, r.SearchType)
+		assert.InDelta(t, 0.95, r.Confidence, 0.01)
+		assert.Contains(t, string(r.ProviderData), "MARC21/slim")
+	})
+
+	t.Run("general", func(t *testing.T) {
+		t.Parallel()
+		var lastURL string
+		client := newClient(t, sru.DNBEndpoint+"?x-info=1", "search_gatsby.json", &lastURL)
+
+		results, err := client.Search(context.Background(), `der "große" gatsby`, bookid.SearchOptions{MaxResults: 500, StartIndex: 20})
+		require.NoError(t, err)
+		require.Len(t, results, 2)
+		for _, want := range []string{sru.DNBEndpoint + "?x-info=1&", "query=%22der+%5C%22gro%C3%9Fe%5C%22+gatsby%22", "recordSchema=marcxml", "startRecord=21", "maximumRecords=100"} {
+			assert.Contains(t, lastURL, want)
+		}
+
+		assert.Equal(t, []bookid.Contributor{
+			{Name: "Matthew J. Bruccoli", Role: bookid.ContributorRoleEditor},
+			{Name: "Francis Cugat", Role: bookid.ContributorRoleIllustrator},
+		}, results[0].Contributors, "roles are read from relator terms")
+
+		r := results[1]
+		assert.Equal(t, "Der große Gatsby: Roman", r.Title)
+		assert.Equal(t, []string{"F. Scott Fitzgerald"}, r.Authors)
+		assert.Equal(t, "10.5555/gatsby-de", r.DOI)
+		assert.Equal(t, "Diogenes", r.Publisher)
+		assert.Equal(t, 2011, r.PublishedYear)
+		assert.Equal(t, "de", r.Language)
+		assert.Equal(t, "Diogenes-Taschenbuch", r.Series)
+		assert.InDelta(t, 24052, r.SeriesVolume, 0)
+		assert.Equal(t, "The great Gatsby", r.OriginalTitle)
+		assert.Equal(t, "en", r.OriginalLanguage)
+		assert.Equal(t, []bookid.Contributor{
+			{Name: "Bettina Abarbanell", Role: bookid.ContributorRoleTranslator},
+			{Name: "Francis Cugat", Role: bookid.ContributorRoleIllustrator},
+		}, r.Contributors, "roles are read from relator codes")
+		assert.Equal(t, bookid.SearchTypeGeneralQuery, r.SearchType)
+		assert.Nil(t, r.ProviderData, "raw data is only kept when requested")
+	})
+
+	t.Run("fields", func(t *testing.T) {
+		t.Parallel()
+		var lastURL string
+		client := newClient(t, sru.LCEndpoint, "search_gatsby_fields.json", &lastURL)
+
+		results, err := client.Search(context.Background(), `title:"Der große Gatsby" author:Fitzgerald year:2011`, bookid.SearchOptions{})
+		require.NoError(t, err)
+		u, err := url.Parse(lastURL)
+		require.NoError(t, err)
+		assert.Equal(t, `dc.title="Der große Gatsby" and dc.creator="Fitzgerald" and dc.date=2011`, u.Query().Get("query"))
+		assert.Equal(t, bookid.SearchTypeTitleAuthor, results[0].SearchType)
+	})
+}
+
+func TestClient_LookupClassification(t *testing.T) {
+	t.Parallel()
+
+	var lastURL string
+	client := newClient(t, sru.LCEndpoint, "classification_0743273567.json", &lastURL)
+
+	c, err := client.LookupClassification(context.Background(), "0-7432-7356-7")
+	require.NoError(t, err)
+	assert.Contains(t, lastURL, "maximumRecords=1")
+	assert.Equal(t, bookid.Classification{Dewey: "813.52", LCC: "PS3511.I9 G7 2004"}, c)
+
+	_, err = client.LookupClassification(context.Background(), "")
+	assert.Equal(t, bookid.EINVALID, bookid.ErrorCode(err))
+}
+
+func TestClient_Search_Errors(t *testing.T) {
+	t.Parallel()
+
+	t.Run("empty_query", func(t *testing.T) {
+		t.Parallel()
+		_, err := sru.NewClient(sru.DNBEndpoint).Search(context.Background(), "  ", bookid.SearchOptions{})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "query cannot be empty")
+	})
+
+	t.Run("diagnostic", func(t *testing.T) {
+		t.Parallel()
+		client := newClient(t, sru.LCEndpoint, "diagnostic.json", nil)
+
+		_, err := client.Search(context.Background(), "0743273567", bookid.SearchOptions{})
+		assert.Equal(t, bookid.EINVALID, bookid.ErrorCode(err))
+		assert.Equal(t, "SRU server rejected the query: Unsupported index: bath.isbn", bookid.ErrorMessage(err))
+	})
+
+	tests := []struct {
+		status int
+		code   string
+	}{
+		{http.StatusForbidden, bookid.EUNAUTHORIZED},
+		{http.StatusTooManyRequests, bookid.ERATELIMIT},
+		{http.StatusServiceUnavailable, bookid.EUNAVAILABLE},
+	}
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			t.Parallel()
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(tt.status)
+			}))
+			t.Cleanup(srv.Close)
+			client := sru.NewClientWithBaseURL(srv.Client(), srv.URL)
+
+			_, err := client.Search(context.Background(), "dune", bookid.SearchOptions{})
+			assert.Equal(t, tt.code, bookid.ErrorCode(err))
+		})
+	}
+}
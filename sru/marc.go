@@ -0,0 +1,236 @@
+package sru
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/classify"
+	"github.com/fwojciec/bookid/dimension"
+	"github.com/fwojciec/bookid/doi"
+	"github.com/fwojciec/bookid/isbn"
+	"github.com/fwojciec/bookid/language"
+	"github.com/fwojciec/bookid/lccn"
+	"github.com/fwojciec/bookid/marc"
+	"github.com/fwojciec/bookid/series"
+)
+
+// isAuthor reports whether a name field names an author of the work. Main
+// entries always do; added entries may name translators, illustrators and
+// the like, identified by a relator term or code.
+func isAuthor(f marc.DataField) bool {
+	if strings.HasPrefix(f.Tag, "1") {
+		return true
+	}
+	term, code := f.Subfield("e"), f.Subfield("4")
+	return (term == "" && code == "") || code == "aut" || strings.HasPrefix(term, "author")
+}
+
+// toBookResult converts a MARC record to our BookResult.
+func toBookResult(r *marc.Record, searchType bookid.SearchType) bookid.BookResult {
+	result := bookid.BookResult{
+		Authors:    []string{},
+		Provider:   ProviderName,
+		SearchType: searchType,
+		Metadata:   map[string]string{},
+	}
+
+	for _, f := range r.Fields("245") {
+		result.Title = cleanTitle(f.Subfield("a"))
+		if subtitle := cleanTitle(f.Subfield("b")); subtitle != "" {
+			result.Title += ": " + subtitle
+		}
+	}
+	for _, f := range r.Fields("100", "110", "700", "710") {
+		name := invertName(f.Subfield("a"))
+		switch {
+		case name == "":
+		case isAuthor(f):
+			result.Authors = append(result.Authors, name)
+		default:
+			if role := marc.RelatorRole(f.Subfield("e"), f.Subfield("4")); role != "" {
+				result.Contributors = append(result.Contributors, bookid.Contributor{Name: name, Role: role})
+			}
+		}
+	}
+
+	for _, f := range r.Fields("020") {
+		code := isbn.Normalize(firstWord(f.Subfield("a")))
+		switch {
+		case result.ISBN13 == "" && isbn.Valid13(code):
+			result.ISBN13 = code
+		case result.ISBN10 == "" && isbn.Valid10(code):
+			result.ISBN10 = code
+		}
+	}
+	for _, f := range r.Fields("010") {
+		if code := lccn.Normalize(f.Subfield("a")); lccn.Valid(code) {
+			result.LCCN = code
+		}
+	}
+	for _, f := range r.Fields("024") {
+		if code, ok := doi.Parse(f.Subfield("a")); ok && f.Ind1 == "7" {
+			result.DOI = code
+		}
+	}
+	for _, f := range r.Fields("035") {
+		if number, ok := strings.CutPrefix(f.Subfield("a"), "(OCoLC)"); ok && result.OCLCNumber == "" {
+			result.OCLCNumber = strings.TrimLeft(strings.TrimSpace(number), "ocmn0")
+		}
+	}
+
+	// The LC call number is split into the class number in $a and the item
+	// number in $b; the Dewey number is in 082 $a.
+	var c bookid.Classification
+	for _, f := range r.Fields("050") {
+		if c.LCC == "" {
+			c.LCC = strings.TrimSpace(f.Subfield("a") + " " + f.Subfield("b"))
+		}
+	}
+	for _, f := range r.Fields("082") {
+		if c.Dewey == "" {
+			c.Dewey = f.Subfield("a")
+		}
+	}
+	if c = classify.Normalize(c); !c.IsZero() {
+		result.Classification = &c
+	}
+
+	// Fixed-length data holds the year in positions 07-10 and the language
+	// in 35-37.
+	if f008 := r.ControlField("008"); len(f008) >= 38 {
+		result.PublishedYear, _ = strconv.Atoi(f008[7:11])
+		if code := strings.TrimSpace(f008[35:38]); code != "" && code != "und" {
+			result.Language = language.FromMARC(code)
+		}
+	}
+	for _, f := range r.Fields("264", "260") {
+		if f.Tag == "264" && f.Ind2 != "1" {
+			continue // Not a publication statement
+		}
+		if result.Publisher == "" {
+			result.Publisher = strings.TrimRight(f.Subfield("b"), " ,:;")
+		}
+		if result.PublishedYear == 0 {
+			result.PublishedYear = extractYear(f.Subfield("c"))
+		}
+		if place := strings.TrimRight(f.Subfield("a"), " :;"); place != "" {
+			result.Metadata["publication_place"] = place
+		}
+	}
+
+	// The authorized form of the series in an added entry is preferred to the
+	// statement transcribed from the book.
+	for _, f := range append(r.Fields("830"), r.Fields("490")...) {
+		if result.Series == "" {
+			result.Series, result.SeriesVolume = series.Parse(f.Subfield("a") + " " + f.Subfield("v"))
+		}
+	}
+
+	// Translations name the language of the original in field 041 and
+	// usually give the original title as the uniform title.
+	for _, f := range r.Fields("041") {
+		if code := f.Subfield("h"); f.Ind1 == "1" && code != "" {
+			result.OriginalLanguage = language.FromMARC(code)
+		}
+	}
+	for _, f := range r.Fields("240") {
+		if title := cleanTitle(f.Subfield("a")); title != "" && (result.OriginalLanguage != "" || f.Subfield("l") != "") {
+			result.OriginalTitle = title
+		}
+	}
+
+	for _, f := range r.Fields("250") {
+		result.Metadata["edition"] = strings.TrimRight(f.Subfield("a"), " /.")
+	}
+	for _, f := range r.Fields("300") {
+		extent := strings.TrimRight(f.Subfield("a"), " :;")
+		if pages := pageCount(extent); pages > 0 {
+			result.PageCount = pages
+		} else {
+			result.Metadata["extent"] = extent
+		}
+		result.Dimensions.Height = dimension.Length(strings.TrimRight(f.Subfield("c"), " ."))
+	}
+
+	// Formatted contents notes separate the titles of the parts with " -- ";
+	// enhanced ones give each title in its own $t.
+	for _, f := range r.Fields("505") {
+		titles := f.Values("t")
+		if len(titles) == 0 {
+			titles = strings.Split(f.Subfield("a"), " -- ")
+		}
+		for _, t := range titles {
+			if t = strings.TrimRight(strings.TrimSpace(t), " /.-"); t != "" {
+				result.TableOfContents = append(result.TableOfContents, t)
+			}
+		}
+	}
+	for _, f := range r.Fields("520") {
+		if summary := strings.TrimSpace(f.Subfield("a")); summary != "" && f.Ind1 != "8" && result.Description == "" {
+			result.Description = summary
+		}
+	}
+	if len(result.Metadata) == 0 {
+		result.Metadata = nil
+	}
+	return result
+}
+
+// pageCount returns the number of pages in a physical description extent
+// such as "180 p." or "xii, 304 pages", or 0 if it does not give one.
+func pageCount(extent string) int {
+	fields := strings.Fields(strings.ReplaceAll(extent, ",", " "))
+	for i := 1; i < len(fields); i++ {
+		if unit := fields[i]; unit == "p." || unit == "p" || strings.HasPrefix(unit, "page") {
+			if n, err := strconv.Atoi(fields[i-1]); err == nil {
+				return n
+			}
+		}
+	}
+	return 0
+}
+
+// firstWord returns s up to its first space, dropping qualifiers such as
+// "(pbk.)" from ISBNs.
+func firstWord(s string) string {
+	s, _, _ = strings.Cut(strings.TrimSpace(s), " ")
+	return s
+}
+
+// cleanTitle strips trailing ISBD punctuation from a title subfield, e.g.
+// "The great Gatsby /" becomes "The great Gatsby".
+func cleanTitle(title string) string {
+	return strings.TrimRight(strings.TrimSpace(title), " /:;.,=")
+}
+
+// invertName converts a heading such as "Fitzgerald, F. Scott (Francis
+// Scott)," to "F. Scott Fitzgerald".
+func invertName(heading string) string {
+	if i := strings.Index(heading, "("); i >= 0 {
+		if j := strings.Index(heading[i:], ")"); j >= 0 {
+			heading = heading[:i] + heading[i+j+1:]
+		}
+	}
+	heading = strings.TrimRight(strings.TrimSpace(heading), " ,")
+	// Drop ISBD punctuation but keep the period of a trailing initial.
+	if fields := strings.Fields(heading); len(fields) > 0 && len(fields[len(fields)-1]) > 2 {
+		heading = strings.TrimSuffix(heading, ".")
+	}
+	family, given, ok := strings.Cut(heading, ",")
+	if !ok {
+		return heading
+	}
+	return strings.Join(strings.Fields(given+" "+family), " ")
+}
+
+// extractYear returns the first four-digit year in an imprint date such as
+// "2004", "c2004." or "[2004]".
+func extractYear(date string) int {
+	for i := 0; i+4 <= len(date); i++ {
+		if year, err := strconv.Atoi(date[i : i+4]); err == nil && year >= 1000 && year <= 2999 {
+			return year
+		}
+	}
+	return 0
+}
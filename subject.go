@@ -0,0 +1,75 @@
+package bookid
+
+import (
+	"context"
+	"strings"
+)
+
+// Subject represents a topic or genre works are tagged with, e.g. "science
+// fiction" or "courtship". Names are normalized by the subject package so
+// that the categories of different providers share subjects.
+type Subject struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+
+	// BISAC subject code, e.g. "FIC028000" for science fiction, when a
+	// provider categorized a work with the subject's BISAC heading.
+	BISACCode string `json:"bisac_code,omitempty"`
+}
+
+// Validate returns an error if the subject contains invalid fields.
+func (s *Subject) Validate() error {
+	if strings.TrimSpace(s.Name) == "" {
+		return Errorf(EINVALID, "Subject name required.")
+	}
+	return nil
+}
+
+// WorkSubject tags a work with a subject.
+type WorkSubject struct {
+	WorkID    int64 `json:"work_id"`
+	SubjectID int64 `json:"subject_id"`
+}
+
+// SubjectService represents a service for managing subjects and the works
+// tagged with them.
+type SubjectService interface {
+	// FindSubjectByID retrieves a single subject by ID.
+	// Returns ENOTFOUND if the subject does not exist.
+	FindSubjectByID(ctx context.Context, id int64) (*Subject, error)
+
+	// FindSubjects retrieves a list of subjects matching the filter along
+	// with the total number of matches, ignoring Offset and Limit.
+	FindSubjects(ctx context.Context, filter SubjectFilter) ([]*Subject, int, error)
+
+	// CreateSubject creates a new subject. The name is normalized first, and
+	// if a subject with the normalized name already exists, subject is
+	// populated from it instead, after recording the BISAC code if the
+	// existing subject has none.
+	CreateSubject(ctx context.Context, subject *Subject) error
+
+	// AddWorkSubject tags a work with a subject. Tagging an already tagged
+	// work is not an error. Returns ENOTFOUND if the work or subject does
+	// not exist.
+	AddWorkSubject(ctx context.Context, ws *WorkSubject) error
+
+	// RemoveWorkSubject removes a subject from a work.
+	// Returns ENOTFOUND if the work is not tagged with the subject.
+	RemoveWorkSubject(ctx context.Context, ws *WorkSubject) error
+}
+
+// SubjectFilter represents a filter used by FindSubjects.
+type SubjectFilter struct {
+	ID *int64
+
+	// Name matches subjects by normalized name, so "Science-Fiction" finds
+	// "science fiction".
+	Name *string
+
+	// WorkID restricts results to the subjects of a work.
+	WorkID *int64
+
+	// Restrict to subset of results.
+	Offset int
+	Limit  int
+}
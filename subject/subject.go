@@ -0,0 +1,89 @@
+// Package subject maps the categories and subjects of providers to
+// normalized subjects, so that Google Books' "Fiction / Science Fiction /
+// General" and Open Library's "Science-fiction" both tag a work with
+// "science fiction".
+package subject
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// MaxSubjects is the number of subjects kept from a single result. Open
+// Library lists hundreds of subjects for popular works, most specific first
+// only by accident, so the first few are kept.
+const MaxSubjects = 10
+
+// Normalize returns the normalized subjects of the categories or subjects of
+// a provider, without duplicates and in the order given. Hierarchical
+// categories such as BISAC's "Fiction / Classics" and subject headings such
+// as "Married people -- Fiction" are split into their parts, and parts
+// carrying no subject, such as "General", are dropped, as are machine tags
+// such as "nyt:combined-print-fiction=2013-05-26".
+func Normalize(categories []string) []string {
+	var subjects []string
+	for _, c := range categories {
+		if strings.ContainsAny(c, ":=") {
+			continue // Machine tag
+		}
+		c = strings.ReplaceAll(c, " -- ", "/")
+		for _, part := range strings.Split(c, "/") {
+			if name := Name(part); name != "" && !slices.Contains(subjects, name) {
+				subjects = append(subjects, name)
+			}
+		}
+		if len(subjects) >= MaxSubjects {
+			return subjects[:MaxSubjects]
+		}
+	}
+	return subjects
+}
+
+// Has reports whether the categories or subjects of a provider include a
+// subject, comparing normalized names.
+func Has(categories []string, name string) bool {
+	name = Name(name)
+	return name != "" && slices.Contains(Normalize(categories), name)
+}
+
+// Name returns the normalized form of a single subject: lowercase words
+// separated by single spaces, with surrounding punctuation removed and
+// common variants spelled the same way. Returns an empty string for parts
+// carrying no subject, such as "General", or naming a person, which come
+// with their years of birth and death.
+func Name(s string) string {
+	s = strings.ToLower(strings.Join(strings.Fields(s), " "))
+	s = strings.Trim(strings.TrimSuffix(s, ", general"), " .;:-\"'")
+	if s == "" || strings.ContainsFunc(s, unicode.IsDigit) {
+		return ""
+	}
+	switch s {
+	case "general", "miscellaneous", "other", "fiction in english", "accessible book", "protected daisy", "in library":
+		return ""
+	}
+	if alias, ok := aliases()[s]; ok {
+		return alias
+	}
+	return s
+}
+
+// aliases returns the normalized names of common variant spellings.
+func aliases() map[string]string {
+	return map[string]string{
+		"science-fiction":              "science fiction",
+		"sci-fi":                       "science fiction",
+		"sf":                           "science fiction",
+		"fantasy fiction":              "fantasy",
+		"detective and mystery":        "mystery",
+		"mystery and detective":        "mystery",
+		"detective stories":            "mystery",
+		"love stories":                 "romance",
+		"juvenile fiction":             "children's fiction",
+		"children's stories":           "children's fiction",
+		"biography & autobiography":    "biography",
+		"autobiography":                "biography",
+		"history and criticism":        "literary criticism",
+		"criticism and interpretation": "literary criticism",
+	}
+}
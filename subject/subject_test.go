@@ -0,0 +1,70 @@
+package subject_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/fwojciec/bookid/subject"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalize(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		categories []string
+		want       []string
+	}{
+		{"google_books", []string{"Fiction / Science Fiction / General"}, []string{"fiction", "science fiction"}},
+		{"subject_heading", []string{"Married people -- Fiction.", "Long Island (N.Y.) -- Fiction"}, []string{"married people", "fiction", "long island (n.y.)"}},
+		{"open_library", []string{"Fiction, general", "Science-fiction", "nyt:combined-print-fiction=2013-05-26", "Fitzgerald, F. Scott (Francis Scott), 1896-1940"}, []string{"fiction", "science fiction"}},
+		{"duplicates", []string{"Fiction", "FICTION", "Juvenile Fiction / Fantasy & Magic"}, []string{"fiction", "children's fiction", "fantasy & magic"}},
+		{"empty", nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.want, subject.Normalize(tt.categories))
+		})
+	}
+
+	t.Run("max_subjects", func(t *testing.T) {
+		t.Parallel()
+		var categories []string
+		for i := range 20 {
+			categories = append(categories, fmt.Sprintf("Subject %c", 'a'+i))
+		}
+		assert.Len(t, subject.Normalize(categories), subject.MaxSubjects)
+	})
+}
+
+func TestName(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		in, want string
+	}{
+		{"  Science   Fiction. ", "science fiction"},
+		{"Sci-Fi", "science fiction"},
+		{"General", ""},
+		{"1896-1940", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.want, subject.Name(tt.in))
+		})
+	}
+}
+
+func TestHas(t *testing.T) {
+	t.Parallel()
+
+	categories := []string{"Fiction / Science Fiction / General"}
+	assert.True(t, subject.Has(categories, "Sci-Fi"))
+	assert.True(t, subject.Has(categories, "fiction"))
+	assert.False(t, subject.Has(categories, "fantasy"))
+	assert.False(t, subject.Has(categories, "General"), "parts without a subject never match")
+}
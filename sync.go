@@ -0,0 +1,121 @@
+package bookid
+
+import (
+	"context"
+	"time"
+)
+
+// SyncRule represents how a sync resolves conflicts: rows changed in both
+// catalogs since they were last synced.
+type SyncRule string
+
+// Sync rules, from the point of view of the catalog receiving changes.
+const (
+	SyncRuleNewer    SyncRule = "newer"    // The row changed last wins
+	SyncRuleLocal    SyncRule = "local"    // The receiving catalog's row wins
+	SyncRuleIncoming SyncRule = "incoming" // The incoming row wins
+)
+
+// Valid returns true if the rule is known.
+func (r SyncRule) Valid() bool {
+	switch r {
+	case SyncRuleNewer, SyncRuleLocal, SyncRuleIncoming:
+		return true
+	}
+	return false
+}
+
+// SyncRecord represents a work with its authors and publications, including
+// those in the trash, as exchanged between synced catalogs. Rows are matched
+// across catalogs by UID; authors are matched by name and authority
+// identifiers like when books are saved.
+type SyncRecord struct {
+	Work         *Work          `json:"work"`
+	Authors      []*Author      `json:"authors,omitempty"` // With their roles in the work
+	Publications []*Publication `json:"publications,omitempty"`
+}
+
+// Tombstone records a work or publication permanently removed from a
+// catalog, so that sync removes it from the other catalog as well.
+type Tombstone struct {
+	UID       string    `json:"uid"`
+	Entity    string    `json:"entity"` // AuditEntityWork or AuditEntityPublication
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+// SyncChanges represents the changes made to a catalog since a time.
+type SyncChanges struct {
+	Records    []*SyncRecord `json:"records"`
+	Tombstones []*Tombstone  `json:"tombstones"`
+}
+
+// SyncOptions represents how changes from another catalog are applied.
+type SyncOptions struct {
+	// Time the catalogs were last synced. Rows changed in both catalogs
+	// since are conflicts. Zero if they were never synced, which makes
+	// every row present in both a conflict.
+	Since time.Time `json:"since,omitzero"`
+
+	// How conflicts are resolved. Defaults to SyncRuleNewer.
+	Rule SyncRule `json:"rule,omitempty"`
+
+	// Resolve conflicts changed at the same time in favor of the incoming
+	// row. Exactly one of two synced catalogs sets it so that both resolve
+	// ties the same way.
+	IncomingWinsTies bool `json:"incoming_wins_ties,omitempty"`
+}
+
+// Validate returns an error if the options contain invalid fields.
+func (o SyncOptions) Validate() error {
+	if o.Rule != "" && !o.Rule.Valid() {
+		return Errorf(EINVALID, "Invalid sync rule %q.", o.Rule)
+	}
+	return nil
+}
+
+// IncomingWins returns true if a conflict between a row changed locally at
+// local and incoming one changed at incoming is resolved in favor of the
+// incoming row.
+func (o SyncOptions) IncomingWins(local, incoming time.Time) bool {
+	switch o.Rule {
+	case SyncRuleLocal:
+		return false
+	case SyncRuleIncoming:
+		return true
+	}
+	if incoming.Equal(local) {
+		return o.IncomingWinsTies
+	}
+	return incoming.After(local)
+}
+
+// SyncConflict represents a conflict resolved by a sync.
+type SyncConflict struct {
+	UID      string `json:"uid"`
+	Entity   string `json:"entity"` // AuditEntityWork or AuditEntityPublication
+	Title    string `json:"title,omitempty"`
+	Incoming bool   `json:"incoming"` // Whether the incoming row won
+}
+
+// SyncReport represents the changes made to a catalog by a sync.
+type SyncReport struct {
+	Created   int             `json:"created"` // Works and publications
+	Updated   int             `json:"updated"`
+	Deleted   int             `json:"deleted"`
+	Conflicts []*SyncConflict `json:"conflicts,omitempty"`
+}
+
+// SyncService represents a service for syncing the works, authors and
+// publications of two catalogs, such as those of a laptop and a home server.
+// Subjects, series, collections, copies and loans are not synced.
+type SyncService interface {
+	// FindChanges retrieves the works that changed since a time, along
+	// with those whose publications changed, and the tombstones of works
+	// and publications removed since. The zero time retrieves everything.
+	FindChanges(ctx context.Context, since time.Time) (*SyncChanges, error)
+
+	// ApplyChanges applies the changes of another catalog, resolving
+	// conflicts by the options. The changes are applied in a single
+	// transaction.
+	ApplyChanges(ctx context.Context, changes *SyncChanges, opts SyncOptions) (*SyncReport, error)
+}
@@ -0,0 +1,139 @@
+// Package tracing instruments bookid with OpenTelemetry spans so the latency
+// of identifying a book can be traced end to end, from an HTTP API request
+// through the finder chain to provider API calls and catalog queries.
+//
+// Instrumented components use the global tracer provider, which discards
+// spans until SetTracerProvider installs one created by NewTracerProvider.
+package tracing
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/fwojciec/bookid"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// InstrumentationName identifies the tracer of this package.
+const InstrumentationName = "github.com/fwojciec/bookid/tracing"
+
+// ServiceName is the service.name resource attribute of exported spans.
+const ServiceName = "bookid"
+
+// Span exporters supported by NewTracerProvider.
+const (
+	// Sends spans to an OpenTelemetry collector over OTLP/HTTP, configured
+	// by the standard OTEL_EXPORTER_OTLP_* environment variables.
+	ExporterOTLP = "otlp"
+
+	// Writes spans as JSON, for debugging.
+	ExporterStdout = "stdout"
+)
+
+// Exporters returns the names of the supported span exporters.
+func Exporters() []string {
+	return []string{ExporterOTLP, ExporterStdout}
+}
+
+// NewTracerProvider returns a tracer provider batching spans to the named
+// exporter. The stdout exporter writes to w. Returns EINVALID if the exporter
+// is not supported. The provider must be shut down to flush pending spans.
+func NewTracerProvider(ctx context.Context, exporter string, w io.Writer) (*sdktrace.TracerProvider, error) {
+	var exp sdktrace.SpanExporter
+	var err error
+	switch exporter {
+	case ExporterOTLP:
+		exp, err = otlptracehttp.New(ctx)
+	case ExporterStdout:
+		exp, err = stdouttrace.New(stdouttrace.WithWriter(w))
+	default:
+		return nil, bookid.Errorf(bookid.EINVALID, "Unknown trace exporter %q; expected one of %s.", exporter, strings.Join(Exporters(), ", "))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exp),
+		sdktrace.WithResource(resource.NewSchemaless(attribute.String("service.name", ServiceName))),
+	), nil
+}
+
+// SetTracerProvider installs tp as the global tracer provider used by
+// instrumented components and propagates trace context in W3C headers.
+func SetTracerProvider(tp trace.TracerProvider) {
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+}
+
+// NewHTTPClient returns an HTTP client tracing outgoing requests as child
+// spans of the request context and propagating the trace to the server.
+func NewHTTPClient() *http.Client {
+	return &http.Client{Transport: otelhttp.NewTransport(http.DefaultTransport)}
+}
+
+// NewHandler returns h wrapped to trace incoming requests, continuing traces
+// started by the caller. Spans are named after the request method to keep
+// their number bounded; the path is recorded as an attribute.
+func NewHandler(h http.Handler) http.Handler {
+	return otelhttp.NewHandler(h, ServiceName, otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+		return r.Method
+	}))
+}
+
+// Ensure type implements interface.
+var _ bookid.BookFinder = (*Finder)(nil)
+
+// Finder wraps a BookFinder and records each search as a span.
+type Finder struct {
+	finder bookid.BookFinder
+	name   string
+
+	// Creates the spans. Defaults to a tracer of the global provider.
+	Tracer trace.Tracer
+}
+
+// NewFinder returns a Finder tracing searches of finder. name identifies the
+// provider, if finder is one, and is added to the span name.
+func NewFinder(finder bookid.BookFinder, name string) *Finder {
+	return &Finder{
+		finder: finder,
+		name:   name,
+		Tracer: otel.Tracer(InstrumentationName),
+	}
+}
+
+// Search searches the wrapped finder within a span recording the query, the
+// number of results, their search type and any error.
+func (f *Finder) Search(ctx context.Context, query string, opts bookid.SearchOptions) ([]bookid.BookResult, error) {
+	attrs := []attribute.KeyValue{attribute.String("bookid.query", query)}
+	name := "Search"
+	if f.name != "" {
+		name += " " + f.name
+		attrs = append(attrs, attribute.String("bookid.provider", f.name))
+	}
+	ctx, span := f.Tracer.Start(ctx, name, trace.WithAttributes(attrs...))
+	defer span.End()
+
+	results, err := f.finder.Search(ctx, query, opts)
+	span.SetAttributes(attribute.Int("bookid.results", len(results)))
+	if len(results) > 0 {
+		span.SetAttributes(attribute.String("bookid.search_type", string(results[0].SearchType)))
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, bookid.ErrorCode(err))
+	}
+	return results, err
+}
@@ -0,0 +1,107 @@
+package tracing_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/mock"
+	"github.com/fwojciec/bookid/tracing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// newRecorder returns a tracer provider recording finished spans.
+func newRecorder(t *testing.T) (*sdktrace.TracerProvider, *tracetest.SpanRecorder) {
+	t.Helper()
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	t.Cleanup(func() { _ = tp.Shutdown(context.Background()) })
+	return tp, recorder
+}
+
+func TestFinder_Search(t *testing.T) {
+	t.Parallel()
+
+	t.Run("OK", func(t *testing.T) {
+		t.Parallel()
+		tp, recorder := newRecorder(t)
+		provider := tracing.NewFinder(&mock.BookFinder{SearchFn: func(context.Context, string, bookid.SearchOptions) ([]bookid.BookResult, error) {
+			return []bookid.BookResult{{Title: "Dune", SearchType: bookid.SearchTypeISBN}}, nil
+		}}, "isbndb")
+		provider.Tracer = tp.Tracer("test")
+		outer := tracing.NewFinder(provider, "")
+		outer.Tracer = tp.Tracer("test")
+
+		_, err := outer.Search(context.Background(), "9780441172719", bookid.SearchOptions{})
+		require.NoError(t, err)
+
+		spans := recorder.Ended()
+		require.Len(t, spans, 2)
+		assert.Equal(t, "Search isbndb", spans[0].Name())
+		assert.Equal(t, "Search", spans[1].Name())
+		assert.Equal(t, spans[1].SpanContext().SpanID(), spans[0].Parent().SpanID())
+		assert.Contains(t, spans[0].Attributes(), attribute.String("bookid.provider", "isbndb"))
+		assert.Contains(t, spans[0].Attributes(), attribute.Int("bookid.results", 1))
+		assert.Contains(t, spans[0].Attributes(), attribute.String("bookid.search_type", "isbn"))
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		t.Parallel()
+		tp, recorder := newRecorder(t)
+		f := tracing.NewFinder(&mock.BookFinder{SearchFn: func(context.Context, string, bookid.SearchOptions) ([]bookid.BookResult, error) {
+			return nil, bookid.Errorf(bookid.ERATELIMIT, "Slow down.")
+		}}, "googlebooks")
+		f.Tracer = tp.Tracer("test")
+
+		_, err := f.Search(context.Background(), "dune", bookid.SearchOptions{})
+		require.Error(t, err)
+
+		spans := recorder.Ended()
+		require.Len(t, spans, 1)
+		assert.Equal(t, codes.Error, spans[0].Status().Code)
+		assert.Equal(t, bookid.ERATELIMIT, spans[0].Status().Description)
+	})
+}
+
+func TestNewTracerProvider(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Stdout", func(t *testing.T) {
+		t.Parallel()
+		var buf bytes.Buffer
+		tp, err := tracing.NewTracerProvider(context.Background(), tracing.ExporterStdout, &buf)
+		require.NoError(t, err)
+
+		_, span := tp.Tracer("test").Start(context.Background(), "Search")
+		span.End()
+		require.NoError(t, tp.Shutdown(context.Background()))
+		assert.Contains(t, buf.String(), `"Name":"Search"`)
+		assert.Contains(t, buf.String(), `"Value":"bookid"`)
+	})
+
+	t.Run("ErrUnknownExporter", func(t *testing.T) {
+		t.Parallel()
+		_, err := tracing.NewTracerProvider(context.Background(), "jaeger", nil)
+		assert.Equal(t, bookid.EINVALID, bookid.ErrorCode(err))
+	})
+}
+
+func TestNewHandler(t *testing.T) {
+	t.Parallel()
+	// The handler uses the global tracer provider, which records nothing,
+	// but must still serve the request.
+	h := tracing.NewHandler(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/search?q=dune", nil))
+	assert.Equal(t, http.StatusTeapot, w.Code)
+}
@@ -0,0 +1,242 @@
+// Package tui implements an interactive terminal picker for choosing among
+// the results of an ambiguous search.
+//
+// The picker reads key presses from any reader and draws to any writer using
+// ANSI escape sequences, so callers are responsible for putting the terminal
+// into raw mode (see golang.org/x/term) and tests can drive it with scripted
+// input.
+package tui
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/fwojciec/bookid"
+)
+
+// Default terminal size, used when the size is unknown.
+const (
+	DefaultWidth  = 80
+	DefaultHeight = 24
+)
+
+// detailHeight is the number of lines of the detail pane, including its
+// separator.
+const detailHeight = 9
+
+// Key presses understood by the picker.
+const (
+	keyCtrlC = 0x03
+	keyEnter = '\r'
+	keyEsc   = 0x1b
+)
+
+// ErrCanceled is returned by Pick when the user quits without choosing.
+var ErrCanceled = errors.New("tui: canceled")
+
+// Picker lets a user choose one of several search results with the arrow
+// keys. The highlighted result's details are shown below the list.
+type Picker struct {
+	in  *bufio.Reader
+	out io.Writer
+
+	// Size of the terminal in columns and lines.
+	Width  int
+	Height int
+}
+
+// NewPicker returns a picker reading keys from in and drawing to out.
+func NewPicker(in io.Reader, out io.Writer) *Picker {
+	return &Picker{
+		in:     bufio.NewReader(in),
+		out:    out,
+		Width:  DefaultWidth,
+		Height: DefaultHeight,
+	}
+}
+
+// Pick shows results and returns the index of the one chosen with Enter.
+// Returns ErrCanceled if the user quits with q, Ctrl-C or Esc twice, and EINVALID
+// if there are no results.
+func (p *Picker) Pick(query string, results []bookid.BookResult) (int, error) {
+	if len(results) == 0 {
+		return 0, bookid.Errorf(bookid.EINVALID, "No results to choose from.")
+	}
+
+	// Use the alternate screen so the shell's scrollback is left intact.
+	if _, err := io.WriteString(p.out, "\x1b[?1049h\x1b[?25l"); err != nil {
+		return 0, err
+	}
+	defer func() { _, _ = io.WriteString(p.out, "\x1b[?25h\x1b[?1049l") }()
+
+	selected, offset := 0, 0
+	for {
+		// Keep the selection within the visible part of the list.
+		rows := p.listHeight()
+		if selected < offset {
+			offset = selected
+		} else if selected >= offset+rows {
+			offset = selected - rows + 1
+		}
+		if err := p.draw(query, results, selected, offset); err != nil {
+			return 0, err
+		}
+
+		key, err := p.readKey()
+		if err != nil {
+			return 0, err
+		}
+		switch key {
+		case "up", "k":
+			selected = max(0, selected-1)
+		case "down", "j":
+			selected = min(len(results)-1, selected+1)
+		case "home", "g":
+			selected = 0
+		case "end", "G":
+			selected = len(results) - 1
+		case "enter":
+			return selected, nil
+		case "quit", "q":
+			return 0, ErrCanceled
+		}
+	}
+}
+
+// readKey reads a key press and returns its name, or the typed character.
+func (p *Picker) readKey() (string, error) {
+	b, err := p.in.ReadByte()
+	if err != nil {
+		return "", err
+	}
+	switch b {
+	case keyEnter, '\n':
+		return "enter", nil
+	case keyCtrlC:
+		return "quit", nil
+	case keyEsc:
+		// Arrow keys are sent as ESC [ A and friends. Anything else after
+		// an escape, such as a second escape, quits.
+		if next, err := p.in.ReadByte(); err != nil {
+			return "", err
+		} else if next != '[' && next != 'O' {
+			return "quit", nil
+		}
+		code, err := p.in.ReadByte()
+		if err != nil {
+			return "", err
+		}
+		switch code {
+		case 'A':
+			return "up", nil
+		case 'B':
+			return "down", nil
+		case 'H':
+			return "home", nil
+		case 'F':
+			return "end", nil
+		}
+		return "", nil
+	}
+	return string(rune(b)), nil
+}
+
+// listHeight returns the number of results shown at once, leaving room for
+// the two header lines, the detail pane and a last empty line so that the
+// screen does not scroll.
+func (p *Picker) listHeight() int {
+	return max(1, p.Height-detailHeight-3)
+}
+
+// draw redraws the whole screen.
+func (p *Picker) draw(query string, results []bookid.BookResult, selected, offset int) error {
+	var b strings.Builder
+	b.WriteString("\x1b[H\x1b[2J")
+	p.line(&b, fmt.Sprintf("%d results for %q", len(results), query), false)
+	p.line(&b, "↑/↓ select · enter save · q quit", false)
+
+	for i := offset; i < min(len(results), offset+p.listHeight()); i++ {
+		p.line(&b, summary(&results[i]), i == selected)
+	}
+
+	p.line(&b, strings.Repeat("─", p.Width), false)
+	for _, field := range details(&results[selected]) {
+		p.line(&b, field, false)
+	}
+
+	_, err := io.WriteString(p.out, b.String())
+	return err
+}
+
+// line writes s truncated to the terminal width, highlighted in reverse video
+// if selected. Raw terminals need an explicit carriage return.
+func (p *Picker) line(b *strings.Builder, s string, selected bool) {
+	s = truncate(s, p.Width)
+	if selected {
+		b.WriteString("\x1b[7m" + s + strings.Repeat(" ", p.Width-utf8.RuneCountInString(s)) + "\x1b[0m")
+	} else {
+		b.WriteString(s)
+	}
+	b.WriteString("\r\n")
+}
+
+// summary returns a one-line description of a result for the list.
+func summary(r *bookid.BookResult) string {
+	var parts []string
+	if len(r.Authors) > 0 {
+		parts = append(parts, strings.Join(r.Authors, ", "))
+	}
+	if r.PublishedYear != 0 {
+		parts = append(parts, strconv.Itoa(r.PublishedYear))
+	}
+	if r.Publisher != "" {
+		parts = append(parts, r.Publisher)
+	}
+	s := fmt.Sprintf("%3.0f%%  %s", r.Confidence*100, r.Title)
+	if len(parts) > 0 {
+		s += " — " + strings.Join(parts, " · ")
+	}
+	return s
+}
+
+// details returns the lines of the detail pane for a result.
+func details(r *bookid.BookResult) []string {
+	year := ""
+	if r.PublishedYear != 0 {
+		year = strconv.Itoa(r.PublishedYear)
+	}
+	confidence := fmt.Sprintf("%.2f", r.Confidence)
+	if r.SearchType != "" {
+		confidence += " (" + string(r.SearchType) + " search)"
+	}
+	fields := []struct{ name, value string }{
+		{"Title", r.Title},
+		{"Authors", strings.Join(r.Authors, ", ")},
+		{"Publisher", r.Publisher},
+		{"Year", year},
+		{"ISBN", strings.TrimSpace(r.ISBN13 + " " + r.ISBN10)},
+		{"Language", r.Language},
+		{"Provider", r.Provider},
+		{"Confidence", confidence},
+	}
+	lines := make([]string, 0, len(fields))
+	for _, f := range fields {
+		lines = append(lines, fmt.Sprintf("%-11s %s", f.name+":", f.value))
+	}
+	return lines
+}
+
+// truncate shortens s to at most n runes, marking cut text with an ellipsis.
+func truncate(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	} else if n < 1 {
+		return ""
+	}
+	return string([]rune(s)[:n-1]) + "…"
+}
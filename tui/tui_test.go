@@ -0,0 +1,84 @@
+package tui_test
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/tui"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func results() []bookid.BookResult {
+	return []bookid.BookResult{
+		{Title: "Dune", Authors: []string{"Frank Herbert"}, PublishedYear: 1965, Publisher: "Chilton", Confidence: 0.9},
+		{Title: "Dune Messiah", Authors: []string{"Frank Herbert"}, PublishedYear: 1969, ISBN13: "9780441172696", Confidence: 0.6},
+		{Title: "Dune: House Atreides", Authors: []string{"Brian Herbert", "Kevin J. Anderson"}, Confidence: 0.4},
+	}
+}
+
+func TestPicker_Pick(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		name  string
+		keys  string
+		want  int
+		isErr error
+	}{
+		{"Enter", "\r", 0, nil},
+		{"Down", "\x1b[B\r", 1, nil},
+		{"DownPastEnd", "\x1b[B\x1b[Bj\x1b[B\r", 2, nil},
+		{"UpPastStart", "j\x1b[Akk\r", 0, nil},
+		{"End", "G\r", 2, nil},
+		{"Quit", "jq", 0, tui.ErrCanceled},
+		{"CtrlC", "\x03", 0, tui.ErrCanceled},
+		{"DoubleEsc", "\x1b\x1b", 0, tui.ErrCanceled},
+		{"EOF", "j", 0, io.EOF},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			p := tui.NewPicker(strings.NewReader(tt.keys), io.Discard)
+			got, err := p.Pick("dune", results())
+			if tt.isErr != nil {
+				require.ErrorIs(t, err, tt.isErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	t.Run("ErrInvalid", func(t *testing.T) {
+		t.Parallel()
+		_, err := tui.NewPicker(strings.NewReader("\r"), io.Discard).Pick("dune", nil)
+		assert.Equal(t, bookid.EINVALID, bookid.ErrorCode(err))
+	})
+}
+
+func TestPicker_Draw(t *testing.T) {
+	t.Parallel()
+
+	var out bytes.Buffer
+	p := tui.NewPicker(strings.NewReader("j\r"), &out)
+	p.Width = 40
+	_, err := p.Pick("dune", results())
+	require.NoError(t, err)
+
+	// The last screen highlights the second result and shows its details.
+	screens := strings.Split(out.String(), "\x1b[H\x1b[2J")
+	last := screens[len(screens)-1]
+	assert.Contains(t, last, "3 results for \"dune\"")
+	assert.Contains(t, last, "\x1b[7m 60%  Dune Messiah — Frank Herbert · 19…")
+	assert.Contains(t, last, "ISBN:       9780441172696")
+
+	// Lines are cut to the terminal width.
+	for _, line := range strings.Split(last, "\r\n") {
+		line = strings.NewReplacer("\x1b[7m", "", "\x1b[0m", "", "\x1b[?25h\x1b[?1049l", "").Replace(line)
+		assert.LessOrEqual(t, len([]rune(line)), 40, line)
+	}
+	assert.Contains(t, last, " 40%  Dune: House Atreides — Brian Herb…")
+}
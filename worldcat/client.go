@@ -0,0 +1,278 @@
+// Package worldcat implements the BookFinder interface on top of the OCLC
+// WorldCat Search API v2. Results carry OCLC numbers so library users can
+// cross-reference holdings.
+package worldcat
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/internal/httpclient"
+	"github.com/fwojciec/bookid/isbn"
+	"github.com/fwojciec/bookid/language"
+	bookidquery "github.com/fwojciec/bookid/query"
+	"github.com/fwojciec/bookid/scoring"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+)
+
+// ProviderName identifies results produced by this package.
+const ProviderName = "worldcat"
+
+// DefaultBaseURL is the root of the WorldCat Search API v2.
+const DefaultBaseURL = "https://americas.discovery.api.oclc.org/worldcat/search/v2"
+
+// DefaultTokenURL is the OCLC OAuth 2 token endpoint.
+const DefaultTokenURL = "https://oauth.oclc.org/token"
+
+// Page sizes for the brief bibs search. The API rejects more than 50.
+const (
+	defaultMaxResults = 10
+	maxMaxResults     = 50
+)
+
+// Client implements the BookFinder interface for the WorldCat Search API.
+type Client struct {
+	httpClient *http.Client
+	baseURL    string
+
+	// Computes the confidence of each result.
+	Scorer *scoring.Scorer
+}
+
+// Register the provider so it can be enabled by name.
+func init() {
+	bookid.RegisterFinder(ProviderName, func(config bookid.ProviderConfig) (bookid.BookFinder, error) {
+		if config.ClientID == "" || config.ClientSecret == "" {
+			return nil, bookid.Errorf(bookid.EUNAUTHORIZED, "WorldCat client credentials are not configured.")
+		}
+		return newClient(config.Client(), config.ClientID, config.ClientSecret), nil
+	})
+}
+
+// NewClient creates a new WorldCat client authenticating with an OCLC WSKey
+// client ID and secret. Access tokens are requested on first use and
+// refreshed when they expire.
+func NewClient(clientID, clientSecret string) *Client {
+	return newClient(http.DefaultClient, clientID, clientSecret)
+}
+
+// newClient creates a new WorldCat client sending token and API requests
+// through httpClient.
+func newClient(httpClient *http.Client, clientID, clientSecret string) *Client {
+	config := &clientcredentials.Config{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		TokenURL:     DefaultTokenURL,
+		Scopes:       []string{"wcapi"},
+	}
+	ctx := context.WithValue(context.Background(), oauth2.HTTPClient, httpClient)
+	return NewClientWithBaseURL(config.Client(ctx), DefaultBaseURL)
+}
+
+// NewClientWithBaseURL creates a new client against a custom endpoint (for
+// testing). httpClient must authenticate requests itself.
+func NewClientWithBaseURL(httpClient *http.Client, baseURL string) *Client {
+	return &Client{
+		httpClient: httpClient,
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		Scorer:     scoring.Default(),
+	}
+}
+
+// Search performs a book search based on the provided query. ISBNs are
+// searched by the "bn" index, fields by their indexes and everything else as
+// keywords.
+func (c *Client) Search(ctx context.Context, query string, opts bookid.SearchOptions) ([]bookid.BookResult, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, errors.New("query cannot be empty")
+	} else if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	parsed := bookidquery.Parse(query)
+	searchType, q := formatQuery(parsed)
+	if q == "" {
+		// No index for the identifiers, so search them as keywords.
+		searchType, q = bookid.SearchTypeGeneralQuery, query
+	}
+
+	params := url.Values{
+		"q":      {q},
+		"limit":  {strconv.Itoa(pageSize(opts.MaxResults))},
+		"offset": {strconv.Itoa(opts.StartIndex + 1)}, // WorldCat offsets are 1-based
+	}
+	if lang := cmp.Or(opts.Language, parsed.Language); lang != "" {
+		params.Set("inLanguage", language.ToMARC(lang))
+	}
+	switch opts.PrintType {
+	case bookid.PrintTypeBooks:
+		params.Set("itemType", "book")
+	case bookid.PrintTypeMagazines:
+		params.Set("itemType", "jrnl")
+	}
+	if opts.OrderBy == bookid.OrderByNewest {
+		params.Set("orderBy", "publicationDateDesc")
+	}
+
+	var resp struct {
+		BriefRecords []json.RawMessage `json:"briefRecords"`
+	}
+	if err := c.get(ctx, "/brief-bibs", params, &resp); err != nil {
+		return nil, err
+	}
+
+	results := make([]bookid.BookResult, 0, len(resp.BriefRecords))
+	for _, raw := range resp.BriefRecords {
+		var bib briefBib
+		if err := json.Unmarshal(raw, &bib); err != nil {
+			return nil, fmt.Errorf("decoding worldcat record: %w", err)
+		}
+		result := bib.toBookResult(searchType)
+		result.ProviderData = raw
+		result.Confidence = c.Scorer.Score(scoring.Input{Query: query, Options: opts, Result: result})
+		results = append(results, result)
+	}
+	return opts.Apply(results), nil
+}
+
+// formatQuery returns the search query for q along with its search type. The
+// first ISBN takes precedence over fields, which are ANDed with the free
+// text. Returns an empty query if q has only identifiers without an index.
+func formatQuery(q bookid.ParsedQuery) (bookid.SearchType, string) {
+	if code := q.ISBN(); code != "" {
+		return bookid.SearchTypeISBN, "bn:" + code
+	}
+
+	var clauses []string
+	if q.Terms != "" {
+		clauses = append(clauses, q.Terms)
+	}
+	for _, field := range []struct{ index, value string }{
+		{"ti:", q.Title},
+		{"au:", q.Author},
+		{"pb:", q.Publisher},
+	} {
+		if field.value != "" {
+			clauses = append(clauses, field.index+strconv.Quote(field.value))
+		}
+	}
+	if q.Year != 0 {
+		clauses = append(clauses, "yr:"+strconv.Itoa(q.Year))
+	}
+
+	searchType := bookid.SearchTypeGeneralQuery
+	if t := q.SearchType(); t == bookid.SearchTypeTitle || t == bookid.SearchTypeTitleAuthor {
+		searchType = t
+	}
+	return searchType, strings.Join(clauses, " AND ")
+}
+
+// pageSize returns the number of records to request for maxResults.
+func pageSize(maxResults int) int {
+	if maxResults <= 0 {
+		return defaultMaxResults
+	}
+	return min(maxResults, maxMaxResults)
+}
+
+// get performs a GET request against the API and decodes the JSON response into v.
+func (c *Client) get(ctx context.Context, path string, params url.Values, v any) error {
+	return httpclient.GetJSON(ctx, c.httpClient, "WorldCat", c.baseURL+path+"?"+params.Encode(), nil, v)
+}
+
+// briefBib is a single record of a brief-bibs response.
+type briefBib struct {
+	OCLCNumber       string   `json:"oclcNumber"`
+	Title            string   `json:"title"`
+	Creator          string   `json:"creator"`
+	Date             string   `json:"date"`
+	Language         string   `json:"language"`
+	Publisher        string   `json:"publisher"`
+	PublicationPlace string   `json:"publicationPlace"`
+	Edition          string   `json:"edition"`
+	GeneralFormat    string   `json:"generalFormat"`
+	SpecificFormat   string   `json:"specificFormat"`
+	ISBNs            []string `json:"isbns"`
+}
+
+// toBookResult converts a brief record to our BookResult.
+func (b *briefBib) toBookResult(searchType bookid.SearchType) bookid.BookResult {
+	result := bookid.BookResult{
+		Title:         cleanTitle(b.Title),
+		Authors:       splitCreators(b.Creator),
+		OCLCNumber:    b.OCLCNumber,
+		Publisher:     b.Publisher,
+		PublishedYear: extractYear(b.Date),
+		Language:      language.FromMARC(b.Language),
+		Provider:      ProviderName,
+		SearchType:    searchType,
+	}
+
+	// Records list every ISBN of the manifestation; keep the first of each form.
+	for _, code := range b.ISBNs {
+		code = isbn.Normalize(code)
+		switch {
+		case result.ISBN13 == "" && isbn.Valid13(code):
+			result.ISBN13 = code
+		case result.ISBN10 == "" && isbn.Valid10(code):
+			result.ISBN10 = code
+		}
+	}
+
+	metadata := map[string]string{
+		"edition":           b.Edition,
+		"format":            b.GeneralFormat,
+		"specific_format":   b.SpecificFormat,
+		"publication_place": b.PublicationPlace,
+	}
+	for k, v := range metadata {
+		if v == "" {
+			delete(metadata, k)
+		}
+	}
+	if len(metadata) > 0 {
+		result.Metadata = metadata
+	}
+	return result
+}
+
+// cleanTitle strips the statement of responsibility and trailing ISBD
+// punctuation from a catalog title, e.g. "The great Gatsby / F. Scott
+// Fitzgerald." becomes "The great Gatsby".
+func cleanTitle(title string) string {
+	title, _, _ = strings.Cut(title, " / ")
+	return strings.TrimRight(strings.TrimSpace(title), " /:;.,")
+}
+
+// splitCreators splits a creator statement such as "F. Scott Fitzgerald;
+// Matthew J. Bruccoli" into individual names.
+func splitCreators(creator string) []string {
+	authors := []string{}
+	for _, name := range strings.Split(creator, ";") {
+		if name = strings.TrimRight(strings.TrimSpace(name), "."); name != "" {
+			authors = append(authors, name)
+		}
+	}
+	return authors
+}
+
+// extractYear returns the first four-digit year in a catalog date such as
+// "2004", "c2004" or "[2004]".
+func extractYear(date string) int {
+	for i := 0; i+4 <= len(date); i++ {
+		if year, err := strconv.Atoi(date[i : i+4]); err == nil && year >= 1000 && year <= 2999 {
+			return year
+		}
+	}
+	return 0
+}
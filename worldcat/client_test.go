@@ -0,0 +1,133 @@
+package worldcat_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"testing"
+
+	"github.com/fwojciec/bookid"
+	"github.com/fwojciec/bookid/internal/httptestutil"
+	"github.com/fwojciec/bookid/worldcat"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newClient returns a client replaying the given synthetic fixture, written
+// by hand after the API documentation rather than recorded. The URL of the
+// last request is stored in lastURL, if set.
+func newClient(t *testing.T, fixture string, lastURL *string) *worldcat.Client {
+	t.Helper()
+	httpClient := httptestutil.Replay(t, filepath.Join("testdata", "synthetic", fixture))
+	if lastURL != nil {
+		httpClient = httptestutil.LastURL(httpClient, lastURL)
+	}
+	return worldcat.NewClientWithBaseURL(httpClient, worldcat.DefaultBaseURL)
+}
+
+func TestClient_Search(t *testing.T) {
+	t.Parallel()
+
+	t.Run("isbn", func(t *testing.T) {
+		t.Parallel()
+		var lastURL string
+		client := newClient(t, "brief_bibs_9780743273565.json", &lastURL)
+
+		results, err := client.Search(context.Background(), "978-0-7432-7356-5", bookid.SearchOptions{MaxResults: 1, IncludeRaw: true})
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		assert.Contains(t, lastURL, "/brief-bibs?")
+		assert.Contains(t, lastURL, "q=bn%3A9780743273565")
+
+		r := results[0]
+		assert.Equal(t, "The great Gatsby", r.Title)
+		assert.Equal(t, []string{"F. Scott Fitzgerald"}, r.Authors)
+		assert.Equal(t, "54005413", r.OCLCNumber)
+		assert.Equal(t, "0743273567", r.ISBN10)
+		assert.Equal(t, "9780743273565", r.ISBN13)
+		assert.Equal(t, "Scribner", r.Publisher)
+		assert.Equal(t, 2004, r.PublishedYear)
+		assert.Equal(t, "en", r.Language)
+		assert.Equal(t, worldcat.ProviderName, r.Provider)
+		assert.Equal(t, bookid.SearchTypeISBN, r.SearchType)
+		assert.InDelta(t, 0.95, r.Confidence, 0.01)
+		assert.NotEmpty(t, r.ProviderData)
+		assert.Equal(t, "New York", r.Metadata["publication_place"])
+		assert.Equal(t, "PrintBook", r.Metadata["specific_format"])
+	})
+
+	t.Run("general", func(t *testing.T) {
+		t.Parallel()
+		var lastURL string
+		client := newClient(t, "brief_bibs_gatsby.json", &lastURL)
+
+		results, err := client.Search(context.Background(), "the great gatsby", bookid.SearchOptions{
+			MaxResults: 100,
+			StartIndex: 20,
+			Language:   "en",
+			PrintType:  bookid.PrintTypeBooks,
+			OrderBy:    bookid.OrderByNewest,
+		})
+		require.NoError(t, err)
+		require.Len(t, results, 2)
+		for _, want := range []string{"q=the+great+gatsby", "limit=50", "offset=21", "inLanguage=eng", "itemType=book", "orderBy=publicationDateDesc"} {
+			assert.Contains(t, lastURL, want)
+		}
+
+		r := results[1]
+		assert.Equal(t, "The great Gatsby : a graphic novel adaptation", r.Title)
+		assert.Equal(t, []string{"Fred Fordham", "F. Scott Fitzgerald"}, r.Authors)
+		assert.Equal(t, "1198377520", r.OCLCNumber)
+		assert.Empty(t, r.ISBN13)
+		assert.Equal(t, bookid.SearchTypeGeneralQuery, r.SearchType)
+		assert.Nil(t, r.ProviderData, "raw data is only kept when requested")
+	})
+
+	t.Run("fields", func(t *testing.T) {
+		t.Parallel()
+		var lastURL string
+		client := newClient(t, "brief_bibs_graphic_novel.json", &lastURL)
+
+		_, err := client.Search(context.Background(), `graphic novel title:"The Great Gatsby" lang:eng`, bookid.SearchOptions{})
+		require.NoError(t, err)
+		u, err := url.Parse(lastURL)
+		require.NoError(t, err)
+		assert.Equal(t, `graphic novel AND ti:"The Great Gatsby"`, u.Query().Get("q"))
+		assert.Equal(t, "eng", u.Query().Get("inLanguage"))
+	})
+}
+
+func TestClient_Search_Errors(t *testing.T) {
+	t.Parallel()
+
+	t.Run("empty_query", func(t *testing.T) {
+		t.Parallel()
+		_, err := worldcat.NewClientWithBaseURL(http.DefaultClient, "").Search(context.Background(), "  ", bookid.SearchOptions{})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "query cannot be empty")
+	})
+
+	tests := []struct {
+		status int
+		code   string
+	}{
+		{http.StatusUnauthorized, bookid.EUNAUTHORIZED},
+		{http.StatusTooManyRequests, bookid.ERATELIMIT},
+		{http.StatusBadGateway, bookid.EUNAVAILABLE},
+	}
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			t.Parallel()
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(tt.status)
+			}))
+			t.Cleanup(srv.Close)
+			client := worldcat.NewClientWithBaseURL(srv.Client(), srv.URL)
+
+			_, err := client.Search(context.Background(), "dune", bookid.SearchOptions{})
+			assert.Equal(t, tt.code, bookid.ErrorCode(err))
+		})
+	}
+}
@@ -0,0 +1,147 @@
+// Package xlsx writes Office Open XML spreadsheets with a single worksheet.
+// Rows are streamed into the archive as they are written, so the size of a
+// spreadsheet is not limited by memory. All cells are written as text, which
+// keeps identifiers such as ISBNs with leading zeros intact.
+package xlsx
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// DefaultSheetName is the name of the worksheet unless Writer.SheetName is set.
+const DefaultSheetName = "Sheet1"
+
+// XML namespaces of the package parts.
+const (
+	spreadsheetNamespace  = "http://schemas.openxmlformats.org/spreadsheetml/2006/main"
+	relationshipNamespace = "http://schemas.openxmlformats.org/officeDocument/2006/relationships"
+	packageRelNamespace   = "http://schemas.openxmlformats.org/package/2006/relationships"
+	contentTypesNamespace = "http://schemas.openxmlformats.org/package/2006/content-types"
+)
+
+// Writer writes rows to a spreadsheet.
+type Writer struct {
+	// SheetName is the name of the worksheet, DefaultSheetName if empty. It
+	// must be set before the first row is written.
+	SheetName string
+
+	zw    *zip.Writer
+	sheet *bufio.Writer
+	rows  int
+}
+
+// NewWriter returns a new Writer that writes to w.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{zw: zip.NewWriter(w)}
+}
+
+// Write writes a row of cells.
+func (w *Writer) Write(cells []string) error {
+	if w.sheet == nil {
+		if err := w.start(); err != nil {
+			return err
+		}
+	}
+
+	w.rows++
+	row := strconv.Itoa(w.rows)
+	var b strings.Builder
+	b.WriteString(`<row r="` + row + `">`)
+	for i, cell := range cells {
+		if cell == "" {
+			continue
+		}
+		b.WriteString(`<c r="` + columnName(i) + row + `" t="inlineStr"><is>`)
+		if strings.TrimSpace(cell) != cell {
+			b.WriteString(`<t xml:space="preserve">`)
+		} else {
+			b.WriteString(`<t>`)
+		}
+		if err := xml.EscapeText(&b, []byte(cell)); err != nil {
+			return err
+		}
+		b.WriteString(`</t></is></c>`)
+	}
+	b.WriteString(`</row>`)
+	_, err := w.sheet.WriteString(b.String())
+	return err
+}
+
+// Close finishes the worksheet and the archive. It does not close the
+// underlying writer. A spreadsheet without rows is still valid.
+func (w *Writer) Close() error {
+	if w.sheet == nil {
+		if err := w.start(); err != nil {
+			return err
+		}
+	}
+	if _, err := w.sheet.WriteString(`</sheetData></worksheet>`); err != nil {
+		return err
+	}
+	if err := w.sheet.Flush(); err != nil {
+		return err
+	}
+	return w.zw.Close()
+}
+
+// start writes the fixed parts of the package and opens the worksheet.
+func (w *Writer) start() error {
+	name := w.SheetName
+	if name == "" {
+		name = DefaultSheetName
+	}
+	var escaped strings.Builder
+	if err := xml.EscapeText(&escaped, []byte(name)); err != nil {
+		return err
+	}
+
+	parts := []struct{ name, content string }{
+		{"[Content_Types].xml", `<Types xmlns="` + contentTypesNamespace + `">` +
+			`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+			`<Default Extension="xml" ContentType="application/xml"/>` +
+			`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+			`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+			`</Types>`},
+		{"_rels/.rels", `<Relationships xmlns="` + packageRelNamespace + `">` +
+			`<Relationship Id="rId1" Type="` + relationshipNamespace + `/officeDocument" Target="xl/workbook.xml"/>` +
+			`</Relationships>`},
+		{"xl/workbook.xml", `<workbook xmlns="` + spreadsheetNamespace + `" xmlns:r="` + relationshipNamespace + `">` +
+			`<sheets><sheet name="` + escaped.String() + `" sheetId="1" r:id="rId1"/></sheets>` +
+			`</workbook>`},
+		{"xl/_rels/workbook.xml.rels", `<Relationships xmlns="` + packageRelNamespace + `">` +
+			`<Relationship Id="rId1" Type="` + relationshipNamespace + `/worksheet" Target="worksheets/sheet1.xml"/>` +
+			`</Relationships>`},
+	}
+	for _, part := range parts {
+		f, err := w.zw.Create(part.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(f, xml.Header+part.content); err != nil {
+			return err
+		}
+	}
+
+	f, err := w.zw.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return err
+	}
+	w.sheet = bufio.NewWriter(f)
+	_, err = w.sheet.WriteString(xml.Header + `<worksheet xmlns="` + spreadsheetNamespace + `"><sheetData>`)
+	return err
+}
+
+// columnName returns the letters of the zero-based column i: A to Z, then AA
+// and so on.
+func columnName(i int) string {
+	var b []byte
+	for i++; i > 0; i = (i - 1) / 26 {
+		b = append([]byte{byte('A' + (i-1)%26)}, b...)
+	}
+	return string(b)
+}
@@ -0,0 +1,95 @@
+package xlsx_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"testing"
+
+	"github.com/fwojciec/bookid/xlsx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// worksheet is the subset of a worksheet part the tests read back.
+type worksheet struct {
+	Rows []struct {
+		R     string `xml:"r,attr"`
+		Cells []struct {
+			R    string `xml:"r,attr"`
+			Text string `xml:"is>t"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+// readPart returns the contents of the named part of a spreadsheet.
+func readPart(t *testing.T, data []byte, name string) []byte {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+	f, err := zr.Open(name)
+	require.NoError(t, err)
+	defer f.Close()
+	b, err := io.ReadAll(f)
+	require.NoError(t, err)
+	return b
+}
+
+func TestWriter(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	w := xlsx.NewWriter(&buf)
+	w.SheetName = "Books & Co"
+	require.NoError(t, w.Write([]string{"title", "isbn10"}))
+	require.NoError(t, w.Write([]string{"Fish <& Chips>", "0743273567"}))
+	require.NoError(t, w.Write([]string{"", " padded "}))
+	require.NoError(t, w.Close())
+
+	var sheet worksheet
+	require.NoError(t, xml.Unmarshal(readPart(t, buf.Bytes(), "xl/worksheets/sheet1.xml"), &sheet))
+	require.Len(t, sheet.Rows, 3)
+	assert.Equal(t, "1", sheet.Rows[0].R)
+	assert.Equal(t, "B2", sheet.Rows[1].Cells[1].R)
+	assert.Equal(t, "Fish <& Chips>", sheet.Rows[1].Cells[0].Text)
+	assert.Equal(t, "0743273567", sheet.Rows[1].Cells[1].Text)
+
+	// Empty cells are omitted and surrounding space is kept.
+	require.Len(t, sheet.Rows[2].Cells, 1)
+	assert.Equal(t, "B3", sheet.Rows[2].Cells[0].R)
+	assert.Equal(t, " padded ", sheet.Rows[2].Cells[0].Text)
+
+	assert.Contains(t, string(readPart(t, buf.Bytes(), "xl/workbook.xml")), `name="Books &amp; Co"`)
+}
+
+func TestWriter_Empty(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	require.NoError(t, xlsx.NewWriter(&buf).Close())
+
+	var sheet worksheet
+	require.NoError(t, xml.Unmarshal(readPart(t, buf.Bytes(), "xl/worksheets/sheet1.xml"), &sheet))
+	assert.Empty(t, sheet.Rows)
+	assert.Contains(t, string(readPart(t, buf.Bytes(), "xl/workbook.xml")), `name="Sheet1"`)
+}
+
+func TestWriter_ManyColumns(t *testing.T) {
+	t.Parallel()
+
+	cells := make([]string, 28)
+	cells[25], cells[26], cells[27] = "z", "aa", "ab"
+
+	var buf bytes.Buffer
+	w := xlsx.NewWriter(&buf)
+	require.NoError(t, w.Write(cells))
+	require.NoError(t, w.Close())
+
+	var sheet worksheet
+	require.NoError(t, xml.Unmarshal(readPart(t, buf.Bytes(), "xl/worksheets/sheet1.xml"), &sheet))
+	require.Len(t, sheet.Rows[0].Cells, 3)
+	assert.Equal(t, "Z1", sheet.Rows[0].Cells[0].R)
+	assert.Equal(t, "AA1", sheet.Rows[0].Cells[1].R)
+	assert.Equal(t, "AB1", sheet.Rows[0].Cells[2].R)
+}